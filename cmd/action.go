@@ -35,6 +35,8 @@ func ActionCommand(cliConfig *Config) *cli.Command {
 	cmd.AddCommand(editActionCommand(cliConfig))
 	cmd.AddCommand(viewActionCommand(cliConfig))
 	cmd.AddCommand(listActionCommand(cliConfig))
+	cmd.AddCommand(deleteCommand(cliConfig, "action", actionOptions))
+	cmd.AddCommand(templateCommand("action", &shieldv1beta1.ActionRequestBody{}))
 
 	bindFlagsFromClientConfig(cmd)
 
@@ -43,6 +45,7 @@ func ActionCommand(cliConfig *Config) *cli.Command {
 
 func createActionCommand(cliConfig *Config) *cli.Command {
 	var filePath, header string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "create",
@@ -50,6 +53,7 @@ func createActionCommand(cliConfig *Config) *cli.Command {
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield action create --file=<action-body> --header=<key>:<value>
+			$ shield action create --file=<action-body> --header=<key>:<value> --dry-run
 		`),
 		Annotations: map[string]string{
 			"action:core": "true",
@@ -74,10 +78,22 @@ func createActionCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			ctx := setCtxHeader(cmd.Context(), header)
-			res, err := client.CreateAction(ctx, &shieldv1beta1.CreateActionRequest{
+			req := &shieldv1beta1.CreateActionRequest{
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "namespace", Field: "namespace_id", Value: reqBody.GetNamespaceId()},
+				)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "CreateAction", req, nil, refs...)
+			}
+
+			ctx := setCtxHeader(cmd.Context(), header)
+			res, err := client.CreateAction(ctx, req)
 			if err != nil {
 				return err
 			}
@@ -90,25 +106,30 @@ func createActionCommand(cliConfig *Config) *cli.Command {
 
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the action body file")
 	cmd.MarkFlagRequired("file")
-	cmd.Flags().StringVarP(&header, "header", "H", "", "Header <key>:<value>")
-	cmd.MarkFlagRequired("header")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func editActionCommand(cliConfig *Config) *cli.Command {
 	var filePath string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "edit",
 		Short: "Edit an action",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield action edit <action-id> --file=<action-body>
+			$ shield action edit --file=<action-body>
+			$ shield action edit <action-id> --file=<action-body> --dry-run
 		`),
 		Annotations: map[string]string{
 			"action:core": "true",
 		},
+		ValidArgsFunction: completeIDs(actionOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -129,11 +150,28 @@ func editActionCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			actionID := args[0]
-			_, err = client.UpdateAction(cmd.Context(), &shieldv1beta1.UpdateActionRequest{
+			actionID, err := resolveID(cmd, client, spinner, args, "action", actionOptions)
+			if err != nil {
+				return err
+			}
+
+			req := &shieldv1beta1.UpdateActionRequest{
 				Id:   actionID,
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "action", Field: "id", Value: actionID},
+					dryRunReference{Resource: "namespace", Field: "namespace_id", Value: reqBody.GetNamespaceId()},
+				)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "UpdateAction", req, nil, refs...)
+			}
+
+			_, err = client.UpdateAction(cmd.Context(), req)
 			if err != nil {
 				return err
 			}
@@ -147,20 +185,27 @@ func editActionCommand(cliConfig *Config) *cli.Command {
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the action body file")
 	cmd.MarkFlagRequired("file")
 
+	bindDryRunFlag(cmd, &dryRun)
+
 	return cmd
 }
 
 func viewActionCommand(cliConfig *Config) *cli.Command {
+	var output string
+
 	cmd := &cli.Command{
 		Use:   "view",
 		Short: "View an action",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield action view <action-id>
+			$ shield action view
+			$ shield action view <action-id> --output=json
 		`),
 		Annotations: map[string]string{
 			"action:core": "true",
 		},
+		ValidArgsFunction: completeIDs(actionOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -171,7 +216,11 @@ func viewActionCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			actionID := args[0]
+			actionID, err := resolveID(cmd, client, spinner, args, "action", actionOptions)
+			if err != nil {
+				return err
+			}
+
 			res, err := client.GetAction(cmd.Context(), &shieldv1beta1.GetActionRequest{
 				Id: actionID,
 			})
@@ -185,6 +234,10 @@ func viewActionCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, action)
+			}
+
 			report = append(report, []string{"ID", "NAME", "NAMESPACE"})
 			report = append(report, []string{
 				action.GetId(),
@@ -197,16 +250,21 @@ func viewActionCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
 	return cmd
 }
 
 func listActionCommand(cliConfig *Config) *cli.Command {
+	var output string
+
 	cmd := &cli.Command{
 		Use:   "list",
 		Short: "List all actions",
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield action list
+			$ shield action list --output=yaml
 		`),
 		Annotations: map[string]string{
 			"action:core": "true",
@@ -231,6 +289,10 @@ func listActionCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, res)
+			}
+
 			if len(actions) == 0 {
 				fmt.Printf("No actions found.\n")
 				return nil
@@ -252,5 +314,7 @@ func listActionCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
 	return cmd
 }
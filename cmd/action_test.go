@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/odpf/shield/cmd"
@@ -25,6 +26,24 @@ func TestClientAction(t *testing.T) {
 				subCommands: []string{"list"},
 				err:         cmd.ErrClientConfigHostNotFound,
 			},
+			{
+				name:        "`action` delete without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"delete", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`action` delete with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
+			{
+				name:        "`action` delete without force should throw error not confirmed",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test", "-H", "X-Shield-Email:admin@odpf.io"},
+				err:         fmt.Errorf("%w: pass --force to delete without a prompt", errors.New("operation cancelled")),
+			},
 			{
 				name:        "`action` list with host flag should pass",
 				want:        "",
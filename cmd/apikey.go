@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	cli "github.com/spf13/cobra"
+)
+
+// apiKeysPath creates, lists and revokes api keys, there are no rpcs for
+// them
+const apiKeysPath = "/admin/v1beta1/apikeys"
+
+type apiKeyResponse struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	UserID    string     `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	Key       string     `json:"key"`
+}
+
+func APIKeyCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:     "apikey",
+		Aliases: []string{"apikeys"},
+		Short:   "Manage api keys",
+		Long: heredoc.Doc(`
+			Work with api keys.
+
+			An api key authenticates requests as the user or service account it belongs
+			to when sent as a bearer token in the authorization header. Keys can be
+			revoked by their id or by their prefix, the part after shk_.
+
+			Users manage their own keys and the superusers the keys of anyone. The admins
+			of every organization a service account is a member of manage its keys.
+		`),
+		Example: heredoc.Doc(`
+			$ shield apikey create --name=ci
+			$ shield apikey create --name=billing --serviceaccount=<serviceaccount-id>
+			$ shield apikey list --user=<user-id>
+			$ shield apikey revoke <apikey-id-or-prefix>
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+	}
+
+	cmd.AddCommand(createAPIKeyCommand(cliConfig))
+	cmd.AddCommand(revokeAPIKeyCommand(cliConfig))
+	cmd.AddCommand(listAPIKeyCommand(cliConfig))
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func createAPIKeyCommand(cliConfig *Config) *cli.Command {
+	var name, userID, serviceAccountID, header string
+
+	cmd := &cli.Command{
+		Use:   "create",
+		Short: "Create an api key",
+		Long: heredoc.Doc(`
+			Create an api key for the current user, a user or a service account.
+
+			The key is only shown once, shield keeps its hash alone.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield apikey create --name=ci
+			$ shield apikey create --name=ci --user=<user-id> --header=<key>:<value>
+			$ shield apikey create --name=billing --serviceaccount=<serviceaccount-id>
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			if userID != "" && serviceAccountID != "" {
+				return errors.New("only one of --user or --serviceaccount can be set")
+			}
+
+			var created apiKeyResponse
+			query := url.Values{"name": {name}, "user": {userID}, "serviceaccount": {serviceAccountID}}
+			if err := postAdminAPI(cmd.Context(), cliConfig, apiKeysPath, query, header, &created); err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully created api key %s with prefix %s, the key is\n \n%s\n", created.ID, created.Prefix, created.Key)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name describing what the key is used for")
+	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringVarP(&userID, "user", "u", "", "ID of the user the key belongs to, the current user by default")
+	cmd.Flags().StringVar(&serviceAccountID, "serviceaccount", "", "ID of the service account the key belongs to")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func revokeAPIKeyCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "revoke <apikey-id-or-prefix>",
+		Short: "Revoke an api key",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield apikey revoke <apikey-id>
+			$ shield apikey revoke 3f9a1c2b7d4e
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			var revoked apiKeyResponse
+			if err := deleteAdminAPI(cmd.Context(), cliConfig, apiKeysPath, url.Values{"id": {args[0]}}, header, &revoked); err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully revoked api key %s with prefix %s\n", revoked.ID, revoked.Prefix)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func listAPIKeyCommand(cliConfig *Config) *cli.Command {
+	var userID, serviceAccountID, header, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List api keys",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield apikey list
+			$ shield apikey list --user=<user-id>
+			$ shield apikey list --serviceaccount=<serviceaccount-id>
+			$ shield apikey list --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			if userID != "" && serviceAccountID != "" {
+				return errors.New("only one of --user or --serviceaccount can be set")
+			}
+
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res struct {
+				APIKeys []apiKeyResponse `json:"api_keys"`
+			}
+			query := url.Values{"user": {userID}, "serviceaccount": {serviceAccountID}}
+			if err := getAdminAPI(cmd.Context(), cliConfig, apiKeysPath, query, header, &res); err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAME", "PREFIX", "USER ID", "CREATED AT", "REVOKED AT"})
+			for _, k := range res.APIKeys {
+				revokedAt := ""
+				if k.RevokedAt != nil {
+					revokedAt = k.RevokedAt.Format(time.RFC3339)
+				}
+				report = append(report, []string{k.ID, k.Name, k.Prefix, k.UserID, k.CreatedAt.Format(time.RFC3339), revokedAt})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d api keys\n \n", len(res.APIKeys))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&userID, "user", "u", "", "Only list the keys of the user, the current user by default")
+	cmd.Flags().StringVar(&serviceAccountID, "serviceaccount", "", "Only list the keys of the service account")
+	bindHeaderFlag(cmd, &header, cliConfig)
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
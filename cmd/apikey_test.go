@@ -0,0 +1,71 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientAPIKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		subCommands []string
+		want        string
+		err         error
+	}{
+		{
+			name:        "`apikey` list only should throw error host not found",
+			want:        "",
+			subCommands: []string{"list"},
+			err:         cmd.ErrClientConfigHostNotFound,
+		},
+		{
+			name:        "`apikey` list with host flag should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"list", "-h", "test"},
+			err:         errors.New("required flag(s) \"header\" not set"),
+		},
+		{
+			name:        "`apikey` list with a user and a service account should throw error",
+			want:        "",
+			subCommands: []string{"list", "-h", "test", "--header", "X-Shield-Email:admin@odpf.io", "--user", "u1", "--serviceaccount", "s1"},
+			err:         errors.New("only one of --user or --serviceaccount can be set"),
+		},
+		{
+			name:        "`apikey` list with unsupported output format should throw error",
+			want:        "",
+			subCommands: []string{"list", "-h", "test", "--header", "X-Shield-Email:admin@odpf.io", "--output", "csv"},
+			err:         errors.New("output format 'csv' is not supported by shield apikey list; valid: table, json, yaml"),
+		},
+		{
+			name:        "`apikey` create with host flag should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"create", "-h", "test"},
+			err:         errors.New("required flag(s) \"header\", \"name\" not set"),
+		},
+		{
+			name:        "`apikey` revoke without id should throw error",
+			want:        "",
+			subCommands: []string{"revoke", "-h", "test", "--header", "X-Shield-Email:admin@odpf.io"},
+			err:         errors.New("accepts 1 arg(s), received 0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+
+			buf := new(bytes.Buffer)
+			cli.SetOutput(buf)
+			cli.SetArgs(append([]string{"apikey"}, tt.subCommands...))
+
+			err := cli.Execute()
+			got := buf.String()
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/internal/reconcile"
+	cli "github.com/spf13/cobra"
+)
+
+func ApplyCommand(cliConfig *Config) *cli.Command {
+	var dir, header string
+	var yes bool
+
+	cmd := &cli.Command{
+		Use:   "apply",
+		Short: "Apply resources declared in files",
+		Long: heredoc.Doc(`
+			Create or update the resources declared in the yaml and json files of a directory.
+
+			Each document declares a kind, one of organization, project, group, role,
+			policy, resource or relation, and a spec with the request body of that kind.
+			Organizations, projects and groups can be referred to by slug and users by
+			email. The changes are shown before anything is applied.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield apply --file=resources/ --header=<key>:<value>
+			$ shield apply -f resources/ -H <key>:<value> --yes
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			resources, err := reconcile.Load(dir)
+			if err != nil {
+				return err
+			}
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			ctx := setCtxHeader(cmd.Context(), header)
+			plan, err := reconcile.BuildPlan(ctx, client, resources)
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			printPlan(plan)
+			if plan.Pending() == 0 {
+				return nil
+			}
+
+			if !yes {
+				if err := confirm(cmd, fmt.Sprintf("Apply %d changes?", plan.Pending())); err != nil {
+					return err
+				}
+			}
+
+			if err := reconcile.Apply(ctx, client, plan); err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully applied %d changes\n", plan.Pending())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&dir, "file", "f", "", "Path to the directory of resource files")
+	cmd.MarkFlagRequired("file")
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply the changes without a confirmation prompt")
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func printPlan(plan reconcile.Plan) {
+	if plan.Pending() == 0 {
+		fmt.Println("No changes, the server matches the files.")
+		return
+	}
+
+	report := [][]string{}
+	report = append(report, []string{"ACTION", "RESOURCE", "SOURCE"})
+	for _, c := range plan {
+		if c.Action == reconcile.ActionNone {
+			continue
+		}
+		report = append(report, []string{c.Action, c.Resource.Name(), c.Resource.Source})
+	}
+	printer.Table(os.Stdout, report)
+	fmt.Printf(" \n%d to change, %d unchanged\n \n", plan.Pending(), len(plan)-plan.Pending())
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	cli "github.com/spf13/cobra"
+)
+
+// auditLogsPath lists the audit logs, it has no rpc of its own
+const auditLogsPath = "/admin/v1beta1/audit"
+
+type auditLogResponse struct {
+	ID           string    `json:"id"`
+	Actor        string    `json:"actor"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	OldPayload   any       `json:"old_payload"`
+	NewPayload   any       `json:"new_payload"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func AuditCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "audit",
+		Short: "List the audit logs of changes",
+		Long: heredoc.Doc(`
+			Work with the audit logs of the changes made to organizations, projects, groups,
+			policies and the other audited resources.
+
+			A log records who made the change, what it was and the resource before and
+			after it. Only the superusers of the server list the logs.
+		`),
+		Example: heredoc.Doc(`
+			$ shield audit list --actor=john.doe@odpf.io --since=24h
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+	}
+
+	cmd.AddCommand(listAuditCommand(cliConfig))
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func listAuditCommand(cliConfig *Config) *cli.Command {
+	var header, actor, since, until, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List the audit logs, the latest first",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield audit list
+			$ shield audit list --actor=john.doe@odpf.io
+			$ shield audit list --since=72h --until=24h
+			$ shield audit list --since=2023-01-02T00:00:00Z --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			query := url.Values{"actor": {actor}}
+			now := time.Now()
+			for param, value := range map[string]string{"since": since, "until": until} {
+				if value == "" {
+					continue
+				}
+				t, err := parseAuditTime(value, now)
+				if err != nil {
+					return fmt.Errorf("invalid %s: %w", param, err)
+				}
+				query.Set(param, t.Format(time.RFC3339))
+			}
+
+			var listed struct {
+				Logs []auditLogResponse `json:"logs"`
+			}
+			if err := getAdminAPI(cmd.Context(), cliConfig, auditLogsPath, query, header, &listed); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+
+			if output == outputJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(listed.Logs)
+			}
+
+			report := [][]string{{"ID", "ACTOR", "ACTION", "RESOURCE TYPE", "RESOURCE ID", "CREATED AT"}}
+			for _, l := range listed.Logs {
+				report = append(report, []string{l.ID, l.Actor, l.Action, l.ResourceType, l.ResourceID, l.CreatedAt.Format(time.RFC3339)})
+			}
+			fmt.Printf(" \nShowing %d audit logs\n \n", len(listed.Logs))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVar(&actor, "actor", "", "Email of the user who made the changes, system for the changes made by shield itself")
+	cmd.Flags().StringVar(&since, "since", "", "Start of the time range, a duration back like 24h or an RFC3339 time")
+	cmd.Flags().StringVar(&until, "until", "", "End of the time range, a duration back like 24h or an RFC3339 time")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON)
+
+	return cmd
+}
+
+// parseAuditTime reads a duration back from now or an RFC3339 time
+func parseAuditTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q should be a duration like 24h or an RFC3339 time", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,47 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientAudit(t *testing.T) {
+	tests := []struct {
+		name        string
+		subCommands []string
+		want        string
+		err         error
+	}{
+		{
+			name:        "`audit` list only should throw error host not found",
+			want:        "",
+			subCommands: []string{"list"},
+			err:         cmd.ErrClientConfigHostNotFound,
+		},
+		{
+			name:        "`audit` list with host flag should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"list", "-h", "test"},
+			err:         errors.New("required flag(s) \"header\" not set"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+
+			buf := new(bytes.Buffer)
+			cli.SetOutput(buf)
+			cli.SetArgs(append([]string{"audit"}, tt.subCommands...))
+
+			err := cli.Execute()
+			got := buf.String()
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
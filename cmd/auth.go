@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/pkg/db"
+	cli "github.com/spf13/cobra"
+)
+
+func AuthCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "auth",
+		Short: "Manage authentication and login sessions",
+		Long: heredoc.Doc(`
+			Work with authentication.
+
+			The client commands send an additional header of "key:value" format
+			with the identity of the user, e.g.
+				shield create user -f user.yaml -H X-Shield-Email:user@odpf.io
+
+			Once logged in with shield auth login, the token of the login is sent
+			instead and the header can be left out.
+
+			Users logging in with the id token of an oidc provider get a session,
+			its short lived access token is renewed with its refresh token until the
+			session expires or is revoked.
+		`),
+		Example: heredoc.Doc(`
+			$ shield auth login --issuer=https://accounts.google.com --client-id=<client-id>
+			$ shield auth logout
+			$ shield auth sessions
+			$ shield auth sessions --user=<user-id>
+			$ shield auth sessions revoke <session-id>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(loginCommand())
+	cmd.AddCommand(logoutCommand())
+	cmd.AddCommand(sessionsCommand())
+
+	return cmd
+}
+
+func sessionsCommand() *cli.Command {
+	var configFile, userID string
+	var all bool
+
+	cmd := &cli.Command{
+		Use:   "sessions",
+		Short: "List login sessions",
+		Long: heredoc.Doc(`
+			List the active login sessions, of all users unless --user is set.
+
+			The commands connect to the database with the server config.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield auth sessions
+			$ shield auth sessions --user=<user-id> --all
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			svc, cleanup, err := sessionService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			sessions, err := svc.List(cmd.Context(), userID, all)
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "USER ID", "USER AGENT", "CREATED AT", "REFRESHED AT", "EXPIRES AT", "REVOKED AT"})
+			for _, s := range sessions {
+				revokedAt := ""
+				if s.IsRevoked() {
+					revokedAt = s.RevokedAt.Format(time.RFC3339)
+				}
+				report = append(report, []string{
+					s.ID,
+					s.UserID,
+					s.UserAgent,
+					s.CreatedAt.Format(time.RFC3339),
+					s.RefreshedAt.Format(time.RFC3339),
+					s.ExpiresAt.Format(time.RFC3339),
+					revokedAt,
+				})
+			}
+
+			spinner.Stop()
+			fmt.Printf(" \nShowing %d sessions\n \n", len(sessions))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&userID, "user", "u", "", "Only list the sessions of the user")
+	cmd.Flags().BoolVar(&all, "all", false, "List the revoked and expired sessions as well")
+
+	cmd.AddCommand(revokeSessionCommand())
+
+	return cmd
+}
+
+func revokeSessionCommand() *cli.Command {
+	var configFile string
+
+	cmd := &cli.Command{
+		Use:   "revoke <session-id>",
+		Short: "Revoke a login session",
+		Long: heredoc.Doc(`
+			Revoke a login session, its access and refresh tokens are rejected from
+			then on.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield auth sessions revoke <session-id>
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			svc, cleanup, err := sessionService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			revoked, err := svc.Revoke(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully revoked session %s of user %s\n", revoked.ID, revoked.UserID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+
+	return cmd
+}
+
+func sessionService(configFile string) (*session.Service, func(), error) {
+	dbClient, appConfig, err := serverDB(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newSessionService(dbClient, appConfig.Event, appConfig.Session), func() { dbClient.Close() }, nil
+}
+
+func newSessionService(dbClient *db.Client, eventConfig event.Config, sessionConfig session.Config) *session.Service {
+	repositories := newStores(dbClient)
+	auditService := newAuditRecorder(dbClient, eventConfig)
+	return session.NewService(
+		repositories.sessions,
+		user.NewService(repositories.users, auditService),
+		auditService,
+		sessionConfig)
+}
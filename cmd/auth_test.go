@@ -0,0 +1,56 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientAuth(t *testing.T) {
+	tests := []struct {
+		name        string
+		cliConfig   *cmd.Config
+		subCommands []string
+		err         error
+	}{
+		{
+			name:        "`auth` login without host should throw error host not found",
+			cliConfig:   &cmd.Config{},
+			subCommands: []string{"auth", "login"},
+			err:         cmd.ErrClientConfigHostNotFound,
+		},
+		{
+			name:        "`auth` login without issuer should throw error",
+			cliConfig:   &cmd.Config{},
+			subCommands: []string{"auth", "login", "-h", "test"},
+			err:         errors.New("--issuer and --client-id are required on the first login"),
+		},
+		{
+			name:        "client commands should require the header when not logged in",
+			cliConfig:   &cmd.Config{},
+			subCommands: []string{"organization", "create", "-h", "test"},
+			err:         errors.New("required flag(s) \"file\", \"header\" not set"),
+		},
+		{
+			name:        "client commands should not require the header once logged in",
+			cliConfig:   &cmd.Config{Auth: cmd.AuthConfig{Host: "test", RefreshToken: "shr_token"}},
+			subCommands: []string{"organization", "create", "-h", "test"},
+			err:         errors.New("required flag(s) \"file\" not set"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := cmd.New(tt.cliConfig)
+
+			buf := new(bytes.Buffer)
+			cli.SetOutput(buf)
+			cli.SetArgs(tt.subCommands)
+
+			err := cli.Execute()
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
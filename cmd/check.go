@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/api"
+	"github.com/odpf/shield/internal/schema"
+	cli "github.com/spf13/cobra"
+)
+
+func CheckCommand() *cli.Command {
+	var configFile, subject, resource, act, consistency string
+	var checkContext []string
+	var explain bool
+
+	cmd := &cli.Command{
+		Use:   "check",
+		Short: "Check the permission of a subject on a resource",
+		Long: heredoc.Doc(`
+			Check whether a subject has a permission on a resource, to debug the access
+			of users and groups.
+
+			The check is made against spicedb with the server config, the way the check
+			API makes it for the calling user. Subjects are user:<id|email>,
+			group:<id|slug> for the members of a group or <namespace>:<id>[#<relation>].
+			Resources are organization:<id|slug>, project:<id|slug>, group:<id|slug> or
+			<namespace>:<name> for the resources of the other namespaces.
+
+			Checks are answered with the least latency by default, the relations written
+			in the last few seconds may not be seen yet. Use --consistency=full to check
+			against the latest relations, or pass the token returned in the
+			x-shield-zedtoken header of a relation write with
+			--consistency=at-least-as-fresh=<token> to see that write at least.
+
+			--context sets the keys of the request the conditions of the policies are
+			evaluated against, ip and now are the ip address and the RFC 3339 time of the
+			request. The tags of the resource checked are set by shield.
+
+			--explain expands the permission and prints the relations leading from the
+			resource to the subject, [+] marks the ones granting access and [-] the ones
+			that don't, like an exclusion blocking it.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield check --subject=user:alice@odpf.io --resource=project:payments --action=edit
+			$ shield check --subject=group:data --resource=entropy/firehose:<name> --action=view --explain
+			$ shield check --subject=user:<user-id> --resource=organization:odpf --action=view --consistency=full -c ./config.yaml
+			$ shield check --subject=user:alice@odpf.io --resource=entropy/firehose:<name> --action=view --context ip=10.0.0.12 --context team=data
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			c, err := relation.ParseConsistency(consistency)
+			if err != nil {
+				return err
+			}
+			request, err := relation.ParseCheckContext(checkContext)
+			if err != nil {
+				return err
+			}
+
+			deps, cleanup, err := serverDeps(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			ctx := relation.WithConsistency(cmd.Context(), c)
+			sub, err := checkSubject(ctx, deps, subject)
+			if err != nil {
+				return err
+			}
+			obj, tags, err := checkObject(ctx, deps, resource)
+			if err != nil {
+				return err
+			}
+			ctx = relation.WithCheckContext(ctx, relation.CheckContext{Request: request, Resource: tags})
+
+			allowed, err := deps.RelationService.CheckSubject(ctx, sub, obj, action.Action{ID: act})
+			if err != nil {
+				return err
+			}
+
+			var tree relation.AccessTree
+			if explain {
+				if tree, err = deps.RelationService.Expand(ctx, obj, act); err != nil {
+					return err
+				}
+			}
+
+			spinner.Stop()
+			if allowed {
+				fmt.Println("ALLOWED")
+			} else {
+				fmt.Println("DENIED")
+			}
+			if explain {
+				fmt.Println()
+				printAccessTree(os.Stdout, tree, sub, 0)
+				if !tree.Reaches(sub) {
+					fmt.Printf("\nno relation of %s leads to %s\n", accessTreeNode(tree), subjectString(sub))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&subject, "subject", "s", "", "Subject checked, user:<id|email>, group:<id|slug> or <namespace>:<id>[#<relation>]")
+	cmd.Flags().StringVarP(&resource, "resource", "r", "", "Resource checked, organization:<id|slug>, project:<id|slug>, group:<id|slug> or <namespace>:<name>")
+	cmd.Flags().StringVarP(&act, "action", "a", "", "Action checked, like view or edit")
+	cmd.Flags().StringVar(&consistency, "consistency", "", "Freshness of the relations checked, full, minimize-latency or at-least-as-fresh=<token> (default minimize-latency)")
+	cmd.Flags().StringArrayVar(&checkContext, "context", nil, "Key of the request the conditions of the policies are evaluated against as key=value, repeatable")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Print the relations granting or blocking the access")
+	cmd.MarkFlagRequired("subject")
+	cmd.MarkFlagRequired("resource")
+	cmd.MarkFlagRequired("action")
+
+	return cmd
+}
+
+// checkSubject resolves user:<id|email>, group:<id|slug> and
+// <namespace>:<id>[#<relation>] to the subject of a check
+func checkSubject(ctx context.Context, deps api.Deps, ref string) (relation.Subject, error) {
+	ns, id, ok := strings.Cut(ref, ":")
+	if !ok || ns == "" || id == "" {
+		return relation.Subject{}, fmt.Errorf("subject %q should be <namespace>:<id>", ref)
+	}
+
+	switch ns {
+	case "user":
+		usr, err := deps.UserService.Get(ctx, id)
+		if err != nil {
+			return relation.Subject{}, fmt.Errorf("user %s: %w", id, err)
+		}
+		return relation.Subject{ID: usr.ID, Namespace: schema.UserPrincipal}, nil
+	case "group":
+		grp, err := deps.GroupService.Get(ctx, id)
+		if err != nil {
+			return relation.Subject{}, fmt.Errorf("group %s: %w", id, err)
+		}
+		return relation.Subject{ID: grp.ID, Namespace: schema.GroupPrincipal, RoleID: schema.MembershipPermission}, nil
+	}
+
+	id, roleID, _ := strings.Cut(id, "#")
+	return relation.Subject{ID: id, Namespace: ns, RoleID: roleID}, nil
+}
+
+// checkObject resolves organization:<id|slug>, project:<id|slug>,
+// group:<id|slug> and <namespace>:<name> to the object of a check and the
+// tags of the resource
+func checkObject(ctx context.Context, deps api.Deps, ref string) (relation.Object, map[string]string, error) {
+	ns, name, ok := strings.Cut(ref, ":")
+	if !ok || ns == "" || name == "" {
+		return relation.Object{}, nil, fmt.Errorf("resource %q should be <namespace>:<name>", ref)
+	}
+
+	switch ns {
+	case "organization", "org":
+		org, err := deps.OrgService.Get(ctx, name)
+		if err != nil {
+			return relation.Object{}, nil, fmt.Errorf("organization %s: %w", name, err)
+		}
+		return relation.Object{ID: org.ID, NamespaceID: schema.OrganizationNamespace}, nil, nil
+	case "project":
+		prj, err := deps.ProjectService.Get(ctx, name)
+		if err != nil {
+			return relation.Object{}, nil, fmt.Errorf("project %s: %w", name, err)
+		}
+		return relation.Object{ID: prj.ID, NamespaceID: schema.ProjectNamespace}, nil, nil
+	case "group":
+		grp, err := deps.GroupService.Get(ctx, name)
+		if err != nil {
+			return relation.Object{}, nil, fmt.Errorf("group %s: %w", name, err)
+		}
+		return relation.Object{ID: grp.ID, NamespaceID: schema.GroupNamespace}, nil, nil
+	}
+
+	if namespace.IsSystemNamespaceID(ns) {
+		return relation.Object{ID: name, NamespaceID: ns}, nil, nil
+	}
+	res, err := deps.ResourceService.GetByNamespace(ctx, name, ns)
+	if err != nil {
+		return relation.Object{}, nil, fmt.Errorf("resource %s: %w", ref, err)
+	}
+	return relation.Object{ID: res.Idxa, NamespaceID: res.NamespaceID}, res.Tags, nil
+}
+
+// printAccessTree prints the nodes of the tree leading to the subject, all
+// the operands of an intersection or exclusion are printed as each of them
+// decides the access
+func printAccessTree(w io.Writer, tree relation.AccessTree, sub relation.Subject, depth int) {
+	indent := strings.Repeat("  ", depth)
+	mark := "[-]"
+	if tree.Grants(sub) {
+		mark = "[+]"
+	}
+	line := fmt.Sprintf("%s%s %s", indent, mark, accessTreeNode(tree))
+	if tree.Operation != "" {
+		line += " (" + tree.Operation + ")"
+	}
+	fmt.Fprintln(w, line)
+
+	if !tree.Reaches(sub) {
+		return
+	}
+	if tree.HasSubject(sub) {
+		fmt.Fprintf(w, "%s    %s\n", indent, subjectString(sub))
+	}
+	for _, child := range tree.Children {
+		if child.Reaches(sub) || tree.Operation == relation.OperationIntersection || tree.Operation == relation.OperationExclusion {
+			printAccessTree(w, child, sub, depth+1)
+		}
+	}
+}
+
+func accessTreeNode(tree relation.AccessTree) string {
+	return fmt.Sprintf("%s:%s#%s", tree.Object.NamespaceID, tree.Object.ID, tree.Relation)
+}
+
+func subjectString(sub relation.Subject) string {
+	s := sub.Namespace + ":" + sub.ID
+	if sub.RoleID != "" {
+		s += "#" + sub.RoleID
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+package cmd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/odpf/shield/core/relation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		subCommands []string
+		want        string
+		err         error
+	}{
+		{
+			name:        "`check` with a context without value should throw error",
+			want:        "",
+			subCommands: []string{"--subject", "user:jane@odpf.io", "--resource", "entropy/firehose:orders", "--action", "view", "--context", "team"},
+			err:         relation.ErrInvalidCheckContext,
+		},
+		{
+			name:        "`check` with an invalid ip in the context should throw error",
+			want:        "",
+			subCommands: []string{"--subject", "user:jane@odpf.io", "--resource", "entropy/firehose:orders", "--action", "view", "--context", "ip=10.0.0.0/8"},
+			err:         relation.ErrInvalidCheckContext,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+
+			buf := new(bytes.Buffer)
+			cli.SetOutput(buf)
+			cli.SetArgs(append([]string{"check"}, tt.subCommands...))
+
+			err := cli.Execute()
+			got := buf.String()
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
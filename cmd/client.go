@@ -2,43 +2,115 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
-	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/odpf/shield/pkg/shieldclient"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
-func createConnection(ctx context.Context, host string) (*grpc.ClientConn, error) {
-	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-	}
-
-	return grpc.DialContext(ctx, host, opts...)
-}
-
-func createClient(ctx context.Context, host string) (shieldv1beta1.ShieldServiceClient, func(), error) {
+func createClient(ctx context.Context, host string) (*shieldclient.Client, func(), error) {
 	dialTimeoutCtx, dialCancel := context.WithTimeout(ctx, time.Second*2)
-	conn, err := createConnection(dialTimeoutCtx, host)
+	client, err := shieldclient.New(dialTimeoutCtx, shieldclient.Config{
+		Host:  host,
+		Token: cliLoginToken,
+		Block: true,
+	})
 	if err != nil {
 		dialCancel()
 		return nil, nil, err
 	}
 	cancel := func() {
 		dialCancel()
-		conn.Close()
+		client.Close()
 	}
-
-	client := shieldv1beta1.NewShieldServiceClient(conn)
 	return client, cancel, nil
 }
 
+// getAdminAPI gets the json of an endpoint the gateway serves next to the
+// rpcs, with the header of the command and the token of the login the way
+// the grpc client sends them
+func getAdminAPI(ctx context.Context, cliConfig *Config, path string, query url.Values, header string, v any) error {
+	return callAdminAPI(ctx, cliConfig, http.MethodGet, path, query, header, v)
+}
+
+// postAdminAPI posts to an endpoint the gateway serves next to the rpcs the
+// way getAdminAPI gets one, the parameters are sent in the query
+func postAdminAPI(ctx context.Context, cliConfig *Config, path string, query url.Values, header string, v any) error {
+	return callAdminAPI(ctx, cliConfig, http.MethodPost, path, query, header, v)
+}
+
+// deleteAdminAPI deletes with an endpoint the gateway serves next to the
+// rpcs the way postAdminAPI posts to one
+func deleteAdminAPI(ctx context.Context, cliConfig *Config, path string, query url.Values, header string, v any) error {
+	return callAdminAPI(ctx, cliConfig, http.MethodDelete, path, query, header, v)
+}
+
+func callAdminAPI(ctx context.Context, cliConfig *Config, method, path string, query url.Values, header string, v any) error {
+	resp, err := doAdminAPI(ctx, cliConfig, method, path, query, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// doAdminAPI sends the requests of the admin api helpers, the body of
+// the response is left to the caller once the response is known to be ok
+func doAdminAPI(ctx context.Context, cliConfig *Config, method, path string, query url.Values, header string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, apiURL(cliConfig.Host, path)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if key, value, ok := strings.Cut(header, ":"); ok {
+		req.Header.Set(key, value)
+	}
+	if req.Header.Get("Authorization") == "" && cliConfig.Auth.LoggedIn() && cliConfig.Auth.Host == cliConfig.Host {
+		token, err := loginToken(ctx, cliConfig)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+		if err != nil {
+			return nil, err
+		}
+		var apiErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("%s: %s", apiErr.Error, apiErr.ErrorDescription)
+		}
+		return nil, fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// isClientCLI reports whether the command talks to the server, the closest
+// "client" annotation wins so offline subcommands of client commands can opt out
 func isClientCLI(cmd *cobra.Command) bool {
 	for c := cmd; c.Parent() != nil; c = c.Parent() {
-		if c.Annotations != nil && c.Annotations["client"] == "true" {
-			return true
+		if client, ok := c.Annotations["client"]; ok {
+			return client == "true"
 		}
 	}
 	return false
@@ -49,6 +121,12 @@ func overrideClientConfigHost(cmd *cobra.Command, cliConfig *Config) error {
 		return ErrClientConfigNotFound
 	}
 
+	if name, err := cmd.Flags().GetString("context"); err == nil && name != "" {
+		if err := cliConfig.useContext(name); err != nil {
+			return err
+		}
+	}
+
 	host, err := cmd.Flags().GetString("host")
 	if err == nil && host != "" {
 		cliConfig.Host = host
@@ -64,4 +142,5 @@ func overrideClientConfigHost(cmd *cobra.Command, cliConfig *Config) error {
 
 func bindFlagsFromClientConfig(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringP("host", "h", "", "Shield API service to connect to")
+	cmd.PersistentFlags().String("context", "", "Context of the client config to use instead of the current one")
 }
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	cli "github.com/spf13/cobra"
+)
+
+const completionTimeout = 3 * time.Second
+
+// completeIDs completes the id argument of a command with the ids of the
+// options listed from the server, and with their slugs when they have one.
+// Nothing is completed when the server can't be reached so the shell
+// doesn't hang on it.
+func completeIDs(list selectOptionsFunc) func(cmd *cli.Command, args []string, toComplete string) ([]string, cli.ShellCompDirective) {
+	return func(cmd *cli.Command, args []string, toComplete string) ([]string, cli.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cli.ShellCompDirectiveNoFileComp
+		}
+		if err := overrideClientConfigHost(cmd, cliConfig); err != nil {
+			return nil, cli.ShellCompDirectiveNoFileComp
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+
+		client, closeClient, err := createClient(ctx, cliConfig.Host)
+		if err != nil {
+			return nil, cli.ShellCompDirectiveNoFileComp
+		}
+		defer closeClient()
+
+		options, err := list(ctx, client)
+		if err != nil {
+			return nil, cli.ShellCompDirectiveNoFileComp
+		}
+		return completions(options, toComplete), cli.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completions are the ids and slugs of the options starting with
+// toComplete, described by the label of their option
+func completions(options []selectOption, toComplete string) []string {
+	var matches []string
+	for _, o := range options {
+		for _, value := range []string{o.Slug, o.ID} {
+			if value != "" && strings.HasPrefix(value, toComplete) {
+				matches = append(matches, fmt.Sprintf("%s\t%s", value, o.Label))
+			}
+		}
+	}
+	return matches
+}
@@ -0,0 +1,33 @@
+package cmd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletion(t *testing.T) {
+	t.Run("should not complete ids or files without a host", func(t *testing.T) {
+		cli := cmd.New(&cmd.Config{})
+
+		buf := new(bytes.Buffer)
+		cli.SetOut(buf)
+		cli.SetArgs([]string{"__complete", "organization", "view", ""})
+
+		assert.NoError(t, cli.Execute())
+		assert.Contains(t, buf.String(), ":4\n")
+	})
+
+	t.Run("should not complete ids past the first argument", func(t *testing.T) {
+		cli := cmd.New(&cmd.Config{})
+
+		buf := new(bytes.Buffer)
+		cli.SetOut(buf)
+		cli.SetArgs([]string{"__complete", "project", "view", "p1", ""})
+
+		assert.NoError(t, cli.Execute())
+		assert.Contains(t, buf.String(), ":4\n")
+	})
+}
@@ -2,25 +2,109 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/odpf/salt/cmdx"
+	"github.com/odpf/salt/printer"
 	"github.com/spf13/cobra"
 )
 
 var cliConfig *Config
 
 type Config struct {
-	Host string `mapstructure:"host"`
+	Host string     `mapstructure:"host"`
+	Auth AuthConfig `yaml:"auth,omitempty" mapstructure:"auth"`
+
+	// CurrentContext is the context the client commands use unless they
+	// are given --context, Host and Auth are used when it is empty
+	CurrentContext string                   `yaml:"current_context,omitempty" mapstructure:"current_context"`
+	Contexts       map[string]ContextConfig `yaml:"contexts,omitempty" mapstructure:"contexts"`
+
+	// activeContext is the context Host and Auth were taken from
+	activeContext string
+}
+
+// ContextConfig is a shield deployment the client commands can be pointed
+// at by its name
+type ContextConfig struct {
+	Host string     `yaml:"host" mapstructure:"host"`
+	Auth AuthConfig `yaml:"auth,omitempty" mapstructure:"auth"`
+}
+
+// AuthConfig is the login of shield auth login, its access token is sent
+// with the requests of the client commands and renewed once it expires
+type AuthConfig struct {
+	// Host is the shield host the login is for, the tokens aren't sent to
+	// other hosts
+	Host         string `yaml:"host,omitempty" mapstructure:"host"`
+	Issuer       string `yaml:"issuer,omitempty" mapstructure:"issuer"`
+	ClientID     string `yaml:"client_id,omitempty" mapstructure:"client_id"`
+	SessionID    string `yaml:"session_id,omitempty" mapstructure:"session_id"`
+	AccessToken  string `yaml:"access_token,omitempty" mapstructure:"access_token"`
+	RefreshToken string `yaml:"refresh_token,omitempty" mapstructure:"refresh_token"`
+	// ExpiresAt is when the access token expires, in unix seconds
+	ExpiresAt int64 `yaml:"expires_at,omitempty" mapstructure:"expires_at"`
+}
+
+func (a AuthConfig) LoggedIn() bool {
+	return a.RefreshToken != ""
+}
+
+func (a AuthConfig) accessTokenExpired(now time.Time) bool {
+	return !now.Before(time.Unix(a.ExpiresAt, 0))
 }
 
 func LoadConfig() (*Config, error) {
 	var config Config
 
 	cfg := cmdx.SetConfig("shield")
-	err := cfg.Load(&config)
+	if err := cfg.Load(&config); err != nil {
+		return &config, err
+	}
 
-	return &config, err
+	if config.CurrentContext != "" {
+		if err := config.useContext(config.CurrentContext); err != nil {
+			return &config, err
+		}
+	}
+	return &config, nil
+}
+
+// useContext points Host and Auth at the context
+func (c *Config) useContext(name string) error {
+	contextConfig, ok := c.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q not found in client config", name)
+	}
+	c.Host = contextConfig.Host
+	c.Auth = contextConfig.Auth
+	c.activeContext = name
+	return nil
+}
+
+// readClientConfig reads the client config as it is stored, without
+// applying its current context
+func readClientConfig() (*cmdx.Config, Config, error) {
+	cfg := cmdx.SetConfig("shield")
+
+	var stored Config
+	if _, err := os.Stat(cfg.File()); err == nil {
+		if err := cfg.Load(&stored); err != nil {
+			return nil, Config{}, err
+		}
+	}
+	return cfg, stored, nil
+}
+
+func writeClientConfig(cfg *cmdx.Config, stored Config) error {
+	if err := cfg.Write(&stored); err != nil {
+		return err
+	}
+	// the logins of the contexts hold credentials
+	return os.Chmod(cfg.File(), 0600)
 }
 
 func configCommand() *cobra.Command {
@@ -29,11 +113,18 @@ func configCommand() *cobra.Command {
 		Short: "Manage client configurations",
 		Example: heredoc.Doc(`
 			$ shield config init
-			$ shield config list`),
+			$ shield config list
+			$ shield config set-context staging --host=shield.staging:8000
+			$ shield config use-context staging
+			$ shield config get-contexts`),
 	}
 
 	cmd.AddCommand(configInitCommand())
 	cmd.AddCommand(configListCommand())
+	cmd.AddCommand(configSetContextCommand())
+	cmd.AddCommand(configUseContextCommand())
+	cmd.AddCommand(configGetContextsCommand())
+	cmd.AddCommand(configDeleteContextCommand())
 
 	return cmd
 }
@@ -85,3 +176,171 @@ func configListCommand() *cobra.Command {
 	}
 	return cmd
 }
+
+func configSetContextCommand() *cobra.Command {
+	var host string
+	var use bool
+
+	cmd := &cobra.Command{
+		Use:   "set-context <name>",
+		Short: "Add or edit a context of the client configuration",
+		Long: heredoc.Doc(`
+			Add a context pointing the client commands at a shield deployment, or edit
+			the host of an existing one. The login of a context is dropped when its
+			host changes.
+		`),
+		Args: cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield config set-context staging --host=shield.staging:8000
+			$ shield config set-context prod --host=shield.prod:8000 --use
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, stored, err := readClientConfig()
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			contextConfig, exists := stored.Contexts[name]
+			if !exists && host == "" {
+				return fmt.Errorf("--host is required for the new context %q", name)
+			}
+			if host != "" && host != contextConfig.Host {
+				contextConfig = ContextConfig{Host: host}
+			}
+			if stored.Contexts == nil {
+				stored.Contexts = map[string]ContextConfig{}
+			}
+			stored.Contexts[name] = contextConfig
+			if use {
+				stored.CurrentContext = name
+			}
+
+			if err := writeClientConfig(cfg, stored); err != nil {
+				return err
+			}
+			fmt.Printf("successfully set context %s with host %s\n", name, contextConfig.Host)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&host, "host", "", "Shield API service the context connects to")
+	cmd.Flags().BoolVar(&use, "use", false, "Use the context from now on")
+
+	return cmd
+}
+
+func configUseContextCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use-context <name>",
+		Short: "Use a context of the client configuration",
+		Long: heredoc.Doc(`
+			Use a context for the client commands from now on. A single command can use
+			another context with the --context flag.
+		`),
+		Args: cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield config use-context prod
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, stored, err := readClientConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := stored.Contexts[args[0]]; !ok {
+				return fmt.Errorf("context %q not found in client config", args[0])
+			}
+
+			stored.CurrentContext = args[0]
+			if err := writeClientConfig(cfg, stored); err != nil {
+				return err
+			}
+			fmt.Printf("switched to context %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func configGetContextsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List the contexts of the client configuration",
+		Args:  cobra.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield config get-contexts
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, stored, err := readClientConfig()
+			if err != nil {
+				return err
+			}
+
+			names := make([]string, 0, len(stored.Contexts))
+			for name := range stored.Contexts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			report := [][]string{}
+			report = append(report, []string{"CURRENT", "NAME", "HOST", "LOGGED IN"})
+			for _, name := range names {
+				current := ""
+				if name == stored.CurrentContext {
+					current = "*"
+				}
+				contextConfig := stored.Contexts[name]
+				report = append(report, []string{current, name, contextConfig.Host, fmt.Sprint(contextConfig.Auth.LoggedIn())})
+			}
+
+			fmt.Printf(" \nShowing %d contexts\n \n", len(names))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+}
+
+func configDeleteContextCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete-context <name>",
+		Short: "Delete a context of the client configuration",
+		Long: heredoc.Doc(`
+			Delete a context, its login is forgotten without revoking its session.
+			Run "shield auth logout --context=<name>" first to revoke it.
+		`),
+		Args: cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield config delete-context staging
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, stored, err := readClientConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := stored.Contexts[args[0]]; !ok {
+				return fmt.Errorf("context %q not found in client config", args[0])
+			}
+
+			delete(stored.Contexts, args[0])
+			if stored.CurrentContext == args[0] {
+				stored.CurrentContext = ""
+			}
+			if err := writeClientConfig(cfg, stored); err != nil {
+				return err
+			}
+			fmt.Printf("successfully deleted context %s\n", args[0])
+			return nil
+		},
+	}
+}
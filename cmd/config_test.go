@@ -0,0 +1,44 @@
+package cmd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientConfigContexts(t *testing.T) {
+	t.Setenv("ODPF_CONFIG_DIR", t.TempDir())
+
+	run := func(args ...string) error {
+		cli := cmd.New(&cmd.Config{})
+		cli.SetOutput(new(bytes.Buffer))
+		cli.SetArgs(args)
+		return cli.Execute()
+	}
+
+	require.NoError(t, run("config", "set-context", "staging", "--host", "shield.staging:8000"))
+	require.NoError(t, run("config", "set-context", "prod", "--host", "shield.prod:8000", "--use"))
+
+	cfg, err := cmd.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", cfg.CurrentContext)
+	assert.Equal(t, "shield.prod:8000", cfg.Host)
+
+	require.NoError(t, run("config", "use-context", "staging"))
+	cfg, err = cmd.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "shield.staging:8000", cfg.Host)
+
+	assert.EqualError(t, run("config", "use-context", "dev"), `context "dev" not found in client config`)
+	assert.EqualError(t, run("config", "set-context", "dev"), `--host is required for the new context "dev"`)
+
+	require.NoError(t, run("config", "delete-context", "staging"))
+	cfg, err = cmd.LoadConfig()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.CurrentContext)
+	assert.Empty(t, cfg.Host)
+	assert.Len(t, cfg.Contexts, 1)
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"strings"
 
+	"github.com/spf13/cobra"
 	"google.golang.org/grpc/metadata"
 )
 
+// setCtxHeader sets the header of the --header flag, the flag can be
+// left out once logged in with shield auth login
 func setCtxHeader(ctx context.Context, header string) context.Context {
+	if header == "" {
+		return ctx
+	}
 	s := strings.Split(header, ":")
 	key := s[0]
 	val := s[1]
@@ -17,3 +23,12 @@ func setCtxHeader(ctx context.Context, header string) context.Context {
 
 	return ctx
 }
+
+// bindHeaderFlag binds the --header flag, it is required unless logged in
+// with shield auth login as the token of the login is sent instead
+func bindHeaderFlag(cmd *cobra.Command, header *string, cliConfig *Config) {
+	cmd.Flags().StringVarP(header, "header", "H", "", "Header <key>:<value>")
+	if cliConfig == nil || !cliConfig.Auth.LoggedIn() {
+		cmd.MarkFlagRequired("header")
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	cli "github.com/spf13/cobra"
+)
+
+// the v1beta1 api has no delete rpcs, the resources are deleted through the
+// delete paths the gateway serves next to them
+var deletePaths = map[string]string{
+	"organization": "/admin/v1beta1/organizations/delete",
+	"project":      "/admin/v1beta1/projects/delete",
+	"group":        "/admin/v1beta1/groups/delete",
+	"role":         "/admin/v1beta1/roles/delete",
+	"action":       "/admin/v1beta1/actions/delete",
+	"namespace":    "/admin/v1beta1/namespaces/delete",
+	"policy":       "/admin/v1beta1/policies/delete",
+}
+
+// deleteCommand deletes the resource of the id after a confirmation prompt,
+// --force deletes it without one
+func deleteCommand(cliConfig *Config, resource string, options selectOptionsFunc) *cli.Command {
+	var header string
+	var force bool
+
+	article := "a"
+	if strings.ContainsAny(resource[:1], "aeiou") {
+		article = "an"
+	}
+
+	cmd := &cli.Command{
+		Use:   "delete <id>",
+		Short: fmt.Sprintf("Delete %s %s", article, resource),
+		Long: heredoc.Docf(`
+			Delete %[2]s %[1]s, the relations of the %[1]s are removed from the authz engine
+			along with it. The %[1]s can't be deleted while other resources refer to it.
+
+			The deletion is confirmed with a prompt, --force deletes without one.
+		`, resource, article),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Docf(`
+			$ shield %[1]s delete <%[1]s-id> --header=<key>:<value>
+			$ shield %[1]s delete <%[1]s-id> --force --header=<key>:<value>
+		`, resource),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(options),
+		RunE: func(cmd *cli.Command, args []string) error {
+			if !force {
+				if !isInteractive(cmd) {
+					return fmt.Errorf("%w: pass --force to delete without a prompt", errNotConfirmed)
+				}
+				if err := confirm(cmd, fmt.Sprintf("Delete %s %s?", resource, args[0])); err != nil {
+					return err
+				}
+			}
+
+			var res struct{}
+			if err := deleteAdminAPI(cmd.Context(), cliConfig, deletePaths[resource], url.Values{"id": {args[0]}}, header, &res); err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully deleted %s %s\n", resource, args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Delete without a confirmation prompt")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/internal/api/v1beta1"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+var errUnresolvedReference = errors.New("could not resolve reference")
+
+// dryRunReference is a value of the request referring to a resource,
+// ID is filled in once the reference is resolved against the server
+type dryRunReference struct {
+	Resource string
+	Field    string
+	Value    string
+	ID       string
+}
+
+func bindDryRunFlag(cmd *cli.Command, dryRun *bool) {
+	cmd.Flags().BoolVar(dryRun, "dry-run", false, "Validate the request and resolve its references without storing anything")
+}
+
+// printDryRun prints the request a command would have sent along with the
+// references it was checked against and, when the server validated the
+// request, what the server would have stored. Nothing is changed on the
+// server.
+func printDryRun(w io.Writer, method string, req, validated proto.Message, refs ...dryRunReference) error {
+	if validated == nil {
+		fmt.Fprintf(w, "dry run, %s was not called with the request\n", method)
+	} else {
+		fmt.Fprintf(w, "dry run, the server validated the request of %s without storing it\n", method)
+	}
+	if err := printJSON(w, req); err != nil {
+		return err
+	}
+	if validated != nil {
+		fmt.Fprintln(w, " \nvalidated result\n ")
+		if err := printJSON(w, validated); err != nil {
+			return err
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, " \nresolved references\n ")
+	report := [][]string{}
+	report = append(report, []string{"RESOURCE", "FIELD", "VALUE", "ID"})
+	for _, r := range refs {
+		report = append(report, []string{r.Resource, r.Field, r.Value, r.ID})
+	}
+	printer.Table(w, report)
+	return nil
+}
+
+// validateOnServer sends the request with the validate only header when the
+// server validates it, the server then runs the checks of the rpc and returns
+// what it would store without storing it. It returns nil without sending the
+// request when the server can't validate it, like a server older than the
+// header, as the server would store it.
+func validateOnServer(ctx context.Context, cmd *cli.Command, cliConfig *Config, req proto.Message, send func(ctx context.Context, opts ...grpc.CallOption) (proto.Message, error)) (proto.Message, error) {
+	name := string(req.ProtoReflect().Descriptor().FullName())
+	validates, err := serverValidates(ctx, cliConfig, name)
+	if err != nil {
+		verbosef(cmd, "couldn't get the requests the server validates, validating on the client: %s", err)
+	}
+	if !validates {
+		verbosef(cmd, "the server doesn't validate %s, it is only validated on the client", name)
+		return nil, nil
+	}
+
+	var header metadata.MD
+	res, err := send(metadata.AppendToOutgoingContext(ctx, v1beta1.ValidateOnlyHeader, "true"), grpc.Header(&header))
+	if err != nil {
+		return nil, err
+	}
+	if len(header.Get(v1beta1.ValidatedHeader)) == 0 {
+		return nil, fmt.Errorf("the server stored the request of %s instead of validating it", name)
+	}
+	return res, nil
+}
+
+// serverValidates tells whether the server validates the request with the
+// full name without storing it
+func serverValidates(ctx context.Context, cliConfig *Config, request string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, capabilitiesTimeout)
+	defer cancel()
+
+	var capabilities struct {
+		ValidateOnly []string `json:"validate_only"`
+	}
+	if err := getAdminAPI(ctx, cliConfig, capabilitiesPath, url.Values{}, "", &capabilities); err != nil {
+		return false, err
+	}
+	for _, r := range capabilities.ValidateOnly {
+		if r == request {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolveReferences looks every referenced resource up, it fails on the
+// first reference the server doesn't know
+func resolveReferences(ctx context.Context, client shieldv1beta1.ShieldServiceClient, refs ...dryRunReference) ([]dryRunReference, error) {
+	resolved := make([]dryRunReference, 0, len(refs))
+	for _, r := range refs {
+		id, err := lookupID(ctx, client, r.Resource, r.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %s: %s", errUnresolvedReference, r.Field, r.Value, err)
+		}
+		r.ID = id
+		resolved = append(resolved, r)
+	}
+	return resolved, nil
+}
+
+func lookupID(ctx context.Context, client shieldv1beta1.ShieldServiceClient, resource, ref string) (string, error) {
+	switch resource {
+	case "organization":
+		res, err := client.GetOrganization(ctx, &shieldv1beta1.GetOrganizationRequest{Id: ref})
+		return res.GetOrganization().GetId(), err
+	case "project":
+		res, err := client.GetProject(ctx, &shieldv1beta1.GetProjectRequest{Id: ref})
+		return res.GetProject().GetId(), err
+	case "group":
+		res, err := client.GetGroup(ctx, &shieldv1beta1.GetGroupRequest{Id: ref})
+		return res.GetGroup().GetId(), err
+	case "role":
+		res, err := client.GetRole(ctx, &shieldv1beta1.GetRoleRequest{Id: ref})
+		return res.GetRole().GetId(), err
+	case "action":
+		res, err := client.GetAction(ctx, &shieldv1beta1.GetActionRequest{Id: ref})
+		return res.GetAction().GetId(), err
+	case "namespace":
+		res, err := client.GetNamespace(ctx, &shieldv1beta1.GetNamespaceRequest{Id: ref})
+		return res.GetNamespace().GetId(), err
+	case "policy":
+		res, err := client.GetPolicy(ctx, &shieldv1beta1.GetPolicyRequest{Id: ref})
+		return res.GetPolicy().GetId(), err
+	case "user":
+		return lookupUserID(ctx, client, ref)
+	default:
+		return "", fmt.Errorf("unknown resource %s", resource)
+	}
+}
+
+// lookupUserID looks a user up by id or, when the reference is an
+// email, by searching the users for that exact email
+func lookupUserID(ctx context.Context, client shieldv1beta1.ShieldServiceClient, ref string) (string, error) {
+	if !strings.Contains(ref, "@") {
+		res, err := client.GetUser(ctx, &shieldv1beta1.GetUserRequest{Id: ref})
+		return res.GetUser().GetId(), err
+	}
+
+	res, err := client.ListUsers(ctx, &shieldv1beta1.ListUsersRequest{Keyword: ref})
+	if err != nil {
+		return "", err
+	}
+	for _, u := range res.GetUsers() {
+		if strings.EqualFold(u.GetEmail(), ref) {
+			return u.GetId(), nil
+		}
+	}
+	return "", errors.New("user doesn't exist")
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/odpf/shield/internal/api/v1beta1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// maxEditAttempts is how many times an edit is applied to the entity it
+// edits, the entity is fetched again whenever it was updated in between
+const maxEditAttempts = 3
+
+// etagOf is the version of the entity in the header of a response, empty
+// when the server does not return any
+func etagOf(header metadata.MD) string {
+	if values := header.Get(v1beta1.ETagHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// updateWithRetry updates an entity on the condition it is still at the
+// version of etag. When it was updated since, refetch fetches it and
+// applies the edit again then returns its new etag, the update is retried
+// with it.
+func updateWithRetry(ctx context.Context, etag string, update func(ctx context.Context) error, refetch func(ctx context.Context) (string, error)) error {
+	for attempt := 1; ; attempt++ {
+		updateCtx := ctx
+		if etag != "" {
+			updateCtx = metadata.AppendToOutgoingContext(ctx, v1beta1.IfMatchHeader, etag)
+		}
+
+		err := update(updateCtx)
+		if status.Code(err) != codes.FailedPrecondition || attempt == maxEditAttempts {
+			return err
+		}
+
+		if etag, err = refetch(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// reapplyEdit returns fresh with the fields edited changed from original,
+// metadata key by key, so an edit made to an entity updated since keeps
+// the changes it did not touch. The request bodies only have string and
+// metadata fields.
+func reapplyEdit[T proto.Message](original, edited, fresh T) T {
+	merged := proto.Clone(fresh).(T)
+	o, e, m := original.ProtoReflect(), edited.ProtoReflect(), merged.ProtoReflect()
+
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Kind() == protoreflect.MessageKind {
+			m.Set(fd, protoreflect.ValueOfMessage(reapplyMetadata(
+				o.Get(fd).Message().Interface().(*structpb.Struct),
+				e.Get(fd).Message().Interface().(*structpb.Struct),
+				m.Get(fd).Message().Interface().(*structpb.Struct),
+			).ProtoReflect()))
+			continue
+		}
+		if o.Get(fd).Interface() != e.Get(fd).Interface() {
+			m.Set(fd, e.Get(fd))
+		}
+	}
+	return merged
+}
+
+func reapplyMetadata(original, edited, fresh *structpb.Struct) *structpb.Struct {
+	merged := &structpb.Struct{Fields: map[string]*structpb.Value{}}
+	for k, v := range fresh.GetFields() {
+		merged.Fields[k] = v
+	}
+	for k, v := range edited.GetFields() {
+		if ov, ok := original.GetFields()[k]; !ok || !proto.Equal(ov, v) {
+			merged.Fields[k] = v
+		}
+	}
+	for k := range original.GetFields() {
+		if _, ok := edited.GetFields()[k]; !ok {
+			delete(merged.Fields, k)
+		}
+	}
+	return merged
+}
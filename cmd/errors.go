@@ -25,6 +25,8 @@ var (
 	ErrClientNotAuthorized = errors.New(heredoc.Doc(`
 		Shield auth error. Shield requires an auth header.
 		
-		Run "shield help auth" for more information.
+		Run "shield auth login" to log in or
+		"shield auth --help" for more information.
 	`))
+	ErrValidationFailed = errors.New("request body failed validation")
 )
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	cli "github.com/spf13/cobra"
+)
+
+// eventsWatchPath streams the events of the changes as server-sent events,
+// it has no rpc of its own
+const eventsWatchPath = "/admin/v1beta1/events/watch"
+
+// errWatchReset is returned when the server dropped the watch for falling
+// behind, the changes since the last event printed have to be listed again
+var errWatchReset = errors.New("watch fell behind the events and was reset")
+
+func EventCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:     "event",
+		Aliases: []string{"events"},
+		Short:   "Watch the events of changes",
+		Long: heredoc.Doc(`
+			Work with the events of the changes made to organizations, projects, groups,
+			policies and the other audited resources.
+
+			Events are named after the resource and what happened to it, e.g.
+			organization.created or policy.updated, the way webhooks are subscribed to
+			them.
+		`),
+		Example: heredoc.Doc(`
+			$ shield event watch --event=organization.* --event=policy.*
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+	}
+
+	cmd.AddCommand(watchEventCommand(cliConfig))
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func watchEventCommand(cliConfig *Config) *cli.Command {
+	var header string
+	var events []string
+
+	cmd := &cli.Command{
+		Use:   "watch",
+		Short: "Print the events of the changes as they are made",
+		Long: heredoc.Doc(`
+			Print the events of the changes made from now on as json, one per line. Events
+			are filtered by type, by resource as in organization.* or every event is
+			printed with * which is the default.
+
+			The server streams the changes recorded by the instance the watch is
+			connected to. A watch falling behind is reset by the server, the command then
+			fails and the changes since the last event printed have to be listed again.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield event watch --header=<key>:<value>
+			$ shield event watch --event=organization.created --event=policy.*
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			resp, err := doAdminAPI(cmd.Context(), cliConfig, http.MethodGet, eventsWatchPath, url.Values{"event": events}, header)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			var eventType string
+			scanner := bufio.NewScanner(resp.Body)
+			scanner.Buffer(make([]byte, 64*1024), 16<<20)
+			for scanner.Scan() {
+				line := scanner.Text()
+				switch {
+				case line == "":
+					eventType = ""
+				case strings.HasPrefix(line, "event:"):
+					eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+					if eventType == "reset" {
+						return errWatchReset
+					}
+				case strings.HasPrefix(line, "data:") && eventType != "":
+					fmt.Fprintln(cmd.OutOrStdout(), strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+				}
+			}
+			if err := scanner.Err(); err != nil && cmd.Context().Err() == nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringArrayVarP(&events, "event", "e", nil, "Event type, all the events of a resource as in organization.* or * for every event, repeatable (default *)")
+
+	return cmd
+}
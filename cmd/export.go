@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/internal/reconcile"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	cli "github.com/spf13/cobra"
+)
+
+const adminRole = "admin"
+
+var errUnsupportedExportType = errors.New("unsupported export file type, use .csv or .json")
+
+func ExportCommand(cliConfig *Config) *cli.Command {
+	var org, dir, header string
+
+	cmd := &cli.Command{
+		Use:   "export",
+		Short: "Export an organization to resource files",
+		Long: heredoc.Doc(`
+			Write an organization with its projects, groups, group memberships, custom roles
+			and their policies, resources and role grants to yaml files of a directory, in
+			the format read by shield apply.
+
+			Organizations, projects and groups are referred to by slug and users by email,
+			so the files can be applied to another server to clone the organization, like
+			from staging to production, or kept as a backup. The users have to exist on the
+			server the files are applied to.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield export --org=<organization-id-or-slug> --output=backup/ --header=<key>:<value>
+			$ shield export --org odpf -o odpf/ -H <key>:<value> && shield apply -f odpf/ -h prod.shield.example.com -H <key>:<value>
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			ctx := setCtxHeader(cmd.Context(), header)
+			resources, err := reconcile.Export(ctx, client, org)
+			if err != nil {
+				return err
+			}
+
+			files, err := reconcile.Write(dir, resources)
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			counts := map[string]int{}
+			for _, r := range resources {
+				counts[r.Kind]++
+			}
+			report := [][]string{}
+			report = append(report, []string{"KIND", "COUNT"})
+			for _, kind := range []string{reconcile.KindOrganization, reconcile.KindProject, reconcile.KindGroup, reconcile.KindRole,
+				reconcile.KindPolicy, reconcile.KindResource, reconcile.KindRelation} {
+				report = append(report, []string{kind, strconv.Itoa(counts[kind])})
+			}
+			printer.Table(os.Stdout, report)
+			fmt.Printf(" \nsuccessfully exported %d resources to %d files in %s\n", len(resources), len(files), dir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&org, "org", "", "Id or slug of the organization to export")
+	cmd.MarkFlagRequired("org")
+	cmd.Flags().StringVarP(&dir, "output", "o", "", "Path to the directory the resource files are written to")
+	cmd.MarkFlagRequired("output")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+type adminExportRecord struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// writeAdminExport writes the admins to outFile, the format
+// is decided by the file extension
+func writeAdminExport(outFile string, admins []*shieldv1beta1.User) error {
+	records := make([]adminExportRecord, 0, len(admins))
+	for _, a := range admins {
+		records = append(records, adminExportRecord{
+			ID:    a.GetId(),
+			Name:  a.GetName(),
+			Email: a.GetEmail(),
+			Role:  adminRole,
+		})
+	}
+
+	ext := filepath.Ext(outFile)
+	if ext != ".csv" && ext != ".json" {
+		return errUnsupportedExportType
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if ext == ".json" {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{"id", "name", "email", "role"}); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := w.Write([]string{r.ID, r.Name, r.Email, r.Role}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/odpf/shield/internal/api/v1beta1"
+	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const metadataFilterPrefix = "metadata."
+
+const (
+	capabilitiesPath = "/admin/v1beta1/capabilities"
+	// capabilitiesTimeout bounds the wait for the capabilities of the server,
+	// the filters are applied on the client once it runs out
+	capabilitiesTimeout = 5 * time.Second
+)
+
+// listFilter narrows down the result of a list command, it is pushed down to
+// the server when the server applies it on the list request, otherwise it is
+// applied on the client with match. The glob filters the request has no
+// field for are pushed down in the filter header.
+type listFilter struct {
+	name  string
+	value string
+	glob  bool
+	match func(item proto.Message, value string) bool
+}
+
+func bindVerboseFlag(cmd *cli.Command) {
+	cmd.PersistentFlags().Bool("verbose", false, "Print additional details about what the command does")
+}
+
+// verbosef writes to stderr when the verbose flag is set so
+// the notes never end up in the output of the command
+func verbosef(cmd *cli.Command, format string, a ...interface{}) {
+	if verbose, err := cmd.Flags().GetBool("verbose"); err != nil || !verbose {
+		return
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), format+"\n", a...)
+}
+
+// pushDownFilters sets every non empty filter the server applies on the list
+// request on the request itself, or on the filter header of the returned
+// context for the globs, and returns the ones that have to be applied on the
+// client. The server is asked which filters it applies, when it can't tell,
+// like a server older than the capabilities, every filter is applied on the
+// client.
+func pushDownFilters(ctx context.Context, cmd *cli.Command, cliConfig *Config, req proto.Message, filters []listFilter) (context.Context, []listFilter) {
+	var set []listFilter
+	for _, f := range filters {
+		if f.value != "" {
+			set = append(set, f)
+		}
+	}
+	if len(set) == 0 {
+		return ctx, nil
+	}
+
+	msg := req.ProtoReflect()
+	supported, err := serverListFilters(ctx, cliConfig, string(msg.Descriptor().FullName()))
+	if err != nil {
+		verbosef(cmd, "couldn't get the filters the server supports, filtering on the client: %s", err)
+	}
+
+	fields := msg.Descriptor().Fields()
+	// the server takes a single glob of a field, the others of the same
+	// field are applied on the client
+	pushed := map[string]bool{}
+	var clientFilters []listFilter
+	for _, f := range set {
+		fd := fields.ByName(protoreflect.Name(f.name))
+		switch {
+		case !supportsFilter(supported, f.name):
+			verbosef(cmd, "filter %q is not supported by the server, filtering on the client", f.name)
+		case fd != nil && fd.Kind() == protoreflect.StringKind && !fd.IsList():
+			msg.Set(fd, protoreflect.ValueOfString(f.value))
+			continue
+		case fd == nil && f.glob && !pushed[f.name]:
+			ctx = metadata.AppendToOutgoingContext(ctx, v1beta1.ListFilterHeader, f.name+"="+f.value)
+			pushed[f.name] = true
+			continue
+		default:
+			verbosef(cmd, "filter %q can't be pushed down to the server, filtering on the client", f.name)
+		}
+		clientFilters = append(clientFilters, f)
+	}
+	return ctx, clientFilters
+}
+
+// supportsFilter reports whether the filter is one of the supported ones,
+// metadata.* stands for any metadata key
+func supportsFilter(supported []string, name string) bool {
+	if strings.HasPrefix(name, metadataFilterPrefix) {
+		return containsString(supported, metadataFilterPrefix+"*")
+	}
+	return containsString(supported, name)
+}
+
+// serverListFilters returns the filters the server applies on the list
+// request with the full name
+func serverListFilters(ctx context.Context, cliConfig *Config, request string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, capabilitiesTimeout)
+	defer cancel()
+
+	var capabilities struct {
+		ListFilters map[string][]string `json:"list_filters"`
+	}
+	if err := getAdminAPI(ctx, cliConfig, capabilitiesPath, url.Values{}, "", &capabilities); err != nil {
+		return nil, err
+	}
+	return capabilities.ListFilters[request], nil
+}
+
+// filterItems keeps the items matching all the client side filters
+func filterItems[T proto.Message](items []T, filters []listFilter) []T {
+	if len(filters) == 0 {
+		return items
+	}
+
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		matched := true
+		for _, f := range filters {
+			if !f.match(item, f.value) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// containsFold reports whether substr is within s, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func bindFieldFilterFlag(cmd *cli.Command, filters *[]string, fields ...string) {
+	usage := fmt.Sprintf("Only list items with a field matching a glob, as field=glob where field is one of %s or metadata.<key>", strings.Join(fields, ", "))
+	cmd.Flags().StringArrayVar(filters, "filter", nil, usage)
+}
+
+// fieldFilters parses the field=glob values of the filter flag, the glob
+// matches the whole value ignoring case and * matches any run of characters
+func fieldFilters(values []string, fields ...string) ([]listFilter, error) {
+	var filters []listFilter
+	for _, v := range values {
+		field, glob, ok := strings.Cut(v, "=")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid filter '%s', expected field=glob", v)
+		}
+		if !containsString(fields, field) && (!strings.HasPrefix(field, metadataFilterPrefix) || field == metadataFilterPrefix) {
+			return nil, fmt.Errorf("unknown filter field '%s'; valid: %s, metadata.<key>", field, strings.Join(fields, ", "))
+		}
+
+		filters = append(filters, listFilter{name: field, value: glob, glob: true, match: func(item proto.Message, value string) bool {
+			s, ok := fieldValue(item, field)
+			return ok && matchGlob(value, s)
+		}})
+	}
+	return filters, nil
+}
+
+// fieldValue reads a string field of the item, or a key of its
+// metadata for the fields prefixed with metadata.
+func fieldValue(item proto.Message, field string) (string, bool) {
+	msg := item.ProtoReflect()
+	fields := msg.Descriptor().Fields()
+
+	if strings.HasPrefix(field, metadataFilterPrefix) {
+		key := strings.TrimPrefix(field, metadataFilterPrefix)
+		fd := fields.ByName("metadata")
+		if fd == nil || fd.Kind() != protoreflect.MessageKind {
+			return "", false
+		}
+		meta, ok := msg.Get(fd).Message().Interface().(*structpb.Struct)
+		if !ok {
+			return "", false
+		}
+		v, ok := meta.AsMap()[key]
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprint(v), true
+	}
+
+	fd := fields.ByName(protoreflect.Name(field))
+	if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+		return "", false
+	}
+	return msg.Get(fd).String(), true
+}
+
+// matchGlob reports whether the whole of s matches the glob ignoring case
+func matchGlob(glob, s string) bool {
+	parts := strings.Split(glob, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("(?is)^" + strings.Join(parts, ".*") + "$").MatchString(s)
+}
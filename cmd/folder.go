@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/folder"
+	cli "github.com/spf13/cobra"
+)
+
+// the folders are created and the resources moved into them with endpoints
+// the gateway serves next to the rpcs, there are no rpcs for them
+const (
+	foldersPath     = "/admin/v1beta1/folders"
+	foldersMovePath = "/admin/v1beta1/folders/move"
+)
+
+type folderResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ProjectID string `json:"project_id"`
+}
+
+func FolderCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:     "folder",
+		Aliases: []string{"folders"},
+		Short:   "Manage folders of projects",
+		Long: heredoc.Doc(`
+			Work with folders.
+
+			Folders group the resources of a project, the roles granted on a folder
+			apply to every resource in it. Folders inherit the permissions of their
+			project.
+
+			Folders are created and resources moved into them through the server as the
+			logged in user or the user of the header. They are listed with the server
+			config.
+		`),
+		Example: heredoc.Doc(`
+			$ shield folder create --project=data --name=pipelines
+			$ shield folder list --project=data
+			$ shield folder move <resource-id> --folder=<folder-id>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(createFolderCommand(cliConfig))
+	cmd.AddCommand(listFolderCommand())
+	cmd.AddCommand(moveFolderCommand(cliConfig))
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func createFolderCommand(cliConfig *Config) *cli.Command {
+	var projectID, name, header string
+
+	cmd := &cli.Command{
+		Use:   "create",
+		Short: "Create a folder in a project",
+		Long: heredoc.Doc(`
+			Create a folder in a project as the logged in user or the user of the header,
+			who has to be an editor of the project.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield folder create --project=data --name=pipelines
+			$ shield folder create --project=data --name=pipelines --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var created folderResponse
+			if err := postAdminAPI(cmd.Context(), cliConfig, foldersPath, url.Values{"project": {projectID}, "name": {name}}, header, &created); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("successfully created folder %s with id %s\n", created.Name, created.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "ID or slug of the project")
+	cmd.MarkFlagRequired("project")
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the folder")
+	cmd.MarkFlagRequired("name")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func listFolderCommand() *cli.Command {
+	var configFile, projectID, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List folders",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield folder list
+			$ shield folder list --project=data
+			$ shield folder list --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			deps, cleanup, err := serverDeps(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			folders, err := deps.FolderService.List(cmd.Context(), folder.Filter{ProjectID: projectID})
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAME", "PROJECT ID"})
+			for _, f := range folders {
+				report = append(report, []string{f.ID, f.Name, f.ProjectID})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d folders\n \n", len(folders))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Only list the folders of the project")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func moveFolderCommand(cliConfig *Config) *cli.Command {
+	var folderID, header string
+	var root bool
+
+	cmd := &cli.Command{
+		Use:   "move <resource-id>",
+		Short: "Move a resource into a folder",
+		Long: heredoc.Doc(`
+			Move a resource into a folder of its project, or back to the root of the
+			project with --root, as the logged in user or the user of the header. The
+			user has to be an editor of the resource and of the folder it is moved into.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield folder move <resource-id> --folder=<folder-id>
+			$ shield folder move <resource-id> --root --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			if (folderID == "") == !root {
+				return errors.New("either --folder or --root has to be set")
+			}
+
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var moved struct {
+				ID        string `json:"id"`
+				ProjectID string `json:"project_id"`
+				FolderID  string `json:"folder_id"`
+			}
+			if err := postAdminAPI(cmd.Context(), cliConfig, foldersMovePath, url.Values{"resource": {args[0]}, "folder": {folderID}}, header, &moved); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			if moved.FolderID == "" {
+				fmt.Printf("successfully moved resource %s to the root of project %s\n", moved.ID, moved.ProjectID)
+				return nil
+			}
+			fmt.Printf("successfully moved resource %s into folder %s\n", moved.ID, moved.FolderID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&folderID, "folder", "f", "", "ID of the folder the resource is moved into")
+	cmd.Flags().BoolVar(&root, "root", false, "Move the resource out of its folder")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
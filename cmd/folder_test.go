@@ -0,0 +1,65 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFolder(t *testing.T) {
+	tests := []struct {
+		name        string
+		subCommands []string
+		want        string
+		err         error
+	}{
+		{
+			name:        "`folder` create only should throw error host not found",
+			want:        "",
+			subCommands: []string{"create", "--project", "data", "--name", "pipelines"},
+			err:         cmd.ErrClientConfigHostNotFound,
+		},
+		{
+			name:        "`folder` create with host flag should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"create", "-h", "test", "--project", "data", "--name", "pipelines"},
+			err:         errors.New("required flag(s) \"header\" not set"),
+		},
+		{
+			name:        "`folder` list with unsupported output format should throw error",
+			want:        "",
+			subCommands: []string{"list", "-o", "tsv"},
+			err:         errors.New("output format 'tsv' is not supported by shield folder list; valid: table, json, yaml"),
+		},
+		{
+			name:        "`folder` move without folder or root should throw error",
+			want:        "",
+			subCommands: []string{"move", "r1", "-h", "test", "--header", "X-Shield-Email:admin@odpf.io"},
+			err:         errors.New("either --folder or --root has to be set"),
+		},
+		{
+			name:        "`folder` move with both folder and root should throw error",
+			want:        "",
+			subCommands: []string{"move", "r1", "-h", "test", "--header", "X-Shield-Email:admin@odpf.io", "--folder", "f1", "--root"},
+			err:         errors.New("either --folder or --root has to be set"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+
+			buf := new(bytes.Buffer)
+			cli.SetOutput(buf)
+			cli.SetArgs(append([]string{"folder"}, tt.subCommands...))
+
+			err := cli.Execute()
+			got := buf.String()
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
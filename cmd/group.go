@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
 
 	"github.com/MakeNowJust/heredoc"
@@ -9,6 +11,9 @@ import (
 	"github.com/odpf/shield/pkg/file"
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
 	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 )
 
 func GroupCommand(cliConfig *Config) *cli.Command {
@@ -24,6 +29,16 @@ func GroupCommand(cliConfig *Config) *cli.Command {
 			$ shield group edit
 			$ shield group view
 			$ shield group list
+			$ shield group memberadd
+			$ shield group memberremove
+			$ shield group memberlist
+			$ shield group subgroupadd
+			$ shield group subgroupremove
+			$ shield group subgrouplist
+			$ shield group owneradd
+			$ shield group ownerremove
+			$ shield group ownerlist
+			$ shield group import
 		`),
 		Annotations: map[string]string{
 			"group":  "core",
@@ -35,6 +50,18 @@ func GroupCommand(cliConfig *Config) *cli.Command {
 	cmd.AddCommand(editGroupCommand(cliConfig))
 	cmd.AddCommand(viewGroupCommand(cliConfig))
 	cmd.AddCommand(listGroupCommand(cliConfig))
+	cmd.AddCommand(deleteCommand(cliConfig, "group", groupOptions))
+	cmd.AddCommand(memberaddGroupCommand(cliConfig))
+	cmd.AddCommand(memberremoveGroupCommand(cliConfig))
+	cmd.AddCommand(memberlistGroupCommand(cliConfig))
+	cmd.AddCommand(subgroupaddGroupCommand(cliConfig))
+	cmd.AddCommand(subgroupremoveGroupCommand(cliConfig))
+	cmd.AddCommand(subgrouplistGroupCommand(cliConfig))
+	cmd.AddCommand(owneraddGroupCommand(cliConfig))
+	cmd.AddCommand(ownerremoveGroupCommand(cliConfig))
+	cmd.AddCommand(ownerlistGroupCommand(cliConfig))
+	cmd.AddCommand(importGroupCommand(cliConfig))
+	cmd.AddCommand(templateCommand("group", &shieldv1beta1.GroupRequestBody{}))
 
 	bindFlagsFromClientConfig(cmd)
 
@@ -43,6 +70,7 @@ func GroupCommand(cliConfig *Config) *cli.Command {
 
 func createGroupCommand(cliConfig *Config) *cli.Command {
 	var filePath, header string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "create",
@@ -50,6 +78,8 @@ func createGroupCommand(cliConfig *Config) *cli.Command {
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield group create --file=<group-body> --header=<key>:<value>
+			$ shield group create --file=<group-body> --header=<key>:<value> --dry-run
+			$ shield group create --header=<key>:<value>
 		`),
 		Annotations: map[string]string{
 			"group": "core",
@@ -59,13 +89,16 @@ func createGroupCommand(cliConfig *Config) *cli.Command {
 			defer spinner.Stop()
 
 			var reqBody shieldv1beta1.GroupRequestBody
-			if err := file.Parse(filePath, &reqBody); err != nil {
-				return err
-			}
+			if filePath != "" {
+				if err := file.Parse(filePath, &reqBody); err != nil {
+					return err
+				}
 
-			err := reqBody.ValidateAll()
-			if err != nil {
-				return err
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+			} else if !isInteractive(cmd) {
+				return errFileRequired
 			}
 
 			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
@@ -74,9 +107,45 @@ func createGroupCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			res, err := client.CreateGroup(setCtxHeader(cmd.Context(), header), &shieldv1beta1.CreateGroupRequest{
+			if filePath == "" {
+				orgID, err := resolveID(cmd, client, spinner, nil, "organization", organizationOptions)
+				if err != nil {
+					return err
+				}
+				reqBody.OrgId = orgID
+
+				spinner.Stop()
+				if err := promptBody(&reqBody.Metadata,
+					promptField{Message: "Name", Value: &reqBody.Name, Required: true},
+					promptField{Message: "Slug", Value: &reqBody.Slug, Required: true},
+				); err != nil {
+					return err
+				}
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+				if !dryRun {
+					if err := confirmBody(cmd, &reqBody); err != nil {
+						return err
+					}
+				}
+			}
+
+			req := &shieldv1beta1.CreateGroupRequest{
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "organization", Field: "org_id", Value: reqBody.GetOrgId()},
+				)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "CreateGroup", req, nil, refs...)
+			}
+
+			res, err := client.CreateGroup(setCtxHeader(cmd.Context(), header), req)
 			if err != nil {
 				return err
 			}
@@ -87,51 +156,141 @@ func createGroupCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the group body file")
-	cmd.MarkFlagRequired("file")
-	cmd.Flags().StringVarP(&header, "header", "H", "", "Header <key>:<value>")
-	cmd.MarkFlagRequired("header")
+	bindFileFlag(cmd, &filePath, "Path to the group body file, prompted for when omitted")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func editGroupCommand(cliConfig *Config) *cli.Command {
 	var filePath string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "edit",
 		Short: "Edit a group",
-		Args:  cli.ExactArgs(1),
+		Long: heredoc.Doc(`
+			Edit a group.
+
+			The group is replaced by the body read from --file. When --file is omitted
+			in an interactive session the name, slug and metadata are prompted for,
+			starting from their current values. When the group was updated by someone
+			else in the meantime, the fields and metadata keys that were edited are
+			applied again to its latest version.
+		`),
+		Args: idArg,
 		Example: heredoc.Doc(`
 			$ shield group edit <group-id> --file=<group-body>
+			$ shield group edit --file=<group-body>
+			$ shield group edit <group-id> --file=<group-body> --dry-run
+			$ shield group edit <group-id>
 		`),
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(groupOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
-			var reqBody shieldv1beta1.GroupRequestBody
-			if err := file.Parse(filePath, &reqBody); err != nil {
-				return err
+			reqBody := &shieldv1beta1.GroupRequestBody{}
+			if filePath != "" {
+				if err := file.Parse(filePath, reqBody); err != nil {
+					return err
+				}
+
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+			} else if !isInteractive(cmd) {
+				return errFileRequired
 			}
 
-			err := reqBody.ValidateAll()
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
 			if err != nil {
 				return err
 			}
+			defer cancel()
 
-			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			groupID, err := resolveID(cmd, client, spinner, args, "group", groupOptions)
 			if err != nil {
 				return err
 			}
-			defer cancel()
 
-			groupID := args[0]
-			_, err = client.UpdateGroup(cmd.Context(), &shieldv1beta1.UpdateGroupRequest{
+			fetchGroup := func(ctx context.Context) (*shieldv1beta1.GroupRequestBody, string, error) {
+				var header metadata.MD
+				getRes, err := client.GetGroup(ctx, &shieldv1beta1.GetGroupRequest{
+					Id: groupID,
+				}, grpc.Header(&header))
+				if err != nil {
+					return nil, "", err
+				}
+				current := getRes.GetGroup()
+				return &shieldv1beta1.GroupRequestBody{
+					Name:     current.GetName(),
+					Slug:     current.GetSlug(),
+					OrgId:    current.GetOrgId(),
+					Metadata: current.GetMetadata(),
+				}, etagOf(header), nil
+			}
+
+			// original is the group as fetched when the edit is prompted for, the
+			// edit is applied again to the group fetched anew when it was updated
+			// in between
+			var original *shieldv1beta1.GroupRequestBody
+			var etag string
+			if filePath == "" {
+				original, etag, err = fetchGroup(cmd.Context())
+				if err != nil {
+					return err
+				}
+				reqBody = proto.Clone(original).(*shieldv1beta1.GroupRequestBody)
+
+				spinner.Stop()
+				if err := promptBody(&reqBody.Metadata,
+					promptField{Message: "Name", Value: &reqBody.Name, Required: true},
+					promptField{Message: "Slug", Value: &reqBody.Slug, Required: true},
+				); err != nil {
+					return err
+				}
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+				if !dryRun {
+					if err := confirmBody(cmd, reqBody); err != nil {
+						return err
+					}
+				}
+			}
+
+			req := &shieldv1beta1.UpdateGroupRequest{
 				Id:   groupID,
-				Body: &reqBody,
+				Body: reqBody,
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "group", Field: "id", Value: groupID},
+					dryRunReference{Resource: "organization", Field: "org_id", Value: reqBody.GetOrgId()},
+				)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "UpdateGroup", req, nil, refs...)
+			}
+
+			err = updateWithRetry(cmd.Context(), etag, func(ctx context.Context) error {
+				_, err := client.UpdateGroup(ctx, req)
+				return err
+			}, func(ctx context.Context) (string, error) {
+				fresh, etag, err := fetchGroup(ctx)
+				if err != nil {
+					return "", err
+				}
+				original, req.Body = fresh, reapplyEdit(original, req.Body, fresh)
+				return etag, nil
 			})
 			if err != nil {
 				return err
@@ -143,25 +302,30 @@ func editGroupCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the group body file")
-	cmd.MarkFlagRequired("file")
+	bindFileFlag(cmd, &filePath, "Path to the group body file, prompted for when omitted")
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func viewGroupCommand(cliConfig *Config) *cli.Command {
 	var metadata bool
+	var output string
 
 	cmd := &cli.Command{
 		Use:   "view",
 		Short: "View a group",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield group view <group-id>
+			$ shield group view
+			$ shield group view <group-id> --output=json
 		`),
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(groupOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -172,7 +336,11 @@ func viewGroupCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			groupID := args[0]
+			groupID, err := resolveID(cmd, client, spinner, args, "group", groupOptions)
+			if err != nil {
+				return err
+			}
+
 			res, err := client.GetGroup(cmd.Context(), &shieldv1beta1.GetGroupRequest{
 				Id: groupID,
 			})
@@ -186,6 +354,10 @@ func viewGroupCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, group)
+			}
+
 			report = append(report, []string{"ID", "NAME", "SLUG", "ORG-ID"})
 			report = append(report, []string{
 				group.GetId(),
@@ -217,17 +389,27 @@ func viewGroupCommand(cliConfig *Config) *cli.Command {
 	}
 
 	cmd.Flags().BoolVarP(&metadata, "metadata", "m", false, "Set this flag to see metadata")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
 
 	return cmd
 }
 
 func listGroupCommand(cliConfig *Config) *cli.Command {
+	var org, output string
+	var filterValues []string
+	var page pageFlags
+
 	cmd := &cli.Command{
 		Use:   "list",
 		Short: "List all groups",
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield group list
+			$ shield group list --org=<organization-id-or-slug>
+			$ shield group list --output=yaml
+			$ shield group list --filter name=*-admins
+			$ shield group list --page-size=20 --page-num=2
+			$ shield group list --all
 		`),
 		Annotations: map[string]string{
 			"group": "core",
@@ -236,22 +418,51 @@ func listGroupCommand(cliConfig *Config) *cli.Command {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
-			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			filters, err := fieldFilters(filterValues, "name", "slug")
 			if err != nil {
 				return err
 			}
-			defer cancel()
 
-			res, err := client.ListGroups(cmd.Context(), &shieldv1beta1.ListGroupsRequest{})
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
 			if err != nil {
 				return err
 			}
+			defer cancel()
+
+			req := &shieldv1beta1.ListGroupsRequest{}
+			ctx, clientFilters := pushDownFilters(cmd.Context(), cmd, cliConfig, req, append([]listFilter{
+				{name: "org_id", value: org, match: func(item proto.Message, value string) bool {
+					return item.(*shieldv1beta1.Group).GetOrgId() == value
+				}},
+			}, filters...))
+
+			var res *shieldv1beta1.ListGroupsResponse
+			if page.all {
+				items, err := listAllPages(page.size, func(size, num int32) ([]*shieldv1beta1.Group, error) {
+					res, err := client.ListGroups(pageContext(ctx, size, num), req)
+					return res.GetGroups(), err
+				})
+				if err != nil {
+					return err
+				}
+				res = &shieldv1beta1.ListGroupsResponse{Groups: items}
+			} else {
+				res, err = client.ListGroups(pageContext(ctx, page.size, page.num), req)
+				if err != nil {
+					return err
+				}
+			}
 
 			report := [][]string{}
+			res.Groups = filterItems(res.GetGroups(), clientFilters)
 			groups := res.GetGroups()
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, res)
+			}
+
 			if len(groups) == 0 {
 				fmt.Printf("No groups found.\n")
 				return nil
@@ -274,5 +485,514 @@ func listGroupCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&org, "org", "", "Only list groups of the organization with the id or slug")
+	cmd.Flags().StringVar(&org, "org-id", "", "Only list groups of the organization")
+	cmd.Flags().MarkDeprecated("org-id", "use --org instead")
+	bindFieldFilterFlag(cmd, &filterValues, "name", "slug")
+	bindPageFlags(cmd, &page)
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func memberaddGroupCommand(cliConfig *Config) *cli.Command {
+	var filePath, header string
+	var dryRun bool
+
+	cmd := &cli.Command{
+		Use:   "memberadd",
+		Short: "add members to a group",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield group memberadd <group-id> --file=<add-group-member-body> --header=<key>:<value>
+			$ shield group memberadd <group-id> --file=<add-group-member-body> --header=<key>:<value> --dry-run
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(groupOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var reqBody shieldv1beta1.AddGroupUserRequestBody
+			if err := file.Parse(filePath, &reqBody); err != nil {
+				return err
+			}
+
+			err := reqBody.ValidateAll()
+			if err != nil {
+				return err
+			}
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			groupID := args[0]
+			req := &shieldv1beta1.AddGroupUserRequest{
+				Id:   groupID,
+				Body: &reqBody,
+			}
+			if dryRun {
+				refs := []dryRunReference{{Resource: "group", Field: "id", Value: groupID}}
+				for _, userID := range reqBody.GetUserIds() {
+					refs = append(refs, dryRunReference{Resource: "user", Field: "user_ids", Value: userID})
+				}
+				refs, err := resolveReferences(cmd.Context(), client, refs...)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "AddGroupUser", req, nil, refs...)
+			}
+
+			ctx := setCtxHeader(cmd.Context(), header)
+			_, err = client.AddGroupUser(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Println("successfully added member(s) to group")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the group member body file")
+	cmd.MarkFlagRequired("file")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	bindDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
+
+func memberremoveGroupCommand(cliConfig *Config) *cli.Command {
+	var userID, header string
+
+	cmd := &cli.Command{
+		Use:   "memberremove",
+		Short: "remove a member from a group",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield group memberremove <group-id> --user=<user-id> --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(groupOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			groupID := args[0]
+			ctx := setCtxHeader(cmd.Context(), header)
+			_, err = client.RemoveGroupUser(ctx, &shieldv1beta1.RemoveGroupUserRequest{
+				Id:     groupID,
+				UserId: userID,
+			})
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Println("successfully removed member from group")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&userID, "user", "u", "", "Id of the user to be removed")
+	cmd.MarkFlagRequired("user")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func memberlistGroupCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "memberlist",
+		Short: "list members of a group",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield group memberlist <group-id>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(groupOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			groupID := args[0]
+			res, err := client.ListGroupUsers(cmd.Context(), &shieldv1beta1.ListGroupUsersRequest{
+				Id: groupID,
+			})
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			members := res.GetUsers()
+
+			spinner.Stop()
+
+			fmt.Printf(" \nShowing %d members\n \n", len(members))
+
+			report = append(report, []string{"ID", "NAME", "EMAIL"})
+			for _, m := range members {
+				report = append(report, []string{
+					m.GetId(),
+					m.GetName(),
+					m.GetEmail(),
+				})
+			}
+			printer.Table(os.Stdout, report)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func importGroupCommand(cliConfig *Config) *cli.Command {
+	var flags importFlags
+
+	cmd := &cli.Command{
+		Use:   "import",
+		Short: "Import groups from a csv file",
+		Long: heredoc.Doc(`
+			Create the groups of the rows of a csv file. The first row names the columns,
+			the name, slug and organization columns are mapped with --name-column,
+			--slug-column and --org-id-column and metadata keys with --metadata-column.
+			The organization of a row is its id or slug.
+
+			Rows are imported --batch-size at a time. Groups that already exist are skipped,
+			or updated with --update-existing keeping the metadata keys not in the file. The
+			result of every row is printed, the import fails if any row failed.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield group import --file=groups.csv
+			$ shield group import -f teams.csv --name-column=Team --slug-column=Handle --org-id-column=Org
+			$ shield group import -f groups.csv --update-existing --metadata-column=cost_center=CostCenter
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			rows, err := readImportRows(flags)
+			if err != nil {
+				return err
+			}
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			ctx := setCtxHeader(cmd.Context(), flags.header)
+			results := importRows(ctx, rows, flags.batchSize, func(ctx context.Context, row importRow) importResult {
+				body := &shieldv1beta1.GroupRequestBody{
+					Name:     row.fields["name"],
+					Slug:     row.fields["slug"],
+					OrgId:    row.fields["org_id"],
+					Metadata: row.metadata,
+				}
+				if err := body.ValidateAll(); err != nil {
+					return newImportResult(body.GetSlug(), importFailed, err)
+				}
+
+				return importExisting(body.GetSlug(), flags.updateExisting, func() error {
+					_, err := client.CreateGroup(ctx, &shieldv1beta1.CreateGroupRequest{Body: body})
+					return err
+				}, func() error {
+					res, err := client.GetGroup(ctx, &shieldv1beta1.GetGroupRequest{Id: body.GetSlug()})
+					if err != nil {
+						return err
+					}
+					body.Metadata = mergeMetadata(res.GetGroup().GetMetadata(), body.GetMetadata())
+					_, err = client.UpdateGroup(ctx, &shieldv1beta1.UpdateGroupRequest{Id: res.GetGroup().GetId(), Body: body})
+					return err
+				})
+			})
+
+			spinner.Stop()
+			return printImportResults(os.Stdout, results, "group")
+		},
+	}
+
+	bindImportFlags(cmd, &flags, cliConfig, "group", "name", "slug", "org_id")
+
+	return cmd
+}
+
+func subgroupaddGroupCommand(cliConfig *Config) *cli.Command {
+	var subgroups []string
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "subgroupadd",
+		Short: "add groups as members of a group",
+		Long: heredoc.Doc(`
+			Add groups as members of a group, the members of the groups are members of the
+			group too. The groups have to belong to the organization of the group and a
+			group can't be nested in itself.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield group subgroupadd <group-id> --group=<group-id> --group=<group-slug> --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(groupOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res groupsResponse
+			query := url.Values{"id": {args[0]}, "subgroup": subgroups}
+			if err := postAdminAPI(cmd.Context(), cliConfig, groupSubgroupsPath, query, header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Println("successfully added group(s) to group")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&subgroups, "group", "g", nil, "Id or slug of the group to be added, can be repeated")
+	cmd.MarkFlagRequired("group")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func subgroupremoveGroupCommand(cliConfig *Config) *cli.Command {
+	var subgroup, header string
+
+	cmd := &cli.Command{
+		Use:   "subgroupremove",
+		Short: "remove a group from the members of a group",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield group subgroupremove <group-id> --group=<group-id> --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(groupOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res groupsResponse
+			query := url.Values{"id": {args[0]}, "subgroup": {subgroup}}
+			if err := deleteAdminAPI(cmd.Context(), cliConfig, groupSubgroupsPath, query, header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Println("successfully removed group from group")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&subgroup, "group", "g", "", "Id or slug of the group to be removed")
+	cmd.MarkFlagRequired("group")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func subgrouplistGroupCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "subgrouplist",
+		Short: "list the groups that are members of a group",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield group subgrouplist <group-id>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(groupOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res groupsResponse
+			if err := getAdminAPI(cmd.Context(), cliConfig, groupSubgroupsPath, url.Values{"id": {args[0]}}, header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf(" \nShowing %d groups\n \n", len(res.Groups))
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAME", "SLUG", "ORG ID"})
+			for _, g := range res.Groups {
+				report = append(report, []string{g.ID, g.Name, g.Slug, g.OrganizationID})
+			}
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+type groupOwnersResponse struct {
+	Owners []struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"owners"`
+}
+
+func owneraddGroupCommand(cliConfig *Config) *cli.Command {
+	var userIDs []string
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "owneradd",
+		Short: "add owners to a group",
+		Long: heredoc.Doc(`
+			Add owners to a group, they manage the members of the group without a role on
+			its organization. The owners and managers of the group and the owners of its
+			organization can add and remove owners.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield group owneradd <group-id> --user=<user-id> --user=<user-email> --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(groupOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res groupOwnersResponse
+			query := url.Values{"id": {args[0]}, "user": userIDs}
+			if err := postAdminAPI(cmd.Context(), cliConfig, groupOwnersPath, query, header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Println("successfully added owner(s) to group")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&userIDs, "user", "u", nil, "Id or email of the user to be added, can be repeated")
+	cmd.MarkFlagRequired("user")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func ownerremoveGroupCommand(cliConfig *Config) *cli.Command {
+	var userID, header string
+
+	cmd := &cli.Command{
+		Use:   "ownerremove",
+		Short: "remove an owner from a group",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield group ownerremove <group-id> --user=<user-id> --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(groupOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res groupOwnersResponse
+			query := url.Values{"id": {args[0]}, "user": {userID}}
+			if err := deleteAdminAPI(cmd.Context(), cliConfig, groupOwnersPath, query, header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Println("successfully removed owner from group")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&userID, "user", "u", "", "Id or email of the user to be removed")
+	cmd.MarkFlagRequired("user")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func ownerlistGroupCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "ownerlist",
+		Short: "list owners of a group",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield group ownerlist <group-id>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(groupOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res groupOwnersResponse
+			if err := getAdminAPI(cmd.Context(), cliConfig, groupOwnersPath, url.Values{"id": {args[0]}}, header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf(" \nShowing %d owners\n \n", len(res.Owners))
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAME", "EMAIL"})
+			for _, o := range res.Owners {
+				report = append(report, []string{o.ID, o.Name, o.Email})
+			}
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
 	return cmd
 }
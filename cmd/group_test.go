@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/odpf/shield/cmd"
@@ -25,12 +26,48 @@ func TestClientGroup(t *testing.T) {
 				subCommands: []string{"list"},
 				err:         cmd.ErrClientConfigHostNotFound,
 			},
+			{
+				name:        "`group` delete without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"delete", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`group` delete with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
+			{
+				name:        "`group` delete without force should throw error not confirmed",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test", "-H", "X-Shield-Email:admin@odpf.io"},
+				err:         fmt.Errorf("%w: pass --force to delete without a prompt", errors.New("operation cancelled")),
+			},
 			{
 				name:        "`group` list with host flag should pass",
 				want:        "",
 				subCommands: []string{"list", "-h", "test"},
 				err:         context.DeadlineExceeded,
 			},
+			{
+				name:        "`group` list with a filter should pass",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "--filter", "name=*-admins", "--filter", "metadata.team=data"},
+				err:         context.DeadlineExceeded,
+			},
+			{
+				name:        "`group` list with a filter without a glob should throw error",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "--filter", "name"},
+				err:         errors.New("invalid filter 'name', expected field=glob"),
+			},
+			{
+				name:        "`group` list with a filter on an unknown field should throw error",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "--filter", "email=*@gojek.com"},
+				err:         errors.New("unknown filter field 'email'; valid: name, slug, metadata.<key>"),
+			},
 			{
 				name:        "`group` create only should throw error host not found",
 				want:        "",
@@ -67,6 +104,42 @@ func TestClientGroup(t *testing.T) {
 				subCommands: []string{"view", "123", "-h", "test"},
 				err:         context.DeadlineExceeded,
 			},
+			{
+				name:        "`group` memberadd without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"memberadd", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`group` memberadd with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"memberadd", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"file\", \"header\" not set"),
+			},
+			{
+				name:        "`group` memberremove with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"memberremove", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\", \"user\" not set"),
+			},
+			{
+				name:        "`group` memberlist with host flag should pass",
+				want:        "",
+				subCommands: []string{"memberlist", "123", "-h", "test"},
+				err:         context.DeadlineExceeded,
+			},
+			{
+				name:        "`group` import without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"import", "-f", "groups.csv"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`group` import with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"import", "-h", "test"},
+				err:         errors.New("required flag(s) \"file\", \"header\" not set"),
+			},
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
@@ -11,12 +11,3 @@ var envHelp = map[string]string{
 			CLICOLOR: set to "0" to disable printing ANSI colors in output.
 		`),
 }
-
-var authHelp = map[string]string{
-	"short": "Auth configs that need to be used with shield",
-	"long": heredoc.Doc(`
-			Send an additional flag header with "key:value" format.
-			Example:
-				shield create user -f user.yaml -H X-Shield-Email:user@odpf.io
-		`),
-}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/odpf/salt/printer"
+	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	importCreated = "created"
+	importUpdated = "updated"
+	importSkipped = "skipped"
+	importFailed  = "failed"
+
+	defaultImportBatchSize = 10
+)
+
+// importFlags are the flags of the csv imports, columns maps the fields of
+// the request bodies to the columns of the file
+type importFlags struct {
+	filePath       string
+	header         string
+	columns        map[string]*string
+	metadata       map[string]string
+	batchSize      int
+	updateExisting bool
+}
+
+func bindImportFlags(cmd *cli.Command, flags *importFlags, cliConfig *Config, resource string, columns ...string) {
+	flags.columns = map[string]*string{}
+	for _, c := range columns {
+		flags.columns[c] = new(string)
+		cmd.Flags().StringVar(flags.columns[c], strings.ReplaceAll(c, "_", "-")+"-column", c, fmt.Sprintf("Column of the %s %s", resource, strings.ReplaceAll(c, "_", " ")))
+	}
+	cmd.Flags().StringVarP(&flags.filePath, "file", "f", "", fmt.Sprintf("Path to the csv file of the %ss, its first row names the columns", resource))
+	cmd.Flags().StringToStringVar(&flags.metadata, "metadata-column", nil, "Metadata key set from a column, as key=column")
+	cmd.Flags().IntVar(&flags.batchSize, "batch-size", defaultImportBatchSize, "Number of rows imported at once")
+	cmd.Flags().BoolVar(&flags.updateExisting, "update-existing", false, fmt.Sprintf("Update the existing %ss instead of skipping them", resource))
+	cmd.MarkFlagRequired("file")
+	bindHeaderFlag(cmd, &flags.header, cliConfig)
+}
+
+// importRow is a row of the file, by field of the request bodies
+type importRow struct {
+	line     int
+	fields   map[string]string
+	metadata *structpb.Struct
+	// err is why the row can't be read, it isn't imported
+	err error
+}
+
+type importResult struct {
+	row    importRow
+	key    string
+	status string
+	err    error
+}
+
+// readImportRows reads the rows of the csv file, the mapped columns have to
+// be among the columns named by its first row
+func readImportRows(flags importFlags) ([]importRow, error) {
+	f, err := os.Open(flags.filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.TrimLeadingSpace = true
+	// rows with missing columns are reported along with the other failed
+	// rows instead of failing the import
+	r.FieldsPerRecord = -1
+	head, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the columns of %s: %w", flags.filePath, err)
+	}
+	index := map[string]int{}
+	for i, name := range head {
+		index[strings.TrimSpace(name)] = i
+	}
+	column := func(name string) (int, error) {
+		i, ok := index[name]
+		if !ok {
+			return 0, fmt.Errorf("%s has no column %s", flags.filePath, name)
+		}
+		return i, nil
+	}
+
+	fields := map[string]int{}
+	for field, name := range flags.columns {
+		if fields[field], err = column(*name); err != nil {
+			return nil, err
+		}
+	}
+	metadata := map[string]int{}
+	for key, name := range flags.metadata {
+		if metadata[key], err = column(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := r.FieldPos(0)
+		row := importRow{line: line, fields: map[string]string{}}
+		if len(record) != len(head) {
+			row.err = fmt.Errorf("row has %d columns, %d expected", len(record), len(head))
+			rows = append(rows, row)
+			continue
+		}
+		for field, i := range fields {
+			row.fields[field] = strings.TrimSpace(record[i])
+		}
+		values := map[string]any{}
+		for key, i := range metadata {
+			values[key] = strings.TrimSpace(record[i])
+		}
+		if row.metadata, err = structpb.NewStruct(values); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// importRows imports the rows a batch at a time, the rows of a batch are
+// imported concurrently and a failed row doesn't stop the others
+func importRows(ctx context.Context, rows []importRow, batchSize int, fn func(ctx context.Context, row importRow) importResult) []importResult {
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+	results := make([]importResult, len(rows))
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if rows[i].err != nil {
+					results[i] = importResult{status: importFailed, err: rows[i].err}
+				} else {
+					results[i] = fn(ctx, rows[i])
+				}
+				results[i].row = rows[i]
+			}(i)
+		}
+		wg.Wait()
+	}
+	return results
+}
+
+// importExisting creates the row, and updates the existing one instead when
+// asked to
+func importExisting(key string, updateExisting bool, create func() error, update func() error) importResult {
+	err := create()
+	if status.Code(err) != codes.AlreadyExists {
+		return newImportResult(key, importCreated, err)
+	}
+	if !updateExisting {
+		return importResult{key: key, status: importSkipped, err: errors.New("already exists")}
+	}
+	return newImportResult(key, importUpdated, update())
+}
+
+func newImportResult(key, done string, err error) importResult {
+	if err != nil {
+		return importResult{key: key, status: importFailed, err: err}
+	}
+	return importResult{key: key, status: done}
+}
+
+// printImportResults prints the result of every row, it fails when any of
+// the rows failed
+func printImportResults(w io.Writer, results []importResult, resource string) error {
+	counts := map[string]int{}
+	report := [][]string{}
+	report = append(report, []string{"LINE", strings.ToUpper(resource), "RESULT", "ERROR"})
+	for _, r := range results {
+		counts[r.status]++
+		msg := ""
+		if r.err != nil {
+			msg = r.err.Error()
+			if s, ok := status.FromError(r.err); ok {
+				msg = s.Message()
+			}
+		}
+		report = append(report, []string{strconv.Itoa(r.row.line), r.key, r.status, msg})
+	}
+
+	fmt.Fprintf(w, " \nImported %d rows: %d created, %d updated, %d skipped, %d failed\n \n",
+		len(results), counts[importCreated], counts[importUpdated], counts[importSkipped], counts[importFailed])
+	printer.Table(w, report)
+
+	if counts[importFailed] > 0 {
+		return fmt.Errorf("%d of %d %ss could not be imported", counts[importFailed], len(results), resource)
+	}
+	return nil
+}
+
+// mergeMetadata sets the imported keys on the metadata of the existing
+// resource, the other keys are kept
+func mergeMetadata(existing, imported *structpb.Struct) *structpb.Struct {
+	merged := &structpb.Struct{Fields: map[string]*structpb.Value{}}
+	for k, v := range existing.GetFields() {
+		merged.Fields[k] = v
+	}
+	for k, v := range imported.GetFields() {
+		merged.Fields[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/config"
+	"github.com/odpf/shield/core/invitation"
+	"github.com/odpf/shield/internal/api"
+	"github.com/odpf/shield/internal/store/spicedb"
+	shieldlogger "github.com/odpf/shield/pkg/logger"
+	"github.com/odpf/shield/pkg/secrets"
+	cli "github.com/spf13/cobra"
+)
+
+// the invitations are created, revoked and accepted with endpoints the
+// gateway serves next to the rpcs, there are no rpcs for them
+const (
+	invitationsPath       = "/admin/v1beta1/invitations"
+	invitationsAcceptPath = "/admin/v1beta1/invitations/accept"
+)
+
+type invitationResponse struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"org_id"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func InvitationCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:     "invitation",
+		Aliases: []string{"invitations"},
+		Short:   "Manage organization invitations",
+		Long: heredoc.Doc(`
+			Work with organization invitations.
+
+			Admins of an organization invite users by email, the invited user joins the
+			organization and the groups listed in the invitation once they accept it.
+
+			Invitations are created, revoked and accepted through the server as the logged
+			in user or the user of the header. They are listed with the server config.
+		`),
+		Example: heredoc.Doc(`
+			$ shield invitation create --org=odpf --email=jane@odpf.io --group=data
+			$ shield invitation list --org=odpf
+			$ shield invitation accept <invitation-id> --header=X-Shield-Email:jane@odpf.io
+			$ shield invitation revoke <invitation-id>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(createInvitationCommand(cliConfig))
+	cmd.AddCommand(acceptInvitationCommand(cliConfig))
+	cmd.AddCommand(listInvitationCommand())
+	cmd.AddCommand(revokeInvitationCommand(cliConfig))
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func createInvitationCommand(cliConfig *Config) *cli.Command {
+	var orgID, email, header string
+	var groupIDs []string
+	var expiry time.Duration
+
+	cmd := &cli.Command{
+		Use:     "create",
+		Aliases: []string{"invite"},
+		Short:   "Invite a user to an organization",
+		Long: heredoc.Doc(`
+			Invite a user to an organization as the logged in user or the user of the
+			header, who has to be an admin of the organization.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield invitation create --org=odpf --email=jane@odpf.io
+			$ shield invitation create --org=odpf --email=jane@odpf.io --group=data --group=infra --expiry=72h --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			query := url.Values{"org": {orgID}, "email": {email}, "group": groupIDs, "expiry": {expiry.String()}}
+			var created invitationResponse
+			if err := postAdminAPI(cmd.Context(), cliConfig, invitationsPath, query, header, &created); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("successfully invited %s with invitation %s, it expires at %s\n", created.Email, created.ID, created.ExpiresAt.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&orgID, "org", "o", "", "ID or slug of the organization")
+	cmd.MarkFlagRequired("org")
+	cmd.Flags().StringVarP(&email, "email", "e", "", "Email of the invited user")
+	cmd.MarkFlagRequired("email")
+	cmd.Flags().StringArrayVarP(&groupIDs, "group", "g", nil, "ID or slug of a group of the organization the user joins, can be repeated")
+	cmd.Flags().DurationVar(&expiry, "expiry", invitation.DefaultExpiry, "How long the invitation can be accepted for")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func acceptInvitationCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "accept <invitation-id>",
+		Short: "Accept an invitation",
+		Long: heredoc.Doc(`
+			Accept an invitation as the logged in user or the user of the header, the
+			invitation has to be for their email. They join the organization and the
+			groups of the invitation, or none of them if the invitation can't be accepted.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield invitation accept <invitation-id>
+			$ shield invitation accept <invitation-id> --header=X-Shield-Email:jane@odpf.io
+		`),
+		Annotations: map[string]string{
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var accepted invitationResponse
+			if err := postAdminAPI(cmd.Context(), cliConfig, invitationsAcceptPath, url.Values{"id": {args[0]}}, header, &accepted); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("successfully accepted invitation %s to organization %s\n", accepted.ID, accepted.OrgID)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func listInvitationCommand() *cli.Command {
+	var configFile, orgID, email, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List invitations",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield invitation list --org=odpf
+			$ shield invitation list --email=jane@odpf.io
+			$ shield invitation list --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			deps, cleanup, err := serverDeps(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			invitations, err := deps.InvitationService.List(cmd.Context(), invitation.Filter{OrgID: orgID, Email: email})
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			report := [][]string{}
+			report = append(report, []string{"ID", "ORG ID", "EMAIL", "GROUPS", "STATUS", "EXPIRES AT"})
+			for _, i := range invitations {
+				report = append(report, []string{i.ID, i.OrgID, i.Email, strings.Join(i.GroupIDs, ","), invitationStatus(i, now), i.ExpiresAt.Format(time.RFC3339)})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d invitations\n \n", len(invitations))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&orgID, "org", "o", "", "Only list the invitations of the organization")
+	cmd.Flags().StringVarP(&email, "email", "e", "", "Only list the invitations of the email")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func revokeInvitationCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "revoke <invitation-id>",
+		Short: "Revoke a pending invitation",
+		Long: heredoc.Doc(`
+			Revoke a pending invitation as the logged in user or the user of the header,
+			who has to be an admin of the organization of the invitation.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield invitation revoke <invitation-id>
+			$ shield invitation revoke <invitation-id> --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			var revoked invitationResponse
+			if err := deleteAdminAPI(cmd.Context(), cliConfig, invitationsPath, url.Values{"id": {args[0]}}, header, &revoked); err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully revoked invitation %s of %s\n", revoked.ID, revoked.Email)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func invitationStatus(i invitation.Invitation, now time.Time) string {
+	switch {
+	case i.IsAccepted():
+		return "accepted"
+	case i.IsRevoked():
+		return "revoked"
+	case i.IsExpired(now):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+// serverDeps builds the services of the server from its config for the
+// commands working on the stores directly, resources are left out as
+// they need the resources config
+func serverDeps(ctx context.Context, configFile string) (api.Deps, func(), error) {
+	appConfig, err := config.Load(configFile)
+	if err != nil {
+		return api.Deps{}, nil, err
+	}
+	return serverDepsOf(ctx, appConfig)
+}
+
+// serverDepsOf builds the services of the server config, the cleanup closes
+// the database
+func serverDepsOf(ctx context.Context, appConfig *config.Shield) (api.Deps, func(), error) {
+	logger := shieldlogger.InitLogger(appConfig.Log)
+
+	resolver := secrets.NewResolver(appConfig.Secrets)
+	dbClient, err := setupDB(appConfig.DB, resolver, logger)
+	if err != nil {
+		return api.Deps{}, nil, err
+	}
+
+	authz, err := setupAuthzEngine(appConfig, dbClient, resolver, logger)
+	if err != nil {
+		dbClient.Close()
+		return api.Deps{}, nil, err
+	}
+
+	deps, err := buildAPIDependencies(ctx, logger, nil, dbClient, authz, spicedb.CheckCacheConfig{}, appConfig.Event)
+	if err != nil {
+		dbClient.Close()
+		return api.Deps{}, nil, err
+	}
+	return deps, func() { dbClient.Close() }, nil
+}
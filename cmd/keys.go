@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/signingkey"
+	"github.com/odpf/shield/pkg/secrets"
+	cli "github.com/spf13/cobra"
+)
+
+func KeysCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "keys",
+		Short: "Manage the keys shield signs tokens with",
+		Long: heredoc.Doc(`
+			Work with the keys shield signs tokens with.
+
+			The keys are generated by the server and kept in the database, their private
+			keys encrypted with the encryption_key of the keys config. A new key is
+			generated every rotation_interval, it is published on /.well-known/jwks.json
+			of the api for the activation_delay before it signs the tokens. The previous
+			key is published for the retention after that so the tokens it signed can
+			still be verified.
+
+			The commands connect to the database with the server config.
+		`),
+		Example: heredoc.Doc(`
+			$ shield keys list
+			$ shield keys rotate
+			$ shield keys rotate --immediately
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(rotateKeysCommand())
+	cmd.AddCommand(listKeysCommand())
+
+	return cmd
+}
+
+func rotateKeysCommand() *cli.Command {
+	var configFile string
+	var immediately bool
+
+	cmd := &cli.Command{
+		Use:   "rotate",
+		Short: "Generate a new signing key",
+		Long: heredoc.Doc(`
+			Generate a new signing key, it signs the tokens once the activation delay
+			passed. The servers pick it up within their refresh interval.
+
+			With --immediately the key signs the tokens right away, like when the signing
+			key leaked. The verifiers that cached the jwks can't verify the tokens it
+			signs until they fetch the jwks again.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield keys rotate
+			$ shield keys rotate --immediately -c ./config.yaml
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			svc, cleanup, err := signingKeyService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			key, err := svc.Rotate(cmd.Context(), immediately)
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("successfully generated %s key %s, it signs the tokens from %s\n",
+				key.Algorithm, key.ID, key.ActivatesAt.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().BoolVar(&immediately, "immediately", false, "Sign the tokens with the key right away")
+
+	return cmd
+}
+
+func listKeysCommand() *cli.Command {
+	var configFile, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List the signing keys",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield keys list
+			$ shield keys list --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			svc, cleanup, err := signingKeyService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			keys, err := svc.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "ALGORITHM", "STATE", "CREATED AT", "ACTIVATES AT", "EXPIRES AT"})
+			for _, key := range keys {
+				expiresAt := ""
+				if !key.ExpiresAt.IsZero() {
+					expiresAt = key.ExpiresAt.Format(time.RFC3339)
+				}
+				report = append(report, []string{
+					key.ID,
+					key.Algorithm,
+					key.State,
+					key.CreatedAt.Format(time.RFC3339),
+					key.ActivatesAt.Format(time.RFC3339),
+					expiresAt,
+				})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d keys\n \n", len(keys))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func signingKeyService(configFile string) (*signingkey.Service, func(), error) {
+	dbClient, appConfig, err := serverDB(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !appConfig.Keys.Enabled() {
+		dbClient.Close()
+		return nil, nil, errors.New("keys are not enabled, set the encryption_key of the keys config")
+	}
+	keysConfig := appConfig.Keys
+	keysConfig.EncryptionKey, err = secrets.NewResolver(appConfig.Secrets).Resolve(context.Background(), keysConfig.EncryptionKey)
+	if err != nil {
+		dbClient.Close()
+		return nil, nil, err
+	}
+	svc, err := signingkey.NewService(newStores(dbClient).signingKeys, newAuditRecorder(dbClient, appConfig.Event), keysConfig)
+	if err != nil {
+		dbClient.Close()
+		return nil, nil, err
+	}
+	return svc, func() { dbClient.Close() }, nil
+}
@@ -0,0 +1,475 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	cli "github.com/spf13/cobra"
+)
+
+const (
+	loginTimeout = 5 * time.Minute
+
+	// accessTokenLeeway renews the access token a bit before it expires
+	// so it doesn't expire on its way to the server
+	accessTokenLeeway = 30 * time.Second
+
+	tokenPath    = "/admin/v1beta1/auth/token"
+	sessionsPath = "/admin/v1beta1/auth/sessions"
+)
+
+var ErrLoginExpired = errors.New(heredoc.Doc(`
+	Shield login expired.
+
+	Run "shield auth login" to log in again.
+`))
+
+func loginCommand() *cli.Command {
+	var issuer, clientID, clientSecret, scopes string
+	var port int
+	var noBrowser bool
+
+	cmd := &cli.Command{
+		Use:   "login",
+		Short: "Log in with an oidc provider",
+		Long: heredoc.Doc(`
+			Log in to shield with an oidc provider configured on the server.
+
+			The browser is opened on the login page of the provider, the authorization
+			code it redirects back with is exchanged for an id token with pkce. Shield
+			starts a session for the id token, its tokens are stored in the client
+			config and sent with the requests of the client commands from then on, so
+			they don't need the --header flag.
+
+			The issuer and the client id are remembered for the next login.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield auth login --issuer=https://accounts.google.com --client-id=<client-id>
+			$ shield auth login --no-browser
+		`),
+		Annotations: map[string]string{
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			if issuer == "" {
+				issuer = cliConfig.Auth.Issuer
+			}
+			if clientID == "" {
+				clientID = cliConfig.Auth.ClientID
+			}
+			if issuer == "" || clientID == "" {
+				return errors.New("--issuer and --client-id are required on the first login")
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), loginTimeout)
+			defer cancel()
+
+			idToken, err := authorizeWithPKCE(ctx, pkceConfig{
+				Issuer:       issuer,
+				ClientID:     clientID,
+				ClientSecret: clientSecret,
+				Scopes:       strings.Fields(strings.ReplaceAll(scopes, ",", " ")),
+				Port:         port,
+				NoBrowser:    noBrowser,
+			})
+			if err != nil {
+				return err
+			}
+
+			tokens, err := requestTokens(ctx, cliConfig.Host, tokenRequest{GrantType: "id_token", IDToken: idToken})
+			if err != nil {
+				return err
+			}
+
+			auth := tokens.authConfig(time.Now())
+			auth.Host = cliConfig.Host
+			auth.Issuer = issuer
+			auth.ClientID = clientID
+			if err := saveAuthConfig(cliConfig.activeContext, auth); err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully logged in to %s with session %s\n", cliConfig.Host, tokens.SessionID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&issuer, "issuer", "", "Issuer url of the oidc provider")
+	cmd.Flags().StringVar(&clientID, "client-id", "", "Client id of shield at the oidc provider")
+	cmd.Flags().StringVar(&clientSecret, "client-secret", "", "Client secret, only for providers requiring one along with pkce")
+	cmd.Flags().StringVar(&scopes, "scopes", "openid email profile", "Scopes to request")
+	cmd.Flags().IntVar(&port, "port", 0, "Local port the provider redirects back to, random by default")
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Print the login url instead of opening the browser")
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func logoutCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "logout",
+		Short: "Log out and revoke the session",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield auth logout
+		`),
+		Annotations: map[string]string{
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			auth := cliConfig.Auth
+			if !auth.LoggedIn() {
+				fmt.Println("not logged in")
+				return nil
+			}
+
+			if token, err := loginToken(cmd.Context(), cliConfig); err == nil {
+				if err := revokeSession(cmd.Context(), auth.Host, token, auth.SessionID); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to revoke session %s: %s\n", auth.SessionID, err)
+				}
+			}
+
+			if err := saveAuthConfig(cliConfig.activeContext, AuthConfig{Issuer: auth.Issuer, ClientID: auth.ClientID}); err != nil {
+				return err
+			}
+			fmt.Printf("successfully logged out of session %s\n", auth.SessionID)
+			return nil
+		},
+	}
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+type pkceConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Port         int
+	NoBrowser    bool
+}
+
+// authorizeWithPKCE runs the authorization code flow with pkce against
+// the provider, the code is received by a server listening on loopback
+func authorizeWithPKCE(ctx context.Context, cfg pkceConfig) (string, error) {
+	var discovery struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+	}
+	if err := getJSON(ctx, strings.TrimSuffix(cfg.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return "", fmt.Errorf("discovering oidc provider: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", cfg.Port))
+	if err != nil {
+		return "", err
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", cfg.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	type callback struct {
+		code string
+		err  error
+	}
+	callbacks := make(chan callback, 1)
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+			q := r.URL.Query()
+			var cb callback
+			switch {
+			case q.Get("state") != state:
+				cb.err = errors.New("login callback has an unexpected state")
+			case q.Get("error") != "":
+				cb.err = fmt.Errorf("login failed: %s %s", q.Get("error"), q.Get("error_description"))
+			default:
+				cb.code = q.Get("code")
+			}
+			if cb.err != nil {
+				http.Error(w, cb.err.Error(), http.StatusBadRequest)
+			} else {
+				fmt.Fprintln(w, "Login received by shield, this window can be closed.")
+			}
+			select {
+			case callbacks <- cb:
+			default:
+			}
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	fmt.Fprintf(os.Stderr, "Log in at\n \n%s\n \n", authURL.String())
+	if !cfg.NoBrowser {
+		if err := openBrowser(authURL.String()); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open the browser: %s\n", err)
+		}
+	}
+
+	var cb callback
+	select {
+	case cb = <-callbacks:
+	case <-ctx.Done():
+		return "", fmt.Errorf("waiting for login: %w", ctx.Err())
+	}
+	if cb.err != nil {
+		return "", cb.err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", cb.code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", cfg.ClientID)
+	form.Set("code_verifier", verifier)
+	if cfg.ClientSecret != "" {
+		form.Set("client_secret", cfg.ClientSecret)
+	}
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := postForm(ctx, discovery.TokenEndpoint, form, &tokens); err != nil {
+		return "", fmt.Errorf("exchanging authorization code: %w", err)
+	}
+	if tokens.Error != "" {
+		return "", fmt.Errorf("exchanging authorization code: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return "", errors.New("oidc provider returned no id token, is the openid scope requested?")
+	}
+	return tokens.IDToken, nil
+}
+
+type tokenRequest struct {
+	GrantType    string `json:"grant_type"`
+	IDToken      string `json:"id_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	SessionID        string `json:"session_id"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (t tokenResponse) authConfig(now time.Time) AuthConfig {
+	return AuthConfig{
+		SessionID:    t.SessionID,
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		ExpiresAt:    now.Add(time.Duration(t.ExpiresIn) * time.Second).Unix(),
+	}
+}
+
+// requestTokens exchanges an id token or a refresh token for the tokens
+// of a session
+func requestTokens(ctx context.Context, host string, req tokenRequest) (tokenResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL(host, tokenPath), bytes.NewReader(body))
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	var resp tokenResponse
+	if err := doJSON(httpReq, &resp); err != nil {
+		return tokenResponse{}, err
+	}
+	if resp.Error != "" {
+		return tokenResponse{}, fmt.Errorf("%s: %s", resp.Error, resp.ErrorDescription)
+	}
+	return resp, nil
+}
+
+func revokeSession(ctx context.Context, host, token, sessionID string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, apiURL(host, sessionsPath+"/"+url.PathEscape(sessionID)), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// loginToken returns the access token of the login, renewing it with the
+// refresh token once it expires
+func loginToken(ctx context.Context, cfg *Config) (string, error) {
+	if !cfg.Auth.accessTokenExpired(time.Now().Add(accessTokenLeeway)) {
+		return cfg.Auth.AccessToken, nil
+	}
+
+	tokens, err := requestTokens(ctx, cfg.Auth.Host, tokenRequest{GrantType: "refresh_token", RefreshToken: cfg.Auth.RefreshToken})
+	if err != nil {
+		return "", fmt.Errorf("%w\n%s", ErrLoginExpired, err.Error())
+	}
+
+	auth := tokens.authConfig(time.Now())
+	auth.Host = cfg.Auth.Host
+	auth.Issuer = cfg.Auth.Issuer
+	auth.ClientID = cfg.Auth.ClientID
+	if err := saveAuthConfig(cfg.activeContext, auth); err != nil {
+		return "", err
+	}
+	cfg.Auth = auth
+	return auth.AccessToken, nil
+}
+
+// cliLoginToken is the access token of the login sent with the requests
+// which don't have an authorization header of their own, only to the host
+// the login is for
+func cliLoginToken(ctx context.Context) (string, error) {
+	if cliConfig == nil || !cliConfig.Auth.LoggedIn() || cliConfig.Auth.Host != cliConfig.Host {
+		return "", nil
+	}
+	return loginToken(ctx, cliConfig)
+}
+
+// saveAuthConfig writes the login to the context it is for in the client
+// config, the config is read again so flags overriding it aren't saved
+// along
+func saveAuthConfig(contextName string, auth AuthConfig) error {
+	cfg, stored, err := readClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if contextName == "" {
+		stored.Auth = auth
+	} else {
+		contextConfig, ok := stored.Contexts[contextName]
+		if !ok {
+			return fmt.Errorf("context %q not found in client config", contextName)
+		}
+		contextConfig.Auth = auth
+		stored.Contexts[contextName] = contextConfig
+	}
+	return writeClientConfig(cfg, stored)
+}
+
+// apiURL is the url of the path on the gateway of the host, the host of
+// the client config has no scheme as it is dialed with grpc
+func apiURL(host, path string) string {
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return strings.TrimSuffix(host, "/") + path
+}
+
+func getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	return doJSON(req, v)
+}
+
+func postForm(ctx context.Context, endpoint string, form url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	return doJSON(req, v)
+}
+
+// doJSON decodes the json body of the response, error responses are
+// decoded as well as they describe the error
+func doJSON(req *http.Request, v any) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func openBrowser(target string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target).Start()
+	default:
+		return exec.Command("xdg-open", target).Start()
+	}
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/metaschema"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/file"
+	cli "github.com/spf13/cobra"
+)
+
+func MetaSchemaCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:     "metaschema",
+		Aliases: []string{"metaschemas"},
+		Short:   "Manage the schemas of metadata",
+		Long: heredoc.Doc(`
+			Work with the schemas of metadata.
+
+			A json schema can be registered for the metadata of users, organizations,
+			projects and groups, creating or updating one of them with metadata not
+			matching the schema of its type is refused with the violations of the
+			schema. Entities created before a schema is registered keep their metadata
+			until they are updated.
+
+			Schemas support the type, enum, const, properties, required,
+			additionalProperties, min/maxProperties, min/maxLength, pattern, format
+			(email, uri, date-time, date and uuid), minimum, maximum, exclusiveMinimum,
+			exclusiveMaximum, multipleOf, items, min/maxItems and uniqueItems keywords.
+
+			The commands connect to the database with the server config.
+		`),
+		Example: heredoc.Doc(`
+			$ shield metaschema create --entity=user --file=user-metadata.json
+			$ shield metaschema list
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(createMetaSchemaCommand())
+	cmd.AddCommand(listMetaSchemaCommand())
+
+	return cmd
+}
+
+func createMetaSchemaCommand() *cli.Command {
+	var configFile, entity, filePath string
+
+	cmd := &cli.Command{
+		Use:   "create",
+		Short: "Register the schema of the metadata of a type of entity",
+		Long: heredoc.Doc(`
+			Register the schema of the metadata of a type of entity.
+
+			The schema is read from a json or yaml file, it replaces the schema
+			registered for the type before.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield metaschema create --entity=user --file=user-metadata.json
+			$ shield metaschema create --entity=organization --file=org-metadata.yaml -c ./config.yaml
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			var doc map[string]any
+			if err := file.Parse(filePath, &doc); err != nil {
+				return err
+			}
+			schema, err := json.Marshal(doc)
+			if err != nil {
+				return err
+			}
+
+			svc, cleanup, err := metaSchemaService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			created, err := svc.Create(cmd.Context(), metaschema.MetaSchema{
+				Entity: entity,
+				Schema: string(schema),
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully registered the metadata schema of %s\n", created.Entity)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&entity, "entity", "e", "", fmt.Sprintf("Type of entity, one of %s", strings.Join(metaschema.Entities, ", ")))
+	cmd.MarkFlagRequired("entity")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the json schema file")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func listMetaSchemaCommand() *cli.Command {
+	var configFile, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List the schemas of metadata",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield metaschema list
+			$ shield metaschema list --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			svc, cleanup, err := metaSchemaService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			schemas, err := svc.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ENTITY", "SCHEMA", "UPDATED AT"})
+			for _, ms := range schemas {
+				var schema bytes.Buffer
+				if err := json.Compact(&schema, []byte(ms.Schema)); err != nil {
+					schema.WriteString(ms.Schema)
+				}
+				report = append(report, []string{ms.Entity, schema.String(), ms.UpdatedAt.Format(time.RFC3339)})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d metadata schemas\n \n", len(schemas))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func metaSchemaService(configFile string) (*metaschema.Service, func(), error) {
+	dbClient, _, err := serverDB(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newMetaSchemaService(dbClient), func() { dbClient.Close() }, nil
+}
+
+func newMetaSchemaService(dbClient *db.Client) *metaschema.Service {
+	repositories := newStores(dbClient)
+	return metaschema.NewService(
+		repositories.metaSchemas,
+		audit.NewService(repositories.audit))
+}
@@ -1,14 +1,25 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/store/blob"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
 	"github.com/odpf/shield/pkg/file"
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/pmezard/go-difflib/difflib"
 	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 )
 
 func NamespaceCommand(cliConfig *Config) *cli.Command {
@@ -24,6 +35,8 @@ func NamespaceCommand(cliConfig *Config) *cli.Command {
 			$ shield namespace edit
 			$ shield namespace view
 			$ shield namespace list
+			$ shield namespace policies
+			$ shield namespace validate
 		`),
 		Annotations: map[string]string{
 			"group":  "core",
@@ -35,6 +48,10 @@ func NamespaceCommand(cliConfig *Config) *cli.Command {
 	cmd.AddCommand(editNamespaceCommand(cliConfig))
 	cmd.AddCommand(viewNamespaceCommand(cliConfig))
 	cmd.AddCommand(listNamespaceCommand(cliConfig))
+	cmd.AddCommand(deleteCommand(cliConfig, "namespace", namespaceOptions))
+	cmd.AddCommand(policiesNamespaceCommand(cliConfig))
+	cmd.AddCommand(validateNamespaceCommand())
+	cmd.AddCommand(templateCommand("namespace", &shieldv1beta1.NamespaceRequestBody{}))
 
 	bindFlagsFromClientConfig(cmd)
 
@@ -42,7 +59,9 @@ func NamespaceCommand(cliConfig *Config) *cli.Command {
 }
 
 func createNamespaceCommand(cliConfig *Config) *cli.Command {
-	var filePath string
+	var filePath, output string
+	var dryRun bool
+	var quiet bool
 
 	cmd := &cli.Command{
 		Use:   "create",
@@ -50,6 +69,9 @@ func createNamespaceCommand(cliConfig *Config) *cli.Command {
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield namespace create --file=<namespace-body>
+			$ shield namespace create --file=<namespace-body> --output=json
+			$ shield namespace create --file=<namespace-body> --quiet
+			$ shield namespace create --file=<namespace-body> --dry-run
 		`),
 		Annotations: map[string]string{
 			"group": "core",
@@ -65,7 +87,8 @@ func createNamespaceCommand(cliConfig *Config) *cli.Command {
 
 			err := reqBody.ValidateAll()
 			if err != nil {
-				return err
+				spinner.Stop()
+				return reportValidationError(os.Stdout, output, err)
 			}
 
 			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
@@ -74,14 +97,29 @@ func createNamespaceCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			res, err := client.CreateNamespace(cmd.Context(), &shieldv1beta1.CreateNamespaceRequest{
+			req := &shieldv1beta1.CreateNamespaceRequest{
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				spinner.Stop()
+				return printDryRun(os.Stdout, "CreateNamespace", req, nil)
+			}
+
+			res, err := client.CreateNamespace(cmd.Context(), req)
 			if err != nil {
 				return err
 			}
 
 			spinner.Stop()
+
+			switch {
+			case output == outputJSON:
+				return printJSON(os.Stdout, res.GetNamespace())
+			case quiet:
+				fmt.Println(res.GetNamespace().GetId())
+				return nil
+			}
+
 			fmt.Printf("successfully created namespace %s with id %s\n", res.GetNamespace().GetName(), res.GetNamespace().GetId())
 			return nil
 		},
@@ -89,23 +127,33 @@ func createNamespaceCommand(cliConfig *Config) *cli.Command {
 
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the namespace body file")
 	cmd.MarkFlagRequired("file")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON)
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only print the id of the created namespace")
+	cmd.MarkFlagsMutuallyExclusive("output", "quiet")
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func editNamespaceCommand(cliConfig *Config) *cli.Command {
-	var filePath string
+	var filePath, output string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "edit",
 		Short: "Edit a namespace",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield namespace edit <namespace-id> --file=<namespace-body>
+			$ shield namespace edit --file=<namespace-body>
+			$ shield namespace edit <namespace-id> --file=<namespace-body> --output=json
+			$ shield namespace edit <namespace-id> --file=<namespace-body> --dry-run
 		`),
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(namespaceOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -117,7 +165,8 @@ func editNamespaceCommand(cliConfig *Config) *cli.Command {
 
 			err := reqBody.ValidateAll()
 			if err != nil {
-				return err
+				spinner.Stop()
+				return reportValidationError(os.Stdout, output, err)
 			}
 
 			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
@@ -126,16 +175,37 @@ func editNamespaceCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			namespaceID := args[0]
-			res, err := client.UpdateNamespace(cmd.Context(), &shieldv1beta1.UpdateNamespaceRequest{
+			namespaceID, err := resolveID(cmd, client, spinner, args, "namespace", namespaceOptions)
+			if err != nil {
+				return err
+			}
+
+			req := &shieldv1beta1.UpdateNamespaceRequest{
 				Id:   namespaceID,
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "namespace", Field: "id", Value: namespaceID},
+				)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "UpdateNamespace", req, nil, refs...)
+			}
+
+			res, err := client.UpdateNamespace(cmd.Context(), req)
 			if err != nil {
 				return err
 			}
 
 			spinner.Stop()
+
+			if output == outputJSON {
+				return printJSON(os.Stdout, res.GetNamespace())
+			}
+
 			fmt.Printf("successfully edited namespace with id %s to id %s and name %s\n", namespaceID, res.GetNamespace().GetId(), res.GetNamespace().GetName())
 			return nil
 		},
@@ -143,21 +213,29 @@ func editNamespaceCommand(cliConfig *Config) *cli.Command {
 
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the namespace body file")
 	cmd.MarkFlagRequired("file")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON)
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func viewNamespaceCommand(cliConfig *Config) *cli.Command {
+	var output string
+
 	cmd := &cli.Command{
 		Use:   "view",
 		Short: "View a namespace",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield namespace view <namespace-id>
+			$ shield namespace view
+			$ shield namespace view <namespace-id> --output=json
 		`),
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(namespaceOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -168,7 +246,11 @@ func viewNamespaceCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			namespaceID := args[0]
+			namespaceID, err := resolveID(cmd, client, spinner, args, "namespace", namespaceOptions)
+			if err != nil {
+				return err
+			}
+
 			res, err := client.GetNamespace(cmd.Context(), &shieldv1beta1.GetNamespaceRequest{
 				Id: namespaceID,
 			})
@@ -182,6 +264,10 @@ func viewNamespaceCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, namespace)
+			}
+
 			report = append(report, []string{"ID", "NAME", "CREATED AT", "UPDATED AT"})
 			report = append(report, []string{
 				namespace.GetId(),
@@ -197,16 +283,21 @@ func viewNamespaceCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
 	return cmd
 }
 
 func listNamespaceCommand(cliConfig *Config) *cli.Command {
+	var output string
+
 	cmd := &cli.Command{
 		Use:   "list",
 		Short: "List all namespaces",
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield namespace list
+			$ shield namespace list --output=yaml
 		`),
 		Annotations: map[string]string{
 			"group": "core",
@@ -231,6 +322,10 @@ func listNamespaceCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, res)
+			}
+
 			fmt.Printf(" \nShowing %d namespaces\n \n", len(namespaces))
 
 			report = append(report, []string{"ID", "NAME", "CREATED AT", "UPDATED AT"})
@@ -248,5 +343,230 @@ func listNamespaceCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func policiesNamespaceCommand(cliConfig *Config) *cli.Command {
+	var output string
+
+	cmd := &cli.Command{
+		Use:   "policies",
+		Short: "List all policies of a namespace",
+		Args:  idArg,
+		Example: heredoc.Doc(`
+			$ shield namespace policies <namespace-id>
+			$ shield namespace policies
+			$ shield namespace policies <namespace-id> --output=json
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(namespaceOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			namespaceID, err := resolveID(cmd, client, spinner, args, "namespace", namespaceOptions)
+			if err != nil {
+				return err
+			}
+
+			req := &shieldv1beta1.ListPoliciesRequest{}
+			ctx, clientFilters := pushDownFilters(cmd.Context(), cmd, cliConfig, req, []listFilter{
+				{name: "namespace_id", value: namespaceID, match: func(item proto.Message, value string) bool {
+					return item.(*shieldv1beta1.Policy).GetNamespaceId() == value
+				}},
+			})
+
+			var header metadata.MD
+			res, err := client.ListPolicies(ctx, req, grpc.Header(&header))
+			if err != nil {
+				return err
+			}
+
+			res.Policies = filterItems(res.GetPolicies(), clientFilters)
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printMessage(os.Stdout, output, res)
+			}
+
+			printPolicies(res.GetPolicies(), denyPolicies(header), policyLabels(header))
+			return nil
+		},
+	}
+
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func validateNamespaceCommand() *cli.Command {
+	var configFile, filePath string
+	var skipDiff bool
+
+	cmd := &cli.Command{
+		Use:   "validate",
+		Short: "Validate the namespace configs against the authz schema",
+		Long: heredoc.Doc(`
+			Validate the namespace configs of a file, or of the yaml files of a directory,
+			along with the predefined namespaces without changing anything: the names
+			have to be valid in the spicedb schema, roles granted to users or groups and
+			permissions granted to roles and permissions which are defined.
+
+			The schema generated from the configs is then compared with the live schema
+			of the authz engine of the server config. The live schema has the custom roles of the
+			organizations and the grants of the policies created through the api as well,
+			they show up as lines only in the live schema.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield namespace validate --file=schema.yaml
+			$ shield namespace validate -f ./resources_config -c ./config.yaml
+			$ shield namespace validate -f schema.yaml --skip-diff
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "false",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			configured, err := readSchemaConfig(cmd.Context(), filePath)
+			if err != nil {
+				return err
+			}
+			configs := schema.WithPredefined(configured)
+
+			var invalid schema.ValidationError
+			if err := schema.Validate(configs); errors.As(err, &invalid) {
+				spinner.Stop()
+				for _, p := range invalid.Problems {
+					fmt.Println(p)
+				}
+				return fmt.Errorf("%s has %d problems", filePath, len(invalid.Problems))
+			} else if err != nil {
+				return err
+			}
+
+			if skipDiff {
+				if _, err := generatedSchema(configs); err != nil {
+					return err
+				}
+				spinner.Stop()
+				fmt.Printf("%s is valid, %d namespaces\n", filePath, len(configs))
+				return nil
+			}
+
+			diff, err := schemaDiff(cmd.Context(), configFile, configs, filePath)
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("%s is valid, %d namespaces\n", filePath, len(configs))
+			if diff == "" {
+				fmt.Println("no changes against the live schema")
+				return nil
+			}
+			fmt.Print(diff)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the namespace config file or directory")
+	cmd.Flags().BoolVar(&skipDiff, "skip-diff", false, "Only validate, without comparing with the live schema")
+	cmd.MarkFlagRequired("file")
+
 	return cmd
 }
+
+// readSchemaConfig reads the namespace configs the way the server reads its
+// resources config path, a single file is read on its own
+func readSchemaConfig(ctx context.Context, path string) (schema.NamespaceConfigMapType, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var bucket blob.Bucket
+	if info.IsDir() {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		if bucket, err = blob.NewStore(ctx, "file://"+abs, ""); err != nil {
+			return nil, err
+		}
+	} else {
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil, fmt.Errorf("%s should be a .yaml or .yml file", path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if bucket, err = blob.NewStore(ctx, "mem://", ""); err != nil {
+			return nil, err
+		}
+		if err := bucket.WriteAll(ctx, filepath.Base(path), content, nil); err != nil {
+			return nil, err
+		}
+	}
+	defer bucket.Close()
+
+	return blob.NewSchemaConfigRepository(bucket).GetSchema(ctx)
+}
+
+// generatedSchema returns the schema generated from the namespace configs,
+// normalized to be compared with the live one
+func generatedSchema(configs schema.NamespaceConfigMapType) (string, error) {
+	return schema_generator.Normalize(strings.Join(schema_generator.GenerateSchema(configs), "\n"))
+}
+
+// schemaDiff returns the unified diff of the live schema of the authz engine
+// of the server config and the schema generated from the namespace configs
+func schemaDiff(ctx context.Context, configFile string, configs schema.NamespaceConfigMapType, toFile string) (string, error) {
+	generated, err := generatedSchema(configs)
+	if err != nil {
+		return "", err
+	}
+	live, err := liveSchema(ctx, configFile)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(live),
+		B:        difflib.SplitLines(generated),
+		FromFile: "live",
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// liveSchema returns the schema the authz engine of the server config has,
+// normalized to be compared with a generated one
+func liveSchema(ctx context.Context, configFile string) (string, error) {
+	authz, cleanup, err := serverAuthzEngine(configFile)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	live, err := authz.policies.ReadSchema(ctx)
+	if err != nil {
+		return "", err
+	}
+	return schema_generator.Normalize(live)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/odpf/shield/cmd"
@@ -25,6 +26,24 @@ func TestClientNamespace(t *testing.T) {
 				subCommands: []string{"list"},
 				err:         cmd.ErrClientConfigHostNotFound,
 			},
+			{
+				name:        "`namespace` delete without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"delete", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`namespace` delete with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
+			{
+				name:        "`namespace` delete without force should throw error not confirmed",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test", "-H", "X-Shield-Email:admin@odpf.io"},
+				err:         fmt.Errorf("%w: pass --force to delete without a prompt", errors.New("operation cancelled")),
+			},
 			{
 				name:        "`namespace` list with host flag should pass",
 				want:        "",
@@ -43,6 +62,24 @@ func TestClientNamespace(t *testing.T) {
 				subCommands: []string{"create", "-h", "test"},
 				err:         errors.New("required flag(s) \"file\" not set"),
 			},
+			{
+				name:        "`namespace` create with both output and quiet flags should throw error",
+				want:        "",
+				subCommands: []string{"create", "-h", "test", "-f", "namespace.json", "-o", "json", "-q"},
+				err:         errors.New("if any flags in the group [output quiet] are set none of the others can be; [output quiet] were all set"),
+			},
+			{
+				name:        "`namespace` policies without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"policies", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`namespace` policies with host flag should pass",
+				want:        "",
+				subCommands: []string{"policies", "123", "-h", "test"},
+				err:         context.DeadlineExceeded,
+			},
 			{
 				name:        "`namespace` edit without host should throw error host not found",
 				want:        "",
@@ -67,6 +104,18 @@ func TestClientNamespace(t *testing.T) {
 				subCommands: []string{"view", "123", "-h", "test"},
 				err:         context.DeadlineExceeded,
 			},
+			{
+				name:        "`namespace` validate without file should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"validate"},
+				err:         errors.New("required flag(s) \"file\" not set"),
+			},
+			{
+				name:        "`namespace` validate without diff should not need a host",
+				want:        "",
+				subCommands: []string{"validate", "-f", "../internal/store/blob/testdata", "--skip-diff"},
+				err:         nil,
+			},
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
@@ -1,14 +1,24 @@
 package cmd
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"strconv"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/odpf/salt/printer"
 	"github.com/odpf/shield/pkg/file"
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
 	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func OrganizationCommand(cliConfig *Config) *cli.Command {
@@ -24,6 +34,9 @@ func OrganizationCommand(cliConfig *Config) *cli.Command {
 			$ shield organization edit
 			$ shield organization view
 			$ shield organization list
+			$ shield organization list --output=json --json-names=proto
+			$ shield organization tree
+			$ shield organization usage <organization-id> --since=30d
 		`),
 		Annotations: map[string]string{
 			"group":  "core",
@@ -35,9 +48,15 @@ func OrganizationCommand(cliConfig *Config) *cli.Command {
 	cmd.AddCommand(editOrganizationCommand(cliConfig))
 	cmd.AddCommand(viewOrganizationCommand(cliConfig))
 	cmd.AddCommand(listOrganizationCommand(cliConfig))
+	cmd.AddCommand(deleteCommand(cliConfig, "organization", organizationOptions))
+	cmd.AddCommand(restoreCommand(cliConfig, "organization"))
 	cmd.AddCommand(admaddOrganizationCommand(cliConfig))
 	cmd.AddCommand(admremoveOrganizationCommand(cliConfig))
 	cmd.AddCommand(admlistOrganizationCommand(cliConfig))
+	cmd.AddCommand(admexportOrganizationCommand(cliConfig))
+	cmd.AddCommand(treeOrganizationCommand(cliConfig))
+	cmd.AddCommand(usageOrganizationCommand(cliConfig))
+	cmd.AddCommand(templateCommand("organization", &shieldv1beta1.OrganizationRequestBody{}))
 
 	bindFlagsFromClientConfig(cmd)
 
@@ -45,7 +64,8 @@ func OrganizationCommand(cliConfig *Config) *cli.Command {
 }
 
 func createOrganizationCommand(cliConfig *Config) *cli.Command {
-	var filePath, header string
+	var filePath, header, output string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "create",
@@ -53,6 +73,9 @@ func createOrganizationCommand(cliConfig *Config) *cli.Command {
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield organization create --file=<organization-body> --header=<key>:<value>
+			$ shield organization create --file=<organization-body> --header=<key>:<value> --output=json
+			$ shield organization create --file=<organization-body> --header=<key>:<value> --dry-run
+			$ shield organization create --header=<key>:<value>
 		`),
 		Annotations: map[string]string{
 			"group": "core",
@@ -62,13 +85,31 @@ func createOrganizationCommand(cliConfig *Config) *cli.Command {
 			defer spinner.Stop()
 
 			var reqBody shieldv1beta1.OrganizationRequestBody
-			if err := file.Parse(filePath, &reqBody); err != nil {
+			if filePath == "" {
+				if !isInteractive(cmd) {
+					return errFileRequired
+				}
+				spinner.Stop()
+				if err := promptBody(&reqBody.Metadata,
+					promptField{Message: "Name", Value: &reqBody.Name, Required: true},
+					promptField{Message: "Slug", Value: &reqBody.Slug, Required: true},
+				); err != nil {
+					return err
+				}
+			} else if err := file.Parse(filePath, &reqBody); err != nil {
 				return err
 			}
 
 			err := reqBody.ValidateAll()
 			if err != nil {
-				return err
+				spinner.Stop()
+				return reportValidationError(os.Stdout, output, err)
+			}
+
+			if filePath == "" && !dryRun {
+				if err := confirmBody(cmd, &reqBody); err != nil {
+					return err
+				}
 			}
 
 			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
@@ -78,52 +119,96 @@ func createOrganizationCommand(cliConfig *Config) *cli.Command {
 			defer cancel()
 
 			ctx := setCtxHeader(cmd.Context(), header)
-			res, err := client.CreateOrganization(ctx, &shieldv1beta1.CreateOrganizationRequest{
+			req := &shieldv1beta1.CreateOrganizationRequest{
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				validated, err := validateOnServer(ctx, cmd, cliConfig, req, func(ctx context.Context, opts ...grpc.CallOption) (proto.Message, error) {
+					return client.CreateOrganization(ctx, req, opts...)
+				})
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "CreateOrganization", req, validated)
+			}
+
+			res, err := client.CreateOrganization(ctx, req)
 			if err != nil {
 				return err
 			}
 
 			spinner.Stop()
+
+			if output == outputJSON {
+				return printJSON(os.Stdout, res.GetOrganization())
+			}
+
 			fmt.Printf("successfully created organization %s with id %s\n", res.GetOrganization().GetName(), res.GetOrganization().GetId())
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the organization body file")
-	cmd.MarkFlagRequired("file")
-	cmd.Flags().StringVarP(&header, "header", "H", "", "Header <key>:<value>")
-	cmd.MarkFlagRequired("header")
+	bindFileFlag(cmd, &filePath, "Path to the organization body file, prompted for when omitted")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON)
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func editOrganizationCommand(cliConfig *Config) *cli.Command {
-	var filePath string
+	var filePath, output string
+	var clearMetadata, yes, dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "edit",
 		Short: "Edit an organization",
-		Args:  cli.ExactArgs(1),
+		Long: heredoc.Doc(`
+			Edit an organization.
+
+			The organization is replaced by the body read from --file, metadata included.
+			With --clear-metadata all the metadata keys are removed, name and slug are
+			taken from --file when given or kept as they are otherwise, any metadata in
+			the file is ignored.
+
+			When --file is omitted in an interactive session the name, slug and
+			metadata are prompted for, starting from their current values. When the
+			organization was updated by someone else in the meantime, the fields and
+			metadata keys that were edited are applied again to its latest version.
+		`),
+		Args: idArg,
 		Example: heredoc.Doc(`
 			$ shield organization edit <organization-id> --file=<organization-body>
+			$ shield organization edit --file=<organization-body>
+			$ shield organization edit <organization-id> --file=<organization-body> --output=json
+			$ shield organization edit <organization-id> --clear-metadata --yes
+			$ shield organization edit <organization-id> --file=<organization-body> --dry-run
+			$ shield organization edit <organization-id>
 		`),
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(organizationOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
-			var reqBody shieldv1beta1.OrganizationRequestBody
-			if err := file.Parse(filePath, &reqBody); err != nil {
-				return err
+			if filePath == "" && !clearMetadata && !isInteractive(cmd) {
+				return errFileRequired
 			}
 
-			err := reqBody.ValidateAll()
-			if err != nil {
-				return err
+			reqBody := &shieldv1beta1.OrganizationRequestBody{}
+			if filePath != "" {
+				if err := file.Parse(filePath, reqBody); err != nil {
+					return err
+				}
+
+				if err := reqBody.ValidateAll(); err != nil {
+					spinner.Stop()
+					return reportValidationError(os.Stdout, output, err)
+				}
 			}
 
 			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
@@ -132,40 +217,153 @@ func editOrganizationCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			organizationID := args[0]
-			_, err = client.UpdateOrganization(cmd.Context(), &shieldv1beta1.UpdateOrganizationRequest{
+			organizationID, err := resolveID(cmd, client, spinner, args, "organization", organizationOptions)
+			if err != nil {
+				return err
+			}
+
+			fetchOrganization := func(ctx context.Context) (*shieldv1beta1.OrganizationRequestBody, string, error) {
+				var header metadata.MD
+				orgRes, err := client.GetOrganization(ctx, &shieldv1beta1.GetOrganizationRequest{
+					Id: organizationID,
+				}, grpc.Header(&header))
+				if err != nil {
+					return nil, "", err
+				}
+				return &shieldv1beta1.OrganizationRequestBody{
+					Name:     orgRes.GetOrganization().GetName(),
+					Slug:     orgRes.GetOrganization().GetSlug(),
+					Metadata: orgRes.GetOrganization().GetMetadata(),
+				}, etagOf(header), nil
+			}
+
+			// original is the organization as fetched when the edit is made to
+			// it, the edit is applied again to the organization fetched anew
+			// when it was updated in between
+			var original *shieldv1beta1.OrganizationRequestBody
+			var etag string
+			if filePath == "" && !clearMetadata {
+				original, etag, err = fetchOrganization(cmd.Context())
+				if err != nil {
+					return err
+				}
+				reqBody = proto.Clone(original).(*shieldv1beta1.OrganizationRequestBody)
+
+				spinner.Stop()
+				if err := promptBody(&reqBody.Metadata,
+					promptField{Message: "Name", Value: &reqBody.Name, Required: true},
+					promptField{Message: "Slug", Value: &reqBody.Slug, Required: true},
+				); err != nil {
+					return err
+				}
+				if err := reqBody.ValidateAll(); err != nil {
+					return reportValidationError(os.Stdout, output, err)
+				}
+				if !dryRun {
+					if err := confirmBody(cmd, reqBody); err != nil {
+						return err
+					}
+				}
+			}
+
+			if clearMetadata {
+				if filePath == "" {
+					original, etag, err = fetchOrganization(cmd.Context())
+					if err != nil {
+						return err
+					}
+					reqBody.Name = original.GetName()
+					reqBody.Slug = original.GetSlug()
+				}
+				reqBody.Metadata = &structpb.Struct{}
+
+				if !yes && !dryRun {
+					spinner.Stop()
+					if err := confirm(cmd, fmt.Sprintf("Remove all metadata of organization %s?", organizationID)); err != nil {
+						return err
+					}
+				}
+			}
+
+			req := &shieldv1beta1.UpdateOrganizationRequest{
 				Id:   organizationID,
-				Body: &reqBody,
+				Body: reqBody,
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "organization", Field: "id", Value: organizationID},
+				)
+				if err != nil {
+					return err
+				}
+				validated, err := validateOnServer(cmd.Context(), cmd, cliConfig, req, func(ctx context.Context, opts ...grpc.CallOption) (proto.Message, error) {
+					return client.UpdateOrganization(ctx, req, opts...)
+				})
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "UpdateOrganization", req, validated, refs...)
+			}
+
+			var res *shieldv1beta1.UpdateOrganizationResponse
+			err = updateWithRetry(cmd.Context(), etag, func(ctx context.Context) error {
+				res, err = client.UpdateOrganization(ctx, req)
+				return err
+			}, func(ctx context.Context) (string, error) {
+				fresh, etag, err := fetchOrganization(ctx)
+				if err != nil {
+					return "", err
+				}
+				original, req.Body = fresh, reapplyEdit(original, req.Body, fresh)
+				if clearMetadata {
+					req.Body.Metadata = &structpb.Struct{}
+				}
+				return etag, nil
 			})
 			if err != nil {
 				return err
 			}
 
 			spinner.Stop()
+
+			if output == outputJSON {
+				return printJSON(os.Stdout, res.GetOrganization())
+			}
+
 			fmt.Printf("successfully edited organization with id %s\n", organizationID)
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the organization body file")
-	cmd.MarkFlagRequired("file")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the organization body file, prompted for when omitted")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON)
+	cmd.Flags().BoolVar(&clearMetadata, "clear-metadata", false, "Remove all the metadata of the organization")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt of --clear-metadata")
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func viewOrganizationCommand(cliConfig *Config) *cli.Command {
 	var metadata bool
+	var output string
 
 	cmd := &cli.Command{
 		Use:   "view",
 		Short: "View an organization",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield organization view <organization-id>
+			$ shield organization view <organization-slug>
+			$ shield organization view
+			$ shield organization view <organization-id> --output=json
 		`),
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(organizationOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -176,7 +374,11 @@ func viewOrganizationCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			organizationID := args[0]
+			organizationID, err := resolveID(cmd, client, spinner, args, "organization", organizationOptions)
+			if err != nil {
+				return err
+			}
+
 			res, err := client.GetOrganization(cmd.Context(), &shieldv1beta1.GetOrganizationRequest{
 				Id: organizationID,
 			})
@@ -190,6 +392,10 @@ func viewOrganizationCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, organization)
+			}
+
 			report = append(report, []string{"ID", "NAME", "SLUG"})
 			report = append(report, []string{
 				organization.GetId(),
@@ -220,17 +426,31 @@ func viewOrganizationCommand(cliConfig *Config) *cli.Command {
 	}
 
 	cmd.Flags().BoolVarP(&metadata, "metadata", "m", false, "Set this flag to see metadata")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
 
 	return cmd
 }
 
 func listOrganizationCommand(cliConfig *Config) *cli.Command {
+	var output, name, parentID, outFile string
+	var filterValues []string
+	var page pageFlags
+	var withDeleted bool
+
 	cmd := &cli.Command{
 		Use:   "list",
 		Short: "List all organizations",
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield organization list
+			$ shield organization list --output=json --json-names=proto
+			$ shield organization list --name=odpf
+			$ shield organization list --name=odpf --output=html --out-file=report.html
+			$ shield organization list --filter slug=odpf-* --filter metadata.team=data
+			$ shield organization list --parent-id=<organization-id>
+			$ shield organization list --page-size=20 --page-num=2
+			$ shield organization list --all
+			$ shield organization list --deleted
 		`),
 		Annotations: map[string]string{
 			"group": "core",
@@ -239,48 +459,115 @@ func listOrganizationCommand(cliConfig *Config) *cli.Command {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
-			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			filters, err := fieldFilters(filterValues, "name", "slug")
 			if err != nil {
 				return err
 			}
-			defer cancel()
 
-			res, err := client.ListOrganizations(cmd.Context(), &shieldv1beta1.ListOrganizationsRequest{})
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
 			if err != nil {
 				return err
 			}
+			defer cancel()
 
-			report := [][]string{}
+			req := &shieldv1beta1.ListOrganizationsRequest{}
+			deleted := deletedItems{}
+			ctx, clientFilters := pushDownFilters(includeDeleted(cmd.Context(), withDeleted), cmd, cliConfig, req, append([]listFilter{
+				{name: "name", value: name, match: func(item proto.Message, value string) bool {
+					return containsFold(item.(*shieldv1beta1.Organization).GetName(), value)
+				}},
+				{name: "parent_id", value: parentID, match: func(item proto.Message, value string) bool {
+					return orgParentID(item.(*shieldv1beta1.Organization)) == value
+				}},
+			}, filters...))
+
+			var res *shieldv1beta1.ListOrganizationsResponse
+			if page.all {
+				items, err := listAllPages(page.size, func(size, num int32) ([]*shieldv1beta1.Organization, error) {
+					var header metadata.MD
+					res, err := client.ListOrganizations(pageContext(ctx, size, num), req, grpc.Header(&header))
+					deleted.add(header)
+					return res.GetOrganizations(), err
+				})
+				if err != nil {
+					return err
+				}
+				res = &shieldv1beta1.ListOrganizationsResponse{Organizations: items}
+			} else {
+				var header metadata.MD
+				res, err = client.ListOrganizations(pageContext(ctx, page.size, page.num), req, grpc.Header(&header))
+				if err != nil {
+					return err
+				}
+				deleted.add(header)
+			}
+
+			res.Organizations = filterItems(res.GetOrganizations(), clientFilters)
 			organizations := res.GetOrganizations()
 
 			spinner.Stop()
 
-			if len(organizations) == 0 {
-				fmt.Printf("No organizations found.\n")
-				return nil
+			var w io.Writer = os.Stdout
+			if outFile != "" {
+				f, err := os.Create(outFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
 			}
 
-			fmt.Printf(" \nShowing %d organizations\n \n", len(organizations))
+			if output == outputJSON || output == outputYAML {
+				return printMessage(w, output, res)
+			}
 
-			report = append(report, []string{"ID", "NAME", "SLUG"})
+			header := []string{"ID", "NAME", "SLUG"}
+			if withDeleted {
+				header = append(header, "STATE")
+			}
+			rows := [][]string{}
 			for _, o := range organizations {
-				report = append(report, []string{
+				row := []string{
 					o.GetId(),
 					o.GetName(),
 					o.GetSlug(),
-				})
+				}
+				if withDeleted {
+					row = append(row, deleted.state(o.GetId()))
+				}
+				rows = append(rows, row)
 			}
-			printer.Table(os.Stdout, report)
+
+			if output == outputHTML {
+				return writeHTMLReport(w, "Organizations", header, rows)
+			}
+
+			if len(organizations) == 0 {
+				fmt.Fprintf(w, "No organizations found.\n")
+				return nil
+			}
+
+			fmt.Fprintf(w, " \nShowing %d organizations\n \n", len(organizations))
+			printer.Table(w, append([][]string{header}, rows...))
 
 			return nil
 		},
 	}
 
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML, outputHTML)
+	cmd.Flags().StringVar(&outFile, "out-file", "", "Write the output to the file instead of stdout")
+	cmd.Flags().StringVar(&name, "name", "", "Only list organizations whose name contains the value")
+	cmd.Flags().StringVar(&parentID, "parent-id", "", "Only list the direct children of the organization")
+	bindFieldFilterFlag(cmd, &filterValues, "name", "slug")
+	bindPageFlags(cmd, &page)
+	cmd.Flags().BoolVar(&withDeleted, "deleted", false, "List the deleted organizations along with the others")
+
 	return cmd
 }
 
 func admaddOrganizationCommand(cliConfig *Config) *cli.Command {
 	var filePath string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "admadd",
@@ -288,10 +575,12 @@ func admaddOrganizationCommand(cliConfig *Config) *cli.Command {
 		Args:  cli.ExactArgs(1),
 		Example: heredoc.Doc(`
 			$ shield organization admadd <organization-id> -file=<add-organization-admin-body>
+			$ shield organization admadd <organization-id> -file=<add-organization-admin-body> --dry-run
 		`),
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(organizationOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -313,10 +602,24 @@ func admaddOrganizationCommand(cliConfig *Config) *cli.Command {
 			defer cancel()
 
 			organizationID := args[0]
-			_, err = client.AddOrganizationAdmin(cmd.Context(), &shieldv1beta1.AddOrganizationAdminRequest{
+			req := &shieldv1beta1.AddOrganizationAdminRequest{
 				Id:   organizationID,
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				refs := []dryRunReference{{Resource: "organization", Field: "id", Value: organizationID}}
+				for _, userID := range reqBody.GetUserIds() {
+					refs = append(refs, dryRunReference{Resource: "user", Field: "user_ids", Value: userID})
+				}
+				refs, err := resolveReferences(cmd.Context(), client, refs...)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "AddOrganizationAdmin", req, nil, refs...)
+			}
+
+			_, err = client.AddOrganizationAdmin(cmd.Context(), req)
 			if err != nil {
 				return err
 			}
@@ -330,6 +633,8 @@ func admaddOrganizationCommand(cliConfig *Config) *cli.Command {
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the provider config")
 	cmd.MarkFlagRequired("file")
 
+	bindDryRunFlag(cmd, &dryRun)
+
 	return cmd
 }
 
@@ -346,6 +651,7 @@ func admremoveOrganizationCommand(cliConfig *Config) *cli.Command {
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(organizationOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -388,6 +694,7 @@ func admlistOrganizationCommand(cliConfig *Config) *cli.Command {
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(organizationOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -399,15 +706,12 @@ func admlistOrganizationCommand(cliConfig *Config) *cli.Command {
 			defer cancel()
 
 			organizationID := args[0]
-			res, err := client.ListOrganizationAdmins(cmd.Context(), &shieldv1beta1.ListOrganizationAdminsRequest{
-				Id: organizationID,
-			})
+			admins, err := listOrganizationAdmins(cmd.Context(), client, organizationID)
 			if err != nil {
 				return err
 			}
 
 			report := [][]string{}
-			admins := res.GetUsers()
 
 			spinner.Stop()
 
@@ -429,3 +733,256 @@ func admlistOrganizationCommand(cliConfig *Config) *cli.Command {
 
 	return cmd
 }
+
+func admexportOrganizationCommand(cliConfig *Config) *cli.Command {
+	var outFile string
+
+	cmd := &cli.Command{
+		Use:   "admexport",
+		Short: "export admins of an organization to a file",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield organization admexport <organization-id> --out=admins.csv
+			$ shield organization admexport <organization-id> --out=admins.json
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(organizationOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			organizationID := args[0]
+			admins, err := listOrganizationAdmins(cmd.Context(), client, organizationID)
+			if err != nil {
+				return err
+			}
+
+			if err := writeAdminExport(outFile, admins); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("successfully exported %d admins to %s\n", len(admins), outFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&outFile, "out", "", "Path to the export file, format is picked from the extension (csv or json)")
+	cmd.MarkFlagRequired("out")
+
+	return cmd
+}
+
+// listOrganizationAdmins fetches every admin of an organization.
+// ListOrganizationAdmins is not paginated yet so a single call
+// returns the complete set.
+func listOrganizationAdmins(ctx context.Context, client shieldv1beta1.ShieldServiceClient, organizationID string) ([]*shieldv1beta1.User, error) {
+	res, err := client.ListOrganizationAdmins(ctx, &shieldv1beta1.ListOrganizationAdminsRequest{
+		Id: organizationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.GetUsers(), nil
+}
+
+func treeOrganizationCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "tree [organization-id]",
+		Short: "Show the hierarchy of organizations",
+		Args:  cli.MaximumNArgs(1),
+		Example: heredoc.Doc(`
+			$ shield organization tree
+			$ shield organization tree <organization-id>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(organizationOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			res, err := client.ListOrganizations(cmd.Context(), &shieldv1beta1.ListOrganizationsRequest{})
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+
+			rootID := ""
+			if len(args) == 1 {
+				rootID = args[0]
+			}
+			return printOrganizationTree(os.Stdout, res.GetOrganizations(), rootID)
+		},
+	}
+
+	return cmd
+}
+
+// orgParentID is the id of the parent of the organization, the server
+// returns it in the metadata of the organization
+func orgParentID(org *shieldv1beta1.Organization) string {
+	return org.GetMetadata().GetFields()["parent_org_id"].GetStringValue()
+}
+
+// printOrganizationTree writes the organizations indented under their parent,
+// starting from the organization with rootID, its id or slug, or from every
+// organization without a parent when rootID is empty
+func printOrganizationTree(w io.Writer, orgs []*shieldv1beta1.Organization, rootID string) error {
+	children := map[string][]*shieldv1beta1.Organization{}
+	byID := map[string]*shieldv1beta1.Organization{}
+	for _, o := range orgs {
+		byID[o.GetId()] = o
+	}
+
+	var roots []*shieldv1beta1.Organization
+	for _, o := range orgs {
+		parentID := orgParentID(o)
+		if rootID != "" && (o.GetId() == rootID || o.GetSlug() == rootID) {
+			roots = append(roots, o)
+		}
+		if _, ok := byID[parentID]; ok {
+			children[parentID] = append(children[parentID], o)
+		} else if rootID == "" {
+			roots = append(roots, o)
+		}
+	}
+	if rootID != "" && len(roots) == 0 {
+		return fmt.Errorf("organization %s not found", rootID)
+	}
+
+	var printNode func(org *shieldv1beta1.Organization, prefix string, last bool, depth int)
+	visited := map[string]bool{}
+	printNode = func(org *shieldv1beta1.Organization, prefix string, last bool, depth int) {
+		branch, indent := "├── ", "│   "
+		if last {
+			branch, indent = "└── ", "    "
+		}
+		if depth == 0 {
+			branch, indent = "", ""
+		}
+		fmt.Fprintf(w, "%s%s%s (%s)\n", prefix, branch, org.GetName(), org.GetId())
+
+		if visited[org.GetId()] {
+			return
+		}
+		visited[org.GetId()] = true
+		kids := children[org.GetId()]
+		for i, child := range kids {
+			printNode(child, prefix+indent, i == len(kids)-1, depth+1)
+		}
+	}
+
+	for _, root := range roots {
+		printNode(root, "", true, 0)
+	}
+	return nil
+}
+
+// orgUsagePath serves the daily usage of an organization, it has no rpc of
+// its own
+const orgUsagePath = "/admin/v1beta1/organizations/usage"
+
+type orgUsageDay struct {
+	Day            string `json:"day,omitempty"`
+	APICalls       int64  `json:"api_calls"`
+	RelationWrites int64  `json:"relation_writes"`
+}
+
+type orgUsageResponse struct {
+	OrgID string        `json:"org_id"`
+	Total orgUsageDay   `json:"total"`
+	Days  []orgUsageDay `json:"days"`
+}
+
+func usageOrganizationCommand(cliConfig *Config) *cli.Command {
+	var header, since, output string
+
+	cmd := &cli.Command{
+		Use:   "usage <organization-id>",
+		Short: "Show the daily usage of an organization",
+		Long: heredoc.Doc(`
+			Show the requests made to the api in an organization and the relations written
+			on its objects every day, for chargeback and capacity planning. Days are in UTC
+			and only the users who can edit the organization can see its usage.
+
+			The requests made in an organization are the ones with its id as org_id in
+			their payload.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield organization usage <organization-id> --since=30d
+			$ shield organization usage <organization-slug> --since=2023-01-01 --output=csv
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(organizationOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res orgUsageResponse
+			if err := getAdminAPI(cmd.Context(), cliConfig, orgUsagePath, url.Values{
+				"org_id": {args[0]},
+				"since":  {since},
+			}, header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			return printOrgUsage(os.Stdout, output, res)
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVar(&since, "since", "30d", "First day of the usage, a number of days back like 30d, a duration like 72h or a date like 2023-01-02")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputCSV)
+
+	return cmd
+}
+
+func printOrgUsage(w io.Writer, output string, res orgUsageResponse) error {
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"day", "api_calls", "relation_writes"}); err != nil {
+			return err
+		}
+		for _, d := range res.Days {
+			if err := cw.Write([]string{d.Day, strconv.FormatInt(d.APICalls, 10), strconv.FormatInt(d.RelationWrites, 10)}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	report := [][]string{{"DAY", "API CALLS", "RELATION WRITES"}}
+	for _, d := range res.Days {
+		report = append(report, []string{d.Day, strconv.FormatInt(d.APICalls, 10), strconv.FormatInt(d.RelationWrites, 10)})
+	}
+	report = append(report, []string{"TOTAL", strconv.FormatInt(res.Total.APICalls, 10), strconv.FormatInt(res.Total.RelationWrites, 10)})
+	printer.Table(w, report)
+	return nil
+}
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/odpf/shield/cmd"
@@ -25,12 +28,72 @@ func TestClientOrganization(t *testing.T) {
 				subCommands: []string{"list"},
 				err:         cmd.ErrClientConfigHostNotFound,
 			},
+			{
+				name:        "`organization` delete without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"delete", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`organization` delete with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
+			{
+				name:        "`organization` restore without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"restore", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`organization` restore with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"restore", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
+			{
+				name:        "`organization` delete without force should throw error not confirmed",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test", "-H", "X-Shield-Email:admin@odpf.io"},
+				err:         fmt.Errorf("%w: pass --force to delete without a prompt", errors.New("operation cancelled")),
+			},
 			{
 				name:        "`organization` list with host flag should pass",
 				want:        "",
 				subCommands: []string{"list", "-h", "test"},
 				err:         context.DeadlineExceeded,
 			},
+			{
+				name:        "`organization` list with name filter should pass",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "--name", "odpf", "--verbose"},
+				err:         context.DeadlineExceeded,
+			},
+			{
+				name:        "`organization` list with unknown output format should throw error",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "-o", "jsom"},
+				err:         errors.New("unknown output format 'jsom'; valid: table, json, yaml, csv, tsv, jsonl, html"),
+			},
+			{
+				name:        "`organization` list with unsupported output format should throw error",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "-o", "tsv"},
+				err:         errors.New("output format 'tsv' is not supported by shield organization list; valid: table, json, yaml, html"),
+			},
+			{
+				name:        "`organization` list with invalid json-names should throw error",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "--json-names", "kebab"},
+				err:         errors.New("invalid json-names \"kebab\", valid values are camel, proto or original"),
+			},
+			{
+				name:        "`organization` admexport with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"admexport", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"out\" not set"),
+			},
 			{
 				name:        "`organization` create only should throw error host not found",
 				want:        "",
@@ -55,18 +118,60 @@ func TestClientOrganization(t *testing.T) {
 				subCommands: []string{"edit", "123", "-h", "test"},
 				err:         errors.New("required flag(s) \"file\" not set"),
 			},
+			{
+				name:        "`organization` edit with clear-metadata flag should not require file",
+				want:        "",
+				subCommands: []string{"edit", "123", "-h", "test", "--clear-metadata", "--yes"},
+				err:         context.DeadlineExceeded,
+			},
 			{
 				name:        "`organization` view without host should throw error host not found",
 				want:        "",
 				subCommands: []string{"view", "123"},
 				err:         cmd.ErrClientConfigHostNotFound,
 			},
+			{
+				name:        "`organization` view without id and with no-interactive flag should throw error",
+				want:        "",
+				subCommands: []string{"view", "-h", "test", "--no-interactive"},
+				err:         errors.New("accepts 1 arg(s), received 0"),
+			},
 			{
 				name:        "`organization` view with host flag should pass",
 				want:        "",
 				subCommands: []string{"view", "123", "-h", "test"},
 				err:         context.DeadlineExceeded,
 			},
+			{
+				name:        "`organization` list by parent with host flag should pass",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "--parent-id", "123"},
+				err:         context.DeadlineExceeded,
+			},
+			{
+				name:        "`organization` tree without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"tree"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`organization` tree with host flag should pass",
+				want:        "",
+				subCommands: []string{"tree", "123", "-h", "test"},
+				err:         context.DeadlineExceeded,
+			},
+			{
+				name:        "`organization` usage without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"usage", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`organization` usage with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"usage", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
@@ -86,4 +191,27 @@ func TestClientOrganization(t *testing.T) {
 			})
 		}
 	})
+	t.Run("with invalid organization body", func(t *testing.T) {
+		bodyFile := filepath.Join(t.TempDir(), "organization.json")
+		err := os.WriteFile(bodyFile, []byte(`{"name": "invalid name!"}`), 0600)
+		assert.NoError(t, err)
+
+		t.Run("should report validation failure in json output", func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+			cli.SetOutput(new(bytes.Buffer))
+			cli.SetArgs([]string{"organization", "create", "-f", bodyFile, "-H", "key:value", "-h", "test", "-o", "json"})
+
+			err := cli.Execute()
+			assert.ErrorIs(t, err, cmd.ErrValidationFailed)
+		})
+
+		t.Run("should return the validation error in table output", func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+			cli.SetOutput(new(bytes.Buffer))
+			cli.SetArgs([]string{"organization", "create", "-f", bodyFile, "-H", "key:value", "-h", "test"})
+
+			err := cli.Execute()
+			assert.ErrorContains(t, err, "invalid OrganizationRequestBody.Name")
+		})
+	})
 }
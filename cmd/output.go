@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputCSV   = "csv"
+	outputTSV   = "tsv"
+	outputJSONL = "jsonl"
+	outputHTML  = "html"
+
+	outputFormatsAnnotation = "output_formats"
+
+	jsonNamesCamel    = "camel"
+	jsonNamesProto    = "proto"
+	jsonNamesOriginal = "original"
+)
+
+// outputFormats are all the formats known to the CLI, each
+// command declares the subset it can render when binding --output
+var outputFormats = []string{outputTable, outputJSON, outputYAML, outputCSV, outputTSV, outputJSONL, outputHTML}
+
+// jsonMarshalOptions is shared by every command emitting JSON so that
+// the field naming convention is consistent across the whole CLI
+var jsonMarshalOptions = protojson.MarshalOptions{
+	Multiline:       true,
+	Indent:          "  ",
+	EmitUnpopulated: true,
+}
+
+func bindJSONNamesFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("json-names", jsonNamesCamel, "Field names in JSON output: camel, proto or original")
+}
+
+func applyJSONNamesFlag(cmd *cobra.Command) error {
+	names, err := cmd.Flags().GetString("json-names")
+	if err != nil {
+		return err
+	}
+
+	switch names {
+	case jsonNamesCamel:
+		jsonMarshalOptions.UseProtoNames = false
+	case jsonNamesProto, jsonNamesOriginal:
+		jsonMarshalOptions.UseProtoNames = true
+	default:
+		return fmt.Errorf("invalid json-names %q, valid values are %s, %s or %s", names, jsonNamesCamel, jsonNamesProto, jsonNamesOriginal)
+	}
+	return nil
+}
+
+func bindOutputFlag(cmd *cobra.Command, output *string, formats ...string) {
+	usage := "Output format: " + strings.Join(formats, ", ")
+	if n := len(formats); n > 1 {
+		usage = "Output format: " + strings.Join(formats[:n-1], ", ") + " or " + formats[n-1]
+	}
+
+	// commands that already use -o for another flag only get the long form
+	shorthand := "o"
+	if cmd.Flags().ShorthandLookup(shorthand) != nil {
+		shorthand = ""
+	}
+
+	cmd.Flags().StringVarP(output, "output", shorthand, formats[0], usage)
+	cmd.Flags().SetAnnotation("output", outputFormatsAnnotation, formats)
+}
+
+// validateOutputFlag checks the format passed to an --output flag bound with
+// bindOutputFlag is known to the CLI and can be rendered by the command
+func validateOutputFlag(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("output")
+	if flag == nil {
+		return nil
+	}
+	supported, ok := flag.Annotations[outputFormatsAnnotation]
+	if !ok {
+		return nil
+	}
+
+	format := flag.Value.String()
+	if !containsString(outputFormats, format) {
+		return fmt.Errorf("unknown output format '%s'; valid: %s", format, strings.Join(outputFormats, ", "))
+	}
+	if !containsString(supported, format) {
+		return fmt.Errorf("output format '%s' is not supported by %s; valid: %s", format, cmd.CommandPath(), strings.Join(supported, ", "))
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func printJSON(w io.Writer, m proto.Message) error {
+	b, err := jsonMarshalOptions.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func printYAML(w io.Writer, m proto.Message) error {
+	b, err := jsonMarshalOptions.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	y, err := yaml.JSONToYAML(b)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(y)
+	return err
+}
+
+// printMessage renders the raw response in one of the machine readable
+// formats, table output is left to the commands as the columns differ
+func printMessage(w io.Writer, output string, m proto.Message) error {
+	switch output {
+	case outputJSON:
+		return printJSON(w, m)
+	case outputYAML:
+		return printYAML(w, m)
+	default:
+		return fmt.Errorf("output format '%s' can't render a message", output)
+	}
+}
+
+// printRows renders the rows of a table report in one of the machine
+// readable formats, as a list of objects keyed by the snake cased headers
+// of the first row
+func printRows(w io.Writer, output string, report [][]string) error {
+	records := []map[string]string{}
+	if len(report) > 0 {
+		header := report[0]
+		for _, row := range report[1:] {
+			record := make(map[string]string, len(header))
+			for i, column := range header {
+				if i < len(row) {
+					record[strings.ReplaceAll(strings.ToLower(column), " ", "_")] = row[i]
+				}
+			}
+			records = append(records, record)
+		}
+	}
+
+	b, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case outputJSON:
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case outputYAML:
+		y, err := yaml.JSONToYAML(b)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(y)
+		return err
+	default:
+		return fmt.Errorf("output format '%s' can't render rows", output)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/odpf/shield/internal/api/v1beta1"
+	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+// defaultPageSize matches the page size the server falls back to
+// when a list request doesn't set one
+const defaultPageSize int32 = 50
+
+type pageFlags struct {
+	size int32
+	num  int32
+	all  bool
+}
+
+func bindPageFlags(cmd *cli.Command, page *pageFlags) {
+	cmd.Flags().Int32Var(&page.size, "page-size", 0, "Number of items per page, the server default is used when not set")
+	cmd.Flags().Int32Var(&page.num, "page-num", 1, "Page to list, starting from 1")
+	cmd.Flags().BoolVar(&page.all, "all", false, "List the items of every page")
+	cmd.MarkFlagsMutuallyExclusive("page-num", "all")
+}
+
+// listAllPages fetches page after page until the server returns
+// a page that isn't full, which means there is nothing left. A page
+// larger than the size means the server doesn't page the list and
+// returned all of it.
+func listAllPages[T any](size int32, list func(size, num int32) ([]T, error)) ([]T, error) {
+	if size < 1 {
+		size = defaultPageSize
+	}
+
+	var items []T
+	for num := int32(1); ; num++ {
+		page, err := list(size, num)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, page...)
+		if int32(len(page)) != size {
+			return items, nil
+		}
+	}
+}
+
+// pageContext sets the page on the headers of the list requests whose
+// messages have no page fields, the server lists everything without a size
+func pageContext(ctx context.Context, size, num int32) context.Context {
+	if size < 1 {
+		return ctx
+	}
+	if num < 1 {
+		num = 1
+	}
+	return metadata.AppendToOutgoingContext(ctx,
+		v1beta1.PageSizeHeader, strconv.Itoa(int(size)),
+		v1beta1.PageNumHeader, strconv.Itoa(int(num)))
+}
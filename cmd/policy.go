@@ -1,16 +1,28 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/internal/api/v1beta1"
+	"github.com/odpf/shield/internal/policytest"
 	"github.com/odpf/shield/pkg/file"
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
 	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 )
 
+var errInvalidEffect = fmt.Errorf("effect should be %s or %s", policy.EffectAllow, policy.EffectDeny)
+
 func PolicyCommand(cliConfig *Config) *cli.Command {
 	cmd := &cli.Command{
 		Use:     "policy",
@@ -24,6 +36,7 @@ func PolicyCommand(cliConfig *Config) *cli.Command {
 			$ shield policy edit
 			$ shield policy view
 			$ shield policy list
+			$ shield policy test -f scenarios.yaml
 		`),
 		Annotations: map[string]string{
 			"group":  "core",
@@ -35,6 +48,9 @@ func PolicyCommand(cliConfig *Config) *cli.Command {
 	cmd.AddCommand(editPolicyCommand(cliConfig))
 	cmd.AddCommand(viewPolicyCommand(cliConfig))
 	cmd.AddCommand(listPolicyCommand(cliConfig))
+	cmd.AddCommand(deleteCommand(cliConfig, "policy", policyOptions))
+	cmd.AddCommand(testPolicyCommand())
+	cmd.AddCommand(templateCommand("policy", &shieldv1beta1.PolicyRequestBody{}))
 
 	bindFlagsFromClientConfig(cmd)
 
@@ -42,7 +58,8 @@ func PolicyCommand(cliConfig *Config) *cli.Command {
 }
 
 func createPolicyCommand(cliConfig *Config) *cli.Command {
-	var filePath, header string
+	var filePath, header, effect, condition string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "create",
@@ -50,6 +67,10 @@ func createPolicyCommand(cliConfig *Config) *cli.Command {
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield policy create --file=<policy-body> --header=<key>:<value>
+			$ shield policy create --file=<policy-body> --header=<key>:<value> --effect=deny
+			$ shield policy create --file=<policy-body> --header=<key>:<value> --condition='ip.in_cidr("10.0.0.0/8")'
+			$ shield policy create --file=<policy-body> --header=<key>:<value> --name="payments deploy" --description="allow payments team to deploy"
+			$ shield policy create --file=<policy-body> --header=<key>:<value> --dry-run
 		`),
 		Annotations: map[string]string{
 			"policy:core": "true",
@@ -58,6 +79,10 @@ func createPolicyCommand(cliConfig *Config) *cli.Command {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
+			if err := validateEffect(effect); err != nil {
+				return err
+			}
+
 			var reqBody shieldv1beta1.PolicyRequestBody
 			if err := file.Parse(filePath, &reqBody); err != nil {
 				return err
@@ -74,10 +99,34 @@ func createPolicyCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			ctx := setCtxHeader(cmd.Context(), header)
-			_, err = client.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{
+			ctx := setEffectHeader(setCtxHeader(cmd.Context(), header), effect)
+			if condition != "" {
+				ctx = setConditionHeader(ctx, condition)
+			}
+			ctx = setLabelHeaders(ctx, cmd)
+			req := &shieldv1beta1.CreatePolicyRequest{
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "role", Field: "role_id", Value: reqBody.GetRoleId()},
+					dryRunReference{Resource: "action", Field: "action_id", Value: reqBody.GetActionId()},
+					dryRunReference{Resource: "namespace", Field: "namespace_id", Value: reqBody.GetNamespaceId()},
+				)
+				if err != nil {
+					return err
+				}
+				validated, err := validateOnServer(ctx, cmd, cliConfig, req, func(ctx context.Context, opts ...grpc.CallOption) (proto.Message, error) {
+					return client.CreatePolicy(ctx, req, opts...)
+				})
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "CreatePolicy", req, validated, refs...)
+			}
+
+			_, err = client.CreatePolicy(ctx, req)
 			if err != nil {
 				return err
 			}
@@ -90,29 +139,46 @@ func createPolicyCommand(cliConfig *Config) *cli.Command {
 
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the policy body file")
 	cmd.MarkFlagRequired("file")
-	cmd.Flags().StringVarP(&header, "header", "H", "", "Header <key>:<value>")
-	cmd.MarkFlagRequired("header")
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVar(&effect, "effect", "", "Effect of the policy, allow or deny (default allow)")
+	cmd.Flags().StringVar(&condition, "condition", "", "Condition on the context of the checks the policy applies to")
+	cmd.Flags().String("name", "", "Human readable name of the policy")
+	cmd.Flags().String("description", "", "Human readable description of the policy")
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func editPolicyCommand(cliConfig *Config) *cli.Command {
-	var filePath string
+	var filePath, effect, condition string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "edit",
 		Short: "Edit a policy",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield policy edit <policy-id> --file=<policy-body>
+			$ shield policy edit <policy-id> --file=<policy-body> --effect=allow
+			$ shield policy edit <policy-id> --file=<policy-body> --condition='resource["env"] == "dev"'
+			$ shield policy edit <policy-id> --file=<policy-body> --condition=""
+			$ shield policy edit <policy-id> --file=<policy-body> --name="payments deploy"
+			$ shield policy edit --file=<policy-body>
+			$ shield policy edit <policy-id> --file=<policy-body> --dry-run
 		`),
 		Annotations: map[string]string{
 			"policy:core": "true",
 		},
+		ValidArgsFunction: completeIDs(policyOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
+			if err := validateEffect(effect); err != nil {
+				return err
+			}
+
 			var reqBody shieldv1beta1.PolicyRequestBody
 			if err := file.Parse(filePath, &reqBody); err != nil {
 				return err
@@ -129,11 +195,41 @@ func editPolicyCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			policyID := args[0]
-			_, err = client.UpdatePolicy(cmd.Context(), &shieldv1beta1.UpdatePolicyRequest{
+			policyID, err := resolveID(cmd, client, spinner, args, "policy", policyOptions)
+			if err != nil {
+				return err
+			}
+
+			req := &shieldv1beta1.UpdatePolicyRequest{
 				Id:   policyID,
 				Body: &reqBody,
-			})
+			}
+			ctx := setEffectHeader(cmd.Context(), effect)
+			if cmd.Flags().Changed("condition") {
+				ctx = setConditionHeader(ctx, condition)
+			}
+			ctx = setLabelHeaders(ctx, cmd)
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "policy", Field: "id", Value: policyID},
+					dryRunReference{Resource: "role", Field: "role_id", Value: reqBody.GetRoleId()},
+					dryRunReference{Resource: "action", Field: "action_id", Value: reqBody.GetActionId()},
+					dryRunReference{Resource: "namespace", Field: "namespace_id", Value: reqBody.GetNamespaceId()},
+				)
+				if err != nil {
+					return err
+				}
+				validated, err := validateOnServer(ctx, cmd, cliConfig, req, func(ctx context.Context, opts ...grpc.CallOption) (proto.Message, error) {
+					return client.UpdatePolicy(ctx, req, opts...)
+				})
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "UpdatePolicy", req, validated, refs...)
+			}
+
+			_, err = client.UpdatePolicy(ctx, req)
 			if err != nil {
 				return err
 			}
@@ -146,21 +242,32 @@ func editPolicyCommand(cliConfig *Config) *cli.Command {
 
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the policy body file")
 	cmd.MarkFlagRequired("file")
+	cmd.Flags().StringVar(&effect, "effect", "", "Effect of the policy, allow or deny (default keeps the current effect)")
+	cmd.Flags().StringVar(&condition, "condition", "", "Condition on the context of the checks the policy applies to, empty to remove it (default keeps the current condition)")
+	cmd.Flags().String("name", "", "Human readable name of the policy (default keeps the current name)")
+	cmd.Flags().String("description", "", "Human readable description of the policy (default keeps the current description)")
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func viewPolicyCommand(cliConfig *Config) *cli.Command {
+	var output string
+
 	cmd := &cli.Command{
 		Use:   "view",
 		Short: "View a policy",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield policy view <policy-id>
+			$ shield policy view
+			$ shield policy view <policy-id> --output=json
 		`),
 		Annotations: map[string]string{
 			"policy:core": "true",
 		},
+		ValidArgsFunction: completeIDs(policyOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -171,10 +278,15 @@ func viewPolicyCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			policyID := args[0]
+			policyID, err := resolveID(cmd, client, spinner, args, "policy", policyOptions)
+			if err != nil {
+				return err
+			}
+
+			var header metadata.MD
 			res, err := client.GetPolicy(cmd.Context(), &shieldv1beta1.GetPolicyRequest{
 				Id: policyID,
-			})
+			}, grpc.Header(&header))
 			if err != nil {
 				return err
 			}
@@ -185,11 +297,25 @@ func viewPolicyCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
-			report = append(report, []string{"ID", "ACTION", "NAMESPACE"})
+			if output != outputTable {
+				return printMessage(os.Stdout, output, policy)
+			}
+
+			var condition string
+			if values := header.Get(v1beta1.PolicyConditionHeader); len(values) > 0 {
+				condition = values[0]
+			}
+
+			labels := policyLabels(header)[policy.GetId()]
+			report = append(report, []string{"ID", "NAME", "DESCRIPTION", "ACTION", "NAMESPACE", "EFFECT", "CONDITION"})
 			report = append(report, []string{
 				policy.GetId(),
+				labels.name,
+				labels.description,
 				policy.GetAction().GetId(),
 				policy.GetNamespace().GetId(),
+				denyPolicies(header).effect(policy.GetId()),
+				condition,
 			})
 			printer.Table(os.Stdout, report)
 
@@ -197,16 +323,21 @@ func viewPolicyCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
 	return cmd
 }
 
 func listPolicyCommand(cliConfig *Config) *cli.Command {
+	var output string
+
 	cmd := &cli.Command{
 		Use:   "list",
 		Short: "List all policies",
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield policy list
+			$ shield policy list --output=yaml
 		`),
 		Annotations: map[string]string{
 			"policy:core": "true",
@@ -221,36 +352,236 @@ func listPolicyCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			res, err := client.ListPolicies(cmd.Context(), &shieldv1beta1.ListPoliciesRequest{})
+			var header metadata.MD
+			res, err := client.ListPolicies(cmd.Context(), &shieldv1beta1.ListPoliciesRequest{}, grpc.Header(&header))
 			if err != nil {
 				return err
 			}
 
-			report := [][]string{}
-			policies := res.GetPolicies()
-
 			spinner.Stop()
 
-			if len(policies) == 0 {
-				fmt.Printf("No policies found.\n")
-				return nil
+			if output != outputTable {
+				return printMessage(os.Stdout, output, res)
 			}
 
-			fmt.Printf(" \nShowing %d policies\n \n", len(policies))
+			printPolicies(res.GetPolicies(), denyPolicies(header), policyLabels(header))
+			return nil
+		},
+	}
 
-			report = append(report, []string{"ID", "ACTION", "NAMESPACE"})
-			for _, p := range policies {
-				report = append(report, []string{
-					p.GetId(),
-					p.GetAction().GetId(),
-					p.GetNamespace().GetId(),
-				})
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func printPolicies(policies []*shieldv1beta1.Policy, denied denyPolicySet, labels map[string]policyLabel) {
+	if len(policies) == 0 {
+		fmt.Printf("No policies found.\n")
+		return
+	}
+
+	fmt.Printf(" \nShowing %d policies\n \n", len(policies))
+
+	report := [][]string{}
+	report = append(report, []string{"ID", "NAME", "ROLE", "ACTION", "NAMESPACE", "EFFECT"})
+	for _, p := range policies {
+		report = append(report, []string{
+			p.GetId(),
+			labels[p.GetId()].name,
+			p.GetRole().GetName(),
+			p.GetAction().GetName(),
+			p.GetNamespace().GetName(),
+			denied.effect(p.GetId()),
+		})
+	}
+	printer.Table(os.Stdout, report)
+}
+
+func validateEffect(effect string) error {
+	switch effect {
+	case "", policy.EffectAllow, policy.EffectDeny:
+		return nil
+	}
+	return errInvalidEffect
+}
+
+// setEffectHeader sends the effect of the policy along the request, the
+// policy messages have no field for it
+func setEffectHeader(ctx context.Context, effect string) context.Context {
+	if effect == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, v1beta1.PolicyEffectHeader, effect)
+}
+
+// setConditionHeader sends the condition of the policy along the request,
+// an empty condition removes the one of the policy edited
+func setConditionHeader(ctx context.Context, condition string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, v1beta1.PolicyConditionHeader, condition)
+}
+
+// setLabelHeaders sends the name and the description of the policy along
+// the request when their flags are set, percent-encoded as header values are
+// ascii
+func setLabelHeaders(ctx context.Context, cmd *cli.Command) context.Context {
+	for flag, header := range map[string]string{"name": v1beta1.PolicyNameHeader, "description": v1beta1.PolicyDescriptionHeader} {
+		if !cmd.Flags().Changed(flag) {
+			continue
+		}
+		value, _ := cmd.Flags().GetString(flag)
+		ctx = metadata.AppendToOutgoingContext(ctx, header, url.PathEscape(value))
+	}
+	return ctx
+}
+
+type policyLabel struct {
+	name        string
+	description string
+}
+
+// policyLabels reads the names and the descriptions of the policies the
+// server returned in the header of its response, by policy id
+func policyLabels(header metadata.MD) map[string]policyLabel {
+	labels := map[string]policyLabel{}
+	for _, value := range header.Get(v1beta1.PolicyLabelsHeader) {
+		for _, encoded := range strings.Split(value, ",") {
+			v, err := url.ParseQuery(encoded)
+			if err != nil {
+				continue
+			}
+			labels[v.Get("id")] = policyLabel{name: v.Get("name"), description: v.Get("description")}
+		}
+	}
+	return labels
+}
+
+// denyPolicySet has the ids of the deny policies the server returned in the
+// header of its response
+type denyPolicySet map[string]bool
+
+func denyPolicies(header metadata.MD) denyPolicySet {
+	denied := denyPolicySet{}
+	for _, value := range header.Get(v1beta1.DenyPoliciesHeader) {
+		for _, id := range strings.Split(value, ",") {
+			denied[id] = true
+		}
+	}
+	return denied
+}
+
+func (d denyPolicySet) effect(id string) string {
+	if d[id] {
+		return policy.EffectDeny
+	}
+	return policy.EffectAllow
+}
+
+func testPolicyCommand() *cli.Command {
+	var configFile, filePath, schemaFile string
+	var live bool
+
+	cmd := &cli.Command{
+		Use:   "test",
+		Short: "Run the authorization scenarios of a test file",
+		Long: heredoc.Doc(`
+			Run the scenarios of a test file, each gives relations and checks what
+			subjects are and aren't allowed with them:
+
+			  schema: schema.yaml
+			  relations:
+			    - shield/organization:odpf#owner@shield/user:alice
+			  scenarios:
+			    - name: viewers can't edit
+			      relations:
+			        - shield/project:payments#viewer@shield/user:bob
+			      checks:
+			        - subject: shield/user:bob
+			          resource: shield/project:payments
+			          permission: edit
+			          allowed: false
+
+			The relations are tuples of the authz schema as type:id#relation@type:id[#relation],
+			the ones at the top are given to every scenario. Checks can set the keys of the
+			request the conditions are evaluated against with context.
+
+			The scenarios run on an ephemeral embedded engine with the schema file of the
+			test file, or --schema, and the predefined namespaces. --live runs them against
+			the authz engine of the server config instead, the relations it doesn't have
+			are written for a scenario and deleted after it.
+
+			The command fails when a check doesn't get what it expects, to fail CI on
+			regressions of the authorization behavior.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield policy test --file=scenarios.yaml
+			$ shield policy test -f scenarios.yaml --schema=schema.yaml
+			$ shield policy test -f scenarios.yaml --live -c ./config.yaml
+		`),
+		Annotations: map[string]string{
+			"client": "false",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			f, err := policytest.Read(filePath)
+			if err != nil {
+				return err
+			}
+			if schemaFile != "" {
+				f.Schema, err = filepath.Abs(schemaFile)
+				if err != nil {
+					return err
+				}
+			}
+
+			var engine policytest.Engine
+			if live {
+				authz, cleanup, err := serverAuthzEngine(configFile)
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+				engine = authz.relations
+			} else {
+				if engine, err = policytest.EphemeralEngine(cmd.Context(), f); err != nil {
+					return err
+				}
 			}
-			printer.Table(os.Stdout, report)
 
+			results, err := policytest.Run(cmd.Context(), engine, f)
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			failed := 0
+			for _, r := range results {
+				switch {
+				case r.Err != nil:
+					fmt.Printf("FAIL %s: %s: %s\n", r.Scenario, r.Check, r.Err)
+				case !r.Passed():
+					fmt.Printf("FAIL %s: %s\n", r.Scenario, r.Check)
+				default:
+					fmt.Printf("PASS %s: %s\n", r.Scenario, r.Check)
+					continue
+				}
+				failed++
+			}
+			fmt.Printf(" \n%d checks, %d failed\n", len(results), failed)
+			if failed > 0 {
+				return fmt.Errorf("%d of %d checks failed", failed, len(results))
+			}
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the test file")
+	cmd.Flags().StringVar(&schemaFile, "schema", "", "Path to the schema file of the ephemeral engine, instead of the one of the test file")
+	cmd.Flags().BoolVar(&live, "live", false, "Run against the authz engine of the server config")
+	cmd.MarkFlagRequired("file")
+
 	return cmd
 }
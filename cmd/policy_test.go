@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/odpf/shield/cmd"
@@ -25,6 +26,24 @@ func TestClientPolicy(t *testing.T) {
 				subCommands: []string{"list"},
 				err:         cmd.ErrClientConfigHostNotFound,
 			},
+			{
+				name:        "`policy` delete without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"delete", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`policy` delete with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
+			{
+				name:        "`policy` delete without force should throw error not confirmed",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test", "-H", "X-Shield-Email:admin@odpf.io"},
+				err:         fmt.Errorf("%w: pass --force to delete without a prompt", errors.New("operation cancelled")),
+			},
 			{
 				name:        "`policy` list with host flag should pass",
 				want:        "",
@@ -43,6 +62,18 @@ func TestClientPolicy(t *testing.T) {
 				subCommands: []string{"create", "-h", "test"},
 				err:         errors.New("required flag(s) \"file\", \"header\" not set"),
 			},
+			{
+				name:        "`policy` create with dry run should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"create", "-h", "test", "--dry-run"},
+				err:         errors.New("required flag(s) \"file\", \"header\" not set"),
+			},
+			{
+				name:        "`policy` create with unknown effect should throw error",
+				want:        "",
+				subCommands: []string{"create", "-h", "test", "-f", "policy.yaml", "-H", "X-Shield-Email:admin@odpf.io", "--effect", "block"},
+				err:         errors.New("effect should be allow or deny"),
+			},
 			{
 				name:        "`policy` edit without host should throw error host not found",
 				want:        "",
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/spf13/cobra"
+)
+
+func bindProfileFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("profile-cpu", "", "Write a CPU profile of the command to the file")
+	cmd.PersistentFlags().String("profile-mem", "", "Write a memory profile to the file once the command exits")
+	cmd.PersistentFlags().MarkHidden("profile-cpu")
+	cmd.PersistentFlags().MarkHidden("profile-mem")
+}
+
+// startProfiling starts the profiles requested with the profile flags, they
+// are written once the command finishes whether it succeeded or not
+func startProfiling(cmd *cobra.Command) error {
+	cpuFile, _ := cmd.Flags().GetString("profile-cpu")
+	memFile, _ := cmd.Flags().GetString("profile-mem")
+	if cpuFile == "" && memFile == "" {
+		return nil
+	}
+
+	var cpuProfile *os.File
+	if cpuFile != "" {
+		f, err := os.Create(cpuFile)
+		if err != nil {
+			return fmt.Errorf("could not create cpu profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("could not start cpu profile: %w", err)
+		}
+		cpuProfile = f
+	}
+
+	cobra.OnFinalize(func() {
+		if cpuProfile != nil {
+			pprof.StopCPUProfile()
+			cpuProfile.Close()
+		}
+		if memFile != "" {
+			if err := writeMemProfile(memFile); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	})
+	return nil
+}
+
+func writeMemProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create memory profile: %w", err)
+	}
+	defer f.Close()
+
+	// get up-to-date statistics
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("could not write memory profile: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -9,6 +10,9 @@ import (
 	"github.com/odpf/shield/pkg/file"
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
 	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 )
 
 func ProjectCommand(cliConfig *Config) *cli.Command {
@@ -24,6 +28,9 @@ func ProjectCommand(cliConfig *Config) *cli.Command {
 			$ shield project edit
 			$ shield project view
 			$ shield project list
+			$ shield project admadd
+			$ shield project admremove
+			$ shield project admlist
 		`),
 		Annotations: map[string]string{
 			"group":  "core",
@@ -35,6 +42,12 @@ func ProjectCommand(cliConfig *Config) *cli.Command {
 	cmd.AddCommand(editProjectCommand(cliConfig))
 	cmd.AddCommand(viewProjectCommand(cliConfig))
 	cmd.AddCommand(listProjectCommand(cliConfig))
+	cmd.AddCommand(deleteCommand(cliConfig, "project", projectOptions))
+	cmd.AddCommand(restoreCommand(cliConfig, "project"))
+	cmd.AddCommand(admaddProjectCommand(cliConfig))
+	cmd.AddCommand(admremoveProjectCommand(cliConfig))
+	cmd.AddCommand(admlistProjectCommand(cliConfig))
+	cmd.AddCommand(templateCommand("project", &shieldv1beta1.ProjectRequestBody{}))
 
 	bindFlagsFromClientConfig(cmd)
 
@@ -43,6 +56,7 @@ func ProjectCommand(cliConfig *Config) *cli.Command {
 
 func createProjectCommand(cliConfig *Config) *cli.Command {
 	var filePath, header string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "create",
@@ -50,6 +64,8 @@ func createProjectCommand(cliConfig *Config) *cli.Command {
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield project create --file=<project-body> --header=<key>:<value>
+			$ shield project create --file=<project-body> --header=<key>:<value> --dry-run
+			$ shield project create --header=<key>:<value>
 		`),
 		Annotations: map[string]string{
 			"project:core": "true",
@@ -59,13 +75,16 @@ func createProjectCommand(cliConfig *Config) *cli.Command {
 			defer spinner.Stop()
 
 			var reqBody shieldv1beta1.ProjectRequestBody
-			if err := file.Parse(filePath, &reqBody); err != nil {
-				return err
-			}
+			if filePath != "" {
+				if err := file.Parse(filePath, &reqBody); err != nil {
+					return err
+				}
 
-			err := reqBody.ValidateAll()
-			if err != nil {
-				return err
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+			} else if !isInteractive(cmd) {
+				return errFileRequired
 			}
 
 			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
@@ -74,10 +93,52 @@ func createProjectCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
+			if filePath == "" {
+				orgID, err := resolveID(cmd, client, spinner, nil, "organization", organizationOptions)
+				if err != nil {
+					return err
+				}
+				reqBody.OrgId = orgID
+
+				spinner.Stop()
+				if err := promptBody(&reqBody.Metadata,
+					promptField{Message: "Name", Value: &reqBody.Name, Required: true},
+					promptField{Message: "Slug", Value: &reqBody.Slug, Required: true},
+				); err != nil {
+					return err
+				}
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+				if !dryRun {
+					if err := confirmBody(cmd, &reqBody); err != nil {
+						return err
+					}
+				}
+			}
+
 			ctx := setCtxHeader(cmd.Context(), header)
-			res, err := client.CreateProject(ctx, &shieldv1beta1.CreateProjectRequest{
+			req := &shieldv1beta1.CreateProjectRequest{
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "organization", Field: "org_id", Value: reqBody.GetOrgId()},
+				)
+				if err != nil {
+					return err
+				}
+				validated, err := validateOnServer(ctx, cmd, cliConfig, req, func(ctx context.Context, opts ...grpc.CallOption) (proto.Message, error) {
+					return client.CreateProject(ctx, req, opts...)
+				})
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "CreateProject", req, validated, refs...)
+			}
+
+			res, err := client.CreateProject(ctx, req)
 			if err != nil {
 				return err
 			}
@@ -88,51 +149,147 @@ func createProjectCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the project body file")
-	cmd.MarkFlagRequired("file")
-	cmd.Flags().StringVarP(&header, "header", "H", "", "Header <key>:<value>")
-	cmd.MarkFlagRequired("header")
+	bindFileFlag(cmd, &filePath, "Path to the project body file, prompted for when omitted")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func editProjectCommand(cliConfig *Config) *cli.Command {
 	var filePath string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "edit",
 		Short: "Edit a project",
-		Args:  cli.ExactArgs(1),
+		Long: heredoc.Doc(`
+			Edit a project.
+
+			The project is replaced by the body read from --file. When --file is omitted
+			in an interactive session the name, slug and metadata are prompted for,
+			starting from their current values. When the project was updated by someone
+			else in the meantime, the fields and metadata keys that were edited are
+			applied again to its latest version.
+		`),
+		Args: idArg,
 		Example: heredoc.Doc(`
 			$ shield project edit <project-id> --file=<project-body>
+			$ shield project edit --file=<project-body>
+			$ shield project edit <project-id> --file=<project-body> --dry-run
+			$ shield project edit <project-id>
 		`),
 		Annotations: map[string]string{
 			"project:core": "true",
 		},
+		ValidArgsFunction: completeIDs(projectOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
-			var reqBody shieldv1beta1.ProjectRequestBody
-			if err := file.Parse(filePath, &reqBody); err != nil {
-				return err
+			reqBody := &shieldv1beta1.ProjectRequestBody{}
+			if filePath != "" {
+				if err := file.Parse(filePath, reqBody); err != nil {
+					return err
+				}
+
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+			} else if !isInteractive(cmd) {
+				return errFileRequired
 			}
 
-			err := reqBody.ValidateAll()
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
 			if err != nil {
 				return err
 			}
+			defer cancel()
 
-			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			projectID, err := resolveID(cmd, client, spinner, args, "project", projectOptions)
 			if err != nil {
 				return err
 			}
-			defer cancel()
 
-			projectID := args[0]
-			_, err = client.UpdateProject(cmd.Context(), &shieldv1beta1.UpdateProjectRequest{
+			fetchProject := func(ctx context.Context) (*shieldv1beta1.ProjectRequestBody, string, error) {
+				var header metadata.MD
+				getRes, err := client.GetProject(ctx, &shieldv1beta1.GetProjectRequest{
+					Id: projectID,
+				}, grpc.Header(&header))
+				if err != nil {
+					return nil, "", err
+				}
+				current := getRes.GetProject()
+				return &shieldv1beta1.ProjectRequestBody{
+					Name:     current.GetName(),
+					Slug:     current.GetSlug(),
+					OrgId:    current.GetOrgId(),
+					Metadata: current.GetMetadata(),
+				}, etagOf(header), nil
+			}
+
+			// original is the project as fetched when the edit is prompted for, the
+			// edit is applied again to the project fetched anew when it was updated
+			// in between
+			var original *shieldv1beta1.ProjectRequestBody
+			var etag string
+			if filePath == "" {
+				original, etag, err = fetchProject(cmd.Context())
+				if err != nil {
+					return err
+				}
+				reqBody = proto.Clone(original).(*shieldv1beta1.ProjectRequestBody)
+
+				spinner.Stop()
+				if err := promptBody(&reqBody.Metadata,
+					promptField{Message: "Name", Value: &reqBody.Name, Required: true},
+					promptField{Message: "Slug", Value: &reqBody.Slug, Required: true},
+				); err != nil {
+					return err
+				}
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+				if !dryRun {
+					if err := confirmBody(cmd, reqBody); err != nil {
+						return err
+					}
+				}
+			}
+
+			req := &shieldv1beta1.UpdateProjectRequest{
 				Id:   projectID,
-				Body: &reqBody,
+				Body: reqBody,
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "project", Field: "id", Value: projectID},
+					dryRunReference{Resource: "organization", Field: "org_id", Value: reqBody.GetOrgId()},
+				)
+				if err != nil {
+					return err
+				}
+				validated, err := validateOnServer(cmd.Context(), cmd, cliConfig, req, func(ctx context.Context, opts ...grpc.CallOption) (proto.Message, error) {
+					return client.UpdateProject(ctx, req, opts...)
+				})
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "UpdateProject", req, validated, refs...)
+			}
+
+			err = updateWithRetry(cmd.Context(), etag, func(ctx context.Context) error {
+				_, err := client.UpdateProject(ctx, req)
+				return err
+			}, func(ctx context.Context) (string, error) {
+				fresh, etag, err := fetchProject(ctx)
+				if err != nil {
+					return "", err
+				}
+				original, req.Body = fresh, reapplyEdit(original, req.Body, fresh)
+				return etag, nil
 			})
 			if err != nil {
 				return err
@@ -144,25 +301,30 @@ func editProjectCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the project body file")
-	cmd.MarkFlagRequired("file")
+	bindFileFlag(cmd, &filePath, "Path to the project body file, prompted for when omitted")
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func viewProjectCommand(cliConfig *Config) *cli.Command {
 	var metadata bool
+	var output string
 
 	cmd := &cli.Command{
 		Use:   "view",
 		Short: "View a project",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield project view <project-id>
+			$ shield project view
+			$ shield project view <project-id> --output=json
 		`),
 		Annotations: map[string]string{
 			"project:core": "true",
 		},
+		ValidArgsFunction: completeIDs(projectOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -173,7 +335,11 @@ func viewProjectCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			projectID := args[0]
+			projectID, err := resolveID(cmd, client, spinner, args, "project", projectOptions)
+			if err != nil {
+				return err
+			}
+
 			res, err := client.GetProject(cmd.Context(), &shieldv1beta1.GetProjectRequest{
 				Id: projectID,
 			})
@@ -187,6 +353,10 @@ func viewProjectCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, project)
+			}
+
 			report = append(report, []string{"ID", "NAME", "SLUG", "ORG-ID"})
 			report = append(report, []string{
 				project.GetId(),
@@ -218,17 +388,29 @@ func viewProjectCommand(cliConfig *Config) *cli.Command {
 	}
 
 	cmd.Flags().BoolVarP(&metadata, "metadata", "m", false, "Set this flag to see metadata")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
 
 	return cmd
 }
 
 func listProjectCommand(cliConfig *Config) *cli.Command {
+	var org, output string
+	var filterValues []string
+	var page pageFlags
+	var withDeleted bool
+
 	cmd := &cli.Command{
 		Use:   "list",
 		Short: "List all projects",
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield project list
+			$ shield project list --org=<organization-id-or-slug>
+			$ shield project list --output=yaml
+			$ shield project list --filter slug=odpf-* --filter metadata.team=data
+			$ shield project list --page-size=20 --page-num=2
+			$ shield project list --all
+			$ shield project list --deleted
 		`),
 		Annotations: map[string]string{
 			"project:core": "true",
@@ -237,22 +419,62 @@ func listProjectCommand(cliConfig *Config) *cli.Command {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
-			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			filters, err := fieldFilters(filterValues, "name", "slug")
 			if err != nil {
 				return err
 			}
-			defer cancel()
 
-			res, err := client.ListProjects(cmd.Context(), &shieldv1beta1.ListProjectsRequest{})
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
 			if err != nil {
 				return err
 			}
+			defer cancel()
+
+			if org != "" {
+				orgID, err := lookupID(cmd.Context(), client, "organization", org)
+				if err != nil {
+					return err
+				}
+				filters = append(filters, listFilter{name: "org_id", value: orgID, match: func(item proto.Message, value string) bool {
+					return item.(*shieldv1beta1.Project).GetOrgId() == value
+				}})
+			}
+
+			req := &shieldv1beta1.ListProjectsRequest{}
+			deleted := deletedItems{}
+			ctx, clientFilters := pushDownFilters(includeDeleted(cmd.Context(), withDeleted), cmd, cliConfig, req, filters)
+
+			var res *shieldv1beta1.ListProjectsResponse
+			if page.all {
+				items, err := listAllPages(page.size, func(size, num int32) ([]*shieldv1beta1.Project, error) {
+					var header metadata.MD
+					res, err := client.ListProjects(pageContext(ctx, size, num), req, grpc.Header(&header))
+					deleted.add(header)
+					return res.GetProjects(), err
+				})
+				if err != nil {
+					return err
+				}
+				res = &shieldv1beta1.ListProjectsResponse{Projects: items}
+			} else {
+				var header metadata.MD
+				res, err = client.ListProjects(pageContext(ctx, page.size, page.num), req, grpc.Header(&header))
+				if err != nil {
+					return err
+				}
+				deleted.add(header)
+			}
 
 			report := [][]string{}
+			res.Projects = filterItems(res.GetProjects(), clientFilters)
 			projects := res.GetProjects()
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, res)
+			}
+
 			if len(projects) == 0 {
 				fmt.Printf("No projects found.\n")
 				return nil
@@ -260,13 +482,203 @@ func listProjectCommand(cliConfig *Config) *cli.Command {
 
 			fmt.Printf(" \nShowing %d project(s)\n \n", len(projects))
 
-			report = append(report, []string{"ID", "NAME", "SLUG", "ORG-ID"})
+			header := []string{"ID", "NAME", "SLUG", "ORG-ID"}
+			if withDeleted {
+				header = append(header, "STATE")
+			}
+			report = append(report, header)
 			for _, p := range projects {
-				report = append(report, []string{
+				row := []string{
 					p.GetId(),
 					p.GetName(),
 					p.GetSlug(),
 					p.GetOrgId(),
+				}
+				if withDeleted {
+					row = append(row, deleted.state(p.GetId()))
+				}
+				report = append(report, row)
+			}
+			printer.Table(os.Stdout, report)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&org, "org", "", "Only list projects of the organization with the id or slug")
+	bindFieldFilterFlag(cmd, &filterValues, "name", "slug")
+	bindPageFlags(cmd, &page)
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+	cmd.Flags().BoolVar(&withDeleted, "deleted", false, "List the deleted projects along with the others")
+
+	return cmd
+}
+
+func admaddProjectCommand(cliConfig *Config) *cli.Command {
+	var filePath, header string
+	var dryRun bool
+
+	cmd := &cli.Command{
+		Use:   "admadd",
+		Short: "add admins to a project",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield project admadd <project-id> --file=<add-project-admin-body> --header=<key>:<value>
+			$ shield project admadd <project-id> --file=<add-project-admin-body> --header=<key>:<value> --dry-run
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(projectOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var reqBody shieldv1beta1.AddProjectAdminRequestBody
+			if err := file.Parse(filePath, &reqBody); err != nil {
+				return err
+			}
+
+			err := reqBody.ValidateAll()
+			if err != nil {
+				return err
+			}
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			projectID := args[0]
+			req := &shieldv1beta1.AddProjectAdminRequest{
+				Id:   projectID,
+				Body: &reqBody,
+			}
+			if dryRun {
+				refs := []dryRunReference{{Resource: "project", Field: "id", Value: projectID}}
+				for _, userID := range reqBody.GetUserIds() {
+					refs = append(refs, dryRunReference{Resource: "user", Field: "user_ids", Value: userID})
+				}
+				refs, err := resolveReferences(cmd.Context(), client, refs...)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "AddProjectAdmin", req, nil, refs...)
+			}
+
+			ctx := setCtxHeader(cmd.Context(), header)
+			_, err = client.AddProjectAdmin(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Println("successfully added admin(s) to project")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the project admin body file")
+	cmd.MarkFlagRequired("file")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	bindDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
+
+func admremoveProjectCommand(cliConfig *Config) *cli.Command {
+	var userID, header string
+
+	cmd := &cli.Command{
+		Use:   "admremove",
+		Short: "remove an admin from a project",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield project admremove <project-id> --user=<user-id> --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(projectOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			projectID := args[0]
+			ctx := setCtxHeader(cmd.Context(), header)
+			_, err = client.RemoveProjectAdmin(ctx, &shieldv1beta1.RemoveProjectAdminRequest{
+				Id:     projectID,
+				UserId: userID,
+			})
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Println("successfully removed admin from project")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&userID, "user", "u", "", "Id of the user to be removed")
+	cmd.MarkFlagRequired("user")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func admlistProjectCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "admlist",
+		Short: "list admins of a project",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield project admlist <project-id>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(projectOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			projectID := args[0]
+			res, err := client.ListProjectAdmins(cmd.Context(), &shieldv1beta1.ListProjectAdminsRequest{
+				Id: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			admins := res.GetUsers()
+
+			spinner.Stop()
+
+			fmt.Printf(" \nShowing %d admins\n \n", len(admins))
+
+			report = append(report, []string{"ID", "NAME", "EMAIL"})
+			for _, a := range admins {
+				report = append(report, []string{
+					a.GetId(),
+					a.GetName(),
+					a.GetEmail(),
 				})
 			}
 			printer.Table(os.Stdout, report)
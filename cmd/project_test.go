@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/odpf/shield/cmd"
@@ -25,12 +26,54 @@ func TestClientProject(t *testing.T) {
 				subCommands: []string{"list"},
 				err:         cmd.ErrClientConfigHostNotFound,
 			},
+			{
+				name:        "`project` delete without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"delete", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`project` delete with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
+			{
+				name:        "`project` restore without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"restore", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`project` restore with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"restore", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
+			{
+				name:        "`project` delete without force should throw error not confirmed",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test", "-H", "X-Shield-Email:admin@odpf.io"},
+				err:         fmt.Errorf("%w: pass --force to delete without a prompt", errors.New("operation cancelled")),
+			},
 			{
 				name:        "`project` list with host flag should pass",
 				want:        "",
 				subCommands: []string{"list", "-h", "test"},
 				err:         context.DeadlineExceeded,
 			},
+			{
+				name:        "`project` list with yaml output should pass",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "-o", "yaml"},
+				err:         context.DeadlineExceeded,
+			},
+			{
+				name:        "`project` list with html output should throw error unsupported format",
+				want:        "",
+				subCommands: []string{"list", "-h", "test", "-o", "html"},
+				err:         errors.New("output format 'html' is not supported by shield project list; valid: table, json, yaml"),
+			},
 			{
 				name:        "`project` create only should throw error host not found",
 				want:        "",
@@ -43,6 +86,12 @@ func TestClientProject(t *testing.T) {
 				subCommands: []string{"create", "-h", "test"},
 				err:         errors.New("required flag(s) \"file\", \"header\" not set"),
 			},
+			{
+				name:        "`project` create with dry run should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"create", "-h", "test", "--dry-run"},
+				err:         errors.New("required flag(s) \"file\", \"header\" not set"),
+			},
 			{
 				name:        "`project` edit without host should throw error host not found",
 				want:        "",
@@ -67,6 +116,30 @@ func TestClientProject(t *testing.T) {
 				subCommands: []string{"view", "123", "-h", "test"},
 				err:         context.DeadlineExceeded,
 			},
+			{
+				name:        "`project` admadd without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"admadd", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`project` admadd with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"admadd", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"file\", \"header\" not set"),
+			},
+			{
+				name:        "`project` admremove with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"admremove", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\", \"user\" not set"),
+			},
+			{
+				name:        "`project` admlist with host flag should pass",
+				want:        "",
+				subCommands: []string{"admlist", "123", "-h", "test"},
+				err:         context.DeadlineExceeded,
+			},
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/odpf/salt/term"
+	cli "github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var errFileRequired = errors.New("required flag(s) \"file\" not set")
+
+// promptField is a string field of a request body asked for when
+// the body is prompted for instead of read from --file
+type promptField struct {
+	Message  string
+	Value    *string
+	Required bool
+}
+
+// bindFileFlag binds --file, it can only be omitted when the session
+// is interactive so the request body can be prompted for instead
+func bindFileFlag(cmd *cli.Command, filePath *string, usage string) {
+	cmd.Flags().StringVarP(filePath, "file", "f", "", usage)
+	if !term.IsTTY() || term.IsCI() {
+		cmd.MarkFlagRequired("file")
+	}
+}
+
+// promptBody asks for the fields and the metadata of a request body, the
+// current values of the fields and the metadata are offered as defaults
+func promptBody(metadata **structpb.Struct, fields ...promptField) error {
+	for _, f := range fields {
+		var opts []survey.AskOpt
+		if f.Required {
+			opts = append(opts, survey.WithValidator(survey.Required))
+		}
+		if err := survey.AskOne(&survey.Input{Message: f.Message + ":", Default: *f.Value}, f.Value, opts...); err != nil {
+			return fmt.Errorf("could not prompt: %w", err)
+		}
+	}
+
+	values := (*metadata).AsMap()
+	if len(values) > 0 {
+		fmt.Println("Metadata keys are kept unless set again, an empty value removes the key.")
+	}
+	for {
+		var key, value string
+		if err := survey.AskOne(&survey.Input{Message: "Metadata key (empty to finish):"}, &key); err != nil {
+			return fmt.Errorf("could not prompt: %w", err)
+		}
+		if key == "" {
+			break
+		}
+
+		current := ""
+		if v, ok := values[key]; ok {
+			current = fmt.Sprint(v)
+		}
+		if err := survey.AskOne(&survey.Input{Message: fmt.Sprintf("Value of %s:", key), Default: current}, &value); err != nil {
+			return fmt.Errorf("could not prompt: %w", err)
+		}
+		if value == "" {
+			delete(values, key)
+			continue
+		}
+		values[key] = value
+	}
+
+	s, err := structpb.NewStruct(values)
+	if err != nil {
+		return err
+	}
+	*metadata = s
+	return nil
+}
+
+// confirmBody shows the prompted request body and asks the user
+// to confirm it before it is sent
+func confirmBody(cmd *cli.Command, body proto.Message) error {
+	fmt.Println()
+	if err := printYAML(os.Stdout, body); err != nil {
+		return err
+	}
+	fmt.Println()
+	return confirm(cmd, "Send the request with this body?")
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	cli "github.com/spf13/cobra"
+)
+
+// the status of the rules of the proxies, reloading them and the circuit
+// breakers of their backends have no rpcs of their own
+const (
+	proxyRulesPath       = "/admin/v1beta1/proxy/rules"
+	proxyRulesReloadPath = "/admin/v1beta1/proxy/rules/reload"
+	proxyBreakersPath    = "/admin/v1beta1/proxy/breakers"
+)
+
+type proxyRulesStatus struct {
+	Name     string     `json:"name"`
+	Version  int64      `json:"version"`
+	Checksum string     `json:"checksum"`
+	LoadedAt time.Time  `json:"loaded_at"`
+	Rulesets int        `json:"rulesets"`
+	Rules    int        `json:"rules"`
+	Error    string     `json:"error,omitempty"`
+	FailedAt *time.Time `json:"failed_at,omitempty"`
+}
+
+type proxyRulesResponse struct {
+	Proxies []proxyRulesStatus `json:"proxies"`
+}
+
+type proxyBreakers struct {
+	Name     string `json:"name"`
+	Backends []struct {
+		Backend  string     `json:"backend"`
+		State    string     `json:"state"`
+		Failures int        `json:"failures"`
+		OpenedAt *time.Time `json:"opened_at,omitempty"`
+	} `json:"backends"`
+}
+
+type proxyBreakersResponse struct {
+	Proxies []proxyBreakers `json:"proxies"`
+}
+
+func ProxyCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "proxy",
+		Short: "Manage the rules and the backends of the proxies",
+		Long: heredoc.Doc(`
+			Work with the proxies served by the server.
+		`),
+		Example: heredoc.Doc(`
+			$ shield proxy rules status
+			$ shield proxy rules reload --proxy=<proxy-name>
+			$ shield proxy breakers
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+	}
+
+	cmd.AddCommand(rulesProxyCommand(cliConfig))
+	cmd.AddCommand(breakersProxyCommand(cliConfig))
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func rulesProxyCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "rules",
+		Short: "Show and reload the rules of the proxies",
+		Long: heredoc.Doc(`
+			The proxies load their rule files on start, refresh them every couple of minutes
+			and reload local rule files as soon as they change. Rule files are validated
+			before they are used, a proxy keeps serving with the rules it had when one of
+			the files is invalid.
+		`),
+	}
+
+	cmd.AddCommand(statusRulesProxyCommand(cliConfig))
+	cmd.AddCommand(reloadRulesProxyCommand(cliConfig))
+
+	return cmd
+}
+
+func statusRulesProxyCommand(cliConfig *Config) *cli.Command {
+	var header, proxy, output string
+
+	cmd := &cli.Command{
+		Use:   "status",
+		Short: "Show the version and the checksum of the rules the proxies serve with",
+		Long: heredoc.Doc(`
+			Show the version and the checksum of the rules the proxies serve with, the
+			version goes up every time rule files with a different checksum are loaded.
+			The error of the last reload is shown when the rule files were rejected.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield proxy rules status --header=<key>:<value>
+			$ shield proxy rules status --proxy=<proxy-name> --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res proxyRulesResponse
+			if err := getAdminAPI(cmd.Context(), cliConfig, proxyRulesPath, proxyQuery(proxy), header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			return printProxyRules(os.Stdout, output, res)
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVar(&proxy, "proxy", "", "Name of the proxy, all the proxies when not set")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON)
+
+	return cmd
+}
+
+func reloadRulesProxyCommand(cliConfig *Config) *cli.Command {
+	var header, proxy, output string
+
+	cmd := &cli.Command{
+		Use:   "reload",
+		Short: "Reload the rule files of the proxies",
+		Long: heredoc.Doc(`
+			Reload the rule files of the proxies right away instead of waiting for their
+			refresh, like after updating the files of a bucket. The command fails when the
+			rule files are invalid, the proxies then keep serving with the rules they had.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield proxy rules reload --header=<key>:<value>
+			$ shield proxy rules reload --proxy=<proxy-name>
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res proxyRulesResponse
+			if err := postAdminAPI(cmd.Context(), cliConfig, proxyRulesReloadPath, proxyQuery(proxy), header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			return printProxyRules(os.Stdout, output, res)
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVar(&proxy, "proxy", "", "Name of the proxy, all the proxies when not set")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON)
+
+	return cmd
+}
+
+func breakersProxyCommand(cliConfig *Config) *cli.Command {
+	var header, proxy, output string
+
+	cmd := &cli.Command{
+		Use:   "breakers",
+		Short: "Show the circuit breakers of the backends of the proxies",
+		Long: heredoc.Doc(`
+			Show the state of the circuit breakers of the backends the proxies sent requests
+			to. A breaker opens after the number of failures in a row of the policy of its
+			backend, the proxy then rejects the requests to the backend until it cooled
+			down and lets one request through to probe it, the breaker is half open until
+			the probe succeeds.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield proxy breakers --header=<key>:<value>
+			$ shield proxy breakers --proxy=<proxy-name> --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res proxyBreakersResponse
+			if err := getAdminAPI(cmd.Context(), cliConfig, proxyBreakersPath, proxyQuery(proxy), header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			return printProxyBreakers(os.Stdout, output, res)
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVar(&proxy, "proxy", "", "Name of the proxy, all the proxies when not set")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON)
+
+	return cmd
+}
+
+func proxyQuery(proxy string) url.Values {
+	query := url.Values{}
+	if proxy != "" {
+		query.Set("proxy", proxy)
+	}
+	return query
+}
+
+func printProxyRules(w io.Writer, output string, res proxyRulesResponse) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	report := [][]string{{"PROXY", "VERSION", "CHECKSUM", "RULES", "LOADED AT", "ERROR"}}
+	for _, p := range res.Proxies {
+		report = append(report, []string{
+			p.Name,
+			strconv.FormatInt(p.Version, 10),
+			p.Checksum,
+			fmt.Sprintf("%d in %d files", p.Rules, p.Rulesets),
+			p.LoadedAt.Format(time.RFC3339),
+			p.Error,
+		})
+	}
+	printer.Table(w, report)
+	return nil
+}
+
+func printProxyBreakers(w io.Writer, output string, res proxyBreakersResponse) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	report := [][]string{{"PROXY", "BACKEND", "STATE", "FAILURES", "OPENED AT"}}
+	for _, p := range res.Proxies {
+		for _, b := range p.Backends {
+			openedAt := ""
+			if b.OpenedAt != nil {
+				openedAt = b.OpenedAt.Format(time.RFC3339)
+			}
+			report = append(report, []string{p.Name, b.Backend, b.State, strconv.Itoa(b.Failures), openedAt})
+		}
+	}
+	printer.Table(w, report)
+	return nil
+}
@@ -0,0 +1,71 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientProxy(t *testing.T) {
+	tests := []struct {
+		name        string
+		subCommands []string
+		want        string
+		err         error
+	}{
+		{
+			name:        "`proxy` rules status only should throw error host not found",
+			want:        "",
+			subCommands: []string{"rules", "status"},
+			err:         cmd.ErrClientConfigHostNotFound,
+		},
+		{
+			name:        "`proxy` rules status with host flag should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"rules", "status", "-h", "test"},
+			err:         errors.New("required flag(s) \"header\" not set"),
+		},
+		{
+			name:        "`proxy` rules reload only should throw error host not found",
+			want:        "",
+			subCommands: []string{"rules", "reload"},
+			err:         cmd.ErrClientConfigHostNotFound,
+		},
+		{
+			name:        "`proxy` rules reload with host flag should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"rules", "reload", "-h", "test"},
+			err:         errors.New("required flag(s) \"header\" not set"),
+		},
+		{
+			name:        "`proxy` breakers only should throw error host not found",
+			want:        "",
+			subCommands: []string{"breakers"},
+			err:         cmd.ErrClientConfigHostNotFound,
+		},
+		{
+			name:        "`proxy` breakers with host flag should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"breakers", "-h", "test"},
+			err:         errors.New("required flag(s) \"header\" not set"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+
+			buf := new(bytes.Buffer)
+			cli.SetOutput(buf)
+			cli.SetArgs(append([]string{"proxy"}, tt.subCommands...))
+
+			err := cli.Execute()
+			got := buf.String()
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
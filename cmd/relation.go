@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/store/spicedb"
+	cli "github.com/spf13/cobra"
+)
+
+func RelationCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:     "relation",
+		Aliases: []string{"relations"},
+		Short:   "Manage relation tuples",
+		Long: heredoc.Doc(`
+			Export and import the relation tuples of the authz store.
+
+			The commands connect to the authz engine of the server config, spicedb, the
+			embedded one or openfga, tuples are written as one json object per line so a dump of one
+			cluster can be imported into another.
+		`),
+		Example: heredoc.Doc(`
+			$ shield relation export --namespace=shield/project -o tuples.json
+			$ shield relation import -f tuples.json -c ./config.yaml
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(exportRelationCommand())
+	cmd.AddCommand(importRelationCommand())
+
+	return cmd
+}
+
+func exportRelationCommand() *cli.Command {
+	var configFile, namespace, outFile string
+
+	cmd := &cli.Command{
+		Use:   "export",
+		Short: "Export the relation tuples of a namespace",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield relation export --namespace=shield/project --output=tuples.json
+			$ shield relation export -n shield/organization -c ./config.yaml > tuples.json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			authz, cleanup, err := serverAuthzEngine(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			repository := authz.relations
+
+			var w io.Writer = os.Stdout
+			if outFile != "" {
+				f, err := os.Create(outFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			buf := bufio.NewWriter(w)
+			enc := json.NewEncoder(buf)
+			count := 0
+			if err := repository.Export(cmd.Context(), namespace, func(t relation.Tuple) error {
+				count++
+				return enc.Encode(t)
+			}); err != nil {
+				return err
+			}
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+
+			if outFile != "" {
+				fmt.Printf("exported %d tuples to %s\n", count, outFile)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace of the objects whose tuples are exported")
+	cmd.MarkFlagRequired("namespace")
+	cmd.Flags().StringVarP(&outFile, "output", "o", "", "Path of the file the tuples are written to, stdout if not set")
+
+	return cmd
+}
+
+func importRelationCommand() *cli.Command {
+	var configFile, filePath string
+
+	cmd := &cli.Command{
+		Use:   "import",
+		Short: "Import relation tuples",
+		Long: heredoc.Doc(`
+			Import the relation tuples of an export, tuples which already exist are left
+			as they are so an import can be run again after a failure.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield relation import --file=tuples.json
+			$ shield relation import -f tuples.json -c ./config.yaml
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			f, err := os.Open(filePath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			authz, cleanup, err := serverAuthzEngine(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			repository := authz.relations
+
+			count := 0
+			dec := json.NewDecoder(bufio.NewReader(f))
+			batch := make([]relation.Tuple, 0, spicedb.MaxImportBatch)
+			for {
+				var t relation.Tuple
+				err := dec.Decode(&t)
+				if err != nil && !errors.Is(err, io.EOF) {
+					return fmt.Errorf("could not read tuple %d: %w", count+len(batch)+1, err)
+				}
+				if err == nil {
+					batch = append(batch, t)
+				}
+
+				if len(batch) == spicedb.MaxImportBatch || (errors.Is(err, io.EOF) && len(batch) > 0) {
+					if err := repository.Import(cmd.Context(), batch); err != nil {
+						return fmt.Errorf("imported %d tuples: %w", count, err)
+					}
+					count += len(batch)
+					batch = batch[:0]
+				}
+				if errors.Is(err, io.EOF) {
+					break
+				}
+			}
+
+			spinner.Stop()
+			fmt.Printf("imported %d tuples from %s\n", count, filePath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the file of tuples")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
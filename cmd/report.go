@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+// htmlReportTemplate renders a self contained document, styles are
+// inlined so the report can be shared as a single file
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+  h1 { font-size: 1.5rem; margin-bottom: 0.25rem; }
+  p.meta { color: #656d76; margin-top: 0; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { border: 1px solid #d0d7de; padding: 0.5rem 0.75rem; text-align: left; }
+  th { background: #f6f8fa; }
+  tr:nth-child(even) td { background: #fbfcfd; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p class="meta">{{ len .Rows }} rows, generated at {{ .GeneratedAt.Format "2006-01-02 15:04:05 MST" }}</p>
+<table>
+  <thead>
+    <tr>{{ range .Header }}<th>{{ . }}</th>{{ end }}</tr>
+  </thead>
+  <tbody>
+{{- range .Rows }}
+    <tr>{{ range . }}<td>{{ . }}</td>{{ end }}</tr>
+{{- end }}
+  </tbody>
+</table>
+</body>
+</html>
+`))
+
+type htmlReport struct {
+	Title       string
+	Header      []string
+	Rows        [][]string
+	GeneratedAt time.Time
+}
+
+// writeHTMLReport writes the rows as a styled html table, values are
+// escaped by the template so the report is safe to open in a browser
+func writeHTMLReport(w io.Writer, title string, header []string, rows [][]string) error {
+	return htmlReportTemplate.Execute(w, htmlReport{
+		Title:       title,
+		Header:      header,
+		Rows:        rows,
+		GeneratedAt: time.Now(),
+	})
+}
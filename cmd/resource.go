@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/relation"
+	cli "github.com/spf13/cobra"
+)
+
+// the expansion of the access to a resource and the checks of many
+// permissions at once have no rpcs of their own
+const (
+	accessExpandPath = "/admin/v1beta1/access/expand"
+	accessCheckPath  = "/admin/v1beta1/access/check"
+)
+
+// maxAccessChecks is the most checks the server makes in one request
+const maxAccessChecks = 100
+
+type accessCheckResponse struct {
+	Namespace  string `json:"namespace"`
+	ObjectID   string `json:"object_id"`
+	Permission string `json:"permission"`
+	Allowed    bool   `json:"allowed"`
+}
+
+type accessTreeResponse struct {
+	Object struct {
+		ID        string `json:"id"`
+		Namespace string `json:"namespace"`
+	} `json:"object"`
+	Relation  string `json:"relation"`
+	Operation string `json:"operation"`
+	Subjects  []struct {
+		ID        string `json:"id"`
+		Namespace string `json:"namespace"`
+		Relation  string `json:"relation"`
+	} `json:"subjects"`
+	Children []accessTreeResponse `json:"children"`
+}
+
+func (r accessTreeResponse) accessTree() relation.AccessTree {
+	tree := relation.AccessTree{
+		Object:    relation.Object{ID: r.Object.ID, NamespaceID: r.Object.Namespace},
+		Relation:  r.Relation,
+		Operation: r.Operation,
+	}
+	for _, sub := range r.Subjects {
+		tree.Subjects = append(tree.Subjects, relation.Subject{ID: sub.ID, Namespace: sub.Namespace, RoleID: sub.Relation})
+	}
+	for _, child := range r.Children {
+		tree.Children = append(tree.Children, child.accessTree())
+	}
+	return tree
+}
+
+func ResourceCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:     "resource",
+		Aliases: []string{"resources"},
+		Short:   "Manage resources",
+		Long: heredoc.Doc(`
+			Work with resources.
+		`),
+		Example: heredoc.Doc(`
+			$ shield resource who-can
+			$ shield resource check
+			$ shield resource tag-policy list
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+	}
+
+	cmd.AddCommand(whoCanResourceCommand(cliConfig))
+	cmd.AddCommand(checkResourceCommand(cliConfig))
+	cmd.AddCommand(tagPolicyResourceCommand())
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func checkResourceCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "check <namespace>:<object-id>#<permission>...",
+		Short: "Check the permissions of the user on resources",
+		Long: heredoc.Docf(`
+			Check the permissions of the logged in user or the user of the header on
+			up to %d resources at once, a resource that doesn't exist is denied.
+
+			Objects of the shield namespaces are referred to by id and the other resources
+			by name, the way permission checks refer to them.
+		`, maxAccessChecks),
+		Args: cli.RangeArgs(1, maxAccessChecks),
+		Example: heredoc.Doc(`
+			$ shield resource check shield/project:<project-id>#edit --header=<key>:<value>
+			$ shield resource check entropy/firehose:<resource-name>#view entropy/firehose:<resource-name>#delete
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res struct {
+				Results []accessCheckResponse `json:"results"`
+			}
+			if err := getAdminAPI(cmd.Context(), cliConfig, accessCheckPath, url.Values{"check": args}, header, &res); err != nil {
+				return err
+			}
+
+			report := [][]string{{"RESOURCE", "PERMISSION", "RESULT"}}
+			for _, r := range res.Results {
+				result := "DENIED"
+				if r.Allowed {
+					result = "ALLOWED"
+				}
+				report = append(report, []string{r.Namespace + ":" + r.ObjectID, r.Permission, result})
+			}
+
+			spinner.Stop()
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func whoCanResourceCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "who-can <namespace>:<object-id> <action>",
+		Short: "List the subjects allowed an action on a resource",
+		Long: heredoc.Doc(`
+			List the users and groups allowed an action on a resource and the relations
+			they get it through, like the role of a group they are a member of.
+
+			Objects of the shield namespaces are referred to by id and the other resources
+			by name, the way permission checks refer to them. Only the users allowed the
+			action can see who else is.
+		`),
+		Args: cli.ExactArgs(2),
+		Example: heredoc.Doc(`
+			$ shield resource who-can shield/project:<project-id> edit --header=<key>:<value>
+			$ shield resource who-can entropy/firehose:<resource-name> view
+		`),
+		Annotations: map[string]string{
+			"resource:core": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			ns, objectID, ok := strings.Cut(args[0], ":")
+			if !ok || ns == "" || objectID == "" {
+				return fmt.Errorf("resource %q should be <namespace>:<object-id>", args[0])
+			}
+
+			var res struct {
+				Tree accessTreeResponse `json:"tree"`
+			}
+			if err := getAdminAPI(cmd.Context(), cliConfig, accessExpandPath, url.Values{
+				"namespace": {ns},
+				"object_id": {objectID},
+				"action":    {args[1]},
+			}, header, &res); err != nil {
+				return err
+			}
+
+			tree := res.Tree.accessTree()
+			report := [][]string{}
+			report = append(report, []string{"SUBJECT", "VIA"})
+			subjects := tree.GrantedSubjects()
+			for _, sub := range subjects {
+				for _, path := range tree.Paths(sub) {
+					var via []string
+					for _, node := range path {
+						via = append(via, accessTreeNode(node))
+					}
+					report = append(report, []string{subjectString(sub), strings.Join(via, " > ")})
+				}
+			}
+
+			spinner.Stop()
+			fmt.Printf(" \nShowing %d subjects allowed to %s %s\n \n", len(subjects), args[1], args[0])
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
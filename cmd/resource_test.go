@@ -0,0 +1,47 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientResource(t *testing.T) {
+	tests := []struct {
+		name        string
+		subCommands []string
+		want        string
+		err         error
+	}{
+		{
+			name:        "`resource` check only should throw error host not found",
+			want:        "",
+			subCommands: []string{"check", "entropy/firehose:f1#view"},
+			err:         cmd.ErrClientConfigHostNotFound,
+		},
+		{
+			name:        "`resource` check with host flag should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"check", "entropy/firehose:f1#view", "-h", "test"},
+			err:         errors.New("required flag(s) \"header\" not set"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+
+			buf := new(bytes.Buffer)
+			cli.SetOutput(buf)
+			cli.SetArgs(append([]string{"resource"}, tt.subCommands...))
+
+			err := cli.Execute()
+			got := buf.String()
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
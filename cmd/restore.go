@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/shield/internal/api/v1beta1"
+	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+// the v1beta1 api has no restore rpcs, the deleted organizations and
+// projects are restored through the restore paths the gateway serves
+var restorePaths = map[string]string{
+	"organization": "/admin/v1beta1/organizations/restore",
+	"project":      "/admin/v1beta1/projects/restore",
+}
+
+// restoreCommand restores the deleted resource of the id, it is restored
+// until the server purges it
+func restoreCommand(cliConfig *Config, resource string) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "restore <id>",
+		Short: fmt.Sprintf("Restore a deleted %s", resource),
+		Long: heredoc.Docf(`
+			Restore a deleted %[1]s as the logged in user or the user of the header, who
+			could delete it. A deleted %[1]s is kept until the server purges it, list the
+			deleted ones with list --deleted.
+		`, resource),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Docf(`
+			$ shield %[1]s list --deleted
+			$ shield %[1]s restore <%[1]s-id> --header=<key>:<value>
+		`, resource),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			var res struct {
+				ID   string `json:"id"`
+				Slug string `json:"slug"`
+			}
+			if err := postAdminAPI(cmd.Context(), cliConfig, restorePaths[resource], url.Values{"id": {args[0]}}, header, &res); err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully restored %s %s with id %s\n", resource, res.Slug, res.ID)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+// deletedItems are the ids of the deleted items listed, the server returns
+// them in the deleted header of the lists that include the deleted items
+type deletedItems map[string]bool
+
+// includeDeleted asks the server to list the deleted items along with the
+// others when include is set
+func includeDeleted(ctx context.Context, include bool) context.Context {
+	if !include {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, v1beta1.IncludeDeletedHeader, "true")
+}
+
+func (d deletedItems) add(header metadata.MD) {
+	for _, ids := range header.Get(v1beta1.DeletedHeader) {
+		for _, id := range strings.Split(ids, ",") {
+			d[id] = true
+		}
+	}
+}
+
+func (d deletedItems) state(id string) string {
+	if d[id] {
+		return "deleted"
+	}
+	return "active"
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -10,6 +11,19 @@ import (
 	"github.com/odpf/shield/pkg/file"
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
 	cli "github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// roleOrgMetadataKey and roleIncludesMetadataKey are the metadata the server
+// keeps the organization of a custom role and the roles a role includes in
+const (
+	roleOrgMetadataKey      = "org_id"
+	roleIncludesMetadataKey = "includes"
+)
+
+var (
+	errActionsWithoutOrg = errors.New("actions can only be given to the custom roles of an organization, set --org")
+	errIncludesAndClear  = errors.New("--include and --clear-includes can't be used together")
 )
 
 func RoleCommand(cliConfig *Config) *cli.Command {
@@ -36,6 +50,8 @@ func RoleCommand(cliConfig *Config) *cli.Command {
 	cmd.AddCommand(editRoleCommand(cliConfig))
 	cmd.AddCommand(viewRoleCommand(cliConfig))
 	cmd.AddCommand(listRoleCommand(cliConfig))
+	cmd.AddCommand(deleteCommand(cliConfig, "role", roleOptions))
+	cmd.AddCommand(templateCommand("role", &shieldv1beta1.RoleRequestBody{}))
 
 	bindFlagsFromClientConfig(cmd)
 
@@ -43,14 +59,27 @@ func RoleCommand(cliConfig *Config) *cli.Command {
 }
 
 func createRoleCommand(cliConfig *Config) *cli.Command {
-	var filePath, header string
+	var filePath, header, org string
+	var actions, includes []string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "create",
 		Short: "Create a role",
-		Args:  cli.NoArgs,
+		Long: heredoc.Doc(`
+			Create a role.
+
+			With --org the role is a custom role of the organization, it can only be
+			granted within the organization and its id has to be <namespace-id>:<name>.
+			Every --action is given to the role by a policy on the namespace of the role.
+			Every --include is a role of the same namespace whose actions the role is also granted.
+		`),
+		Args: cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield role create --file=<role-body> --header=<key>:<value>
+			$ shield role create --file=<role-body> --header=<key>:<value> --dry-run
+			$ shield role create --file=<role-body> --header=<key>:<value> --org=<organization-id> --action=<action-id>
+			$ shield role create --file=<role-body> --header=<key>:<value> --include=<role-id>
 		`),
 		Annotations: map[string]string{
 			"role:core": "true",
@@ -59,6 +88,10 @@ func createRoleCommand(cliConfig *Config) *cli.Command {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
+			if len(actions) > 0 && org == "" {
+				return errActionsWithoutOrg
+			}
+
 			var reqBody shieldv1beta1.RoleRequestBody
 			if err := file.Parse(filePath, &reqBody); err != nil {
 				return err
@@ -77,13 +110,61 @@ func createRoleCommand(cliConfig *Config) *cli.Command {
 
 			ctx := setCtxHeader(cmd.Context(), header)
 
-			res, err := client.CreateRole(ctx, &shieldv1beta1.CreateRoleRequest{
+			var orgRef dryRunReference
+			if org != "" {
+				orgID, err := lookupID(cmd.Context(), client, "organization", org)
+				if err != nil {
+					return fmt.Errorf("%w: org %s: %s", errUnresolvedReference, org, err)
+				}
+				orgRef = dryRunReference{Resource: "organization", Field: "org", Value: org, ID: orgID}
+
+				setRoleMetadata(&reqBody, roleOrgMetadataKey, structpb.NewStringValue(orgID))
+			}
+			if len(includes) > 0 {
+				setRoleIncludes(&reqBody, includes)
+			}
+
+			req := &shieldv1beta1.CreateRoleRequest{
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				toResolve := []dryRunReference{
+					{Resource: "namespace", Field: "namespace_id", Value: reqBody.GetNamespaceId()},
+				}
+				for _, a := range actions {
+					toResolve = append(toResolve, dryRunReference{Resource: "action", Field: "action", Value: a})
+				}
+				for _, r := range includes {
+					toResolve = append(toResolve, dryRunReference{Resource: "role", Field: "include", Value: r})
+				}
+				refs, err := resolveReferences(cmd.Context(), client, toResolve...)
+				if err != nil {
+					return err
+				}
+				if org != "" {
+					refs = append(refs, orgRef)
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "CreateRole", req, nil, refs...)
+			}
+
+			res, err := client.CreateRole(ctx, req)
 			if err != nil {
 				return err
 			}
 
+			for _, a := range actions {
+				if _, err := client.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{
+					Body: &shieldv1beta1.PolicyRequestBody{
+						RoleId:      res.GetRole().GetId(),
+						ActionId:    a,
+						NamespaceId: reqBody.GetNamespaceId(),
+					},
+				}); err != nil {
+					return fmt.Errorf("role %s was created, giving it action %s: %w", res.GetRole().GetId(), a, err)
+				}
+			}
+
 			spinner.Stop()
 			fmt.Printf("successfully created role %s with id %s\n", res.GetRole().GetName(), res.GetRole().GetId())
 			return nil
@@ -92,29 +173,44 @@ func createRoleCommand(cliConfig *Config) *cli.Command {
 
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the role body file")
 	cmd.MarkFlagRequired("file")
-	cmd.Flags().StringVarP(&header, "header", "H", "", "Header <key>:<value>")
-	cmd.MarkFlagRequired("header")
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVar(&org, "org", "", "Id or slug of the organization the role is a custom role of")
+	cmd.Flags().StringArrayVar(&actions, "action", nil, "Id of an action to give the custom role, can be repeated")
+	cmd.Flags().StringArrayVar(&includes, "include", nil, "Id of a role the role includes, can be repeated")
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func editRoleCommand(cliConfig *Config) *cli.Command {
 	var filePath string
+	var includes []string
+	var clearIncludes, dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "edit",
 		Short: "Edit a role",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield role edit <role-id> --file=<role-body>
+			$ shield role edit --file=<role-body>
+			$ shield role edit <role-id> --file=<role-body> --dry-run
+			$ shield role edit <role-id> --file=<role-body> --include=<role-id>
+			$ shield role edit <role-id> --file=<role-body> --clear-includes
 		`),
 		Annotations: map[string]string{
 			"role:core": "true",
 		},
+		ValidArgsFunction: completeIDs(roleOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
+			if clearIncludes && len(includes) > 0 {
+				return errIncludesAndClear
+			}
+
 			var reqBody shieldv1beta1.RoleRequestBody
 			if err := file.Parse(filePath, &reqBody); err != nil {
 				return err
@@ -125,17 +221,38 @@ func editRoleCommand(cliConfig *Config) *cli.Command {
 				return err
 			}
 
+			if clearIncludes || len(includes) > 0 {
+				setRoleIncludes(&reqBody, includes)
+			}
+
 			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
 			if err != nil {
 				return err
 			}
 			defer cancel()
 
-			roleID := args[0]
-			_, err = client.UpdateRole(cmd.Context(), &shieldv1beta1.UpdateRoleRequest{
+			roleID, err := resolveID(cmd, client, spinner, args, "role", roleOptions)
+			if err != nil {
+				return err
+			}
+
+			req := &shieldv1beta1.UpdateRoleRequest{
 				Id:   roleID,
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "role", Field: "id", Value: roleID},
+					dryRunReference{Resource: "namespace", Field: "namespace_id", Value: reqBody.GetNamespaceId()},
+				)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "UpdateRole", req, nil, refs...)
+			}
+
+			_, err = client.UpdateRole(cmd.Context(), req)
 			if err != nil {
 				return err
 			}
@@ -148,23 +265,31 @@ func editRoleCommand(cliConfig *Config) *cli.Command {
 
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the role body file")
 	cmd.MarkFlagRequired("file")
+	cmd.Flags().StringArrayVar(&includes, "include", nil, "Id of a role the role includes, replaces the included roles, can be repeated")
+	cmd.Flags().BoolVar(&clearIncludes, "clear-includes", false, "Remove all the roles the role includes")
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func viewRoleCommand(cliConfig *Config) *cli.Command {
 	var metadata bool
+	var output string
 
 	cmd := &cli.Command{
 		Use:   "view",
 		Short: "View a role",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield role view <role-id>
+			$ shield role view
+			$ shield role view <role-id> --output=json
 		`),
 		Annotations: map[string]string{
 			"role:core": "true",
 		},
+		ValidArgsFunction: completeIDs(roleOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -175,7 +300,11 @@ func viewRoleCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			roleID := args[0]
+			roleID, err := resolveID(cmd, client, spinner, args, "role", roleOptions)
+			if err != nil {
+				return err
+			}
+
 			res, err := client.GetRole(cmd.Context(), &shieldv1beta1.GetRoleRequest{
 				Id: roleID,
 			})
@@ -189,12 +318,17 @@ func viewRoleCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
-			report = append(report, []string{"ID", "NAME", "TYPE(S)", "NAMESPACE"})
+			if output != outputTable {
+				return printMessage(os.Stdout, output, role)
+			}
+
+			report = append(report, []string{"ID", "NAME", "TYPE(S)", "NAMESPACE", "INCLUDES"})
 			report = append(report, []string{
 				role.GetId(),
 				role.GetName(),
 				strings.Join(role.GetTypes(), ", "),
 				role.GetNamespace().GetId(),
+				strings.Join(roleIncludes(role), ", "),
 			})
 			printer.Table(os.Stdout, report)
 
@@ -220,17 +354,21 @@ func viewRoleCommand(cliConfig *Config) *cli.Command {
 	}
 
 	cmd.Flags().BoolVarP(&metadata, "metadata", "m", false, "Set this flag to see metadata")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
 
 	return cmd
 }
 
 func listRoleCommand(cliConfig *Config) *cli.Command {
+	var output string
+
 	cmd := &cli.Command{
 		Use:   "list",
 		Short: "List all roles",
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield role list
+			$ shield role list --output=yaml
 		`),
 		Annotations: map[string]string{
 			"role:core": "true",
@@ -255,6 +393,10 @@ func listRoleCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, res)
+			}
+
 			if len(roles) == 0 {
 				fmt.Printf("No roles found.\n")
 				return nil
@@ -277,5 +419,36 @@ func listRoleCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
 	return cmd
 }
+
+func setRoleMetadata(reqBody *shieldv1beta1.RoleRequestBody, key string, value *structpb.Value) {
+	if reqBody.Metadata == nil {
+		reqBody.Metadata = &structpb.Struct{}
+	}
+	if reqBody.Metadata.Fields == nil {
+		reqBody.Metadata.Fields = map[string]*structpb.Value{}
+	}
+	reqBody.Metadata.Fields[key] = value
+}
+
+// setRoleIncludes sets the roles the role includes, an empty list removes them
+func setRoleIncludes(reqBody *shieldv1beta1.RoleRequestBody, includes []string) {
+	values := make([]*structpb.Value, 0, len(includes))
+	for _, r := range includes {
+		values = append(values, structpb.NewStringValue(r))
+	}
+	setRoleMetadata(reqBody, roleIncludesMetadataKey, structpb.NewListValue(&structpb.ListValue{Values: values}))
+}
+
+// roleIncludes are the roles the role includes, the server keeps them in
+// the metadata of the role
+func roleIncludes(r *shieldv1beta1.Role) []string {
+	var includes []string
+	for _, v := range r.GetMetadata().GetFields()[roleIncludesMetadataKey].GetListValue().GetValues() {
+		includes = append(includes, v.GetStringValue())
+	}
+	return includes
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/odpf/shield/cmd"
@@ -25,6 +26,24 @@ func TestClientRole(t *testing.T) {
 				subCommands: []string{"list"},
 				err:         cmd.ErrClientConfigHostNotFound,
 			},
+			{
+				name:        "`role` delete without host should throw error host not found",
+				want:        "",
+				subCommands: []string{"delete", "123"},
+				err:         cmd.ErrClientConfigHostNotFound,
+			},
+			{
+				name:        "`role` delete with host flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test"},
+				err:         errors.New("required flag(s) \"header\" not set"),
+			},
+			{
+				name:        "`role` delete without force should throw error not confirmed",
+				want:        "",
+				subCommands: []string{"delete", "123", "-h", "test", "-H", "X-Shield-Email:admin@odpf.io"},
+				err:         fmt.Errorf("%w: pass --force to delete without a prompt", errors.New("operation cancelled")),
+			},
 			{
 				name:        "`role` list with host flag should pass",
 				want:        "",
@@ -43,6 +62,18 @@ func TestClientRole(t *testing.T) {
 				subCommands: []string{"create", "-h", "test"},
 				err:         errors.New("required flag(s) \"file\", \"header\" not set"),
 			},
+			{
+				name:        "`role` create with org flag should throw error missing required flag",
+				want:        "",
+				subCommands: []string{"create", "-h", "test", "--org", "org-1"},
+				err:         errors.New("required flag(s) \"file\", \"header\" not set"),
+			},
+			{
+				name:        "`role` create with action flag without org flag should throw error",
+				want:        "",
+				subCommands: []string{"create", "-h", "test", "-f", "role.yaml", "-H", "X-Shield-Email:admin@odpf.io", "--action", "edit.shield/project"},
+				err:         errors.New("actions can only be given to the custom roles of an organization, set --org"),
+			},
 			{
 				name:        "`role` edit without host should throw error host not found",
 				want:        "",
@@ -55,6 +86,12 @@ func TestClientRole(t *testing.T) {
 				subCommands: []string{"edit", "123", "-h", "test"},
 				err:         errors.New("required flag(s) \"file\" not set"),
 			},
+			{
+				name:        "`role` edit with include and clear includes flags should throw error",
+				want:        "",
+				subCommands: []string{"edit", "123", "-h", "test", "-f", "role.json", "--include", "shield/project:viewer", "--clear-includes"},
+				err:         errors.New("--include and --clear-includes can't be used together"),
+			},
 			{
 				name:        "`role` view without host should throw error host not found",
 				want:        "",
@@ -32,7 +32,21 @@ func New(cfg *Config) *cli.Command {
 		},
 	}
 
+	bindJSONNamesFlag(cmd)
+	bindNoInteractiveFlag(cmd)
+	bindVerboseFlag(cmd)
+	bindProfileFlags(cmd)
+
 	cmd.PersistentPreRunE = func(subCmd *cobra.Command, args []string) error {
+		if err := startProfiling(subCmd); err != nil {
+			return err
+		}
+		if err := applyJSONNamesFlag(subCmd); err != nil {
+			return err
+		}
+		if err := validateOutputFlag(subCmd); err != nil {
+			return err
+		}
 		if isClientCLI(subCmd) {
 			if err := overrideClientConfigHost(subCmd, cliConfig); err != nil {
 				return err
@@ -47,16 +61,36 @@ func New(cfg *Config) *cli.Command {
 	cmd.AddCommand(OrganizationCommand(cliConfig))
 	cmd.AddCommand(GroupCommand(cliConfig))
 	cmd.AddCommand(ProjectCommand(cliConfig))
+	cmd.AddCommand(ResourceCommand(cliConfig))
 	cmd.AddCommand(RoleCommand(cliConfig))
 	cmd.AddCommand(ActionCommand(cliConfig))
 	cmd.AddCommand(PolicyCommand(cliConfig))
+	cmd.AddCommand(ApplyCommand(cliConfig))
+	cmd.AddCommand(ExportCommand(cliConfig))
+	cmd.AddCommand(SchemaCommand())
+	cmd.AddCommand(CheckCommand())
+	cmd.AddCommand(RelationCommand())
+	cmd.AddCommand(ServiceAccountCommand(cliConfig))
+	cmd.AddCommand(KeysCommand())
+	cmd.AddCommand(APIKeyCommand(cliConfig))
+	cmd.AddCommand(WebhookCommand())
+	cmd.AddCommand(RuleCommand())
+	cmd.AddCommand(EventCommand(cliConfig))
+	cmd.AddCommand(AuditCommand(cliConfig))
+	cmd.AddCommand(ProxyCommand(cliConfig))
+	cmd.AddCommand(InvitationCommand(cliConfig))
+	cmd.AddCommand(FolderCommand(cliConfig))
+	cmd.AddCommand(MetaSchemaCommand())
+	cmd.AddCommand(AuthCommand())
+	cmd.AddCommand(WhoamiCommand(cliConfig))
+	cmd.AddCommand(SyncCommand())
+	cmd.AddCommand(DevCommand())
 	cmd.AddCommand(configCommand())
 
 	// Help topics
 	cmdx.SetHelp(cmd)
 	cmd.AddCommand(cmdx.SetCompletionCmd("shield"))
 	cmd.AddCommand(cmdx.SetHelpTopicCmd("environment", envHelp))
-	cmd.AddCommand(cmdx.SetHelpTopicCmd("auth", authHelp))
 	cmd.AddCommand(cmdx.SetRefCmd(cmd))
 	return cmd
 }
@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/pkg/file"
+	cli "github.com/spf13/cobra"
+)
+
+// ruleBody is a stored rule as it is written in a file, a frontend of a
+// backend the way the rule files have them
+type ruleBody struct {
+	Name     string `json:"name"`
+	Proxy    string `json:"proxy"`
+	Frontend struct {
+		Path        string `json:"path"`
+		Method      string `json:"method"`
+		GRPCMethod  string `json:"grpc_method"`
+		MaxBodySize int64  `json:"max_body_size"`
+	} `json:"frontend"`
+	Backend struct {
+		Name   string `json:"name"`
+		Target string `json:"target"`
+		Prefix string `json:"prefix"`
+
+		Timeout        string              `json:"timeout"`
+		Retry          rule.Retry          `json:"retry"`
+		CircuitBreaker rule.CircuitBreaker `json:"circuit_breaker"`
+		Splits         []rule.Split        `json:"splits"`
+	} `json:"backend"`
+	Middlewares []ruleSpecBody `json:"middlewares"`
+	Hooks       []ruleSpecBody `json:"hooks"`
+}
+
+type ruleSpecBody struct {
+	Name   string                 `json:"name"`
+	Config map[string]interface{} `json:"config"`
+}
+
+func (b ruleBody) toRule() rule.Rule {
+	rl := rule.Rule{
+		Name:  b.Name,
+		Proxy: b.Proxy,
+		Frontend: rule.Frontend{
+			URL:         b.Frontend.Path,
+			Method:      b.Frontend.Method,
+			GRPCMethod:  b.Frontend.GRPCMethod,
+			MaxBodySize: b.Frontend.MaxBodySize,
+		},
+		Backend: rule.Backend{
+			URL:       b.Backend.Target,
+			Namespace: b.Backend.Name,
+			Prefix:    b.Backend.Prefix,
+			Policy: rule.Policy{
+				Timeout:        b.Backend.Timeout,
+				Retry:          b.Backend.Retry,
+				CircuitBreaker: b.Backend.CircuitBreaker,
+			},
+			Splits: b.Backend.Splits,
+		},
+	}
+	for _, m := range b.Middlewares {
+		rl.Middlewares = append(rl.Middlewares, rule.MiddlewareSpec{Name: m.Name, Config: m.Config})
+	}
+	for _, h := range b.Hooks {
+		rl.Hooks = append(rl.Hooks, rule.HookSpec{Name: h.Name, Config: h.Config})
+	}
+	return rl
+}
+
+func RuleCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:     "rule",
+		Aliases: []string{"rules"},
+		Short:   "Manage the rules stored for the proxies",
+		Long: heredoc.Doc(`
+			Work with the rules of the proxies stored in the database.
+
+			A rule routes the requests matching its frontend to its backend, through the
+			middlewares and the hooks authorizing them, the way the rules of the rule
+			files do. A proxy serves the rules stored for its name ahead of the rules of
+			its rule files, which are kept to bootstrap the proxies, and picks up the
+			changes to the stored rules within seconds.
+
+			The commands connect to the database with the server config.
+		`),
+		Example: heredoc.Doc(`
+			$ shield rule create --file=<rule-body>
+			$ shield rule edit <rule-id> --file=<rule-body>
+			$ shield rule list --proxy=<proxy-name>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(createRuleCommand())
+	cmd.AddCommand(editRuleCommand())
+	cmd.AddCommand(listRuleCommand())
+
+	return cmd
+}
+
+func createRuleCommand() *cli.Command {
+	var configFile, filePath string
+
+	cmd := &cli.Command{
+		Use:   "create",
+		Short: "Store a rule for a proxy",
+		Long: heredoc.Doc(`
+			Store a rule for a proxy, the rule is named uniquely among the rules of the
+			proxy. The rule body is a yaml or json file:
+
+			  name: list-firehoses
+			  proxy: <proxy-name>
+			  frontend:
+			    path: "/api/firehoses"
+			    method: GET
+			  backend:
+			    name: entropy
+			    target: "http://localhost:3000"
+			  middlewares:
+			    - name: authz
+			      config:
+			        action: firehose_view
+
+			A backend sets timeout, retry and circuit_breaker to stop a slow or failing
+			target from holding up the proxy:
+
+			  backend:
+			    name: entropy
+			    target: "http://localhost:3000"
+			    timeout: 10s
+			    retry:
+			      attempts: 2
+			      budget: 0.2
+			    circuit_breaker:
+			      failures: 5
+			      cooldown: 30s
+
+			A backend sends a part of its requests to other targets with splits, like the
+			canary of a service, the requests with the header or the cookie of a split go
+			to its target and the split takes its weight, a percentage, of the others:
+
+			  backend:
+			    name: entropy
+			    target: "http://entropy:3000"
+			    splits:
+			      - name: canary
+			        target: "http://entropy-canary:3000"
+			        weight: 10
+			        header:
+			          name: X-Canary
+			          value: "true"
+
+			A frontend of a gRPC method sets grpc_method, like package.Service/Method,
+			instead of the path and the method.
+
+			Rules the proxies can't serve, without a path, with a path which isn't a
+			valid regular expression or a target which isn't an absolute url, are
+			rejected.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield rule create --file=<rule-body>
+			$ shield rule create -f rule.yaml -c ./config.yaml
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			var body ruleBody
+			if err := file.Parse(filePath, &body); err != nil {
+				return err
+			}
+
+			manager, cleanup, err := ruleManager(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			created, err := manager.Create(cmd.Context(), body.toRule())
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully created rule %s with id %s\n", created.Name, created.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the rule body file")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func editRuleCommand() *cli.Command {
+	var configFile, filePath string
+
+	cmd := &cli.Command{
+		Use:   "edit <rule-id>",
+		Short: "Replace a stored rule",
+		Long: heredoc.Doc(`
+			Replace a stored rule with the rule body of the file, the way shield rule
+			create reads it.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield rule edit <rule-id> --file=<rule-body>
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			var body ruleBody
+			if err := file.Parse(filePath, &body); err != nil {
+				return err
+			}
+
+			manager, cleanup, err := ruleManager(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			rl := body.toRule()
+			rl.ID = args[0]
+			if _, err := manager.Update(cmd.Context(), rl); err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully edited rule %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the rule body file")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func listRuleCommand() *cli.Command {
+	var configFile, proxy, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List the stored rules",
+		Long: heredoc.Doc(`
+			List the stored rules in the order the proxies match them, the rules of the
+			rule files are not listed.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield rule list
+			$ shield rule list --proxy=<proxy-name>
+			$ shield rule list --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			manager, cleanup, err := ruleManager(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			rules, err := manager.List(cmd.Context(), rule.Filter{Proxy: proxy})
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAME", "PROXY", "METHOD", "PATH", "BACKEND", "TARGET", "UPDATED AT"})
+			for _, r := range rules {
+				report = append(report, []string{r.ID, r.Name, r.Proxy, r.Frontend.Method, r.Frontend.URL, r.Backend.Namespace, r.Backend.URL, r.UpdatedAt.Format(time.RFC3339)})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d rules\n \n", len(rules))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVar(&proxy, "proxy", "", "Name of the proxy, the rules of every proxy when not set")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func ruleManager(configFile string) (*rule.Manager, func(), error) {
+	dbClient, appConfig, err := serverDB(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rule.NewManager(
+		newStores(dbClient).rules,
+		newAuditRecorder(dbClient, appConfig.Event)), func() { dbClient.Close() }, nil
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/schema/migration"
+	"github.com/odpf/shield/internal/store/blob"
+	"github.com/odpf/shield/internal/store/spicedb"
+	shieldlogger "github.com/odpf/shield/pkg/logger"
+	"github.com/odpf/shield/pkg/secrets"
+	cli "github.com/spf13/cobra"
+)
+
+func SchemaCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "schema",
+		Short: "Migrate the authz schema to a schema file",
+		Long: heredoc.Doc(`
+			Work with a declarative schema file.
+
+			The schema file declares the namespaces along with their roles and permissions:
+
+			  version: 1
+			  namespaces:
+			    entropy:
+			      type: resource_group
+			      resource_types:
+			        - name: firehose
+			          roles:
+			            - name: viewer
+			              principals: [shield/user, shield/group]
+			          permissions:
+			            - name: view
+			              roles: [owner, viewer, organization:owner]
+
+			The namespaces are written as in the files of the resources config path.
+
+			The namespaces, roles, actions and policies of postgres and the schema of
+			spicedb are migrated to it along with the predefined namespaces.
+		`),
+		Example: heredoc.Doc(`
+			$ shield schema plan -f schema.yaml
+			$ shield schema apply -f schema.yaml
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(planSchemaCommand())
+	cmd.AddCommand(applySchemaCommand())
+
+	return cmd
+}
+
+func planSchemaCommand() *cli.Command {
+	var configFile, filePath string
+
+	cmd := &cli.Command{
+		Use:   "plan",
+		Short: "Show what migrating to a schema file changes",
+		Long: heredoc.Doc(`
+			Compare a schema file with postgres and spicedb without changing anything.
+
+			The changes to the namespaces, roles, actions and policies of postgres are
+			listed along with the relationships of spicedb on the roles and namespaces
+			deleted, which apply refuses to remove without --force. The diff of the live
+			schema of spicedb and the one generated from the file follows, the custom
+			roles of the organizations show up as lines only in the live schema.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield schema plan --file=schema.yaml
+			$ shield schema plan -f schema.yaml -c ./config.yaml
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			configs, err := readSchemaFile(filePath)
+			if err != nil {
+				return err
+			}
+			migrator, cleanup, err := serverSchemaMigrator(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			plan, err := planSchema(cmd.Context(), migrator, filePath, configs)
+			if err != nil {
+				return err
+			}
+			diff, err := schemaDiff(cmd.Context(), configFile, schema.WithPredefined(configs), filePath)
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf(" \nFound %d changes, run shield schema apply to make them\n \n", len(plan.Changes))
+			printSchemaChanges(plan)
+			if inUse := plan.InUse(); len(inUse) > 0 {
+				fmt.Printf(" \n%d deletions are in use, apply needs --force to remove their relationships\n", len(inUse))
+			}
+			if diff == "" {
+				fmt.Println(" \nno changes against the live schema")
+				return nil
+			}
+			fmt.Print(" \n", diff)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the schema file")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func applySchemaCommand() *cli.Command {
+	var configFile, filePath string
+	var force bool
+
+	cmd := &cli.Command{
+		Use:   "apply",
+		Short: "Migrate postgres and spicedb to a schema file",
+		Long: heredoc.Doc(`
+			Migrate the namespaces, roles, actions and policies of postgres and the schema
+			of spicedb to a schema file.
+
+			Deleting a role or a namespace which relationships of spicedb are still on is
+			refused, --force removes those relationships and their relations first.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield schema apply --file=schema.yaml
+			$ shield schema apply -f schema.yaml -c ./config.yaml --force
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			configs, err := readSchemaFile(filePath)
+			if err != nil {
+				return err
+			}
+			migrator, cleanup, err := serverSchemaMigrator(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			plan, err := planSchema(cmd.Context(), migrator, filePath, configs)
+			if err != nil {
+				return err
+			}
+			if err := migrator.Apply(cmd.Context(), plan, force); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf(" \nMade %d changes\n \n", len(plan.Changes))
+			printSchemaChanges(plan)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the schema file")
+	cmd.Flags().BoolVar(&force, "force", false, "Remove the relationships of the roles and namespaces deleted")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func readSchemaFile(filePath string) (schema.NamespaceConfigMapType, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return blob.ParseSchemaFile(content)
+}
+
+// planSchema plans the migration to the schema file, the problems of an
+// invalid file are printed one per line as namespace validate does
+func planSchema(ctx context.Context, migrator *migration.Migrator, filePath string, configs schema.NamespaceConfigMapType) (migration.Plan, error) {
+	plan, err := migrator.Plan(ctx, configs)
+	var invalid schema.ValidationError
+	if errors.As(err, &invalid) {
+		for _, p := range invalid.Problems {
+			fmt.Println(p)
+		}
+		return migration.Plan{}, fmt.Errorf("%s has %d problems", filePath, len(invalid.Problems))
+	}
+	return plan, err
+}
+
+func printSchemaChanges(plan migration.Plan) {
+	if len(plan.Changes) == 0 {
+		return
+	}
+	report := [][]string{}
+	report = append(report, []string{"ACTION", "KIND", "ID", "IN USE"})
+	for _, c := range plan.Changes {
+		inUse := ""
+		if c.InUse > 0 {
+			inUse = strconv.Itoa(c.InUse)
+		}
+		report = append(report, []string{c.Action, c.Kind, c.ID, inUse})
+	}
+	printer.Table(os.Stdout, report)
+}
+
+func serverSchemaMigrator(ctx context.Context, configFile string) (*migration.Migrator, func(), error) {
+	dbClient, appConfig, err := serverDB(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger := shieldlogger.InitLogger(appConfig.Log)
+
+	authz, err := setupAuthzEngine(appConfig, dbClient, secrets.NewResolver(appConfig.Secrets), logger)
+	if err != nil {
+		dbClient.Close()
+		return nil, nil, err
+	}
+
+	deps, err := buildAPIDependencies(ctx, logger, nil, dbClient, authz, spicedb.CheckCacheConfig{}, appConfig.Event)
+	if err != nil {
+		dbClient.Close()
+		return nil, nil, err
+	}
+	return migration.NewMigrator(
+		deps.NamespaceService,
+		deps.RoleService,
+		deps.ActionService,
+		deps.PolicyService,
+		deps.RelationService,
+		authz.relations,
+		authz.policies,
+	), func() { dbClient.Close() }, nil
+}
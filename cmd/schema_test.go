@@ -0,0 +1,62 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/odpf/shield/internal/store/blob"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "schema.yaml")
+	if err := os.WriteFile(schemaFile, []byte("version: 2\nnamespaces: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		subCommands []string
+		want        string
+		err         error
+	}{
+		{
+			name:        "`schema` plan without file should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"plan"},
+			err:         errors.New("required flag(s) \"file\" not set"),
+		},
+		{
+			name:        "`schema` apply without file should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"apply", "--force"},
+			err:         errors.New("required flag(s) \"file\" not set"),
+		},
+		{
+			name:        "`schema` plan with an unsupported version should throw error before connecting",
+			want:        "",
+			subCommands: []string{"plan", "-f", schemaFile},
+			err:         fmt.Errorf("%w: 2, version 1 is supported", blob.ErrSchemaFileVersion),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+
+			buf := new(bytes.Buffer)
+			cli.SetOutput(buf)
+			cli.SetArgs(append([]string{"schema"}, tt.subCommands...))
+
+			err := cli.Execute()
+			got := buf.String()
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/salt/term"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	cli "github.com/spf13/cobra"
+)
+
+var (
+	errNothingToSelect = errors.New("nothing found to select from")
+	errNotConfirmed    = errors.New("operation cancelled")
+)
+
+type selectOption struct {
+	ID    string
+	Label string
+	// Slug identifies the option as well as its id, if it has one
+	Slug string
+}
+
+type selectOptionsFunc func(ctx context.Context, client shieldv1beta1.ShieldServiceClient) ([]selectOption, error)
+
+func bindNoInteractiveFlag(cmd *cli.Command) {
+	cmd.PersistentFlags().Bool("no-interactive", false, "Disable interactive prompts")
+}
+
+// isInteractive reports whether the command may prompt the user
+func isInteractive(cmd *cli.Command) bool {
+	if noInteractive, err := cmd.Flags().GetBool("no-interactive"); err == nil && noInteractive {
+		return false
+	}
+	return term.IsTTY() && !term.IsCI()
+}
+
+// idArg accepts exactly one id argument, the id can be omitted
+// when the session is interactive so it can be picked from a list
+func idArg(cmd *cli.Command, args []string) error {
+	if len(args) == 0 && isInteractive(cmd) {
+		return nil
+	}
+	return cli.ExactArgs(1)(cmd, args)
+}
+
+// resolveID returns the id given as argument or lets the user pick one with
+// a fuzzy finder over the options returned by list
+func resolveID(cmd *cli.Command, client shieldv1beta1.ShieldServiceClient, spinner *printer.Indicator, args []string, resource string, list selectOptionsFunc) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	options, err := list(cmd.Context(), client)
+	if err != nil {
+		return "", err
+	}
+	if len(options) == 0 {
+		return "", fmt.Errorf("%w: no %s available", errNothingToSelect, resource)
+	}
+
+	spinner.Stop()
+
+	labels := make([]string, 0, len(options))
+	for _, o := range options {
+		labels = append(labels, fmt.Sprintf("%s  %s", o.Label, o.ID))
+	}
+
+	var selected int
+	if err := survey.AskOne(&survey.Select{
+		Message:  fmt.Sprintf("Select %s:", resource),
+		Options:  labels,
+		PageSize: 20,
+	}, &selected, survey.WithFilter(fuzzyMatch)); err != nil {
+		return "", fmt.Errorf("could not prompt: %w", err)
+	}
+
+	return options[selected].ID, nil
+}
+
+// confirm asks the user to confirm the operation, it is never
+// confirmed when the session is not interactive
+func confirm(cmd *cli.Command, message string) error {
+	if !isInteractive(cmd) {
+		return fmt.Errorf("%w: pass --yes to confirm without a prompt", errNotConfirmed)
+	}
+
+	var confirmed bool
+	if err := survey.AskOne(&survey.Confirm{Message: message}, &confirmed); err != nil {
+		return fmt.Errorf("could not prompt: %w", err)
+	}
+	if !confirmed {
+		return errNotConfirmed
+	}
+	return nil
+}
+
+// fuzzyMatch matches when every character of the filter appears
+// in the option in the same order, like fzf does
+func fuzzyMatch(filter string, option string, _ int) bool {
+	option = strings.ToLower(option)
+	for _, r := range strings.ToLower(filter) {
+		idx := strings.IndexRune(option, r)
+		if idx < 0 {
+			return false
+		}
+		option = option[idx+1:]
+	}
+	return true
+}
+
+func organizationOptions(ctx context.Context, client shieldv1beta1.ShieldServiceClient) ([]selectOption, error) {
+	res, err := client.ListOrganizations(ctx, &shieldv1beta1.ListOrganizationsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var options []selectOption
+	for _, o := range res.GetOrganizations() {
+		options = append(options, selectOption{ID: o.GetId(), Label: fmt.Sprintf("%s (%s)", o.GetName(), o.GetSlug()), Slug: o.GetSlug()})
+	}
+	return options, nil
+}
+
+func projectOptions(ctx context.Context, client shieldv1beta1.ShieldServiceClient) ([]selectOption, error) {
+	res, err := client.ListProjects(ctx, &shieldv1beta1.ListProjectsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var options []selectOption
+	for _, p := range res.GetProjects() {
+		options = append(options, selectOption{ID: p.GetId(), Label: fmt.Sprintf("%s (%s)", p.GetName(), p.GetSlug()), Slug: p.GetSlug()})
+	}
+	return options, nil
+}
+
+func groupOptions(ctx context.Context, client shieldv1beta1.ShieldServiceClient) ([]selectOption, error) {
+	res, err := client.ListGroups(ctx, &shieldv1beta1.ListGroupsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var options []selectOption
+	for _, g := range res.GetGroups() {
+		options = append(options, selectOption{ID: g.GetId(), Label: fmt.Sprintf("%s (%s)", g.GetName(), g.GetSlug()), Slug: g.GetSlug()})
+	}
+	return options, nil
+}
+
+func userOptions(ctx context.Context, client shieldv1beta1.ShieldServiceClient) ([]selectOption, error) {
+	res, err := client.ListUsers(ctx, &shieldv1beta1.ListUsersRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var options []selectOption
+	for _, u := range res.GetUsers() {
+		options = append(options, selectOption{ID: u.GetId(), Label: fmt.Sprintf("%s <%s>", u.GetName(), u.GetEmail())})
+	}
+	return options, nil
+}
+
+func namespaceOptions(ctx context.Context, client shieldv1beta1.ShieldServiceClient) ([]selectOption, error) {
+	res, err := client.ListNamespaces(ctx, &shieldv1beta1.ListNamespacesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var options []selectOption
+	for _, n := range res.GetNamespaces() {
+		options = append(options, selectOption{ID: n.GetId(), Label: n.GetName()})
+	}
+	return options, nil
+}
+
+func roleOptions(ctx context.Context, client shieldv1beta1.ShieldServiceClient) ([]selectOption, error) {
+	res, err := client.ListRoles(ctx, &shieldv1beta1.ListRolesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var options []selectOption
+	for _, r := range res.GetRoles() {
+		options = append(options, selectOption{ID: r.GetId(), Label: fmt.Sprintf("%s [%s]", r.GetName(), r.GetNamespaceId())})
+	}
+	return options, nil
+}
+
+func actionOptions(ctx context.Context, client shieldv1beta1.ShieldServiceClient) ([]selectOption, error) {
+	res, err := client.ListActions(ctx, &shieldv1beta1.ListActionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var options []selectOption
+	for _, a := range res.GetActions() {
+		options = append(options, selectOption{ID: a.GetId(), Label: fmt.Sprintf("%s [%s]", a.GetName(), a.GetNamespaceId())})
+	}
+	return options, nil
+}
+
+func policyOptions(ctx context.Context, client shieldv1beta1.ShieldServiceClient) ([]selectOption, error) {
+	res, err := client.ListPolicies(ctx, &shieldv1beta1.ListPoliciesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var options []selectOption
+	for _, p := range res.GetPolicies() {
+		options = append(options, selectOption{ID: p.GetId(), Label: fmt.Sprintf("%s %s [%s]", p.GetRoleId(), p.GetActionId(), p.GetNamespaceId())})
+	}
+	return options, nil
+}
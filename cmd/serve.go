@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,31 +17,63 @@ import (
 
 	"github.com/odpf/shield/config"
 	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/apikey"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/directory"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/folder"
 	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/odpf/shield/core/invitation"
+	"github.com/odpf/shield/core/metaschema"
 	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/oidc"
 	"github.com/odpf/shield/core/organization"
 	"github.com/odpf/shield/core/policy"
 	"github.com/odpf/shield/core/project"
 	"github.com/odpf/shield/core/relation"
 	"github.com/odpf/shield/core/resource"
 	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/core/signingkey"
+	"github.com/odpf/shield/core/usage"
 	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/core/webhook"
 	"github.com/odpf/shield/internal/api"
+	"github.com/odpf/shield/internal/health"
+	"github.com/odpf/shield/internal/metrics"
+	"github.com/odpf/shield/internal/proxy/token"
 	"github.com/odpf/shield/internal/schema"
 	"github.com/odpf/shield/internal/server"
 	"github.com/odpf/shield/internal/store/blob"
-	"github.com/odpf/shield/internal/store/postgres"
+	"github.com/odpf/shield/internal/store/embedded"
+	"github.com/odpf/shield/internal/store/ldap"
+	"github.com/odpf/shield/internal/store/openfga"
 	"github.com/odpf/shield/internal/store/spicedb"
+	"github.com/odpf/shield/internal/tracing"
 	"github.com/odpf/shield/pkg/db"
+	shieldlogger "github.com/odpf/shield/pkg/logger"
+	"github.com/odpf/shield/pkg/secrets"
 
 	"github.com/odpf/salt/log"
 	salt_server "github.com/odpf/salt/server"
 	"github.com/pkg/profile"
+	"github.com/robfig/cron/v3"
 	"google.golang.org/grpc/codes"
 )
 
 var (
 	ruleCacheRefreshDelay = time.Minute * 2
+	// ruleStoreWatchInterval is how often the proxies check whether the rules
+	// stored in the database changed
+	ruleStoreWatchInterval = time.Second * 5
+	purgeDeletedInterval   = time.Hour
+	// usageFlushInterval is how often the usage counted by the instance is
+	// added to the usage of the organizations in the database
+	usageFlushInterval = time.Second * 30
+	// idempotencyCleanupInterval is how often the expired idempotency keys
+	// are deleted
+	idempotencyCleanupInterval = time.Hour
 )
 
 func StartServer(logger *log.Zap, cfg *config.Shield) error {
@@ -52,7 +85,17 @@ func StartServer(logger *log.Zap, cfg *config.Shield) error {
 	ctx, cancelFunc := context.WithCancel(salt_server.HandleSignals(context.Background()))
 	defer cancelFunc()
 
-	dbClient, err := setupDB(cfg.DB, logger)
+	stopTracing, err := tracing.Setup(cfg.Tracing, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		logger.Info("cleaning up tracing")
+		stopTracing()
+	}()
+
+	resolver := secrets.NewResolver(cfg.Secrets)
+	dbClient, err := setupDB(cfg.DB, resolver, logger)
 	if err != nil {
 		return err
 	}
@@ -60,6 +103,14 @@ func StartServer(logger *log.Zap, cfg *config.Shield) error {
 		logger.Info("cleaning up db")
 		dbClient.Close()
 	}()
+	if err := metrics.RegisterDBStats(dbClient.Stats); err != nil {
+		return err
+	}
+	if dbClient.HasReplica() {
+		if err := metrics.RegisterDBReplicaStats(dbClient.ReplicaStats, dbClient.ReplicaFallbacks); err != nil {
+			return err
+		}
+	}
 
 	// load resource config
 	if cfg.App.ResourcesConfigPath == "" {
@@ -79,7 +130,7 @@ func StartServer(logger *log.Zap, cfg *config.Shield) error {
 		defer resourceBlobRepository.Close()
 	}()
 
-	spiceDBClient, err := spicedb.New(cfg.SpiceDB, logger)
+	authz, err := setupAuthzEngine(cfg, dbClient, resolver, logger)
 	if err != nil {
 		return err
 	}
@@ -90,18 +141,21 @@ func StartServer(logger *log.Zap, cfg *config.Shield) error {
 	}
 
 	//
-	actionRepository := postgres.NewActionRepository(dbClient)
-	actionService := action.NewService(actionRepository)
+	repositories := newStores(dbClient)
+	auditService := audit.NewService(repositories.audit)
+
+	actionService := action.NewService(repositories.actions)
 
-	roleRepository := postgres.NewRoleRepository(dbClient)
-	roleService := role.NewService(roleRepository)
+	namespaceService := namespace.NewService(repositories.namespaces)
 
-	policyPGRepository := postgres.NewPolicyRepository(dbClient)
-	policySpiceRepository := spicedb.NewPolicyRepository(spiceDBClient)
-	policyService := policy.NewService(policyPGRepository)
+	userService := user.NewService(repositories.users, auditService)
 
-	namespaceRepository := postgres.NewNamespaceRepository(dbClient)
-	namespaceService := namespace.NewService(namespaceRepository)
+	roleRepository := repositories.roles
+	relationService := relation.NewService(repositories.relations, authz.relations, roleRepository, userService, auditService)
+
+	policyService := policy.NewService(newPolicyRepository(dbClient, logger), authz.policies, roleRepository, actionService, auditService)
+
+	roleService := role.NewService(roleRepository, authz.roles, namespaceService, relationService, userService, policyService)
 
 	s := schema.NewSchemaMigrationService(
 		blob.NewSchemaConfigRepository(resourceBlobFS),
@@ -109,7 +163,7 @@ func StartServer(logger *log.Zap, cfg *config.Shield) error {
 		roleService,
 		actionService,
 		policyService,
-		policySpiceRepository,
+		authz.policies,
 	)
 
 	err = s.RunMigrations(ctx)
@@ -117,16 +171,149 @@ func StartServer(logger *log.Zap, cfg *config.Shield) error {
 		return err
 	}
 
-	deps, err := buildAPIDependencies(ctx, logger, resourceBlobRepository, dbClient, spiceDBClient)
+	deps, err := buildAPIDependencies(ctx, logger, resourceBlobRepository, dbClient, authz, cfg.SpiceDB.CheckCache, cfg.Event)
+	if err != nil {
+		return err
+	}
+	if cfg.OIDC.Enabled() {
+		if deps.OIDCService, err = oidc.NewService(cfg.OIDC, deps.UserService); err != nil {
+			return err
+		}
+	}
+	deps.SessionService = newSessionService(dbClient, cfg.Event, cfg.Session)
+	deps.IdempotencyService = idempotency.NewService(repositories.idempotency, cfg.App.Idempotency)
+	migrationFs, migrationPath := migrationsOf(dbClient.DriverName())
+	healthChecks := []health.Check{
+		{Name: dbHealthCheckName(dbClient), Run: dbClient.PingContext},
+		{Name: "migrations", Run: func(ctx context.Context) error {
+			return dbClient.CheckMigrations(ctx, migrationFs, migrationPath)
+		}},
+	}
+	if authz.healthCheck.Run != nil {
+		healthChecks = append(healthChecks, authz.healthCheck)
+	}
+	deps.HealthChecker = health.NewChecker(healthChecks...)
+
+	if cfg.App.DeletedResourceRetention > 0 {
+		purgeCron, err := schedulePurgeDeleted(ctx, logger, cfg.App.DeletedResourceRetention, deps)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			logger.Info("cleaning up purge of deleted resources")
+			<-purgeCron.Stop().Done()
+		}()
+	}
+
+	idempotencyCron, err := scheduleIdempotencyCleanup(ctx, logger, deps.IdempotencyService)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		logger.Info("cleaning up deletion of expired idempotency keys")
+		<-idempotencyCron.Stop().Done()
+	}()
+
+	dispatcherCtx, stopDispatcher := context.WithCancel(ctx)
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		webhook.NewDispatcher(repositories.webhooks, cfg.Webhook).Run(dispatcherCtx, logger)
+	}()
+	defer func() {
+		logger.Info("cleaning up webhook dispatcher")
+		stopDispatcher()
+		<-dispatcherDone
+	}()
+
+	usageCtx, stopUsage := context.WithCancel(ctx)
+	usageDone := make(chan struct{})
+	go func() {
+		defer close(usageDone)
+		deps.UsageCounter.Run(usageCtx, logger, usageFlushInterval)
+	}()
+	defer func() {
+		logger.Info("cleaning up usage counter")
+		stopUsage()
+		<-usageDone
+	}()
+
+	publisher, err := event.NewPublisher(cfg.Event, logger)
+	if err != nil {
+		return err
+	}
+	if publisher != nil {
+		relayCtx, stopRelay := context.WithCancel(ctx)
+		relayDone := make(chan struct{})
+		go func() {
+			defer close(relayDone)
+			event.NewRelay(repositories.events, publisher, cfg.Event).Run(relayCtx, logger)
+		}()
+		defer func() {
+			logger.Info("cleaning up event relay")
+			stopRelay()
+			<-relayDone
+			if closer, ok := publisher.(io.Closer); ok {
+				closer.Close()
+			}
+		}()
+	}
+
+	if cfg.LDAP.Enabled() {
+		syncCtx, stopSync := context.WithCancel(ctx)
+		syncDone := make(chan struct{})
+		go func() {
+			defer close(syncDone)
+			newDirectoryService(deps, cfg.LDAP).Run(syncCtx, logger)
+		}()
+		defer func() {
+			logger.Info("cleaning up directory sync")
+			stopSync()
+			<-syncDone
+		}()
+	}
+
+	// the tokens of the requests the proxies authorized, verified by their
+	// backends with the jwks of the api
+	var signer token.Signer
+	if cfg.Keys.Enabled() {
+		keysConfig := cfg.Keys
+		// fetched once, the keys can only be decrypted with the encryption
+		// key they were encrypted with
+		if keysConfig.EncryptionKey, err = resolver.Resolve(ctx, keysConfig.EncryptionKey); err != nil {
+			return err
+		}
+		if deps.SigningKeyService, err = signingkey.NewService(repositories.signingKeys, newAuditRecorder(dbClient, cfg.Event), keysConfig); err != nil {
+			return err
+		}
+		if err := deps.SigningKeyService.Load(ctx); err != nil {
+			return fmt.Errorf("failed to load signing keys: %w", err)
+		}
+		keysCtx, stopKeys := context.WithCancel(ctx)
+		keysDone := make(chan struct{})
+		go func() {
+			defer close(keysDone)
+			deps.SigningKeyService.Run(keysCtx, logger)
+		}()
+		defer func() {
+			logger.Info("cleaning up signing keys")
+			stopKeys()
+			<-keysDone
+		}()
+		signer = deps.SigningKeyService
+	}
+	minter, err := token.NewMinter(cfg.Proxy.Token, signer)
 	if err != nil {
 		return err
 	}
 
 	// serving proxies
-	cbs, cps, err := serveProxies(ctx, logger, cfg.App.IdentityProxyHeader, cfg.App.UserIDHeader, cfg.Proxy, deps.ResourceService, deps.RelationService, deps.UserService, deps.ProjectService)
+	cbs, cps, ruleServices, breakers, err := serveProxies(ctx, logger, cfg.App.IdentityProxyHeader, cfg.App.UserIDHeader, cfg.Proxy, deps.ResourceService, deps.RelationService, deps.UserService, deps.GroupService, deps.ProjectService, repositories.rules, minter)
 	if err != nil {
 		return err
 	}
+	deps.ProxyRuleServices = ruleServices
+	deps.ProxyBreakers = breakers
 	defer func() {
 		// clean up stage
 		logger.Info("cleaning up rules proxy blob")
@@ -153,6 +340,7 @@ func StartServer(logger *log.Zap, cfg *config.Shield) error {
 	signal.Notify(keystrokeTermChan, os.Interrupt, os.Kill, syscall.SIGTERM)
 
 	// serving server
+	cfg.App.Version = config.Version
 	muxServer, err := server.Serve(ctx, logger, cfg.App, nrApp, deps)
 	if err != nil {
 		return err
@@ -180,42 +368,68 @@ func buildAPIDependencies(
 	logger log.Logger,
 	resourceBlobRepository *blob.ResourcesRepository,
 	dbc *db.Client,
-	sdb *spicedb.SpiceDB,
+	authz authzEngine,
+	checkCacheConfig spicedb.CheckCacheConfig,
+	eventConfig event.Config,
 ) (api.Deps, error) {
-	actionRepository := postgres.NewActionRepository(dbc)
-	actionService := action.NewService(actionRepository)
+	repositories := newStores(dbc)
+	eventHub := event.NewHub(eventConfig.WatchBuffer)
+	usageCounter := usage.NewCounter(repositories.usage, repositories.objects)
+	auditService := newAuditRecorder(dbc, eventConfig, eventHub, usageCounter)
+
+	actionService := action.NewService(repositories.actions)
 
-	namespaceRepository := postgres.NewNamespaceRepository(dbc)
-	namespaceService := namespace.NewService(namespaceRepository)
+	namespaceService := namespace.NewService(repositories.namespaces)
 
-	userRepository := postgres.NewUserRepository(dbc)
-	userService := user.NewService(userRepository)
+	userService := user.NewService(repositories.users, auditService)
+
+	roleRepository := repositories.roles
+
+	var relationAuthzRepository relation.AuthzRepository = authz.relations
+	// the embedded engine reads the tuples from the database, there is no watch
+	// to clear the cache of its checks with
+	if checkCacheConfig.Enabled && authz.spiceDB != nil {
+		cachedRelationRepository := spicedb.NewCachedRelationRepository(
+			spicedb.NewRelationRepository(authz.spiceDB),
+			newCheckCache(checkCacheConfig),
+			checkCacheConfig)
+		go cachedRelationRepository.Watch(ctx, logger)
+		relationAuthzRepository = cachedRelationRepository
+	}
+	// roles check the admins of their organization through the relations
+	// and sync their grants through the policies, so both look up roles in
+	// the store directly
+	relationService := relation.NewService(repositories.relations, relationAuthzRepository, roleRepository, userService, auditService)
 
-	roleRepository := postgres.NewRoleRepository(dbc)
-	roleService := role.NewService(roleRepository)
+	policyService := policy.NewService(newPolicyRepository(dbc, logger), authz.policies, roleRepository, actionService, auditService)
 
-	relationPGRepository := postgres.NewRelationRepository(dbc)
-	relationSpiceRepository := spicedb.NewRelationRepository(sdb)
-	relationService := relation.NewService(relationPGRepository, relationSpiceRepository, roleService, userService)
+	roleService := role.NewService(roleRepository, authz.roles, namespaceService, relationService, userService, policyService)
 
-	groupRepository := postgres.NewGroupRepository(dbc)
-	groupService := group.NewService(groupRepository, relationService, userService)
+	groupService := group.NewService(repositories.groups, relationService, userService, auditService)
 
-	organizationRepository := postgres.NewOrganizationRepository(dbc)
-	organizationService := organization.NewService(organizationRepository, relationService, userService)
+	organizationService := organization.NewService(repositories.organizations, relationService, userService, auditService)
 
-	projectRepository := postgres.NewProjectRepository(dbc)
-	projectService := project.NewService(projectRepository, relationService, userService)
+	projectService := project.NewService(repositories.projects, relationService, userService, auditService)
 
-	policyPGRepository := postgres.NewPolicyRepository(dbc)
-	policyService := policy.NewService(policyPGRepository)
+	usageService := usage.NewService(repositories.usage, usageCounter, organizationService, relationService, userService)
+
+	serviceUserService := serviceuser.NewService(repositories.serviceUsers, userService, auditService)
+
+	apiKeyService := apikey.NewService(repositories.apiKeys, userService, auditService)
+
+	invitationService := invitation.NewService(repositories.invitations, organizationService, groupService, userService, relationService, auditService)
 
-	resourcePGRepository := postgres.NewResourceRepository(dbc)
 	resourceService := resource.NewService(
-		resourcePGRepository,
+		repositories.resources,
 		resourceBlobRepository,
+		repositories.tagPolicies,
 		relationService,
-		userService)
+		userService,
+		policyService)
+
+	folderService := folder.NewService(repositories.folders, projectService, resourceService, userService, relationService, auditService)
+
+	metaSchemaService := metaschema.NewService(repositories.metaSchemas, auditService)
 
 	dependencies := api.Deps{
 		OrgService:       organizationService,
@@ -224,14 +438,93 @@ func buildAPIDependencies(
 		GroupService:     groupService,
 		RelationService:  relationService,
 		ResourceService:  resourceService,
+		FolderService:    folderService,
 		RoleService:      roleService,
 		PolicyService:    policyService,
 		ActionService:    actionService,
 		NamespaceService: namespaceService,
+
+		ServiceUserService: serviceUserService,
+		APIKeyService:      apiKeyService,
+		InvitationService:  invitationService,
+		MetaSchemaService:  metaSchemaService,
+
+		AuditService: audit.NewService(repositories.audit),
+		EventHub:     eventHub,
+		UsageCounter: usageCounter,
+		UsageService: usageService,
 	}
 	return dependencies, nil
 }
 
+// newAuditRecorder audits the changes made to the resources and notifies
+// them to the webhooks subscribed to them, they are written to the outbox
+// as well when events are published. The other notifiers, like the hub of
+// the watchers of the server, are notified last.
+func newAuditRecorder(dbc *db.Client, eventConfig event.Config, others ...event.Notifier) *event.Recorder {
+	repositories := newStores(dbc)
+	auditService := audit.NewService(repositories.audit)
+	notifiers := []event.Notifier{
+		webhook.NewService(repositories.webhooks, auditService),
+	}
+	if eventConfig.Publisher != "" {
+		notifiers = append(notifiers, event.NewService(repositories.events))
+	}
+	notifiers = append(notifiers, others...)
+	return event.NewRecorder(auditService, notifiers...)
+}
+
+// schedulePurgeDeleted periodically purges the organizations and projects
+// deleted longer than the retention ago, projects go first as an
+// organization can't be purged while its projects refer to it
+func schedulePurgeDeleted(ctx context.Context, logger log.Logger, retention time.Duration, deps api.Deps) (*cron.Cron, error) {
+	c := cron.New(cron.WithChain(
+		cron.SkipIfStillRunning(cron.DefaultLogger),
+	))
+	if _, err := c.AddFunc("@every "+purgeDeletedInterval.String(), func() {
+		if count, err := deps.ProjectService.PurgeDeleted(ctx, retention); err != nil {
+			logger.Warn("failed to purge deleted projects", "err", err)
+		} else if count > 0 {
+			logger.Info("purged deleted projects", "count", count)
+		}
+
+		if count, err := deps.OrgService.PurgeDeleted(ctx, retention); err != nil {
+			logger.Warn("failed to purge deleted organizations", "err", err)
+		} else if count > 0 {
+			logger.Info("purged deleted organizations", "count", count)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	c.Start()
+
+	return c, nil
+}
+
+// scheduleIdempotencyCleanup periodically deletes the idempotency keys past
+// their ttl
+func scheduleIdempotencyCleanup(ctx context.Context, logger log.Logger, service *idempotency.Service) (*cron.Cron, error) {
+	c := cron.New(cron.WithChain(
+		cron.SkipIfStillRunning(cron.DefaultLogger),
+	))
+	if _, err := c.AddFunc("@every "+idempotencyCleanupInterval.String(), func() {
+		if count, err := service.DeleteExpired(ctx); err != nil {
+			logger.Warn("failed to delete expired idempotency keys", "err", err)
+		} else if count > 0 {
+			logger.Info("deleted expired idempotency keys", "count", count)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	c.Start()
+
+	return c, nil
+}
+
+func newDirectoryService(deps api.Deps, cfg directory.Config) *directory.Service {
+	return directory.NewService(ldap.NewDirectory(cfg), deps.UserService, deps.GroupService, deps.OrgService, cfg)
+}
+
 func setupNewRelic(cfg config.NewRelic, logger log.Logger) (newrelic.Application, error) {
 	nrCfg := newrelic.NewConfig(cfg.AppName, cfg.License)
 	nrCfg.Enabled = cfg.Enabled
@@ -254,12 +547,16 @@ func setupNewRelic(cfg config.NewRelic, logger log.Logger) (newrelic.Application
 	return nil, nil
 }
 
-func setupDB(cfg db.Config, logger log.Logger) (dbc *db.Client, err error) {
+func setupDB(cfg db.Config, resolver *secrets.Resolver, logger log.Logger) (dbc *db.Client, err error) {
 	// prefer use pgx instead of lib/pq for postgres to catch pg error
 	if cfg.Driver == "postgres" {
 		cfg.Driver = "pgx"
 	}
-	dbc, err = db.New(cfg)
+	if cfg.Password != "" {
+		dbc, err = db.NewWithPassword(cfg, resolver.Value(cfg.Password).Get)
+	} else {
+		dbc, err = db.New(cfg)
+	}
 	if err != nil {
 		err = fmt.Errorf("failed to setup db: %w", err)
 		return
@@ -267,3 +564,116 @@ func setupDB(cfg db.Config, logger log.Logger) (dbc *db.Client, err error) {
 
 	return
 }
+
+// setupSpiceDB connects to spicedb with its pre shared key, fetched again
+// when it is a secret reference that expired
+func setupSpiceDB(cfg spicedb.Config, resolver *secrets.Resolver, logger log.Logger) (*spicedb.SpiceDB, error) {
+	return spicedb.New(cfg, resolver.Value(cfg.PreSharedKey).Get, logger)
+}
+
+// newCheckCache keeps the results of the permission checks in redis when
+// its address is set, in the memory of the instance otherwise
+func newCheckCache(cfg spicedb.CheckCacheConfig) spicedb.CheckCache {
+	if cfg.Redis.Addr != "" {
+		return spicedb.NewRedisCheckCache(cfg.Redis)
+	}
+	return spicedb.NewLRUCheckCache(cfg.Size)
+}
+
+// authzEngine has the repositories of the engine the permissions are
+// evaluated by
+type authzEngine struct {
+	relations authzRelationRepository
+	policies  authzPolicyRepository
+	roles     role.AuthzRepository
+	// spiceDB is nil unless the engine is spicedb
+	spiceDB *spicedb.SpiceDB
+	// healthCheck checks the engine when it is a service of its own
+	healthCheck health.Check
+}
+
+type authzRelationRepository interface {
+	relation.AuthzRepository
+	relation.TupleRepository
+	Import(ctx context.Context, tuples []relation.Tuple) error
+}
+
+type authzPolicyRepository interface {
+	policy.AuthzRepository
+	schema.AuthzEngine
+	ReadSchema(ctx context.Context) (string, error)
+}
+
+// setupAuthzEngine sets up the authz engine of the config, spicedb unless
+// the embedded engine evaluating the tuples kept in the database or openfga
+// is selected. Both of them keep the schema in the database.
+func setupAuthzEngine(cfg *config.Shield, dbc *db.Client, resolver *secrets.Resolver, logger log.Logger) (authzEngine, error) {
+	switch cfg.Authz.Engine {
+	case "", config.AuthzEngineSpiceDB:
+		sdb, err := setupSpiceDB(cfg.SpiceDB, resolver, logger)
+		if err != nil {
+			return authzEngine{}, err
+		}
+		return authzEngine{
+			relations:   spicedb.NewRelationRepository(sdb),
+			policies:    spicedb.NewPolicyRepository(sdb),
+			roles:       spicedb.NewRoleRepository(sdb),
+			spiceDB:     sdb,
+			healthCheck: health.Check{Name: "spicedb", Run: sdb.Check},
+		}, nil
+	case config.AuthzEngineEmbedded:
+		engine := embedded.New(newStores(dbc).authz)
+		logger.Info("evaluating permissions with the embedded authz engine")
+		return authzEngine{
+			relations: embedded.NewRelationRepository(engine),
+			policies:  embedded.NewPolicyRepository(engine),
+			roles:     embedded.NewRoleRepository(engine),
+		}, nil
+	case config.AuthzEngineOpenFGA:
+		var token func(ctx context.Context) (string, error)
+		if cfg.OpenFGA.APIToken != "" {
+			token = resolver.Value(cfg.OpenFGA.APIToken).Get
+		}
+		fga, err := openfga.New(context.Background(), cfg.OpenFGA, token, newStores(dbc).authz, logger)
+		if err != nil {
+			return authzEngine{}, err
+		}
+		return authzEngine{
+			relations:   openfga.NewRelationRepository(fga),
+			policies:    openfga.NewPolicyRepository(fga),
+			roles:       openfga.NewRoleRepository(fga),
+			healthCheck: health.Check{Name: "openfga", Run: fga.Check},
+		}, nil
+	default:
+		return authzEngine{}, fmt.Errorf("unknown authz engine %q, it is either %s, %s or %s",
+			cfg.Authz.Engine, config.AuthzEngineSpiceDB, config.AuthzEngineEmbedded, config.AuthzEngineOpenFGA)
+	}
+}
+
+// serverAuthzEngine sets up the authz engine of the server config for the
+// commands not needing the database otherwise, the engines other than
+// spicedb connect to it
+func serverAuthzEngine(configFile string) (authzEngine, func(), error) {
+	appConfig, err := config.Load(configFile)
+	if err != nil {
+		return authzEngine{}, nil, err
+	}
+	logger := shieldlogger.InitLogger(appConfig.Log)
+	resolver := secrets.NewResolver(appConfig.Secrets)
+
+	cleanup := func() {}
+	var dbClient *db.Client
+	if appConfig.Authz.Engine != "" && appConfig.Authz.Engine != config.AuthzEngineSpiceDB {
+		if dbClient, err = setupDB(appConfig.DB, resolver, logger); err != nil {
+			return authzEngine{}, nil, err
+		}
+		cleanup = func() { dbClient.Close() }
+	}
+
+	authz, err := setupAuthzEngine(appConfig, dbClient, resolver, logger)
+	if err != nil {
+		cleanup()
+		return authzEngine{}, nil, err
+	}
+	return authz, cleanup, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/url"
 
 	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/group"
 	"github.com/odpf/shield/core/project"
 	"github.com/odpf/shield/core/relation"
 	"github.com/odpf/shield/core/resource"
@@ -15,12 +17,15 @@ import (
 	"github.com/odpf/shield/internal/proxy"
 	"github.com/odpf/shield/internal/proxy/hook"
 	authz_hook "github.com/odpf/shield/internal/proxy/hook/authz"
+	response_hook "github.com/odpf/shield/internal/proxy/hook/response"
 	"github.com/odpf/shield/internal/proxy/middleware/attributes"
 	"github.com/odpf/shield/internal/proxy/middleware/authz"
 	"github.com/odpf/shield/internal/proxy/middleware/basic_auth"
+	"github.com/odpf/shield/internal/proxy/middleware/grpc_status"
 	"github.com/odpf/shield/internal/proxy/middleware/observability"
 	"github.com/odpf/shield/internal/proxy/middleware/prefix"
 	"github.com/odpf/shield/internal/proxy/middleware/rulematch"
+	"github.com/odpf/shield/internal/proxy/token"
 	"github.com/odpf/shield/internal/store/blob"
 )
 
@@ -33,50 +38,74 @@ func serveProxies(
 	resourceService *resource.Service,
 	relationService *relation.Service,
 	userService *user.Service,
+	groupService *group.Service,
 	projectService *project.Service,
-) ([]func() error, []func(ctx context.Context) error, error) {
+	ruleStore rule.Repository,
+	minter *token.Minter,
+) ([]func() error, []func(ctx context.Context) error, map[string]*rule.Service, map[string]*proxy.Breakers, error) {
 	var cleanUpBlobs []func() error
 	var cleanUpProxies []func(ctx context.Context) error
+	ruleServices := map[string]*rule.Service{}
+	breakers := map[string]*proxy.Breakers{}
 
 	for _, svcConfig := range cfg.Services {
 		hookPipeline := buildHookPipeline(logger, resourceService, relationService, identityProxyHeaderKey)
 
+		tlsBackends, err := proxy.NewTLSBackends(ctx, logger, svcConfig.Backends)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		breakers[svcConfig.Name] = proxy.NewBreakers(svcConfig.Name)
 		h2cProxy := proxy.NewH2c(
-			proxy.NewH2cRoundTripper(logger, hookPipeline),
+			proxy.NewH2cRoundTripper(logger, hookPipeline, breakers[svcConfig.Name], tlsBackends),
 			proxy.NewDirector(),
 		)
 
 		// load rules sets
 		if svcConfig.RulesPath == "" {
-			return nil, nil, errors.New("ruleset field cannot be left empty")
+			return nil, nil, nil, nil, errors.New("ruleset field cannot be left empty")
 		}
 
 		ruleBlobFS, err := blob.NewStore(ctx, svcConfig.RulesPath, svcConfig.RulesPathSecret)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, nil, err
 		}
 
-		ruleBlobRepository := blob.NewRuleRepository(logger, ruleBlobFS)
+		ruleBlobRepository := blob.NewRuleRepository(logger, ruleBlobFS).WithStore(ruleStore, svcConfig.Name)
 		if err := ruleBlobRepository.InitCache(ctx, ruleCacheRefreshDelay); err != nil {
-			return nil, nil, err
+			return nil, nil, nil, nil, err
 		}
+		ruleBlobRepository.WatchStore(ctx, ruleStoreWatchInterval)
 		cleanUpBlobs = append(cleanUpBlobs, ruleBlobRepository.Close)
+		// local rule files are reloaded as soon as they change
+		if rulesURL, err := url.Parse(svcConfig.RulesPath); err == nil && rulesURL.Scheme == "file" {
+			if err := ruleBlobRepository.Watch(ctx, rulesURL.Path); err != nil {
+				return nil, nil, nil, nil, err
+			}
+		}
 
 		ruleService := rule.NewService(ruleBlobRepository)
+		ruleServices[svcConfig.Name] = ruleService
 
-		middlewarePipeline := buildMiddlewarePipeline(logger, h2cProxy, identityProxyHeaderKey, userIDHeaderKey, resourceService, userService, ruleService, projectService)
+		middlewarePipeline := buildMiddlewarePipeline(logger, h2cProxy, identityProxyHeaderKey, userIDHeaderKey, resourceService, userService, groupService, ruleService, projectService, minter)
 
-		cps := proxy.Serve(ctx, logger, svcConfig, middlewarePipeline)
+		cps, err := proxy.Serve(ctx, logger, svcConfig, middlewarePipeline)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
 		cleanUpProxies = append(cleanUpProxies, cps)
 	}
 
 	logger.Info("[shield] proxy is up")
-	return cleanUpBlobs, cleanUpProxies, nil
+	return cleanUpBlobs, cleanUpProxies, ruleServices, breakers, nil
 }
 
 func buildHookPipeline(log log.Logger, resourceService v1beta1.ResourceService, relationService v1beta1.RelationService, identityProxyHeaderKey string) hook.Service {
+	// Note: execution order is top down, the authz hook records the resources
+	// created before the response hook enriches the response with them
 	rootHook := hook.New()
-	return authz_hook.New(log, rootHook, rootHook, resourceService, relationService, identityProxyHeaderKey)
+	responseHook := response_hook.New(log, rootHook, rootHook, resourceService, identityProxyHeaderKey)
+	return authz_hook.New(log, responseHook, rootHook, resourceService, relationService, identityProxyHeaderKey)
 }
 
 // buildPipeline builds middleware sequence
@@ -86,15 +115,18 @@ func buildMiddlewarePipeline(
 	identityProxyHeaderKey, userIDHeaderKey string,
 	resourceService *resource.Service,
 	userService *user.Service,
+	groupService *group.Service,
 	ruleService *rule.Service,
 	projectService *project.Service,
+	minter *token.Minter,
 ) http.Handler {
 	// Note: execution order is bottom up
 	prefixWare := prefix.New(logger, proxy)
-	casbinAuthz := authz.New(logger, prefixWare, userIDHeaderKey, resourceService, userService)
+	casbinAuthz := authz.New(logger, prefixWare, userIDHeaderKey, resourceService, userService, groupService, minter)
 	basicAuthn := basic_auth.New(logger, casbinAuthz)
 	attributeExtractor := attributes.New(logger, basicAuthn, identityProxyHeaderKey, projectService)
 	matchWare := rulematch.New(logger, attributeExtractor, rulematch.NewRouteMatcher(ruleService))
-	observability := observability.New(logger, matchWare)
+	grpcStatus := grpc_status.New(logger, matchWare)
+	observability := observability.New(logger, grpcStatus)
 	return observability
 }
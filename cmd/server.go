@@ -1,15 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
 	"github.com/odpf/shield/config"
-	"github.com/odpf/shield/internal/store/postgres/migrations"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/bootstrap"
+	"github.com/odpf/shield/internal/health"
 	"github.com/odpf/shield/pkg/db"
 	shieldlogger "github.com/odpf/shield/pkg/logger"
+	"github.com/odpf/shield/pkg/secrets"
 	"github.com/spf13/cobra"
 	cli "github.com/spf13/cobra"
 )
@@ -26,15 +39,22 @@ func ServerCommand() *cobra.Command {
 			$ shield server start -c ./config.yaml
 			$ shield server migrate
 			$ shield server migrate -c ./config.yaml
+			$ shield server migrate-status
 			$ shield server migrate-rollback
-			$ shield server migrate-rollback -c ./config.yaml
+			$ shield server migrate-rollback 20230120100000 -c ./config.yaml
+			$ shield server ping
+			$ shield server ping --url http://shield.example.com:8080
+			$ shield server gc --dry-run
 		`),
 	}
 
 	cmd.AddCommand(serverInitCommand())
 	cmd.AddCommand(serverStartCommand())
 	cmd.AddCommand(serverMigrateCommand())
+	cmd.AddCommand(serverMigrateStatusCommand())
 	cmd.AddCommand(serverMigrateRollbackCommand())
+	cmd.AddCommand(serverPingCommand())
+	cmd.AddCommand(serverGCCommand())
 
 	return cmd
 }
@@ -43,6 +63,8 @@ func serverInitCommand() *cobra.Command {
 	var configFile string
 	var resourcesURL string
 	var rulesURL string
+	var bootstrapFile string
+	var serverConfigFile string
 
 	c := &cli.Command{
 		Use:   "init",
@@ -50,9 +72,21 @@ func serverInitCommand() *cobra.Command {
 		Long: heredoc.Doc(`
 			Initializing server. Creating a sample of shield server config.
 			Default: ./config.yaml
+
+			With a bootstrap file, the namespaces, actions and roles it declares, its
+			admin user and the organization the admin owns are created instead, with
+			the server config. Entities which exist are left as they are unless the
+			file changes them, so it can be run again.
+		`),
+		Example: heredoc.Doc(`
+			$ shield server init
+			$ shield server init -f bootstrap.yaml -c ./config.yaml
 		`),
-		Example: "shield server init",
 		RunE: func(cmd *cli.Command, args []string) error {
+			if bootstrapFile != "" {
+				return runBootstrap(cmd, bootstrapFile, serverConfigFile)
+			}
+
 			cwd, err := os.Getwd()
 			if err != nil {
 				return err
@@ -91,10 +125,45 @@ func serverInitCommand() *cobra.Command {
 		GCS Bucket "gs://shield-bucket-example"
 		(default: file://{pwd}/rules)
 	`))
+	c.Flags().StringVarP(&bootstrapFile, "file", "f", "", "Path to the bootstrap file of a fresh deployment")
+	c.Flags().StringVarP(&serverConfigFile, "config", "c", "", "Config file path, used with a bootstrap file")
 
 	return c
 }
 
+func runBootstrap(cmd *cli.Command, bootstrapFile, configFile string) error {
+	spec, err := bootstrap.Load(bootstrapFile)
+	if err != nil {
+		return err
+	}
+
+	spinner := printer.Spin("")
+	defer spinner.Stop()
+
+	deps, cleanup, err := serverDeps(cmd.Context(), configFile)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	steps, runErr := bootstrap.NewBootstrapper(deps.NamespaceService, deps.ActionService, deps.RoleService,
+		deps.UserService, deps.OrgService, deps.RelationService).Run(cmd.Context(), spec)
+
+	report := [][]string{}
+	report = append(report, []string{"KIND", "ID", "RESULT"})
+	for _, step := range steps {
+		report = append(report, []string{step.Kind, step.ID, step.Result})
+	}
+	spinner.Stop()
+	printer.Table(os.Stdout, report)
+	if runErr != nil {
+		return runErr
+	}
+
+	fmt.Println("deployment bootstrapped")
+	return nil
+}
+
 func serverStartCommand() *cobra.Command {
 	var configFile string
 
@@ -123,17 +192,69 @@ func serverMigrateCommand() *cobra.Command {
 	c := &cli.Command{
 		Use:     "migrate",
 		Short:   "Run DB Schema Migrations",
-		Example: "shield migrate",
+		Example: "shield server migrate",
 		RunE: func(c *cli.Command, args []string) error {
-			appConfig, err := config.Load(configFile)
+			dbConfig, err := migrationDBConfig(c.Context(), configFile)
 			if err != nil {
-				panic(err)
+				return err
+			}
+
+			migrationFs, migrationPath := migrationsOf(dbConfig.Driver)
+			if err := db.RunMigrations(dbConfig, migrationFs, migrationPath); err != nil {
+				return err
+			}
+
+			status, err := db.GetMigrationStatus(dbConfig, migrationFs, migrationPath)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("database migrated to version %d\n", status.Version)
+			return nil
+		},
+	}
+
+	c.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	return c
+}
+
+func serverMigrateStatusCommand() *cobra.Command {
+	var configFile string
+
+	c := &cli.Command{
+		Use:     "migrate-status",
+		Short:   "Show the applied and pending DB Schema Migrations",
+		Example: "shield server migrate-status",
+		Args:    cli.NoArgs,
+		RunE: func(c *cli.Command, args []string) error {
+			dbConfig, err := migrationDBConfig(c.Context(), configFile)
+			if err != nil {
+				return err
+			}
+
+			migrationFs, migrationPath := migrationsOf(dbConfig.Driver)
+			status, err := db.GetMigrationStatus(dbConfig, migrationFs, migrationPath)
+			if err != nil {
+				return err
+			}
+
+			pending := 0
+			report := [][]string{}
+			report = append(report, []string{"VERSION", "NAME", "STATUS"})
+			for _, m := range status.Migrations {
+				migrationStatus := "applied"
+				switch {
+				case !m.Applied:
+					migrationStatus = "pending"
+					pending++
+				case status.Dirty && m.Version == status.Version:
+					migrationStatus = "dirty"
+				}
+				report = append(report, []string{strconv.FormatUint(uint64(m.Version), 10), m.Name, migrationStatus})
 			}
 
-			return db.RunMigrations(db.Config{
-				Driver: appConfig.DB.Driver,
-				URL:    appConfig.DB.URL,
-			}, migrations.MigrationFs, migrations.ResourcePath)
+			fmt.Printf(" \nDatabase at version %d, %d pending migrations\n \n", status.Version, pending)
+			printer.Table(os.Stdout, report)
+			return nil
 		},
 	}
 
@@ -145,22 +266,237 @@ func serverMigrateRollbackCommand() *cobra.Command {
 	var configFile string
 
 	c := &cli.Command{
-		Use:     "migration-rollback",
-		Short:   "Run DB Schema Migrations Rollback to last state",
-		Example: "shield migration-rollback",
+		Use:     "migrate-rollback [version]",
+		Aliases: []string{"migration-rollback"},
+		Short:   "Run DB Schema Migrations Rollback to last state or to a version",
+		Long: heredoc.Doc(`
+			Roll back the DB Schema Migrations.
+
+			The last migration applied is rolled back unless a version is given, the
+			migrations after the version are rolled back then. All of the migrations
+			are rolled back with version 0.
+		`),
+		Example: heredoc.Doc(`
+			$ shield server migrate-rollback
+			$ shield server migrate-rollback 20230120100000
+		`),
+		Args: cli.MaximumNArgs(1),
 		RunE: func(c *cli.Command, args []string) error {
-			appConfig, err := config.Load(configFile)
+			dbConfig, err := migrationDBConfig(c.Context(), configFile)
 			if err != nil {
-				panic(err)
+				return err
+			}
+
+			migrationFs, migrationPath := migrationsOf(dbConfig.Driver)
+			if len(args) == 0 {
+				return db.RunRollback(dbConfig, migrationFs, migrationPath)
+			}
+
+			version, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid migration version %q", args[0])
+			}
+			if err := db.RunRollbackTo(dbConfig, migrationFs, migrationPath, uint(version)); err != nil {
+				return err
+			}
+			fmt.Printf("database rolled back to version %d\n", version)
+			return nil
+		},
+	}
+
+	c.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	return c
+}
+
+func migrationDBConfig(ctx context.Context, configFile string) (db.Config, error) {
+	appConfig, err := config.Load(configFile)
+	if err != nil {
+		return db.Config{}, err
+	}
+	dbConfig := db.Config{
+		Driver: appConfig.DB.Driver,
+		URL:    appConfig.DB.URL,
+	}
+	if appConfig.DB.Password != "" {
+		// the migrations run once, the password is only fetched for them
+		password, err := secrets.NewResolver(appConfig.Secrets).Resolve(ctx, appConfig.DB.Password)
+		if err != nil {
+			return db.Config{}, err
+		}
+		if dbConfig.URL, err = db.WithPassword(dbConfig.URL, password); err != nil {
+			return db.Config{}, err
+		}
+	}
+	return dbConfig, nil
+}
+
+func serverPingCommand() *cobra.Command {
+	var configFile string
+	var serverURL string
+
+	c := &cli.Command{
+		Use:   "ping",
+		Short: "Check the server is alive and ready",
+		Long: heredoc.Doc(`
+			Check the server is alive and ready to serve requests.
+
+			The server is ready once postgres and the authz engine, spicedb or openfga
+			unless the embedded one is used, are reachable and the migrations are applied. The server is found at the host and port of the
+			server config unless its url is given.
+		`),
+		Example: "shield server ping",
+		RunE: func(cmd *cli.Command, args []string) error {
+			if serverURL == "" {
+				appConfig, err := config.Load(configFile)
+				if err != nil {
+					return err
+				}
+				host := appConfig.App.Host
+				if host == "" || host == "0.0.0.0" {
+					host = "localhost"
+				}
+				serverURL = "http://" + net.JoinHostPort(host, strconv.Itoa(appConfig.App.Port))
+			}
+			serverURL = strings.TrimSuffix(serverURL, "/")
+
+			client := &http.Client{Timeout: health.CheckTimeout + 5*time.Second}
+			if _, err := getHealth(client, serverURL+"/healthz"); err != nil {
+				return fmt.Errorf("server is not alive: %w", err)
+			}
+
+			result, err := getHealth(client, serverURL+"/readyz")
+			if err != nil {
+				return err
+			}
+
+			names := make([]string, 0, len(result.Checks))
+			for name := range result.Checks {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			report := [][]string{}
+			report = append(report, []string{"CHECK", "STATUS", "ERROR"})
+			for _, name := range names {
+				report = append(report, []string{name, result.Checks[name].Status, result.Checks[name].Error})
+			}
+			fmt.Printf(" \nServer at %s is alive and %s\n \n", serverURL, readiness(result))
+			printer.Table(os.Stdout, report)
+
+			if !result.Ready() {
+				return errors.New("server is not ready")
+			}
+			return nil
+		},
+	}
+
+	c.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	c.Flags().StringVar(&serverURL, "url", "", "URL of the server, e.g. http://localhost:8080")
+	return c
+}
+
+func getHealth(client *http.Client, url string) (health.Result, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return health.Result{}, err
+	}
+	defer resp.Body.Close()
+
+	var result health.Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return health.Result{}, fmt.Errorf("unexpected response of %s with status %d", url, resp.StatusCode)
+	}
+	return result, nil
+}
+
+func readiness(result health.Result) string {
+	if result.Ready() {
+		return "ready"
+	}
+	return "not ready"
+}
+
+func serverGCCommand() *cobra.Command {
+	var configFile string
+	var namespaces []string
+	var dryRun bool
+
+	c := &cli.Command{
+		Use:   "gc",
+		Short: "Remove the orphaned relation tuples",
+		Long: heredoc.Doc(`
+			Remove the relation tuples of spicedb whose object or subject no longer
+			exists in the database.
+
+			Purging a project removes its resources and their relations from the database in
+			a single transaction, the tuples are removed from spicedb afterwards and are
+			left behind when that fails. The tuples on the objects of every namespace are
+			checked unless namespaces are given, --dry-run only lists the orphaned tuples.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield server gc --dry-run
+			$ shield server gc --namespace=shield/project -c ./config.yaml
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			dbClient, appConfig, err := serverDB(configFile)
+			if err != nil {
+				return err
+			}
+			defer dbClient.Close()
+
+			authz, cleanup, err := serverAuthzEngine(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if len(namespaces) == 0 {
+				all, err := newStores(dbClient).namespaces.List(cmd.Context())
+				if err != nil {
+					return err
+				}
+				for _, ns := range all {
+					namespaces = append(namespaces, ns.ID)
+				}
+			}
+
+			collector := relation.NewCollector(authz.relations, newStores(dbClient).objects, newAuditRecorder(dbClient, appConfig.Event))
+			orphans, err := collector.Orphans(cmd.Context(), namespaces)
+			if err != nil {
+				return err
 			}
 
-			return db.RunRollback(db.Config{
-				Driver: appConfig.DB.Driver,
-				URL:    appConfig.DB.URL,
-			}, migrations.MigrationFs, migrations.ResourcePath)
+			report := [][]string{}
+			report = append(report, []string{"TUPLE", "MISSING"})
+			for _, o := range orphans {
+				report = append(report, []string{o.String(), o.Missing})
+			}
+
+			if !dryRun && len(orphans) > 0 {
+				if err := collector.Collect(cmd.Context(), orphans); err != nil {
+					return err
+				}
+			}
+
+			spinner.Stop()
+			if dryRun {
+				fmt.Printf(" \nFound %d orphaned tuples, run without --dry-run to remove them\n \n", len(orphans))
+			} else {
+				fmt.Printf(" \nRemoved %d orphaned tuples\n \n", len(orphans))
+			}
+			if len(orphans) > 0 {
+				printer.Table(os.Stdout, report)
+			}
+			return nil
 		},
 	}
 
 	c.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	c.Flags().StringSliceVarP(&namespaces, "namespace", "n", nil, "Namespaces whose objects' tuples are checked, all of them if not set")
+	c.Flags().BoolVar(&dryRun, "dry-run", false, "List the orphaned tuples without removing them")
 	return c
 }
@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/config"
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/pkg/db"
+	shieldlogger "github.com/odpf/shield/pkg/logger"
+	"github.com/odpf/shield/pkg/secrets"
+	cli "github.com/spf13/cobra"
+)
+
+// the service accounts and their keys are created and listed with
+// endpoints the gateway serves next to the rpcs, there are no rpcs for them
+const (
+	serviceAccountsPath    = "/admin/v1beta1/serviceaccounts"
+	serviceAccountKeysPath = "/admin/v1beta1/serviceaccounts/keys"
+)
+
+type serviceAccountResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type serviceAccountKeyResponse struct {
+	ID         string    `json:"id"`
+	Algorithm  string    `json:"algorithm"`
+	CreatedAt  time.Time `json:"created_at"`
+	PrivateKey string    `json:"private_key"`
+}
+
+func ServiceAccountCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:     "serviceaccount",
+		Aliases: []string{"serviceaccounts"},
+		Short:   "Manage service accounts",
+		Long: heredoc.Doc(`
+			Work with service accounts.
+
+			A service account is a machine user, it can be added to groups, projects and
+			policies like any other user. Requests are authenticated as a service account
+			with a JWT signed by one of its keys and sent as a bearer token in the
+			authorization header.
+
+			Service accounts are members of an organization, the admins of the organization
+			manage them and their keys through the server as the logged in user or the user
+			of the header. Tokens are signed locally.
+		`),
+		Example: heredoc.Doc(`
+			$ shield serviceaccount create billing-job --org=odpf
+			$ shield serviceaccount list
+			$ shield serviceaccount keys create <serviceaccount-id>
+			$ shield serviceaccount keys list <serviceaccount-id>
+			$ shield serviceaccount token <serviceaccount-id> --key-id=<key-id> --key=key.pem
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+	}
+
+	cmd.AddCommand(createServiceAccountCommand(cliConfig))
+	cmd.AddCommand(listServiceAccountCommand(cliConfig))
+	cmd.AddCommand(serviceAccountKeysCommand(cliConfig))
+	cmd.AddCommand(serviceAccountTokenCommand())
+
+	bindFlagsFromClientConfig(cmd)
+
+	return cmd
+}
+
+func createServiceAccountCommand(cliConfig *Config) *cli.Command {
+	var orgID, header string
+
+	cmd := &cli.Command{
+		Use:   "create <name>",
+		Short: "Create a service account",
+		Long: heredoc.Doc(`
+			Create a service account in an organization as the logged in user or the user
+			of the header, who has to be an admin of the organization. The service account
+			is a member of the organization, its admins manage it and its keys.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield serviceaccount create billing-job --org=odpf
+			$ shield serviceaccount create billing-job --org=odpf --header=<key>:<value>
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var created serviceAccountResponse
+			if err := postAdminAPI(cmd.Context(), cliConfig, serviceAccountsPath, url.Values{"name": {args[0]}, "org": {orgID}}, header, &created); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("successfully created service account %s with id %s, it is the user %s with email %s\n",
+				created.Name, created.ID, created.UserID, created.Email)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&orgID, "org", "o", "", "ID or slug of the organization the service account is a member of")
+	cmd.MarkFlagRequired("org")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func listServiceAccountCommand(cliConfig *Config) *cli.Command {
+	var header, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List the service accounts the user manages",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield serviceaccount list
+			$ shield serviceaccount list --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var listed struct {
+				ServiceAccounts []serviceAccountResponse `json:"serviceaccounts"`
+			}
+			if err := getAdminAPI(cmd.Context(), cliConfig, serviceAccountsPath, url.Values{}, header, &listed); err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAME", "USER ID", "CREATED AT"})
+			for _, su := range listed.ServiceAccounts {
+				report = append(report, []string{su.ID, su.Name, su.UserID, su.CreatedAt.Format(time.RFC3339)})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d service accounts\n \n", len(listed.ServiceAccounts))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func serviceAccountKeysCommand(cliConfig *Config) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "keys",
+		Short: "Manage the keys of a service account",
+		Example: heredoc.Doc(`
+			$ shield serviceaccount keys create <serviceaccount-id> --algorithm=ed25519 --output=key.pem
+			$ shield serviceaccount keys list <serviceaccount-id>
+		`),
+	}
+
+	cmd.AddCommand(createServiceAccountKeyCommand(cliConfig))
+	cmd.AddCommand(listServiceAccountKeyCommand(cliConfig))
+
+	return cmd
+}
+
+func createServiceAccountKeyCommand(cliConfig *Config) *cli.Command {
+	var algorithm, outFile, header string
+
+	cmd := &cli.Command{
+		Use:   "create <serviceaccount-id>",
+		Short: "Create a key for a service account",
+		Long: heredoc.Doc(`
+			Create a key for a service account.
+
+			The private key is only shown once, shield keeps the public key alone.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield serviceaccount keys create <serviceaccount-id>
+			$ shield serviceaccount keys create <serviceaccount-id> --algorithm=rsa --output=key.pem
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			var created serviceAccountKeyResponse
+			if err := postAdminAPI(cmd.Context(), cliConfig, serviceAccountKeysPath, url.Values{"id": {args[0]}, "algorithm": {algorithm}}, header, &created); err != nil {
+				return err
+			}
+
+			if outFile == "" {
+				fmt.Printf("successfully created key %s, its private key is\n \n%s", created.ID, created.PrivateKey)
+				return nil
+			}
+			if err := os.WriteFile(outFile, []byte(created.PrivateKey), 0o600); err != nil {
+				return err
+			}
+			fmt.Printf("successfully created key %s, its private key is written to %s\n", created.ID, outFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&algorithm, "algorithm", "a", serviceuser.AlgorithmEd25519, "Key algorithm: rsa or ed25519")
+	cmd.Flags().StringVarP(&outFile, "output", "o", "", "Path of the file the private key is written to, stdout if not set")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func listServiceAccountKeyCommand(cliConfig *Config) *cli.Command {
+	var header, output string
+
+	cmd := &cli.Command{
+		Use:   "list <serviceaccount-id>",
+		Short: "List the keys of a service account",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield serviceaccount keys list <serviceaccount-id>
+			$ shield serviceaccount keys list <serviceaccount-id> --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var listed struct {
+				Keys []serviceAccountKeyResponse `json:"keys"`
+			}
+			if err := getAdminAPI(cmd.Context(), cliConfig, serviceAccountKeysPath, url.Values{"id": {args[0]}}, header, &listed); err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "ALGORITHM", "CREATED AT"})
+			for _, k := range listed.Keys {
+				report = append(report, []string{k.ID, k.Algorithm, k.CreatedAt.Format(time.RFC3339)})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d keys\n \n", len(listed.Keys))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func serviceAccountTokenCommand() *cli.Command {
+	var keyID, keyFile string
+	var ttl time.Duration
+
+	cmd := &cli.Command{
+		Use:   "token <serviceaccount-id>",
+		Short: "Sign a token for a service account",
+		Long: heredoc.Doc(`
+			Sign a token for a service account with one of its private keys.
+
+			The token is sent as "Authorization: Bearer <token>", it is signed locally
+			and nothing is sent to shield. Tokens signed by other means need the id of
+			the service account in sub, shield in aud and iat and exp claims at most an
+			hour apart.
+		`),
+		Args: cli.ExactArgs(1),
+		Annotations: map[string]string{
+			"client": "false",
+		},
+		Example: heredoc.Doc(`
+			$ shield serviceaccount token <serviceaccount-id> --key-id=<key-id> --key=key.pem
+			$ shield serviceaccount token <serviceaccount-id> --key-id=<key-id> --key=key.pem --ttl=5m
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			privateKey, err := os.ReadFile(keyFile)
+			if err != nil {
+				return err
+			}
+
+			token, err := serviceuser.SignToken(keyID, args[0], privateKey, ttl)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(token)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&keyID, "key-id", "", "ID of the key")
+	cmd.MarkFlagRequired("key-id")
+	cmd.Flags().StringVarP(&keyFile, "key", "k", "", "Path to the private key file")
+	cmd.MarkFlagRequired("key")
+	cmd.Flags().DurationVar(&ttl, "ttl", 15*time.Minute, fmt.Sprintf("How long the token is valid for, at most %s", serviceuser.MaxTokenTTL))
+
+	return cmd
+}
+
+// serverDB connects to the database of the server config, the config is
+// returned along for the services the commands build
+func serverDB(configFile string) (*db.Client, *config.Shield, error) {
+	appConfig, err := config.Load(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger := shieldlogger.InitLogger(appConfig.Log)
+
+	dbClient, err := setupDB(appConfig.DB, secrets.NewResolver(appConfig.Secrets), logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dbClient, appConfig, nil
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"embed"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/apikey"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/folder"
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/odpf/shield/core/invitation"
+	"github.com/odpf/shield/core/metaschema"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/core/signingkey"
+	"github.com/odpf/shield/core/usage"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/core/webhook"
+	"github.com/odpf/shield/internal/store/embedded"
+	"github.com/odpf/shield/internal/store/postgres"
+	pgmigrations "github.com/odpf/shield/internal/store/postgres/migrations"
+	"github.com/odpf/shield/internal/store/sqlite"
+	sqlitemigrations "github.com/odpf/shield/internal/store/sqlite/migrations"
+	"github.com/odpf/shield/pkg/db"
+)
+
+// stores has the repositories of the database the server is configured
+// with, postgres unless the driver is sqlite
+type stores struct {
+	actions       action.Repository
+	apiKeys       apikey.Repository
+	audit         audit.Repository
+	authz         embedded.Store
+	events        event.Repository
+	folders       folder.Repository
+	groups        group.Repository
+	idempotency   idempotency.Repository
+	invitations   invitation.Repository
+	metaSchemas   metaschema.Repository
+	namespaces    namespace.Repository
+	objects       usage.ObjectRepository
+	organizations organization.Repository
+	projects      project.Repository
+	relations     relation.Repository
+	resources     resource.Repository
+	roles         role.Repository
+	rules         rule.Repository
+	serviceUsers  serviceuser.Repository
+	sessions      session.Repository
+	signingKeys   signingkey.Repository
+	tagPolicies   resource.TagPolicyRepository
+	usage         usage.Repository
+	users         user.Repository
+	webhooks      webhook.Repository
+}
+
+func newStores(dbc *db.Client) stores {
+	if db.IsSQLite(dbc.DriverName()) {
+		return stores{
+			actions:       sqlite.NewActionRepository(dbc),
+			apiKeys:       sqlite.NewAPIKeyRepository(dbc),
+			audit:         sqlite.NewAuditRepository(dbc),
+			authz:         sqlite.NewAuthzRepository(dbc),
+			events:        sqlite.NewEventRepository(dbc),
+			folders:       sqlite.NewFolderRepository(dbc),
+			groups:        sqlite.NewGroupRepository(dbc),
+			idempotency:   sqlite.NewIdempotencyRepository(dbc),
+			invitations:   sqlite.NewInvitationRepository(dbc),
+			metaSchemas:   sqlite.NewMetaSchemaRepository(dbc),
+			namespaces:    sqlite.NewNamespaceRepository(dbc),
+			objects:       sqlite.NewObjectRepository(dbc),
+			organizations: sqlite.NewOrganizationRepository(dbc),
+			projects:      sqlite.NewProjectRepository(dbc),
+			relations:     sqlite.NewRelationRepository(dbc),
+			resources:     sqlite.NewResourceRepository(dbc),
+			roles:         sqlite.NewRoleRepository(dbc),
+			rules:         sqlite.NewRuleRepository(dbc),
+			serviceUsers:  sqlite.NewServiceUserRepository(dbc),
+			sessions:      sqlite.NewSessionRepository(dbc),
+			signingKeys:   sqlite.NewSigningKeyRepository(dbc),
+			tagPolicies:   sqlite.NewTagPolicyRepository(dbc),
+			usage:         sqlite.NewOrganizationUsageRepository(dbc),
+			users:         sqlite.NewUserRepository(dbc),
+			webhooks:      sqlite.NewWebhookRepository(dbc),
+		}
+	}
+	return stores{
+		actions:       postgres.NewActionRepository(dbc),
+		apiKeys:       postgres.NewAPIKeyRepository(dbc),
+		audit:         postgres.NewAuditRepository(dbc),
+		authz:         postgres.NewAuthzRepository(dbc),
+		events:        postgres.NewEventRepository(dbc),
+		folders:       postgres.NewFolderRepository(dbc),
+		groups:        postgres.NewGroupRepository(dbc),
+		idempotency:   postgres.NewIdempotencyRepository(dbc),
+		invitations:   postgres.NewInvitationRepository(dbc),
+		metaSchemas:   postgres.NewMetaSchemaRepository(dbc),
+		namespaces:    postgres.NewNamespaceRepository(dbc),
+		objects:       postgres.NewObjectRepository(dbc),
+		organizations: postgres.NewOrganizationRepository(dbc),
+		projects:      postgres.NewProjectRepository(dbc),
+		relations:     postgres.NewRelationRepository(dbc),
+		resources:     postgres.NewResourceRepository(dbc),
+		roles:         postgres.NewRoleRepository(dbc),
+		rules:         postgres.NewRuleRepository(dbc),
+		serviceUsers:  postgres.NewServiceUserRepository(dbc),
+		sessions:      postgres.NewSessionRepository(dbc),
+		signingKeys:   postgres.NewSigningKeyRepository(dbc),
+		tagPolicies:   postgres.NewTagPolicyRepository(dbc),
+		usage:         postgres.NewOrganizationUsageRepository(dbc),
+		users:         postgres.NewUserRepository(dbc),
+		webhooks:      postgres.NewWebhookRepository(dbc),
+	}
+}
+
+// newPolicyRepository is the policy repository of the database, logging its
+// queries when the database is configured to
+func newPolicyRepository(dbc *db.Client, logger log.Logger) policy.Repository {
+	logQuery := func(ctx context.Context, query string, args []interface{}, duration time.Duration) {
+		logger.Debug("policy query", "query", query, "args", args, "duration", duration.String())
+	}
+
+	if db.IsSQLite(dbc.DriverName()) {
+		repository := sqlite.NewPolicyRepository(dbc)
+		if !dbc.LogQueries() {
+			return repository
+		}
+		return repository.WithQueryLogger(logQuery)
+	}
+	repository := postgres.NewPolicyRepository(dbc)
+	if !dbc.LogQueries() {
+		return repository
+	}
+	return repository.WithQueryLogger(logQuery)
+}
+
+// migrationsOf are the migrations of the database of the driver and their
+// path in the file system
+func migrationsOf(driver string) (embed.FS, string) {
+	if db.IsSQLite(driver) {
+		return sqlitemigrations.MigrationFs, sqlitemigrations.ResourcePath
+	}
+	return pgmigrations.MigrationFs, pgmigrations.ResourcePath
+}
+
+// dbHealthCheckName is the name the database is checked by in the health
+// of the server
+func dbHealthCheckName(dbc *db.Client) string {
+	if db.IsSQLite(dbc.DriverName()) {
+		return db.DriverSQLite
+	}
+	return "postgres"
+}
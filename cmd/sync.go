@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/config"
+	cli "github.com/spf13/cobra"
+)
+
+func SyncCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "sync",
+		Short: "Sync users and groups from external directories",
+		Long: heredoc.Doc(`
+			Sync the users and groups of external directories into shield.
+		`),
+		Example: heredoc.Doc(`
+			$ shield sync ldap --dry-run
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(syncLDAPCommand())
+
+	return cmd
+}
+
+func syncLDAPCommand() *cli.Command {
+	var configFile string
+	var dryRun bool
+
+	cmd := &cli.Command{
+		Use:   "ldap",
+		Short: "Sync the users and groups of the ldap directory",
+		Long: heredoc.Doc(`
+			Sync the users and groups of the ldap or active directory server of the
+			server config, the server syncs them every ldap.sync_interval as well.
+
+			Users are matched by email, the missing ones are created and the names of
+			the others are updated. Groups are matched by the slug of their name and the
+			missing ones are created in ldap.organization, their members are made the
+			same as in the directory. Users and groups missing from the directory are
+			left as they are.
+
+			Entries that can't be synced are reported as conflicts and skipped, like
+			users without an email, disabled users or groups of another organization.
+			--dry-run only lists the changes and the conflicts.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield sync ldap --dry-run
+			$ shield sync ldap -c ./config.yaml
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			appConfig, err := config.Load(configFile)
+			if err != nil {
+				return err
+			}
+			if !appConfig.LDAP.Enabled() {
+				return errors.New("ldap.url isn't set in the server config")
+			}
+
+			deps, cleanup, err := serverDeps(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			service := newDirectoryService(deps, appConfig.LDAP)
+			plan, err := service.Plan(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				if err := service.Apply(cmd.Context(), plan); err != nil {
+					return err
+				}
+			}
+
+			spinner.Stop()
+			if dryRun {
+				fmt.Printf(" \nFound %d changes, run without --dry-run to make them\n \n", len(plan.Changes))
+			} else {
+				fmt.Printf(" \nMade %d changes\n \n", len(plan.Changes))
+			}
+			if len(plan.Changes) > 0 {
+				report := [][]string{}
+				report = append(report, []string{"ACTION", "KIND", "NAME", "GROUP"})
+				for _, c := range plan.Changes {
+					report = append(report, []string{c.Action, c.Kind, c.Name, c.Group})
+				}
+				printer.Table(os.Stdout, report)
+			}
+
+			if len(plan.Conflicts) > 0 {
+				fmt.Printf(" \nSkipped %d conflicting entries\n \n", len(plan.Conflicts))
+				report := [][]string{}
+				report = append(report, []string{"ENTRY", "CONFLICT"})
+				for _, c := range plan.Conflicts {
+					report = append(report, []string{c.DN, c.Reason})
+				}
+				printer.Table(os.Stdout, report)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the changes without making them")
+
+	return cmd
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	cli "github.com/spf13/cobra"
+)
+
+func tagPolicyResourceCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:     "tag-policy",
+		Aliases: []string{"tag-policies"},
+		Short:   "Manage tag policies of resources",
+		Long: heredoc.Doc(`
+			Work with tag policies.
+
+			A tag policy grants a role of a namespace to a subject on every resource of the
+			namespace carrying all of its tags. The role is granted when the policy is
+			created and whenever resources are tagged through the API, the tags are set in
+			the x-shield-resource-tags header of the requests as comma separated key=value
+			pairs. Sync grants and revokes the roles of every policy after the tags were
+			changed any other way.
+
+			Revoking a role deletes the relation of the subject to the resource, a relation
+			created by hand for the same role goes with it.
+
+			The commands connect to the database and spicedb with the server config.
+		`),
+		Example: heredoc.Doc(`
+			$ shield resource tag-policy create --namespace=entropy/firehose --tags=env=prod --role=viewer --subject=shield/user:jane@odpf.io
+			$ shield resource tag-policy list --namespace=entropy/firehose
+			$ shield resource tag-policy delete <tag-policy-id>
+			$ shield resource tag-policy sync
+		`),
+		Annotations: map[string]string{
+			"client": "false",
+		},
+	}
+
+	cmd.AddCommand(createTagPolicyCommand())
+	cmd.AddCommand(listTagPolicyCommand())
+	cmd.AddCommand(deleteTagPolicyCommand())
+	cmd.AddCommand(syncTagPolicyCommand())
+
+	return cmd
+}
+
+func createTagPolicyCommand() *cli.Command {
+	var configFile, namespaceID, tags, roleID, subject string
+
+	cmd := &cli.Command{
+		Use:   "create",
+		Short: "Grant a role on the resources with tags",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield resource tag-policy create --namespace=entropy/firehose --tags=env=prod,team=data --role=viewer --subject=shield/user:jane@odpf.io
+			$ shield resource tag-policy create --namespace=entropy/firehose --tags=env=staging --role=owner --subject=shield/group:<group-id>
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			parsedTags, err := resource.ParseTags(tags)
+			if err != nil {
+				return err
+			}
+			subjectNS, subjectID, ok := strings.Cut(subject, ":")
+			if !ok || subjectNS == "" || subjectID == "" {
+				return fmt.Errorf("subject %q should be <namespace>:<id>", subject)
+			}
+
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			deps, cleanup, err := serverDeps(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			created, err := deps.ResourceService.CreateTagPolicy(cmd.Context(), resource.TagPolicy{
+				NamespaceID: namespaceID,
+				Tags:        parsedTags,
+				RoleID:      roleID,
+				Subject:     relation.Subject{ID: subjectID, Namespace: subjectNS},
+			})
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("successfully created tag policy with id %s\n", created.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&namespaceID, "namespace", "n", "", "Namespace of the resources")
+	cmd.MarkFlagRequired("namespace")
+	cmd.Flags().StringVarP(&tags, "tags", "t", "", "Comma separated key=value pairs the resources carry")
+	cmd.MarkFlagRequired("tags")
+	cmd.Flags().StringVarP(&roleID, "role", "r", "", "Name of the role of the namespace granted")
+	cmd.MarkFlagRequired("role")
+	cmd.Flags().StringVarP(&subject, "subject", "s", "", "Subject granted the role as <namespace>:<id>, users by id or email")
+	cmd.MarkFlagRequired("subject")
+
+	return cmd
+}
+
+func listTagPolicyCommand() *cli.Command {
+	var configFile, namespaceID, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List tag policies",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield resource tag-policy list
+			$ shield resource tag-policy list --namespace=entropy/firehose
+			$ shield resource tag-policy list --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			deps, cleanup, err := serverDeps(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			policies, err := deps.ResourceService.ListTagPolicies(cmd.Context(), namespaceID)
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAMESPACE", "TAGS", "ROLE", "SUBJECT"})
+			for _, p := range policies {
+				report = append(report, []string{p.ID, p.NamespaceID, resource.FormatTags(p.Tags), p.RoleID, p.Subject.Namespace + ":" + p.Subject.ID})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d tag policies\n \n", len(policies))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVarP(&namespaceID, "namespace", "n", "", "Only list the tag policies of the namespace")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func deleteTagPolicyCommand() *cli.Command {
+	var configFile string
+
+	cmd := &cli.Command{
+		Use:   "delete <tag-policy-id>",
+		Short: "Revoke the roles of a tag policy and delete it",
+		Args:  cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield resource tag-policy delete <tag-policy-id>
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			deps, cleanup, err := serverDeps(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if err := deps.ResourceService.DeleteTagPolicy(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("successfully deleted tag policy %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+
+	return cmd
+}
+
+func syncTagPolicyCommand() *cli.Command {
+	var configFile string
+
+	cmd := &cli.Command{
+		Use:   "sync",
+		Short: "Grant and revoke the roles of tag policies by the tags of resources",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield resource tag-policy sync
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			deps, cleanup, err := serverDeps(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			changes, err := deps.ResourceService.SyncTagPolicies(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("successfully synced tag policies, granted or revoked %d roles\n", changes)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		subCommands []string
+		want        string
+		err         error
+	}{
+		{
+			name:        "`tag-policy` create without subject should throw error missing required flag",
+			want:        "",
+			subCommands: []string{"create", "--namespace", "entropy/firehose", "--tags", "env=prod", "--role", "viewer"},
+			err:         errors.New("required flag(s) \"subject\" not set"),
+		},
+		{
+			name:        "`tag-policy` create with a subject without namespace should throw error",
+			want:        "",
+			subCommands: []string{"create", "--namespace", "entropy/firehose", "--tags", "env=prod", "--role", "viewer", "--subject", "jane@odpf.io"},
+			err:         errors.New("subject \"jane@odpf.io\" should be <namespace>:<id>"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := cmd.New(&cmd.Config{})
+
+			buf := new(bytes.Buffer)
+			cli.SetOutput(buf)
+			cli.SetArgs(append([]string{"resource", "tag-policy"}, tt.subCommands...))
+
+			err := cli.Execute()
+			got := buf.String()
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
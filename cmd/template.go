@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+	cli "github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// templateMaxDepth stops the expansion of recursive messages
+const templateMaxDepth = 4
+
+type templateKind int
+
+const (
+	templateScalar templateKind = iota
+	templateList
+	templateMap
+	templateObject
+	templateObjectList
+)
+
+// templateField is a field of a request body skeleton, object fields
+// and lists of objects have the fields of the embedded message
+type templateField struct {
+	Name    string
+	Comment string
+	Kind    templateKind
+	Zero    string
+	Fields  []templateField
+}
+
+func templateCommand(resource string, body proto.Message) *cli.Command {
+	var output string
+
+	cmd := &cli.Command{
+		Use:   "template",
+		Short: fmt.Sprintf("Print a skeleton of the %s body", resource),
+		Long: heredoc.Docf(`
+			Print a skeleton of the %[1]s body with all its fields, to be filled in
+			and passed to the create and edit commands with --file.
+
+			The yaml skeleton has a comment with the type and the allowed values
+			of each field.
+		`, resource),
+		Args: cli.NoArgs,
+		Example: heredoc.Docf(`
+			$ shield %[1]s template > %[1]s.yaml
+			$ shield %[1]s template --output=json > %[1]s.json
+		`, resource),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "false",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			fields := templateFields(body.ProtoReflect().Descriptor(), 0)
+
+			var b strings.Builder
+			if output == outputJSON {
+				writeJSONTemplate(&b, fields, "")
+				b.WriteString("\n")
+			} else {
+				fmt.Fprintf(&b, "# %s body, pass it with --file to shield %s create or edit\n", resource, resource)
+				writeYAMLTemplate(&b, fields, "")
+			}
+
+			_, err := fmt.Fprint(cmd.OutOrStdout(), b.String())
+			return err
+		},
+	}
+
+	bindOutputFlag(cmd, &output, outputYAML, outputJSON)
+
+	return cmd
+}
+
+func templateFields(md protoreflect.MessageDescriptor, depth int) []templateField {
+	var fields []templateField
+	for i := 0; i < md.Fields().Len(); i++ {
+		fd := md.Fields().Get(i)
+		field := templateField{Name: string(fd.Name())}
+
+		typ := templateType(fd)
+		switch {
+		case fd.IsMap() || isStruct(fd):
+			field.Kind = templateMap
+			typ = "map of key/values"
+		case fd.Kind() == protoreflect.MessageKind && !isTimestamp(fd) && depth < templateMaxDepth:
+			field.Kind = templateObject
+			if fd.IsList() {
+				field.Kind = templateObjectList
+			}
+			field.Fields = templateFields(fd.Message(), depth+1)
+		case fd.IsList():
+			field.Kind = templateList
+		default:
+			field.Zero = templateZero(fd)
+		}
+
+		if fd.IsList() && !fd.IsMap() {
+			typ = "list of " + typ
+		}
+		field.Comment = strings.Join(append([]string{typ}, templateConstraints(fd)...), ", ")
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func templateType(fd protoreflect.FieldDescriptor) string {
+	switch {
+	case isTimestamp(fd):
+		return "timestamp in RFC 3339 format"
+	case fd.Kind() == protoreflect.MessageKind:
+		return "object"
+	case fd.Kind() == protoreflect.EnumKind:
+		return "enum"
+	default:
+		return fd.Kind().String()
+	}
+}
+
+func templateZero(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return "false"
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind:
+		return `""`
+	case protoreflect.EnumKind:
+		return fmt.Sprintf("%q", fd.Enum().Values().Get(0).Name())
+	default:
+		return "0"
+	}
+}
+
+// templateConstraints describes the validation rules of the field,
+// only the rules used by the request bodies are described
+func templateConstraints(fd protoreflect.FieldDescriptor) []string {
+	var constraints []string
+	if fd.Kind() == protoreflect.EnumKind {
+		var values []string
+		for i := 0; i < fd.Enum().Values().Len(); i++ {
+			values = append(values, string(fd.Enum().Values().Get(i).Name()))
+		}
+		constraints = append(constraints, "one of "+strings.Join(values, ", "))
+	}
+
+	rules, ok := proto.GetExtension(fd.Options(), validate.E_Rules).(*validate.FieldRules)
+	if !ok || rules == nil {
+		return constraints
+	}
+	if rules.GetRepeated().GetMinItems() > 0 {
+		constraints = append(constraints, fmt.Sprintf("at least %d items", rules.GetRepeated().GetMinItems()))
+	}
+	if items := rules.GetRepeated().GetItems(); items != nil {
+		rules = items
+	}
+	if rules.GetMessage().GetRequired() {
+		constraints = append(constraints, "required")
+	}
+
+	str := rules.GetString_()
+	if str == nil {
+		return constraints
+	}
+	if str.GetMinLen() > 0 {
+		constraints = append(constraints, fmt.Sprintf("at least %d characters", str.GetMinLen()))
+	}
+	if str.GetMaxLen() > 0 {
+		constraints = append(constraints, fmt.Sprintf("at most %d characters", str.GetMaxLen()))
+	}
+	if str.GetPattern() != "" {
+		constraints = append(constraints, "matching "+str.GetPattern())
+	}
+	if len(str.GetIn()) > 0 {
+		constraints = append(constraints, "one of "+strings.Join(str.GetIn(), ", "))
+	}
+	if str.GetEmail() {
+		constraints = append(constraints, "an email address")
+	}
+	if str.GetUuid() {
+		constraints = append(constraints, "an uuid")
+	}
+	return constraints
+}
+
+func isStruct(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.MessageKind && fd.Message().FullName() == "google.protobuf.Struct"
+}
+
+func isTimestamp(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.MessageKind && fd.Message().FullName() == "google.protobuf.Timestamp"
+}
+
+func writeYAMLTemplate(b *strings.Builder, fields []templateField, indent string) {
+	for _, f := range fields {
+		fmt.Fprintf(b, "%s# %s\n", indent, f.Comment)
+		switch f.Kind {
+		case templateList:
+			fmt.Fprintf(b, "%s%s: []\n", indent, f.Name)
+		case templateMap:
+			fmt.Fprintf(b, "%s%s: {}\n", indent, f.Name)
+		case templateObject:
+			fmt.Fprintf(b, "%s%s:\n", indent, f.Name)
+			writeYAMLTemplate(b, f.Fields, indent+"  ")
+		case templateObjectList:
+			fmt.Fprintf(b, "%s%s:\n", indent, f.Name)
+			var item strings.Builder
+			writeYAMLTemplate(&item, f.Fields, indent+"    ")
+			b.WriteString(indent + "  - " + strings.TrimPrefix(item.String(), indent+"    "))
+		default:
+			fmt.Fprintf(b, "%s%s: %s\n", indent, f.Name, f.Zero)
+		}
+	}
+}
+
+func writeJSONTemplate(b *strings.Builder, fields []templateField, indent string) {
+	b.WriteString("{\n")
+	for i, f := range fields {
+		fmt.Fprintf(b, "%s  %q: ", indent, f.Name)
+		switch f.Kind {
+		case templateList:
+			b.WriteString("[]")
+		case templateMap:
+			b.WriteString("{}")
+		case templateObject:
+			writeJSONTemplate(b, f.Fields, indent+"  ")
+		case templateObjectList:
+			b.WriteString("[\n" + indent + "    ")
+			writeJSONTemplate(b, f.Fields, indent+"    ")
+			b.WriteString("\n" + indent + "  ]")
+		default:
+			b.WriteString(f.Zero)
+		}
+		if i < len(fields)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + "}")
+}
@@ -0,0 +1,55 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/shield/cmd"
+	"github.com/odpf/shield/pkg/file"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate(t *testing.T) {
+	template := func(t *testing.T, args ...string) string {
+		cli := cmd.New(&cmd.Config{})
+
+		buf := new(bytes.Buffer)
+		cli.SetOut(buf)
+		cli.SetArgs(args)
+
+		require.NoError(t, cli.Execute())
+		return buf.String()
+	}
+
+	t.Run("should describe the fields of the body in the yaml skeleton", func(t *testing.T) {
+		out := template(t, "user", "template")
+
+		assert.Contains(t, out, "# string, matching ^[A-Za-z0-9_-]+$\nname: \"\"\n")
+		assert.Contains(t, out, "# string, an email address\nemail: \"\"\n")
+		assert.Contains(t, out, "# map of key/values\nmetadata: {}\n")
+	})
+
+	for _, output := range []string{"yaml", "json"} {
+		t.Run("should parse the "+output+" skeleton as a body file", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "group."+output)
+			require.NoError(t, os.WriteFile(path, []byte(template(t, "group", "template", "-o", output)), 0600))
+
+			var body shieldv1beta1.GroupRequestBody
+			assert.NoError(t, file.Parse(path, &body))
+		})
+	}
+
+	t.Run("should render the org id of a parsed yaml body file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "project.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("name: p1\norg_id: o1\nmetadata:\n  team: iam\n"), 0600))
+
+		var body shieldv1beta1.ProjectRequestBody
+		require.NoError(t, file.Parse(path, &body))
+		assert.Equal(t, "o1", body.GetOrgId())
+		assert.Equal(t, "iam", body.GetMetadata().AsMap()["team"])
+	})
+}
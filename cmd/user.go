@@ -3,15 +3,46 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/api"
+	"github.com/odpf/shield/internal/api/v1beta1"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/store/spicedb"
 	"github.com/odpf/shield/pkg/file"
+	shieldlogger "github.com/odpf/shield/pkg/logger"
+	"github.com/odpf/shield/pkg/secrets"
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
 	cli "github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 )
 
+// the users are enabled and disabled with endpoints the gateway serves next
+// to the rpcs, the user messages have no state
+const (
+	usersEnablePath  = "/admin/v1beta1/users/enable"
+	usersDisablePath = "/admin/v1beta1/users/disable"
+)
+
+type userStateResponse struct {
+	ID              string `json:"id"`
+	Email           string `json:"email"`
+	State           string `json:"state"`
+	RevokedSessions int    `json:"revoked_sessions"`
+}
+
 func UserCommand(cliConfig *Config) *cli.Command {
 	cmd := &cli.Command{
 		Use:     "user",
@@ -25,6 +56,13 @@ func UserCommand(cliConfig *Config) *cli.Command {
 			$ shield user edit
 			$ shield user view
 			$ shield user list
+			$ shield user resources
+			$ shield user groups
+			$ shield user orgs
+			$ shield user import
+			$ shield user disable
+			$ shield user enable
+			$ shield user purge
 		`),
 		Annotations: map[string]string{
 			"group":  "core",
@@ -36,6 +74,14 @@ func UserCommand(cliConfig *Config) *cli.Command {
 	cmd.AddCommand(editUserCommand(cliConfig))
 	cmd.AddCommand(viewUserCommand(cliConfig))
 	cmd.AddCommand(listUserCommand(cliConfig))
+	cmd.AddCommand(resourcesUserCommand(cliConfig))
+	cmd.AddCommand(groupsUserCommand(cliConfig))
+	cmd.AddCommand(orgsUserCommand(cliConfig))
+	cmd.AddCommand(importUserCommand(cliConfig))
+	cmd.AddCommand(disableUserCommand(cliConfig))
+	cmd.AddCommand(enableUserCommand(cliConfig))
+	cmd.AddCommand(purgeUserCommand())
+	cmd.AddCommand(templateCommand("user", &shieldv1beta1.UserRequestBody{}))
 
 	bindFlagsFromClientConfig(cmd)
 
@@ -44,6 +90,7 @@ func UserCommand(cliConfig *Config) *cli.Command {
 
 func createUserCommand(cliConfig *Config) *cli.Command {
 	var filePath, header string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "create",
@@ -51,6 +98,8 @@ func createUserCommand(cliConfig *Config) *cli.Command {
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield user create --file=<user-body>
+			$ shield user create --file=<user-body> --dry-run
+			$ shield user create
 		`),
 		Annotations: map[string]string{
 			"group": "core",
@@ -60,13 +109,16 @@ func createUserCommand(cliConfig *Config) *cli.Command {
 			defer spinner.Stop()
 
 			var reqBody shieldv1beta1.UserRequestBody
-			if err := file.Parse(filePath, &reqBody); err != nil {
-				return err
-			}
+			if filePath != "" {
+				if err := file.Parse(filePath, &reqBody); err != nil {
+					return err
+				}
 
-			err := reqBody.ValidateAll()
-			if err != nil {
-				return err
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+			} else if !isInteractive(cmd) {
+				return errFileRequired
 			}
 
 			ctx := context.Background()
@@ -76,9 +128,33 @@ func createUserCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			res, err := client.CreateUser(setCtxHeader(ctx, header), &shieldv1beta1.CreateUserRequest{
+			if filePath == "" {
+				spinner.Stop()
+				if err := promptBody(&reqBody.Metadata,
+					promptField{Message: "Name", Value: &reqBody.Name, Required: true},
+					promptField{Message: "Email", Value: &reqBody.Email, Required: true},
+				); err != nil {
+					return err
+				}
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+				if !dryRun {
+					if err := confirmBody(cmd, &reqBody); err != nil {
+						return err
+					}
+				}
+			}
+
+			req := &shieldv1beta1.CreateUserRequest{
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				spinner.Stop()
+				return printDryRun(os.Stdout, "CreateUser", req, nil)
+			}
+
+			res, err := client.CreateUser(setCtxHeader(ctx, header), req)
 			if err != nil {
 				return err
 			}
@@ -89,39 +165,47 @@ func createUserCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the user body file")
-	cmd.MarkFlagRequired("file")
-	cmd.Flags().StringVarP(&header, "header", "H", "", "Header <key>:<value>")
-	cmd.MarkFlagRequired("header")
+	bindFileFlag(cmd, &filePath, "Path to the user body file, prompted for when omitted")
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func editUserCommand(cliConfig *Config) *cli.Command {
 	var filePath string
+	var dryRun bool
 
 	cmd := &cli.Command{
 		Use:   "edit",
 		Short: "Edit an user",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield user edit <user-id> --file=<user-body>
+			$ shield user edit --file=<user-body>
+			$ shield user edit <user-id> --file=<user-body> --dry-run
+			$ shield user edit <user-id>
 		`),
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(userOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
 			var reqBody shieldv1beta1.UserRequestBody
-			if err := file.Parse(filePath, &reqBody); err != nil {
-				return err
-			}
+			if filePath != "" {
+				if err := file.Parse(filePath, &reqBody); err != nil {
+					return err
+				}
 
-			err := reqBody.ValidateAll()
-			if err != nil {
-				return err
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+			} else if !isInteractive(cmd) {
+				return errFileRequired
 			}
 
 			ctx := context.Background()
@@ -131,11 +215,55 @@ func editUserCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			userID := args[0]
-			_, err = client.UpdateUser(ctx, &shieldv1beta1.UpdateUserRequest{
+			userID, err := resolveID(cmd, client, spinner, args, "user", userOptions)
+			if err != nil {
+				return err
+			}
+
+			if filePath == "" {
+				getRes, err := client.GetUser(cmd.Context(), &shieldv1beta1.GetUserRequest{
+					Id: userID,
+				})
+				if err != nil {
+					return err
+				}
+				reqBody.Name = getRes.GetUser().GetName()
+				reqBody.Email = getRes.GetUser().GetEmail()
+				reqBody.Metadata = getRes.GetUser().GetMetadata()
+
+				spinner.Stop()
+				if err := promptBody(&reqBody.Metadata,
+					promptField{Message: "Name", Value: &reqBody.Name, Required: true},
+					promptField{Message: "Email", Value: &reqBody.Email, Required: true},
+				); err != nil {
+					return err
+				}
+				if err := reqBody.ValidateAll(); err != nil {
+					return err
+				}
+				if !dryRun {
+					if err := confirmBody(cmd, &reqBody); err != nil {
+						return err
+					}
+				}
+			}
+
+			req := &shieldv1beta1.UpdateUserRequest{
 				Id:   userID,
 				Body: &reqBody,
-			})
+			}
+			if dryRun {
+				refs, err := resolveReferences(cmd.Context(), client,
+					dryRunReference{Resource: "user", Field: "id", Value: userID},
+				)
+				if err != nil {
+					return err
+				}
+				spinner.Stop()
+				return printDryRun(os.Stdout, "UpdateUser", req, nil, refs...)
+			}
+
+			_, err = client.UpdateUser(ctx, req)
 			if err != nil {
 				return err
 			}
@@ -146,25 +274,31 @@ func editUserCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the user body file")
-	cmd.MarkFlagRequired("file")
+	bindFileFlag(cmd, &filePath, "Path to the user body file, prompted for when omitted")
+
+	bindDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
 
 func viewUserCommand(cliConfig *Config) *cli.Command {
 	var metadata bool
+	var output string
 
 	cmd := &cli.Command{
 		Use:   "view",
 		Short: "View an user",
-		Args:  cli.ExactArgs(1),
+		Args:  idArg,
 		Example: heredoc.Doc(`
 			$ shield user view <user-id>
+			$ shield user view <user-email>
+			$ shield user view
+			$ shield user view <user-id> --output=json
 		`),
 		Annotations: map[string]string{
 			"group": "core",
 		},
+		ValidArgsFunction: completeIDs(userOptions),
 		RunE: func(cmd *cli.Command, args []string) error {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
@@ -176,7 +310,11 @@ func viewUserCommand(cliConfig *Config) *cli.Command {
 			}
 			defer cancel()
 
-			userID := args[0]
+			userID, err := resolveID(cmd, client, spinner, args, "user", userOptions)
+			if err != nil {
+				return err
+			}
+
 			res, err := client.GetUser(ctx, &shieldv1beta1.GetUserRequest{
 				Id: userID,
 			})
@@ -190,6 +328,10 @@ func viewUserCommand(cliConfig *Config) *cli.Command {
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, user)
+			}
+
 			report = append(report, []string{"ID", "NAME", "EMAIL"})
 			report = append(report, []string{
 				user.GetId(),
@@ -215,17 +357,28 @@ func viewUserCommand(cliConfig *Config) *cli.Command {
 	}
 
 	cmd.Flags().BoolVarP(&metadata, "metadata", "m", false, "Set this flag to see metadata")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
 
 	return cmd
 }
 
 func listUserCommand(cliConfig *Config) *cli.Command {
+	var keyword, state, output string
+	var filterValues []string
+	var page pageFlags
+
 	cmd := &cli.Command{
 		Use:   "list",
 		Short: "List all users",
 		Args:  cli.NoArgs,
 		Example: heredoc.Doc(`
 			$ shield user list
+			$ shield user list --keyword=john
+			$ shield user list --output=yaml
+			$ shield user list --page-size=100 --page-num=2
+			$ shield user list --all
+			$ shield user list --filter email=*@gojek.com
+			$ shield user list --state=disabled
 		`),
 		Annotations: map[string]string{
 			"group": "core",
@@ -234,31 +387,91 @@ func listUserCommand(cliConfig *Config) *cli.Command {
 			spinner := printer.Spin("")
 			defer spinner.Stop()
 
+			filters, err := fieldFilters(filterValues, "name", "email")
+			if err != nil {
+				return err
+			}
+
+			if _, err := user.ParseState(state); err != nil {
+				return err
+			}
+
 			ctx := context.Background()
+			if state != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, v1beta1.UserStateHeader, state)
+			}
 			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
 			if err != nil {
 				return err
 			}
 			defer cancel()
 
-			res, err := client.ListUsers(ctx, &shieldv1beta1.ListUsersRequest{})
-			if err != nil {
-				return err
+			// the users are disabled when their id is in the header of any
+			// of the pages listed
+			disabled := map[string]bool{}
+			listUsers := func(req *shieldv1beta1.ListUsersRequest) (*shieldv1beta1.ListUsersResponse, error) {
+				var header metadata.MD
+				res, err := client.ListUsers(ctx, req, grpc.Header(&header))
+				for _, ids := range header.Get(v1beta1.DisabledUsersHeader) {
+					for _, id := range strings.Split(ids, ",") {
+						disabled[id] = true
+					}
+				}
+				return res, err
+			}
+
+			req := &shieldv1beta1.ListUsersRequest{
+				PageSize: page.size,
+				PageNum:  page.num,
+			}
+			ctx, clientFilters := pushDownFilters(ctx, cmd, cliConfig, req, append([]listFilter{
+				{name: "keyword", value: keyword, match: func(item proto.Message, value string) bool {
+					u := item.(*shieldv1beta1.User)
+					return containsFold(u.GetName(), value) || containsFold(u.GetEmail(), value)
+				}},
+			}, filters...))
+
+			var res *shieldv1beta1.ListUsersResponse
+			if page.all {
+				users, err := listAllPages(page.size, func(size, num int32) ([]*shieldv1beta1.User, error) {
+					req.PageSize, req.PageNum = size, num
+					res, err := listUsers(req)
+					return res.GetUsers(), err
+				})
+				if err != nil {
+					return err
+				}
+				res = &shieldv1beta1.ListUsersResponse{Count: int32(len(users)), Users: users}
+			} else {
+				res, err = listUsers(req)
+				if err != nil {
+					return err
+				}
 			}
 
 			report := [][]string{}
+			res.Users = filterItems(res.GetUsers(), clientFilters)
 			users := res.GetUsers()
 
 			spinner.Stop()
 
+			if output != outputTable {
+				return printMessage(os.Stdout, output, res)
+			}
+
 			fmt.Printf(" \nShowing %d users\n \n", len(users))
 
-			report = append(report, []string{"ID", "NAME", "EMAIL"})
+			report = append(report, []string{"ID", "NAME", "EMAIL", "STATE"})
 			for _, u := range users {
+				userState := user.StateEnabled
+				if disabled[u.GetId()] {
+					userState = user.StateDisabled
+				}
 				report = append(report, []string{
 					u.GetId(),
 					u.GetName(),
 					u.GetEmail(),
+					string(userState),
 				})
 			}
 			printer.Table(os.Stdout, report)
@@ -267,5 +480,557 @@ func listUserCommand(cliConfig *Config) *cli.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&keyword, "keyword", "", "Only list users whose name or email contains the keyword")
+	cmd.Flags().StringVar(&state, "state", "", "Only list the users of the state, enabled or disabled")
+	bindFieldFilterFlag(cmd, &filterValues, "name", "email")
+	bindPageFlags(cmd, &page)
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+// accessResourcesPath serves the resources a user has access to, it has no
+// rpc of its own
+const accessResourcesPath = "/admin/v1beta1/access/resources"
+
+type userResource struct {
+	ID          string `json:"id"`
+	URN         string `json:"urn"`
+	Name        string `json:"name"`
+	NamespaceID string `json:"namespace_id"`
+	ProjectID   string `json:"project_id"`
+}
+
+type userResourcesResponse struct {
+	Count     int32          `json:"count"`
+	Resources []userResource `json:"resources"`
+}
+
+func resourcesUserCommand(cliConfig *Config) *cli.Command {
+	var header, namespace, action string
+	var page pageFlags
+
+	cmd := &cli.Command{
+		Use:   "resources <user-id>",
+		Short: "List the resources a user is allowed an action on",
+		Long: heredoc.Doc(`
+			List the resources of a namespace a user is allowed an action on, ordered by
+			their id. Users can only list their own resources.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield user resources <user-id> --namespace=entropy/firehose --action=view --header=<key>:<value>
+			$ shield user resources <user-email> --namespace=shield/project --action=edit --all
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(userOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			list := func(size, num int32) (userResourcesResponse, error) {
+				query := url.Values{
+					"user_id":   {args[0]},
+					"namespace": {namespace},
+					"action":    {action},
+					"page_num":  {strconv.Itoa(int(num))},
+				}
+				if size > 0 {
+					query.Set("page_size", strconv.Itoa(int(size)))
+				}
+				var res userResourcesResponse
+				err := getAdminAPI(cmd.Context(), cliConfig, accessResourcesPath, query, header, &res)
+				return res, err
+			}
+
+			var res userResourcesResponse
+			if page.all {
+				var err error
+				res.Resources, err = listAllPages(page.size, func(size, num int32) ([]userResource, error) {
+					res, err := list(size, num)
+					return res.Resources, err
+				})
+				if err != nil {
+					return err
+				}
+				res.Count = int32(len(res.Resources))
+			} else {
+				var err error
+				if res, err = list(page.size, page.num); err != nil {
+					return err
+				}
+			}
+
+			spinner.Stop()
+			fmt.Printf(" \nShowing %d of %d resources\n \n", len(res.Resources), res.Count)
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAME", "URN", "PROJECT"})
+			for _, r := range res.Resources {
+				report = append(report, []string{r.ID, r.Name, r.URN, r.ProjectID})
+			}
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace of the resources")
+	cmd.Flags().StringVarP(&action, "action", "a", "", "Action the user is allowed on the resources, like view")
+	cmd.MarkFlagRequired("namespace")
+	cmd.MarkFlagRequired("action")
+	bindPageFlags(cmd, &page)
+
+	return cmd
+}
+
+func groupsUserCommand(cliConfig *Config) *cli.Command {
+	var header, role string
+
+	cmd := &cli.Command{
+		Use:   "groups <user-id>",
+		Short: "List the groups of a user",
+		Long: heredoc.Doc(`
+			List the groups a user has a role on, only the ones of the role with --role.
+			The user is referred to by id or email.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield user groups <user-id> --header=<key>:<value>
+			$ shield user groups <user-email> --role=shield/group:member
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(userOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			res, err := client.ListUserGroups(setCtxHeader(cmd.Context(), header), &shieldv1beta1.ListUserGroupsRequest{
+				Id:   args[0],
+				Role: role,
+			})
+			if err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			groups := res.GetGroups()
+			fmt.Printf(" \nShowing %d groups\n \n", len(groups))
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAME", "SLUG", "ORG ID"})
+			for _, g := range groups {
+				report = append(report, []string{g.GetId(), g.GetName(), g.GetSlug(), g.GetOrgId()})
+			}
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVarP(&role, "role", "r", "", "Role the user has on the groups")
+
+	return cmd
+}
+
+func orgsUserCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:     "orgs <user-id>",
+		Aliases: []string{"organizations"},
+		Short:   "List the organizations of a user",
+		Long: heredoc.Doc(`
+			List the organizations a user has a role on or has a role on one of the
+			groups of. The user is referred to by id or email.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield user orgs <user-id> --header=<key>:<value>
+			$ shield user orgs <user-email>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		ValidArgsFunction: completeIDs(userOptions),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var res organizationsResponse
+			if err := getAdminAPI(cmd.Context(), cliConfig, userOrganizationsPath, url.Values{"id": {args[0]}}, header, &res); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf(" \nShowing %d organizations\n \n", len(res.Organizations))
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "NAME", "SLUG"})
+			for _, o := range res.Organizations {
+				report = append(report, []string{o.ID, o.Name, o.Slug})
+			}
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func disableUserCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "disable <user-id|email>",
+		Short: "Disable a user",
+		Long: heredoc.Doc(`
+			Disable a user, the user can't log in or authenticate with their api keys and
+			tokens from then on, and the permission checks made as the user are rejected.
+			The active sessions of the user are revoked.
+
+			Only the superusers and the admins of every organization the user is a member
+			of can disable them.
+			The memberships and roles of the user are kept, use purge to remove them.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield user disable <user-id>
+			$ shield user disable alice@odpf.io --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var disabled userStateResponse
+			if err := postAdminAPI(cmd.Context(), cliConfig, usersDisablePath, url.Values{"id": {args[0]}}, header, &disabled); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("disabled user %s and revoked %d sessions\n", disabled.Email, disabled.RevokedSessions)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func enableUserCommand(cliConfig *Config) *cli.Command {
+	var header string
+
+	cmd := &cli.Command{
+		Use:   "enable <user-id|email>",
+		Short: "Enable a disabled user",
+		Long: heredoc.Doc(`
+			Enable a disabled user, the user can log in and their permission checks are
+			allowed again with the memberships and roles they had when they were
+			disabled. Their revoked sessions are not restored.
+
+			Only the superusers and the admins of every organization the user is a member
+			of can enable them.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield user enable <user-id>
+			$ shield user enable alice@odpf.io --header=<key>:<value>
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var enabled userStateResponse
+			if err := postAdminAPI(cmd.Context(), cliConfig, usersEnablePath, url.Values{"id": {args[0]}}, header, &enabled); err != nil {
+				return err
+			}
+
+			spinner.Stop()
+			fmt.Printf("enabled user %s\n", enabled.Email)
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+
+	return cmd
+}
+
+func purgeUserCommand() *cli.Command {
+	var configFile string
+
+	cmd := &cli.Command{
+		Use:   "purge <user-id|email>",
+		Short: "Remove the access of a user across the system",
+		Long: heredoc.Doc(`
+			Disable a user and remove everything granting them access with the server
+			config, like when they leave the organization: their sessions and api keys
+			are revoked, and their group memberships, organization and project roles and
+			the other relations they are the subject of are removed from postgres and
+			spicedb.
+
+			The user is kept, disabled, so the audit logs still refer to them. Every
+			removal is audited along with a summary of everything removed.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield user purge <user-id>
+			$ shield user purge alice@odpf.io -c ./config.yaml
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "false",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			d, cleanup, err := serverUserDeprovisioning(cmd.Context(), configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			purged, err := d.purge(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"KIND", "REMOVED"})
+			for _, id := range purged.Sessions {
+				report = append(report, []string{"session", id})
+			}
+			for _, id := range purged.APIKeys {
+				report = append(report, []string{"api key", id})
+			}
+			for _, rel := range purged.Relations {
+				report = append(report, []string{"relation", rel})
+			}
+			for _, t := range purged.Tuples {
+				report = append(report, []string{"tuple", t})
+			}
+
+			spinner.Stop()
+			fmt.Printf(" \nPurged user %s, removed %d relations and %d tuples\n \n", purged.Email, len(purged.Relations), len(purged.Tuples))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+
+	return cmd
+}
+
+// userDeprovisioning disables and purges the users with the services of the
+// server, the tuples of spicedb are removed by the relation collector as not
+// all of them have a relation in postgres
+type userDeprovisioning struct {
+	deps      api.Deps
+	collector *relation.Collector
+	audit     *event.Recorder
+}
+
+// userPurge is the summary of a user purge as it is audited, everything
+// removed is referred to by id
+type userPurge struct {
+	ID        string   `json:"id"`
+	Email     string   `json:"email"`
+	Sessions  []string `json:"sessions"`
+	APIKeys   []string `json:"api_keys"`
+	Relations []string `json:"relations"`
+	Tuples    []string `json:"tuples"`
+}
+
+func serverUserDeprovisioning(ctx context.Context, configFile string) (userDeprovisioning, func(), error) {
+	dbClient, appConfig, err := serverDB(configFile)
+	if err != nil {
+		return userDeprovisioning{}, nil, err
+	}
+	logger := shieldlogger.InitLogger(appConfig.Log)
+
+	authz, err := setupAuthzEngine(appConfig, dbClient, secrets.NewResolver(appConfig.Secrets), logger)
+	if err != nil {
+		dbClient.Close()
+		return userDeprovisioning{}, nil, err
+	}
+
+	deps, err := buildAPIDependencies(ctx, logger, nil, dbClient, authz, spicedb.CheckCacheConfig{}, appConfig.Event)
+	if err != nil {
+		dbClient.Close()
+		return userDeprovisioning{}, nil, err
+	}
+	deps.SessionService = newSessionService(dbClient, appConfig.Event, appConfig.Session)
+
+	auditRecorder := newAuditRecorder(dbClient, appConfig.Event)
+	return userDeprovisioning{
+		deps:      deps,
+		collector: relation.NewCollector(authz.relations, newStores(dbClient).objects, auditRecorder),
+		audit:     auditRecorder,
+	}, func() { dbClient.Close() }, nil
+}
+
+// disable disables the user and revokes their active sessions, disabling a
+// disabled user revokes the sessions left if any
+func (d userDeprovisioning) disable(ctx context.Context, idOrEmail string) (user.User, []session.Session, error) {
+	usr, err := d.deps.UserService.Disable(ctx, idOrEmail)
+	if err != nil {
+		return user.User{}, nil, err
+	}
+	sessions, err := d.deps.SessionService.RevokeAll(ctx, usr.ID)
+	if err != nil {
+		return user.User{}, nil, err
+	}
+	return usr, sessions, nil
+}
+
+func (d userDeprovisioning) purge(ctx context.Context, idOrEmail string) (userPurge, error) {
+	usr, sessions, err := d.disable(ctx, idOrEmail)
+	if err != nil {
+		return userPurge{}, err
+	}
+	purged := userPurge{ID: usr.ID, Email: usr.Email, Sessions: []string{}, APIKeys: []string{}, Relations: []string{}, Tuples: []string{}}
+	for _, sess := range sessions {
+		purged.Sessions = append(purged.Sessions, sess.ID)
+	}
+
+	keys, err := d.deps.APIKeyService.List(ctx, usr.ID)
+	if err != nil {
+		return userPurge{}, err
+	}
+	for _, key := range keys {
+		if key.IsRevoked() {
+			continue
+		}
+		if _, err := d.deps.APIKeyService.Revoke(ctx, key.ID); err != nil {
+			return userPurge{}, err
+		}
+		purged.APIKeys = append(purged.APIKeys, key.ID)
+	}
+
+	sub := relation.Subject{ID: usr.ID, Namespace: schema.UserPrincipal}
+	relations, err := d.deps.RelationService.DeleteSubject(ctx, sub)
+	if err != nil {
+		return userPurge{}, err
+	}
+	for _, rel := range relations {
+		purged.Relations = append(purged.Relations, rel.ID)
+	}
+
+	namespaces, err := d.deps.NamespaceService.List(ctx)
+	if err != nil {
+		return userPurge{}, err
+	}
+	var namespaceIDs []string
+	for _, ns := range namespaces {
+		namespaceIDs = append(namespaceIDs, ns.ID)
+	}
+	tuples, err := d.collector.SubjectTuples(ctx, namespaceIDs, sub)
+	if err != nil {
+		return userPurge{}, err
+	}
+	if err := d.collector.Remove(ctx, tuples); err != nil {
+		return userPurge{}, err
+	}
+	for _, t := range tuples {
+		purged.Tuples = append(purged.Tuples, t.String())
+	}
+
+	return purged, d.audit.Record(ctx, audit.ActionPurge, "user", usr.ID, purged, nil)
+}
+
+func importUserCommand(cliConfig *Config) *cli.Command {
+	var flags importFlags
+
+	cmd := &cli.Command{
+		Use:   "import",
+		Short: "Import users from a csv file",
+		Long: heredoc.Doc(`
+			Create the users of the rows of a csv file, like an export of another identity
+			system. The first row names the columns, the name and email columns are mapped
+			with --name-column and --email-column and metadata keys with --metadata-column.
+
+			Rows are imported --batch-size at a time. Users that already exist are skipped,
+			or updated with --update-existing keeping the metadata keys not in the file. The
+			result of every row is printed, the import fails if any row failed.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield user import --file=users.csv
+			$ shield user import -f export.csv --name-column="Full Name" --email-column=Mail --metadata-column=team=Department
+			$ shield user import -f users.csv --update-existing --batch-size=50
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			rows, err := readImportRows(flags)
+			if err != nil {
+				return err
+			}
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			ctx := setCtxHeader(cmd.Context(), flags.header)
+			results := importRows(ctx, rows, flags.batchSize, func(ctx context.Context, row importRow) importResult {
+				body := &shieldv1beta1.UserRequestBody{
+					Name:     row.fields["name"],
+					Email:    row.fields["email"],
+					Metadata: row.metadata,
+				}
+				if err := body.ValidateAll(); err != nil {
+					return newImportResult(body.GetEmail(), importFailed, err)
+				}
+
+				return importExisting(body.GetEmail(), flags.updateExisting, func() error {
+					_, err := client.CreateUser(ctx, &shieldv1beta1.CreateUserRequest{Body: body})
+					return err
+				}, func() error {
+					res, err := client.GetUser(ctx, &shieldv1beta1.GetUserRequest{Id: body.GetEmail()})
+					if err != nil {
+						return err
+					}
+					body.Metadata = mergeMetadata(res.GetUser().GetMetadata(), body.GetMetadata())
+					_, err = client.UpdateUser(ctx, &shieldv1beta1.UpdateUserRequest{Id: res.GetUser().GetId(), Body: body})
+					return err
+				})
+			})
+
+			spinner.Stop()
+			return printImportResults(os.Stdout, results, "user")
+		},
+	}
+
+	bindImportFlags(cmd, &flags, cliConfig, "user", "name", "email")
+
 	return cmd
 }
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const (
+	constraintPattern = "pattern"
+	constraintEmail   = "email"
+	constraintInvalid = "invalid"
+)
+
+// validationViolation is a single field level failure reported
+// by the generated ValidateAll of a request body
+type validationViolation struct {
+	Field      string `json:"field"`
+	Constraint string `json:"constraint"`
+	Message    string `json:"message"`
+}
+
+type fieldValidationError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+type multiValidationError interface {
+	AllErrors() []error
+}
+
+// validationViolations flattens the errors returned by ValidateAll, embedded
+// message failures are expanded into the violations of the embedded fields
+func validationViolations(err error, prefix string) []validationViolation {
+	var multi multiValidationError
+	if errors.As(err, &multi) {
+		var violations []validationViolation
+		for _, e := range multi.AllErrors() {
+			violations = append(violations, validationViolations(e, prefix)...)
+		}
+		return violations
+	}
+
+	var fieldErr fieldValidationError
+	if !errors.As(err, &fieldErr) {
+		return []validationViolation{{Field: strings.TrimSuffix(prefix, "."), Constraint: constraintInvalid, Message: err.Error()}}
+	}
+
+	field := prefix + snakeCase(fieldErr.Field())
+	if cause := fieldErr.Cause(); cause != nil {
+		return validationViolations(cause, field+".")
+	}
+
+	return []validationViolation{{
+		Field:      field,
+		Constraint: validationConstraint(fieldErr.Reason()),
+		Message:    fieldErr.Reason(),
+	}}
+}
+
+func validationConstraint(reason string) string {
+	switch {
+	case strings.Contains(reason, "regex pattern"):
+		return constraintPattern
+	case strings.Contains(reason, "email"):
+		return constraintEmail
+	default:
+		return constraintInvalid
+	}
+}
+
+// reportValidationError renders a ValidateAll failure in the requested output
+// format, in json mode the violations are written to w and ErrValidationFailed
+// is returned so the caller exits with a non zero status without extra noise
+func reportValidationError(w io.Writer, output string, err error) error {
+	if output != outputJSON {
+		return err
+	}
+
+	b, mErr := json.MarshalIndent(validationViolations(err, ""), "", "  ")
+	if mErr != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(b))
+	return ErrValidationFailed
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
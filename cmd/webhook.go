@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/webhook"
+	"github.com/odpf/shield/pkg/db"
+	cli "github.com/spf13/cobra"
+)
+
+func WebhookCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:     "webhook",
+		Aliases: []string{"webhooks"},
+		Short:   "Manage webhooks",
+		Long: heredoc.Doc(`
+			Work with webhooks.
+
+			A webhook is an endpoint the changes made to organizations, projects, groups,
+			policies and the other audited resources are posted to as json. An event is
+			named after the resource and what happened to it, e.g. organization.created
+			or policy.updated. Webhooks subscribe to event types, to every event of a
+			resource as in organization.* or to every event as *.
+
+			Requests are signed with the secret of the webhook, the X-Shield-Signature
+			header is sha256= followed by the hex encoded HMAC-SHA256 of the
+			X-Shield-Timestamp header and the body joined by a dot. Deliveries failing
+			are retried with an exponential backoff.
+
+			The commands connect to the database with the server config.
+		`),
+		Example: heredoc.Doc(`
+			$ shield webhook create --url=https://example.com/hooks --secret=<secret> --event=organization.*
+			$ shield webhook list
+			$ shield webhook deliveries <webhook-id>
+			$ shield webhook delete <webhook-id>
+		`),
+		Annotations: map[string]string{
+			"group": "core",
+		},
+	}
+
+	cmd.AddCommand(createWebhookCommand())
+	cmd.AddCommand(listWebhookCommand())
+	cmd.AddCommand(deliveriesWebhookCommand())
+	cmd.AddCommand(deleteWebhookCommand())
+
+	return cmd
+}
+
+func createWebhookCommand() *cli.Command {
+	var configFile, url, secret string
+	var events []string
+
+	cmd := &cli.Command{
+		Use:   "create",
+		Short: "Create a webhook",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield webhook create --url=https://example.com/hooks --secret=<secret> --event=organization.created --event=policy.*
+			$ shield webhook create --url=https://example.com/hooks --secret=<secret> --event=* -c ./config.yaml
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			svc, cleanup, err := webhookService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			created, err := svc.Create(cmd.Context(), webhook.Webhook{
+				URL:    url,
+				Secret: secret,
+				Events: events,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully created webhook %s\n", created.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	cmd.Flags().StringVar(&url, "url", "", "Endpoint the events are posted to")
+	cmd.MarkFlagRequired("url")
+	cmd.Flags().StringVar(&secret, "secret", "", "Secret the requests are signed with")
+	cmd.MarkFlagRequired("secret")
+	cmd.Flags().StringArrayVarP(&events, "event", "e", nil, "Event type to subscribe to, can be repeated")
+	cmd.MarkFlagRequired("event")
+
+	return cmd
+}
+
+func listWebhookCommand() *cli.Command {
+	var configFile, output string
+
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List webhooks",
+		Args:  cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield webhook list
+			$ shield webhook list --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			svc, cleanup, err := webhookService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			hooks, err := svc.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "URL", "EVENTS", "CREATED AT"})
+			for _, h := range hooks {
+				report = append(report, []string{h.ID, h.URL, strings.Join(h.Events, ","), h.CreatedAt.Format(time.RFC3339)})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d webhooks\n \n", len(hooks))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func deliveriesWebhookCommand() *cli.Command {
+	var configFile, output string
+
+	cmd := &cli.Command{
+		Use:   "deliveries <webhook-id>",
+		Short: "List the deliveries of a webhook",
+		Long: heredoc.Doc(`
+			List the deliveries of a webhook, latest first.
+
+			A pending delivery is retried at its next attempt, a failed one ran out of
+			attempts.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield webhook deliveries <webhook-id>
+			$ shield webhook deliveries <webhook-id> --output=json
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			svc, cleanup, err := webhookService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			deliveries, err := svc.ListDeliveries(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			report := [][]string{}
+			report = append(report, []string{"ID", "EVENT", "STATUS", "ATTEMPTS", "NEXT ATTEMPT AT", "LAST ERROR", "CREATED AT"})
+			for _, d := range deliveries {
+				nextAttemptAt := ""
+				if d.Status == webhook.DeliveryPending {
+					nextAttemptAt = d.NextAttemptAt.Format(time.RFC3339)
+				}
+				report = append(report, []string{d.ID, d.EventType, d.Status, strconv.Itoa(d.Attempts), nextAttemptAt, d.LastError, d.CreatedAt.Format(time.RFC3339)})
+			}
+
+			spinner.Stop()
+
+			if output != outputTable {
+				return printRows(os.Stdout, output, report)
+			}
+
+			fmt.Printf(" \nShowing %d deliveries\n \n", len(deliveries))
+			printer.Table(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	bindOutputFlag(cmd, &output, outputTable, outputJSON, outputYAML)
+
+	return cmd
+}
+
+func deleteWebhookCommand() *cli.Command {
+	var configFile string
+
+	cmd := &cli.Command{
+		Use:   "delete <webhook-id>",
+		Short: "Delete a webhook",
+		Long: heredoc.Doc(`
+			Delete a webhook along with its deliveries.
+		`),
+		Args: cli.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ shield webhook delete <webhook-id>
+		`),
+		RunE: func(cmd *cli.Command, args []string) error {
+			svc, cleanup, err := webhookService(configFile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if err := svc.Delete(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+
+			fmt.Printf("successfully deleted webhook %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path")
+
+	return cmd
+}
+
+func webhookService(configFile string) (*webhook.Service, func(), error) {
+	dbClient, _, err := serverDB(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newWebhookService(dbClient), func() { dbClient.Close() }, nil
+}
+
+func newWebhookService(dbClient *db.Client) *webhook.Service {
+	repositories := newStores(dbClient)
+	return webhook.NewService(
+		repositories.webhooks,
+		audit.NewService(repositories.audit))
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/salt/printer"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	cli "github.com/spf13/cobra"
+)
+
+// the groups, organizations and permissions of the current user, the
+// organizations of any user, the groups that are members of a group and the
+// owners of a group are served next to the gateway api, they have no rpcs of
+// their own
+const (
+	selfGroupsPath        = "/admin/v1beta1/users/self/groups"
+	selfOrganizationsPath = "/admin/v1beta1/users/self/organizations"
+	selfPermissionsPath   = "/admin/v1beta1/users/self/permissions"
+	userOrganizationsPath = "/admin/v1beta1/users/organizations"
+	groupSubgroupsPath    = "/admin/v1beta1/groups/subgroups"
+	groupOwnersPath       = "/admin/v1beta1/groups/owners"
+)
+
+type groupsResponse struct {
+	Groups []struct {
+		ID             string `json:"id"`
+		Name           string `json:"name"`
+		Slug           string `json:"slug"`
+		OrganizationID string `json:"org_id"`
+	} `json:"groups"`
+}
+
+type organizationsResponse struct {
+	Organizations []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"organizations"`
+}
+
+type selfPermissionsResponse struct {
+	Namespace   string   `json:"namespace"`
+	ObjectID    string   `json:"object_id"`
+	Permissions []string `json:"permissions"`
+}
+
+func WhoamiCommand(cliConfig *Config) *cli.Command {
+	var header, resource string
+
+	cmd := &cli.Command{
+		Use:   "whoami",
+		Short: "Show the current user",
+		Long: heredoc.Doc(`
+			Show the user the commands are sent as, along with the organizations and the
+			groups they are a member of.
+
+			With --resource the permissions the user has on a resource are listed too,
+			the resource is <namespace>:<id> with the id of the objects of the shield
+			namespaces and the name of the other resources.
+		`),
+		Args: cli.NoArgs,
+		Example: heredoc.Doc(`
+			$ shield whoami
+			$ shield whoami --header=X-Shield-Email:user@odpf.io
+			$ shield whoami --resource=entropy/firehose:firehose-1
+		`),
+		Annotations: map[string]string{
+			"group":  "core",
+			"client": "true",
+		},
+		RunE: func(cmd *cli.Command, args []string) error {
+			spinner := printer.Spin("")
+			defer spinner.Stop()
+
+			var ns, objectID string
+			if resource != "" {
+				var ok bool
+				if ns, objectID, ok = strings.Cut(resource, ":"); !ok || ns == "" || objectID == "" {
+					return fmt.Errorf("resource should be <namespace>:<id>, got %q", resource)
+				}
+			}
+
+			client, cancel, err := createClient(cmd.Context(), cliConfig.Host)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			res, err := client.GetCurrentUser(setCtxHeader(cmd.Context(), header), &shieldv1beta1.GetCurrentUserRequest{})
+			if err != nil {
+				return err
+			}
+
+			var orgs organizationsResponse
+			if err := getAdminAPI(cmd.Context(), cliConfig, selfOrganizationsPath, url.Values{}, header, &orgs); err != nil {
+				return err
+			}
+			var groups groupsResponse
+			if err := getAdminAPI(cmd.Context(), cliConfig, selfGroupsPath, url.Values{}, header, &groups); err != nil {
+				return err
+			}
+			var permissions selfPermissionsResponse
+			if resource != "" {
+				query := url.Values{"namespace": {ns}, "object_id": {objectID}}
+				if err := getAdminAPI(cmd.Context(), cliConfig, selfPermissionsPath, query, header, &permissions); err != nil {
+					return err
+				}
+			}
+
+			spinner.Stop()
+
+			usr := res.GetUser()
+			printer.Table(os.Stdout, [][]string{
+				{"ID", "NAME", "EMAIL"},
+				{usr.GetId(), usr.GetName(), usr.GetEmail()},
+			})
+
+			fmt.Print("\nORGANIZATIONS\n")
+			orgReport := [][]string{{"ID", "NAME", "SLUG"}}
+			for _, o := range orgs.Organizations {
+				orgReport = append(orgReport, []string{o.ID, o.Name, o.Slug})
+			}
+			printer.Table(os.Stdout, orgReport)
+
+			fmt.Print("\nGROUPS\n")
+			groupReport := [][]string{{"ID", "NAME", "SLUG", "ORG ID"}}
+			for _, g := range groups.Groups {
+				groupReport = append(groupReport, []string{g.ID, g.Name, g.Slug, g.OrganizationID})
+			}
+			printer.Table(os.Stdout, groupReport)
+
+			if resource != "" {
+				fmt.Printf("\nPERMISSIONS ON %s\n", resource)
+				permissionReport := [][]string{{"PERMISSION"}}
+				for _, p := range permissions.Permissions {
+					permissionReport = append(permissionReport, []string{p})
+				}
+				printer.Table(os.Stdout, permissionReport)
+			}
+			return nil
+		},
+	}
+
+	bindHeaderFlag(cmd, &header, cliConfig)
+	cmd.Flags().StringVarP(&resource, "resource", "r", "", "Resource to list the permissions of the user on, as <namespace>:<id>")
+
+	return cmd
+}
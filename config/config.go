@@ -6,11 +6,20 @@ import (
 	"path/filepath"
 
 	"github.com/odpf/salt/config"
+	"github.com/odpf/shield/core/directory"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/oidc"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/core/signingkey"
+	"github.com/odpf/shield/core/webhook"
 	"github.com/odpf/shield/internal/proxy"
 	"github.com/odpf/shield/internal/server"
+	"github.com/odpf/shield/internal/store/openfga"
 	"github.com/odpf/shield/internal/store/spicedb"
+	"github.com/odpf/shield/internal/tracing"
 	"github.com/odpf/shield/pkg/db"
 	"github.com/odpf/shield/pkg/logger"
+	"github.com/odpf/shield/pkg/secrets"
 )
 
 type Shield struct {
@@ -22,6 +31,30 @@ type Shield struct {
 	App      server.Config        `yaml:"app"`
 	DB       db.Config            `yaml:"db"`
 	SpiceDB  spicedb.Config       `yaml:"spicedb"`
+	OpenFGA  openfga.Config       `yaml:"openfga"`
+	Authz    Authz                `yaml:"authz"`
+	Webhook  webhook.Config       `yaml:"webhook"`
+	Event    event.Config         `yaml:"event"`
+	Tracing  tracing.Config       `yaml:"tracing"`
+	OIDC     oidc.Config          `yaml:"oidc"`
+	Session  session.Config       `yaml:"session"`
+	LDAP     directory.Config     `yaml:"ldap"`
+	Keys     signingkey.Config    `yaml:"keys"`
+	Secrets  secrets.Config       `yaml:"secrets"`
+}
+
+const (
+	AuthzEngineSpiceDB  = "spicedb"
+	AuthzEngineEmbedded = "embedded"
+	AuthzEngineOpenFGA  = "openfga"
+)
+
+// Authz selects the engine the permissions are evaluated by
+type Authz struct {
+	// Engine is spicedb, embedded to evaluate the relations kept in
+	// postgres without spicedb in small deployments, or openfga to keep
+	// them in the store of openfga
+	Engine string `yaml:"engine" mapstructure:"engine" default:"spicedb"`
 }
 
 type NewRelic struct {
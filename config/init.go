@@ -150,7 +150,7 @@ func initRulesPath(rURL string) error {
 		return err
 	}
 
-	ruleRestGrpc, err := resourcesConfig.ReadFile("rules/sample.grpc.yaml")
+	ruleRestGrpc, err := rulesConfig.ReadFile("rules/sample.grpc.yaml")
 	if err != nil {
 		return err
 	}
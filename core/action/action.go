@@ -10,6 +10,7 @@ type Repository interface {
 	Create(ctx context.Context, action Action) (Action, error)
 	List(ctx context.Context) ([]Action, error)
 	Update(ctx context.Context, action Action) (Action, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type Action struct {
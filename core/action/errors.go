@@ -6,4 +6,5 @@ var (
 	ErrInvalidID     = errors.New("action id is invalid")
 	ErrNotExist      = errors.New("action doesn't exist")
 	ErrInvalidDetail = errors.New("invalid action detail")
+	ErrInUse         = errors.New("action is still referenced by other resources")
 )
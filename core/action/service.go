@@ -2,6 +2,10 @@ package action
 
 import (
 	"context"
+	"fmt"
+	"strings"
+
+	"github.com/odpf/shield/core/namespace"
 )
 
 type Service struct {
@@ -19,6 +23,9 @@ func (s Service) Get(ctx context.Context, id string) (Action, error) {
 }
 
 func (s Service) Create(ctx context.Context, action Action) (Action, error) {
+	if err := validate(action); err != nil {
+		return Action{}, err
+	}
 	newAction, err := s.repository.Create(ctx, action)
 	if err != nil {
 		return Action{}, err
@@ -32,6 +39,10 @@ func (s Service) List(ctx context.Context) ([]Action, error) {
 }
 
 func (s Service) Update(ctx context.Context, id string, action Action) (Action, error) {
+	action.ID = id
+	if err := validate(action); err != nil {
+		return Action{}, err
+	}
 	updatedAction, err := s.repository.Update(ctx, Action{
 		Name:        action.Name,
 		ID:          id,
@@ -43,3 +54,17 @@ func (s Service) Update(ctx context.Context, id string, action Action) (Action,
 
 	return updatedAction, nil
 }
+
+func (s Service) Delete(ctx context.Context, id string) error {
+	return s.repository.Delete(ctx, id)
+}
+
+// validate checks the action can be written to the authz schema, it is the
+// permission named by its id without the namespace suffix
+func validate(action Action) error {
+	permission := strings.TrimSuffix(action.ID, "."+action.NamespaceID)
+	if !namespace.IsValidRelationName(permission) {
+		return fmt.Errorf("%w: %s is not a valid authz schema permission name", ErrInvalidDetail, permission)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package apikey
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, key APIKey) (APIKey, error)
+	Get(ctx context.Context, id string) (APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (APIKey, error)
+	List(ctx context.Context, userID string) ([]APIKey, error)
+	Revoke(ctx context.Context, id string) (APIKey, error)
+}
+
+// APIKey is a long lived credential of a user or a service user, the key
+// itself is only known to its holder, shield keeps its hash and its prefix
+// which identifies the key without revealing it
+type APIKey struct {
+	ID        string
+	UserID    string
+	Name      string
+	Prefix    string
+	Hash      string
+	CreatedAt time.Time
+	RevokedAt time.Time
+}
+
+func (k APIKey) IsRevoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+// withoutHash is the key as it is recorded in the audit logs
+func (k APIKey) withoutHash() APIKey {
+	k.Hash = ""
+	return k
+}
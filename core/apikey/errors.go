@@ -0,0 +1,11 @@
+package apikey
+
+import "errors"
+
+var (
+	ErrNotExist      = errors.New("api key doesn't exist")
+	ErrInvalidDetail = errors.New("invalid api key detail")
+	ErrConflict      = errors.New("api key already exist")
+	ErrRevoked       = errors.New("api key is revoked")
+	ErrInvalidKey    = errors.New("api key is invalid")
+)
@@ -0,0 +1,59 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// keyScheme starts every key so leaked keys can be told apart from
+	// other secrets, e.g. by secret scanners
+	keyScheme = "shk"
+
+	prefixBytes = 6
+	secretBytes = 32
+)
+
+// IsKey tells if the token is formatted as an api key
+func IsKey(token string) bool {
+	return strings.HasPrefix(token, keyScheme+"_")
+}
+
+// generateKey returns a new key along with its prefix, the key is
+// formatted as shk_<prefix>_<secret>
+func generateKey() (key, prefix string, err error) {
+	p := make([]byte, prefixBytes)
+	if _, err := rand.Read(p); err != nil {
+		return "", "", err
+	}
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix = hex.EncodeToString(p)
+	return keyScheme + "_" + prefix + "_" + hex.EncodeToString(secret), prefix, nil
+}
+
+// parseKey returns the prefix of the key
+func parseKey(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != keyScheme || len(parts[1]) != 2*prefixBytes || len(parts[2]) != 2*secretBytes {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// hashKey is a plain sha256, the keys are random enough for a slow
+// password hash to add nothing while slowing every request down
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func matchesHash(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(hash)) == 1
+}
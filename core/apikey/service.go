@@ -0,0 +1,125 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+const auditResourceType = "apikey"
+
+type UserService interface {
+	GetByID(ctx context.Context, id string) (user.User, error)
+}
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+type Service struct {
+	repository   Repository
+	userService  UserService
+	auditService AuditService
+}
+
+func NewService(repository Repository, userService UserService, auditService AuditService) *Service {
+	return &Service{
+		repository:   repository,
+		userService:  userService,
+		auditService: auditService,
+	}
+}
+
+// Create mints a key for the user, the key is returned once and
+// can't be recovered afterwards
+func (s Service) Create(ctx context.Context, userID, name string) (APIKey, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return APIKey{}, "", fmt.Errorf("%w: name is required", ErrInvalidDetail)
+	}
+	if _, err := s.userService.GetByID(ctx, userID); err != nil {
+		return APIKey{}, "", err
+	}
+
+	key, prefix, err := generateKey()
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	created, err := s.repository.Create(ctx, APIKey{
+		UserID: userID,
+		Name:   name,
+		Prefix: prefix,
+		Hash:   hashKey(key),
+	})
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, created.ID, nil, created.withoutHash()); err != nil {
+		return APIKey{}, "", err
+	}
+	return created, key, nil
+}
+
+func (s Service) List(ctx context.Context, userID string) ([]APIKey, error) {
+	return s.repository.List(ctx, userID)
+}
+
+// Get returns the key of the id or prefix
+func (s Service) Get(ctx context.Context, idOrPrefix string) (APIKey, error) {
+	if uuid.IsValid(idOrPrefix) {
+		return s.repository.Get(ctx, idOrPrefix)
+	}
+	return s.repository.GetByPrefix(ctx, idOrPrefix)
+}
+
+// Revoke revokes the key of the id or prefix, revoked keys are kept so
+// they can still be listed
+func (s Service) Revoke(ctx context.Context, idOrPrefix string) (APIKey, error) {
+	key, err := s.Get(ctx, idOrPrefix)
+	if err != nil {
+		return APIKey{}, err
+	}
+	if key.IsRevoked() {
+		return APIKey{}, ErrRevoked
+	}
+
+	revoked, err := s.repository.Revoke(ctx, key.ID)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionRevoke, auditResourceType, revoked.ID, key.withoutHash(), revoked.withoutHash()); err != nil {
+		return APIKey{}, err
+	}
+	return revoked, nil
+}
+
+// Authenticate returns the user the key belongs to
+func (s Service) Authenticate(ctx context.Context, token string) (user.User, error) {
+	prefix, ok := parseKey(token)
+	if !ok {
+		return user.User{}, ErrInvalidKey
+	}
+
+	key, err := s.repository.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, ErrNotExist) {
+			return user.User{}, ErrInvalidKey
+		}
+		return user.User{}, err
+	}
+	if !matchesHash(token, key.Hash) {
+		return user.User{}, ErrInvalidKey
+	}
+	if key.IsRevoked() {
+		return user.User{}, ErrRevoked
+	}
+
+	return s.userService.GetByID(ctx, key.UserID)
+}
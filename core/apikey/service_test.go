@@ -0,0 +1,118 @@
+package apikey_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/odpf/shield/core/apikey"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	keys []apikey.APIKey
+}
+
+func (r *memoryRepository) Create(ctx context.Context, key apikey.APIKey) (apikey.APIKey, error) {
+	key.ID = "key" + strconv.Itoa(len(r.keys)+1)
+	r.keys = append(r.keys, key)
+	return key, nil
+}
+
+func (r *memoryRepository) Get(ctx context.Context, id string) (apikey.APIKey, error) {
+	for _, k := range r.keys {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return apikey.APIKey{}, apikey.ErrNotExist
+}
+
+func (r *memoryRepository) GetByPrefix(ctx context.Context, prefix string) (apikey.APIKey, error) {
+	for _, k := range r.keys {
+		if k.Prefix == prefix {
+			return k, nil
+		}
+	}
+	return apikey.APIKey{}, apikey.ErrNotExist
+}
+
+func (r *memoryRepository) List(ctx context.Context, userID string) ([]apikey.APIKey, error) {
+	return r.keys, nil
+}
+
+func (r *memoryRepository) Revoke(ctx context.Context, id string) (apikey.APIKey, error) {
+	for i, k := range r.keys {
+		if k.ID == id {
+			r.keys[i].RevokedAt = r.keys[i].CreatedAt.AddDate(0, 0, 1)
+			return r.keys[i], nil
+		}
+	}
+	return apikey.APIKey{}, apikey.ErrNotExist
+}
+
+type memoryUserService struct{}
+
+func (memoryUserService) GetByID(ctx context.Context, id string) (user.User, error) {
+	if id != "u1" {
+		return user.User{}, user.ErrNotExist
+	}
+	return user.User{ID: "u1", Email: "john.doe@odpf.io"}, nil
+}
+
+type noopAuditService struct{}
+
+func (noopAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	return nil
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should authenticate a created key until it is revoked", func(t *testing.T) {
+		repository := &memoryRepository{}
+		s := apikey.NewService(repository, memoryUserService{}, noopAuditService{})
+
+		created, key, err := s.Create(ctx, "u1", "ci")
+		require.NoError(t, err)
+		assert.True(t, apikey.IsKey(key))
+		assert.Contains(t, key, created.Prefix)
+		assert.NotContains(t, created.Hash, key)
+
+		usr, err := s.Authenticate(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, "u1", usr.ID)
+
+		_, err = s.Revoke(ctx, created.Prefix)
+		require.NoError(t, err)
+		_, err = s.Authenticate(ctx, key)
+		assert.ErrorIs(t, err, apikey.ErrRevoked)
+
+		_, err = s.Revoke(ctx, created.Prefix)
+		assert.ErrorIs(t, err, apikey.ErrRevoked)
+	})
+
+	t.Run("should reject a key whose secret doesn't match", func(t *testing.T) {
+		s := apikey.NewService(&memoryRepository{}, memoryUserService{}, noopAuditService{})
+
+		_, key, err := s.Create(ctx, "u1", "ci")
+		require.NoError(t, err)
+
+		forged := key[:strings.LastIndex(key, "_")+1] + strings.Repeat("0", 64)
+		_, err = s.Authenticate(ctx, forged)
+		assert.ErrorIs(t, err, apikey.ErrInvalidKey)
+
+		_, err = s.Authenticate(ctx, "shk_not_a_key")
+		assert.ErrorIs(t, err, apikey.ErrInvalidKey)
+	})
+
+	t.Run("should return error if the user doesn't exist", func(t *testing.T) {
+		s := apikey.NewService(&memoryRepository{}, memoryUserService{}, noopAuditService{})
+
+		_, _, err := s.Create(ctx, "u2", "ci")
+		assert.ErrorIs(t, err, user.ErrNotExist)
+	})
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionPurge   = "purge"
+	ActionRevoke  = "revoke"
+
+	// SystemActor is recorded for the changes not made on behalf of a user,
+	// e.g. the resources created while bootstrapping the schema
+	SystemActor = "system"
+)
+
+type Repository interface {
+	Create(ctx context.Context, log Log) (Log, error)
+	List(ctx context.Context, flt Filter) ([]Log, error)
+}
+
+// Log is a change made to a resource, the payloads are the resource before
+// and after the change, OldPayload is nil on create and NewPayload on delete
+type Log struct {
+	ID           string
+	Actor        string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	OldPayload   any
+	NewPayload   any
+	CreatedAt    time.Time
+}
@@ -0,0 +1,8 @@
+package audit
+
+import "errors"
+
+var (
+	ErrInvalidDetail    = errors.New("invalid audit log detail")
+	ErrInvalidTimeRange = errors.New("audit log time range is invalid")
+)
@@ -0,0 +1,11 @@
+package audit
+
+import "time"
+
+// Filter narrows down the logs listed, a zero Since or Until
+// leaves the time range open on that side
+type Filter struct {
+	Actor string
+	Since time.Time
+	Until time.Time
+}
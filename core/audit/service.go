@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/odpf/shield/core/user"
+)
+
+type Service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+// Record stores a change made to a resource, the actor is the
+// user of the request or SystemActor when there is none
+func (s Service) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	actor, ok := user.GetEmailFromContext(ctx)
+	if !ok || actor == "" {
+		actor = SystemActor
+	}
+
+	_, err := s.repository.Create(ctx, Log{
+		Actor:        actor,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		OldPayload:   oldPayload,
+		NewPayload:   newPayload,
+	})
+	return err
+}
+
+func (s Service) List(ctx context.Context, flt Filter) ([]Log, error) {
+	if !flt.Since.IsZero() && !flt.Until.IsZero() && flt.Until.Before(flt.Since) {
+		return nil, ErrInvalidTimeRange
+	}
+	return s.repository.List(ctx, flt)
+}
@@ -0,0 +1,62 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepository struct {
+	logs []audit.Log
+}
+
+func (r *memoryRepository) Create(ctx context.Context, log audit.Log) (audit.Log, error) {
+	r.logs = append(r.logs, log)
+	return log, nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, flt audit.Filter) ([]audit.Log, error) {
+	return r.logs, nil
+}
+
+func TestServiceRecord(t *testing.T) {
+	t.Run("should record the user of the request as the actor", func(t *testing.T) {
+		repo := &memoryRepository{}
+		svc := audit.NewService(repo)
+		ctx := user.SetContextWithEmail(context.Background(), "john.doe@odpf.io")
+
+		err := svc.Record(ctx, audit.ActionUpdate, "organization", "org-1", map[string]any{"name": "old"}, map[string]any{"name": "new"})
+		assert.NoError(t, err)
+		assert.Equal(t, []audit.Log{{
+			Actor:        "john.doe@odpf.io",
+			Action:       audit.ActionUpdate,
+			ResourceType: "organization",
+			ResourceID:   "org-1",
+			OldPayload:   map[string]any{"name": "old"},
+			NewPayload:   map[string]any{"name": "new"},
+		}}, repo.logs)
+	})
+
+	t.Run("should record the system as the actor without a user", func(t *testing.T) {
+		repo := &memoryRepository{}
+		svc := audit.NewService(repo)
+
+		err := svc.Record(context.Background(), audit.ActionCreate, "policy", "policy-1", nil, map[string]any{"id": "policy-1"})
+		assert.NoError(t, err)
+		assert.Equal(t, audit.SystemActor, repo.logs[0].Actor)
+	})
+}
+
+func TestServiceList(t *testing.T) {
+	t.Run("should return error if the time range ends before it starts", func(t *testing.T) {
+		svc := audit.NewService(&memoryRepository{})
+		now := time.Now()
+
+		_, err := svc.List(context.Background(), audit.Filter{Since: now, Until: now.Add(-time.Hour)})
+		assert.ErrorIs(t, err, audit.ErrInvalidTimeRange)
+	})
+}
@@ -0,0 +1,51 @@
+package directory
+
+import "time"
+
+type Config struct {
+	// URL of the ldap or active directory server, ldap://host:389 or
+	// ldaps://host:636. The directory isn't synced when it is empty.
+	URL string `yaml:"url" mapstructure:"url"`
+	// BindDN and BindPassword authenticate the searches, they are made
+	// anonymously when the dn is empty
+	BindDN       string `yaml:"bind_dn" mapstructure:"bind_dn"`
+	BindPassword string `yaml:"bind_password" mapstructure:"bind_password"`
+	// Organization the groups of the directory are created in, by id or slug
+	Organization string `yaml:"organization" mapstructure:"organization"`
+	// SyncInterval is how often the server syncs the directory, it is only
+	// synced with shield sync ldap when it is zero
+	SyncInterval time.Duration `yaml:"sync_interval" mapstructure:"sync_interval"`
+	// PageSize is the number of entries read at a time
+	PageSize int `yaml:"page_size" mapstructure:"page_size" default:"500"`
+	// Timeout of the requests made to the server
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" default:"10s"`
+
+	Users  UsersConfig  `yaml:"users" mapstructure:"users"`
+	Groups GroupsConfig `yaml:"groups" mapstructure:"groups"`
+}
+
+type UsersConfig struct {
+	// BaseDN is the dn the users are searched under
+	BaseDN string `yaml:"base_dn" mapstructure:"base_dn"`
+	Filter string `yaml:"filter" mapstructure:"filter" default:"(objectClass=person)"`
+	// the attributes of the entries the users are made of, the id
+	// attribute is what the members of the groups refer to when they aren't
+	// the dns of the users, like the memberUid of posix groups
+	IDAttribute    string `yaml:"id_attribute" mapstructure:"id_attribute" default:"uid"`
+	EmailAttribute string `yaml:"email_attribute" mapstructure:"email_attribute" default:"mail"`
+	NameAttribute  string `yaml:"name_attribute" mapstructure:"name_attribute" default:"cn"`
+}
+
+type GroupsConfig struct {
+	// BaseDN is the dn the groups are searched under, groups aren't synced
+	// when it is empty
+	BaseDN          string `yaml:"base_dn" mapstructure:"base_dn"`
+	Filter          string `yaml:"filter" mapstructure:"filter" default:"(objectClass=groupOfNames)"`
+	NameAttribute   string `yaml:"name_attribute" mapstructure:"name_attribute" default:"cn"`
+	MemberAttribute string `yaml:"member_attribute" mapstructure:"member_attribute" default:"member"`
+}
+
+// Enabled reports whether a directory is configured
+func (c Config) Enabled() bool {
+	return c.URL != ""
+}
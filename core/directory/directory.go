@@ -0,0 +1,77 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/user"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionAdd    = "add"
+	ActionRemove = "remove"
+
+	KindUser   = "user"
+	KindGroup  = "group"
+	KindMember = "member"
+)
+
+// Source reads the users and groups of a directory like ldap
+type Source interface {
+	Users(ctx context.Context) ([]User, error)
+	Groups(ctx context.Context) ([]Group, error)
+}
+
+// User is a user entry of the directory
+type User struct {
+	DN    string
+	ID    string
+	Email string
+	Name  string
+}
+
+// Group is a group entry of the directory, members are the dns or the ids
+// of the user entries
+type Group struct {
+	DN      string
+	Name    string
+	Members []string
+}
+
+// Change is what syncing the directory does to shield. Name is the email
+// of the user or the slug of the group changed, Group is the slug of the
+// group of a member.
+type Change struct {
+	Action string
+	Kind   string
+	Name   string
+	Group  string
+
+	// user and group are the shield user and group changed
+	user  user.User
+	group group.Group
+}
+
+func (c Change) String() string {
+	if c.Kind == KindMember {
+		return fmt.Sprintf("%s member %s of group %s", c.Action, c.Name, c.Group)
+	}
+	return fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.Name)
+}
+
+// Conflict is an entry of the directory that can't be synced, the entry is
+// skipped
+type Conflict struct {
+	DN     string
+	Reason string
+}
+
+// Plan is the changes syncing the directory makes to shield, in the order
+// they are applied, and the entries that can't be synced
+type Plan struct {
+	Changes   []Change
+	Conflicts []Conflict
+}
@@ -0,0 +1,259 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/pkg/metadata"
+	"github.com/odpf/shield/pkg/str"
+)
+
+type UserService interface {
+	GetByEmail(ctx context.Context, email string) (user.User, error)
+	Create(ctx context.Context, user user.User) (user.User, error)
+	UpdateByID(ctx context.Context, toUpdate user.User) (user.User, error)
+}
+
+type GroupService interface {
+	Get(ctx context.Context, idOrSlug string) (group.Group, error)
+	Create(ctx context.Context, grp group.Group) (group.Group, error)
+	ListUsers(ctx context.Context, idOrSlug string) ([]user.User, error)
+	AddMember(ctx context.Context, grp group.Group, email string) error
+	RemoveMember(ctx context.Context, grp group.Group, userID string) error
+}
+
+type OrganizationService interface {
+	Get(ctx context.Context, idOrSlug string) (organization.Organization, error)
+}
+
+// Service syncs the users and groups of a directory into shield. Users are
+// matched by email and groups by slug, the members of the groups of the
+// directory are made the same as in the directory while the users and groups
+// missing from the directory are left as they are.
+type Service struct {
+	source              Source
+	userService         UserService
+	groupService        GroupService
+	organizationService OrganizationService
+	config              Config
+}
+
+func NewService(source Source, userService UserService, groupService GroupService, organizationService OrganizationService, config Config) *Service {
+	return &Service{
+		source:              source,
+		userService:         userService,
+		groupService:        groupService,
+		organizationService: organizationService,
+		config:              config,
+	}
+}
+
+// Run syncs the directory every sync interval until the context is done,
+// failed syncs are retried at the next interval
+func (s Service) Run(ctx context.Context, logger log.Logger) {
+	if s.config.SyncInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+	for {
+		plan, err := s.Sync(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("failed to sync directory", "err", err)
+		} else if err == nil {
+			if len(plan.Changes) > 0 {
+				logger.Info("synced directory", "changes", len(plan.Changes))
+			}
+			for _, c := range plan.Conflicts {
+				logger.Warn("skipped directory entry", "dn", c.DN, "reason", c.Reason)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sync plans the sync of the directory and applies it
+func (s Service) Sync(ctx context.Context) (Plan, error) {
+	plan, err := s.Plan(ctx)
+	if err != nil {
+		return Plan{}, err
+	}
+	return plan, s.Apply(ctx, plan)
+}
+
+// Plan compares the directory with shield and returns the changes syncing
+// it makes, without making them
+func (s Service) Plan(ctx context.Context) (Plan, error) {
+	var plan Plan
+
+	entries, err := s.source.Users(ctx)
+	if err != nil {
+		return Plan{}, fmt.Errorf("reading users: %w", err)
+	}
+	// synced maps the dns and ids of the user entries to the email of the
+	// users the members of the groups refer to
+	synced := map[string]string{}
+	seen := map[string]string{}
+	for _, entry := range entries {
+		email := strings.ToLower(strings.TrimSpace(entry.Email))
+		if email == "" {
+			plan.Conflicts = append(plan.Conflicts, Conflict{DN: entry.DN, Reason: "entry has no email"})
+			continue
+		}
+		if dn, ok := seen[email]; ok {
+			plan.Conflicts = append(plan.Conflicts, Conflict{DN: entry.DN, Reason: fmt.Sprintf("email %s is the email of %s as well", email, dn)})
+			continue
+		}
+		seen[email] = entry.DN
+
+		usr, err := s.userService.GetByEmail(ctx, email)
+		switch {
+		case errors.Is(err, user.ErrNotExist):
+			plan.Changes = append(plan.Changes, Change{Action: ActionCreate, Kind: KindUser, Name: email, user: user.User{Email: email, Name: entry.Name}})
+		case err != nil:
+			return Plan{}, err
+		case usr.IsDisabled():
+			plan.Conflicts = append(plan.Conflicts, Conflict{DN: entry.DN, Reason: fmt.Sprintf("user %s is disabled", email)})
+			continue
+		case entry.Name != "" && entry.Name != usr.Name:
+			usr.Name = entry.Name
+			plan.Changes = append(plan.Changes, Change{Action: ActionUpdate, Kind: KindUser, Name: email, user: usr})
+		}
+		synced[strings.ToLower(entry.DN)] = email
+		if entry.ID != "" {
+			synced[strings.ToLower(entry.ID)] = email
+		}
+	}
+
+	if s.config.Groups.BaseDN == "" {
+		return plan, nil
+	}
+	groups, err := s.source.Groups(ctx)
+	if err != nil {
+		return Plan{}, fmt.Errorf("reading groups: %w", err)
+	}
+	org, err := s.organizationService.Get(ctx, s.config.Organization)
+	if err != nil {
+		return Plan{}, fmt.Errorf("organization %s: %w", s.config.Organization, err)
+	}
+
+	var members []Change
+	slugs := map[string]string{}
+	for _, entry := range groups {
+		slug := strings.ToLower(str.GenerateSlug(entry.Name))
+		if slug == "" {
+			plan.Conflicts = append(plan.Conflicts, Conflict{DN: entry.DN, Reason: "entry has no name"})
+			continue
+		}
+		if dn, ok := slugs[slug]; ok {
+			plan.Conflicts = append(plan.Conflicts, Conflict{DN: entry.DN, Reason: fmt.Sprintf("slug %s is the slug of %s as well", slug, dn)})
+			continue
+		}
+		slugs[slug] = entry.DN
+
+		current := map[string]user.User{}
+		grp, err := s.groupService.Get(ctx, slug)
+		switch {
+		case errors.Is(err, group.ErrNotExist):
+			grp = group.Group{Name: entry.Name, Slug: slug, OrganizationID: org.ID, Metadata: metadata.Metadata{}}
+			plan.Changes = append(plan.Changes, Change{Action: ActionCreate, Kind: KindGroup, Name: slug, group: grp})
+		case err != nil:
+			return Plan{}, err
+		case grp.OrganizationID != org.ID:
+			plan.Conflicts = append(plan.Conflicts, Conflict{DN: entry.DN, Reason: fmt.Sprintf("group %s belongs to another organization", slug)})
+			continue
+		default:
+			users, err := s.groupService.ListUsers(ctx, grp.ID)
+			if err != nil {
+				return Plan{}, err
+			}
+			for _, u := range users {
+				current[strings.ToLower(u.Email)] = u
+			}
+		}
+
+		wanted := map[string]bool{}
+		for _, m := range entry.Members {
+			email, ok := synced[strings.ToLower(m)]
+			if !ok {
+				plan.Conflicts = append(plan.Conflicts, Conflict{DN: entry.DN, Reason: fmt.Sprintf("member %s isn't a synced user", m)})
+				continue
+			}
+			wanted[email] = true
+		}
+		for _, email := range sortedKeys(wanted) {
+			if _, ok := current[email]; !ok {
+				members = append(members, Change{Action: ActionAdd, Kind: KindMember, Name: email, Group: slug, group: grp})
+			}
+		}
+		for _, email := range sortedKeys(current) {
+			if !wanted[email] {
+				members = append(members, Change{Action: ActionRemove, Kind: KindMember, Name: email, Group: slug, group: grp, user: current[email]})
+			}
+		}
+	}
+	plan.Changes = append(plan.Changes, members...)
+	return plan, nil
+}
+
+// Apply makes the changes of the plan in order, it stops at the first
+// failure leaving the changes applied before it in place
+func (s Service) Apply(ctx context.Context, plan Plan) error {
+	// created are the groups created so far, the members of a new group
+	// are added once it has an id
+	created := map[string]group.Group{}
+	for _, c := range plan.Changes {
+		if err := s.apply(ctx, c, created); err != nil {
+			return fmt.Errorf("could not %s: %w", c, err)
+		}
+	}
+	return nil
+}
+
+func (s Service) apply(ctx context.Context, c Change, created map[string]group.Group) error {
+	switch {
+	case c.Kind == KindUser && c.Action == ActionCreate:
+		_, err := s.userService.Create(ctx, c.user)
+		return err
+	case c.Kind == KindUser && c.Action == ActionUpdate:
+		_, err := s.userService.UpdateByID(ctx, c.user)
+		return err
+	case c.Kind == KindGroup && c.Action == ActionCreate:
+		grp, err := s.groupService.Create(ctx, c.group)
+		created[grp.Slug] = grp
+		return err
+	case c.Kind == KindMember:
+		grp := c.group
+		if grp.ID == "" {
+			grp = created[c.Group]
+		}
+		if c.Action == ActionAdd {
+			return s.groupService.AddMember(ctx, grp, c.Name)
+		}
+		return s.groupService.RemoveMember(ctx, grp, c.user.ID)
+	}
+	return fmt.Errorf("unknown change %s", c)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
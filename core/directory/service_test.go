@@ -0,0 +1,169 @@
+package directory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/directory"
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySource struct {
+	users  []directory.User
+	groups []directory.Group
+}
+
+func (s memorySource) Users(ctx context.Context) ([]directory.User, error) {
+	return s.users, nil
+}
+
+func (s memorySource) Groups(ctx context.Context) ([]directory.Group, error) {
+	return s.groups, nil
+}
+
+type memoryUserService struct {
+	users map[string]user.User
+}
+
+func (s *memoryUserService) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	usr, ok := s.users[email]
+	if !ok {
+		return user.User{}, user.ErrNotExist
+	}
+	return usr, nil
+}
+
+func (s *memoryUserService) Create(ctx context.Context, usr user.User) (user.User, error) {
+	usr.ID = "id-" + usr.Email
+	s.users[usr.Email] = usr
+	return usr, nil
+}
+
+func (s *memoryUserService) UpdateByID(ctx context.Context, usr user.User) (user.User, error) {
+	s.users[usr.Email] = usr
+	return usr, nil
+}
+
+type memoryGroupService struct {
+	groups map[string]group.Group
+	// members are the emails of the members by group id
+	members map[string][]string
+	users   *memoryUserService
+}
+
+func (s *memoryGroupService) Get(ctx context.Context, slug string) (group.Group, error) {
+	grp, ok := s.groups[slug]
+	if !ok {
+		return group.Group{}, group.ErrNotExist
+	}
+	return grp, nil
+}
+
+func (s *memoryGroupService) Create(ctx context.Context, grp group.Group) (group.Group, error) {
+	grp.ID = "id-" + grp.Slug
+	s.groups[grp.Slug] = grp
+	return grp, nil
+}
+
+func (s *memoryGroupService) ListUsers(ctx context.Context, id string) ([]user.User, error) {
+	var users []user.User
+	for _, email := range s.members[id] {
+		users = append(users, s.users.users[email])
+	}
+	return users, nil
+}
+
+func (s *memoryGroupService) AddMember(ctx context.Context, grp group.Group, email string) error {
+	s.members[grp.ID] = append(s.members[grp.ID], email)
+	return nil
+}
+
+func (s *memoryGroupService) RemoveMember(ctx context.Context, grp group.Group, userID string) error {
+	var kept []string
+	for _, email := range s.members[grp.ID] {
+		if s.users.users[email].ID != userID {
+			kept = append(kept, email)
+		}
+	}
+	s.members[grp.ID] = kept
+	return nil
+}
+
+type memoryOrganizationService struct{}
+
+func (memoryOrganizationService) Get(ctx context.Context, idOrSlug string) (organization.Organization, error) {
+	return organization.Organization{ID: "org1", Slug: "odpf"}, nil
+}
+
+func TestServiceSync(t *testing.T) {
+	users := &memoryUserService{users: map[string]user.User{
+		"bob@odpf.io":   {ID: "u-bob", Email: "bob@odpf.io", Name: "Bob"},
+		"carol@odpf.io": {ID: "u-carol", Email: "carol@odpf.io", Name: "Carol"},
+		"dave@odpf.io":  {ID: "u-dave", Email: "dave@odpf.io", Name: "Dave"},
+	}}
+	groups := &memoryGroupService{
+		groups: map[string]group.Group{
+			"data":    {ID: "g-data", Slug: "data", OrganizationID: "org1"},
+			"finance": {ID: "g-finance", Slug: "finance", OrganizationID: "org2"},
+		},
+		members: map[string][]string{"g-data": {"carol@odpf.io", "dave@odpf.io"}},
+		users:   users,
+	}
+	source := memorySource{
+		users: []directory.User{
+			{DN: "uid=alice,ou=people,dc=odpf,dc=io", ID: "alice", Email: "Alice@odpf.io", Name: "Alice"},
+			{DN: "uid=bob,ou=people,dc=odpf,dc=io", ID: "bob", Email: "bob@odpf.io", Name: "Robert"},
+			{DN: "uid=carol,ou=people,dc=odpf,dc=io", ID: "carol", Email: "carol@odpf.io", Name: "Carol"},
+			{DN: "uid=nomail,ou=people,dc=odpf,dc=io", ID: "nomail"},
+			{DN: "uid=bob2,ou=people,dc=odpf,dc=io", ID: "bob2", Email: "bob@odpf.io"},
+		},
+		groups: []directory.Group{
+			{DN: "cn=Data,ou=groups,dc=odpf,dc=io", Name: "Data", Members: []string{"uid=alice,ou=people,dc=odpf,dc=io", "carol", "uid=nomail,ou=people,dc=odpf,dc=io"}},
+			{DN: "cn=Platform Team,ou=groups,dc=odpf,dc=io", Name: "Platform Team", Members: []string{"bob"}},
+			{DN: "cn=Finance,ou=groups,dc=odpf,dc=io", Name: "Finance", Members: []string{"bob"}},
+		},
+	}
+	s := directory.NewService(source, users, groups, memoryOrganizationService{}, directory.Config{
+		Organization: "odpf",
+		Groups:       directory.GroupsConfig{BaseDN: "ou=groups,dc=odpf,dc=io"},
+	})
+
+	plan, err := s.Plan(context.Background())
+	require.NoError(t, err)
+	var changes []string
+	for _, c := range plan.Changes {
+		changes = append(changes, c.String())
+	}
+	assert.Equal(t, []string{
+		"create user alice@odpf.io",
+		"update user bob@odpf.io",
+		"create group platform-team",
+		"add member alice@odpf.io of group data",
+		"remove member dave@odpf.io of group data",
+		"add member bob@odpf.io of group platform-team",
+	}, changes)
+	assert.Equal(t, []directory.Conflict{
+		{DN: "uid=nomail,ou=people,dc=odpf,dc=io", Reason: "entry has no email"},
+		{DN: "uid=bob2,ou=people,dc=odpf,dc=io", Reason: "email bob@odpf.io is the email of uid=bob,ou=people,dc=odpf,dc=io as well"},
+		{DN: "cn=Data,ou=groups,dc=odpf,dc=io", Reason: "member uid=nomail,ou=people,dc=odpf,dc=io isn't a synced user"},
+		{DN: "cn=Finance,ou=groups,dc=odpf,dc=io", Reason: "group finance belongs to another organization"},
+	}, plan.Conflicts)
+	assert.Len(t, users.users, 3, "planning changes nothing")
+
+	require.NoError(t, s.Apply(context.Background(), plan))
+	assert.Equal(t, "Alice", users.users["alice@odpf.io"].Name)
+	assert.Equal(t, "Robert", users.users["bob@odpf.io"].Name)
+	assert.Equal(t, "org1", groups.groups["platform-team"].OrganizationID)
+	assert.ElementsMatch(t, []string{"carol@odpf.io", "alice@odpf.io"}, groups.members["g-data"])
+	assert.Equal(t, []string{"bob@odpf.io"}, groups.members["id-platform-team"])
+
+	t.Run("should change nothing once synced", func(t *testing.T) {
+		plan, err := s.Plan(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, plan.Changes)
+	})
+}
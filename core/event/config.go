@@ -0,0 +1,51 @@
+package event
+
+import "time"
+
+const (
+	PublisherKafka = "kafka"
+	PublisherNATS  = "nats"
+	PublisherLog   = "log"
+)
+
+type Config struct {
+	// Publisher is the broker the events are published to, kafka, nats or
+	// log, events are not published when it is empty
+	Publisher string `yaml:"publisher" mapstructure:"publisher"`
+	// BatchSize is the number of events published at once
+	BatchSize int `yaml:"batch_size" mapstructure:"batch_size" default:"100"`
+	// PollInterval is how often the outbox is looked up
+	PollInterval time.Duration `yaml:"poll_interval" mapstructure:"poll_interval" default:"1s"`
+	// Lease is how long the events of a batch are held by a relay before
+	// they are published again by any relay, it has to outlast publishing
+	Lease time.Duration `yaml:"lease" mapstructure:"lease" default:"1m"`
+	// WatchBuffer is the number of events a watcher can fall behind by
+	// before it is dropped
+	WatchBuffer int         `yaml:"watch_buffer" mapstructure:"watch_buffer" default:"100"`
+	Kafka       KafkaConfig `yaml:"kafka" mapstructure:"kafka"`
+	NATS        NATSConfig  `yaml:"nats" mapstructure:"nats"`
+}
+
+// KafkaConfig is the topic the events are produced to through the rest
+// proxy of confluent, v2 of its api, shield doesn't connect to the brokers
+type KafkaConfig struct {
+	// RESTProxyURL is the url of the kafka rest proxy the events are
+	// produced through, e.g. http://localhost:8082
+	RESTProxyURL string `yaml:"rest_proxy_url" mapstructure:"rest_proxy_url"`
+	// Topic the events are produced to
+	Topic string `yaml:"topic" mapstructure:"topic" default:"shield-events"`
+	// Timeout is how long the proxy has to acknowledge a batch
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" default:"10s"`
+}
+
+type NATSConfig struct {
+	// URL of the nats servers, comma separated, e.g. nats://localhost:4222
+	URL string `yaml:"url" mapstructure:"url" default:"nats://localhost:4222"`
+	// Subject the subjects of the events start with, an event of the type
+	// organization.created is published to shield.events.organization.created.
+	// A jetstream stream has to take the subjects, e.g. shield.events.>
+	Subject string `yaml:"subject" mapstructure:"subject" default:"shield.events"`
+	// Timeout is how long the server has to connect and the stream has to
+	// acknowledge a batch
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" default:"10s"`
+}
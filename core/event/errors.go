@@ -0,0 +1,9 @@
+package event
+
+import "errors"
+
+var (
+	ErrInvalidDetail      = errors.New("invalid event detail")
+	ErrUnknownPublisher   = errors.New("unknown event publisher")
+	ErrPublishingRejected = errors.New("broker rejected the events")
+)
@@ -0,0 +1,64 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/odpf/shield/core/audit"
+)
+
+// actions are the past tense of the audited actions, the events are named
+// after what happened to the resource, e.g. organization.created
+var actions = map[string]string{
+	audit.ActionCreate:  "created",
+	audit.ActionUpdate:  "updated",
+	audit.ActionDelete:  "deleted",
+	audit.ActionRestore: "restored",
+	audit.ActionPurge:   "purged",
+	audit.ActionRevoke:  "revoked",
+}
+
+type Repository interface {
+	Create(ctx context.Context, msg Message) error
+	// Claim returns at most limit messages in the order they were created,
+	// they are not returned again before the lease passed so a message is
+	// published by a single relay at a time
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Message, error)
+	// Delete removes the messages published from the outbox
+	Delete(ctx context.Context, ids []string) error
+}
+
+// Event is a change made to a resource, the payloads are the resource
+// before and after the change. The id is kept when the event is published
+// more than once so consumers can drop the duplicates.
+type Event struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	Actor        string    `json:"actor"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	OldPayload   any       `json:"old_payload,omitempty"`
+	NewPayload   any       `json:"new_payload,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Message is an event in the outbox, Payload is the event as json and Key
+// the id of its resource, brokers partitioning by key keep the events of
+// a resource in order
+type Message struct {
+	ID        string
+	Type      string
+	Key       string
+	Payload   []byte
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// TypeOf is the type of the event of the action on the resource type
+func TypeOf(resourceType, action string) string {
+	if past, ok := actions[action]; ok {
+		action = past
+	}
+	return fmt.Sprintf("%s.%s", resourceType, action)
+}
@@ -0,0 +1,25 @@
+package event
+
+import "strings"
+
+// MatchesFilter reports whether the filter the events are subscribed with
+// matches the event type, * matches every event and organization.* every
+// event of the organizations
+func MatchesFilter(filter, eventType string) bool {
+	if filter == "*" || filter == eventType {
+		return true
+	}
+	if strings.HasSuffix(filter, ".*") {
+		return strings.HasPrefix(eventType, strings.TrimSuffix(filter, "*"))
+	}
+	return false
+}
+
+// ValidFilter reports whether the filter is *, <resource>.* or an event type
+func ValidFilter(filter string) bool {
+	if filter == "*" {
+		return true
+	}
+	resourceType, action, ok := strings.Cut(filter, ".")
+	return ok && resourceType != "" && action != "" && !strings.Contains(action, ".")
+}
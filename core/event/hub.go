@@ -0,0 +1,97 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Hub pushes the events recorded by this instance to the watchers connected
+// to it. Notifying never blocks the change being recorded, a watcher falling
+// more than its buffer behind is dropped and has to list what it missed
+// before watching again.
+type Hub struct {
+	mu       sync.Mutex
+	watchers map[*watcher]struct{}
+	buffer   int
+}
+
+type watcher struct {
+	filters []string
+	events  chan Event
+}
+
+func NewHub(buffer int) *Hub {
+	return &Hub{
+		watchers: map[*watcher]struct{}{},
+		buffer:   buffer,
+	}
+}
+
+// Watch returns the events matching any of the filters recorded from now on,
+// the channel is closed once ctx is done or the watcher was dropped
+func (h *Hub) Watch(ctx context.Context, filters []string) (<-chan Event, error) {
+	if len(filters) == 0 {
+		return nil, fmt.Errorf("%w: at least a filter is required", ErrInvalidDetail)
+	}
+	for _, flt := range filters {
+		if !ValidFilter(flt) {
+			return nil, fmt.Errorf("%w: invalid filter %s", ErrInvalidDetail, flt)
+		}
+	}
+
+	w := &watcher{filters: filters, events: make(chan Event, h.buffer)}
+	h.mu.Lock()
+	h.watchers[w] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.drop(w)
+	}()
+	return w.events, nil
+}
+
+// Notify hands the event to the watchers of it
+func (h *Hub) Notify(ctx context.Context, ev Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for w := range h.watchers {
+		if !w.matches(ev.Type) {
+			continue
+		}
+		select {
+		case w.events <- ev:
+		default:
+			h.drop(w)
+		}
+	}
+	return nil
+}
+
+// Watchers returns the number of watchers connected
+func (h *Hub) Watchers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.watchers)
+}
+
+// drop closes the events of the watcher once, h.mu has to be held
+func (h *Hub) drop(w *watcher) {
+	if _, ok := h.watchers[w]; !ok {
+		return
+	}
+	delete(h.watchers, w)
+	close(w.events)
+}
+
+func (w *watcher) matches(eventType string) bool {
+	for _, flt := range w.filters {
+		if MatchesFilter(flt, eventType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package event_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	t.Run("should push the events matching the filters of the watcher", func(t *testing.T) {
+		hub := event.NewHub(10)
+		events, err := hub.Watch(context.Background(), []string{"organization.*", "policy.deleted"})
+		assert.NoError(t, err)
+
+		for _, typ := range []string{"organization.created", "group.created", "policy.updated", "policy.deleted"} {
+			assert.NoError(t, hub.Notify(context.Background(), event.Event{Type: typ}))
+		}
+
+		assert.Equal(t, "organization.created", (<-events).Type)
+		assert.Equal(t, "policy.deleted", (<-events).Type)
+		assert.Empty(t, events)
+	})
+
+	t.Run("should close the events once the context is done", func(t *testing.T) {
+		hub := event.NewHub(10)
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := hub.Watch(ctx, []string{"*"})
+		assert.NoError(t, err)
+
+		cancel()
+		select {
+		case _, ok := <-events:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("events were not closed")
+		}
+		assert.Equal(t, 0, hub.Watchers())
+	})
+
+	t.Run("should drop the watcher falling behind without blocking", func(t *testing.T) {
+		hub := event.NewHub(1)
+		slow, err := hub.Watch(context.Background(), []string{"*"})
+		assert.NoError(t, err)
+		other, err := hub.Watch(context.Background(), []string{"group.*"})
+		assert.NoError(t, err)
+
+		assert.NoError(t, hub.Notify(context.Background(), event.Event{ID: "1", Type: "organization.created"}))
+		assert.NoError(t, hub.Notify(context.Background(), event.Event{ID: "2", Type: "organization.updated"}))
+
+		assert.Equal(t, "1", (<-slow).ID)
+		_, ok := <-slow
+		assert.False(t, ok)
+		assert.Equal(t, 1, hub.Watchers())
+		assert.Empty(t, other)
+	})
+
+	t.Run("should return error if a filter is invalid", func(t *testing.T) {
+		hub := event.NewHub(10)
+		for _, filters := range [][]string{nil, {"organization"}, {"*", "organization.created.now"}} {
+			_, err := hub.Watch(context.Background(), filters)
+			assert.ErrorIs(t, err, event.ErrInvalidDetail)
+		}
+		assert.Equal(t, 0, hub.Watchers())
+	})
+}
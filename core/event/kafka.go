@@ -0,0 +1,94 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+	maxErrorBody     = 512
+)
+
+// KafkaPublisher produces the messages to a topic through the confluent
+// kafka rest proxy, keyed by their resource
+type KafkaPublisher struct {
+	client   *http.Client
+	topicURL string
+}
+
+func NewKafkaPublisher(cfg KafkaConfig) (*KafkaPublisher, error) {
+	proxyURL, err := url.Parse(cfg.RESTProxyURL)
+	if err != nil || !proxyURL.IsAbs() || proxyURL.Host == "" {
+		return nil, fmt.Errorf("%w: kafka rest proxy url should be an absolute url", ErrInvalidDetail)
+	}
+	if strings.TrimSpace(cfg.Topic) == "" {
+		return nil, fmt.Errorf("%w: kafka topic is required", ErrInvalidDetail)
+	}
+
+	return &KafkaPublisher{
+		client:   &http.Client{Timeout: cfg.Timeout},
+		topicURL: strings.TrimSuffix(proxyURL.String(), "/") + "/topics/" + url.PathEscape(cfg.Topic),
+	}, nil
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (p KafkaPublisher) Publish(ctx context.Context, msgs []Message) error {
+	records := make([]kafkaRecord, 0, len(msgs))
+	for _, msg := range msgs {
+		records = append(records, kafkaRecord{Key: msg.Key, Value: msg.Payload})
+	}
+	body, err := json.Marshal(map[string][]kafkaRecord{"records": records})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.topicURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("%w: rest proxy responded %s: %s", ErrPublishingRejected, resp.Status, body)
+	}
+
+	// the proxy responds ok even when some of the records failed, with
+	// the error of each in its offset
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return err
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("%w: %s", ErrPublishingRejected, offset.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package event_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaPublisherPublish(t *testing.T) {
+	msgs := []event.Message{
+		{ID: "e1", Key: "org-1", Payload: []byte(`{"type":"organization.created"}`)},
+		{ID: "e2", Key: "org-1", Payload: []byte(`{"type":"organization.updated"}`)},
+	}
+
+	t.Run("should produce the messages keyed by their resource", func(t *testing.T) {
+		var got struct {
+			Records []struct {
+				Key   string          `json:"key"`
+				Value json.RawMessage `json:"value"`
+			} `json:"records"`
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/topics/shield-events" || r.Header.Get("Content-Type") != "application/vnd.kafka.json.v2+json" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.Write([]byte(`{"offsets":[{"partition":0,"offset":1},{"partition":0,"offset":2}]}`))
+		}))
+		defer server.Close()
+
+		publisher, err := event.NewKafkaPublisher(event.KafkaConfig{RESTProxyURL: server.URL + "/", Topic: "shield-events", Timeout: time.Second})
+		require.NoError(t, err)
+
+		assert.NoError(t, publisher.Publish(context.Background(), msgs))
+		require.Len(t, got.Records, 2)
+		assert.Equal(t, "org-1", got.Records[0].Key)
+		assert.JSONEq(t, `{"type":"organization.updated"}`, string(got.Records[1].Value))
+	})
+
+	t.Run("should return error if a record failed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"offsets":[{"partition":0,"offset":1},{"error_code":50002,"error":"leader not available"}]}`))
+		}))
+		defer server.Close()
+
+		publisher, err := event.NewKafkaPublisher(event.KafkaConfig{RESTProxyURL: server.URL, Topic: "shield-events", Timeout: time.Second})
+		require.NoError(t, err)
+
+		err = publisher.Publish(context.Background(), msgs)
+		assert.ErrorIs(t, err, event.ErrPublishingRejected)
+	})
+
+	t.Run("should return error if the proxy rejected the request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}))
+		defer server.Close()
+
+		publisher, err := event.NewKafkaPublisher(event.KafkaConfig{RESTProxyURL: server.URL, Topic: "shield-events", Timeout: time.Second})
+		require.NoError(t, err)
+
+		err = publisher.Publish(context.Background(), msgs)
+		assert.ErrorIs(t, err, event.ErrPublishingRejected)
+	})
+}
+
+func TestNewPublisher(t *testing.T) {
+	publisher, err := event.NewPublisher(event.Config{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, publisher)
+
+	_, err = event.NewPublisher(event.Config{Publisher: "pulsar"}, nil)
+	assert.ErrorIs(t, err, event.ErrUnknownPublisher)
+
+	_, err = event.NewPublisher(event.Config{Publisher: event.PublisherNATS}, nil)
+	assert.ErrorIs(t, err, event.ErrInvalidDetail)
+
+	_, err = event.NewPublisher(event.Config{Publisher: event.PublisherKafka}, nil)
+	assert.ErrorIs(t, err, event.ErrInvalidDetail)
+}
@@ -0,0 +1,101 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsKeyHeader is the header the resource of a message is kept in, nats
+// has no keys
+const natsKeyHeader = "Shield-Event-Key"
+
+// NATSPublisher publishes the messages to jetstream, on the subject of their
+// type under the subject of the config. The stream of the subjects
+// acknowledges a message once it is stored and drops the ones published
+// again within its duplicate window, the id of a message is its event id.
+type NATSPublisher struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+	timeout time.Duration
+}
+
+func NewNATSPublisher(cfg NATSConfig) (*NATSPublisher, error) {
+	if strings.TrimSpace(cfg.URL) == "" {
+		return nil, fmt.Errorf("%w: nats url is required", ErrInvalidDetail)
+	}
+	subject := strings.TrimSuffix(cfg.Subject, ".")
+	if strings.TrimSpace(subject) == "" || strings.ContainsAny(subject, " *>") {
+		return nil, fmt.Errorf("%w: nats subject should be a subject without wildcards", ErrInvalidDetail)
+	}
+
+	// the server being down when shield starts is like it going down later
+	// on, the events wait in the outbox until it is back
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name("shield"),
+		nats.Timeout(cfg.Timeout),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, err
+	}
+	js, err := conn.JetStream(nats.MaxWait(cfg.Timeout))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &NATSPublisher{
+		conn:    conn,
+		js:      js,
+		subject: subject,
+		timeout: cfg.Timeout,
+	}, nil
+}
+
+func (p NATSPublisher) Publish(ctx context.Context, msgs []Message) error {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	acks := make([]nats.PubAckFuture, 0, len(msgs))
+	for _, msg := range msgs {
+		m := nats.NewMsg(p.subject)
+		if msg.Type != "" {
+			m.Subject += "." + msg.Type
+		}
+		m.Header.Set(natsKeyHeader, msg.Key)
+		m.Data = msg.Payload
+
+		ack, err := p.js.PublishMsgAsync(m, nats.MsgId(msg.ID))
+		if err != nil {
+			return err
+		}
+		acks = append(acks, ack)
+	}
+
+	for _, ack := range acks {
+		select {
+		case <-ack.Ok():
+		case err := <-ack.Err():
+			return fmt.Errorf("%w: %s", ErrPublishingRejected, err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close drops the connection, the messages not acknowledged yet are
+// published again by the relays from the outbox
+func (p NATSPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
@@ -0,0 +1,148 @@
+package event_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jetStreamServer speaks enough of the nats protocol to take the messages
+// published to jetstream and acknowledge them with ack
+type jetStreamServer struct {
+	listener net.Listener
+	ack      func(subject string, seq int) string
+
+	mu        sync.Mutex
+	published []publishedMessage
+}
+
+type publishedMessage struct {
+	subject string
+	headers string
+	payload string
+}
+
+func newJetStreamServer(t *testing.T, ack func(subject string, seq int) string) *jetStreamServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &jetStreamServer{listener: listener, ack: ack}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *jetStreamServer) url() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+func (s *jetStreamServer) messages() []publishedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]publishedMessage(nil), s.published...)
+}
+
+func (s *jetStreamServer) serve(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprint(conn, `INFO {"server_id":"test","version":"2.9.0","proto":1,"headers":true,"jetstream":true,"max_payload":1048576}`+"\r\n")
+
+	r := bufio.NewReader(conn)
+	sid := ""
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "SUB":
+			sid = fields[len(fields)-1]
+		case "HPUB":
+			// HPUB <subject> <reply> <header size> <total size>
+			headerSize, _ := strconv.Atoi(fields[3])
+			totalSize, _ := strconv.Atoi(fields[4])
+			body := make([]byte, totalSize+2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.published = append(s.published, publishedMessage{
+				subject: fields[1],
+				headers: string(body[:headerSize]),
+				payload: string(body[headerSize:totalSize]),
+			})
+			seq := len(s.published)
+			s.mu.Unlock()
+
+			reply := s.ack(fields[1], seq)
+			fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", fields[2], sid, len(reply), reply)
+		}
+	}
+}
+
+func TestNATSPublisherPublish(t *testing.T) {
+	msgs := []event.Message{
+		{ID: "e1", Type: "organization.created", Key: "org-1", Payload: []byte(`{"type":"organization.created"}`)},
+		{ID: "e2", Type: "organization.updated", Key: "org-1", Payload: []byte(`{"type":"organization.updated"}`)},
+	}
+
+	t.Run("should publish the messages on the subjects of their type", func(t *testing.T) {
+		server := newJetStreamServer(t, func(subject string, seq int) string {
+			return fmt.Sprintf(`{"stream":"shield","seq":%d}`, seq)
+		})
+		publisher, err := event.NewNATSPublisher(event.NATSConfig{URL: server.url(), Subject: "shield.events", Timeout: time.Second})
+		require.NoError(t, err)
+		defer publisher.Close()
+
+		require.NoError(t, publisher.Publish(context.Background(), msgs))
+		published := server.messages()
+		require.Len(t, published, 2)
+		assert.Equal(t, "shield.events.organization.created", published[0].subject)
+		assert.Contains(t, published[0].headers, "Nats-Msg-Id: e1")
+		assert.Contains(t, published[0].headers, "Shield-Event-Key: org-1")
+		assert.Equal(t, `{"type":"organization.updated"}`, published[1].payload)
+	})
+
+	t.Run("should return error if the stream refused a message", func(t *testing.T) {
+		server := newJetStreamServer(t, func(subject string, seq int) string {
+			if seq == 2 {
+				return `{"error":{"code":503,"err_code":10077,"description":"maximum messages exceeded"}}`
+			}
+			return fmt.Sprintf(`{"stream":"shield","seq":%d}`, seq)
+		})
+		publisher, err := event.NewNATSPublisher(event.NATSConfig{URL: server.url(), Subject: "shield.events", Timeout: time.Second})
+		require.NoError(t, err)
+		defer publisher.Close()
+
+		err = publisher.Publish(context.Background(), msgs)
+		assert.ErrorIs(t, err, event.ErrPublishingRejected)
+	})
+
+	t.Run("should refuse a subject with wildcards", func(t *testing.T) {
+		_, err := event.NewNATSPublisher(event.NATSConfig{URL: "nats://localhost:4222", Subject: "shield.>"})
+		assert.ErrorIs(t, err, event.ErrInvalidDetail)
+	})
+}
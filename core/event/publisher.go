@@ -0,0 +1,51 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odpf/salt/log"
+)
+
+// Publisher publishes the messages to a broker, it returns nil only once
+// the broker acknowledged all of them. Messages failing to publish are
+// published again, so are the ones of a batch published in part.
+type Publisher interface {
+	Publish(ctx context.Context, msgs []Message) error
+}
+
+// NewPublisher is the publisher of the config, nil when events are not
+// published
+func NewPublisher(cfg Config, logger log.Logger) (Publisher, error) {
+	switch cfg.Publisher {
+	case "":
+		return nil, nil
+	case PublisherKafka:
+		return NewKafkaPublisher(cfg.Kafka)
+	case PublisherNATS:
+		return NewNATSPublisher(cfg.NATS)
+	case PublisherLog:
+		return NewLogPublisher(logger), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPublisher, cfg.Publisher)
+	}
+}
+
+// LogPublisher logs the messages instead of publishing them, to look at the
+// events without a broker
+type LogPublisher struct {
+	logger log.Logger
+}
+
+func NewLogPublisher(logger log.Logger) *LogPublisher {
+	return &LogPublisher{
+		logger: logger,
+	}
+}
+
+func (p LogPublisher) Publish(ctx context.Context, msgs []Message) error {
+	for _, msg := range msgs {
+		p.logger.Info("event", "id", msg.ID, "type", msg.Type, "key", msg.Key, "payload", string(msg.Payload))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, ev Event) error
+}
+
+// Recorder records the changes made to the resources in the audit logs and
+// notifies them as events, to the webhooks subscribed to them and the
+// outbox of the broker
+type Recorder struct {
+	auditService AuditService
+	notifiers    []Notifier
+}
+
+func NewRecorder(auditService AuditService, notifiers ...Notifier) *Recorder {
+	return &Recorder{
+		auditService: auditService,
+		notifiers:    notifiers,
+	}
+}
+
+func (r Recorder) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	if err := r.auditService.Record(ctx, action, resourceType, resourceID, oldPayload, newPayload); err != nil {
+		return err
+	}
+
+	actor, ok := user.GetEmailFromContext(ctx)
+	if !ok || actor == "" {
+		actor = audit.SystemActor
+	}
+	ev := Event{
+		ID:           uuid.NewString(),
+		Type:         TypeOf(resourceType, action),
+		Actor:        actor,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		OldPayload:   oldPayload,
+		NewPayload:   newPayload,
+		CreatedAt:    time.Now().UTC(),
+	}
+	for _, n := range r.notifiers {
+		if err := n.Notify(ctx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
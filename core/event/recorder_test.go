@@ -0,0 +1,100 @@
+package event_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryAuditService struct {
+	logs []audit.Log
+	err  error
+}
+
+func (s *memoryAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.logs = append(s.logs, audit.Log{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		OldPayload:   oldPayload,
+		NewPayload:   newPayload,
+	})
+	return nil
+}
+
+type memoryNotifier struct {
+	events []event.Event
+}
+
+func (n *memoryNotifier) Notify(ctx context.Context, ev event.Event) error {
+	n.events = append(n.events, ev)
+	return nil
+}
+
+func TestRecorderRecord(t *testing.T) {
+	t.Run("should audit the change and notify it as an event", func(t *testing.T) {
+		auditService := &memoryAuditService{}
+		webhooks, outbox := &memoryNotifier{}, &memoryNotifier{}
+		recorder := event.NewRecorder(auditService, webhooks, outbox)
+		ctx := user.SetContextWithEmail(context.Background(), "john.doe@odpf.io")
+
+		err := recorder.Record(ctx, audit.ActionDelete, "project", "project-1", map[string]any{"name": "old"}, nil)
+		assert.NoError(t, err)
+		assert.Len(t, auditService.logs, 1)
+		require.Len(t, webhooks.events, 1)
+		assert.Equal(t, webhooks.events, outbox.events)
+
+		ev := webhooks.events[0]
+		assert.NotEmpty(t, ev.ID)
+		assert.Equal(t, "project.deleted", ev.Type)
+		assert.Equal(t, "john.doe@odpf.io", ev.Actor)
+		assert.Equal(t, "project-1", ev.ResourceID)
+		assert.Equal(t, map[string]any{"name": "old"}, ev.OldPayload)
+		assert.Nil(t, ev.NewPayload)
+	})
+
+	t.Run("should not notify a change failing to be audited", func(t *testing.T) {
+		notifier := &memoryNotifier{}
+		recorder := event.NewRecorder(&memoryAuditService{err: errors.New("db down")}, notifier)
+
+		err := recorder.Record(context.Background(), audit.ActionCreate, "group", "group-1", nil, nil)
+		assert.Error(t, err)
+		assert.Empty(t, notifier.events)
+	})
+}
+
+func TestServiceNotify(t *testing.T) {
+	repo := &memoryRepository{}
+	ev := event.Event{
+		ID:           "4fd3b1a0-5b0e-4c5a-8a1f-6a0c1e2d9b11",
+		Type:         "policy.created",
+		ResourceType: "policy",
+		ResourceID:   "policy-1",
+	}
+
+	err := event.NewService(repo).Notify(context.Background(), ev)
+	assert.NoError(t, err)
+	require.Len(t, repo.msgs, 1)
+	assert.Equal(t, ev.ID, repo.msgs[0].ID)
+	assert.Equal(t, "policy-1", repo.msgs[0].Key)
+
+	var published event.Event
+	require.NoError(t, json.Unmarshal(repo.msgs[0].Payload, &published))
+	assert.Equal(t, ev, published)
+}
+
+func TestTypeOf(t *testing.T) {
+	assert.Equal(t, "organization.created", event.TypeOf("organization", audit.ActionCreate))
+	assert.Equal(t, "apikey.revoked", event.TypeOf("apikey", audit.ActionRevoke))
+	assert.Equal(t, "relation.custom", event.TypeOf("relation", "custom"))
+}
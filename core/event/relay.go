@@ -0,0 +1,72 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/odpf/salt/log"
+)
+
+// Relay publishes the events of the outbox, an event is removed from the
+// outbox once the broker acknowledged it so every event is published at
+// least once. Events are published in the order they were created unless
+// a batch is retried or several relays run.
+type Relay struct {
+	repository Repository
+	publisher  Publisher
+	config     Config
+}
+
+func NewRelay(repository Repository, publisher Publisher, config Config) *Relay {
+	return &Relay{
+		repository: repository,
+		publisher:  publisher,
+		config:     config,
+	}
+}
+
+// Run publishes the events until the context is done
+func (r Relay) Run(ctx context.Context, logger log.Logger) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		published, err := r.publish(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("failed to publish events", "err", err)
+		}
+		// there may be more in the outbox right away when the batch was full
+		if err == nil && published == r.config.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// publish publishes a batch of the outbox, the events are published again
+// once their lease passed if the broker didn't acknowledge them
+func (r Relay) publish(ctx context.Context) (int, error) {
+	msgs, err := r.repository.Claim(ctx, r.config.BatchSize, r.config.Lease)
+	if err != nil || len(msgs) == 0 {
+		return 0, err
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, r.config.Lease)
+	defer cancel()
+	if err := r.publisher.Publish(publishCtx, msgs); err != nil {
+		return 0, err
+	}
+
+	ids := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	if err := r.repository.Delete(ctx, ids); err != nil {
+		return 0, err
+	}
+	return len(msgs), nil
+}
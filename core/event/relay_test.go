@@ -0,0 +1,116 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepository struct {
+	mu      sync.Mutex
+	msgs    []event.Message
+	claimed map[string]time.Time
+}
+
+func (r *memoryRepository) Create(ctx context.Context, msg event.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, msg)
+	return nil
+}
+
+func (r *memoryRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]event.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.claimed == nil {
+		r.claimed = map[string]time.Time{}
+	}
+	var claimed []event.Message
+	for i, msg := range r.msgs {
+		if len(claimed) == limit {
+			break
+		}
+		if r.claimed[msg.ID].After(time.Now()) {
+			continue
+		}
+		r.claimed[msg.ID] = time.Now().Add(lease)
+		r.msgs[i].Attempts++
+		claimed = append(claimed, r.msgs[i])
+	}
+	return claimed, nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deleted := map[string]bool{}
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	var kept []event.Message
+	for _, msg := range r.msgs {
+		if !deleted[msg.ID] {
+			kept = append(kept, msg)
+		}
+	}
+	r.msgs = kept
+	return nil
+}
+
+func (r *memoryRepository) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.msgs)
+}
+
+// flakyPublisher fails the first batches it is given
+type flakyPublisher struct {
+	mu        sync.Mutex
+	failures  int
+	published []string
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, msgs []event.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+	for _, msg := range msgs {
+		p.published = append(p.published, msg.ID)
+	}
+	return nil
+}
+
+func TestRelayRun(t *testing.T) {
+	cfg := event.Config{
+		BatchSize:    2,
+		PollInterval: time.Millisecond,
+		Lease:        5 * time.Millisecond,
+	}
+
+	repo := &memoryRepository{msgs: []event.Message{{ID: "e1"}, {ID: "e2"}, {ID: "e3"}}}
+	publisher := &flakyPublisher{failures: 2}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		event.NewRelay(repo, publisher, cfg).Run(ctx, log.NewNoop())
+	}()
+
+	assert.Eventually(t, func() bool { return repo.len() == 0 }, 5*time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	// the events of the batches the broker failed are published once it
+	// is back, in order
+	assert.Equal(t, []string{"e1", "e2", "e3"}, publisher.published)
+}
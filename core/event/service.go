@@ -0,0 +1,32 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Service writes the events to the outbox in the store the changes are made
+// in, the relay publishes them to the broker afterwards
+type Service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+func (s Service) Notify(ctx context.Context, ev Event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.Create(ctx, Message{
+		ID:      ev.ID,
+		Type:    ev.Type,
+		Key:     ev.ResourceID,
+		Payload: payload,
+	})
+}
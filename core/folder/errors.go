@@ -0,0 +1,13 @@
+package folder
+
+import "errors"
+
+var (
+	ErrNotExist          = errors.New("folder doesn't exist")
+	ErrInvalidDetail     = errors.New("invalid folder detail")
+	ErrConflict          = errors.New("folder already exist")
+	ErrNotProjectEditor  = errors.New("only editors of the project can manage its folders")
+	ErrNotResourceEditor = errors.New("only editors of the resource can move it")
+	ErrNotFolderEditor   = errors.New("only editors of the folder can move resources into it")
+	ErrOutsideProject    = errors.New("folder doesn't belong to the project of the resource")
+)
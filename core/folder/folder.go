@@ -0,0 +1,27 @@
+package folder
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, f Folder) (Folder, error)
+	Get(ctx context.Context, id string) (Folder, error)
+	List(ctx context.Context, flt Filter) ([]Folder, error)
+}
+
+// Folder groups resources of a project, access granted on the folder is
+// granted on every resource moved into it
+type Folder struct {
+	ID        string
+	Name      string
+	ProjectID string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Filter narrows down the folders listed, empty fields match all
+type Filter struct {
+	ProjectID string
+}
@@ -0,0 +1,206 @@
+package folder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+)
+
+const (
+	auditResourceType         = "folder"
+	auditResourceResourceType = "resource"
+)
+
+type ProjectService interface {
+	Get(ctx context.Context, idOrSlug string) (project.Project, error)
+}
+
+type ResourceService interface {
+	Get(ctx context.Context, id string) (resource.Resource, error)
+	SetFolder(ctx context.Context, id, folderID string) (resource.Resource, error)
+}
+
+type UserService interface {
+	FetchCurrentUser(ctx context.Context) (user.User, error)
+}
+
+type RelationService interface {
+	Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error)
+	DeleteV2(ctx context.Context, rel relation.RelationV2) error
+	CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, action action.Action) (bool, error)
+}
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+type Service struct {
+	repository      Repository
+	projectService  ProjectService
+	resourceService ResourceService
+	userService     UserService
+	relationService RelationService
+	auditService    AuditService
+}
+
+func NewService(repository Repository, projectService ProjectService, resourceService ResourceService, userService UserService, relationService RelationService, auditService AuditService) *Service {
+	return &Service{
+		repository:      repository,
+		projectService:  projectService,
+		resourceService: resourceService,
+		userService:     userService,
+		relationService: relationService,
+		auditService:    auditService,
+	}
+}
+
+func (s Service) Get(ctx context.Context, id string) (Folder, error) {
+	return s.repository.Get(ctx, id)
+}
+
+// Create creates the folder in the project, the current user has to be an
+// editor of the project
+func (s Service) Create(ctx context.Context, f Folder) (Folder, error) {
+	if strings.TrimSpace(f.Name) == "" {
+		return Folder{}, fmt.Errorf("%w: name is empty", ErrInvalidDetail)
+	}
+
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return Folder{}, err
+	}
+	prj, err := s.projectService.Get(ctx, f.ProjectID)
+	if err != nil {
+		return Folder{}, err
+	}
+	if err := s.checkPermission(ctx, currentUser, schema.ProjectNamespace, prj.ID, ErrNotProjectEditor); err != nil {
+		return Folder{}, err
+	}
+
+	created, err := s.repository.Create(ctx, Folder{
+		Name:      strings.TrimSpace(f.Name),
+		ProjectID: prj.ID,
+	})
+	if err != nil {
+		return Folder{}, err
+	}
+
+	if _, err = s.relationService.Create(ctx, relation.RelationV2{
+		Object: relation.Object{
+			ID:          created.ID,
+			NamespaceID: schema.FolderNamespace,
+		},
+		Subject: relation.Subject{
+			ID:        prj.ID,
+			Namespace: schema.ProjectNamespace,
+			RoleID:    schema.ProjectRelationName,
+		},
+	}); err != nil {
+		return Folder{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, created.ID, nil, created); err != nil {
+		return Folder{}, err
+	}
+	return created, nil
+}
+
+func (s Service) List(ctx context.Context, flt Filter) ([]Folder, error) {
+	if flt.ProjectID != "" {
+		prj, err := s.projectService.Get(ctx, flt.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		flt.ProjectID = prj.ID
+	}
+	return s.repository.List(ctx, flt)
+}
+
+// Move moves the resource into the folder, or back to the root of its
+// project when the folder id is empty. The current user has to be an editor
+// of the resource and of the folder it is moved into, the folder has to
+// belong to the project of the resource.
+func (s Service) Move(ctx context.Context, resourceID, folderID string) (resource.Resource, error) {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return resource.Resource{}, err
+	}
+	res, err := s.resourceService.Get(ctx, resourceID)
+	if err != nil {
+		return resource.Resource{}, err
+	}
+	if err := s.checkPermission(ctx, currentUser, res.NamespaceID, res.Idxa, ErrNotResourceEditor); err != nil {
+		return resource.Resource{}, err
+	}
+
+	if folderID != "" {
+		f, err := s.repository.Get(ctx, folderID)
+		if err != nil {
+			return resource.Resource{}, err
+		}
+		if f.ProjectID != res.ProjectID {
+			return resource.Resource{}, ErrOutsideProject
+		}
+		if err := s.checkPermission(ctx, currentUser, schema.FolderNamespace, f.ID, ErrNotFolderEditor); err != nil {
+			return resource.Resource{}, err
+		}
+	}
+	if res.FolderID == folderID {
+		return res, nil
+	}
+
+	if res.FolderID != "" {
+		if err := s.relationService.DeleteV2(ctx, folderRelation(res, res.FolderID)); err != nil {
+			return resource.Resource{}, err
+		}
+	}
+	moved, err := s.resourceService.SetFolder(ctx, res.Idxa, folderID)
+	if err != nil {
+		return resource.Resource{}, err
+	}
+	if folderID != "" {
+		if _, err := s.relationService.Create(ctx, folderRelation(res, folderID)); err != nil {
+			return resource.Resource{}, err
+		}
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionUpdate, auditResourceResourceType, moved.Idxa, res, moved); err != nil {
+		return resource.Resource{}, err
+	}
+	return moved, nil
+}
+
+func (s Service) checkPermission(ctx context.Context, usr user.User, namespaceID, id string, denied error) error {
+	allowed, err := s.relationService.CheckPermission(ctx, usr, namespace.Namespace{ID: namespaceID}, id, action.Action{ID: schema.EditPermission})
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return denied
+	}
+	return nil
+}
+
+// folderRelation is the relation of the resource to the folder it is in
+func folderRelation(res resource.Resource, folderID string) relation.RelationV2 {
+	return relation.RelationV2{
+		Object: relation.Object{
+			ID:          res.Idxa,
+			NamespaceID: res.NamespaceID,
+		},
+		Subject: relation.Subject{
+			ID:        folderID,
+			Namespace: schema.FolderNamespace,
+			RoleID:    schema.FolderRelationName,
+		},
+	}
+}
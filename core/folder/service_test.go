@@ -0,0 +1,199 @@
+package folder_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/folder"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	folders map[string]folder.Folder
+}
+
+func (r *memoryRepository) Create(ctx context.Context, f folder.Folder) (folder.Folder, error) {
+	f.ID = "f" + strconv.Itoa(len(r.folders)+1)
+	r.folders[f.ID] = f
+	return f, nil
+}
+
+func (r *memoryRepository) Get(ctx context.Context, id string) (folder.Folder, error) {
+	f, ok := r.folders[id]
+	if !ok {
+		return folder.Folder{}, folder.ErrNotExist
+	}
+	return f, nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, flt folder.Filter) ([]folder.Folder, error) {
+	var folders []folder.Folder
+	for _, f := range r.folders {
+		if flt.ProjectID == "" || f.ProjectID == flt.ProjectID {
+			folders = append(folders, f)
+		}
+	}
+	return folders, nil
+}
+
+type projectService struct{}
+
+func (projectService) Get(ctx context.Context, idOrSlug string) (project.Project, error) {
+	switch idOrSlug {
+	case "data", "p1":
+		return project.Project{ID: "p1", Slug: "data"}, nil
+	case "infra", "p2":
+		return project.Project{ID: "p2", Slug: "infra"}, nil
+	}
+	return project.Project{}, project.ErrNotExist
+}
+
+type resourceService struct {
+	resources map[string]resource.Resource
+}
+
+func (s *resourceService) Get(ctx context.Context, id string) (resource.Resource, error) {
+	res, ok := s.resources[id]
+	if !ok {
+		return resource.Resource{}, resource.ErrNotExist
+	}
+	return res, nil
+}
+
+func (s *resourceService) SetFolder(ctx context.Context, id, folderID string) (resource.Resource, error) {
+	res := s.resources[id]
+	res.FolderID = folderID
+	s.resources[id] = res
+	return res, nil
+}
+
+type userService struct{}
+
+func (userService) FetchCurrentUser(ctx context.Context) (user.User, error) {
+	email, ok := user.GetEmailFromContext(ctx)
+	if !ok || email == "" {
+		return user.User{}, user.ErrMissingEmail
+	}
+	return user.User{ID: "id-" + email, Email: email}, nil
+}
+
+type relationService struct {
+	editors map[string]bool
+	created []relation.RelationV2
+	deleted []relation.RelationV2
+}
+
+func (s *relationService) Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	s.created = append(s.created, rel)
+	return rel, nil
+}
+
+func (s *relationService) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	s.deleted = append(s.deleted, rel)
+	return nil
+}
+
+func (s *relationService) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, act action.Action) (bool, error) {
+	return s.editors[usr.Email], nil
+}
+
+type noopAuditService struct{}
+
+func (noopAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	return nil
+}
+
+func TestService(t *testing.T) {
+	editor := user.SetContextWithEmail(context.Background(), "admin@odpf.io")
+	viewer := user.SetContextWithEmail(context.Background(), "jane@odpf.io")
+
+	newService := func() (*folder.Service, *relationService) {
+		relations := &relationService{editors: map[string]bool{"admin@odpf.io": true}}
+		resources := &resourceService{resources: map[string]resource.Resource{
+			"r1": {Idxa: "r1", ProjectID: "p1", NamespaceID: "entropy/firehose"},
+			"r2": {Idxa: "r2", ProjectID: "p2", NamespaceID: "entropy/firehose"},
+		}}
+		return folder.NewService(
+			&memoryRepository{folders: map[string]folder.Folder{}},
+			projectService{}, resources, userService{}, relations, noopAuditService{}), relations
+	}
+
+	t.Run("should create the folder under its project", func(t *testing.T) {
+		s, relations := newService()
+
+		got, err := s.Create(editor, folder.Folder{Name: "pipelines", ProjectID: "data"})
+		require.NoError(t, err)
+		assert.Equal(t, "p1", got.ProjectID)
+		assert.Equal(t, []relation.RelationV2{{
+			Object:  relation.Object{ID: got.ID, NamespaceID: schema.FolderNamespace},
+			Subject: relation.Subject{ID: "p1", Namespace: schema.ProjectNamespace, RoleID: schema.ProjectRelationName},
+		}}, relations.created)
+
+		folders, err := s.List(context.Background(), folder.Filter{ProjectID: "data"})
+		require.NoError(t, err)
+		assert.Equal(t, []folder.Folder{got}, folders)
+	})
+
+	t.Run("should return error if the current user can't edit the project", func(t *testing.T) {
+		s, _ := newService()
+
+		_, err := s.Create(viewer, folder.Folder{Name: "pipelines", ProjectID: "data"})
+		assert.ErrorIs(t, err, folder.ErrNotProjectEditor)
+	})
+
+	t.Run("should move the resource from one folder to another and back to the root", func(t *testing.T) {
+		s, relations := newService()
+		first, err := s.Create(editor, folder.Folder{Name: "pipelines", ProjectID: "data"})
+		require.NoError(t, err)
+		second, err := s.Create(editor, folder.Folder{Name: "dashboards", ProjectID: "data"})
+		require.NoError(t, err)
+		relations.created = nil
+
+		moved, err := s.Move(editor, "r1", first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, moved.FolderID)
+
+		moved, err = s.Move(editor, "r1", second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, moved.FolderID)
+
+		moved, err = s.Move(editor, "r1", "")
+		require.NoError(t, err)
+		assert.Equal(t, "", moved.FolderID)
+
+		require.Len(t, relations.created, 2)
+		assert.Equal(t, first.ID, relations.created[0].Subject.ID)
+		assert.Equal(t, second.ID, relations.created[1].Subject.ID)
+		assert.Equal(t, schema.FolderRelationName, relations.created[1].Subject.RoleID)
+		require.Len(t, relations.deleted, 2)
+		assert.Equal(t, first.ID, relations.deleted[0].Subject.ID)
+		assert.Equal(t, second.ID, relations.deleted[1].Subject.ID)
+	})
+
+	t.Run("should return error if the folder is of another project", func(t *testing.T) {
+		s, _ := newService()
+		f, err := s.Create(editor, folder.Folder{Name: "pipelines", ProjectID: "data"})
+		require.NoError(t, err)
+
+		_, err = s.Move(editor, "r2", f.ID)
+		assert.ErrorIs(t, err, folder.ErrOutsideProject)
+	})
+
+	t.Run("should return error if the current user can't edit the resource", func(t *testing.T) {
+		s, _ := newService()
+		f, err := s.Create(editor, folder.Folder{Name: "pipelines", ProjectID: "data"})
+		require.NoError(t, err)
+
+		_, err = s.Move(viewer, "r1", f.ID)
+		assert.ErrorIs(t, err, folder.ErrNotResourceEditor)
+	})
+}
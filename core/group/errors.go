@@ -11,4 +11,8 @@ var (
 	ErrListingGroupRelations = errors.New("error while listing relations")
 	ErrFetchingUsers         = errors.New("error while fetching users")
 	ErrFetchingGroups        = errors.New("error while fetching groups")
+	ErrInUse                 = errors.New("group is still referenced by other resources")
+	ErrVersionMismatch       = errors.New("group was updated since the version")
+	ErrCyclicMembership      = errors.New("group would be a member of itself")
+	ErrOtherOrganization     = errors.New("group belongs to another organization")
 )
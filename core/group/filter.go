@@ -1,5 +1,12 @@
 package group
 
+// Filter narrows down the groups listed, Name, Slug and the
+// Metadata values are globs where * matches any run of characters
 type Filter struct {
 	OrganizationID string
+	Name           string
+	Slug           string
+	Metadata       map[string]string
+	Limit          int32
+	Page           int32
 }
@@ -21,6 +21,7 @@ type Repository interface {
 	ListUsersByGroupID(ctx context.Context, groupId string, roleId string) ([]user.User, error)
 	ListUsersByGroupSlug(ctx context.Context, groupSlug string, roleId string) ([]user.User, error)
 	ListGroupRelations(ctx context.Context, objectId, subjectType, role string) ([]relation.RelationV2, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type Group struct {
@@ -29,6 +30,9 @@ type Group struct {
 	Slug           string
 	OrganizationID string `json:"orgId"`
 	Metadata       metadata.Metadata
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	// Version is incremented on every update, an update made with a
+	// version is refused once the group was updated since
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
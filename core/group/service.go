@@ -6,11 +6,12 @@ import (
 	"strings"
 
 	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/audit"
 	"github.com/odpf/shield/core/namespace"
 	"github.com/odpf/shield/core/relation"
-	"github.com/odpf/shield/core/role"
 	"github.com/odpf/shield/core/user"
 	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/pkg/errors"
 	"github.com/odpf/shield/pkg/str"
 	"github.com/odpf/shield/pkg/uuid"
 )
@@ -18,26 +19,37 @@ import (
 type RelationService interface {
 	Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error)
 	Delete(ctx context.Context, rel relation.Relation) error
+	DeleteV2(ctx context.Context, rel relation.RelationV2) error
+	DeleteSubjectRelations(ctx context.Context, resourceType, optionalResourceID string) error
 	CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, action action.Action) (bool, error)
 }
 
 type UserService interface {
 	FetchCurrentUser(ctx context.Context) (user.User, error)
-	GetByID(ctx context.Context, id string) (user.User, error)
+	Get(ctx context.Context, idOrEmail string) (user.User, error)
 	GetByIDs(ctx context.Context, userIDs []string) ([]user.User, error)
+	GetByIDsOrEmails(ctx context.Context, idsOrEmails []string) ([]user.User, error)
+}
+
+const auditResourceType = "group"
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
 }
 
 type Service struct {
 	repository      Repository
 	relationService RelationService
 	userService     UserService
+	auditService    AuditService
 }
 
-func NewService(repository Repository, relationService RelationService, userService UserService) *Service {
+func NewService(repository Repository, relationService RelationService, userService UserService, auditService AuditService) *Service {
 	return &Service{
 		repository:      repository,
 		relationService: relationService,
 		userService:     userService,
+		auditService:    auditService,
 	}
 }
 
@@ -51,6 +63,10 @@ func (s Service) Create(ctx context.Context, grp Group) (Group, error) {
 		return Group{}, err
 	}
 
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, newGroup.ID, nil, newGroup); err != nil {
+		return Group{}, err
+	}
+
 	return newGroup, nil
 }
 
@@ -70,48 +86,386 @@ func (s Service) List(ctx context.Context, flt Filter) ([]Group, error) {
 }
 
 func (s Service) Update(ctx context.Context, grp Group) (Group, error) {
+	var oldGroup, updatedGroup Group
+	var err error
 	if strings.TrimSpace(grp.ID) != "" {
-		return s.repository.UpdateByID(ctx, grp)
+		if oldGroup, err = s.repository.GetByID(ctx, grp.ID); err != nil {
+			return Group{}, err
+		}
+		updatedGroup, err = s.repository.UpdateByID(ctx, grp)
+	} else {
+		if oldGroup, err = s.repository.GetBySlug(ctx, grp.Slug); err != nil {
+			return Group{}, err
+		}
+		updatedGroup, err = s.repository.UpdateBySlug(ctx, grp)
+	}
+	if err != nil {
+		return Group{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionUpdate, auditResourceType, updatedGroup.ID, oldGroup, updatedGroup); err != nil {
+		return Group{}, err
 	}
-	return s.repository.UpdateBySlug(ctx, grp)
+
+	return updatedGroup, nil
 }
 
-func (s Service) ListUserGroups(ctx context.Context, userId string, roleId string) ([]Group, error) {
-	return s.repository.ListUserGroups(ctx, userId, roleId)
+// ListUserGroups returns the groups the user, an id or an email, has a role
+// on, only the ones of the role when it is set. A user with several roles on
+// a group gets the group once.
+func (s Service) ListUserGroups(ctx context.Context, userIdOrEmail string, roleId string) ([]Group, error) {
+	usr, err := s.userService.Get(ctx, userIdOrEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.repository.ListUserGroups(ctx, usr.ID, roleId)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	userGroups := []Group{}
+	for _, g := range groups {
+		if seen[g.ID] {
+			continue
+		}
+		seen[g.ID] = true
+		userGroups = append(userGroups, g)
+	}
+	return userGroups, nil
 }
 
 func (s Service) ListUsers(ctx context.Context, idOrSlug string) ([]user.User, error) {
+	memberRoleID := schema.GetRoleID(schema.GroupNamespace, schema.MemberRole)
 	if uuid.IsValid(idOrSlug) {
-		return s.repository.ListUsersByGroupID(ctx, idOrSlug, role.DefinitionTeamMember.ID)
+		return s.repository.ListUsersByGroupID(ctx, idOrSlug, memberRoleID)
 	}
-	return s.repository.ListUsersByGroupSlug(ctx, idOrSlug, role.DefinitionTeamMember.ID)
+	return s.repository.ListUsersByGroupSlug(ctx, idOrSlug, memberRoleID)
 }
 
+// ListAdmins returns the managers of the group
 func (s Service) ListAdmins(ctx context.Context, idOrSlug string) ([]user.User, error) {
+	return s.listUsersOfRole(ctx, idOrSlug, schema.ManagerRole)
+}
+
+// ListOwners returns the owners of the group
+func (s Service) ListOwners(ctx context.Context, idOrSlug string) ([]user.User, error) {
+	return s.listUsersOfRole(ctx, idOrSlug, schema.OwnerRole)
+}
+
+func (s Service) listUsersOfRole(ctx context.Context, idOrSlug, roleName string) ([]user.User, error) {
+	roleID := schema.GetRoleID(schema.GroupNamespace, roleName)
 	if uuid.IsValid(idOrSlug) {
-		return s.repository.ListUsersByGroupID(ctx, idOrSlug, role.DefinitionTeamAdmin.ID)
+		return s.repository.ListUsersByGroupID(ctx, idOrSlug, roleID)
 	}
-	return s.repository.ListUsersByGroupSlug(ctx, idOrSlug, role.DefinitionTeamAdmin.ID)
+	return s.repository.ListUsersByGroupSlug(ctx, idOrSlug, roleID)
 }
 
+// AddUsers makes the users members of the group, the current user has to be
+// able to edit the group. Either all the users are added or none of them.
+// It returns the members of the group.
 func (s Service) AddUsers(ctx context.Context, groupIdOrSlug string, userIds []string) ([]user.User, error) {
-	// TODO(discussion): To be replaced with create relation
-	return []user.User{}, nil
+	grp, err := s.editableGroup(ctx, groupIdOrSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userService.GetByIDsOrEmails(ctx, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.ListUsers(ctx, grp.ID)
+	if err != nil {
+		return nil, err
+	}
+	isMember := map[string]bool{}
+	for _, m := range members {
+		isMember[m.ID] = true
+	}
+
+	var added []user.User
+	for _, usr := range users {
+		if isMember[usr.ID] {
+			continue
+		}
+		if _, err := s.relationService.Create(ctx, memberRelation(grp, usr.Email)); err != nil {
+			// undo the members added so far
+			for _, a := range added {
+				if delErr := s.relationService.DeleteV2(ctx, memberRelation(grp, a.ID)); delErr != nil {
+					return nil, fmt.Errorf("%w: rollback: %s", err, delErr.Error())
+				}
+			}
+			return nil, err
+		}
+		added = append(added, usr)
+	}
+
+	return s.ListUsers(ctx, grp.ID)
 }
 
+// RemoveUser removes the user from the members of the group, the current
+// user has to be able to edit the group. It returns the members of the group.
 func (s Service) RemoveUser(ctx context.Context, groupIdOrSlug string, userId string) ([]user.User, error) {
-	// TODO(discussion): To be replaced with remove relation
-	return []user.User{}, nil
+	grp, err := s.editableGroup(ctx, groupIdOrSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	usr, err := s.userService.Get(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.relationService.DeleteV2(ctx, memberRelation(grp, usr.ID)); err != nil {
+		if errors.Is(err, relation.ErrNotExist) {
+			return nil, user.ErrNotExist
+		}
+		return nil, err
+	}
+
+	return s.ListUsers(ctx, grp.ID)
+}
+
+// ListSubgroups returns the groups that are members of the group, the
+// groups nested in them are not listed
+func (s Service) ListSubgroups(ctx context.Context, idOrSlug string) ([]Group, error) {
+	grp, err := s.Get(ctx, idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.subgroupIDs(ctx, grp.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []Group{}, nil
+	}
+	return s.repository.GetByIDs(ctx, ids)
 }
 
+// AddSubgroups makes the groups members of the group, their members are
+// members of the group too. The current user has to be able to edit the
+// group, the groups have to belong to its organization and can't have the
+// group nested in them. Either all the groups are added or none of them.
+// It returns the groups that are members of the group.
+func (s Service) AddSubgroups(ctx context.Context, groupIdOrSlug string, subgroupIdsOrSlugs []string) ([]Group, error) {
+	grp, err := s.editableGroup(ctx, groupIdOrSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	var subgroups []Group
+	for _, ref := range subgroupIdsOrSlugs {
+		subgroup, err := s.Get(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		if subgroup.OrganizationID != grp.OrganizationID {
+			return nil, fmt.Errorf("%w: %s", ErrOtherOrganization, subgroup.Slug)
+		}
+		if err := s.checkNesting(ctx, grp, subgroup); err != nil {
+			return nil, err
+		}
+		subgroups = append(subgroups, subgroup)
+	}
+
+	existing, err := s.subgroupIDs(ctx, grp.ID)
+	if err != nil {
+		return nil, err
+	}
+	isMember := map[string]bool{}
+	for _, id := range existing {
+		isMember[id] = true
+	}
+
+	var added []Group
+	for _, subgroup := range subgroups {
+		if isMember[subgroup.ID] {
+			continue
+		}
+		if _, err := s.relationService.Create(ctx, subgroupRelation(grp, subgroup.ID)); err != nil {
+			// undo the groups added so far
+			for _, a := range added {
+				if delErr := s.relationService.DeleteV2(ctx, subgroupRelation(grp, a.ID)); delErr != nil {
+					return nil, fmt.Errorf("%w: rollback: %s", err, delErr.Error())
+				}
+			}
+			return nil, err
+		}
+		isMember[subgroup.ID] = true
+		added = append(added, subgroup)
+	}
+
+	return s.ListSubgroups(ctx, grp.ID)
+}
+
+// RemoveSubgroup removes the group from the members of the group, the
+// current user has to be able to edit the group. It returns the groups that
+// are members of the group.
+func (s Service) RemoveSubgroup(ctx context.Context, groupIdOrSlug string, subgroupIdOrSlug string) ([]Group, error) {
+	grp, err := s.editableGroup(ctx, groupIdOrSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	subgroup, err := s.Get(ctx, subgroupIdOrSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.relationService.DeleteV2(ctx, subgroupRelation(grp, subgroup.ID)); err != nil {
+		if errors.Is(err, relation.ErrNotExist) {
+			return nil, ErrNotExist
+		}
+		return nil, err
+	}
+
+	return s.ListSubgroups(ctx, grp.ID)
+}
+
+// checkNesting refuses to make the subgroup a member of the group when the
+// group is the subgroup or is nested in it, the membership would be a
+// cycle the authz engine can't resolve
+func (s Service) checkNesting(ctx context.Context, grp Group, subgroup Group) error {
+	visited := map[string]bool{}
+	next := []string{subgroup.ID}
+	for len(next) > 0 {
+		id := next[0]
+		next = next[1:]
+		if id == grp.ID {
+			return fmt.Errorf("%w: %s is nested in %s", ErrCyclicMembership, grp.Slug, subgroup.Slug)
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		ids, err := s.subgroupIDs(ctx, id)
+		if err != nil {
+			return err
+		}
+		next = append(next, ids...)
+	}
+	return nil
+}
+
+// subgroupIDs returns the ids of the groups that are members of the group
+func (s Service) subgroupIDs(ctx context.Context, id string) ([]string, error) {
+	relations, err := s.repository.ListGroupRelations(ctx, id, "group", schema.MemberRole)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrListingGroupRelations, err.Error())
+	}
+	var ids []string
+	for _, rel := range relations {
+		ids = append(ids, rel.Subject.ID)
+	}
+	return ids, nil
+}
+
+// AddMember makes the user of the email a member of the group without
+// checking the current user, for the syncs made by the system like the
+// directory sync
+func (s Service) AddMember(ctx context.Context, grp Group, email string) error {
+	_, err := s.relationService.Create(ctx, memberRelation(grp, email))
+	return err
+}
+
+// RemoveMember removes the user from the members of the group without
+// checking the current user, for the syncs made by the system
+func (s Service) RemoveMember(ctx context.Context, grp Group, userID string) error {
+	return s.relationService.DeleteV2(ctx, memberRelation(grp, userID))
+}
+
+// AddAdmins makes the users managers of the group, the current user has to
+// be able to delete the group. Either all the users are added or none of
+// them. It returns the managers of the group.
 func (s Service) AddAdmins(ctx context.Context, groupIdOrSlug string, userIds []string) ([]user.User, error) {
-	// TODO(discussion): To be replaced with create relation
-	return []user.User{}, nil
+	return s.addUsersWithRole(ctx, groupIdOrSlug, userIds, schema.ManagerRole)
 }
 
+// RemoveAdmin removes the user from the managers of the group, the current
+// user has to be able to delete the group. It returns the managers of the
+// group.
 func (s Service) RemoveAdmin(ctx context.Context, groupIdOrSlug string, userId string) ([]user.User, error) {
-	// TODO(discussion): Can be removed for remove relation
-	return []user.User{}, nil
+	return s.removeUserWithRole(ctx, groupIdOrSlug, userId, schema.ManagerRole)
+}
+
+// AddOwners makes the users owners of the group, they manage its members
+// without a role on its organization. The current user has to be able to
+// delete the group. Either all the users are added or none of them. It
+// returns the owners of the group.
+func (s Service) AddOwners(ctx context.Context, groupIdOrSlug string, userIds []string) ([]user.User, error) {
+	return s.addUsersWithRole(ctx, groupIdOrSlug, userIds, schema.OwnerRole)
+}
+
+// RemoveOwner removes the user from the owners of the group, the current
+// user has to be able to delete the group. It returns the owners of the
+// group.
+func (s Service) RemoveOwner(ctx context.Context, groupIdOrSlug string, userId string) ([]user.User, error) {
+	return s.removeUserWithRole(ctx, groupIdOrSlug, userId, schema.OwnerRole)
+}
+
+func (s Service) addUsersWithRole(ctx context.Context, groupIdOrSlug string, userIds []string, roleName string) ([]user.User, error) {
+	grp, err := s.permittedGroup(ctx, groupIdOrSlug, schema.DeletePermission)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userService.GetByIDsOrEmails(ctx, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.listUsersOfRole(ctx, grp.ID, roleName)
+	if err != nil {
+		return nil, err
+	}
+	hasRole := map[string]bool{}
+	for _, u := range current {
+		hasRole[u.ID] = true
+	}
+
+	var added []user.User
+	for _, usr := range users {
+		if hasRole[usr.ID] {
+			continue
+		}
+		if _, err := s.relationService.Create(ctx, userRoleRelation(grp, usr.Email, roleName)); err != nil {
+			// undo the users added so far
+			for _, a := range added {
+				if delErr := s.relationService.DeleteV2(ctx, userRoleRelation(grp, a.ID, roleName)); delErr != nil {
+					return nil, fmt.Errorf("%w: rollback: %s", err, delErr.Error())
+				}
+			}
+			return nil, err
+		}
+		hasRole[usr.ID] = true
+		added = append(added, usr)
+	}
+
+	return s.listUsersOfRole(ctx, grp.ID, roleName)
+}
+
+func (s Service) removeUserWithRole(ctx context.Context, groupIdOrSlug string, userId string, roleName string) ([]user.User, error) {
+	grp, err := s.permittedGroup(ctx, groupIdOrSlug, schema.DeletePermission)
+	if err != nil {
+		return nil, err
+	}
+
+	usr, err := s.userService.Get(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.relationService.DeleteV2(ctx, userRoleRelation(grp, usr.ID, roleName)); err != nil {
+		if errors.Is(err, relation.ErrNotExist) {
+			return nil, user.ErrNotExist
+		}
+		return nil, err
+	}
+
+	return s.listUsersOfRole(ctx, grp.ID, roleName)
 }
 
 func (s Service) ListGroupRelations(ctx context.Context, objectId, subjectType, role string) ([]user.User, []Group, map[string][]string, map[string][]string, error) {
@@ -179,3 +533,99 @@ func (s Service) addTeamToOrg(ctx context.Context, team Group) error {
 
 	return nil
 }
+
+// editableGroup returns the group if the current user can edit it
+func (s Service) editableGroup(ctx context.Context, idOrSlug string) (Group, error) {
+	return s.permittedGroup(ctx, idOrSlug, schema.EditPermission)
+}
+
+// permittedGroup returns the group when the current user has the permission
+// on it
+func (s Service) permittedGroup(ctx context.Context, idOrSlug string, permission string) (Group, error) {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return Group{}, err
+	}
+
+	grp, err := s.Get(ctx, idOrSlug)
+	if err != nil {
+		return Group{}, err
+	}
+
+	allowed, err := s.relationService.CheckPermission(ctx, currentUser, namespace.Namespace{ID: schema.GroupNamespace}, grp.ID, action.Action{ID: permission})
+	if err != nil {
+		return Group{}, err
+	}
+	if !allowed {
+		return Group{}, errors.ErrForbidden
+	}
+	return grp, nil
+}
+
+// memberRelation is the member relation of the subject to the group, the
+// subject is the email of the user when creating the relation and the id
+// of the user when deleting it
+func memberRelation(grp Group, subjectID string) relation.RelationV2 {
+	return relation.RelationV2{
+		Object: relation.Object{
+			ID:          grp.ID,
+			NamespaceID: schema.GroupNamespace,
+		},
+		Subject: relation.Subject{
+			ID:        subjectID,
+			Namespace: schema.UserPrincipal,
+			RoleID:    schema.MemberRole,
+		},
+	}
+}
+
+// userRoleRelation is the relation of the role of the user to the group,
+// the subject is the email of the user when creating the relation and the
+// id of the user when deleting it
+func userRoleRelation(grp Group, subjectID, roleName string) relation.RelationV2 {
+	return relation.RelationV2{
+		Object: relation.Object{
+			ID:          grp.ID,
+			NamespaceID: schema.GroupNamespace,
+		},
+		Subject: relation.Subject{
+			ID:        subjectID,
+			Namespace: schema.UserPrincipal,
+			RoleID:    roleName,
+		},
+	}
+}
+
+// subgroupRelation is the member relation of the subgroup to the group
+func subgroupRelation(grp Group, subgroupID string) relation.RelationV2 {
+	return relation.RelationV2{
+		Object: relation.Object{
+			ID:          grp.ID,
+			NamespaceID: schema.GroupNamespace,
+		},
+		Subject: relation.Subject{
+			ID:        subgroupID,
+			Namespace: schema.GroupPrincipal,
+			RoleID:    schema.MemberRole,
+		},
+	}
+}
+
+// Delete removes the group and the relations of the group
+// from the authz engine
+func (s Service) Delete(ctx context.Context, idOrSlug string) error {
+	grp, err := s.Get(ctx, idOrSlug)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.Delete(ctx, grp.ID); err != nil {
+		return err
+	}
+
+	if err := s.relationService.DeleteSubjectRelations(ctx, schema.GroupNamespace, grp.ID); err != nil {
+		return err
+	}
+
+	return s.auditService.Record(ctx, audit.ActionDelete, auditResourceType, grp.ID, grp, nil)
+}
@@ -0,0 +1,444 @@
+package group_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const groupID = "8e73f4c9-3c39-4a73-a06c-b1b2e5e2dc23"
+
+type memoryRepository struct {
+	group.Repository
+	relations *memoryRelationService
+}
+
+func (r memoryRepository) GetByID(ctx context.Context, id string) (group.Group, error) {
+	if id != groupID {
+		return group.Group{}, group.ErrNotExist
+	}
+	return group.Group{ID: groupID, Slug: "data"}, nil
+}
+
+func (r memoryRepository) ListUsersByGroupID(ctx context.Context, id string, roleID string) ([]user.User, error) {
+	var users []user.User
+	for _, u := range r.relations.withRole(schema.GetRoleName(roleID)) {
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+type memoryRelationService struct {
+	group.RelationService
+	users   map[string]user.User
+	members map[string]user.User
+	// owners are kept apart from the users of the other roles
+	owners map[string]user.User
+	// failOn fails creating the relation of the email
+	failOn string
+	editor string
+	// owner can delete the group besides editing it
+	owner string
+}
+
+func (s *memoryRelationService) Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	if rel.Subject.ID == s.failOn {
+		return relation.RelationV2{}, relation.ErrCreatingRelationInAuthzEngine
+	}
+	for _, u := range s.users {
+		if u.Email == rel.Subject.ID {
+			s.withRole(rel.Subject.RoleID)[u.ID] = u
+		}
+	}
+	return rel, nil
+}
+
+func (s *memoryRelationService) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	users := s.withRole(rel.Subject.RoleID)
+	if _, ok := users[rel.Subject.ID]; !ok {
+		return relation.ErrNotExist
+	}
+	delete(users, rel.Subject.ID)
+	return nil
+}
+
+func (s *memoryRelationService) withRole(roleName string) map[string]user.User {
+	if roleName == schema.OwnerRole {
+		return s.owners
+	}
+	return s.members
+}
+
+func (s *memoryRelationService) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, act action.Action) (bool, error) {
+	if act.ID == schema.DeletePermission {
+		return usr.ID == s.owner, nil
+	}
+	return usr.ID == s.editor || usr.ID == s.owner, nil
+}
+
+type memoryUserService struct {
+	group.UserService
+	current user.User
+	users   map[string]user.User
+}
+
+func (s memoryUserService) FetchCurrentUser(ctx context.Context) (user.User, error) {
+	return s.current, nil
+}
+
+func (s memoryUserService) Get(ctx context.Context, idOrEmail string) (user.User, error) {
+	for _, u := range s.users {
+		if u.ID == idOrEmail || u.Email == idOrEmail {
+			return u, nil
+		}
+	}
+	return user.User{}, user.ErrNotExist
+}
+
+func (s memoryUserService) GetByIDs(ctx context.Context, ids []string) ([]user.User, error) {
+	var users []user.User
+	for _, id := range ids {
+		if u, ok := s.users[id]; ok {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (s memoryUserService) GetByIDsOrEmails(ctx context.Context, idsOrEmails []string) ([]user.User, error) {
+	var users []user.User
+	for _, ref := range idsOrEmails {
+		u, err := s.Get(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func TestServiceMembers(t *testing.T) {
+	users := map[string]user.User{
+		"u1": {ID: "u1", Email: "u1@example.com"},
+		"u2": {ID: "u2", Email: "u2@example.com"},
+		"u3": {ID: "u3", Email: "u3@example.com"},
+	}
+	setup := func(current string) (*group.Service, *memoryRelationService) {
+		relations := &memoryRelationService{users: users, members: map[string]user.User{}, editor: "u1"}
+		userService := memoryUserService{current: users[current], users: users}
+		return group.NewService(memoryRepository{relations: relations}, relations, userService, nil), relations
+	}
+
+	t.Run("should add the users as members of the group", func(t *testing.T) {
+		s, _ := setup("u1")
+
+		got, err := s.AddUsers(context.Background(), groupID, []string{"u2", "u3"})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []user.User{users["u2"], users["u3"]}, got)
+	})
+
+	t.Run("should add the users referred to by email", func(t *testing.T) {
+		s, _ := setup("u1")
+
+		got, err := s.AddUsers(context.Background(), groupID, []string{"u2@example.com", "u3"})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []user.User{users["u2"], users["u3"]}, got)
+
+		got, err = s.RemoveUser(context.Background(), groupID, "u3@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, []user.User{users["u2"]}, got)
+	})
+
+	t.Run("should not add any user if adding one of them fails", func(t *testing.T) {
+		s, relations := setup("u1")
+		relations.failOn = "u3@example.com"
+
+		_, err := s.AddUsers(context.Background(), groupID, []string{"u2", "u3"})
+		assert.ErrorIs(t, err, relation.ErrCreatingRelationInAuthzEngine)
+		assert.Empty(t, relations.members)
+	})
+
+	t.Run("should return error if a user doesn't exist", func(t *testing.T) {
+		s, relations := setup("u1")
+
+		_, err := s.AddUsers(context.Background(), groupID, []string{"u2", "u4"})
+		assert.ErrorIs(t, err, user.ErrNotExist)
+		assert.Empty(t, relations.members)
+	})
+
+	t.Run("should return error if the current user can't edit the group", func(t *testing.T) {
+		s, _ := setup("u2")
+
+		_, err := s.AddUsers(context.Background(), groupID, []string{"u3"})
+		assert.ErrorIs(t, err, shielderrors.ErrForbidden)
+	})
+
+	t.Run("should remove the member of the group", func(t *testing.T) {
+		s, _ := setup("u1")
+		_, err := s.AddUsers(context.Background(), groupID, []string{"u2", "u3"})
+		assert.NoError(t, err)
+
+		got, err := s.RemoveUser(context.Background(), groupID, "u2")
+		assert.NoError(t, err)
+		assert.Equal(t, []user.User{users["u3"]}, got)
+	})
+
+	t.Run("should return error if the user isn't a member", func(t *testing.T) {
+		s, _ := setup("u1")
+
+		_, err := s.RemoveUser(context.Background(), groupID, "u2")
+		assert.ErrorIs(t, err, user.ErrNotExist)
+	})
+}
+
+func TestServiceAdmins(t *testing.T) {
+	users := map[string]user.User{
+		"u1": {ID: "u1", Email: "u1@example.com"},
+		"u2": {ID: "u2", Email: "u2@example.com"},
+		"u3": {ID: "u3", Email: "u3@example.com"},
+	}
+	setup := func(current string) (*group.Service, *memoryRelationService) {
+		relations := &memoryRelationService{users: users, members: map[string]user.User{}, owners: map[string]user.User{}, editor: "u2", owner: "u1"}
+		userService := memoryUserService{current: users[current], users: users}
+		return group.NewService(memoryRepository{relations: relations}, relations, userService, nil), relations
+	}
+
+	t.Run("should add and remove the managers of the group", func(t *testing.T) {
+		s, relations := setup("u1")
+
+		got, err := s.AddAdmins(context.Background(), groupID, []string{"u2@example.com", "u3"})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []user.User{users["u2"], users["u3"]}, got)
+		assert.Empty(t, relations.owners)
+
+		got, err = s.RemoveAdmin(context.Background(), groupID, "u3")
+		assert.NoError(t, err)
+		assert.Equal(t, []user.User{users["u2"]}, got)
+	})
+
+	t.Run("should add and remove the owners of the group apart from its managers", func(t *testing.T) {
+		s, relations := setup("u1")
+
+		got, err := s.AddOwners(context.Background(), groupID, []string{"u2@example.com", "u3"})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []user.User{users["u2"], users["u3"]}, got)
+		assert.Empty(t, relations.members)
+
+		admins, err := s.ListAdmins(context.Background(), groupID)
+		assert.NoError(t, err)
+		assert.Empty(t, admins)
+
+		got, err = s.RemoveOwner(context.Background(), groupID, "u3")
+		assert.NoError(t, err)
+		assert.Equal(t, []user.User{users["u2"]}, got)
+	})
+
+	t.Run("should return error if the current user can only edit the group", func(t *testing.T) {
+		s, relations := setup("u2")
+
+		_, err := s.AddOwners(context.Background(), groupID, []string{"u3"})
+		assert.ErrorIs(t, err, shielderrors.ErrForbidden)
+		_, err = s.RemoveOwner(context.Background(), groupID, "u1")
+		assert.ErrorIs(t, err, shielderrors.ErrForbidden)
+		_, err = s.AddAdmins(context.Background(), groupID, []string{"u3"})
+		assert.ErrorIs(t, err, shielderrors.ErrForbidden)
+		assert.Empty(t, relations.owners)
+		assert.Empty(t, relations.members)
+
+		// managing the members only needs the edit permission
+		_, err = s.AddUsers(context.Background(), groupID, []string{"u3"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error if the user isn't an owner", func(t *testing.T) {
+		s, _ := setup("u1")
+
+		_, err := s.RemoveOwner(context.Background(), groupID, "u2")
+		assert.ErrorIs(t, err, user.ErrNotExist)
+	})
+}
+
+type userGroupsRepository struct {
+	group.Repository
+	groups map[string][]group.Group
+}
+
+func (r userGroupsRepository) ListUserGroups(ctx context.Context, userID string, roleID string) ([]group.Group, error) {
+	return r.groups[userID], nil
+}
+
+func TestServiceListUserGroups(t *testing.T) {
+	users := map[string]user.User{
+		"u1": {ID: "u1", Email: "u1@example.com"},
+	}
+	data, infra := group.Group{ID: "g1", Slug: "data"}, group.Group{ID: "g2", Slug: "infra"}
+	repository := userGroupsRepository{groups: map[string][]group.Group{
+		"u1": {data, infra, data},
+	}}
+	s := group.NewService(repository, nil, memoryUserService{users: users}, nil)
+
+	t.Run("should list every group of the user once", func(t *testing.T) {
+		got, err := s.ListUserGroups(context.Background(), "u1", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []group.Group{data, infra}, got)
+	})
+
+	t.Run("should list the groups of the user referred to by email", func(t *testing.T) {
+		got, err := s.ListUserGroups(context.Background(), "u1@example.com", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []group.Group{data, infra}, got)
+	})
+
+	t.Run("should return error if the user doesn't exist", func(t *testing.T) {
+		_, err := s.ListUserGroups(context.Background(), "u2", "")
+		assert.ErrorIs(t, err, user.ErrNotExist)
+	})
+}
+
+// nestedGroups keeps the groups and the groups that are members of them
+type nestedGroups struct {
+	group.Repository
+	groups    []group.Group
+	subgroups map[string][]string
+}
+
+func (n *nestedGroups) GetBySlug(ctx context.Context, slug string) (group.Group, error) {
+	for _, g := range n.groups {
+		if g.Slug == slug {
+			return g, nil
+		}
+	}
+	return group.Group{}, group.ErrNotExist
+}
+
+func (n *nestedGroups) GetByID(ctx context.Context, id string) (group.Group, error) {
+	for _, g := range n.groups {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+	return group.Group{}, group.ErrNotExist
+}
+
+func (n *nestedGroups) GetByIDs(ctx context.Context, ids []string) ([]group.Group, error) {
+	var groups []group.Group
+	for _, id := range ids {
+		g, err := n.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+func (n *nestedGroups) ListGroupRelations(ctx context.Context, objectID, subjectType, role string) ([]relation.RelationV2, error) {
+	var relations []relation.RelationV2
+	for _, id := range n.subgroups[objectID] {
+		relations = append(relations, relation.RelationV2{
+			Object:  relation.Object{ID: objectID, NamespaceID: "shield/group"},
+			Subject: relation.Subject{ID: id, Namespace: "shield/group", RoleID: "shield/group:member"},
+		})
+	}
+	return relations, nil
+}
+
+// nestedRelations writes the member relations of the groups to nestedGroups
+type nestedRelations struct {
+	group.RelationService
+	*nestedGroups
+}
+
+func (n nestedRelations) Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	n.subgroups[rel.Object.ID] = append(n.subgroups[rel.Object.ID], rel.Subject.ID)
+	return rel, nil
+}
+
+func (n nestedRelations) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	ids := n.subgroups[rel.Object.ID]
+	for i, id := range ids {
+		if id == rel.Subject.ID {
+			n.subgroups[rel.Object.ID] = append(ids[:i:i], ids[i+1:]...)
+			return nil
+		}
+	}
+	return relation.ErrNotExist
+}
+
+func (n nestedRelations) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, act action.Action) (bool, error) {
+	return true, nil
+}
+
+func TestServiceSubgroups(t *testing.T) {
+	engineering := group.Group{ID: "0f8f3a4e-6c1b-4c55-9d5e-3f1b6a0f4b01", Slug: "engineering", OrganizationID: "org-1"}
+	platform := group.Group{ID: "0f8f3a4e-6c1b-4c55-9d5e-3f1b6a0f4b02", Slug: "platform", OrganizationID: "org-1"}
+	infra := group.Group{ID: "0f8f3a4e-6c1b-4c55-9d5e-3f1b6a0f4b03", Slug: "infra", OrganizationID: "org-1"}
+	sales := group.Group{ID: "0f8f3a4e-6c1b-4c55-9d5e-3f1b6a0f4b04", Slug: "sales", OrganizationID: "org-2"}
+	setup := func() (*group.Service, *nestedGroups) {
+		n := &nestedGroups{
+			groups:    []group.Group{engineering, platform, infra, sales},
+			subgroups: map[string][]string{},
+		}
+		userService := memoryUserService{current: user.User{ID: "u1"}}
+		return group.NewService(n, nestedRelations{nestedGroups: n}, userService, nil), n
+	}
+
+	t.Run("should add the groups as members of the group", func(t *testing.T) {
+		s, _ := setup()
+
+		got, err := s.AddSubgroups(context.Background(), "engineering", []string{"platform", infra.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, []group.Group{platform, infra}, got)
+
+		got, err = s.AddSubgroups(context.Background(), "engineering", []string{"platform"})
+		assert.NoError(t, err)
+		assert.Equal(t, []group.Group{platform, infra}, got)
+	})
+
+	t.Run("should not make a group a member of itself", func(t *testing.T) {
+		s, n := setup()
+
+		_, err := s.AddSubgroups(context.Background(), "engineering", []string{"engineering"})
+		assert.ErrorIs(t, err, group.ErrCyclicMembership)
+		assert.Empty(t, n.subgroups)
+	})
+
+	t.Run("should not make a group a member of a group nested in it", func(t *testing.T) {
+		s, _ := setup()
+		_, err := s.AddSubgroups(context.Background(), "engineering", []string{"platform"})
+		assert.NoError(t, err)
+		_, err = s.AddSubgroups(context.Background(), "platform", []string{"infra"})
+		assert.NoError(t, err)
+
+		_, err = s.AddSubgroups(context.Background(), "infra", []string{"engineering"})
+		assert.ErrorIs(t, err, group.ErrCyclicMembership)
+	})
+
+	t.Run("should not add a group of another organization", func(t *testing.T) {
+		s, _ := setup()
+
+		_, err := s.AddSubgroups(context.Background(), "engineering", []string{"sales"})
+		assert.ErrorIs(t, err, group.ErrOtherOrganization)
+	})
+
+	t.Run("should remove the group from the members of the group", func(t *testing.T) {
+		s, _ := setup()
+		_, err := s.AddSubgroups(context.Background(), "engineering", []string{"platform", "infra"})
+		assert.NoError(t, err)
+
+		got, err := s.RemoveSubgroup(context.Background(), "engineering", "platform")
+		assert.NoError(t, err)
+		assert.Equal(t, []group.Group{infra}, got)
+
+		_, err = s.RemoveSubgroup(context.Background(), "engineering", "platform")
+		assert.ErrorIs(t, err, group.ErrNotExist)
+	})
+}
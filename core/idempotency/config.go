@@ -0,0 +1,9 @@
+package idempotency
+
+import "time"
+
+type Config struct {
+	// TTL is how long the idempotency keys are kept, a key can be used
+	// again for a different request once it expired
+	TTL time.Duration `yaml:"ttl" mapstructure:"ttl" default:"24h"`
+}
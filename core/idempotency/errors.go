@@ -0,0 +1,10 @@
+package idempotency
+
+import "errors"
+
+var (
+	ErrInvalidDetail = errors.New("invalid idempotency key detail")
+	ErrInProgress    = errors.New("a request with the idempotency key is in progress")
+	ErrMismatch      = errors.New("the idempotency key was used for a different request")
+	ErrNotRecorded   = errors.New("the response of the request couldn't be recorded")
+)
@@ -0,0 +1,41 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// Reserve records the key unless the same key of the identity and the
+	// method is recorded and not expired yet, the recorded key is returned
+	// then and reserved is false
+	Reserve(ctx context.Context, key Key) (recorded Key, reserved bool, err error)
+	// Complete records the response of the request the key was reserved for
+	Complete(ctx context.Context, key Key) error
+	// Release removes a key whose request failed, the request can then be
+	// made again with the same key
+	Release(ctx context.Context, key Key) error
+	// DeleteExpired removes the keys expired before the time
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Key is the idempotency key of a request, the keys are scoped by the
+// identity which made the request and by its method. The request made
+// first with a key is recorded with a hash of its payload, the response of
+// the request is recorded once it completed to be returned to the retries
+// made with the same key.
+type Key struct {
+	Key         string
+	Identity    string
+	Method      string
+	RequestHash string
+	Response    []byte
+	CreatedAt   time.Time
+	CompletedAt time.Time
+	ExpiresAt   time.Time
+}
+
+// IsCompleted tells if the response of the request is recorded
+func (k Key) IsCompleted() bool {
+	return !k.CompletedAt.IsZero()
+}
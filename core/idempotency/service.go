@@ -0,0 +1,70 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxKeyLength is the length of the longest idempotency key
+const MaxKeyLength = 255
+
+type Service struct {
+	repository Repository
+	cfg        Config
+}
+
+func NewService(repository Repository, cfg Config) *Service {
+	return &Service{
+		repository: repository,
+		cfg:        cfg,
+	}
+}
+
+// Do makes the request with op once per key. The retries of a completed
+// request get its recorded response and replayed is true, the retries of a
+// request in progress get ErrInProgress and the key being used again for
+// a request with another hash gets ErrMismatch. The key is released when
+// op fails so the request can be retried. When the response can't be
+// recorded it is returned along with ErrNotRecorded, the key stays in
+// progress until it expires.
+func (s Service) Do(ctx context.Context, key Key, op func(ctx context.Context) ([]byte, error)) (response []byte, replayed bool, err error) {
+	if strings.TrimSpace(key.Key) == "" || len(key.Key) > MaxKeyLength {
+		return nil, false, fmt.Errorf("%w: the key should have 1 to %d characters", ErrInvalidDetail, MaxKeyLength)
+	}
+
+	key.ExpiresAt = time.Now().Add(s.cfg.TTL)
+	recorded, reserved, err := s.repository.Reserve(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if !reserved {
+		switch {
+		case recorded.RequestHash != key.RequestHash:
+			return nil, false, ErrMismatch
+		case !recorded.IsCompleted():
+			return nil, false, ErrInProgress
+		}
+		return recorded.Response, true, nil
+	}
+
+	response, err = op(ctx)
+	if err != nil {
+		if releaseErr := s.repository.Release(ctx, key); releaseErr != nil {
+			return nil, false, fmt.Errorf("%w, and the idempotency key couldn't be released: %s", err, releaseErr)
+		}
+		return nil, false, err
+	}
+
+	key.Response = response
+	if err := s.repository.Complete(ctx, key); err != nil {
+		return response, false, fmt.Errorf("%w: %s", ErrNotRecorded, err)
+	}
+	return response, false, nil
+}
+
+// DeleteExpired removes the keys past their ttl
+func (s Service) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.repository.DeleteExpired(ctx, time.Now())
+}
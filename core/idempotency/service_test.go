@@ -0,0 +1,138 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	keys map[string]idempotency.Key
+}
+
+func (r *memoryRepository) id(key idempotency.Key) string {
+	return key.Identity + "|" + key.Method + "|" + key.Key
+}
+
+func (r *memoryRepository) Reserve(ctx context.Context, key idempotency.Key) (idempotency.Key, bool, error) {
+	if recorded, ok := r.keys[r.id(key)]; ok && recorded.ExpiresAt.After(time.Now()) {
+		return recorded, false, nil
+	}
+	r.keys[r.id(key)] = key
+	return key, true, nil
+}
+
+func (r *memoryRepository) Complete(ctx context.Context, key idempotency.Key) error {
+	key.CompletedAt = time.Now()
+	r.keys[r.id(key)] = key
+	return nil
+}
+
+func (r *memoryRepository) Release(ctx context.Context, key idempotency.Key) error {
+	delete(r.keys, r.id(key))
+	return nil
+}
+
+func (r *memoryRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	for id, key := range r.keys {
+		if !key.ExpiresAt.After(before) {
+			delete(r.keys, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestServiceDo(t *testing.T) {
+	ctx := context.Background()
+	key := idempotency.Key{Key: "create-acme", Identity: "jane@acme.io", Method: "CreateOrganization", RequestHash: "hash-1"}
+	newService := func(ttl time.Duration) (*idempotency.Service, *memoryRepository) {
+		repo := &memoryRepository{keys: map[string]idempotency.Key{}}
+		return idempotency.NewService(repo, idempotency.Config{TTL: ttl}), repo
+	}
+
+	t.Run("should make the request once and replay its response to the retries", func(t *testing.T) {
+		svc, _ := newService(time.Hour)
+		calls := 0
+		op := func(ctx context.Context) ([]byte, error) {
+			calls++
+			return []byte("org-1"), nil
+		}
+
+		response, replayed, err := svc.Do(ctx, key, op)
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, []byte("org-1"), response)
+
+		response, replayed, err = svc.Do(ctx, key, op)
+		require.NoError(t, err)
+		assert.True(t, replayed)
+		assert.Equal(t, []byte("org-1"), response)
+		assert.Equal(t, 1, calls)
+
+		other := key
+		other.Identity = "john@acme.io"
+		_, replayed, err = svc.Do(ctx, other, op)
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should refuse the key used for a different request", func(t *testing.T) {
+		svc, _ := newService(time.Hour)
+		_, _, err := svc.Do(ctx, key, func(ctx context.Context) ([]byte, error) { return []byte("org-1"), nil })
+		require.NoError(t, err)
+
+		different := key
+		different.RequestHash = "hash-2"
+		_, _, err = svc.Do(ctx, different, func(ctx context.Context) ([]byte, error) { return nil, nil })
+		assert.ErrorIs(t, err, idempotency.ErrMismatch)
+	})
+
+	t.Run("should refuse the retries of a request in progress", func(t *testing.T) {
+		svc, _ := newService(time.Hour)
+		_, _, err := svc.Do(ctx, key, func(ctx context.Context) ([]byte, error) {
+			_, _, err := svc.Do(ctx, key, func(ctx context.Context) ([]byte, error) { return nil, nil })
+			assert.ErrorIs(t, err, idempotency.ErrInProgress)
+			return []byte("org-1"), nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("should release the key of a failed request so it can be retried", func(t *testing.T) {
+		svc, repo := newService(time.Hour)
+		_, _, err := svc.Do(ctx, key, func(ctx context.Context) ([]byte, error) { return nil, errors.New("conflict") })
+		assert.EqualError(t, err, "conflict")
+		assert.Empty(t, repo.keys)
+
+		response, replayed, err := svc.Do(ctx, key, func(ctx context.Context) ([]byte, error) { return []byte("org-1"), nil })
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, []byte("org-1"), response)
+	})
+
+	t.Run("should refuse an empty or too long key", func(t *testing.T) {
+		svc, _ := newService(time.Hour)
+		empty := key
+		empty.Key = " "
+		_, _, err := svc.Do(ctx, empty, func(ctx context.Context) ([]byte, error) { return nil, nil })
+		assert.ErrorIs(t, err, idempotency.ErrInvalidDetail)
+	})
+
+	t.Run("should delete the expired keys", func(t *testing.T) {
+		svc, repo := newService(-time.Minute)
+		_, _, err := svc.Do(ctx, key, func(ctx context.Context) ([]byte, error) { return []byte("org-1"), nil })
+		require.NoError(t, err)
+
+		count, err := svc.DeleteExpired(ctx)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+		assert.Empty(t, repo.keys)
+	})
+}
@@ -0,0 +1,12 @@
+package invitation
+
+import "errors"
+
+var (
+	ErrNotExist        = errors.New("invitation doesn't exist")
+	ErrInvalidDetail   = errors.New("invalid invitation detail")
+	ErrNotPending      = errors.New("invitation is already accepted, revoked or expired")
+	ErrInvalidInvitee  = errors.New("invitation is for another user")
+	ErrNotOrgAdmin     = errors.New("only admins of the organization can manage its invitations")
+	ErrGroupOutsideOrg = errors.New("group doesn't belong to the organization")
+)
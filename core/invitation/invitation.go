@@ -0,0 +1,55 @@
+package invitation
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultExpiry is how long an invitation can be accepted for when
+// it is created without an expiry
+const DefaultExpiry = 7 * 24 * time.Hour
+
+type Repository interface {
+	Create(ctx context.Context, inv Invitation) (Invitation, error)
+	Get(ctx context.Context, id string) (Invitation, error)
+	List(ctx context.Context, flt Filter) ([]Invitation, error)
+	Accept(ctx context.Context, id string) (Invitation, error)
+	Revoke(ctx context.Context, id string) (Invitation, error)
+}
+
+// Invitation asks the user of the email to join the organization and
+// the groups of it, the user is added to them when it is accepted
+type Invitation struct {
+	ID         string
+	OrgID      string
+	Email      string
+	GroupIDs   []string
+	InvitedBy  string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	AcceptedAt time.Time
+	RevokedAt  time.Time
+}
+
+func (i Invitation) IsAccepted() bool {
+	return !i.AcceptedAt.IsZero()
+}
+
+func (i Invitation) IsRevoked() bool {
+	return !i.RevokedAt.IsZero()
+}
+
+func (i Invitation) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// IsPending tells if the invitation can still be accepted
+func (i Invitation) IsPending(now time.Time) bool {
+	return !i.IsAccepted() && !i.IsRevoked() && !i.IsExpired(now)
+}
+
+// Filter narrows down the invitations listed, empty fields match all
+type Filter struct {
+	OrgID string
+	Email string
+}
@@ -0,0 +1,270 @@
+package invitation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+)
+
+const auditResourceType = "invitation"
+
+type OrganizationService interface {
+	Get(ctx context.Context, idOrSlug string) (organization.Organization, error)
+}
+
+type GroupService interface {
+	Get(ctx context.Context, idOrSlug string) (group.Group, error)
+}
+
+type UserService interface {
+	FetchCurrentUser(ctx context.Context) (user.User, error)
+}
+
+type RelationService interface {
+	Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error)
+	GetRelationByFields(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error)
+	DeleteV2(ctx context.Context, rel relation.RelationV2) error
+	CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, action action.Action) (bool, error)
+}
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+type Service struct {
+	repository      Repository
+	orgService      OrganizationService
+	groupService    GroupService
+	userService     UserService
+	relationService RelationService
+	auditService    AuditService
+	now             func() time.Time
+}
+
+func NewService(repository Repository, orgService OrganizationService, groupService GroupService, userService UserService, relationService RelationService, auditService AuditService) *Service {
+	return &Service{
+		repository:      repository,
+		orgService:      orgService,
+		groupService:    groupService,
+		userService:     userService,
+		relationService: relationService,
+		auditService:    auditService,
+		now:             time.Now,
+	}
+}
+
+// Create invites the email to the organization, the current user has to be
+// an admin of it and the groups have to belong to it
+func (s Service) Create(ctx context.Context, inv Invitation) (Invitation, error) {
+	if !strings.Contains(inv.Email, "@") {
+		return Invitation{}, fmt.Errorf("%w: email is invalid", ErrInvalidDetail)
+	}
+
+	org, currentUser, err := s.orgAdmin(ctx, inv.OrgID)
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	groupIDs := make([]string, 0, len(inv.GroupIDs))
+	for _, idOrSlug := range inv.GroupIDs {
+		grp, err := s.groupService.Get(ctx, idOrSlug)
+		if err != nil {
+			return Invitation{}, err
+		}
+		if grp.OrganizationID != org.ID {
+			return Invitation{}, fmt.Errorf("%w: %s", ErrGroupOutsideOrg, idOrSlug)
+		}
+		groupIDs = append(groupIDs, grp.ID)
+	}
+
+	expiresAt := inv.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = s.now().Add(DefaultExpiry)
+	}
+	if !expiresAt.After(s.now()) {
+		return Invitation{}, fmt.Errorf("%w: expiry is in the past", ErrInvalidDetail)
+	}
+
+	created, err := s.repository.Create(ctx, Invitation{
+		OrgID:     org.ID,
+		Email:     strings.ToLower(inv.Email),
+		GroupIDs:  groupIDs,
+		InvitedBy: currentUser.Email,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, created.ID, nil, created); err != nil {
+		return Invitation{}, err
+	}
+	return created, nil
+}
+
+func (s Service) Get(ctx context.Context, id string) (Invitation, error) {
+	return s.repository.Get(ctx, id)
+}
+
+func (s Service) List(ctx context.Context, flt Filter) ([]Invitation, error) {
+	if flt.OrgID != "" {
+		org, err := s.orgService.Get(ctx, flt.OrgID)
+		if err != nil {
+			return nil, err
+		}
+		flt.OrgID = org.ID
+	}
+	flt.Email = strings.ToLower(flt.Email)
+	return s.repository.List(ctx, flt)
+}
+
+// Accept adds the current user to the organization and the groups of the
+// invitation, the invitation has to be for the email of the current user.
+// The memberships added are removed again when the invitation can't be
+// accepted, so a failed accept leaves the user as they were.
+func (s Service) Accept(ctx context.Context, id string) (Invitation, error) {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	inv, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return Invitation{}, err
+	}
+	if !strings.EqualFold(inv.Email, currentUser.Email) {
+		return Invitation{}, ErrInvalidInvitee
+	}
+	if !inv.IsPending(s.now()) {
+		return Invitation{}, ErrNotPending
+	}
+
+	var added []relation.RelationV2
+	rollback := func(err error) (Invitation, error) {
+		for _, rel := range added {
+			if delErr := s.relationService.DeleteV2(ctx, rel); delErr != nil {
+				return Invitation{}, fmt.Errorf("%w: rollback: %s", err, delErr)
+			}
+		}
+		return Invitation{}, err
+	}
+
+	memberships := []relation.RelationV2{membership(currentUser, inv.OrgID, schema.OrganizationNamespace, schema.ViewerRole)}
+	for _, groupID := range inv.GroupIDs {
+		memberships = append(memberships, membership(currentUser, groupID, schema.GroupNamespace, schema.MemberRole))
+	}
+	for _, rel := range memberships {
+		created, err := s.addMember(ctx, currentUser, rel)
+		if err != nil {
+			return rollback(err)
+		}
+		if created {
+			added = append(added, rel)
+		}
+	}
+
+	// the invitation is only accepted if it is still pending, another
+	// request may have accepted or revoked it meanwhile
+	accepted, err := s.repository.Accept(ctx, inv.ID)
+	if err != nil {
+		return rollback(err)
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionUpdate, auditResourceType, accepted.ID, inv, accepted); err != nil {
+		return Invitation{}, err
+	}
+	return accepted, nil
+}
+
+// Revoke withdraws a pending invitation, the current user has to be an
+// admin of the organization
+func (s Service) Revoke(ctx context.Context, id string) (Invitation, error) {
+	inv, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return Invitation{}, err
+	}
+	if _, _, err := s.orgAdmin(ctx, inv.OrgID); err != nil {
+		return Invitation{}, err
+	}
+	if !inv.IsPending(s.now()) {
+		return Invitation{}, ErrNotPending
+	}
+
+	revoked, err := s.repository.Revoke(ctx, inv.ID)
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionRevoke, auditResourceType, revoked.ID, inv, revoked); err != nil {
+		return Invitation{}, err
+	}
+	return revoked, nil
+}
+
+// orgAdmin returns the organization if the current user can edit it
+func (s Service) orgAdmin(ctx context.Context, orgIDOrSlug string) (organization.Organization, user.User, error) {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return organization.Organization{}, user.User{}, err
+	}
+
+	org, err := s.orgService.Get(ctx, orgIDOrSlug)
+	if err != nil {
+		return organization.Organization{}, user.User{}, err
+	}
+
+	allowed, err := s.relationService.CheckPermission(ctx, currentUser, namespace.Namespace{ID: schema.OrganizationNamespace}, org.ID, action.Action{ID: schema.EditPermission})
+	if err != nil {
+		return organization.Organization{}, user.User{}, err
+	}
+	if !allowed {
+		return organization.Organization{}, user.User{}, ErrNotOrgAdmin
+	}
+	return org, currentUser, nil
+}
+
+// membership is the relation making the user a member of the object with
+// the role
+func membership(usr user.User, objectID, objectNamespace, role string) relation.RelationV2 {
+	return relation.RelationV2{
+		Object: relation.Object{
+			ID:          objectID,
+			NamespaceID: objectNamespace,
+		},
+		Subject: relation.Subject{
+			ID:        usr.ID,
+			Namespace: schema.UserPrincipal,
+			RoleID:    role,
+		},
+	}
+}
+
+// addMember creates the membership unless the user has it already, it
+// reports whether the membership was created
+func (s Service) addMember(ctx context.Context, usr user.User, rel relation.RelationV2) (bool, error) {
+	_, err := s.relationService.GetRelationByFields(ctx, rel)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, relation.ErrNotExist) {
+		return false, err
+	}
+
+	// the relations of users are created with their email
+	rel.Subject.ID = usr.Email
+	if _, err := s.relationService.Create(ctx, rel); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,228 @@
+package invitation_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/invitation"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	invitations map[string]invitation.Invitation
+}
+
+func (r *memoryRepository) Create(ctx context.Context, inv invitation.Invitation) (invitation.Invitation, error) {
+	inv.ID = "inv" + strconv.Itoa(len(r.invitations)+1)
+	r.invitations[inv.ID] = inv
+	return inv, nil
+}
+
+func (r *memoryRepository) Get(ctx context.Context, id string) (invitation.Invitation, error) {
+	inv, ok := r.invitations[id]
+	if !ok {
+		return invitation.Invitation{}, invitation.ErrNotExist
+	}
+	return inv, nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, flt invitation.Filter) ([]invitation.Invitation, error) {
+	var invitations []invitation.Invitation
+	for _, inv := range r.invitations {
+		invitations = append(invitations, inv)
+	}
+	return invitations, nil
+}
+
+func (r *memoryRepository) Accept(ctx context.Context, id string) (invitation.Invitation, error) {
+	inv := r.invitations[id]
+	inv.AcceptedAt = time.Now()
+	r.invitations[id] = inv
+	return inv, nil
+}
+
+func (r *memoryRepository) Revoke(ctx context.Context, id string) (invitation.Invitation, error) {
+	inv := r.invitations[id]
+	inv.RevokedAt = time.Now()
+	r.invitations[id] = inv
+	return inv, nil
+}
+
+type orgService struct{}
+
+func (orgService) Get(ctx context.Context, idOrSlug string) (organization.Organization, error) {
+	if idOrSlug != "odpf" && idOrSlug != "org1" {
+		return organization.Organization{}, organization.ErrNotExist
+	}
+	return organization.Organization{ID: "org1", Slug: "odpf"}, nil
+}
+
+type groupService struct{}
+
+func (groupService) Get(ctx context.Context, idOrSlug string) (group.Group, error) {
+	switch idOrSlug {
+	case "data":
+		return group.Group{ID: "g1", Slug: "data", OrganizationID: "org1"}, nil
+	case "other":
+		return group.Group{ID: "g2", Slug: "other", OrganizationID: "org2"}, nil
+	}
+	return group.Group{}, group.ErrNotExist
+}
+
+type userService struct{}
+
+func (userService) FetchCurrentUser(ctx context.Context) (user.User, error) {
+	email, ok := user.GetEmailFromContext(ctx)
+	if !ok || email == "" {
+		return user.User{}, user.ErrMissingEmail
+	}
+	return user.User{ID: "id-" + email, Email: email}, nil
+}
+
+type relationService struct {
+	admins  map[string]bool
+	created []relation.RelationV2
+	deleted []relation.RelationV2
+	// existing are the objects the users are members of already
+	existing map[string]bool
+	// failing are the objects the memberships of can't be created
+	failing map[string]bool
+}
+
+func (s *relationService) Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	if s.failing[rel.Object.ID] {
+		return relation.RelationV2{}, relation.ErrCreatingRelationInAuthzEngine
+	}
+	s.created = append(s.created, rel)
+	return rel, nil
+}
+
+func (s *relationService) GetRelationByFields(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	if s.existing[rel.Object.ID] {
+		return rel, nil
+	}
+	return relation.RelationV2{}, relation.ErrNotExist
+}
+
+func (s *relationService) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	s.deleted = append(s.deleted, rel)
+	return nil
+}
+
+func (s *relationService) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, act action.Action) (bool, error) {
+	return s.admins[usr.Email], nil
+}
+
+type noopAuditService struct{}
+
+func (noopAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	return nil
+}
+
+func TestService(t *testing.T) {
+	admin := user.SetContextWithEmail(context.Background(), "admin@odpf.io")
+	invitee := user.SetContextWithEmail(context.Background(), "jane@odpf.io")
+
+	newService := func() (*invitation.Service, *relationService) {
+		relations := &relationService{admins: map[string]bool{"admin@odpf.io": true}, existing: map[string]bool{}, failing: map[string]bool{}}
+		return invitation.NewService(
+			&memoryRepository{invitations: map[string]invitation.Invitation{}},
+			orgService{}, groupService{}, userService{}, relations, noopAuditService{}), relations
+	}
+
+	t.Run("should add the invitee to the organization and groups on accept", func(t *testing.T) {
+		s, relations := newService()
+
+		inv, err := s.Create(admin, invitation.Invitation{OrgID: "odpf", Email: "Jane@odpf.io", GroupIDs: []string{"data"}})
+		require.NoError(t, err)
+		assert.Equal(t, "org1", inv.OrgID)
+		assert.Equal(t, []string{"g1"}, inv.GroupIDs)
+		assert.Equal(t, "admin@odpf.io", inv.InvitedBy)
+		assert.False(t, inv.ExpiresAt.IsZero())
+
+		accepted, err := s.Accept(invitee, inv.ID)
+		require.NoError(t, err)
+		assert.True(t, accepted.IsAccepted())
+		require.Len(t, relations.created, 2)
+		assert.Equal(t, "org1", relations.created[0].Object.ID)
+		assert.Equal(t, "g1", relations.created[1].Object.ID)
+
+		_, err = s.Accept(invitee, inv.ID)
+		assert.ErrorIs(t, err, invitation.ErrNotPending)
+	})
+
+	t.Run("should return error if the inviter isn't an admin of the organization", func(t *testing.T) {
+		s, _ := newService()
+
+		_, err := s.Create(invitee, invitation.Invitation{OrgID: "odpf", Email: "john@odpf.io"})
+		assert.ErrorIs(t, err, invitation.ErrNotOrgAdmin)
+	})
+
+	t.Run("should return error if a group is of another organization", func(t *testing.T) {
+		s, _ := newService()
+
+		_, err := s.Create(admin, invitation.Invitation{OrgID: "odpf", Email: "jane@odpf.io", GroupIDs: []string{"other"}})
+		assert.ErrorIs(t, err, invitation.ErrGroupOutsideOrg)
+	})
+
+	t.Run("should return error if another user accepts", func(t *testing.T) {
+		s, _ := newService()
+
+		inv, err := s.Create(admin, invitation.Invitation{OrgID: "odpf", Email: "john@odpf.io"})
+		require.NoError(t, err)
+
+		_, err = s.Accept(invitee, inv.ID)
+		assert.ErrorIs(t, err, invitation.ErrInvalidInvitee)
+	})
+
+	t.Run("should return error if a revoked invitation is accepted", func(t *testing.T) {
+		s, _ := newService()
+
+		inv, err := s.Create(admin, invitation.Invitation{OrgID: "odpf", Email: "jane@odpf.io"})
+		require.NoError(t, err)
+		_, err = s.Revoke(admin, inv.ID)
+		require.NoError(t, err)
+
+		_, err = s.Accept(invitee, inv.ID)
+		assert.ErrorIs(t, err, invitation.ErrNotPending)
+	})
+	t.Run("should remove the memberships added if the invitation can't be accepted", func(t *testing.T) {
+		s, relations := newService()
+		relations.failing["g1"] = true
+
+		inv, err := s.Create(admin, invitation.Invitation{OrgID: "odpf", Email: "jane@odpf.io", GroupIDs: []string{"data"}})
+		require.NoError(t, err)
+
+		_, err = s.Accept(invitee, inv.ID)
+		assert.ErrorIs(t, err, relation.ErrCreatingRelationInAuthzEngine)
+		require.Len(t, relations.deleted, 1)
+		assert.Equal(t, "org1", relations.deleted[0].Object.ID)
+
+		pending, err := s.Get(context.Background(), inv.ID)
+		require.NoError(t, err)
+		assert.True(t, pending.IsPending(time.Now()))
+	})
+
+	t.Run("should keep the memberships the invitee had if the invitation can't be accepted", func(t *testing.T) {
+		s, relations := newService()
+		relations.existing["org1"] = true
+		relations.failing["g1"] = true
+
+		inv, err := s.Create(admin, invitation.Invitation{OrgID: "odpf", Email: "jane@odpf.io", GroupIDs: []string{"data"}})
+		require.NoError(t, err)
+
+		_, err = s.Accept(invitee, inv.ID)
+		assert.Error(t, err)
+		assert.Empty(t, relations.created)
+		assert.Empty(t, relations.deleted)
+	})
+}
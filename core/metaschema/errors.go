@@ -0,0 +1,42 @@
+package metaschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotExist        = errors.New("metadata schema doesn't exist")
+	ErrInvalidEntity   = errors.New("metadata schemas can only be registered for users, organizations, projects and groups")
+	ErrInvalidSchema   = errors.New("invalid metadata schema")
+	ErrInvalidMetadata = errors.New("metadata doesn't match the schema")
+)
+
+// Violation is a metadata value breaking a keyword of the schema
+type Violation struct {
+	// Field is the path of the value, metadata.<key> for the value of a key
+	Field string
+	// Constraint is the keyword of the schema the value breaks
+	Constraint string
+	Message    string
+}
+
+// ValidationError lists every violation of the schema by the metadata of an
+// entity
+type ValidationError struct {
+	Entity     string
+	Violations []Violation
+}
+
+func (e ValidationError) Error() string {
+	violations := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		violations = append(violations, fmt.Sprintf("%s %s", v.Field, v.Message))
+	}
+	return fmt.Sprintf("%s of the %s: %s", ErrInvalidMetadata, e.Entity, strings.Join(violations, "; "))
+}
+
+func (e ValidationError) Unwrap() error {
+	return ErrInvalidMetadata
+}
@@ -0,0 +1,45 @@
+package metaschema
+
+import (
+	"context"
+	"time"
+)
+
+// the types of entities whose metadata can have a schema
+const (
+	EntityUser         = "user"
+	EntityOrganization = "organization"
+	EntityProject      = "project"
+	EntityGroup        = "group"
+)
+
+// Entities are the types of entities whose metadata can have a schema
+var Entities = []string{EntityUser, EntityOrganization, EntityProject, EntityGroup}
+
+type Repository interface {
+	// Upsert registers the schema of the entity type, replacing its
+	// previous schema
+	Upsert(ctx context.Context, ms MetaSchema) (MetaSchema, error)
+	Get(ctx context.Context, entity string) (MetaSchema, error)
+	List(ctx context.Context) ([]MetaSchema, error)
+}
+
+// MetaSchema is the json schema the metadata of every entity of a type are
+// validated against when the entity is created or updated
+type MetaSchema struct {
+	Entity    string
+	Schema    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsValidEntity tells if the metadata of entities of the type can have a
+// schema
+func IsValidEntity(entity string) bool {
+	for _, e := range Entities {
+		if e == entity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,384 @@
+package metaschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+// schema is the subset of json schema metadata are validated against, a
+// keyword outside of it is refused rather than silently not enforced
+type schema struct {
+	// annotations, they are not validated
+	Schema      string          `json:"$schema"`
+	ID          string          `json:"$id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Default     json.RawMessage `json:"default"`
+	Examples    json.RawMessage `json:"examples"`
+
+	Type  types           `json:"type"`
+	Enum  []any           `json:"enum"`
+	Const json.RawMessage `json:"const"`
+
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *additional        `json:"additionalProperties"`
+	MinProperties        *int               `json:"minProperties"`
+	MaxProperties        *int               `json:"maxProperties"`
+
+	MinLength *int   `json:"minLength"`
+	MaxLength *int   `json:"maxLength"`
+	Pattern   string `json:"pattern"`
+	Format    string `json:"format"`
+
+	Minimum          *float64 `json:"minimum"`
+	Maximum          *float64 `json:"maximum"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum"`
+	MultipleOf       *float64 `json:"multipleOf"`
+
+	Items       *schema `json:"items"`
+	MinItems    *int    `json:"minItems"`
+	MaxItems    *int    `json:"maxItems"`
+	UniqueItems bool    `json:"uniqueItems"`
+
+	pattern *regexp.Regexp
+	// constant is Const decoded, set when the schema has a const
+	constant *any
+}
+
+// types is the type keyword, a single type or a list of types
+type types []string
+
+func (t *types) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*t = types{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return fmt.Errorf("type should be a type or a list of types")
+	}
+	*t = list
+	return nil
+}
+
+// additional is the additionalProperties keyword, either a boolean or the
+// schema of the properties not listed
+type additional struct {
+	allowed bool
+	schema  *schema
+}
+
+func (a *additional) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &a.allowed); err == nil {
+		return nil
+	}
+	a.allowed = true
+	return decodeStrict(b, &a.schema)
+}
+
+// decodeStrict decodes a schema refusing the keywords it does not know
+func decodeStrict(b []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("more than one json value")
+	}
+	return nil
+}
+
+var (
+	validTypes   = map[string]bool{"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true}
+	validFormats = map[string]func(string) bool{
+		"email": func(s string) bool {
+			addr, err := mail.ParseAddress(s)
+			return err == nil && addr.Address == s
+		},
+		"uri": func(s string) bool {
+			u, err := url.Parse(s)
+			return err == nil && u.Scheme != ""
+		},
+		"date-time": func(s string) bool {
+			_, err := time.Parse(time.RFC3339, s)
+			return err == nil
+		},
+		"date": func(s string) bool {
+			_, err := time.Parse("2006-01-02", s)
+			return err == nil
+		},
+		"uuid": uuid.IsValid,
+	}
+)
+
+// compile parses a json schema, checking every keyword is one metadata can
+// be validated against
+func compile(raw string) (*schema, error) {
+	var s schema
+	if err := decodeStrict([]byte(raw), &s); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchema, err)
+	}
+	if err := s.prepare("schema"); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchema, err)
+	}
+	return &s, nil
+}
+
+// prepare checks the keywords of the schema and of the schemas in it and
+// compiles their patterns
+func (s *schema) prepare(path string) error {
+	for _, t := range s.Type {
+		if !validTypes[t] {
+			return fmt.Errorf("%s: unknown type %s", path, t)
+		}
+	}
+	if s.Format != "" && validFormats[s.Format] == nil {
+		return fmt.Errorf("%s: unknown format %s", path, s.Format)
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: pattern: %s", path, err)
+		}
+		s.pattern = pattern
+	}
+	if s.MultipleOf != nil && *s.MultipleOf <= 0 {
+		return fmt.Errorf("%s: multipleOf should be greater than 0", path)
+	}
+
+	for i, v := range s.Enum {
+		s.Enum[i] = normalize(v)
+	}
+	if len(s.Const) > 0 {
+		var constant any
+		if err := json.Unmarshal(s.Const, &constant); err != nil {
+			return fmt.Errorf("%s: const: %s", path, err)
+		}
+		s.constant = &constant
+	}
+
+	for _, key := range sortedKeys(s.Properties) {
+		if err := s.Properties[key].prepare(path + ".properties." + key); err != nil {
+			return err
+		}
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.schema != nil {
+		if err := s.AdditionalProperties.schema.prepare(path + ".additionalProperties"); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.prepare(path + ".items"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate reports the violations of the schema by the value at field
+func (s *schema) validate(field string, value any, report func(Violation)) {
+	violate := func(constraint, format string, args ...any) {
+		report(Violation{Field: field, Constraint: constraint, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !s.hasTypeOf(value) {
+		violate("type", "should be of type %s", strings.Join(s.Type, " or "))
+		return
+	}
+	if len(s.Enum) > 0 && !contains(s.Enum, value) {
+		violate("enum", "should be one of %s", encode(s.Enum))
+	}
+	if s.constant != nil && !reflect.DeepEqual(*s.constant, value) {
+		violate("const", "should be %s", encode(*s.constant))
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		s.validateObject(field, value, violate, report)
+	case []any:
+		s.validateArray(field, value, violate, report)
+	case string:
+		length := utf8.RuneCountInString(value)
+		if s.MinLength != nil && length < *s.MinLength {
+			violate("minLength", "should be at least %d characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			violate("maxLength", "should be at most %d characters long", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(value) {
+			violate("pattern", "should match the pattern %s", s.Pattern)
+		}
+		if s.Format != "" && !validFormats[s.Format](value) {
+			violate("format", "should be a valid %s", s.Format)
+		}
+	case float64:
+		if s.Minimum != nil && value < *s.Minimum {
+			violate("minimum", "should be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && value > *s.Maximum {
+			violate("maximum", "should be at most %v", *s.Maximum)
+		}
+		if s.ExclusiveMinimum != nil && value <= *s.ExclusiveMinimum {
+			violate("exclusiveMinimum", "should be greater than %v", *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil && value >= *s.ExclusiveMaximum {
+			violate("exclusiveMaximum", "should be less than %v", *s.ExclusiveMaximum)
+		}
+		if s.MultipleOf != nil && !isInteger(value / *s.MultipleOf) {
+			violate("multipleOf", "should be a multiple of %v", *s.MultipleOf)
+		}
+	}
+}
+
+func (s *schema) validateObject(field string, value map[string]any, violate func(constraint, format string, args ...any), report func(Violation)) {
+	for _, key := range s.Required {
+		if _, ok := value[key]; !ok {
+			report(Violation{Field: field + "." + key, Constraint: "required", Message: "is required"})
+		}
+	}
+	if s.MinProperties != nil && len(value) < *s.MinProperties {
+		violate("minProperties", "should have at least %d keys", *s.MinProperties)
+	}
+	if s.MaxProperties != nil && len(value) > *s.MaxProperties {
+		violate("maxProperties", "should have at most %d keys", *s.MaxProperties)
+	}
+
+	for _, key := range sortedKeys(value) {
+		if property, ok := s.Properties[key]; ok {
+			property.validate(field+"."+key, value[key], report)
+			continue
+		}
+		if s.AdditionalProperties == nil {
+			continue
+		}
+		if !s.AdditionalProperties.allowed {
+			report(Violation{Field: field + "." + key, Constraint: "additionalProperties", Message: "is not allowed"})
+			continue
+		}
+		if s.AdditionalProperties.schema != nil {
+			s.AdditionalProperties.schema.validate(field+"."+key, value[key], report)
+		}
+	}
+}
+
+func (s *schema) validateArray(field string, value []any, violate func(constraint, format string, args ...any), report func(Violation)) {
+	if s.MinItems != nil && len(value) < *s.MinItems {
+		violate("minItems", "should have at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(value) > *s.MaxItems {
+		violate("maxItems", "should have at most %d items", *s.MaxItems)
+	}
+	if s.UniqueItems {
+		for i := range value {
+			if contains(value[:i], value[i]) {
+				violate("uniqueItems", "should not have duplicate items")
+				break
+			}
+		}
+	}
+	if s.Items != nil {
+		for i, item := range value {
+			s.Items.validate(fmt.Sprintf("%s[%d]", field, i), item, report)
+		}
+	}
+}
+
+func (s *schema) hasTypeOf(value any) bool {
+	for _, t := range s.Type {
+		switch v := value.(type) {
+		case map[string]any:
+			if t == "object" {
+				return true
+			}
+		case []any:
+			if t == "array" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && isInteger(v)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalize turns the numbers decoded from the schema into the float64 of
+// the metadata values
+func normalize(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = normalize(v[k])
+		}
+	}
+	return v
+}
+
+func isInteger(f float64) bool {
+	return f == math.Trunc(f) && !math.IsInf(f, 0)
+}
+
+func contains(values []any, value any) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func encode(v any) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
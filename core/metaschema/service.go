@@ -0,0 +1,106 @@
+package metaschema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/odpf/shield/core/audit"
+)
+
+const auditResourceType = "metaschema"
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+type Service struct {
+	repository   Repository
+	auditService AuditService
+}
+
+func NewService(repository Repository, auditService AuditService) *Service {
+	return &Service{
+		repository:   repository,
+		auditService: auditService,
+	}
+}
+
+// Create registers the schema the metadata of the entities of a type are
+// validated against, it replaces the previous schema of the type. Entities
+// created before keep their metadata until they are updated.
+func (s Service) Create(ctx context.Context, ms MetaSchema) (MetaSchema, error) {
+	if !IsValidEntity(ms.Entity) {
+		return MetaSchema{}, ErrInvalidEntity
+	}
+	if _, err := compile(ms.Schema); err != nil {
+		return MetaSchema{}, err
+	}
+
+	existing, err := s.repository.Get(ctx, ms.Entity)
+	if err != nil && !errors.Is(err, ErrNotExist) {
+		return MetaSchema{}, err
+	}
+
+	created, err := s.repository.Upsert(ctx, ms)
+	if err != nil {
+		return MetaSchema{}, err
+	}
+
+	if existing.Entity == "" {
+		err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, created.Entity, nil, created)
+	} else {
+		err = s.auditService.Record(ctx, audit.ActionUpdate, auditResourceType, created.Entity, existing, created)
+	}
+	if err != nil {
+		return MetaSchema{}, err
+	}
+	return created, nil
+}
+
+func (s Service) Get(ctx context.Context, entity string) (MetaSchema, error) {
+	return s.repository.Get(ctx, entity)
+}
+
+func (s Service) List(ctx context.Context) ([]MetaSchema, error) {
+	return s.repository.List(ctx)
+}
+
+// Validate checks the metadata of an entity of the type against the schema
+// registered for the type, a ValidationError lists every violation. Any
+// metadata is valid for a type without schema.
+func (s Service) Validate(ctx context.Context, entity string, metadata map[string]any) error {
+	ms, err := s.repository.Get(ctx, entity)
+	if err != nil {
+		if errors.Is(err, ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	sch, err := compile(ms.Schema)
+	if err != nil {
+		return err
+	}
+
+	// the values are validated the way they are encoded in json, whatever
+	// the go types they were built with
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidMetadata, err)
+	}
+	value := map[string]any{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidMetadata, err)
+	}
+
+	var violations []Violation
+	sch.validate("metadata", value, func(v Violation) {
+		violations = append(violations, v)
+	})
+	if len(violations) > 0 {
+		return ValidationError{Entity: entity, Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package metaschema_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/metaschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	mu      sync.Mutex
+	schemas map[string]metaschema.MetaSchema
+}
+
+func (r *memoryRepository) Upsert(ctx context.Context, ms metaschema.MetaSchema) (metaschema.MetaSchema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.schemas == nil {
+		r.schemas = map[string]metaschema.MetaSchema{}
+	}
+	r.schemas[ms.Entity] = ms
+	return ms, nil
+}
+
+func (r *memoryRepository) Get(ctx context.Context, entity string) (metaschema.MetaSchema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ms, ok := r.schemas[entity]
+	if !ok {
+		return metaschema.MetaSchema{}, metaschema.ErrNotExist
+	}
+	return ms, nil
+}
+
+func (r *memoryRepository) List(ctx context.Context) ([]metaschema.MetaSchema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var schemas []metaschema.MetaSchema
+	for _, ms := range r.schemas {
+		schemas = append(schemas, ms)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Entity < schemas[j].Entity })
+	return schemas, nil
+}
+
+type memoryAuditService struct {
+	logs []audit.Log
+}
+
+func (s *memoryAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	s.logs = append(s.logs, audit.Log{Action: action, ResourceType: resourceType, ResourceID: resourceID})
+	return nil
+}
+
+const userSchema = `{
+	"type": "object",
+	"required": ["manager_email"],
+	"properties": {
+		"manager_email": {"type": "string", "format": "email"},
+		"level": {"type": "integer", "minimum": 1, "maximum": 5},
+		"team": {"enum": ["data", "platform"]},
+		"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "uniqueItems": true}
+	},
+	"additionalProperties": false
+}`
+
+func TestCreate(t *testing.T) {
+	t.Run("should register the schema and audit it", func(t *testing.T) {
+		auditService := &memoryAuditService{}
+		s := metaschema.NewService(&memoryRepository{}, auditService)
+
+		_, err := s.Create(context.Background(), metaschema.MetaSchema{Entity: metaschema.EntityUser, Schema: userSchema})
+		require.NoError(t, err)
+		_, err = s.Create(context.Background(), metaschema.MetaSchema{Entity: metaschema.EntityUser, Schema: `{"type": "object"}`})
+		require.NoError(t, err)
+
+		registered, err := s.Get(context.Background(), metaschema.EntityUser)
+		require.NoError(t, err)
+		assert.Equal(t, `{"type": "object"}`, registered.Schema)
+		require.Len(t, auditService.logs, 2)
+		assert.Equal(t, audit.ActionCreate, auditService.logs[0].Action)
+		assert.Equal(t, audit.ActionUpdate, auditService.logs[1].Action)
+	})
+
+	t.Run("should refuse a schema for another type of entity", func(t *testing.T) {
+		s := metaschema.NewService(&memoryRepository{}, &memoryAuditService{})
+
+		_, err := s.Create(context.Background(), metaschema.MetaSchema{Entity: "role", Schema: `{}`})
+		assert.ErrorIs(t, err, metaschema.ErrInvalidEntity)
+	})
+
+	t.Run("should refuse a schema with keywords that can't be enforced", func(t *testing.T) {
+		s := metaschema.NewService(&memoryRepository{}, &memoryAuditService{})
+
+		for _, schema := range []string{
+			`{"type": "object"`,
+			`{"oneOf": [{"type": "string"}]}`,
+			`{"type": "text"}`,
+			`{"properties": {"name": {"format": "phone"}}}`,
+			`{"additionalProperties": {"pattern": "("}}`,
+		} {
+			_, err := s.Create(context.Background(), metaschema.MetaSchema{Entity: metaschema.EntityGroup, Schema: schema})
+			assert.ErrorIs(t, err, metaschema.ErrInvalidSchema, schema)
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	repository := &memoryRepository{}
+	s := metaschema.NewService(repository, &memoryAuditService{})
+	_, err := s.Create(context.Background(), metaschema.MetaSchema{Entity: metaschema.EntityUser, Schema: userSchema})
+	require.NoError(t, err)
+
+	t.Run("should accept metadata matching the schema", func(t *testing.T) {
+		err := s.Validate(context.Background(), metaschema.EntityUser, map[string]any{
+			"manager_email": "lead@odpf.io",
+			"level":         3,
+			"team":          "data",
+			"tags":          []any{"ml", "etl"},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should accept any metadata of an entity type without schema", func(t *testing.T) {
+		err := s.Validate(context.Background(), metaschema.EntityGroup, map[string]any{"anything": true})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should report every violation of the schema", func(t *testing.T) {
+		err := s.Validate(context.Background(), metaschema.EntityUser, map[string]any{
+			"level": 2.5,
+			"team":  "sales",
+			"tags":  []any{"ml", "ML", "ml"},
+			"other": "value",
+		})
+		assert.ErrorIs(t, err, metaschema.ErrInvalidMetadata)
+
+		var validationErr metaschema.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, metaschema.EntityUser, validationErr.Entity)
+		assert.Equal(t, []metaschema.Violation{
+			{Field: "metadata.manager_email", Constraint: "required", Message: "is required"},
+			{Field: "metadata.level", Constraint: "type", Message: "should be of type integer"},
+			{Field: "metadata.other", Constraint: "additionalProperties", Message: "is not allowed"},
+			{Field: "metadata.tags", Constraint: "uniqueItems", Message: "should not have duplicate items"},
+			{Field: "metadata.tags[1]", Constraint: "pattern", Message: "should match the pattern ^[a-z]+$"},
+			{Field: "metadata.team", Constraint: "enum", Message: `should be one of ["data","platform"]`},
+		}, validationErr.Violations)
+	})
+
+	t.Run("should report a value of the wrong format", func(t *testing.T) {
+		err := s.Validate(context.Background(), metaschema.EntityUser, map[string]any{"manager_email": "lead"})
+
+		var validationErr metaschema.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []metaschema.Violation{
+			{Field: "metadata.manager_email", Constraint: "format", Message: "should be a valid email"},
+		}, validationErr.Violations)
+	})
+}
@@ -1,6 +1,6 @@
 package namespace
 
-var systemIdsDefinition = []string{DefinitionTeam.ID, DefinitionUser.ID, DefinitionOrg.ID, DefinitionProject.ID}
+var systemIdsDefinition = []string{DefinitionTeam.ID, DefinitionUser.ID, DefinitionOrg.ID, DefinitionProject.ID, DefinitionFolder.ID}
 
 var DefinitionOrg = Namespace{
 	ID:   "shield/organization",
@@ -12,6 +12,11 @@ var DefinitionProject = Namespace{
 	Name: "Project",
 }
 
+var DefinitionFolder = Namespace{
+	ID:   "shield/folder",
+	Name: "Folder",
+}
+
 var DefinitionTeam = Namespace{
 	ID:   "shield/group",
 	Name: "Group",
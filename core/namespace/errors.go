@@ -7,4 +7,5 @@ var (
 	ErrNotExist      = errors.New("namespace doesn't exist")
 	ErrConflict      = errors.New("namespace name already exist")
 	ErrInvalidDetail = errors.New("invalid namespace detail")
+	ErrInUse         = errors.New("namespace is still referenced by other resources")
 )
@@ -3,14 +3,26 @@ package namespace
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 )
 
+var (
+	// idPattern is the name the authz schema allows for the definition of a
+	// namespace, optionally prefixed by its backend
+	idPattern = regexp.MustCompile(`^([a-z][a-z0-9_]{1,61}[a-z0-9]/)?[a-z][a-z0-9_]{1,62}[a-z0-9]$`)
+	// relationNamePattern is the name the authz schema allows for the
+	// relations and permissions of a definition, the roles and actions of a
+	// namespace are written as them
+	relationNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,62}[a-z0-9]$`)
+)
+
 type Repository interface {
 	Get(ctx context.Context, id string) (Namespace, error)
 	Create(ctx context.Context, ns Namespace) (Namespace, error)
 	List(ctx context.Context) ([]Namespace, error)
 	Update(ctx context.Context, ns Namespace) (Namespace, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type Namespace struct {
@@ -42,3 +54,15 @@ func CreateID(backend, resourceType string) string {
 
 	return fmt.Sprintf("%s/%s", backend, resourceType)
 }
+
+// IsValidID reports whether the id can name the definition of the namespace
+// in the authz schema
+func IsValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// IsValidRelationName reports whether the name can name a relation or a
+// permission in the authz schema
+func IsValidRelationName(name string) bool {
+	return relationNamePattern.MatchString(name)
+}
@@ -2,6 +2,7 @@ package namespace
 
 import (
 	"context"
+	"fmt"
 )
 
 type Service struct {
@@ -19,6 +20,9 @@ func (s Service) Get(ctx context.Context, id string) (Namespace, error) {
 }
 
 func (s Service) Create(ctx context.Context, ns Namespace) (Namespace, error) {
+	if err := validate(ns); err != nil {
+		return Namespace{}, err
+	}
 	return s.repository.Create(ctx, ns)
 }
 
@@ -27,9 +31,24 @@ func (s Service) List(ctx context.Context) ([]Namespace, error) {
 }
 
 func (s Service) Update(ctx context.Context, ns Namespace) (Namespace, error) {
+	if err := validate(ns); err != nil {
+		return Namespace{}, err
+	}
 	updatedNamespace, err := s.repository.Update(ctx, ns)
 	if err != nil {
 		return Namespace{}, err
 	}
 	return updatedNamespace, nil
 }
+
+func (s Service) Delete(ctx context.Context, id string) error {
+	return s.repository.Delete(ctx, id)
+}
+
+// validate checks the namespace can be written to the authz schema
+func validate(ns Namespace) error {
+	if !IsValidID(ns.ID) {
+		return fmt.Errorf("%w: %s is not a valid authz schema definition name", ErrInvalidDetail, ns.ID)
+	}
+	return nil
+}
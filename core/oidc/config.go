@@ -0,0 +1,29 @@
+package oidc
+
+import "time"
+
+type Config struct {
+	// Providers are the identity providers whose id tokens authenticate
+	// requests, e.g. google, okta or keycloak. Tokens are not accepted when
+	// there are none.
+	Providers []ProviderConfig `yaml:"providers" mapstructure:"providers"`
+	// ClaimsMetadata maps the claims of the tokens to the metadata keys of
+	// the users, the metadata keys have to exist
+	ClaimsMetadata map[string]string `yaml:"claims_metadata" mapstructure:"claims_metadata"`
+	// Timeout of the requests made to the providers
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" default:"10s"`
+}
+
+type ProviderConfig struct {
+	// Issuer is the url the provider is discovered at, it has to be the iss
+	// claim of its tokens e.g. https://accounts.google.com
+	Issuer string `yaml:"issuer" mapstructure:"issuer"`
+	// Audiences are the client ids the tokens can be issued to, a token has
+	// to be issued to one of them
+	Audiences []string `yaml:"audiences" mapstructure:"audiences"`
+}
+
+// Enabled reports whether id tokens authenticate requests
+func (c Config) Enabled() bool {
+	return len(c.Providers) > 0
+}
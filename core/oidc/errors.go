@@ -0,0 +1,11 @@
+package oidc
+
+import "errors"
+
+var (
+	ErrInvalidDetail = errors.New("invalid oidc detail")
+	ErrInvalidToken  = errors.New("invalid id token")
+	ErrUnknownIssuer = errors.New("unknown token issuer")
+	ErrMissingEmail  = errors.New("id token doesn't have a verified email")
+	ErrDiscovery     = errors.New("failed to discover oidc provider")
+)
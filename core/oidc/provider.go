@@ -0,0 +1,162 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// minDiscoveryRetryInterval is how often the discovery of a provider is
+// retried once it failed, so tokens can't flood a provider which is down
+const minDiscoveryRetryInterval = time.Minute
+
+// signingAlgorithms are the asymmetric algorithms the providers sign id
+// tokens with, the ones advertised by a provider are used when it has any
+var signingAlgorithms = []string{
+	gooidc.RS256, gooidc.RS384, gooidc.RS512,
+	gooidc.ES256, gooidc.ES384, gooidc.ES512,
+}
+
+// provider verifies the tokens of an issuer with go-oidc, the issuer is
+// discovered on the first token and its keys are cached by go-oidc, they are
+// fetched again for a token signed with a key not seen yet
+type provider struct {
+	cfg    ProviderConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu           sync.Mutex
+	verifier     *gooidc.IDTokenVerifier
+	discoveredAt time.Time
+}
+
+func newProvider(cfg ProviderConfig, client *http.Client) *provider {
+	return &provider{
+		cfg:    cfg,
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// verify checks the token is signed by the provider, isn't expired and is
+// issued to one of its audiences, it returns the claims of the token
+func (p *provider) verify(ctx context.Context, token string) (claims, error) {
+	verifier, err := p.discover(ctx)
+	if err != nil {
+		return claims{}, err
+	}
+	idToken, err := verifier.Verify(gooidc.ClientContext(ctx, p.client), token)
+	if err != nil {
+		return claims{}, fmt.Errorf("%w: %s", ErrInvalidToken, err.Error())
+	}
+	if !issuedTo(idToken.Audience, p.cfg.Audiences) {
+		return claims{}, fmt.Errorf("%w: token isn't issued to an accepted audience", ErrInvalidToken)
+	}
+
+	var c claims
+	if err := idToken.Claims(&c); err != nil {
+		return claims{}, fmt.Errorf("%w: claims: %s", ErrInvalidToken, err.Error())
+	}
+	if err := idToken.Claims(&c.Raw); err != nil {
+		return claims{}, fmt.Errorf("%w: claims: %s", ErrInvalidToken, err.Error())
+	}
+	return c, nil
+}
+
+func (p *provider) discover(ctx context.Context) (*gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.verifier != nil {
+		return p.verifier, nil
+	}
+	if !p.discoveredAt.IsZero() && p.now().Sub(p.discoveredAt) < minDiscoveryRetryInterval {
+		return nil, fmt.Errorf("%w: %s, retried in %s", ErrDiscovery, p.cfg.Issuer, minDiscoveryRetryInterval)
+	}
+	p.discoveredAt = p.now()
+
+	// the keys are fetched later with the client of the context, without its
+	// cancellation
+	discovered, err := gooidc.NewProvider(gooidc.ClientContext(ctx, p.client), p.cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err.Error())
+	}
+	// the audiences are checked once verified, a token has to be issued to
+	// one of them rather than to a single client id
+	p.verifier = discovered.Verifier(&gooidc.Config{
+		SkipClientIDCheck:    true,
+		SupportedSigningAlgs: signingAlgorithms,
+		Now:                  p.now,
+	})
+	return p.verifier, nil
+}
+
+func issuedTo(tokenAudiences, audiences []string) bool {
+	for _, aud := range tokenAudiences {
+		for _, accepted := range audiences {
+			if aud == accepted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claims are the claims of an id token used to authenticate its user, Raw
+// has all of them
+type claims struct {
+	Email         string         `json:"email"`
+	EmailVerified flexBool       `json:"email_verified"`
+	Name          string         `json:"name"`
+	Raw           map[string]any `json:"-"`
+}
+
+// flexBool is a boolean claim some providers encode as a string
+type flexBool struct {
+	Set   bool
+	Value bool
+}
+
+func (f *flexBool) UnmarshalJSON(b []byte) error {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		*f = flexBool{Set: true, Value: v}
+	case string:
+		*f = flexBool{Set: true, Value: v == "true"}
+	}
+	return nil
+}
+
+// verifiedEmail is the email of the user, providers which tell whether the
+// email is verified have to have verified it
+func (c claims) verifiedEmail() (string, error) {
+	email := strings.TrimSpace(c.Email)
+	if email == "" || (c.EmailVerified.Set && !c.EmailVerified.Value) {
+		return "", ErrMissingEmail
+	}
+	return email, nil
+}
+
+// peekIssuer is the issuer of a token, read without verifying the token
+func peekIssuer(token string) (string, bool) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return "", false
+	}
+	var c jwt.Claims
+	if err := parsed.UnsafeClaimsWithoutVerification(&c); err != nil || c.Issuer == "" {
+		return "", false
+	}
+	return c.Issuer, true
+}
@@ -0,0 +1,136 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/pkg/metadata"
+)
+
+type UserService interface {
+	GetByEmail(ctx context.Context, email string) (user.User, error)
+	Create(ctx context.Context, user user.User) (user.User, error)
+	UpdateByEmail(ctx context.Context, toUpdate user.User) (user.User, error)
+}
+
+// Service authenticates the requests carrying an id token of a configured
+// provider, users are created on their first login
+type Service struct {
+	providers      map[string]*provider
+	claimsMetadata map[string]string
+	userService    UserService
+}
+
+func NewService(cfg Config, userService UserService) (*Service, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+	providers := make(map[string]*provider, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		issuer, err := url.Parse(p.Issuer)
+		if err != nil || !issuer.IsAbs() || issuer.Host == "" {
+			return nil, fmt.Errorf("%w: issuer %q should be an absolute url", ErrInvalidDetail, p.Issuer)
+		}
+		if len(p.Audiences) == 0 {
+			return nil, fmt.Errorf("%w: issuer %s has no audiences", ErrInvalidDetail, p.Issuer)
+		}
+		if _, ok := providers[p.Issuer]; ok {
+			return nil, fmt.Errorf("%w: issuer %s is configured twice", ErrInvalidDetail, p.Issuer)
+		}
+		providers[p.Issuer] = newProvider(p, client)
+	}
+
+	return &Service{
+		providers:      providers,
+		claimsMetadata: cfg.ClaimsMetadata,
+		userService:    userService,
+	}, nil
+}
+
+// Handles reports whether the token is issued by a configured provider,
+// the token isn't verified
+func (s Service) Handles(token string) bool {
+	issuer, ok := peekIssuer(token)
+	if !ok {
+		return false
+	}
+	_, ok = s.providers[issuer]
+	return ok
+}
+
+// Authenticate returns the user of the verified email of the id token,
+// creating the user on their first login. The metadata mapped from the
+// claims is kept up to date on every login.
+func (s Service) Authenticate(ctx context.Context, token string) (user.User, error) {
+	issuer, ok := peekIssuer(token)
+	if !ok {
+		return user.User{}, fmt.Errorf("%w: token doesn't have an issuer", ErrInvalidToken)
+	}
+	p, ok := s.providers[issuer]
+	if !ok {
+		return user.User{}, fmt.Errorf("%w: %s", ErrUnknownIssuer, issuer)
+	}
+	c, err := p.verify(ctx, token)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	email, err := c.verifiedEmail()
+	if err != nil {
+		return user.User{}, err
+	}
+	claimsMetadata := s.metadataOf(c)
+
+	usr, err := s.userService.GetByEmail(ctx, email)
+	if errors.Is(err, user.ErrNotExist) {
+		name := c.Name
+		if name == "" {
+			name = email
+		}
+		usr, err = s.userService.Create(ctx, user.User{Name: name, Email: email, Metadata: claimsMetadata})
+		if errors.Is(err, user.ErrConflict) {
+			// created by a concurrent first login
+			return s.userService.GetByEmail(ctx, email)
+		}
+		return usr, err
+	}
+	if err != nil {
+		return user.User{}, err
+	}
+
+	if len(claimsMetadata) == 0 {
+		return usr, nil
+	}
+	merged := metadata.Metadata{}
+	for k, v := range usr.Metadata {
+		merged[k] = v
+	}
+	for k, v := range claimsMetadata {
+		merged[k] = v
+	}
+	if sameJSON(usr.Metadata, merged) {
+		return usr, nil
+	}
+	usr.Metadata = merged
+	return s.userService.UpdateByEmail(ctx, usr)
+}
+
+// metadataOf maps the claims of the token to metadata of the user
+func (s Service) metadataOf(c claims) metadata.Metadata {
+	md := metadata.Metadata{}
+	for claim, key := range s.claimsMetadata {
+		if v, ok := c.Raw[claim]; ok {
+			md[key] = v
+		}
+	}
+	return md
+}
+
+func sameJSON(a, b map[string]any) bool {
+	aj, aErr := json.Marshal(a)
+	bj, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aj) == string(bj)
+}
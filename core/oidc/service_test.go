@@ -0,0 +1,211 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/odpf/shield/core/oidc"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAudience = "shield-client"
+
+type memoryUserService struct {
+	users map[string]user.User
+}
+
+func (s *memoryUserService) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	usr, ok := s.users[email]
+	if !ok {
+		return user.User{}, user.ErrNotExist
+	}
+	return usr, nil
+}
+
+func (s *memoryUserService) Create(ctx context.Context, usr user.User) (user.User, error) {
+	if _, ok := s.users[usr.Email]; ok {
+		return user.User{}, user.ErrConflict
+	}
+	usr.ID = "user" + strconv.Itoa(len(s.users)+1)
+	s.users[usr.Email] = usr
+	return usr, nil
+}
+
+func (s *memoryUserService) UpdateByEmail(ctx context.Context, toUpdate user.User) (user.User, error) {
+	if _, ok := s.users[toUpdate.Email]; !ok {
+		return user.User{}, user.ErrNotExist
+	}
+	s.users[toUpdate.Email] = toUpdate
+	return toUpdate, nil
+}
+
+// testIssuer is an oidc provider serving its discovery document and keys
+type testIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	iss := &testIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   iss.server.URL,
+			"jwks_uri": iss.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+			Key:       &key.PublicKey,
+			KeyID:     "key1",
+			Algorithm: string(jose.RS256),
+			Use:       "sig",
+		}}})
+	})
+	iss.server = httptest.NewServer(mux)
+	t.Cleanup(iss.server.Close)
+	return iss
+}
+
+func (iss *testIssuer) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: iss.key}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "key1"))
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
+func (iss *testIssuer) claims(email string) map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":            iss.server.URL,
+		"sub":            "subject",
+		"aud":            testAudience,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          email,
+		"email_verified": true,
+		"name":           "Jane",
+		"hd":             "example.com",
+	}
+}
+
+func newService(t *testing.T, iss *testIssuer, users *memoryUserService) *oidc.Service {
+	t.Helper()
+	svc, err := oidc.NewService(oidc.Config{
+		Providers:      []oidc.ProviderConfig{{Issuer: iss.server.URL, Audiences: []string{testAudience}}},
+		ClaimsMetadata: map[string]string{"hd": "domain"},
+		Timeout:        time.Second,
+	}, users)
+	require.NoError(t, err)
+	return svc
+}
+
+func TestServiceAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	iss := newTestIssuer(t)
+
+	t.Run("should create the user on the first login", func(t *testing.T) {
+		users := &memoryUserService{users: map[string]user.User{}}
+		svc := newService(t, iss, users)
+
+		got, err := svc.Authenticate(ctx, iss.sign(t, iss.claims("jane@example.com")))
+		require.NoError(t, err)
+		assert.Equal(t, "Jane", got.Name)
+		assert.Equal(t, "jane@example.com", got.Email)
+		assert.Equal(t, "example.com", got.Metadata["domain"])
+		assert.Len(t, users.users, 1)
+	})
+
+	t.Run("should update the metadata of an existing user", func(t *testing.T) {
+		users := &memoryUserService{users: map[string]user.User{
+			"jane@example.com": {ID: "user1", Name: "Jane", Email: "jane@example.com", Metadata: map[string]any{"domain": "old.com", "team": "iam"}},
+		}}
+		svc := newService(t, iss, users)
+
+		got, err := svc.Authenticate(ctx, iss.sign(t, iss.claims("jane@example.com")))
+		require.NoError(t, err)
+		assert.Equal(t, "user1", got.ID)
+		assert.Equal(t, "example.com", users.users["jane@example.com"].Metadata["domain"])
+		assert.Equal(t, "iam", users.users["jane@example.com"].Metadata["team"])
+	})
+
+	t.Run("should return error if the token is issued to another audience", func(t *testing.T) {
+		svc := newService(t, iss, &memoryUserService{users: map[string]user.User{}})
+		claims := iss.claims("jane@example.com")
+		claims["aud"] = "another-client"
+
+		_, err := svc.Authenticate(ctx, iss.sign(t, claims))
+		assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+	})
+
+	t.Run("should return error if the token is expired", func(t *testing.T) {
+		svc := newService(t, iss, &memoryUserService{users: map[string]user.User{}})
+		claims := iss.claims("jane@example.com")
+		claims["exp"] = time.Now().Add(-time.Hour).Unix()
+
+		_, err := svc.Authenticate(ctx, iss.sign(t, claims))
+		assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+	})
+
+	t.Run("should return error if the signature is invalid", func(t *testing.T) {
+		svc := newService(t, iss, &memoryUserService{users: map[string]user.User{}})
+		token := iss.sign(t, iss.claims("jane@example.com"))
+
+		_, err := svc.Authenticate(ctx, token[:len(token)-4]+"AAAA")
+		assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+	})
+
+	t.Run("should return error if the email is not verified", func(t *testing.T) {
+		svc := newService(t, iss, &memoryUserService{users: map[string]user.User{}})
+		claims := iss.claims("jane@example.com")
+		claims["email_verified"] = "false"
+
+		_, err := svc.Authenticate(ctx, iss.sign(t, claims))
+		assert.ErrorIs(t, err, oidc.ErrMissingEmail)
+	})
+}
+
+func TestServiceHandles(t *testing.T) {
+	iss := newTestIssuer(t)
+	svc := newService(t, iss, &memoryUserService{users: map[string]user.User{}})
+
+	assert.True(t, svc.Handles(iss.sign(t, iss.claims("jane@example.com"))))
+
+	other := iss.claims("jane@example.com")
+	other["iss"] = "https://accounts.example.com"
+	assert.False(t, svc.Handles(iss.sign(t, other)))
+	assert.False(t, svc.Handles("not-a-token"))
+}
+
+func TestNewService(t *testing.T) {
+	t.Run("should return error if the issuer is not an absolute url", func(t *testing.T) {
+		_, err := oidc.NewService(oidc.Config{
+			Providers: []oidc.ProviderConfig{{Issuer: "accounts.example.com", Audiences: []string{testAudience}}},
+		}, nil)
+		assert.ErrorIs(t, err, oidc.ErrInvalidDetail)
+	})
+
+	t.Run("should return error if the provider has no audiences", func(t *testing.T) {
+		_, err := oidc.NewService(oidc.Config{
+			Providers: []oidc.ProviderConfig{{Issuer: "https://accounts.example.com"}},
+		}, nil)
+		assert.ErrorIs(t, err, oidc.ErrInvalidDetail)
+	})
+}
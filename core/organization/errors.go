@@ -3,9 +3,13 @@ package organization
 import "errors"
 
 var (
-	ErrNotExist      = errors.New("org doesn't exist")
-	ErrInvalidUUID   = errors.New("invalid syntax of uuid")
-	ErrInvalidID     = errors.New("org id is invalid")
-	ErrConflict      = errors.New("org already exist")
-	ErrInvalidDetail = errors.New("invalid org detail")
+	ErrNotExist        = errors.New("org doesn't exist")
+	ErrInvalidUUID     = errors.New("invalid syntax of uuid")
+	ErrInvalidID       = errors.New("org id is invalid")
+	ErrConflict        = errors.New("org already exist")
+	ErrInvalidDetail   = errors.New("invalid org detail")
+	ErrInUse           = errors.New("org is still referenced by other resources")
+	ErrNotDeleted      = errors.New("org isn't deleted")
+	ErrInvalidParent   = errors.New("parent org doesn't exist or can't be edited")
+	ErrVersionMismatch = errors.New("org was updated since the version")
 )
@@ -0,0 +1,18 @@
+package organization
+
+// Filter narrows down the organizations listed, Name, Slug and the
+// Metadata values are globs where * matches any run of characters
+type Filter struct {
+	Name     string
+	Slug     string
+	Metadata map[string]string
+	// ParentID lists the direct children of the organization
+	ParentID string
+	// UserID lists the organizations the user is a member of, through a
+	// role on the organization or a membership of one of its groups
+	UserID string
+	// IncludeDeleted lists the soft deleted organizations as well
+	IncludeDeleted bool
+	Limit          int32
+	Page           int32
+}
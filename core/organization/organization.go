@@ -12,17 +12,34 @@ type Repository interface {
 	GetByID(ctx context.Context, id string) (Organization, error)
 	GetBySlug(ctx context.Context, slug string) (Organization, error)
 	Create(ctx context.Context, org Organization) (Organization, error)
-	List(ctx context.Context) ([]Organization, error)
+	List(ctx context.Context, flt Filter) ([]Organization, error)
 	UpdateByID(ctx context.Context, org Organization) (Organization, error)
 	UpdateBySlug(ctx context.Context, org Organization) (Organization, error)
 	ListAdminsByOrgID(ctx context.Context, id string) ([]user.User, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	ListDeleted(ctx context.Context, deletedBefore time.Time) ([]Organization, error)
+	Purge(ctx context.Context, id string) error
 }
 
 type Organization struct {
-	ID        string
-	Name      string
-	Slug      string
-	Metadata  metadata.Metadata
+	ID       string
+	Name     string
+	Slug     string
+	Metadata metadata.Metadata
+	// ParentID is the id of the organization this one is a business unit
+	// of, the admins of the parent are admins of it as well
+	ParentID string
+	// Version is incremented on every update, an update made with a
+	// version is refused once the organization was updated since
+	Version   int64
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// DeletedAt is set once the organization is deleted, it can be
+	// restored until it is purged after the retention window
+	DeletedAt time.Time
+}
+
+func (o Organization) IsDeleted() bool {
+	return !o.DeletedAt.IsZero()
 }
@@ -2,9 +2,12 @@ package organization
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/audit"
 	"github.com/odpf/shield/core/namespace"
 	"github.com/odpf/shield/core/relation"
 	"github.com/odpf/shield/core/user"
@@ -15,6 +18,7 @@ import (
 type RelationService interface {
 	Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error)
 	Delete(ctx context.Context, rel relation.Relation) error
+	DeleteSubjectRelations(ctx context.Context, resourceType, optionalResourceID string) error
 	CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, action action.Action) (bool, error)
 }
 
@@ -24,21 +28,42 @@ type UserService interface {
 	GetByIDs(ctx context.Context, userIDs []string) ([]user.User, error)
 }
 
+const auditResourceType = "organization"
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
 type Service struct {
 	repository      Repository
 	relationService RelationService
 	userService     UserService
+	auditService    AuditService
 }
 
-func NewService(repository Repository, relationService RelationService, userService UserService) *Service {
+func NewService(repository Repository, relationService RelationService, userService UserService, auditService AuditService) *Service {
 	return &Service{
 		repository:      repository,
 		relationService: relationService,
 		userService:     userService,
+		auditService:    auditService,
 	}
 }
 
+// Get returns the organization unless it is deleted, deleted organizations
+// are only visible to Restore until they are purged
 func (s Service) Get(ctx context.Context, idOrSlug string) (Organization, error) {
+	org, err := s.get(ctx, idOrSlug)
+	if err != nil {
+		return Organization{}, err
+	}
+	if org.IsDeleted() {
+		return Organization{}, ErrNotExist
+	}
+	return org, nil
+}
+
+func (s Service) get(ctx context.Context, idOrSlug string) (Organization, error) {
 	if uuid.IsValid(idOrSlug) {
 		return s.repository.GetByID(ctx, idOrSlug)
 	}
@@ -51,10 +76,18 @@ func (s Service) Create(ctx context.Context, org Organization) (Organization, er
 		return Organization{}, fmt.Errorf("%w: %s", user.ErrInvalidEmail, err.Error())
 	}
 
+	var parent Organization
+	if org.ParentID != "" {
+		if parent, err = s.parentOrg(ctx, currentUser, org.ParentID); err != nil {
+			return Organization{}, err
+		}
+	}
+
 	newOrg, err := s.repository.Create(ctx, Organization{
 		Name:     org.Name,
 		Slug:     org.Slug,
 		Metadata: org.Metadata,
+		ParentID: parent.ID,
 	})
 	if err != nil {
 		return Organization{}, err
@@ -64,18 +97,46 @@ func (s Service) Create(ctx context.Context, org Organization) (Organization, er
 		return Organization{}, err
 	}
 
+	if parent.ID != "" {
+		if err = s.addOrgToParent(ctx, newOrg, parent); err != nil {
+			return Organization{}, err
+		}
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, newOrg.ID, nil, newOrg); err != nil {
+		return Organization{}, err
+	}
+
 	return newOrg, nil
 }
 
-func (s Service) List(ctx context.Context) ([]Organization, error) {
-	return s.repository.List(ctx)
+func (s Service) List(ctx context.Context, flt Filter) ([]Organization, error) {
+	return s.repository.List(ctx, flt)
 }
 
 func (s Service) Update(ctx context.Context, org Organization) (Organization, error) {
+	var oldOrganization, updatedOrganization Organization
+	var err error
 	if org.ID != "" {
-		return s.repository.UpdateByID(ctx, org)
+		if oldOrganization, err = s.repository.GetByID(ctx, org.ID); err != nil {
+			return Organization{}, err
+		}
+		updatedOrganization, err = s.repository.UpdateByID(ctx, org)
+	} else {
+		if oldOrganization, err = s.repository.GetBySlug(ctx, org.Slug); err != nil {
+			return Organization{}, err
+		}
+		updatedOrganization, err = s.repository.UpdateBySlug(ctx, org)
+	}
+	if err != nil {
+		return Organization{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionUpdate, auditResourceType, updatedOrganization.ID, oldOrganization, updatedOrganization); err != nil {
+		return Organization{}, err
 	}
-	return s.repository.UpdateBySlug(ctx, org)
+
+	return updatedOrganization, nil
 }
 
 func (s Service) AddAdmins(ctx context.Context, idOrSlug string, userIds []string) ([]user.User, error) {
@@ -101,6 +162,46 @@ func (s Service) RemoveAdmin(ctx context.Context, idOrSlug string, userId string
 	return []user.User{}, nil
 }
 
+// parentOrg returns the organization a new organization is created under,
+// the current user has to be able to edit it
+func (s Service) parentOrg(ctx context.Context, currentUser user.User, idOrSlug string) (Organization, error) {
+	parent, err := s.Get(ctx, idOrSlug)
+	if err != nil {
+		if errors.Is(err, ErrNotExist) || errors.Is(err, ErrInvalidUUID) || errors.Is(err, ErrInvalidID) {
+			return Organization{}, fmt.Errorf("%w: %s", ErrInvalidParent, idOrSlug)
+		}
+		return Organization{}, err
+	}
+
+	allowed, err := s.relationService.CheckPermission(ctx, currentUser, namespace.Namespace{ID: schema.OrganizationNamespace}, parent.ID, action.Action{ID: schema.EditPermission})
+	if err != nil {
+		return Organization{}, err
+	}
+	if !allowed {
+		return Organization{}, fmt.Errorf("%w: %s", ErrInvalidParent, idOrSlug)
+	}
+	return parent, nil
+}
+
+func (s Service) addOrgToParent(ctx context.Context, org Organization, parent Organization) error {
+	rel := relation.RelationV2{
+		Object: relation.Object{
+			ID:          org.ID,
+			NamespaceID: schema.OrganizationNamespace,
+		},
+		Subject: relation.Subject{
+			ID:        parent.ID,
+			Namespace: schema.OrganizationNamespace,
+			RoleID:    schema.ParentRelationName,
+		},
+	}
+
+	if _, err := s.relationService.Create(ctx, rel); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s Service) addAdminToOrg(ctx context.Context, user user.User, org Organization) error {
 	rel := relation.RelationV2{
 		Object: relation.Object{
@@ -119,3 +220,82 @@ func (s Service) addAdminToOrg(ctx context.Context, user user.User, org Organiza
 	}
 	return nil
 }
+
+// Delete marks the organization as deleted, it is left out of lists and can be
+// restored until PurgeDeleted removes it along with its relations
+func (s Service) Delete(ctx context.Context, idOrSlug string) error {
+	org, err := s.Get(ctx, idOrSlug)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.Delete(ctx, org.ID); err != nil {
+		return err
+	}
+
+	return s.auditService.Record(ctx, audit.ActionDelete, auditResourceType, org.ID, org, nil)
+}
+
+// GetDeleted returns the organization only while it is deleted, it is
+// ErrNotDeleted otherwise
+func (s Service) GetDeleted(ctx context.Context, idOrSlug string) (Organization, error) {
+	org, err := s.get(ctx, idOrSlug)
+	if err != nil {
+		return Organization{}, err
+	}
+	if !org.IsDeleted() {
+		return Organization{}, ErrNotDeleted
+	}
+	return org, nil
+}
+
+func (s Service) Restore(ctx context.Context, idOrSlug string) (Organization, error) {
+	org, err := s.GetDeleted(ctx, idOrSlug)
+	if err != nil {
+		return Organization{}, err
+	}
+
+	if err := s.repository.Restore(ctx, org.ID); err != nil {
+		return Organization{}, err
+	}
+
+	restored, err := s.repository.GetByID(ctx, org.ID)
+	if err != nil {
+		return Organization{}, err
+	}
+
+	if err := s.auditService.Record(ctx, audit.ActionRestore, auditResourceType, restored.ID, org, restored); err != nil {
+		return Organization{}, err
+	}
+	return restored, nil
+}
+
+// PurgeDeleted permanently removes the organizations deleted longer than the
+// retention ago and their relations, it returns how many were purged
+func (s Service) PurgeDeleted(ctx context.Context, retention time.Duration) (int, error) {
+	deleted, err := s.repository.ListDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, org := range deleted {
+		if err := s.repository.Purge(ctx, org.ID); err != nil {
+			// still referenced, it is purged once the resources referring to it are
+			if errors.Is(err, ErrInUse) {
+				continue
+			}
+			return purged, err
+		}
+
+		if err := s.relationService.DeleteSubjectRelations(ctx, schema.OrganizationNamespace, org.ID); err != nil {
+			return purged, err
+		}
+
+		if err := s.auditService.Record(ctx, audit.ActionPurge, auditResourceType, org.ID, org, nil); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
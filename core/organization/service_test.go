@@ -0,0 +1,162 @@
+package organization_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepository struct {
+	organization.Repository
+	orgs map[string]organization.Organization
+}
+
+func (r memoryRepository) GetBySlug(ctx context.Context, slug string) (organization.Organization, error) {
+	org, ok := r.orgs[slug]
+	if !ok {
+		return organization.Organization{}, organization.ErrNotExist
+	}
+	return org, nil
+}
+
+func (r memoryRepository) Create(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	org.ID = org.Slug + "-id"
+	r.orgs[org.Slug] = org
+	return org, nil
+}
+
+type memoryRelationService struct {
+	organization.RelationService
+	relations []relation.RelationV2
+	// editors are the organizations the current user can edit
+	editors map[string]bool
+}
+
+func (s *memoryRelationService) Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	s.relations = append(s.relations, rel)
+	return rel, nil
+}
+
+func (s *memoryRelationService) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, act action.Action) (bool, error) {
+	return s.editors[resourceIdxa], nil
+}
+
+type currentUserService struct {
+	organization.UserService
+}
+
+func (s currentUserService) FetchCurrentUser(ctx context.Context) (user.User, error) {
+	return user.User{ID: "u1", Email: "u1@example.com"}, nil
+}
+
+type noopAuditService struct{}
+
+func (noopAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	return nil
+}
+
+func TestServiceCreateWithParent(t *testing.T) {
+	setup := func() (*organization.Service, *memoryRelationService) {
+		repository := memoryRepository{orgs: map[string]organization.Organization{
+			"odpf": {ID: "odpf-id", Name: "odpf", Slug: "odpf"},
+		}}
+		relations := &memoryRelationService{editors: map[string]bool{"odpf-id": true}}
+		return organization.NewService(repository, relations, currentUserService{}, noopAuditService{}), relations
+	}
+
+	t.Run("should create the organization under its parent", func(t *testing.T) {
+		s, relations := setup()
+
+		got, err := s.Create(context.Background(), organization.Organization{Name: "data", Slug: "data", ParentID: "odpf"})
+		assert.NoError(t, err)
+		assert.Equal(t, "odpf-id", got.ParentID)
+		assert.Contains(t, relations.relations, relation.RelationV2{
+			Object:  relation.Object{ID: "data-id", NamespaceID: schema.OrganizationNamespace},
+			Subject: relation.Subject{ID: "odpf-id", Namespace: schema.OrganizationNamespace, RoleID: schema.ParentRelationName},
+		})
+	})
+
+	t.Run("should return error if the parent doesn't exist", func(t *testing.T) {
+		s, _ := setup()
+
+		_, err := s.Create(context.Background(), organization.Organization{Name: "data", Slug: "data", ParentID: "gojek"})
+		assert.ErrorIs(t, err, organization.ErrInvalidParent)
+	})
+
+	t.Run("should return error if the current user can't edit the parent", func(t *testing.T) {
+		s, relations := setup()
+		relations.editors = nil
+
+		_, err := s.Create(context.Background(), organization.Organization{Name: "data", Slug: "data", ParentID: "odpf"})
+		assert.ErrorIs(t, err, organization.ErrInvalidParent)
+		assert.Empty(t, relations.relations)
+	})
+}
+
+func TestServiceGetDeleted(t *testing.T) {
+	repo := memoryRepository{orgs: map[string]organization.Organization{
+		"odpf":    {ID: "odpf-id", Slug: "odpf"},
+		"retired": {ID: "retired-id", Slug: "retired", DeletedAt: time.Now()},
+	}}
+	svc := organization.NewService(repo, &memoryRelationService{}, currentUserService{}, noopAuditService{})
+
+	t.Run("should return the deleted organization", func(t *testing.T) {
+		org, err := svc.GetDeleted(context.Background(), "retired")
+		assert.NoError(t, err)
+		assert.Equal(t, "retired-id", org.ID)
+	})
+
+	t.Run("should return error if the organization isn't deleted", func(t *testing.T) {
+		_, err := svc.GetDeleted(context.Background(), "odpf")
+		assert.ErrorIs(t, err, organization.ErrNotDeleted)
+	})
+}
+
+func TestServiceValidateCreate(t *testing.T) {
+	setup := func() (*organization.Service, memoryRepository, *memoryRelationService) {
+		repository := memoryRepository{orgs: map[string]organization.Organization{
+			"odpf": {ID: "odpf-id", Name: "odpf", Slug: "odpf"},
+		}}
+		relations := &memoryRelationService{editors: map[string]bool{"odpf-id": true}}
+		return organization.NewService(repository, relations, currentUserService{}, noopAuditService{}), repository, relations
+	}
+
+	t.Run("should return the organization without creating it", func(t *testing.T) {
+		s, repository, relations := setup()
+
+		got, err := s.ValidateCreate(context.Background(), organization.Organization{Name: "data", Slug: "data", ParentID: "odpf"})
+		assert.NoError(t, err)
+		assert.Equal(t, "odpf-id", got.ParentID)
+		assert.NotContains(t, repository.orgs, "data")
+		assert.Empty(t, relations.relations)
+	})
+
+	t.Run("should return error if the slug is taken", func(t *testing.T) {
+		s, _, _ := setup()
+
+		_, err := s.ValidateCreate(context.Background(), organization.Organization{Name: "odpf", Slug: "odpf"})
+		assert.ErrorIs(t, err, organization.ErrConflict)
+	})
+
+	t.Run("should return error if the name is empty", func(t *testing.T) {
+		s, _, _ := setup()
+
+		_, err := s.ValidateCreate(context.Background(), organization.Organization{Slug: "data"})
+		assert.ErrorIs(t, err, organization.ErrInvalidDetail)
+	})
+
+	t.Run("should return error if the parent doesn't exist", func(t *testing.T) {
+		s, _, _ := setup()
+
+		_, err := s.ValidateCreate(context.Background(), organization.Organization{Name: "data", Slug: "data", ParentID: "gojek"})
+		assert.ErrorIs(t, err, organization.ErrInvalidParent)
+	})
+}
@@ -0,0 +1,91 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/odpf/shield/core/user"
+)
+
+// ValidateCreate runs the checks Create and the repository make without
+// creating the organization, it returns the organization as it would be
+// created
+func (s Service) ValidateCreate(ctx context.Context, org Organization) (Organization, error) {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return Organization{}, fmt.Errorf("%w: %s", user.ErrInvalidEmail, err.Error())
+	}
+
+	if strings.TrimSpace(org.Name) == "" || strings.TrimSpace(org.Slug) == "" {
+		return Organization{}, ErrInvalidDetail
+	}
+
+	if org.ParentID != "" {
+		parent, err := s.parentOrg(ctx, currentUser, org.ParentID)
+		if err != nil {
+			return Organization{}, err
+		}
+		org.ParentID = parent.ID
+	}
+
+	if err := s.checkSlug(ctx, "", org.Slug); err != nil {
+		return Organization{}, err
+	}
+	return org, nil
+}
+
+// ValidateUpdate runs the checks Update and the repository make without
+// updating the organization, it returns the organization as it would be
+// updated
+func (s Service) ValidateUpdate(ctx context.Context, org Organization) (Organization, error) {
+	var current Organization
+	var err error
+	if org.ID != "" {
+		current, err = s.repository.GetByID(ctx, org.ID)
+	} else {
+		current, err = s.repository.GetBySlug(ctx, org.Slug)
+	}
+	if err != nil {
+		return Organization{}, err
+	}
+	if current.IsDeleted() {
+		return Organization{}, ErrNotExist
+	}
+	if org.Version != 0 && org.Version != current.Version {
+		return Organization{}, ErrVersionMismatch
+	}
+
+	if strings.TrimSpace(org.Name) == "" || strings.TrimSpace(org.Slug) == "" {
+		return Organization{}, ErrInvalidDetail
+	}
+	// an update by slug keeps the slug
+	if org.ID != "" && org.Slug != current.Slug {
+		if err := s.checkSlug(ctx, current.ID, org.Slug); err != nil {
+			return Organization{}, err
+		}
+	}
+
+	updated := current
+	updated.Name = org.Name
+	updated.Slug = org.Slug
+	updated.Metadata = org.Metadata
+	return updated, nil
+}
+
+// checkSlug returns ErrConflict when an organization other than the one of
+// the id has the slug, deleted ones included as they keep their slug until
+// they are purged
+func (s Service) checkSlug(ctx context.Context, id, slug string) error {
+	existing, err := s.repository.GetBySlug(ctx, slug)
+	switch {
+	case errors.Is(err, ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	case existing.ID != id:
+		return fmt.Errorf("%w: %s", ErrConflict, slug)
+	}
+	return nil
+}
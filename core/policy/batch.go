@@ -0,0 +1,141 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/odpf/shield/core/audit"
+)
+
+// MaxBatchSize is the most policies created by a single CreateBatch
+const MaxBatchSize = 1000
+
+// BatchFailure is a policy of a batch that wasn't created, by its index in
+// the batch
+type BatchFailure struct {
+	Index int
+	Err   error
+}
+
+// CreateBatch creates the policies like Create, in a single transaction of
+// the store and a single update of the grants in the authz engine. The
+// policies that can't be created, like those conflicting with an existing
+// policy, are reported as failures by their index and the others are
+// created. A policy which exists as is is returned like a created one. An
+// error is returned when the batch can't be written at all, nothing is
+// created then. The policies are returned in the order of the batch.
+func (s Service) CreateBatch(ctx context.Context, policies []Policy) ([]Policy, []BatchFailure, error) {
+	if len(policies) > MaxBatchSize {
+		return nil, nil, fmt.Errorf("%w: %d policies, at most %d", ErrBatchTooLarge, len(policies), MaxBatchSize)
+	}
+
+	existing, err := s.repository.List(ctx, Filters{})
+	if err != nil {
+		return nil, nil, err
+	}
+	// byGrant has the policies of the store and of the batch by their role,
+	// action and namespace, the ones of the batch with their index
+	type batched struct {
+		policy Policy
+		index  int
+	}
+	byGrant := map[[3]string]batched{}
+	for _, p := range existing {
+		byGrant[[3]string{p.RoleID, p.ActionID, p.NamespaceID}] = batched{policy: p, index: -1}
+	}
+
+	var failures []BatchFailure
+	// indexes has the index in the batch of the policies of valid
+	var valid []Policy
+	var indexes []int
+	for i, pol := range policies {
+		if err := validateEffect(&pol); err != nil {
+			failures = append(failures, BatchFailure{Index: i, Err: err})
+			continue
+		}
+		if err := s.validateCondition(&pol); err != nil {
+			failures = append(failures, BatchFailure{Index: i, Err: err})
+			continue
+		}
+		if err := s.validateOrgRolePolicy(ctx, pol); err != nil {
+			failures = append(failures, BatchFailure{Index: i, Err: err})
+			continue
+		}
+
+		key := [3]string{pol.RoleID, pol.ActionID, pol.NamespaceID}
+		if p, ok := byGrant[key]; ok {
+			if err := conflictOf(p.policy, pol, p.index); err != nil {
+				failures = append(failures, BatchFailure{Index: i, Err: err})
+				continue
+			}
+		} else {
+			byGrant[key] = batched{policy: pol, index: i}
+		}
+		valid = append(valid, pol)
+		indexes = append(indexes, i)
+	}
+	if len(valid) == 0 {
+		return nil, failures, nil
+	}
+
+	before, err := s.grants(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created, storeFailures, err := s.repository.CreateBatch(ctx, valid)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, f := range storeFailures {
+		failures = append(failures, BatchFailure{Index: indexes[f.Index], Err: f.Err})
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Index < failures[j].Index })
+
+	// the policies which existed before are neither rolled back nor audited
+	existed := map[string]bool{}
+	for _, p := range existing {
+		existed[p.ID] = true
+	}
+	var added []Policy
+	for _, p := range created {
+		if !existed[p.ID] {
+			existed[p.ID] = true
+			added = append(added, p)
+		}
+	}
+
+	if err := s.syncGrants(ctx, before); err != nil {
+		for _, p := range added {
+			if rbErr := s.repository.Delete(ctx, p.ID); rbErr != nil {
+				return nil, nil, fmt.Errorf("%w: %s, deleting policy: %s", ErrUpdatingAuthz, err.Error(), rbErr.Error())
+			}
+		}
+		return nil, nil, fmt.Errorf("%w: %s", ErrUpdatingAuthz, err.Error())
+	}
+
+	for _, p := range added {
+		if err := s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, p.ID, nil, p); err != nil {
+			return nil, nil, err
+		}
+	}
+	return created, failures, nil
+}
+
+// conflictOf returns the conflict of a policy of the batch with the policy
+// of the same role and action, which is in the store when index is -1 and
+// earlier in the batch otherwise
+func conflictOf(p, pol Policy, index int) error {
+	ref := "policy " + p.ID
+	if index >= 0 {
+		ref = fmt.Sprintf("policy %d of the batch", index)
+	}
+	if p.Effect != pol.Effect {
+		return fmt.Errorf("%w: %s %s has the same role and action", ErrConflict, p.Effect, ref)
+	}
+	if p.Condition != pol.Condition {
+		return fmt.Errorf("%w: %s has the same role and action under another condition", ErrConflict, ref)
+	}
+	return nil
+}
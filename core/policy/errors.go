@@ -3,9 +3,13 @@ package policy
 import "errors"
 
 var (
-	ErrNotExist      = errors.New("policies doesn't exist")
-	ErrInvalidUUID   = errors.New("invalid syntax of uuid")
-	ErrInvalidID     = errors.New("policy id is invalid")
-	ErrConflict      = errors.New("policy already exist")
-	ErrInvalidDetail = errors.New("invalid policy detail")
+	ErrNotExist        = errors.New("policies doesn't exist")
+	ErrInvalidUUID     = errors.New("invalid syntax of uuid")
+	ErrInvalidID       = errors.New("policy id is invalid")
+	ErrConflict        = errors.New("policy already exist")
+	ErrInvalidDetail   = errors.New("invalid policy detail")
+	ErrUpdatingAuthz   = errors.New("error while updating policy in authz engine")
+	ErrInUse           = errors.New("policy is still referenced by other resources")
+	ErrBatchTooLarge   = errors.New("batch has too many policies")
+	ErrVersionMismatch = errors.New("policy was updated since the version")
 )
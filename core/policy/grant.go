@@ -0,0 +1,175 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+)
+
+// grant is what a policy gives in the authz engine, an action on a
+// namespace to a role or, for a deny policy, its exclusion. The grants of a
+// conditional policy are under its condition, which the authz engine keeps
+// by the id of the policy.
+type grant struct {
+	roleID      string
+	namespaceID string
+	actionID    string
+	effect      string
+	condition   string
+	policyID    string
+}
+
+func grantOf(pol Policy) grant {
+	g := grant{roleID: pol.RoleID, namespaceID: pol.NamespaceID, actionID: pol.ActionID, effect: pol.Effect}
+	if pol.Condition != "" {
+		g.condition, g.policyID = pol.Condition, pol.ID
+	}
+	return g
+}
+
+// SyncGrants runs the change to the roles and updates the authz engine with
+// the grants the roles including other roles gain or lose by it
+func (s Service) SyncGrants(ctx context.Context, change func() error) error {
+	before, err := s.grants(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := change(); err != nil {
+		return err
+	}
+
+	if err := s.syncGrants(ctx, before); err != nil {
+		return fmt.Errorf("%w: %s", ErrUpdatingAuthz, err.Error())
+	}
+	return nil
+}
+
+// RestoreGrants gives every grant of the stored policies in the authz
+// engine, the schema written from the namespace configs only has the grants
+// of their policies
+func (s Service) RestoreGrants(ctx context.Context) error {
+	grants, err := s.grants(ctx)
+	if err != nil {
+		return err
+	}
+	if len(grants) == 0 {
+		return nil
+	}
+
+	if err := s.authzRepository.Add(ctx, grants); err != nil {
+		return fmt.Errorf("%w: %s", ErrUpdatingAuthz, err.Error())
+	}
+	return nil
+}
+
+// grants returns the grants of the stored policies, a policy grants its
+// action to its role and to every role including it, directly or through
+// other roles. The including roles are denied the action of a deny policy
+// the same way.
+func (s Service) grants(ctx context.Context) ([]Policy, error) {
+	roles, err := s.roleService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	includedBy := map[string][]string{}
+	for _, rl := range roles {
+		for _, included := range rl.Includes {
+			includedBy[included] = append(includedBy[included], rl.ID)
+		}
+	}
+
+	policies, err := s.repository.List(ctx, Filters{})
+	if err != nil {
+		return nil, err
+	}
+
+	var grants []Policy
+	seen := map[grant]bool{}
+	for _, pol := range policies {
+		queue := []string{pol.RoleID}
+		for len(queue) > 0 {
+			g := pol
+			g.RoleID, queue = queue[0], queue[1:]
+			if seen[grantOf(g)] {
+				continue
+			}
+			seen[grantOf(g)] = true
+			grants = append(grants, g)
+			queue = append(queue, includedBy[g.RoleID]...)
+		}
+	}
+	return grants, nil
+}
+
+// syncGrants revokes the grants that were given before and no longer are,
+// then gives the new ones. The revoked grants are restored if the new ones
+// can't be given so access is not silently lost.
+func (s Service) syncGrants(ctx context.Context, before []Policy) error {
+	after, err := s.grants(ctx)
+	if err != nil {
+		return err
+	}
+
+	toRemove, err := s.applyGrants(ctx, before, after)
+	if err != nil && len(toRemove) > 0 {
+		if rbErr := s.authzRepository.Add(ctx, toRemove); rbErr != nil {
+			return fmt.Errorf("%s, restoring previous grant: %s", err.Error(), rbErr.Error())
+		}
+	}
+	return err
+}
+
+// applyGrants revokes the grants of before which are not in after and gives
+// the ones of after which are not in before, it returns the revoked grants
+func (s Service) applyGrants(ctx context.Context, before, after []Policy) ([]Policy, error) {
+	toRemove, toAdd := diffGrants(before, after), diffGrants(after, before)
+	if len(toRemove) > 0 {
+		if err := s.authzRepository.Remove(ctx, toRemove); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(toAdd) > 0 {
+		if err := s.authzRepository.Add(ctx, toAdd); err != nil {
+			return toRemove, err
+		}
+	}
+	return toRemove, nil
+}
+
+// resyncGrants gives every grant of the stored policies and revokes the
+// given ones none of them gives anymore. It is run once a failed change is
+// reverted in the store, the grants are recomputed from the whole policy set
+// so a grant another policy still gives is never revoked.
+func (s Service) resyncGrants(ctx context.Context, given []Policy) error {
+	current, err := s.grants(ctx)
+	if err != nil {
+		return err
+	}
+
+	if toRemove := diffGrants(given, current); len(toRemove) > 0 {
+		if err := s.authzRepository.Remove(ctx, toRemove); err != nil {
+			return err
+		}
+	}
+	if len(current) > 0 {
+		return s.authzRepository.Add(ctx, current)
+	}
+	return nil
+}
+
+// diffGrants returns the grants of a which are not in b
+func diffGrants(a, b []Policy) []Policy {
+	inB := map[grant]bool{}
+	for _, pol := range b {
+		inB[grantOf(pol)] = true
+	}
+
+	var diff []Policy
+	for _, pol := range a {
+		if !inB[grantOf(pol)] {
+			diff = append(diff, pol)
+		}
+	}
+	return diff
+}
@@ -7,22 +7,63 @@ import (
 
 type Repository interface {
 	Get(ctx context.Context, id string) (Policy, error)
-	List(ctx context.Context) ([]Policy, error)
+	List(ctx context.Context, flt Filters) ([]Policy, error)
+	ListExpanded(ctx context.Context, flt Filters) ([]ExpandedPolicy, error)
 	Create(ctx context.Context, pol Policy) (string, error)
+	// CreateBatch creates the policies in a single transaction and returns
+	// them with their ids, the policies the store refuses are left out and
+	// reported by their index
+	CreateBatch(ctx context.Context, policies []Policy) ([]Policy, []BatchFailure, error)
 	Update(ctx context.Context, pol Policy) (string, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type AuthzRepository interface {
 	Add(ctx context.Context, policies []Policy) error
+	Remove(ctx context.Context, policies []Policy) error
+	// ValidateCondition returns an error if the condition can't be
+	// evaluated by the authz engine
+	ValidateCondition(condition string) error
+	// AddObject makes the conditional policies apply to a new object of
+	// their namespace, the objects existing when a policy is added are
+	// taken care of by Add
+	AddObject(ctx context.Context, policies []Policy, objectID string) error
 }
 
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
 type Policy struct {
 	ID          string
 	RoleID      string
 	NamespaceID string
 	ActionID    string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	// Effect is either allow or deny, the role of a deny policy doesn't
+	// have the action even if another policy allows it
+	Effect string
+	// Condition is an expression over the context of a check, the policy
+	// only applies to the checks meeting it. See relation.CheckContext for
+	// the parameters it can use.
+	Condition string
+	// Name and Description are optional human readable labels
+	Name        string
+	Description string
+	// Version is incremented on every update, an update made with a
+	// version is refused once the policy was updated since
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ExpandedPolicy is a policy along with the human readable
+// names of the role, action and namespace it refers to
+type ExpandedPolicy struct {
+	Policy
+	RoleName      string
+	ActionName    string
+	NamespaceName string
 }
 
 type Filters struct {
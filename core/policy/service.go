@@ -2,15 +2,46 @@ package policy
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/role"
 )
 
+const auditResourceType = "policy"
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+type RoleService interface {
+	Get(ctx context.Context, id string) (role.Role, error)
+	List(ctx context.Context) ([]role.Role, error)
+}
+
+type ActionService interface {
+	Get(ctx context.Context, id string) (action.Action, error)
+}
+
 type Service struct {
-	repository Repository
+	repository      Repository
+	authzRepository AuthzRepository
+	roleService     RoleService
+	actionService   ActionService
+	auditService    AuditService
 }
 
-func NewService(repository Repository) *Service {
+func NewService(repository Repository, authzRepository AuthzRepository, roleService RoleService, actionService ActionService, auditService AuditService) *Service {
 	return &Service{
-		repository: repository,
+		repository:      repository,
+		authzRepository: authzRepository,
+		roleService:     roleService,
+		actionService:   actionService,
+		auditService:    auditService,
 	}
 }
 
@@ -18,31 +49,324 @@ func (s Service) Get(ctx context.Context, id string) (Policy, error) {
 	return s.repository.Get(ctx, id)
 }
 
-func (s Service) List(ctx context.Context) ([]Policy, error) {
-	return s.repository.List(ctx)
+// List returns the policies matching the filters, with expand the names of the
+// role, action and namespace of every policy are fetched along in a single query
+func (s Service) List(ctx context.Context, flt Filters, expand bool) ([]ExpandedPolicy, error) {
+	if expand {
+		return s.repository.ListExpanded(ctx, flt)
+	}
+
+	policies, err := s.repository.List(ctx, flt)
+	if err != nil {
+		return []ExpandedPolicy{}, err
+	}
+
+	expanded := make([]ExpandedPolicy, 0, len(policies))
+	for _, p := range policies {
+		expanded = append(expanded, ExpandedPolicy{Policy: p})
+	}
+	return expanded, nil
 }
 
-func (s Service) Create(ctx context.Context, policy Policy) ([]Policy, error) {
-	if _, err := s.repository.Create(ctx, policy); err != nil {
+// Create persists the policy and gives its grant in the authz engine, an
+// existing policy of the same role, action and namespace is kept as is and
+// can't be created with the opposite effect or another condition
+func (s Service) Create(ctx context.Context, pol Policy) ([]Policy, error) {
+	pol, exists, err := s.validateCreate(ctx, pol)
+	if err != nil {
+		return []Policy{}, err
+	}
+	if exists {
+		return s.repository.List(ctx, Filters{})
+	}
+
+	before, err := s.grants(ctx)
+	if err != nil {
 		return []Policy{}, err
 	}
-	policies, err := s.repository.List(ctx)
+
+	pol.ID, err = s.repository.Create(ctx, pol)
 	if err != nil {
 		return []Policy{}, err
 	}
 
-	return policies, err
+	after, err := s.grants(ctx)
+	if err == nil {
+		_, err = s.applyGrants(ctx, before, after)
+	}
+	if err != nil {
+		if rbErr := s.repository.Delete(ctx, pol.ID); rbErr != nil {
+			return []Policy{}, fmt.Errorf("%w: %s, deleting policy: %s", ErrUpdatingAuthz, err.Error(), rbErr.Error())
+		}
+		if rbErr := s.resyncGrants(ctx, append(before, after...)); rbErr != nil {
+			return []Policy{}, fmt.Errorf("%w: %s, restoring grants: %s", ErrUpdatingAuthz, err.Error(), rbErr.Error())
+		}
+		return []Policy{}, fmt.Errorf("%w: %s", ErrUpdatingAuthz, err.Error())
+	}
+
+	if err := s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, pol.ID, nil, pol); err != nil {
+		return []Policy{}, err
+	}
+
+	return s.repository.List(ctx, Filters{})
 }
 
+// Store persists the policy without giving its grant in the authz engine,
+// the grants of the policies of the namespace configs are part of the
+// schema generated from them
+func (s Service) Store(ctx context.Context, pol Policy) (Policy, error) {
+	if err := validateEffect(&pol); err != nil {
+		return Policy{}, err
+	}
+
+	policyID, err := s.repository.Create(ctx, pol)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	pol.ID = policyID
+	if err := s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, pol.ID, nil, pol); err != nil {
+		return Policy{}, err
+	}
+	return pol, nil
+}
+
+// Update persists the policy and, if the grant changed, revokes the grants of
+// the previous version in the authz engine before granting the new one. The
+// condition is replaced like the role and action, an empty one removes it.
+// The stored policy is reverted if the authz engine can't be updated and the
+// grants are recomputed from the whole policy set.
 func (s Service) Update(ctx context.Context, pol Policy) ([]Policy, error) {
+	oldPolicy, pol, err := s.validateUpdate(ctx, pol)
+	if err != nil {
+		return []Policy{}, err
+	}
+
+	before, err := s.grants(ctx)
+	if err != nil {
+		return []Policy{}, err
+	}
+
 	if _, err := s.repository.Update(ctx, pol); err != nil {
 		return []Policy{}, err
 	}
 
-	policies, err := s.repository.List(ctx)
+	if grantChanged(oldPolicy, pol) {
+		after, err := s.grants(ctx)
+		if err == nil {
+			_, err = s.applyGrants(ctx, before, after)
+		}
+		if err != nil {
+			// the update incremented the version the policy was read at
+			revert := oldPolicy
+			revert.Version = 0
+			if _, rbErr := s.repository.Update(ctx, revert); rbErr != nil {
+				return []Policy{}, fmt.Errorf("%w: %s, reverting policy: %s", ErrUpdatingAuthz, err.Error(), rbErr.Error())
+			}
+			if rbErr := s.resyncGrants(ctx, append(before, after...)); rbErr != nil {
+				return []Policy{}, fmt.Errorf("%w: %s, restoring grants: %s", ErrUpdatingAuthz, err.Error(), rbErr.Error())
+			}
+			return []Policy{}, fmt.Errorf("%w: %s", ErrUpdatingAuthz, err.Error())
+		}
+	}
+
+	if err := s.auditService.Record(ctx, audit.ActionUpdate, auditResourceType, pol.ID, oldPolicy, pol); err != nil {
+		return []Policy{}, err
+	}
+
+	policies, err := s.repository.List(ctx, Filters{})
 	if err != nil {
 		return []Policy{}, err
 	}
 
 	return policies, err
 }
+
+// ValidateCreate runs the checks of Create without creating the policy, it
+// returns the policy as it would be created or, when a policy of the same
+// role, action and namespace exists, the existing one Create keeps
+func (s Service) ValidateCreate(ctx context.Context, pol Policy) (Policy, error) {
+	pol, _, err := s.validateCreate(ctx, pol)
+	return pol, err
+}
+
+// validateCreate returns the policy as it is created and whether a policy
+// of the same role, action and namespace exists, the existing one then
+func (s Service) validateCreate(ctx context.Context, pol Policy) (Policy, bool, error) {
+	if err := validateEffect(&pol); err != nil {
+		return Policy{}, false, err
+	}
+
+	if err := s.validateCondition(&pol); err != nil {
+		return Policy{}, false, err
+	}
+
+	if err := s.validateOrgRolePolicy(ctx, pol); err != nil {
+		return Policy{}, false, err
+	}
+
+	existing, err := s.repository.List(ctx, Filters{NamespaceID: pol.NamespaceID})
+	if err != nil {
+		return Policy{}, false, err
+	}
+	for _, p := range existing {
+		if p.RoleID == pol.RoleID && p.ActionID == pol.ActionID {
+			if p.Effect != pol.Effect {
+				return Policy{}, false, fmt.Errorf("%w: %s policy %s has the same role and action", ErrConflict, p.Effect, p.ID)
+			}
+			if p.Condition != pol.Condition {
+				return Policy{}, false, fmt.Errorf("%w: policy %s has the same role and action under another condition", ErrConflict, p.ID)
+			}
+			return p, true, nil
+		}
+	}
+	return pol, false, nil
+}
+
+// ValidateUpdate runs the checks of Update without updating the policy, it
+// returns the policy as it would be updated
+func (s Service) ValidateUpdate(ctx context.Context, pol Policy) (Policy, error) {
+	_, pol, err := s.validateUpdate(ctx, pol)
+	return pol, err
+}
+
+// validateUpdate returns the stored policy and the policy as it is updated
+func (s Service) validateUpdate(ctx context.Context, pol Policy) (Policy, Policy, error) {
+	oldPolicy, err := s.repository.Get(ctx, pol.ID)
+	if err != nil {
+		return Policy{}, Policy{}, err
+	}
+	if pol.Version != 0 && pol.Version != oldPolicy.Version {
+		return Policy{}, Policy{}, ErrVersionMismatch
+	}
+
+	// labels are optional, an update without them keeps the existing ones
+	if pol.Name == "" {
+		pol.Name = oldPolicy.Name
+	}
+	if pol.Description == "" {
+		pol.Description = oldPolicy.Description
+	}
+	if pol.Effect == "" {
+		pol.Effect = oldPolicy.Effect
+	}
+	if err := validateEffect(&pol); err != nil {
+		return Policy{}, Policy{}, err
+	}
+
+	if err := s.validateCondition(&pol); err != nil {
+		return Policy{}, Policy{}, err
+	}
+
+	if err := s.validateOrgRolePolicy(ctx, pol); err != nil {
+		return Policy{}, Policy{}, err
+	}
+	return oldPolicy, pol, nil
+}
+
+// validateOrgRolePolicy checks the custom role of an organization is only
+// given the actions of the namespace it is defined on
+func (s Service) validateOrgRolePolicy(ctx context.Context, pol Policy) error {
+	rl, err := s.roleService.Get(ctx, pol.RoleID)
+	if err != nil {
+		if errors.Is(err, role.ErrNotExist) || errors.Is(err, role.ErrInvalidID) {
+			return fmt.Errorf("%w: %s", ErrInvalidDetail, err.Error())
+		}
+		return err
+	}
+	if rl.OrgID == "" {
+		return nil
+	}
+
+	act, err := s.actionService.Get(ctx, pol.ActionID)
+	if err != nil {
+		if errors.Is(err, action.ErrNotExist) || errors.Is(err, action.ErrInvalidID) {
+			return fmt.Errorf("%w: %s", ErrInvalidDetail, err.Error())
+		}
+		return err
+	}
+	if act.NamespaceID != rl.NamespaceID || pol.NamespaceID != rl.NamespaceID {
+		return fmt.Errorf("%w: action %s is not in namespace %s of role %s", ErrInvalidDetail, act.ID, rl.NamespaceID, rl.ID)
+	}
+	return nil
+}
+
+func grantChanged(oldPolicy, newPolicy Policy) bool {
+	return oldPolicy.RoleID != newPolicy.RoleID ||
+		oldPolicy.ActionID != newPolicy.ActionID ||
+		oldPolicy.NamespaceID != newPolicy.NamespaceID ||
+		oldPolicy.Effect != newPolicy.Effect ||
+		oldPolicy.Condition != newPolicy.Condition
+}
+
+// validateEffect defaults the effect of the policy to allow
+func validateEffect(pol *Policy) error {
+	switch pol.Effect {
+	case "":
+		pol.Effect = EffectAllow
+	case EffectAllow, EffectDeny:
+	default:
+		return fmt.Errorf("%w: effect should be %s or %s", ErrInvalidDetail, EffectAllow, EffectDeny)
+	}
+	return nil
+}
+
+// validateCondition checks the authz engine can evaluate the condition of
+// the policy. Conditions are only supported on the namespaces of resources,
+// the resources being the objects AddObject is called for.
+func (s Service) validateCondition(pol *Policy) error {
+	pol.Condition = strings.TrimSpace(pol.Condition)
+	if pol.Condition == "" {
+		return nil
+	}
+	if namespace.IsSystemNamespaceID(pol.NamespaceID) {
+		return fmt.Errorf("%w: conditions are only supported on the namespaces of resources", ErrInvalidDetail)
+	}
+	if err := s.authzRepository.ValidateCondition(pol.Condition); err != nil {
+		return fmt.Errorf("%w: condition: %s", ErrInvalidDetail, err.Error())
+	}
+	return nil
+}
+
+// AddObject makes the conditional policies of the namespace apply to a
+// new object of it
+func (s Service) AddObject(ctx context.Context, namespaceID, objectID string) error {
+	policies, err := s.repository.List(ctx, Filters{NamespaceID: namespaceID})
+	if err != nil {
+		return err
+	}
+
+	var conditional []Policy
+	for _, pol := range policies {
+		if pol.Condition != "" {
+			conditional = append(conditional, pol)
+		}
+	}
+	if len(conditional) == 0 {
+		return nil
+	}
+	return s.authzRepository.AddObject(ctx, conditional, objectID)
+}
+
+// Delete removes the policy and revokes its grant in the authz engine
+func (s Service) Delete(ctx context.Context, id string) error {
+	pol, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	before, err := s.grants(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.syncGrants(ctx, before); err != nil {
+		return fmt.Errorf("%w: %s", ErrUpdatingAuthz, err.Error())
+	}
+
+	return s.auditService.Record(ctx, audit.ActionDelete, auditResourceType, pol.ID, pol, nil)
+}
@@ -0,0 +1,742 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/role"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepository struct {
+	policies map[string]policy.Policy
+	// names of the roles, actions and namespaces by id
+	names map[string]string
+}
+
+func (r *memoryRepository) Get(ctx context.Context, id string) (policy.Policy, error) {
+	p, ok := r.policies[id]
+	if !ok {
+		return policy.Policy{}, policy.ErrNotExist
+	}
+	return p, nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, flt policy.Filters) ([]policy.Policy, error) {
+	var policies []policy.Policy
+	for _, p := range r.policies {
+		if flt.NamespaceID != "" && p.NamespaceID != flt.NamespaceID {
+			continue
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+func (r *memoryRepository) ListExpanded(ctx context.Context, flt policy.Filters) ([]policy.ExpandedPolicy, error) {
+	var policies []policy.ExpandedPolicy
+	for _, p := range r.policies {
+		if flt.NamespaceID != "" && p.NamespaceID != flt.NamespaceID {
+			continue
+		}
+		policies = append(policies, policy.ExpandedPolicy{
+			Policy:        p,
+			RoleName:      r.names[p.RoleID],
+			ActionName:    r.names[p.ActionID],
+			NamespaceName: r.names[p.NamespaceID],
+		})
+	}
+	return policies, nil
+}
+
+func (r *memoryRepository) Create(ctx context.Context, pol policy.Policy) (string, error) {
+	r.policies[pol.ID] = pol
+	return pol.ID, nil
+}
+
+// CreateBatch refuses the policies without an action like the stores do
+func (r *memoryRepository) CreateBatch(ctx context.Context, policies []policy.Policy) ([]policy.Policy, []policy.BatchFailure, error) {
+	var created []policy.Policy
+	var failures []policy.BatchFailure
+	for i, pol := range policies {
+		if pol.ActionID == "" {
+			failures = append(failures, policy.BatchFailure{Index: i, Err: policy.ErrInvalidDetail})
+			continue
+		}
+		for _, p := range r.policies {
+			if p.RoleID == pol.RoleID && p.ActionID == pol.ActionID && p.NamespaceID == pol.NamespaceID {
+				pol.ID = p.ID
+			}
+		}
+		r.policies[pol.ID] = pol
+		created = append(created, pol)
+	}
+	return created, failures, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, pol policy.Policy) (string, error) {
+	if _, ok := r.policies[pol.ID]; !ok {
+		return "", policy.ErrNotExist
+	}
+	r.policies[pol.ID] = pol
+	return pol.ID, nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := r.policies[id]; !ok {
+		return policy.ErrNotExist
+	}
+	delete(r.policies, id)
+	return nil
+}
+
+// memoryAuthz grants or denies an action on a namespace to a role
+type memoryAuthz struct {
+	grants map[policy.Policy]bool
+	addErr error
+	// objects has the policies whose conditions apply to an object by its id
+	objects map[string][]string
+}
+
+func grantKey(p policy.Policy) policy.Policy {
+	return policy.Policy{RoleID: p.RoleID, NamespaceID: p.NamespaceID, ActionID: p.ActionID, Effect: p.Effect}
+}
+
+func (a *memoryAuthz) Add(ctx context.Context, policies []policy.Policy) error {
+	if a.addErr != nil {
+		err := a.addErr
+		a.addErr = nil
+		return err
+	}
+	for _, p := range policies {
+		a.grants[grantKey(p)] = true
+	}
+	return nil
+}
+
+func (a *memoryAuthz) Remove(ctx context.Context, policies []policy.Policy) error {
+	for _, p := range policies {
+		delete(a.grants, grantKey(p))
+	}
+	return nil
+}
+
+// ValidateCondition only accepts comparisons
+func (a *memoryAuthz) ValidateCondition(condition string) error {
+	if !strings.Contains(condition, "==") {
+		return errors.New("condition should be a boolean expression")
+	}
+	return nil
+}
+
+func (a *memoryAuthz) AddObject(ctx context.Context, policies []policy.Policy, objectID string) error {
+	if a.objects == nil {
+		a.objects = map[string][]string{}
+	}
+	for _, p := range policies {
+		a.objects[objectID] = append(a.objects[objectID], p.ID)
+	}
+	return nil
+}
+
+// memoryAudit keeps the changes recorded by the service
+type memoryAudit struct {
+	actions []string
+}
+
+func (a *memoryAudit) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	a.actions = append(a.actions, action+" "+resourceType+" "+resourceID)
+	return nil
+}
+
+// memoryRoles has the custom roles, every other role is a system role
+type memoryRoles map[string]role.Role
+
+func (r memoryRoles) Get(ctx context.Context, id string) (role.Role, error) {
+	if rl, ok := r[id]; ok {
+		return rl, nil
+	}
+	return role.Role{ID: id}, nil
+}
+
+func (r memoryRoles) List(ctx context.Context) ([]role.Role, error) {
+	var roles []role.Role
+	for _, rl := range r {
+		roles = append(roles, rl)
+	}
+	return roles, nil
+}
+
+type memoryActions map[string]action.Action
+
+func (a memoryActions) Get(ctx context.Context, id string) (action.Action, error) {
+	act, ok := a[id]
+	if !ok {
+		return action.Action{}, action.ErrNotExist
+	}
+	return act, nil
+}
+
+// authorizes reports whether the role is allowed the action and not denied it
+func (a *memoryAuthz) authorizes(roleID, namespaceID, actionID string) bool {
+	grant := policy.Policy{RoleID: roleID, NamespaceID: namespaceID, ActionID: actionID}
+	allow, deny := grant, grant
+	allow.Effect, deny.Effect = policy.EffectAllow, policy.EffectDeny
+	return a.grants[allow] && !a.grants[deny]
+}
+
+func TestServiceCreate(t *testing.T) {
+	ownerCanDelete := policy.Policy{
+		ID:          "policy-1",
+		RoleID:      "shield/project:owner",
+		NamespaceID: "shield/project",
+		ActionID:    "delete.shield/project",
+		Effect:      policy.EffectAllow,
+	}
+
+	t.Run("should grant the action and default the effect to allow", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{}}
+		svc := policy.NewService(repo, authz, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		created := ownerCanDelete
+		created.Effect = ""
+		_, err := svc.Create(context.Background(), created)
+		assert.NoError(t, err)
+		assert.Equal(t, policy.EffectAllow, repo.policies["policy-1"].Effect)
+		assert.True(t, authz.authorizes("shield/project:owner", "shield/project", "delete.shield/project"))
+	})
+
+	t.Run("should deny the action even if another policy allows it", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{ownerCanDelete.ID: ownerCanDelete}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{grantKey(ownerCanDelete): true}}
+		roles := memoryRoles{
+			"shield/project:owner":   {ID: "shield/project:owner"},
+			"shield/project:auditor": {ID: "shield/project:auditor"},
+			"shield/project:admin":   {ID: "shield/project:admin", Includes: []string{"shield/project:owner", "shield/project:auditor"}},
+		}
+		authz.grants[policy.Policy{RoleID: "shield/project:admin", NamespaceID: "shield/project", ActionID: "delete.shield/project", Effect: policy.EffectAllow}] = true
+		svc := policy.NewService(repo, authz, roles, memoryActions{}, &memoryAudit{})
+
+		_, err := svc.Create(context.Background(), policy.Policy{
+			ID:          "policy-2",
+			RoleID:      "shield/project:auditor",
+			NamespaceID: "shield/project",
+			ActionID:    "delete.shield/project",
+			Effect:      policy.EffectDeny,
+		})
+		assert.NoError(t, err)
+		assert.True(t, authz.authorizes("shield/project:owner", "shield/project", "delete.shield/project"))
+		assert.False(t, authz.authorizes("shield/project:admin", "shield/project", "delete.shield/project"))
+	})
+
+	t.Run("should return error if the policy exists with the opposite effect", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{ownerCanDelete.ID: ownerCanDelete}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		denied := ownerCanDelete
+		denied.ID = "policy-2"
+		denied.Effect = policy.EffectDeny
+		_, err := svc.Create(context.Background(), denied)
+		assert.ErrorIs(t, err, policy.ErrConflict)
+		assert.NotContains(t, repo.policies, "policy-2")
+	})
+
+	t.Run("should delete the policy if its grant can't be written", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{}, addErr: errors.New("spicedb unavailable")}
+		svc := policy.NewService(repo, authz, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		_, err := svc.Create(context.Background(), ownerCanDelete)
+		assert.ErrorIs(t, err, policy.ErrUpdatingAuthz)
+		assert.Empty(t, repo.policies)
+	})
+
+	t.Run("should return error if the effect is unknown", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		unknown := ownerCanDelete
+		unknown.Effect = "block"
+		_, err := svc.Create(context.Background(), unknown)
+		assert.ErrorIs(t, err, policy.ErrInvalidDetail)
+	})
+}
+
+func TestServiceValidateCreate(t *testing.T) {
+	ownerCanDelete := policy.Policy{
+		ID:          "policy-1",
+		RoleID:      "shield/project:owner",
+		NamespaceID: "shield/project",
+		ActionID:    "delete.shield/project",
+		Effect:      policy.EffectAllow,
+	}
+
+	t.Run("should return the policy without creating it or granting the action", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{}}
+		svc := policy.NewService(repo, authz, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		validated := ownerCanDelete
+		validated.Effect = ""
+		got, err := svc.ValidateCreate(context.Background(), validated)
+		assert.NoError(t, err)
+		assert.Equal(t, policy.EffectAllow, got.Effect)
+		assert.Empty(t, repo.policies)
+		assert.False(t, authz.authorizes("shield/project:owner", "shield/project", "delete.shield/project"))
+	})
+
+	t.Run("should return error if the policy exists with the opposite effect", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{ownerCanDelete.ID: ownerCanDelete}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		denied := ownerCanDelete
+		denied.ID = "policy-2"
+		denied.Effect = policy.EffectDeny
+		_, err := svc.ValidateCreate(context.Background(), denied)
+		assert.ErrorIs(t, err, policy.ErrConflict)
+	})
+}
+
+func TestServiceCreateBatch(t *testing.T) {
+	ownerCanDelete := policy.Policy{
+		ID:          "policy-1",
+		RoleID:      "shield/project:owner",
+		NamespaceID: "shield/project",
+		ActionID:    "delete.shield/project",
+		Effect:      policy.EffectAllow,
+	}
+	viewerCanGet := policy.Policy{
+		ID:          "policy-2",
+		RoleID:      "shield/project:viewer",
+		NamespaceID: "shield/project",
+		ActionID:    "get.shield/project",
+	}
+
+	t.Run("should create the valid policies and report the others by their index", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{ownerCanDelete.ID: ownerCanDelete}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{grantKey(ownerCanDelete): true}}
+		audit := &memoryAudit{}
+		svc := policy.NewService(repo, authz, memoryRoles{}, memoryActions{}, audit)
+
+		denied := ownerCanDelete
+		denied.ID, denied.Effect = "policy-3", policy.EffectDeny
+		noAction := viewerCanGet
+		noAction.ID, noAction.ActionID = "policy-4", ""
+		created, failures, err := svc.CreateBatch(context.Background(), []policy.Policy{ownerCanDelete, denied, viewerCanGet, noAction})
+		assert.NoError(t, err)
+
+		assert.Len(t, created, 2)
+		assert.Equal(t, "policy-1", created[0].ID)
+		assert.Equal(t, "policy-2", created[1].ID)
+		assert.Equal(t, policy.EffectAllow, created[1].Effect)
+		assert.Len(t, failures, 2)
+		assert.Equal(t, 1, failures[0].Index)
+		assert.ErrorIs(t, failures[0].Err, policy.ErrConflict)
+		assert.Equal(t, 3, failures[1].Index)
+		assert.ErrorIs(t, failures[1].Err, policy.ErrInvalidDetail)
+
+		assert.True(t, authz.authorizes("shield/project:viewer", "shield/project", "get.shield/project"))
+		assert.Equal(t, []string{"create policy policy-2"}, audit.actions)
+	})
+
+	t.Run("should report the policies conflicting with an earlier one of the batch", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		denied := viewerCanGet
+		denied.ID, denied.Effect = "policy-3", policy.EffectDeny
+		_, failures, err := svc.CreateBatch(context.Background(), []policy.Policy{viewerCanGet, denied})
+		assert.NoError(t, err)
+		assert.Len(t, failures, 1)
+		assert.Equal(t, 1, failures[0].Index)
+		assert.ErrorIs(t, failures[0].Err, policy.ErrConflict)
+	})
+
+	t.Run("should delete the created policies if their grants can't be written", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{ownerCanDelete.ID: ownerCanDelete}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{}, addErr: errors.New("spicedb unavailable")}
+		svc := policy.NewService(repo, authz, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		_, _, err := svc.CreateBatch(context.Background(), []policy.Policy{ownerCanDelete, viewerCanGet})
+		assert.ErrorIs(t, err, policy.ErrUpdatingAuthz)
+		assert.Equal(t, map[string]policy.Policy{ownerCanDelete.ID: ownerCanDelete}, repo.policies)
+	})
+
+	t.Run("should refuse a batch larger than the limit", func(t *testing.T) {
+		svc := policy.NewService(&memoryRepository{policies: map[string]policy.Policy{}}, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		_, _, err := svc.CreateBatch(context.Background(), make([]policy.Policy, policy.MaxBatchSize+1))
+		assert.ErrorIs(t, err, policy.ErrBatchTooLarge)
+	})
+}
+
+func TestServiceCreateForOrgRole(t *testing.T) {
+	roles := memoryRoles{"shield/project:reviewer": {
+		ID:          "shield/project:reviewer",
+		Name:        "reviewer",
+		NamespaceID: "shield/project",
+		OrgID:       "9f256f86-31a3-11ec-8d3d-0242ac130003",
+	}}
+	actions := memoryActions{
+		"edit.shield/project":      {ID: "edit.shield/project", NamespaceID: "shield/project"},
+		"edit.shield/organization": {ID: "edit.shield/organization", NamespaceID: "shield/organization"},
+	}
+
+	t.Run("should create the policy if the action is in the namespace of the role", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, roles, actions, &memoryAudit{})
+
+		_, err := svc.Create(context.Background(), policy.Policy{
+			ID:          "policy-1",
+			RoleID:      "shield/project:reviewer",
+			NamespaceID: "shield/project",
+			ActionID:    "edit.shield/project",
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, repo.policies, "policy-1")
+	})
+
+	t.Run("should return error if the action is in another namespace", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, roles, actions, &memoryAudit{})
+
+		_, err := svc.Create(context.Background(), policy.Policy{
+			ID:          "policy-1",
+			RoleID:      "shield/project:reviewer",
+			NamespaceID: "shield/organization",
+			ActionID:    "edit.shield/organization",
+		})
+		assert.ErrorIs(t, err, policy.ErrInvalidDetail)
+		assert.Empty(t, repo.policies)
+	})
+
+	t.Run("should return error if the action doesn't exist", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, roles, actions, &memoryAudit{})
+
+		_, err := svc.Create(context.Background(), policy.Policy{
+			ID:          "policy-1",
+			RoleID:      "shield/project:reviewer",
+			NamespaceID: "shield/project",
+			ActionID:    "approve.shield/project",
+		})
+		assert.ErrorIs(t, err, policy.ErrInvalidDetail)
+	})
+}
+
+func TestServiceUpdate(t *testing.T) {
+	existing := policy.Policy{
+		ID:          "policy-1",
+		RoleID:      "shield/project:owner",
+		NamespaceID: "shield/project",
+		ActionID:    "delete.shield/project",
+		Effect:      policy.EffectAllow,
+	}
+
+	setup := func() (*policy.Service, *memoryRepository, *memoryAuthz) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{existing.ID: existing}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{grantKey(existing): true}}
+		return policy.NewService(repo, authz, memoryRoles{}, memoryActions{}, &memoryAudit{}), repo, authz
+	}
+
+	t.Run("should revoke the old grant when the role changes", func(t *testing.T) {
+		svc, repo, authz := setup()
+		updated := existing
+		updated.RoleID = "shield/project:editor"
+
+		_, err := svc.Update(context.Background(), updated)
+		assert.NoError(t, err)
+
+		assert.False(t, authz.authorizes("shield/project:owner", "shield/project", "delete.shield/project"))
+		assert.True(t, authz.authorizes("shield/project:editor", "shield/project", "delete.shield/project"))
+		assert.Equal(t, updated, repo.policies[existing.ID])
+	})
+
+	t.Run("should revoke the old grant when the action changes", func(t *testing.T) {
+		svc, _, authz := setup()
+		updated := existing
+		updated.ActionID = "edit.shield/project"
+
+		_, err := svc.Update(context.Background(), updated)
+		assert.NoError(t, err)
+
+		assert.False(t, authz.authorizes("shield/project:owner", "shield/project", "delete.shield/project"))
+		assert.True(t, authz.authorizes("shield/project:owner", "shield/project", "edit.shield/project"))
+	})
+
+	t.Run("should keep the old grant and revert the policy if the new grant can't be written", func(t *testing.T) {
+		svc, repo, authz := setup()
+		authz.addErr = errors.New("spicedb unavailable")
+		updated := existing
+		updated.RoleID = "shield/project:editor"
+
+		_, err := svc.Update(context.Background(), updated)
+		assert.ErrorIs(t, err, policy.ErrUpdatingAuthz)
+
+		assert.True(t, authz.authorizes("shield/project:owner", "shield/project", "delete.shield/project"))
+		assert.False(t, authz.authorizes("shield/project:editor", "shield/project", "delete.shield/project"))
+		assert.Equal(t, existing, repo.policies[existing.ID])
+	})
+
+	t.Run("should keep the grants of the other policies if the new grant can't be written", func(t *testing.T) {
+		svc, repo, authz := setup()
+		other := policy.Policy{ID: "policy-2", RoleID: "shield/project:owner", NamespaceID: "shield/project", ActionID: "edit.shield/project", Effect: policy.EffectAllow}
+		repo.policies[other.ID] = other
+		authz.grants[grantKey(other)] = true
+		authz.addErr = errors.New("spicedb unavailable")
+		updated := existing
+		updated.ActionID = "edit.shield/project"
+		updated.RoleID = "shield/project:editor"
+
+		_, err := svc.Update(context.Background(), updated)
+		assert.ErrorIs(t, err, policy.ErrUpdatingAuthz)
+
+		assert.True(t, authz.authorizes("shield/project:owner", "shield/project", "delete.shield/project"))
+		assert.True(t, authz.authorizes("shield/project:owner", "shield/project", "edit.shield/project"))
+		assert.False(t, authz.authorizes("shield/project:editor", "shield/project", "edit.shield/project"))
+		assert.Equal(t, existing, repo.policies[existing.ID])
+	})
+
+	t.Run("should keep the name and description if the update doesn't set them", func(t *testing.T) {
+		svc, repo, _ := setup()
+		labelled := existing
+		labelled.Name = "project owners can delete"
+		labelled.Description = "allow owners to delete their projects"
+		repo.policies[existing.ID] = labelled
+
+		updated := existing
+		updated.RoleID = "shield/project:editor"
+
+		_, err := svc.Update(context.Background(), updated)
+		assert.NoError(t, err)
+		assert.Equal(t, "project owners can delete", repo.policies[existing.ID].Name)
+		assert.Equal(t, "allow owners to delete their projects", repo.policies[existing.ID].Description)
+	})
+
+	t.Run("should deny the action when the effect changes to deny", func(t *testing.T) {
+		svc, _, authz := setup()
+		updated := existing
+		updated.Effect = policy.EffectDeny
+
+		_, err := svc.Update(context.Background(), updated)
+		assert.NoError(t, err)
+		assert.False(t, authz.authorizes("shield/project:owner", "shield/project", "delete.shield/project"))
+		assert.True(t, authz.grants[grantKey(updated)])
+	})
+
+	t.Run("should return error if policy doesn't exist", func(t *testing.T) {
+		svc, _, _ := setup()
+
+		_, err := svc.Update(context.Background(), policy.Policy{ID: "missing"})
+		assert.ErrorIs(t, err, policy.ErrNotExist)
+	})
+}
+
+func TestServiceGrantsToIncludingRoles(t *testing.T) {
+	viewerCanView := policy.Policy{
+		ID:          "policy-1",
+		RoleID:      "shield/project:viewer",
+		NamespaceID: "shield/project",
+		ActionID:    "view.shield/project",
+		Effect:      policy.EffectAllow,
+	}
+	roles := memoryRoles{
+		"shield/project:viewer": {ID: "shield/project:viewer"},
+		"shield/project:editor": {ID: "shield/project:editor", Includes: []string{"shield/project:viewer"}},
+		"shield/project:owner":  {ID: "shield/project:owner", Includes: []string{"shield/project:editor"}},
+	}
+
+	t.Run("should revoke the grants of the including roles when the policy is deleted", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{viewerCanView.ID: viewerCanView}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{}}
+		for _, r := range []string{"shield/project:viewer", "shield/project:editor", "shield/project:owner"} {
+			authz.grants[policy.Policy{RoleID: r, NamespaceID: "shield/project", ActionID: "view.shield/project", Effect: policy.EffectAllow}] = true
+		}
+		svc := policy.NewService(repo, authz, roles, memoryActions{}, &memoryAudit{})
+
+		err := svc.Delete(context.Background(), viewerCanView.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, authz.grants)
+	})
+
+	t.Run("should keep a grant the including role has by its own policy", func(t *testing.T) {
+		ownerCanView := viewerCanView
+		ownerCanView.ID = "policy-2"
+		ownerCanView.RoleID = "shield/project:owner"
+		repo := &memoryRepository{policies: map[string]policy.Policy{viewerCanView.ID: viewerCanView, ownerCanView.ID: ownerCanView}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{}}
+		for _, r := range []string{"shield/project:viewer", "shield/project:editor", "shield/project:owner"} {
+			authz.grants[policy.Policy{RoleID: r, NamespaceID: "shield/project", ActionID: "view.shield/project", Effect: policy.EffectAllow}] = true
+		}
+		svc := policy.NewService(repo, authz, roles, memoryActions{}, &memoryAudit{})
+
+		err := svc.Delete(context.Background(), viewerCanView.ID)
+		assert.NoError(t, err)
+		assert.False(t, authz.authorizes("shield/project:editor", "shield/project", "view.shield/project"))
+		assert.True(t, authz.authorizes("shield/project:owner", "shield/project", "view.shield/project"))
+	})
+
+	t.Run("should grant the actions of an included role when a role includes it", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{viewerCanView.ID: viewerCanView}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{}}
+		withAuditor := memoryRoles{"shield/project:viewer": roles["shield/project:viewer"]}
+		svc := policy.NewService(repo, authz, withAuditor, memoryActions{}, &memoryAudit{})
+
+		err := svc.SyncGrants(context.Background(), func() error {
+			withAuditor["shield/project:auditor"] = role.Role{ID: "shield/project:auditor", Includes: []string{"shield/project:viewer"}}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.True(t, authz.authorizes("shield/project:auditor", "shield/project", "view.shield/project"))
+	})
+}
+
+func TestServiceList(t *testing.T) {
+	existing := policy.Policy{
+		ID:          "policy-1",
+		RoleID:      "shield/project:owner",
+		NamespaceID: "shield/project",
+		ActionID:    "delete.shield/project",
+		Effect:      policy.EffectAllow,
+	}
+	repo := &memoryRepository{
+		policies: map[string]policy.Policy{existing.ID: existing},
+		names: map[string]string{
+			"shield/project:owner":  "Owner",
+			"shield/project":        "Project",
+			"delete.shield/project": "Delete Project",
+		},
+	}
+	svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+	t.Run("should return the policies with the names when expanded", func(t *testing.T) {
+		got, err := svc.List(context.Background(), policy.Filters{}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []policy.ExpandedPolicy{{
+			Policy:        existing,
+			RoleName:      "Owner",
+			ActionName:    "Delete Project",
+			NamespaceName: "Project",
+		}}, got)
+	})
+
+	t.Run("should return the policies without the names when not expanded", func(t *testing.T) {
+		got, err := svc.List(context.Background(), policy.Filters{}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []policy.ExpandedPolicy{{Policy: existing}}, got)
+	})
+
+	t.Run("should only return the policies of the namespace", func(t *testing.T) {
+		got, err := svc.List(context.Background(), policy.Filters{NamespaceID: "shield/organization"}, true)
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestServiceDelete(t *testing.T) {
+	existing := policy.Policy{
+		ID:          "policy-1",
+		RoleID:      "shield/project:owner",
+		NamespaceID: "shield/project",
+		ActionID:    "delete.shield/project",
+		Effect:      policy.EffectAllow,
+	}
+
+	t.Run("should delete the policy and revoke its grant", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{existing.ID: existing}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{grantKey(existing): true}}
+		audit := &memoryAudit{}
+		svc := policy.NewService(repo, authz, memoryRoles{}, memoryActions{}, audit)
+
+		err := svc.Delete(context.Background(), existing.ID)
+		assert.NoError(t, err)
+		assert.NotContains(t, repo.policies, existing.ID)
+		assert.False(t, authz.authorizes("shield/project:owner", "shield/project", "delete.shield/project"))
+		assert.Equal(t, []string{"delete policy policy-1"}, audit.actions)
+	})
+
+	t.Run("should return error if policy doesn't exist", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		err := svc.Delete(context.Background(), "missing")
+		assert.ErrorIs(t, err, policy.ErrNotExist)
+	})
+}
+
+func TestServiceConditions(t *testing.T) {
+	devViewers := policy.Policy{
+		ID:          "policy-1",
+		RoleID:      "entropy/firehose:viewer",
+		NamespaceID: "entropy/firehose",
+		ActionID:    "delete.entropy/firehose",
+		Condition:   ` resource["env"] == "dev" `,
+	}
+
+	t.Run("should create the policy with the trimmed condition", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		_, err := svc.Create(context.Background(), devViewers)
+		assert.NoError(t, err)
+		assert.Equal(t, `resource["env"] == "dev"`, repo.policies["policy-1"].Condition)
+	})
+
+	t.Run("should return error if the authz engine can't evaluate the condition", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		invalid := devViewers
+		invalid.Condition = `resource["env"]`
+		_, err := svc.Create(context.Background(), invalid)
+		assert.ErrorIs(t, err, policy.ErrInvalidDetail)
+		assert.Empty(t, repo.policies)
+	})
+
+	t.Run("should return error if the namespace is a system namespace", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		_, err := svc.Create(context.Background(), policy.Policy{
+			ID:          "policy-2",
+			RoleID:      "shield/project:owner",
+			NamespaceID: "shield/project",
+			ActionID:    "delete.shield/project",
+			Condition:   `request["team"] == "data"`,
+		})
+		assert.ErrorIs(t, err, policy.ErrInvalidDetail)
+	})
+
+	t.Run("should return error if the policy exists under another condition", func(t *testing.T) {
+		repo := &memoryRepository{policies: map[string]policy.Policy{devViewers.ID: devViewers}}
+		svc := policy.NewService(repo, &memoryAuthz{grants: map[policy.Policy]bool{}}, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		other := devViewers
+		other.ID = "policy-2"
+		other.Condition = `resource["env"] == "prod"`
+		_, err := svc.Create(context.Background(), other)
+		assert.ErrorIs(t, err, policy.ErrConflict)
+	})
+
+	t.Run("should apply the conditional policies of the namespace to a new object", func(t *testing.T) {
+		unconditional := devViewers
+		unconditional.ID, unconditional.Condition = "policy-2", ""
+		otherNamespace := devViewers
+		otherNamespace.ID, otherNamespace.NamespaceID = "policy-3", "entropy/dagger"
+		repo := &memoryRepository{policies: map[string]policy.Policy{
+			devViewers.ID:     devViewers,
+			unconditional.ID:  unconditional,
+			otherNamespace.ID: otherNamespace,
+		}}
+		authz := &memoryAuthz{grants: map[policy.Policy]bool{}}
+		svc := policy.NewService(repo, authz, memoryRoles{}, memoryActions{}, &memoryAudit{})
+
+		assert.NoError(t, svc.AddObject(context.Background(), "entropy/firehose", "firehose-1"))
+		assert.Equal(t, map[string][]string{"firehose-1": {"policy-1"}}, authz.objects)
+	})
+}
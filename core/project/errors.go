@@ -3,9 +3,12 @@ package project
 import "errors"
 
 var (
-	ErrNotExist      = errors.New("project doesn't exist")
-	ErrInvalidUUID   = errors.New("invalid syntax of uuid")
-	ErrInvalidID     = errors.New("project id is invalid")
-	ErrConflict      = errors.New("project already exist")
-	ErrInvalidDetail = errors.New("invalid project detail")
+	ErrNotExist        = errors.New("project doesn't exist")
+	ErrInvalidUUID     = errors.New("invalid syntax of uuid")
+	ErrInvalidID       = errors.New("project id is invalid")
+	ErrConflict        = errors.New("project already exist")
+	ErrInvalidDetail   = errors.New("invalid project detail")
+	ErrInUse           = errors.New("project is still referenced by other resources")
+	ErrNotDeleted      = errors.New("project isn't deleted")
+	ErrVersionMismatch = errors.New("project was updated since the version")
 )
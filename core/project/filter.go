@@ -0,0 +1,13 @@
+package project
+
+// Filter narrows down the projects listed, Name, Slug and the
+// Metadata values are globs where * matches any run of characters
+type Filter struct {
+	Name     string
+	Slug     string
+	Metadata map[string]string
+	// IncludeDeleted lists the soft deleted projects as well
+	IncludeDeleted bool
+	Limit          int32
+	Page           int32
+}
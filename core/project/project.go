@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/relation"
 	"github.com/odpf/shield/core/user"
 	"github.com/odpf/shield/pkg/metadata"
 )
@@ -13,10 +14,14 @@ type Repository interface {
 	GetByID(ctx context.Context, id string) (Project, error)
 	GetBySlug(ctx context.Context, slug string) (Project, error)
 	Create(ctx context.Context, org Project) (Project, error)
-	List(ctx context.Context) ([]Project, error)
+	List(ctx context.Context, flt Filter) ([]Project, error)
 	UpdateByID(ctx context.Context, toUpdate Project) (Project, error)
 	UpdateBySlug(ctx context.Context, toUpdate Project) (Project, error)
 	ListAdmins(ctx context.Context, id string) ([]user.User, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	ListDeleted(ctx context.Context, deletedBefore time.Time) ([]Project, error)
+	Purge(ctx context.Context, id string) ([]relation.Object, error)
 }
 
 type Project struct {
@@ -25,6 +30,16 @@ type Project struct {
 	Slug         string
 	Organization organization.Organization
 	Metadata     metadata.Metadata
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// Version is incremented on every update, an update made with a
+	// version is refused once the project was updated since
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt is set once the project is deleted, it can be
+	// restored until it is purged after the retention window
+	DeletedAt time.Time
+}
+
+func (p Project) IsDeleted() bool {
+	return !p.DeletedAt.IsZero()
 }
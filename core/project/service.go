@@ -2,43 +2,72 @@ package project
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/audit"
 	"github.com/odpf/shield/core/namespace"
 	"github.com/odpf/shield/core/organization"
 	"github.com/odpf/shield/core/relation"
 	"github.com/odpf/shield/core/user"
 	"github.com/odpf/shield/internal/schema"
+	shielderrors "github.com/odpf/shield/pkg/errors"
 	"github.com/odpf/shield/pkg/uuid"
 )
 
 type RelationService interface {
 	Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error)
 	Delete(ctx context.Context, rel relation.Relation) error
+	DeleteV2(ctx context.Context, rel relation.RelationV2) error
+	DeleteSubjectRelations(ctx context.Context, resourceType, optionalResourceID string) error
 	CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, action action.Action) (bool, error)
 }
 
 type UserService interface {
 	FetchCurrentUser(ctx context.Context) (user.User, error)
-	GetByID(ctx context.Context, id string) (user.User, error)
+	Get(ctx context.Context, idOrEmail string) (user.User, error)
 	GetByIDs(ctx context.Context, userIDs []string) ([]user.User, error)
+	GetByIDsOrEmails(ctx context.Context, idsOrEmails []string) ([]user.User, error)
+}
+
+const auditResourceType = "project"
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
 }
 
 type Service struct {
 	repository      Repository
 	relationService RelationService
 	userService     UserService
+	auditService    AuditService
 }
 
-func NewService(repository Repository, relationService RelationService, userService UserService) *Service {
+func NewService(repository Repository, relationService RelationService, userService UserService, auditService AuditService) *Service {
 	return &Service{
 		repository:      repository,
 		relationService: relationService,
 		userService:     userService,
+		auditService:    auditService,
 	}
 }
 
+// Get returns the project unless it is deleted, deleted projects
+// are only visible to Restore until they are purged
 func (s Service) Get(ctx context.Context, idOrSlug string) (Project, error) {
+	prj, err := s.get(ctx, idOrSlug)
+	if err != nil {
+		return Project{}, err
+	}
+	if prj.IsDeleted() {
+		return Project{}, ErrNotExist
+	}
+	return prj, nil
+}
+
+func (s Service) get(ctx context.Context, idOrSlug string) (Project, error) {
 	if uuid.IsValid(idOrSlug) {
 		return s.repository.GetByID(ctx, idOrSlug)
 	}
@@ -60,32 +89,154 @@ func (s Service) Create(ctx context.Context, prj Project) (Project, error) {
 		return Project{}, err
 	}
 
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, newProject.ID, nil, newProject); err != nil {
+		return Project{}, err
+	}
+
 	return newProject, nil
 }
 
-func (s Service) List(ctx context.Context) ([]Project, error) {
-	return s.repository.List(ctx)
+func (s Service) List(ctx context.Context, flt Filter) ([]Project, error) {
+	return s.repository.List(ctx, flt)
 }
 
 func (s Service) Update(ctx context.Context, prj Project) (Project, error) {
+	var oldProject, updatedProject Project
+	var err error
 	if prj.ID != "" {
-		return s.repository.UpdateByID(ctx, prj)
+		if oldProject, err = s.repository.GetByID(ctx, prj.ID); err != nil {
+			return Project{}, err
+		}
+		updatedProject, err = s.repository.UpdateByID(ctx, prj)
+	} else {
+		if oldProject, err = s.repository.GetBySlug(ctx, prj.Slug); err != nil {
+			return Project{}, err
+		}
+		updatedProject, err = s.repository.UpdateBySlug(ctx, prj)
+	}
+	if err != nil {
+		return Project{}, err
 	}
-	return s.repository.UpdateBySlug(ctx, prj)
+
+	if err = s.auditService.Record(ctx, audit.ActionUpdate, auditResourceType, updatedProject.ID, oldProject, updatedProject); err != nil {
+		return Project{}, err
+	}
+
+	return updatedProject, nil
 }
 
+// AddAdmins makes the users owners of the project, the current user has to
+// be able to edit the project. Either all the users are added or none of
+// them. It returns the admins of the project.
 func (s Service) AddAdmins(ctx context.Context, idOrSlug string, userIds []string) ([]user.User, error) {
-	// TODO(discussion): can be done with create relations
-	return []user.User{}, nil
+	prj, err := s.editableProject(ctx, idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userService.GetByIDsOrEmails(ctx, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	admins, err := s.repository.ListAdmins(ctx, prj.ID)
+	if err != nil {
+		return nil, err
+	}
+	isAdmin := map[string]bool{}
+	for _, a := range admins {
+		isAdmin[a.ID] = true
+	}
+
+	var added []user.User
+	for _, usr := range users {
+		if isAdmin[usr.ID] {
+			continue
+		}
+		if _, err := s.relationService.Create(ctx, adminRelation(prj, usr.Email)); err != nil {
+			// undo the admins added so far
+			for _, a := range added {
+				if delErr := s.relationService.DeleteV2(ctx, adminRelation(prj, a.ID)); delErr != nil {
+					return nil, fmt.Errorf("%w: rollback: %s", err, delErr.Error())
+				}
+			}
+			return nil, err
+		}
+		added = append(added, usr)
+	}
+
+	return s.repository.ListAdmins(ctx, prj.ID)
 }
 
-func (s Service) ListAdmins(ctx context.Context, id string) ([]user.User, error) {
-	return s.repository.ListAdmins(ctx, id)
+func (s Service) ListAdmins(ctx context.Context, idOrSlug string) ([]user.User, error) {
+	prj, err := s.Get(ctx, idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.ListAdmins(ctx, prj.ID)
 }
 
+// RemoveAdmin removes the user from the owners of the project, the current
+// user has to be able to edit the project. It returns the admins of the
+// project.
 func (s Service) RemoveAdmin(ctx context.Context, idOrSlug string, userId string) ([]user.User, error) {
-	// TODO(discussion): can be done with delete relations
-	return []user.User{}, nil
+	prj, err := s.editableProject(ctx, idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	usr, err := s.userService.Get(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.relationService.DeleteV2(ctx, adminRelation(prj, usr.ID)); err != nil {
+		if errors.Is(err, relation.ErrNotExist) {
+			return nil, user.ErrNotExist
+		}
+		return nil, err
+	}
+
+	return s.repository.ListAdmins(ctx, prj.ID)
+}
+
+// editableProject returns the project if the current user can edit it
+func (s Service) editableProject(ctx context.Context, idOrSlug string) (Project, error) {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return Project{}, err
+	}
+
+	prj, err := s.Get(ctx, idOrSlug)
+	if err != nil {
+		return Project{}, err
+	}
+
+	allowed, err := s.relationService.CheckPermission(ctx, currentUser, namespace.Namespace{ID: schema.ProjectNamespace}, prj.ID, action.Action{ID: schema.EditPermission})
+	if err != nil {
+		return Project{}, err
+	}
+	if !allowed {
+		return Project{}, shielderrors.ErrForbidden
+	}
+	return prj, nil
+}
+
+// adminRelation is the owner relation of the subject to the project, the
+// subject is the email of the user when creating the relation and the id
+// of the user when deleting it
+func adminRelation(prj Project, subjectID string) relation.RelationV2 {
+	return relation.RelationV2{
+		Object: relation.Object{
+			ID:          prj.ID,
+			NamespaceID: schema.ProjectNamespace,
+		},
+		Subject: relation.Subject{
+			ID:        subjectID,
+			Namespace: schema.UserPrincipal,
+			RoleID:    schema.OwnerRole,
+		},
+	}
 }
 
 func (s Service) addProjectToOrg(ctx context.Context, prj Project, org organization.Organization) error {
@@ -107,3 +258,91 @@ func (s Service) addProjectToOrg(ctx context.Context, prj Project, org organizat
 
 	return nil
 }
+
+// Delete marks the project as deleted, it is left out of lists and can be
+// restored until PurgeDeleted removes it along with its resources and relations
+func (s Service) Delete(ctx context.Context, idOrSlug string) error {
+	prj, err := s.Get(ctx, idOrSlug)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.Delete(ctx, prj.ID); err != nil {
+		return err
+	}
+
+	return s.auditService.Record(ctx, audit.ActionDelete, auditResourceType, prj.ID, prj, nil)
+}
+
+// GetDeleted returns the project only while it is deleted, it is
+// ErrNotDeleted otherwise
+func (s Service) GetDeleted(ctx context.Context, idOrSlug string) (Project, error) {
+	prj, err := s.get(ctx, idOrSlug)
+	if err != nil {
+		return Project{}, err
+	}
+	if !prj.IsDeleted() {
+		return Project{}, ErrNotDeleted
+	}
+	return prj, nil
+}
+
+func (s Service) Restore(ctx context.Context, idOrSlug string) (Project, error) {
+	prj, err := s.GetDeleted(ctx, idOrSlug)
+	if err != nil {
+		return Project{}, err
+	}
+
+	if err := s.repository.Restore(ctx, prj.ID); err != nil {
+		return Project{}, err
+	}
+
+	restored, err := s.repository.GetByID(ctx, prj.ID)
+	if err != nil {
+		return Project{}, err
+	}
+
+	if err := s.auditService.Record(ctx, audit.ActionRestore, auditResourceType, restored.ID, prj, restored); err != nil {
+		return Project{}, err
+	}
+	return restored, nil
+}
+
+// PurgeDeleted permanently removes the projects deleted longer than the
+// retention ago along with their resources, folders and the relations on
+// them, it returns how many were purged. The tuples of the authz store are
+// removed after the postgres rows, the ones left behind by a failure in
+// between are collected by the relation garbage collector
+func (s Service) PurgeDeleted(ctx context.Context, retention time.Duration) (int, error) {
+	deleted, err := s.repository.ListDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, prj := range deleted {
+		objects, err := s.repository.Purge(ctx, prj.ID)
+		if err != nil {
+			// still referenced, it is purged once the rows referring to it are
+			if errors.Is(err, ErrInUse) {
+				continue
+			}
+			return purged, err
+		}
+
+		if err := s.relationService.DeleteSubjectRelations(ctx, schema.ProjectNamespace, prj.ID); err != nil {
+			return purged, err
+		}
+		for _, obj := range objects {
+			if err := s.relationService.DeleteSubjectRelations(ctx, obj.NamespaceID, obj.ID); err != nil {
+				return purged, err
+			}
+		}
+
+		if err := s.auditService.Record(ctx, audit.ActionPurge, auditResourceType, prj.ID, prj, nil); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,166 @@
+package project_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/user"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepository struct {
+	project.Repository
+	relations *memoryRelationService
+}
+
+func (r memoryRepository) GetBySlug(ctx context.Context, slug string) (project.Project, error) {
+	if slug != "p1" {
+		return project.Project{}, project.ErrNotExist
+	}
+	return project.Project{ID: "p1-id", Slug: "p1"}, nil
+}
+
+func (r memoryRepository) GetByID(ctx context.Context, id string) (project.Project, error) {
+	return r.GetBySlug(ctx, id)
+}
+
+func (r memoryRepository) ListAdmins(ctx context.Context, id string) ([]user.User, error) {
+	var users []user.User
+	for _, u := range r.relations.owners {
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+type memoryRelationService struct {
+	project.RelationService
+	users  map[string]user.User
+	owners map[string]user.User
+	// failOn fails creating the relation of the email
+	failOn string
+	editor string
+}
+
+func (s *memoryRelationService) Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	if rel.Subject.ID == s.failOn {
+		return relation.RelationV2{}, relation.ErrCreatingRelationInAuthzEngine
+	}
+	for _, u := range s.users {
+		if u.Email == rel.Subject.ID {
+			s.owners[u.ID] = u
+		}
+	}
+	return rel, nil
+}
+
+func (s *memoryRelationService) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	if _, ok := s.owners[rel.Subject.ID]; !ok {
+		return relation.ErrNotExist
+	}
+	delete(s.owners, rel.Subject.ID)
+	return nil
+}
+
+func (s *memoryRelationService) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, act action.Action) (bool, error) {
+	return usr.ID == s.editor, nil
+}
+
+type memoryUserService struct {
+	project.UserService
+	current user.User
+	users   map[string]user.User
+}
+
+func (s memoryUserService) FetchCurrentUser(ctx context.Context) (user.User, error) {
+	return s.current, nil
+}
+
+func (s memoryUserService) Get(ctx context.Context, idOrEmail string) (user.User, error) {
+	for _, u := range s.users {
+		if u.ID == idOrEmail || u.Email == idOrEmail {
+			return u, nil
+		}
+	}
+	return user.User{}, user.ErrNotExist
+}
+
+func (s memoryUserService) GetByIDs(ctx context.Context, ids []string) ([]user.User, error) {
+	var users []user.User
+	for _, id := range ids {
+		if u, ok := s.users[id]; ok {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (s memoryUserService) GetByIDsOrEmails(ctx context.Context, idsOrEmails []string) ([]user.User, error) {
+	var users []user.User
+	for _, ref := range idsOrEmails {
+		u, err := s.Get(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func TestServiceAdmins(t *testing.T) {
+	users := map[string]user.User{
+		"u1": {ID: "u1", Email: "u1@example.com"},
+		"u2": {ID: "u2", Email: "u2@example.com"},
+		"u3": {ID: "u3", Email: "u3@example.com"},
+	}
+	setup := func(current string) (*project.Service, *memoryRelationService) {
+		relations := &memoryRelationService{users: users, owners: map[string]user.User{}, editor: "u1"}
+		userService := memoryUserService{current: users[current], users: users}
+		return project.NewService(memoryRepository{relations: relations}, relations, userService, nil), relations
+	}
+
+	t.Run("should add the users as admins of the project", func(t *testing.T) {
+		s, _ := setup("u1")
+
+		got, err := s.AddAdmins(context.Background(), "p1", []string{"u2", "u3"})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []user.User{users["u2"], users["u3"]}, got)
+	})
+
+	t.Run("should not add any user if adding one of them fails", func(t *testing.T) {
+		s, relations := setup("u1")
+		relations.failOn = "u3@example.com"
+
+		_, err := s.AddAdmins(context.Background(), "p1", []string{"u2", "u3"})
+		assert.ErrorIs(t, err, relation.ErrCreatingRelationInAuthzEngine)
+		assert.Empty(t, relations.owners)
+	})
+
+	t.Run("should return error if the current user can't edit the project", func(t *testing.T) {
+		s, _ := setup("u2")
+
+		_, err := s.AddAdmins(context.Background(), "p1", []string{"u3"})
+		assert.ErrorIs(t, err, shielderrors.ErrForbidden)
+	})
+
+	t.Run("should remove the admin of the project", func(t *testing.T) {
+		s, _ := setup("u1")
+		_, err := s.AddAdmins(context.Background(), "p1", []string{"u2", "u3"})
+		assert.NoError(t, err)
+
+		got, err := s.RemoveAdmin(context.Background(), "p1", "u2")
+		assert.NoError(t, err)
+		assert.Equal(t, []user.User{users["u3"]}, got)
+	})
+
+	t.Run("should return error if the user isn't an admin", func(t *testing.T) {
+		s, _ := setup("u1")
+
+		_, err := s.RemoveAdmin(context.Background(), "p1", "u2")
+		assert.ErrorIs(t, err, user.ErrNotExist)
+	})
+}
@@ -0,0 +1,74 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidateCreate runs the checks Create and the repository make without
+// creating the project, it returns the project as it would be created
+func (s Service) ValidateCreate(ctx context.Context, prj Project) (Project, error) {
+	if strings.TrimSpace(prj.Name) == "" || strings.TrimSpace(prj.Slug) == "" {
+		return Project{}, ErrInvalidDetail
+	}
+	if err := s.checkSlug(ctx, "", prj.Slug); err != nil {
+		return Project{}, err
+	}
+	return prj, nil
+}
+
+// ValidateUpdate runs the checks Update and the repository make without
+// updating the project, it returns the project as it would be updated
+func (s Service) ValidateUpdate(ctx context.Context, prj Project) (Project, error) {
+	var current Project
+	var err error
+	if prj.ID != "" {
+		current, err = s.repository.GetByID(ctx, prj.ID)
+	} else {
+		current, err = s.repository.GetBySlug(ctx, prj.Slug)
+	}
+	if err != nil {
+		return Project{}, err
+	}
+	if current.IsDeleted() {
+		return Project{}, ErrNotExist
+	}
+	if prj.Version != 0 && prj.Version != current.Version {
+		return Project{}, ErrVersionMismatch
+	}
+
+	if strings.TrimSpace(prj.Name) == "" || strings.TrimSpace(prj.Slug) == "" {
+		return Project{}, ErrInvalidDetail
+	}
+	// an update by slug keeps the slug
+	if prj.ID != "" && prj.Slug != current.Slug {
+		if err := s.checkSlug(ctx, current.ID, prj.Slug); err != nil {
+			return Project{}, err
+		}
+	}
+
+	updated := current
+	updated.Name = prj.Name
+	updated.Slug = prj.Slug
+	updated.Organization = prj.Organization
+	updated.Metadata = prj.Metadata
+	return updated, nil
+}
+
+// checkSlug returns ErrConflict when a project other than the one of the id
+// has the slug, deleted ones included as they keep their slug until they are
+// purged
+func (s Service) checkSlug(ctx context.Context, id, slug string) error {
+	existing, err := s.repository.GetBySlug(ctx, slug)
+	switch {
+	case errors.Is(err, ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	case existing.ID != id:
+		return fmt.Errorf("%w: %s", ErrConflict, slug)
+	}
+	return nil
+}
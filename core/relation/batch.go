@@ -0,0 +1,112 @@
+package relation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/internal/schema"
+)
+
+// MaxBatchSize is the most relations created by a single CreateBatch, the
+// tuples of a batch are written to the authz engine in a single request
+const MaxBatchSize = 1000
+
+// BatchFailure is a relation of a batch that wasn't created, by its index
+// in the batch
+type BatchFailure struct {
+	Index int
+	Err   error
+}
+
+// TupleOf is the tuple the relation is written as in the authz engine
+func TupleOf(rel RelationV2) Tuple {
+	t := Tuple{
+		ResourceType: rel.Object.NamespaceID,
+		ResourceID:   rel.Object.ID,
+		Relation:     schema.GetRoleName(rel.Subject.RoleID),
+		SubjectType:  rel.Subject.Namespace,
+		SubjectID:    rel.Subject.ID,
+	}
+	if rel.Subject.Namespace == schema.GroupPrincipal {
+		t.SubjectRelation = "membership"
+	}
+	return t
+}
+
+// CreateBatch creates the relations like Create, in a single transaction of
+// the store and a single write of the authz engine. The relations that
+// can't be created, like those of unknown users or objects, are reported as
+// failures by their index and the others are created. An error is returned
+// when the batch can't be written at all, nothing is created then. The
+// created relations are in the order of the batch.
+func (s Service) CreateBatch(ctx context.Context, relations []RelationV2) ([]RelationV2, []BatchFailure, error) {
+	if len(relations) > MaxBatchSize {
+		return nil, nil, fmt.Errorf("%w: %d relations, at most %d", ErrBatchTooLarge, len(relations), MaxBatchSize)
+	}
+
+	var failures []BatchFailure
+	// indexes has the index in the batch of the relations of valid
+	var valid []RelationV2
+	var indexes []int
+	for i, rel := range relations {
+		if rel.Subject.Namespace == schema.UserPrincipal || rel.Subject.Namespace == "user" {
+			fetchedUser, err := s.userService.GetByEmail(ctx, rel.Subject.ID)
+			if err != nil {
+				failures = append(failures, BatchFailure{Index: i, Err: fmt.Errorf("%w: %s", ErrFetchingUser, err.Error())})
+				continue
+			}
+			rel.Subject.Namespace = schema.UserPrincipal
+			rel.Subject.ID = fetchedUser.ID
+		}
+
+		if err := s.checkRoleScope(ctx, rel); err != nil {
+			failures = append(failures, BatchFailure{Index: i, Err: err})
+			continue
+		}
+		valid = append(valid, rel)
+		indexes = append(indexes, i)
+	}
+	if len(valid) == 0 {
+		return nil, failures, nil
+	}
+
+	created, storeFailures, err := s.repository.CreateBatch(ctx, valid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrCreatingRelationInStore, err.Error())
+	}
+	for _, f := range storeFailures {
+		failures = append(failures, BatchFailure{Index: indexes[f.Index], Err: f.Err})
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Index < failures[j].Index })
+	if len(created) == 0 {
+		return nil, failures, nil
+	}
+
+	// spicedb refuses a write updating the same tuple twice
+	tuples := make([]Tuple, 0, len(created))
+	seen := map[Tuple]bool{}
+	for _, rel := range created {
+		if t := TupleOf(rel); !seen[t] {
+			seen[t] = true
+			tuples = append(tuples, t)
+		}
+	}
+	if err := s.authzRepository.Import(ctx, tuples); err != nil {
+		// like Create, a relation only present in the store grants nothing
+		for _, rel := range created {
+			if delErr := s.repository.DeleteByID(ctx, rel.ID); delErr != nil {
+				return nil, nil, fmt.Errorf("%w: %s: rollback: %s", ErrCreatingRelationInAuthzEngine, err.Error(), delErr.Error())
+			}
+		}
+		return nil, nil, fmt.Errorf("%w: %s", ErrCreatingRelationInAuthzEngine, err.Error())
+	}
+
+	for _, rel := range created {
+		if err := s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, rel.ID, nil, rel); err != nil {
+			return nil, nil, err
+		}
+	}
+	return created, failures, nil
+}
@@ -0,0 +1,141 @@
+package relation_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRelationRepository creates the relations of the known namespaces,
+// the ids of their roles prefixed by the namespace the way the store does
+type memoryRelationRepository struct {
+	relation.Repository
+	namespaces map[string]bool
+	relations  map[string]relation.RelationV2
+}
+
+func (r *memoryRelationRepository) CreateBatch(ctx context.Context, relations []relation.RelationV2) ([]relation.RelationV2, []relation.BatchFailure, error) {
+	var created []relation.RelationV2
+	var failures []relation.BatchFailure
+	for i, rel := range relations {
+		if !r.namespaces[rel.Object.NamespaceID] {
+			failures = append(failures, relation.BatchFailure{Index: i, Err: relation.ErrInvalidDetail})
+			continue
+		}
+		rel.ID = fmt.Sprintf("relation-%d", len(r.relations)+1)
+		rel.Subject.RoleID = schema.GetRoleID(rel.Object.NamespaceID, rel.Subject.RoleID)
+		r.relations[rel.ID] = rel
+		created = append(created, rel)
+	}
+	return created, failures, nil
+}
+
+func (r *memoryRelationRepository) DeleteByID(ctx context.Context, id string) error {
+	delete(r.relations, id)
+	return nil
+}
+
+type memoryImportRepository struct {
+	relation.AuthzRepository
+	imports   [][]relation.Tuple
+	importErr error
+}
+
+func (r *memoryImportRepository) Import(ctx context.Context, tuples []relation.Tuple) error {
+	if r.importErr != nil {
+		return r.importErr
+	}
+	r.imports = append(r.imports, tuples)
+	return nil
+}
+
+type systemRoles struct{}
+
+func (systemRoles) Get(ctx context.Context, id string) (role.Role, error) {
+	return role.Role{}, role.ErrNotExist
+}
+
+type memoryUsers map[string]string
+
+func (u memoryUsers) GetByID(ctx context.Context, id string) (user.User, error) {
+	for email, userID := range u {
+		if userID == id {
+			return user.User{ID: id, Email: email}, nil
+		}
+	}
+	return user.User{}, user.ErrNotExist
+}
+
+func (u memoryUsers) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	id, ok := u[email]
+	if !ok {
+		return user.User{}, user.ErrNotExist
+	}
+	return user.User{ID: id, Email: email}, nil
+}
+
+func TestServiceCreateBatch(t *testing.T) {
+	ownerOf := func(subject, namespace, project string) relation.RelationV2 {
+		return relation.RelationV2{
+			Object:  relation.Object{ID: project, NamespaceID: "shield/project"},
+			Subject: relation.Subject{ID: subject, Namespace: namespace, RoleID: "owner"},
+		}
+	}
+	newService := func(authz *memoryImportRepository, audit *memoryAuditService) (*relation.Service, *memoryRelationRepository) {
+		repo := &memoryRelationRepository{namespaces: map[string]bool{"shield/project": true}, relations: map[string]relation.RelationV2{}}
+		return relation.NewService(repo, authz, systemRoles{}, memoryUsers{"jane@acme.io": "u1"}, audit), repo
+	}
+
+	t.Run("should create the valid relations in a single write and report the others by their index", func(t *testing.T) {
+		authz, audit := &memoryImportRepository{}, &memoryAuditService{}
+		svc, _ := newService(authz, audit)
+
+		unknownNamespace := ownerOf("g1", "shield/group", "p1")
+		unknownNamespace.Object.NamespaceID = "compute/instance"
+		created, failures, err := svc.CreateBatch(context.Background(), []relation.RelationV2{
+			ownerOf("jane@acme.io", "user", "p1"),
+			ownerOf("john@acme.io", "user", "p1"),
+			unknownNamespace,
+			ownerOf("g1", "shield/group", "p1"),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, created, 2)
+		assert.Equal(t, relation.Subject{ID: "u1", Namespace: "shield/user", RoleID: "shield/project:owner"}, created[0].Subject)
+		require.Len(t, failures, 2)
+		assert.Equal(t, 1, failures[0].Index)
+		assert.ErrorIs(t, failures[0].Err, relation.ErrFetchingUser)
+		assert.Equal(t, 2, failures[1].Index)
+		assert.ErrorIs(t, failures[1].Err, relation.ErrInvalidDetail)
+
+		require.Len(t, authz.imports, 1)
+		assert.Equal(t, []relation.Tuple{
+			{ResourceType: "shield/project", ResourceID: "p1", Relation: "owner", SubjectType: "shield/user", SubjectID: "u1"},
+			{ResourceType: "shield/project", ResourceID: "p1", Relation: "owner", SubjectType: "shield/group", SubjectID: "g1", SubjectRelation: "membership"},
+		}, authz.imports[0])
+		assert.Len(t, audit.records, 2)
+	})
+
+	t.Run("should delete the created relations if the authz engine can't be written", func(t *testing.T) {
+		svc, repo := newService(&memoryImportRepository{importErr: errors.New("spicedb unavailable")}, &memoryAuditService{})
+
+		_, _, err := svc.CreateBatch(context.Background(), []relation.RelationV2{ownerOf("g1", "shield/group", "p1")})
+		assert.ErrorIs(t, err, relation.ErrCreatingRelationInAuthzEngine)
+		assert.Empty(t, repo.relations)
+	})
+
+	t.Run("should refuse a batch larger than the limit", func(t *testing.T) {
+		svc, _ := newService(&memoryImportRepository{}, &memoryAuditService{})
+
+		_, _, err := svc.CreateBatch(context.Background(), make([]relation.RelationV2, relation.MaxBatchSize+1))
+		assert.ErrorIs(t, err, relation.ErrBatchTooLarge)
+	})
+}
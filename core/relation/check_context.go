@@ -0,0 +1,78 @@
+package relation
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+const (
+	CheckContextIP  = "ip"
+	CheckContextNow = "now"
+)
+
+// CheckContext is what the conditions of the policies are evaluated against
+// in the permission checks made with it. A condition is an expression over
+// the parameters:
+//
+//	ip        ipaddress, the ip of the request
+//	now       timestamp, the now of the request or the time of the check
+//	request   map<any>, every key of the request
+//	resource  map<any>, the tags of the resource checked
+//
+// A condition using a parameter the check doesn't set is not met.
+type CheckContext struct {
+	Request  map[string]string
+	Resource map[string]string
+}
+
+// IsZero tells whether the check has nothing to evaluate the conditions
+// against but its time
+func (c CheckContext) IsZero() bool {
+	return len(c.Request) == 0 && len(c.Resource) == 0
+}
+
+// ParseCheckContext parses key=value pairs into the request of a check
+// context, blank pairs are skipped. The ip has to be an ip address and now
+// a RFC 3339 time.
+func ParseCheckContext(pairs []string) (map[string]string, error) {
+	request := map[string]string{}
+	for _, pair := range pairs {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %s should be key=value", ErrInvalidCheckContext, pair)
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case CheckContextIP:
+			if _, err := netip.ParseAddr(value); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidCheckContext, err)
+			}
+		case CheckContextNow:
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidCheckContext, err)
+			}
+		}
+		request[key] = value
+	}
+	return request, nil
+}
+
+type checkContextKey struct{}
+
+// WithCheckContext sets the context of the permission checks made with ctx
+func WithCheckContext(ctx context.Context, c CheckContext) context.Context {
+	return context.WithValue(ctx, checkContextKey{}, c)
+}
+
+func CheckContextFromContext(ctx context.Context) CheckContext {
+	c, _ := ctx.Value(checkContextKey{}).(CheckContext)
+	return c
+}
@@ -0,0 +1,44 @@
+package relation_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/odpf/shield/core/relation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCheckContext(t *testing.T) {
+	tests := []struct {
+		pairs []string
+		want  map[string]string
+		err   error
+	}{
+		{pairs: nil, want: map[string]string{}},
+		{pairs: []string{"", " "}, want: map[string]string{}},
+		{pairs: []string{"team=data", " env = prod "}, want: map[string]string{"team": "data", "env": "prod"}},
+		{pairs: []string{"ip=10.0.0.1", "now=2023-01-26T10:00:00Z"}, want: map[string]string{"ip": "10.0.0.1", "now": "2023-01-26T10:00:00Z"}},
+		{pairs: []string{"query=a=b"}, want: map[string]string{"query": "a=b"}},
+		{pairs: []string{"team"}, err: relation.ErrInvalidCheckContext},
+		{pairs: []string{"=data"}, err: relation.ErrInvalidCheckContext},
+		{pairs: []string{"ip=10.0.0.0/8"}, err: relation.ErrInvalidCheckContext},
+		{pairs: []string{"now=yesterday"}, err: relation.ErrInvalidCheckContext},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.pairs, ","), func(t *testing.T) {
+			got, err := relation.ParseCheckContext(tt.pairs)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckContext(t *testing.T) {
+	assert.True(t, relation.CheckContextFromContext(context.Background()).IsZero())
+
+	c := relation.CheckContext{Request: map[string]string{"team": "data"}}
+	ctx := relation.WithCheckContext(context.Background(), c)
+	assert.Equal(t, c, relation.CheckContextFromContext(ctx))
+	assert.False(t, relation.CheckContextFromContext(ctx).IsZero())
+}
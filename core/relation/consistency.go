@@ -0,0 +1,100 @@
+package relation
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+const (
+	ConsistencyMinimizeLatency = "minimize-latency"
+	ConsistencyFull            = "full"
+	ConsistencyAtLeastAsFresh  = "at-least-as-fresh"
+)
+
+// Consistency is how fresh the relations a permission check is evaluated
+// against have to be, the zero value lets the authz engine answer from
+// its caches with the least latency
+type Consistency struct {
+	// Full evaluates the check against the latest relations
+	Full bool
+	// AtLeastAsFresh is the token of a write the check has to observe
+	AtLeastAsFresh string
+}
+
+// ParseConsistency parses full, minimize-latency or at-least-as-fresh=<token>,
+// an empty value minimizes the latency
+func ParseConsistency(value string) (Consistency, error) {
+	switch value {
+	case "", ConsistencyMinimizeLatency:
+		return Consistency{}, nil
+	case ConsistencyFull:
+		return Consistency{Full: true}, nil
+	}
+
+	if token := strings.TrimPrefix(value, ConsistencyAtLeastAsFresh+"="); token != value && token != "" {
+		return Consistency{AtLeastAsFresh: token}, nil
+	}
+	return Consistency{}, ErrInvalidConsistency
+}
+
+func (c Consistency) String() string {
+	switch {
+	case c.Full:
+		return ConsistencyFull
+	case c.AtLeastAsFresh != "":
+		return ConsistencyAtLeastAsFresh + "=" + c.AtLeastAsFresh
+	default:
+		return ConsistencyMinimizeLatency
+	}
+}
+
+// IsZero tells whether the check may be answered with the least latency
+func (c Consistency) IsZero() bool {
+	return c == Consistency{}
+}
+
+type consistencyKey struct{}
+
+// WithConsistency sets the consistency of the permission checks made with ctx
+func WithConsistency(ctx context.Context, c Consistency) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, c)
+}
+
+func ConsistencyFromContext(ctx context.Context) Consistency {
+	c, _ := ctx.Value(consistencyKey{}).(Consistency)
+	return c
+}
+
+// Revision holds the token of the last write to the authz engine made with
+// the context it is recorded in, the token can be passed back as the
+// consistency of a later check to observe the write
+type Revision struct {
+	mu    sync.Mutex
+	token string
+}
+
+type revisionKey struct{}
+
+// WithRevision returns a context recording the token of the writes made with it
+func WithRevision(ctx context.Context) (context.Context, *Revision) {
+	rev := &Revision{}
+	return context.WithValue(ctx, revisionKey{}, rev), rev
+}
+
+// RecordRevision records the token of a write if ctx was made by WithRevision
+func RecordRevision(ctx context.Context, token string) {
+	rev, ok := ctx.Value(revisionKey{}).(*Revision)
+	if !ok || token == "" {
+		return
+	}
+	rev.mu.Lock()
+	defer rev.mu.Unlock()
+	rev.token = token
+}
+
+func (r *Revision) Token() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.token
+}
@@ -0,0 +1,43 @@
+package relation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/relation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConsistency(t *testing.T) {
+	tests := []struct {
+		value string
+		want  relation.Consistency
+		err   error
+	}{
+		{value: "", want: relation.Consistency{}},
+		{value: "minimize-latency", want: relation.Consistency{}},
+		{value: "full", want: relation.Consistency{Full: true}},
+		{value: "at-least-as-fresh=GhUKEzE2", want: relation.Consistency{AtLeastAsFresh: "GhUKEzE2"}},
+		{value: "at-least-as-fresh=", err: relation.ErrInvalidConsistency},
+		{value: "exact", err: relation.ErrInvalidConsistency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := relation.ParseConsistency(tt.value)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+			if err == nil && tt.value != "" {
+				assert.Equal(t, tt.value, got.String())
+			}
+		})
+	}
+}
+
+func TestRevision(t *testing.T) {
+	relation.RecordRevision(context.Background(), "ignored")
+
+	ctx, rev := relation.WithRevision(context.Background())
+	relation.RecordRevision(ctx, "token-1")
+	relation.RecordRevision(ctx, "token-2")
+	assert.Equal(t, "token-2", rev.Token())
+}
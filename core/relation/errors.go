@@ -11,4 +11,9 @@ var (
 	ErrCreatingRelationInStore       = errors.New("error while creating relation")
 	ErrCreatingRelationInAuthzEngine = errors.New("error while creating relation in authz engine")
 	ErrFetchingUser                  = errors.New("error while fetching user")
+	ErrRoleOutsideOrg                = errors.New("role can't be granted outside its organization")
+	ErrInvalidConsistency            = errors.New("consistency should be full, minimize-latency or at-least-as-fresh=<token>")
+	ErrInvalidCheckContext           = errors.New("invalid check context")
+	ErrInvalidTuple                  = errors.New("invalid tuple")
+	ErrBatchTooLarge                 = errors.New("batch has too many relations")
 )
@@ -0,0 +1,199 @@
+package relation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+)
+
+// maxExpandDepth is how many subject sets deep an expansion follows, like
+// the members of a group which is a member of another group
+const maxExpandDepth = 8
+
+const (
+	OperationUnion        = "union"
+	OperationIntersection = "intersection"
+	OperationExclusion    = "exclusion"
+)
+
+// AccessTree is the expansion of a permission or a relation of an object. A
+// leaf has the subjects of the relation and the expansions of the subject
+// sets among them as children, the other nodes combine the access granted
+// by their children with the operation.
+type AccessTree struct {
+	Object    Object
+	Relation  string
+	Operation string
+	Children  []AccessTree
+	Subjects  []Subject
+}
+
+// Grants tells whether the tree grants access to the subject, the
+// operations are evaluated the way the authz engine evaluates them
+func (t AccessTree) Grants(sub Subject) bool {
+	switch t.Operation {
+	case OperationIntersection:
+		for _, child := range t.Children {
+			if !child.Grants(sub) {
+				return false
+			}
+		}
+		return len(t.Children) > 0
+	case OperationExclusion:
+		if len(t.Children) == 0 || !t.Children[0].Grants(sub) {
+			return false
+		}
+		for _, child := range t.Children[1:] {
+			if child.Grants(sub) {
+				return false
+			}
+		}
+		return true
+	default:
+		if t.HasSubject(sub) {
+			return true
+		}
+		for _, child := range t.Children {
+			if child.Grants(sub) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Reaches tells whether the subject is anywhere in the tree, whether or not
+// the tree grants it access
+func (t AccessTree) Reaches(sub Subject) bool {
+	if t.HasSubject(sub) {
+		return true
+	}
+	for _, child := range t.Children {
+		if child.Reaches(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantedSubjects returns the subjects of the tree it grants access to in
+// the order they are met, the subject sets granted access are returned along
+// with their members
+func (t AccessTree) GrantedSubjects() []Subject {
+	var subjects []Subject
+	seen := map[Subject]bool{}
+	var walk func(node AccessTree)
+	walk = func(node AccessTree) {
+		for _, sub := range node.Subjects {
+			if !seen[sub] {
+				seen[sub] = true
+				if t.Grants(sub) {
+					subjects = append(subjects, sub)
+				}
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(t)
+	return subjects
+}
+
+// Paths returns the paths from the root of the tree to the nodes having the
+// subject along which every node grants the subject access, the last node
+// of a path has the subject
+func (t AccessTree) Paths(sub Subject) [][]AccessTree {
+	if !t.Grants(sub) {
+		return nil
+	}
+
+	var paths [][]AccessTree
+	if t.HasSubject(sub) {
+		paths = append(paths, []AccessTree{t})
+	}
+	for _, child := range t.Children {
+		for _, path := range child.Paths(sub) {
+			paths = append(paths, append([]AccessTree{t}, path...))
+		}
+	}
+	return paths
+}
+
+// HasSubject tells whether the subject is one of the subjects of the node,
+// a wildcard subject stands for all the subjects of its namespace
+func (t AccessTree) HasSubject(sub Subject) bool {
+	for _, s := range t.Subjects {
+		if authzType(s.Namespace) == authzType(sub.Namespace) && s.RoleID == sub.RoleID &&
+			(s.ID == sub.ID || (s.ID == "*" && s.RoleID == "")) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSubject checks the permission of any subject on the object, a subject
+// with a role id is the set of subjects having the role, like the members of
+// a group. A disabled user has no permission.
+func (s Service) CheckSubject(ctx context.Context, sub Subject, obj Object, act action.Action) (bool, error) {
+	if sub.Namespace == schema.UserPrincipal && sub.RoleID == "" && sub.ID != "*" {
+		usr, err := s.userService.GetByID(ctx, sub.ID)
+		switch {
+		case err == nil && usr.IsDisabled():
+			return false, nil
+		case err != nil && !errors.Is(err, user.ErrNotExist) && !errors.Is(err, user.ErrInvalidID) && !errors.Is(err, user.ErrInvalidUUID):
+			return false, err
+		}
+	}
+
+	return s.authzRepository.Check(ctx, Relation{
+		ObjectNamespace:  namespace.Namespace{ID: obj.NamespaceID},
+		ObjectID:         obj.ID,
+		SubjectNamespace: namespace.Namespace{ID: sub.Namespace},
+		SubjectID:        sub.ID,
+		SubjectRoleID:    sub.RoleID,
+	}, act)
+}
+
+// Expand expands the permission of the object into the tree of the relations
+// granting it, the subject sets met on the way are expanded as well
+func (s Service) Expand(ctx context.Context, obj Object, permission string) (AccessTree, error) {
+	return s.expand(ctx, obj, permission, 0)
+}
+
+func (s Service) expand(ctx context.Context, obj Object, permission string, depth int) (AccessTree, error) {
+	tree, err := s.authzRepository.Expand(ctx, obj, permission)
+	if err != nil {
+		return AccessTree{}, err
+	}
+	return s.expandSubjectSets(ctx, tree, depth)
+}
+
+func (s Service) expandSubjectSets(ctx context.Context, tree AccessTree, depth int) (AccessTree, error) {
+	for i, child := range tree.Children {
+		expanded, err := s.expandSubjectSets(ctx, child, depth)
+		if err != nil {
+			return AccessTree{}, err
+		}
+		tree.Children[i] = expanded
+	}
+
+	if depth >= maxExpandDepth {
+		return tree, nil
+	}
+	for _, sub := range tree.Subjects {
+		if sub.RoleID == "" {
+			continue
+		}
+		child, err := s.expand(ctx, Object{ID: sub.ID, NamespaceID: sub.Namespace}, sub.RoleID, depth+1)
+		if err != nil {
+			return AccessTree{}, err
+		}
+		tree.Children = append(tree.Children, child)
+	}
+	return tree, nil
+}
@@ -0,0 +1,187 @@
+package relation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryExpandRepository expands the relations of the objects from the
+// trees keyed by type:id#relation
+type memoryExpandRepository struct {
+	relation.AuthzRepository
+	trees map[string]relation.AccessTree
+}
+
+func (r memoryExpandRepository) Expand(ctx context.Context, obj relation.Object, permission string) (relation.AccessTree, error) {
+	return r.trees[obj.NamespaceID+":"+obj.ID+"#"+permission], nil
+}
+
+func TestAccessTreeGrants(t *testing.T) {
+	alice := relation.Subject{ID: "alice", Namespace: "shield/user"}
+	bob := relation.Subject{ID: "bob", Namespace: "shield/user"}
+	leaf := func(rel string, subjects ...relation.Subject) relation.AccessTree {
+		return relation.AccessTree{Object: relation.Object{ID: "p1", NamespaceID: "shield/project"}, Relation: rel, Subjects: subjects}
+	}
+
+	tests := []struct {
+		name    string
+		tree    relation.AccessTree
+		granted []relation.Subject
+		reached []relation.Subject
+	}{
+		{
+			name: "union grants the subjects of any operand",
+			tree: relation.AccessTree{Operation: relation.OperationUnion, Children: []relation.AccessTree{
+				leaf("owner", alice), leaf("editor", bob),
+			}},
+			granted: []relation.Subject{alice, bob},
+			reached: []relation.Subject{alice, bob},
+		},
+		{
+			name: "intersection grants the subjects of every operand",
+			tree: relation.AccessTree{Operation: relation.OperationIntersection, Children: []relation.AccessTree{
+				leaf("member", alice, bob), leaf("editor", bob),
+			}},
+			granted: []relation.Subject{bob},
+			reached: []relation.Subject{alice, bob},
+		},
+		{
+			name: "exclusion blocks the subjects of the excluded operands",
+			tree: relation.AccessTree{Operation: relation.OperationExclusion, Children: []relation.AccessTree{
+				leaf("viewer", alice, bob), leaf("banned", alice),
+			}},
+			granted: []relation.Subject{bob},
+			reached: []relation.Subject{alice, bob},
+		},
+		{
+			name:    "wildcard grants every subject of its namespace",
+			tree:    leaf("viewer", relation.Subject{ID: "*", Namespace: "shield/user"}),
+			granted: []relation.Subject{alice, bob},
+			reached: []relation.Subject{alice, bob},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, sub := range []relation.Subject{alice, bob} {
+				assert.Equal(t, contains(tt.granted, sub), tt.tree.Grants(sub), sub.ID)
+				assert.Equal(t, contains(tt.reached, sub), tt.tree.Reaches(sub), sub.ID)
+			}
+		})
+	}
+}
+
+func contains(subjects []relation.Subject, sub relation.Subject) bool {
+	for _, s := range subjects {
+		if s == sub {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServiceExpand(t *testing.T) {
+	alice := relation.Subject{ID: "alice", Namespace: "shield/user"}
+	members := relation.Subject{ID: "g1", Namespace: "shield/group", RoleID: "membership"}
+	repository := memoryExpandRepository{trees: map[string]relation.AccessTree{
+		"shield/project:p1#view": {
+			Object:    relation.Object{ID: "p1", NamespaceID: "shield/project"},
+			Relation:  "view",
+			Operation: relation.OperationUnion,
+			Children: []relation.AccessTree{{
+				Object:   relation.Object{ID: "p1", NamespaceID: "shield/project"},
+				Relation: "viewer",
+				Subjects: []relation.Subject{members},
+			}},
+		},
+		"shield/group:g1#membership": {
+			Object:   relation.Object{ID: "g1", NamespaceID: "shield/group"},
+			Relation: "membership",
+			Subjects: []relation.Subject{alice},
+		},
+	}}
+	s := relation.NewService(nil, repository, nil, nil, nil)
+
+	tree, err := s.Expand(context.Background(), relation.Object{ID: "p1", NamespaceID: "shield/project"}, "view")
+	require.NoError(t, err)
+	assert.True(t, tree.Grants(alice))
+	assert.True(t, tree.Grants(members))
+	assert.Equal(t, "membership", tree.Children[0].Children[0].Relation)
+}
+
+func TestAccessTreePaths(t *testing.T) {
+	alice := relation.Subject{ID: "alice", Namespace: "shield/user"}
+	bob := relation.Subject{ID: "bob", Namespace: "shield/user"}
+	members := relation.Subject{ID: "g1", Namespace: "shield/group", RoleID: "membership"}
+	owner := relation.AccessTree{Relation: "owner", Subjects: []relation.Subject{alice}}
+	group := relation.AccessTree{Relation: "membership", Subjects: []relation.Subject{alice, bob}}
+	viewer := relation.AccessTree{Relation: "viewer", Subjects: []relation.Subject{members}, Children: []relation.AccessTree{group}}
+	banned := relation.AccessTree{Relation: "banned", Subjects: []relation.Subject{bob}}
+	view := relation.AccessTree{Relation: "view", Operation: relation.OperationUnion, Children: []relation.AccessTree{owner, viewer}}
+	tree := relation.AccessTree{Relation: "read", Operation: relation.OperationExclusion, Children: []relation.AccessTree{view, banned}}
+
+	assert.Equal(t, []relation.Subject{alice, members}, tree.GrantedSubjects())
+	assert.Equal(t, [][]relation.AccessTree{
+		{tree, view, owner},
+		{tree, view, viewer, group},
+	}, tree.Paths(alice))
+	assert.Equal(t, [][]relation.AccessTree{{tree, view, viewer}}, tree.Paths(members))
+	assert.Empty(t, tree.Paths(bob))
+}
+
+// allowingRepository allows every check
+type allowingRepository struct {
+	relation.AuthzRepository
+}
+
+func (r allowingRepository) Check(ctx context.Context, rel relation.Relation, act action.Action) (bool, error) {
+	return true, nil
+}
+
+type disabledUsers map[string]bool
+
+func (u disabledUsers) GetByID(ctx context.Context, id string) (user.User, error) {
+	disabled, ok := u[id]
+	if !ok {
+		return user.User{}, user.ErrNotExist
+	}
+	usr := user.User{ID: id}
+	if disabled {
+		usr.DisabledAt = time.Now()
+	}
+	return usr, nil
+}
+
+func (u disabledUsers) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	return user.User{}, user.ErrNotExist
+}
+
+func TestServiceCheckSubject(t *testing.T) {
+	s := relation.NewService(nil, allowingRepository{}, nil, disabledUsers{"alice": false, "bob": true}, nil)
+	obj := relation.Object{ID: "p1", NamespaceID: "shield/project"}
+	view := action.Action{ID: "view"}
+
+	for _, tt := range []struct {
+		sub     relation.Subject
+		allowed bool
+	}{
+		{sub: relation.Subject{ID: "alice", Namespace: "shield/user"}, allowed: true},
+		{sub: relation.Subject{ID: "bob", Namespace: "shield/user"}, allowed: false},
+		{sub: relation.Subject{ID: "g1", Namespace: "shield/group", RoleID: "membership"}, allowed: true},
+	} {
+		allowed, err := s.CheckSubject(context.Background(), tt.sub, obj, view)
+		require.NoError(t, err)
+		assert.Equal(t, tt.allowed, allowed, tt.sub.ID)
+	}
+
+	allowed, err := s.CheckPermission(context.Background(), user.User{ID: "bob", DisabledAt: time.Now()}, namespace.Namespace{ID: obj.NamespaceID}, obj.ID, view)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,157 @@
+package relation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/odpf/shield/core/audit"
+)
+
+const (
+	// OrphanObject marks the tuples whose object doesn't exist
+	OrphanObject = "object"
+	// OrphanSubject marks the tuples whose subject doesn't exist
+	OrphanSubject = "subject"
+)
+
+// TupleRepository reads and removes the tuples of the authz store
+type TupleRepository interface {
+	Export(ctx context.Context, resourceType string, fn func(Tuple) error) error
+	DeleteTuples(ctx context.Context, tuples []Tuple) error
+}
+
+// ObjectRepository looks up the objects of the namespaces, ids which can't
+// refer to an object like the wildcard are reported as existing so the
+// tuples referring to them are never collected
+type ObjectRepository interface {
+	ExistingIDs(ctx context.Context, namespaceID string, ids []string) (map[string]bool, error)
+}
+
+// Orphan is a tuple of the authz store whose object or subject no longer
+// exists, they are left behind when the rows are removed from postgres but
+// removing the tuples fails
+type Orphan struct {
+	Tuple
+	// Missing is OrphanObject or OrphanSubject, the object is checked first
+	Missing string
+}
+
+// Collector finds and removes the orphaned tuples of the authz store
+type Collector struct {
+	tupleRepository  TupleRepository
+	objectRepository ObjectRepository
+	auditService     AuditService
+}
+
+func NewCollector(tupleRepository TupleRepository, objectRepository ObjectRepository, auditService AuditService) *Collector {
+	return &Collector{
+		tupleRepository:  tupleRepository,
+		objectRepository: objectRepository,
+		auditService:     auditService,
+	}
+}
+
+// Orphans returns the tuples on the objects of the namespaces whose object
+// or subject doesn't exist, subjects of other namespaces are not checked
+func (c Collector) Orphans(ctx context.Context, namespaceIDs []string) ([]Orphan, error) {
+	namespaces := map[string]string{}
+	for _, nsID := range namespaceIDs {
+		namespaces[authzType(nsID)] = nsID
+	}
+
+	var tuples []Tuple
+	refs := map[string][]string{}
+	for _, nsID := range namespaceIDs {
+		if err := c.tupleRepository.Export(ctx, authzType(nsID), func(t Tuple) error {
+			tuples = append(tuples, t)
+			refs[nsID] = append(refs[nsID], t.ResourceID)
+			if subjectNS, ok := namespaces[t.SubjectType]; ok {
+				refs[subjectNS] = append(refs[subjectNS], t.SubjectID)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	existing := map[string]map[string]bool{}
+	for nsID, ids := range refs {
+		found, err := c.objectRepository.ExistingIDs(ctx, nsID, ids)
+		if err != nil {
+			return nil, err
+		}
+		existing[nsID] = found
+	}
+
+	var orphans []Orphan
+	for _, t := range tuples {
+		if !existing[namespaces[t.ResourceType]][t.ResourceID] {
+			orphans = append(orphans, Orphan{Tuple: t, Missing: OrphanObject})
+			continue
+		}
+		if subjectNS, ok := namespaces[t.SubjectType]; ok && !existing[subjectNS][t.SubjectID] {
+			orphans = append(orphans, Orphan{Tuple: t, Missing: OrphanSubject})
+		}
+	}
+	return orphans, nil
+}
+
+// Collect removes the orphaned tuples from the authz store, the removal
+// of each tuple is audited
+func (c Collector) Collect(ctx context.Context, orphans []Orphan) error {
+	tuples := make([]Tuple, 0, len(orphans))
+	for _, o := range orphans {
+		tuples = append(tuples, o.Tuple)
+	}
+	if err := c.tupleRepository.DeleteTuples(ctx, tuples); err != nil {
+		return err
+	}
+
+	for _, o := range orphans {
+		if err := c.auditService.Record(ctx, audit.ActionPurge, auditResourceType, o.String(), o, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SubjectTuples returns the tuples on the objects of the namespaces the
+// subject is the subject of, whichever relation they are of
+func (c Collector) SubjectTuples(ctx context.Context, namespaceIDs []string, sub Subject) ([]Tuple, error) {
+	var tuples []Tuple
+	for _, nsID := range namespaceIDs {
+		if err := c.tupleRepository.Export(ctx, authzType(nsID), func(t Tuple) error {
+			if t.SubjectType == authzType(sub.Namespace) && t.SubjectID == sub.ID {
+				tuples = append(tuples, t)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return tuples, nil
+}
+
+// Remove removes the tuples from the authz store, the removal of each tuple
+// is audited
+func (c Collector) Remove(ctx context.Context, tuples []Tuple) error {
+	if len(tuples) == 0 {
+		return nil
+	}
+	if err := c.tupleRepository.DeleteTuples(ctx, tuples); err != nil {
+		return err
+	}
+
+	for _, t := range tuples {
+		if err := c.auditService.Record(ctx, audit.ActionPurge, auditResourceType, t.String(), t, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authzType is the type of the objects of the namespace in the authz
+// store, dashes aren't allowed in the type names
+func authzType(namespaceID string) string {
+	return strings.ReplaceAll(namespaceID, "-", "_")
+}
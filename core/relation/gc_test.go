@@ -0,0 +1,124 @@
+package relation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/relation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTupleRepository struct {
+	tuples []relation.Tuple
+}
+
+func (r *memoryTupleRepository) Export(ctx context.Context, resourceType string, fn func(relation.Tuple) error) error {
+	for _, t := range r.tuples {
+		if t.ResourceType != resourceType {
+			continue
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryTupleRepository) DeleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	var kept []relation.Tuple
+	for _, t := range r.tuples {
+		deleted := false
+		for _, d := range tuples {
+			deleted = deleted || t == d
+		}
+		if !deleted {
+			kept = append(kept, t)
+		}
+	}
+	r.tuples = kept
+	return nil
+}
+
+type memoryObjectRepository map[string][]string
+
+func (r memoryObjectRepository) ExistingIDs(ctx context.Context, namespaceID string, ids []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	for _, id := range ids {
+		existing[id] = id == "*"
+		for _, object := range r[namespaceID] {
+			existing[id] = existing[id] || object == id
+		}
+	}
+	return existing, nil
+}
+
+type memoryAuditService struct {
+	records []string
+}
+
+func (s *memoryAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	s.records = append(s.records, action+" "+resourceID)
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	owner := relation.Tuple{ResourceType: "shield/project", ResourceID: "p1", Relation: "owner", SubjectType: "shield/user", SubjectID: "u1"}
+	purgedProject := relation.Tuple{ResourceType: "shield/project", ResourceID: "p2", Relation: "owner", SubjectType: "shield/user", SubjectID: "u1"}
+	deletedUser := relation.Tuple{ResourceType: "shield/project", ResourceID: "p1", Relation: "viewer", SubjectType: "shield/user", SubjectID: "u2"}
+	resource := relation.Tuple{ResourceType: "entropy_firehose", ResourceID: "r1", Relation: "project", SubjectType: "shield/project", SubjectID: "p2"}
+	wildcard := relation.Tuple{ResourceType: "entropy_firehose", ResourceID: "r2", Relation: "viewer", SubjectType: "shield/user", SubjectID: "*"}
+
+	tuples := &memoryTupleRepository{tuples: []relation.Tuple{owner, purgedProject, deletedUser, resource, wildcard}}
+	objects := memoryObjectRepository{
+		"shield/project":   {"p1"},
+		"shield/user":      {"u1"},
+		"entropy-firehose": {"r1", "r2"},
+	}
+	auditService := &memoryAuditService{}
+	collector := relation.NewCollector(tuples, objects, auditService)
+
+	orphans, err := collector.Orphans(context.Background(), []string{"shield/project", "shield/user", "entropy-firehose"})
+	require.NoError(t, err)
+	assert.Equal(t, []relation.Orphan{
+		{Tuple: purgedProject, Missing: relation.OrphanObject},
+		{Tuple: deletedUser, Missing: relation.OrphanSubject},
+		{Tuple: resource, Missing: relation.OrphanSubject},
+	}, orphans)
+
+	require.NoError(t, collector.Collect(context.Background(), orphans))
+	assert.Equal(t, []relation.Tuple{owner, wildcard}, tuples.tuples)
+	assert.Equal(t, []string{
+		"purge shield/project:p2#owner@shield/user:u1",
+		"purge shield/project:p1#viewer@shield/user:u2",
+		"purge entropy_firehose:r1#project@shield/project:p2",
+	}, auditService.records)
+
+	t.Run("should only check the subjects of the given namespaces", func(t *testing.T) {
+		orphans, err := collector.Orphans(context.Background(), []string{"entropy-firehose"})
+		require.NoError(t, err)
+		assert.Empty(t, orphans)
+	})
+}
+
+func TestCollectorSubjectTuples(t *testing.T) {
+	owner := relation.Tuple{ResourceType: "shield/project", ResourceID: "p1", Relation: "owner", SubjectType: "shield/user", SubjectID: "u1"}
+	viewer := relation.Tuple{ResourceType: "shield/project", ResourceID: "p1", Relation: "viewer", SubjectType: "shield/user", SubjectID: "u2"}
+	member := relation.Tuple{ResourceType: "shield/group", ResourceID: "g1", Relation: "member", SubjectType: "shield/user", SubjectID: "u1"}
+	editor := relation.Tuple{ResourceType: "entropy_firehose", ResourceID: "r1", Relation: "editor", SubjectType: "shield/user", SubjectID: "u1"}
+
+	tuples := &memoryTupleRepository{tuples: []relation.Tuple{owner, viewer, member, editor}}
+	auditService := &memoryAuditService{}
+	collector := relation.NewCollector(tuples, memoryObjectRepository{}, auditService)
+
+	found, err := collector.SubjectTuples(context.Background(), []string{"shield/project", "entropy-firehose"}, relation.Subject{ID: "u1", Namespace: "shield/user"})
+	require.NoError(t, err)
+	assert.Equal(t, []relation.Tuple{owner, editor}, found)
+
+	require.NoError(t, collector.Remove(context.Background(), found))
+	assert.Equal(t, []relation.Tuple{viewer, member}, tuples.tuples)
+	assert.Equal(t, []string{
+		"purge shield/project:p1#owner@shield/user:u1",
+		"purge entropy_firehose:r1#editor@shield/user:u1",
+	}, auditService.records)
+}
@@ -2,6 +2,8 @@ package relation
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/odpf/shield/core/action"
@@ -17,6 +19,10 @@ type Repository interface {
 	Update(ctx context.Context, toUpdate Relation) (Relation, error)
 	DeleteByID(ctx context.Context, id string) error
 	GetByFields(ctx context.Context, rel RelationV2) (RelationV2, error)
+	// CreateBatch creates the relations in a single transaction, the
+	// relations the store refuses are left out and reported by their index
+	CreateBatch(ctx context.Context, relations []RelationV2) ([]RelationV2, []BatchFailure, error)
+	DeleteBySubject(ctx context.Context, sub Subject) ([]RelationV2, error)
 }
 
 type AuthzRepository interface {
@@ -25,6 +31,9 @@ type AuthzRepository interface {
 	DeleteV2(ctx context.Context, rel RelationV2) error
 	DeleteSubjectRelations(ctx context.Context, resourceType, optionalResourceID string) error
 	AddV2(ctx context.Context, rel RelationV2) error
+	Expand(ctx context.Context, obj Object, permission string) (AccessTree, error)
+	LookupResources(ctx context.Context, namespaceID, permission string, sub Subject) ([]string, error)
+	Import(ctx context.Context, tuples []Tuple) error
 }
 
 type RoleService interface {
@@ -32,9 +41,14 @@ type RoleService interface {
 }
 
 type UserService interface {
+	GetByID(ctx context.Context, id string) (user.User, error)
 	GetByEmail(ctx context.Context, email string) (user.User, error)
 }
 
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
 type Relation struct {
 	ID                 string
 	SubjectNamespace   namespace.Namespace
@@ -70,6 +84,49 @@ type RelationV2 struct {
 	UpdatedAt time.Time
 }
 
+// Tuple is a relationship of the authz store as it is exported, imported
+// and garbage collected, types are the namespace ids of the objects
+type Tuple struct {
+	ResourceType    string `json:"resource_type"`
+	ResourceID      string `json:"resource_id"`
+	Relation        string `json:"relation"`
+	SubjectType     string `json:"subject_type"`
+	SubjectID       string `json:"subject_id"`
+	SubjectRelation string `json:"subject_relation,omitempty"`
+}
+
+// String formats the tuple as type:id#relation@type:id[#relation]
+func (t Tuple) String() string {
+	s := fmt.Sprintf("%s:%s#%s@%s:%s", t.ResourceType, t.ResourceID, t.Relation, t.SubjectType, t.SubjectID)
+	if t.SubjectRelation != "" {
+		s += "#" + t.SubjectRelation
+	}
+	return s
+}
+
+// ParseTuple parses a tuple formatted as type:id#relation@type:id[#relation]
+func ParseTuple(s string) (Tuple, error) {
+	resource, subject, ok := strings.Cut(strings.TrimSpace(s), "@")
+	if !ok {
+		return Tuple{}, fmt.Errorf("%w: %s should be type:id#relation@type:id[#relation]", ErrInvalidTuple, s)
+	}
+	resource, rel, _ := strings.Cut(resource, "#")
+	resourceType, resourceID, _ := strings.Cut(resource, ":")
+	subject, subjectRelation, _ := strings.Cut(subject, "#")
+	subjectType, subjectID, _ := strings.Cut(subject, ":")
+	if resourceType == "" || resourceID == "" || rel == "" || subjectType == "" || subjectID == "" {
+		return Tuple{}, fmt.Errorf("%w: %s should be type:id#relation@type:id[#relation]", ErrInvalidTuple, s)
+	}
+	return Tuple{
+		ResourceType:    resourceType,
+		ResourceID:      resourceID,
+		Relation:        rel,
+		SubjectType:     subjectType,
+		SubjectID:       subjectID,
+		SubjectRelation: subjectRelation,
+	}, nil
+}
+
 type RelationType string
 
 var RelationTypes = struct {
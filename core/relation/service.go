@@ -2,27 +2,34 @@ package relation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/audit"
 	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/role"
 	"github.com/odpf/shield/core/user"
 	"github.com/odpf/shield/internal/schema"
 )
 
+const auditResourceType = "relation"
+
 type Service struct {
 	repository      Repository
 	authzRepository AuthzRepository
 	roleService     RoleService
 	userService     UserService
+	auditService    AuditService
 }
 
-func NewService(repository Repository, authzRepository AuthzRepository, roleService RoleService, userService UserService) *Service {
+func NewService(repository Repository, authzRepository AuthzRepository, roleService RoleService, userService UserService, auditService AuditService) *Service {
 	return &Service{
 		repository:      repository,
 		authzRepository: authzRepository,
 		roleService:     roleService,
 		userService:     userService,
+		auditService:    auditService,
 	}
 }
 
@@ -42,6 +49,10 @@ func (s Service) Create(ctx context.Context, rel RelationV2) (RelationV2, error)
 		rel.Subject.ID = fetchedUser.ID
 	}
 
+	if err := s.checkRoleScope(ctx, rel); err != nil {
+		return RelationV2{}, err
+	}
+
 	createdRelation, err := s.repository.Create(ctx, rel)
 	if err != nil {
 		return RelationV2{}, fmt.Errorf("%w: %s", ErrCreatingRelationInStore, err.Error())
@@ -49,12 +60,55 @@ func (s Service) Create(ctx context.Context, rel RelationV2) (RelationV2, error)
 
 	err = s.authzRepository.AddV2(ctx, createdRelation)
 	if err != nil {
+		// keep the store in line with the authz engine, a relation
+		// only present in the store grants nothing
+		if delErr := s.repository.DeleteByID(ctx, createdRelation.ID); delErr != nil {
+			return RelationV2{}, fmt.Errorf("%w: %s: rollback: %s", ErrCreatingRelationInAuthzEngine, err.Error(), delErr.Error())
+		}
 		return RelationV2{}, fmt.Errorf("%w: %s", ErrCreatingRelationInAuthzEngine, err.Error())
 	}
 
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, createdRelation.ID, nil, createdRelation); err != nil {
+		return RelationV2{}, err
+	}
+
 	return createdRelation, nil
 }
 
+// checkRoleScope checks the custom role of an organization is granted on the
+// organization or on an object that belongs to it
+func (s Service) checkRoleScope(ctx context.Context, rel RelationV2) error {
+	rl, err := s.roleService.Get(ctx, schema.GetRoleID(rel.Object.NamespaceID, rel.Subject.RoleID))
+	if err != nil {
+		if errors.Is(err, role.ErrNotExist) {
+			// relations between namespaces have no role of their own
+			return nil
+		}
+		return err
+	}
+	if rl.OrgID == "" {
+		return nil
+	}
+
+	if rel.Object.NamespaceID == schema.OrganizationNamespace {
+		if rel.Object.ID != rl.OrgID {
+			return ErrRoleOutsideOrg
+		}
+		return nil
+	}
+
+	if _, err := s.repository.GetByFields(ctx, RelationV2{
+		Object:  Object{ID: rel.Object.ID},
+		Subject: Subject{ID: rl.OrgID, RoleID: schema.OrganizationRelationName},
+	}); err != nil {
+		if errors.Is(err, ErrNotExist) {
+			return ErrRoleOutsideOrg
+		}
+		return err
+	}
+	return nil
+}
+
 func (s Service) List(ctx context.Context) ([]RelationV2, error) {
 	return s.repository.List(ctx)
 }
@@ -115,10 +169,19 @@ func (s Service) DeleteV2(ctx context.Context, rel RelationV2) error {
 		return err
 	}
 
-	return s.repository.DeleteByID(ctx, fetchedRel.ID)
+	if err := s.repository.DeleteByID(ctx, fetchedRel.ID); err != nil {
+		return err
+	}
+
+	return s.auditService.Record(ctx, audit.ActionDelete, auditResourceType, fetchedRel.ID, fetchedRel, nil)
 }
 
+// CheckPermission checks the permission of the user on the object, a
+// disabled user has no permission while keeping their relations
 func (s Service) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, action action.Action) (bool, error) {
+	if usr.IsDisabled() {
+		return false, nil
+	}
 	return s.authzRepository.Check(ctx, Relation{
 		ObjectNamespace:  resourceNS,
 		ObjectID:         resourceIdxa,
@@ -127,6 +190,28 @@ func (s Service) CheckPermission(ctx context.Context, usr user.User, resourceNS
 	}, action)
 }
 
+// DeleteSubject removes the relations of the subject from the store, like
+// the memberships and roles of a user being purged. The tuples of the authz
+// engine are removed by the Collector as they may not all have a relation.
+func (s Service) DeleteSubject(ctx context.Context, sub Subject) ([]RelationV2, error) {
+	deleted, err := s.repository.DeleteBySubject(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range deleted {
+		if err := s.auditService.Record(ctx, audit.ActionPurge, auditResourceType, rel.ID, rel, nil); err != nil {
+			return nil, err
+		}
+	}
+	return deleted, nil
+}
+
+// LookupResources returns the ids of the objects of the namespace the
+// subject has the permission on
+func (s Service) LookupResources(ctx context.Context, namespaceID, permission string, sub Subject) ([]string, error) {
+	return s.authzRepository.LookupResources(ctx, namespaceID, permission, sub)
+}
+
 func (s Service) DeleteSubjectRelations(ctx context.Context, resourceType, optionalResourceID string) error {
 	return s.authzRepository.DeleteSubjectRelations(ctx, resourceType, optionalResourceID)
 }
@@ -9,4 +9,7 @@ var (
 	ErrInvalidURN    = errors.New("resource urn is invalid")
 	ErrConflict      = errors.New("resource already exist")
 	ErrInvalidDetail = errors.New("invalid resource detail")
+	ErrTooManyChecks = errors.New("too many permission checks")
+
+	ErrTagPolicyNotExist = errors.New("tag policy doesn't exist")
 )
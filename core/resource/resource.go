@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/odpf/shield/core/action"
 	"github.com/odpf/shield/core/namespace"
 )
 
@@ -17,12 +18,37 @@ type Repository interface {
 	List(ctx context.Context, flt Filter) ([]Resource, error)
 	Update(ctx context.Context, id string, resource Resource) (Resource, error)
 	GetByNamespace(ctx context.Context, name string, ns string) (Resource, error)
+	SetFolder(ctx context.Context, id, folderID string) (Resource, error)
 }
 
 type ConfigRepository interface {
 	GetAll(ctx context.Context) ([]YAML, error)
 }
 
+const (
+	// MaxBatchChecks is the most permission checks evaluated in one batch
+	MaxBatchChecks = 100
+
+	batchCheckConcurrency = 10
+
+	// defaultPageSize is the size of the pages of resources listed when
+	// the size isn't set
+	defaultPageSize int32 = 50
+)
+
+// Check is a permission check of an action on a resource
+type Check struct {
+	Resource Resource
+	Action   action.Action
+}
+
+// PagedResources is a page of resources along with the count of the
+// resources of every page
+type PagedResources struct {
+	Count     int32
+	Resources []Resource
+}
+
 type Resource struct {
 	Idxa           string
 	URN            string
@@ -31,8 +57,12 @@ type Resource struct {
 	OrganizationID string
 	NamespaceID    string
 	UserID         string
+	FolderID       string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	// Tags are the labels of the resource the tag policies match on, nil
+	// leaves the tags of a resource as they are on updates
+	Tags map[string]string
 }
 
 func (res Resource) CreateURN() string {
@@ -47,10 +77,15 @@ func (res Resource) CreateURN() string {
 }
 
 type Filter struct {
+	// IDs are the idxa of the resources listed
+	IDs            []string
 	ProjectID      string
 	GroupID        string
 	OrganizationID string
 	NamespaceID    string
+	FolderID       string
+	// Tags lists the resources carrying all of them
+	Tags map[string]string
 }
 
 type YAML struct {
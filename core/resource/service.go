@@ -2,7 +2,11 @@ package resource
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/odpf/shield/core/action"
 	"github.com/odpf/shield/core/group"
@@ -12,32 +16,45 @@ import (
 	"github.com/odpf/shield/core/relation"
 	"github.com/odpf/shield/core/user"
 	"github.com/odpf/shield/internal/schema"
+	shielderrors "github.com/odpf/shield/pkg/errors"
 )
 
 type RelationService interface {
 	Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error)
 	Delete(ctx context.Context, rel relation.Relation) error
+	DeleteV2(ctx context.Context, rel relation.RelationV2) error
 	CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, action action.Action) (bool, error)
 	DeleteSubjectRelations(ctx context.Context, resourceType, optionalResourceID string) error
+	LookupResources(ctx context.Context, namespaceID, permission string, sub relation.Subject) ([]string, error)
+	Expand(ctx context.Context, obj relation.Object, permission string) (relation.AccessTree, error)
 }
 
 type UserService interface {
 	FetchCurrentUser(ctx context.Context) (user.User, error)
+	Get(ctx context.Context, idOrEmail string) (user.User, error)
+}
+
+type PolicyService interface {
+	AddObject(ctx context.Context, namespaceID, objectID string) error
 }
 
 type Service struct {
-	repository       Repository
-	configRepository ConfigRepository
-	relationService  RelationService
-	userService      UserService
+	repository          Repository
+	configRepository    ConfigRepository
+	tagPolicyRepository TagPolicyRepository
+	relationService     RelationService
+	userService         UserService
+	policyService       PolicyService
 }
 
-func NewService(repository Repository, configRepository ConfigRepository, relationService RelationService, userService UserService) *Service {
+func NewService(repository Repository, configRepository ConfigRepository, tagPolicyRepository TagPolicyRepository, relationService RelationService, userService UserService, policyService PolicyService) *Service {
 	return &Service{
-		repository:       repository,
-		configRepository: configRepository,
-		relationService:  relationService,
-		userService:      userService,
+		repository:          repository,
+		configRepository:    configRepository,
+		tagPolicyRepository: tagPolicyRepository,
+		relationService:     relationService,
+		userService:         userService,
+		policyService:       policyService,
 	}
 }
 
@@ -45,6 +62,11 @@ func (s Service) Get(ctx context.Context, id string) (Resource, error) {
 	return s.repository.GetByID(ctx, id)
 }
 
+// GetByNamespace returns the resource of the namespace with the name
+func (s Service) GetByNamespace(ctx context.Context, name, ns string) (Resource, error) {
+	return s.repository.GetByNamespace(ctx, name, ns)
+}
+
 func (s Service) Create(ctx context.Context, res Resource) (Resource, error) {
 	urn := res.CreateURN()
 
@@ -65,6 +87,7 @@ func (s Service) Create(ctx context.Context, res Resource) (Resource, error) {
 		ProjectID:      res.ProjectID,
 		NamespaceID:    res.NamespaceID,
 		UserID:         userId,
+		Tags:           res.Tags,
 	})
 	if err != nil {
 		return Resource{}, err
@@ -82,6 +105,21 @@ func (s Service) Create(ctx context.Context, res Resource) (Resource, error) {
 		return Resource{}, err
 	}
 
+	// an existing resource created again stays in its folder
+	if newResource.FolderID != "" {
+		if err = s.AddFolderToResource(ctx, newResource.FolderID, newResource); err != nil {
+			return Resource{}, err
+		}
+	}
+
+	if err = s.policyService.AddObject(ctx, newResource.NamespaceID, newResource.Idxa); err != nil {
+		return Resource{}, err
+	}
+
+	if err = s.syncResourceTags(ctx, newResource, true); err != nil {
+		return Resource{}, err
+	}
+
 	return newResource, nil
 }
 
@@ -91,7 +129,22 @@ func (s Service) List(ctx context.Context, flt Filter) ([]Resource, error) {
 
 func (s Service) Update(ctx context.Context, id string, resource Resource) (Resource, error) {
 	// TODO there should be an update logic like create here
-	return s.repository.Update(ctx, id, resource)
+	updated, err := s.repository.Update(ctx, id, resource)
+	if err != nil {
+		return Resource{}, err
+	}
+	if resource.Tags != nil {
+		if err := s.syncResourceTags(ctx, updated, false); err != nil {
+			return Resource{}, err
+		}
+	}
+	return updated, nil
+}
+
+// SetFolder sets the folder the resource is in, the relation of the resource
+// to the folder is up to the caller
+func (s Service) SetFolder(ctx context.Context, id, folderID string) (Resource, error) {
+	return s.repository.SetFolder(ctx, id, folderID)
 }
 
 func (s Service) AddProjectToResource(ctx context.Context, project project.Project, res Resource) error {
@@ -133,6 +186,25 @@ func (s Service) AddOrgToResource(ctx context.Context, org organization.Organiza
 	return nil
 }
 
+func (s Service) AddFolderToResource(ctx context.Context, folderID string, res Resource) error {
+	rel := relation.RelationV2{
+		Object: relation.Object{
+			ID:          res.Idxa,
+			NamespaceID: res.NamespaceID,
+		},
+		Subject: relation.Subject{
+			RoleID:    schema.FolderRelationName,
+			ID:        folderID,
+			Namespace: schema.FolderNamespace,
+		},
+	}
+
+	if _, err := s.relationService.Create(ctx, rel); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s Service) AddTeamToResource(ctx context.Context, team group.Group, res Resource) error {
 	//resourceNS := namespace.Namespace{
 	//	ID: res.NamespaceID,
@@ -200,18 +272,160 @@ func (s Service) CheckAuthz(ctx context.Context, res Resource, act action.Action
 		return false, err
 	}
 
-	isSystemNS := namespace.IsSystemNamespaceID(res.NamespaceID)
-	fetchedResource := res
+	return s.checkAuthz(ctx, currentUser, res, act)
+}
+
+// CheckAuthzBatch checks the permissions of the current user on up to
+// MaxBatchChecks resources, the checks run concurrently and the results
+// are in the order of the checks. A resource that doesn't exist is denied
+// instead of failing the whole batch.
+func (s Service) CheckAuthzBatch(ctx context.Context, checks []Check) ([]bool, error) {
+	if len(checks) > MaxBatchChecks {
+		return nil, fmt.Errorf("%w: %d checks, at most %d are allowed", ErrTooManyChecks, len(checks), MaxBatchChecks)
+	}
+
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]bool, len(checks))
+	errs := make([]error, len(checks))
+	sem := make(chan struct{}, batchCheckConcurrency)
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = s.checkAuthz(ctx, currentUser, c.Resource, c.Action)
+			if errors.Is(errs[i], ErrNotExist) {
+				results[i], errs[i] = false, nil
+			}
+		}(i, c)
+	}
+	wg.Wait()
 
-	if isSystemNS {
-		fetchedResource.Idxa = res.Name
-	} else {
-		fetchedResource, err = s.repository.GetByNamespace(ctx, res.Name, res.NamespaceID)
+	for _, err := range errs {
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 	}
+	return results, nil
+}
+
+func (s Service) checkAuthz(ctx context.Context, usr user.User, res Resource, act action.Action) (bool, error) {
+	fetchedResource, err := s.authzResource(ctx, res)
+	if err != nil {
+		return false, err
+	}
+
+	// the conditions of the policies are evaluated against the tags of
+	// the resource
+	if len(fetchedResource.Tags) > 0 {
+		checkCtx := relation.CheckContextFromContext(ctx)
+		checkCtx.Resource = fetchedResource.Tags
+		ctx = relation.WithCheckContext(ctx, checkCtx)
+	}
 
 	fetchedResourceNS := namespace.Namespace{ID: fetchedResource.NamespaceID}
-	return s.relationService.CheckPermission(ctx, currentUser, fetchedResourceNS, fetchedResource.Idxa, act)
+	return s.relationService.CheckPermission(ctx, usr, fetchedResourceNS, fetchedResource.Idxa, act)
+}
+
+// authzResource returns the resource with the idxa its relations are
+// written with, the objects of the system namespaces are referred to by id
+func (s Service) authzResource(ctx context.Context, res Resource) (Resource, error) {
+	if namespace.IsSystemNamespaceID(res.NamespaceID) {
+		res.Idxa = res.Name
+		return res, nil
+	}
+	return s.repository.GetByNamespace(ctx, res.Name, res.NamespaceID)
+}
+
+// ExpandAccess returns the tree of the relations, roles and groups through
+// which the subjects get the action on the resource. Only the users allowed
+// the action can see who else is.
+func (s Service) ExpandAccess(ctx context.Context, res Resource, act action.Action) (relation.AccessTree, error) {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+
+	allowed, err := s.checkAuthz(ctx, currentUser, res, act)
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+	if !allowed {
+		return relation.AccessTree{}, shielderrors.ErrForbidden
+	}
+
+	fetchedResource, err := s.authzResource(ctx, res)
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+	return s.relationService.Expand(ctx, relation.Object{ID: fetchedResource.Idxa, NamespaceID: fetchedResource.NamespaceID}, act.ID)
+}
+
+// ListUserResources returns a page of the resources of the namespace the
+// user is allowed the action on, ordered by their idxa. Users can only list
+// their own resources, userID is the id or email of the user and the
+// current user when empty.
+func (s Service) ListUserResources(ctx context.Context, userID, ns string, act action.Action, limit, page int32) (PagedResources, error) {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return PagedResources{}, err
+	}
+	if userID != "" && userID != currentUser.ID && userID != currentUser.Email {
+		return PagedResources{}, shielderrors.ErrForbidden
+	}
+
+	ids, err := s.relationService.LookupResources(ctx, ns, act.ID, relation.Subject{ID: currentUser.ID, Namespace: schema.UserPrincipal})
+	if err != nil {
+		return PagedResources{}, err
+	}
+	sort.Strings(ids)
+
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if page < 1 {
+		page = 1
+	}
+	start, end := int((page-1)*limit), int(page*limit)
+	if start > len(ids) {
+		start = len(ids)
+	}
+	if end > len(ids) {
+		end = len(ids)
+	}
+	pageIDs := ids[start:end]
+
+	resources := []Resource{}
+	if namespace.IsSystemNamespaceID(ns) {
+		for _, id := range pageIDs {
+			resources = append(resources, Resource{Idxa: id, URN: id, Name: id, NamespaceID: ns})
+		}
+	} else if len(pageIDs) > 0 {
+		fetched, err := s.repository.List(ctx, Filter{IDs: pageIDs, NamespaceID: ns})
+		if err != nil {
+			return PagedResources{}, err
+		}
+		byID := map[string]Resource{}
+		for _, res := range fetched {
+			byID[res.Idxa] = res
+		}
+		// the relations of resources being purged may outlive them
+		for _, id := range pageIDs {
+			if res, ok := byID[id]; ok {
+				resources = append(resources, res)
+			}
+		}
+	}
+
+	return PagedResources{
+		Count:     int32(len(ids)),
+		Resources: resources,
+	}, nil
 }
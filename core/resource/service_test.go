@@ -0,0 +1,220 @@
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/user"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepository struct {
+	resource.Repository
+	// resources by namespace and name
+	resources map[string]resource.Resource
+}
+
+func (r memoryRepository) GetByNamespace(ctx context.Context, name string, ns string) (resource.Resource, error) {
+	res, ok := r.resources[ns+"/"+name]
+	if !ok {
+		return resource.Resource{}, resource.ErrNotExist
+	}
+	return res, nil
+}
+
+func (r memoryRepository) List(ctx context.Context, flt resource.Filter) ([]resource.Resource, error) {
+	var resources []resource.Resource
+	for _, res := range r.resources {
+		for _, id := range flt.IDs {
+			if res.Idxa == id && res.NamespaceID == flt.NamespaceID {
+				resources = append(resources, res)
+			}
+		}
+	}
+	return resources, nil
+}
+
+type memoryRelationService struct {
+	resource.RelationService
+	// allowed are the actions the users can take on the resources, keyed by
+	// user id, resource namespace, resource idxa and action
+	allowed map[string]bool
+	err     error
+}
+
+func (s memoryRelationService) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, act action.Action) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.allowed[usr.ID+":"+resourceNS.ID+":"+resourceIdxa+":"+act.ID], nil
+}
+
+func (s memoryRelationService) Expand(ctx context.Context, obj relation.Object, permission string) (relation.AccessTree, error) {
+	return relation.AccessTree{Object: obj, Relation: permission}, nil
+}
+
+func (s memoryRelationService) LookupResources(ctx context.Context, namespaceID, permission string, sub relation.Subject) ([]string, error) {
+	var ids []string
+	for key, allowed := range s.allowed {
+		var userID, ns, idxa, act string
+		if allowed && splitKey(key, &userID, &ns, &idxa, &act) && userID == sub.ID && ns == namespaceID && act == permission {
+			ids = append(ids, idxa)
+		}
+	}
+	return ids, nil
+}
+
+// splitKey splits the key of an allowed action into its parts, the
+// namespaces have no colons
+func splitKey(key string, parts ...*string) bool {
+	fields := strings.Split(key, ":")
+	if len(fields) != len(parts) {
+		return false
+	}
+	for i, f := range fields {
+		*parts[i] = f
+	}
+	return true
+}
+
+type currentUserService struct {
+	usr user.User
+}
+
+func (s currentUserService) FetchCurrentUser(ctx context.Context) (user.User, error) {
+	return s.usr, nil
+}
+
+func (s currentUserService) Get(ctx context.Context, idOrEmail string) (user.User, error) {
+	if idOrEmail != s.usr.ID && idOrEmail != s.usr.Email {
+		return user.User{}, user.ErrNotExist
+	}
+	return s.usr, nil
+}
+
+func TestServiceCheckAuthzBatch(t *testing.T) {
+	repository := memoryRepository{resources: map[string]resource.Resource{
+		"entropy/firehose/f1": {Idxa: "r1", Name: "f1", NamespaceID: "entropy/firehose"},
+		"entropy/firehose/f2": {Idxa: "r2", Name: "f2", NamespaceID: "entropy/firehose"},
+	}}
+	relationService := memoryRelationService{allowed: map[string]bool{
+		"u1:entropy/firehose:r1:view": true,
+		"u1:entropy/firehose:r2:edit": true,
+	}}
+	userService := currentUserService{usr: user.User{ID: "u1"}}
+
+	check := func(name, act string) resource.Check {
+		return resource.Check{
+			Resource: resource.Resource{Name: name, NamespaceID: "entropy/firehose"},
+			Action:   action.Action{ID: act},
+		}
+	}
+
+	t.Run("should return the result of every check in order", func(t *testing.T) {
+		s := resource.NewService(repository, nil, nil, relationService, userService, nil)
+
+		got, err := s.CheckAuthzBatch(context.Background(), []resource.Check{
+			check("f1", "view"),
+			check("f1", "edit"),
+			check("f2", "edit"),
+			check("f3", "view"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []bool{true, false, true, false}, got)
+	})
+
+	t.Run("should return error if there are too many checks", func(t *testing.T) {
+		s := resource.NewService(repository, nil, nil, relationService, userService, nil)
+
+		checks := make([]resource.Check, resource.MaxBatchChecks+1)
+		_, err := s.CheckAuthzBatch(context.Background(), checks)
+		assert.ErrorIs(t, err, resource.ErrTooManyChecks)
+	})
+
+	t.Run("should return error if a check fails", func(t *testing.T) {
+		expectedErr := errors.New("spicedb unavailable")
+		s := resource.NewService(repository, nil, nil, memoryRelationService{err: expectedErr}, userService, nil)
+
+		_, err := s.CheckAuthzBatch(context.Background(), []resource.Check{check("f1", "view")})
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestServiceExpandAccess(t *testing.T) {
+	repository := memoryRepository{resources: map[string]resource.Resource{
+		"entropy/firehose/f1": {Idxa: "r1", Name: "f1", NamespaceID: "entropy/firehose"},
+	}}
+	relationService := memoryRelationService{allowed: map[string]bool{
+		"u1:entropy/firehose:r1:view": true,
+	}}
+	userService := currentUserService{usr: user.User{ID: "u1"}}
+	s := resource.NewService(repository, nil, nil, relationService, userService, nil)
+	firehose := resource.Resource{Name: "f1", NamespaceID: "entropy/firehose"}
+
+	t.Run("should expand the action on the resource by its idxa", func(t *testing.T) {
+		got, err := s.ExpandAccess(context.Background(), firehose, action.Action{ID: "view"})
+		assert.NoError(t, err)
+		assert.Equal(t, relation.AccessTree{Object: relation.Object{ID: "r1", NamespaceID: "entropy/firehose"}, Relation: "view"}, got)
+	})
+
+	t.Run("should return error if the current user isn't allowed the action", func(t *testing.T) {
+		_, err := s.ExpandAccess(context.Background(), firehose, action.Action{ID: "edit"})
+		assert.ErrorIs(t, err, shielderrors.ErrForbidden)
+	})
+
+	t.Run("should return error if the resource doesn't exist", func(t *testing.T) {
+		_, err := s.ExpandAccess(context.Background(), resource.Resource{Name: "f2", NamespaceID: "entropy/firehose"}, action.Action{ID: "view"})
+		assert.ErrorIs(t, err, resource.ErrNotExist)
+	})
+}
+
+func TestServiceListUserResources(t *testing.T) {
+	repository := memoryRepository{resources: map[string]resource.Resource{
+		"entropy/firehose/f1": {Idxa: "r1", Name: "f1", NamespaceID: "entropy/firehose"},
+		"entropy/firehose/f2": {Idxa: "r2", Name: "f2", NamespaceID: "entropy/firehose"},
+		"entropy/firehose/f3": {Idxa: "r3", Name: "f3", NamespaceID: "entropy/firehose"},
+	}}
+	relationService := memoryRelationService{allowed: map[string]bool{
+		"u1:entropy/firehose:r3:view": true,
+		"u1:entropy/firehose:r1:view": true,
+		"u1:entropy/firehose:r2:edit": true,
+		"u1:shield/project:p1:view":   true,
+		"u2:entropy/firehose:r2:view": true,
+	}}
+	userService := currentUserService{usr: user.User{ID: "u1", Email: "u1@odpf.io"}}
+	s := resource.NewService(repository, nil, nil, relationService, userService, nil)
+	view := action.Action{ID: "view"}
+
+	t.Run("should list a page of the resources the user is allowed the action on", func(t *testing.T) {
+		got, err := s.ListUserResources(context.Background(), "u1", "entropy/firehose", view, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, resource.PagedResources{
+			Count:     2,
+			Resources: []resource.Resource{repository.resources["entropy/firehose/f3"]},
+		}, got)
+	})
+
+	t.Run("should list the objects of the system namespaces by id", func(t *testing.T) {
+		got, err := s.ListUserResources(context.Background(), "u1@odpf.io", "shield/project", view, 0, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []resource.Resource{{Idxa: "p1", URN: "p1", Name: "p1", NamespaceID: "shield/project"}}, got.Resources)
+	})
+
+	t.Run("should return an empty page past the last one", func(t *testing.T) {
+		got, err := s.ListUserResources(context.Background(), "", "entropy/firehose", view, 50, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, resource.PagedResources{Count: 2, Resources: []resource.Resource{}}, got)
+	})
+
+	t.Run("should return error if the user isn't the current user", func(t *testing.T) {
+		_, err := s.ListUserResources(context.Background(), "u2", "entropy/firehose", view, 0, 0)
+		assert.ErrorIs(t, err, shielderrors.ErrForbidden)
+	})
+}
@@ -0,0 +1,258 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/schema"
+)
+
+var ErrInvalidTags = errors.New("tags should be comma separated key=value pairs")
+
+type TagPolicyRepository interface {
+	Create(ctx context.Context, pol TagPolicy) (TagPolicy, error)
+	Get(ctx context.Context, id string) (TagPolicy, error)
+	List(ctx context.Context, namespaceID string) ([]TagPolicy, error)
+	Delete(ctx context.Context, id string) error
+	// ListGrants returns the ids of the resources the policy granted the
+	// role on
+	ListGrants(ctx context.Context, policyID string) ([]string, error)
+	AddGrant(ctx context.Context, policyID, resourceID string) error
+	RemoveGrant(ctx context.Context, policyID, resourceID string) error
+}
+
+// TagPolicy grants the role of the namespace to the subject on every
+// resource of the namespace carrying all of the tags. The policy is expanded
+// into relations when it is created, as resources are tagged and on sync,
+// the resources the relations were made on are kept as its grants.
+type TagPolicy struct {
+	ID          string
+	NamespaceID string
+	Tags        map[string]string
+	// RoleID is the name of the role in the namespace
+	RoleID    string
+	Subject   relation.Subject
+	CreatedAt time.Time
+}
+
+// Matches tells if the tags carry all of the tags of the policy
+func (p TagPolicy) Matches(tags map[string]string) bool {
+	for k, v := range p.Tags {
+		if value, ok := tags[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseTags parses comma separated key=value pairs, an empty string is
+// no tags
+func ParseTags(s string) (map[string]string, error) {
+	tags := map[string]string{}
+	if strings.TrimSpace(s) == "" {
+		return tags, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTags, pair)
+		}
+		tags[key] = strings.TrimSpace(value)
+	}
+	return tags, nil
+}
+
+// FormatTags formats the tags as ParseTags parses them, sorted by key
+func FormatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// CreateTagPolicy creates the policy and grants the role on the resources
+// of the namespace it matches, users are referred to by id or email
+func (s Service) CreateTagPolicy(ctx context.Context, pol TagPolicy) (TagPolicy, error) {
+	if len(pol.Tags) == 0 {
+		return TagPolicy{}, fmt.Errorf("%w: tag policy has no tags", ErrInvalidDetail)
+	}
+	if pol.NamespaceID == "" || pol.RoleID == "" || pol.Subject.ID == "" || pol.Subject.Namespace == "" {
+		return TagPolicy{}, fmt.Errorf("%w: tag policy needs a namespace, role and subject", ErrInvalidDetail)
+	}
+	if pol.Subject.Namespace == schema.UserPrincipal {
+		usr, err := s.userService.Get(ctx, pol.Subject.ID)
+		if err != nil {
+			return TagPolicy{}, err
+		}
+		pol.Subject.ID = usr.ID
+	}
+	pol.Subject.RoleID = ""
+
+	created, err := s.tagPolicyRepository.Create(ctx, pol)
+	if err != nil {
+		return TagPolicy{}, err
+	}
+	if _, err := s.syncTagPolicy(ctx, created); err != nil {
+		return TagPolicy{}, err
+	}
+	return created, nil
+}
+
+func (s Service) ListTagPolicies(ctx context.Context, namespaceID string) ([]TagPolicy, error) {
+	return s.tagPolicyRepository.List(ctx, namespaceID)
+}
+
+// DeleteTagPolicy revokes the role the policy granted and deletes it
+func (s Service) DeleteTagPolicy(ctx context.Context, id string) error {
+	pol, err := s.tagPolicyRepository.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	granted, err := s.tagPolicyRepository.ListGrants(ctx, pol.ID)
+	if err != nil {
+		return err
+	}
+	for _, resourceID := range granted {
+		if err := s.revokeTagPolicy(ctx, pol, resourceID); err != nil {
+			return err
+		}
+	}
+	return s.tagPolicyRepository.Delete(ctx, pol.ID)
+}
+
+// SyncTagPolicies grants and revokes the roles of every tag policy so the
+// grants follow the tags of the resources, it returns the number of grants
+// made and revoked
+func (s Service) SyncTagPolicies(ctx context.Context) (int, error) {
+	policies, err := s.tagPolicyRepository.List(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	changes := 0
+	for _, pol := range policies {
+		n, err := s.syncTagPolicy(ctx, pol)
+		changes += n
+		if err != nil {
+			return changes, err
+		}
+	}
+	return changes, nil
+}
+
+func (s Service) syncTagPolicy(ctx context.Context, pol TagPolicy) (int, error) {
+	matching, err := s.repository.List(ctx, Filter{NamespaceID: pol.NamespaceID, Tags: pol.Tags})
+	if err != nil {
+		return 0, err
+	}
+	grants, err := s.tagPolicyRepository.ListGrants(ctx, pol.ID)
+	if err != nil {
+		return 0, err
+	}
+	granted := map[string]bool{}
+	for _, id := range grants {
+		granted[id] = true
+	}
+
+	changes := 0
+	for _, res := range matching {
+		if granted[res.Idxa] {
+			delete(granted, res.Idxa)
+			continue
+		}
+		if err := s.grantTagPolicy(ctx, pol, res.Idxa); err != nil {
+			return changes, err
+		}
+		changes++
+	}
+	for _, id := range grants {
+		if !granted[id] {
+			continue
+		}
+		if err := s.revokeTagPolicy(ctx, pol, id); err != nil {
+			return changes, err
+		}
+		changes++
+	}
+	return changes, nil
+}
+
+// syncResourceTags grants and revokes the roles of the tag policies of the
+// namespace of the resource after its tags are set. The relations of a
+// resource created again are deleted from spicedb, regrant makes the roles
+// granted on it again.
+func (s Service) syncResourceTags(ctx context.Context, res Resource, regrant bool) error {
+	policies, err := s.tagPolicyRepository.List(ctx, res.NamespaceID)
+	if err != nil {
+		return err
+	}
+	for _, pol := range policies {
+		grants, err := s.tagPolicyRepository.ListGrants(ctx, pol.ID)
+		if err != nil {
+			return err
+		}
+		granted := false
+		for _, id := range grants {
+			if id == res.Idxa {
+				granted = true
+				break
+			}
+		}
+
+		switch matches := pol.Matches(res.Tags); {
+		case matches && (!granted || regrant):
+			err = s.grantTagPolicy(ctx, pol, res.Idxa)
+		case !matches && granted:
+			err = s.revokeTagPolicy(ctx, pol, res.Idxa)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Service) grantTagPolicy(ctx context.Context, pol TagPolicy, resourceID string) error {
+	rel := tagPolicyRelation(pol, resourceID)
+	// relations to users are created with their email
+	if rel.Subject.Namespace == schema.UserPrincipal {
+		usr, err := s.userService.Get(ctx, rel.Subject.ID)
+		if err != nil {
+			return err
+		}
+		rel.Subject.ID = usr.Email
+	}
+	if _, err := s.relationService.Create(ctx, rel); err != nil {
+		return err
+	}
+	return s.tagPolicyRepository.AddGrant(ctx, pol.ID, resourceID)
+}
+
+func (s Service) revokeTagPolicy(ctx context.Context, pol TagPolicy, resourceID string) error {
+	// the relation may have been deleted directly already
+	if err := s.relationService.DeleteV2(ctx, tagPolicyRelation(pol, resourceID)); err != nil && !errors.Is(err, relation.ErrNotExist) {
+		return err
+	}
+	return s.tagPolicyRepository.RemoveGrant(ctx, pol.ID, resourceID)
+}
+
+func tagPolicyRelation(pol TagPolicy, resourceID string) relation.RelationV2 {
+	return relation.RelationV2{
+		Object: relation.Object{
+			ID:          resourceID,
+			NamespaceID: pol.NamespaceID,
+		},
+		Subject: relation.Subject{
+			ID:        pol.Subject.ID,
+			Namespace: pol.Subject.Namespace,
+			RoleID:    pol.RoleID,
+		},
+	}
+}
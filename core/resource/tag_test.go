@@ -0,0 +1,227 @@
+package resource_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type taggedRepository struct {
+	resource.Repository
+	// resources by idxa
+	resources map[string]resource.Resource
+}
+
+func (r *taggedRepository) List(ctx context.Context, flt resource.Filter) ([]resource.Resource, error) {
+	var resources []resource.Resource
+	for _, res := range r.resources {
+		if res.NamespaceID == flt.NamespaceID && (resource.TagPolicy{Tags: flt.Tags}).Matches(res.Tags) {
+			resources = append(resources, res)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Idxa < resources[j].Idxa })
+	return resources, nil
+}
+
+func (r *taggedRepository) Update(ctx context.Context, id string, res resource.Resource) (resource.Resource, error) {
+	updated, ok := r.resources[id]
+	if !ok {
+		return resource.Resource{}, resource.ErrNotExist
+	}
+	updated.Tags = res.Tags
+	r.resources[id] = updated
+	return updated, nil
+}
+
+type memoryTagPolicyRepository struct {
+	policies map[string]resource.TagPolicy
+	// grants by policy id and resource id
+	grants map[string]map[string]bool
+}
+
+func (r *memoryTagPolicyRepository) Create(ctx context.Context, pol resource.TagPolicy) (resource.TagPolicy, error) {
+	pol.ID = "tp" + strconv.Itoa(len(r.policies)+1)
+	r.policies[pol.ID] = pol
+	r.grants[pol.ID] = map[string]bool{}
+	return pol, nil
+}
+
+func (r *memoryTagPolicyRepository) Get(ctx context.Context, id string) (resource.TagPolicy, error) {
+	pol, ok := r.policies[id]
+	if !ok {
+		return resource.TagPolicy{}, resource.ErrTagPolicyNotExist
+	}
+	return pol, nil
+}
+
+func (r *memoryTagPolicyRepository) List(ctx context.Context, namespaceID string) ([]resource.TagPolicy, error) {
+	var policies []resource.TagPolicy
+	for _, pol := range r.policies {
+		if namespaceID == "" || pol.NamespaceID == namespaceID {
+			policies = append(policies, pol)
+		}
+	}
+	return policies, nil
+}
+
+func (r *memoryTagPolicyRepository) Delete(ctx context.Context, id string) error {
+	delete(r.policies, id)
+	delete(r.grants, id)
+	return nil
+}
+
+func (r *memoryTagPolicyRepository) ListGrants(ctx context.Context, policyID string) ([]string, error) {
+	var ids []string
+	for id := range r.grants[policyID] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (r *memoryTagPolicyRepository) AddGrant(ctx context.Context, policyID, resourceID string) error {
+	r.grants[policyID][resourceID] = true
+	return nil
+}
+
+func (r *memoryTagPolicyRepository) RemoveGrant(ctx context.Context, policyID, resourceID string) error {
+	delete(r.grants[policyID], resourceID)
+	return nil
+}
+
+type recordingRelationService struct {
+	resource.RelationService
+	created []relation.RelationV2
+	deleted []relation.RelationV2
+}
+
+func (s *recordingRelationService) Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	s.created = append(s.created, rel)
+	return rel, nil
+}
+
+func (s *recordingRelationService) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	s.deleted = append(s.deleted, rel)
+	return nil
+}
+
+func TestParseTags(t *testing.T) {
+	tags, err := resource.ParseTags("env=prod, team = data,empty=")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "data", "empty": ""}, tags)
+	assert.Equal(t, "empty=,env=prod,team=data", resource.FormatTags(tags))
+
+	tags, err = resource.ParseTags(" ")
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	_, err = resource.ParseTags("env=prod,team")
+	assert.ErrorIs(t, err, resource.ErrInvalidTags)
+}
+
+func TestServiceTagPolicies(t *testing.T) {
+	jane := user.User{ID: "u1", Email: "jane@odpf.io"}
+	newService := func() (*resource.Service, *taggedRepository, *memoryTagPolicyRepository, *recordingRelationService) {
+		repository := &taggedRepository{resources: map[string]resource.Resource{
+			"r1": {Idxa: "r1", NamespaceID: "entropy/firehose", Tags: map[string]string{"env": "prod", "team": "data"}},
+			"r2": {Idxa: "r2", NamespaceID: "entropy/firehose", Tags: map[string]string{"env": "staging", "team": "data"}},
+			"r3": {Idxa: "r3", NamespaceID: "guardian/appeal", Tags: map[string]string{"env": "prod"}},
+		}}
+		policies := &memoryTagPolicyRepository{policies: map[string]resource.TagPolicy{}, grants: map[string]map[string]bool{}}
+		relations := &recordingRelationService{}
+		return resource.NewService(repository, nil, policies, relations, currentUserService{usr: jane}, nil), repository, policies, relations
+	}
+	prodViewers := resource.TagPolicy{
+		NamespaceID: "entropy/firehose",
+		Tags:        map[string]string{"env": "prod"},
+		RoleID:      "viewer",
+		Subject:     relation.Subject{ID: "jane@odpf.io", Namespace: schema.UserPrincipal},
+	}
+	// relations to users are created by email and deleted by id
+	grant := func(resourceID string) relation.RelationV2 {
+		return relation.RelationV2{
+			Object:  relation.Object{ID: resourceID, NamespaceID: "entropy/firehose"},
+			Subject: relation.Subject{ID: "jane@odpf.io", Namespace: schema.UserPrincipal, RoleID: "viewer"},
+		}
+	}
+	revoke := func(resourceID string) relation.RelationV2 {
+		rel := grant(resourceID)
+		rel.Subject.ID = "u1"
+		return rel
+	}
+
+	t.Run("should grant the role on the resources of the namespace with the tags", func(t *testing.T) {
+		s, _, policies, relations := newService()
+
+		created, err := s.CreateTagPolicy(context.Background(), prodViewers)
+		require.NoError(t, err)
+		assert.Equal(t, "u1", created.Subject.ID)
+		assert.Equal(t, []relation.RelationV2{grant("r1")}, relations.created)
+		assert.Equal(t, map[string]bool{"r1": true}, policies.grants[created.ID])
+	})
+
+	t.Run("should return error if the policy has no tags", func(t *testing.T) {
+		s, _, _, _ := newService()
+
+		_, err := s.CreateTagPolicy(context.Background(), resource.TagPolicy{
+			NamespaceID: "entropy/firehose",
+			RoleID:      "viewer",
+			Subject:     prodViewers.Subject,
+		})
+		assert.ErrorIs(t, err, resource.ErrInvalidDetail)
+	})
+
+	t.Run("should follow the tags of an updated resource", func(t *testing.T) {
+		s, _, policies, relations := newService()
+		created, err := s.CreateTagPolicy(context.Background(), prodViewers)
+		require.NoError(t, err)
+
+		_, err = s.Update(context.Background(), "r2", resource.Resource{Tags: map[string]string{"env": "prod"}})
+		require.NoError(t, err)
+		_, err = s.Update(context.Background(), "r1", resource.Resource{Tags: map[string]string{}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []relation.RelationV2{grant("r1"), grant("r2")}, relations.created)
+		assert.Equal(t, []relation.RelationV2{revoke("r1")}, relations.deleted)
+		assert.Equal(t, map[string]bool{"r2": true}, policies.grants[created.ID])
+	})
+
+	t.Run("should grant and revoke the roles the tags changed on sync", func(t *testing.T) {
+		s, repository, policies, relations := newService()
+		created, err := s.CreateTagPolicy(context.Background(), prodViewers)
+		require.NoError(t, err)
+
+		// tags changed without the service
+		r1 := repository.resources["r1"]
+		r1.Tags = map[string]string{"env": "staging"}
+		repository.resources["r1"] = r1
+		r2 := repository.resources["r2"]
+		r2.Tags = map[string]string{"env": "prod"}
+		repository.resources["r2"] = r2
+
+		changes, err := s.SyncTagPolicies(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, changes)
+		assert.Equal(t, []relation.RelationV2{grant("r1"), grant("r2")}, relations.created)
+		assert.Equal(t, []relation.RelationV2{revoke("r1")}, relations.deleted)
+		assert.Equal(t, map[string]bool{"r2": true}, policies.grants[created.ID])
+	})
+
+	t.Run("should revoke the roles granted when the policy is deleted", func(t *testing.T) {
+		s, _, policies, relations := newService()
+		created, err := s.CreateTagPolicy(context.Background(), prodViewers)
+		require.NoError(t, err)
+
+		require.NoError(t, s.DeleteTagPolicy(context.Background(), created.ID))
+		assert.Equal(t, []relation.RelationV2{revoke("r1")}, relations.deleted)
+		assert.Empty(t, policies.policies)
+	})
+}
@@ -3,8 +3,11 @@ package role
 import "errors"
 
 var (
-	ErrNotExist      = errors.New("role doesn't exist")
-	ErrInvalidID     = errors.New("role id is invalid")
-	ErrConflict      = errors.New("role name already exist")
-	ErrInvalidDetail = errors.New("invalid role detail")
+	ErrNotExist              = errors.New("role doesn't exist")
+	ErrInvalidID             = errors.New("role id is invalid")
+	ErrConflict              = errors.New("role name already exist")
+	ErrInvalidDetail         = errors.New("invalid role detail")
+	ErrInUse                 = errors.New("role is still referenced by other resources")
+	ErrNamespaceNotPermitted = errors.New("namespace is not permitted for organization roles")
+	ErrCyclicInclude         = errors.New("role includes itself")
 )
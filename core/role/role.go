@@ -15,6 +15,14 @@ type Repository interface {
 	List(ctx context.Context) ([]Role, error)
 	Create(ctx context.Context, role Role) (string, error)
 	Update(ctx context.Context, toUpdate Role) (string, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// AuthzRepository keeps the custom roles of the organizations as relations
+// of their namespace in the authz engine
+type AuthzRepository interface {
+	Add(ctx context.Context, roles []Role) error
+	Remove(ctx context.Context, roles []Role) error
 }
 
 type Role struct {
@@ -22,9 +30,15 @@ type Role struct {
 	Name        string
 	Types       []string
 	NamespaceID string
-	Metadata    metadata.Metadata
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	// OrgID is set for the custom roles of an organization, such roles
+	// can only be granted on the organization and the objects within it
+	OrgID string
+	// Includes are the ids of the roles of the same namespace this role
+	// includes, the role is granted every action they are granted
+	Includes  []string
+	Metadata  metadata.Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 func GetOwnerRole(ns namespace.Namespace) Role {
@@ -2,21 +2,103 @@ package role
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/user"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+	"github.com/odpf/shield/pkg/uuid"
 )
 
+// editPermission is the permission of the admins of an organization, the
+// schema package refers to roles so its definitions can't be used here
+const editPermission = "edit"
+
+type NamespaceService interface {
+	Get(ctx context.Context, id string) (namespace.Namespace, error)
+}
+
+type RelationService interface {
+	CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, action action.Action) (bool, error)
+}
+
+type UserService interface {
+	FetchCurrentUser(ctx context.Context) (user.User, error)
+}
+
+type PolicyService interface {
+	SyncGrants(ctx context.Context, change func() error) error
+}
+
 type Service struct {
-	repository Repository
+	repository       Repository
+	authzRepository  AuthzRepository
+	namespaceService NamespaceService
+	relationService  RelationService
+	userService      UserService
+	policyService    PolicyService
 }
 
-func NewService(repository Repository) *Service {
+func NewService(repository Repository, authzRepository AuthzRepository, namespaceService NamespaceService, relationService RelationService, userService UserService, policyService PolicyService) *Service {
 	return &Service{
-		repository: repository,
+		repository:       repository,
+		authzRepository:  authzRepository,
+		namespaceService: namespaceService,
+		relationService:  relationService,
+		userService:      userService,
+		policyService:    policyService,
 	}
 }
 
+// Create creates the role, a role including other roles is granted
+// their actions in the authz engine
 func (s Service) Create(ctx context.Context, toCreate Role) (Role, error) {
-	roleID, err := s.repository.Create(ctx, toCreate)
-	if err != nil {
+	if err := validateSchemaNames(toCreate); err != nil {
+		return Role{}, err
+	}
+	if toCreate.OrgID != "" {
+		if err := s.validateOrgRole(ctx, toCreate); err != nil {
+			return Role{}, err
+		}
+		// roles are upserted, a custom role can't take over an existing one
+		if _, err := s.repository.Get(ctx, toCreate.ID); err == nil {
+			return Role{}, ErrConflict
+		} else if !errors.Is(err, ErrNotExist) {
+			return Role{}, err
+		}
+	}
+	if len(toCreate.Includes) > 0 {
+		if err := s.validateIncludes(ctx, toCreate); err != nil {
+			return Role{}, err
+		}
+	}
+
+	// the relation of a custom role has to be in the authz schema before
+	// the actions of the roles it includes can be granted to it
+	if toCreate.OrgID != "" {
+		if err := s.authzRepository.Add(ctx, []Role{toCreate}); err != nil {
+			return Role{}, err
+		}
+	}
+
+	var roleID string
+	if err := s.syncGrants(ctx, len(toCreate.Includes) > 0, func() (err error) {
+		roleID, err = s.repository.Create(ctx, toCreate)
+		return err
+	}); err != nil {
+		if roleID != "" {
+			if rbErr := s.repository.Delete(ctx, roleID); rbErr != nil {
+				return Role{}, fmt.Errorf("%s, deleting role: %s", err.Error(), rbErr.Error())
+			}
+		}
+		if toCreate.OrgID != "" {
+			if rbErr := s.authzRepository.Remove(ctx, []Role{toCreate}); rbErr != nil {
+				return Role{}, fmt.Errorf("%s, removing role from authz engine: %s", err.Error(), rbErr.Error())
+			}
+		}
 		return Role{}, err
 	}
 	return s.repository.Get(ctx, roleID)
@@ -30,10 +112,246 @@ func (s Service) List(ctx context.Context) ([]Role, error) {
 	return s.repository.List(ctx)
 }
 
+// Update edits the role, the organization of a custom role can't be changed
+// and only its admins can edit it. The included roles are kept unless
+// Includes is set, an empty Includes removes them.
 func (s Service) Update(ctx context.Context, toUpdate Role) (Role, error) {
-	roleID, err := s.repository.Update(ctx, toUpdate)
+	existing, err := s.repository.Get(ctx, toUpdate.ID)
 	if err != nil {
 		return Role{}, err
 	}
+
+	if err := validateSchemaNames(toUpdate); err != nil {
+		return Role{}, err
+	}
+
+	toUpdate.OrgID = existing.OrgID
+	if toUpdate.OrgID != "" {
+		if err := s.validateOrgRole(ctx, toUpdate); err != nil {
+			return Role{}, err
+		}
+	}
+
+	if toUpdate.Includes == nil {
+		toUpdate.Includes = existing.Includes
+	}
+	includesChanged := !sameStrings(existing.Includes, toUpdate.Includes)
+	if includesChanged {
+		if err := s.validateIncludes(ctx, toUpdate); err != nil {
+			return Role{}, err
+		}
+	}
+
+	typesChanged := toUpdate.OrgID != "" && !sameStrings(existing.Types, toUpdate.Types)
+	if typesChanged {
+		if err := s.authzRepository.Add(ctx, []Role{toUpdate}); err != nil {
+			return Role{}, err
+		}
+	}
+
+	var roleID string
+	if err := s.syncGrants(ctx, includesChanged, func() (err error) {
+		roleID, err = s.repository.Update(ctx, toUpdate)
+		return err
+	}); err != nil {
+		if roleID != "" {
+			if _, rbErr := s.repository.Update(ctx, existing); rbErr != nil {
+				return Role{}, fmt.Errorf("%s, reverting role: %s", err.Error(), rbErr.Error())
+			}
+		}
+		if typesChanged {
+			if rbErr := s.authzRepository.Add(ctx, []Role{existing}); rbErr != nil {
+				return Role{}, fmt.Errorf("%s, reverting role in authz engine: %s", err.Error(), rbErr.Error())
+			}
+		}
+		return Role{}, err
+	}
 	return s.repository.Get(ctx, roleID)
 }
+
+// Delete deletes the role, a custom role is removed from the authz schema
+func (s Service) Delete(ctx context.Context, id string) error {
+	existing, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if existing.OrgID != "" {
+		if err := s.editableOrg(ctx, existing.OrgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.syncGrants(ctx, len(existing.Includes) > 0, func() error {
+		return s.repository.Delete(ctx, id)
+	}); err != nil {
+		return err
+	}
+
+	if existing.OrgID != "" {
+		return s.authzRepository.Remove(ctx, []Role{existing})
+	}
+	return nil
+}
+
+// RestoreOrgRoles adds the custom roles of the organizations to the authz
+// schema, the schema written from the namespace configs doesn't have them
+func (s Service) RestoreOrgRoles(ctx context.Context) error {
+	roles, err := s.repository.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var orgRoles []Role
+	for _, rl := range roles {
+		if rl.OrgID != "" {
+			orgRoles = append(orgRoles, rl)
+		}
+	}
+	if len(orgRoles) == 0 {
+		return nil
+	}
+	return s.authzRepository.Add(ctx, orgRoles)
+}
+
+// syncGrants runs the change, syncing the grants of the roles including
+// other roles if the change affects them
+func (s Service) syncGrants(ctx context.Context, affectsGrants bool, change func() error) error {
+	if !affectsGrants {
+		return change()
+	}
+	return s.policyService.SyncGrants(ctx, change)
+}
+
+// validateIncludes checks the included roles exist in the namespace of the
+// role, belong to its organization if they are custom roles and don't
+// include the role back, directly or through other roles
+func (s Service) validateIncludes(ctx context.Context, rl Role) error {
+	for _, id := range rl.Includes {
+		if id == rl.ID {
+			return ErrCyclicInclude
+		}
+
+		included, err := s.repository.Get(ctx, id)
+		if err != nil {
+			if errors.Is(err, ErrNotExist) {
+				return fmt.Errorf("%w: included role %s doesn't exist", ErrInvalidDetail, id)
+			}
+			return err
+		}
+		if included.NamespaceID != rl.NamespaceID {
+			return fmt.Errorf("%w: included role %s is not in namespace %s", ErrInvalidDetail, id, rl.NamespaceID)
+		}
+		if included.OrgID != "" && included.OrgID != rl.OrgID {
+			return fmt.Errorf("%w: included role %s belongs to another organization", ErrInvalidDetail, id)
+		}
+	}
+
+	visited := map[string]bool{}
+	queue := append([]string{}, rl.Includes...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == rl.ID {
+			return ErrCyclicInclude
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		included, err := s.repository.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		queue = append(queue, included.Includes...)
+	}
+	return nil
+}
+
+// validateSchemaNames checks the role can be written to the authz schema, it
+// is the relation named by the end of its id and its types are the
+// definitions, optionally with a relation, it can be granted to
+func validateSchemaNames(rl Role) error {
+	if name := rl.ID[strings.LastIndex(rl.ID, ":")+1:]; !namespace.IsValidRelationName(name) {
+		return fmt.Errorf("%w: %s is not a valid authz schema relation name", ErrInvalidDetail, name)
+	}
+	for _, t := range rl.Types {
+		definition, relation, hasRelation := strings.Cut(t, "#")
+		if !namespace.IsValidID(definition) || (hasRelation && !namespace.IsValidRelationName(relation)) {
+			return fmt.Errorf("%w: %s is not a valid authz schema type", ErrInvalidDetail, t)
+		}
+	}
+	return nil
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateOrgRole checks the custom role of an organization is defined by an
+// admin of the organization on a namespace objects of the organization live
+// in, its id has to be the one the relations of the role refer to
+func (s Service) validateOrgRole(ctx context.Context, rl Role) error {
+	if !uuid.IsValid(rl.OrgID) {
+		return ErrInvalidDetail
+	}
+
+	if wantID := fmt.Sprintf("%s:%s", rl.NamespaceID, rl.Name); rl.ID != wantID {
+		return fmt.Errorf("%w: id of organization role should be %s", ErrInvalidDetail, wantID)
+	}
+
+	if len(rl.Types) == 0 {
+		return fmt.Errorf("%w: organization role should be granted to users or groups", ErrInvalidDetail)
+	}
+	for _, t := range rl.Types {
+		if t != namespace.DefinitionUser.ID && t != namespace.DefinitionTeam.ID {
+			return fmt.Errorf("%w: organization role can't be granted to %s", ErrInvalidDetail, t)
+		}
+	}
+
+	ns, err := s.namespaceService.Get(ctx, rl.NamespaceID)
+	if err != nil {
+		return err
+	}
+	if !permittedOrgNamespace(ns) {
+		return ErrNamespaceNotPermitted
+	}
+
+	return s.editableOrg(ctx, rl.OrgID)
+}
+
+func (s Service) editableOrg(ctx context.Context, orgID string) error {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	allowed, err := s.relationService.CheckPermission(ctx, currentUser, namespace.Namespace{ID: namespace.DefinitionOrg.ID}, orgID, action.Action{ID: editPermission})
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return shielderrors.ErrForbidden
+	}
+	return nil
+}
+
+// permittedOrgNamespace reports whether the objects of the namespace belong
+// to an organization, the organization itself, its projects, groups and
+// resources
+func permittedOrgNamespace(ns namespace.Namespace) bool {
+	switch ns.ID {
+	case namespace.DefinitionOrg.ID, namespace.DefinitionProject.ID, namespace.DefinitionTeam.ID:
+		return true
+	}
+	return ns.Backend != ""
+}
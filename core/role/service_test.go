@@ -0,0 +1,319 @@
+package role_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const orgID = "9f256f86-31a3-11ec-8d3d-0242ac130003"
+
+type memoryRepository struct {
+	role.Repository
+	roles map[string]role.Role
+}
+
+func (r *memoryRepository) Get(ctx context.Context, id string) (role.Role, error) {
+	rl, ok := r.roles[id]
+	if !ok {
+		return role.Role{}, role.ErrNotExist
+	}
+	return rl, nil
+}
+
+func (r *memoryRepository) Create(ctx context.Context, rl role.Role) (string, error) {
+	r.roles[rl.ID] = rl
+	return rl.ID, nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id string) error {
+	delete(r.roles, id)
+	return nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, rl role.Role) (string, error) {
+	if _, ok := r.roles[rl.ID]; !ok {
+		return "", role.ErrNotExist
+	}
+	r.roles[rl.ID] = rl
+	return rl.ID, nil
+}
+
+// memoryAuthzRepository keeps the roles in the authz schema by id
+type memoryAuthzRepository map[string]role.Role
+
+func (r memoryAuthzRepository) Add(ctx context.Context, roles []role.Role) error {
+	for _, rl := range roles {
+		r[rl.ID] = rl
+	}
+	return nil
+}
+
+func (r memoryAuthzRepository) Remove(ctx context.Context, roles []role.Role) error {
+	for _, rl := range roles {
+		delete(r, rl.ID)
+	}
+	return nil
+}
+
+// syncingPolicyService counts the changes to the roles it synced the grants of
+type syncingPolicyService struct {
+	synced int
+}
+
+func (s *syncingPolicyService) SyncGrants(ctx context.Context, change func() error) error {
+	s.synced++
+	return change()
+}
+
+type memoryNamespaceService map[string]namespace.Namespace
+
+func (s memoryNamespaceService) Get(ctx context.Context, id string) (namespace.Namespace, error) {
+	ns, ok := s[id]
+	if !ok {
+		return namespace.Namespace{}, namespace.ErrNotExist
+	}
+	return ns, nil
+}
+
+// memoryRelationService allows the admins to edit the organization
+type memoryRelationService struct {
+	admins map[string]bool
+}
+
+func (s memoryRelationService) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, act action.Action) (bool, error) {
+	return resourceNS.ID == namespace.DefinitionOrg.ID && resourceIdxa == orgID && act.ID == "edit" && s.admins[usr.ID], nil
+}
+
+type currentUserService struct {
+	usr user.User
+}
+
+func (s currentUserService) FetchCurrentUser(ctx context.Context) (user.User, error) {
+	return s.usr, nil
+}
+
+func TestServiceCreateOrgRole(t *testing.T) {
+	namespaces := memoryNamespaceService{
+		"shield/project":   {ID: "shield/project"},
+		"shield/user":      {ID: "shield/user"},
+		"entropy/firehose": {ID: "entropy/firehose", Backend: "entropy", ResourceType: "firehose"},
+	}
+	relationService := memoryRelationService{admins: map[string]bool{"admin": true}}
+
+	reviewer := role.Role{
+		ID:          "shield/project:reviewer",
+		Name:        "reviewer",
+		Types:       []string{role.UserType},
+		NamespaceID: "shield/project",
+		OrgID:       orgID,
+	}
+
+	t.Run("should create the role if the user is an admin of the organization", func(t *testing.T) {
+		repo := &memoryRepository{roles: map[string]role.Role{}}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, namespaces, relationService, currentUserService{usr: user.User{ID: "admin"}}, nil)
+
+		got, err := s.Create(context.Background(), reviewer)
+		assert.NoError(t, err)
+		assert.Equal(t, reviewer, got)
+		assert.Contains(t, authz, reviewer.ID)
+	})
+
+	t.Run("should create the role on a resource namespace", func(t *testing.T) {
+		repo := &memoryRepository{roles: map[string]role.Role{}}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, namespaces, relationService, currentUserService{usr: user.User{ID: "admin"}}, nil)
+
+		operator := reviewer
+		operator.ID = "entropy/firehose:operator"
+		operator.Name = "operator"
+		operator.NamespaceID = "entropy/firehose"
+		_, err := s.Create(context.Background(), operator)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error if the user isn't an admin of the organization", func(t *testing.T) {
+		repo := &memoryRepository{roles: map[string]role.Role{}}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, namespaces, relationService, currentUserService{usr: user.User{ID: "member"}}, nil)
+
+		_, err := s.Create(context.Background(), reviewer)
+		assert.ErrorIs(t, err, errors.ErrForbidden)
+		assert.Empty(t, repo.roles)
+		assert.Empty(t, authz)
+	})
+
+	t.Run("should return error if the role already exists", func(t *testing.T) {
+		repo := &memoryRepository{roles: map[string]role.Role{
+			"shield/project:reviewer": {ID: "shield/project:reviewer", Name: "reviewer", NamespaceID: "shield/project"},
+		}}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, namespaces, relationService, currentUserService{usr: user.User{ID: "admin"}}, nil)
+
+		_, err := s.Create(context.Background(), reviewer)
+		assert.ErrorIs(t, err, role.ErrConflict)
+		assert.Empty(t, repo.roles["shield/project:reviewer"].OrgID)
+	})
+
+	t.Run("should return error if the role can't be granted to a type", func(t *testing.T) {
+		repo := &memoryRepository{roles: map[string]role.Role{}}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, namespaces, relationService, currentUserService{usr: user.User{ID: "admin"}}, nil)
+
+		robot := reviewer
+		robot.Types = []string{"shield/project"}
+		_, err := s.Create(context.Background(), robot)
+		assert.ErrorIs(t, err, role.ErrInvalidDetail)
+	})
+
+	t.Run("should return error if the namespace doesn't belong to organizations", func(t *testing.T) {
+		repo := &memoryRepository{roles: map[string]role.Role{}}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, namespaces, relationService, currentUserService{usr: user.User{ID: "admin"}}, nil)
+
+		impersonator := reviewer
+		impersonator.ID = "shield/user:impersonator"
+		impersonator.Name = "impersonator"
+		impersonator.NamespaceID = "shield/user"
+		_, err := s.Create(context.Background(), impersonator)
+		assert.ErrorIs(t, err, role.ErrNamespaceNotPermitted)
+	})
+
+	t.Run("should return error if the id doesn't match the namespace and name", func(t *testing.T) {
+		repo := &memoryRepository{roles: map[string]role.Role{}}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, namespaces, relationService, currentUserService{usr: user.User{ID: "admin"}}, nil)
+
+		misnamed := reviewer
+		misnamed.ID = "reviewer"
+		_, err := s.Create(context.Background(), misnamed)
+		assert.ErrorIs(t, err, role.ErrInvalidDetail)
+	})
+
+	t.Run("should return error if the name can't be a relation of the authz schema", func(t *testing.T) {
+		repo := &memoryRepository{roles: map[string]role.Role{}}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, nil, nil, nil, nil)
+
+		invalid := role.Role{ID: "shield/project:Release-Manager", Name: "Release-Manager", NamespaceID: "shield/project"}
+		_, err := s.Create(context.Background(), invalid)
+		assert.ErrorIs(t, err, role.ErrInvalidDetail)
+		assert.Empty(t, repo.roles)
+	})
+
+	t.Run("should not check the organization of a system role", func(t *testing.T) {
+		repo := &memoryRepository{roles: map[string]role.Role{}}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, nil, nil, nil, nil)
+
+		owner := role.Role{ID: "shield/project:owner", Name: "owner", NamespaceID: "shield/project"}
+		_, err := s.Create(context.Background(), owner)
+		assert.NoError(t, err)
+	})
+}
+
+func TestServiceDeleteOrgRole(t *testing.T) {
+	reviewer := role.Role{
+		ID:          "shield/project:reviewer",
+		Name:        "reviewer",
+		Types:       []string{role.UserType},
+		NamespaceID: "shield/project",
+		OrgID:       orgID,
+	}
+	repo := &memoryRepository{roles: map[string]role.Role{reviewer.ID: reviewer}}
+	authz := memoryAuthzRepository{reviewer.ID: reviewer}
+	s := role.NewService(repo, authz, nil, memoryRelationService{admins: map[string]bool{"admin": true}}, currentUserService{usr: user.User{ID: "admin"}}, nil)
+
+	err := s.Delete(context.Background(), reviewer.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, repo.roles)
+	assert.Empty(t, authz)
+}
+
+func TestServiceIncludes(t *testing.T) {
+	roles := func() map[string]role.Role {
+		return map[string]role.Role{
+			"shield/project:viewer": {ID: "shield/project:viewer", Name: "viewer", NamespaceID: "shield/project"},
+			"shield/project:editor": {ID: "shield/project:editor", Name: "editor", NamespaceID: "shield/project", Includes: []string{"shield/project:viewer"}},
+			"shield/group:member":   {ID: "shield/group:member", Name: "member", NamespaceID: "shield/group"},
+		}
+	}
+
+	t.Run("should create the role and sync the grants of the included roles", func(t *testing.T) {
+		repo := &memoryRepository{roles: roles()}
+		authz := memoryAuthzRepository{}
+		policyService := &syncingPolicyService{}
+		s := role.NewService(repo, authz, nil, nil, nil, policyService)
+
+		owner := role.Role{ID: "shield/project:owner", Name: "owner", NamespaceID: "shield/project", Includes: []string{"shield/project:editor"}}
+		got, err := s.Create(context.Background(), owner)
+		assert.NoError(t, err)
+		assert.Equal(t, owner, got)
+		assert.Equal(t, 1, policyService.synced)
+	})
+
+	t.Run("should return error if the included role is in another namespace", func(t *testing.T) {
+		repo := &memoryRepository{roles: roles()}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, nil, nil, nil, &syncingPolicyService{})
+
+		owner := role.Role{ID: "shield/project:owner", Name: "owner", NamespaceID: "shield/project", Includes: []string{"shield/group:member"}}
+		_, err := s.Create(context.Background(), owner)
+		assert.ErrorIs(t, err, role.ErrInvalidDetail)
+		assert.NotContains(t, repo.roles, owner.ID)
+	})
+
+	t.Run("should return error if the included role doesn't exist", func(t *testing.T) {
+		repo := &memoryRepository{roles: roles()}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, nil, nil, nil, &syncingPolicyService{})
+
+		owner := role.Role{ID: "shield/project:owner", Name: "owner", NamespaceID: "shield/project", Includes: []string{"shield/project:admin"}}
+		_, err := s.Create(context.Background(), owner)
+		assert.ErrorIs(t, err, role.ErrInvalidDetail)
+	})
+
+	t.Run("should return error if the role includes itself", func(t *testing.T) {
+		repo := &memoryRepository{roles: roles()}
+		authz := memoryAuthzRepository{}
+		s := role.NewService(repo, authz, nil, nil, nil, &syncingPolicyService{})
+
+		viewer := repo.roles["shield/project:viewer"]
+		viewer.Includes = []string{"shield/project:viewer"}
+		_, err := s.Update(context.Background(), viewer)
+		assert.ErrorIs(t, err, role.ErrCyclicInclude)
+	})
+
+	t.Run("should return error if the role is included back through another role", func(t *testing.T) {
+		repo := &memoryRepository{roles: roles()}
+		authz := memoryAuthzRepository{}
+		policyService := &syncingPolicyService{}
+		s := role.NewService(repo, authz, nil, nil, nil, policyService)
+
+		viewer := repo.roles["shield/project:viewer"]
+		viewer.Includes = []string{"shield/project:editor"}
+		_, err := s.Update(context.Background(), viewer)
+		assert.ErrorIs(t, err, role.ErrCyclicInclude)
+		assert.Empty(t, repo.roles["shield/project:viewer"].Includes)
+		assert.Equal(t, 0, policyService.synced)
+	})
+
+	t.Run("should keep the included roles if the update doesn't set them", func(t *testing.T) {
+		repo := &memoryRepository{roles: roles()}
+		authz := memoryAuthzRepository{}
+		policyService := &syncingPolicyService{}
+		s := role.NewService(repo, authz, nil, nil, nil, policyService)
+
+		got, err := s.Update(context.Background(), role.Role{ID: "shield/project:editor", Name: "Editor", NamespaceID: "shield/project"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"shield/project:viewer"}, got.Includes)
+		assert.Equal(t, 0, policyService.synced)
+	})
+}
@@ -0,0 +1,99 @@
+package rule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+const auditResourceType = "rule"
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+// Manager creates and updates the rules stored in the database, the proxies
+// serve them along with the rules of their rule files
+type Manager struct {
+	repository   Repository
+	auditService AuditService
+}
+
+func NewManager(repository Repository, auditService AuditService) *Manager {
+	return &Manager{
+		repository:   repository,
+		auditService: auditService,
+	}
+}
+
+// Create stores a rule for the proxy of its Proxy, a name is unique among
+// the rules of a proxy
+func (m Manager) Create(ctx context.Context, rl Rule) (Rule, error) {
+	if err := validate(&rl); err != nil {
+		return Rule{}, err
+	}
+
+	created, err := m.repository.Create(ctx, rl)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	if err = m.auditService.Record(ctx, audit.ActionCreate, auditResourceType, created.ID, nil, created); err != nil {
+		return Rule{}, err
+	}
+	return created, nil
+}
+
+// Update replaces the rule of the ID of rl
+func (m Manager) Update(ctx context.Context, rl Rule) (Rule, error) {
+	existing, err := m.Get(ctx, rl.ID)
+	if err != nil {
+		return Rule{}, err
+	}
+	if err := validate(&rl); err != nil {
+		return Rule{}, err
+	}
+
+	updated, err := m.repository.Update(ctx, rl)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	if err = m.auditService.Record(ctx, audit.ActionUpdate, auditResourceType, updated.ID, existing, updated); err != nil {
+		return Rule{}, err
+	}
+	return updated, nil
+}
+
+func (m Manager) Get(ctx context.Context, id string) (Rule, error) {
+	if !uuid.IsValid(id) {
+		return Rule{}, ErrNotExist
+	}
+	return m.repository.Get(ctx, id)
+}
+
+// List returns the stored rules, of the proxy of the filter when it is set
+func (m Manager) List(ctx context.Context, flt Filter) ([]Rule, error) {
+	return m.repository.List(ctx, flt)
+}
+
+// validate rejects the rules the proxies can't serve, the same way the rule
+// files are validated, the frontend of a grpc method is stored as its path
+func validate(rl *Rule) error {
+	if strings.TrimSpace(rl.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidDetail)
+	}
+	if strings.TrimSpace(rl.Proxy) == "" {
+		return fmt.Errorf("%w: proxy is required", ErrInvalidDetail)
+	}
+	compiled := *rl
+	if err := compiled.Compile(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDetail, err)
+	}
+	rl.Frontend.URL = compiled.Frontend.URL
+	rl.Frontend.Method = compiled.Frontend.Method
+	return nil
+}
@@ -0,0 +1,132 @@
+package rule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ruleID = "9f256f86-31a3-11ec-8d3d-0242ac130003"
+
+type memoryRepository struct {
+	rule.Repository
+	rules []rule.Rule
+}
+
+func (r *memoryRepository) Create(ctx context.Context, rl rule.Rule) (rule.Rule, error) {
+	for _, existing := range r.rules {
+		if existing.Proxy == rl.Proxy && existing.Name == rl.Name {
+			return rule.Rule{}, rule.ErrConflict
+		}
+	}
+	rl.ID = ruleID
+	r.rules = append(r.rules, rl)
+	return rl, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, rl rule.Rule) (rule.Rule, error) {
+	for i, existing := range r.rules {
+		if existing.ID == rl.ID {
+			r.rules[i] = rl
+			return rl, nil
+		}
+	}
+	return rule.Rule{}, rule.ErrNotExist
+}
+
+func (r *memoryRepository) Get(ctx context.Context, id string) (rule.Rule, error) {
+	for _, existing := range r.rules {
+		if existing.ID == id {
+			return existing, nil
+		}
+	}
+	return rule.Rule{}, rule.ErrNotExist
+}
+
+type memoryAuditService struct {
+	logs []audit.Log
+}
+
+func (s *memoryAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	s.logs = append(s.logs, audit.Log{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		OldPayload:   oldPayload,
+		NewPayload:   newPayload,
+	})
+	return nil
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	valid := rule.Rule{
+		Name:     "list-firehoses",
+		Proxy:    "entropy",
+		Frontend: rule.Frontend{URL: "/api/firehoses", Method: "GET"},
+		Backend:  rule.Backend{URL: "http://localhost:3000", Namespace: "entropy"},
+	}
+
+	t.Run("should reject the rules the proxies can't serve", func(t *testing.T) {
+		tests := []struct {
+			name string
+			edit func(rl *rule.Rule)
+		}{
+			{name: "without a name", edit: func(rl *rule.Rule) { rl.Name = " " }},
+			{name: "without a proxy", edit: func(rl *rule.Rule) { rl.Proxy = "" }},
+			{name: "without a path", edit: func(rl *rule.Rule) { rl.Frontend.URL = "" }},
+			{name: "with an invalid path", edit: func(rl *rule.Rule) { rl.Frontend.URL = "/api/(firehoses" }},
+			{name: "with a relative target", edit: func(rl *rule.Rule) { rl.Backend.URL = "/entropy" }},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				m := rule.NewManager(&memoryRepository{}, &memoryAuditService{})
+				rl := valid
+				tt.edit(&rl)
+				_, err := m.Create(ctx, rl)
+				assert.ErrorIs(t, err, rule.ErrInvalidDetail)
+			})
+		}
+	})
+
+	t.Run("should audit the rules created and updated", func(t *testing.T) {
+		auditService := &memoryAuditService{}
+		m := rule.NewManager(&memoryRepository{}, auditService)
+
+		created, err := m.Create(ctx, valid)
+		require.NoError(t, err)
+		assert.Equal(t, ruleID, created.ID)
+		assert.Nil(t, created.Frontend.URLRx)
+
+		_, err = m.Create(ctx, valid)
+		assert.ErrorIs(t, err, rule.ErrConflict)
+
+		edited := created
+		edited.Frontend.Method = "POST"
+		updated, err := m.Update(ctx, edited)
+		require.NoError(t, err)
+		assert.Equal(t, "POST", updated.Frontend.Method)
+
+		require.Len(t, auditService.logs, 2)
+		assert.Equal(t, audit.ActionCreate, auditService.logs[0].Action)
+		assert.Equal(t, audit.ActionUpdate, auditService.logs[1].Action)
+		assert.Equal(t, "rule", auditService.logs[1].ResourceType)
+		assert.Equal(t, created, auditService.logs[1].OldPayload)
+	})
+
+	t.Run("should return not exist updating a rule which doesn't exist", func(t *testing.T) {
+		m := rule.NewManager(&memoryRepository{}, &memoryAuditService{})
+		rl := valid
+		rl.ID = "not-a-uuid"
+		_, err := m.Update(ctx, rl)
+		assert.ErrorIs(t, err, rule.ErrNotExist)
+
+		rl.ID = ruleID
+		_, err = m.Update(ctx, rl)
+		assert.ErrorIs(t, err, rule.ErrNotExist)
+	})
+}
@@ -3,15 +3,57 @@ package rule
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
+	"time"
 )
 
 var (
 	ErrUnknown = errors.New("undefined proxy rule")
+	// ErrInvalidRuleset is returned when the rule files can't be loaded, the
+	// rules loaded before stay in use
+	ErrInvalidRuleset = errors.New("invalid proxy ruleset")
+	ErrNotExist       = errors.New("proxy rule doesn't exist")
+	ErrConflict       = errors.New("proxy rule already exists")
+	ErrInvalidDetail  = errors.New("invalid proxy rule detail")
 )
 
 type ConfigRepository interface {
 	GetAll(ctx context.Context) ([]Ruleset, error)
+	Reload(ctx context.Context) (Status, error)
+	Status(ctx context.Context) Status
+}
+
+// Repository stores the rules managed without rule files, a proxy serves
+// the rules stored for its name
+type Repository interface {
+	Create(ctx context.Context, rl Rule) (Rule, error)
+	Update(ctx context.Context, rl Rule) (Rule, error)
+	Get(ctx context.Context, id string) (Rule, error)
+	List(ctx context.Context, flt Filter) ([]Rule, error)
+	// Version changes every time the stored rules change
+	Version(ctx context.Context) (string, error)
+}
+
+type Filter struct {
+	Proxy string
+}
+
+// Status is the ruleset a proxy serves with, its version goes up every time
+// rule files with a different checksum are loaded
+type Status struct {
+	Version  int64
+	Checksum string
+	LoadedAt time.Time
+	Rulesets int
+	Rules    int
+
+	// Error is why the last reload was rejected, it is cleared by the next
+	// reload succeeding
+	Error    string
+	FailedAt time.Time
 }
 
 type Ruleset struct {
@@ -19,10 +61,51 @@ type Ruleset struct {
 }
 
 type Rule struct {
+	// ID, Name and Proxy are only set on the stored rules, Proxy is the name
+	// of the proxy serving the rule
+	ID    string `yaml:"-"`
+	Name  string `yaml:"name"`
+	Proxy string `yaml:"proxy"`
+
 	Frontend    Frontend        `yaml:"frontend"`
 	Backend     Backend         `yaml:"backend"`
 	Middlewares MiddlewareSpecs `yaml:"middlewares"`
 	Hooks       HookSpecs       `yaml:"hooks"`
+
+	CreatedAt time.Time `yaml:"-"`
+	UpdatedAt time.Time `yaml:"-"`
+}
+
+// Compile checks the rule can be served and compiles the url of its
+// frontend
+func (r *Rule) Compile() error {
+	if r.Frontend.GRPCMethod != "" {
+		if err := r.Frontend.compileGRPC(); err != nil {
+			return err
+		}
+	}
+	if r.Frontend.URL == "" {
+		return fmt.Errorf("a frontend of backend %q has no path", r.Backend.Namespace)
+	}
+	if r.Frontend.MaxBodySize < 0 {
+		return fmt.Errorf("frontend %q can't have a negative max body size", r.Frontend.URL)
+	}
+	if err := r.Backend.Policy.validate(); err != nil {
+		return fmt.Errorf("backend %q: %s", r.Backend.Namespace, err)
+	}
+	if target, err := url.Parse(r.Backend.URL); err != nil || target.Scheme == "" || target.Host == "" {
+		return fmt.Errorf("backend %q should target an absolute url", r.Backend.Namespace)
+	}
+	if err := validateSplits(r.Backend.Splits); err != nil {
+		return fmt.Errorf("backend %q: %s", r.Backend.Namespace, err)
+	}
+	// TODO: only compile between delimiter, maybe angular brackets
+	urlRx, err := regexp.Compile(r.Frontend.URL)
+	if err != nil {
+		return fmt.Errorf("frontend %q is not a valid regular expression: %s", r.Frontend.URL, err)
+	}
+	r.Frontend.URLRx = urlRx
+	return nil
 }
 
 type MiddlewareSpec struct {
@@ -62,10 +145,163 @@ type Frontend struct {
 	URLRx *regexp.Regexp `yaml:"-"`
 
 	Method string `yaml:"method"`
+
+	// GRPCMethod is the full name of the gRPC method of the frontend, like
+	// package.Service/Method or package.Service/* for all the methods of a
+	// service, the method is then the method path param
+	GRPCMethod string `yaml:"grpc_method"`
+
+	// MaxBodySize is the largest request body in bytes the frontend accepts,
+	// the size of the bodies is not limited when it is 0
+	MaxBodySize int64 `yaml:"max_body_size"`
+}
+
+// grpcMethodRx is a full gRPC method name, the leading slash of the path of
+// the method is optional
+var grpcMethodRx = regexp.MustCompile(`^/?([A-Za-z_][A-Za-z0-9_.]*)/([A-Za-z_][A-Za-z0-9_]*|\*)$`)
+
+// compileGRPC sets the url and the method of the frontend to the ones of
+// the requests of its gRPC method, gRPC calls are POST requests to the path
+// of the full method name
+func (f *Frontend) compileGRPC() error {
+	match := grpcMethodRx.FindStringSubmatch(f.GRPCMethod)
+	if match == nil {
+		return fmt.Errorf("frontend %q is not a full grpc method name", f.GRPCMethod)
+	}
+	method := match[2]
+	if method == "*" {
+		method = "{method}"
+	}
+	path := "/" + match[1] + "/" + method
+	if f.URL != "" && f.URL != path {
+		return fmt.Errorf("frontend %q can't have both a path and a grpc method", f.GRPCMethod)
+	}
+	if f.Method != "" && f.Method != http.MethodPost {
+		return fmt.Errorf("frontend %q of a grpc method can't have method %s", f.GRPCMethod, f.Method)
+	}
+	f.URL = path
+	f.Method = http.MethodPost
+	return nil
 }
 
 type Backend struct {
 	URL       string `yaml:"url"`
 	Namespace string `yaml:"namespace"`
 	Prefix    string `yaml:"prefix"`
+	Policy    Policy `yaml:"policy"`
+	// Splits send a part of the requests to other targets of the backend,
+	// like the canary of a service, the rest go to URL
+	Splits []Split `yaml:"splits"`
+}
+
+// Split is a target of a backend taking the requests pinned to it by a
+// header or a cookie along with a weight of the other requests, the weight
+// is the percentage of the requests it takes
+type Split struct {
+	Name   string `yaml:"name" json:"name,omitempty"`
+	Target string `yaml:"target" json:"target"`
+	Weight int    `yaml:"weight" json:"weight,omitempty"`
+	Header *Match `yaml:"header" json:"header,omitempty"`
+	Cookie *Match `yaml:"cookie" json:"cookie,omitempty"`
+}
+
+// Match is a header or a cookie of a request, a match without a value
+// matches any value
+type Match struct {
+	Name  string `yaml:"name" json:"name"`
+	Value string `yaml:"value" json:"value,omitempty"`
+}
+
+// SplitName is the name of the split, its target when it has none
+func (s Split) SplitName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Target
+}
+
+func validateSplits(splits []Split) error {
+	weights := 0
+	for _, s := range splits {
+		if target, err := url.Parse(s.Target); err != nil || target.Scheme == "" || target.Host == "" {
+			return fmt.Errorf("split %q should target an absolute url", s.SplitName())
+		}
+		if s.Weight < 0 {
+			return fmt.Errorf("split %q can't have a negative weight", s.SplitName())
+		}
+		if (s.Header != nil && s.Header.Name == "") || (s.Cookie != nil && s.Cookie.Name == "") {
+			return fmt.Errorf("split %q pins the requests of a header or a cookie without a name", s.SplitName())
+		}
+		weights += s.Weight
+	}
+	if weights > 100 {
+		return fmt.Errorf("splits take %d%% of the requests, over 100%%", weights)
+	}
+	return nil
+}
+
+// Policy is how the proxy calls a backend, a backend without a policy is
+// called once and waited for as long as it takes
+type Policy struct {
+	// Timeout is how long a call to the backend can take, like 10s
+	Timeout        string         `yaml:"timeout" json:"timeout,omitempty"`
+	Retry          Retry          `yaml:"retry" json:"retry"`
+	CircuitBreaker CircuitBreaker `yaml:"circuit_breaker" json:"circuit_breaker"`
+}
+
+// Retry sends the idempotent requests failing with a bad gateway, an
+// unavailable backend or a timeout again
+type Retry struct {
+	// Attempts is how many times a failed request is sent again
+	Attempts int `yaml:"attempts" json:"attempts,omitempty"`
+	// Budget is the ratio of the requests to the backend which can be sent
+	// again, like 0.2, so retries don't pile up on a failing backend, the
+	// retries are not limited when it is 0
+	Budget float64 `yaml:"budget" json:"budget,omitempty"`
+}
+
+// CircuitBreaker stops sending requests to a backend after it failed a
+// number of requests in a row, until it cools down
+type CircuitBreaker struct {
+	// Failures is the number of failures in a row opening the breaker, the
+	// breaker is disabled when it is 0
+	Failures int `yaml:"failures" json:"failures,omitempty"`
+	// Cooldown is how long an open breaker rejects the requests before it
+	// lets one through to probe the backend, like 30s
+	Cooldown string `yaml:"cooldown" json:"cooldown,omitempty"`
+}
+
+// TimeoutDuration is the timeout of the policy, 0 when it has none
+func (p Policy) TimeoutDuration() time.Duration {
+	d, _ := time.ParseDuration(p.Timeout)
+	return d
+}
+
+// CooldownDuration is the cooldown of the circuit breaker
+func (c CircuitBreaker) CooldownDuration() time.Duration {
+	d, _ := time.ParseDuration(c.Cooldown)
+	return d
+}
+
+func (p Policy) validate() error {
+	if p.Timeout != "" {
+		if d, err := time.ParseDuration(p.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("timeout %q is not a duration", p.Timeout)
+		}
+	}
+	if p.Retry.Attempts < 0 {
+		return errors.New("retry attempts can't be negative")
+	}
+	if p.Retry.Budget < 0 || p.Retry.Budget > 1 {
+		return errors.New("retry budget should be a ratio between 0 and 1")
+	}
+	if p.CircuitBreaker.Failures < 0 {
+		return errors.New("circuit breaker failures can't be negative")
+	}
+	if p.CircuitBreaker.Failures > 0 {
+		if d, err := time.ParseDuration(p.CircuitBreaker.Cooldown); err != nil || d <= 0 {
+			return fmt.Errorf("circuit breaker cooldown %q is not a duration", p.CircuitBreaker.Cooldown)
+		}
+	}
+	return nil
 }
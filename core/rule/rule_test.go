@@ -0,0 +1,196 @@
+package rule_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/odpf/shield/core/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleCompile(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		title    string
+		frontend rule.Frontend
+		want     rule.Frontend
+		wantErr  bool
+	}{
+		{
+			title:    "should compile a path",
+			frontend: rule.Frontend{URL: "/api/firehoses/{name}", Method: http.MethodGet},
+			want:     rule.Frontend{URL: "/api/firehoses/{name}", Method: http.MethodGet},
+		},
+		{
+			title:    "should route a grpc method",
+			frontend: rule.Frontend{GRPCMethod: "odpf.entropy.v1beta1.ResourceService/CreateResource"},
+			want:     rule.Frontend{URL: "/odpf.entropy.v1beta1.ResourceService/CreateResource", Method: http.MethodPost, GRPCMethod: "odpf.entropy.v1beta1.ResourceService/CreateResource"},
+		},
+		{
+			title:    "should route all the methods of a grpc service",
+			frontend: rule.Frontend{GRPCMethod: "/odpf.entropy.v1beta1.ResourceService/*"},
+			want:     rule.Frontend{URL: "/odpf.entropy.v1beta1.ResourceService/{method}", Method: http.MethodPost, GRPCMethod: "/odpf.entropy.v1beta1.ResourceService/*"},
+		},
+		{
+			title:    "should compile a grpc method compiled before",
+			frontend: rule.Frontend{URL: "/odpf.entropy.v1beta1.ResourceService/{method}", Method: http.MethodPost, GRPCMethod: "odpf.entropy.v1beta1.ResourceService/*"},
+			want:     rule.Frontend{URL: "/odpf.entropy.v1beta1.ResourceService/{method}", Method: http.MethodPost, GRPCMethod: "odpf.entropy.v1beta1.ResourceService/*"},
+		},
+		{
+			title:    "should reject a grpc method without a service",
+			frontend: rule.Frontend{GRPCMethod: "CreateResource"},
+			wantErr:  true,
+		},
+		{
+			title:    "should reject a grpc method with a path",
+			frontend: rule.Frontend{URL: "/api/resources", GRPCMethod: "odpf.entropy.v1beta1.ResourceService/CreateResource"},
+			wantErr:  true,
+		},
+		{
+			title:    "should reject a grpc method with a method other than post",
+			frontend: rule.Frontend{Method: http.MethodGet, GRPCMethod: "odpf.entropy.v1beta1.ResourceService/GetResource"},
+			wantErr:  true,
+		},
+		{
+			title:    "should reject a frontend without a path",
+			frontend: rule.Frontend{Method: http.MethodGet},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range table {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			rl := rule.Rule{
+				Frontend: tt.frontend,
+				Backend:  rule.Backend{URL: "http://localhost:8080", Namespace: "entropy"},
+			}
+			err := rl.Compile()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, rl.Frontend.URLRx)
+			rl.Frontend.URLRx = nil
+			assert.Equal(t, tt.want, rl.Frontend)
+		})
+	}
+}
+
+func TestRuleCompileBackendPolicy(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		title   string
+		policy  rule.Policy
+		wantErr bool
+	}{
+		{
+			title: "should compile a backend without a policy",
+		},
+		{
+			title: "should compile a backend policy",
+			policy: rule.Policy{
+				Timeout:        "10s",
+				Retry:          rule.Retry{Attempts: 2, Budget: 0.2},
+				CircuitBreaker: rule.CircuitBreaker{Failures: 5, Cooldown: "30s"},
+			},
+		},
+		{
+			title:   "should reject a timeout which isn't a duration",
+			policy:  rule.Policy{Timeout: "10"},
+			wantErr: true,
+		},
+		{
+			title:   "should reject negative retry attempts",
+			policy:  rule.Policy{Retry: rule.Retry{Attempts: -1}},
+			wantErr: true,
+		},
+		{
+			title:   "should reject a retry budget over 1",
+			policy:  rule.Policy{Retry: rule.Retry{Attempts: 1, Budget: 20}},
+			wantErr: true,
+		},
+		{
+			title:   "should reject a circuit breaker without a cooldown",
+			policy:  rule.Policy{CircuitBreaker: rule.CircuitBreaker{Failures: 5}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range table {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			rl := rule.Rule{
+				Frontend: rule.Frontend{URL: "/api/firehoses", Method: http.MethodGet},
+				Backend:  rule.Backend{URL: "http://localhost:8080", Namespace: "entropy", Policy: tt.policy},
+			}
+			err := rl.Compile()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRuleCompileBackendSplits(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		title   string
+		splits  []rule.Split
+		wantErr bool
+	}{
+		{
+			title: "should compile weighted and pinned splits",
+			splits: []rule.Split{
+				{Name: "canary", Target: "http://entropy-canary:3000", Weight: 10, Header: &rule.Match{Name: "X-Canary", Value: "true"}},
+				{Name: "beta", Target: "http://entropy-beta:3000", Cookie: &rule.Match{Name: "beta"}},
+			},
+		},
+		{
+			title:   "should reject a split without an absolute target",
+			splits:  []rule.Split{{Name: "canary", Target: "entropy-canary", Weight: 10}},
+			wantErr: true,
+		},
+		{
+			title: "should reject splits taking more than all the requests",
+			splits: []rule.Split{
+				{Target: "http://entropy-canary:3000", Weight: 60},
+				{Target: "http://entropy-beta:3000", Weight: 60},
+			},
+			wantErr: true,
+		},
+		{
+			title:   "should reject a split pinned by a header without a name",
+			splits:  []rule.Split{{Target: "http://entropy-canary:3000", Header: &rule.Match{Value: "true"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range table {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			rl := rule.Rule{
+				Frontend: rule.Frontend{URL: "/api/firehoses", Method: http.MethodGet},
+				Backend:  rule.Backend{URL: "http://entropy:3000", Namespace: "entropy", Splits: tt.splits},
+			}
+			err := rl.Compile()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -15,3 +15,13 @@ func NewService(configRepository ConfigRepository) *Service {
 func (s Service) GetAllConfigs(ctx context.Context) ([]Ruleset, error) {
 	return s.configRepository.GetAll(ctx)
 }
+
+// Reload loads the rule files again, rule files failing validation are
+// rejected and the rules loaded before stay in use
+func (s Service) Reload(ctx context.Context) (Status, error) {
+	return s.configRepository.Reload(ctx)
+}
+
+func (s Service) Status(ctx context.Context) Status {
+	return s.configRepository.Status(ctx)
+}
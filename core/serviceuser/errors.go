@@ -0,0 +1,13 @@
+package serviceuser
+
+import "errors"
+
+var (
+	ErrNotExist             = errors.New("service user doesn't exist")
+	ErrInvalidID            = errors.New("service user id is invalid")
+	ErrInvalidDetail        = errors.New("invalid service user detail")
+	ErrConflict             = errors.New("service user already exist")
+	ErrKeyNotExist          = errors.New("service user key doesn't exist")
+	ErrUnsupportedAlgorithm = errors.New("unsupported key algorithm, use rsa or ed25519")
+	ErrInvalidToken         = errors.New("service user token is invalid")
+)
@@ -0,0 +1,159 @@
+package serviceuser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/user"
+)
+
+const (
+	auditResourceType    = "serviceuser"
+	auditKeyResourceType = "serviceuser_key"
+)
+
+// nameRegex limits names to what can be the local part of the email
+// of the backing user
+var nameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+type UserService interface {
+	Create(ctx context.Context, user user.User) (user.User, error)
+	GetByID(ctx context.Context, id string) (user.User, error)
+	GetByEmail(ctx context.Context, email string) (user.User, error)
+}
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+type Service struct {
+	repository   Repository
+	userService  UserService
+	auditService AuditService
+	now          func() time.Time
+}
+
+func NewService(repository Repository, userService UserService, auditService AuditService) *Service {
+	return &Service{
+		repository:   repository,
+		userService:  userService,
+		auditService: auditService,
+		now:          time.Now,
+	}
+}
+
+// Email is the email of the user backing the service user of the name
+func Email(name string) string {
+	return fmt.Sprintf("%s@%s", name, EmailDomain)
+}
+
+// Create creates the service user along with the user backing it, the
+// backing user is reused when an earlier create failed after making it
+func (s Service) Create(ctx context.Context, serviceUser ServiceUser) (ServiceUser, error) {
+	if !nameRegex.MatchString(serviceUser.Name) {
+		return ServiceUser{}, fmt.Errorf("%w: name must be lowercase letters, digits, dots, dashes or underscores", ErrInvalidDetail)
+	}
+
+	usr, err := s.userService.Create(ctx, user.User{
+		Name:  serviceUser.Name,
+		Email: Email(serviceUser.Name),
+	})
+	if errors.Is(err, user.ErrConflict) {
+		usr, err = s.userService.GetByEmail(ctx, Email(serviceUser.Name))
+	}
+	if err != nil {
+		return ServiceUser{}, err
+	}
+
+	created, err := s.repository.Create(ctx, ServiceUser{
+		UserID: usr.ID,
+		Name:   serviceUser.Name,
+	})
+	if err != nil {
+		return ServiceUser{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, created.ID, nil, created); err != nil {
+		return ServiceUser{}, err
+	}
+	return created, nil
+}
+
+func (s Service) Get(ctx context.Context, id string) (ServiceUser, error) {
+	return s.repository.Get(ctx, id)
+}
+
+func (s Service) List(ctx context.Context) ([]ServiceUser, error) {
+	return s.repository.List(ctx)
+}
+
+// CreateKey generates a key pair for the service user, the private key
+// is returned PEM encoded and is not stored
+func (s Service) CreateKey(ctx context.Context, serviceUserID, algorithm string) (Key, []byte, error) {
+	if _, err := s.repository.Get(ctx, serviceUserID); err != nil {
+		return Key{}, nil, err
+	}
+
+	publicKey, privateKey, err := generateKey(algorithm)
+	if err != nil {
+		return Key{}, nil, err
+	}
+
+	key, err := s.repository.CreateKey(ctx, Key{
+		ServiceUserID: serviceUserID,
+		Algorithm:     algorithm,
+		PublicKey:     publicKey,
+	})
+	if err != nil {
+		return Key{}, nil, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditKeyResourceType, key.ID, nil, key); err != nil {
+		return Key{}, nil, err
+	}
+	return key, privateKey, nil
+}
+
+func (s Service) ListKeys(ctx context.Context, serviceUserID string) ([]Key, error) {
+	if _, err := s.repository.Get(ctx, serviceUserID); err != nil {
+		return nil, err
+	}
+	return s.repository.ListKeys(ctx, serviceUserID)
+}
+
+// Authenticate verifies a token signed with a key of a service user and
+// returns the user backing that service user
+func (s Service) Authenticate(ctx context.Context, token string) (user.User, error) {
+	parsed, keyID, err := parseToken(token)
+	if err != nil {
+		return user.User{}, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	key, err := s.repository.GetKey(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, ErrKeyNotExist) {
+			return user.User{}, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+		}
+		return user.User{}, err
+	}
+	claims, err := verifyToken(parsed, key.PublicKey)
+	if err != nil {
+		return user.User{}, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	if key.ServiceUserID != claims.Subject {
+		return user.User{}, fmt.Errorf("%w: key doesn't belong to the subject", ErrInvalidToken)
+	}
+	if err := validateClaims(claims, s.now()); err != nil {
+		return user.User{}, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	serviceUser, err := s.repository.Get(ctx, key.ServiceUserID)
+	if err != nil {
+		return user.User{}, err
+	}
+	return s.userService.GetByID(ctx, serviceUser.UserID)
+}
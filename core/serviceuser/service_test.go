@@ -0,0 +1,226 @@
+package serviceuser_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	serviceUsers map[string]serviceuser.ServiceUser
+	keys         map[string]serviceuser.Key
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		serviceUsers: map[string]serviceuser.ServiceUser{},
+		keys:         map[string]serviceuser.Key{},
+	}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, su serviceuser.ServiceUser) (serviceuser.ServiceUser, error) {
+	su.ID = "su" + strconv.Itoa(len(r.serviceUsers)+1)
+	r.serviceUsers[su.ID] = su
+	return su, nil
+}
+
+func (r *memoryRepository) Get(ctx context.Context, id string) (serviceuser.ServiceUser, error) {
+	su, ok := r.serviceUsers[id]
+	if !ok {
+		return serviceuser.ServiceUser{}, serviceuser.ErrNotExist
+	}
+	return su, nil
+}
+
+func (r *memoryRepository) List(ctx context.Context) ([]serviceuser.ServiceUser, error) {
+	var serviceUsers []serviceuser.ServiceUser
+	for _, su := range r.serviceUsers {
+		serviceUsers = append(serviceUsers, su)
+	}
+	return serviceUsers, nil
+}
+
+func (r *memoryRepository) CreateKey(ctx context.Context, key serviceuser.Key) (serviceuser.Key, error) {
+	key.ID = "key" + strconv.Itoa(len(r.keys)+1)
+	r.keys[key.ID] = key
+	return key, nil
+}
+
+func (r *memoryRepository) GetKey(ctx context.Context, id string) (serviceuser.Key, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return serviceuser.Key{}, serviceuser.ErrKeyNotExist
+	}
+	return key, nil
+}
+
+func (r *memoryRepository) ListKeys(ctx context.Context, serviceUserID string) ([]serviceuser.Key, error) {
+	var keys []serviceuser.Key
+	for _, k := range r.keys {
+		if k.ServiceUserID == serviceUserID {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+type memoryUserService struct {
+	users map[string]user.User
+}
+
+func (s *memoryUserService) Create(ctx context.Context, usr user.User) (user.User, error) {
+	for _, u := range s.users {
+		if u.Email == usr.Email {
+			return user.User{}, user.ErrConflict
+		}
+	}
+	usr.ID = "u" + strconv.Itoa(len(s.users)+1)
+	s.users[usr.ID] = usr
+	return usr, nil
+}
+
+func (s *memoryUserService) GetByID(ctx context.Context, id string) (user.User, error) {
+	usr, ok := s.users[id]
+	if !ok {
+		return user.User{}, user.ErrNotExist
+	}
+	return usr, nil
+}
+
+func (s *memoryUserService) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	for _, u := range s.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return user.User{}, user.ErrNotExist
+}
+
+type noopAuditService struct{}
+
+func (noopAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	return nil
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+
+	newService := func() *serviceuser.Service {
+		return serviceuser.NewService(newMemoryRepository(), &memoryUserService{users: map[string]user.User{}}, noopAuditService{})
+	}
+
+	t.Run("should create the service user along with its user", func(t *testing.T) {
+		s := newService()
+
+		su, err := s.Create(ctx, serviceuser.ServiceUser{Name: "billing-job"})
+		require.NoError(t, err)
+		assert.Equal(t, "u1", su.UserID)
+		assert.Equal(t, "billing-job", su.Name)
+	})
+
+	t.Run("should return error if the name is invalid", func(t *testing.T) {
+		_, err := newService().Create(ctx, serviceuser.ServiceUser{Name: "Billing Job"})
+		assert.ErrorIs(t, err, serviceuser.ErrInvalidDetail)
+	})
+
+	t.Run("should return error if the key algorithm is unsupported", func(t *testing.T) {
+		s := newService()
+		su, err := s.Create(ctx, serviceuser.ServiceUser{Name: "billing-job"})
+		require.NoError(t, err)
+
+		_, _, err = s.CreateKey(ctx, su.ID, "dsa")
+		assert.ErrorIs(t, err, serviceuser.ErrUnsupportedAlgorithm)
+	})
+
+	for _, algorithm := range []string{serviceuser.AlgorithmRSA, serviceuser.AlgorithmEd25519} {
+		algorithm := algorithm
+		t.Run("should authenticate tokens signed with an "+algorithm+" key", func(t *testing.T) {
+			s := newService()
+			su, err := s.Create(ctx, serviceuser.ServiceUser{Name: "billing-job"})
+			require.NoError(t, err)
+			key, privateKey, err := s.CreateKey(ctx, su.ID, algorithm)
+			require.NoError(t, err)
+
+			token, err := serviceuser.SignToken(key.ID, su.ID, privateKey, time.Minute)
+			require.NoError(t, err)
+
+			usr, err := s.Authenticate(ctx, token)
+			require.NoError(t, err)
+			assert.Equal(t, serviceuser.Email("billing-job"), usr.Email)
+		})
+	}
+
+	t.Run("should reject tokens which are tampered with or signed by another key", func(t *testing.T) {
+		s := newService()
+		su, err := s.Create(ctx, serviceuser.ServiceUser{Name: "billing-job"})
+		require.NoError(t, err)
+		other, err := s.Create(ctx, serviceuser.ServiceUser{Name: "report-job"})
+		require.NoError(t, err)
+		key, privateKey, err := s.CreateKey(ctx, su.ID, serviceuser.AlgorithmEd25519)
+		require.NoError(t, err)
+		otherKey, otherPrivateKey, err := s.CreateKey(ctx, other.ID, serviceuser.AlgorithmEd25519)
+		require.NoError(t, err)
+
+		// signed with the key of another service user
+		token, err := serviceuser.SignToken(otherKey.ID, su.ID, otherPrivateKey, time.Minute)
+		require.NoError(t, err)
+		_, err = s.Authenticate(ctx, token)
+		assert.ErrorIs(t, err, serviceuser.ErrInvalidToken)
+
+		// claims of another token with the signature of this one
+		token, err = serviceuser.SignToken(key.ID, su.ID, privateKey, time.Minute)
+		require.NoError(t, err)
+		otherToken, err := serviceuser.SignToken(key.ID, su.ID, privateKey, 2*time.Minute)
+		require.NoError(t, err)
+		parts, otherParts := strings.Split(token, "."), strings.Split(otherToken, ".")
+		_, err = s.Authenticate(ctx, parts[0]+"."+otherParts[1]+"."+parts[2])
+		assert.ErrorIs(t, err, serviceuser.ErrInvalidToken)
+
+		_, err = s.Authenticate(ctx, "not-a-token")
+		assert.ErrorIs(t, err, serviceuser.ErrInvalidToken)
+	})
+
+	t.Run("should reject tokens issued to another audience", func(t *testing.T) {
+		s := newService()
+		su, err := s.Create(ctx, serviceuser.ServiceUser{Name: "billing-job"})
+		require.NoError(t, err)
+		key, privateKey, err := s.CreateKey(ctx, su.ID, serviceuser.AlgorithmEd25519)
+		require.NoError(t, err)
+
+		block, _ := pem.Decode(privateKey)
+		signingKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		require.NoError(t, err)
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.EdDSA, Key: signingKey}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", key.ID))
+		require.NoError(t, err)
+
+		now := time.Now()
+		for _, aud := range []jwt.Audience{nil, {"another-service"}} {
+			token, err := jwt.Signed(signer).Claims(jwt.Claims{
+				Subject:  su.ID,
+				Audience: aud,
+				IssuedAt: jwt.NewNumericDate(now),
+				Expiry:   jwt.NewNumericDate(now.Add(time.Minute)),
+			}).CompactSerialize()
+			require.NoError(t, err)
+
+			_, err = s.Authenticate(ctx, token)
+			assert.ErrorIs(t, err, serviceuser.ErrInvalidToken)
+		}
+	})
+
+	t.Run("should not sign tokens valid for longer than the max ttl", func(t *testing.T) {
+		_, err := serviceuser.SignToken("key1", "su1", nil, 2*serviceuser.MaxTokenTTL)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,39 @@
+package serviceuser
+
+import (
+	"context"
+	"time"
+)
+
+// EmailDomain is the domain of the emails given to the users backing
+// service users, their emails are never used to send anything
+const EmailDomain = "serviceuser.shield"
+
+type Repository interface {
+	Create(ctx context.Context, serviceUser ServiceUser) (ServiceUser, error)
+	Get(ctx context.Context, id string) (ServiceUser, error)
+	List(ctx context.Context) ([]ServiceUser, error)
+	CreateKey(ctx context.Context, key Key) (Key, error)
+	GetKey(ctx context.Context, id string) (Key, error)
+	ListKeys(ctx context.Context, serviceUserID string) ([]Key, error)
+}
+
+// ServiceUser is a machine identity, it is backed by a user so it can be
+// added to groups, projects and policies the same way as any other user
+type ServiceUser struct {
+	ID        string
+	UserID    string
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Key is a credential of a service user, only the public key is kept,
+// the private key is handed out once when the key is created
+type Key struct {
+	ID            string
+	ServiceUserID string
+	Algorithm     string
+	PublicKey     []byte
+	CreatedAt     time.Time
+}
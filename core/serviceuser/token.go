@@ -0,0 +1,163 @@
+package serviceuser
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+const (
+	AlgorithmRSA     = "rsa"
+	AlgorithmEd25519 = "ed25519"
+
+	rsaKeyBits = 2048
+
+	// MaxTokenTTL is the longest a token can be valid for, tokens are
+	// expected to be signed for every few requests rather than kept
+	MaxTokenTTL = time.Hour
+
+	// TokenAudience is the aud claim of the tokens, the tokens signed for
+	// shield aren't accepted by the other services trusting the keys
+	TokenAudience = "shield"
+
+	// clockSkew is how far off the clocks of the clients can be
+	clockSkew = time.Minute
+)
+
+// generateKey returns a new key pair of the algorithm, PEM encoded
+// as PKIX for the public key and PKCS #8 for the private key
+func generateKey(algorithm string) (publicKey, privateKey []byte, err error) {
+	var pub, priv any
+	switch algorithm {
+	case AlgorithmRSA:
+		k, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, nil, err
+		}
+		pub, priv = &k.PublicKey, k
+	case AlgorithmEd25519:
+		pub, priv, err = ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, ErrUnsupportedAlgorithm
+	}
+
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), nil
+}
+
+// SignToken returns a JWT which authenticates as the service user, it is
+// signed with the private key handed out when the key was created
+func SignToken(keyID, serviceUserID string, privateKey []byte, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > MaxTokenTTL {
+		return "", fmt.Errorf("token ttl must be more than 0 and at most %s", MaxTokenTTL)
+	}
+
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return "", errors.New("private key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+
+	var algorithm jose.SignatureAlgorithm
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		algorithm = jose.RS256
+	case ed25519.PrivateKey:
+		algorithm = jose.EdDSA
+	default:
+		return "", ErrUnsupportedAlgorithm
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: algorithm, Key: key}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyID))
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return jwt.Signed(signer).Claims(jwt.Claims{
+		Subject:  serviceUserID,
+		Audience: jwt.Audience{TokenAudience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(ttl)),
+	}).CompactSerialize()
+}
+
+// parseToken returns the token and the id of the key it claims to be signed
+// with, the token is yet to be verified
+func parseToken(token string) (*jwt.JSONWebToken, string, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(parsed.Headers) != 1 {
+		return nil, "", errors.New("token should have a single signature")
+	}
+	return parsed, parsed.Headers[0].KeyID, nil
+}
+
+// verifyToken checks the signature with the public key and returns the
+// claims, the algorithm of the token has to be the one of the key so a key
+// can't be used as another
+func verifyToken(parsed *jwt.JSONWebToken, publicKey []byte) (jwt.Claims, error) {
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return jwt.Claims{}, errors.New("public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return jwt.Claims{}, err
+	}
+
+	var algorithm jose.SignatureAlgorithm
+	switch key.(type) {
+	case *rsa.PublicKey:
+		algorithm = jose.RS256
+	case ed25519.PublicKey:
+		algorithm = jose.EdDSA
+	default:
+		return jwt.Claims{}, ErrUnsupportedAlgorithm
+	}
+	if got := parsed.Headers[0].Algorithm; got != string(algorithm) {
+		return jwt.Claims{}, fmt.Errorf("algorithm %s doesn't match the %s key", got, algorithm)
+	}
+
+	var claims jwt.Claims
+	if err := parsed.Claims(crypto.PublicKey(key), &claims); err != nil {
+		return jwt.Claims{}, err
+	}
+	return claims, nil
+}
+
+// validateClaims checks the token is issued to shield for no longer than
+// MaxTokenTTL, isn't issued in the future and hasn't expired yet
+func validateClaims(claims jwt.Claims, now time.Time) error {
+	if claims.Expiry == nil || claims.IssuedAt == nil {
+		return errors.New("token doesn't have iat and exp claims")
+	}
+	if claims.Expiry.Time().Sub(claims.IssuedAt.Time()) > MaxTokenTTL {
+		return fmt.Errorf("token is valid for longer than %s", MaxTokenTTL)
+	}
+	return claims.ValidateWithLeeway(jwt.Expected{Audience: jwt.Audience{TokenAudience}, Time: now}, clockSkew)
+}
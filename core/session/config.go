@@ -0,0 +1,12 @@
+package session
+
+import "time"
+
+type Config struct {
+	// AccessTokenTTL is how long the access tokens issued on login and on
+	// refresh are valid
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl" mapstructure:"access_token_ttl" default:"15m"`
+	// RefreshTokenTTL is how long a session can be refreshed after login,
+	// the user has to log in again afterwards
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" mapstructure:"refresh_token_ttl" default:"720h"`
+}
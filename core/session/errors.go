@@ -0,0 +1,11 @@
+package session
+
+import "errors"
+
+var (
+	ErrNotExist      = errors.New("session doesn't exist")
+	ErrInvalidDetail = errors.New("invalid session detail")
+	ErrRevoked       = errors.New("session is revoked")
+	ErrInvalidToken  = errors.New("session token is invalid")
+	ErrExpired       = errors.New("session token is expired")
+)
@@ -0,0 +1,233 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/user"
+)
+
+const auditResourceType = "session"
+
+type UserService interface {
+	GetByID(ctx context.Context, id string) (user.User, error)
+}
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+type Service struct {
+	repository   Repository
+	userService  UserService
+	auditService AuditService
+	cfg          Config
+}
+
+func NewService(repository Repository, userService UserService, auditService AuditService, cfg Config) *Service {
+	return &Service{
+		repository:   repository,
+		userService:  userService,
+		auditService: auditService,
+		cfg:          cfg,
+	}
+}
+
+// Create starts a session of the user once they logged in, the tokens are
+// returned once and can't be recovered afterwards
+func (s Service) Create(ctx context.Context, userID, userAgent string) (Session, Tokens, error) {
+	usr, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return Session{}, Tokens{}, err
+	}
+	if usr.IsDisabled() {
+		return Session{}, Tokens{}, user.ErrDisabled
+	}
+
+	now := time.Now()
+	tokens, err := s.newTokens(now)
+	if err != nil {
+		return Session{}, Tokens{}, err
+	}
+
+	created, err := s.repository.Create(ctx, Session{
+		UserID:          userID,
+		UserAgent:       userAgent,
+		AccessHash:      hashToken(tokens.AccessToken),
+		AccessExpiresAt: tokens.AccessExpiresAt,
+		RefreshHash:     hashToken(tokens.RefreshToken),
+		ExpiresAt:       now.Add(s.cfg.RefreshTokenTTL),
+	})
+	if err != nil {
+		return Session{}, Tokens{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, created.ID, nil, created.withoutHashes()); err != nil {
+		return Session{}, Tokens{}, err
+	}
+	return created, tokens, nil
+}
+
+// Refresh issues new tokens of the session of the refresh token, the
+// refresh token is rotated as well so it can be used only once
+func (s Service) Refresh(ctx context.Context, refreshToken string) (Session, Tokens, error) {
+	if !validToken(refreshScheme, refreshToken) {
+		return Session{}, Tokens{}, ErrInvalidToken
+	}
+
+	sess, err := s.repository.GetByRefreshHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrNotExist) {
+			return Session{}, Tokens{}, ErrInvalidToken
+		}
+		return Session{}, Tokens{}, err
+	}
+	now := time.Now()
+	if sess.IsRevoked() {
+		return Session{}, Tokens{}, ErrRevoked
+	}
+	if !sess.IsActive(now) {
+		return Session{}, Tokens{}, ErrExpired
+	}
+
+	tokens, err := s.newTokens(now)
+	if err != nil {
+		return Session{}, Tokens{}, err
+	}
+	previousHash := sess.RefreshHash
+	sess.AccessHash = hashToken(tokens.AccessToken)
+	sess.AccessExpiresAt = tokens.AccessExpiresAt
+	sess.RefreshHash = hashToken(tokens.RefreshToken)
+
+	rotated, err := s.repository.Rotate(ctx, sess, previousHash)
+	if err != nil {
+		if errors.Is(err, ErrNotExist) {
+			// refreshed or revoked concurrently
+			return Session{}, Tokens{}, ErrInvalidToken
+		}
+		return Session{}, Tokens{}, err
+	}
+	return rotated, tokens, nil
+}
+
+// Authenticate returns the user of the session of the access token
+func (s Service) Authenticate(ctx context.Context, accessToken string) (user.User, error) {
+	if !validToken(accessScheme, accessToken) {
+		return user.User{}, ErrInvalidToken
+	}
+
+	sess, err := s.repository.GetByAccessHash(ctx, hashToken(accessToken))
+	if err != nil {
+		if errors.Is(err, ErrNotExist) {
+			return user.User{}, ErrInvalidToken
+		}
+		return user.User{}, err
+	}
+	if sess.IsRevoked() {
+		return user.User{}, ErrRevoked
+	}
+	if !time.Now().Before(sess.AccessExpiresAt) {
+		return user.User{}, ErrExpired
+	}
+
+	return s.userService.GetByID(ctx, sess.UserID)
+}
+
+func (s Service) Get(ctx context.Context, id string) (Session, error) {
+	return s.repository.Get(ctx, id)
+}
+
+// List returns the sessions of the user, all users when userID is empty.
+// Revoked and expired sessions are only listed along with the active ones
+// when all is set.
+func (s Service) List(ctx context.Context, userID string, all bool) ([]Session, error) {
+	sessions, err := s.repository.List(ctx, userID)
+	if err != nil || all {
+		return sessions, err
+	}
+
+	now := time.Now()
+	var active []Session
+	for _, sess := range sessions {
+		if sess.IsActive(now) {
+			active = append(active, sess)
+		}
+	}
+	return active, nil
+}
+
+// Revoke ends the session, its access and refresh tokens are rejected
+// from then on
+func (s Service) Revoke(ctx context.Context, id string) (Session, error) {
+	sess, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return Session{}, err
+	}
+	return s.revoke(ctx, sess)
+}
+
+// RevokeOwn ends a session of the user, the sessions of other users are
+// reported as not existing
+func (s Service) RevokeOwn(ctx context.Context, userID, id string) (Session, error) {
+	sess, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return Session{}, err
+	}
+	if sess.UserID != userID {
+		return Session{}, ErrNotExist
+	}
+	return s.revoke(ctx, sess)
+}
+
+// RevokeAll ends the active sessions of the user, like when the user is
+// disabled
+func (s Service) RevokeAll(ctx context.Context, userID string) ([]Session, error) {
+	sessions, err := s.List(ctx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var revoked []Session
+	for _, sess := range sessions {
+		r, err := s.revoke(ctx, sess)
+		if err != nil {
+			return nil, err
+		}
+		revoked = append(revoked, r)
+	}
+	return revoked, nil
+}
+
+func (s Service) revoke(ctx context.Context, sess Session) (Session, error) {
+	if sess.IsRevoked() {
+		return Session{}, ErrRevoked
+	}
+
+	revoked, err := s.repository.Revoke(ctx, sess.ID)
+	if err != nil {
+		return Session{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionRevoke, auditResourceType, revoked.ID, sess.withoutHashes(), revoked.withoutHashes()); err != nil {
+		return Session{}, err
+	}
+	return revoked, nil
+}
+
+func (s Service) newTokens(now time.Time) (Tokens, error) {
+	accessToken, err := generateToken(accessScheme)
+	if err != nil {
+		return Tokens{}, err
+	}
+	refreshToken, err := generateToken(refreshScheme)
+	if err != nil {
+		return Tokens{}, err
+	}
+	return Tokens{
+		AccessToken:     accessToken,
+		AccessExpiresAt: now.Add(s.cfg.AccessTokenTTL),
+		RefreshToken:    refreshToken,
+	}, nil
+}
@@ -0,0 +1,204 @@
+package session_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	sessions []session.Session
+}
+
+func (r *memoryRepository) Create(ctx context.Context, sess session.Session) (session.Session, error) {
+	sess.ID = "session" + strconv.Itoa(len(r.sessions)+1)
+	sess.CreatedAt = time.Now()
+	r.sessions = append(r.sessions, sess)
+	return sess, nil
+}
+
+func (r *memoryRepository) Get(ctx context.Context, id string) (session.Session, error) {
+	return r.find(func(s session.Session) bool { return s.ID == id })
+}
+
+func (r *memoryRepository) GetByAccessHash(ctx context.Context, hash string) (session.Session, error) {
+	return r.find(func(s session.Session) bool { return s.AccessHash == hash })
+}
+
+func (r *memoryRepository) GetByRefreshHash(ctx context.Context, hash string) (session.Session, error) {
+	return r.find(func(s session.Session) bool { return s.RefreshHash == hash })
+}
+
+func (r *memoryRepository) find(match func(session.Session) bool) (session.Session, error) {
+	for _, s := range r.sessions {
+		if match(s) {
+			return s, nil
+		}
+	}
+	return session.Session{}, session.ErrNotExist
+}
+
+func (r *memoryRepository) List(ctx context.Context, userID string) ([]session.Session, error) {
+	return r.sessions, nil
+}
+
+func (r *memoryRepository) Rotate(ctx context.Context, toRotate session.Session, refreshHash string) (session.Session, error) {
+	for i, s := range r.sessions {
+		if s.ID == toRotate.ID && s.RefreshHash == refreshHash && !s.IsRevoked() {
+			toRotate.RefreshedAt = time.Now()
+			r.sessions[i] = toRotate
+			return toRotate, nil
+		}
+	}
+	return session.Session{}, session.ErrNotExist
+}
+
+func (r *memoryRepository) Revoke(ctx context.Context, id string) (session.Session, error) {
+	for i, s := range r.sessions {
+		if s.ID == id {
+			r.sessions[i].RevokedAt = time.Now()
+			return r.sessions[i], nil
+		}
+	}
+	return session.Session{}, session.ErrNotExist
+}
+
+type memoryUserService struct{}
+
+func (memoryUserService) GetByID(ctx context.Context, id string) (user.User, error) {
+	switch id {
+	case "u1":
+		return user.User{ID: "u1", Email: "john.doe@odpf.io"}, nil
+	case "disabled":
+		return user.User{ID: "disabled", Email: "jane.doe@odpf.io", DisabledAt: time.Now()}, nil
+	}
+	return user.User{}, user.ErrNotExist
+}
+
+type noopAuditService struct{}
+
+func (noopAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	return nil
+}
+
+var testConfig = session.Config{
+	AccessTokenTTL:  15 * time.Minute,
+	RefreshTokenTTL: 24 * time.Hour,
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should authenticate the access token of a session until it is revoked", func(t *testing.T) {
+		s := session.NewService(&memoryRepository{}, memoryUserService{}, noopAuditService{}, testConfig)
+
+		created, tokens, err := s.Create(ctx, "u1", "shield-cli")
+		require.NoError(t, err)
+		assert.True(t, session.IsAccessToken(tokens.AccessToken))
+		assert.False(t, session.IsAccessToken(tokens.RefreshToken))
+		assert.NotEqual(t, tokens.AccessToken, created.AccessHash)
+
+		usr, err := s.Authenticate(ctx, tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "u1", usr.ID)
+
+		_, err = s.RevokeOwn(ctx, "u2", created.ID)
+		assert.ErrorIs(t, err, session.ErrNotExist)
+
+		_, err = s.RevokeOwn(ctx, "u1", created.ID)
+		require.NoError(t, err)
+		_, err = s.Authenticate(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, session.ErrRevoked)
+		_, _, err = s.Refresh(ctx, tokens.RefreshToken)
+		assert.ErrorIs(t, err, session.ErrRevoked)
+	})
+
+	t.Run("should rotate the tokens on refresh", func(t *testing.T) {
+		s := session.NewService(&memoryRepository{}, memoryUserService{}, noopAuditService{}, testConfig)
+
+		created, tokens, err := s.Create(ctx, "u1", "")
+		require.NoError(t, err)
+
+		refreshed, newTokens, err := s.Refresh(ctx, tokens.RefreshToken)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, refreshed.ID)
+		assert.NotEqual(t, tokens.RefreshToken, newTokens.RefreshToken)
+
+		_, err = s.Authenticate(ctx, newTokens.AccessToken)
+		assert.NoError(t, err)
+		_, err = s.Authenticate(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, session.ErrInvalidToken)
+		_, _, err = s.Refresh(ctx, tokens.RefreshToken)
+		assert.ErrorIs(t, err, session.ErrInvalidToken)
+	})
+
+	t.Run("should reject expired tokens", func(t *testing.T) {
+		repository := &memoryRepository{}
+		s := session.NewService(repository, memoryUserService{}, noopAuditService{}, testConfig)
+
+		_, tokens, err := s.Create(ctx, "u1", "")
+		require.NoError(t, err)
+		repository.sessions[0].AccessExpiresAt = time.Now().Add(-time.Minute)
+
+		_, err = s.Authenticate(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, session.ErrExpired)
+
+		repository.sessions[0].ExpiresAt = time.Now().Add(-time.Minute)
+		_, _, err = s.Refresh(ctx, tokens.RefreshToken)
+		assert.ErrorIs(t, err, session.ErrExpired)
+
+		active, err := s.List(ctx, "u1", false)
+		require.NoError(t, err)
+		assert.Empty(t, active)
+		all, err := s.List(ctx, "u1", true)
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+	})
+
+	t.Run("should reject malformed tokens", func(t *testing.T) {
+		s := session.NewService(&memoryRepository{}, memoryUserService{}, noopAuditService{}, testConfig)
+
+		_, err := s.Authenticate(ctx, "shs_not_a_token")
+		assert.ErrorIs(t, err, session.ErrInvalidToken)
+		_, _, err = s.Refresh(ctx, "shs_not_a_token")
+		assert.ErrorIs(t, err, session.ErrInvalidToken)
+	})
+
+	t.Run("should return error if the user doesn't exist", func(t *testing.T) {
+		s := session.NewService(&memoryRepository{}, memoryUserService{}, noopAuditService{}, testConfig)
+
+		_, _, err := s.Create(ctx, "u2", "")
+		assert.ErrorIs(t, err, user.ErrNotExist)
+	})
+
+	t.Run("should not start sessions of disabled users", func(t *testing.T) {
+		s := session.NewService(&memoryRepository{}, memoryUserService{}, noopAuditService{}, testConfig)
+
+		_, _, err := s.Create(ctx, "disabled", "")
+		assert.ErrorIs(t, err, user.ErrDisabled)
+	})
+
+	t.Run("should revoke all the active sessions of the user", func(t *testing.T) {
+		s := session.NewService(&memoryRepository{}, memoryUserService{}, noopAuditService{}, testConfig)
+
+		first, tokens, err := s.Create(ctx, "u1", "")
+		require.NoError(t, err)
+		second, _, err := s.Create(ctx, "u1", "")
+		require.NoError(t, err)
+		_, err = s.Revoke(ctx, second.ID)
+		require.NoError(t, err)
+
+		revoked, err := s.RevokeAll(ctx, "u1")
+		require.NoError(t, err)
+		require.Len(t, revoked, 1)
+		assert.Equal(t, first.ID, revoked[0].ID)
+		_, err = s.Authenticate(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, session.ErrRevoked)
+	})
+}
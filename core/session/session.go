@@ -0,0 +1,57 @@
+package session
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, sess Session) (Session, error)
+	Get(ctx context.Context, id string) (Session, error)
+	GetByAccessHash(ctx context.Context, hash string) (Session, error)
+	GetByRefreshHash(ctx context.Context, hash string) (Session, error)
+	List(ctx context.Context, userID string) ([]Session, error)
+	// Rotate replaces the tokens of the session if its refresh token is
+	// still the one of refreshHash, so a refresh token is used only once
+	Rotate(ctx context.Context, toRotate Session, refreshHash string) (Session, error)
+	Revoke(ctx context.Context, id string) (Session, error)
+}
+
+// Session is a login of a user, it is used through short lived access
+// tokens which are renewed with its refresh token until the session
+// expires. Shield only keeps the hashes of the tokens.
+type Session struct {
+	ID              string
+	UserID          string
+	UserAgent       string
+	AccessHash      string
+	AccessExpiresAt time.Time
+	RefreshHash     string
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+	RefreshedAt     time.Time
+	RevokedAt       time.Time
+}
+
+// Tokens are handed out once on login and on every refresh
+type Tokens struct {
+	AccessToken     string
+	AccessExpiresAt time.Time
+	RefreshToken    string
+}
+
+func (s Session) IsRevoked() bool {
+	return !s.RevokedAt.IsZero()
+}
+
+// IsActive tells if the session can still be refreshed
+func (s Session) IsActive(now time.Time) bool {
+	return !s.IsRevoked() && now.Before(s.ExpiresAt)
+}
+
+// withoutHashes is the session as it is recorded in the audit logs
+func (s Session) withoutHashes() Session {
+	s.AccessHash = ""
+	s.RefreshHash = ""
+	return s
+}
@@ -0,0 +1,47 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// accessScheme and refreshScheme start the tokens so they can be told
+	// apart from each other and from api keys
+	accessScheme  = "shs"
+	refreshScheme = "shr"
+
+	secretBytes = 32
+)
+
+// IsAccessToken tells if the token is formatted as a session access token
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, accessScheme+"_")
+}
+
+// generateToken returns a token formatted as <scheme>_<secret>
+func generateToken(scheme string) (string, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return scheme + "_" + hex.EncodeToString(secret), nil
+}
+
+func validToken(scheme, token string) bool {
+	secret := strings.TrimPrefix(token, scheme+"_")
+	if secret == token || len(secret) != 2*secretBytes {
+		return false
+	}
+	_, err := hex.DecodeString(secret)
+	return err == nil
+}
+
+// hashToken is a plain sha256 as for api keys, the tokens are random
+// enough for a slow hash to add nothing
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
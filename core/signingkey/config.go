@@ -0,0 +1,27 @@
+package signingkey
+
+import "time"
+
+type Config struct {
+	// EncryptionKey encrypts the private keys kept in postgres, 32 bytes
+	// encoded in base64. Shield doesn't sign tokens when it is not set.
+	EncryptionKey string `yaml:"encryption_key" mapstructure:"encryption_key"`
+	// Algorithm of the keys generated on rotation, rsa or ed25519
+	Algorithm string `yaml:"algorithm" mapstructure:"algorithm" default:"rsa"`
+	// RotationInterval is how often a new key is generated, keys are only
+	// rotated with shield keys rotate when it is 0
+	RotationInterval time.Duration `yaml:"rotation_interval" mapstructure:"rotation_interval" default:"720h"`
+	// ActivationDelay is how long a new key is published on the jwks before
+	// tokens are signed with it, so the verifiers caching the jwks have it
+	ActivationDelay time.Duration `yaml:"activation_delay" mapstructure:"activation_delay" default:"10m"`
+	// Retention is how long a key is still published once another key
+	// signs the tokens, it has to outlive the tokens the key signed
+	Retention time.Duration `yaml:"retention" mapstructure:"retention" default:"24h"`
+	// RefreshInterval is how often the instances load the keys rotated by
+	// the other instances and rotate the keys when they are due
+	RefreshInterval time.Duration `yaml:"refresh_interval" mapstructure:"refresh_interval" default:"1m"`
+}
+
+func (c Config) Enabled() bool {
+	return c.EncryptionKey != ""
+}
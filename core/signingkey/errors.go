@@ -0,0 +1,12 @@
+package signingkey
+
+import "errors"
+
+var (
+	ErrNotExist             = errors.New("signing key doesn't exist")
+	ErrInvalidDetail        = errors.New("invalid signing key detail")
+	ErrConflict             = errors.New("signing key was rotated already")
+	ErrUnsupportedAlgorithm = errors.New("unsupported key algorithm, use rsa or ed25519")
+	ErrInvalidEncryptionKey = errors.New("encryption key must be 32 bytes encoded in base64")
+	ErrNoSigningKey         = errors.New("no signing key is loaded yet")
+)
@@ -0,0 +1,131 @@
+package signingkey
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+
+	"github.com/go-jose/go-jose/v3"
+)
+
+const rsaKeyBits = 2048
+
+// generateKey returns a new key pair of the algorithm, the public key PEM
+// encoded as PKIX and the private key as PKCS #8 DER
+func generateKey(algorithm string) (publicKey, privateKey []byte, err error) {
+	var pub, priv any
+	switch algorithm {
+	case AlgorithmRSA:
+		k, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, nil, err
+		}
+		pub, priv = &k.PublicKey, k
+	case AlgorithmEd25519:
+		pub, priv, err = ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, ErrUnsupportedAlgorithm
+	}
+
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), privDER, nil
+}
+
+func newAEAD(encryptionKey string) (cipher.AEAD, error) {
+	key, err := base64.StdEncoding.DecodeString(encryptionKey)
+	if err != nil || len(key) != 32 {
+		return nil, ErrInvalidEncryptionKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the private key of the key id with a random nonce put in
+// front of it, the id is authenticated so a private key can't be swapped
+// with the one of another key
+func seal(aead cipher.AEAD, id string, privateKey []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, privateKey, []byte(id)), nil
+}
+
+func open(aead cipher.AEAD, key Key) (crypto.Signer, error) {
+	if len(key.EncryptedPrivateKey) < aead.NonceSize() {
+		return nil, errors.New("encrypted private key is too short")
+	}
+	nonce, sealed := key.EncryptedPrivateKey[:aead.NonceSize()], key.EncryptedPrivateKey[aead.NonceSize():]
+	der, err := aead.Open(nil, nonce, sealed, []byte(key.ID))
+	if err != nil {
+		return nil, errors.New("private key can't be decrypted with the encryption key")
+	}
+	private, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+// jwa is the algorithm of the tokens signed with a key of the algorithm
+func jwa(algorithm string) jose.SignatureAlgorithm {
+	if algorithm == AlgorithmRSA {
+		return jose.RS256
+	}
+	return jose.EdDSA
+}
+
+// signJWT returns the claims as a JWT signed with the key
+func signJWT(key Key, signer crypto.Signer, claims any) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	s, err := jose.NewSigner(jose.SigningKey{Algorithm: jwa(key.Algorithm), Key: signer}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", key.ID))
+	if err != nil {
+		return "", err
+	}
+	signed, err := s.Sign(payload)
+	if err != nil {
+		return "", err
+	}
+	return signed.CompactSerialize()
+}
+
+// jwk is the public key of the key as it is published on the jwks
+func jwk(key Key, signer crypto.Signer) jose.JSONWebKey {
+	return jose.JSONWebKey{
+		Key:       signer.Public(),
+		KeyID:     key.ID,
+		Algorithm: string(jwa(key.Algorithm)),
+		Use:       "sig",
+	}
+}
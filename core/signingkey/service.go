@@ -0,0 +1,255 @@
+package signingkey
+
+import (
+	"context"
+	"crypto"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/odpf/salt/log"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+const auditResourceType = "signing_key"
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+// Service rotates the keys shield signs tokens with, every instance signs
+// with the keys it loaded last from postgres
+type Service struct {
+	repository   Repository
+	auditService AuditService
+	cfg          Config
+	aead         cipher.AEAD
+	now          func() time.Time
+
+	// keys holds the *keySet loaded last
+	keys atomic.Value
+}
+
+type keySet struct {
+	keys    []Key
+	signers map[string]crypto.Signer
+}
+
+func NewService(repository Repository, auditService AuditService, cfg Config) (*Service, error) {
+	aead, err := newAEAD(cfg.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Algorithm != AlgorithmRSA && cfg.Algorithm != AlgorithmEd25519 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	return &Service{
+		repository:   repository,
+		auditService: auditService,
+		cfg:          cfg,
+		aead:         aead,
+		now:          time.Now,
+	}, nil
+}
+
+// Rotate generates a new key, it signs the tokens once the activation delay
+// passed or right away when immediately is set, like when the signing key
+// leaked
+func (s *Service) Rotate(ctx context.Context, immediately bool) (Key, error) {
+	now := s.now()
+	activatesAt := now.Add(s.cfg.ActivationDelay)
+	if immediately {
+		activatesAt = now
+	}
+	// no key is created after now, the key is always created
+	return s.create(ctx, activatesAt, now)
+}
+
+func (s *Service) create(ctx context.Context, activatesAt, createdAfter time.Time) (Key, error) {
+	publicKey, privateKey, err := generateKey(s.cfg.Algorithm)
+	if err != nil {
+		return Key{}, err
+	}
+	key := Key{
+		ID:          uuid.NewString(),
+		Algorithm:   s.cfg.Algorithm,
+		PublicKey:   publicKey,
+		ActivatesAt: activatesAt,
+	}
+	if key.EncryptedPrivateKey, err = seal(s.aead, key.ID, privateKey); err != nil {
+		return Key{}, err
+	}
+
+	created, err := s.repository.Create(ctx, key, createdAfter)
+	if err != nil {
+		return Key{}, err
+	}
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, created.ID, nil, created.withoutPrivateKey()); err != nil {
+		return Key{}, err
+	}
+	return created, nil
+}
+
+// rotateIfDue creates the first key, which signs right away, and a new key
+// once the last one is older than the rotation interval
+func (s *Service) rotateIfDue(ctx context.Context, keys []Key) error {
+	now := s.now()
+	if len(keys) == 0 {
+		_, err := s.create(ctx, now, time.Unix(0, 0))
+		if errors.Is(err, ErrConflict) {
+			return nil
+		}
+		return err
+	}
+	if s.cfg.RotationInterval <= 0 {
+		return nil
+	}
+
+	last := keys[0].CreatedAt
+	for _, key := range keys {
+		if key.CreatedAt.After(last) {
+			last = key.CreatedAt
+		}
+	}
+	if now.Sub(last) < s.cfg.RotationInterval {
+		return nil
+	}
+	_, err := s.create(ctx, now.Add(s.cfg.ActivationDelay), now.Add(-s.cfg.RotationInterval))
+	if errors.Is(err, ErrConflict) {
+		return nil
+	}
+	return err
+}
+
+// List returns the keys with their state, ordered by the time they
+// activate
+func (s *Service) List(ctx context.Context) ([]KeyStatus, error) {
+	keys, err := s.repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return statuses(keys, s.now(), s.cfg.Retention), nil
+}
+
+// statuses tells the state of the keys ordered by the time they activate,
+// the key activated last signs the tokens, the first key when none is
+// active yet
+func statuses(keys []Key, now time.Time, retention time.Duration) []KeyStatus {
+	signing := 0
+	for i, key := range keys {
+		if !key.ActivatesAt.After(now) {
+			signing = i
+		}
+	}
+
+	result := make([]KeyStatus, 0, len(keys))
+	for i, key := range keys {
+		status := KeyStatus{Key: key}
+		switch {
+		case i < signing:
+			status.State = StatePrevious
+			status.ExpiresAt = keys[i+1].ActivatesAt.Add(retention)
+		case i == signing:
+			status.State = StateSigning
+		default:
+			status.State = StateNext
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// Load rotates the keys when they are due, removes the previous keys whose
+// retention passed and loads the others to sign and verify tokens with
+func (s *Service) Load(ctx context.Context) error {
+	keys, err := s.repository.List(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.rotateIfDue(ctx, keys); err != nil {
+		return fmt.Errorf("failed to rotate keys: %w", err)
+	}
+	if keys, err = s.repository.List(ctx); err != nil {
+		return err
+	}
+
+	set := &keySet{signers: map[string]crypto.Signer{}}
+	for _, status := range statuses(keys, s.now(), s.cfg.Retention) {
+		if status.State == StatePrevious && !status.ExpiresAt.After(s.now()) {
+			if err := s.delete(ctx, status.Key); err != nil {
+				return err
+			}
+			continue
+		}
+		signer, err := open(s.aead, status.Key)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", status.ID, err)
+		}
+		set.keys = append(set.keys, status.Key)
+		set.signers[status.ID] = signer
+	}
+	s.keys.Store(set)
+	return nil
+}
+
+func (s *Service) delete(ctx context.Context, key Key) error {
+	if err := s.repository.Delete(ctx, key.ID); err != nil {
+		// another instance removed it first
+		if errors.Is(err, ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return s.auditService.Record(ctx, audit.ActionDelete, auditResourceType, key.ID, key.withoutPrivateKey(), nil)
+}
+
+// Run loads the keys every refresh interval until the context is done, the
+// keys are expected to be loaded once before
+func (s *Service) Run(ctx context.Context, logger log.Logger) {
+	ticker := time.NewTicker(s.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// the keys loaded before are kept when they can't be loaded
+			if err := s.Load(ctx); err != nil {
+				logger.Warn("failed to load signing keys", "err", err)
+			}
+		}
+	}
+}
+
+// Sign returns the claims as a JWT signed with the signing key
+func (s *Service) Sign(claims any) (string, error) {
+	set, ok := s.keys.Load().(*keySet)
+	if !ok || len(set.keys) == 0 {
+		return "", ErrNoSigningKey
+	}
+	for _, status := range statuses(set.keys, s.now(), s.cfg.Retention) {
+		if status.State == StateSigning {
+			return signJWT(status.Key, set.signers[status.ID], claims)
+		}
+	}
+	return "", ErrNoSigningKey
+}
+
+// JWKS is the public keys of the keys loaded, the next keys are published
+// before they sign and the previous keys until their retention passed
+func (s *Service) JWKS() jose.JSONWebKeySet {
+	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{}}
+	set, ok := s.keys.Load().(*keySet)
+	if !ok {
+		return jwks
+	}
+	for _, key := range set.keys {
+		jwks.Keys = append(jwks.Keys, jwk(key, set.signers[key.ID]))
+	}
+	return jwks
+}
@@ -0,0 +1,211 @@
+package signingkey_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/signingkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const encryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+type memoryRepository struct {
+	keys []signingkey.Key
+}
+
+func (r *memoryRepository) Create(ctx context.Context, key signingkey.Key, createdAfter time.Time) (signingkey.Key, error) {
+	for _, k := range r.keys {
+		if k.CreatedAt.After(createdAfter) {
+			return signingkey.Key{}, signingkey.ErrConflict
+		}
+	}
+	key.CreatedAt = time.Now()
+	r.keys = append(r.keys, key)
+	return key, nil
+}
+
+func (r *memoryRepository) List(ctx context.Context) ([]signingkey.Key, error) {
+	keys := append([]signingkey.Key{}, r.keys...)
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].ActivatesAt.Before(keys[j].ActivatesAt) })
+	return keys, nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id string) error {
+	for i, k := range r.keys {
+		if k.ID == id {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			return nil
+		}
+	}
+	return signingkey.ErrNotExist
+}
+
+// age moves the keys back in time, as if d passed
+func (r *memoryRepository) age(d time.Duration) {
+	for i := range r.keys {
+		r.keys[i].CreatedAt = r.keys[i].CreatedAt.Add(-d)
+		r.keys[i].ActivatesAt = r.keys[i].ActivatesAt.Add(-d)
+	}
+}
+
+type memoryAuditService struct {
+	actions []string
+}
+
+func (s *memoryAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	s.actions = append(s.actions, action+" "+resourceID)
+	return nil
+}
+
+// verify checks the token with the public key of the jwks it names and
+// returns the id of the key
+func verify(t *testing.T, jwks jose.JSONWebKeySet, tkn string) string {
+	t.Helper()
+	parsed, err := jwt.ParseSigned(tkn)
+	require.NoError(t, err)
+	require.Len(t, parsed.Headers, 1)
+	header := parsed.Headers[0]
+
+	keys := jwks.Key(header.KeyID)
+	require.Len(t, keys, 1, "jwks doesn't have key %s", header.KeyID)
+	assert.Equal(t, keys[0].Algorithm, header.Algorithm)
+
+	var claims map[string]any
+	assert.NoError(t, parsed.Claims(keys[0].Key, &claims))
+	return header.KeyID
+}
+
+func states(t *testing.T, s *signingkey.Service) []string {
+	t.Helper()
+	keys, err := s.List(context.Background())
+	require.NoError(t, err)
+	var result []string
+	for _, k := range keys {
+		result = append(result, k.State)
+	}
+	return result
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	cfg := signingkey.Config{
+		EncryptionKey:    encryptionKey,
+		Algorithm:        signingkey.AlgorithmEd25519,
+		RotationInterval: 720 * time.Hour,
+		ActivationDelay:  10 * time.Minute,
+		Retention:        24 * time.Hour,
+		RefreshInterval:  time.Minute,
+	}
+
+	t.Run("should fail with an invalid config", func(t *testing.T) {
+		_, err := signingkey.NewService(&memoryRepository{}, &memoryAuditService{}, signingkey.Config{EncryptionKey: "short", Algorithm: signingkey.AlgorithmRSA})
+		assert.ErrorIs(t, err, signingkey.ErrInvalidEncryptionKey)
+		_, err = signingkey.NewService(&memoryRepository{}, &memoryAuditService{}, signingkey.Config{EncryptionKey: encryptionKey, Algorithm: "dsa"})
+		assert.ErrorIs(t, err, signingkey.ErrUnsupportedAlgorithm)
+	})
+
+	t.Run("should not sign before the keys are loaded", func(t *testing.T) {
+		s, err := signingkey.NewService(&memoryRepository{}, &memoryAuditService{}, cfg)
+		require.NoError(t, err)
+		_, err = s.Sign(map[string]string{"sub": "u1"})
+		assert.ErrorIs(t, err, signingkey.ErrNoSigningKey)
+		assert.Empty(t, s.JWKS().Keys)
+	})
+
+	t.Run("should rotate the keys through their states", func(t *testing.T) {
+		repo := &memoryRepository{}
+		auditService := &memoryAuditService{}
+		s, err := signingkey.NewService(repo, auditService, cfg)
+		require.NoError(t, err)
+
+		// the first key signs right away
+		require.NoError(t, s.Load(ctx))
+		require.Len(t, repo.keys, 1)
+		first := repo.keys[0].ID
+		tkn, err := s.Sign(map[string]string{"sub": "u1"})
+		require.NoError(t, err)
+		assert.Equal(t, first, verify(t, s.JWKS(), tkn))
+
+		require.NoError(t, s.Load(ctx))
+		assert.Len(t, repo.keys, 1)
+
+		// the next key is published before it signs
+		repo.age(cfg.RotationInterval)
+		require.NoError(t, s.Load(ctx))
+		require.Len(t, repo.keys, 2)
+		second := repo.keys[1].ID
+		assert.Len(t, s.JWKS().Keys, 2)
+		tkn, err = s.Sign(map[string]string{"sub": "u1"})
+		require.NoError(t, err)
+		assert.Equal(t, first, verify(t, s.JWKS(), tkn))
+		assert.Equal(t, []string{signingkey.StateSigning, signingkey.StateNext}, states(t, s))
+
+		repo.age(cfg.ActivationDelay)
+		require.NoError(t, s.Load(ctx))
+		tkn, err = s.Sign(map[string]string{"sub": "u1"})
+		require.NoError(t, err)
+		assert.Equal(t, second, verify(t, s.JWKS(), tkn))
+		assert.Equal(t, []string{signingkey.StatePrevious, signingkey.StateSigning}, states(t, s))
+
+		// the previous key is removed once its retention passed
+		repo.age(cfg.Retention)
+		require.NoError(t, s.Load(ctx))
+		require.Len(t, repo.keys, 1)
+		assert.Equal(t, second, repo.keys[0].ID)
+		assert.Len(t, s.JWKS().Keys, 1)
+		assert.Equal(t, []string{
+			audit.ActionCreate + " " + first,
+			audit.ActionCreate + " " + second,
+			audit.ActionDelete + " " + first,
+		}, auditService.actions)
+	})
+
+	t.Run("should sign with a key rotated immediately", func(t *testing.T) {
+		repo := &memoryRepository{}
+		cfg := cfg
+		cfg.Algorithm = signingkey.AlgorithmRSA
+		s, err := signingkey.NewService(repo, &memoryAuditService{}, cfg)
+		require.NoError(t, err)
+		require.NoError(t, s.Load(ctx))
+
+		key, err := s.Rotate(ctx, true)
+		require.NoError(t, err)
+		repo.age(time.Second)
+		require.NoError(t, s.Load(ctx))
+		tkn, err := s.Sign(map[string]string{"sub": "u1"})
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, verify(t, s.JWKS(), tkn))
+	})
+
+	t.Run("should not rotate the keys without a rotation interval", func(t *testing.T) {
+		repo := &memoryRepository{}
+		cfg := cfg
+		cfg.RotationInterval = 0
+		s, err := signingkey.NewService(repo, &memoryAuditService{}, cfg)
+		require.NoError(t, err)
+		require.NoError(t, s.Load(ctx))
+		repo.age(365 * 24 * time.Hour)
+		require.NoError(t, s.Load(ctx))
+		assert.Len(t, repo.keys, 1)
+	})
+
+	t.Run("should fail to load keys encrypted with another encryption key", func(t *testing.T) {
+		repo := &memoryRepository{}
+		s, err := signingkey.NewService(repo, &memoryAuditService{}, cfg)
+		require.NoError(t, err)
+		require.NoError(t, s.Load(ctx))
+
+		cfg := cfg
+		cfg.EncryptionKey = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+		other, err := signingkey.NewService(repo, &memoryAuditService{}, cfg)
+		require.NoError(t, err)
+		assert.ErrorContains(t, other.Load(ctx), "can't be decrypted")
+	})
+}
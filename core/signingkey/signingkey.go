@@ -0,0 +1,57 @@
+package signingkey
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AlgorithmRSA     = "rsa"
+	AlgorithmEd25519 = "ed25519"
+)
+
+// states of a key, a next key is published before it signs the tokens and
+// a previous key is published until the tokens it signed expire
+const (
+	StateNext     = "next"
+	StateSigning  = "signing"
+	StatePrevious = "previous"
+)
+
+type Repository interface {
+	// Create stores the key unless a key was created after createdAfter, so
+	// the instances rotating the keys at once store a single key. It is
+	// stored whatever the other keys when createdAfter is zero.
+	Create(ctx context.Context, key Key, createdAfter time.Time) (Key, error)
+	// List returns the keys ordered by the time they activate
+	List(ctx context.Context) ([]Key, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Key is a key pair shield signs tokens with, the private key is kept
+// encrypted and only decrypted in memory
+type Key struct {
+	ID        string
+	Algorithm string
+	// PublicKey is PEM encoded as PKIX
+	PublicKey []byte
+	// EncryptedPrivateKey is the PKCS #8 private key sealed with the
+	// encryption key of the config
+	EncryptedPrivateKey []byte
+	CreatedAt           time.Time
+	ActivatesAt         time.Time
+}
+
+// withoutPrivateKey is the key as it is recorded in the audit logs
+func (k Key) withoutPrivateKey() Key {
+	k.EncryptedPrivateKey = nil
+	return k
+}
+
+// KeyStatus is a key with its state at a time
+type KeyStatus struct {
+	Key
+	State string
+	// ExpiresAt is when a previous key is no longer published
+	ExpiresAt time.Time
+}
@@ -0,0 +1,164 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/odpf/salt/log"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+// relationResourceType is the resource type the changes of the relations
+// are recorded with
+const relationResourceType = "relation"
+
+type key struct {
+	orgID string
+	day   time.Time
+}
+
+// Counter counts the usage of the organizations in memory and adds it to
+// the repository when flushed, so requests don't wait on the database. The
+// counts failing to be added are kept for the next flush.
+type Counter struct {
+	repository       Repository
+	objectRepository ObjectRepository
+	now              func() time.Time
+
+	mu     sync.Mutex
+	counts map[key]*Usage
+}
+
+func NewCounter(repository Repository, objectRepository ObjectRepository) *Counter {
+	return &Counter{
+		repository:       repository,
+		objectRepository: objectRepository,
+		now:              time.Now,
+		counts:           map[key]*Usage{},
+	}
+}
+
+// CountAPICall counts a request made in the organization
+func (c *Counter) CountAPICall(orgID string) {
+	c.add(orgID, func(u *Usage) { u.APICalls++ })
+}
+
+// Notify counts the relations created and deleted as written in the
+// organization of their object, the change is never failed by counting it
+func (c *Counter) Notify(ctx context.Context, ev event.Event) error {
+	if ev.ResourceType != relationResourceType {
+		return nil
+	}
+	switch ev.Type {
+	case event.TypeOf(relationResourceType, audit.ActionCreate), event.TypeOf(relationResourceType, audit.ActionDelete):
+	default:
+		return nil
+	}
+
+	payload := ev.NewPayload
+	if payload == nil {
+		payload = ev.OldPayload
+	}
+	rel, ok := payload.(relation.RelationV2)
+	if !ok {
+		return nil
+	}
+	orgID, err := c.objectRepository.OrgIDOf(ctx, rel.Object.NamespaceID, rel.Object.ID)
+	if err != nil {
+		return nil
+	}
+	c.add(orgID, func(u *Usage) { u.RelationWrites++ })
+	return nil
+}
+
+func (c *Counter) add(orgID string, count func(u *Usage)) {
+	if !uuid.IsValid(orgID) {
+		return
+	}
+	k := key{orgID: orgID, day: DayOf(c.now())}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u, ok := c.counts[k]
+	if !ok {
+		u = &Usage{OrgID: k.orgID, Day: k.day}
+		c.counts[k] = u
+	}
+	count(u)
+}
+
+// Flush adds the usage counted since the last flush to the repository, the
+// usage of the organizations which don't exist is dropped
+func (c *Counter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	counts := c.counts
+	c.counts = map[key]*Usage{}
+	c.mu.Unlock()
+	if len(counts) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(counts))
+	for k := range counts {
+		ids = append(ids, k.orgID)
+	}
+	existing, err := c.objectRepository.ExistingIDs(ctx, schema.OrganizationNamespace, ids)
+	if err != nil {
+		c.restore(counts)
+		return err
+	}
+
+	usages := make([]Usage, 0, len(counts))
+	for k, u := range counts {
+		if existing[k.orgID] {
+			usages = append(usages, *u)
+		}
+	}
+	if err := c.repository.Add(ctx, usages); err != nil {
+		c.restore(counts)
+		return err
+	}
+	return nil
+}
+
+// restore puts back the counts which failed to be flushed
+func (c *Counter) restore(counts map[key]*Usage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, u := range counts {
+		if current, ok := c.counts[k]; ok {
+			current.APICalls += u.APICalls
+			current.RelationWrites += u.RelationWrites
+			continue
+		}
+		c.counts[k] = u
+	}
+}
+
+// Run flushes the counts every interval until ctx is done, and once more
+// then so the counts of the instance stopping are not lost
+func (c *Counter) Run(ctx context.Context, logger log.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Flush(ctx); err != nil && ctx.Err() == nil {
+				logger.Warn("failed to flush usage", "err", err)
+			}
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := c.Flush(flushCtx); err != nil {
+				logger.Warn("failed to flush usage", "err", err)
+			}
+			cancel()
+			return
+		}
+	}
+}
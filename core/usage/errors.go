@@ -0,0 +1,5 @@
+package usage
+
+import "errors"
+
+var ErrInvalidDetail = errors.New("invalid usage detail")
@@ -0,0 +1,100 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+)
+
+type OrgService interface {
+	Get(ctx context.Context, idOrSlug string) (organization.Organization, error)
+}
+
+type RelationService interface {
+	CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, action action.Action) (bool, error)
+}
+
+type UserService interface {
+	FetchCurrentUser(ctx context.Context) (user.User, error)
+}
+
+type Service struct {
+	repository      Repository
+	counter         *Counter
+	orgService      OrgService
+	relationService RelationService
+	userService     UserService
+	now             func() time.Time
+}
+
+func NewService(repository Repository, counter *Counter, orgService OrgService, relationService RelationService, userService UserService) *Service {
+	return &Service{
+		repository:      repository,
+		counter:         counter,
+		orgService:      orgService,
+		relationService: relationService,
+		userService:     userService,
+		now:             time.Now,
+	}
+}
+
+// Get returns the usage of the organization on every day since the day
+// given up to today, the days without usage included. The usage counted by
+// this instance is flushed first, the usage of the other instances is the
+// one they flushed last. Only the users who can edit the organization can
+// get its usage.
+func (s Service) Get(ctx context.Context, orgIDOrSlug string, since time.Time) ([]Usage, error) {
+	currentUser, err := s.userService.FetchCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.orgService.Get(ctx, orgIDOrSlug)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := s.relationService.CheckPermission(ctx, currentUser, namespace.Namespace{ID: schema.OrganizationNamespace}, org.ID, action.Action{ID: schema.EditPermission})
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, shielderrors.ErrForbidden
+	}
+
+	today := DayOf(s.now())
+	since = DayOf(since)
+	if since.After(today) {
+		return nil, fmt.Errorf("%w: since is after today", ErrInvalidDetail)
+	}
+
+	if s.counter != nil {
+		if err := s.counter.Flush(ctx); err != nil {
+			return nil, err
+		}
+	}
+	stored, err := s.repository.List(ctx, org.ID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[time.Time]Usage, len(stored))
+	for _, u := range stored {
+		byDay[DayOf(u.Day)] = u
+	}
+	var usages []Usage
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		u, ok := byDay[day]
+		if !ok {
+			u = Usage{OrgID: org.ID}
+		}
+		u.Day = day
+		usages = append(usages, u)
+	}
+	return usages, nil
+}
@@ -0,0 +1,60 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Repository interface {
+	// Add adds the counts to the ones of the organizations on the days
+	Add(ctx context.Context, usages []Usage) error
+	// List returns the usage of the organization on the days since the
+	// day given, ordered by day
+	List(ctx context.Context, orgID string, since time.Time) ([]Usage, error)
+}
+
+// ObjectRepository finds the organizations the objects of the relations
+// belong to
+type ObjectRepository interface {
+	// OrgIDOf is the organization of the object, empty if it belongs to
+	// none or doesn't exist
+	OrgIDOf(ctx context.Context, namespaceID, id string) (string, error)
+	ExistingIDs(ctx context.Context, namespaceID string, ids []string) (map[string]bool, error)
+}
+
+// Usage is the number of requests made to the api in an organization and
+// of relations written on its objects during a day, days are in UTC
+type Usage struct {
+	OrgID          string
+	Day            time.Time
+	APICalls       int64
+	RelationWrites int64
+}
+
+// DayOf is the day of the time, midnight in UTC
+func DayOf(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// ParseSince parses the first day of a usage report, a number of days back
+// from today like 30d, a duration like 72h or a date like 2023-01-02
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("%w: since %q", ErrInvalidDetail, s)
+		}
+		return DayOf(now).AddDate(0, 0, -n), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return DayOf(now.Add(-d)), nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: since %q should be a number of days, a duration or a date", ErrInvalidDetail, s)
+}
@@ -0,0 +1,219 @@
+package usage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/usage"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	orgID    = "9f256f86-31a3-11ec-8d3d-0242ac130003"
+	otherOrg = "c2d85306-96f4-4895-98b4-c3e5c2f3084d"
+)
+
+type memoryRepository struct {
+	usages []usage.Usage
+	err    error
+}
+
+func (r *memoryRepository) Add(ctx context.Context, usages []usage.Usage) error {
+	if r.err != nil {
+		return r.err
+	}
+	for _, u := range usages {
+		added := false
+		for i, existing := range r.usages {
+			if existing.OrgID == u.OrgID && existing.Day.Equal(u.Day) {
+				r.usages[i].APICalls += u.APICalls
+				r.usages[i].RelationWrites += u.RelationWrites
+				added = true
+			}
+		}
+		if !added {
+			r.usages = append(r.usages, u)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, orgID string, since time.Time) ([]usage.Usage, error) {
+	var usages []usage.Usage
+	for _, u := range r.usages {
+		if u.OrgID == orgID && !u.Day.Before(since) {
+			usages = append(usages, u)
+		}
+	}
+	return usages, nil
+}
+
+// memoryObjectRepository has the organizations of the objects, the
+// organizations are the ones existing
+type memoryObjectRepository struct {
+	orgs map[string]string
+}
+
+func (r memoryObjectRepository) OrgIDOf(ctx context.Context, namespaceID, id string) (string, error) {
+	if namespaceID == schema.OrganizationNamespace {
+		return id, nil
+	}
+	return r.orgs[id], nil
+}
+
+func (r memoryObjectRepository) ExistingIDs(ctx context.Context, namespaceID string, ids []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	for _, id := range ids {
+		existing[id] = id == orgID
+	}
+	return existing, nil
+}
+
+func relationEvent(action string, rel relation.RelationV2) event.Event {
+	ev := event.Event{Type: event.TypeOf("relation", action), ResourceType: "relation", ResourceID: rel.ID}
+	if action == audit.ActionDelete {
+		ev.OldPayload = rel
+	} else {
+		ev.NewPayload = rel
+	}
+	return ev
+}
+
+func TestCounter(t *testing.T) {
+	ctx := context.Background()
+	objects := memoryObjectRepository{orgs: map[string]string{"project-1": orgID}}
+	rel := relation.RelationV2{ID: "r1", Object: relation.Object{ID: "project-1", NamespaceID: schema.ProjectNamespace}}
+
+	t.Run("should add the requests and relation writes of the organizations on flush", func(t *testing.T) {
+		repository := &memoryRepository{}
+		c := usage.NewCounter(repository, objects)
+
+		c.CountAPICall(orgID)
+		c.CountAPICall(orgID)
+		// not an organization id, like a slug, or an organization which doesn't exist
+		c.CountAPICall("odpf")
+		c.CountAPICall(otherOrg)
+		require.NoError(t, c.Notify(ctx, relationEvent(audit.ActionCreate, rel)))
+		require.NoError(t, c.Notify(ctx, relationEvent(audit.ActionDelete, rel)))
+		require.NoError(t, c.Notify(ctx, event.Event{Type: "project.created", ResourceType: "project", NewPayload: rel}))
+
+		require.NoError(t, c.Flush(ctx))
+		assert.Equal(t, []usage.Usage{
+			{OrgID: orgID, Day: usage.DayOf(time.Now()), APICalls: 2, RelationWrites: 2},
+		}, repository.usages)
+
+		// the counts are reset once flushed
+		c.CountAPICall(orgID)
+		require.NoError(t, c.Flush(ctx))
+		assert.Equal(t, int64(3), repository.usages[0].APICalls)
+	})
+
+	t.Run("should keep the counts failing to be flushed", func(t *testing.T) {
+		repository := &memoryRepository{err: errors.New("db down")}
+		c := usage.NewCounter(repository, objects)
+
+		c.CountAPICall(orgID)
+		assert.Error(t, c.Flush(ctx))
+
+		repository.err = nil
+		c.CountAPICall(orgID)
+		require.NoError(t, c.Flush(ctx))
+		assert.Equal(t, int64(2), repository.usages[0].APICalls)
+	})
+}
+
+type memoryOrgService struct{}
+
+func (memoryOrgService) Get(ctx context.Context, idOrSlug string) (organization.Organization, error) {
+	if idOrSlug == "odpf" || idOrSlug == orgID {
+		return organization.Organization{ID: orgID, Slug: "odpf"}, nil
+	}
+	return organization.Organization{}, organization.ErrNotExist
+}
+
+type memoryRelationService struct {
+	editors map[string]bool
+}
+
+func (s memoryRelationService) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceIdxa string, act action.Action) (bool, error) {
+	return s.editors[usr.ID+":"+resourceIdxa], nil
+}
+
+type currentUserService struct {
+	id string
+}
+
+func (s currentUserService) FetchCurrentUser(ctx context.Context) (user.User, error) {
+	return user.User{ID: s.id}, nil
+}
+
+func TestServiceGet(t *testing.T) {
+	ctx := context.Background()
+	today := usage.DayOf(time.Now())
+	relations := memoryRelationService{editors: map[string]bool{"admin:" + orgID: true}}
+
+	t.Run("should return every day since the day given with the usage not flushed yet", func(t *testing.T) {
+		repository := &memoryRepository{usages: []usage.Usage{
+			{OrgID: orgID, Day: today.AddDate(0, 0, -2), APICalls: 5, RelationWrites: 1},
+			{OrgID: orgID, Day: today.AddDate(0, 0, -10), APICalls: 7},
+		}}
+		c := usage.NewCounter(repository, memoryObjectRepository{})
+		c.CountAPICall(orgID)
+		s := usage.NewService(repository, c, memoryOrgService{}, relations, currentUserService{id: "admin"})
+
+		got, err := s.Get(ctx, "odpf", today.AddDate(0, 0, -2))
+		require.NoError(t, err)
+		assert.Equal(t, []usage.Usage{
+			{OrgID: orgID, Day: today.AddDate(0, 0, -2), APICalls: 5, RelationWrites: 1},
+			{OrgID: orgID, Day: today.AddDate(0, 0, -1)},
+			{OrgID: orgID, Day: today, APICalls: 1},
+		}, got)
+	})
+
+	t.Run("should only return the usage to the users who can edit the organization", func(t *testing.T) {
+		s := usage.NewService(&memoryRepository{}, nil, memoryOrgService{}, relations, currentUserService{id: "member"})
+		_, err := s.Get(ctx, "odpf", today)
+		assert.ErrorIs(t, err, shielderrors.ErrForbidden)
+	})
+
+	t.Run("should not return the usage of the days to come", func(t *testing.T) {
+		s := usage.NewService(&memoryRepository{}, nil, memoryOrgService{}, relations, currentUserService{id: "admin"})
+		_, err := s.Get(ctx, "odpf", today.AddDate(0, 0, 1))
+		assert.ErrorIs(t, err, usage.ErrInvalidDetail)
+	})
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2023, 1, 27, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		since string
+		want  time.Time
+		err   error
+	}{
+		{since: "30d", want: time.Date(2022, 12, 28, 0, 0, 0, 0, time.UTC)},
+		{since: "0d", want: time.Date(2023, 1, 27, 0, 0, 0, 0, time.UTC)},
+		{since: "48h", want: time.Date(2023, 1, 25, 0, 0, 0, 0, time.UTC)},
+		{since: "2023-01-02", want: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{since: "-1d", err: usage.ErrInvalidDetail},
+		{since: "yesterday", err: usage.ErrInvalidDetail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			got, err := usage.ParseSince(tt.since, now)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
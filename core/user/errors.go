@@ -11,4 +11,6 @@ var (
 	ErrKeyAlreadyExists = errors.New("key already exist")
 	ErrMissingEmail     = errors.New("user email is missing")
 	ErrInvalidUUID      = errors.New("invalid syntax of uuid")
+	ErrDisabled         = errors.New("user is disabled")
+	ErrInvalidState     = errors.New("invalid user state")
 )
@@ -1,7 +1,15 @@
 package user
 
+// Filter narrows down the users listed, Keyword matches a part of the name
+// or email while Name, Email and the Metadata values are globs where *
+// matches any run of characters. State lists the users of the state only
+// when it is set.
 type Filter struct {
-	Limit   int32
-	Page    int32
-	Keyword string
+	Limit    int32
+	Page     int32
+	Keyword  string
+	Name     string
+	Email    string
+	Metadata map[string]string
+	State    State
 }
@@ -5,13 +5,29 @@ import (
 	"strings"
 )
 
+// the changes to the users are audited, the audit package refers to users
+// so its actions can't be used here
+const (
+	auditResourceType  = "user"
+	auditActionCreate  = "create"
+	auditActionUpdate  = "update"
+	auditActionDisable = "disable"
+	auditActionEnable  = "enable"
+)
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
 type Service struct {
-	repository Repository
+	repository   Repository
+	auditService AuditService
 }
 
-func NewService(repository Repository) *Service {
+func NewService(repository Repository, auditService AuditService) *Service {
 	return &Service{
-		repository: repository,
+		repository:   repository,
+		auditService: auditService,
 	}
 }
 
@@ -27,6 +43,57 @@ func (s Service) GetByEmail(ctx context.Context, email string) (User, error) {
 	return s.repository.GetByEmail(ctx, email)
 }
 
+// Get returns the user with the id or, when idOrEmail is an email, the user
+// with the email, emails are unique so at most one user matches
+func (s Service) Get(ctx context.Context, idOrEmail string) (User, error) {
+	if isEmail(idOrEmail) {
+		return s.repository.GetByEmail(ctx, idOrEmail)
+	}
+	return s.repository.GetByID(ctx, idOrEmail)
+}
+
+// GetByIDsOrEmails returns the users referred to by id or email, each user
+// once, it fails with ErrNotExist when any of them doesn't exist
+func (s Service) GetByIDsOrEmails(ctx context.Context, idsOrEmails []string) ([]User, error) {
+	var ids, emails []string
+	for _, ref := range idsOrEmails {
+		if isEmail(ref) {
+			emails = append(emails, ref)
+		} else {
+			ids = append(ids, ref)
+		}
+	}
+
+	var users []User
+	if len(ids) > 0 {
+		byID, err := s.repository.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(byID) != len(unique(ids)) {
+			return nil, ErrNotExist
+		}
+		users = append(users, byID...)
+	}
+	for _, email := range emails {
+		usr, err := s.repository.GetByEmail(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, usr)
+	}
+
+	seen := map[string]bool{}
+	var result []User
+	for _, usr := range users {
+		if !seen[usr.ID] {
+			seen[usr.ID] = true
+			result = append(result, usr)
+		}
+	}
+	return result, nil
+}
+
 func (s Service) Create(ctx context.Context, user User) (User, error) {
 	newUser, err := s.repository.Create(ctx, User{
 		Name:     user.Name,
@@ -37,6 +104,9 @@ func (s Service) Create(ctx context.Context, user User) (User, error) {
 		return User{}, err
 	}
 
+	if err = s.auditService.Record(ctx, auditActionCreate, auditResourceType, newUser.ID, nil, newUser); err != nil {
+		return User{}, err
+	}
 	return newUser, nil
 }
 
@@ -65,11 +135,27 @@ func (s Service) List(ctx context.Context, flt Filter) (PagedUsers, error) {
 }
 
 func (s Service) UpdateByID(ctx context.Context, toUpdate User) (User, error) {
-	return s.repository.UpdateByID(ctx, toUpdate)
+	existing, err := s.repository.GetByID(ctx, toUpdate.ID)
+	if err != nil {
+		return User{}, err
+	}
+	updated, err := s.repository.UpdateByID(ctx, toUpdate)
+	if err != nil {
+		return User{}, err
+	}
+	return updated, s.auditService.Record(ctx, auditActionUpdate, auditResourceType, updated.ID, existing, updated)
 }
 
 func (s Service) UpdateByEmail(ctx context.Context, toUpdate User) (User, error) {
-	return s.repository.UpdateByEmail(ctx, toUpdate)
+	existing, err := s.repository.GetByEmail(ctx, toUpdate.Email)
+	if err != nil {
+		return User{}, err
+	}
+	updated, err := s.repository.UpdateByEmail(ctx, toUpdate)
+	if err != nil {
+		return User{}, err
+	}
+	return updated, s.auditService.Record(ctx, auditActionUpdate, auditResourceType, updated.ID, existing, updated)
 }
 
 func (s Service) FetchCurrentUser(ctx context.Context) (User, error) {
@@ -87,6 +173,71 @@ func (s Service) FetchCurrentUser(ctx context.Context) (User, error) {
 	if err != nil {
 		return User{}, err
 	}
+	if fetchedUser.IsDisabled() {
+		return User{}, ErrDisabled
+	}
 
 	return fetchedUser, nil
 }
+
+// Disable stops the user from authenticating, the requests and permission
+// checks made as the user are rejected from then on. Disabling a disabled
+// user changes nothing.
+func (s Service) Disable(ctx context.Context, idOrEmail string) (User, error) {
+	existing, err := s.Get(ctx, idOrEmail)
+	if err != nil {
+		return User{}, err
+	}
+	if existing.IsDisabled() {
+		return existing, nil
+	}
+
+	if err := s.repository.Disable(ctx, existing.ID); err != nil {
+		return User{}, err
+	}
+	disabled, err := s.repository.GetByID(ctx, existing.ID)
+	if err != nil {
+		return User{}, err
+	}
+	return disabled, s.auditService.Record(ctx, auditActionDisable, auditResourceType, disabled.ID, existing, disabled)
+}
+
+// Enable lets a disabled user authenticate again, with the memberships and
+// roles they had when they were disabled. Enabling an enabled user changes
+// nothing.
+func (s Service) Enable(ctx context.Context, idOrEmail string) (User, error) {
+	existing, err := s.Get(ctx, idOrEmail)
+	if err != nil {
+		return User{}, err
+	}
+	if !existing.IsDisabled() {
+		return existing, nil
+	}
+
+	if err := s.repository.Enable(ctx, existing.ID); err != nil {
+		return User{}, err
+	}
+	enabled, err := s.repository.GetByID(ctx, existing.ID)
+	if err != nil {
+		return User{}, err
+	}
+	return enabled, s.auditService.Record(ctx, auditActionEnable, auditResourceType, enabled.ID, existing, enabled)
+}
+
+// isEmail tells the emails apart from the ids, ids are uuids
+// which never have an @
+func isEmail(ref string) bool {
+	return strings.Contains(ref, "@")
+}
+
+func unique(values []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -2,6 +2,8 @@ package user
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/odpf/shield/pkg/metadata"
@@ -16,6 +18,29 @@ type Repository interface {
 	UpdateByID(ctx context.Context, toUpdate User) (User, error)
 	UpdateByEmail(ctx context.Context, toUpdate User) (User, error)
 	CreateMetadataKey(ctx context.Context, key UserMetadataKey) (UserMetadataKey, error)
+	// Disable sets the time the user is disabled at unless it is already
+	Disable(ctx context.Context, id string) error
+	// Enable clears the time the user is disabled at
+	Enable(ctx context.Context, id string) error
+}
+
+// State tells whether a user can authenticate, disabled users keep their
+// memberships and roles so they get them back once enabled
+type State string
+
+const (
+	StateEnabled  State = "enabled"
+	StateDisabled State = "disabled"
+)
+
+// ParseState parses the state of the users to list, empty for every state
+func ParseState(s string) (State, error) {
+	switch state := State(strings.ToLower(strings.TrimSpace(s))); state {
+	case "", StateEnabled, StateDisabled:
+		return state, nil
+	default:
+		return "", fmt.Errorf("%w: %s, should be %s or %s", ErrInvalidState, s, StateEnabled, StateDisabled)
+	}
 }
 
 type User struct {
@@ -25,6 +50,20 @@ type User struct {
 	Metadata  metadata.Metadata
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// DisabledAt is set once the user is disabled, disabled users can't
+	// authenticate
+	DisabledAt time.Time
+}
+
+func (u User) IsDisabled() bool {
+	return !u.DisabledAt.IsZero()
+}
+
+func (u User) State() State {
+	if u.IsDisabled() {
+		return StateDisabled
+	}
+	return StateEnabled
 }
 
 type UserMetadataKey struct {
@@ -0,0 +1,20 @@
+package webhook
+
+import "time"
+
+type Config struct {
+	// Workers is the number of deliveries posted concurrently, webhooks
+	// are not delivered when it is 0
+	Workers int `yaml:"workers" mapstructure:"workers" default:"4"`
+	// MaxAttempts is the number of times a delivery is posted before it is
+	// marked failed
+	MaxAttempts int `yaml:"max_attempts" mapstructure:"max_attempts" default:"8"`
+	// Backoff is the wait before the first retry, it doubles on every retry
+	Backoff time.Duration `yaml:"backoff" mapstructure:"backoff" default:"10s"`
+	// MaxBackoff caps the wait between the retries
+	MaxBackoff time.Duration `yaml:"max_backoff" mapstructure:"max_backoff" default:"1h"`
+	// Timeout is how long an endpoint has to respond to a delivery
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" default:"10s"`
+	// PollInterval is how often the pending deliveries are looked up
+	PollInterval time.Duration `yaml:"poll_interval" mapstructure:"poll_interval" default:"1s"`
+}
@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/odpf/salt/log"
+)
+
+const (
+	HeaderEvent     = "X-Shield-Event"
+	HeaderDelivery  = "X-Shield-Delivery"
+	HeaderTimestamp = "X-Shield-Timestamp"
+	// HeaderSignature is the hex encoded HMAC-SHA256 of the timestamp and
+	// the body joined by a dot, keyed by the secret of the webhook and
+	// prefixed with sha256=
+	HeaderSignature = "X-Shield-Signature"
+
+	maxErrorBody = 512
+)
+
+// Dispatcher posts the pending deliveries to their webhooks with a pool of
+// workers, failed deliveries are retried with an exponential backoff
+type Dispatcher struct {
+	repository Repository
+	client     *http.Client
+	config     Config
+	now        func() time.Time
+}
+
+func NewDispatcher(repository Repository, config Config) *Dispatcher {
+	return &Dispatcher{
+		repository: repository,
+		client:     &http.Client{Timeout: config.Timeout},
+		config:     config,
+		now:        time.Now,
+	}
+}
+
+// Run delivers the pending deliveries until the context is done
+func (d Dispatcher) Run(ctx context.Context, logger log.Logger) {
+	if d.config.Workers <= 0 {
+		return
+	}
+
+	// a claimed delivery isn't claimed again before its lease passed, it
+	// has to outlast the attempt
+	lease := d.config.Timeout + time.Minute
+	deliveries := make(chan Delivery)
+	var wg sync.WaitGroup
+	for i := 0; i < d.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dl := range deliveries {
+				if err := d.deliver(ctx, dl); err != nil {
+					logger.Warn("failed to update webhook delivery", "delivery", dl.ID, "err", err)
+				}
+			}
+		}()
+	}
+	defer func() {
+		close(deliveries)
+		wg.Wait()
+	}()
+
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		claimed, err := d.repository.ClaimDeliveries(ctx, d.config.Workers, lease)
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("failed to claim webhook deliveries", "err", err)
+		}
+		for _, dl := range claimed {
+			select {
+			case deliveries <- dl:
+			case <-ctx.Done():
+				return
+			}
+		}
+		// there may be more due right away when the batch was full
+		if len(claimed) == d.config.Workers {
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver posts the delivery and stores its outcome, an attempt failing
+// is retried later until the attempts run out
+func (d Dispatcher) deliver(ctx context.Context, dl Delivery) error {
+	hook, err := d.repository.Get(ctx, dl.WebhookID)
+	if err != nil {
+		return err
+	}
+
+	dl.Attempts++
+	if err := d.post(ctx, hook, dl); err != nil {
+		dl.LastError = err.Error()
+		if dl.Attempts >= d.config.MaxAttempts {
+			dl.Status = DeliveryFailed
+		} else {
+			dl.NextAttemptAt = d.now().Add(d.backoff(dl.Attempts))
+		}
+	} else {
+		dl.Status = DeliveryDelivered
+		dl.LastError = ""
+		dl.DeliveredAt = d.now()
+	}
+
+	_, err = d.repository.UpdateDelivery(ctx, dl)
+	return err
+}
+
+func (d Dispatcher) post(ctx context.Context, hook Webhook, dl Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, dl.EventType)
+	req.Header.Set(HeaderDelivery, dl.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(hook.Secret, timestamp, dl.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("endpoint responded %s: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// backoff is the wait before the retry following the attempt
+func (d Dispatcher) backoff(attempt int) time.Duration {
+	wait := d.config.Backoff
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wait >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	if wait > d.config.MaxBackoff {
+		return d.config.MaxBackoff
+	}
+	return wait
+}
+
+// Sign is the signature of the body posted at the timestamp, endpoints
+// compare it with the one of the HeaderSignature
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,116 @@
+package webhook_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+var dispatcherConfig = webhook.Config{
+	Workers:      2,
+	MaxAttempts:  3,
+	Backoff:      time.Millisecond,
+	MaxBackoff:   5 * time.Millisecond,
+	Timeout:      time.Second,
+	PollInterval: time.Millisecond,
+}
+
+// dispatch runs the dispatcher until the delivery is no longer pending
+func dispatch(t *testing.T, repo *memoryRepository, id string) webhook.Delivery {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		webhook.NewDispatcher(repo, dispatcherConfig).Run(ctx, log.NewNoop())
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	var dl webhook.Delivery
+	assert.Eventually(t, func() bool {
+		dl = repo.delivery(id)
+		return dl.Status != webhook.DeliveryPending
+	}, 5*time.Second, time.Millisecond)
+	return dl
+}
+
+func TestDispatcherRun(t *testing.T) {
+	payload := []byte(`{"type":"organization.created"}`)
+
+	t.Run("should post the signed payload", func(t *testing.T) {
+		var received atomic.Value
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			wantSignature := "sha256=" + webhook.Sign("s3cret", r.Header.Get(webhook.HeaderTimestamp), body)
+			if r.Header.Get(webhook.HeaderSignature) != wantSignature || r.Header.Get(webhook.HeaderEvent) != "organization.created" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			received.Store(body)
+		}))
+		defer server.Close()
+
+		repo := &memoryRepository{
+			hooks:      []webhook.Webhook{{ID: "hook1", URL: server.URL, Secret: "s3cret"}},
+			deliveries: []webhook.Delivery{{ID: "delivery1", WebhookID: "hook1", EventType: "organization.created", Payload: payload, Status: webhook.DeliveryPending}},
+		}
+
+		dl := dispatch(t, repo, "delivery1")
+		assert.Equal(t, webhook.DeliveryDelivered, dl.Status)
+		assert.Equal(t, 1, dl.Attempts)
+		assert.False(t, dl.DeliveredAt.IsZero())
+		assert.Equal(t, payload, received.Load())
+	})
+
+	t.Run("should retry a failing delivery until the attempts run out", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		repo := &memoryRepository{
+			hooks:      []webhook.Webhook{{ID: "hook1", URL: server.URL, Secret: "s3cret"}},
+			deliveries: []webhook.Delivery{{ID: "delivery1", WebhookID: "hook1", Payload: payload, Status: webhook.DeliveryPending}},
+		}
+
+		dl := dispatch(t, repo, "delivery1")
+		assert.Equal(t, webhook.DeliveryFailed, dl.Status)
+		assert.Equal(t, 3, dl.Attempts)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+		assert.Contains(t, dl.LastError, "503")
+	})
+
+	t.Run("should deliver once the endpoint recovers", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer server.Close()
+
+		repo := &memoryRepository{
+			hooks:      []webhook.Webhook{{ID: "hook1", URL: server.URL, Secret: "s3cret"}},
+			deliveries: []webhook.Delivery{{ID: "delivery1", WebhookID: "hook1", Payload: payload, Status: webhook.DeliveryPending}},
+		}
+
+		dl := dispatch(t, repo, "delivery1")
+		assert.Equal(t, webhook.DeliveryDelivered, dl.Status)
+		assert.Equal(t, 2, dl.Attempts)
+		assert.Empty(t, dl.LastError)
+	})
+}
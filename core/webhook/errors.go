@@ -0,0 +1,8 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrNotExist      = errors.New("webhook doesn't exist")
+	ErrInvalidDetail = errors.New("invalid webhook detail")
+)
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+const auditResourceType = "webhook"
+
+type AuditService interface {
+	Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error
+}
+
+type Service struct {
+	repository   Repository
+	auditService AuditService
+}
+
+func NewService(repository Repository, auditService AuditService) *Service {
+	return &Service{
+		repository:   repository,
+		auditService: auditService,
+	}
+}
+
+// Create registers the endpoint for the events of the filters, a filter is
+// an event type, all the events of a resource type as in organization.* or
+// every event as *
+func (s Service) Create(ctx context.Context, hook Webhook) (Webhook, error) {
+	endpoint, err := url.Parse(hook.URL)
+	if err != nil || !endpoint.IsAbs() || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return Webhook{}, fmt.Errorf("%w: url should be an absolute http or https url", ErrInvalidDetail)
+	}
+	if strings.TrimSpace(hook.Secret) == "" {
+		return Webhook{}, fmt.Errorf("%w: secret is required", ErrInvalidDetail)
+	}
+	if len(hook.Events) == 0 {
+		return Webhook{}, fmt.Errorf("%w: at least an event is required", ErrInvalidDetail)
+	}
+	for _, ev := range hook.Events {
+		if !event.ValidFilter(ev) {
+			return Webhook{}, fmt.Errorf("%w: invalid event %s", ErrInvalidDetail, ev)
+		}
+	}
+
+	created, err := s.repository.Create(ctx, hook)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	if err = s.auditService.Record(ctx, audit.ActionCreate, auditResourceType, created.ID, nil, created.withoutSecret()); err != nil {
+		return Webhook{}, err
+	}
+	return created, nil
+}
+
+func (s Service) Get(ctx context.Context, id string) (Webhook, error) {
+	if !uuid.IsValid(id) {
+		return Webhook{}, ErrNotExist
+	}
+	return s.repository.Get(ctx, id)
+}
+
+func (s Service) List(ctx context.Context) ([]Webhook, error) {
+	return s.repository.List(ctx)
+}
+
+// Delete deletes the webhook along with its deliveries
+func (s Service) Delete(ctx context.Context, id string) error {
+	existing, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repository.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.auditService.Record(ctx, audit.ActionDelete, auditResourceType, id, existing.withoutSecret(), nil)
+}
+
+func (s Service) ListDeliveries(ctx context.Context, webhookID string) ([]Delivery, error) {
+	if _, err := s.Get(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.repository.ListDeliveries(ctx, webhookID)
+}
+
+// Notify queues a delivery of the event to every webhook subscribed to it,
+// the dispatcher posts them
+func (s Service) Notify(ctx context.Context, ev event.Event) error {
+	hooks, err := s.repository.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var subscribed []Webhook
+	for _, hook := range hooks {
+		for _, flt := range hook.Events {
+			if event.MatchesFilter(flt, ev.Type) {
+				subscribed = append(subscribed, hook)
+				break
+			}
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]Delivery, 0, len(subscribed))
+	for _, hook := range subscribed {
+		deliveries = append(deliveries, Delivery{
+			WebhookID:     hook.ID,
+			EventType:     ev.Type,
+			Payload:       payload,
+			Status:        DeliveryPending,
+			NextAttemptAt: ev.CreatedAt,
+		})
+	}
+	return s.repository.CreateDeliveries(ctx, deliveries)
+}
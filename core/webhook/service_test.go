@@ -0,0 +1,211 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	mu         sync.Mutex
+	hooks      []webhook.Webhook
+	deliveries []webhook.Delivery
+}
+
+func (r *memoryRepository) Create(ctx context.Context, hook webhook.Webhook) (webhook.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hook.ID = "hook" + strconv.Itoa(len(r.hooks)+1)
+	r.hooks = append(r.hooks, hook)
+	return hook, nil
+}
+
+func (r *memoryRepository) Get(ctx context.Context, id string) (webhook.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range r.hooks {
+		if h.ID == id {
+			return h, nil
+		}
+	}
+	return webhook.Webhook{}, webhook.ErrNotExist
+}
+
+func (r *memoryRepository) List(ctx context.Context) ([]webhook.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hooks, nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, h := range r.hooks {
+		if h.ID == id {
+			r.hooks = append(r.hooks[:i], r.hooks[i+1:]...)
+			return nil
+		}
+	}
+	return webhook.ErrNotExist
+}
+
+func (r *memoryRepository) CreateDeliveries(ctx context.Context, deliveries []webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, dl := range deliveries {
+		dl.ID = "delivery" + strconv.Itoa(len(r.deliveries)+1)
+		r.deliveries = append(r.deliveries, dl)
+	}
+	return nil
+}
+
+func (r *memoryRepository) ListDeliveries(ctx context.Context, webhookID string) ([]webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deliveries []webhook.Delivery
+	for _, dl := range r.deliveries {
+		if dl.WebhookID == webhookID {
+			deliveries = append(deliveries, dl)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *memoryRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []webhook.Delivery
+	for i, dl := range r.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if dl.Status == webhook.DeliveryPending && !dl.NextAttemptAt.After(time.Now()) {
+			r.deliveries[i].NextAttemptAt = time.Now().Add(lease)
+			claimed = append(claimed, r.deliveries[i])
+		}
+	}
+	return claimed, nil
+}
+
+func (r *memoryRepository) UpdateDelivery(ctx context.Context, delivery webhook.Delivery) (webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, dl := range r.deliveries {
+		if dl.ID == delivery.ID {
+			r.deliveries[i] = delivery
+			return delivery, nil
+		}
+	}
+	return webhook.Delivery{}, webhook.ErrNotExist
+}
+
+func (r *memoryRepository) delivery(id string) webhook.Delivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, dl := range r.deliveries {
+		if dl.ID == id {
+			return dl
+		}
+	}
+	return webhook.Delivery{}
+}
+
+type memoryAuditService struct {
+	logs []audit.Log
+}
+
+func (s *memoryAuditService) Record(ctx context.Context, action, resourceType, resourceID string, oldPayload, newPayload any) error {
+	s.logs = append(s.logs, audit.Log{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		OldPayload:   oldPayload,
+		NewPayload:   newPayload,
+	})
+	return nil
+}
+
+func TestServiceCreate(t *testing.T) {
+	valid := webhook.Webhook{
+		URL:    "https://example.com/hooks",
+		Secret: "s3cret",
+		Events: []string{"organization.created", "policy.*", "*"},
+	}
+
+	t.Run("should create the webhook and audit it without its secret", func(t *testing.T) {
+		auditService := &memoryAuditService{}
+		svc := webhook.NewService(&memoryRepository{}, auditService)
+
+		created, err := svc.Create(context.Background(), valid)
+		assert.NoError(t, err)
+		assert.Equal(t, "hook1", created.ID)
+		assert.Equal(t, "s3cret", created.Secret)
+		require.Len(t, auditService.logs, 1)
+		assert.Empty(t, auditService.logs[0].NewPayload.(webhook.Webhook).Secret)
+	})
+
+	tests := []struct {
+		name   string
+		modify func(h *webhook.Webhook)
+	}{
+		{"relative url", func(h *webhook.Webhook) { h.URL = "/hooks" }},
+		{"non http url", func(h *webhook.Webhook) { h.URL = "ftp://example.com/hooks" }},
+		{"missing secret", func(h *webhook.Webhook) { h.Secret = " " }},
+		{"missing events", func(h *webhook.Webhook) { h.Events = nil }},
+		{"event without action", func(h *webhook.Webhook) { h.Events = []string{"organization"} }},
+		{"event with extra segment", func(h *webhook.Webhook) { h.Events = []string{"organization.created.now"} }},
+	}
+	for _, tc := range tests {
+		t.Run("should return error on "+tc.name, func(t *testing.T) {
+			hook := valid
+			hook.Events = append([]string{}, valid.Events...)
+			tc.modify(&hook)
+
+			_, err := webhook.NewService(&memoryRepository{}, &memoryAuditService{}).Create(context.Background(), hook)
+			assert.ErrorIs(t, err, webhook.ErrInvalidDetail)
+		})
+	}
+}
+
+func TestServiceNotify(t *testing.T) {
+	repo := &memoryRepository{
+		hooks: []webhook.Webhook{
+			{ID: "all", Events: []string{"*"}},
+			{ID: "orgs", Events: []string{"organization.*"}},
+			{ID: "org-created", Events: []string{"project.created", "organization.created"}},
+			{ID: "policies", Events: []string{"policy.updated"}},
+		},
+	}
+	svc := webhook.NewService(repo, &memoryAuditService{})
+
+	err := svc.Notify(context.Background(), event.Event{
+		ID:           "event-1",
+		Type:         "organization.created",
+		ResourceType: "organization",
+		ResourceID:   "org-1",
+	})
+	assert.NoError(t, err)
+
+	var hookIDs []string
+	for _, dl := range repo.deliveries {
+		hookIDs = append(hookIDs, dl.WebhookID)
+		assert.Equal(t, webhook.DeliveryPending, dl.Status)
+		assert.Equal(t, "organization.created", dl.EventType)
+	}
+	assert.Equal(t, []string{"all", "orgs", "org-created"}, hookIDs)
+
+	var ev event.Event
+	require.NoError(t, json.Unmarshal(repo.deliveries[0].Payload, &ev))
+	assert.Equal(t, "event-1", ev.ID)
+	assert.Equal(t, "org-1", ev.ResourceID)
+	assert.Equal(t, repo.deliveries[0].Payload, repo.deliveries[2].Payload)
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+type Repository interface {
+	Create(ctx context.Context, hook Webhook) (Webhook, error)
+	Get(ctx context.Context, id string) (Webhook, error)
+	List(ctx context.Context) ([]Webhook, error)
+	Delete(ctx context.Context, id string) error
+	CreateDeliveries(ctx context.Context, deliveries []Delivery) error
+	ListDeliveries(ctx context.Context, webhookID string) ([]Delivery, error)
+	// ClaimDeliveries returns at most limit pending deliveries due by now,
+	// they are not returned again before the lease passed so a delivery is
+	// attempted by a single worker at a time
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery Delivery) (Delivery, error)
+}
+
+// Webhook is an endpoint the changes made to the resources are posted to,
+// Events are the types of the events it is subscribed to and the Secret
+// signs the requests so the endpoint can verify they come from shield
+type Webhook struct {
+	ID        string
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+}
+
+// withoutSecret is the webhook as it is recorded in the audit logs
+func (w Webhook) withoutSecret() Webhook {
+	w.Secret = ""
+	return w
+}
+
+// Delivery is an event to post to a webhook, a failed attempt is retried
+// at NextAttemptAt until the attempts run out
+type Delivery struct {
+	ID            string
+	WebhookID     string
+	EventType     string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	DeliveredAt   time.Time
+}
@@ -2,8 +2,16 @@ package api
 
 import (
 	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/apikey"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/folder"
 	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/odpf/shield/core/invitation"
+	"github.com/odpf/shield/core/metaschema"
 	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/oidc"
 	"github.com/odpf/shield/core/organization"
 	"github.com/odpf/shield/core/policy"
 	"github.com/odpf/shield/core/project"
@@ -11,7 +19,13 @@ import (
 	"github.com/odpf/shield/core/resource"
 	"github.com/odpf/shield/core/role"
 	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/core/signingkey"
+	"github.com/odpf/shield/core/usage"
 	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/health"
+	"github.com/odpf/shield/internal/proxy"
 )
 
 type Deps struct {
@@ -25,5 +39,38 @@ type Deps struct {
 	ActionService    *action.Service
 	RelationService  *relation.Service
 	ResourceService  *resource.Service
+	FolderService    *folder.Service
 	RuleService      *rule.Service
+
+	ServiceUserService *serviceuser.Service
+	APIKeyService      *apikey.Service
+	InvitationService  *invitation.Service
+	SessionService     *session.Service
+	// MetaSchemaService validates the metadata of the users, organizations,
+	// projects and groups against the schemas registered for them
+	MetaSchemaService *metaschema.Service
+	// IdempotencyService replays the responses of the create requests
+	// retried with the same idempotency key
+	IdempotencyService *idempotency.Service
+	// OIDCService is set when oidc providers are configured
+	OIDCService *oidc.Service
+	// AuditService lists the changes recorded by the instances
+	AuditService *audit.Service
+	// EventHub pushes the changes recorded by the instance to its watchers
+	EventHub *event.Hub
+	// UsageCounter counts the requests and relation writes of the
+	// organizations made through the instance
+	UsageCounter *usage.Counter
+	UsageService *usage.Service
+	// ProxyRuleServices are the rules of the proxies served by the
+	// instance, by the name of the proxy
+	ProxyRuleServices map[string]*rule.Service
+	// ProxyBreakers are the circuit breakers of the backends of the proxies
+	// served by the same process, by proxy name
+	ProxyBreakers map[string]*proxy.Breakers
+	// SigningKeyService signs the tokens shield issues, its public keys are
+	// served on the jwks, nil when no encryption key is configured
+	SigningKeyService *signingkey.Service
+
+	HealthChecker *health.Checker
 }
@@ -0,0 +1,35 @@
+package v1beta1
+
+import (
+	"context"
+
+	"github.com/odpf/shield/core/relation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// the check and relation messages have no fields for consistency tokens,
+// the consistency of a check is read from the ConsistencyHeader of its
+// request and the token of a relation write is returned in the
+// ZedTokenHeader of its response. Policies are written to the spicedb
+// schema which has no tokens, checks made right after a policy change
+// ask for full consistency instead
+const (
+	ConsistencyHeader = "x-shield-consistency"
+	ZedTokenHeader    = "x-shield-zedtoken"
+)
+
+func checkConsistency(ctx context.Context) (relation.Consistency, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(ConsistencyHeader); len(values) > 0 {
+		return relation.ParseConsistency(values[0])
+	}
+	return relation.Consistency{}, nil
+}
+
+func setZedTokenHeader(ctx context.Context, rev *relation.Revision) error {
+	if rev.Token() == "" {
+		return nil
+	}
+	return grpc.SetHeader(ctx, metadata.Pairs(ZedTokenHeader, rev.Token()))
+}
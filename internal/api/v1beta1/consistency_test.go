@@ -0,0 +1,60 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/internal/api/v1beta1/mocks"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestConsistencyTokens(t *testing.T) {
+	t.Run("should return the token of the relation written in the header", func(t *testing.T) {
+		mockResourceSrv := new(mocks.ResourceService)
+		mockResourceSrv.EXPECT().CheckAuthz(mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		mockRelationSrv := new(mocks.RelationService)
+		mockRelationSrv.EXPECT().Create(mock.Anything, mock.Anything).
+			Run(func(ctx context.Context, rel relation.RelationV2) {
+				relation.RecordRevision(ctx, "token-1")
+			}).Return(testRelationV2, nil)
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+
+		_, err := Handler{resourceService: mockResourceSrv, relationService: mockRelationSrv}.CreateRelation(ctx, &shieldv1beta1.CreateRelationRequest{
+			Body: &shieldv1beta1.RelationRequestBody{ObjectId: "object-id", ObjectNamespace: "ns2", Subject: "ns1:subject-id", RoleName: "role1"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"token-1"}, stream.header.Get(ZedTokenHeader))
+	})
+
+	t.Run("should check with the consistency of the header", func(t *testing.T) {
+		mockResourceSrv := new(mocks.ResourceService)
+		mockResourceSrv.EXPECT().CheckAuthz(mock.Anything, resource.Resource{Name: "object-id", NamespaceID: "ns2"}, action.Action{ID: "view"}).
+			Run(func(ctx context.Context, res resource.Resource, act action.Action) {
+				assert.Equal(t, relation.Consistency{AtLeastAsFresh: "token-1"}, relation.ConsistencyFromContext(ctx))
+			}).Return(true, nil)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ConsistencyHeader, "at-least-as-fresh=token-1"))
+
+		resp, err := Handler{resourceService: mockResourceSrv}.CheckResourcePermission(ctx, &shieldv1beta1.CheckResourcePermissionRequest{
+			ObjectId: "object-id", ObjectNamespace: "ns2", Permission: "view",
+		})
+		assert.NoError(t, err)
+		assert.True(t, resp.GetStatus())
+	})
+
+	t.Run("should reject an invalid consistency", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ConsistencyHeader, "at-least-as-fresh="))
+
+		_, err := Handler{}.CheckResourcePermission(ctx, &shieldv1beta1.CheckResourcePermissionRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,40 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// the organizations and projects have no state field, the deleted ones are
+// listed along with the others with the IncludeDeletedHeader of the request
+// set to true and the ids of the deleted ones are returned in the
+// DeletedHeader of the response
+const (
+	IncludeDeletedHeader = "x-shield-include-deleted"
+	DeletedHeader        = "x-shield-deleted"
+)
+
+func includeDeleted(ctx context.Context) (bool, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(IncludeDeletedHeader)
+	if len(values) == 0 {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return false, fmt.Errorf("%s should be true or false", IncludeDeletedHeader)
+	}
+	return include, nil
+}
+
+func setDeletedHeader(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return grpc.SetHeader(ctx, metadata.Pairs(DeletedHeader, strings.Join(ids, ",")))
+}
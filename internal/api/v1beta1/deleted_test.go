@@ -0,0 +1,34 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/internal/api/v1beta1/mocks"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestIncludeDeleted(t *testing.T) {
+	t.Run("should list the deleted projects with the header", func(t *testing.T) {
+		mockProjectSrv := new(mocks.ProjectService)
+		mockProjectSrv.EXPECT().List(mock.Anything, project.Filter{IncludeDeleted: true}).Return([]project.Project{{ID: "project-1", Slug: "odpf-shield"}}, nil)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IncludeDeletedHeader, "true"))
+
+		resp, err := Handler{projectService: mockProjectSrv}.ListProjects(ctx, &shieldv1beta1.ListProjectsRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, resp.GetProjects(), 1)
+	})
+
+	t.Run("should refuse a header that isn't a bool", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IncludeDeletedHeader, "sometimes"))
+
+		_, err := Handler{}.ListOrganizations(ctx, &shieldv1beta1.ListOrganizationsRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,58 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// the list messages have no fields for the globs the lists are narrowed
+// down by, every value of the ListFilterHeader is a field=glob where the
+// field is one of the fields of the list or metadata.<key>. The glob matches
+// the whole value ignoring case and * matches any run of characters.
+const (
+	ListFilterHeader = "x-shield-filter"
+
+	metadataFilterPrefix = "metadata."
+)
+
+// listFilters returns the globs of the fields and of the metadata keys in
+// the ListFilterHeader of the request, a field can only be filtered once
+func listFilters(ctx context.Context, fields ...string) (map[string]string, map[string]string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	globs := map[string]string{}
+	metadataGlobs := map[string]string{}
+	for _, v := range md.Get(ListFilterHeader) {
+		field, glob, ok := strings.Cut(v, "=")
+		if !ok || field == "" {
+			return nil, nil, fmt.Errorf("%s should be field=glob, got '%s'", ListFilterHeader, v)
+		}
+
+		target, key := globs, field
+		if strings.HasPrefix(field, metadataFilterPrefix) && field != metadataFilterPrefix {
+			target, key = metadataGlobs, strings.TrimPrefix(field, metadataFilterPrefix)
+		} else if !containsField(fields, field) {
+			return nil, nil, fmt.Errorf("%s can't filter by '%s', valid: %s, metadata.<key>", ListFilterHeader, field, strings.Join(fields, ", "))
+		}
+		if _, ok := target[key]; ok {
+			return nil, nil, fmt.Errorf("%s filters '%s' more than once", ListFilterHeader, field)
+		}
+		target[key] = glob
+	}
+	// no metadata globs leave the metadata of the filter unset
+	if len(metadataGlobs) == 0 {
+		metadataGlobs = nil
+	}
+	return globs, metadataGlobs, nil
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
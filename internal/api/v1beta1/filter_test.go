@@ -0,0 +1,55 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/api/v1beta1/mocks"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestListFilters(t *testing.T) {
+	t.Run("should list the projects matching the globs of the headers", func(t *testing.T) {
+		mockProjectSrv := new(mocks.ProjectService)
+		mockProjectSrv.EXPECT().List(mock.Anything, project.Filter{
+			Slug:     "odpf-*",
+			Metadata: map[string]string{"team": "data"},
+		}).Return([]project.Project{{ID: "project-1", Slug: "odpf-shield"}}, nil)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ListFilterHeader, "slug=odpf-*", ListFilterHeader, "metadata.team=data"))
+
+		resp, err := Handler{projectService: mockProjectSrv}.ListProjects(ctx, &shieldv1beta1.ListProjectsRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, resp.GetProjects(), 1)
+	})
+
+	t.Run("should list the users matching the email glob of the header", func(t *testing.T) {
+		mockUserSrv := new(mocks.UserService)
+		mockUserSrv.EXPECT().List(mock.Anything, user.Filter{Email: "*@gojek.com"}).Return(user.PagedUsers{Count: 1, Users: []user.User{{ID: "user-1", Email: "john@gojek.com"}}}, nil)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ListFilterHeader, "email=*@gojek.com"))
+
+		resp, err := Handler{userService: mockUserSrv}.ListUsers(ctx, &shieldv1beta1.ListUsersRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, resp.GetUsers(), 1)
+	})
+
+	t.Run("should refuse a field the list can't be filtered by", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ListFilterHeader, "email=*@gojek.com"))
+
+		_, err := Handler{}.ListOrganizations(ctx, &shieldv1beta1.ListOrganizationsRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("should refuse a field filtered more than once", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ListFilterHeader, "name=a*", ListFilterHeader, "name=*b"))
+
+		_, err := Handler{}.ListGroups(ctx, &shieldv1beta1.ListGroupsRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -13,6 +13,7 @@ import (
 	grpczap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 
 	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/metaschema"
 	"github.com/odpf/shield/core/organization"
 	"github.com/odpf/shield/core/user"
 
@@ -48,8 +49,28 @@ func (h Handler) ListGroups(ctx context.Context, request *shieldv1beta1.ListGrou
 
 	var groups []*shieldv1beta1.Group
 
+	orgID, err := h.resolveOrgID(ctx, request.GetOrgId(), grpcOrgNotFoundErr)
+	if err != nil {
+		return nil, err
+	}
+
+	size, num, err := listPage(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	globs, metadataGlobs, err := listFilters(ctx, "name", "slug")
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	groupList, err := h.groupService.List(ctx, group.Filter{
-		OrganizationID: request.GetOrgId(),
+		OrganizationID: orgID,
+		Name:           globs["name"],
+		Slug:           globs["slug"],
+		Metadata:       metadataGlobs,
+		Limit:          size,
+		Page:           num,
 	})
 	if err != nil {
 		logger.Error(err.Error())
@@ -82,10 +103,19 @@ func (h Handler) CreateGroup(ctx context.Context, request *shieldv1beta1.CreateG
 		return nil, grpcBadBodyError
 	}
 
+	if err := h.validateMetadata(ctx, metaschema.EntityGroup, metaDataMap); err != nil {
+		return nil, err
+	}
+
+	orgID, err := h.resolveOrgID(ctx, request.GetBody().GetOrgId(), grpcBadBodyError)
+	if err != nil {
+		return nil, err
+	}
+
 	grp := group.Group{
 		Name:           request.GetBody().GetName(),
 		Slug:           request.GetBody().GetSlug(),
-		OrganizationID: request.GetBody().GetOrgId(),
+		OrganizationID: orgID,
 		Metadata:       metaDataMap,
 	}
 
@@ -101,7 +131,7 @@ func (h Handler) CreateGroup(ctx context.Context, request *shieldv1beta1.CreateG
 			return nil, grpcConflictError
 		case errors.Is(err, group.ErrInvalidDetail), errors.Is(err, organization.ErrNotExist), errors.Is(err, organization.ErrInvalidUUID):
 			return nil, grpcBadBodyError
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		default:
 			return nil, grpcInternalServerError
@@ -113,6 +143,10 @@ func (h Handler) CreateGroup(ctx context.Context, request *shieldv1beta1.CreateG
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
+	if err := setETagHeader(ctx, newGroup.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
 
 	return &shieldv1beta1.CreateGroupResponse{Group: &shieldv1beta1.Group{
 		Id:        newGroup.ID,
@@ -144,6 +178,10 @@ func (h Handler) GetGroup(ctx context.Context, request *shieldv1beta1.GetGroupRe
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
+	if err := setETagHeader(ctx, fetchedGroup.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
 
 	return &shieldv1beta1.GetGroupResponse{Group: &groupPB}, nil
 }
@@ -161,13 +199,13 @@ func (h Handler) AddGroupUser(ctx context.Context, request *shieldv1beta1.AddGro
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, errors.ErrForbidden):
 			return nil, grpcPermissionDenied
 		case errors.Is(err, group.ErrNotExist):
 			return nil, grpcGroupNotFoundErr
-		case errors.Is(err, user.ErrInvalidUUID), errors.Is(err, user.ErrInvalidID):
+		case errors.Is(err, user.ErrInvalidUUID), errors.Is(err, user.ErrInvalidID), errors.Is(err, user.ErrNotExist):
 			return nil, grpcBadBodyError
 		default:
 			return nil, grpcInternalServerError
@@ -195,7 +233,7 @@ func (h Handler) RemoveGroupUser(ctx context.Context, request *shieldv1beta1.Rem
 	if _, err := h.groupService.RemoveUser(ctx, request.GetId(), request.GetUserId()); err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, errors.ErrForbidden):
 			return nil, grpcPermissionDenied
@@ -219,27 +257,42 @@ func (h Handler) UpdateGroup(ctx context.Context, request *shieldv1beta1.UpdateG
 	if request.GetBody() == nil {
 		return nil, grpcBadBodyError
 	}
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	metaDataMap, err := metadata.Build(request.GetBody().GetMetadata().AsMap())
 	if err != nil {
 		return nil, grpcBadBodyError
 	}
 
+	if err := h.validateMetadata(ctx, metaschema.EntityGroup, metaDataMap); err != nil {
+		return nil, err
+	}
+
+	orgID, err := h.resolveOrgID(ctx, request.GetBody().GetOrgId(), grpcBadBodyError)
+	if err != nil {
+		return nil, err
+	}
+
 	var updatedGroup group.Group
 	if uuid.IsValid(request.GetId()) {
 		updatedGroup, err = h.groupService.Update(ctx, group.Group{
 			ID:             request.GetId(),
 			Name:           request.GetBody().GetName(),
 			Slug:           request.GetBody().GetSlug(),
-			OrganizationID: request.GetBody().GetOrgId(),
+			OrganizationID: orgID,
 			Metadata:       metaDataMap,
+			Version:        version,
 		})
 	} else {
 		updatedGroup, err = h.groupService.Update(ctx, group.Group{
 			Name:           request.GetBody().GetName(),
 			Slug:           request.GetId(),
-			OrganizationID: request.GetBody().GetOrgId(),
+			OrganizationID: orgID,
 			Metadata:       metaDataMap,
+			Version:        version,
 		})
 	}
 	if err != nil {
@@ -251,6 +304,8 @@ func (h Handler) UpdateGroup(ctx context.Context, request *shieldv1beta1.UpdateG
 			return nil, grpcGroupNotFoundErr
 		case errors.Is(err, group.ErrConflict):
 			return nil, grpcConflictError
+		case errors.Is(err, group.ErrVersionMismatch):
+			return nil, grpcVersionMismatchError
 		case errors.Is(err, group.ErrInvalidDetail),
 			errors.Is(err, organization.ErrInvalidUUID),
 			errors.Is(err, organization.ErrNotExist):
@@ -264,6 +319,10 @@ func (h Handler) UpdateGroup(ctx context.Context, request *shieldv1beta1.UpdateG
 	if err != nil {
 		return nil, grpcInternalServerError
 	}
+	if err := setETagHeader(ctx, updatedGroup.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
 
 	return &shieldv1beta1.UpdateGroupResponse{Group: &groupPB}, nil
 }
@@ -309,7 +368,7 @@ func (h Handler) AddGroupAdmin(ctx context.Context, request *shieldv1beta1.AddGr
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, errors.ErrForbidden):
 			return nil, grpcPermissionDenied
@@ -344,7 +403,7 @@ func (h Handler) RemoveGroupAdmin(ctx context.Context, request *shieldv1beta1.Re
 	if _, err := h.groupService.RemoveAdmin(ctx, request.GetId(), request.GetUserId()); err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, errors.ErrForbidden):
 			return nil, grpcPermissionDenied
@@ -104,20 +104,32 @@ func TestHandler_ListGroups(t *testing.T) {
 	randomID := uuid.NewString()
 	tests := []struct {
 		name    string
-		setup   func(gs *mocks.GroupService)
+		setup   func(gs *mocks.GroupService, os *mocks.OrganizationService)
 		request *shieldv1beta1.ListGroupsRequest
 		want    *shieldv1beta1.ListGroupsResponse
 		wantErr error
 	}{
 		{
-			name: "should return empty groups if query param org_id is not uuid",
-			setup: func(gs *mocks.GroupService) {
+			name: "should return org not found error if query param org_id is not uuid and no org has the slug",
+			setup: func(gs *mocks.GroupService, os *mocks.OrganizationService) {
+				os.EXPECT().Get(mock.AnythingOfType("*context.emptyCtx"), "some-id").Return(organization.Organization{}, organization.ErrNotExist)
+			},
+			request: &shieldv1beta1.ListGroupsRequest{
+				OrgId: "some-id",
+			},
+			want:    nil,
+			wantErr: grpcOrgNotFoundErr,
+		},
+		{
+			name: "should return the groups of the org with the slug of query param org_id",
+			setup: func(gs *mocks.GroupService, os *mocks.OrganizationService) {
+				os.EXPECT().Get(mock.AnythingOfType("*context.emptyCtx"), "org-1").Return(organization.Organization{ID: randomID, Slug: "org-1"}, nil)
 				gs.EXPECT().List(mock.AnythingOfType("*context.emptyCtx"), group.Filter{
-					OrganizationID: "some-id",
+					OrganizationID: randomID,
 				}).Return([]group.Group{}, nil)
 			},
 			request: &shieldv1beta1.ListGroupsRequest{
-				OrgId: "some-id",
+				OrgId: "org-1",
 			},
 			want: &shieldv1beta1.ListGroupsResponse{
 				Groups: nil,
@@ -126,7 +138,7 @@ func TestHandler_ListGroups(t *testing.T) {
 		},
 		{
 			name: "should return empty groups if query param org_id is not exist",
-			setup: func(gs *mocks.GroupService) {
+			setup: func(gs *mocks.GroupService, os *mocks.OrganizationService) {
 				gs.EXPECT().List(mock.AnythingOfType("*context.emptyCtx"), group.Filter{
 					OrganizationID: randomID,
 				}).Return([]group.Group{}, nil)
@@ -141,7 +153,7 @@ func TestHandler_ListGroups(t *testing.T) {
 		},
 		{
 			name: "should return all groups if no query param filter exist",
-			setup: func(gs *mocks.GroupService) {
+			setup: func(gs *mocks.GroupService, os *mocks.OrganizationService) {
 				var testGroupList []group.Group
 				for _, u := range testGroupMap {
 					testGroupList = append(testGroupList, u)
@@ -170,7 +182,7 @@ func TestHandler_ListGroups(t *testing.T) {
 		},
 		{
 			name: "should return filtered groups if query param org_id exist",
-			setup: func(gs *mocks.GroupService) {
+			setup: func(gs *mocks.GroupService, os *mocks.OrganizationService) {
 				var testGroupList []group.Group
 				for _, u := range testGroupMap {
 					testGroupList = append(testGroupList, u)
@@ -205,11 +217,13 @@ func TestHandler_ListGroups(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockGroupSvc := new(mocks.GroupService)
+			mockOrgSvc := new(mocks.OrganizationService)
 			if tt.setup != nil {
-				tt.setup(mockGroupSvc)
+				tt.setup(mockGroupSvc, mockOrgSvc)
 			}
 			h := Handler{
 				groupService: mockGroupSvc,
+				orgService:   mockOrgSvc,
 			}
 			got, err := h.ListGroups(context.Background(), tt.request)
 			assert.EqualValues(t, got, tt.want)
@@ -224,14 +238,14 @@ func TestHandler_CreateGroup(t *testing.T) {
 	someGroupID := uuid.NewString()
 	tests := []struct {
 		name    string
-		setup   func(ctx context.Context, gs *mocks.GroupService) context.Context
+		setup   func(ctx context.Context, gs *mocks.GroupService, os *mocks.OrganizationService) context.Context
 		request *shieldv1beta1.CreateGroupRequest
 		want    *shieldv1beta1.CreateGroupResponse
 		wantErr error
 	}{
 		{
 			name: "should return unauthenticated error if auth email in context is empty and group service return invalid user email",
-			setup: func(ctx context.Context, gs *mocks.GroupService) context.Context {
+			setup: func(ctx context.Context, gs *mocks.GroupService, os *mocks.OrganizationService) context.Context {
 				gs.EXPECT().Create(mock.AnythingOfType("*context.emptyCtx"), group.Group{
 					Name: "some group",
 					Slug: "some-group",
@@ -251,7 +265,7 @@ func TestHandler_CreateGroup(t *testing.T) {
 		},
 		{
 			name: "should return internal error if group service return some error",
-			setup: func(ctx context.Context, gs *mocks.GroupService) context.Context {
+			setup: func(ctx context.Context, gs *mocks.GroupService, os *mocks.OrganizationService) context.Context {
 				gs.EXPECT().Create(mock.AnythingOfType("*context.valueCtx"), group.Group{
 					Name: "some group",
 					Slug: "some-group",
@@ -271,7 +285,7 @@ func TestHandler_CreateGroup(t *testing.T) {
 		},
 		{
 			name: "should return already exist error if group service return error conflict",
-			setup: func(ctx context.Context, gs *mocks.GroupService) context.Context {
+			setup: func(ctx context.Context, gs *mocks.GroupService, os *mocks.OrganizationService) context.Context {
 				gs.EXPECT().Create(mock.AnythingOfType("*context.valueCtx"), group.Group{
 					Name: "some group",
 					Slug: "some-group",
@@ -292,7 +306,7 @@ func TestHandler_CreateGroup(t *testing.T) {
 		},
 		{
 			name: "should return bad request error if name empty",
-			setup: func(ctx context.Context, gs *mocks.GroupService) context.Context {
+			setup: func(ctx context.Context, gs *mocks.GroupService, os *mocks.OrganizationService) context.Context {
 				gs.EXPECT().Create(mock.AnythingOfType("*context.valueCtx"), group.Group{
 					Slug: "some-group",
 
@@ -310,14 +324,9 @@ func TestHandler_CreateGroup(t *testing.T) {
 			wantErr: grpcBadBodyError,
 		},
 		{
-			name: "should return bad request error if org id is not uuid",
-			setup: func(ctx context.Context, gs *mocks.GroupService) context.Context {
-				gs.EXPECT().Create(mock.AnythingOfType("*context.valueCtx"), group.Group{
-					Name:           "some group",
-					Slug:           "some-group",
-					OrganizationID: "some-org-id",
-					Metadata:       metadata.Metadata{},
-				}).Return(group.Group{}, organization.ErrInvalidUUID)
+			name: "should return bad request error if org id is not uuid and no org has the slug",
+			setup: func(ctx context.Context, gs *mocks.GroupService, os *mocks.OrganizationService) context.Context {
+				os.EXPECT().Get(mock.AnythingOfType("*context.valueCtx"), "some-org-id").Return(organization.Organization{}, organization.ErrNotExist)
 				return user.SetContextWithEmail(ctx, email)
 			},
 			request: &shieldv1beta1.CreateGroupRequest{Body: &shieldv1beta1.GroupRequestBody{
@@ -331,7 +340,7 @@ func TestHandler_CreateGroup(t *testing.T) {
 		},
 		{
 			name: "should return bad request error if org id not exist",
-			setup: func(ctx context.Context, gs *mocks.GroupService) context.Context {
+			setup: func(ctx context.Context, gs *mocks.GroupService, os *mocks.OrganizationService) context.Context {
 				gs.EXPECT().Create(mock.AnythingOfType("*context.valueCtx"), group.Group{
 					Name: "some group",
 					Slug: "some-group",
@@ -358,7 +367,7 @@ func TestHandler_CreateGroup(t *testing.T) {
 		},
 		{
 			name: "should return success if group service return nil",
-			setup: func(ctx context.Context, gs *mocks.GroupService) context.Context {
+			setup: func(ctx context.Context, gs *mocks.GroupService, os *mocks.OrganizationService) context.Context {
 				gs.EXPECT().Create(mock.AnythingOfType("*context.valueCtx"), group.Group{
 					Name: "some group",
 					Slug: "some-group",
@@ -399,12 +408,14 @@ func TestHandler_CreateGroup(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockGroupSvc := new(mocks.GroupService)
+			mockOrgSvc := new(mocks.OrganizationService)
 			ctx := context.Background()
 			if tt.setup != nil {
-				ctx = tt.setup(ctx, mockGroupSvc)
+				ctx = tt.setup(ctx, mockGroupSvc, mockOrgSvc)
 			}
 			h := Handler{
 				groupService: mockGroupSvc,
+				orgService:   mockOrgSvc,
 			}
 			got, err := h.CreateGroup(ctx, tt.request)
 			assert.EqualValues(t, got, tt.want)
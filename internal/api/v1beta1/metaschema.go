@@ -0,0 +1,50 @@
+package v1beta1
+
+import (
+	"context"
+	"errors"
+
+	grpczap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/odpf/shield/core/metaschema"
+	"github.com/odpf/shield/pkg/metadata"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type MetaSchemaService interface {
+	Validate(ctx context.Context, entity string, metadata map[string]any) error
+}
+
+// validateMetadata checks the metadata of an entity against the schema
+// registered for its type, the violations are returned as the field
+// violations of a bad request
+func (h Handler) validateMetadata(ctx context.Context, entity string, md metadata.Metadata) error {
+	if h.metaSchemaService == nil {
+		return nil
+	}
+
+	err := h.metaSchemaService.Validate(ctx, entity, md)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr metaschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		grpczap.Extract(ctx).Error(err.Error())
+		return grpcInternalServerError
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range validationErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Message,
+		})
+	}
+	st, detailsErr := status.New(codes.InvalidArgument, validationErr.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	}
+	return st.Err()
+}
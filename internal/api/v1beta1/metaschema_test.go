@@ -0,0 +1,67 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/metaschema"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/api/v1beta1/mocks"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type metaSchemaServiceFunc func(ctx context.Context, entity string, metadata map[string]any) error
+
+func (f metaSchemaServiceFunc) Validate(ctx context.Context, entity string, metadata map[string]any) error {
+	return f(ctx, entity, metadata)
+}
+
+func TestMetadataValidation(t *testing.T) {
+	requireManager := metaSchemaServiceFunc(func(ctx context.Context, entity string, metadata map[string]any) error {
+		if _, ok := metadata["manager_email"]; ok || entity != metaschema.EntityUser {
+			return nil
+		}
+		return metaschema.ValidationError{Entity: entity, Violations: []metaschema.Violation{
+			{Field: "metadata.manager_email", Constraint: "required", Message: "is required"},
+		}}
+	})
+
+	t.Run("should return the violations of the schema as field violations", func(t *testing.T) {
+		_, err := Handler{metaSchemaService: requireManager}.CreateUser(user.SetContextWithEmail(context.Background(), "admin@odpf.io"), &shieldv1beta1.CreateUserRequest{Body: &shieldv1beta1.UserRequestBody{
+			Name:     "user 1",
+			Email:    "user1@odpf.io",
+			Metadata: &structpb.Struct{},
+		}})
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		require.Len(t, st.Details(), 1)
+		badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+		require.True(t, ok)
+		require.Len(t, badRequest.GetFieldViolations(), 1)
+		assert.Equal(t, "metadata.manager_email", badRequest.GetFieldViolations()[0].GetField())
+		assert.Equal(t, "is required", badRequest.GetFieldViolations()[0].GetDescription())
+	})
+
+	t.Run("should create the user when the metadata match the schema", func(t *testing.T) {
+		mockUserSrv := new(mocks.UserService)
+		mockUserSrv.EXPECT().Create(mock.Anything, mock.Anything).Return(user.User{Name: "user 1", Email: "user1@odpf.io"}, nil)
+		manager, err := structpb.NewStruct(map[string]any{"manager_email": "lead@odpf.io"})
+		require.NoError(t, err)
+
+		_, err = Handler{userService: mockUserSrv, metaSchemaService: requireManager}.CreateUser(user.SetContextWithEmail(context.Background(), "admin@odpf.io"), &shieldv1beta1.CreateUserRequest{Body: &shieldv1beta1.UserRequestBody{
+			Name:     "user 1",
+			Email:    "user1@odpf.io",
+			Metadata: manager,
+		}})
+		assert.NoError(t, err)
+	})
+}
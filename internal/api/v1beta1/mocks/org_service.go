@@ -162,13 +162,13 @@ func (_c *OrganizationService_Get_Call) Return(_a0 organization.Organization, _a
 	return _c
 }
 
-// List provides a mock function with given fields: ctx
-func (_m *OrganizationService) List(ctx context.Context) ([]organization.Organization, error) {
-	ret := _m.Called(ctx)
+// List provides a mock function with given fields: ctx, flt
+func (_m *OrganizationService) List(ctx context.Context, flt organization.Filter) ([]organization.Organization, error) {
+	ret := _m.Called(ctx, flt)
 
 	var r0 []organization.Organization
-	if rf, ok := ret.Get(0).(func(context.Context) []organization.Organization); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, organization.Filter) []organization.Organization); ok {
+		r0 = rf(ctx, flt)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]organization.Organization)
@@ -176,8 +176,8 @@ func (_m *OrganizationService) List(ctx context.Context) ([]organization.Organiz
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, organization.Filter) error); ok {
+		r1 = rf(ctx, flt)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -192,13 +192,14 @@ type OrganizationService_List_Call struct {
 
 // List is a helper method to define mock.On call
 //  - ctx context.Context
-func (_e *OrganizationService_Expecter) List(ctx interface{}) *OrganizationService_List_Call {
-	return &OrganizationService_List_Call{Call: _e.mock.On("List", ctx)}
+//  - flt organization.Filter
+func (_e *OrganizationService_Expecter) List(ctx interface{}, flt interface{}) *OrganizationService_List_Call {
+	return &OrganizationService_List_Call{Call: _e.mock.On("List", ctx, flt)}
 }
 
-func (_c *OrganizationService_List_Call) Run(run func(ctx context.Context)) *OrganizationService_List_Call {
+func (_c *OrganizationService_List_Call) Run(run func(ctx context.Context, flt organization.Filter)) *OrganizationService_List_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
+		run(args[0].(context.Context), args[1].(organization.Filter))
 	})
 	return _c
 }
@@ -348,6 +349,96 @@ func (_c *OrganizationService_Update_Call) Return(_a0 organization.Organization,
 	return _c
 }
 
+// ValidateCreate provides a mock function with given fields: ctx, org
+func (_m *OrganizationService) ValidateCreate(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	ret := _m.Called(ctx, org)
+
+	var r0 organization.Organization
+	if rf, ok := ret.Get(0).(func(context.Context, organization.Organization) organization.Organization); ok {
+		r0 = rf(ctx, org)
+	} else {
+		r0 = ret.Get(0).(organization.Organization)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, organization.Organization) error); ok {
+		r1 = rf(ctx, org)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OrganizationService_ValidateCreate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateCreate'
+type OrganizationService_ValidateCreate_Call struct {
+	*mock.Call
+}
+
+// ValidateCreate is a helper method to define mock.On call
+//  - ctx context.Context
+//  - org organization.Organization
+func (_e *OrganizationService_Expecter) ValidateCreate(ctx interface{}, org interface{}) *OrganizationService_ValidateCreate_Call {
+	return &OrganizationService_ValidateCreate_Call{Call: _e.mock.On("ValidateCreate", ctx, org)}
+}
+
+func (_c *OrganizationService_ValidateCreate_Call) Run(run func(ctx context.Context, org organization.Organization)) *OrganizationService_ValidateCreate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(organization.Organization))
+	})
+	return _c
+}
+
+func (_c *OrganizationService_ValidateCreate_Call) Return(_a0 organization.Organization, _a1 error) *OrganizationService_ValidateCreate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+// ValidateUpdate provides a mock function with given fields: ctx, toUpdate
+func (_m *OrganizationService) ValidateUpdate(ctx context.Context, toUpdate organization.Organization) (organization.Organization, error) {
+	ret := _m.Called(ctx, toUpdate)
+
+	var r0 organization.Organization
+	if rf, ok := ret.Get(0).(func(context.Context, organization.Organization) organization.Organization); ok {
+		r0 = rf(ctx, toUpdate)
+	} else {
+		r0 = ret.Get(0).(organization.Organization)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, organization.Organization) error); ok {
+		r1 = rf(ctx, toUpdate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OrganizationService_ValidateUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateUpdate'
+type OrganizationService_ValidateUpdate_Call struct {
+	*mock.Call
+}
+
+// ValidateUpdate is a helper method to define mock.On call
+//  - ctx context.Context
+//  - toUpdate organization.Organization
+func (_e *OrganizationService_Expecter) ValidateUpdate(ctx interface{}, toUpdate interface{}) *OrganizationService_ValidateUpdate_Call {
+	return &OrganizationService_ValidateUpdate_Call{Call: _e.mock.On("ValidateUpdate", ctx, toUpdate)}
+}
+
+func (_c *OrganizationService_ValidateUpdate_Call) Run(run func(ctx context.Context, toUpdate organization.Organization)) *OrganizationService_ValidateUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(organization.Organization))
+	})
+	return _c
+}
+
+func (_c *OrganizationService_ValidateUpdate_Call) Return(_a0 organization.Organization, _a1 error) *OrganizationService_ValidateUpdate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
 type mockConstructorTestingTNewOrganizationService interface {
 	mock.TestingT
 	Cleanup(func())
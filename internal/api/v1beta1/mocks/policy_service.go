@@ -114,22 +114,22 @@ func (_c *PolicyService_Get_Call) Return(_a0 policy.Policy, _a1 error) *PolicySe
 	return _c
 }
 
-// List provides a mock function with given fields: ctx
-func (_m *PolicyService) List(ctx context.Context) ([]policy.Policy, error) {
-	ret := _m.Called(ctx)
+// List provides a mock function with given fields: ctx, flt, expand
+func (_m *PolicyService) List(ctx context.Context, flt policy.Filters, expand bool) ([]policy.ExpandedPolicy, error) {
+	ret := _m.Called(ctx, flt, expand)
 
-	var r0 []policy.Policy
-	if rf, ok := ret.Get(0).(func(context.Context) []policy.Policy); ok {
-		r0 = rf(ctx)
+	var r0 []policy.ExpandedPolicy
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Filters, bool) []policy.ExpandedPolicy); ok {
+		r0 = rf(ctx, flt, expand)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]policy.Policy)
+			r0 = ret.Get(0).([]policy.ExpandedPolicy)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, policy.Filters, bool) error); ok {
+		r1 = rf(ctx, flt, expand)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -144,18 +144,20 @@ type PolicyService_List_Call struct {
 
 // List is a helper method to define mock.On call
 //  - ctx context.Context
-func (_e *PolicyService_Expecter) List(ctx interface{}) *PolicyService_List_Call {
-	return &PolicyService_List_Call{Call: _e.mock.On("List", ctx)}
+//  - flt policy.Filters
+//  - expand bool
+func (_e *PolicyService_Expecter) List(ctx interface{}, flt interface{}, expand interface{}) *PolicyService_List_Call {
+	return &PolicyService_List_Call{Call: _e.mock.On("List", ctx, flt, expand)}
 }
 
-func (_c *PolicyService_List_Call) Run(run func(ctx context.Context)) *PolicyService_List_Call {
+func (_c *PolicyService_List_Call) Run(run func(ctx context.Context, flt policy.Filters, expand bool)) *PolicyService_List_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
+		run(args[0].(context.Context), args[1].(policy.Filters), args[2].(bool))
 	})
 	return _c
 }
 
-func (_c *PolicyService_List_Call) Return(_a0 []policy.Policy, _a1 error) *PolicyService_List_Call {
+func (_c *PolicyService_List_Call) Return(_a0 []policy.ExpandedPolicy, _a1 error) *PolicyService_List_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
@@ -207,6 +209,96 @@ func (_c *PolicyService_Update_Call) Return(_a0 []policy.Policy, _a1 error) *Pol
 	return _c
 }
 
+// ValidateCreate provides a mock function with given fields: ctx, pol
+func (_m *PolicyService) ValidateCreate(ctx context.Context, pol policy.Policy) (policy.Policy, error) {
+	ret := _m.Called(ctx, pol)
+
+	var r0 policy.Policy
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Policy) policy.Policy); ok {
+		r0 = rf(ctx, pol)
+	} else {
+		r0 = ret.Get(0).(policy.Policy)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, policy.Policy) error); ok {
+		r1 = rf(ctx, pol)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PolicyService_ValidateCreate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateCreate'
+type PolicyService_ValidateCreate_Call struct {
+	*mock.Call
+}
+
+// ValidateCreate is a helper method to define mock.On call
+//  - ctx context.Context
+//  - pol policy.Policy
+func (_e *PolicyService_Expecter) ValidateCreate(ctx interface{}, pol interface{}) *PolicyService_ValidateCreate_Call {
+	return &PolicyService_ValidateCreate_Call{Call: _e.mock.On("ValidateCreate", ctx, pol)}
+}
+
+func (_c *PolicyService_ValidateCreate_Call) Run(run func(ctx context.Context, pol policy.Policy)) *PolicyService_ValidateCreate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(policy.Policy))
+	})
+	return _c
+}
+
+func (_c *PolicyService_ValidateCreate_Call) Return(_a0 policy.Policy, _a1 error) *PolicyService_ValidateCreate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+// ValidateUpdate provides a mock function with given fields: ctx, pol
+func (_m *PolicyService) ValidateUpdate(ctx context.Context, pol policy.Policy) (policy.Policy, error) {
+	ret := _m.Called(ctx, pol)
+
+	var r0 policy.Policy
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Policy) policy.Policy); ok {
+		r0 = rf(ctx, pol)
+	} else {
+		r0 = ret.Get(0).(policy.Policy)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, policy.Policy) error); ok {
+		r1 = rf(ctx, pol)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PolicyService_ValidateUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateUpdate'
+type PolicyService_ValidateUpdate_Call struct {
+	*mock.Call
+}
+
+// ValidateUpdate is a helper method to define mock.On call
+//  - ctx context.Context
+//  - pol policy.Policy
+func (_e *PolicyService_Expecter) ValidateUpdate(ctx interface{}, pol interface{}) *PolicyService_ValidateUpdate_Call {
+	return &PolicyService_ValidateUpdate_Call{Call: _e.mock.On("ValidateUpdate", ctx, pol)}
+}
+
+func (_c *PolicyService_ValidateUpdate_Call) Run(run func(ctx context.Context, pol policy.Policy)) *PolicyService_ValidateUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(policy.Policy))
+	})
+	return _c
+}
+
+func (_c *PolicyService_ValidateUpdate_Call) Return(_a0 policy.Policy, _a1 error) *PolicyService_ValidateUpdate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
 type mockConstructorTestingTNewPolicyService interface {
 	mock.TestingT
 	Cleanup(func())
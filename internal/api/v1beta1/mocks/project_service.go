@@ -162,13 +162,13 @@ func (_c *ProjectService_Get_Call) Return(_a0 project.Project, _a1 error) *Proje
 	return _c
 }
 
-// List provides a mock function with given fields: ctx
-func (_m *ProjectService) List(ctx context.Context) ([]project.Project, error) {
-	ret := _m.Called(ctx)
+// List provides a mock function with given fields: ctx, flt
+func (_m *ProjectService) List(ctx context.Context, flt project.Filter) ([]project.Project, error) {
+	ret := _m.Called(ctx, flt)
 
 	var r0 []project.Project
-	if rf, ok := ret.Get(0).(func(context.Context) []project.Project); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, project.Filter) []project.Project); ok {
+		r0 = rf(ctx, flt)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]project.Project)
@@ -176,8 +176,8 @@ func (_m *ProjectService) List(ctx context.Context) ([]project.Project, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, project.Filter) error); ok {
+		r1 = rf(ctx, flt)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -192,13 +192,14 @@ type ProjectService_List_Call struct {
 
 // List is a helper method to define mock.On call
 //  - ctx context.Context
-func (_e *ProjectService_Expecter) List(ctx interface{}) *ProjectService_List_Call {
-	return &ProjectService_List_Call{Call: _e.mock.On("List", ctx)}
+//  - flt project.Filter
+func (_e *ProjectService_Expecter) List(ctx interface{}, flt interface{}) *ProjectService_List_Call {
+	return &ProjectService_List_Call{Call: _e.mock.On("List", ctx, flt)}
 }
 
-func (_c *ProjectService_List_Call) Run(run func(ctx context.Context)) *ProjectService_List_Call {
+func (_c *ProjectService_List_Call) Run(run func(ctx context.Context, flt project.Filter)) *ProjectService_List_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
+		run(args[0].(context.Context), args[1].(project.Filter))
 	})
 	return _c
 }
@@ -348,6 +349,96 @@ func (_c *ProjectService_Update_Call) Return(_a0 project.Project, _a1 error) *Pr
 	return _c
 }
 
+// ValidateCreate provides a mock function with given fields: ctx, prj
+func (_m *ProjectService) ValidateCreate(ctx context.Context, prj project.Project) (project.Project, error) {
+	ret := _m.Called(ctx, prj)
+
+	var r0 project.Project
+	if rf, ok := ret.Get(0).(func(context.Context, project.Project) project.Project); ok {
+		r0 = rf(ctx, prj)
+	} else {
+		r0 = ret.Get(0).(project.Project)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, project.Project) error); ok {
+		r1 = rf(ctx, prj)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProjectService_ValidateCreate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateCreate'
+type ProjectService_ValidateCreate_Call struct {
+	*mock.Call
+}
+
+// ValidateCreate is a helper method to define mock.On call
+//  - ctx context.Context
+//  - prj project.Project
+func (_e *ProjectService_Expecter) ValidateCreate(ctx interface{}, prj interface{}) *ProjectService_ValidateCreate_Call {
+	return &ProjectService_ValidateCreate_Call{Call: _e.mock.On("ValidateCreate", ctx, prj)}
+}
+
+func (_c *ProjectService_ValidateCreate_Call) Run(run func(ctx context.Context, prj project.Project)) *ProjectService_ValidateCreate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(project.Project))
+	})
+	return _c
+}
+
+func (_c *ProjectService_ValidateCreate_Call) Return(_a0 project.Project, _a1 error) *ProjectService_ValidateCreate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+// ValidateUpdate provides a mock function with given fields: ctx, toUpdate
+func (_m *ProjectService) ValidateUpdate(ctx context.Context, toUpdate project.Project) (project.Project, error) {
+	ret := _m.Called(ctx, toUpdate)
+
+	var r0 project.Project
+	if rf, ok := ret.Get(0).(func(context.Context, project.Project) project.Project); ok {
+		r0 = rf(ctx, toUpdate)
+	} else {
+		r0 = ret.Get(0).(project.Project)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, project.Project) error); ok {
+		r1 = rf(ctx, toUpdate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProjectService_ValidateUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateUpdate'
+type ProjectService_ValidateUpdate_Call struct {
+	*mock.Call
+}
+
+// ValidateUpdate is a helper method to define mock.On call
+//  - ctx context.Context
+//  - toUpdate project.Project
+func (_e *ProjectService_Expecter) ValidateUpdate(ctx interface{}, toUpdate interface{}) *ProjectService_ValidateUpdate_Call {
+	return &ProjectService_ValidateUpdate_Call{Call: _e.mock.On("ValidateUpdate", ctx, toUpdate)}
+}
+
+func (_c *ProjectService_ValidateUpdate_Call) Run(run func(ctx context.Context, toUpdate project.Project)) *ProjectService_ValidateUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(project.Project))
+	})
+	return _c
+}
+
+func (_c *ProjectService_ValidateUpdate_Call) Return(_a0 project.Project, _a1 error) *ProjectService_ValidateUpdate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
 type mockConstructorTestingTNewProjectService interface {
 	mock.TestingT
 	Cleanup(func())
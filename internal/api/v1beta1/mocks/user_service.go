@@ -156,6 +156,51 @@ func (_c *UserService_FetchCurrentUser_Call) Return(_a0 user.User, _a1 error) *U
 	return _c
 }
 
+// Get provides a mock function with given fields: ctx, idOrEmail
+func (_m *UserService) Get(ctx context.Context, idOrEmail string) (user.User, error) {
+	ret := _m.Called(ctx, idOrEmail)
+
+	var r0 user.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) user.User); ok {
+		r0 = rf(ctx, idOrEmail)
+	} else {
+		r0 = ret.Get(0).(user.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, idOrEmail)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserService_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type UserService_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//  - ctx context.Context
+//  - idOrEmail string
+func (_e *UserService_Expecter) Get(ctx interface{}, idOrEmail interface{}) *UserService_Get_Call {
+	return &UserService_Get_Call{Call: _e.mock.On("Get", ctx, idOrEmail)}
+}
+
+func (_c *UserService_Get_Call) Run(run func(ctx context.Context, idOrEmail string)) *UserService_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserService_Get_Call) Return(_a0 user.User, _a1 error) *UserService_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
 // GetByEmail provides a mock function with given fields: ctx, email
 func (_m *UserService) GetByEmail(ctx context.Context, email string) (user.User, error) {
 	ret := _m.Called(ctx, email)
@@ -12,6 +12,7 @@ import (
 
 	grpczap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 
+	"github.com/odpf/shield/core/metaschema"
 	"github.com/odpf/shield/core/organization"
 
 	"google.golang.org/grpc/codes"
@@ -23,12 +24,36 @@ import (
 
 var grpcOrgNotFoundErr = status.Errorf(codes.NotFound, "org doesn't exist")
 
+// orgParentMetadataKey carries the parent of an organization in its
+// metadata until the organization messages have a field for it
+const orgParentMetadataKey = "parent_org_id"
+
+// resolveOrgID returns the id of the organization referred to by id or
+// slug, notFound is returned when there is no such organization
+func (h Handler) resolveOrgID(ctx context.Context, idOrSlug string, notFound error) (string, error) {
+	if idOrSlug == "" || uuid.IsValid(idOrSlug) {
+		return idOrSlug, nil
+	}
+
+	org, err := h.orgService.Get(ctx, idOrSlug)
+	if err != nil {
+		grpczap.Extract(ctx).Error(err.Error())
+		if errors.Is(err, organization.ErrNotExist) {
+			return "", notFound
+		}
+		return "", grpcInternalServerError
+	}
+	return org.ID, nil
+}
+
 //go:generate mockery --name=OrganizationService -r --case underscore --with-expecter --structname OrganizationService --filename org_service.go --output=./mocks
 type OrganizationService interface {
 	Get(ctx context.Context, idOrSlug string) (organization.Organization, error)
 	Create(ctx context.Context, org organization.Organization) (organization.Organization, error)
-	List(ctx context.Context) ([]organization.Organization, error)
+	List(ctx context.Context, flt organization.Filter) ([]organization.Organization, error)
 	Update(ctx context.Context, toUpdate organization.Organization) (organization.Organization, error)
+	ValidateCreate(ctx context.Context, org organization.Organization) (organization.Organization, error)
+	ValidateUpdate(ctx context.Context, toUpdate organization.Organization) (organization.Organization, error)
 	AddAdmins(ctx context.Context, idOrSlug string, userIds []string) ([]user.User, error)
 	RemoveAdmin(ctx context.Context, idOrSlug string, userId string) ([]user.User, error)
 	ListAdmins(ctx context.Context, id string) ([]user.User, error)
@@ -38,12 +63,44 @@ func (h Handler) ListOrganizations(ctx context.Context, request *shieldv1beta1.L
 	logger := grpczap.Extract(ctx)
 	var orgs []*shieldv1beta1.Organization
 
-	orgList, err := h.orgService.List(ctx)
+	size, num, err := listPage(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	globs, metadataGlobs, err := listFilters(ctx, "name", "slug")
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	withDeleted, err := includeDeleted(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	orgList, err := h.orgService.List(ctx, organization.Filter{
+		Name:           globs["name"],
+		Slug:           globs["slug"],
+		Metadata:       metadataGlobs,
+		IncludeDeleted: withDeleted,
+		Limit:          size,
+		Page:           num,
+	})
 	if err != nil {
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
 
+	var deleted []string
+	for _, v := range orgList {
+		if v.IsDeleted() {
+			deleted = append(deleted, v.ID)
+		}
+	}
+	if err := setDeletedHeader(ctx, deleted); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
 	for _, v := range orgList {
 		orgPB, err := transformOrgToPB(v)
 		if err != nil {
@@ -73,23 +130,41 @@ func (h Handler) CreateOrganization(ctx context.Context, request *shieldv1beta1.
 		return nil, grpcBadBodyError
 	}
 
+	parentID, _ := metaDataMap[orgParentMetadataKey].(string)
+	delete(metaDataMap, orgParentMetadataKey)
+
+	if err := h.validateMetadata(ctx, metaschema.EntityOrganization, metaDataMap); err != nil {
+		return nil, err
+	}
+
 	org := organization.Organization{
 		Name:     request.GetBody().GetName(),
 		Slug:     request.GetBody().GetSlug(),
 		Metadata: metaDataMap,
+		ParentID: parentID,
 	}
 
 	if strings.TrimSpace(org.Slug) == "" {
 		org.Slug = str.GenerateSlug(org.Name)
 	}
 
-	newOrg, err := h.orgService.Create(ctx, org)
+	validate, err := validateOnly(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var newOrg organization.Organization
+	if validate {
+		newOrg, err = h.orgService.ValidateCreate(ctx, org)
+	} else {
+		newOrg, err = h.orgService.Create(ctx, org)
+	}
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
-		case errors.Is(err, organization.ErrInvalidDetail):
+		case errors.Is(err, organization.ErrInvalidDetail), errors.Is(err, organization.ErrInvalidParent):
 			return nil, grpcBadBodyError
 		case errors.Is(err, organization.ErrConflict):
 			return nil, grpcConflictError
@@ -97,11 +172,21 @@ func (h Handler) CreateOrganization(ctx context.Context, request *shieldv1beta1.
 		return nil, grpcInternalServerError
 	}
 
-	metaData, err := newOrg.Metadata.ToStructPB()
+	metaData, err := orgMetadata(newOrg).ToStructPB()
 	if err != nil {
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
+	if err := setETagHeader(ctx, newOrg.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+	if validate {
+		if err := setValidatedHeader(ctx); err != nil {
+			logger.Error(err.Error())
+			return nil, grpcInternalServerError
+		}
+	}
 
 	return &shieldv1beta1.CreateOrganizationResponse{Organization: &shieldv1beta1.Organization{
 		Id:        newOrg.ID,
@@ -134,6 +219,10 @@ func (h Handler) GetOrganization(ctx context.Context, request *shieldv1beta1.Get
 		logger.Error(err.Error())
 		return nil, status.Errorf(codes.Internal, ErrInternalServer.Error())
 	}
+	if err := setETagHeader(ctx, fetchedOrg.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
 
 	return &shieldv1beta1.GetOrganizationResponse{
 		Organization: &orgPB,
@@ -146,25 +235,46 @@ func (h Handler) UpdateOrganization(ctx context.Context, request *shieldv1beta1.
 	if request.GetBody() == nil {
 		return nil, grpcBadBodyError
 	}
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	metaDataMap, err := metadata.Build(request.GetBody().GetMetadata().AsMap())
 	if err != nil {
 		return nil, grpcBadBodyError
 	}
+	// the parent can only be set when the organization is created
+	delete(metaDataMap, orgParentMetadataKey)
+
+	if err := h.validateMetadata(ctx, metaschema.EntityOrganization, metaDataMap); err != nil {
+		return nil, err
+	}
+
+	validate, err := validateOnly(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	update := h.orgService.Update
+	if validate {
+		update = h.orgService.ValidateUpdate
+	}
 
 	var updatedOrg organization.Organization
 	if uuid.IsValid(request.GetId()) {
-		updatedOrg, err = h.orgService.Update(ctx, organization.Organization{
+		updatedOrg, err = update(ctx, organization.Organization{
 			ID:       request.GetId(),
 			Name:     request.GetBody().GetName(),
 			Slug:     request.GetBody().GetSlug(),
 			Metadata: metaDataMap,
+			Version:  version,
 		})
 	} else {
-		updatedOrg, err = h.orgService.Update(ctx, organization.Organization{
+		updatedOrg, err = update(ctx, organization.Organization{
 			Name:     request.GetBody().GetName(),
 			Slug:     request.GetId(),
 			Metadata: metaDataMap,
+			Version:  version,
 		})
 	}
 	if err != nil {
@@ -174,6 +284,10 @@ func (h Handler) UpdateOrganization(ctx context.Context, request *shieldv1beta1.
 			return nil, grpcOrgNotFoundErr
 		case errors.Is(err, organization.ErrConflict):
 			return nil, grpcConflictError
+		case errors.Is(err, organization.ErrVersionMismatch):
+			return nil, grpcVersionMismatchError
+		case errors.Is(err, organization.ErrInvalidDetail):
+			return nil, grpcBadBodyError
 		default:
 			return nil, grpcInternalServerError
 		}
@@ -184,6 +298,16 @@ func (h Handler) UpdateOrganization(ctx context.Context, request *shieldv1beta1.
 		logger.Error(err.Error())
 		return nil, ErrInternalServer
 	}
+	if err := setETagHeader(ctx, updatedOrg.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+	if validate {
+		if err := setValidatedHeader(ctx); err != nil {
+			logger.Error(err.Error())
+			return nil, grpcInternalServerError
+		}
+	}
 
 	return &shieldv1beta1.UpdateOrganizationResponse{Organization: &orgPB}, nil
 }
@@ -195,7 +319,7 @@ func (h Handler) AddOrganizationAdmin(ctx context.Context, request *shieldv1beta
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, errors.ErrForbidden):
 			return nil, grpcPermissionDenied
@@ -256,7 +380,7 @@ func (h Handler) RemoveOrganizationAdmin(ctx context.Context, request *shieldv1b
 	if _, err := h.orgService.RemoveAdmin(ctx, request.GetId(), request.GetUserId()); err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, errors.ErrForbidden):
 			return nil, grpcPermissionDenied
@@ -274,8 +398,21 @@ func (h Handler) RemoveOrganizationAdmin(ctx context.Context, request *shieldv1b
 	}, nil
 }
 
+// orgMetadata is the metadata of the organization along with its parent
+func orgMetadata(org organization.Organization) metadata.Metadata {
+	if org.ParentID == "" {
+		return org.Metadata
+	}
+	withParent := metadata.Metadata{}
+	for k, v := range org.Metadata {
+		withParent[k] = v
+	}
+	withParent[orgParentMetadataKey] = org.ParentID
+	return withParent
+}
+
 func transformOrgToPB(org organization.Organization) (shieldv1beta1.Organization, error) {
-	metaData, err := org.Metadata.ToStructPB()
+	metaData, err := orgMetadata(org).ToStructPB()
 	if err != nil {
 		return shieldv1beta1.Organization{}, err
 	}
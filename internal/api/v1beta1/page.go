@@ -0,0 +1,45 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// the list messages of the organizations, projects and groups have no page
+// fields, their lists are paged with the PageSizeHeader and the
+// PageNumHeader of the request. The whole list is returned without a page
+// size, as it was before the lists were paged.
+const (
+	PageSizeHeader = "x-shield-page-size"
+	PageNumHeader  = "x-shield-page-num"
+)
+
+// listPage returns the page size and the page number of the headers of the
+// request, zero when they are not set
+func listPage(ctx context.Context) (int32, int32, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	parse := func(header string) (int32, error) {
+		values := md.Get(header)
+		if len(values) == 0 {
+			return 0, nil
+		}
+		n, err := strconv.ParseInt(values[0], 10, 32)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("%s should be a positive number", header)
+		}
+		return int32(n), nil
+	}
+
+	size, err := parse(PageSizeHeader)
+	if err != nil {
+		return 0, 0, err
+	}
+	num, err := parse(PageNumHeader)
+	if err != nil {
+		return 0, 0, err
+	}
+	return size, num, nil
+}
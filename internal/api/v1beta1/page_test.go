@@ -0,0 +1,34 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/internal/api/v1beta1/mocks"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestListPage(t *testing.T) {
+	t.Run("should list the page of the headers", func(t *testing.T) {
+		mockOrgSrv := new(mocks.OrganizationService)
+		mockOrgSrv.EXPECT().List(mock.Anything, organization.Filter{Limit: 2, Page: 3}).Return([]organization.Organization{{ID: "org-5", Name: "org 5"}}, nil)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(PageSizeHeader, "2", PageNumHeader, "3"))
+
+		resp, err := Handler{orgService: mockOrgSrv}.ListOrganizations(ctx, &shieldv1beta1.ListOrganizationsRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, resp.GetOrganizations(), 1)
+	})
+
+	t.Run("should refuse a page size that isn't a positive number", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(PageSizeHeader, "0"))
+
+		_, err := Handler{}.ListProjects(ctx, &shieldv1beta1.ListProjectsRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -4,17 +4,39 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/relation"
 	"github.com/odpf/shield/core/resource"
 	"github.com/odpf/shield/core/user"
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
 
 	grpczap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+// the check messages have no field for the context the conditions of the
+// policies are evaluated against, it is read as comma separated key=value
+// pairs from the CheckContextHeader of the requests
+const CheckContextHeader = "x-shield-check-context"
+
+func checkContext(ctx context.Context) (relation.CheckContext, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var pairs []string
+	for _, value := range md.Get(CheckContextHeader) {
+		pairs = append(pairs, strings.Split(value, ",")...)
+	}
+
+	request, err := relation.ParseCheckContext(pairs)
+	if err != nil {
+		return relation.CheckContext{}, err
+	}
+	return relation.CheckContext{Request: request}, nil
+}
+
 func (h Handler) CheckResourcePermission(ctx context.Context, req *shieldv1beta1.CheckResourcePermissionRequest) (*shieldv1beta1.CheckResourcePermissionResponse, error) {
 	logger := grpczap.Extract(ctx)
 	//if err := req.ValidateAll(); err != nil {
@@ -23,13 +45,24 @@ func (h Handler) CheckResourcePermission(ctx context.Context, req *shieldv1beta1
 	//	return nil, status.Errorf(codes.NotFound, formattedErr.Error())
 	//}
 
+	consistency, err := checkConsistency(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	checkCtx, err := checkContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = relation.WithCheckContext(relation.WithConsistency(ctx, consistency), checkCtx)
 	result, err := h.resourceService.CheckAuthz(ctx, resource.Resource{
 		Name:        req.GetObjectId(),
 		NamespaceID: req.GetObjectNamespace(),
 	}, action.Action{ID: req.GetPermission()})
 	if err != nil {
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		default:
 			formattedErr := fmt.Errorf("%s: %w", ErrInternalServer, err)
@@ -3,9 +3,13 @@ package v1beta1
 import (
 	"context"
 	"errors"
+	"net/url"
+	"strings"
 
 	grpczap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -17,31 +21,135 @@ import (
 //go:generate mockery --name=PolicyService -r --case underscore --with-expecter --structname PolicyService --filename policy_service.go --output=./mocks
 type PolicyService interface {
 	Get(ctx context.Context, id string) (policy.Policy, error)
-	List(ctx context.Context) ([]policy.Policy, error)
+	List(ctx context.Context, flt policy.Filters, expand bool) ([]policy.ExpandedPolicy, error)
 	Create(ctx context.Context, pol policy.Policy) ([]policy.Policy, error)
 	Update(ctx context.Context, pol policy.Policy) ([]policy.Policy, error)
+	ValidateCreate(ctx context.Context, pol policy.Policy) (policy.Policy, error)
+	ValidateUpdate(ctx context.Context, pol policy.Policy) (policy.Policy, error)
 }
 
+// the policy messages have no field for the effect of a policy, it is read
+// from the PolicyEffectHeader of the requests creating or updating policies
+// and the ids of the deny policies are returned in the DenyPoliciesHeader
+// of the responses. The condition of a policy is read from and returned in
+// the PolicyConditionHeader the same way, an update without the header
+// keeps the condition and one with an empty header removes it. The name and
+// the description of a policy are read from the PolicyNameHeader and the
+// PolicyDescriptionHeader percent-encoded, an update without them keeps the
+// ones of the policy, and returned in the PolicyLabelsHeader as a url encoded
+// id, name and description per policy labelled.
+const (
+	PolicyEffectHeader      = "x-shield-policy-effect"
+	DenyPoliciesHeader      = "x-shield-deny-policies"
+	PolicyConditionHeader   = "x-shield-policy-condition"
+	PolicyNameHeader        = "x-shield-policy-name"
+	PolicyDescriptionHeader = "x-shield-policy-description"
+	PolicyLabelsHeader      = "x-shield-policy-labels"
+)
+
 var grpcPolicyNotFoundErr = status.Errorf(codes.NotFound, "policy doesn't exist")
 
+func policyEffect(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(PolicyEffectHeader); len(values) > 0 {
+		return strings.ToLower(values[0])
+	}
+	return ""
+}
+
+// policyCondition returns false if the request has no PolicyConditionHeader
+func policyCondition(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(PolicyConditionHeader); len(values) > 0 {
+		return values[0], true
+	}
+	return "", false
+}
+
+// policyLabels returns the name and the description of the headers of the
+// request, empty when they are not set
+func policyLabels(ctx context.Context) (string, string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var labels [2]string
+	for i, header := range []string{PolicyNameHeader, PolicyDescriptionHeader} {
+		values := md.Get(header)
+		if len(values) == 0 {
+			continue
+		}
+		label, err := url.PathUnescape(values[0])
+		if err != nil {
+			return "", "", status.Errorf(codes.InvalidArgument, "%s should be percent-encoded", header)
+		}
+		labels[i] = label
+	}
+	return labels[0], labels[1], nil
+}
+
+func setPolicyLabelsHeader(ctx context.Context, policies []policy.Policy) error {
+	var labels []string
+	for _, p := range policies {
+		if p.Name == "" && p.Description == "" {
+			continue
+		}
+		labels = append(labels, url.Values{"id": {p.ID}, "name": {p.Name}, "description": {p.Description}}.Encode())
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	return grpc.SetHeader(ctx, metadata.Pairs(PolicyLabelsHeader, strings.Join(labels, ",")))
+}
+
+func setPolicyConditionHeader(ctx context.Context, pol policy.Policy) error {
+	if pol.Condition == "" {
+		return nil
+	}
+	return grpc.SetHeader(ctx, metadata.Pairs(PolicyConditionHeader, pol.Condition))
+}
+
+func setDenyPoliciesHeader(ctx context.Context, policies []policy.Policy) error {
+	var ids []string
+	for _, p := range policies {
+		if p.Effect == policy.EffectDeny {
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return grpc.SetHeader(ctx, metadata.Pairs(DenyPoliciesHeader, strings.Join(ids, ",")))
+}
+
 func (h Handler) ListPolicies(ctx context.Context, request *shieldv1beta1.ListPoliciesRequest) (*shieldv1beta1.ListPoliciesResponse, error) {
 	logger := grpczap.Extract(ctx)
 	var policies []*shieldv1beta1.Policy
 
-	policyList, err := h.policyService.List(ctx)
+	// the request has no way to ask for lean policies, names are
+	// always expanded so clients don't have to look them up
+	policyList, err := h.policyService.List(ctx, policy.Filters{}, true)
 	if err != nil {
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
 
+	var listed []policy.Policy
 	for _, p := range policyList {
-		policyPB, err := transformPolicyToPB(p)
+		policyPB, err := transformExpandedPolicyToPB(p)
 		if err != nil {
 			logger.Error(err.Error())
 			return nil, grpcInternalServerError
 		}
 
 		policies = append(policies, &policyPB)
+		listed = append(listed, p.Policy)
+	}
+
+	if err := setDenyPoliciesHeader(ctx, listed); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+	if err := setPolicyLabelsHeader(ctx, listed); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
 	}
 
 	return &shieldv1beta1.ListPoliciesResponse{Policies: policies}, nil
@@ -51,16 +159,42 @@ func (h Handler) CreatePolicy(ctx context.Context, request *shieldv1beta1.Create
 	logger := grpczap.Extract(ctx)
 	var policies []*shieldv1beta1.Policy
 
-	newPolicies, err := h.policyService.Create(ctx, policy.Policy{
+	name, description, err := policyLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	validate, err := validateOnly(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	condition, _ := policyCondition(ctx)
+	pol := policy.Policy{
 		RoleID:      request.GetBody().GetRoleId(),
 		NamespaceID: request.GetBody().GetNamespaceId(),
 		ActionID:    request.GetBody().GetActionId(),
-	})
+		Effect:      policyEffect(ctx),
+		Condition:   condition,
+		Name:        name,
+		Description: description,
+	}
+	var newPolicies []policy.Policy
+	if validate {
+		// only the policy validated is returned
+		var validated policy.Policy
+		validated, err = h.policyService.ValidateCreate(ctx, pol)
+		newPolicies = []policy.Policy{validated}
+	} else {
+		newPolicies, err = h.policyService.Create(ctx, pol)
+	}
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
 		case errors.Is(err, policy.ErrInvalidDetail):
 			return nil, grpcBadBodyError
+		case errors.Is(err, policy.ErrConflict):
+			return nil, grpcConflictError
 		default:
 			return nil, grpcInternalServerError
 		}
@@ -76,10 +210,20 @@ func (h Handler) CreatePolicy(ctx context.Context, request *shieldv1beta1.Create
 		policies = append(policies, &policyPB)
 	}
 
-	if err != nil {
+	if err := setDenyPoliciesHeader(ctx, newPolicies); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+	if err := setPolicyLabelsHeader(ctx, newPolicies); err != nil {
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
+	if validate {
+		if err := setValidatedHeader(ctx); err != nil {
+			logger.Error(err.Error())
+			return nil, grpcInternalServerError
+		}
+	}
 
 	return &shieldv1beta1.CreatePolicyResponse{Policies: policies}, nil
 }
@@ -106,6 +250,26 @@ func (h Handler) GetPolicy(ctx context.Context, request *shieldv1beta1.GetPolicy
 		return nil, grpcInternalServerError
 	}
 
+	if err := setDenyPoliciesHeader(ctx, []policy.Policy{fetchedPolicy}); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
+	if err := setPolicyConditionHeader(ctx, fetchedPolicy); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
+	if err := setPolicyLabelsHeader(ctx, []policy.Policy{fetchedPolicy}); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
+	if err := setETagHeader(ctx, fetchedPolicy.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
 	return &shieldv1beta1.GetPolicyResponse{Policy: &policyPB}, nil
 }
 
@@ -113,12 +277,49 @@ func (h Handler) UpdatePolicy(ctx context.Context, request *shieldv1beta1.Update
 	logger := grpczap.Extract(ctx)
 	var policies []*shieldv1beta1.Policy
 
-	updatedPolices, err := h.policyService.Update(ctx, policy.Policy{
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, description, err := policyLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, ok := policyCondition(ctx)
+	if !ok {
+		existing, err := h.policyService.Get(ctx, request.GetId())
+		if err == nil {
+			condition = existing.Condition
+		}
+	}
+
+	validate, err := validateOnly(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	pol := policy.Policy{
 		ID:          request.GetId(),
 		RoleID:      request.GetBody().GetRoleId(),
 		NamespaceID: request.GetBody().GetNamespaceId(),
 		ActionID:    request.GetBody().GetActionId(),
-	})
+		Effect:      policyEffect(ctx),
+		Condition:   condition,
+		Name:        name,
+		Description: description,
+		Version:     version,
+	}
+	var updatedPolices []policy.Policy
+	if validate {
+		// only the policy validated is returned
+		var validated policy.Policy
+		validated, err = h.policyService.ValidateUpdate(ctx, pol)
+		updatedPolices = []policy.Policy{validated}
+	} else {
+		updatedPolices, err = h.policyService.Update(ctx, pol)
+	}
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
@@ -131,6 +332,8 @@ func (h Handler) UpdatePolicy(ctx context.Context, request *shieldv1beta1.Update
 			return nil, grpcBadBodyError
 		case errors.Is(err, policy.ErrConflict):
 			return nil, grpcConflictError
+		case errors.Is(err, policy.ErrVersionMismatch):
+			return nil, grpcVersionMismatchError
 		default:
 			return nil, grpcInternalServerError
 		}
@@ -142,13 +345,29 @@ func (h Handler) UpdatePolicy(ctx context.Context, request *shieldv1beta1.Update
 			logger.Error(err.Error())
 			return nil, grpcInternalServerError
 		}
+		if p.ID == request.GetId() {
+			if err := setETagHeader(ctx, p.Version); err != nil {
+				logger.Error(err.Error())
+				return nil, grpcInternalServerError
+			}
+		}
 		policies = append(policies, &policyPB)
 	}
 
-	if err != nil {
+	if err := setDenyPoliciesHeader(ctx, updatedPolices); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+	if err := setPolicyLabelsHeader(ctx, updatedPolices); err != nil {
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
+	if validate {
+		if err := setValidatedHeader(ctx); err != nil {
+			logger.Error(err.Error())
+			return nil, grpcInternalServerError
+		}
+	}
 	return &shieldv1beta1.UpdatePolicyResponse{Policies: policies}, nil
 }
 
@@ -159,3 +378,17 @@ func transformPolicyToPB(policy policy.Policy) (shieldv1beta1.Policy, error) {
 		UpdatedAt: timestamppb.New(policy.UpdatedAt),
 	}, nil
 }
+
+func transformExpandedPolicyToPB(pol policy.ExpandedPolicy) (shieldv1beta1.Policy, error) {
+	return shieldv1beta1.Policy{
+		Id:          pol.ID,
+		RoleId:      pol.RoleID,
+		ActionId:    pol.ActionID,
+		NamespaceId: pol.NamespaceID,
+		Role:        &shieldv1beta1.Role{Id: pol.RoleID, Name: pol.RoleName},
+		Action:      &shieldv1beta1.Action{Id: pol.ActionID, Name: pol.ActionName},
+		Namespace:   &shieldv1beta1.Namespace{Id: pol.NamespaceID, Name: pol.NamespaceName},
+		CreatedAt:   timestamppb.New(pol.CreatedAt),
+		UpdatedAt:   timestamppb.New(pol.UpdatedAt),
+	}, nil
+}
@@ -3,6 +3,7 @@ package v1beta1
 import (
 	"context"
 	"errors"
+	"net/url"
 	"testing"
 	"time"
 
@@ -12,7 +13,9 @@ import (
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -40,7 +43,7 @@ func TestListPolicies(t *testing.T) {
 		{
 			title: "should return internal error if policy service return some error",
 			setup: func(ps *mocks.PolicyService) {
-				ps.EXPECT().List(mock.Anything).Return([]policy.Policy{}, errors.New("some error"))
+				ps.EXPECT().List(mock.Anything, policy.Filters{}, true).Return([]policy.ExpandedPolicy{}, errors.New("some error"))
 			},
 			want: nil,
 			err:  status.Errorf(codes.Internal, ErrInternalServer.Error()),
@@ -48,15 +51,26 @@ func TestListPolicies(t *testing.T) {
 		{
 			title: "should return success if policy service return nil error",
 			setup: func(ps *mocks.PolicyService) {
-				var testPoliciesList []policy.Policy
+				var testPoliciesList []policy.ExpandedPolicy
 				for _, p := range testPolicyMap {
-					testPoliciesList = append(testPoliciesList, p)
+					testPoliciesList = append(testPoliciesList, policy.ExpandedPolicy{
+						Policy:        p,
+						RoleName:      "Reader",
+						ActionName:    "Read",
+						NamespaceName: "Policy 1",
+					})
 				}
-				ps.EXPECT().List(mock.Anything).Return(testPoliciesList, nil)
+				ps.EXPECT().List(mock.Anything, policy.Filters{}, true).Return(testPoliciesList, nil)
 			},
 			want: &shieldv1beta1.ListPoliciesResponse{Policies: []*shieldv1beta1.Policy{
 				{
-					Id: testPolicyID,
+					Id:          testPolicyID,
+					RoleId:      "reader",
+					ActionId:    "read",
+					NamespaceId: "policy-1",
+					Role:        &shieldv1beta1.Role{Id: "reader", Name: "Reader"},
+					Action:      &shieldv1beta1.Action{Id: "read", Name: "Read"},
+					Namespace:   &shieldv1beta1.Namespace{Id: "policy-1", Name: "Policy 1"},
 					// @TODO(krtkvrm): issues/171
 					//Action: &shieldv1beta1.Action{
 					//	Id:   "read",
@@ -558,6 +572,9 @@ func TestHandler_UpdatePolicy(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockPolicySrv := new(mocks.PolicyService)
+			// an update without the condition header keeps the condition
+			// of the existing policy
+			mockPolicySrv.EXPECT().Get(mock.AnythingOfType("*context.emptyCtx"), mock.Anything).Return(policy.Policy{}, nil)
 			if tt.setup != nil {
 				tt.setup(mockPolicySrv)
 			}
@@ -568,3 +585,130 @@ func TestHandler_UpdatePolicy(t *testing.T) {
 		})
 	}
 }
+
+// headerStream keeps the headers set by a handler
+type headerStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestPolicyEffect(t *testing.T) {
+	denyPolicy := policy.Policy{ID: "deny-1", RoleID: "reader", NamespaceID: "policy-1", ActionID: "read", Effect: policy.EffectDeny}
+	allowPolicy := policy.Policy{ID: "allow-1", RoleID: "writer", NamespaceID: "policy-1", ActionID: "write", Effect: policy.EffectAllow}
+
+	t.Run("should return the ids of the deny policies in the header when listing", func(t *testing.T) {
+		mockPolicySrv := new(mocks.PolicyService)
+		mockPolicySrv.EXPECT().List(mock.Anything, policy.Filters{}, true).Return([]policy.ExpandedPolicy{
+			{Policy: denyPolicy}, {Policy: allowPolicy},
+		}, nil)
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+
+		_, err := Handler{policyService: mockPolicySrv}.ListPolicies(ctx, &shieldv1beta1.ListPoliciesRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"deny-1"}, stream.header.Get(DenyPoliciesHeader))
+	})
+
+	t.Run("should create the policy with the effect of the header", func(t *testing.T) {
+		mockPolicySrv := new(mocks.PolicyService)
+		mockPolicySrv.EXPECT().Create(mock.Anything, policy.Policy{
+			RoleID:      "reader",
+			NamespaceID: "policy-1",
+			ActionID:    "read",
+			Effect:      policy.EffectDeny,
+		}).Return([]policy.Policy{denyPolicy}, nil)
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(PolicyEffectHeader, "DENY"))
+
+		_, err := Handler{policyService: mockPolicySrv}.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{Body: &shieldv1beta1.PolicyRequestBody{
+			RoleId:      "reader",
+			NamespaceId: "policy-1",
+			ActionId:    "read",
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"deny-1"}, stream.header.Get(DenyPoliciesHeader))
+	})
+
+	t.Run("should return conflict if the policy exists with the opposite effect", func(t *testing.T) {
+		mockPolicySrv := new(mocks.PolicyService)
+		mockPolicySrv.EXPECT().Create(mock.Anything, mock.Anything).Return([]policy.Policy{}, policy.ErrConflict)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(PolicyEffectHeader, policy.EffectDeny))
+
+		_, err := Handler{policyService: mockPolicySrv}.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{Body: &shieldv1beta1.PolicyRequestBody{
+			RoleId:      "reader",
+			NamespaceId: "policy-1",
+			ActionId:    "read",
+		}})
+		assert.Equal(t, grpcConflictError, err)
+	})
+}
+
+func TestPolicyLabels(t *testing.T) {
+	labelledPolicy := policy.Policy{
+		ID:          "labelled-1",
+		RoleID:      "reader",
+		NamespaceID: "policy-1",
+		ActionID:    "read",
+		Name:        "payments deploy",
+		Description: "lets the readers deploy, 100% of the time",
+	}
+
+	t.Run("should create the policy with the name and the description of the headers", func(t *testing.T) {
+		mockPolicySrv := new(mocks.PolicyService)
+		mockPolicySrv.EXPECT().Create(mock.Anything, policy.Policy{
+			RoleID:      "reader",
+			NamespaceID: "policy-1",
+			ActionID:    "read",
+			Name:        labelledPolicy.Name,
+			Description: labelledPolicy.Description,
+		}).Return([]policy.Policy{labelledPolicy}, nil)
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(
+			PolicyNameHeader, url.PathEscape(labelledPolicy.Name),
+			PolicyDescriptionHeader, url.PathEscape(labelledPolicy.Description),
+		))
+
+		_, err := Handler{policyService: mockPolicySrv}.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{Body: &shieldv1beta1.PolicyRequestBody{
+			RoleId:      "reader",
+			NamespaceId: "policy-1",
+			ActionId:    "read",
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{url.Values{
+			"id":          {labelledPolicy.ID},
+			"name":        {labelledPolicy.Name},
+			"description": {labelledPolicy.Description},
+		}.Encode()}, stream.header.Get(PolicyLabelsHeader))
+	})
+
+	t.Run("should return invalid argument if the name isn't percent-encoded", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(PolicyNameHeader, "100%"))
+
+		_, err := Handler{policyService: new(mocks.PolicyService)}.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{Body: &shieldv1beta1.PolicyRequestBody{
+			RoleId:      "reader",
+			NamespaceId: "policy-1",
+			ActionId:    "read",
+		}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("should not set the labels header for the policies without labels", func(t *testing.T) {
+		mockPolicySrv := new(mocks.PolicyService)
+		mockPolicySrv.EXPECT().List(mock.Anything, policy.Filters{}, true).Return([]policy.ExpandedPolicy{
+			{Policy: policy.Policy{ID: "plain-1", RoleID: "reader", NamespaceID: "policy-1", ActionID: "read"}},
+		}, nil)
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+
+		_, err := Handler{policyService: mockPolicySrv}.ListPolicies(ctx, &shieldv1beta1.ListPoliciesRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, stream.header.Get(PolicyLabelsHeader))
+	})
+}
@@ -12,6 +12,7 @@ import (
 
 	grpczap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 
+	"github.com/odpf/shield/core/metaschema"
 	"github.com/odpf/shield/core/organization"
 	"github.com/odpf/shield/core/project"
 
@@ -28,8 +29,10 @@ var grpcProjectNotFoundErr = status.Errorf(codes.NotFound, "project doesn't exis
 type ProjectService interface {
 	Get(ctx context.Context, idOrSlugd string) (project.Project, error)
 	Create(ctx context.Context, prj project.Project) (project.Project, error)
-	List(ctx context.Context) ([]project.Project, error)
+	List(ctx context.Context, flt project.Filter) ([]project.Project, error)
 	Update(ctx context.Context, toUpdate project.Project) (project.Project, error)
+	ValidateCreate(ctx context.Context, prj project.Project) (project.Project, error)
+	ValidateUpdate(ctx context.Context, toUpdate project.Project) (project.Project, error)
 	AddAdmins(ctx context.Context, idOrSlug string, userIds []string) ([]user.User, error)
 	RemoveAdmin(ctx context.Context, idOrSlug string, userId string) ([]user.User, error)
 	ListAdmins(ctx context.Context, id string) ([]user.User, error)
@@ -42,12 +45,44 @@ func (h Handler) ListProjects(
 	logger := grpczap.Extract(ctx)
 	var projects []*shieldv1beta1.Project
 
-	projectList, err := h.projectService.List(ctx)
+	size, num, err := listPage(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	globs, metadataGlobs, err := listFilters(ctx, "name", "slug")
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	withDeleted, err := includeDeleted(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	projectList, err := h.projectService.List(ctx, project.Filter{
+		Name:           globs["name"],
+		Slug:           globs["slug"],
+		Metadata:       metadataGlobs,
+		IncludeDeleted: withDeleted,
+		Limit:          size,
+		Page:           num,
+	})
 	if err != nil {
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
 
+	var deleted []string
+	for _, v := range projectList {
+		if v.IsDeleted() {
+			deleted = append(deleted, v.ID)
+		}
+	}
+	if err := setDeletedHeader(ctx, deleted); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
 	for _, v := range projectList {
 		projectPB, err := transformProjectToPB(v)
 		if err != nil {
@@ -73,22 +108,41 @@ func (h Handler) CreateProject(
 		return nil, grpcBadBodyError
 	}
 
+	if err := h.validateMetadata(ctx, metaschema.EntityProject, metaDataMap); err != nil {
+		return nil, err
+	}
+
+	orgID, err := h.resolveOrgID(ctx, request.GetBody().GetOrgId(), grpcBadBodyError)
+	if err != nil {
+		return nil, err
+	}
+
 	prj := project.Project{
 		Name:         request.GetBody().GetName(),
 		Slug:         request.GetBody().GetSlug(),
 		Metadata:     metaDataMap,
-		Organization: organization.Organization{ID: request.GetBody().GetOrgId()},
+		Organization: organization.Organization{ID: orgID},
 	}
 
 	if strings.TrimSpace(prj.Slug) == "" {
 		prj.Slug = str.GenerateSlug(prj.Name)
 	}
 
-	newProject, err := h.projectService.Create(ctx, prj)
+	validate, err := validateOnly(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var newProject project.Project
+	if validate {
+		newProject, err = h.projectService.ValidateCreate(ctx, prj)
+	} else {
+		newProject, err = h.projectService.Create(ctx, prj)
+	}
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, organization.ErrInvalidUUID), errors.Is(err, project.ErrInvalidDetail):
 			return nil, grpcBadBodyError
@@ -104,6 +158,16 @@ func (h Handler) CreateProject(
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
+	if err := setETagHeader(ctx, newProject.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+	if validate {
+		if err := setValidatedHeader(ctx); err != nil {
+			logger.Error(err.Error())
+			return nil, grpcInternalServerError
+		}
+	}
 
 	return &shieldv1beta1.CreateProjectResponse{Project: &shieldv1beta1.Project{
 		Id:        newProject.ID,
@@ -137,6 +201,10 @@ func (h Handler) GetProject(
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
+	if err := setETagHeader(ctx, fetchedProject.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
 
 	return &shieldv1beta1.GetProjectResponse{Project: &projectPB}, nil
 }
@@ -147,26 +215,51 @@ func (h Handler) UpdateProject(
 ) (*shieldv1beta1.UpdateProjectResponse, error) {
 	logger := grpczap.Extract(ctx)
 
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	metaDataMap, err := metadata.Build(request.GetBody().GetMetadata().AsMap())
 	if err != nil {
 		return nil, grpcBadBodyError
 	}
 
+	if err := h.validateMetadata(ctx, metaschema.EntityProject, metaDataMap); err != nil {
+		return nil, err
+	}
+
+	orgID, err := h.resolveOrgID(ctx, request.GetBody().GetOrgId(), grpcBadBodyError)
+	if err != nil {
+		return nil, err
+	}
+
+	validate, err := validateOnly(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	update := h.projectService.Update
+	if validate {
+		update = h.projectService.ValidateUpdate
+	}
+
 	var updatedProject project.Project
 	if uuid.IsValid(request.GetId()) {
-		updatedProject, err = h.projectService.Update(ctx, project.Project{
+		updatedProject, err = update(ctx, project.Project{
 			ID:           request.GetId(),
 			Name:         request.GetBody().GetName(),
 			Slug:         request.GetBody().GetSlug(),
-			Organization: organization.Organization{ID: request.GetBody().GetOrgId()},
+			Organization: organization.Organization{ID: orgID},
 			Metadata:     metaDataMap,
+			Version:      version,
 		})
 	} else {
-		updatedProject, err = h.projectService.Update(ctx, project.Project{
+		updatedProject, err = update(ctx, project.Project{
 			Name:         request.GetBody().GetName(),
 			Slug:         request.GetId(),
-			Organization: organization.Organization{ID: request.GetBody().GetOrgId()},
+			Organization: organization.Organization{ID: orgID},
 			Metadata:     metaDataMap,
+			Version:      version,
 		})
 	}
 	if err != nil {
@@ -179,6 +272,8 @@ func (h Handler) UpdateProject(
 			return nil, grpcProjectNotFoundErr
 		case errors.Is(err, project.ErrConflict):
 			return nil, grpcConflictError
+		case errors.Is(err, project.ErrVersionMismatch):
+			return nil, grpcVersionMismatchError
 		case errors.Is(err, project.ErrInvalidDetail):
 			return nil, grpcBadBodyError
 		default:
@@ -191,6 +286,16 @@ func (h Handler) UpdateProject(
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
+	if err := setETagHeader(ctx, updatedProject.Version); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+	if validate {
+		if err := setValidatedHeader(ctx); err != nil {
+			logger.Error(err.Error())
+			return nil, grpcInternalServerError
+		}
+	}
 
 	return &shieldv1beta1.UpdateProjectResponse{Project: &projectPB}, nil
 }
@@ -206,13 +311,13 @@ func (h Handler) AddProjectAdmin(
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, errors.ErrForbidden):
 			return nil, grpcPermissionDenied
 		case errors.Is(err, project.ErrNotExist):
 			return nil, grpcProjectNotFoundErr
-		case errors.Is(err, user.ErrInvalidID), errors.Is(err, user.ErrInvalidUUID):
+		case errors.Is(err, user.ErrInvalidID), errors.Is(err, user.ErrInvalidUUID), errors.Is(err, user.ErrNotExist):
 			return nil, grpcBadBodyError
 		default:
 			return nil, grpcInternalServerError
@@ -273,7 +378,7 @@ func (h Handler) RemoveProjectAdmin(
 	if _, err := h.projectService.RemoveAdmin(ctx, request.GetId(), request.GetUserId()); err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, errors.ErrForbidden):
 			return nil, grpcPermissionDenied
@@ -68,7 +68,7 @@ func (h Handler) CreateRelation(ctx context.Context, request *shieldv1beta1.Crea
 	}, action.Action{ID: schema.EditPermission})
 	if err != nil {
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		default:
 			formattedErr := fmt.Errorf("%s: %w", ErrInternalServer, err)
@@ -81,6 +81,7 @@ func (h Handler) CreateRelation(ctx context.Context, request *shieldv1beta1.Crea
 		return nil, status.Errorf(codes.PermissionDenied, "user does not have permission to perform this action")
 	}
 
+	ctx, rev := relation.WithRevision(ctx)
 	newRelation, err := h.relationService.Create(ctx, relation.RelationV2{
 		Object: relation.Object{
 			ID:          request.GetBody().GetObjectId(),
@@ -96,7 +97,8 @@ func (h Handler) CreateRelation(ctx context.Context, request *shieldv1beta1.Crea
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, relation.ErrInvalidDetail):
+		case errors.Is(err, relation.ErrInvalidDetail),
+			errors.Is(err, relation.ErrRoleOutsideOrg):
 			return nil, grpcBadBodyError
 		default:
 			return nil, grpcInternalServerError
@@ -109,6 +111,11 @@ func (h Handler) CreateRelation(ctx context.Context, request *shieldv1beta1.Crea
 		return nil, grpcInternalServerError
 	}
 
+	if err := setZedTokenHeader(ctx, rev); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
 	return &shieldv1beta1.CreateRelationResponse{
 		Relation: &relationPB,
 	}, nil
@@ -171,7 +178,7 @@ func (h Handler) DeleteRelation(ctx context.Context, request *shieldv1beta1.Dele
 	}, action.Action{ID: schema.EditPermission})
 	if err != nil {
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		default:
 			formattedErr := fmt.Errorf("%s: %w", ErrInternalServer, err)
@@ -184,6 +191,7 @@ func (h Handler) DeleteRelation(ctx context.Context, request *shieldv1beta1.Dele
 		return nil, status.Errorf(codes.PermissionDenied, "user does not have permission to perform this action")
 	}
 
+	ctx, rev := relation.WithRevision(ctx)
 	err = h.relationService.DeleteV2(ctx, relation.RelationV2{
 		Object: relation.Object{
 			ID: request.GetObjectId(),
@@ -205,6 +213,11 @@ func (h Handler) DeleteRelation(ctx context.Context, request *shieldv1beta1.Dele
 		}
 	}
 
+	if err := setZedTokenHeader(ctx, rev); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
 	return &shieldv1beta1.DeleteRelationResponse{
 		Message: "Relation deleted",
 	}, nil
@@ -12,7 +12,9 @@ import (
 	"github.com/odpf/shield/core/resource"
 	"github.com/odpf/shield/core/user"
 	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -26,17 +28,47 @@ type ResourceService interface {
 	CheckAuthz(ctx context.Context, resource resource.Resource, action action.Action) (bool, error)
 }
 
+// the resource messages have no field for tags, they are read as comma
+// separated key=value pairs from the ResourceTagsHeader of the requests
+// creating, updating or listing resources and returned in the same header
+// of the responses with a single resource
+const ResourceTagsHeader = "x-shield-resource-tags"
+
 var grpcResourceNotFoundErr = status.Errorf(codes.NotFound, "resource doesn't exist")
 
+// resourceTags returns nil if the request has no ResourceTagsHeader
+func resourceTags(ctx context.Context) (map[string]string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(ResourceTagsHeader)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return resource.ParseTags(values[0])
+}
+
+func setResourceTagsHeader(ctx context.Context, res resource.Resource) error {
+	if len(res.Tags) == 0 {
+		return nil
+	}
+	return grpc.SetHeader(ctx, metadata.Pairs(ResourceTagsHeader, resource.FormatTags(res.Tags)))
+}
+
 func (h Handler) ListResources(ctx context.Context, request *shieldv1beta1.ListResourcesRequest) (*shieldv1beta1.ListResourcesResponse, error) {
 	logger := grpczap.Extract(ctx)
 	var resources []*shieldv1beta1.Resource
 
+	tags, err := resourceTags(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, grpcBadBodyError
+	}
+
 	filters := resource.Filter{
 		NamespaceID:    request.GetNamespaceId(),
 		OrganizationID: request.GetOrganizationId(),
 		ProjectID:      request.GetProjectId(),
 		GroupID:        request.GetGroupId(),
+		Tags:           tags,
 	}
 
 	resourcesList, err := h.resourceService.List(ctx, filters)
@@ -65,13 +97,19 @@ func (h Handler) CreateResource(ctx context.Context, request *shieldv1beta1.Crea
 		return nil, grpcBadBodyError
 	}
 
+	tags, err := resourceTags(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, grpcBadBodyError
+	}
+
 	projId := request.GetBody().GetProjectId()
 	project, err := h.projectService.Get(ctx, projId)
 	if err != nil {
 		logger.Error(err.Error())
 
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		default:
 			return nil, grpcInternalServerError
@@ -83,11 +121,12 @@ func (h Handler) CreateResource(ctx context.Context, request *shieldv1beta1.Crea
 		ProjectID:      request.GetBody().GetProjectId(),
 		NamespaceID:    request.GetBody().GetNamespaceId(),
 		Name:           request.GetBody().GetName(),
+		Tags:           tags,
 	})
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		case errors.Is(err, resource.ErrInvalidUUID),
 			errors.Is(err, resource.ErrInvalidDetail):
@@ -129,6 +168,11 @@ func (h Handler) CreateResource(ctx context.Context, request *shieldv1beta1.Crea
 		return nil, grpcInternalServerError
 	}
 
+	if err := setResourceTagsHeader(ctx, newResource); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
 	return &shieldv1beta1.CreateResourceResponse{
 		Resource: &resourcePB,
 	}, nil
@@ -156,6 +200,11 @@ func (h Handler) GetResource(ctx context.Context, request *shieldv1beta1.GetReso
 		return nil, grpcInternalServerError
 	}
 
+	if err := setResourceTagsHeader(ctx, fetchedResource); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
 	return &shieldv1beta1.GetResourceResponse{
 		Resource: &resourcePB,
 	}, nil
@@ -168,13 +217,19 @@ func (h Handler) UpdateResource(ctx context.Context, request *shieldv1beta1.Upda
 		return nil, grpcBadBodyError
 	}
 
+	tags, err := resourceTags(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, grpcBadBodyError
+	}
+
 	projId := request.GetBody().GetProjectId()
 	project, err := h.projectService.Get(ctx, projId)
 	if err != nil {
 		logger.Error(err.Error())
 
 		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
+		case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrDisabled):
 			return nil, grpcUnauthenticated
 		default:
 			return nil, grpcInternalServerError
@@ -186,6 +241,7 @@ func (h Handler) UpdateResource(ctx context.Context, request *shieldv1beta1.Upda
 		ProjectID:      request.GetBody().GetProjectId(),
 		NamespaceID:    request.GetBody().GetNamespaceId(),
 		Name:           request.GetBody().GetName(),
+		Tags:           tags,
 	})
 	if err != nil {
 		logger.Error(err.Error())
@@ -210,6 +266,11 @@ func (h Handler) UpdateResource(ctx context.Context, request *shieldv1beta1.Upda
 		return nil, grpcInternalServerError
 	}
 
+	if err := setResourceTagsHeader(ctx, updatedResource); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
 	return &shieldv1beta1.UpdateResourceResponse{
 		Resource: &resourcePB,
 	}, nil
@@ -2,10 +2,11 @@ package v1beta1
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
 	"github.com/odpf/shield/core/namespace"
 	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/pkg/errors"
 	"github.com/odpf/shield/pkg/metadata"
 
 	"google.golang.org/grpc/codes"
@@ -18,6 +19,14 @@ import (
 
 var grpcRoleNotFoundErr = status.Errorf(codes.NotFound, "role doesn't exist")
 
+// roleOrgMetadataKey and roleIncludesMetadataKey carry the organization of a
+// custom role and the roles a role includes in its metadata until the role
+// messages have fields for them
+const (
+	roleOrgMetadataKey      = "org_id"
+	roleIncludesMetadataKey = "includes"
+)
+
 //go:generate mockery --name=RoleService -r --case underscore --with-expecter --structname RoleService --filename role_service.go --output=./mocks
 type RoleService interface {
 	Get(ctx context.Context, id string) (role.Role, error)
@@ -57,11 +66,22 @@ func (h Handler) CreateRole(ctx context.Context, request *shieldv1beta1.CreateRo
 		return nil, grpcBadBodyError
 	}
 
+	orgID, _ := metaDataMap[roleOrgMetadataKey].(string)
+	delete(metaDataMap, roleOrgMetadataKey)
+
+	includes, err := roleIncludes(metaDataMap)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, grpcBadBodyError
+	}
+
 	newRole, err := h.roleService.Create(ctx, role.Role{
 		ID:          request.GetBody().GetId(),
 		Name:        request.GetBody().GetName(),
 		Types:       request.GetBody().GetTypes(),
 		NamespaceID: request.GetBody().GetNamespaceId(),
+		OrgID:       orgID,
+		Includes:    includes,
 		Metadata:    metaDataMap,
 	})
 	if err != nil {
@@ -69,8 +89,12 @@ func (h Handler) CreateRole(ctx context.Context, request *shieldv1beta1.CreateRo
 		switch {
 		case errors.Is(err, namespace.ErrNotExist),
 			errors.Is(err, role.ErrInvalidID),
-			errors.Is(err, role.ErrInvalidDetail):
+			errors.Is(err, role.ErrInvalidDetail),
+			errors.Is(err, role.ErrNamespaceNotPermitted),
+			errors.Is(err, role.ErrCyclicInclude):
 			return nil, grpcBadBodyError
+		case errors.Is(err, errors.ErrForbidden):
+			return nil, grpcPermissionDenied
 		case errors.Is(err, role.ErrConflict):
 			return nil, grpcConflictError
 		default:
@@ -117,19 +141,31 @@ func (h Handler) UpdateRole(ctx context.Context, request *shieldv1beta1.UpdateRo
 	if err != nil {
 		return nil, grpcBadBodyError
 	}
+	delete(metaDataMap, roleOrgMetadataKey)
+
+	includes, err := roleIncludes(metaDataMap)
+	if err != nil {
+		return nil, grpcBadBodyError
+	}
 
 	updatedRole, err := h.roleService.Update(ctx, role.Role{
 		ID:          request.GetId(),
 		Name:        request.GetBody().GetName(),
 		Types:       request.GetBody().GetTypes(),
 		NamespaceID: request.GetBody().GetNamespaceId(),
+		Includes:    includes,
 		Metadata:    metaDataMap,
 	})
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
-		case errors.Is(err, role.ErrInvalidDetail):
+		case errors.Is(err, role.ErrInvalidDetail),
+			errors.Is(err, role.ErrNamespaceNotPermitted),
+			errors.Is(err, role.ErrCyclicInclude),
+			errors.Is(err, namespace.ErrNotExist):
 			return nil, grpcBadBodyError
+		case errors.Is(err, errors.ErrForbidden):
+			return nil, grpcPermissionDenied
 		case errors.Is(err, role.ErrInvalidID),
 			errors.Is(err, role.ErrNotExist):
 			return nil, grpcRoleNotFoundErr
@@ -149,8 +185,53 @@ func (h Handler) UpdateRole(ctx context.Context, request *shieldv1beta1.UpdateRo
 	return &shieldv1beta1.UpdateRoleResponse{Role: &rolePB}, nil
 }
 
+// roleIncludes takes the roles a role includes out of its metadata, nil if
+// the metadata doesn't list them
+func roleIncludes(meta metadata.Metadata) ([]string, error) {
+	value, ok := meta[roleIncludesMetadataKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(meta, roleIncludesMetadataKey)
+
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("metadata %s is not a list", roleIncludesMetadataKey)
+	}
+	includes := make([]string, 0, len(list))
+	for _, v := range list {
+		id, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("metadata %s has a value that is not a role id", roleIncludesMetadataKey)
+		}
+		includes = append(includes, id)
+	}
+	return includes, nil
+}
+
+func roleMetadata(rl role.Role) metadata.Metadata {
+	if rl.OrgID == "" && len(rl.Includes) == 0 {
+		return rl.Metadata
+	}
+	withBridged := metadata.Metadata{}
+	for k, v := range rl.Metadata {
+		withBridged[k] = v
+	}
+	if rl.OrgID != "" {
+		withBridged[roleOrgMetadataKey] = rl.OrgID
+	}
+	if len(rl.Includes) > 0 {
+		includes := make([]any, 0, len(rl.Includes))
+		for _, id := range rl.Includes {
+			includes = append(includes, id)
+		}
+		withBridged[roleIncludesMetadataKey] = includes
+	}
+	return withBridged
+}
+
 func transformRoleToPB(from role.Role) (shieldv1beta1.Role, error) {
-	metaData, err := from.Metadata.ToStructPB()
+	metaData, err := roleMetadata(from).ToStructPB()
 	if err != nil {
 		return shieldv1beta1.Role{}, err
 	}
@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/odpf/shield/core/metaschema"
 	"github.com/odpf/shield/core/user"
 	"github.com/odpf/shield/pkg/metadata"
 	"github.com/odpf/shield/pkg/uuid"
@@ -21,6 +22,7 @@ var grpcUserNotFoundError = status.Errorf(codes.NotFound, "user doesn't exist")
 
 //go:generate mockery --name=UserService -r --case underscore --with-expecter --structname UserService --filename user_service.go --output=./mocks
 type UserService interface {
+	Get(ctx context.Context, idOrEmail string) (user.User, error)
 	GetByID(ctx context.Context, id string) (user.User, error)
 	GetByIDs(ctx context.Context, userIDs []string) ([]user.User, error)
 	GetByEmail(ctx context.Context, email string) (user.User, error)
@@ -36,15 +38,33 @@ func (h Handler) ListUsers(ctx context.Context, request *shieldv1beta1.ListUsers
 	logger := grpczap.Extract(ctx)
 	var users []*shieldv1beta1.User
 
+	state, err := userState(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	globs, metadataGlobs, err := listFilters(ctx, "name", "email")
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	userResp, err := h.userService.List(ctx, user.Filter{
-		Limit:   request.GetPageSize(),
-		Page:    request.GetPageNum(),
-		Keyword: request.GetKeyword(),
+		Limit:    request.GetPageSize(),
+		Page:     request.GetPageNum(),
+		Keyword:  request.GetKeyword(),
+		Name:     globs["name"],
+		Email:    globs["email"],
+		Metadata: metadataGlobs,
+		State:    state,
 	})
 	if err != nil {
 		logger.Error(err.Error())
 		return nil, grpcInternalServerError
 	}
+	if err := setDisabledUsersHeader(ctx, userResp.Users); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
 
 	userList := userResp.Users
 	for _, user := range userList {
@@ -92,6 +112,10 @@ func (h Handler) CreateUser(ctx context.Context, request *shieldv1beta1.CreateUs
 		return nil, grpcBadBodyError
 	}
 
+	if err := h.validateMetadata(ctx, metaschema.EntityUser, metaDataMap); err != nil {
+		return nil, err
+	}
+
 	// TODO might need to check the valid email form
 	newUser, err := h.userService.Create(ctx, user.User{
 		Name:     request.GetBody().GetName(),
@@ -154,7 +178,7 @@ func (h Handler) CreateMetadataKey(ctx context.Context, request *shieldv1beta1.C
 func (h Handler) GetUser(ctx context.Context, request *shieldv1beta1.GetUserRequest) (*shieldv1beta1.GetUserResponse, error) {
 	logger := grpczap.Extract(ctx)
 
-	fetchedUser, err := h.userService.GetByID(ctx, request.GetId())
+	fetchedUser, err := h.userService.Get(ctx, request.GetId())
 	if err != nil {
 		logger.Error(err.Error())
 		switch {
@@ -165,6 +189,11 @@ func (h Handler) GetUser(ctx context.Context, request *shieldv1beta1.GetUserRequ
 		}
 	}
 
+	if err := setDisabledUsersHeader(ctx, []user.User{fetchedUser}); err != nil {
+		logger.Error(err.Error())
+		return nil, grpcInternalServerError
+	}
+
 	userPB, err := transformUserToPB(fetchedUser)
 	if err != nil {
 		logger.Error(err.Error())
@@ -200,6 +229,9 @@ func (h Handler) GetCurrentUser(ctx context.Context, request *shieldv1beta1.GetC
 			return nil, grpcInternalServerError
 		}
 	}
+	if fetchedUser.IsDisabled() {
+		return nil, grpcUnauthenticated
+	}
 
 	userPB, err := transformUserToPB(fetchedUser)
 	if err != nil {
@@ -234,6 +266,10 @@ func (h Handler) UpdateUser(ctx context.Context, request *shieldv1beta1.UpdateUs
 		return nil, grpcBadBodyError
 	}
 
+	if err := h.validateMetadata(ctx, metaschema.EntityUser, metaDataMap); err != nil {
+		return nil, err
+	}
+
 	id := request.GetId()
 	if uuid.IsValid(id) {
 		updatedUser, err = h.userService.UpdateByID(ctx, user.User{
@@ -316,6 +352,10 @@ func (h Handler) UpdateCurrentUser(ctx context.Context, request *shieldv1beta1.U
 		return nil, grpcBadBodyError
 	}
 
+	if err := h.validateMetadata(ctx, metaschema.EntityUser, metaDataMap); err != nil {
+		return nil, err
+	}
+
 	// if email in request body is different from the email in the header
 	if request.GetBody().GetEmail() != email {
 		return nil, grpcBadBodyError
@@ -352,7 +392,12 @@ func (h Handler) ListUserGroups(ctx context.Context, request *shieldv1beta1.List
 	groupsList, err := h.groupService.ListUserGroups(ctx, request.GetId(), request.GetRole())
 	if err != nil {
 		logger.Error(err.Error())
-		return nil, grpcInternalServerError
+		switch {
+		case errors.Is(err, user.ErrNotExist), errors.Is(err, user.ErrInvalidID), errors.Is(err, user.ErrInvalidUUID), errors.Is(err, user.ErrInvalidEmail):
+			return nil, grpcUserNotFoundError
+		default:
+			return nil, grpcInternalServerError
+		}
 	}
 
 	for _, group := range groupsList {
@@ -0,0 +1,40 @@
+package v1beta1
+
+import (
+	"context"
+	"strings"
+
+	"github.com/odpf/shield/core/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// the user messages have no field for the state of a user, the users listed
+// are narrowed down to a state with the UserStateHeader of the request and
+// the ids of the disabled users are returned in the DisabledUsersHeader of
+// the responses
+const (
+	UserStateHeader     = "x-shield-user-state"
+	DisabledUsersHeader = "x-shield-disabled-users"
+)
+
+func userState(ctx context.Context) (user.State, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(UserStateHeader); len(values) > 0 {
+		return user.ParseState(values[0])
+	}
+	return "", nil
+}
+
+func setDisabledUsersHeader(ctx context.Context, users []user.User) error {
+	var ids []string
+	for _, u := range users {
+		if u.IsDisabled() {
+			ids = append(ids, u.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return grpc.SetHeader(ctx, metadata.Pairs(DisabledUsersHeader, strings.Join(ids, ",")))
+}
@@ -0,0 +1,44 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/api/v1beta1/mocks"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUserState(t *testing.T) {
+	disabledUser := user.User{ID: "user-2", Name: "user 2", Email: "user2@odpf.io", DisabledAt: time.Now()}
+
+	t.Run("should list the users of the state of the header and return the ids of the disabled users", func(t *testing.T) {
+		mockUserSrv := new(mocks.UserService)
+		mockUserSrv.EXPECT().List(mock.Anything, user.Filter{State: user.StateDisabled}).Return(user.PagedUsers{
+			Count: 1,
+			Users: []user.User{disabledUser},
+		}, nil)
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(UserStateHeader, "Disabled"))
+
+		resp, err := Handler{userService: mockUserSrv}.ListUsers(ctx, &shieldv1beta1.ListUsersRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, resp.GetUsers(), 1)
+		assert.Equal(t, []string{"user-2"}, stream.header.Get(DisabledUsersHeader))
+	})
+
+	t.Run("should refuse to list the users of an unknown state", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(UserStateHeader, "suspended"))
+
+		_, err := Handler{}.ListUsers(ctx, &shieldv1beta1.ListUsersRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
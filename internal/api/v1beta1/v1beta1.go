@@ -21,25 +21,29 @@ type Handler struct {
 	relationService  RelationService
 	resourceService  ResourceService
 	ruleService      RuleService
+	// metaSchemaService is nil when the metadata are not validated
+	metaSchemaService MetaSchemaService
 }
 
 func Register(ctx context.Context, s *server.MuxServer, gw *server.GRPCGateway, deps api.Deps) {
 	gw.RegisterHandler(ctx, shieldv1beta1.RegisterShieldServiceHandlerFromEndpoint)
 
-	s.RegisterService(
-		&shieldv1beta1.ShieldService_ServiceDesc,
-		&Handler{
-			orgService:       deps.OrgService,
-			projectService:   deps.ProjectService,
-			groupService:     deps.GroupService,
-			roleService:      deps.RoleService,
-			policyService:    deps.PolicyService,
-			userService:      deps.UserService,
-			namespaceService: deps.NamespaceService,
-			actionService:    deps.ActionService,
-			relationService:  deps.RelationService,
-			resourceService:  deps.ResourceService,
-			ruleService:      deps.RuleService,
-		},
-	)
+	handler := &Handler{
+		orgService:       deps.OrgService,
+		projectService:   deps.ProjectService,
+		groupService:     deps.GroupService,
+		roleService:      deps.RoleService,
+		policyService:    deps.PolicyService,
+		userService:      deps.UserService,
+		namespaceService: deps.NamespaceService,
+		actionService:    deps.ActionService,
+		relationService:  deps.RelationService,
+		resourceService:  deps.ResourceService,
+		ruleService:      deps.RuleService,
+	}
+	if deps.MetaSchemaService != nil {
+		handler.metaSchemaService = deps.MetaSchemaService
+	}
+
+	s.RegisterService(&shieldv1beta1.ShieldService_ServiceDesc, handler)
 }
@@ -0,0 +1,37 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// the create and update messages have no validate only field, with the
+// ValidateOnlyHeader of the request set to true the rpcs of the organizations,
+// projects and policies run their checks and return the object as it would be
+// stored without storing it. The ValidatedHeader of the response tells nothing
+// was stored, the other rpcs ignore the header and store the object.
+const (
+	ValidateOnlyHeader = "x-shield-validate-only"
+	ValidatedHeader    = "x-shield-validated"
+)
+
+func validateOnly(ctx context.Context) (bool, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(ValidateOnlyHeader)
+	if len(values) == 0 {
+		return false, nil
+	}
+	validate, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return false, fmt.Errorf("%s should be true or false", ValidateOnlyHeader)
+	}
+	return validate, nil
+}
+
+func setValidatedHeader(ctx context.Context) error {
+	return grpc.SetHeader(ctx, metadata.Pairs(ValidatedHeader, "true"))
+}
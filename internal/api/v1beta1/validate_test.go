@@ -0,0 +1,58 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/internal/api/v1beta1/mocks"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidateOnly(t *testing.T) {
+	body := &shieldv1beta1.PolicyRequestBody{
+		RoleId:      "reader",
+		NamespaceId: "policy-1",
+		ActionId:    "read",
+	}
+
+	t.Run("should validate the policy without creating it", func(t *testing.T) {
+		mockPolicySrv := new(mocks.PolicyService)
+		mockPolicySrv.EXPECT().ValidateCreate(mock.Anything, policy.Policy{
+			RoleID:      "reader",
+			NamespaceID: "policy-1",
+			ActionID:    "read",
+		}).Return(policy.Policy{RoleID: "reader", NamespaceID: "policy-1", ActionID: "read", Effect: policy.EffectAllow}, nil)
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ValidateOnlyHeader, "true"))
+
+		res, err := Handler{policyService: mockPolicySrv}.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{Body: body})
+		assert.NoError(t, err)
+		assert.Len(t, res.GetPolicies(), 1)
+		assert.Equal(t, []string{"true"}, stream.header.Get(ValidatedHeader))
+		mockPolicySrv.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return conflict if the policy can't be created", func(t *testing.T) {
+		mockPolicySrv := new(mocks.PolicyService)
+		mockPolicySrv.EXPECT().ValidateCreate(mock.Anything, mock.Anything).Return(policy.Policy{}, policy.ErrConflict)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ValidateOnlyHeader, "true"))
+
+		_, err := Handler{policyService: mockPolicySrv}.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{Body: body})
+		assert.Equal(t, grpcConflictError, err)
+	})
+
+	t.Run("should return invalid argument if the header isn't a bool", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ValidateOnlyHeader, "maybe"))
+
+		_, err := Handler{policyService: new(mocks.PolicyService)}.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{Body: body})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,55 @@
+package v1beta1
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// the organization, project, group and policy messages have no field for
+// their version, it is returned in the ETagHeader of the responses with an
+// entity and an update made with the IfMatchHeader is refused with a failed
+// precondition once the entity was updated since that version. An update
+// without the header, or with *, is made whatever the version.
+const (
+	ETagHeader    = "etag"
+	IfMatchHeader = "if-match"
+)
+
+var (
+	grpcVersionMismatchError = status.Error(codes.FailedPrecondition, "the entity was updated since the version of if-match")
+	grpcBadIfMatchError      = status.Error(codes.InvalidArgument, "if-match should be an etag returned by the api")
+)
+
+// formatETag quotes the version the way http entity tags are
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ifMatchVersion is the version of the IfMatchHeader of the request, 0 when
+// the request has none
+func ifMatchVersion(ctx context.Context) (int64, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(IfMatchHeader)
+	if len(values) == 0 || strings.TrimSpace(values[0]) == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimSpace(values[0]), `"`), 10, 64)
+	if err != nil || version < 1 {
+		return 0, grpcBadIfMatchError
+	}
+	return version, nil
+}
+
+func setETagHeader(ctx context.Context, version int64) error {
+	if version == 0 {
+		return nil
+	}
+	return grpc.SetHeader(ctx, metadata.Pairs(ETagHeader, formatETag(version)))
+}
@@ -0,0 +1,75 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/internal/api/v1beta1/mocks"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestOrganizationVersion(t *testing.T) {
+	body := &shieldv1beta1.OrganizationRequestBody{Name: "org 1", Slug: "org-1"}
+
+	t.Run("should return the version of the organization in the etag", func(t *testing.T) {
+		mockOrgSrv := new(mocks.OrganizationService)
+		mockOrgSrv.EXPECT().Get(mock.Anything, "org-1").Return(organization.Organization{Slug: "org-1", Version: 3}, nil)
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+
+		_, err := Handler{orgService: mockOrgSrv}.GetOrganization(ctx, &shieldv1beta1.GetOrganizationRequest{Id: "org-1"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`"3"`}, stream.header.Get(ETagHeader))
+	})
+
+	t.Run("should update with the version of if-match", func(t *testing.T) {
+		mockOrgSrv := new(mocks.OrganizationService)
+		mockOrgSrv.EXPECT().Update(mock.Anything, organization.Organization{
+			Name:     "org 1",
+			Slug:     "org-1",
+			Metadata: map[string]any{},
+			Version:  3,
+		}).Return(organization.Organization{Name: "org 1", Slug: "org-1", Version: 4}, nil)
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(IfMatchHeader, `"3"`))
+
+		_, err := Handler{orgService: mockOrgSrv}.UpdateOrganization(ctx, &shieldv1beta1.UpdateOrganizationRequest{Id: "org-1", Body: body})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`"4"`}, stream.header.Get(ETagHeader))
+	})
+
+	t.Run("should return failed precondition if the organization was updated since", func(t *testing.T) {
+		mockOrgSrv := new(mocks.OrganizationService)
+		mockOrgSrv.EXPECT().Update(mock.Anything, mock.Anything).Return(organization.Organization{}, organization.ErrVersionMismatch)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IfMatchHeader, `"3"`))
+
+		_, err := Handler{orgService: mockOrgSrv}.UpdateOrganization(ctx, &shieldv1beta1.UpdateOrganizationRequest{Id: "org-1", Body: body})
+		assert.Equal(t, grpcVersionMismatchError, err)
+	})
+
+	t.Run("should return bad request if if-match is not an etag", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IfMatchHeader, "latest"))
+
+		_, err := Handler{}.UpdateOrganization(ctx, &shieldv1beta1.UpdateOrganizationRequest{Id: "org-1", Body: body})
+		assert.Equal(t, grpcBadIfMatchError, err)
+	})
+
+	t.Run("should update whatever the version without if-match", func(t *testing.T) {
+		mockOrgSrv := new(mocks.OrganizationService)
+		mockOrgSrv.EXPECT().Update(mock.Anything, organization.Organization{
+			Name:     "org 1",
+			Slug:     "org-1",
+			Metadata: map[string]any{},
+		}).Return(organization.Organization{Name: "org 1", Slug: "org-1", Version: 4}, nil)
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), &headerStream{})
+
+		_, err := Handler{orgService: mockOrgSrv}.UpdateOrganization(ctx, &shieldv1beta1.UpdateOrganizationRequest{Id: "org-1", Body: body})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,281 @@
+// Package bootstrap sets up a fresh deployment from a declarative spec, it
+// can be run again with the same spec as existing entities are left as they
+// are unless the spec changed them
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+)
+
+const (
+	ResultCreated   = "created"
+	ResultUpdated   = "updated"
+	ResultUnchanged = "unchanged"
+)
+
+type NamespaceService interface {
+	Get(ctx context.Context, id string) (namespace.Namespace, error)
+	Create(ctx context.Context, ns namespace.Namespace) (namespace.Namespace, error)
+	Update(ctx context.Context, ns namespace.Namespace) (namespace.Namespace, error)
+}
+
+type ActionService interface {
+	Get(ctx context.Context, id string) (action.Action, error)
+	Create(ctx context.Context, action action.Action) (action.Action, error)
+	Update(ctx context.Context, id string, action action.Action) (action.Action, error)
+}
+
+type RoleService interface {
+	Get(ctx context.Context, id string) (role.Role, error)
+	Create(ctx context.Context, toCreate role.Role) (role.Role, error)
+	Update(ctx context.Context, toUpdate role.Role) (role.Role, error)
+}
+
+type UserService interface {
+	GetByEmail(ctx context.Context, email string) (user.User, error)
+	Create(ctx context.Context, user user.User) (user.User, error)
+}
+
+type OrganizationService interface {
+	Get(ctx context.Context, idOrSlug string) (organization.Organization, error)
+	Create(ctx context.Context, org organization.Organization) (organization.Organization, error)
+	ListAdmins(ctx context.Context, idOrSlug string) ([]user.User, error)
+}
+
+type RelationService interface {
+	Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error)
+}
+
+// Step is what bootstrapping did to an entity of the spec
+type Step struct {
+	Kind   string
+	ID     string
+	Result string
+}
+
+type Bootstrapper struct {
+	namespaceService    NamespaceService
+	actionService       ActionService
+	roleService         RoleService
+	userService         UserService
+	organizationService OrganizationService
+	relationService     RelationService
+}
+
+func NewBootstrapper(namespaceService NamespaceService, actionService ActionService, roleService RoleService,
+	userService UserService, organizationService OrganizationService, relationService RelationService) *Bootstrapper {
+	return &Bootstrapper{
+		namespaceService:    namespaceService,
+		actionService:       actionService,
+		roleService:         roleService,
+		userService:         userService,
+		organizationService: organizationService,
+		relationService:     relationService,
+	}
+}
+
+// Run creates what the spec declares in the order the entities depend on
+// each other, namespaces, actions, roles, the admin and the organization
+// the admin owns. The steps taken before an error are returned with it.
+func (b Bootstrapper) Run(ctx context.Context, spec Spec) ([]Step, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	var steps []Step
+	for _, ns := range spec.Namespaces {
+		result, err := b.applyNamespace(ctx, ns.toNamespace())
+		if err != nil {
+			return steps, err
+		}
+		steps = append(steps, Step{Kind: "namespace", ID: ns.ID, Result: result})
+	}
+
+	for _, act := range spec.Actions {
+		result, err := b.applyAction(ctx, act.toAction())
+		if err != nil {
+			return steps, err
+		}
+		steps = append(steps, Step{Kind: "action", ID: act.ID, Result: result})
+	}
+
+	for _, rl := range spec.Roles {
+		result, err := b.applyRole(ctx, rl.toRole())
+		if err != nil {
+			return steps, err
+		}
+		steps = append(steps, Step{Kind: "role", ID: rl.ID, Result: result})
+	}
+
+	admin, result, err := b.applyAdmin(ctx, spec.Admin.toUser())
+	if err != nil {
+		return steps, err
+	}
+	steps = append(steps, Step{Kind: "user", ID: admin.Email, Result: result})
+
+	// the organization is created on behalf of the admin, who becomes its
+	// owner
+	ctx = user.SetContextWithEmail(ctx, admin.Email)
+	result, err = b.applyOrganization(ctx, spec.Organization.toOrganization(), admin)
+	if err != nil {
+		return steps, err
+	}
+	steps = append(steps, Step{Kind: "organization", ID: spec.Organization.Slug, Result: result})
+
+	return steps, nil
+}
+
+func (b Bootstrapper) applyNamespace(ctx context.Context, ns namespace.Namespace) (string, error) {
+	existing, err := b.namespaceService.Get(ctx, ns.ID)
+	if errors.Is(err, namespace.ErrNotExist) {
+		if _, err := b.namespaceService.Create(ctx, ns); err != nil {
+			return "", err
+		}
+		return ResultCreated, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if existing.Name == ns.Name && existing.Backend == ns.Backend && existing.ResourceType == ns.ResourceType {
+		return ResultUnchanged, nil
+	}
+	if _, err := b.namespaceService.Update(ctx, ns); err != nil {
+		return "", err
+	}
+	return ResultUpdated, nil
+}
+
+func (b Bootstrapper) applyAction(ctx context.Context, act action.Action) (string, error) {
+	existing, err := b.actionService.Get(ctx, act.ID)
+	if errors.Is(err, action.ErrNotExist) {
+		if _, err := b.actionService.Create(ctx, act); err != nil {
+			return "", err
+		}
+		return ResultCreated, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if existing.Name == act.Name && existing.NamespaceID == act.NamespaceID {
+		return ResultUnchanged, nil
+	}
+	if _, err := b.actionService.Update(ctx, act.ID, act); err != nil {
+		return "", err
+	}
+	return ResultUpdated, nil
+}
+
+func (b Bootstrapper) applyRole(ctx context.Context, rl role.Role) (string, error) {
+	existing, err := b.roleService.Get(ctx, rl.ID)
+	if errors.Is(err, role.ErrNotExist) {
+		if _, err := b.roleService.Create(ctx, rl); err != nil {
+			return "", err
+		}
+		return ResultCreated, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if existing.Name == rl.Name && existing.NamespaceID == rl.NamespaceID &&
+		sameStrings(existing.Types, rl.Types) && sameJSON(existing.Metadata, rl.Metadata) {
+		return ResultUnchanged, nil
+	}
+	if _, err := b.roleService.Update(ctx, rl); err != nil {
+		return "", err
+	}
+	return ResultUpdated, nil
+}
+
+// applyAdmin creates the admin unless a user with the email exists, an
+// existing user is left as is
+func (b Bootstrapper) applyAdmin(ctx context.Context, admin user.User) (user.User, string, error) {
+	existing, err := b.userService.GetByEmail(ctx, admin.Email)
+	if err == nil {
+		return existing, ResultUnchanged, nil
+	}
+	if !errors.Is(err, user.ErrNotExist) {
+		return user.User{}, "", err
+	}
+
+	created, err := b.userService.Create(ctx, admin)
+	if err != nil {
+		return user.User{}, "", err
+	}
+	return created, ResultCreated, nil
+}
+
+// applyOrganization creates the organization unless one with the slug
+// exists, the admin is made an owner of an existing one if it isn't yet
+func (b Bootstrapper) applyOrganization(ctx context.Context, org organization.Organization, admin user.User) (string, error) {
+	existing, err := b.organizationService.Get(ctx, org.Slug)
+	if errors.Is(err, organization.ErrNotExist) {
+		if _, err := b.organizationService.Create(ctx, org); err != nil {
+			return "", err
+		}
+		return ResultCreated, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	admins, err := b.organizationService.ListAdmins(ctx, existing.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range admins {
+		if a.ID == admin.ID {
+			return ResultUnchanged, nil
+		}
+	}
+
+	if _, err := b.relationService.Create(ctx, relation.RelationV2{
+		Object: relation.Object{
+			ID:          existing.ID,
+			NamespaceID: schema.OrganizationNamespace,
+		},
+		Subject: relation.Subject{
+			ID:        admin.Email,
+			Namespace: schema.UserPrincipal,
+			RoleID:    schema.OwnerRole,
+		},
+	}); err != nil {
+		return "", err
+	}
+	return ResultUpdated, nil
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameJSON compares metadata as stored, numbers read from a file and from
+// the database are of different types
+func sameJSON(a, b map[string]any) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	aj, aErr := json.Marshal(a)
+	bj, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aj) == string(bj)
+}
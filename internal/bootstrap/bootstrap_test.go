@@ -0,0 +1,216 @@
+package bootstrap_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/bootstrap"
+)
+
+type store struct {
+	namespaces map[string]namespace.Namespace
+	actions    map[string]action.Action
+	roles      map[string]role.Role
+	users      map[string]user.User
+	orgs       map[string]organization.Organization
+	admins     map[string][]user.User
+	relations  []relation.RelationV2
+}
+
+func newStore() *store {
+	return &store{
+		namespaces: map[string]namespace.Namespace{},
+		actions:    map[string]action.Action{},
+		roles:      map[string]role.Role{},
+		users:      map[string]user.User{},
+		orgs:       map[string]organization.Organization{},
+		admins:     map[string][]user.User{},
+	}
+}
+
+type namespaceService struct{ *store }
+
+func (s namespaceService) Get(ctx context.Context, id string) (namespace.Namespace, error) {
+	ns, ok := s.namespaces[id]
+	if !ok {
+		return namespace.Namespace{}, namespace.ErrNotExist
+	}
+	return ns, nil
+}
+
+func (s namespaceService) Create(ctx context.Context, ns namespace.Namespace) (namespace.Namespace, error) {
+	s.namespaces[ns.ID] = ns
+	return ns, nil
+}
+
+func (s namespaceService) Update(ctx context.Context, ns namespace.Namespace) (namespace.Namespace, error) {
+	return s.Create(ctx, ns)
+}
+
+type actionService struct{ *store }
+
+func (s actionService) Get(ctx context.Context, id string) (action.Action, error) {
+	act, ok := s.actions[id]
+	if !ok {
+		return action.Action{}, action.ErrNotExist
+	}
+	return act, nil
+}
+
+func (s actionService) Create(ctx context.Context, act action.Action) (action.Action, error) {
+	s.actions[act.ID] = act
+	return act, nil
+}
+
+func (s actionService) Update(ctx context.Context, id string, act action.Action) (action.Action, error) {
+	return s.Create(ctx, act)
+}
+
+type roleService struct{ *store }
+
+func (s roleService) Get(ctx context.Context, id string) (role.Role, error) {
+	rl, ok := s.roles[id]
+	if !ok {
+		return role.Role{}, role.ErrNotExist
+	}
+	return rl, nil
+}
+
+func (s roleService) Create(ctx context.Context, rl role.Role) (role.Role, error) {
+	s.roles[rl.ID] = rl
+	return rl, nil
+}
+
+func (s roleService) Update(ctx context.Context, rl role.Role) (role.Role, error) {
+	return s.Create(ctx, rl)
+}
+
+type userService struct{ *store }
+
+func (s userService) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	usr, ok := s.users[email]
+	if !ok {
+		return user.User{}, user.ErrNotExist
+	}
+	return usr, nil
+}
+
+func (s userService) Create(ctx context.Context, usr user.User) (user.User, error) {
+	usr.ID = "user-" + usr.Email
+	s.users[usr.Email] = usr
+	return usr, nil
+}
+
+type organizationService struct{ *store }
+
+func (s organizationService) Get(ctx context.Context, slug string) (organization.Organization, error) {
+	org, ok := s.orgs[slug]
+	if !ok {
+		return organization.Organization{}, organization.ErrNotExist
+	}
+	return org, nil
+}
+
+func (s organizationService) Create(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	email, _ := user.GetEmailFromContext(ctx)
+	org.ID = "org-" + org.Slug
+	s.orgs[org.Slug] = org
+	s.admins[org.ID] = []user.User{s.users[email]}
+	return org, nil
+}
+
+func (s organizationService) ListAdmins(ctx context.Context, id string) ([]user.User, error) {
+	return s.admins[id], nil
+}
+
+type relationService struct{ *store }
+
+func (s relationService) Create(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	s.relations = append(s.relations, rel)
+	return rel, nil
+}
+
+func newBootstrapper(s *store) *bootstrap.Bootstrapper {
+	return bootstrap.NewBootstrapper(namespaceService{s}, actionService{s}, roleService{s}, userService{s}, organizationService{s}, relationService{s})
+}
+
+var spec = bootstrap.Spec{
+	Namespaces: []bootstrap.Namespace{{ID: "entropy/firehose", Name: "Firehose", Backend: "entropy", ResourceType: "firehose"}},
+	Actions:    []bootstrap.Action{{ID: "entropy/firehose.update", Name: "Update Firehose", NamespaceID: "entropy/firehose"}},
+	Roles: []bootstrap.Role{{ID: "entropy/firehose:operator", Name: "operator", Types: []string{"shield/user"},
+		NamespaceID: "entropy/firehose", Metadata: map[string]any{"tier": 1}}},
+	Admin:        bootstrap.User{Name: "Admin", Email: "admin@odpf.io"},
+	Organization: bootstrap.Organization{Name: "ODPF", Slug: "odpf"},
+}
+
+func TestBootstrapperRun(t *testing.T) {
+	t.Run("should create what the spec declares", func(t *testing.T) {
+		s := newStore()
+
+		steps, err := newBootstrapper(s).Run(context.Background(), spec)
+		require.NoError(t, err)
+		assert.Len(t, steps, 5)
+		for _, step := range steps {
+			assert.Equal(t, bootstrap.ResultCreated, step.Result, step.Kind)
+		}
+		assert.Equal(t, "user-admin@odpf.io", s.admins["org-odpf"][0].ID)
+	})
+
+	t.Run("should leave the entities unchanged when run again", func(t *testing.T) {
+		s := newStore()
+		_, err := newBootstrapper(s).Run(context.Background(), spec)
+		require.NoError(t, err)
+
+		// metadata is read back from the database with numbers as floats
+		rl := s.roles["entropy/firehose:operator"]
+		rl.Metadata = map[string]any{"tier": float64(1)}
+		s.roles[rl.ID] = rl
+
+		steps, err := newBootstrapper(s).Run(context.Background(), spec)
+		require.NoError(t, err)
+		for _, step := range steps {
+			assert.Equal(t, bootstrap.ResultUnchanged, step.Result, step.Kind)
+		}
+	})
+
+	t.Run("should make the admin an owner of an existing organization", func(t *testing.T) {
+		s := newStore()
+		s.orgs["odpf"] = organization.Organization{ID: "org-odpf", Slug: "odpf"}
+
+		steps, err := newBootstrapper(s).Run(context.Background(), spec)
+		require.NoError(t, err)
+		assert.Equal(t, bootstrap.Step{Kind: "organization", ID: "odpf", Result: bootstrap.ResultUpdated}, steps[len(steps)-1])
+		require.Len(t, s.relations, 1)
+		assert.Equal(t, "admin@odpf.io", s.relations[0].Subject.ID)
+		assert.Equal(t, "owner", s.relations[0].Subject.RoleID)
+	})
+
+	t.Run("should return error if the spec has no admin", func(t *testing.T) {
+		invalid := spec
+		invalid.Admin = bootstrap.User{}
+
+		_, err := newBootstrapper(newStore()).Run(context.Background(), invalid)
+		assert.ErrorIs(t, err, bootstrap.ErrInvalidSpec)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should return error if the file has an unknown field", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bootstrap.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("admin:\n  email: admin@odpf.io\norganisation:\n  slug: odpf\n"), 0o600))
+
+		_, err := bootstrap.Load(path)
+		assert.ErrorIs(t, err, bootstrap.ErrInvalidSpec)
+	})
+}
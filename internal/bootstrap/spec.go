@@ -0,0 +1,124 @@
+package bootstrap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/core/user"
+)
+
+var ErrInvalidSpec = errors.New("invalid bootstrap spec")
+
+// Spec declares what a fresh deployment starts with, the admin is the
+// owner of the organization
+type Spec struct {
+	Namespaces   []Namespace  `yaml:"namespaces"`
+	Actions      []Action     `yaml:"actions"`
+	Roles        []Role       `yaml:"roles"`
+	Admin        User         `yaml:"admin"`
+	Organization Organization `yaml:"organization"`
+}
+
+type Namespace struct {
+	ID           string `yaml:"id"`
+	Name         string `yaml:"name"`
+	Backend      string `yaml:"backend"`
+	ResourceType string `yaml:"resource_type"`
+}
+
+type Action struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	NamespaceID string `yaml:"namespace_id"`
+}
+
+type Role struct {
+	ID          string         `yaml:"id"`
+	Name        string         `yaml:"name"`
+	Types       []string       `yaml:"types"`
+	NamespaceID string         `yaml:"namespace_id"`
+	Metadata    map[string]any `yaml:"metadata"`
+}
+
+type User struct {
+	Name     string         `yaml:"name"`
+	Email    string         `yaml:"email"`
+	Metadata map[string]any `yaml:"metadata"`
+}
+
+type Organization struct {
+	Name     string         `yaml:"name"`
+	Slug     string         `yaml:"slug"`
+	Metadata map[string]any `yaml:"metadata"`
+}
+
+// Load reads the spec of a yaml file, unknown fields are rejected so typos
+// don't go unnoticed
+func Load(path string) (Spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	var spec Spec
+	if err := decoder.Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("%w: %s", ErrInvalidSpec, err.Error())
+	}
+	return spec, spec.Validate()
+}
+
+func (s Spec) Validate() error {
+	for _, ns := range s.Namespaces {
+		if ns.ID == "" || ns.Name == "" {
+			return fmt.Errorf("%w: namespaces need an id and a name", ErrInvalidSpec)
+		}
+	}
+	for _, act := range s.Actions {
+		if act.ID == "" || act.Name == "" || act.NamespaceID == "" {
+			return fmt.Errorf("%w: actions need an id, a name and a namespace_id", ErrInvalidSpec)
+		}
+	}
+	for _, rl := range s.Roles {
+		if rl.ID == "" || rl.Name == "" || rl.NamespaceID == "" || len(rl.Types) == 0 {
+			return fmt.Errorf("%w: roles need an id, a name, a namespace_id and types", ErrInvalidSpec)
+		}
+	}
+	if s.Admin.Email == "" || !strings.Contains(s.Admin.Email, "@") {
+		return fmt.Errorf("%w: admin needs an email", ErrInvalidSpec)
+	}
+	if s.Organization.Name == "" || s.Organization.Slug == "" {
+		return fmt.Errorf("%w: organization needs a name and a slug", ErrInvalidSpec)
+	}
+	return nil
+}
+
+func (ns Namespace) toNamespace() namespace.Namespace {
+	return namespace.Namespace{ID: ns.ID, Name: ns.Name, Backend: ns.Backend, ResourceType: ns.ResourceType}
+}
+
+func (a Action) toAction() action.Action {
+	return action.Action{ID: a.ID, Name: a.Name, NamespaceID: a.NamespaceID}
+}
+
+func (r Role) toRole() role.Role {
+	return role.Role{ID: r.ID, Name: r.Name, Types: r.Types, NamespaceID: r.NamespaceID, Metadata: r.Metadata}
+}
+
+func (u User) toUser() user.User {
+	return user.User{Name: u.Name, Email: u.Email, Metadata: u.Metadata}
+}
+
+func (o Organization) toOrganization() organization.Organization {
+	return organization.Organization{Name: o.Name, Slug: o.Slug, Metadata: o.Metadata}
+}
@@ -0,0 +1,200 @@
+// Package devenv runs what a local shield needs for development, a postgres
+// cluster kept in a directory of its own and started with the postgres
+// binaries of the host, so the authorization flows of an application can be
+// tried without docker. The directory is marked as a dev environment and
+// only marked directories are ever wiped.
+package devenv
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+const (
+	// markerFile marks a directory as a dev environment
+	markerFile = ".shield-dev"
+
+	user     = "shield"
+	database = "shield"
+)
+
+var (
+	ErrPostgresNotFound = errors.New("postgres binaries not found, install postgres or set the directory of its binaries")
+	ErrNotDevDir        = errors.New("directory is not a shield dev environment")
+)
+
+// Postgres is the postgres cluster of a dev environment, its data is kept in
+// the pgdata directory of the environment
+type Postgres struct {
+	// Dir is the directory of the dev environment
+	Dir string
+	// BinDir is the directory of initdb and pg_ctl, they are looked up in
+	// the PATH when it is empty
+	BinDir string
+	Port   int
+}
+
+func (p Postgres) dataDir() string {
+	return filepath.Join(p.Dir, "pgdata")
+}
+
+// URL is the url of the database of shield on the cluster
+func (p Postgres) URL() string {
+	return p.url(database)
+}
+
+func (p Postgres) url(db string) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(user),
+		Host:     "127.0.0.1:" + strconv.Itoa(p.Port),
+		Path:     "/" + db,
+		RawQuery: "sslmode=disable",
+	}
+	return u.String()
+}
+
+func (p Postgres) bin(name string) (string, error) {
+	if p.BinDir != "" {
+		path := filepath.Join(p.BinDir, name)
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrPostgresNotFound, err)
+		}
+		return path, nil
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrPostgresNotFound, err)
+	}
+	return path, nil
+}
+
+func (p Postgres) run(ctx context.Context, name string, args ...string) error {
+	path, err := p.bin(name)
+	if err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// Start initializes the cluster unless it exists, starts it and creates the
+// database of shield unless it exists
+func (p Postgres) Start(ctx context.Context) error {
+	if err := Init(p.Dir); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(p.dataDir(), "PG_VERSION")); os.IsNotExist(err) {
+		if err := p.run(ctx, "initdb", "-D", p.dataDir(), "-U", user, "--auth=trust", "-E", "UTF8"); err != nil {
+			return err
+		}
+	}
+
+	if p.Running(ctx) {
+		// left running by an up which didn't stop
+		if err := p.Stop(ctx); err != nil {
+			return err
+		}
+	}
+	// the cluster only listens on the loopback, without a unix socket as
+	// the path of the directory may be too long for one
+	options := fmt.Sprintf("-p %d -c listen_addresses=127.0.0.1 -c unix_socket_directories=''", p.Port)
+	if err := p.run(ctx, "pg_ctl", "-D", p.dataDir(), "-l", filepath.Join(p.Dir, "postgres.log"), "-o", options, "-w", "start"); err != nil {
+		return err
+	}
+	return p.createDatabase(ctx)
+}
+
+func (p Postgres) createDatabase(ctx context.Context) error {
+	conn, err := sql.Open("pgx", p.url("postgres"))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	var exists bool
+	if err := conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)", database).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	_, err = conn.ExecContext(ctx, "CREATE DATABASE "+database)
+	return err
+}
+
+// Running tells whether the cluster is running
+func (p Postgres) Running(ctx context.Context) bool {
+	if _, err := os.Stat(filepath.Join(p.dataDir(), "PG_VERSION")); err != nil {
+		return false
+	}
+	return p.run(ctx, "pg_ctl", "-D", p.dataDir(), "status") == nil
+}
+
+// Stop stops the cluster, waiting for the connections to be closed
+func (p Postgres) Stop(ctx context.Context) error {
+	return p.run(ctx, "pg_ctl", "-D", p.dataDir(), "-m", "fast", "-w", "stop")
+}
+
+// Init creates the directory of a dev environment and marks it, an existing
+// directory has to be empty or a dev environment already
+func Init(dir string) error {
+	if IsDevDir(dir) {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%w: %s isn't empty", ErrNotDevDir, dir)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, markerFile), []byte("created by shield dev up\n"), 0o600)
+}
+
+// IsDevDir tells whether the directory is a dev environment
+func IsDevDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, markerFile))
+	return err == nil
+}
+
+// Reset stops the cluster of the dev environment when it is running and
+// removes the directory with everything in it, directories which aren't a
+// dev environment are refused
+func Reset(ctx context.Context, p Postgres) error {
+	if _, err := os.Stat(p.Dir); os.IsNotExist(err) {
+		return nil
+	}
+	if !IsDevDir(p.Dir) {
+		return fmt.Errorf("%w: %s", ErrNotDevDir, p.Dir)
+	}
+	if p.Running(ctx) {
+		if err := p.Stop(ctx); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(p.Dir)
+}
@@ -0,0 +1,43 @@
+package devenv_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/shield/internal/devenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevDir(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should refuse the directories which aren't a dev environment", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0o600))
+
+		assert.ErrorIs(t, devenv.Init(dir), devenv.ErrNotDevDir)
+		assert.ErrorIs(t, devenv.Reset(ctx, devenv.Postgres{Dir: dir}), devenv.ErrNotDevDir)
+		assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+	})
+
+	t.Run("should wipe a dev environment", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "dev")
+		require.NoError(t, devenv.Init(dir))
+		require.NoError(t, devenv.Init(dir))
+		assert.True(t, devenv.IsDevDir(dir))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("version: 1"), 0o600))
+
+		require.NoError(t, devenv.Reset(ctx, devenv.Postgres{Dir: dir, BinDir: t.TempDir()}))
+		assert.NoDirExists(t, dir)
+		assert.NoError(t, devenv.Reset(ctx, devenv.Postgres{Dir: dir}))
+	})
+
+	t.Run("should tell the postgres binaries are missing", func(t *testing.T) {
+		pg := devenv.Postgres{Dir: filepath.Join(t.TempDir(), "dev"), BinDir: t.TempDir(), Port: 54329}
+		assert.ErrorIs(t, pg.Start(ctx), devenv.ErrPostgresNotFound)
+		assert.Equal(t, "postgres://shield@127.0.0.1:54329/shield?sslmode=disable", pg.URL())
+	})
+}
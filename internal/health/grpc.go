@@ -0,0 +1,33 @@
+package health
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCServer is the grpc.health.v1 service, the server and all of its
+// services are serving when the checks pass
+type GRPCServer struct {
+	healthpb.UnimplementedHealthServer
+	checker *Checker
+}
+
+func NewGRPCServer(checker *Checker) *GRPCServer {
+	return &GRPCServer{
+		checker: checker,
+	}
+}
+
+func (s *GRPCServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !s.checker.Ready(ctx).Ready() {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *GRPCServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	return status.Error(codes.Unimplemented, "watching the health of the server is not supported")
+}
@@ -0,0 +1,107 @@
+// Package health tells whether the server is alive and whether the
+// dependencies it needs to serve requests are ready, over http for the
+// probes of kubernetes and over the grpc health service
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusFailed      = "failed"
+	StatusUnavailable = "unavailable"
+
+	// CheckTimeout is how long the checks of the dependencies can take
+	CheckTimeout = 5 * time.Second
+)
+
+// Check is a dependency the server needs to serve requests, it is ready
+// when Run returns no error
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type Result struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r Result) Ready() bool {
+	return r.Status == StatusOK
+}
+
+type Checker struct {
+	checks []Check
+}
+
+func NewChecker(checks ...Check) *Checker {
+	return &Checker{
+		checks: checks,
+	}
+}
+
+// Ready runs the checks concurrently, the server is ready when all of them
+// pass
+func (c *Checker) Ready(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	result := Result{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			checkResult := CheckResult{Status: StatusOK}
+			if err := check.Run(ctx); err != nil {
+				checkResult = CheckResult{Status: StatusFailed, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Checks[check.Name] = checkResult
+			if checkResult.Status != StatusOK {
+				result.Status = StatusUnavailable
+			}
+		}(check)
+	}
+	wg.Wait()
+	return result
+}
+
+// LivenessHandler answers as long as the server is able to handle requests,
+// the dependencies are not checked so the server isn't restarted when one
+// of them is down
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, Result{Status: StatusOK})
+	})
+}
+
+// ReadinessHandler answers with the result of the checks, with a service
+// unavailable status when any of them failed
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, c.Ready(r.Context()))
+	})
+}
+
+func writeResult(w http.ResponseWriter, result Result) {
+	w.Header().Set("Content-Type", "application/json")
+	if !result.Ready() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
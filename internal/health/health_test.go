@@ -0,0 +1,50 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/odpf/shield/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	ok := health.Check{Name: "postgres", Run: func(ctx context.Context) error { return nil }}
+	failing := health.Check{Name: "spicedb", Run: func(ctx context.Context) error { return errors.New("connection refused") }}
+
+	t.Run("should be ready if all the checks pass", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		health.NewChecker(ok).ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var got health.Result
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+		assert.Equal(t, health.StatusOK, got.Status)
+		assert.Equal(t, health.CheckResult{Status: health.StatusOK}, got.Checks["postgres"])
+	})
+
+	t.Run("should be unavailable if a check fails", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		health.NewChecker(ok, failing).ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		var got health.Result
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+		assert.Equal(t, health.StatusUnavailable, got.Status)
+		assert.Equal(t, health.CheckResult{Status: health.StatusFailed, Error: "connection refused"}, got.Checks["spicedb"])
+	})
+}
+
+func TestGRPCServerCheck(t *testing.T) {
+	failing := health.Check{Name: "migrations", Run: func(ctx context.Context) error { return errors.New("migrations pending") }}
+
+	got, err := health.NewGRPCServer(health.NewChecker(failing)).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, got.GetStatus())
+}
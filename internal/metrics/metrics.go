@@ -0,0 +1,258 @@
+// Package metrics instruments the api server, the proxies and the stores,
+// the metrics are served in the prometheus format at the metrics path of
+// the api server
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// names of the metrics, the hit ratio of the check cache is the rate of the
+// hits over the rate of the hits and misses
+const (
+	GRPCRequestsTotal          = "shield_grpc_requests_total"
+	GRPCRequestDuration        = "shield_grpc_request_duration_seconds"
+	HTTPRequestsTotal          = "shield_http_requests_total"
+	HTTPRequestDuration        = "shield_http_request_duration_seconds"
+	SpiceDBCheckDuration       = "shield_spicedb_check_duration_seconds"
+	OpenFGACheckDuration       = "shield_openfga_check_duration_seconds"
+	CheckCacheHitsTotal        = "shield_check_cache_hits_total"
+	CheckCacheMissesTotal      = "shield_check_cache_misses_total"
+	DBMaxOpenConnections       = "shield_db_max_open_connections"
+	DBOpenConnections          = "shield_db_open_connections"
+	DBInUseConnections         = "shield_db_in_use_connections"
+	DBIdleConnections          = "shield_db_idle_connections"
+	DBWaitTotal                = "shield_db_wait_total"
+	DBWaitDurationSecondsTotal = "shield_db_wait_duration_seconds_total"
+	DBReplicaOpenConnections   = "shield_db_replica_open_connections"
+	DBReplicaInUseConnections  = "shield_db_replica_in_use_connections"
+	DBReplicaIdleConnections   = "shield_db_replica_idle_connections"
+	DBReplicaWaitTotal         = "shield_db_replica_wait_total"
+	DBReplicaFallbacksTotal    = "shield_db_replica_fallbacks_total"
+	RateLimitedTotal           = "shield_rate_limited_requests_total"
+	ProxyCircuitBreakerState   = "shield_proxy_circuit_breaker_state"
+	ProxyCircuitRejectedTotal  = "shield_proxy_circuit_rejected_requests_total"
+	ProxyRetriesTotal          = "shield_proxy_retries_total"
+)
+
+// values of the state of a circuit breaker
+const (
+	circuitClosed   = 0
+	circuitHalfOpen = 1
+	circuitOpen     = 2
+)
+
+// ServerAPI is the server label of the requests to the api, the requests
+// to a proxy are labelled with the name of its service
+const ServerAPI = "api"
+
+// registry keeps the metrics of shield apart from the default registry of
+// prometheus the libraries register theirs in
+var registry = prometheus.NewRegistry()
+
+// the label values are only passed by the functions below, each passes as
+// many as its metric has labels
+var (
+	factory = promauto.With(registry)
+
+	grpcRequests = factory.NewCounterVec(prometheus.CounterOpts{Name: GRPCRequestsTotal,
+		Help: "Number of grpc requests handled by the api by method and status code."}, []string{"method", "code"})
+	grpcDuration = factory.NewHistogramVec(prometheus.HistogramOpts{Name: GRPCRequestDuration,
+		Help: "Latency of the grpc requests handled by the api by method.", Buckets: prometheus.DefBuckets}, []string{"method"})
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{Name: HTTPRequestsTotal,
+		Help: "Number of http requests handled by server, method and status code."}, []string{"server", "method", "code"})
+	httpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{Name: HTTPRequestDuration,
+		Help: "Latency of the http requests handled by server and method.", Buckets: prometheus.DefBuckets}, []string{"server", "method"})
+	spiceDBCheckDuration = factory.NewHistogramVec(prometheus.HistogramOpts{Name: SpiceDBCheckDuration,
+		Help: "Latency of the permission checks made to spicedb by object namespace and result.", Buckets: prometheus.DefBuckets}, []string{"namespace", "result"})
+	openFGACheckDuration = factory.NewHistogramVec(prometheus.HistogramOpts{Name: OpenFGACheckDuration,
+		Help: "Latency of the permission checks made to openfga by object namespace and result.", Buckets: prometheus.DefBuckets}, []string{"namespace", "result"})
+	checkCacheHits = factory.NewCounterVec(prometheus.CounterOpts{Name: CheckCacheHitsTotal,
+		Help: "Number of permission checks answered by the check cache by object namespace."}, []string{"namespace"})
+	checkCacheMisses = factory.NewCounterVec(prometheus.CounterOpts{Name: CheckCacheMissesTotal,
+		Help: "Number of permission checks missing the check cache by object namespace."}, []string{"namespace"})
+	rateLimited = factory.NewCounterVec(prometheus.CounterOpts{Name: RateLimitedTotal,
+		Help: "Number of requests rejected for being over the rate limit by scope, identity or organization."}, []string{"scope"})
+	circuitState = factory.NewGaugeVec(prometheus.GaugeOpts{Name: ProxyCircuitBreakerState,
+		Help: "State of the circuit breakers of the backends of the proxies, 0 closed, 1 half open and 2 open."}, []string{"server", "backend"})
+	circuitRejected = factory.NewCounterVec(prometheus.CounterOpts{Name: ProxyCircuitRejectedTotal,
+		Help: "Number of proxied requests rejected by the open circuit breaker of their backend."}, []string{"server", "backend"})
+	proxyRetries = factory.NewCounterVec(prometheus.CounterOpts{Name: ProxyRetriesTotal,
+		Help: "Number of proxied requests sent again to their backend after a failure."}, []string{"server", "backend"})
+)
+
+// Handler serves the metrics of shield along with the stats of the go
+// runtime and of the process
+func Handler() http.Handler {
+	return promhttp.HandlerFor(prometheus.Gatherers{registry, prometheus.DefaultGatherer}, promhttp.HandlerOpts{})
+}
+
+// UnaryServerInterceptor counts the grpc requests and observes their latency
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		grpcDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		grpcRequests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		return resp, err
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the websocket connections through, the reverse proxy takes
+// over the connection of an upgraded request
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer doesn't support hijacking")
+	}
+	return h.Hijack()
+}
+
+// HTTPMiddleware counts the http requests of the server and observes their
+// latency, paths are left out as they hold ids
+func HTTPMiddleware(server string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		httpDuration.WithLabelValues(server, r.Method).Observe(time.Since(start).Seconds())
+		httpRequests.WithLabelValues(server, r.Method, strconv.Itoa(rec.status)).Inc()
+	})
+}
+
+// ObserveSpiceDBCheck observes the latency of a permission check since
+// start, the result is allowed, denied or error
+func ObserveSpiceDBCheck(namespace string, start time.Time, allowed bool, err error) {
+	spiceDBCheckDuration.WithLabelValues(namespace, checkResult(allowed, err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveOpenFGACheck observes the latency of a permission check made to
+// openfga since start, the result is allowed, denied or error
+func ObserveOpenFGACheck(namespace string, start time.Time, allowed bool, err error) {
+	openFGACheckDuration.WithLabelValues(namespace, checkResult(allowed, err)).Observe(time.Since(start).Seconds())
+}
+
+func checkResult(allowed bool, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case allowed:
+		return "allowed"
+	default:
+		return "denied"
+	}
+}
+
+func CheckCacheHit(namespace string) {
+	checkCacheHits.WithLabelValues(namespace).Inc()
+}
+
+func CheckCacheMiss(namespace string) {
+	checkCacheMisses.WithLabelValues(namespace).Inc()
+}
+
+func RateLimited(scope string) {
+	rateLimited.WithLabelValues(scope).Inc()
+}
+
+// CircuitBreakerState sets the state of the circuit breaker of a backend of
+// a proxy, the state is closed, half_open or open
+func CircuitBreakerState(server, backend, state string) {
+	value := circuitClosed
+	switch state {
+	case "half_open":
+		value = circuitHalfOpen
+	case "open":
+		value = circuitOpen
+	}
+	circuitState.WithLabelValues(server, backend).Set(float64(value))
+}
+
+func CircuitRejected(server, backend string) {
+	circuitRejected.WithLabelValues(server, backend).Inc()
+}
+
+func ProxyRetry(server, backend string) {
+	proxyRetries.WithLabelValues(server, backend).Inc()
+}
+
+// RegisterDBStats exports the stats of the connection pool of the database,
+// it is registered once per process and registering it again returns the
+// error of the registry
+func RegisterDBStats(stats func() sql.DBStats) error {
+	return register(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: DBMaxOpenConnections, Help: "Maximum number of open connections to the database."},
+			func() float64 { return float64(stats().MaxOpenConnections) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: DBOpenConnections, Help: "Number of open connections to the database."},
+			func() float64 { return float64(stats().OpenConnections) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: DBInUseConnections, Help: "Number of connections to the database in use."},
+			func() float64 { return float64(stats().InUse) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: DBIdleConnections, Help: "Number of idle connections to the database."},
+			func() float64 { return float64(stats().Idle) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: DBWaitTotal, Help: "Number of times a connection to the database was waited for."},
+			func() float64 { return float64(stats().WaitCount) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: DBWaitDurationSecondsTotal, Help: "Time spent waiting for connections to the database."},
+			func() float64 { return stats().WaitDuration.Seconds() }),
+	)
+}
+
+// RegisterDBReplicaStats exports the stats of the connection pool of the
+// read replica and the number of reads that fell back to the primary, it is
+// registered once per process and registering it again returns the error of
+// the registry
+func RegisterDBReplicaStats(stats func() sql.DBStats, fallbacks func() uint64) error {
+	return register(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: DBReplicaOpenConnections, Help: "Number of open connections to the read replica."},
+			func() float64 { return float64(stats().OpenConnections) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: DBReplicaInUseConnections, Help: "Number of connections to the read replica in use."},
+			func() float64 { return float64(stats().InUse) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: DBReplicaIdleConnections, Help: "Number of idle connections to the read replica."},
+			func() float64 { return float64(stats().Idle) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: DBReplicaWaitTotal, Help: "Number of times a connection to the read replica was waited for."},
+			func() float64 { return float64(stats().WaitCount) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: DBReplicaFallbacksTotal, Help: "Number of reads made again on the primary after failing on the read replica."},
+			func() float64 { return float64(fallbacks()) }),
+	)
+}
+
+// register registers all the collectors or none of them
+func register(collectors ...prometheus.Collector) error {
+	for i, c := range collectors {
+		if err := registry.Register(c); err != nil {
+			for _, registered := range collectors[:i] {
+				registry.Unregister(registered)
+			}
+			return err
+		}
+	}
+	return nil
+}
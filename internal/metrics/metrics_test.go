@@ -0,0 +1,115 @@
+package metrics_test
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := metrics.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/odpf.shield.v1beta1.ShieldService/GetOrganization"}
+
+	_, _ = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "organization doesn't exist")
+	})
+
+	body := scrape(t)
+	assert.Contains(t, body, `shield_grpc_requests_total{code="NotFound",method="/odpf.shield.v1beta1.ShieldService/GetOrganization"} 1`)
+	assert.Contains(t, body, `shield_grpc_request_duration_seconds_count{method="/odpf.shield.v1beta1.ShieldService/GetOrganization"} 1`)
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	handler := metrics.HTTPMiddleware("test-proxy", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/resources", nil))
+
+	body := scrape(t)
+	assert.Contains(t, body, `shield_http_requests_total{code="403",method="POST",server="test-proxy"} 1`)
+	assert.Contains(t, body, `shield_http_request_duration_seconds_count{method="POST",server="test-proxy"} 1`)
+}
+
+func TestHTTPMiddlewareUpgrade(t *testing.T) {
+	// the backend switches to echoing what it reads
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		buf.Flush()
+		io.Copy(conn, buf)
+	}))
+	defer backend.Close()
+	target, err := url.Parse(backend.URL)
+	require.NoError(t, err)
+
+	proxy := httptest.NewServer(metrics.HTTPMiddleware("test-proxy", httputil.NewSingleHostReverseProxy(target)))
+	defer proxy.Close()
+
+	conn, err := net.Dial("tcp", proxy.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte("GET /api/stream HTTP/1.1\r\nHost: shield\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n"))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	res, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	echoed := make([]byte, 4)
+	_, err = io.ReadFull(reader, echoed)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(echoed))
+}
+
+func TestObserveSpiceDBCheck(t *testing.T) {
+	metrics.ObserveSpiceDBCheck("shield/project", time.Now(), true, nil)
+	metrics.ObserveSpiceDBCheck("shield/project", time.Now(), false, errors.New("unavailable"))
+	metrics.CheckCacheHit("shield/project")
+
+	body := scrape(t)
+	assert.Contains(t, body, `shield_spicedb_check_duration_seconds_count{namespace="shield/project",result="allowed"} 1`)
+	assert.Contains(t, body, `shield_spicedb_check_duration_seconds_count{namespace="shield/project",result="error"} 1`)
+	assert.Contains(t, body, `shield_check_cache_hits_total{namespace="shield/project"} 1`)
+}
+
+func TestRegisterDBStats(t *testing.T) {
+	stats := func() sql.DBStats { return sql.DBStats{MaxOpenConnections: 10, InUse: 2} }
+
+	require.NoError(t, metrics.RegisterDBStats(stats))
+	body := scrape(t)
+	assert.Contains(t, body, "shield_db_max_open_connections 10\n")
+	assert.Contains(t, body, "shield_db_in_use_connections 2\n")
+
+	assert.Error(t, metrics.RegisterDBStats(stats))
+}
@@ -0,0 +1,294 @@
+// Package policytest runs the scenarios of a test file against an authz
+// engine, each scenario gives relations and checks what the subjects are
+// and aren't allowed with them. The tests guard the authorization behavior
+// of a schema against regressions, in CI or before migrating to it.
+package policytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/store/blob"
+	"github.com/odpf/shield/internal/store/embedded"
+)
+
+var ErrInvalidFile = errors.New("invalid policy test file")
+
+// Engine is the authz engine the checks are made against, the relation
+// repositories of the engines are ones
+type Engine interface {
+	Check(ctx context.Context, rel relation.Relation, act action.Action) (bool, error)
+	Export(ctx context.Context, resourceType string, fn func(relation.Tuple) error) error
+	Import(ctx context.Context, tuples []relation.Tuple) error
+	DeleteTuples(ctx context.Context, tuples []relation.Tuple) error
+}
+
+// File is a test file:
+//
+//	schema: schema.yaml
+//	relations:
+//	  - shield/organization:odpf#owner@shield/user:alice
+//	scenarios:
+//	  - name: members of the viewers can't edit
+//	    relations:
+//	      - shield/project:payments#viewer@shield/group:data#membership
+//	      - shield/group:data#member@shield/user:bob
+//	    checks:
+//	      - subject: shield/user:bob
+//	        resource: shield/project:payments
+//	        permission: view
+//	        allowed: true
+//	      - subject: shield/user:bob
+//	        resource: shield/project:payments
+//	        permission: edit
+//	        allowed: false
+//
+// The relations are tuples of the authz schema, the ones of the file are
+// given to every scenario along with their own.
+type File struct {
+	// Schema is the path of the schema file the ephemeral engine is set up
+	// with, relative to the test file
+	Schema    string     `json:"schema"`
+	Relations []string   `json:"relations"`
+	Scenarios []Scenario `json:"scenarios"`
+
+	// dir is where the test file is, the schema path is relative to it
+	dir string
+}
+
+type Scenario struct {
+	Name      string   `json:"name"`
+	Relations []string `json:"relations"`
+	Checks    []Check  `json:"checks"`
+}
+
+// Check is a permission of the subject on the resource the scenario expects
+// the subject to be allowed or denied, the context sets the keys of the
+// request the conditions are evaluated against
+type Check struct {
+	Subject    string            `json:"subject"`
+	Resource   string            `json:"resource"`
+	Permission string            `json:"permission"`
+	Allowed    *bool             `json:"allowed"`
+	Context    map[string]string `json:"context"`
+}
+
+func (c Check) String() string {
+	verb := "can't"
+	if c.Allowed != nil && *c.Allowed {
+		verb = "can"
+	}
+	return fmt.Sprintf("%s %s %s %s", c.Subject, verb, c.Permission, c.Resource)
+}
+
+// Read reads the test file at the path
+func Read(path string) (File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	f, err := Parse(content)
+	if err != nil {
+		return File{}, fmt.Errorf("%s: %w", path, err)
+	}
+	f.dir = filepath.Dir(path)
+	return f, nil
+}
+
+// Parse parses the test file and validates its relations and checks
+func Parse(content []byte) (File, error) {
+	var f File
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return File{}, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+	if len(f.Scenarios) == 0 {
+		return File{}, fmt.Errorf("%w: no scenarios", ErrInvalidFile)
+	}
+	if _, err := parseTuples(f.Relations); err != nil {
+		return File{}, err
+	}
+	for i, s := range f.Scenarios {
+		if s.Name == "" {
+			return File{}, fmt.Errorf("%w: scenario %d has no name", ErrInvalidFile, i+1)
+		}
+		if _, err := parseTuples(s.Relations); err != nil {
+			return File{}, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		if len(s.Checks) == 0 {
+			return File{}, fmt.Errorf("%w: %s has no checks", ErrInvalidFile, s.Name)
+		}
+		for j, c := range s.Checks {
+			if c.Subject == "" || c.Resource == "" || c.Permission == "" || c.Allowed == nil {
+				return File{}, fmt.Errorf("%w: check %d of %s needs a subject, resource, permission and allowed", ErrInvalidFile, j+1, s.Name)
+			}
+		}
+	}
+	return f, nil
+}
+
+func parseTuples(relations []string) ([]relation.Tuple, error) {
+	var tuples []relation.Tuple
+	for _, r := range relations {
+		t, err := relation.ParseTuple(r)
+		if err != nil {
+			return nil, err
+		}
+		tuples = append(tuples, t)
+	}
+	return tuples, nil
+}
+
+// EphemeralEngine returns an embedded engine in memory with the schema of
+// the schema file of the test file and the predefined namespaces, just the
+// predefined ones when the test file has no schema file
+func EphemeralEngine(ctx context.Context, f File) (*embedded.RelationRepository, error) {
+	configs := schema.NamespaceConfigMapType{}
+	if f.Schema != "" {
+		path := f.Schema
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(f.dir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if configs, err = blob.ParseSchemaFile(content); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	engine := embedded.New(embedded.NewMemoryStore())
+	if err := embedded.NewPolicyRepository(engine).WriteSchema(ctx, schema.WithPredefined(configs)); err != nil {
+		return nil, err
+	}
+	return embedded.NewRelationRepository(engine), nil
+}
+
+// Result is the outcome of a check of a scenario, Err is why the check
+// couldn't be made
+type Result struct {
+	Scenario string
+	Check    Check
+	Allowed  bool
+	Err      error
+}
+
+func (r Result) Passed() bool {
+	return r.Err == nil && r.Allowed == *r.Check.Allowed
+}
+
+// Run runs the scenarios of the file one after the other, the relations of
+// a scenario the engine doesn't have yet are written for its checks and
+// deleted after them so the relations of the engine are left as they were
+func Run(ctx context.Context, engine Engine, f File) ([]Result, error) {
+	given, err := parseTuples(f.Relations)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, s := range f.Scenarios {
+		own, err := parseTuples(s.Relations)
+		if err != nil {
+			return nil, err
+		}
+		scenarioResults, err := runScenario(ctx, engine, s, append(append([]relation.Tuple{}, given...), own...))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		results = append(results, scenarioResults...)
+	}
+	return results, nil
+}
+
+func runScenario(ctx context.Context, engine Engine, s Scenario, tuples []relation.Tuple) (results []Result, err error) {
+	added, err := missingTuples(ctx, engine, tuples)
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.Import(ctx, added); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if deleteErr := engine.DeleteTuples(context.Background(), added); deleteErr != nil && err == nil {
+			err = fmt.Errorf("deleting the relations of the scenario: %w", deleteErr)
+		}
+	}()
+
+	// the relations were just written, the checks have to see them
+	ctx = relation.WithConsistency(ctx, relation.Consistency{Full: true})
+	for _, c := range s.Checks {
+		result := Result{Scenario: s.Name, Check: c}
+		result.Allowed, result.Err = check(ctx, engine, c)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// missingTuples returns the tuples the engine doesn't have, only those are
+// written and deleted by a scenario
+func missingTuples(ctx context.Context, engine Engine, tuples []relation.Tuple) ([]relation.Tuple, error) {
+	existing := map[relation.Tuple]bool{}
+	exported := map[string]bool{}
+	for _, t := range tuples {
+		if exported[t.ResourceType] {
+			continue
+		}
+		exported[t.ResourceType] = true
+		if err := engine.Export(ctx, t.ResourceType, func(e relation.Tuple) error {
+			existing[e] = true
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	var missing []relation.Tuple
+	for _, t := range tuples {
+		if !existing[t] {
+			existing[t] = true
+			missing = append(missing, t)
+		}
+	}
+	return missing, nil
+}
+
+func check(ctx context.Context, engine Engine, c Check) (bool, error) {
+	subjectType, subjectID, ok := strings.Cut(c.Subject, ":")
+	if !ok || subjectType == "" || subjectID == "" {
+		return false, fmt.Errorf("subject %q should be type:id[#relation]", c.Subject)
+	}
+	subjectID, subjectRelation, _ := strings.Cut(subjectID, "#")
+	resourceType, resourceID, ok := strings.Cut(c.Resource, ":")
+	if !ok || resourceType == "" || resourceID == "" {
+		return false, fmt.Errorf("resource %q should be type:id", c.Resource)
+	}
+
+	if len(c.Context) > 0 {
+		var pairs []string
+		for key, value := range c.Context {
+			pairs = append(pairs, key+"="+value)
+		}
+		request, err := relation.ParseCheckContext(pairs)
+		if err != nil {
+			return false, err
+		}
+		ctx = relation.WithCheckContext(ctx, relation.CheckContext{Request: request})
+	}
+	return engine.Check(ctx, relation.Relation{
+		ObjectNamespace:  namespace.Namespace{ID: resourceType},
+		ObjectID:         resourceID,
+		SubjectNamespace: namespace.Namespace{ID: subjectType},
+		SubjectID:        subjectID,
+		SubjectRoleID:    subjectRelation,
+	}, action.Action{ID: c.Permission})
+}
@@ -0,0 +1,97 @@
+package policytest_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/policytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFile = `
+relations:
+  - shield/organization:odpf#owner@shield/user:alice
+  - shield/project:payments#organization@shield/organization:odpf
+scenarios:
+  - name: owners of the organization manage its projects
+    checks:
+      - subject: shield/user:alice
+        resource: shield/project:payments
+        permission: edit
+        allowed: true
+      - subject: shield/user:bob
+        resource: shield/project:payments
+        permission: view
+        allowed: false
+  - name: members of the viewers can't edit
+    relations:
+      - shield/project:payments#viewer@shield/group:data#membership
+      - shield/group:data#member@shield/user:bob
+    checks:
+      - subject: shield/user:bob
+        resource: shield/project:payments
+        permission: view
+        allowed: true
+      - subject: shield/user:bob
+        resource: shield/project:payments
+        permission: edit
+        allowed: true
+`
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "scenarios.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testFile), 0o600))
+	f, err := policytest.Read(path)
+	require.NoError(t, err)
+
+	engine, err := policytest.EphemeralEngine(ctx, f)
+	require.NoError(t, err)
+	kept := relation.Tuple{
+		ResourceType: "shield/group", ResourceID: "data",
+		Relation:    "member",
+		SubjectType: "shield/user", SubjectID: "bob",
+	}
+	require.NoError(t, engine.Import(ctx, []relation.Tuple{kept}))
+
+	results, err := policytest.Run(ctx, engine, f)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	t.Run("should pass the checks getting what they expect", func(t *testing.T) {
+		for _, r := range results[:3] {
+			assert.NoError(t, r.Err)
+			assert.True(t, r.Passed(), "%s: %s", r.Scenario, r.Check)
+		}
+		assert.False(t, results[3].Passed())
+		assert.Equal(t, "shield/user:bob can edit shield/project:payments", results[3].Check.String())
+	})
+
+	t.Run("should leave the relations of the engine as they were", func(t *testing.T) {
+		var tuples []relation.Tuple
+		for _, ns := range []string{"shield/organization", "shield/project", "shield/group"} {
+			require.NoError(t, engine.Export(ctx, ns, func(t relation.Tuple) error {
+				tuples = append(tuples, t)
+				return nil
+			}))
+		}
+		assert.Equal(t, []relation.Tuple{kept}, tuples)
+	})
+}
+
+func TestParse(t *testing.T) {
+	for name, content := range map[string]string{
+		"no scenarios":     `relations: []`,
+		"invalid relation": "relations: [shield/project:payments#viewer]\nscenarios: [{name: s, checks: [{subject: shield/user:bob, resource: shield/project:payments, permission: view, allowed: true}]}]",
+		"no expectation":   "scenarios: [{name: s, checks: [{subject: shield/user:bob, resource: shield/project:payments, permission: view}]}]",
+	} {
+		t.Run("should reject a file with "+name, func(t *testing.T) {
+			_, err := policytest.Parse([]byte(content))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package proxy
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/internal/metrics"
+)
+
+// states of the circuit breaker of a backend
+const (
+	CircuitClosed   = "closed"
+	CircuitHalfOpen = "half_open"
+	CircuitOpen     = "open"
+)
+
+// the retries of a backend are counted against the requests to it over a
+// window, a few retries are let through in every window whatever the budget
+// so a backend with little traffic is still retried
+const (
+	retryBudgetWindow = 10 * time.Second
+	retryBudgetMin    = 3
+)
+
+// ErrCircuitOpen is returned for the requests to a backend whose circuit
+// breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker of the backend is open")
+
+// Breakers keeps the circuit breakers and the retry budgets of the backends
+// of a proxy, the backends are named by their rules
+type Breakers struct {
+	server string
+	now    func() time.Time
+
+	mu       sync.Mutex
+	backends map[string]*backendState
+}
+
+type backendState struct {
+	state    string
+	failures int
+	openedAt time.Time
+	// probing is set while the request let through a half open breaker is
+	// on its way
+	probing bool
+
+	windowStart time.Time
+	requests    int
+	retries     int
+}
+
+// BreakerStatus is the state of the circuit breaker of a backend
+type BreakerStatus struct {
+	Backend  string
+	State    string
+	Failures int
+	OpenedAt time.Time
+}
+
+func NewBreakers(server string) *Breakers {
+	return &Breakers{
+		server:   server,
+		now:      time.Now,
+		backends: make(map[string]*backendState),
+	}
+}
+
+// backendName is the name of the backend of a rule, its target when the
+// backend has no name
+func backendName(backend rule.Backend) string {
+	if backend.Namespace != "" {
+		return backend.Namespace
+	}
+	return backend.URL
+}
+
+func (b *Breakers) backend(name string) *backendState {
+	s, ok := b.backends[name]
+	if !ok {
+		s = &backendState{state: CircuitClosed, windowStart: b.now()}
+		b.backends[name] = s
+	}
+	return s
+}
+
+func (b *Breakers) setState(name string, s *backendState, state string) {
+	s.state = state
+	metrics.CircuitBreakerState(b.server, name, state)
+}
+
+// Allow tells whether a request can be sent to the backend, an open breaker
+// lets a single request through to probe the backend once it cooled down
+func (b *Breakers) Allow(name string, cb rule.CircuitBreaker) bool {
+	if cb.Failures == 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.backend(name)
+	switch s.state {
+	case CircuitOpen:
+		if b.now().Sub(s.openedAt) < cb.CooldownDuration() {
+			metrics.CircuitRejected(b.server, name)
+			return false
+		}
+		b.setState(name, s, CircuitHalfOpen)
+	case CircuitHalfOpen:
+		if s.probing {
+			metrics.CircuitRejected(b.server, name)
+			return false
+		}
+	default:
+		return true
+	}
+	s.probing = true
+	return true
+}
+
+// Done records whether a request the breaker allowed failed, the failures
+// in a row open the breaker and a failed probe opens it again
+func (b *Breakers) Done(name string, cb rule.CircuitBreaker, failed bool) {
+	if cb.Failures == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.backend(name)
+	s.probing = false
+	if !failed {
+		s.failures = 0
+		if s.state != CircuitClosed {
+			b.setState(name, s, CircuitClosed)
+		}
+		return
+	}
+	s.failures++
+	if s.state == CircuitHalfOpen || (s.state == CircuitClosed && s.failures >= cb.Failures) {
+		s.openedAt = b.now()
+		b.setState(name, s, CircuitOpen)
+	}
+}
+
+// Request counts a request to the backend for its retry budget
+func (b *Breakers) Request(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.backend(name)
+	b.roll(s)
+	s.requests++
+}
+
+// Retry tells whether a failed request to the backend can be sent again
+// within the budget of the backend, the retries are not limited when the
+// budget is 0
+func (b *Breakers) Retry(name string, budget float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.backend(name)
+	b.roll(s)
+	if budget > 0 && s.retries >= retryBudgetMin && float64(s.retries+1) > budget*float64(s.requests) {
+		return false
+	}
+	s.retries++
+	metrics.ProxyRetry(b.server, name)
+	return true
+}
+
+func (b *Breakers) roll(s *backendState) {
+	if now := b.now(); now.Sub(s.windowStart) >= retryBudgetWindow {
+		s.windowStart = now
+		s.requests = 0
+		s.retries = 0
+	}
+}
+
+// Status is the state of the circuit breakers of the backends the proxy
+// sent requests to, ordered by backend
+func (b *Breakers) Status() []BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statuses := make([]BreakerStatus, 0, len(b.backends))
+	for name, s := range b.backends {
+		statuses = append(statuses, BreakerStatus{
+			Backend:  name,
+			State:    s.state,
+			Failures: s.failures,
+			OpenedAt: s.openedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Backend < statuses[j].Backend })
+	return statuses
+}
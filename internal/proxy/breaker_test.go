@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/internal/proxy/hook"
+	"github.com/odpf/shield/internal/proxy/middleware"
+)
+
+func TestBreakers(t *testing.T) {
+	cb := rule.CircuitBreaker{Failures: 2, Cooldown: "30s"}
+	now := time.Date(2023, 1, 31, 10, 0, 0, 0, time.UTC)
+	breakers := NewBreakers("test")
+	breakers.now = func() time.Time { return now }
+
+	t.Run("should open after the failures in a row", func(t *testing.T) {
+		assert.True(t, breakers.Allow("entropy", cb))
+		breakers.Done("entropy", cb, true)
+		assert.True(t, breakers.Allow("entropy", cb))
+		breakers.Done("entropy", cb, true)
+
+		assert.False(t, breakers.Allow("entropy", cb))
+		assert.Equal(t, []BreakerStatus{{Backend: "entropy", State: CircuitOpen, Failures: 2, OpenedAt: now}}, breakers.Status())
+	})
+
+	t.Run("should let a single probe through once cooled down", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		assert.True(t, breakers.Allow("entropy", cb))
+		assert.False(t, breakers.Allow("entropy", cb))
+		assert.Equal(t, CircuitHalfOpen, breakers.Status()[0].State)
+	})
+
+	t.Run("should open again when the probe fails", func(t *testing.T) {
+		breakers.Done("entropy", cb, true)
+		assert.False(t, breakers.Allow("entropy", cb))
+	})
+
+	t.Run("should close when the probe succeeds", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		assert.True(t, breakers.Allow("entropy", cb))
+		breakers.Done("entropy", cb, false)
+		assert.True(t, breakers.Allow("entropy", cb))
+		assert.Equal(t, CircuitClosed, breakers.Status()[0].State)
+	})
+
+	t.Run("should never open a disabled breaker", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			assert.True(t, breakers.Allow("siren", rule.CircuitBreaker{}))
+			breakers.Done("siren", rule.CircuitBreaker{}, true)
+		}
+	})
+}
+
+func TestBreakersRetryBudget(t *testing.T) {
+	now := time.Date(2023, 1, 31, 10, 0, 0, 0, time.UTC)
+	breakers := NewBreakers("test")
+	breakers.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		breakers.Request("entropy")
+	}
+	// a few retries are let through whatever the budget, then a retry for
+	// every 5 requests
+	for i := 0; i < retryBudgetMin; i++ {
+		assert.True(t, breakers.Retry("entropy", 0.2))
+	}
+	assert.False(t, breakers.Retry("entropy", 0.2))
+	assert.True(t, breakers.Retry("entropy", 0))
+
+	now = now.Add(retryBudgetWindow)
+	breakers.Request("entropy")
+	assert.True(t, breakers.Retry("entropy", 0.2))
+}
+
+func TestRoundTripPolicy(t *testing.T) {
+	var calls int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/down":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer backend.Close()
+
+	policy := rule.Policy{
+		Timeout:        "50ms",
+		Retry:          rule.Retry{Attempts: 1},
+		CircuitBreaker: rule.CircuitBreaker{Failures: 2, Cooldown: "1m"},
+	}
+	logger := log.NewZap(log.ZapWithNoop())
+	proxy := NewH2c(NewH2cRoundTripper(logger, hook.New(), NewBreakers("test"), nil), NewDirector())
+	send := func(method, path, body, backendName string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(logger.NewContext(req.Context()))
+		middleware.EnrichRule(req, &rule.Rule{Backend: rule.Backend{URL: backend.URL, Namespace: backendName, Policy: policy}})
+		require.NoError(t, middleware.EnrichRequestBody(req))
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("should retry an idempotent request with its body", func(t *testing.T) {
+		rec := send(http.MethodPut, "/flaky", "f1", "entropy")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "f1", rec.Body.String())
+		assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should time out a slow backend", func(t *testing.T) {
+		rec := send(http.MethodGet, "/slow", "", "siren")
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	})
+
+	t.Run("should reject the requests to a backend with an open breaker", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			rec := send(http.MethodPost, "/down", "", "guardian")
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		}
+
+		rec := send(http.MethodPost, "/", "", "guardian")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
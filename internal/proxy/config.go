@@ -1,7 +1,16 @@
 package proxy
 
+import (
+	"github.com/odpf/shield/internal/proxy/token"
+	"github.com/odpf/shield/pkg/tlsutil"
+)
+
 type ServicesConfig struct {
 	Services []Config `yaml:"services" mapstructure:"services"`
+
+	// Token mints a token for the requests the proxies authorized, sent to
+	// their backends in a header
+	Token token.Config `yaml:"token" mapstructure:"token"`
 }
 
 type Config struct {
@@ -18,4 +27,34 @@ type Config struct {
 	// RulesPathSecret could be a env name, file path or actual value required
 	// to access RulesPath files
 	RulesPathSecret string `yaml:"ruleset_secret" mapstructure:"ruleset_secret"`
+
+	// Backends configure how the proxy connects to the backends of its rules,
+	// by the name of the backend
+	Backends []BackendConfig `yaml:"backends" mapstructure:"backends"`
+
+	// TLS serves the proxy over tls on a port of its own
+	TLS tlsutil.Config `yaml:"tls" mapstructure:"tls"`
+}
+
+type BackendConfig struct {
+	Name string     `yaml:"name" mapstructure:"name"`
+	TLS  *TLSConfig `yaml:"tls" mapstructure:"tls"`
+}
+
+// TLSConfig is the client certificate the proxy presents to a backend and
+// the certificate authority the certificate of the backend is verified with,
+// the files are loaded again when they change
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" mapstructure:"cert_file"`
+	KeyFile  string `yaml:"key_file" mapstructure:"key_file"`
+	// CAFile is the certificate authority of the backend, the system
+	// certificate authorities when not set
+	CAFile string `yaml:"ca_file" mapstructure:"ca_file"`
+	// ServerName is the name the certificate of the backend is verified for,
+	// the host of the target of the backend when not set
+	ServerName string `yaml:"server_name" mapstructure:"server_name"`
+	// SPIFFEID is the spiffe id the certificate of the backend should have,
+	// like spiffe://odpf.io/ns/default/sa/entropy, the certificate is then
+	// verified for the id instead of the server name
+	SPIFFEID string `yaml:"spiffe_id" mapstructure:"spiffe_id"`
 }
@@ -2,16 +2,20 @@ package proxy
 
 import (
 	"context"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/odpf/shield/core/rule"
 	"github.com/odpf/shield/internal/proxy/middleware"
 	"github.com/odpf/shield/pkg/httputil"
 )
 
 var ctxRequestErrorKey = struct{}{}
 
+type ctxSplitKey struct{}
+
 type Director struct {
 }
 
@@ -22,8 +26,14 @@ func NewDirector() *Director {
 func (h Director) Direct(req *http.Request) {
 	matchedRule, _ := middleware.ExtractRule(req)
 
+	backendURL := matchedRule.Backend.URL
+	if split, ok := pickSplit(req, matchedRule.Backend.Splits); ok {
+		backendURL = split.Target
+		*req = *req.WithContext(context.WithValue(req.Context(), ctxSplitKey{}, split.SplitName()))
+	}
+
 	// update backend request to match rules
-	target, err := url.Parse(matchedRule.Backend.URL)
+	target, err := url.Parse(backendURL)
 	if err != nil {
 		// backend is not configured properly
 		*req = *req.WithContext(context.WithValue(req.Context(), ctxRequestErrorKey, err))
@@ -49,6 +59,58 @@ func (h Director) Direct(req *http.Request) {
 	req.Header.Set("proxy-by", "shield")
 }
 
+// pickSplit picks the split of a backend the request goes to, a request with
+// the header or the cookie of a split goes to it and the other requests are
+// split by weight, the requests left go to the target of the backend
+func pickSplit(req *http.Request, splits []rule.Split) (rule.Split, bool) {
+	if len(splits) == 0 {
+		return rule.Split{}, false
+	}
+	for _, s := range splits {
+		if pinned(req, s) {
+			return s, true
+		}
+	}
+	n := rand.Intn(100)
+	for _, s := range splits {
+		if n < s.Weight {
+			return s, true
+		}
+		n -= s.Weight
+	}
+	return rule.Split{}, false
+}
+
+func pinned(req *http.Request, s rule.Split) bool {
+	if s.Header != nil {
+		if values, ok := req.Header[http.CanonicalHeaderKey(s.Header.Name)]; ok && (s.Header.Value == "" || contains(values, s.Header.Value)) {
+			return true
+		}
+	}
+	if s.Cookie != nil {
+		if cookie, err := req.Cookie(s.Cookie.Name); err == nil && (s.Cookie.Value == "" || cookie.Value == s.Cookie.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// splitName is the name of the split of its backend the director sent the
+// request to
+func splitName(req *http.Request) (string, bool) {
+	name, ok := req.Context().Value(ctxSplitKey{}).(string)
+	return name, ok
+}
+
 func joinURLPath(a, b *url.URL) (path, rawpath string) {
 	if a.RawPath == "" && b.RawPath == "" {
 		return singleJoiningSlash(a.Path, b.Path), ""
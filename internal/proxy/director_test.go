@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/internal/proxy/middleware"
+)
+
+func TestDirectSplits(t *testing.T) {
+	canary := rule.Split{
+		Name:   "canary",
+		Target: "http://entropy-canary:3000",
+		Header: &rule.Match{Name: "X-Canary", Value: "true"},
+		Cookie: &rule.Match{Name: "canary"},
+	}
+
+	table := []struct {
+		title     string
+		split     rule.Split
+		header    http.Header
+		cookie    *http.Cookie
+		wantHost  string
+		wantSplit string
+	}{
+		{
+			title:    "should send the requests to the target of the backend",
+			split:    canary,
+			wantHost: "entropy:3000",
+		},
+		{
+			title:     "should send a request with the header of a split to it",
+			split:     canary,
+			header:    http.Header{"X-Canary": []string{"true"}},
+			wantHost:  "entropy-canary:3000",
+			wantSplit: "canary",
+		},
+		{
+			title:    "should not send a request with another value of the header to the split",
+			split:    canary,
+			header:   http.Header{"X-Canary": []string{"false"}},
+			wantHost: "entropy:3000",
+		},
+		{
+			title:     "should send a request with the cookie of a split to it",
+			split:     canary,
+			cookie:    &http.Cookie{Name: "canary", Value: "1"},
+			wantHost:  "entropy-canary:3000",
+			wantSplit: "canary",
+		},
+		{
+			title:     "should send the requests to a split taking all of them",
+			split:     rule.Split{Target: "http://entropy-v2:3000", Weight: 100},
+			wantHost:  "entropy-v2:3000",
+			wantSplit: "http://entropy-v2:3000",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.title, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/firehoses", nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			middleware.EnrichRule(req, &rule.Rule{Backend: rule.Backend{
+				URL:    "http://entropy:3000",
+				Splits: []rule.Split{tt.split},
+			}})
+
+			NewDirector().Direct(req)
+			assert.Equal(t, tt.wantHost, req.URL.Host)
+			split, _ := splitName(req)
+			assert.Equal(t, tt.wantSplit, split)
+		})
+	}
+}
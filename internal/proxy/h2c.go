@@ -1,9 +1,13 @@
 package proxy
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httputil"
 	"time"
+
+	"github.com/odpf/salt/log"
+	"go.uber.org/zap"
 )
 
 type RequestDirector interface {
@@ -22,7 +26,7 @@ type H2c struct {
 
 func NewH2c(roundTripper http.RoundTripper, director RequestDirector) *H2c {
 	return &H2c{
-		proxy:        &httputil.ReverseProxy{},
+		proxy:        &httputil.ReverseProxy{ErrorHandler: errorHandler},
 		bufferPool:   newBufferPool(),
 		roundTripper: roundTripper,
 		director:     director,
@@ -38,3 +42,19 @@ func (p *H2c) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	p.proxy.Director = p.director.Direct
 	p.proxy.ServeHTTP(w, r)
 }
+
+// errorHandler replies to the requests the backend didn't answer, a backend
+// whose circuit breaker is open is unavailable and a backend which didn't
+// answer within its timeout is a gateway timeout
+func errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	code := http.StatusBadGateway
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		code = http.StatusServiceUnavailable
+	case timedOut(err):
+		code = http.StatusGatewayTimeout
+	}
+	log.ZapFromContext(r.Context()).GetInternalZapLogger().Desugar().Warn("request_failed",
+		zap.String("path", r.URL.Path), zap.Int("status", code), zap.Error(err))
+	w.WriteHeader(code)
+}
@@ -78,7 +78,7 @@ func (a Authz) Info() hook.Info {
 }
 
 func (a Authz) ServeHook(res *http.Response, err error) (*http.Response, error) {
-	if err != nil || res.StatusCode >= 400 {
+	if err != nil || hook.Failed(res) {
 		return a.escape.ServeHook(res, err)
 	}
 
@@ -148,6 +148,20 @@ func (a Authz) ServeHook(res *http.Response, err error) (*http.Response, error)
 			}
 			attributes[id] = payloadField
 
+			a.log.Info("middleware: extracted", "field", payloadField, "attr", attr)
+		case hook.AttributeTypeFormPayload:
+			if attr.Key == "" {
+				a.log.Error("middleware: payload key field empty")
+				return a.escape.ServeHook(res, fmt.Errorf("payload key field empty"))
+			}
+
+			payloadField, err := body_extractor.FormPayloadHandler{ContentType: headerSource.Get("Content-Type")}.Extract(bodySource, attr.Key)
+			if err != nil {
+				a.log.Error("middleware: failed to parse form payload", "err", err)
+				return a.escape.ServeHook(res, fmt.Errorf("failed to parse form payload"))
+			}
+			attributes[id] = payloadField
+
 			a.log.Info("middleware: extracted", "field", payloadField, "attr", attr)
 		case hook.AttributeTypeHeader:
 			if attr.Key == "" {
@@ -202,6 +216,7 @@ func (a Authz) ServeHook(res *http.Response, err error) (*http.Response, error)
 		a.log.Error(err.Error())
 		return a.escape.ServeHook(res, fmt.Errorf(err.Error()))
 	}
+	var created []resource.Resource
 	for _, resource := range resources {
 		newResource, err := a.resourceService.Create(res.Request.Context(), resource)
 		if err != nil {
@@ -209,6 +224,7 @@ func (a Authz) ServeHook(res *http.Response, err error) (*http.Response, error)
 			return a.escape.ServeHook(res, fmt.Errorf(err.Error()))
 		}
 		a.log.Info(fmt.Sprintf("Resource %s created with ID %s", newResource.URN, newResource.Idxa))
+		created = append(created, newResource)
 
 		for _, rel := range config.Relations {
 			subjectId, err := getAttributesValues(attributes[rel.SubjectIDAttribute])
@@ -236,6 +252,7 @@ func (a Authz) ServeHook(res *http.Response, err error) (*http.Response, error)
 			a.log.Info(fmt.Sprintf("created relation: %s for %s %s", newRelation.Subject.RoleID, newRelation.Subject.ID, newRelation.Subject.Namespace))
 		}
 	}
+	hook.EnrichCreatedResources(res, created)
 
 	return a.next.ServeHook(res, nil)
 }
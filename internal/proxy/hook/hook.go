@@ -1,8 +1,10 @@
 package hook
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/odpf/shield/core/resource"
 	"github.com/odpf/shield/core/rule"
 	"github.com/odpf/shield/internal/proxy/middleware"
 )
@@ -20,6 +22,7 @@ type Info struct {
 const (
 	AttributeTypeJSONPayload AttributeType = "json_payload"
 	AttributeTypeGRPCPayload AttributeType = "grpc_payload"
+	AttributeTypeFormPayload AttributeType = "form_payload"
 	AttributeTypeQuery       AttributeType = "query"
 	AttributeTypeHeader      AttributeType = "header"
 	AttributeTypeConstant    AttributeType = "constant"
@@ -38,6 +41,16 @@ type Attribute struct {
 	Value  string        `yaml:"value" mapstructure:"value"`
 }
 
+// Failed tells the backend failed the request, gRPC backends respond to a
+// failed call with an ok http status and the grpc-status of the error
+func Failed(res *http.Response) bool {
+	if res.StatusCode >= 400 {
+		return true
+	}
+	status := res.Header.Get("Grpc-Status")
+	return status != "" && status != "0"
+}
+
 func ExtractHook(r *http.Request, name string) (rule.HookSpec, bool) {
 	rl, ok := ExtractRule(r)
 	if !ok {
@@ -55,6 +68,20 @@ func ExtractRule(r *http.Request) (*rule.Rule, bool) {
 	return rl, true
 }
 
+type contextCreatedResourcesKey struct{}
+
+// EnrichCreatedResources keeps the resources recorded for a response on its
+// request for the hooks after the one which created them
+func EnrichCreatedResources(res *http.Response, resources []resource.Resource) {
+	created, _ := ExtractCreatedResources(res.Request)
+	res.Request = res.Request.WithContext(context.WithValue(res.Request.Context(), contextCreatedResourcesKey{}, append(created, resources...)))
+}
+
+func ExtractCreatedResources(r *http.Request) ([]resource.Resource, bool) {
+	created, ok := r.Context().Value(contextCreatedResourcesKey{}).([]resource.Resource)
+	return created, ok
+}
+
 type Hook struct{}
 
 func New() Hook {
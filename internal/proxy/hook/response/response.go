@@ -0,0 +1,327 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/proxy/hook"
+	"github.com/odpf/shield/internal/proxy/middleware"
+	"github.com/odpf/shield/pkg/body_extractor"
+)
+
+// the attributes of the resources created for the response by the hooks
+// before this one, like the authz hook
+const (
+	AttributeResourceID  = "resource_id"
+	AttributeResourceURN = "resource_urn"
+)
+
+type ResourceService interface {
+	CheckAuthz(ctx context.Context, resource resource.Resource, act action.Action) (bool, error)
+}
+
+// Response enriches the responses of the backends before they are sent back
+// to the caller, it sets headers to the attributes of the request and of the
+// response and removes the fields of json responses the caller isn't allowed
+// to see
+type Response struct {
+	log log.Logger
+
+	// To go to next hook
+	next hook.Service
+
+	// To skip all the next hooks and just respond back
+	escape hook.Service
+
+	identityProxyHeaderKey string
+
+	resourceService ResourceService
+}
+
+func New(log log.Logger, next, escape hook.Service, resourceService ResourceService, identityProxyHeaderKey string) Response {
+	return Response{
+		log:                    log,
+		next:                   next,
+		escape:                 escape,
+		resourceService:        resourceService,
+		identityProxyHeaderKey: identityProxyHeaderKey,
+	}
+}
+
+type Config struct {
+	// Headers are set on the response to the value of the attribute of their
+	// name, values of a list are joined by commas
+	Headers    map[string]string         `yaml:"headers" mapstructure:"headers"`
+	Attributes map[string]hook.Attribute `yaml:"attributes" mapstructure:"attributes"`
+	Redact     []Redaction               `yaml:"redact" mapstructure:"redact"`
+}
+
+// Redaction removes the fields of a json response unless the caller has the
+// permission on the resources named by the attribute of the permission, a
+// field is a path of keys joined by dots and lists on the path are walked
+// through
+type Redaction struct {
+	Fields     []string   `yaml:"fields" mapstructure:"fields"`
+	Permission Permission `yaml:"permission" mapstructure:"permission"`
+}
+
+type Permission struct {
+	Name      string `yaml:"name" mapstructure:"name"`
+	Namespace string `yaml:"namespace" mapstructure:"namespace"`
+	Attribute string `yaml:"attribute" mapstructure:"attribute"`
+}
+
+func (h Response) Info() hook.Info {
+	return hook.Info{
+		Name:        "response",
+		Description: "hook to enrich the response and redact the fields the caller isn't allowed to see",
+	}
+}
+
+func (h Response) ServeHook(res *http.Response, err error) (*http.Response, error) {
+	if err != nil || hook.Failed(res) {
+		return h.escape.ServeHook(res, err)
+	}
+
+	hookSpec, ok := hook.ExtractHook(res.Request, h.Info().Name)
+	if !ok {
+		return h.next.ServeHook(res, nil)
+	}
+
+	// fields are not left in the response because the config is broken
+	config := Config{}
+	if err := mapstructure.Decode(hookSpec.Config, &config); err != nil {
+		h.log.Error("hook: failed to decode response config", "config", hookSpec.Config)
+		return h.fail(res, fmt.Errorf("invalid response hook config"))
+	}
+
+	attributes := map[string]interface{}{}
+	if created, ok := hook.ExtractCreatedResources(res.Request); ok {
+		var ids, urns []string
+		for _, r := range created {
+			ids = append(ids, r.Idxa)
+			urns = append(urns, r.URN)
+		}
+		attributes[AttributeResourceID] = ids
+		attributes[AttributeResourceURN] = urns
+	}
+	paramMap, _ := middleware.ExtractPathParams(res.Request)
+	for key, value := range paramMap {
+		attributes[key] = value
+	}
+	for id, attr := range config.Attributes {
+		value, err := extractAttribute(res, attr)
+		if err != nil {
+			h.log.Error("hook: failed to extract attribute", "attr", attr, "err", err)
+			return h.fail(res, err)
+		}
+		attributes[id] = value
+	}
+
+	for name, attr := range config.Headers {
+		values, ok := attributeValues(attributes[attr])
+		if !ok || len(values) == 0 {
+			h.log.Warn("hook: attribute of response header not found", "header", name, "attribute", attr)
+			continue
+		}
+		res.Header.Set(name, strings.Join(values, ","))
+	}
+
+	if len(config.Redact) > 0 {
+		ctx := user.SetContextWithEmail(res.Request.Context(), res.Request.Header.Get(h.identityProxyHeaderKey))
+		var fields [][]string
+		for _, redaction := range config.Redact {
+			allowed, err := h.allowed(ctx, redaction.Permission, attributes)
+			if err != nil {
+				h.log.Error("hook: failed to check the permission of redacted fields", "permission", redaction.Permission.Name, "err", err)
+				return h.fail(res, err)
+			}
+			if allowed {
+				continue
+			}
+			for _, field := range redaction.Fields {
+				fields = append(fields, strings.Split(field, "."))
+			}
+		}
+		if err := redact(res, fields); err != nil {
+			h.log.Error("hook: failed to redact response", "err", err)
+			return h.fail(res, err)
+		}
+	}
+
+	return h.next.ServeHook(res, nil)
+}
+
+// fail drops the body of the response before escaping, the fields which
+// should have been redacted are not sent back along with the error
+func (h Response) fail(res *http.Response, err error) (*http.Response, error) {
+	if res.Body != nil {
+		res.Body.Close()
+	}
+	res.Body = http.NoBody
+	res.ContentLength = 0
+	res.Header.Del("Content-Length")
+	return h.escape.ServeHook(res, err)
+}
+
+// allowed checks the caller has the permission on every resource named by
+// the attribute of the permission
+func (h Response) allowed(ctx context.Context, permission Permission, attributes map[string]interface{}) (bool, error) {
+	names, ok := attributeValues(attributes[permission.Attribute])
+	if !ok || len(names) == 0 {
+		return false, fmt.Errorf("attribute %s of permission %s not found", permission.Attribute, permission.Name)
+	}
+	for _, name := range names {
+		allowed, err := h.resourceService.CheckAuthz(ctx, resource.Resource{
+			Name:        name,
+			NamespaceID: permission.Namespace,
+		}, action.Action{ID: permission.Name})
+		if err != nil {
+			return false, err
+		}
+		if !allowed {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// extractAttribute reads an attribute of the response, or of the request
+// when its source is the request
+func extractAttribute(res *http.Response, attr hook.Attribute) (interface{}, error) {
+	fromRequest := attr.Source == string(hook.SourceRequest)
+	switch attr.Type {
+	case hook.AttributeTypeJSONPayload:
+		if attr.Key == "" {
+			return nil, fmt.Errorf("payload key field empty")
+		}
+		if fromRequest {
+			body, ok := middleware.ExtractRequestBody(res.Request)
+			if !ok {
+				return nil, fmt.Errorf("request body not found")
+			}
+			return body_extractor.JSONPayloadHandler{}.Extract(&body, attr.Key)
+		}
+		return body_extractor.JSONPayloadHandler{}.Extract(&res.Body, attr.Key)
+	case hook.AttributeTypeFormPayload:
+		if attr.Key == "" {
+			return nil, fmt.Errorf("payload key field empty")
+		}
+		if fromRequest {
+			body, ok := middleware.ExtractRequestBody(res.Request)
+			if !ok {
+				return nil, fmt.Errorf("request body not found")
+			}
+			return body_extractor.FormPayloadHandler{ContentType: res.Request.Header.Get("Content-Type")}.Extract(&body, attr.Key)
+		}
+		return body_extractor.FormPayloadHandler{ContentType: res.Header.Get("Content-Type")}.Extract(&res.Body, attr.Key)
+	case hook.AttributeTypeHeader:
+		header := res.Header
+		if fromRequest {
+			header = res.Request.Header
+		}
+		if value := header.Get(attr.Key); value != "" {
+			return value, nil
+		}
+		return nil, fmt.Errorf("header %s is empty", attr.Key)
+	case hook.AttributeTypeQuery:
+		if value := res.Request.URL.Query().Get(attr.Key); value != "" {
+			return value, nil
+		}
+		return nil, fmt.Errorf("query %s is empty", attr.Key)
+	case hook.AttributeTypeConstant:
+		if attr.Value == "" {
+			return nil, fmt.Errorf("constant value empty")
+		}
+		return attr.Value, nil
+	default:
+		return nil, fmt.Errorf("unsupported attribute type: %v", attr.Type)
+	}
+}
+
+func attributeValues(attribute interface{}) ([]string, bool) {
+	switch v := attribute.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []interface{}:
+		var values []string
+		for _, i := range v {
+			values = append(values, fmt.Sprint(i))
+		}
+		return values, true
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, true
+	default:
+		return []string{fmt.Sprint(v)}, true
+	}
+}
+
+// redact removes the fields from the json body of the response, a response
+// without a body is left as it is
+func redact(res *http.Response, fields [][]string) error {
+	if len(fields) == 0 || res.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if len(bytes.TrimSpace(body)) == 0 {
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		return nil
+	}
+	if !strings.Contains(res.Header.Get("Content-Type"), "json") {
+		return fmt.Errorf("fields of a %q response can't be redacted", res.Header.Get("Content-Type"))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var payload interface{}
+	if err := dec.Decode(&payload); err != nil {
+		return fmt.Errorf("failed to parse json response: %w", err)
+	}
+	for _, path := range fields {
+		removeField(payload, path)
+	}
+	redacted, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(redacted))
+	res.ContentLength = int64(len(redacted))
+	res.Header.Set("Content-Length", strconv.Itoa(len(redacted)))
+	return nil
+}
+
+func removeField(payload interface{}, path []string) {
+	switch v := payload.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(v, path[0])
+			return
+		}
+		removeField(v[path[0]], path[1:])
+	case []interface{}:
+		for _, item := range v {
+			removeField(item, path)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/internal/proxy/hook"
+	"github.com/odpf/shield/internal/proxy/middleware"
+	shieldlogger "github.com/odpf/shield/pkg/logger"
+)
+
+type resourceService struct {
+	allowed map[string]bool
+	err     error
+}
+
+func (s resourceService) CheckAuthz(ctx context.Context, r resource.Resource, act action.Action) (bool, error) {
+	return s.allowed[r.NamespaceID+"/"+r.Name+"/"+act.ID], s.err
+}
+
+func newResponse(t *testing.T, config map[string]interface{}, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/api/firehoses/f1?project=p1", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Shield-Email", "user@odpf.io")
+	middleware.EnrichRule(req, &rule.Rule{
+		Hooks: rule.HookSpecs{{Name: "response", Config: config}},
+	})
+	middleware.EnrichPathParams(req, map[string]string{"firehose": "f1"})
+
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func TestServeHook(t *testing.T) {
+	t.Parallel()
+
+	firehoses := `{"firehoses":[{"name":"f1","config":{"password":"secret","topic":"t1"}},{"name":"f2","config":{"password":"hidden"}}],"size":2}`
+
+	table := []struct {
+		title       string
+		config      map[string]interface{}
+		created     []resource.Resource
+		service     resourceService
+		contentType string
+		body        string
+		wantBody    string
+		wantHeads   map[string]string
+		wantErr     bool
+	}{
+		{
+			title: "should set headers to the created resources and the attributes",
+			config: map[string]interface{}{
+				"headers": map[string]interface{}{
+					"X-Resource-Id":  "resource_id",
+					"X-Resource-Urn": "resource_urn",
+					"X-Project":      "project",
+					"X-Firehose":     "firehose",
+				},
+				"attributes": map[string]interface{}{
+					"project": map[string]interface{}{"key": "project", "type": "query"},
+				},
+			},
+			created: []resource.Resource{
+				{Idxa: "id1", URN: "r/ns/f1"},
+				{Idxa: "id2", URN: "r/ns/f2"},
+			},
+			body:     firehoses,
+			wantBody: firehoses,
+			wantHeads: map[string]string{
+				"X-Resource-Id":  "id1,id2",
+				"X-Resource-Urn": "r/ns/f1,r/ns/f2",
+				"X-Project":      "p1",
+				"X-Firehose":     "f1",
+			},
+		},
+		{
+			title: "should redact the fields the caller isn't allowed to see",
+			config: map[string]interface{}{
+				"redact": []interface{}{
+					map[string]interface{}{
+						"fields":     []interface{}{"firehoses.config.password", "size"},
+						"permission": map[string]interface{}{"name": "update", "namespace": "entropy/firehose", "attribute": "firehose"},
+					},
+				},
+			},
+			body:     firehoses,
+			wantBody: `{"firehoses":[{"config":{"topic":"t1"},"name":"f1"},{"config":{},"name":"f2"}]}`,
+		},
+		{
+			title: "should keep the fields the caller is allowed to see",
+			config: map[string]interface{}{
+				"redact": []interface{}{
+					map[string]interface{}{
+						"fields":     []interface{}{"firehoses.config.password"},
+						"permission": map[string]interface{}{"name": "update", "namespace": "entropy/firehose", "attribute": "firehose"},
+					},
+				},
+			},
+			service:  resourceService{allowed: map[string]bool{"entropy/firehose/f1/update": true}},
+			body:     firehoses,
+			wantBody: firehoses,
+		},
+		{
+			title: "should fail when the permission can't be checked",
+			config: map[string]interface{}{
+				"redact": []interface{}{
+					map[string]interface{}{
+						"fields":     []interface{}{"size"},
+						"permission": map[string]interface{}{"name": "update", "namespace": "entropy/firehose", "attribute": "firehose"},
+					},
+				},
+			},
+			service: resourceService{err: errors.New("unavailable")},
+			body:    firehoses,
+			wantErr: true,
+		},
+		{
+			title: "should fail when the attribute of the permission is missing",
+			config: map[string]interface{}{
+				"redact": []interface{}{
+					map[string]interface{}{
+						"fields":     []interface{}{"size"},
+						"permission": map[string]interface{}{"name": "update", "namespace": "entropy/firehose", "attribute": "missing"},
+					},
+				},
+			},
+			body:    firehoses,
+			wantErr: true,
+		},
+		{
+			title: "should fail to redact a response which isn't json",
+			config: map[string]interface{}{
+				"redact": []interface{}{
+					map[string]interface{}{
+						"fields":     []interface{}{"size"},
+						"permission": map[string]interface{}{"name": "update", "namespace": "entropy/firehose", "attribute": "firehose"},
+					},
+				},
+			},
+			contentType: "text/plain",
+			body:        "size=2",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range table {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			rootHook := hook.New()
+			h := New(shieldlogger.InitLogger(shieldlogger.Config{}), rootHook, rootHook, tt.service, "X-Shield-Email")
+
+			res := newResponse(t, tt.config, tt.body)
+			if tt.contentType != "" {
+				res.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.created != nil {
+				hook.EnrichCreatedResources(res, tt.created)
+			}
+
+			got, err := h.ServeHook(res, nil)
+			assert.NoError(t, err)
+
+			body, err := io.ReadAll(got.Body)
+			assert.NoError(t, err)
+			if tt.wantErr {
+				assert.Equal(t, http.StatusInternalServerError, got.StatusCode)
+				assert.Empty(t, body)
+				return
+			}
+			assert.JSONEq(t, tt.wantBody, string(body))
+			assert.Equal(t, int64(len(body)), got.ContentLength)
+			for key, value := range tt.wantHeads {
+				assert.Equal(t, value, got.Header.Get(key))
+			}
+		})
+	}
+}
+
+func TestServeHookWithoutSpec(t *testing.T) {
+	t.Parallel()
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/api/firehoses", nil)
+	assert.NoError(t, err)
+	res := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"size":2}`)), Request: req}
+
+	rootHook := hook.New()
+	h := New(shieldlogger.InitLogger(shieldlogger.Config{}), rootHook, rootHook, resourceService{}, "X-Shield-Email")
+	got, err := h.ServeHook(res, nil)
+	assert.NoError(t, err)
+
+	body, err := io.ReadAll(got.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"size":2}`, string(body))
+}
@@ -5,6 +5,7 @@ const (
 	AttributeTypeHeader      AttributeType = "header"
 	AttributeTypeJSONPayload AttributeType = "json_payload"
 	AttributeTypeGRPCPayload AttributeType = "grpc_payload"
+	AttributeTypeFormPayload AttributeType = "form_payload"
 	AttributeTypePathParam   AttributeType = "path_param"
 	AttributeTypeConstant    AttributeType = "constant"
 )
@@ -95,7 +95,7 @@ func (a *Attributes) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			}
 
 			// TODO: we can optimise this by parsing all field at once
-			payloadField, err := body_extractor.GRPCPayloadHandler{}.Extract(&req.Body, attr.Index)
+			payloadField, err := body_extractor.GRPCPayloadHandler{}.Extract(middleware.RequestBody(req), attr.Index)
 			if err != nil {
 				a.log.Error("middleware: failed to parse grpc payload", "err", err)
 				a.notAllowed(rw)
@@ -111,7 +111,7 @@ func (a *Attributes) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 				a.notAllowed(rw)
 				return
 			}
-			payloadField, err := body_extractor.JSONPayloadHandler{}.Extract(&req.Body, attr.Key)
+			payloadField, err := body_extractor.JSONPayloadHandler{}.Extract(middleware.RequestBody(req), attr.Key)
 			if err != nil {
 				a.log.Error("middleware: failed to parse grpc payload", "err", err)
 				a.notAllowed(rw)
@@ -121,6 +121,22 @@ func (a *Attributes) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			requestAttributes[res] = payloadField
 			a.log.Info("middleware: extracted", "field", payloadField, "attr", attr)
 
+		case middleware.AttributeTypeFormPayload:
+			if attr.Key == "" {
+				a.log.Error("middleware: payload key field empty")
+				a.notAllowed(rw)
+				return
+			}
+			payloadField, err := body_extractor.FormPayloadHandler{ContentType: req.Header.Get("Content-Type")}.Extract(middleware.RequestBody(req), attr.Key)
+			if err != nil {
+				a.log.Error("middleware: failed to parse form payload", "err", err)
+				a.notAllowed(rw)
+				return
+			}
+
+			requestAttributes[res] = payloadField
+			a.log.Info("middleware: extracted", "field", payloadField, "attr", attr)
+
 		case middleware.AttributeTypeHeader:
 			if attr.Key == "" {
 				a.log.Error("middleware: header key field empty")
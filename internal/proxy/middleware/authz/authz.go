@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/odpf/salt/log"
 
 	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/group"
 	"github.com/odpf/shield/core/resource"
 	"github.com/odpf/shield/core/user"
 	"github.com/odpf/shield/internal/proxy/middleware"
+	"github.com/odpf/shield/internal/proxy/middleware/attributes"
+	"github.com/odpf/shield/internal/proxy/token"
 	"github.com/odpf/shield/pkg/body_extractor"
 )
 
@@ -24,18 +28,32 @@ type UserService interface {
 	FetchCurrentUser(ctx context.Context) (user.User, error)
 }
 
+type GroupService interface {
+	ListUserGroups(ctx context.Context, userId string, roleId string) ([]group.Group, error)
+}
+
 type Authz struct {
 	log             log.Logger
 	userIDHeaderKey string
 	next            http.Handler
 	resourceService ResourceService
 	userService     UserService
+	groupService    GroupService
+	// minter mints the tokens of the authorized requests for the backends,
+	// no token is minted when it is nil
+	minter *token.Minter
 }
 
 type Config struct {
 	Actions     []string                        `yaml:"actions" mapstructure:"actions"`
 	Permissions []Permission                    `yaml:"permissions" mapstructure:"permissions"`
 	Attributes  map[string]middleware.Attribute `yaml:"attributes" mapstructure:"attributes"`
+
+	// Recheck is how often the permissions of a websocket or a server-sent
+	// events connection are checked again, like 5m, the connection is closed
+	// once they are revoked. They are only checked at the handshake when it
+	// is not set.
+	Recheck string `yaml:"recheck" mapstructure:"recheck"`
 }
 
 type Permission struct {
@@ -49,13 +67,17 @@ func New(
 	next http.Handler,
 	userIDHeaderKey string,
 	resourceService ResourceService,
-	userService UserService) *Authz {
+	userService UserService,
+	groupService GroupService,
+	minter *token.Minter) *Authz {
 	return &Authz{
 		log:             log,
 		userIDHeaderKey: userIDHeaderKey,
 		next:            next,
 		resourceService: resourceService,
 		userService:     userService,
+		groupService:    groupService,
+		minter:          minter,
 	}
 }
 
@@ -67,6 +89,12 @@ func (c Authz) Info() *middleware.MiddlewareInfo {
 }
 
 func (c *Authz) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// the backends trust the token header, only the tokens minted here are
+	// sent to them
+	if c.minter != nil {
+		req.Header.Del(c.minter.Header())
+	}
+
 	usr, err := c.userService.FetchCurrentUser(req.Context())
 	if err != nil {
 		c.log.Error("middleware: failed to get user details", "err", err.Error())
@@ -121,7 +149,7 @@ func (c *Authz) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			}
 
 			// TODO: we can optimise this by parsing all field at once
-			payloadField, err := body_extractor.GRPCPayloadHandler{}.Extract(&req.Body, attr.Index)
+			payloadField, err := body_extractor.GRPCPayloadHandler{}.Extract(middleware.RequestBody(req), attr.Index)
 			if err != nil {
 				c.log.Error("middleware: failed to parse grpc payload", "err", err)
 				return
@@ -136,7 +164,7 @@ func (c *Authz) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 				c.notAllowed(rw)
 				return
 			}
-			payloadField, err := body_extractor.JSONPayloadHandler{}.Extract(&req.Body, attr.Key)
+			payloadField, err := body_extractor.JSONPayloadHandler{}.Extract(middleware.RequestBody(req), attr.Key)
 			if err != nil {
 				c.log.Error("middleware: failed to parse grpc payload", "err", err)
 				c.notAllowed(rw)
@@ -146,6 +174,22 @@ func (c *Authz) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			permissionAttributes[res] = payloadField
 			c.log.Info("middleware: extracted", "field", payloadField, "attr", attr)
 
+		case middleware.AttributeTypeFormPayload:
+			if attr.Key == "" {
+				c.log.Error("middleware: payload key field empty")
+				c.notAllowed(rw)
+				return
+			}
+			payloadField, err := body_extractor.FormPayloadHandler{ContentType: req.Header.Get("Content-Type")}.Extract(middleware.RequestBody(req), attr.Key)
+			if err != nil {
+				c.log.Error("middleware: failed to parse form payload", "err", err)
+				c.notAllowed(rw)
+				return
+			}
+
+			permissionAttributes[res] = payloadField
+			c.log.Info("middleware: extracted", "field", payloadField, "attr", attr)
+
 		case middleware.AttributeTypeHeader:
 			if attr.Key == "" {
 				c.log.Error("middleware: header key field empty")
@@ -206,32 +250,120 @@ func (c *Authz) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		permissionAttributes[key] = value
 	}
 
-	isAuthorized := false
-	for _, permission := range config.Permissions {
-		isAuthorized, err = c.resourceService.CheckAuthz(req.Context(), resource.Resource{
+	permission, isAuthorized, err := c.authorized(req.Context(), config.Permissions, permissionAttributes)
+	if err != nil {
+		c.log.Error("error while creating resource obj", "err", err)
+		c.notAllowed(rw)
+		return
+	}
+
+	c.log.Info("authz check successful", "user", permissionAttributes["user"], "resource", permissionAttributes["resource"], "result", isAuthorized)
+	if !isAuthorized {
+		c.log.Info("user not allowed to make request", "user", permissionAttributes["user"], "resource", permissionAttributes["resource"], "result", isAuthorized)
+		c.notAllowed(rw)
+		return
+	}
+
+	if config.Recheck != "" && middleware.Streaming(req) {
+		interval, err := time.ParseDuration(config.Recheck)
+		if err != nil || interval <= 0 {
+			c.log.Error("middleware: invalid authz recheck interval", "recheck", config.Recheck)
+			c.notAllowed(rw)
+			return
+		}
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+		go c.recheck(ctx, cancel, interval, config.Permissions, permissionAttributes)
+		req = req.WithContext(ctx)
+	}
+
+	if c.minter != nil {
+		tkn, err := c.mint(req, usr, permission, permissionAttributes)
+		if err != nil {
+			c.log.Error("middleware: failed to mint token", "err", err)
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		req.Header.Set(c.minter.Header(), tkn)
+	}
+
+	c.next.ServeHTTP(rw, req)
+}
+
+// mint mints the token of the request for the backend with the user, the
+// organization of the request, the groups of the user in it and the
+// permission the request was authorized with
+func (c Authz) mint(req *http.Request, usr user.User, permission Permission, permissionAttributes map[string]interface{}) (string, error) {
+	claims := token.Claims{
+		Subject: usr.ID,
+		Email:   usr.Email,
+		Permission: token.Permission{
+			Name:      permission.Name,
+			Namespace: permission.Namespace,
+			Resource:  permissionAttributes[permission.Attribute].(string),
+		},
+	}
+	if requestAttributes, ok := attributes.GetAttributesFromContext(req.Context()); ok {
+		claims.Organization, _ = requestAttributes["organization"].(string)
+	}
+
+	groups, err := c.groupService.ListUserGroups(req.Context(), usr.ID, "")
+	if err != nil {
+		return "", err
+	}
+	for _, grp := range groups {
+		if claims.Organization == "" || grp.OrganizationID == claims.Organization {
+			claims.Groups = append(claims.Groups, grp.ID)
+		}
+	}
+	return c.minter.Mint(claims)
+}
+
+// authorized checks the user has at least one of the permissions, the
+// permission the user has is returned
+func (c Authz) authorized(ctx context.Context, permissions []Permission, permissionAttributes map[string]interface{}) (Permission, bool, error) {
+	for _, permission := range permissions {
+		isAuthorized, err := c.resourceService.CheckAuthz(ctx, resource.Resource{
 			Name:        permissionAttributes[permission.Attribute].(string),
 			NamespaceID: permission.Namespace,
 		}, action.Action{
 			ID: permission.Name,
 		})
 		if err != nil {
-			c.log.Error("error while creating resource obj", "err", err)
-			c.notAllowed(rw)
-			return
+			return Permission{}, false, err
 		}
 		if isAuthorized {
-			break
+			return permission, true, nil
 		}
 	}
+	return Permission{}, false, nil
+}
 
-	c.log.Info("authz check successful", "user", permissionAttributes["user"], "resource", permissionAttributes["resource"], "result", isAuthorized)
-	if !isAuthorized {
-		c.log.Info("user not allowed to make request", "user", permissionAttributes["user"], "resource", permissionAttributes["resource"], "result", isAuthorized)
-		c.notAllowed(rw)
-		return
+// recheck checks the permissions of a long-lived connection every interval
+// and closes the connection, by cancelling its request, once they are
+// revoked
+func (c Authz) recheck(ctx context.Context, cancel context.CancelFunc, interval time.Duration, permissions []Permission, permissionAttributes map[string]interface{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, isAuthorized, err := c.authorized(ctx, permissions, permissionAttributes)
+			if err != nil {
+				// a failing check doesn't drop the connection, it is checked
+				// again at the next tick
+				c.log.Warn("middleware: failed to recheck the permissions of connection", "err", err)
+				continue
+			}
+			if !isAuthorized {
+				c.log.Info("closing connection the user is no longer allowed to", "user", permissionAttributes["user"], "resource", permissionAttributes["resource"])
+				cancel()
+				return
+			}
+		}
 	}
-
-	c.next.ServeHTTP(rw, req)
 }
 
 func (w Authz) notAllowed(rw http.ResponseWriter) {
@@ -147,7 +147,7 @@ func (w BasicAuth) authorizeRequest(conf Config, user string, req *http.Request)
 			}
 
 			// TODO: we can optimise this by parsing all field at once
-			payloadField, err := body_extractor.GRPCPayloadHandler{}.Extract(&req.Body, attr.Index)
+			payloadField, err := body_extractor.GRPCPayloadHandler{}.Extract(middleware.RequestBody(req), attr.Index)
 			if err != nil {
 				w.log.Error("middleware: failed to parse grpc payload", "err", err)
 				return false
@@ -160,12 +160,25 @@ func (w BasicAuth) authorizeRequest(conf Config, user string, req *http.Request)
 				w.log.Error("middleware: payload key field empty")
 				return false
 			}
-			payloadField, err := body_extractor.JSONPayloadHandler{}.Extract(&req.Body, attr.Key)
+			payloadField, err := body_extractor.JSONPayloadHandler{}.Extract(middleware.RequestBody(req), attr.Key)
 			if err != nil {
 				w.log.Error("middleware: failed to parse json payload", "err", err)
 				return false
 			}
 
+			templateMap[res] = payloadField
+			w.log.Debug("middleware: extracted", "field", payloadField, "attr", attr)
+		case middleware.AttributeTypeFormPayload:
+			if attr.Key == "" {
+				w.log.Error("middleware: payload key field empty")
+				return false
+			}
+			payloadField, err := body_extractor.FormPayloadHandler{ContentType: req.Header.Get("Content-Type")}.Extract(middleware.RequestBody(req), attr.Key)
+			if err != nil {
+				w.log.Error("middleware: failed to parse form payload", "err", err)
+				return false
+			}
+
 			templateMap[res] = payloadField
 			w.log.Debug("middleware: extracted", "field", payloadField, "attr", attr)
 		default:
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -14,27 +16,121 @@ func EnrichRule(req *http.Request, r *rule.Rule) {
 	*req = *req.WithContext(rule.WithContext(req.Context(), r))
 }
 
+// bodyBufferSize is how much of a request body is kept for the attributes
+// of the middlewares and the hooks, the rest of a larger body is streamed to
+// the backend without being held by the proxy
+const bodyBufferSize = 1 << 20
+
+var (
+	// ErrBodyTooLarge is returned reading a request body larger than the max
+	// body size of its rule
+	ErrBodyTooLarge = errors.New("request body too large")
+	// ErrBodyNotBuffered is returned reading the buffered request body past
+	// the part of it which was buffered
+	ErrBodyNotBuffered = errors.New("request body too large to be buffered")
+)
+
+type contextRequestBodyPartialKey struct{}
+
+// EnrichRequestBody keeps the request body for the middlewares and the hooks
+// to read, only the first bodyBufferSize bytes of a larger body are kept
 func EnrichRequestBody(r *http.Request) error {
-	reqBody, err := ioutil.ReadAll(r.Body)
+	reqBody, err := ioutil.ReadAll(io.LimitReader(r.Body, bodyBufferSize))
 	if err != nil {
 		return err
 	}
-	defer (r.Body).Close()
 
 	// repopulate body
-	(*r).Body = ioutil.NopCloser(bytes.NewBuffer(reqBody))
-	*r = *r.WithContext(httputil.SetContextWithRequestBody(r.Context(), reqBody))
+	partial := len(reqBody) == bodyBufferSize
+	if partial {
+		(*r).Body = readCloser{
+			Reader: io.MultiReader(bytes.NewReader(reqBody), r.Body),
+			Closer: r.Body,
+		}
+	} else {
+		(r.Body).Close()
+		(*r).Body = ioutil.NopCloser(bytes.NewBuffer(reqBody))
+	}
+	ctx := httputil.SetContextWithRequestBody(r.Context(), reqBody)
+	*r = *r.WithContext(context.WithValue(ctx, contextRequestBodyPartialKey{}, partial))
 	return nil
 }
 
+// ExtractRequestBody reads the buffered request body, reading the body of a
+// request larger than the buffer fails with ErrBodyNotBuffered after its
+// buffered part, so the attributes of the start of a multipart body can be
+// read but not the ones of a large json body
 func ExtractRequestBody(r *http.Request) (io.ReadCloser, bool) {
 	body, ok := httputil.GetRequestBodyFromContext(r.Context())
 	if !ok {
 		return nil, false
 	}
+	if partial, _ := r.Context().Value(contextRequestBodyPartialKey{}).(bool); partial {
+		return ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{ErrBodyNotBuffered})), true
+	}
 	return ioutil.NopCloser(bytes.NewBuffer(body)), true
 }
 
+// ReplayRequestBody is the whole request body to send the request again, the
+// body of a request larger than the buffer was streamed and can't be replayed
+func ReplayRequestBody(r *http.Request) (io.ReadCloser, bool) {
+	if partial, _ := r.Context().Value(contextRequestBodyPartialKey{}).(bool); partial {
+		return nil, false
+	}
+	return ExtractRequestBody(r)
+}
+
+// RequestBody is the buffered request body to read the attributes of the
+// request from, the request body is read when it wasn't buffered
+func RequestBody(r *http.Request) *io.ReadCloser {
+	if body, ok := ExtractRequestBody(r); ok {
+		return &body
+	}
+	return &r.Body
+}
+
+// LimitRequestBody fails reading the request body with ErrBodyTooLarge once
+// more than max bytes are read
+func LimitRequestBody(r *http.Request, max int64) {
+	r.Body = &limitedBody{ReadCloser: r.Body, left: max}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	left int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.left < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	// a byte more than what is left tells the body is too large
+	if int64(len(p)) > b.left+1 {
+		p = p[:b.left+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.left {
+		n = int(b.left)
+		b.left = -1
+		return n, ErrBodyTooLarge
+	}
+	b.left -= int64(n)
+	return n, err
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
 func ExtractRule(r *http.Request) (*rule.Rule, bool) {
 	return rule.GetFromContext(r.Context())
 }
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnrichRequestBody(t *testing.T) {
+	t.Run("should buffer a small body", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/api/firehoses", strings.NewReader(`{"name":"f1"}`))
+		assert.NoError(t, err)
+		assert.NoError(t, EnrichRequestBody(req))
+
+		buffered, ok := ExtractRequestBody(req)
+		assert.True(t, ok)
+		read, err := ioutil.ReadAll(buffered)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"f1"}`, string(read))
+
+		forwarded, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"f1"}`, string(forwarded))
+
+		replayed, ok := ReplayRequestBody(req)
+		assert.True(t, ok)
+		read, err = ioutil.ReadAll(replayed)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"f1"}`, string(read))
+	})
+
+	t.Run("should stream a large body", func(t *testing.T) {
+		payload := bytes.Repeat([]byte("a"), bodyBufferSize*3)
+		req, err := http.NewRequest(http.MethodPost, "/api/uploads", bytes.NewReader(payload))
+		assert.NoError(t, err)
+		assert.NoError(t, EnrichRequestBody(req))
+
+		buffered, ok := ExtractRequestBody(req)
+		assert.True(t, ok)
+		read, err := ioutil.ReadAll(buffered)
+		assert.ErrorIs(t, err, ErrBodyNotBuffered)
+		assert.Len(t, read, bodyBufferSize)
+
+		forwarded, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, payload, forwarded)
+
+		_, ok = ReplayRequestBody(req)
+		assert.False(t, ok)
+	})
+}
+
+func TestLimitRequestBody(t *testing.T) {
+	t.Run("should read a body up to the limit", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/api/uploads", strings.NewReader("12345"))
+		assert.NoError(t, err)
+		LimitRequestBody(req, 5)
+
+		read, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "12345", string(read))
+	})
+
+	t.Run("should fail reading a body over the limit", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/api/uploads", strings.NewReader("123456"))
+		assert.NoError(t, err)
+		LimitRequestBody(req, 5)
+
+		assert.ErrorIs(t, EnrichRequestBody(req), ErrBodyTooLarge)
+	})
+}
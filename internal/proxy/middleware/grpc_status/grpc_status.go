@@ -0,0 +1,121 @@
+package grpc_status
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/odpf/salt/log"
+	"google.golang.org/grpc/codes"
+
+	"github.com/odpf/shield/internal/proxy/middleware"
+)
+
+// Ware replies to the gRPC calls the proxy rejects with the gRPC status of
+// the http status the middlewares and the hooks respond with, gRPC clients
+// only read the status of a call from its grpc-status
+type Ware struct {
+	log  *log.Zap
+	next http.Handler
+}
+
+func New(log *log.Zap, next http.Handler) *Ware {
+	return &Ware{
+		log:  log,
+		next: next,
+	}
+}
+
+func (m Ware) Info() *middleware.MiddlewareInfo {
+	return &middleware.MiddlewareInfo{
+		Name:        "_grpc_status",
+		Description: "to reply with the grpc status of rejected grpc calls",
+	}
+}
+
+func (m *Ware) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+		m.next.ServeHTTP(rw, req)
+		return
+	}
+	m.next.ServeHTTP(&statusWriter{ResponseWriter: rw}, req)
+}
+
+// statusWriter turns an error status into an ok status with the grpc-status
+// of the error and drops the body and the trailers written along with it,
+// the body of a gRPC response is made of the messages of the call and its
+// trailers would override the grpc-status
+type statusWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	rejected    bool
+	discarded   http.Header
+}
+
+func (w *statusWriter) Header() http.Header {
+	if w.rejected {
+		return w.discarded
+	}
+	return w.ResponseWriter.Header()
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code < 400 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.rejected = true
+	w.discarded = http.Header{}
+	header := w.ResponseWriter.Header()
+	header.Del("Content-Length")
+	header.Del("Trailer")
+	header.Set("Content-Type", "application/grpc")
+	header.Set("Grpc-Status", strconv.Itoa(int(Code(code))))
+	header.Set("Grpc-Message", http.StatusText(code))
+	w.ResponseWriter.WriteHeader(http.StatusOK)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush lets the messages of streaming calls through as they come
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Code is the gRPC status of an http status, the way gRPC clients map the
+// http status of a response which isn't a gRPC response
+func Code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusInternalServerError:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
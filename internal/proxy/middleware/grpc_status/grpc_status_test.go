@@ -0,0 +1,79 @@
+package grpc_status
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	shieldlogger "github.com/odpf/shield/pkg/logger"
+)
+
+func TestServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		title       string
+		contentType string
+		status      int
+		wantStatus  int
+		wantGRPC    string
+		wantBody    string
+	}{
+		{
+			title:       "should reply to a rejected grpc call with its grpc status",
+			contentType: "application/grpc",
+			status:      http.StatusUnauthorized,
+			wantStatus:  http.StatusOK,
+			wantGRPC:    "16",
+		},
+		{
+			title:       "should reply to a rejected grpc call with a codec with its grpc status",
+			contentType: "application/grpc+proto",
+			status:      http.StatusForbidden,
+			wantStatus:  http.StatusOK,
+			wantGRPC:    "7",
+		},
+		{
+			title:       "should let an ok grpc call through",
+			contentType: "application/grpc",
+			status:      http.StatusOK,
+			wantStatus:  http.StatusOK,
+			wantBody:    "message",
+		},
+		{
+			title:       "should let a rejected http request through",
+			contentType: "application/json",
+			status:      http.StatusUnauthorized,
+			wantStatus:  http.StatusUnauthorized,
+			wantBody:    "message",
+		},
+	}
+
+	for _, tt := range table {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Trailer", "Grpc-Status")
+				rw.WriteHeader(tt.status)
+				rw.Write([]byte("message"))
+				rw.Header().Set("Grpc-Status", "0")
+			})
+			req := httptest.NewRequest(http.MethodPost, "/odpf.shield.v1beta1.ShieldService/ListUsers", nil)
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+
+			New(shieldlogger.InitLogger(shieldlogger.Config{}), next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+			if tt.wantGRPC != "" {
+				assert.Equal(t, tt.wantGRPC, rec.Header().Get("Grpc-Status"))
+				assert.Empty(t, rec.Header().Get("Trailer"))
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -22,3 +23,12 @@ func Elapsed(what string) func() {
 		fmt.Printf("%s took %v\n", what, time.Since(start))
 	}
 }
+
+// Streaming tells the request opens a long-lived connection, a websocket or
+// a server-sent events stream, the response of which is streamed as it comes
+func Streaming(req *http.Request) bool {
+	if strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return true
+	}
+	return strings.Contains(req.Header.Get("Accept"), "text/event-stream")
+}
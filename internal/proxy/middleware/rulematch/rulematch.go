@@ -2,6 +2,7 @@ package rulematch
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/odpf/shield/core/rule"
@@ -52,9 +53,23 @@ func (m *Ware) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 	middleware.EnrichRule(req, matchedRule)
 
+	if maxBodySize := matchedRule.Frontend.MaxBodySize; maxBodySize > 0 {
+		if req.ContentLength > maxBodySize {
+			logger.Info("request_body_too_large", zap.Int64("content_length", req.ContentLength))
+			rw.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		// bodies without a content length are cut off once they are larger
+		middleware.LimitRequestBody(req, maxBodySize)
+	}
+
 	// enriching context with request body to use it in hooks
 	if err := middleware.EnrichRequestBody(req); err != nil {
 		logger.Error("error_enriching_request_body", zap.Error(err))
+		if errors.Is(err, middleware.ErrBodyTooLarge) {
+			rw.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -1,12 +1,17 @@
 package proxy
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
+	"io"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/odpf/shield/internal/proxy/hook"
+	"github.com/odpf/shield/internal/proxy/middleware"
 
 	"github.com/odpf/salt/log"
 	"go.uber.org/zap"
@@ -24,8 +29,13 @@ type h2cTransportWrapper struct {
 	httpTransport *http.Transport
 	grpcTransport *http2.Transport
 
-	log  log.Logger
-	hook hook.Service
+	// tlsBackends are the backends connected to with a client certificate,
+	// by name
+	tlsBackends map[string]*TLSBackend
+
+	log      log.Logger
+	hook     hook.Service
+	breakers *Breakers
 }
 
 func (t *h2cTransportWrapper) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -41,42 +51,190 @@ func (t *h2cTransportWrapper) RoundTrip(req *http.Request) (*http.Response, erro
 	}
 
 	req.Header.Del("Accept-Encoding")
+	grpc := strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc")
 	var transport http.RoundTripper = t.httpTransport
-	if req.Header.Get("Content-Type") == "application/grpc" {
+	if grpc {
 		transport = t.grpcTransport
 	}
+	if rl, ok := middleware.ExtractRule(req); ok {
+		if backend, ok := t.tlsBackends[rl.Backend.Namespace]; ok {
+			transport = backend.Transport(grpc)
+		}
+	}
 
-	req = req.WithContext(WithoutCancel(req.Context()))
+	// a long-lived connection ends with the request, like when the client
+	// goes away or the authz middleware revokes it
+	streaming := middleware.Streaming(req)
+	if !streaming {
+		req = req.WithContext(WithoutCancel(req.Context()))
+	}
 
 	logger.Info("request_forwarded")
 
-	res, err := transport.RoundTrip(req)
+	res, err := t.send(req, transport, streaming, logger)
 	if err != nil {
 		return res, err
 	}
 
 	logger.Info("request_completed", zap.String("status", res.Status))
 
+	// the hooks read the body of the response, which is the connection of an
+	// upgraded request and has no end for a stream
+	if res.StatusCode == http.StatusSwitchingProtocols || strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		return res, nil
+	}
 	return t.hook.ServeHook(res, nil)
 }
 
-func NewH2cRoundTripper(log log.Logger, hook hook.Service) http.RoundTripper {
+// send sends the request to the backend of its rule within the policy of the
+// backend, the idempotent requests failing with a bad gateway, an unavailable
+// backend or a timeout are sent again while the retry budget of the backend
+// allows it
+func (t *h2cTransportWrapper) send(req *http.Request, transport http.RoundTripper, streaming bool, logger *zap.Logger) (*http.Response, error) {
+	rl, ok := middleware.ExtractRule(req)
+	if !ok {
+		return transport.RoundTrip(req)
+	}
+	// the splits of a backend have breakers of their own, a failing canary
+	// doesn't open the breaker of the stable target
+	backend, policy := backendName(rl.Backend), rl.Backend.Policy
+	if split, ok := splitName(req); ok {
+		backend += "/" + split
+	}
+
+	// a long-lived connection is neither timed out nor sent again
+	attempts, timeout := 1, policy.TimeoutDuration()
+	if streaming {
+		timeout = 0
+	} else if idempotent(req.Method) {
+		attempts += policy.Retry.Attempts
+	}
+
+	t.breakers.Request(backend)
+	if !t.breakers.Allow(backend, policy.CircuitBreaker) {
+		return nil, ErrCircuitOpen
+	}
+	for attempt := 1; ; attempt++ {
+		res, err := roundTripWithTimeout(transport, req, timeout)
+		t.breakers.Done(backend, policy.CircuitBreaker, err != nil || res.StatusCode >= http.StatusInternalServerError)
+		if attempt >= attempts || !retriable(res, err) {
+			return res, err
+		}
+
+		body, ok := replayBody(req)
+		if !ok || !t.breakers.Retry(backend, policy.Retry.Budget) || !t.breakers.Allow(backend, policy.CircuitBreaker) {
+			return res, err
+		}
+		if res != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+			res.Body.Close()
+		}
+		logger.Info("request_retried", zap.Int("attempt", attempt+1))
+
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+}
+
+// roundTripWithTimeout sends the request within the timeout, reading the
+// response body is part of it so the timeout ends when the body is closed
+func roundTripWithTimeout(transport http.RoundTripper, req *http.Request, timeout time.Duration) (*http.Response, error) {
+	if timeout <= 0 {
+		return transport.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	res, err := transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return res, err
+	}
+	res.Body = cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retriable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// replayBody is the body to send a request again with, the body of a request
+// larger than the buffer of the proxy was streamed and is gone
+func replayBody(req *http.Request) (io.ReadCloser, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req.Body, true
+	}
+	return middleware.ReplayRequestBody(req)
+}
+
+// timedOut tells whether the backend failed to answer within its timeout
+func timedOut(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+func NewH2cRoundTripper(log log.Logger, hook hook.Service, breakers *Breakers, tlsBackends map[string]*TLSBackend) http.RoundTripper {
 	return &h2cTransportWrapper{
-		httpTransport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout:   10 * time.Second,
-				KeepAlive: 1 * time.Minute,
-			}).DialContext,
-			DisableCompression: true,
-		},
-		grpcTransport: &http2.Transport{
-			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
-				return net.Dial(network, addr)
-			},
-			AllowHTTP:          true,
+		httpTransport: newHTTPTransport(nil),
+		grpcTransport: newGRPCTransport(nil),
+		tlsBackends:   tlsBackends,
+		log:           log,
+		hook:          hook,
+		breakers:      breakers,
+	}
+}
+
+// newHTTPTransport is the transport of the http requests, the requests to
+// https targets are made with the tls config when it is set
+func newHTTPTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 1 * time.Minute,
+		}).DialContext,
+		TLSClientConfig:    tlsConfig,
+		ForceAttemptHTTP2:  tlsConfig != nil,
+		DisableCompression: true,
+	}
+}
+
+// newGRPCTransport is the transport of the gRPC calls, made over h2c to
+// http targets unless a tls config is set
+func newGRPCTransport(tlsConfig *tls.Config) *http2.Transport {
+	if tlsConfig != nil {
+		return &http2.Transport{
+			TLSClientConfig:    tlsConfig,
 			DisableCompression: true,
+		}
+	}
+	return &http2.Transport{
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
 		},
-		log:  log,
-		hook: hook,
+		AllowHTTP:          true,
+		DisableCompression: true,
 	}
 }
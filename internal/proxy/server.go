@@ -6,6 +6,9 @@ import (
 	"net/http"
 
 	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/internal/metrics"
+	"github.com/odpf/shield/internal/tracing"
+	"github.com/odpf/shield/pkg/tlsutil"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 )
@@ -15,19 +18,28 @@ func Serve(
 	logger log.Logger,
 	cfg Config,
 	handler http.Handler,
-) func(ctx context.Context) error {
+) (func(ctx context.Context) error, error) {
 	proxyURL := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 	logger.Info("starting h2c proxy", "url", proxyURL)
 
 	mux := http.NewServeMux()
 	mux.Handle("/ping", healthCheck())
-	mux.Handle("/", handler)
+	mux.Handle("/", tracing.HTTPMiddleware(metrics.HTTPMiddleware(cfg.Name, handler)))
 
 	proxySrv := http.Server{
 		Addr:    proxyURL,
 		Handler: h2c.NewHandler(mux, &http2.Server{}),
 	}
 
+	// tls is served next to the h2c port, http/2 is then negotiated over tls
+	shutdownTLS := func(context.Context) error { return nil }
+	if cfg.TLS.Enabled() {
+		var err error
+		if shutdownTLS, err = tlsutil.Serve(logger, cfg.Host, cfg.TLS, mux); err != nil {
+			return nil, fmt.Errorf("proxy %s failed to serve tls: %w", cfg.Name, err)
+		}
+	}
+
 	go func(ctx context.Context, logger log.Logger, cfg Config) {
 		if err := proxySrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("failed to serve", "err", err)
@@ -37,7 +49,13 @@ func Serve(
 	}(ctx, logger, cfg)
 
 	logger.Info("[shield] proxy ready", "service", cfg.Name)
-	return proxySrv.Shutdown
+	return func(ctx context.Context) error {
+		tlsErr := shutdownTLS(ctx)
+		if err := proxySrv.Shutdown(ctx); err != nil {
+			return err
+		}
+		return tlsErr
+	}, nil
 }
 
 func healthCheck() http.HandlerFunc {
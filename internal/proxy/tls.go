@@ -0,0 +1,218 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/odpf/salt/log"
+	"golang.org/x/net/http2"
+)
+
+// tlsReloadDelay lets the certificate, the key and the certificate authority
+// of a backend be rotated together before they are loaded again
+const tlsReloadDelay = time.Second
+
+// TLSBackend is a backend the proxy connects to with a client certificate,
+// its transports are built again when its certificate files change
+type TLSBackend struct {
+	name string
+	cfg  TLSConfig
+	log  log.Logger
+
+	// transports holds the *transports built with the certificates loaded
+	// last
+	transports atomic.Value
+}
+
+type transports struct {
+	http *http.Transport
+	grpc *http2.Transport
+}
+
+// NewTLSBackends loads the certificates of the backends with a tls config
+// and reloads them as they change until the context is done, the backends
+// are named as in the rules
+func NewTLSBackends(ctx context.Context, log log.Logger, backends []BackendConfig) (map[string]*TLSBackend, error) {
+	tlsBackends := map[string]*TLSBackend{}
+	for _, backend := range backends {
+		if backend.TLS == nil {
+			continue
+		}
+		if backend.Name == "" {
+			return nil, errors.New("a backend with a tls config has no name")
+		}
+		b := &TLSBackend{name: backend.Name, cfg: *backend.TLS, log: log}
+		if err := b.load(); err != nil {
+			return nil, fmt.Errorf("backend %q: %w", backend.Name, err)
+		}
+		if err := b.watch(ctx); err != nil {
+			return nil, fmt.Errorf("backend %q: failed to watch certificates: %w", backend.Name, err)
+		}
+		tlsBackends[backend.Name] = b
+	}
+	return tlsBackends, nil
+}
+
+// Transport is the transport of the gRPC or of the http requests to the
+// backend
+func (b *TLSBackend) Transport(grpc bool) http.RoundTripper {
+	t := b.transports.Load().(*transports)
+	if grpc {
+		return t.grpc
+	}
+	return t.http
+}
+
+func (b *TLSBackend) load() error {
+	tlsConfig, err := b.cfg.build()
+	if err != nil {
+		return err
+	}
+	previous, _ := b.transports.Load().(*transports)
+	b.transports.Store(&transports{
+		http: newHTTPTransport(tlsConfig),
+		grpc: newGRPCTransport(tlsConfig),
+	})
+	// the connections made with the previous certificates are closed once
+	// their requests are done
+	if previous != nil {
+		previous.http.CloseIdleConnections()
+		previous.grpc.CloseIdleConnections()
+	}
+	return nil
+}
+
+// watch watches the directories of the certificate files, the files of a
+// kubernetes secret are replaced by swapping a link in their directory
+func (b *TLSBackend) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dirs := map[string]bool{}
+	for _, file := range []string{b.cfg.CertFile, b.cfg.KeyFile, b.cfg.CAFile} {
+		if file == "" || dirs[filepath.Dir(file)] {
+			continue
+		}
+		dirs[filepath.Dir(file)] = true
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		var reload <-chan time.Time
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				reload = time.After(tlsReloadDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				b.log.Warn("failed to watch backend certificates", "backend", b.name, "err", err)
+			case <-reload:
+				reload = nil
+				// the backend keeps the certificates it had when the new
+				// ones can't be loaded
+				if err := b.load(); err != nil {
+					b.log.Warn("failed to reload backend certificates", "backend", b.name, "err", err)
+					continue
+				}
+				b.log.Info("reloaded backend certificates", "backend", b.name)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (c TLSConfig) build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("a client certificate needs both a cert file and a key file")
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate authority: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, errors.New("certificate authority has no pem certificate")
+		}
+	}
+
+	if c.SPIFFEID != "" {
+		if !strings.HasPrefix(c.SPIFFEID, "spiffe://") {
+			return nil, fmt.Errorf("spiffe id %q should start with spiffe://", c.SPIFFEID)
+		}
+		roots := tlsConfig.RootCAs
+		if roots == nil {
+			var err error
+			if roots, err = x509.SystemCertPool(); err != nil {
+				return nil, err
+			}
+		}
+		// an svid names its workload by its uri rather than a host name, the
+		// chain is verified here along with the id instead
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifySPIFFEID(roots, c.SPIFFEID)
+	}
+	return tlsConfig, nil
+}
+
+func verifySPIFFEID(roots *x509.CertPool, id string) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("backend sent no certificate")
+		}
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs = append(certs, cert)
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+			return err
+		}
+		for _, uri := range certs[0].URIs {
+			if uri.String() == id {
+				return nil
+			}
+		}
+		return fmt.Errorf("certificate of the backend doesn't have the spiffe id %s", id)
+	}
+}
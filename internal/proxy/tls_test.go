@@ -0,0 +1,172 @@
+package proxy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func (c testCert) keyPEM(t *testing.T) []byte {
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.pem, c.keyPEM(t))
+	require.NoError(t, err)
+	return cert
+}
+
+// newTestCert issues a certificate from the parent, a certificate authority
+// when the parent is nil
+func newTestCert(t *testing.T, parent *testCert, template *x509.Certificate) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return testCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func TestTLSBackend(t *testing.T) {
+	ca := newTestCert(t, nil, &x509.Certificate{Subject: pkix.Name{CommonName: "mesh"}})
+	rogueCA := newTestCert(t, nil, &x509.Certificate{Subject: pkix.Name{CommonName: "rogue"}})
+	spiffeID, err := url.Parse("spiffe://odpf.io/ns/default/sa/entropy")
+	require.NoError(t, err)
+	serverCert := newTestCert(t, &ca, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "entropy"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		URIs:        []*url.URL{spiffeID},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	clientCert := newTestCert(t, &ca, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "shield"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	rogueCert := newTestCert(t, &rogueCA, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "shield"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	backend.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate(t)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	backend.StartTLS()
+	defer backend.Close()
+
+	dir := t.TempDir()
+	writeCerts := func(cert testCert) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt"), cert.pem, 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), cert.keyPEM(t), 0o600))
+	}
+	writeCerts(clientCert)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), ca.pem, 0o600))
+
+	newBackend := func(cfg TLSConfig) (*TLSBackend, error) {
+		cfg.CertFile = filepath.Join(dir, "tls.crt")
+		cfg.KeyFile = filepath.Join(dir, "tls.key")
+		cfg.CAFile = filepath.Join(dir, "ca.crt")
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		backends, err := NewTLSBackends(ctx, log.NewNoop(), []BackendConfig{{Name: "entropy", TLS: &cfg}})
+		if err != nil {
+			return nil, err
+		}
+		return backends["entropy"], nil
+	}
+	get := func(b *TLSBackend) (int, error) {
+		req, err := http.NewRequest(http.MethodGet, backend.URL, nil)
+		require.NoError(t, err)
+		res, err := b.Transport(false).RoundTrip(req)
+		if err != nil {
+			return 0, err
+		}
+		defer res.Body.Close()
+		return res.StatusCode, nil
+	}
+
+	t.Run("should connect with the client certificate", func(t *testing.T) {
+		b, err := newBackend(TLSConfig{})
+		require.NoError(t, err)
+		code, err := get(b)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("should verify the spiffe id of the backend", func(t *testing.T) {
+		b, err := newBackend(TLSConfig{SPIFFEID: spiffeID.String()})
+		require.NoError(t, err)
+		code, err := get(b)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+
+		b, err = newBackend(TLSConfig{SPIFFEID: "spiffe://odpf.io/ns/default/sa/siren"})
+		require.NoError(t, err)
+		_, err = get(b)
+		assert.ErrorContains(t, err, "spiffe id")
+	})
+
+	t.Run("should reject a spiffe id without the spiffe scheme", func(t *testing.T) {
+		_, err := newBackend(TLSConfig{SPIFFEID: "odpf.io/entropy"})
+		assert.Error(t, err)
+	})
+
+	t.Run("should reload the certificates when they change", func(t *testing.T) {
+		writeCerts(rogueCert)
+		b, err := newBackend(TLSConfig{})
+		require.NoError(t, err)
+		_, err = get(b)
+		assert.Error(t, err)
+
+		writeCerts(clientCert)
+		assert.Eventually(t, func() bool {
+			code, err := get(b)
+			return err == nil && code == http.StatusOK
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+}
@@ -0,0 +1,99 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// Config is how the proxies mint the tokens of the requests they authorized
+// for their backends, the tokens are signed with the keys shield rotates
+// and verified with the jwks of the api
+type Config struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Issuer is the iss claim of the tokens
+	Issuer string `yaml:"issuer" mapstructure:"issuer" default:"shield"`
+	// Audience is the aud claim of the tokens, not set when empty
+	Audience string `yaml:"audience" mapstructure:"audience"`
+	// TTL is how long the tokens are valid, they are minted for every
+	// request so it can be short
+	TTL time.Duration `yaml:"ttl" mapstructure:"ttl" default:"5m"`
+	// Header is the header of the request the token is sent to the backend
+	// in, it is removed from the requests coming to the proxy
+	Header string `yaml:"header" mapstructure:"header" default:"X-Shield-Token"`
+}
+
+// Claims are the claims of a token minted for a request the proxy
+// authorized
+type Claims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Audience  string `json:"aud,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	ID        string `json:"jti"`
+
+	Email        string     `json:"email,omitempty"`
+	Organization string     `json:"org,omitempty"`
+	Groups       []string   `json:"groups"`
+	Permission   Permission `json:"permission"`
+}
+
+// Permission is the permission the request was authorized with
+type Permission struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Resource  string `json:"resource"`
+}
+
+// Signer signs the claims as a JWT with the signing key
+type Signer interface {
+	Sign(claims any) (string, error)
+}
+
+// Minter mints the tokens of the proxies
+type Minter struct {
+	cfg    Config
+	signer Signer
+	now    func() time.Time
+}
+
+// NewMinter returns the minter of the config, the proxies don't mint tokens
+// when it returns nil
+func NewMinter(cfg Config, signer Signer) (*Minter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if signer == nil {
+		return nil, errors.New("tokens can't be minted without signing keys, set an encryption key for the keys")
+	}
+	if cfg.TTL <= 0 {
+		return nil, errors.New("token ttl must be more than 0")
+	}
+	return &Minter{cfg: cfg, signer: signer, now: time.Now}, nil
+}
+
+// Header is the header the tokens are sent to the backends in
+func (m *Minter) Header() string {
+	return m.cfg.Header
+}
+
+// Mint signs a token with the claims, the registered claims are set by the
+// minter
+func (m *Minter) Mint(claims Claims) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	now := m.now()
+	claims.Issuer = m.cfg.Issuer
+	claims.Audience = m.cfg.Audience
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(m.cfg.TTL).Unix()
+	claims.ID = hex.EncodeToString(id)
+	if claims.Groups == nil {
+		claims.Groups = []string{}
+	}
+	return m.signer.Sign(claims)
+}
@@ -0,0 +1,74 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// claimsSigner returns the claims it was asked to sign
+type claimsSigner struct {
+	claims any
+}
+
+func (s *claimsSigner) Sign(claims any) (string, error) {
+	s.claims = claims
+	return "signed", nil
+}
+
+func TestMinter(t *testing.T) {
+	cfg := Config{Enabled: true, Issuer: "shield", Audience: "entropy", TTL: 5 * time.Minute, Header: "X-Shield-Token"}
+	claims := Claims{
+		Subject:      "user-1",
+		Email:        "user@odpf.io",
+		Organization: "org-1",
+		Permission:   Permission{Name: "view", Namespace: "entropy/firehose", Resource: "firehose-1"},
+	}
+
+	t.Run("should not mint tokens when disabled", func(t *testing.T) {
+		m, err := NewMinter(Config{}, &claimsSigner{})
+		assert.NoError(t, err)
+		assert.Nil(t, m)
+	})
+
+	t.Run("should fail without a signer", func(t *testing.T) {
+		_, err := NewMinter(cfg, nil)
+		assert.ErrorContains(t, err, "without signing keys")
+	})
+
+	t.Run("should fail without a ttl", func(t *testing.T) {
+		_, err := NewMinter(Config{Enabled: true}, &claimsSigner{})
+		assert.ErrorContains(t, err, "ttl")
+	})
+
+	t.Run("should sign the claims with the registered claims set", func(t *testing.T) {
+		signer := &claimsSigner{}
+		m, err := NewMinter(cfg, signer)
+		require.NoError(t, err)
+		now := time.Date(2023, 2, 2, 10, 0, 0, 0, time.UTC)
+		m.now = func() time.Time { return now }
+
+		tkn, err := m.Mint(claims)
+		require.NoError(t, err)
+		assert.Equal(t, "signed", tkn)
+		assert.Equal(t, "X-Shield-Token", m.Header())
+
+		got, ok := signer.claims.(Claims)
+		require.True(t, ok)
+		assert.Len(t, got.ID, 32)
+		got.ID = ""
+		assert.Equal(t, Claims{
+			Issuer:       "shield",
+			Subject:      "user-1",
+			Audience:     "entropy",
+			IssuedAt:     now.Unix(),
+			ExpiresAt:    now.Add(5 * time.Minute).Unix(),
+			Email:        "user@odpf.io",
+			Organization: "org-1",
+			Groups:       []string{},
+			Permission:   Permission{Name: "view", Namespace: "entropy/firehose", Resource: "firehose-1"},
+		}, got)
+	})
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import "time"
+
+type Config struct {
+	// Identity limits the requests of every user, service user and api key,
+	// the requests without an identity are limited by their address
+	Identity Limit `yaml:"identity" mapstructure:"identity"`
+	// Organization limits the requests made in every organization, the
+	// organization of a request is the org_id of its payload
+	Organization Limit `yaml:"organization" mapstructure:"organization"`
+	// Redis shares the buckets between the instances, they are kept in the
+	// memory of every instance when no address is set
+	Redis RedisConfig `yaml:"redis" mapstructure:"redis"`
+}
+
+// Limit is a token bucket refilled with Rate tokens a second up to Burst
+// tokens, a request takes a token
+type Limit struct {
+	// Rate is the number of requests a second, requests are not limited
+	// when it is 0
+	Rate float64 `yaml:"rate" mapstructure:"rate"`
+	// Burst is the number of requests made at once after being idle, it is
+	// the rate rounded up when not set
+	Burst int `yaml:"burst" mapstructure:"burst"`
+}
+
+func (l Limit) enabled() bool {
+	return l.Rate > 0
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	b := int(l.Rate)
+	if float64(b) < l.Rate {
+		b++
+	}
+	return b
+}
+
+type RedisConfig struct {
+	// Addr is the host:port of redis
+	Addr     string `yaml:"addr" mapstructure:"addr"`
+	Password string `yaml:"password" mapstructure:"password"`
+	DB       int    `yaml:"db" mapstructure:"db"`
+	// KeyPrefix starts the keys of the buckets
+	KeyPrefix string `yaml:"key_prefix" mapstructure:"key_prefix" default:"shield:ratelimit:"`
+	// Timeout of a request to redis, the request is let through when redis
+	// doesn't answer in time
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" default:"100ms"`
+	// PoolSize is the number of connections kept open to redis
+	PoolSize int `yaml:"pool_size" mapstructure:"pool_size" default:"10"`
+}
@@ -0,0 +1,117 @@
+// Package ratelimit limits the requests made to the api by every identity
+// and in every organization with token buckets, kept in memory or in redis
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// scopes of the buckets, a request is counted against the bucket of its
+// identity and the one of its organization
+const (
+	ScopeIdentity     = "identity"
+	ScopeOrganization = "organization"
+)
+
+// Result is the state of the bucket after a request took a token, Limit is
+// 0 when the request was not limited
+type Result struct {
+	Allowed bool
+	Scope   string
+	// Limit is the size of the bucket, the requests made at once
+	Limit int
+	// Remaining is the number of requests left before being limited
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until a request is allowed, 0 when it was
+	RetryAfter time.Duration
+}
+
+type store interface {
+	take(ctx context.Context, key string, limit Limit) (Result, error)
+	close() error
+}
+
+type Limiter struct {
+	cfg   Config
+	store store
+}
+
+// New keeps the buckets in redis if an address is set and in memory
+// otherwise, connections to redis are made as they are needed
+func New(cfg Config) *Limiter {
+	l := &Limiter{cfg: cfg}
+	if cfg.Redis.Addr != "" {
+		l.store = newRedisStore(cfg.Redis)
+	} else {
+		l.store = newMemoryStore(time.Now)
+	}
+	return l
+}
+
+// Enabled reports whether the requests are limited at all
+func (l *Limiter) Enabled() bool {
+	return l != nil && (l.cfg.Identity.enabled() || l.cfg.Organization.enabled())
+}
+
+// Allow takes a token of the bucket of the identity and then of the
+// organization if the identity is allowed, the organization is skipped when
+// empty. The result is the one of the bucket limiting the request or else
+// of the one with the least requests remaining.
+func (l *Limiter) Allow(ctx context.Context, identity, orgID string) (Result, error) {
+	var res Result
+	if l.cfg.Identity.enabled() && identity != "" {
+		r, err := l.store.take(ctx, ScopeIdentity+":"+identity, l.cfg.Identity)
+		if err != nil {
+			return Result{Allowed: true}, err
+		}
+		r.Scope = ScopeIdentity
+		if !r.Allowed {
+			return r, nil
+		}
+		res = r
+	}
+	if l.cfg.Organization.enabled() && orgID != "" {
+		r, err := l.store.take(ctx, ScopeOrganization+":"+orgID, l.cfg.Organization)
+		if err != nil {
+			return Result{Allowed: true}, err
+		}
+		r.Scope = ScopeOrganization
+		if !r.Allowed || res.Limit == 0 || r.Remaining < res.Remaining {
+			res = r
+		}
+	}
+	if res.Limit == 0 {
+		res.Allowed = true
+	}
+	return res, nil
+}
+
+func (l *Limiter) Close() error {
+	return l.store.close()
+}
+
+// resultOf is the result of a bucket left with tokens after a request
+func resultOf(allowed bool, tokens float64, limit Limit) Result {
+	burst := limit.burst()
+	res := Result{
+		Allowed:   allowed,
+		Limit:     burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsOf((float64(burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		res.RetryAfter = secondsOf((1 - tokens) / limit.Rate)
+	}
+	return res
+}
+
+func secondsOf(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(cfg Config) (*Limiter, *clock) {
+	c := &clock{now: time.Date(2023, 1, 27, 10, 0, 0, 0, time.UTC)}
+	return &Limiter{cfg: cfg, store: newMemoryStore(c.Now)}, c
+}
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should not limit the requests without limits", func(t *testing.T) {
+		l, _ := newTestLimiter(Config{})
+		assert.False(t, l.Enabled())
+
+		res, err := l.Allow(ctx, "jane@odpf.io", "odpf")
+		require.NoError(t, err)
+		assert.Equal(t, Result{Allowed: true}, res)
+	})
+
+	t.Run("should limit the identity to its burst and refill it at its rate", func(t *testing.T) {
+		l, c := newTestLimiter(Config{Identity: Limit{Rate: 1, Burst: 2}})
+		assert.True(t, l.Enabled())
+
+		for remaining := 1; remaining >= 0; remaining-- {
+			res, err := l.Allow(ctx, "jane@odpf.io", "")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, ScopeIdentity, res.Scope)
+			assert.Equal(t, 2, res.Limit)
+			assert.Equal(t, remaining, res.Remaining)
+		}
+
+		res, err := l.Allow(ctx, "jane@odpf.io", "")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, time.Second, res.RetryAfter)
+		assert.Equal(t, 2*time.Second, res.Reset)
+
+		// other identities have their own bucket
+		res, err = l.Allow(ctx, "john@odpf.io", "")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+
+		c.now = c.now.Add(500 * time.Millisecond)
+		res, err = l.Allow(ctx, "jane@odpf.io", "")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+		c.now = c.now.Add(500 * time.Millisecond)
+		res, err = l.Allow(ctx, "jane@odpf.io", "")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+	})
+
+	t.Run("should limit the organization across identities", func(t *testing.T) {
+		l, _ := newTestLimiter(Config{
+			Identity:     Limit{Rate: 10},
+			Organization: Limit{Rate: 2},
+		})
+
+		res, err := l.Allow(ctx, "jane@odpf.io", "odpf")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, ScopeOrganization, res.Scope)
+		assert.Equal(t, 1, res.Remaining)
+
+		res, err = l.Allow(ctx, "john@odpf.io", "odpf")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+
+		res, err = l.Allow(ctx, "jack@odpf.io", "odpf")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, ScopeOrganization, res.Scope)
+
+		// the requests made in no organization are only limited by identity
+		res, err = l.Allow(ctx, "jack@odpf.io", "")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, ScopeIdentity, res.Scope)
+		assert.Equal(t, 10, res.Limit)
+	})
+
+	t.Run("should remove the buckets full again", func(t *testing.T) {
+		l, c := newTestLimiter(Config{Identity: Limit{Rate: 1, Burst: 5}})
+		_, err := l.Allow(ctx, "jane@odpf.io", "")
+		require.NoError(t, err)
+
+		c.now = c.now.Add(sweepInterval)
+		_, err = l.Allow(ctx, "john@odpf.io", "")
+		require.NoError(t, err)
+
+		buckets := l.store.(*memoryStore).buckets
+		assert.Len(t, buckets, 1)
+		assert.Contains(t, buckets, ScopeIdentity+":john@odpf.io")
+	})
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the buckets full again are removed
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// fill refills the bucket for the time passed since it was last updated
+func (b *bucket) fill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.burst()), b.tokens+elapsed*b.limit.Rate)
+	}
+	b.updated = now
+}
+
+// memoryStore keeps the buckets of the instance, every instance limits the
+// requests made to it on its own
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func newMemoryStore(now func() time.Time) *memoryStore {
+	return &memoryStore{
+		buckets:   map[string]*bucket{},
+		now:       now,
+		lastSweep: now(),
+	}
+}
+
+func (s *memoryStore) take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.burst()), updated: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	b.fill(now)
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return resultOf(allowed, b.tokens, limit), nil
+}
+
+// sweep removes the buckets full again, they are the same as new ones
+func (s *memoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		b.fill(now)
+		if b.tokens >= float64(b.limit.burst()) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func (s *memoryStore) close() error {
+	return nil
+}
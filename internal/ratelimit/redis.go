@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript takes a token of the bucket of KEYS[1] refilled with ARGV[1]
+// tokens a second up to ARGV[2] tokens, with the time of redis so the
+// instances share the same clock. Buckets expire once full again.
+var takeScript = redis.NewScript(`
+redis.replicate_commands()
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(b[1]) or burst
+local updated = tonumber(b[2]) or now
+if now > updated then
+  tokens = math.min(burst, tokens + (now - updated) * rate)
+end
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// redisStore keeps the buckets in redis, the buckets are shared by the
+// instances using the same redis
+type redisStore struct {
+	cfg    RedisConfig
+	client *redis.Client
+}
+
+func newRedisStore(cfg RedisConfig) *redisStore {
+	return &redisStore{
+		cfg: cfg,
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+			PoolSize: cfg.PoolSize,
+			// the timeout of a request is the deadline of its context
+			ContextTimeoutEnabled: true,
+		}),
+	}
+}
+
+func (s *redisStore) take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if s.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.Timeout)
+		defer cancel()
+	}
+
+	// the script is sent by its sha and loaded the first time redis
+	// doesn't know it
+	values, err := takeScript.Run(ctx, s.client, []string{s.cfg.KeyPrefix + key}, limit.Rate, limit.burst()).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("redis: unexpected reply %v", values)
+	}
+	allowed, _ := values[0].(int64)
+	tokens, err := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("redis: unexpected tokens %v", values[1])
+	}
+	return resultOf(allowed == 1, tokens, limit), nil
+}
+
+func (s *redisStore) close() error {
+	return s.client.Close()
+}
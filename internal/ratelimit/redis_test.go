@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisStore(t *testing.T) {
+	m := miniredis.RunT(t)
+	m.RequireAuth("secret")
+	now := time.Unix(1672531200, 0)
+	m.SetTime(now)
+
+	s := newRedisStore(RedisConfig{Addr: m.Addr(), Password: "secret", KeyPrefix: "shield:ratelimit:", Timeout: time.Second, PoolSize: 1})
+	defer s.close()
+	limit := Limit{Rate: 0.5, Burst: 3}
+
+	for _, remaining := range []int{2, 1, 0} {
+		res, err := s.take(context.Background(), "identity:jane@odpf.io", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, remaining, res.Remaining)
+	}
+	assert.True(t, m.Exists("shield:ratelimit:identity:jane@odpf.io"))
+
+	res, err := s.take(context.Background(), "identity:jane@odpf.io", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 2*time.Second, res.RetryAfter)
+
+	// the bucket is refilled with the time of redis
+	m.SetTime(now.Add(2 * time.Second))
+	res, err = s.take(context.Background(), "identity:jane@odpf.io", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestRedisStoreUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	l := New(Config{Identity: Limit{Rate: 1}, Redis: RedisConfig{Addr: addr, Timeout: time.Second}})
+	defer l.Close()
+	res, err := l.Allow(context.Background(), "jane@odpf.io", "")
+	assert.Error(t, err)
+	assert.True(t, res.Allowed)
+}
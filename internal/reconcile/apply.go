@@ -0,0 +1,171 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/odpf/shield/internal/schema"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Apply makes the pending changes of the plan in order, it stops at the
+// first failure leaving the changes applied before it in place
+func Apply(ctx context.Context, client Client, plan Plan) error {
+	for _, c := range plan {
+		if c.Action == ActionNone {
+			continue
+		}
+		if err := apply(ctx, client, c); err != nil {
+			return fmt.Errorf("could not %s %s: %w", c.Action, c.Resource.Name(), err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, client Client, c Change) error {
+	switch b := c.Resource.Body.(type) {
+	case *shieldv1beta1.OrganizationRequestBody:
+		if c.Action == ActionCreate {
+			_, err := client.CreateOrganization(ctx, &shieldv1beta1.CreateOrganizationRequest{Body: b})
+			return err
+		}
+		_, err := client.UpdateOrganization(ctx, &shieldv1beta1.UpdateOrganizationRequest{Id: c.ID, Body: b})
+		return err
+
+	case *shieldv1beta1.ProjectRequestBody:
+		body := proto.Clone(b).(*shieldv1beta1.ProjectRequestBody)
+		orgID, err := resolve(ctx, client, KindOrganization, b.GetOrgId())
+		if err != nil {
+			return err
+		}
+		body.OrgId = orgID
+
+		if c.Action == ActionCreate {
+			_, err := client.CreateProject(ctx, &shieldv1beta1.CreateProjectRequest{Body: body})
+			return err
+		}
+		_, err = client.UpdateProject(ctx, &shieldv1beta1.UpdateProjectRequest{Id: c.ID, Body: body})
+		return err
+
+	case *shieldv1beta1.GroupRequestBody:
+		body := proto.Clone(b).(*shieldv1beta1.GroupRequestBody)
+		orgID, err := resolve(ctx, client, KindOrganization, b.GetOrgId())
+		if err != nil {
+			return err
+		}
+		body.OrgId = orgID
+
+		if c.Action == ActionCreate {
+			_, err := client.CreateGroup(ctx, &shieldv1beta1.CreateGroupRequest{Body: body})
+			return err
+		}
+		_, err = client.UpdateGroup(ctx, &shieldv1beta1.UpdateGroupRequest{Id: c.ID, Body: body})
+		return err
+
+	case *shieldv1beta1.RoleRequestBody:
+		body := proto.Clone(b).(*shieldv1beta1.RoleRequestBody)
+		if orgRef := roleOrg(b); orgRef != "" {
+			orgID, err := resolve(ctx, client, KindOrganization, orgRef)
+			if err != nil {
+				return err
+			}
+			body.Metadata = withRoleOrg(b.GetMetadata(), orgID)
+		}
+
+		if c.Action == ActionCreate {
+			_, err := client.CreateRole(ctx, &shieldv1beta1.CreateRoleRequest{Body: body})
+			return err
+		}
+		_, err := client.UpdateRole(ctx, &shieldv1beta1.UpdateRoleRequest{Id: c.ID, Body: body})
+		return err
+
+	case *shieldv1beta1.PolicyRequestBody:
+		_, err := client.CreatePolicy(ctx, &shieldv1beta1.CreatePolicyRequest{Body: b})
+		return err
+
+	case *shieldv1beta1.ResourceRequestBody:
+		body := proto.Clone(b).(*shieldv1beta1.ResourceRequestBody)
+		projectID, err := resolve(ctx, client, KindProject, b.GetProjectId())
+		if err != nil {
+			return err
+		}
+		body.ProjectId = projectID
+		for _, rel := range body.GetRelations() {
+			if rel.Subject, err = resolveSubject(ctx, client, rel.GetSubject()); err != nil {
+				return err
+			}
+		}
+
+		_, err = client.CreateResource(ctx, &shieldv1beta1.CreateResourceRequest{Body: body})
+		return err
+
+	case *shieldv1beta1.RelationRequestBody:
+		body := proto.Clone(b).(*shieldv1beta1.RelationRequestBody)
+		objectID, err := resolve(ctx, client, namespaceKinds[b.GetObjectNamespace()], b.GetObjectId())
+		if err != nil {
+			return err
+		}
+		body.ObjectId = objectID
+		if body.Subject, err = resolveSubject(ctx, client, b.GetSubject()); err != nil {
+			return err
+		}
+
+		_, err = client.CreateRelation(ctx, &shieldv1beta1.CreateRelationRequest{Body: body})
+		return err
+
+	default:
+		return ErrUnknownKind
+	}
+}
+
+// resolve looks the organization, project or group up when applying as it
+// may have been created by an earlier change of the same plan
+func resolve(ctx context.Context, client Client, kind, ref string) (string, error) {
+	id, err := lookup(ctx, client, kind, ref)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s %s: %s", ErrUnresolvedReference, kind, ref, err)
+	}
+	return id, nil
+}
+
+// resolveSubject returns the subject of a relation as the server creates
+// it, users by email and groups by id
+func resolveSubject(ctx context.Context, client Client, subject string) (string, error) {
+	namespace, ref, _ := strings.Cut(subject, ":")
+	if isUserPrincipal(namespace) {
+		res, err := client.GetUser(ctx, &shieldv1beta1.GetUserRequest{Id: ref})
+		if err != nil {
+			return "", fmt.Errorf("%w: user %s: %s", ErrUnresolvedReference, ref, err)
+		}
+		return schema.UserPrincipal + ":" + res.GetUser().GetEmail(), nil
+	}
+
+	if _, ok := namespaceKinds[namespace]; !ok {
+		return subject, nil
+	}
+	id, err := resolve(ctx, client, namespaceKinds[namespace], ref)
+	if err != nil {
+		return "", err
+	}
+	return namespace + ":" + id, nil
+}
+
+// lookup returns the id of the organization, project or group with the id
+// or slug, references to other kinds are returned as they are
+func lookup(ctx context.Context, client Client, kind, ref string) (string, error) {
+	switch kind {
+	case KindOrganization:
+		res, err := client.GetOrganization(ctx, &shieldv1beta1.GetOrganizationRequest{Id: ref})
+		return res.GetOrganization().GetId(), err
+	case KindProject:
+		res, err := client.GetProject(ctx, &shieldv1beta1.GetProjectRequest{Id: ref})
+		return res.GetProject().GetId(), err
+	case KindGroup:
+		res, err := client.GetGroup(ctx, &shieldv1beta1.GetGroupRequest{Id: ref})
+		return res.GetGroup().GetId(), err
+	default:
+		return ref, nil
+	}
+}
@@ -0,0 +1,337 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/odpf/shield/internal/schema"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// ExportClient is the subset of the shield api used to export an organization
+type ExportClient interface {
+	GetOrganization(ctx context.Context, in *shieldv1beta1.GetOrganizationRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetOrganizationResponse, error)
+	ListProjects(ctx context.Context, in *shieldv1beta1.ListProjectsRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListProjectsResponse, error)
+	ListGroups(ctx context.Context, in *shieldv1beta1.ListGroupsRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListGroupsResponse, error)
+	ListRoles(ctx context.Context, in *shieldv1beta1.ListRolesRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListRolesResponse, error)
+	ListPolicies(ctx context.Context, in *shieldv1beta1.ListPoliciesRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListPoliciesResponse, error)
+	ListResources(ctx context.Context, in *shieldv1beta1.ListResourcesRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListResourcesResponse, error)
+	ListRelations(ctx context.Context, in *shieldv1beta1.ListRelationsRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListRelationsResponse, error)
+	GetUser(ctx context.Context, in *shieldv1beta1.GetUserRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetUserResponse, error)
+}
+
+// files are the files the resources of each kind are written to
+var files = map[string]string{
+	KindOrganization: "organization.yaml",
+	KindProject:      "projects.yaml",
+	KindGroup:        "groups.yaml",
+	KindRole:         "roles.yaml",
+	KindPolicy:       "policies.yaml",
+	KindResource:     "resources.yaml",
+	KindRelation:     "relations.yaml",
+}
+
+type exporter struct {
+	client ExportClient
+	org    *shieldv1beta1.Organization
+	// slugs are the slugs of the organization, its projects and groups by
+	// namespace and id
+	slugs map[string]map[string]string
+	// emails are the emails of the users by id
+	emails map[string]string
+	// listed are the relations of the server, listed once
+	listed []*shieldv1beta1.Relation
+}
+
+// Export returns the resources declaring the organization with its
+// projects, groups, custom roles and their policies, resources and the
+// relations granting users and groups roles on them. Organizations,
+// projects and groups are referred to by slug and users by email so the
+// resources can be applied to another server.
+func Export(ctx context.Context, client ExportClient, orgIDOrSlug string) ([]Resource, error) {
+	res, err := client.GetOrganization(ctx, &shieldv1beta1.GetOrganizationRequest{Id: orgIDOrSlug})
+	if err != nil {
+		return nil, err
+	}
+	org := res.GetOrganization()
+
+	e := &exporter{
+		client: client,
+		org:    org,
+		slugs: map[string]map[string]string{
+			schema.OrganizationNamespace: {org.GetId(): org.GetSlug()},
+			schema.ProjectNamespace:      {},
+			schema.GroupNamespace:        {},
+		},
+		emails: map[string]string{},
+	}
+
+	resources := []Resource{{Kind: KindOrganization, Body: &shieldv1beta1.OrganizationRequestBody{
+		Name:     org.GetName(),
+		Slug:     org.GetSlug(),
+		Metadata: org.GetMetadata(),
+	}}}
+	for _, export := range []func(ctx context.Context) ([]Resource, error){
+		e.projects, e.groups, e.roles, e.resources, e.relations,
+	} {
+		exported, err := export(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, exported...)
+	}
+
+	// sorted so exporting an unchanged organization writes the same files
+	sort.SliceStable(resources, func(i, j int) bool {
+		if kindOrder(resources[i].Kind) != kindOrder(resources[j].Kind) {
+			return kindOrder(resources[i].Kind) < kindOrder(resources[j].Kind)
+		}
+		return resources[i].Name() < resources[j].Name()
+	})
+	return resources, nil
+}
+
+func (e *exporter) projects(ctx context.Context) ([]Resource, error) {
+	res, err := e.client.ListProjects(ctx, &shieldv1beta1.ListProjectsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+	for _, prj := range res.GetProjects() {
+		if prj.GetOrgId() != e.org.GetId() {
+			continue
+		}
+		e.slugs[schema.ProjectNamespace][prj.GetId()] = prj.GetSlug()
+		resources = append(resources, Resource{Kind: KindProject, Body: &shieldv1beta1.ProjectRequestBody{
+			Name:     prj.GetName(),
+			Slug:     prj.GetSlug(),
+			OrgId:    e.org.GetSlug(),
+			Metadata: prj.GetMetadata(),
+		}})
+	}
+	return resources, nil
+}
+
+func (e *exporter) groups(ctx context.Context) ([]Resource, error) {
+	res, err := e.client.ListGroups(ctx, &shieldv1beta1.ListGroupsRequest{OrgId: e.org.GetId()})
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+	for _, grp := range res.GetGroups() {
+		e.slugs[schema.GroupNamespace][grp.GetId()] = grp.GetSlug()
+		resources = append(resources, Resource{Kind: KindGroup, Body: &shieldv1beta1.GroupRequestBody{
+			Name:     grp.GetName(),
+			Slug:     grp.GetSlug(),
+			OrgId:    e.org.GetSlug(),
+			Metadata: grp.GetMetadata(),
+		}})
+	}
+	return resources, nil
+}
+
+// roles returns the custom roles of the organization along with their
+// policies, the predefined roles are the same on every server
+func (e *exporter) roles(ctx context.Context) ([]Resource, error) {
+	res, err := e.client.ListRoles(ctx, &shieldv1beta1.ListRolesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+	custom := map[string]bool{}
+	for _, role := range res.GetRoles() {
+		body := &shieldv1beta1.RoleRequestBody{
+			Id:          role.GetId(),
+			Name:        role.GetName(),
+			Types:       role.GetTypes(),
+			NamespaceId: role.GetNamespaceId(),
+			Metadata:    role.GetMetadata(),
+		}
+		if roleOrg(body) != e.org.GetId() {
+			continue
+		}
+		body.Metadata = withRoleOrg(role.GetMetadata(), e.org.GetSlug())
+		custom[role.GetId()] = true
+		resources = append(resources, Resource{Kind: KindRole, Body: body})
+	}
+	if len(custom) == 0 {
+		return resources, nil
+	}
+
+	policies, err := e.client.ListPolicies(ctx, &shieldv1beta1.ListPoliciesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	for _, pol := range policies.GetPolicies() {
+		if !custom[pol.GetRoleId()] {
+			continue
+		}
+		resources = append(resources, Resource{Kind: KindPolicy, Body: &shieldv1beta1.PolicyRequestBody{
+			RoleId:      pol.GetRoleId(),
+			ActionId:    pol.GetActionId(),
+			NamespaceId: pol.GetNamespaceId(),
+		}})
+	}
+	return resources, nil
+}
+
+// resources returns the resources of the organization, the relations on a
+// resource are declared along with it as its id differs between servers
+func (e *exporter) resources(ctx context.Context) ([]Resource, error) {
+	res, err := e.client.ListResources(ctx, &shieldv1beta1.ListResourcesRequest{OrganizationId: e.org.GetId()})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.GetResources()) == 0 {
+		return nil, nil
+	}
+	relations, err := e.listRelations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+	for _, rsc := range res.GetResources() {
+		body := &shieldv1beta1.ResourceRequestBody{
+			Name:        rsc.GetName(),
+			ProjectId:   e.slugs[schema.ProjectNamespace][rsc.GetProject().GetId()],
+			NamespaceId: rsc.GetNamespace().GetId(),
+		}
+		if body.ProjectId == "" {
+			body.ProjectId = rsc.GetProject().GetId()
+		}
+		for _, rel := range relations {
+			if rel.GetObjectNamespace() != body.GetNamespaceId() || rel.GetObjectId() != rsc.GetId() {
+				continue
+			}
+			subject, ok, err := e.subject(ctx, rel.GetSubject())
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				body.Relations = append(body.Relations, &shieldv1beta1.Relation{Subject: subject, RoleName: rel.GetRoleName()})
+			}
+		}
+		resources = append(resources, Resource{Kind: KindResource, Body: body})
+	}
+	return resources, nil
+}
+
+// relations returns the relations granting users and groups roles on the
+// organization, its projects and groups, the relations between them are
+// made by the server when the projects and groups are created
+func (e *exporter) relations(ctx context.Context) ([]Resource, error) {
+	relations, err := e.listRelations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+	for _, rel := range relations {
+		object, ok := e.slugs[rel.GetObjectNamespace()][rel.GetObjectId()]
+		if !ok {
+			continue
+		}
+		subject, ok, err := e.subject(ctx, rel.GetSubject())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		resources = append(resources, Resource{Kind: KindRelation, Body: &shieldv1beta1.RelationRequestBody{
+			ObjectId:        object,
+			ObjectNamespace: rel.GetObjectNamespace(),
+			Subject:         subject,
+			RoleName:        rel.GetRoleName(),
+		}})
+	}
+	return resources, nil
+}
+
+func (e *exporter) listRelations(ctx context.Context) ([]*shieldv1beta1.Relation, error) {
+	if e.listed == nil {
+		res, err := e.client.ListRelations(ctx, &shieldv1beta1.ListRelationsRequest{})
+		if err != nil {
+			return nil, err
+		}
+		e.listed = res.GetRelations()
+	}
+	return e.listed, nil
+}
+
+// subject returns the subject of a relation with users referred to by email
+// and the groups of the organization by slug, it is false for subjects which
+// aren't users or groups
+func (e *exporter) subject(ctx context.Context, subject string) (string, bool, error) {
+	namespace, id, _ := strings.Cut(subject, ":")
+	switch namespace {
+	case schema.UserPrincipal:
+		email, ok := e.emails[id]
+		if !ok {
+			res, err := e.client.GetUser(ctx, &shieldv1beta1.GetUserRequest{Id: id})
+			if err != nil {
+				return "", false, fmt.Errorf("user %s: %w", id, err)
+			}
+			email = res.GetUser().GetEmail()
+			e.emails[id] = email
+		}
+		return namespace + ":" + email, true, nil
+	case schema.GroupPrincipal:
+		if slug, ok := e.slugs[schema.GroupNamespace][id]; ok {
+			return namespace + ":" + slug, true, nil
+		}
+		return subject, true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+// Write writes the resources to a yaml file of each kind in dir, in the
+// format Load reads, and returns the paths of the files written
+func Write(dir string, resources []Resource) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	byKind := map[string][]string{}
+	for _, r := range resources {
+		spec, err := protojson.Marshal(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name(), err)
+		}
+		j, err := json.Marshal(document{Kind: r.Kind, Spec: spec})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name(), err)
+		}
+		y, err := yaml.JSONToYAML(j)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name(), err)
+		}
+		byKind[r.Kind] = append(byKind[r.Kind], string(y))
+	}
+
+	var written []string
+	for _, kind := range kinds {
+		if len(byKind[kind]) == 0 {
+			continue
+		}
+		path := filepath.Join(dir, files[kind])
+		if err := os.WriteFile(path, []byte(strings.Join(byKind[kind], "---\n")), 0o644); err != nil {
+			return nil, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
@@ -0,0 +1,324 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/odpf/shield/internal/schema"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionNone   = "none"
+)
+
+var ErrUnresolvedReference = errors.New("unresolved reference")
+
+// Client is the subset of the shield api used to reconcile resources
+type Client interface {
+	GetOrganization(ctx context.Context, in *shieldv1beta1.GetOrganizationRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetOrganizationResponse, error)
+	CreateOrganization(ctx context.Context, in *shieldv1beta1.CreateOrganizationRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreateOrganizationResponse, error)
+	UpdateOrganization(ctx context.Context, in *shieldv1beta1.UpdateOrganizationRequest, opts ...grpc.CallOption) (*shieldv1beta1.UpdateOrganizationResponse, error)
+	GetProject(ctx context.Context, in *shieldv1beta1.GetProjectRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetProjectResponse, error)
+	CreateProject(ctx context.Context, in *shieldv1beta1.CreateProjectRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreateProjectResponse, error)
+	UpdateProject(ctx context.Context, in *shieldv1beta1.UpdateProjectRequest, opts ...grpc.CallOption) (*shieldv1beta1.UpdateProjectResponse, error)
+	GetGroup(ctx context.Context, in *shieldv1beta1.GetGroupRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetGroupResponse, error)
+	CreateGroup(ctx context.Context, in *shieldv1beta1.CreateGroupRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreateGroupResponse, error)
+	UpdateGroup(ctx context.Context, in *shieldv1beta1.UpdateGroupRequest, opts ...grpc.CallOption) (*shieldv1beta1.UpdateGroupResponse, error)
+	GetRole(ctx context.Context, in *shieldv1beta1.GetRoleRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetRoleResponse, error)
+	CreateRole(ctx context.Context, in *shieldv1beta1.CreateRoleRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreateRoleResponse, error)
+	UpdateRole(ctx context.Context, in *shieldv1beta1.UpdateRoleRequest, opts ...grpc.CallOption) (*shieldv1beta1.UpdateRoleResponse, error)
+	ListPolicies(ctx context.Context, in *shieldv1beta1.ListPoliciesRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListPoliciesResponse, error)
+	CreatePolicy(ctx context.Context, in *shieldv1beta1.CreatePolicyRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreatePolicyResponse, error)
+	ListResources(ctx context.Context, in *shieldv1beta1.ListResourcesRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListResourcesResponse, error)
+	CreateResource(ctx context.Context, in *shieldv1beta1.CreateResourceRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreateResourceResponse, error)
+	ListRelations(ctx context.Context, in *shieldv1beta1.ListRelationsRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListRelationsResponse, error)
+	CreateRelation(ctx context.Context, in *shieldv1beta1.CreateRelationRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreateRelationResponse, error)
+	GetUser(ctx context.Context, in *shieldv1beta1.GetUserRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetUserResponse, error)
+}
+
+// Change is what applying a resource does to the server, ID is the
+// id of the existing resource when it is updated
+type Change struct {
+	Action   string
+	Resource Resource
+	ID       string
+}
+
+type Plan []Change
+
+// Pending is the number of changes that modify the server
+func (p Plan) Pending() int {
+	n := 0
+	for _, c := range p {
+		if c.Action != ActionNone {
+			n++
+		}
+	}
+	return n
+}
+
+type planner struct {
+	client Client
+	// declared are the slugs of the organizations, projects and groups
+	// declared in the files, by kind
+	declared  map[string]map[string]bool
+	policies  []*shieldv1beta1.Policy
+	relations []*shieldv1beta1.Relation
+}
+
+// BuildPlan compares the resources with the server and returns the
+// changes needed in the order they have to be applied, organizations
+// first so the projects and groups referring to them can be created
+func BuildPlan(ctx context.Context, client Client, resources []Resource) (Plan, error) {
+	sorted := make([]Resource, len(resources))
+	copy(sorted, resources)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return kindOrder(sorted[i].Kind) < kindOrder(sorted[j].Kind)
+	})
+
+	p := &planner{client: client, declared: map[string]map[string]bool{
+		KindOrganization: {},
+		KindProject:      {},
+		KindGroup:        {},
+	}}
+	seen := map[string]string{}
+	for _, r := range sorted {
+		if src, ok := seen[r.Name()]; ok {
+			return nil, fmt.Errorf("%s: %s is already declared in %s", r.Source, r.Name(), src)
+		}
+		seen[r.Name()] = r.Source
+
+		switch b := r.Body.(type) {
+		case *shieldv1beta1.OrganizationRequestBody:
+			p.declared[KindOrganization][b.GetSlug()] = true
+		case *shieldv1beta1.ProjectRequestBody:
+			p.declared[KindProject][b.GetSlug()] = true
+		case *shieldv1beta1.GroupRequestBody:
+			p.declared[KindGroup][b.GetSlug()] = true
+		}
+	}
+
+	var plan Plan
+	for _, r := range sorted {
+		change, err := p.change(ctx, r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", r.Source, r.Name(), err)
+		}
+		plan = append(plan, change)
+	}
+	return plan, nil
+}
+
+func kindOrder(kind string) int {
+	for i, k := range kinds {
+		if k == kind {
+			return i
+		}
+	}
+	return len(kinds)
+}
+
+func (p *planner) change(ctx context.Context, r Resource) (Change, error) {
+	switch b := r.Body.(type) {
+	case *shieldv1beta1.OrganizationRequestBody:
+		res, err := p.client.GetOrganization(ctx, &shieldv1beta1.GetOrganizationRequest{Id: b.GetSlug()})
+		if err != nil {
+			return notFoundChange(r, err)
+		}
+		org := res.GetOrganization()
+		return updateChange(r, org.GetId(),
+			org.GetName() != b.GetName() || !metadataEqual(org.GetMetadata(), b.GetMetadata())), nil
+
+	case *shieldv1beta1.ProjectRequestBody:
+		orgID, err := p.resolve(ctx, KindOrganization, b.GetOrgId())
+		if err != nil {
+			return Change{}, err
+		}
+		res, err := p.client.GetProject(ctx, &shieldv1beta1.GetProjectRequest{Id: b.GetSlug()})
+		if err != nil {
+			return notFoundChange(r, err)
+		}
+		prj := res.GetProject()
+		return updateChange(r, prj.GetId(),
+			prj.GetName() != b.GetName() || prj.GetOrgId() != orgID || !metadataEqual(prj.GetMetadata(), b.GetMetadata())), nil
+
+	case *shieldv1beta1.GroupRequestBody:
+		orgID, err := p.resolve(ctx, KindOrganization, b.GetOrgId())
+		if err != nil {
+			return Change{}, err
+		}
+		res, err := p.client.GetGroup(ctx, &shieldv1beta1.GetGroupRequest{Id: b.GetSlug()})
+		if err != nil {
+			return notFoundChange(r, err)
+		}
+		grp := res.GetGroup()
+		return updateChange(r, grp.GetId(),
+			grp.GetName() != b.GetName() || grp.GetOrgId() != orgID || !metadataEqual(grp.GetMetadata(), b.GetMetadata())), nil
+
+	case *shieldv1beta1.RoleRequestBody:
+		meta := b.GetMetadata()
+		if orgRef := roleOrg(b); orgRef != "" {
+			orgID, err := p.resolve(ctx, KindOrganization, orgRef)
+			if err != nil {
+				return Change{}, err
+			}
+			meta = withRoleOrg(meta, orgID)
+		}
+		res, err := p.client.GetRole(ctx, &shieldv1beta1.GetRoleRequest{Id: b.GetId()})
+		if err != nil {
+			return notFoundChange(r, err)
+		}
+		role := res.GetRole()
+		return updateChange(r, role.GetId(),
+			role.GetName() != b.GetName() ||
+				role.GetNamespaceId() != b.GetNamespaceId() ||
+				!stringsEqual(role.GetTypes(), b.GetTypes()) ||
+				!metadataEqual(role.GetMetadata(), meta)), nil
+
+	case *shieldv1beta1.PolicyRequestBody:
+		if p.policies == nil {
+			res, err := p.client.ListPolicies(ctx, &shieldv1beta1.ListPoliciesRequest{})
+			if err != nil {
+				return Change{}, err
+			}
+			p.policies = res.GetPolicies()
+		}
+		for _, pol := range p.policies {
+			if pol.GetRoleId() == b.GetRoleId() && pol.GetActionId() == b.GetActionId() && pol.GetNamespaceId() == b.GetNamespaceId() {
+				return Change{Action: ActionNone, Resource: r, ID: pol.GetId()}, nil
+			}
+		}
+		return Change{Action: ActionCreate, Resource: r}, nil
+
+	case *shieldv1beta1.ResourceRequestBody:
+		projectID, err := p.resolve(ctx, KindProject, b.GetProjectId())
+		if err != nil {
+			return Change{}, err
+		}
+		if projectID == "" {
+			return Change{Action: ActionCreate, Resource: r}, nil
+		}
+		res, err := p.client.ListResources(ctx, &shieldv1beta1.ListResourcesRequest{ProjectId: projectID, NamespaceId: b.GetNamespaceId()})
+		if err != nil {
+			return Change{}, err
+		}
+		// the relations of a resource are only created along with it
+		for _, rsc := range res.GetResources() {
+			if rsc.GetName() == b.GetName() {
+				return Change{Action: ActionNone, Resource: r, ID: rsc.GetId()}, nil
+			}
+		}
+		return Change{Action: ActionCreate, Resource: r}, nil
+
+	case *shieldv1beta1.RelationRequestBody:
+		objectID, err := p.resolve(ctx, namespaceKinds[b.GetObjectNamespace()], b.GetObjectId())
+		if err != nil {
+			return Change{}, err
+		}
+		subject, err := p.subject(ctx, b.GetSubject())
+		if err != nil {
+			return Change{}, err
+		}
+		if objectID == "" || subject == "" {
+			return Change{Action: ActionCreate, Resource: r}, nil
+		}
+
+		if p.relations == nil {
+			res, err := p.client.ListRelations(ctx, &shieldv1beta1.ListRelationsRequest{})
+			if err != nil {
+				return Change{}, err
+			}
+			p.relations = res.GetRelations()
+		}
+		for _, rel := range p.relations {
+			if rel.GetObjectId() == objectID && rel.GetObjectNamespace() == b.GetObjectNamespace() &&
+				rel.GetSubject() == subject && rel.GetRoleName() == b.GetRoleName() {
+				return Change{Action: ActionNone, Resource: r, ID: rel.GetId()}, nil
+			}
+		}
+		return Change{Action: ActionCreate, Resource: r}, nil
+
+	default:
+		return Change{}, ErrUnknownKind
+	}
+}
+
+// resolve returns the id of the organization, project or group a resource
+// refers to by id or slug, one declared in the files but not created yet
+// has no id. References to other kinds are ids already.
+func (p *planner) resolve(ctx context.Context, kind, ref string) (string, error) {
+	id, err := lookup(ctx, p.client, kind, ref)
+	if err == nil {
+		return id, nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return "", err
+	}
+	if p.declared[kind][ref] {
+		return "", nil
+	}
+	return "", fmt.Errorf("%w: %s %s is neither declared nor on the server", ErrUnresolvedReference, kind, ref)
+}
+
+// subject returns the subject of a relation as the server lists it, users
+// can be referred to by email and groups by slug
+func (p *planner) subject(ctx context.Context, subject string) (string, error) {
+	namespace, ref, _ := strings.Cut(subject, ":")
+	if isUserPrincipal(namespace) {
+		res, err := p.client.GetUser(ctx, &shieldv1beta1.GetUserRequest{Id: ref})
+		if err != nil {
+			return "", fmt.Errorf("%w: user %s: %s", ErrUnresolvedReference, ref, err)
+		}
+		return schema.UserPrincipal + ":" + res.GetUser().GetId(), nil
+	}
+
+	id, err := p.resolve(ctx, namespaceKinds[namespace], ref)
+	if err != nil || id == "" {
+		return "", err
+	}
+	return namespace + ":" + id, nil
+}
+
+func notFoundChange(r Resource, err error) (Change, error) {
+	if status.Code(err) != codes.NotFound {
+		return Change{}, err
+	}
+	return Change{Action: ActionCreate, Resource: r}, nil
+}
+
+func updateChange(r Resource, id string, changed bool) Change {
+	if !changed {
+		return Change{Action: ActionNone, Resource: r, ID: id}
+	}
+	return Change{Action: ActionUpdate, Resource: r, ID: id}
+}
+
+func metadataEqual(a, b *structpb.Struct) bool {
+	if len(a.GetFields()) == 0 && len(b.GetFields()) == 0 {
+		return true
+	}
+	return proto.Equal(a, b)
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,320 @@
+package reconcile_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/shield/internal/reconcile"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type fakeClient struct {
+	reconcile.Client
+
+	orgs      map[string]*shieldv1beta1.Organization
+	projects  map[string]*shieldv1beta1.Project
+	policies  []*shieldv1beta1.Policy
+	relations []*shieldv1beta1.Relation
+	calls     []string
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		orgs:     map[string]*shieldv1beta1.Organization{},
+		projects: map[string]*shieldv1beta1.Project{},
+	}
+}
+
+func (c *fakeClient) GetOrganization(ctx context.Context, in *shieldv1beta1.GetOrganizationRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetOrganizationResponse, error) {
+	for _, o := range c.orgs {
+		if o.GetId() == in.GetId() || o.GetSlug() == in.GetId() {
+			return &shieldv1beta1.GetOrganizationResponse{Organization: o}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "org doesn't exist")
+}
+
+func (c *fakeClient) CreateOrganization(ctx context.Context, in *shieldv1beta1.CreateOrganizationRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreateOrganizationResponse, error) {
+	c.calls = append(c.calls, "create organization "+in.GetBody().GetSlug())
+	org := &shieldv1beta1.Organization{Id: "org-" + in.GetBody().GetSlug(), Name: in.GetBody().GetName(), Slug: in.GetBody().GetSlug()}
+	c.orgs[org.GetSlug()] = org
+	return &shieldv1beta1.CreateOrganizationResponse{Organization: org}, nil
+}
+
+func (c *fakeClient) UpdateOrganization(ctx context.Context, in *shieldv1beta1.UpdateOrganizationRequest, opts ...grpc.CallOption) (*shieldv1beta1.UpdateOrganizationResponse, error) {
+	c.calls = append(c.calls, "update organization "+in.GetId())
+	return &shieldv1beta1.UpdateOrganizationResponse{}, nil
+}
+
+func (c *fakeClient) GetProject(ctx context.Context, in *shieldv1beta1.GetProjectRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetProjectResponse, error) {
+	if p, ok := c.projects[in.GetId()]; ok {
+		return &shieldv1beta1.GetProjectResponse{Project: p}, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "project doesn't exist")
+}
+
+func (c *fakeClient) CreateProject(ctx context.Context, in *shieldv1beta1.CreateProjectRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreateProjectResponse, error) {
+	c.calls = append(c.calls, "create project "+in.GetBody().GetSlug()+" in "+in.GetBody().GetOrgId())
+	return &shieldv1beta1.CreateProjectResponse{}, nil
+}
+
+func (c *fakeClient) ListPolicies(ctx context.Context, in *shieldv1beta1.ListPoliciesRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListPoliciesResponse, error) {
+	return &shieldv1beta1.ListPoliciesResponse{Policies: c.policies}, nil
+}
+
+func (c *fakeClient) ListRelations(ctx context.Context, in *shieldv1beta1.ListRelationsRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListRelationsResponse, error) {
+	return &shieldv1beta1.ListRelationsResponse{Relations: c.relations}, nil
+}
+
+func (c *fakeClient) CreateRelation(ctx context.Context, in *shieldv1beta1.CreateRelationRequest, opts ...grpc.CallOption) (*shieldv1beta1.CreateRelationResponse, error) {
+	c.calls = append(c.calls, "create relation "+in.GetBody().GetObjectId()+"#"+in.GetBody().GetRoleName()+"@"+in.GetBody().GetSubject())
+	return &shieldv1beta1.CreateRelationResponse{}, nil
+}
+
+func (c *fakeClient) GetUser(ctx context.Context, in *shieldv1beta1.GetUserRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetUserResponse, error) {
+	if in.GetId() == "alice@odpf.io" || in.GetId() == "u-alice" {
+		return &shieldv1beta1.GetUserResponse{User: &shieldv1beta1.User{Id: "u-alice", Email: "alice@odpf.io"}}, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "user doesn't exist")
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should load every document of the yaml and json files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "orgs.yaml", `kind: organization
+spec:
+  name: ODPF
+  slug: odpf
+---
+kind: project
+spec:
+  name: Shield
+  slug: shield
+  orgId: odpf
+`)
+		writeFile(t, dir, "policy.json", `{"kind": "policy", "spec": {"roleId": "owner", "actionId": "view", "namespaceId": "project"}}`)
+		writeFile(t, dir, "README.md", "not a resource")
+
+		resources, err := reconcile.Load(dir)
+		require.NoError(t, err)
+
+		var names []string
+		for _, r := range resources {
+			names = append(names, r.Name())
+		}
+		assert.Equal(t, []string{"organization/odpf", "project/shield", "policy/project:owner:view"}, names)
+	})
+
+	t.Run("should return error if the kind is unknown", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "user.yaml", "kind: user\nspec:\n  name: john\n")
+
+		_, err := reconcile.Load(dir)
+		assert.ErrorIs(t, err, reconcile.ErrUnknownKind)
+	})
+
+	t.Run("should return error if the spec has unknown fields", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "org.yaml", "kind: organization\nspec:\n  slugs: odpf\n")
+
+		_, err := reconcile.Load(dir)
+		assert.ErrorIs(t, err, reconcile.ErrInvalidSpec)
+	})
+
+	t.Run("should return error if there are no resources", func(t *testing.T) {
+		_, err := reconcile.Load(t.TempDir())
+		assert.ErrorIs(t, err, reconcile.ErrNoResources)
+	})
+}
+
+func TestBuildPlanAndApply(t *testing.T) {
+	metadata, err := structpb.NewStruct(map[string]interface{}{"team": "iam"})
+	require.NoError(t, err)
+
+	org := reconcile.Resource{Kind: reconcile.KindOrganization, Body: &shieldv1beta1.OrganizationRequestBody{Name: "ODPF", Slug: "odpf", Metadata: metadata}}
+	prj := reconcile.Resource{Kind: reconcile.KindProject, Body: &shieldv1beta1.ProjectRequestBody{Name: "Shield", Slug: "shield", OrgId: "odpf"}}
+	pol := reconcile.Resource{Kind: reconcile.KindPolicy, Body: &shieldv1beta1.PolicyRequestBody{RoleId: "owner", ActionId: "view", NamespaceId: "project"}}
+
+	t.Run("should order the changes by kind and create the missing resources", func(t *testing.T) {
+		client := newFakeClient()
+
+		plan, err := reconcile.BuildPlan(context.Background(), client, []reconcile.Resource{pol, prj, org})
+		require.NoError(t, err)
+		require.Len(t, plan, 3)
+		assert.Equal(t, reconcile.KindOrganization, plan[0].Resource.Kind)
+		assert.Equal(t, reconcile.KindProject, plan[1].Resource.Kind)
+		assert.Equal(t, 3, plan.Pending())
+
+		client.policies = []*shieldv1beta1.Policy{{Id: "p1", RoleId: "owner", ActionId: "view", NamespaceId: "project"}}
+		err = reconcile.Apply(context.Background(), client, plan[:2])
+		require.NoError(t, err)
+		assert.Equal(t, []string{"create organization odpf", "create project shield in org-odpf"}, client.calls)
+	})
+
+	t.Run("should only update the resources which differ from the server", func(t *testing.T) {
+		client := newFakeClient()
+		client.orgs["odpf"] = &shieldv1beta1.Organization{Id: "org-odpf", Name: "ODPF", Slug: "odpf"}
+		client.projects["shield"] = &shieldv1beta1.Project{Id: "prj-shield", Name: "Shield", Slug: "shield", OrgId: "org-odpf"}
+		client.policies = []*shieldv1beta1.Policy{{Id: "p1", RoleId: "owner", ActionId: "view", NamespaceId: "project"}}
+
+		plan, err := reconcile.BuildPlan(context.Background(), client, []reconcile.Resource{org, prj, pol})
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.ActionUpdate, plan[0].Action)
+		assert.Equal(t, "org-odpf", plan[0].ID)
+		assert.Equal(t, reconcile.ActionNone, plan[1].Action)
+		assert.Equal(t, reconcile.ActionNone, plan[2].Action)
+		assert.Equal(t, 1, plan.Pending())
+	})
+
+	t.Run("should resolve the slugs and emails relations refer to", func(t *testing.T) {
+		client := newFakeClient()
+		client.orgs["odpf"] = &shieldv1beta1.Organization{Id: "org-odpf", Name: "ODPF", Slug: "odpf"}
+		client.projects["shield"] = &shieldv1beta1.Project{Id: "prj-shield", Name: "Shield", Slug: "shield", OrgId: "org-odpf"}
+		client.relations = []*shieldv1beta1.Relation{{Id: "r1", ObjectId: "prj-shield", ObjectNamespace: "shield/project", Subject: "shield/user:u-alice", RoleName: "owner"}}
+
+		owner := reconcile.Resource{Kind: reconcile.KindRelation, Body: &shieldv1beta1.RelationRequestBody{ObjectId: "shield", ObjectNamespace: "shield/project", Subject: "shield/user:alice@odpf.io", RoleName: "owner"}}
+		viewer := reconcile.Resource{Kind: reconcile.KindRelation, Body: &shieldv1beta1.RelationRequestBody{ObjectId: "odpf", ObjectNamespace: "shield/organization", Subject: "shield/user:u-alice", RoleName: "viewer"}}
+		plan, err := reconcile.BuildPlan(context.Background(), client, []reconcile.Resource{owner, viewer})
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.ActionNone, plan[0].Action)
+		assert.Equal(t, reconcile.ActionCreate, plan[1].Action)
+
+		require.NoError(t, reconcile.Apply(context.Background(), client, plan))
+		assert.Equal(t, []string{"create relation org-odpf#viewer@shield/user:alice@odpf.io"}, client.calls)
+	})
+
+	t.Run("should return error if a project refers to an unknown organization", func(t *testing.T) {
+		_, err := reconcile.BuildPlan(context.Background(), newFakeClient(), []reconcile.Resource{prj})
+		assert.ErrorIs(t, err, reconcile.ErrUnresolvedReference)
+	})
+
+	t.Run("should return error if a resource is declared twice", func(t *testing.T) {
+		_, err := reconcile.BuildPlan(context.Background(), newFakeClient(), []reconcile.Resource{org, org})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error if the server fails", func(t *testing.T) {
+		client := &failingClient{fakeClient: newFakeClient()}
+		_, err := reconcile.BuildPlan(context.Background(), client, []reconcile.Resource{org})
+		assert.Equal(t, codes.Unavailable, status.Code(errors.Unwrap(err)))
+	})
+}
+
+type failingClient struct {
+	*fakeClient
+}
+
+func (c *failingClient) GetOrganization(ctx context.Context, in *shieldv1beta1.GetOrganizationRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetOrganizationResponse, error) {
+	return nil, status.Errorf(codes.Unavailable, "unavailable")
+}
+
+type exportClient struct {
+	reconcile.ExportClient
+}
+
+func (exportClient) GetOrganization(ctx context.Context, in *shieldv1beta1.GetOrganizationRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetOrganizationResponse, error) {
+	return &shieldv1beta1.GetOrganizationResponse{Organization: &shieldv1beta1.Organization{Id: "org-odpf", Name: "ODPF", Slug: "odpf"}}, nil
+}
+
+func (exportClient) ListProjects(ctx context.Context, in *shieldv1beta1.ListProjectsRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListProjectsResponse, error) {
+	return &shieldv1beta1.ListProjectsResponse{Projects: []*shieldv1beta1.Project{
+		{Id: "prj-shield", Name: "Shield", Slug: "shield", OrgId: "org-odpf"},
+		{Id: "prj-other", Name: "Other", Slug: "other", OrgId: "org-other"},
+	}}, nil
+}
+
+func (exportClient) ListGroups(ctx context.Context, in *shieldv1beta1.ListGroupsRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListGroupsResponse, error) {
+	return &shieldv1beta1.ListGroupsResponse{Groups: []*shieldv1beta1.Group{{Id: "grp-data", Name: "Data", Slug: "data", OrgId: "org-odpf"}}}, nil
+}
+
+func (exportClient) ListRoles(ctx context.Context, in *shieldv1beta1.ListRolesRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListRolesResponse, error) {
+	metadata, err := structpb.NewStruct(map[string]interface{}{"org_id": "org-odpf"})
+	if err != nil {
+		return nil, err
+	}
+	return &shieldv1beta1.ListRolesResponse{Roles: []*shieldv1beta1.Role{
+		{Id: "shield/project:owner", Name: "Owner", NamespaceId: "shield/project"},
+		{Id: "shield/project:auditor", Name: "Auditor", NamespaceId: "shield/project", Metadata: metadata},
+	}}, nil
+}
+
+func (exportClient) ListPolicies(ctx context.Context, in *shieldv1beta1.ListPoliciesRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListPoliciesResponse, error) {
+	return &shieldv1beta1.ListPoliciesResponse{Policies: []*shieldv1beta1.Policy{
+		{Id: "p1", RoleId: "shield/project:owner", ActionId: "edit", NamespaceId: "shield/project"},
+		{Id: "p2", RoleId: "shield/project:auditor", ActionId: "view", NamespaceId: "shield/project"},
+	}}, nil
+}
+
+func (exportClient) ListResources(ctx context.Context, in *shieldv1beta1.ListResourcesRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListResourcesResponse, error) {
+	return &shieldv1beta1.ListResourcesResponse{Resources: []*shieldv1beta1.Resource{{
+		Id:        "rsc-1",
+		Name:      "kafka-1",
+		Project:   &shieldv1beta1.Project{Id: "prj-shield"},
+		Namespace: &shieldv1beta1.Namespace{Id: "entropy/kafka"},
+	}}}, nil
+}
+
+func (exportClient) ListRelations(ctx context.Context, in *shieldv1beta1.ListRelationsRequest, opts ...grpc.CallOption) (*shieldv1beta1.ListRelationsResponse, error) {
+	return &shieldv1beta1.ListRelationsResponse{Relations: []*shieldv1beta1.Relation{
+		{Id: "r1", ObjectId: "grp-data", ObjectNamespace: "shield/group", Subject: "shield/user:u-alice", RoleName: "member"},
+		{Id: "r2", ObjectId: "prj-shield", ObjectNamespace: "shield/project", Subject: "shield/group:grp-data", RoleName: "shield/project:auditor"},
+		{Id: "r3", ObjectId: "prj-shield", ObjectNamespace: "shield/project", Subject: "shield/organization:org-odpf", RoleName: "organization"},
+		{Id: "r4", ObjectId: "prj-other", ObjectNamespace: "shield/project", Subject: "shield/user:u-alice", RoleName: "owner"},
+		{Id: "r5", ObjectId: "rsc-1", ObjectNamespace: "entropy/kafka", Subject: "shield/user:u-alice", RoleName: "owner"},
+	}}, nil
+}
+
+func (exportClient) GetUser(ctx context.Context, in *shieldv1beta1.GetUserRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetUserResponse, error) {
+	return &shieldv1beta1.GetUserResponse{User: &shieldv1beta1.User{Id: "u-alice", Email: "alice@odpf.io"}}, nil
+}
+
+func TestExport(t *testing.T) {
+	resources, err := reconcile.Export(context.Background(), exportClient{}, "odpf")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	files, err := reconcile.Write(dir, resources)
+	require.NoError(t, err)
+	assert.Len(t, files, 7)
+
+	loaded, err := reconcile.Load(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, r := range loaded {
+		names = append(names, r.Name())
+	}
+	assert.ElementsMatch(t, []string{
+		"organization/odpf",
+		"project/shield",
+		"group/data",
+		"role/shield/project:auditor",
+		"policy/shield/project:shield/project:auditor:view",
+		"resource/entropy/kafka:shield/kafka-1",
+		"relation/shield/group:data#member@shield/user:alice@odpf.io",
+		"relation/shield/project:shield#shield/project:auditor@shield/group:data",
+	}, names)
+
+	for _, r := range loaded {
+		switch b := r.Body.(type) {
+		case *shieldv1beta1.RoleRequestBody:
+			assert.Equal(t, "odpf", b.GetMetadata().AsMap()["org_id"])
+		case *shieldv1beta1.ResourceRequestBody:
+			require.Len(t, b.GetRelations(), 1)
+			assert.Equal(t, "shield/user:alice@odpf.io", b.GetRelations()[0].GetSubject())
+		}
+	}
+}
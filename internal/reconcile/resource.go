@@ -0,0 +1,203 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/odpf/shield/internal/schema"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	KindOrganization = "organization"
+	KindProject      = "project"
+	KindGroup        = "group"
+	KindRole         = "role"
+	KindPolicy       = "policy"
+	KindResource     = "resource"
+	KindRelation     = "relation"
+)
+
+// kinds are in the order they are applied, a resource can only
+// refer to resources of the kinds applied before its own
+var kinds = []string{KindOrganization, KindProject, KindGroup, KindRole, KindPolicy, KindResource, KindRelation}
+
+// namespaceKinds are the kinds of the namespaces of the objects and
+// subjects of relations which can be referred to by slug
+var namespaceKinds = map[string]string{
+	schema.OrganizationNamespace: KindOrganization,
+	schema.ProjectNamespace:      KindProject,
+	schema.GroupNamespace:        KindGroup,
+}
+
+// roleOrgMetadataKey carries the organization of a custom role in the
+// metadata of its spec, by id or slug
+const roleOrgMetadataKey = "org_id"
+
+var (
+	ErrUnknownKind = errors.New("unknown resource kind")
+	ErrInvalidSpec = errors.New("invalid resource spec")
+	ErrNoResources = errors.New("no resources found")
+)
+
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// Resource is a resource declared in a file, Body is the request body
+// used to create or update the resource of that kind
+type Resource struct {
+	Kind   string
+	Source string
+	Body   proto.Message
+}
+
+type document struct {
+	Kind string          `json:"kind"`
+	Spec json.RawMessage `json:"spec"`
+}
+
+// Name identifies the resource in the plan and in errors
+func (r Resource) Name() string {
+	switch b := r.Body.(type) {
+	case *shieldv1beta1.OrganizationRequestBody:
+		return r.Kind + "/" + b.GetSlug()
+	case *shieldv1beta1.ProjectRequestBody:
+		return r.Kind + "/" + b.GetSlug()
+	case *shieldv1beta1.GroupRequestBody:
+		return r.Kind + "/" + b.GetSlug()
+	case *shieldv1beta1.RoleRequestBody:
+		return r.Kind + "/" + b.GetId()
+	case *shieldv1beta1.PolicyRequestBody:
+		return fmt.Sprintf("%s/%s:%s:%s", r.Kind, b.GetNamespaceId(), b.GetRoleId(), b.GetActionId())
+	case *shieldv1beta1.ResourceRequestBody:
+		return fmt.Sprintf("%s/%s:%s/%s", r.Kind, b.GetNamespaceId(), b.GetProjectId(), b.GetName())
+	case *shieldv1beta1.RelationRequestBody:
+		return fmt.Sprintf("%s/%s:%s#%s@%s", r.Kind, b.GetObjectNamespace(), b.GetObjectId(), b.GetRoleName(), b.GetSubject())
+	default:
+		return r.Kind
+	}
+}
+
+func newBody(kind string) (proto.Message, error) {
+	switch kind {
+	case KindOrganization:
+		return &shieldv1beta1.OrganizationRequestBody{}, nil
+	case KindProject:
+		return &shieldv1beta1.ProjectRequestBody{}, nil
+	case KindGroup:
+		return &shieldv1beta1.GroupRequestBody{}, nil
+	case KindRole:
+		return &shieldv1beta1.RoleRequestBody{}, nil
+	case KindPolicy:
+		return &shieldv1beta1.PolicyRequestBody{}, nil
+	case KindResource:
+		return &shieldv1beta1.ResourceRequestBody{}, nil
+	case KindRelation:
+		return &shieldv1beta1.RelationRequestBody{}, nil
+	default:
+		return nil, fmt.Errorf("%w '%s', valid kinds are %s", ErrUnknownKind, kind, strings.Join(kinds, ", "))
+	}
+}
+
+// Load reads the resources of every yaml and json file under dir, a yaml
+// file can declare several resources separated by ---
+func Load(dir string) ([]Resource, error) {
+	var resources []Resource
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		fileResources, err := parse(path, b)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, fileResources...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoResources, dir)
+	}
+	return resources, nil
+}
+
+func parse(source string, b []byte) ([]Resource, error) {
+	var resources []Resource
+	for i, raw := range documentSeparator.Split(string(b), -1) {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		j, err := yaml.YAMLToJSON([]byte(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", source, i+1, err)
+		}
+
+		var doc document
+		if err := json.Unmarshal(j, &doc); err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", source, i+1, err)
+		}
+
+		body, err := newBody(doc.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", source, i+1, err)
+		}
+		if len(doc.Spec) == 0 {
+			return nil, fmt.Errorf("%s: document %d: %w: spec is missing", source, i+1, ErrInvalidSpec)
+		}
+		if err := protojson.Unmarshal(doc.Spec, body); err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w: %s", source, i+1, ErrInvalidSpec, err)
+		}
+		if v, ok := body.(interface{ ValidateAll() error }); ok {
+			if err := v.ValidateAll(); err != nil {
+				return nil, fmt.Errorf("%s: document %d: %w: %s", source, i+1, ErrInvalidSpec, err)
+			}
+		}
+
+		resources = append(resources, Resource{Kind: doc.Kind, Source: source, Body: body})
+	}
+	return resources, nil
+}
+
+func isUserPrincipal(namespace string) bool {
+	return namespace == schema.UserPrincipal || namespace == "user"
+}
+
+// roleOrg is the organization of a custom role
+func roleOrg(b *shieldv1beta1.RoleRequestBody) string {
+	return b.GetMetadata().GetFields()[roleOrgMetadataKey].GetStringValue()
+}
+
+// withRoleOrg returns a copy of the metadata with the organization of the
+// custom role set to orgID
+func withRoleOrg(meta *structpb.Struct, orgID string) *structpb.Struct {
+	withOrg := &structpb.Struct{Fields: map[string]*structpb.Value{}}
+	for k, v := range meta.GetFields() {
+		withOrg.Fields[k] = v
+	}
+	withOrg.Fields[roleOrgMetadataKey] = structpb.NewStringValue(orgID)
+	return withOrg
+}
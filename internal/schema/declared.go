@@ -0,0 +1,121 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/role"
+)
+
+// Declared is what the namespace configs make in postgres, the roles of a
+// namespace include the relations to its inherited namespaces
+type Declared struct {
+	Namespaces []namespace.Namespace
+	Roles      []role.Role
+	Actions    []action.Action
+	Policies   []policy.Policy
+}
+
+// Declare returns the namespaces, roles, actions and policies of the
+// namespace configs, sorted by namespace
+func Declare(configs NamespaceConfigMapType) (Declared, error) {
+	var d Declared
+	for _, namespaceID := range sortedKeys(configs) {
+		v := configs[namespaceID]
+
+		backend := ""
+		resourceType := ""
+		if v.Type == ResourceGroupNamespace {
+			st := strings.Split(namespaceID, "/")
+			backend = st[0]
+			resourceType = st[1]
+		}
+		d.Namespaces = append(d.Namespaces, namespace.Namespace{
+			ID:           namespaceID,
+			Name:         namespaceID,
+			Backend:      backend,
+			ResourceType: resourceType,
+		})
+
+		for _, roleID := range sortedKeys(v.Roles) {
+			d.Roles = append(d.Roles, role.Role{
+				ID:          GetRoleID(namespaceID, roleID),
+				Name:        roleID,
+				Types:       v.Roles[roleID],
+				NamespaceID: namespaceID,
+			})
+		}
+		for _, ins := range v.InheritedNamespaces {
+			d.Roles = append(d.Roles, role.Role{
+				ID:          GetRoleID(namespaceID, ins.Name),
+				Name:        ins.Name,
+				Types:       []string{ins.NamespaceId},
+				NamespaceID: namespaceID,
+			})
+		}
+
+		// IMP: we should depreciate actions with principals
+		for _, actionID := range sortedKeys(v.Permissions) {
+			d.Actions = append(d.Actions, action.Action{
+				ID:          fmt.Sprintf("%s.%s", actionID, namespaceID),
+				Name:        actionID,
+				NamespaceID: namespaceID,
+			})
+
+			for _, r := range v.Permissions[actionID] {
+				transformedRole, err := getRoleAndPrincipal(r, namespaceID)
+				if err != nil {
+					return Declared{}, err
+				}
+				roleNamespace := GetNamespace(transformedRole.NamespaceID)
+
+				// a permission has no role of its own, the authz engine
+				// resolves it through the permission or the relation
+				if _, ok := configs[roleNamespace].Permissions[transformedRole.ID]; ok {
+					continue
+				}
+
+				if _, ok := configs[roleNamespace].Roles[transformedRole.ID]; !ok {
+					return Declared{}, fmt.Errorf("role %s not associated with namespace: %s", transformedRole.ID, transformedRole.NamespaceID)
+				}
+
+				d.Policies = append(d.Policies, policy.Policy{
+					RoleID:      GetRoleID(roleNamespace, transformedRole.ID),
+					NamespaceID: namespaceID,
+					ActionID:    fmt.Sprintf("%s.%s", actionID, namespaceID),
+				})
+			}
+		}
+	}
+	return d, nil
+}
+
+// create creates what the namespace configs declare, the namespaces, roles
+// and actions are upserted and the policies stored without their grants
+func (s SchemaService) create(ctx context.Context, d Declared) error {
+	for _, ns := range d.Namespaces {
+		if _, err := s.namespaceService.Create(ctx, ns); err != nil {
+			return err
+		}
+	}
+	for _, rl := range d.Roles {
+		if _, err := s.roleService.Create(ctx, rl); err != nil {
+			return err
+		}
+	}
+	for _, act := range d.Actions {
+		if _, err := s.actionService.Create(ctx, act); err != nil {
+			return err
+		}
+	}
+	for _, pol := range d.Policies {
+		if _, err := s.policyService.Store(ctx, pol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
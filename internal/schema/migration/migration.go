@@ -0,0 +1,402 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/internal/schema"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	KindNamespace = "namespace"
+	KindRole      = "role"
+	KindAction    = "action"
+	KindPolicy    = "policy"
+)
+
+var ErrRelationInUse = errors.New("relation is still in use")
+
+type NamespaceService interface {
+	schema.NamespaceService
+	List(ctx context.Context) ([]namespace.Namespace, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type RoleService interface {
+	schema.RoleService
+	List(ctx context.Context) ([]role.Role, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type ActionService interface {
+	schema.ActionService
+	List(ctx context.Context) ([]action.Action, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type PolicyService interface {
+	schema.PolicyService
+	List(ctx context.Context, flt policy.Filters, expand bool) ([]policy.ExpandedPolicy, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type RelationService interface {
+	List(ctx context.Context) ([]relation.RelationV2, error)
+	DeleteV2(ctx context.Context, rel relation.RelationV2) error
+}
+
+type TupleRepository interface {
+	Export(ctx context.Context, resourceType string, fn func(relation.Tuple) error) error
+	DeleteTuples(ctx context.Context, tuples []relation.Tuple) error
+}
+
+// Change is what migrating to the namespace configs does to postgres. ID is
+// the id of the namespace, role or action changed, or the role and action of
+// the policy. InUse is the number of relationships of the authz engine on
+// the relation or the namespace a deletion removes.
+type Change struct {
+	Action string
+	Kind   string
+	ID     string
+	InUse  int
+
+	namespace namespace.Namespace
+	role      role.Role
+	action    action.Action
+	policy    policy.Policy
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.ID)
+}
+
+// Plan is the changes migrating to the namespace configs makes, in
+// the order they are applied
+type Plan struct {
+	Changes []Change
+
+	configs schema.NamespaceConfigMapType
+	// tuples are the relationships of the deletions in use, they are removed
+	// along with them when forced
+	tuples []relation.Tuple
+}
+
+// InUse returns the deletions of roles and namespaces that relationships
+// still rely on
+func (p Plan) InUse() []Change {
+	var inUse []Change
+	for _, c := range p.Changes {
+		if c.InUse > 0 {
+			inUse = append(inUse, c)
+		}
+	}
+	return inUse
+}
+
+// Migrator migrates the server to a declarative schema: the namespaces,
+// roles, actions and policies of postgres are made the ones the namespace
+// configs declare and the schema generated from them is written to the authz
+// engine. The custom roles of the organizations, their policies and the deny
+// policies aren't declared by the configs and are left as they are.
+type Migrator struct {
+	schemaService    *schema.SchemaService
+	namespaceService NamespaceService
+	roleService      RoleService
+	actionService    ActionService
+	policyService    PolicyService
+	relationService  RelationService
+	tupleRepository  TupleRepository
+}
+
+func NewMigrator(
+	namespaceService NamespaceService,
+	roleService RoleService,
+	actionService ActionService,
+	policyService PolicyService,
+	relationService RelationService,
+	tupleRepository TupleRepository,
+	authzEngine schema.AuthzEngine) *Migrator {
+	return &Migrator{
+		schemaService:    schema.NewSchemaMigrationService(nil, namespaceService, roleService, actionService, policyService, authzEngine),
+		namespaceService: namespaceService,
+		roleService:      roleService,
+		actionService:    actionService,
+		policyService:    policyService,
+		relationService:  relationService,
+		tupleRepository:  tupleRepository,
+	}
+}
+
+// Plan compares the namespace configs with postgres and returns the changes
+// migrating to them makes, without making them
+func (m Migrator) Plan(ctx context.Context, configs schema.NamespaceConfigMapType) (Plan, error) {
+	merged := schema.WithPredefined(configs)
+	if err := schema.Validate(merged); err != nil {
+		return Plan{}, err
+	}
+	want, err := schema.Declare(merged)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	var creates, deletes []Change
+	plan := Plan{configs: configs}
+
+	namespaces, err := m.namespaceService.List(ctx)
+	if err != nil {
+		return Plan{}, err
+	}
+	currentNamespaces := map[string]namespace.Namespace{}
+	for _, ns := range namespaces {
+		currentNamespaces[ns.ID] = ns
+	}
+	for _, ns := range want.Namespaces {
+		existing, ok := currentNamespaces[ns.ID]
+		delete(currentNamespaces, ns.ID)
+		switch {
+		case !ok:
+			creates = append(creates, Change{Action: ActionCreate, Kind: KindNamespace, ID: ns.ID})
+		case existing.Name != ns.Name || existing.Backend != ns.Backend || existing.ResourceType != ns.ResourceType:
+			creates = append(creates, Change{Action: ActionUpdate, Kind: KindNamespace, ID: ns.ID})
+		}
+	}
+
+	roles, err := m.roleService.List(ctx)
+	if err != nil {
+		return Plan{}, err
+	}
+	// systemRoles are the roles the configs declare, the custom roles of the
+	// organizations and their policies are left out
+	systemRoles := map[string]bool{}
+	currentRoles := map[string]role.Role{}
+	for _, rl := range roles {
+		if rl.OrgID == "" {
+			systemRoles[rl.ID] = true
+			currentRoles[rl.ID] = rl
+		}
+	}
+	for _, rl := range want.Roles {
+		existing, ok := currentRoles[rl.ID]
+		delete(currentRoles, rl.ID)
+		switch {
+		case !ok:
+			creates = append(creates, Change{Action: ActionCreate, Kind: KindRole, ID: rl.ID})
+		case existing.Name != rl.Name || existing.NamespaceID != rl.NamespaceID || !sameStrings(existing.Types, rl.Types):
+			creates = append(creates, Change{Action: ActionUpdate, Kind: KindRole, ID: rl.ID})
+		}
+	}
+
+	actions, err := m.actionService.List(ctx)
+	if err != nil {
+		return Plan{}, err
+	}
+	currentActions := map[string]action.Action{}
+	for _, act := range actions {
+		currentActions[act.ID] = act
+	}
+	for _, act := range want.Actions {
+		existing, ok := currentActions[act.ID]
+		delete(currentActions, act.ID)
+		switch {
+		case !ok:
+			creates = append(creates, Change{Action: ActionCreate, Kind: KindAction, ID: act.ID})
+		case existing.Name != act.Name || existing.NamespaceID != act.NamespaceID:
+			creates = append(creates, Change{Action: ActionUpdate, Kind: KindAction, ID: act.ID})
+		}
+	}
+
+	policies, err := m.policyService.List(ctx, policy.Filters{}, false)
+	if err != nil {
+		return Plan{}, err
+	}
+	currentPolicies := map[string]policy.Policy{}
+	for _, pol := range policies {
+		if pol.Effect == policy.EffectDeny || !systemRoles[pol.RoleID] {
+			continue
+		}
+		currentPolicies[policyKey(pol.Policy)] = pol.Policy
+	}
+	for _, pol := range want.Policies {
+		if _, ok := currentPolicies[policyKey(pol)]; !ok {
+			creates = append(creates, Change{Action: ActionCreate, Kind: KindPolicy, ID: policyKey(pol)})
+		}
+		delete(currentPolicies, policyKey(pol))
+	}
+
+	// policies are deleted first, their grants are revoked from the current
+	// schema, and the namespaces last once nothing refers to them
+	for _, key := range sortedKeys(currentPolicies) {
+		plan.Changes = append(plan.Changes, Change{Action: ActionDelete, Kind: KindPolicy, ID: key, policy: currentPolicies[key]})
+	}
+	plan.Changes = append(plan.Changes, creates...)
+	for _, id := range sortedKeys(currentActions) {
+		deletes = append(deletes, Change{Action: ActionDelete, Kind: KindAction, ID: id, action: currentActions[id]})
+	}
+	for _, id := range sortedKeys(currentRoles) {
+		deletes = append(deletes, Change{Action: ActionDelete, Kind: KindRole, ID: id, role: currentRoles[id]})
+	}
+	for _, id := range sortedKeys(currentNamespaces) {
+		deletes = append(deletes, Change{Action: ActionDelete, Kind: KindNamespace, ID: id, namespace: currentNamespaces[id]})
+	}
+	if err := m.countInUse(ctx, deletes, &plan); err != nil {
+		return Plan{}, err
+	}
+	plan.Changes = append(plan.Changes, deletes...)
+	return plan, nil
+}
+
+// countInUse counts the relationships on the relations of the deleted roles
+// and the objects of the deleted namespaces, the authz engine can't remove
+// them from the schema while they have relationships
+func (m Migrator) countInUse(ctx context.Context, deletes []Change, plan *Plan) error {
+	// removed are the deletions by namespace and relation, a deleted
+	// namespace is keyed by an empty relation
+	removed := map[string]map[string]*Change{}
+	for i, c := range deletes {
+		var namespaceID, rel string
+		switch c.Kind {
+		case KindRole:
+			namespaceID, rel = c.role.NamespaceID, relationName(c.role.ID)
+		case KindNamespace:
+			namespaceID = c.namespace.ID
+		default:
+			continue
+		}
+		if removed[namespaceID] == nil {
+			removed[namespaceID] = map[string]*Change{}
+		}
+		removed[namespaceID][rel] = &deletes[i]
+	}
+
+	for _, namespaceID := range sortedKeys(removed) {
+		if err := m.tupleRepository.Export(ctx, namespaceID, func(t relation.Tuple) error {
+			c, ok := removed[namespaceID][""]
+			if !ok {
+				if c, ok = removed[namespaceID][t.Relation]; !ok {
+					return nil
+				}
+			}
+			c.InUse++
+			plan.tuples = append(plan.tuples, t)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("reading relationships of %s: %w", namespaceID, err)
+		}
+	}
+	return nil
+}
+
+// Apply makes the changes of the plan. The deletions in use are refused
+// unless forced, forcing them deletes their relations and relationships
+// first.
+func (m Migrator) Apply(ctx context.Context, plan Plan, force bool) error {
+	inUse := plan.InUse()
+	if len(inUse) > 0 && !force {
+		names := make([]string, 0, len(inUse))
+		for _, c := range inUse {
+			names = append(names, fmt.Sprintf("%s %s has %d relationships", c.Kind, c.ID, c.InUse))
+		}
+		return fmt.Errorf("%w: %s", ErrRelationInUse, strings.Join(names, ", "))
+	}
+	if len(inUse) > 0 {
+		if err := m.deleteRelations(ctx, inUse, plan.tuples); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range plan.Changes {
+		if c.Kind == KindPolicy && c.Action == ActionDelete {
+			if err := m.policyService.Delete(ctx, c.policy.ID); err != nil {
+				return fmt.Errorf("could not %s: %w", c, err)
+			}
+		}
+	}
+
+	if err := m.schemaService.Migrate(ctx, plan.configs); err != nil {
+		return err
+	}
+
+	for _, c := range plan.Changes {
+		if c.Action != ActionDelete {
+			continue
+		}
+		var err error
+		switch c.Kind {
+		case KindAction:
+			err = m.actionService.Delete(ctx, c.action.ID)
+		case KindRole:
+			err = m.roleService.Delete(ctx, c.role.ID)
+		case KindNamespace:
+			err = m.namespaceService.Delete(ctx, c.namespace.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("could not %s: %w", c, err)
+		}
+	}
+	return nil
+}
+
+// deleteRelations deletes the relations of postgres on the deleted roles and
+// namespaces, then the relationships left without one
+func (m Migrator) deleteRelations(ctx context.Context, inUse []Change, tuples []relation.Tuple) error {
+	relations, err := m.relationService.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rel := range relations {
+		for _, c := range inUse {
+			if (c.Kind == KindRole && schema.GetRoleID(rel.Object.NamespaceID, relationName(rel.Subject.RoleID)) == c.role.ID) ||
+				(c.Kind == KindNamespace && rel.Object.NamespaceID == c.namespace.ID) {
+				if err := m.relationService.DeleteV2(ctx, rel); err != nil {
+					return fmt.Errorf("could not delete relation %s: %w", rel.ID, err)
+				}
+				break
+			}
+		}
+	}
+	return m.tupleRepository.DeleteTuples(ctx, tuples)
+}
+
+// policyKey identifies a policy by the role it grants the action to
+func policyKey(pol policy.Policy) string {
+	return fmt.Sprintf("%s->%s", pol.RoleID, pol.ActionID)
+}
+
+// relationName is the relation of the role in the authz schema, the name its
+// id ends with. The relations of postgres refer to roles by either.
+func relationName(roleID string) string {
+	return roleID[strings.LastIndex(roleID, ":")+1:]
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
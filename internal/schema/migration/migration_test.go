@@ -0,0 +1,356 @@
+package migration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/schema/migration"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryNamespaces map[string]namespace.Namespace
+
+func (s memoryNamespaces) Create(ctx context.Context, ns namespace.Namespace) (namespace.Namespace, error) {
+	s[ns.ID] = ns
+	return ns, nil
+}
+
+func (s memoryNamespaces) List(ctx context.Context) ([]namespace.Namespace, error) {
+	var list []namespace.Namespace
+	for _, ns := range s {
+		list = append(list, ns)
+	}
+	return list, nil
+}
+
+func (s memoryNamespaces) Delete(ctx context.Context, id string) error {
+	delete(s, id)
+	return nil
+}
+
+type memoryRoles map[string]role.Role
+
+func (s memoryRoles) Create(ctx context.Context, rl role.Role) (role.Role, error) {
+	s[rl.ID] = rl
+	return rl, nil
+}
+
+func (s memoryRoles) List(ctx context.Context) ([]role.Role, error) {
+	var list []role.Role
+	for _, rl := range s {
+		list = append(list, rl)
+	}
+	return list, nil
+}
+
+func (s memoryRoles) Delete(ctx context.Context, id string) error {
+	delete(s, id)
+	return nil
+}
+
+func (s memoryRoles) RestoreOrgRoles(ctx context.Context) error {
+	return nil
+}
+
+type memoryActions map[string]action.Action
+
+func (s memoryActions) Create(ctx context.Context, act action.Action) (action.Action, error) {
+	s[act.ID] = act
+	return act, nil
+}
+
+func (s memoryActions) List(ctx context.Context) ([]action.Action, error) {
+	var list []action.Action
+	for _, act := range s {
+		list = append(list, act)
+	}
+	return list, nil
+}
+
+func (s memoryActions) Delete(ctx context.Context, id string) error {
+	delete(s, id)
+	return nil
+}
+
+// memoryPolicies keeps the policies by id, stored policies are upserted by
+// role, namespace and action
+type memoryPolicies map[string]policy.Policy
+
+func (s memoryPolicies) Store(ctx context.Context, pol policy.Policy) (policy.Policy, error) {
+	for id, p := range s {
+		if p.RoleID == pol.RoleID && p.NamespaceID == pol.NamespaceID && p.ActionID == pol.ActionID {
+			pol.ID = id
+		}
+	}
+	if pol.ID == "" {
+		pol.ID = fmt.Sprintf("policy-%d", len(s)+1)
+	}
+	if pol.Effect == "" {
+		pol.Effect = policy.EffectAllow
+	}
+	s[pol.ID] = pol
+	return pol, nil
+}
+
+func (s memoryPolicies) List(ctx context.Context, flt policy.Filters, expand bool) ([]policy.ExpandedPolicy, error) {
+	var list []policy.ExpandedPolicy
+	for _, pol := range s {
+		list = append(list, policy.ExpandedPolicy{Policy: pol})
+	}
+	return list, nil
+}
+
+func (s memoryPolicies) Delete(ctx context.Context, id string) error {
+	delete(s, id)
+	return nil
+}
+
+func (s memoryPolicies) RestoreGrants(ctx context.Context) error {
+	return nil
+}
+
+type memoryRelations struct {
+	relations []relation.RelationV2
+}
+
+func (s *memoryRelations) List(ctx context.Context) ([]relation.RelationV2, error) {
+	return s.relations, nil
+}
+
+func (s *memoryRelations) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	for i, r := range s.relations {
+		if r.ID == rel.ID {
+			s.relations = append(s.relations[:i], s.relations[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type memoryTuples struct {
+	tuples []relation.Tuple
+}
+
+func (s *memoryTuples) Export(ctx context.Context, resourceType string, fn func(relation.Tuple) error) error {
+	for _, t := range s.tuples {
+		if t.ResourceType == resourceType {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memoryTuples) DeleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	deleted := map[relation.Tuple]bool{}
+	for _, t := range tuples {
+		deleted[t] = true
+	}
+	kept := s.tuples[:0]
+	for _, t := range s.tuples {
+		if !deleted[t] {
+			kept = append(kept, t)
+		}
+	}
+	s.tuples = kept
+	return nil
+}
+
+type memoryAuthzEngine struct {
+	written schema.NamespaceConfigMapType
+}
+
+func (e *memoryAuthzEngine) WriteSchema(ctx context.Context, configs schema.NamespaceConfigMapType) error {
+	e.written = configs
+	return nil
+}
+
+type server struct {
+	namespaces memoryNamespaces
+	roles      memoryRoles
+	actions    memoryActions
+	policies   memoryPolicies
+	relations  *memoryRelations
+	tuples     *memoryTuples
+	authz      *memoryAuthzEngine
+}
+
+func newServer() server {
+	return server{
+		namespaces: memoryNamespaces{},
+		roles:      memoryRoles{},
+		actions:    memoryActions{},
+		policies:   memoryPolicies{},
+		relations:  &memoryRelations{},
+		tuples:     &memoryTuples{},
+		authz:      &memoryAuthzEngine{},
+	}
+}
+
+func (s server) migrator() *migration.Migrator {
+	return migration.NewMigrator(s.namespaces, s.roles, s.actions, s.policies, s.relations, s.tuples, s.authz)
+}
+
+func firehoseConfigs(roles ...string) schema.NamespaceConfigMapType {
+	config := schema.NamespaceConfig{
+		Type:        schema.ResourceGroupNamespace,
+		Roles:       map[string][]string{},
+		Permissions: map[string][]string{"sink_edit": {schema.OwnerRole}},
+	}
+	for _, r := range roles {
+		config.Roles[r] = []string{schema.UserPrincipal}
+		config.Permissions["sink_edit"] = append(config.Permissions["sink_edit"], r)
+	}
+	return schema.NamespaceConfigMapType{"entropy/firehose": config}
+}
+
+func changes(plan migration.Plan) []string {
+	var list []string
+	for _, c := range plan.Changes {
+		list = append(list, c.String())
+	}
+	return list
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create everything declared on an empty server", func(t *testing.T) {
+		s := newServer()
+		m := s.migrator()
+
+		plan, err := m.Plan(ctx, firehoseConfigs("sink_editor"))
+		assert.NoError(t, err)
+		assert.Contains(t, changes(plan), "create namespace entropy/firehose")
+		assert.Contains(t, changes(plan), "create role entropy/firehose:sink_editor")
+		assert.Contains(t, changes(plan), "create action sink_edit.entropy/firehose")
+		assert.Contains(t, changes(plan), "create policy entropy/firehose:sink_editor->sink_edit.entropy/firehose")
+		assert.Empty(t, plan.InUse())
+
+		assert.NoError(t, m.Apply(ctx, plan, false))
+		assert.Contains(t, s.roles, "entropy/firehose:sink_editor")
+		assert.Contains(t, s.authz.written, "entropy/firehose")
+
+		plan, err = m.Plan(ctx, firehoseConfigs("sink_editor"))
+		assert.NoError(t, err)
+		assert.Empty(t, plan.Changes)
+	})
+
+	t.Run("should delete what is no longer declared", func(t *testing.T) {
+		s := newServer()
+		m := s.migrator()
+		plan, err := m.Plan(ctx, firehoseConfigs("sink_editor", "sink_viewer"))
+		assert.NoError(t, err)
+		assert.NoError(t, m.Apply(ctx, plan, false))
+
+		plan, err = m.Plan(ctx, firehoseConfigs("sink_editor"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"delete policy entropy/firehose:sink_viewer->sink_edit.entropy/firehose",
+			"delete role entropy/firehose:sink_viewer",
+		}, changes(plan))
+
+		assert.NoError(t, m.Apply(ctx, plan, false))
+		assert.NotContains(t, s.roles, "entropy/firehose:sink_viewer")
+	})
+
+	t.Run("should leave the custom roles and the deny policies as they are", func(t *testing.T) {
+		s := newServer()
+		m := s.migrator()
+		plan, err := m.Plan(ctx, firehoseConfigs("sink_editor"))
+		assert.NoError(t, err)
+		assert.NoError(t, m.Apply(ctx, plan, false))
+
+		s.roles["entropy/firehose:operator"] = role.Role{ID: "entropy/firehose:operator", Name: "operator", NamespaceID: "entropy/firehose", OrgID: "org"}
+		s.policies["custom"] = policy.Policy{ID: "custom", RoleID: "entropy/firehose:operator", NamespaceID: "entropy/firehose", ActionID: "sink_edit.entropy/firehose", Effect: policy.EffectAllow}
+		s.policies["deny"] = policy.Policy{ID: "deny", RoleID: "entropy/firehose:sink_editor", NamespaceID: "entropy/firehose", ActionID: "view.entropy/firehose", Effect: policy.EffectDeny}
+
+		plan, err = m.Plan(ctx, firehoseConfigs("sink_editor"))
+		assert.NoError(t, err)
+		assert.Empty(t, plan.Changes)
+	})
+
+	t.Run("should refuse to delete a role in use unless forced", func(t *testing.T) {
+		s := newServer()
+		m := s.migrator()
+		plan, err := m.Plan(ctx, firehoseConfigs("sink_editor", "sink_viewer"))
+		assert.NoError(t, err)
+		assert.NoError(t, m.Apply(ctx, plan, false))
+
+		s.relations.relations = []relation.RelationV2{{
+			ID:      "relation",
+			Object:  relation.Object{ID: "firehose-1", NamespaceID: "entropy/firehose"},
+			Subject: relation.Subject{ID: "user-1", Namespace: schema.UserPrincipal, RoleID: "entropy/firehose:sink_viewer"},
+		}}
+		s.tuples.tuples = []relation.Tuple{
+			{ResourceType: "entropy/firehose", ResourceID: "firehose-1", Relation: "sink_viewer", SubjectType: schema.UserPrincipal, SubjectID: "user-1"},
+			{ResourceType: "entropy/firehose", ResourceID: "firehose-1", Relation: "sink_editor", SubjectType: schema.UserPrincipal, SubjectID: "user-2"},
+		}
+
+		plan, err = m.Plan(ctx, firehoseConfigs("sink_editor"))
+		assert.NoError(t, err)
+		inUse := plan.InUse()
+		assert.Len(t, inUse, 1)
+		assert.Equal(t, "delete role entropy/firehose:sink_viewer", inUse[0].String())
+		assert.Equal(t, 1, inUse[0].InUse)
+
+		err = m.Apply(ctx, plan, false)
+		assert.ErrorIs(t, err, migration.ErrRelationInUse)
+		assert.Contains(t, s.roles, "entropy/firehose:sink_viewer")
+		assert.Len(t, s.tuples.tuples, 2)
+
+		assert.NoError(t, m.Apply(ctx, plan, true))
+		assert.NotContains(t, s.roles, "entropy/firehose:sink_viewer")
+		assert.Empty(t, s.relations.relations)
+		assert.Equal(t, []relation.Tuple{
+			{ResourceType: "entropy/firehose", ResourceID: "firehose-1", Relation: "sink_editor", SubjectType: schema.UserPrincipal, SubjectID: "user-2"},
+		}, s.tuples.tuples)
+	})
+
+	t.Run("should return error if the configs are invalid", func(t *testing.T) {
+		s := newServer()
+		configs := firehoseConfigs("sink_editor")
+		configs["entropy/firehose"].Permissions["sink_edit"] = append(configs["entropy/firehose"].Permissions["sink_edit"], "sink_admin")
+
+		_, err := s.migrator().Plan(ctx, configs)
+		assert.ErrorIs(t, err, schema.ErrInvalidSchema)
+	})
+}
+
+func TestMigratorDeleteNamespace(t *testing.T) {
+	ctx := context.Background()
+	s := newServer()
+	m := s.migrator()
+	configs := firehoseConfigs("sink_editor")
+	configs["entropy/dagger"] = schema.NamespaceConfig{Type: schema.ResourceGroupNamespace}
+	plan, err := m.Plan(ctx, configs)
+	assert.NoError(t, err)
+	assert.NoError(t, m.Apply(ctx, plan, false))
+
+	s.tuples.tuples = []relation.Tuple{
+		{ResourceType: "entropy/dagger", ResourceID: "dagger-1", Relation: "project", SubjectType: schema.ProjectNamespace, SubjectID: "project-1"},
+	}
+	plan, err = m.Plan(ctx, firehoseConfigs("sink_editor"))
+	assert.NoError(t, err)
+
+	// the relationships on the objects of a deleted namespace are counted
+	// along with it rather than with its roles
+	var inUse []string
+	for _, c := range plan.InUse() {
+		inUse = append(inUse, fmt.Sprintf("%s %d", c, c.InUse))
+	}
+	assert.Equal(t, []string{"delete namespace entropy/dagger 1"}, inUse)
+	assert.Contains(t, changes(plan), "delete role entropy/dagger:project")
+
+	assert.NoError(t, m.Apply(ctx, plan, true))
+	assert.NotContains(t, s.namespaces, "entropy/dagger")
+	assert.Empty(t, s.tuples.tuples)
+}
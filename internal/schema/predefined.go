@@ -6,11 +6,14 @@ const (
 	OrganizationNamespace = "shield/organization"
 	ProjectNamespace      = "shield/project"
 	GroupNamespace        = "shield/group"
+	FolderNamespace       = "shield/folder"
 
 	// relation
 	OrganizationRelationName = "organization"
 	ProjectRelationName      = "project"
 	GroupRelationName        = "group"
+	FolderRelationName       = "folder"
+	ParentRelationName       = "parent"
 
 	// roles
 	OwnerRole   = "owner"
@@ -32,23 +35,36 @@ const (
 	GroupPrincipal = "shield/group"
 )
 
-var InheritedRelations = map[string]bool{
-	OrganizationRelationName: true,
-	ProjectRelationName:      true,
+// InheritedRelations are the namespaces the inherited relations point to
+var InheritedRelations = map[string]string{
+	OrganizationRelationName: OrganizationNamespace,
+	ProjectRelationName:      ProjectNamespace,
+	ParentRelationName:       OrganizationNamespace,
+	FolderRelationName:       FolderNamespace,
 }
 
 var OrganizationNamespaceConfig = NamespaceConfig{
+	InheritedNamespaces: []InheritedNamespace{
+		{
+			Name:        ParentRelationName,
+			NamespaceId: OrganizationNamespace,
+		},
+	},
 	Roles: map[string][]string{
 		OwnerRole:  {UserPrincipal, GroupPrincipal},
 		EditorRole: {UserPrincipal, GroupPrincipal},
 		ViewerRole: {UserPrincipal, GroupPrincipal},
 	},
 	Permissions: map[string][]string{
+		// permissions of the parent organization are inherited
+		// all the way up the hierarchy
 		EditPermission: {
 			OwnerRole, EditorRole,
+			PermissionInheritanceFormatter(ParentRelationName, EditPermission),
 		},
 		ViewPermission: {
 			OwnerRole, EditorRole, ViewerRole,
+			PermissionInheritanceFormatter(ParentRelationName, ViewPermission),
 		},
 	},
 }
@@ -70,12 +86,14 @@ var ProjectNamespaceConfig = NamespaceConfig{
 			OwnerRole, EditorRole,
 			PermissionInheritanceFormatter(OrganizationRelationName, OwnerRole),
 			PermissionInheritanceFormatter(OrganizationRelationName, EditorRole),
+			PermissionInheritanceFormatter(OrganizationRelationName, EditPermission),
 		},
 		ViewPermission: {
 			OwnerRole, EditorRole, ViewerRole,
 			PermissionInheritanceFormatter(OrganizationRelationName, OwnerRole),
 			PermissionInheritanceFormatter(OrganizationRelationName, EditorRole),
 			PermissionInheritanceFormatter(OrganizationRelationName, ViewerRole),
+			PermissionInheritanceFormatter(OrganizationRelationName, ViewPermission),
 		},
 		DeletePermission: {
 			OwnerRole,
@@ -92,27 +110,65 @@ var GroupNamespaceConfig = NamespaceConfig{
 		},
 	},
 	Roles: map[string][]string{
-		MemberRole:  {UserPrincipal},
+		// a group can be a member of another group, its members are
+		// members of the other group too
+		MemberRole: {UserPrincipal, GroupPrincipal},
+		// owners and managers manage the members of the group without
+		// a role on its organization, delete the group and manage its
+		// owners and managers
+		OwnerRole:   {UserPrincipal},
 		ManagerRole: {UserPrincipal},
 	},
 	Permissions: map[string][]string{
 		EditPermission: {
-			ManagerRole,
+			OwnerRole, ManagerRole,
 			PermissionInheritanceFormatter(OrganizationRelationName, OwnerRole),
 			PermissionInheritanceFormatter(OrganizationRelationName, EditorRole),
+			PermissionInheritanceFormatter(OrganizationRelationName, EditPermission),
 		},
 		ViewPermission: {
-			ManagerRole, MemberRole,
+			OwnerRole, ManagerRole, MemberRole,
 			PermissionInheritanceFormatter(OrganizationRelationName, OwnerRole),
 			PermissionInheritanceFormatter(OrganizationRelationName, EditorRole),
 			PermissionInheritanceFormatter(OrganizationRelationName, ViewerRole),
+			PermissionInheritanceFormatter(OrganizationRelationName, ViewPermission),
 		},
 		DeletePermission: {
-			ManagerRole,
+			OwnerRole, ManagerRole,
 			PermissionInheritanceFormatter(OrganizationRelationName, OwnerRole),
 		},
 		MembershipPermission: {
-			MemberRole, ManagerRole,
+			MemberRole, ManagerRole, OwnerRole,
+		},
+	},
+}
+
+// FolderNamespaceConfig groups the resources of a project, the permissions
+// of the project are inherited by the folder and its resources
+var FolderNamespaceConfig = NamespaceConfig{
+	InheritedNamespaces: []InheritedNamespace{
+		{
+			Name:        ProjectRelationName,
+			NamespaceId: ProjectNamespace,
+		},
+	},
+	Roles: map[string][]string{
+		OwnerRole:  {UserPrincipal, GroupPrincipal},
+		EditorRole: {UserPrincipal, GroupPrincipal},
+		ViewerRole: {UserPrincipal, GroupPrincipal},
+	},
+	Permissions: map[string][]string{
+		EditPermission: {
+			OwnerRole, EditorRole,
+			PermissionInheritanceFormatter(ProjectRelationName, EditPermission),
+		},
+		ViewPermission: {
+			OwnerRole, EditorRole, ViewerRole,
+			PermissionInheritanceFormatter(ProjectRelationName, ViewPermission),
+		},
+		DeletePermission: {
+			OwnerRole,
+			PermissionInheritanceFormatter(ProjectRelationName, DeletePermission),
 		},
 	},
 }
@@ -122,6 +178,7 @@ var PreDefinedSystemNamespaceConfig = NamespaceConfigMapType{
 	OrganizationNamespace: OrganizationNamespaceConfig,
 	ProjectNamespace:      ProjectNamespaceConfig,
 	GroupNamespace:        GroupNamespaceConfig,
+	FolderNamespace:       FolderNamespaceConfig,
 }
 
 var PreDefinedResourceGroupNamespaceConfig = NamespaceConfig{
@@ -135,6 +192,10 @@ var PreDefinedResourceGroupNamespaceConfig = NamespaceConfig{
 			Name:        ProjectRelationName,
 			NamespaceId: ProjectNamespace,
 		},
+		{
+			Name:        FolderRelationName,
+			NamespaceId: FolderNamespace,
+		},
 	},
 	Roles: map[string][]string{
 		OwnerRole:  {UserPrincipal, GroupPrincipal},
@@ -148,6 +209,7 @@ var PreDefinedResourceGroupNamespaceConfig = NamespaceConfig{
 			PermissionInheritanceFormatter(OrganizationRelationName, EditorRole),
 			PermissionInheritanceFormatter(ProjectRelationName, OwnerRole),
 			PermissionInheritanceFormatter(ProjectRelationName, EditorRole),
+			PermissionInheritanceFormatter(FolderRelationName, EditPermission),
 		},
 		ViewPermission: {
 			OwnerRole, EditorRole, ViewerRole,
@@ -157,11 +219,13 @@ var PreDefinedResourceGroupNamespaceConfig = NamespaceConfig{
 			PermissionInheritanceFormatter(ProjectRelationName, OwnerRole),
 			PermissionInheritanceFormatter(ProjectRelationName, EditorRole),
 			PermissionInheritanceFormatter(ProjectRelationName, ViewerRole),
+			PermissionInheritanceFormatter(FolderRelationName, ViewPermission),
 		},
 		DeletePermission: {
 			OwnerRole,
 			PermissionInheritanceFormatter(OrganizationRelationName, OwnerRole),
 			PermissionInheritanceFormatter(ProjectRelationName, OwnerRole),
+			PermissionInheritanceFormatter(FolderRelationName, DeletePermission),
 		},
 	},
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/odpf/shield/core/action"
 	"github.com/odpf/shield/core/namespace"
@@ -43,10 +42,12 @@ type NamespaceService interface {
 
 type RoleService interface {
 	Create(ctx context.Context, toCreate role.Role) (role.Role, error)
+	RestoreOrgRoles(ctx context.Context) error
 }
 
 type PolicyService interface {
-	Create(ctx context.Context, policy policy.Policy) ([]policy.Policy, error)
+	Store(ctx context.Context, pol policy.Policy) (policy.Policy, error)
+	RestoreGrants(ctx context.Context) error
 }
 
 type ActionService interface {
@@ -92,113 +93,62 @@ func (s SchemaService) RunMigrations(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	return s.Migrate(ctx, namespaceConfigMap)
+}
 
-	// combining predefined and configured namespaces
-	namespaceConfigMap = MergeNamespaceConfigMap(PreDefinedSystemNamespaceConfig, namespaceConfigMap)
+// Migrate creates what the configured namespaces declare and writes the
+// schema generated from them to the authz engine
+func (s SchemaService) Migrate(ctx context.Context, namespaceConfigMap NamespaceConfigMapType) error {
+	namespaceConfigMap = WithPredefined(namespaceConfigMap)
 
-	// adding predefined roles and permissions for resource group namespaces
-	for n, nc := range namespaceConfigMap {
-		if nc.Type == ResourceGroupNamespace {
-			namespaceConfigMap = MergeNamespaceConfigMap(namespaceConfigMap, NamespaceConfigMapType{
-				n: PreDefinedResourceGroupNamespaceConfig,
-			})
-		}
+	// nothing is created from configs the authz engine would reject
+	if err := Validate(namespaceConfigMap); err != nil {
+		return fmt.Errorf("%w: %s", ErrMigration, err.Error())
 	}
 
-	//spiceDBSchema := GenerateSchema(namespaceConfigMap)
-
-	// iterate over namespace
-	for namespaceId, v := range namespaceConfigMap {
-		// create namespace
-		backend := ""
-		resourceType := ""
-		if v.Type == ResourceGroupNamespace {
-			st := strings.Split(namespaceId, "/")
-			backend = st[0]
-			resourceType = st[1]
-		}
-		_, err := s.namespaceService.Create(ctx, namespace.Namespace{
-			ID:           namespaceId,
-			Name:         namespaceId,
-			Backend:      backend,
-			ResourceType: resourceType,
-		})
-		if err != nil {
-			return fmt.Errorf("%w: %s", ErrMigration, err.Error())
-		}
-
-		// create roles
-		for roleId, principals := range v.Roles {
-			_, err := s.roleService.Create(ctx, role.Role{
-				ID:          fmt.Sprintf("%s:%s", namespaceId, roleId),
-				Name:        roleId,
-				Types:       principals,
-				NamespaceID: namespaceId,
-			})
-			if err != nil {
-				return fmt.Errorf("%w: %s", ErrMigration, err.Error())
-			}
-		}
-
-		// create role for inherited namespaces
-		for _, ins := range v.InheritedNamespaces {
-			_, err := s.roleService.Create(ctx, role.Role{
-				ID:          fmt.Sprintf("%s:%s", namespaceId, ins.Name),
-				Name:        ins.Name,
-				Types:       []string{ins.NamespaceId},
-				NamespaceID: namespaceId,
-			})
-			if err != nil {
-				return fmt.Errorf("%w: %s", ErrMigration, err.Error())
-			}
-		}
-
-		// create actions
-		// IMP: we should depreciate actions with principals
-		for actionId := range v.Permissions {
-			_, err := s.actionService.Create(ctx, action.Action{
-				ID:          fmt.Sprintf("%s.%s", actionId, namespaceId),
-				Name:        actionId,
-				NamespaceID: namespaceId,
-			})
-			if err != nil {
-				return fmt.Errorf("%w: %s", ErrMigration, err.Error())
-			}
-		}
+	d, err := Declare(namespaceConfigMap)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrMigration, err.Error())
+	}
+	if err := s.create(ctx, d); err != nil {
+		return fmt.Errorf("%w: %s", ErrMigration, err.Error())
 	}
 
-	for namespaceId, v := range namespaceConfigMap {
-		// create policies
-		for actionId, roles := range v.Permissions {
-			for _, r := range roles {
-				transformedRole, err := getRoleAndPrincipal(r, namespaceId)
-				if err != nil {
-					return fmt.Errorf("%w: %s", ErrMigration, err.Error())
-				}
-
-				if _, ok := namespaceConfigMap[GetNamespace(transformedRole.NamespaceID)].Roles[transformedRole.ID]; !ok {
-					return fmt.Errorf("role %s not associated with namespace: %s", transformedRole.ID, transformedRole.NamespaceID)
-				}
-
-				_, err = s.policyService.Create(ctx, policy.Policy{
-					RoleID:      GetRoleID(GetNamespace(transformedRole.NamespaceID), transformedRole.ID),
-					NamespaceID: namespaceId,
-					ActionID:    fmt.Sprintf("%s.%s", actionId, namespaceId),
-				})
-				if err != nil {
-					return fmt.Errorf("%w: %s", ErrMigration, err.Error())
-				}
-			}
-		}
+	if err := s.authzEngine.WriteSchema(ctx, namespaceConfigMap); err != nil {
+		return fmt.Errorf("%w: %s", ErrMigration, err.Error())
 	}
 
-	if err = s.authzEngine.WriteSchema(ctx, namespaceConfigMap); err != nil {
+	// the schema written from the configs doesn't have the custom roles of
+	// the organizations nor the grants of the policies created through the
+	// api, deny policies included
+	if err := s.roleService.RestoreOrgRoles(ctx); err != nil {
+		return fmt.Errorf("%w: %s", ErrMigration, err.Error())
+	}
+	if err := s.policyService.RestoreGrants(ctx); err != nil {
 		return fmt.Errorf("%w: %s", ErrMigration, err.Error())
 	}
 
 	return nil
 }
 
+// WithPredefined combines the configured namespaces with the predefined
+// ones, the resource group namespaces get the predefined roles and
+// permissions of resource groups
+func WithPredefined(namespaceConfigMap NamespaceConfigMapType) NamespaceConfigMapType {
+	// combining predefined and configured namespaces
+	namespaceConfigMap = MergeNamespaceConfigMap(PreDefinedSystemNamespaceConfig, namespaceConfigMap)
+
+	// adding predefined roles and permissions for resource group namespaces
+	for n, nc := range namespaceConfigMap {
+		if nc.Type == ResourceGroupNamespace {
+			namespaceConfigMap = MergeNamespaceConfigMap(namespaceConfigMap, NamespaceConfigMapType{
+				n: PreDefinedResourceGroupNamespaceConfig,
+			})
+		}
+	}
+	return namespaceConfigMap
+}
+
 func MergeNamespaceConfigMap(smallMap, largeMap NamespaceConfigMapType) NamespaceConfigMapType {
 	combinedMap := make(NamespaceConfigMapType)
 	maps.Copy(combinedMap, smallMap)
@@ -58,8 +58,8 @@ func Contains[T comparable](s []T, e T) bool {
 
 func GetNamespace(namespaceID string) string {
 	splittedString := strings.Split(namespaceID, "/")
-	if len(splittedString) == 1 && InheritedRelations[namespaceID] {
-		return fmt.Sprintf("shield/%s", namespaceID)
+	if ns, ok := InheritedRelations[namespaceID]; ok && len(splittedString) == 1 {
+		return ns
 	}
 
 	return namespaceID
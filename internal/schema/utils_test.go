@@ -11,3 +11,10 @@ func TestAppendIfUnique(t *testing.T) {
 	fmt.Println(AppendIfUnique([]string{"1", "2", "3"}, []string{"3", "4"}))
 	assert.ElementsMatch(t, AppendIfUnique([]string{"1", "2", "3"}, []string{"3", "4"}), []string{"1", "2", "3", "4"})
 }
+
+func TestGetNamespace(t *testing.T) {
+	assert.Equal(t, OrganizationNamespace, GetNamespace(OrganizationRelationName))
+	assert.Equal(t, OrganizationNamespace, GetNamespace(ParentRelationName))
+	assert.Equal(t, ProjectNamespace, GetNamespace(ProjectRelationName))
+	assert.Equal(t, "entropy/firehose", GetNamespace("entropy/firehose"))
+}
@@ -0,0 +1,140 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/odpf/shield/core/namespace"
+)
+
+var ErrInvalidSchema = errors.New("namespace configs don't make a valid authz schema")
+
+// ValidationError lists every problem of the namespace configs
+type ValidationError struct {
+	Problems []string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInvalidSchema, strings.Join(e.Problems, "; "))
+}
+
+func (e ValidationError) Unwrap() error {
+	return ErrInvalidSchema
+}
+
+// Validate checks the schema generated from the namespace configs is
+// consistent before anything is written: the names are valid for the authz
+// schema, roles are granted to users or groups, inherited namespaces exist
+// and every permission is granted to roles, permissions or the roles and
+// permissions of an inherited namespace that are defined.
+func Validate(configs NamespaceConfigMapType) error {
+	var problems []string
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	for _, id := range sortedKeys(configs) {
+		config := configs[id]
+		if !namespace.IsValidID(id) {
+			report("namespace %s: not a valid definition name", id)
+		}
+
+		// relations are the names the permissions of the namespace can refer
+		// to, by the kind of relation they are
+		relations := map[string]string{}
+		define := func(name, kind string) {
+			if !namespace.IsValidRelationName(name) {
+				report("namespace %s: %s %s is not a valid relation name", id, kind, name)
+			}
+			if existing, ok := relations[name]; ok {
+				report("namespace %s: %s is both a %s and a %s", id, name, existing, kind)
+				return
+			}
+			relations[name] = kind
+		}
+
+		inherited := map[string]string{}
+		for _, ins := range config.InheritedNamespaces {
+			define(ins.Name, "inherited namespace")
+			inherited[ins.Name] = ins.NamespaceId
+			if _, ok := configs[ins.NamespaceId]; !ok {
+				report("namespace %s: inherited namespace %s is not defined", id, ins.NamespaceId)
+			}
+		}
+
+		for _, name := range sortedKeys(config.Roles) {
+			define(name, "role")
+			if len(config.Roles[name]) == 0 {
+				report("namespace %s: role %s has no principals", id, name)
+			}
+			for _, p := range config.Roles[name] {
+				if p != UserPrincipal && p != GroupPrincipal {
+					report("namespace %s: role %s can't be granted to %s", id, name, p)
+				}
+			}
+		}
+		for _, name := range sortedKeys(config.Permissions) {
+			define(name, "permission")
+		}
+
+		for _, name := range sortedKeys(config.Permissions) {
+			roles := config.Permissions[name]
+			if len(roles) == 0 {
+				report("namespace %s: permission %s is not granted to any role", id, name)
+			}
+			for _, r := range roles {
+				if problem := undefinedRelation(configs, relations, inherited, r); problem != "" {
+					report("namespace %s: permission %s: %s", id, name, problem)
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// undefinedRelation describes why the role a permission is granted to isn't
+// defined, it is either a role or permission of the namespace or of the
+// namespace an inherited relation points to
+func undefinedRelation(configs NamespaceConfigMapType, relations, inherited map[string]string, roleName string) string {
+	parts := strings.Split(roleName, ":")
+	switch len(parts) {
+	case 1:
+		// an inherited namespace is only referred to along with the role or
+		// permission of it granted
+		if kind, ok := relations[roleName]; !ok || kind == "inherited namespace" {
+			return fmt.Sprintf("%s is not a role or permission", roleName)
+		}
+	case 2:
+		target, ok := inherited[parts[0]]
+		if !ok {
+			return fmt.Sprintf("%s is not an inherited namespace", parts[0])
+		}
+		config, ok := configs[target]
+		if !ok {
+			return ""
+		}
+		_, isRole := config.Roles[parts[1]]
+		_, isPermission := config.Permissions[parts[1]]
+		if !isRole && !isPermission {
+			return fmt.Sprintf("%s is not a role or permission of %s", parts[1], target)
+		}
+	default:
+		return fmt.Sprintf("%s should be a role or inherited_namespace:role", roleName)
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	firehose := func() NamespaceConfig {
+		return NamespaceConfig{
+			Type:                ResourceGroupNamespace,
+			InheritedNamespaces: []InheritedNamespace{{Name: OrganizationRelationName, NamespaceId: OrganizationNamespace}},
+			Roles: map[string][]string{
+				OwnerRole:     {UserPrincipal, GroupPrincipal},
+				"sink_editor": {UserPrincipal},
+			},
+			Permissions: map[string][]string{
+				ViewPermission: {OwnerRole, "sink_editor", PermissionInheritanceFormatter(OrganizationRelationName, ViewPermission)},
+				"sink_edit":    {OwnerRole, "sink_editor", PermissionInheritanceFormatter(OrganizationRelationName, OwnerRole)},
+			},
+		}
+	}
+	withFirehose := func(change func(c *NamespaceConfig)) NamespaceConfigMapType {
+		c := firehose()
+		change(&c)
+		return NamespaceConfigMapType{
+			UserPrincipal:         NamespaceConfig{},
+			OrganizationNamespace: OrganizationNamespaceConfig,
+			"entropy/firehose":    c,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		configs  NamespaceConfigMapType
+		problems []string
+	}{
+		{
+			name:    "should accept the predefined namespaces",
+			configs: PreDefinedSystemNamespaceConfig,
+		},
+		{
+			name:    "should accept a resource namespace inheriting the organization",
+			configs: withFirehose(func(c *NamespaceConfig) {}),
+		},
+		{
+			name: "should reject names the authz schema doesn't allow",
+			configs: NamespaceConfigMapType{
+				UserPrincipal:       NamespaceConfig{},
+				"Entropy/Firehose":  NamespaceConfig{Roles: map[string][]string{"sink-editor": {UserPrincipal}}},
+				"entropy/firehose2": NamespaceConfig{Permissions: map[string][]string{"v": {"owner"}}},
+			},
+			problems: []string{
+				"namespace Entropy/Firehose: not a valid definition name",
+				"namespace Entropy/Firehose: role sink-editor is not a valid relation name",
+				"namespace entropy/firehose2: permission v is not a valid relation name",
+				"namespace entropy/firehose2: permission v: owner is not a role or permission",
+			},
+		},
+		{
+			name: "should reject a role granted to principals other than users and groups",
+			configs: withFirehose(func(c *NamespaceConfig) {
+				c.Roles["sink_editor"] = []string{"shield/project"}
+				c.Roles["viewer"] = nil
+			}),
+			problems: []string{
+				"namespace entropy/firehose: role sink_editor can't be granted to shield/project",
+				"namespace entropy/firehose: role viewer has no principals",
+			},
+		},
+		{
+			name: "should reject a permission and a role sharing a name",
+			configs: withFirehose(func(c *NamespaceConfig) {
+				c.Permissions["sink_editor"] = []string{OwnerRole}
+			}),
+			problems: []string{"namespace entropy/firehose: sink_editor is both a role and a permission"},
+		},
+		{
+			name: "should reject undefined relations and orphan permissions",
+			configs: withFirehose(func(c *NamespaceConfig) {
+				c.Permissions["sink_edit"] = []string{"sink_admin", "organization", "project:owner", "organization:sink_editor"}
+				c.Permissions["delete"] = nil
+			}),
+			problems: []string{
+				"namespace entropy/firehose: permission delete is not granted to any role",
+				"namespace entropy/firehose: permission sink_edit: sink_admin is not a role or permission",
+				"namespace entropy/firehose: permission sink_edit: organization is not a role or permission",
+				"namespace entropy/firehose: permission sink_edit: project is not an inherited namespace",
+				"namespace entropy/firehose: permission sink_edit: sink_editor is not a role or permission of shield/organization",
+			},
+		},
+		{
+			name: "should reject an inherited namespace that isn't defined",
+			configs: withFirehose(func(c *NamespaceConfig) {
+				c.InheritedNamespaces = append(c.InheritedNamespaces, InheritedNamespace{Name: ProjectRelationName, NamespaceId: ProjectNamespace})
+			}),
+			problems: []string{"namespace entropy/firehose: inherited namespace shield/project is not defined"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.configs)
+			if len(tt.problems) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidSchema)
+			assert.Equal(t, ValidationError{Problems: tt.problems}, err)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/api/v1beta1"
+	"github.com/odpf/shield/internal/server/grpc_interceptors"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+)
+
+// the access paths are served next to the gateway api, expanding the
+// access to a resource and listing the resources a user has access to have
+// no rpcs of their own
+const (
+	accessExpandPath    = "/admin/v1beta1/access/expand"
+	accessResourcesPath = "/admin/v1beta1/access/resources"
+	accessCheckPath     = "/admin/v1beta1/access/check"
+)
+
+type resourceResponse struct {
+	ID             string    `json:"id"`
+	URN            string    `json:"urn"`
+	Name           string    `json:"name"`
+	NamespaceID    string    `json:"namespace_id"`
+	ProjectID      string    `json:"project_id,omitempty"`
+	OrganizationID string    `json:"organization_id,omitempty"`
+	UserID         string    `json:"user_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type accessObjectResponse struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+}
+
+type accessSubjectResponse struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+	Relation  string `json:"relation,omitempty"`
+}
+
+type accessTreeResponse struct {
+	Object    accessObjectResponse    `json:"object"`
+	Relation  string                  `json:"relation"`
+	Operation string                  `json:"operation,omitempty"`
+	Subjects  []accessSubjectResponse `json:"subjects,omitempty"`
+	Children  []accessTreeResponse    `json:"children,omitempty"`
+}
+
+func newAccessTreeResponse(tree relation.AccessTree) accessTreeResponse {
+	resp := accessTreeResponse{
+		Object:    accessObjectResponse{ID: tree.Object.ID, Namespace: tree.Object.NamespaceID},
+		Relation:  tree.Relation,
+		Operation: tree.Operation,
+	}
+	for _, sub := range tree.Subjects {
+		resp.Subjects = append(resp.Subjects, accessSubjectResponse{ID: sub.ID, Namespace: sub.Namespace, Relation: sub.RoleID})
+	}
+	for _, child := range tree.Children {
+		resp.Children = append(resp.Children, newAccessTreeResponse(child))
+	}
+	return resp
+}
+
+type accessCheckResponse struct {
+	Namespace  string `json:"namespace"`
+	ObjectID   string `json:"object_id"`
+	Permission string `json:"permission"`
+	Allowed    bool   `json:"allowed"`
+}
+
+// accessCheckHandler checks the permissions of the current user on many
+// resources at once, every check query parameter is a
+// <namespace>:<object-id>#<permission> and the results are in the order of
+// the checks. A resource that doesn't exist is denied.
+func accessCheckHandler(resourceService *resource.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		values := r.URL.Query()["check"]
+		if len(values) == 0 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "check is required"})
+			return
+		}
+		checks := make([]resource.Check, len(values))
+		for i, v := range values {
+			ns, rest, _ := strings.Cut(v, ":")
+			hash := strings.LastIndex(rest, "#")
+			if ns == "" || hash < 1 || hash == len(rest)-1 {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "check " + strconv.Quote(v) + " should be <namespace>:<object-id>#<permission>"})
+				return
+			}
+			checks[i] = resource.Check{
+				Resource: resource.Resource{Name: rest[:hash], NamespaceID: ns},
+				Action:   action.Action{ID: rest[hash+1:]},
+			}
+		}
+
+		allowed, err := resourceService.CheckAuthzBatch(r.Context(), checks)
+		if err != nil {
+			if errors.Is(err, resource.ErrTooManyChecks) {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+				return
+			}
+			writeAccessError(w, err)
+			return
+		}
+
+		resp := struct {
+			Results []accessCheckResponse `json:"results"`
+		}{Results: make([]accessCheckResponse, len(checks))}
+		for i, c := range checks {
+			resp.Results[i] = accessCheckResponse{
+				Namespace:  c.Resource.NamespaceID,
+				ObjectID:   c.Resource.Name,
+				Permission: c.Action.ID,
+				Allowed:    allowed[i],
+			}
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// accessExpandHandler returns the tree of the relations through which the
+// subjects get an action on a resource, the resource is referred to the way
+// permission checks refer to it, by the id of the objects of the system
+// namespaces and by the name of the other resources
+func accessExpandHandler(resourceService *resource.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		query := r.URL.Query()
+		ns, objectID, act := query.Get("namespace"), query.Get("object_id"), query.Get("action")
+		if ns == "" || objectID == "" || act == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "namespace, object_id and action are required"})
+			return
+		}
+		c, err := relation.ParseConsistency(r.Header.Get(v1beta1.ConsistencyHeader))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+			return
+		}
+
+		request, err := relation.ParseCheckContext(strings.Split(r.Header.Get(v1beta1.CheckContextHeader), ","))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+			return
+		}
+		ctx := relation.WithCheckContext(relation.WithConsistency(r.Context(), c), relation.CheckContext{Request: request})
+
+		tree, err := resourceService.ExpandAccess(ctx, resource.Resource{
+			Name:        objectID,
+			NamespaceID: ns,
+		}, action.Action{ID: act})
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, struct {
+			Tree accessTreeResponse `json:"tree"`
+		}{Tree: newAccessTreeResponse(tree)})
+	})
+}
+
+// accessResourcesHandler lists a page of the resources of a namespace a user
+// is allowed an action on, users can only list their own resources
+func accessResourcesHandler(resourceService *resource.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		query := r.URL.Query()
+		ns, act := query.Get("namespace"), query.Get("action")
+		if ns == "" || act == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "namespace and action are required"})
+			return
+		}
+		var pageSize, pageNum int64
+		var err error
+		if v := query.Get("page_size"); v != "" {
+			if pageSize, err = strconv.ParseInt(v, 10, 32); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "page_size should be a number"})
+				return
+			}
+		}
+		if v := query.Get("page_num"); v != "" {
+			if pageNum, err = strconv.ParseInt(v, 10, 32); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "page_num should be a number"})
+				return
+			}
+		}
+		c, err := relation.ParseConsistency(r.Header.Get(v1beta1.ConsistencyHeader))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+			return
+		}
+
+		request, err := relation.ParseCheckContext(strings.Split(r.Header.Get(v1beta1.CheckContextHeader), ","))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+			return
+		}
+		ctx := relation.WithCheckContext(relation.WithConsistency(r.Context(), c), relation.CheckContext{Request: request})
+
+		paged, err := resourceService.ListUserResources(ctx, query.Get("user_id"), ns, action.Action{ID: act}, int32(pageSize), int32(pageNum))
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		resp := struct {
+			Count     int32              `json:"count"`
+			Resources []resourceResponse `json:"resources"`
+		}{Count: paged.Count, Resources: []resourceResponse{}}
+		for _, res := range paged.Resources {
+			resp.Resources = append(resp.Resources, resourceResponse{
+				ID:             res.Idxa,
+				URN:            res.URN,
+				Name:           res.Name,
+				NamespaceID:    res.NamespaceID,
+				ProjectID:      res.ProjectID,
+				OrganizationID: res.OrganizationID,
+				UserID:         res.UserID,
+				CreatedAt:      res.CreatedAt,
+				UpdatedAt:      res.UpdatedAt,
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// identityHandler sets the email of the caller on the context of the
+// request the way the grpc interceptors do, from the identity header unless
+// it is disabled and from the bearer token which takes precedence
+func identityHandler(cfg Config, authenticator grpc_interceptors.Authenticator, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if !cfg.DisableIdentityHeader {
+			ctx = user.SetContextWithEmail(ctx, r.Header.Get(cfg.IdentityProxyHeader))
+		}
+
+		if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != r.Header.Get("Authorization") {
+			usr, err := authenticator.Authenticate(ctx, token)
+			if err != nil {
+				writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid_token", ErrorDescription: err.Error()})
+				return
+			}
+			ctx = user.SetContextWithEmail(ctx, usr.Email)
+		}
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func writeAccessError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, user.ErrMissingEmail),
+		errors.Is(err, user.ErrNotExist),
+		errors.Is(err, user.ErrDisabled):
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated", ErrorDescription: err.Error()})
+	case errors.Is(err, shielderrors.ErrForbidden):
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden", ErrorDescription: err.Error()})
+	case errors.Is(err, resource.ErrNotExist):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "server_error"})
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessCheckHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{name: "should refuse a request without checks", method: http.MethodGet, target: accessCheckPath, want: http.StatusBadRequest},
+		{name: "should refuse a check without a permission", method: http.MethodGet, target: accessCheckPath + "?check=entropy/firehose:f1", want: http.StatusBadRequest},
+		{name: "should refuse a check without an object", method: http.MethodGet, target: accessCheckPath + "?check=entropy/firehose:%23view", want: http.StatusBadRequest},
+		{name: "should refuse the methods other than get", method: http.MethodPost, target: accessCheckPath + "?check=entropy/firehose:f1%23view", want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			accessCheckHandler(nil).ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
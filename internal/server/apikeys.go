@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/odpf/shield/core/apikey"
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/core/user"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+)
+
+// the api keys have no rpcs, they are created, listed and revoked next to
+// the gateway api
+const apiKeysPath = "/admin/v1beta1/apikeys"
+
+var errAPIKeyOwners = errors.New("only one of user or serviceaccount can be set")
+
+type apiKeyResponse struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	UserID    string     `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	// Key is only set when the key is created, shield keeps its hash alone
+	Key string `json:"key,omitempty"`
+}
+
+func newAPIKeyResponse(key apikey.APIKey) apiKeyResponse {
+	resp := apiKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		UserID:    key.UserID,
+		CreatedAt: key.CreatedAt,
+	}
+	if key.IsRevoked() {
+		resp.RevokedAt = &key.RevokedAt
+	}
+	return resp
+}
+
+// apiKeysHandler lists the keys of the user or the service account of the
+// user or serviceaccount query parameter, creates one named after the name
+// query parameter with POST and revokes the key of the id query parameter,
+// an id or a prefix, with DELETE. The keys are those of the current user
+// without the parameters, see manageAPIKeys for who manages the keys of
+// others.
+func apiKeysHandler(userService *user.Service, serviceUserService *serviceuser.Service, apiKeyService *apikey.Service, orgService userOrgService, relationService permissionChecker, superusers []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		currentUser, err := userService.FetchCurrentUser(r.Context())
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		manageable := func(ctx context.Context, userID string) error {
+			return manageAPIKeys(ctx, serviceUserService, orgService, relationService, superusers, currentUser, userID)
+		}
+
+		query := r.URL.Query()
+		switch r.Method {
+		case http.MethodGet:
+			userID, err := apiKeyOwner(r.Context(), serviceUserService, currentUser, query)
+			if err == nil {
+				err = manageable(r.Context(), userID)
+			}
+			if err != nil {
+				writeAPIKeyError(w, err)
+				return
+			}
+
+			keys, err := apiKeyService.List(r.Context(), userID)
+			if err != nil {
+				writeAPIKeyError(w, err)
+				return
+			}
+			resp := struct {
+				APIKeys []apiKeyResponse `json:"api_keys"`
+			}{APIKeys: []apiKeyResponse{}}
+			for _, k := range keys {
+				resp.APIKeys = append(resp.APIKeys, newAPIKeyResponse(k))
+			}
+			writeJSON(w, http.StatusOK, resp)
+		case http.MethodPost:
+			userID, err := apiKeyOwner(r.Context(), serviceUserService, currentUser, query)
+			if err == nil {
+				err = manageable(r.Context(), userID)
+			}
+			if err != nil {
+				writeAPIKeyError(w, err)
+				return
+			}
+
+			created, key, err := apiKeyService.Create(r.Context(), userID, query.Get("name"))
+			if err != nil {
+				writeAPIKeyError(w, err)
+				return
+			}
+			resp := newAPIKeyResponse(created)
+			resp.Key = key
+			writeJSON(w, http.StatusOK, resp)
+		case http.MethodDelete:
+			idOrPrefix := query.Get("id")
+			if idOrPrefix == "" {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+				return
+			}
+
+			key, err := apiKeyService.Get(r.Context(), idOrPrefix)
+			if err == nil {
+				err = manageable(r.Context(), key.UserID)
+			}
+			if err != nil {
+				writeAPIKeyError(w, err)
+				return
+			}
+
+			revoked, err := apiKeyService.Revoke(r.Context(), key.ID)
+			if err != nil {
+				writeAPIKeyError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, newAPIKeyResponse(revoked))
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+		}
+	})
+}
+
+type serviceUserLister interface {
+	List(ctx context.Context) ([]serviceuser.ServiceUser, error)
+}
+
+// manageAPIKeys checks the current user manages the keys of the user. Users
+// manage their own keys and the superusers those of anyone. The admins of
+// every organization a service account is a member of manage its keys, the
+// admins never manage the keys of human users as they can add anyone to
+// their organization.
+func manageAPIKeys(ctx context.Context, serviceUsers serviceUserLister, orgService userOrgService, relationService permissionChecker, superusers []string, currentUser user.User, userID string) error {
+	if userID == currentUser.ID || checkSuperuser(superusers, currentUser) == nil {
+		return nil
+	}
+
+	list, err := serviceUsers.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, su := range list {
+		if su.UserID == userID {
+			return adminOfEveryOrg(ctx, orgService, relationService, currentUser, user.User{ID: userID})
+		}
+	}
+	return shielderrors.ErrForbidden
+}
+
+// apiKeyOwner returns the id of the user of the user or the serviceaccount
+// query parameter, or of the current user without them
+func apiKeyOwner(ctx context.Context, serviceUserService *serviceuser.Service, currentUser user.User, query url.Values) (string, error) {
+	userID, serviceAccountID := query.Get("user"), query.Get("serviceaccount")
+	switch {
+	case userID != "" && serviceAccountID != "":
+		return "", errAPIKeyOwners
+	case serviceAccountID != "":
+		serviceUser, err := serviceUserService.Get(ctx, serviceAccountID)
+		if err != nil {
+			return "", err
+		}
+		return serviceUser.UserID, nil
+	case userID != "":
+		return userID, nil
+	default:
+		return currentUser.ID, nil
+	}
+}
+
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apikey.ErrNotExist),
+		errors.Is(err, user.ErrNotExist),
+		errors.Is(err, user.ErrInvalidUUID),
+		errors.Is(err, user.ErrInvalidID),
+		errors.Is(err, serviceuser.ErrNotExist),
+		errors.Is(err, serviceuser.ErrInvalidID):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+	case errors.Is(err, apikey.ErrRevoked):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "conflict", ErrorDescription: err.Error()})
+	case errors.Is(err, apikey.ErrInvalidDetail),
+		errors.Is(err, errAPIKeyOwners):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+	default:
+		writeAccessError(w, err)
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/core/user"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryServiceUserLister []serviceuser.ServiceUser
+
+func (l memoryServiceUserLister) List(ctx context.Context) ([]serviceuser.ServiceUser, error) {
+	return l, nil
+}
+
+func TestManageAPIKeys(t *testing.T) {
+	serviceUsers := memoryServiceUserLister{{ID: "billing", UserID: "billing-user"}, {ID: "shared", UserID: "shared-user"}}
+	orgs := memoryUserOrgService{
+		"john":         {{ID: "odpf"}},
+		"billing-user": {{ID: "odpf"}},
+		"shared-user":  {{ID: "odpf"}, {ID: "gotocompany"}},
+	}
+	admins := memoryPermissionChecker{"admin": {"odpf"}}
+	superusers := []string{"root@odpf.io"}
+
+	tests := []struct {
+		name    string
+		current user.User
+		userID  string
+		wantErr error
+	}{
+		{
+			name:    "should let users manage their own keys",
+			current: user.User{ID: "john"},
+			userID:  "john",
+		},
+		{
+			name:    "should let the superusers manage the keys of anyone",
+			current: user.User{ID: "root", Email: "root@odpf.io"},
+			userID:  "john",
+		},
+		{
+			name:    "should let the admins manage the keys of the service accounts of their organization",
+			current: user.User{ID: "admin"},
+			userID:  "billing-user",
+		},
+		{
+			name:    "should return forbidden if an admin manages the keys of a member of their organization",
+			current: user.User{ID: "admin"},
+			userID:  "john",
+			wantErr: shielderrors.ErrForbidden,
+		},
+		{
+			name:    "should return forbidden if an admin manages the keys of a service account of another organization too",
+			current: user.User{ID: "admin"},
+			userID:  "shared-user",
+			wantErr: shielderrors.ErrForbidden,
+		},
+		{
+			name:    "should return forbidden if a member manages the keys of a service account",
+			current: user.User{ID: "john"},
+			userID:  "billing-user",
+			wantErr: shielderrors.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := manageAPIKeys(context.Background(), serviceUsers, orgs, admins, superusers, tt.current, tt.userID)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/core/user"
+)
+
+// the audit logs have no rpc, they are listed next to the gateway api
+const auditLogsPath = "/admin/v1beta1/audit"
+
+type auditLogResponse struct {
+	ID           string    `json:"id"`
+	Actor        string    `json:"actor"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	OldPayload   any       `json:"old_payload"`
+	NewPayload   any       `json:"new_payload"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// auditLogsHandler lists the changes made by the actor of the actor query
+// parameter, all of them without it, between the since and until query
+// parameters, RFC3339 times leaving the range open on their side when they
+// aren't set. The logs hold the resources of every organization, only the
+// superusers list them.
+func auditLogsHandler(userService *user.Service, auditService *audit.Service, superusers []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		currentUser, err := userService.FetchCurrentUser(r.Context())
+		if err == nil {
+			err = checkSuperuser(superusers, currentUser)
+		}
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		query := r.URL.Query()
+		flt := audit.Filter{Actor: query.Get("actor")}
+		for param, t := range map[string]*time.Time{"since": &flt.Since, "until": &flt.Until} {
+			if query.Get(param) == "" {
+				continue
+			}
+			if *t, err = time.Parse(time.RFC3339, query.Get(param)); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: param + " should be an RFC3339 time"})
+				return
+			}
+		}
+
+		logs, err := auditService.List(r.Context(), flt)
+		if err != nil {
+			writeAuditError(w, err)
+			return
+		}
+		resp := struct {
+			Logs []auditLogResponse `json:"logs"`
+		}{Logs: []auditLogResponse{}}
+		for _, l := range logs {
+			resp.Logs = append(resp.Logs, auditLogResponse{
+				ID:           l.ID,
+				Actor:        l.Actor,
+				Action:       l.Action,
+				ResourceType: l.ResourceType,
+				ResourceID:   l.ResourceID,
+				OldPayload:   l.OldPayload,
+				NewPayload:   l.NewPayload,
+				CreatedAt:    l.CreatedAt,
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func writeAuditError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, audit.ErrInvalidTimeRange):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+	default:
+		writeAccessError(w, err)
+	}
+}
@@ -0,0 +1,289 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/api/v1beta1"
+	"github.com/odpf/shield/internal/schema"
+)
+
+// the batches are served next to the gateway api, creating relations and
+// policies in batches has no rpcs of its own
+const (
+	relationsBatchPath = "/admin/v1beta1/relations/batch"
+	policiesBatchPath  = "/admin/v1beta1/policies/batch"
+
+	// maxBatchBodyBytes fits a batch of the largest size
+	maxBatchBodyBytes = 4 << 20
+)
+
+// relationBody is a relation of a batch, with the fields of the body of
+// CreateRelation
+type relationBody struct {
+	ObjectID        string `json:"object_id"`
+	ObjectNamespace string `json:"object_namespace"`
+	// Subject is namespace:id, the id of a user being their email
+	Subject  string `json:"subject"`
+	RoleName string `json:"role_name"`
+}
+
+type relationResponse struct {
+	Index           int    `json:"index"`
+	ID              string `json:"id"`
+	ObjectID        string `json:"object_id"`
+	ObjectNamespace string `json:"object_namespace"`
+	Subject         string `json:"subject"`
+	RoleName        string `json:"role_name"`
+}
+
+// policyBody is a policy of a batch, with the fields of the body of
+// CreatePolicy and the effect and condition of its headers
+type policyBody struct {
+	RoleID      string `json:"role_id"`
+	NamespaceID string `json:"namespace_id"`
+	ActionID    string `json:"action_id"`
+	Effect      string `json:"effect,omitempty"`
+	Condition   string `json:"condition,omitempty"`
+}
+
+type policyResponse struct {
+	Index       int    `json:"index"`
+	ID          string `json:"id"`
+	RoleID      string `json:"role_id"`
+	NamespaceID string `json:"namespace_id"`
+	ActionID    string `json:"action_id"`
+	Effect      string `json:"effect"`
+	Condition   string `json:"condition,omitempty"`
+}
+
+// batchFailureResponse is an item of a batch that wasn't created, by its
+// index in the batch
+type batchFailureResponse struct {
+	Index            int    `json:"index"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// batchFailures sorts the failures by index and returns the indexes of the
+// items of a batch of size n which didn't fail, in order
+func batchFailures(failures []batchFailureResponse, n int) []int {
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Index < failures[j].Index })
+	failed := map[int]bool{}
+	for _, f := range failures {
+		failed[f.Index] = true
+	}
+	var indexes []int
+	for i := 0; i < n; i++ {
+		if !failed[i] {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// relationsBatchHandler creates up to relation.MaxBatchSize relations at
+// once. Like CreateRelation, the current user should be allowed to edit the
+// object of every relation. The relations the user can't create or which
+// are invalid are reported as failures by their index in the batch and the
+// others are created in a single write. The token of the write is set in the
+// zedtoken header.
+func relationsBatchHandler(resourceService *resource.Service, relationService *relation.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		var req struct {
+			Relations []relationBody `json:"relations"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "body should be json"})
+			return
+		}
+		if len(req.Relations) > relation.MaxBatchSize {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: fmt.Sprintf("a batch has at most %d relations", relation.MaxBatchSize)})
+			return
+		}
+
+		var failures []batchFailureResponse
+		var checks []resource.Check
+		var checked []int
+		rels := make([]relation.RelationV2, len(req.Relations))
+		for i, body := range req.Relations {
+			principal, subjectID, ok := strings.Cut(body.Subject, ":")
+			if !ok || body.ObjectID == "" || body.ObjectNamespace == "" || body.RoleName == "" {
+				failures = append(failures, batchFailureResponse{Index: i, Error: "invalid_request",
+					ErrorDescription: "object_id, object_namespace, role_name and a subject like namespace:id are required"})
+				continue
+			}
+			rels[i] = relation.RelationV2{
+				Object:  relation.Object{ID: body.ObjectID, NamespaceID: body.ObjectNamespace},
+				Subject: relation.Subject{ID: subjectID, Namespace: principal, RoleID: body.RoleName},
+			}
+			checks = append(checks, resource.Check{
+				Resource: resource.Resource{Name: body.ObjectID, NamespaceID: body.ObjectNamespace},
+				Action:   action.Action{ID: schema.EditPermission},
+			})
+			checked = append(checked, i)
+		}
+
+		var permitted []relation.RelationV2
+		var indexes []int
+		for start := 0; start < len(checks); start += resource.MaxBatchChecks {
+			end := start + resource.MaxBatchChecks
+			if end > len(checks) {
+				end = len(checks)
+			}
+			allowed, err := resourceService.CheckAuthzBatch(r.Context(), checks[start:end])
+			if err != nil {
+				writeAccessError(w, err)
+				return
+			}
+			for j, ok := range allowed {
+				i := checked[start+j]
+				if !ok {
+					failures = append(failures, batchFailureResponse{Index: i, Error: "forbidden",
+						ErrorDescription: "user does not have permission to perform this action"})
+					continue
+				}
+				permitted = append(permitted, rels[i])
+				indexes = append(indexes, i)
+			}
+		}
+
+		ctx, rev := relation.WithRevision(r.Context())
+		created, relFailures, err := relationService.CreateBatch(ctx, permitted)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "server_error", ErrorDescription: "failed to create the relations"})
+			return
+		}
+		for _, f := range relFailures {
+			failures = append(failures, relationFailure(indexes[f.Index], f.Err))
+		}
+
+		resp := struct {
+			Relations []relationResponse     `json:"relations"`
+			Failures  []batchFailureResponse `json:"failures"`
+		}{Relations: []relationResponse{}, Failures: []batchFailureResponse{}}
+		for j, i := range batchFailures(failures, len(req.Relations)) {
+			rel := created[j]
+			resp.Relations = append(resp.Relations, relationResponse{
+				Index:           i,
+				ID:              rel.ID,
+				ObjectID:        rel.Object.ID,
+				ObjectNamespace: rel.Object.NamespaceID,
+				Subject:         rel.Subject.Namespace + ":" + rel.Subject.ID,
+				RoleName:        rel.Subject.RoleID,
+			})
+		}
+		resp.Failures = append(resp.Failures, failures...)
+
+		if token := rev.Token(); token != "" {
+			w.Header().Set(v1beta1.ZedTokenHeader, token)
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func relationFailure(index int, err error) batchFailureResponse {
+	switch {
+	case errors.Is(err, relation.ErrInvalidDetail),
+		errors.Is(err, relation.ErrRoleOutsideOrg),
+		errors.Is(err, relation.ErrFetchingUser):
+		return batchFailureResponse{Index: index, Error: "invalid_request", ErrorDescription: err.Error()}
+	default:
+		return batchFailureResponse{Index: index, Error: "server_error", ErrorDescription: "failed to create the relation"}
+	}
+}
+
+// policiesBatchHandler creates up to policy.MaxBatchSize policies at once,
+// the effect and the condition of every policy are in its body rather than
+// in headers. The invalid and conflicting policies are reported as failures
+// by their index in the batch and the others are created in a single
+// transaction with a single update of the grants.
+func policiesBatchHandler(userService *user.Service, policyService *policy.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		if _, err := userService.FetchCurrentUser(r.Context()); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		var req struct {
+			Policies []policyBody `json:"policies"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "body should be json"})
+			return
+		}
+
+		policies := make([]policy.Policy, 0, len(req.Policies))
+		for _, body := range req.Policies {
+			policies = append(policies, policy.Policy{
+				RoleID:      body.RoleID,
+				NamespaceID: body.NamespaceID,
+				ActionID:    body.ActionID,
+				Effect:      strings.ToLower(body.Effect),
+				Condition:   body.Condition,
+			})
+		}
+		created, polFailures, err := policyService.CreateBatch(r.Context(), policies)
+		if err != nil {
+			if errors.Is(err, policy.ErrBatchTooLarge) {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "server_error", ErrorDescription: "failed to create the policies"})
+			return
+		}
+
+		failures := make([]batchFailureResponse, 0, len(polFailures))
+		for _, f := range polFailures {
+			failures = append(failures, policyFailure(f.Index, f.Err))
+		}
+		resp := struct {
+			Policies []policyResponse       `json:"policies"`
+			Failures []batchFailureResponse `json:"failures"`
+		}{Policies: []policyResponse{}, Failures: failures}
+		for j, i := range batchFailures(failures, len(req.Policies)) {
+			pol := created[j]
+			resp.Policies = append(resp.Policies, policyResponse{
+				Index:       i,
+				ID:          pol.ID,
+				RoleID:      pol.RoleID,
+				NamespaceID: pol.NamespaceID,
+				ActionID:    pol.ActionID,
+				Effect:      pol.Effect,
+				Condition:   pol.Condition,
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func policyFailure(index int, err error) batchFailureResponse {
+	switch {
+	case errors.Is(err, policy.ErrInvalidDetail):
+		return batchFailureResponse{Index: index, Error: "invalid_request", ErrorDescription: err.Error()}
+	case errors.Is(err, policy.ErrConflict):
+		return batchFailureResponse{Index: index, Error: "conflict", ErrorDescription: err.Error()}
+	default:
+		return batchFailureResponse{Index: index, Error: "server_error", ErrorDescription: "failed to create the policy"}
+	}
+}
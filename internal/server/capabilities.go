@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"google.golang.org/protobuf/proto"
+)
+
+// the capabilities are served next to the gateway api so the clients can
+// tell what the server they talk to supports, a server without the path is
+// older than the capabilities
+const capabilitiesPath = "/admin/v1beta1/capabilities"
+
+// listFilters are the fields the handlers narrow the lists down by, the
+// other fields of the requests are ignored. The fields that aren't in the
+// request are globs of the filter header, metadata.* stands for the globs
+// of any metadata key.
+var listFilters = map[proto.Message][]string{
+	&shieldv1beta1.ListUsersRequest{}:         {"keyword", "name", "email", "metadata.*"},
+	&shieldv1beta1.ListGroupsRequest{}:        {"org_id", "name", "slug", "metadata.*"},
+	&shieldv1beta1.ListOrganizationsRequest{}: {"name", "slug", "metadata.*"},
+	&shieldv1beta1.ListProjectsRequest{}:      {"name", "slug", "metadata.*"},
+}
+
+// validateOnlyRequests are the requests the handlers validate without
+// storing anything with the validate only header, the others ignore it
+var validateOnlyRequests = []proto.Message{
+	&shieldv1beta1.CreateOrganizationRequest{},
+	&shieldv1beta1.UpdateOrganizationRequest{},
+	&shieldv1beta1.CreateProjectRequest{},
+	&shieldv1beta1.UpdateProjectRequest{},
+	&shieldv1beta1.CreatePolicyRequest{},
+	&shieldv1beta1.UpdatePolicyRequest{},
+}
+
+type capabilitiesResponse struct {
+	Version string `json:"version"`
+	// ListFilters are the filters of the list requests by the full name of
+	// the request message
+	ListFilters map[string][]string `json:"list_filters"`
+	// ValidateOnly are the full names of the requests validated with the
+	// validate only header
+	ValidateOnly []string `json:"validate_only"`
+}
+
+// capabilitiesHandler returns the version of the server, the filters it
+// applies on the list requests and the requests it validates only
+func capabilitiesHandler(version string) http.Handler {
+	resp := capabilitiesResponse{Version: version, ListFilters: map[string][]string{}}
+	for req, fields := range listFilters {
+		resp.ListFilters[string(req.ProtoReflect().Descriptor().FullName())] = fields
+	}
+	for _, req := range validateOnlyRequests {
+		resp.ValidateOnly = append(resp.ValidateOnly, string(req.ProtoReflect().Descriptor().FullName()))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
@@ -1,5 +1,14 @@
 package server
 
+import (
+	"time"
+
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/odpf/shield/internal/ratelimit"
+	"github.com/odpf/shield/internal/server/grpc_interceptors"
+	"github.com/odpf/shield/pkg/tlsutil"
+)
+
 type Config struct {
 	// port to listen on
 	Port int `yaml:"port" mapstructure:"port" default:"8080"`
@@ -7,6 +16,8 @@ type Config struct {
 	Host string `yaml:"host" mapstructure:"host" default:"127.0.0.1"`
 
 	Name string
+	// Version is the version the server was built with, it isn't configured
+	Version string `yaml:"-" mapstructure:"-"`
 
 	// RulesPath is a directory path where ruleset is defined
 	// that this service should implement
@@ -19,6 +30,11 @@ type Config struct {
 	// Headers which will have user's email id
 	IdentityProxyHeader string `yaml:"identity_proxy_header" mapstructure:"identity_proxy_header" default:"X-Shield-Email"`
 
+	// DisableIdentityHeader stops trusting the identity header of the
+	// requests to the api, they are then only authenticated with bearer
+	// tokens such as the id tokens of the oidc providers
+	DisableIdentityHeader bool `yaml:"disable_identity_header" mapstructure:"disable_identity_header"`
+
 	// Header which will have user_id
 	UserIDHeader string `yaml:"user_id_header" mapstructure:"user_id_header" default:"X-Shield-User-Id"`
 
@@ -29,4 +45,33 @@ type Config struct {
 	// ResourcesPathSecretSecret could be a env name, file path or actual value required
 	// to access ResourcesPathSecretPath files
 	ResourcesConfigPathSecret string `yaml:"resources_config_path_secret" mapstructure:"resources_config_path_secret"`
+
+	// Superusers are the emails of the platform admins, only they delete the
+	// actions, the namespaces, the policies and the roles of no organization
+	// which every organization shares
+	Superusers []string `yaml:"superusers" mapstructure:"superusers"`
+
+	// DeletedResourceRetention is how long deleted organizations and projects
+	// can be restored before they are purged along with their relations,
+	// deleted resources are never purged when it is 0
+	DeletedResourceRetention time.Duration `yaml:"deleted_resource_retention" mapstructure:"deleted_resource_retention" default:"720h"`
+
+	// MetricsPath is the path the prometheus metrics are served at on the
+	// port of the api
+	MetricsPath string `yaml:"metrics_path" mapstructure:"metrics_path" default:"/metrics"`
+
+	// RequestLog configures the structured logs of the requests to the api
+	RequestLog grpc_interceptors.RequestLogConfig `yaml:"request_log" mapstructure:"request_log"`
+
+	// RateLimit limits the requests to the api of every identity and in
+	// every organization
+	RateLimit ratelimit.Config `yaml:"rate_limit" mapstructure:"rate_limit"`
+
+	// Idempotency keeps the idempotency keys of the requests creating the
+	// resources of shield
+	Idempotency idempotency.Config `yaml:"idempotency" mapstructure:"idempotency"`
+
+	// TLS serves the api over tls on a port of its own, the grpc services
+	// and the http handlers are both served on it
+	TLS tlsutil.Config `yaml:"tls" mapstructure:"tls"`
 }
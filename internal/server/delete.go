@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/api"
+	"github.com/odpf/shield/internal/schema"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+)
+
+// the v1beta1 api has no delete rpcs, the organizations, projects, groups,
+// roles, actions, namespaces and policies are deleted next to the gateway
+// api with DELETE and the id query parameter
+const (
+	organizationsDeletePath = "/admin/v1beta1/organizations/delete"
+	projectsDeletePath      = "/admin/v1beta1/projects/delete"
+	groupsDeletePath        = "/admin/v1beta1/groups/delete"
+	rolesDeletePath         = "/admin/v1beta1/roles/delete"
+	actionsDeletePath       = "/admin/v1beta1/actions/delete"
+	namespacesDeletePath    = "/admin/v1beta1/namespaces/delete"
+	policiesDeletePath      = "/admin/v1beta1/policies/delete"
+)
+
+// deleteHandlers are the handlers of the delete paths. The organizations
+// are deleted by their admins, the projects and the groups by their owners
+// and the owners of their organization, the custom roles of an
+// organization by its admins. The actions, namespaces, policies and the
+// roles of no organization are shared by every organization, only the
+// superusers delete them.
+func deleteHandlers(deps api.Deps, superusers []string) map[string]http.Handler {
+	return map[string]http.Handler{
+		organizationsDeletePath: deleteHandler(deps.UserService, func(ctx context.Context, usr user.User, idOrSlug string) error {
+			org, err := deps.OrgService.Get(ctx, idOrSlug)
+			if err != nil {
+				return err
+			}
+			if err := permitted(ctx, deps.RelationService, usr, schema.OrganizationNamespace, org.ID, schema.EditPermission); err != nil {
+				return err
+			}
+			return deps.OrgService.Delete(ctx, org.ID)
+		}),
+		projectsDeletePath: deleteHandler(deps.UserService, func(ctx context.Context, usr user.User, idOrSlug string) error {
+			prj, err := deps.ProjectService.Get(ctx, idOrSlug)
+			if err != nil {
+				return err
+			}
+			if err := permitted(ctx, deps.RelationService, usr, schema.ProjectNamespace, prj.ID, schema.DeletePermission); err != nil {
+				return err
+			}
+			return deps.ProjectService.Delete(ctx, prj.ID)
+		}),
+		groupsDeletePath: deleteHandler(deps.UserService, func(ctx context.Context, usr user.User, idOrSlug string) error {
+			grp, err := deps.GroupService.Get(ctx, idOrSlug)
+			if err != nil {
+				return err
+			}
+			if err := permitted(ctx, deps.RelationService, usr, schema.GroupNamespace, grp.ID, schema.DeletePermission); err != nil {
+				return err
+			}
+			return deps.GroupService.Delete(ctx, grp.ID)
+		}),
+		rolesDeletePath: deleteHandler(deps.UserService, func(ctx context.Context, usr user.User, id string) error {
+			rl, err := deps.RoleService.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			if rl.OrgID == "" {
+				err = checkSuperuser(superusers, usr)
+			} else {
+				err = permitted(ctx, deps.RelationService, usr, schema.OrganizationNamespace, rl.OrgID, schema.EditPermission)
+			}
+			if err != nil {
+				return err
+			}
+			return deps.RoleService.Delete(ctx, rl.ID)
+		}),
+		actionsDeletePath: deleteHandler(deps.UserService, func(ctx context.Context, usr user.User, id string) error {
+			if err := checkSuperuser(superusers, usr); err != nil {
+				return err
+			}
+			return deps.ActionService.Delete(ctx, id)
+		}),
+		namespacesDeletePath: deleteHandler(deps.UserService, func(ctx context.Context, usr user.User, id string) error {
+			if err := checkSuperuser(superusers, usr); err != nil {
+				return err
+			}
+			return deps.NamespaceService.Delete(ctx, id)
+		}),
+		policiesDeletePath: deleteHandler(deps.UserService, func(ctx context.Context, usr user.User, id string) error {
+			if err := checkSuperuser(superusers, usr); err != nil {
+				return err
+			}
+			return deps.PolicyService.Delete(ctx, id)
+		}),
+	}
+}
+
+// deleteHandler deletes the object of the id query parameter as the current
+// user
+func deleteHandler(userService *user.Service, del func(ctx context.Context, usr user.User, id string) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.Header().Set("Allow", http.MethodDelete)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		currentUser, err := userService.FetchCurrentUser(r.Context())
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+			return
+		}
+
+		if err := del(r.Context(), currentUser, id); err != nil {
+			writeDeleteError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, struct {
+			ID string `json:"id"`
+		}{ID: id})
+	})
+}
+
+func writeDeleteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, organization.ErrNotExist), errors.Is(err, organization.ErrInvalidUUID), errors.Is(err, organization.ErrInvalidID),
+		errors.Is(err, project.ErrNotExist), errors.Is(err, project.ErrInvalidUUID), errors.Is(err, project.ErrInvalidID),
+		errors.Is(err, group.ErrNotExist), errors.Is(err, group.ErrInvalidUUID), errors.Is(err, group.ErrInvalidID),
+		errors.Is(err, role.ErrNotExist), errors.Is(err, role.ErrInvalidID),
+		errors.Is(err, action.ErrNotExist), errors.Is(err, action.ErrInvalidID),
+		errors.Is(err, namespace.ErrNotExist), errors.Is(err, namespace.ErrInvalidID),
+		errors.Is(err, policy.ErrNotExist), errors.Is(err, policy.ErrInvalidUUID), errors.Is(err, policy.ErrInvalidID):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+	case errors.Is(err, organization.ErrInUse),
+		errors.Is(err, project.ErrInUse),
+		errors.Is(err, group.ErrInUse),
+		errors.Is(err, role.ErrInUse),
+		errors.Is(err, action.ErrInUse),
+		errors.Is(err, namespace.ErrInUse),
+		errors.Is(err, policy.ErrInUse):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "conflict", ErrorDescription: err.Error()})
+	default:
+		writeAccessError(w, err)
+	}
+}
+
+// permitted returns ErrForbidden unless the user has the permission on the
+// object
+func permitted(ctx context.Context, relationService *relation.Service, usr user.User, namespaceID, id, permission string) error {
+	allowed, err := relationService.CheckPermission(ctx, usr, namespace.Namespace{ID: namespaceID}, id, action.Action{ID: permission})
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return shielderrors.ErrForbidden
+	}
+	return nil
+}
+
+// checkSuperuser returns ErrForbidden unless the email of the user is one
+// of the superusers
+func checkSuperuser(superusers []string, usr user.User) error {
+	for _, email := range superusers {
+		if strings.EqualFold(email, usr.Email) {
+			return nil
+		}
+	}
+	return shielderrors.ErrForbidden
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/odpf/shield/core/folder"
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/resource"
+)
+
+// the folders have no rpcs, they are created and the resources moved into
+// them next to the gateway api
+const (
+	foldersPath     = "/admin/v1beta1/folders"
+	foldersMovePath = "/admin/v1beta1/folders/move"
+)
+
+type folderResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ProjectID string    `json:"project_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type folderMoveResponse struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	FolderID  string `json:"folder_id"`
+}
+
+// foldersHandler creates the folder named after the name query parameter
+// in the project of the project query parameter, an id or a slug. The
+// current user has to be an editor of the project.
+func foldersHandler(folderService *folder.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		projectID := r.URL.Query().Get("project")
+		if projectID == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "project is required"})
+			return
+		}
+
+		created, err := folderService.Create(r.Context(), folder.Folder{Name: r.URL.Query().Get("name"), ProjectID: projectID})
+		if err != nil {
+			writeFolderError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, folderResponse{ID: created.ID, Name: created.Name, ProjectID: created.ProjectID, CreatedAt: created.CreatedAt})
+	})
+}
+
+// folderMoveHandler moves the resource of the resource query parameter into
+// the folder of the folder query parameter, or back to the root of its
+// project without it. The current user has to be an editor of the resource
+// and of the folder.
+func folderMoveHandler(folderService *folder.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		resourceID := r.URL.Query().Get("resource")
+		if resourceID == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "resource is required"})
+			return
+		}
+
+		moved, err := folderService.Move(r.Context(), resourceID, r.URL.Query().Get("folder"))
+		if err != nil {
+			writeFolderError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, folderMoveResponse{ID: moved.Idxa, ProjectID: moved.ProjectID, FolderID: moved.FolderID})
+	})
+}
+
+func writeFolderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, folder.ErrNotExist),
+		errors.Is(err, project.ErrNotExist),
+		errors.Is(err, project.ErrInvalidUUID),
+		errors.Is(err, project.ErrInvalidID),
+		errors.Is(err, resource.ErrInvalidUUID),
+		errors.Is(err, resource.ErrInvalidID):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+	case errors.Is(err, folder.ErrConflict):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "conflict", ErrorDescription: err.Error()})
+	case errors.Is(err, folder.ErrNotProjectEditor),
+		errors.Is(err, folder.ErrNotResourceEditor),
+		errors.Is(err, folder.ErrNotFolderEditor):
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden", ErrorDescription: err.Error()})
+	case errors.Is(err, folder.ErrInvalidDetail),
+		errors.Is(err, folder.ErrOutsideProject):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+	default:
+		writeAccessError(w, err)
+	}
+}
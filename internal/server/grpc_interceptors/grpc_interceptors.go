@@ -3,12 +3,17 @@ package grpc_interceptors
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/odpf/shield/core/user"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+const bearerPrefix = "Bearer "
+
 func EnrichCtxWithIdentity(identityHeader string) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		md, ok := metadata.FromIncomingContext(ctx)
@@ -26,3 +31,41 @@ func EnrichCtxWithIdentity(identityHeader string) grpc.UnaryServerInterceptor {
 		return handler(ctx, req)
 	}
 }
+
+// Authenticator resolves the user a bearer token of a request belongs to
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (user.User, error)
+}
+
+// AuthenticatorFunc is a function used as an Authenticator
+type AuthenticatorFunc func(ctx context.Context, token string) (user.User, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, token string) (user.User, error) {
+	return f(ctx, token)
+}
+
+// EnrichCtxWithBearerIdentity sets the identity of the requests carrying a
+// bearer token in their authorization header to the user the token belongs
+// to, it must run after EnrichCtxWithIdentity to take precedence over the
+// identity header
+func EnrichCtxWithBearerIdentity(authenticator Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		values := md.Get("authorization")
+		if len(values) == 0 || !strings.HasPrefix(values[0], bearerPrefix) {
+			return handler(ctx, req)
+		}
+
+		usr, err := authenticator.Authenticate(ctx, strings.TrimPrefix(values[0], bearerPrefix))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		ctx = user.SetContextWithEmail(ctx, usr.Email)
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,153 @@
+package grpc_interceptors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/odpf/shield/internal/api/v1beta1"
+)
+
+// IdempotencyKeyHeader carries the idempotency key of a request, the
+// response of a request replayed for its key has IdempotentReplayedHeader
+const (
+	IdempotencyKeyHeader     = "idempotency-key"
+	IdempotentReplayedHeader = "idempotent-replayed"
+)
+
+// idempotentMethodPrefix selects the methods the idempotency keys apply to,
+// the ones creating the resources of shield
+const idempotentMethodPrefix = "/odpf.shield.v1beta1.ShieldService/Create"
+
+// optionHeaderPrefix is the prefix of the headers carrying the options the
+// messages have no fields for, the effect of a policy or the tags of a
+// resource, they are part of the request a key is used again with
+const optionHeaderPrefix = "x-shield-"
+
+// Idempotency makes the requests carrying an idempotency key once per key,
+// the retries get the response of the first request rather than creating
+// the same resource again. The keys are scoped by the identity of the
+// request, it must run after the identity is set. The validate only requests
+// store nothing, their responses aren't recorded for the key either.
+func Idempotency(service *idempotency.Service) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, idempotentMethodPrefix) {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(IdempotencyKeyHeader)
+		if len(values) == 0 {
+			return handler(ctx, req)
+		}
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		// the handler refuses an invalid value
+		if values := md.Get(v1beta1.ValidateOnlyHeader); len(values) > 0 {
+			if validate, err := strconv.ParseBool(values[0]); err != nil || validate {
+				return handler(ctx, req)
+			}
+		}
+
+		requestHash, err := hashRequest(msg, md)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		var resp interface{}
+		response, replayed, err := service.Do(ctx, idempotency.Key{
+			Key:         values[0],
+			Identity:    RequestIdentity(ctx),
+			Method:      info.FullMethod,
+			RequestHash: requestHash,
+		}, func(ctx context.Context) ([]byte, error) {
+			var err error
+			if resp, err = handler(ctx, req); err != nil {
+				return nil, err
+			}
+			return proto.Marshal(resp.(proto.Message))
+		})
+		switch {
+		case errors.Is(err, idempotency.ErrNotRecorded):
+			ctxzap.Extract(ctx).Warn("idempotency key not completed", zap.Error(err))
+			return resp, nil
+		case errors.Is(err, idempotency.ErrInvalidDetail), errors.Is(err, idempotency.ErrMismatch):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, idempotency.ErrInProgress):
+			return nil, status.Error(codes.Aborted, err.Error())
+		case err != nil:
+			return nil, err
+		case !replayed:
+			return resp, nil
+		}
+
+		replay, err := newResponse(info.FullMethod)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if err := proto.Unmarshal(response, replay); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedHeader, "true"))
+		return replay, nil
+	}
+}
+
+// hashRequest is the fingerprint of a request the key is used again with,
+// the message and the option headers of the request
+func hashRequest(msg proto.Message, md metadata.MD) (string, error) {
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(payload)
+
+	var keys []string
+	for key := range md {
+		if strings.HasPrefix(key, optionHeaderPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range md[key] {
+			h.Write([]byte("\n" + key + ":" + value))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newResponse is an empty response of the method, /package.Service/Method
+func newResponse(fullMethod string) (proto.Message, error) {
+	name := protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(fullMethod, "/"), "/", "."))
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil, err
+	}
+	method, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, errors.New(fullMethod + " is not a method")
+	}
+	typ, err := protoregistry.GlobalTypes.FindMessageByName(method.Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+	return typ.New().Interface(), nil
+}
@@ -0,0 +1,201 @@
+package grpc_interceptors_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/api/v1beta1"
+	"github.com/odpf/shield/internal/server/grpc_interceptors"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+)
+
+type memoryIdempotencyRepository map[string]idempotency.Key
+
+func (r memoryIdempotencyRepository) Reserve(ctx context.Context, key idempotency.Key) (idempotency.Key, bool, error) {
+	id := key.Identity + key.Method + key.Key
+	if recorded, ok := r[id]; ok {
+		return recorded, false, nil
+	}
+	r[id] = key
+	return key, true, nil
+}
+
+func (r memoryIdempotencyRepository) Complete(ctx context.Context, key idempotency.Key) error {
+	key.CompletedAt = time.Now()
+	r[key.Identity+key.Method+key.Key] = key
+	return nil
+}
+
+func (r memoryIdempotencyRepository) Release(ctx context.Context, key idempotency.Key) error {
+	delete(r, key.Identity+key.Method+key.Key)
+	return nil
+}
+
+func (r memoryIdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: createProjectMethod}
+	calls := 0
+	create := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return &shieldv1beta1.CreateProjectResponse{Project: &shieldv1beta1.Project{
+			Id:   fmt.Sprintf("project-%d", calls),
+			Name: req.(*shieldv1beta1.CreateProjectRequest).GetBody().GetName(),
+		}}, nil
+	}
+	req := func(name string) *shieldv1beta1.CreateProjectRequest {
+		return &shieldv1beta1.CreateProjectRequest{Body: &shieldv1beta1.ProjectRequestBody{Name: name, OrgId: "org-1"}}
+	}
+	ctxWithKey := func(email, key string) context.Context {
+		ctx := user.SetContextWithEmail(context.Background(), email)
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(grpc_interceptors.IdempotencyKeyHeader, key))
+	}
+	interceptor := grpc_interceptors.Idempotency(idempotency.NewService(memoryIdempotencyRepository{}, idempotency.Config{TTL: time.Hour}))
+
+	t.Run("should replay the response of the first request to the retries with its key", func(t *testing.T) {
+		first, err := interceptor(ctxWithKey("jane@acme.io", "k1"), req("Billing"), info, create)
+		require.NoError(t, err)
+		retry, err := interceptor(ctxWithKey("jane@acme.io", "k1"), req("Billing"), info, create)
+		require.NoError(t, err)
+
+		assert.True(t, proto.Equal(first.(proto.Message), retry.(proto.Message)))
+		assert.Equal(t, "project-1", retry.(*shieldv1beta1.CreateProjectResponse).GetProject().GetId())
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should refuse the key used again for a different request", func(t *testing.T) {
+		_, err := interceptor(ctxWithKey("jane@acme.io", "k1"), req("Payments"), info, create)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("should scope the keys by identity", func(t *testing.T) {
+		resp, err := interceptor(ctxWithKey("john@acme.io", "k1"), req("Billing"), info, create)
+		require.NoError(t, err)
+		assert.Equal(t, "project-2", resp.(*shieldv1beta1.CreateProjectResponse).GetProject().GetId())
+	})
+
+	t.Run("should make the requests without a key or of other methods every time", func(t *testing.T) {
+		ctx := user.SetContextWithEmail(context.Background(), "jane@acme.io")
+		_, err := interceptor(ctx, req("Billing"), info, create)
+		require.NoError(t, err)
+		_, err = interceptor(ctxWithKey("jane@acme.io", "k1"), req("Billing"), &grpc.UnaryServerInfo{FullMethod: "/odpf.shield.v1beta1.ShieldService/UpdateProject"}, create)
+		require.NoError(t, err)
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("should let a failed request be retried with its key", func(t *testing.T) {
+		_, err := interceptor(ctxWithKey("jane@acme.io", "k2"), req("Billing"), info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Unavailable, "spicedb unavailable")
+		})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+
+		resp, err := interceptor(ctxWithKey("jane@acme.io", "k2"), req("Billing"), info, create)
+		require.NoError(t, err)
+		assert.Equal(t, "project-5", resp.(*shieldv1beta1.CreateProjectResponse).GetProject().GetId())
+	})
+}
+
+func TestIdempotencyValidateOnly(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: createProjectMethod}
+	calls := 0
+	create := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return &shieldv1beta1.CreateProjectResponse{Project: &shieldv1beta1.Project{Id: fmt.Sprintf("project-%d", calls)}}, nil
+	}
+	req := &shieldv1beta1.CreateProjectRequest{Body: &shieldv1beta1.ProjectRequestBody{Name: "Billing", OrgId: "org-1"}}
+	ctx := user.SetContextWithEmail(context.Background(), "jane@acme.io")
+	interceptor := grpc_interceptors.Idempotency(idempotency.NewService(memoryIdempotencyRepository{}, idempotency.Config{TTL: time.Hour}))
+
+	_, err := interceptor(metadata.NewIncomingContext(ctx, metadata.Pairs(grpc_interceptors.IdempotencyKeyHeader, "k1", v1beta1.ValidateOnlyHeader, "true")), req, info, create)
+	require.NoError(t, err)
+
+	resp, err := interceptor(metadata.NewIncomingContext(ctx, metadata.Pairs(grpc_interceptors.IdempotencyKeyHeader, "k1")), req, info, create)
+	require.NoError(t, err)
+	assert.Equal(t, "project-2", resp.(*shieldv1beta1.CreateProjectResponse).GetProject().GetId())
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyOptionHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		req    proto.Message
+		resp   proto.Message
+		header string
+		first  string
+		retry  string
+	}{
+		{
+			name:   "should refuse the key used again for a policy of another effect",
+			method: "/odpf.shield.v1beta1.ShieldService/CreatePolicy",
+			req:    &shieldv1beta1.CreatePolicyRequest{Body: &shieldv1beta1.PolicyRequestBody{RoleId: "viewer", NamespaceId: "shield/project"}},
+			resp:   &shieldv1beta1.CreatePolicyResponse{},
+			header: v1beta1.PolicyEffectHeader,
+			first:  "allow",
+			retry:  "deny",
+		},
+		{
+			name:   "should refuse the key used again for a policy of another condition",
+			method: "/odpf.shield.v1beta1.ShieldService/CreatePolicy",
+			req:    &shieldv1beta1.CreatePolicyRequest{Body: &shieldv1beta1.PolicyRequestBody{RoleId: "viewer", NamespaceId: "shield/project"}},
+			resp:   &shieldv1beta1.CreatePolicyResponse{},
+			header: v1beta1.PolicyConditionHeader,
+			first:  `request.ip == "10.0.0.1"`,
+			retry:  `request.ip == "10.0.0.2"`,
+		},
+		{
+			name:   "should refuse the key used again for a policy of another name",
+			method: "/odpf.shield.v1beta1.ShieldService/CreatePolicy",
+			req:    &shieldv1beta1.CreatePolicyRequest{Body: &shieldv1beta1.PolicyRequestBody{RoleId: "viewer", NamespaceId: "shield/project"}},
+			resp:   &shieldv1beta1.CreatePolicyResponse{},
+			header: v1beta1.PolicyNameHeader,
+			first:  "viewers",
+			retry:  "readers",
+		},
+		{
+			name:   "should refuse the key used again for a resource of other tags",
+			method: "/odpf.shield.v1beta1.ShieldService/CreateResource",
+			req:    &shieldv1beta1.CreateResourceRequest{Body: &shieldv1beta1.ResourceRequestBody{Name: "dashboard"}},
+			resp:   &shieldv1beta1.CreateResourceResponse{},
+			header: v1beta1.ResourceTagsHeader,
+			first:  "env=staging",
+			retry:  "env=production",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+			calls := 0
+			create := func(ctx context.Context, req interface{}) (interface{}, error) {
+				calls++
+				return tt.resp, nil
+			}
+			ctx := user.SetContextWithEmail(context.Background(), "jane@acme.io")
+			interceptor := grpc_interceptors.Idempotency(idempotency.NewService(memoryIdempotencyRepository{}, idempotency.Config{TTL: time.Hour}))
+
+			_, err := interceptor(metadata.NewIncomingContext(ctx, metadata.Pairs(grpc_interceptors.IdempotencyKeyHeader, "k1", tt.header, tt.first)), tt.req, info, create)
+			require.NoError(t, err)
+
+			_, err = interceptor(metadata.NewIncomingContext(ctx, metadata.Pairs(grpc_interceptors.IdempotencyKeyHeader, "k1", tt.header, tt.retry)), tt.req, info, create)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+			_, err = interceptor(metadata.NewIncomingContext(ctx, metadata.Pairs(grpc_interceptors.IdempotencyKeyHeader, "k1", tt.header, tt.first)), tt.req, info, create)
+			require.NoError(t, err)
+			assert.Equal(t, 1, calls)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package grpc_interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/odpf/shield/core/user"
+)
+
+// verbosities of the request logs, a basic log has the method, the actor,
+// the organization, the latency and the status of the request while a full
+// log also has its payload
+const (
+	VerbosityOff   = "off"
+	VerbosityBasic = "basic"
+	VerbosityFull  = "full"
+)
+
+const redacted = "[REDACTED]"
+
+type RequestLogConfig struct {
+	// Verbosity of the logs of the requests, off, basic or full
+	Verbosity string `yaml:"verbosity" mapstructure:"verbosity" default:"basic"`
+	// Methods overrides the verbosity of the requests to a method, keyed by
+	// the full grpc method e.g. /odpf.shield.v1beta1.ShieldService/CheckResourcePermission
+	Methods map[string]string `yaml:"methods" mapstructure:"methods"`
+	// LogEmails logs the emails of the actors and in the payloads as they
+	// are, they are masked otherwise
+	LogEmails bool `yaml:"log_emails" mapstructure:"log_emails"`
+	// RedactKeys are the fields of the payloads whose values are not logged,
+	// e.g. keys of the metadata of the users, matched case insensitively
+	RedactKeys []string `yaml:"redact_keys" mapstructure:"redact_keys"`
+}
+
+func (c RequestLogConfig) verbosityOf(method string) string {
+	if v, ok := c.Methods[method]; ok {
+		return v
+	}
+	return c.Verbosity
+}
+
+// LogRequests logs a structured entry for every request once handled, the
+// requests failing with a server error are logged as errors. It must run
+// after the identity of the request is set to log its actor. The handlers
+// get the logger of the request from the context with ctxzap.
+func LogRequests(logger *zap.Logger, cfg RequestLogConfig) grpc.UnaryServerInterceptor {
+	redactKeys := make(map[string]bool, len(cfg.RedactKeys))
+	for _, k := range cfg.RedactKeys {
+		redactKeys[strings.ToLower(k)] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = ctxzap.ToContext(ctx, logger.With(zap.String("grpc.method", info.FullMethod)))
+
+		verbosity := cfg.verbosityOf(info.FullMethod)
+		if verbosity == VerbosityOff {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		fields := []zap.Field{
+			zap.String("grpc.method", info.FullMethod),
+			zap.String("grpc.code", code.String()),
+			zap.Duration("grpc.latency", time.Since(start)),
+		}
+		if email, ok := user.GetEmailFromContext(ctx); ok && email != "" {
+			if !cfg.LogEmails {
+				email = maskEmail(email)
+			}
+			fields = append(fields, zap.String("actor", email))
+		}
+		if orgID := orgIDOf(req); orgID != "" {
+			fields = append(fields, zap.String("org_id", orgID))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+		}
+		if verbosity == VerbosityFull {
+			if payload, ok := redactedPayload(req, redactKeys, !cfg.LogEmails); ok {
+				fields = append(fields, zap.Any("grpc.request", payload))
+			}
+		}
+		if err != nil {
+			fields = append(fields, zap.String("error", status.Convert(err).Message()))
+		}
+
+		logger.Check(levelOf(code), "handled request").Write(fields...)
+		return resp, err
+	}
+}
+
+// levelOf logs the errors of the server as errors, the errors of the
+// clients are only warnings
+func levelOf(code codes.Code) zapcore.Level {
+	switch code {
+	case codes.OK:
+		return zapcore.InfoLevel
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented, codes.DeadlineExceeded:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.WarnLevel
+	}
+}
+
+// orgIDOf is the organization the request is made in, read from the org_id
+// field of the request or of its body
+func orgIDOf(req interface{}) string {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return ""
+	}
+	m := msg.ProtoReflect()
+	if orgID := stringField(m, "org_id"); orgID != "" {
+		return orgID
+	}
+	body := m.Descriptor().Fields().ByName("body")
+	if body == nil || body.Kind() != protoreflect.MessageKind || !m.Has(body) {
+		return ""
+	}
+	return stringField(m.Get(body).Message(), "org_id")
+}
+
+func stringField(m protoreflect.Message, name protoreflect.Name) string {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+		return ""
+	}
+	return m.Get(fd).String()
+}
+
+// redactedPayload is the request as json values, with the values of the
+// redacted keys removed and the emails masked if maskEmails is set
+func redactedPayload(req interface{}, redactKeys map[string]bool, maskEmails bool) (interface{}, bool) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil, false
+	}
+	raw, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, false
+	}
+	var payload interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, false
+	}
+	return redact(payload, redactKeys, maskEmails), true
+}
+
+func redact(v interface{}, redactKeys map[string]bool, maskEmails bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if redactKeys[strings.ToLower(k)] {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redact(val, redactKeys, maskEmails)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redact(val, redactKeys, maskEmails)
+		}
+		return v
+	case string:
+		if maskEmails && isEmail(v) {
+			return maskEmail(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isEmail(s string) bool {
+	at := strings.LastIndex(s, "@")
+	return at > 0 && at < len(s)-1 && !strings.ContainsAny(s, " \t\n") && strings.Contains(s[at:], ".")
+}
+
+// maskEmail keeps the first character of the local part and the domain of
+// an email so the logs can still be told apart
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return redacted
+	}
+	_, size := utf8.DecodeRuneInString(email)
+	return email[:size] + "***" + email[at:]
+}
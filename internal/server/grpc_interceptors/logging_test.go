@@ -0,0 +1,97 @@
+package grpc_interceptors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/server/grpc_interceptors"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+)
+
+const createProjectMethod = "/odpf.shield.v1beta1.ShieldService/CreateProject"
+
+func TestLogRequests(t *testing.T) {
+	metadata, err := structpb.NewStruct(map[string]interface{}{"phone": "+62 812", "contact": "owner@odpf.io"})
+	require.NoError(t, err)
+	req := &shieldv1beta1.CreateProjectRequest{Body: &shieldv1beta1.ProjectRequestBody{
+		Name:     "Odpf",
+		OrgId:    "9f256f86-31a3-11ec-8d3d-0242ac130003",
+		Metadata: metadata,
+	}}
+	ctx := user.SetContextWithEmail(context.Background(), "jane@odpf.io")
+	info := &grpc.UnaryServerInfo{FullMethod: createProjectMethod}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	t.Run("should log the actor and the organization of the request", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		interceptor := grpc_interceptors.LogRequests(zap.New(core), grpc_interceptors.RequestLogConfig{Verbosity: grpc_interceptors.VerbosityBasic})
+
+		_, err := interceptor(ctx, req, info, ok)
+		require.NoError(t, err)
+		require.Equal(t, 1, logs.Len())
+
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, createProjectMethod, fields["grpc.method"])
+		assert.Equal(t, "OK", fields["grpc.code"])
+		assert.Equal(t, "j***@odpf.io", fields["actor"])
+		assert.Equal(t, "9f256f86-31a3-11ec-8d3d-0242ac130003", fields["org_id"])
+		assert.NotContains(t, fields, "grpc.request")
+	})
+
+	t.Run("should log the payload with the sensitive fields redacted", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		interceptor := grpc_interceptors.LogRequests(zap.New(core), grpc_interceptors.RequestLogConfig{
+			Verbosity:  grpc_interceptors.VerbosityBasic,
+			Methods:    map[string]string{createProjectMethod: grpc_interceptors.VerbosityFull},
+			RedactKeys: []string{"Phone"},
+		})
+
+		_, err := interceptor(ctx, req, info, ok)
+		require.NoError(t, err)
+		require.Equal(t, 1, logs.Len())
+
+		payload := logs.All()[0].ContextMap()["grpc.request"].(map[string]interface{})
+		got := payload["body"].(map[string]interface{})["metadata"].(map[string]interface{})
+		assert.Equal(t, "[REDACTED]", got["phone"])
+		assert.Equal(t, "o***@odpf.io", got["contact"])
+	})
+
+	t.Run("should not log the requests to a method turned off", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		interceptor := grpc_interceptors.LogRequests(zap.New(core), grpc_interceptors.RequestLogConfig{
+			Verbosity: grpc_interceptors.VerbosityBasic,
+			Methods:   map[string]string{createProjectMethod: grpc_interceptors.VerbosityOff},
+		})
+
+		_, err := interceptor(ctx, req, info, ok)
+		require.NoError(t, err)
+		assert.Zero(t, logs.Len())
+	})
+
+	t.Run("should log the server errors as errors", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		interceptor := grpc_interceptors.LogRequests(zap.New(core), grpc_interceptors.RequestLogConfig{Verbosity: grpc_interceptors.VerbosityBasic, LogEmails: true})
+
+		_, err := interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Internal, "internal server error")
+		})
+		require.Error(t, err)
+		require.Equal(t, 1, logs.Len())
+
+		entry := logs.All()[0]
+		assert.Equal(t, zapcore.ErrorLevel, entry.Level)
+		assert.Equal(t, "jane@odpf.io", entry.ContextMap()["actor"])
+		assert.Equal(t, "internal server error", entry.ContextMap()["error"])
+	})
+}
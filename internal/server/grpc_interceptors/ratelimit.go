@@ -0,0 +1,120 @@
+package grpc_interceptors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/metrics"
+	"github.com/odpf/shield/internal/ratelimit"
+)
+
+// headers of the state of the bucket limiting the requests, the ones of the
+// ietf draft on rate limit headers, and the time to wait once limited
+const (
+	RateLimitLimitHeader     = "ratelimit-limit"
+	RateLimitRemainingHeader = "ratelimit-remaining"
+	RateLimitResetHeader     = "ratelimit-reset"
+	RetryAfterHeader         = "retry-after"
+)
+
+// RateLimitedReason is the reason of the error info of the requests
+// rejected for being over the limit, the status code is resource exhausted
+const RateLimitedReason = "RATE_LIMITED"
+
+const errorDomain = "shield.odpf.io"
+
+// RateLimit rejects the requests over the limit of their identity or of
+// their organization, the requests are let through if the buckets can't
+// be read. It must run after the identity of the request is set.
+func RateLimit(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !limiter.Enabled() {
+			return handler(ctx, req)
+		}
+
+		res, err := limiter.Allow(ctx, RequestIdentity(ctx), orgIDOf(req))
+		if err != nil {
+			ctxzap.Extract(ctx).Warn("rate limit not applied", zap.Error(err))
+			return handler(ctx, req)
+		}
+		if res.Limit > 0 {
+			grpc.SetHeader(ctx, metadata.New(RateLimitHeaders(res)))
+		}
+		if !res.Allowed {
+			metrics.RateLimited(res.Scope)
+			return nil, RateLimitedError(res)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RequestIdentity is the identity the requests are limited by, the email of
+// the user or else the address of the client
+func RequestIdentity(ctx context.Context) string {
+	if email, ok := user.GetEmailFromContext(ctx); ok && email != "" {
+		return email
+	}
+	// requests through the gateway come from the gateway itself
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-forwarded-for"); len(values) > 0 {
+			addr, _, _ := strings.Cut(values[0], ",")
+			return "addr:" + strings.TrimSpace(addr)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "addr:" + host
+	}
+	return ""
+}
+
+// RateLimitHeaders are the headers of the state of the bucket, the times
+// are in seconds rounded up
+func RateLimitHeaders(res ratelimit.Result) map[string]string {
+	headers := map[string]string{
+		RateLimitLimitHeader:     strconv.Itoa(res.Limit),
+		RateLimitRemainingHeader: strconv.Itoa(res.Remaining),
+		RateLimitResetHeader:     strconv.Itoa(ceilSeconds(res.Reset)),
+	}
+	if !res.Allowed {
+		headers[RetryAfterHeader] = strconv.Itoa(ceilSeconds(res.RetryAfter))
+	}
+	return headers
+}
+
+// RateLimitedError is the error of a request over the limit, with the
+// RATE_LIMITED reason and the time to retry in as details
+func RateLimitedError(res ratelimit.Result) error {
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("rate limit of the %s exceeded, retry in %ds", res.Scope, ceilSeconds(res.RetryAfter)))
+	detailed, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: RateLimitedReason, Domain: errorDomain, Metadata: map[string]string{"scope": res.Scope}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(res.RetryAfter)},
+	)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
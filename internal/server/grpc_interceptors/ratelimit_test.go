@@ -0,0 +1,79 @@
+package grpc_interceptors_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/ratelimit"
+	"github.com/odpf/shield/internal/server/grpc_interceptors"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+)
+
+func TestRateLimit(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: createProjectMethod}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	req := func(orgID string) *shieldv1beta1.CreateProjectRequest {
+		return &shieldv1beta1.CreateProjectRequest{Body: &shieldv1beta1.ProjectRequestBody{Name: "Odpf", OrgId: orgID}}
+	}
+
+	t.Run("should let the requests through without limits", func(t *testing.T) {
+		interceptor := grpc_interceptors.RateLimit(ratelimit.New(ratelimit.Config{}))
+		resp, err := interceptor(context.Background(), req(""), info, ok)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+
+	t.Run("should reject the requests over the limit of the organization", func(t *testing.T) {
+		interceptor := grpc_interceptors.RateLimit(ratelimit.New(ratelimit.Config{
+			Organization: ratelimit.Limit{Rate: 1},
+		}))
+
+		jane := user.SetContextWithEmail(context.Background(), "jane@odpf.io")
+		john := user.SetContextWithEmail(context.Background(), "john@odpf.io")
+		_, err := interceptor(jane, req("odpf"), info, ok)
+		require.NoError(t, err)
+
+		// requests in another organization are not limited by it
+		_, err = interceptor(john, req("gojek"), info, ok)
+		require.NoError(t, err)
+
+		_, err = interceptor(john, req("odpf"), info, ok)
+		st := status.Convert(err)
+		assert.Equal(t, codes.ResourceExhausted, st.Code())
+		require.Len(t, st.Details(), 2)
+		errInfo, isErrInfo := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, isErrInfo)
+		assert.Equal(t, grpc_interceptors.RateLimitedReason, errInfo.GetReason())
+		assert.Equal(t, ratelimit.ScopeOrganization, errInfo.GetMetadata()["scope"])
+	})
+}
+
+func TestRequestIdentity(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", "10.0.0.1, 10.0.0.2"))
+	assert.Equal(t, "addr:10.0.0.1", grpc_interceptors.RequestIdentity(ctx))
+
+	ctx = user.SetContextWithEmail(ctx, "jane@odpf.io")
+	assert.Equal(t, "jane@odpf.io", grpc_interceptors.RequestIdentity(ctx))
+
+	assert.Equal(t, "", grpc_interceptors.RequestIdentity(context.Background()))
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	headers := grpc_interceptors.RateLimitHeaders(ratelimit.Result{Limit: 10, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 100 * time.Millisecond})
+	assert.Equal(t, map[string]string{
+		"ratelimit-limit":     "10",
+		"ratelimit-remaining": "0",
+		"ratelimit-reset":     "2",
+		"retry-after":         "1",
+	}, headers)
+}
@@ -0,0 +1,23 @@
+package grpc_interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+type UsageCounter interface {
+	CountAPICall(orgID string)
+}
+
+// CountUsage counts the requests made in an organization, the organization
+// of a request is the org_id of its payload. The requests rejected before
+// it, like the ones over the rate limit, are not counted.
+func CountUsage(counter UsageCounter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if orgID := orgIDOf(req); orgID != "" {
+			counter.CountAPICall(orgID)
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/invitation"
+	"github.com/odpf/shield/core/organization"
+)
+
+// the invitations have no rpcs, they are created and revoked next to the
+// gateway api by the admins of the organization and accepted by the invited
+// user themselves
+const (
+	invitationsPath       = "/admin/v1beta1/invitations"
+	invitationsAcceptPath = "/admin/v1beta1/invitations/accept"
+)
+
+type invitationResponse struct {
+	ID         string     `json:"id"`
+	OrgID      string     `json:"org_id"`
+	Email      string     `json:"email"`
+	GroupIDs   []string   `json:"group_ids"`
+	InvitedBy  string     `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func newInvitationResponse(inv invitation.Invitation) invitationResponse {
+	resp := invitationResponse{
+		ID:        inv.ID,
+		OrgID:     inv.OrgID,
+		Email:     inv.Email,
+		GroupIDs:  inv.GroupIDs,
+		InvitedBy: inv.InvitedBy,
+		ExpiresAt: inv.ExpiresAt,
+	}
+	if inv.IsAccepted() {
+		resp.AcceptedAt = &inv.AcceptedAt
+	}
+	if inv.IsRevoked() {
+		resp.RevokedAt = &inv.RevokedAt
+	}
+	return resp
+}
+
+// invitationsHandler invites the email query parameter to the organization
+// of the org query parameter with POST, the user joins the groups of the
+// repeated group query parameter once they accept it. The invitation can be
+// accepted until the expiry query parameter, a duration, has passed. DELETE
+// revokes the pending invitation of the id query parameter. The current user
+// has to be an admin of the organization.
+func invitationsHandler(invitationService *invitation.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.Method {
+		case http.MethodPost:
+			inv := invitation.Invitation{OrgID: query.Get("org"), Email: query.Get("email"), GroupIDs: query["group"]}
+			if inv.OrgID == "" || inv.Email == "" {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "org and email are required"})
+				return
+			}
+			if expiry := query.Get("expiry"); expiry != "" {
+				d, err := time.ParseDuration(expiry)
+				if err != nil {
+					writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "expiry should be a duration like 72h"})
+					return
+				}
+				inv.ExpiresAt = time.Now().Add(d)
+			}
+
+			created, err := invitationService.Create(r.Context(), inv)
+			if err != nil {
+				writeInvitationError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, newInvitationResponse(created))
+		case http.MethodDelete:
+			id := query.Get("id")
+			if id == "" {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+				return
+			}
+
+			revoked, err := invitationService.Revoke(r.Context(), id)
+			if err != nil {
+				writeInvitationError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, newInvitationResponse(revoked))
+		default:
+			w.Header().Set("Allow", "POST, DELETE")
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+		}
+	})
+}
+
+// invitationAcceptHandler accepts the invitation of the id query parameter
+// as the current user, who joins the organization and the groups of the
+// invitation. The invitation has to be for the email of the current user.
+func invitationAcceptHandler(invitationService *invitation.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+			return
+		}
+
+		accepted, err := invitationService.Accept(r.Context(), id)
+		if err != nil {
+			writeInvitationError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newInvitationResponse(accepted))
+	})
+}
+
+func writeInvitationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, invitation.ErrNotExist),
+		errors.Is(err, organization.ErrNotExist),
+		errors.Is(err, organization.ErrInvalidID),
+		errors.Is(err, organization.ErrInvalidUUID),
+		errors.Is(err, group.ErrNotExist),
+		errors.Is(err, group.ErrInvalidID),
+		errors.Is(err, group.ErrInvalidUUID):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+	case errors.Is(err, invitation.ErrNotPending):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "conflict", ErrorDescription: err.Error()})
+	case errors.Is(err, invitation.ErrInvalidInvitee),
+		errors.Is(err, invitation.ErrNotOrgAdmin):
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden", ErrorDescription: err.Error()})
+	case errors.Is(err, invitation.ErrInvalidDetail),
+		errors.Is(err, invitation.ErrGroupOutsideOrg):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+	default:
+		writeAccessError(w, err)
+	}
+}
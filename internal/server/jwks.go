@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/odpf/shield/core/signingkey"
+)
+
+// the public keys of the tokens shield signs, served where the verifiers
+// look for them by convention
+const jwksPath = "/.well-known/jwks.json"
+
+// jwksHandler serves the public keys the tokens signed by shield are
+// verified with, the verifiers can cache them for a few minutes as the keys
+// are published before they sign
+func jwksHandler(keys *signingkey.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		if keys == nil {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: "tokens are not signed"})
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		writeJSON(w, http.StatusOK, keys.JWKS())
+	})
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/user"
+)
+
+// the owners of a group are listed, added and removed next to the gateway
+// api, the group admin rpcs manage its managers
+const groupOwnersPath = "/admin/v1beta1/groups/owners"
+
+type groupOwnerResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// groupOwnersHandler lists the owners of the group of the id query
+// parameter, an id or a slug, adds the users of the user query parameters
+// with POST and removes the user of the user query parameter with DELETE.
+// Adding and removing needs the delete permission on the group.
+func groupOwnersHandler(userService *user.Service, groupService *group.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := userService.FetchCurrentUser(r.Context()); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		query := r.URL.Query()
+		idOrSlug := query.Get("id")
+		if idOrSlug == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+			return
+		}
+
+		var owners []user.User
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			owners, err = groupService.ListOwners(r.Context(), idOrSlug)
+		case http.MethodPost:
+			if len(query["user"]) == 0 {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "user is required"})
+				return
+			}
+			owners, err = groupService.AddOwners(r.Context(), idOrSlug, query["user"])
+		case http.MethodDelete:
+			userID := query.Get("user")
+			if userID == "" {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "user is required"})
+				return
+			}
+			owners, err = groupService.RemoveOwner(r.Context(), idOrSlug, userID)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, group.ErrNotExist),
+				errors.Is(err, group.ErrInvalidUUID),
+				errors.Is(err, group.ErrInvalidID),
+				errors.Is(err, user.ErrNotExist),
+				errors.Is(err, user.ErrInvalidUUID),
+				errors.Is(err, user.ErrInvalidID):
+				writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+			default:
+				writeAccessError(w, err)
+			}
+			return
+		}
+
+		resp := struct {
+			Owners []groupOwnerResponse `json:"owners"`
+		}{Owners: []groupOwnerResponse{}}
+		for _, o := range owners {
+			resp.Owners = append(resp.Owners, groupOwnerResponse{ID: o.ID, Name: o.Name, Email: o.Email})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
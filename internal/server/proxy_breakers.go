@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/proxy"
+)
+
+// the circuit breakers of the backends of the proxies are served next to
+// the rules of the proxies
+const proxyBreakersPath = "/admin/v1beta1/proxy/breakers"
+
+type proxyBreakersResponse struct {
+	Name     string                   `json:"name"`
+	Backends []backendBreakerResponse `json:"backends"`
+}
+
+type backendBreakerResponse struct {
+	Backend  string     `json:"backend"`
+	State    string     `json:"state"`
+	Failures int        `json:"failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// proxyBreakersHandler returns the state of the circuit breakers of the
+// backends the proxies sent requests to, or of the proxy of the proxy query
+// parameter
+func proxyBreakersHandler(userService *user.Service, breakers map[string]*proxy.Breakers) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		if _, err := userService.FetchCurrentUser(r.Context()); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		names, ok := proxyNames(breakers, r.URL.Query().Get("proxy"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: "proxy doesn't exist"})
+			return
+		}
+
+		resp := struct {
+			Proxies []proxyBreakersResponse `json:"proxies"`
+		}{Proxies: []proxyBreakersResponse{}}
+		for _, name := range names {
+			proxyResp := proxyBreakersResponse{Name: name, Backends: []backendBreakerResponse{}}
+			for _, status := range breakers[name].Status() {
+				backendResp := backendBreakerResponse{
+					Backend:  status.Backend,
+					State:    status.State,
+					Failures: status.Failures,
+				}
+				if !status.OpenedAt.IsZero() {
+					openedAt := status.OpenedAt
+					backendResp.OpenedAt = &openedAt
+				}
+				proxyResp.Backends = append(proxyResp.Backends, backendResp)
+			}
+			resp.Proxies = append(resp.Proxies, proxyResp)
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
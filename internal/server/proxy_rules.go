@@ -0,0 +1,137 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/core/user"
+)
+
+// the rules of the proxies are served next to the gateway api, the status of
+// the rules and reloading them have no rpcs of their own
+const (
+	proxyRulesPath       = "/admin/v1beta1/proxy/rules"
+	proxyRulesReloadPath = "/admin/v1beta1/proxy/rules/reload"
+)
+
+type proxyRulesResponse struct {
+	Name     string     `json:"name"`
+	Version  int64      `json:"version"`
+	Checksum string     `json:"checksum"`
+	LoadedAt time.Time  `json:"loaded_at"`
+	Rulesets int        `json:"rulesets"`
+	Rules    int        `json:"rules"`
+	Error    string     `json:"error,omitempty"`
+	FailedAt *time.Time `json:"failed_at,omitempty"`
+}
+
+func newProxyRulesResponse(name string, status rule.Status) proxyRulesResponse {
+	resp := proxyRulesResponse{
+		Name:     name,
+		Version:  status.Version,
+		Checksum: status.Checksum,
+		LoadedAt: status.LoadedAt,
+		Rulesets: status.Rulesets,
+		Rules:    status.Rules,
+		Error:    status.Error,
+	}
+	if !status.FailedAt.IsZero() {
+		resp.FailedAt = &status.FailedAt
+	}
+	return resp
+}
+
+// proxyRulesHandler returns the version and the checksum of the rules loaded
+// by the proxies, or by the proxy of the proxy query parameter
+func proxyRulesHandler(userService *user.Service, ruleServices map[string]*rule.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		if _, err := userService.FetchCurrentUser(r.Context()); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		names, ok := proxyNames(ruleServices, r.URL.Query().Get("proxy"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: "proxy doesn't exist"})
+			return
+		}
+
+		resp := struct {
+			Proxies []proxyRulesResponse `json:"proxies"`
+		}{Proxies: []proxyRulesResponse{}}
+		for _, name := range names {
+			resp.Proxies = append(resp.Proxies, newProxyRulesResponse(name, ruleServices[name].Status(r.Context())))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// proxyRulesReloadHandler loads the rule files of the proxies, or of the
+// proxy of the proxy query parameter, without waiting for their refresh.
+// Rule files failing validation are rejected and the proxy keeps serving
+// with the rules it had.
+func proxyRulesReloadHandler(userService *user.Service, ruleServices map[string]*rule.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		if _, err := userService.FetchCurrentUser(r.Context()); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		names, ok := proxyNames(ruleServices, r.URL.Query().Get("proxy"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: "proxy doesn't exist"})
+			return
+		}
+
+		resp := struct {
+			Proxies []proxyRulesResponse `json:"proxies"`
+		}{Proxies: []proxyRulesResponse{}}
+		var invalid []string
+		for _, name := range names {
+			status, err := ruleServices[name].Reload(r.Context())
+			if err != nil {
+				if !errors.Is(err, rule.ErrInvalidRuleset) {
+					writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "server_error", ErrorDescription: fmt.Sprintf("%s: failed to load the rule files", name)})
+					return
+				}
+				invalid = append(invalid, fmt.Sprintf("%s: %s", name, err))
+			}
+			resp.Proxies = append(resp.Proxies, newProxyRulesResponse(name, status))
+		}
+		if len(invalid) > 0 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_ruleset", ErrorDescription: strings.Join(invalid, "; ")})
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// proxyNames are the names of the proxies in order, or the name given when
+// it is one of them
+func proxyNames[V any](proxies map[string]V, name string) ([]string, bool) {
+	if name != "" {
+		_, ok := proxies[name]
+		return []string{name}, ok
+	}
+	names := make([]string, 0, len(proxies))
+	for name := range proxies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, true
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/metrics"
+	"github.com/odpf/shield/internal/ratelimit"
+	"github.com/odpf/shield/internal/server/grpc_interceptors"
+)
+
+// rateLimitHandler limits the requests to the endpoints served next to the
+// rpcs the way the grpc interceptor does, by identity only since they are
+// not made in an organization. It must run after identityHandler.
+func rateLimitHandler(limiter *ratelimit.Limiter, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.Enabled() {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		res, err := limiter.Allow(r.Context(), httpIdentity(r), "")
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if res.Limit > 0 {
+			for k, v := range grpc_interceptors.RateLimitHeaders(res) {
+				w.Header().Set(k, v)
+			}
+		}
+		if !res.Allowed {
+			metrics.RateLimited(res.Scope)
+			writeJSON(w, http.StatusTooManyRequests, errorResponse{
+				Error:            "rate_limited",
+				ErrorDescription: fmt.Sprintf("rate limit of the %s exceeded", res.Scope),
+			})
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// httpIdentity is the identity of the request the way
+// grpc_interceptors.RequestIdentity finds it
+func httpIdentity(r *http.Request) string {
+	if email, ok := user.GetEmailFromContext(r.Context()); ok && email != "" {
+		return email
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		addr, _, _ := strings.Cut(forwarded, ",")
+		return "addr:" + strings.TrimSpace(addr)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/api"
+	"github.com/odpf/shield/internal/schema"
+)
+
+// the v1beta1 api has no restore rpcs, the organizations and projects
+// deleted are restored through the restore paths until they are purged
+const (
+	organizationsRestorePath = "/admin/v1beta1/organizations/restore"
+	projectsRestorePath      = "/admin/v1beta1/projects/restore"
+)
+
+type restoreResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// restoreHandlers are the handlers of the restore paths, the users who
+// could delete the organizations and the projects restore them
+func restoreHandlers(deps api.Deps) map[string]http.Handler {
+	return map[string]http.Handler{
+		organizationsRestorePath: restoreHandler(deps.UserService, func(ctx context.Context, usr user.User, idOrSlug string) (restoreResponse, error) {
+			org, err := deps.OrgService.GetDeleted(ctx, idOrSlug)
+			if err != nil {
+				return restoreResponse{}, err
+			}
+			if err := permitted(ctx, deps.RelationService, usr, schema.OrganizationNamespace, org.ID, schema.EditPermission); err != nil {
+				return restoreResponse{}, err
+			}
+			restored, err := deps.OrgService.Restore(ctx, org.ID)
+			return restoreResponse{ID: restored.ID, Name: restored.Name, Slug: restored.Slug}, err
+		}),
+		projectsRestorePath: restoreHandler(deps.UserService, func(ctx context.Context, usr user.User, idOrSlug string) (restoreResponse, error) {
+			prj, err := deps.ProjectService.GetDeleted(ctx, idOrSlug)
+			if err != nil {
+				return restoreResponse{}, err
+			}
+			if err := permitted(ctx, deps.RelationService, usr, schema.ProjectNamespace, prj.ID, schema.DeletePermission); err != nil {
+				return restoreResponse{}, err
+			}
+			restored, err := deps.ProjectService.Restore(ctx, prj.ID)
+			return restoreResponse{ID: restored.ID, Name: restored.Name, Slug: restored.Slug}, err
+		}),
+	}
+}
+
+// restoreHandler restores the object of the id query parameter as the
+// current user
+func restoreHandler(userService *user.Service, restore func(ctx context.Context, usr user.User, id string) (restoreResponse, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		currentUser, err := userService.FetchCurrentUser(r.Context())
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+			return
+		}
+
+		restored, err := restore(r.Context(), currentUser, id)
+		if err != nil {
+			writeRestoreError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, restored)
+	})
+}
+
+func writeRestoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, organization.ErrNotExist), errors.Is(err, organization.ErrInvalidUUID), errors.Is(err, organization.ErrInvalidID),
+		errors.Is(err, project.ErrNotExist), errors.Is(err, project.ErrInvalidUUID), errors.Is(err, project.ErrInvalidID):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+	case errors.Is(err, organization.ErrNotDeleted),
+		errors.Is(err, project.ErrNotDeleted):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "conflict", ErrorDescription: err.Error()})
+	default:
+		writeAccessError(w, err)
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/pkg/metadata"
+)
+
+// the current user gets and updates themself through the GetCurrentUser and
+// UpdateCurrentUser rpcs of /v1beta1/users/self, their groups, organizations
+// and permissions are served next to them as they have no rpcs of their own
+const (
+	selfGroupsPath        = "/admin/v1beta1/users/self/groups"
+	selfOrganizationsPath = "/admin/v1beta1/users/self/organizations"
+	selfPermissionsPath   = "/admin/v1beta1/users/self/permissions"
+)
+
+type groupResponse struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Slug           string            `json:"slug"`
+	OrganizationID string            `json:"org_id"`
+	Metadata       metadata.Metadata `json:"metadata,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+type organizationResponse struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Slug      string            `json:"slug"`
+	ParentID  string            `json:"parent_id,omitempty"`
+	Metadata  metadata.Metadata `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func newGroupResponses(groups []group.Group) []groupResponse {
+	resp := []groupResponse{}
+	for _, g := range groups {
+		resp = append(resp, groupResponse{
+			ID:             g.ID,
+			Name:           g.Name,
+			Slug:           g.Slug,
+			OrganizationID: g.OrganizationID,
+			Metadata:       g.Metadata,
+			CreatedAt:      g.CreatedAt,
+			UpdatedAt:      g.UpdatedAt,
+		})
+	}
+	return resp
+}
+
+func newOrganizationResponses(orgs []organization.Organization) []organizationResponse {
+	resp := []organizationResponse{}
+	for _, o := range orgs {
+		resp = append(resp, organizationResponse{
+			ID:        o.ID,
+			Name:      o.Name,
+			Slug:      o.Slug,
+			ParentID:  o.ParentID,
+			Metadata:  o.Metadata,
+			CreatedAt: o.CreatedAt,
+			UpdatedAt: o.UpdatedAt,
+		})
+	}
+	return resp
+}
+
+// selfGroupsHandler lists the groups the current user has a role on, only
+// the ones of the role query parameter when it is set
+func selfGroupsHandler(userService *user.Service, groupService *group.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		currentUser, err := userService.FetchCurrentUser(r.Context())
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		groups, err := groupService.ListUserGroups(r.Context(), currentUser.ID, r.URL.Query().Get("role"))
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, struct {
+			Groups []groupResponse `json:"groups"`
+		}{Groups: newGroupResponses(groups)})
+	})
+}
+
+// selfOrganizationsHandler lists the organizations the current user has a
+// role on or has a role on one of the groups of
+func selfOrganizationsHandler(userService *user.Service, orgService *organization.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		currentUser, err := userService.FetchCurrentUser(r.Context())
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		orgs, err := orgService.List(r.Context(), organization.Filter{UserID: currentUser.ID})
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, struct {
+			Organizations []organizationResponse `json:"organizations"`
+		}{Organizations: newOrganizationResponses(orgs)})
+	})
+}
+
+// selfPermissionsHandler returns the permissions of its namespace the
+// current user has on an object, referred to the way permission checks
+// refer to it, so front-ends can tell what the user can do with it
+func selfPermissionsHandler(actionService *action.Service, resourceService *resource.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		query := r.URL.Query()
+		ns, objectID := query.Get("namespace"), query.Get("object_id")
+		if ns == "" || objectID == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "namespace and object_id are required"})
+			return
+		}
+
+		actions, err := actionService.List(r.Context())
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+		permissions := namespacePermissions(actions, ns)
+		if len(permissions) == 0 {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: "namespace doesn't exist or has no permissions"})
+			return
+		}
+
+		var checks []resource.Check
+		for _, p := range permissions {
+			checks = append(checks, resource.Check{
+				Resource: resource.Resource{Name: objectID, NamespaceID: ns},
+				Action:   action.Action{ID: p},
+			})
+		}
+		allowed := []string{}
+		for start := 0; start < len(checks); start += resource.MaxBatchChecks {
+			end := start + resource.MaxBatchChecks
+			if end > len(checks) {
+				end = len(checks)
+			}
+			results, err := resourceService.CheckAuthzBatch(r.Context(), checks[start:end])
+			if err != nil {
+				writeAccessError(w, err)
+				return
+			}
+			for i, ok := range results {
+				if ok {
+					allowed = append(allowed, permissions[start+i])
+				}
+			}
+		}
+
+		writeJSON(w, http.StatusOK, struct {
+			Namespace   string   `json:"namespace"`
+			ObjectID    string   `json:"object_id"`
+			Permissions []string `json:"permissions"`
+		}{Namespace: ns, ObjectID: objectID, Permissions: allowed})
+	})
+}
+
+// namespacePermissions returns the sorted permissions of the schema of the
+// namespace, they are declared as the actions with the <permission>.<namespace>
+// ids and named after the permission
+func namespacePermissions(actions []action.Action, ns string) []string {
+	var permissions []string
+	for _, a := range actions {
+		if a.NamespaceID == ns && a.ID == fmt.Sprintf("%s.%s", a.Name, ns) {
+			permissions = append(permissions, a.Name)
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
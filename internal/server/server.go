@@ -2,12 +2,14 @@ package server
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"net/http"
+	"net/textproto"
+	"strings"
 	"time"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -15,26 +17,132 @@ import (
 	"github.com/newrelic/go-agent/_integrations/nrgrpc"
 	"github.com/odpf/salt/log"
 	"github.com/odpf/salt/server"
+	"github.com/odpf/shield/core/apikey"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/core/user"
 	"github.com/odpf/shield/internal/api"
 	"github.com/odpf/shield/internal/api/v1beta1"
+	"github.com/odpf/shield/internal/health"
+	"github.com/odpf/shield/internal/metrics"
+	"github.com/odpf/shield/internal/ratelimit"
 	"github.com/odpf/shield/internal/server/grpc_interceptors"
+	"github.com/odpf/shield/internal/tracing"
+	"github.com/odpf/shield/pkg/tlsutil"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 )
 
-func registerHandler(ctx context.Context, s *server.MuxServer, gw *server.GRPCGateway, deps api.Deps) {
-	s.RegisterHandler("/admin*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+func registerHandler(ctx context.Context, s *server.MuxServer, mux *http.ServeMux, gw *server.GRPCGateway, gwmux *runtime.ServeMux, cfg Config, deps api.Deps, limiter *ratelimit.Limiter) {
+	mux.Handle("/admin*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "pong")
 	}))
 
-	s.RegisterHandler("/admin/ping", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/admin/ping", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "pong")
 	}))
 
-	// grpc gateway api will have version endpoints
-	s.SetGateway("/admin", gw)
+	// probes of kubernetes, the server is ready once its dependencies are
+	mux.Handle("/healthz", health.LivenessHandler())
+	mux.Handle("/readyz", deps.HealthChecker.ReadinessHandler())
+	s.RegisterService(&healthpb.Health_ServiceDesc, health.NewGRPCServer(deps.HealthChecker))
+
+	// sessions started with the id tokens of the oidc providers
+	mux.Handle(tokenPath, tokenHandler(deps.OIDCService, deps.SessionService))
+	mux.Handle(sessionsPath, sessionsHandler(bearerAuthenticator(deps), deps.SessionService))
+	mux.Handle(sessionsPath+"/", sessionsHandler(bearerAuthenticator(deps), deps.SessionService))
+
+	// who has access to a resource and through which relations, the
+	// resources a user has access to and the checks of many permissions
+	mux.Handle(accessExpandPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, accessExpandHandler(deps.ResourceService))))
+	mux.Handle(accessResourcesPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, accessResourcesHandler(deps.ResourceService))))
+	mux.Handle(accessCheckPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, accessCheckHandler(deps.ResourceService))))
+
+	// relations and policies created in batches, for onboarding large
+	// organizations
+	mux.Handle(relationsBatchPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, relationsBatchHandler(deps.ResourceService, deps.RelationService))))
+	mux.Handle(policiesBatchPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, policiesBatchHandler(deps.UserService, deps.PolicyService))))
+
+	// the changes recorded by the instance as they are made
+	mux.Handle(eventsWatchPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, eventsWatchHandler(deps.UserService, deps.EventHub))))
+
+	// the groups, organizations and permissions of the current user
+	mux.Handle(selfGroupsPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, selfGroupsHandler(deps.UserService, deps.GroupService))))
+	mux.Handle(selfOrganizationsPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, selfOrganizationsHandler(deps.UserService, deps.OrgService))))
+	mux.Handle(selfPermissionsPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, selfPermissionsHandler(deps.ActionService, deps.ResourceService))))
+
+	// users disabled and enabled again with their memberships and roles
+	mux.Handle(usersEnablePath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, userEnableHandler(deps.UserService, deps.OrgService, deps.RelationService, cfg.Superusers))))
+	mux.Handle(usersDisablePath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, userDisableHandler(deps.UserService, deps.OrgService, deps.RelationService, cfg.Superusers, deps.SessionService))))
+
+	// the organizations a user is a member of, their groups have an rpc
+	mux.Handle(userOrganizationsPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, userOrganizationsHandler(deps.UserService, deps.OrgService))))
+
+	// the organizations, projects, groups, roles, actions, namespaces and
+	// policies deleted
+	for path, h := range deleteHandlers(deps, cfg.Superusers) {
+		mux.Handle(path, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, h)))
+	}
+
+	// the organizations and projects deleted restored until they are purged
+	for path, h := range restoreHandlers(deps) {
+		mux.Handle(path, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, h)))
+	}
+
+	// the api keys of the users and the service accounts
+	mux.Handle(apiKeysPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, apiKeysHandler(deps.UserService, deps.ServiceUserService, deps.APIKeyService, deps.OrgService, deps.RelationService, cfg.Superusers))))
+
+	// the service accounts and their keys, managed by the admins of the
+	// organizations they are members of
+	mux.Handle(serviceAccountsPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, serviceAccountsHandler(deps.UserService, deps.ServiceUserService, deps.OrgService, deps.RelationService))))
+	mux.Handle(serviceAccountKeysPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, serviceAccountKeysHandler(deps.UserService, deps.ServiceUserService, deps.OrgService, deps.RelationService))))
+
+	// the invitations created and revoked by the admins of the organizations
+	// and accepted by the invited users
+	mux.Handle(invitationsPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, invitationsHandler(deps.InvitationService))))
+	mux.Handle(invitationsAcceptPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, invitationAcceptHandler(deps.InvitationService))))
+
+	// the folders of the projects and the resources moved into them
+	mux.Handle(foldersPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, foldersHandler(deps.FolderService))))
+	mux.Handle(foldersMovePath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, folderMoveHandler(deps.FolderService))))
+
+	// the groups that are members of a group
+	mux.Handle(groupSubgroupsPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, groupSubgroupsHandler(deps.UserService, deps.GroupService))))
+
+	// the owners of a group, its admins are the managers
+	mux.Handle(groupOwnersPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, groupOwnersHandler(deps.UserService, deps.GroupService))))
+
+	// the changes made to the resources, listed by the superusers
+	mux.Handle(auditLogsPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, auditLogsHandler(deps.UserService, deps.AuditService, cfg.Superusers))))
+
+	// the daily usage of an organization, for chargeback
+	mux.Handle(orgUsagePath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, orgUsageHandler(deps.UsageService))))
+
+	// the rules the proxies serve with, reloaded without a restart, and the
+	// circuit breakers of their backends
+	mux.Handle(proxyRulesPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, proxyRulesHandler(deps.UserService, deps.ProxyRuleServices))))
+	mux.Handle(proxyRulesReloadPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, proxyRulesReloadHandler(deps.UserService, deps.ProxyRuleServices))))
+	mux.Handle(proxyBreakersPath, identityHandler(cfg, bearerAuthenticator(deps), rateLimitHandler(limiter, proxyBreakersHandler(deps.UserService, deps.ProxyBreakers))))
+
+	// the version of the server and the filters of the list requests it
+	// applies, for the clients to push their filters down
+	mux.Handle(capabilitiesPath, capabilitiesHandler(cfg.Version))
+
+	// public keys of the tokens the proxies mint for their backends
+	mux.Handle(jwksPath, jwksHandler(deps.SigningKeyService))
+
+	// runtime and check cache metrics
+	mux.Handle("/admin/debug/vars", expvar.Handler())
+
+	// metrics of the api, the proxies and the stores in the prometheus format
+	mux.Handle(cfg.MetricsPath, metrics.Handler())
+
+	// grpc gateway api will have version endpoints, served as the gateway
+	// would set itself up but through the tracing and the metrics of the
+	// http requests
+	mux.Handle("/admin/", http.StripPrefix("/admin", tracing.HTTPMiddleware(metrics.HTTPMiddleware(metrics.ServerAPI, gwmux))))
 	v1beta1.Register(ctx, s, gw, deps)
 }
 
@@ -45,29 +153,95 @@ func Serve(
 	nrApp newrelic.Application,
 	deps api.Deps,
 ) (*server.MuxServer, error) {
+	// the connections to redis are closed once the server is shut down
+	limiter := ratelimit.New(cfg.RateLimit)
+	go func() {
+		<-ctx.Done()
+		limiter.Close()
+	}()
+
+	grpcServer := grpc.NewServer(getGRPCMiddleware(cfg, logger, nrApp, bearerAuthenticator(deps), limiter, deps))
 	s, err := server.NewMux(server.Config{
 		Port: cfg.Port,
-	}, server.WithMuxGRPCServerOptions(getGRPCMiddleware(cfg, logger, nrApp)))
+	}, server.WithMuxGRPCServer(grpcServer))
 	if err != nil {
 		return nil, err
 	}
 
-	gw, err := server.NewGateway("", cfg.Port, server.WithGatewayMuxOptions(
-		runtime.WithIncomingHeaderMatcher(customHeaderMatcherFunc(map[string]bool{cfg.IdentityProxyHeader: true}))),
+	gwmux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(customHeaderMatcherFunc(map[string]bool{
+			cfg.IdentityProxyHeader: true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.PolicyEffectHeader):             true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.PolicyConditionHeader):          true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.PolicyNameHeader):               true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.PolicyDescriptionHeader):        true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.ConsistencyHeader):              true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.CheckContextHeader):             true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.ResourceTagsHeader):             true,
+			textproto.CanonicalMIMEHeaderKey(grpc_interceptors.IdempotencyKeyHeader): true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.IfMatchHeader):                  true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.UserStateHeader):                true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.PageSizeHeader):                 true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.PageNumHeader):                  true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.ListFilterHeader):               true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.IncludeDeletedHeader):           true,
+			textproto.CanonicalMIMEHeaderKey(v1beta1.ValidateOnlyHeader):             true,
+		})),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcherFunc(map[string]bool{
+			grpc_interceptors.RateLimitLimitHeader:     true,
+			grpc_interceptors.RateLimitRemainingHeader: true,
+			grpc_interceptors.RateLimitResetHeader:     true,
+			grpc_interceptors.RetryAfterHeader:         true,
+			grpc_interceptors.IdempotentReplayedHeader: true,
+			v1beta1.ETagHeader:                         true,
+			v1beta1.ValidatedHeader:                    true,
+		})),
+		runtime.WithMetadata(tracing.GatewayMetadata),
 	)
+	gw, err := server.NewGateway("", cfg.Port, server.WithGRPCGateway(gwmux))
 	if err != nil {
 		return nil, err
 	}
 
-	registerHandler(ctx, s, gw, deps)
+	// the http handlers are served on the plaintext port along with the
+	// grpc services, and on the tls port when tls is terminated
+	mux := http.NewServeMux()
+	registerHandler(ctx, s, mux, gw, gwmux, cfg, deps, limiter)
+	s.RegisterHandler("/", mux)
+
+	if cfg.TLS.Enabled() {
+		shutdownTLS, err := tlsutil.Serve(logger, cfg.Host, cfg.TLS, grpcHandler(grpcServer, mux))
+		if err != nil {
+			return nil, fmt.Errorf("failed to serve tls: %w", err)
+		}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second*10)
+			defer shutdownCancel()
+			shutdownTLS(shutdownCtx)
+		}()
+	}
 
 	go s.Serve()
 
-	logger.Info("[shield] api is up", "port", cfg.Port)
+	logger.Info("[shield] api is up", "port", cfg.Port, "tls_port", cfg.TLS.Port)
 
 	return s, nil
 }
 
+// grpcHandler sends the grpc requests to the grpc server and the others to
+// the http handler, the grpc requests come over http/2 with a grpc content
+// type
+func grpcHandler(grpcServer *grpc.Server, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
 func Cleanup(ctx context.Context, s *server.MuxServer) {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer shutdownCancel()
@@ -76,7 +250,7 @@ func Cleanup(ctx context.Context, s *server.MuxServer) {
 }
 
 // REVISIT: passing config.Shield as reference
-func getGRPCMiddleware(cfg Config, logger log.Logger, nrApp newrelic.Application) grpc.ServerOption {
+func getGRPCMiddleware(cfg Config, logger log.Logger, nrApp newrelic.Application, authenticator grpc_interceptors.Authenticator, limiter *ratelimit.Limiter, deps api.Deps) grpc.ServerOption {
 	recoveryFunc := func(p interface{}) (err error) {
 		fmt.Println("-----------------------------")
 		return status.Errorf(codes.Internal, "internal server error")
@@ -91,14 +265,60 @@ func getGRPCMiddleware(cfg Config, logger log.Logger, nrApp newrelic.Application
 	if ok {
 		grpcZapLogger = loggerZap.GetInternalZapLogger()
 	}
-	return grpc.UnaryInterceptor(
-		grpc_middleware.ChainUnaryServer(
-			grpc_interceptors.EnrichCtxWithIdentity(cfg.IdentityProxyHeader),
-			grpc_zap.UnaryServerInterceptor(grpcZapLogger.Desugar()),
-			grpc_recovery.UnaryServerInterceptor(grpcRecoveryOpts...),
-			grpc_ctxtags.UnaryServerInterceptor(),
-			nrgrpc.UnaryServerInterceptor(nrApp),
-		))
+	interceptors := []grpc.UnaryServerInterceptor{
+		tracing.UnaryServerInterceptor(),
+		metrics.UnaryServerInterceptor(),
+	}
+	if !cfg.DisableIdentityHeader {
+		interceptors = append(interceptors, grpc_interceptors.EnrichCtxWithIdentity(cfg.IdentityProxyHeader))
+	}
+	interceptors = append(interceptors,
+		grpc_interceptors.EnrichCtxWithBearerIdentity(authenticator),
+		grpc_interceptors.LogRequests(grpcZapLogger.Desugar(), cfg.RequestLog),
+		grpc_interceptors.RateLimit(limiter),
+	)
+	if deps.UsageCounter != nil {
+		interceptors = append(interceptors, grpc_interceptors.CountUsage(deps.UsageCounter))
+	}
+	if deps.IdempotencyService != nil {
+		interceptors = append(interceptors, grpc_interceptors.Idempotency(deps.IdempotencyService))
+	}
+	interceptors = append(interceptors,
+		grpc_recovery.UnaryServerInterceptor(grpcRecoveryOpts...),
+		grpc_ctxtags.UnaryServerInterceptor(),
+		nrgrpc.UnaryServerInterceptor(nrApp),
+	)
+	return grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(interceptors...))
+}
+
+// bearerAuthenticator authenticates api keys, session access tokens, the id
+// tokens of the oidc providers and the tokens signed by service users, they
+// are told apart by the format of the token and its issuer
+func bearerAuthenticator(deps api.Deps) grpc_interceptors.Authenticator {
+	authenticate := func(ctx context.Context, token string) (user.User, error) {
+		if apikey.IsKey(token) {
+			return deps.APIKeyService.Authenticate(ctx, token)
+		}
+		if session.IsAccessToken(token) {
+			return deps.SessionService.Authenticate(ctx, token)
+		}
+		if deps.OIDCService != nil && deps.OIDCService.Handles(token) {
+			return deps.OIDCService.Authenticate(ctx, token)
+		}
+		return deps.ServiceUserService.Authenticate(ctx, token)
+	}
+	return grpc_interceptors.AuthenticatorFunc(func(ctx context.Context, token string) (user.User, error) {
+		usr, err := authenticate(ctx, token)
+		if err != nil {
+			return user.User{}, err
+		}
+		// the keys and tokens of disabled users stay valid until they
+		// expire, the user is checked instead
+		if usr.IsDisabled() {
+			return user.User{}, user.ErrDisabled
+		}
+		return usr, nil
+	})
 }
 
 func customHeaderMatcherFunc(headerKeys map[string]bool) func(key string) (string, bool) {
@@ -109,3 +329,14 @@ func customHeaderMatcherFunc(headerKeys map[string]bool) func(key string) (strin
 		return runtime.DefaultHeaderMatcher(key)
 	}
 }
+
+// outgoingHeaderMatcherFunc passes the headers as they are to the responses
+// of the gateway, the others are prefixed the way the gateway does
+func outgoingHeaderMatcherFunc(headerKeys map[string]bool) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		if _, ok := headerKeys[key]; ok {
+			return key, true
+		}
+		return fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, key), true
+	}
+}
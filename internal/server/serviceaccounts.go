@@ -0,0 +1,216 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+)
+
+// the service accounts and their keys have no rpcs, they are created and
+// listed next to the gateway api
+const (
+	serviceAccountsPath    = "/admin/v1beta1/serviceaccounts"
+	serviceAccountKeysPath = "/admin/v1beta1/serviceaccounts/keys"
+)
+
+type serviceAccountResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newServiceAccountResponse(su serviceuser.ServiceUser) serviceAccountResponse {
+	return serviceAccountResponse{
+		ID:        su.ID,
+		Name:      su.Name,
+		UserID:    su.UserID,
+		Email:     serviceuser.Email(su.Name),
+		CreatedAt: su.CreatedAt,
+	}
+}
+
+type serviceAccountKeyResponse struct {
+	ID               string    `json:"id"`
+	ServiceAccountID string    `json:"serviceaccount_id"`
+	Algorithm        string    `json:"algorithm"`
+	CreatedAt        time.Time `json:"created_at"`
+	// PrivateKey is only set when the key is created, shield keeps the
+	// public key alone
+	PrivateKey string `json:"private_key,omitempty"`
+}
+
+func newServiceAccountKeyResponse(key serviceuser.Key) serviceAccountKeyResponse {
+	return serviceAccountKeyResponse{
+		ID:               key.ID,
+		ServiceAccountID: key.ServiceUserID,
+		Algorithm:        key.Algorithm,
+		CreatedAt:        key.CreatedAt,
+	}
+}
+
+// serviceAccountsHandler lists the service accounts the current user
+// manages and, with POST, creates the one named after the name query
+// parameter in the organization of the org query parameter, an id or a
+// slug. The service account is made a member of the organization so its
+// admins manage it, only they can create it.
+func serviceAccountsHandler(userService *user.Service, serviceUserService *serviceuser.Service, orgService *organization.Service, relationService *relation.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		currentUser, err := userService.FetchCurrentUser(r.Context())
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		query := r.URL.Query()
+		switch r.Method {
+		case http.MethodGet:
+			serviceUsers, err := serviceUserService.List(r.Context())
+			if err != nil {
+				writeServiceAccountError(w, err)
+				return
+			}
+			resp := struct {
+				ServiceAccounts []serviceAccountResponse `json:"serviceaccounts"`
+			}{ServiceAccounts: []serviceAccountResponse{}}
+			for _, su := range serviceUsers {
+				err := adminOfUser(r.Context(), orgService, relationService, currentUser, user.User{ID: su.UserID})
+				if errors.Is(err, shielderrors.ErrForbidden) {
+					continue
+				}
+				if err != nil {
+					writeServiceAccountError(w, err)
+					return
+				}
+				resp.ServiceAccounts = append(resp.ServiceAccounts, newServiceAccountResponse(su))
+			}
+			writeJSON(w, http.StatusOK, resp)
+		case http.MethodPost:
+			if query.Get("org") == "" {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "org is required"})
+				return
+			}
+			org, err := orgService.Get(r.Context(), query.Get("org"))
+			if err != nil {
+				writeServiceAccountError(w, err)
+				return
+			}
+			allowed, err := relationService.CheckPermission(r.Context(), currentUser, namespace.Namespace{ID: schema.OrganizationNamespace}, org.ID, action.Action{ID: schema.EditPermission})
+			if err == nil && !allowed {
+				err = shielderrors.ErrForbidden
+			}
+			if err != nil {
+				writeServiceAccountError(w, err)
+				return
+			}
+
+			created, err := serviceUserService.Create(r.Context(), serviceuser.ServiceUser{Name: query.Get("name")})
+			if err != nil {
+				writeServiceAccountError(w, err)
+				return
+			}
+			// the relations of users are created with their email
+			if _, err := relationService.Create(r.Context(), relation.RelationV2{
+				Object:  relation.Object{ID: org.ID, NamespaceID: schema.OrganizationNamespace},
+				Subject: relation.Subject{ID: serviceuser.Email(created.Name), Namespace: schema.UserPrincipal, RoleID: schema.ViewerRole},
+			}); err != nil {
+				writeServiceAccountError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, newServiceAccountResponse(created))
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+		}
+	})
+}
+
+// serviceAccountKeysHandler lists the keys of the service account of the id
+// query parameter and, with POST, creates one of the algorithm query
+// parameter. Only the admins of every organization the service account is a
+// member of manage its keys, as with its api keys.
+func serviceAccountKeysHandler(userService *user.Service, serviceUserService *serviceuser.Service, orgService *organization.Service, relationService *relation.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			w.Header().Set("Allow", "GET, POST")
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		currentUser, err := userService.FetchCurrentUser(r.Context())
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		query := r.URL.Query()
+		if query.Get("id") == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+			return
+		}
+		su, err := serviceUserService.Get(r.Context(), query.Get("id"))
+		if err == nil {
+			err = adminOfEveryOrg(r.Context(), orgService, relationService, currentUser, user.User{ID: su.UserID})
+		}
+		if err != nil {
+			writeServiceAccountError(w, err)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			keys, err := serviceUserService.ListKeys(r.Context(), su.ID)
+			if err != nil {
+				writeServiceAccountError(w, err)
+				return
+			}
+			resp := struct {
+				Keys []serviceAccountKeyResponse `json:"keys"`
+			}{Keys: []serviceAccountKeyResponse{}}
+			for _, k := range keys {
+				resp.Keys = append(resp.Keys, newServiceAccountKeyResponse(k))
+			}
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+
+		algorithm := query.Get("algorithm")
+		if algorithm == "" {
+			algorithm = serviceuser.AlgorithmEd25519
+		}
+		key, privateKey, err := serviceUserService.CreateKey(r.Context(), su.ID, algorithm)
+		if err != nil {
+			writeServiceAccountError(w, err)
+			return
+		}
+		resp := newServiceAccountKeyResponse(key)
+		resp.PrivateKey = string(privateKey)
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func writeServiceAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, serviceuser.ErrNotExist),
+		errors.Is(err, serviceuser.ErrInvalidID),
+		errors.Is(err, organization.ErrNotExist),
+		errors.Is(err, organization.ErrInvalidUUID),
+		errors.Is(err, organization.ErrInvalidID):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+	case errors.Is(err, serviceuser.ErrConflict):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "conflict", ErrorDescription: err.Error()})
+	case errors.Is(err, serviceuser.ErrInvalidDetail),
+		errors.Is(err, serviceuser.ErrUnsupportedAlgorithm):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+	default:
+		writeAccessError(w, err)
+	}
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/odpf/shield/core/oidc"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/server/grpc_interceptors"
+)
+
+const (
+	grantTypeIDToken      = "id_token"
+	grantTypeRefreshToken = "refresh_token"
+
+	// sessionsPath is served next to the gateway api, the sessions have no
+	// rpcs of their own
+	sessionsPath = "/admin/v1beta1/auth/sessions"
+	tokenPath    = "/admin/v1beta1/auth/token"
+)
+
+type tokenRequest struct {
+	GrantType    string `json:"grant_type"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	SessionID    string `json:"session_id"`
+}
+
+type sessionResponse struct {
+	ID          string    `json:"id"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedAt   time.Time `json:"created_at"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type errorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// tokenHandler issues the tokens of a new session in exchange of the id
+// token of an oidc provider, and renews them in exchange of the refresh
+// token of the session
+func tokenHandler(oidcService *oidc.Service, sessionService *session.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		var req tokenRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "body should be json"})
+			return
+		}
+
+		var sess session.Session
+		var tokens session.Tokens
+		var err error
+		switch req.GrantType {
+		case grantTypeIDToken:
+			if oidcService == nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "unsupported_grant_type", ErrorDescription: "no oidc providers are configured"})
+				return
+			}
+			var usr user.User
+			if usr, err = oidcService.Authenticate(r.Context(), req.IDToken); err == nil {
+				sess, tokens, err = sessionService.Create(r.Context(), usr.ID, r.UserAgent())
+			}
+		case grantTypeRefreshToken:
+			sess, tokens, err = sessionService.Refresh(r.Context(), req.RefreshToken)
+		default:
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "unsupported_grant_type"})
+			return
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, tokenResponse{
+			AccessToken:  tokens.AccessToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int64(time.Until(tokens.AccessExpiresAt).Seconds()),
+			RefreshToken: tokens.RefreshToken,
+			SessionID:    sess.ID,
+		})
+	})
+}
+
+// sessionsHandler lists the active sessions of the user of the bearer
+// token and revokes them by their id
+func sessionsHandler(authenticator grpc_interceptors.Authenticator, sessionService *session.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token == r.Header.Get("Authorization") {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid_token", ErrorDescription: "bearer token is required"})
+			return
+		}
+		usr, err := authenticator.Authenticate(r.Context(), token)
+		if err != nil {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid_token", ErrorDescription: err.Error()})
+			return
+		}
+
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, sessionsPath), "/")
+		switch {
+		case r.Method == http.MethodGet && id == "":
+			sessions, err := sessionService.List(r.Context(), usr.ID, false)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			resp := struct {
+				Sessions []sessionResponse `json:"sessions"`
+			}{Sessions: []sessionResponse{}}
+			for _, sess := range sessions {
+				resp.Sessions = append(resp.Sessions, sessionResponse{
+					ID:          sess.ID,
+					UserAgent:   sess.UserAgent,
+					CreatedAt:   sess.CreatedAt,
+					RefreshedAt: sess.RefreshedAt,
+					ExpiresAt:   sess.ExpiresAt,
+				})
+			}
+			writeJSON(w, http.StatusOK, resp)
+		case r.Method == http.MethodDelete && id != "":
+			if _, err := sessionService.RevokeOwn(r.Context(), usr.ID, id); err != nil {
+				writeError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+		}
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, session.ErrNotExist):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+	case errors.Is(err, session.ErrRevoked),
+		errors.Is(err, session.ErrInvalidToken),
+		errors.Is(err, session.ErrExpired),
+		errors.Is(err, oidc.ErrInvalidToken),
+		errors.Is(err, oidc.ErrUnknownIssuer),
+		errors.Is(err, oidc.ErrMissingEmail),
+		errors.Is(err, user.ErrDisabled):
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid_grant", ErrorDescription: err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "server_error"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/user"
+)
+
+// the groups that are members of a group are listed, added and removed next
+// to the gateway api, the group rpcs only take users as members
+const groupSubgroupsPath = "/admin/v1beta1/groups/subgroups"
+
+// groupSubgroupsHandler lists the groups that are members of the group of
+// the id query parameter, an id or a slug, adds the groups of the subgroup
+// query parameters with POST and removes the group of the subgroup query
+// parameter with DELETE. Adding and removing needs the edit permission on
+// the group.
+func groupSubgroupsHandler(userService *user.Service, groupService *group.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := userService.FetchCurrentUser(r.Context()); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		query := r.URL.Query()
+		idOrSlug := query.Get("id")
+		if idOrSlug == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+			return
+		}
+
+		var subgroups []group.Group
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			subgroups, err = groupService.ListSubgroups(r.Context(), idOrSlug)
+		case http.MethodPost:
+			if len(query["subgroup"]) == 0 {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "subgroup is required"})
+				return
+			}
+			subgroups, err = groupService.AddSubgroups(r.Context(), idOrSlug, query["subgroup"])
+		case http.MethodDelete:
+			subgroup := query.Get("subgroup")
+			if subgroup == "" {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "subgroup is required"})
+				return
+			}
+			subgroups, err = groupService.RemoveSubgroup(r.Context(), idOrSlug, subgroup)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, group.ErrNotExist),
+				errors.Is(err, group.ErrInvalidUUID),
+				errors.Is(err, group.ErrInvalidID):
+				writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+			case errors.Is(err, group.ErrCyclicMembership):
+				writeJSON(w, http.StatusConflict, errorResponse{Error: "conflict", ErrorDescription: err.Error()})
+			case errors.Is(err, group.ErrOtherOrganization):
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+			default:
+				writeAccessError(w, err)
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, struct {
+			Groups []groupResponse `json:"groups"`
+		}{Groups: newGroupResponses(subgroups)})
+	})
+}
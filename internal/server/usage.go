@@ -0,0 +1,83 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/usage"
+)
+
+// orgUsagePath serves the daily usage of an organization, it has no rpc of
+// its own
+const orgUsagePath = "/admin/v1beta1/organizations/usage"
+
+// defaultUsageSince is the usage reported when no start is given
+const defaultUsageSince = "30d"
+
+type usageResponse struct {
+	Day            string `json:"day,omitempty"`
+	APICalls       int64  `json:"api_calls"`
+	RelationWrites int64  `json:"relation_writes"`
+}
+
+// orgUsageHandler returns the usage of the organization of the org_id query
+// parameter on every day since the day of the since parameter, 30 days back
+// by default, along with the total of the days
+func orgUsageHandler(usageService *usage.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+
+		query := r.URL.Query()
+		orgID, sinceParam := query.Get("org_id"), query.Get("since")
+		if orgID == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "org_id is required"})
+			return
+		}
+		if sinceParam == "" {
+			sinceParam = defaultUsageSince
+		}
+		since, err := usage.ParseSince(sinceParam, time.Now())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+			return
+		}
+
+		usages, err := usageService.Get(r.Context(), orgID, since)
+		if err != nil {
+			switch {
+			case errors.Is(err, usage.ErrInvalidDetail):
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+			case errors.Is(err, organization.ErrNotExist),
+				errors.Is(err, organization.ErrInvalidUUID),
+				errors.Is(err, organization.ErrInvalidID):
+				writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+			default:
+				writeAccessError(w, err)
+			}
+			return
+		}
+
+		resp := struct {
+			OrgID string          `json:"org_id"`
+			Total usageResponse   `json:"total"`
+			Days  []usageResponse `json:"days"`
+		}{Days: []usageResponse{}}
+		for _, u := range usages {
+			resp.OrgID = u.OrgID
+			resp.Total.APICalls += u.APICalls
+			resp.Total.RelationWrites += u.RelationWrites
+			resp.Days = append(resp.Days, usageResponse{
+				Day:            u.Day.Format("2006-01-02"),
+				APICalls:       u.APICalls,
+				RelationWrites: u.RelationWrites,
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
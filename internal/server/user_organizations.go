@@ -0,0 +1,59 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/user"
+)
+
+// the groups of a user are listed with the ListUserGroups rpc of
+// /v1beta1/users/{id}/groups, there is no rpc for their organizations so
+// they are served next to the gateway api
+const userOrganizationsPath = "/admin/v1beta1/users/organizations"
+
+// userOrganizationsHandler lists the organizations the user of the id query
+// parameter, an id or an email, has a role on or has a role on one of the
+// groups of
+func userOrganizationsHandler(userService *user.Service, orgService *organization.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		if _, err := userService.FetchCurrentUser(r.Context()); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		idOrEmail := r.URL.Query().Get("id")
+		if idOrEmail == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+			return
+		}
+		usr, err := userService.Get(r.Context(), idOrEmail)
+		if err != nil {
+			switch {
+			case errors.Is(err, user.ErrNotExist),
+				errors.Is(err, user.ErrInvalidUUID),
+				errors.Is(err, user.ErrInvalidID),
+				errors.Is(err, user.ErrInvalidEmail):
+				writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+			default:
+				writeAccessError(w, err)
+			}
+			return
+		}
+
+		orgs, err := orgService.List(r.Context(), organization.Filter{UserID: usr.ID})
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, struct {
+			Organizations []organizationResponse `json:"organizations"`
+		}{Organizations: newOrganizationResponses(orgs)})
+	})
+}
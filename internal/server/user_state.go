@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	shielderrors "github.com/odpf/shield/pkg/errors"
+)
+
+// the users are enabled and disabled next to the gateway api, the user
+// messages have no state and there are no rpcs to change it
+const (
+	usersEnablePath  = "/admin/v1beta1/users/enable"
+	usersDisablePath = "/admin/v1beta1/users/disable"
+)
+
+type userStateService interface {
+	FetchCurrentUser(ctx context.Context) (user.User, error)
+	Get(ctx context.Context, idOrEmail string) (user.User, error)
+	Enable(ctx context.Context, idOrEmail string) (user.User, error)
+	Disable(ctx context.Context, idOrEmail string) (user.User, error)
+}
+
+type userOrgService interface {
+	List(ctx context.Context, flt organization.Filter) ([]organization.Organization, error)
+}
+
+type permissionChecker interface {
+	CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceID string, action action.Action) (bool, error)
+}
+
+type userStateResponse struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	State           user.State `json:"state"`
+	DisabledAt      *time.Time `json:"disabled_at,omitempty"`
+	RevokedSessions int        `json:"revoked_sessions,omitempty"`
+}
+
+func newUserStateResponse(usr user.User) userStateResponse {
+	resp := userStateResponse{
+		ID:    usr.ID,
+		Name:  usr.Name,
+		Email: usr.Email,
+		State: usr.State(),
+	}
+	if usr.IsDisabled() {
+		resp.DisabledAt = &usr.DisabledAt
+	}
+	return resp
+}
+
+// userEnableHandler enables the user of the id query parameter, an id or an
+// email, the user gets back the memberships and roles they had
+func userEnableHandler(userService userStateService, orgService userOrgService, relationService permissionChecker, superusers []string) http.Handler {
+	return userStateHandler(userService, orgService, relationService, superusers, func(ctx context.Context, idOrEmail string) (userStateResponse, error) {
+		usr, err := userService.Enable(ctx, idOrEmail)
+		if err != nil {
+			return userStateResponse{}, err
+		}
+		return newUserStateResponse(usr), nil
+	})
+}
+
+// userDisableHandler disables the user of the id query parameter, an id or
+// an email, and revokes their active sessions. The memberships and roles of
+// the user are kept.
+func userDisableHandler(userService userStateService, orgService userOrgService, relationService permissionChecker, superusers []string, sessionService *session.Service) http.Handler {
+	return userStateHandler(userService, orgService, relationService, superusers, func(ctx context.Context, idOrEmail string) (userStateResponse, error) {
+		usr, err := userService.Disable(ctx, idOrEmail)
+		if err != nil {
+			return userStateResponse{}, err
+		}
+		resp := newUserStateResponse(usr)
+		if sessionService != nil {
+			sessions, err := sessionService.RevokeAll(ctx, usr.ID)
+			if err != nil {
+				return userStateResponse{}, err
+			}
+			resp.RevokedSessions = len(sessions)
+		}
+		return resp, nil
+	})
+}
+
+// userStateHandler changes the state of the user of the id query parameter.
+// The state is global, only the superusers and the admins of every
+// organization the user is a member of can change it.
+func userStateHandler(userService userStateService, orgService userOrgService, relationService permissionChecker, superusers []string, change func(ctx context.Context, idOrEmail string) (userStateResponse, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		currentUser, err := userService.FetchCurrentUser(r.Context())
+		if err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		idOrEmail := r.URL.Query().Get("id")
+		if idOrEmail == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: "id is required"})
+			return
+		}
+
+		resp, err := func() (userStateResponse, error) {
+			usr, err := userService.Get(r.Context(), idOrEmail)
+			if err != nil {
+				return userStateResponse{}, err
+			}
+			if checkSuperuser(superusers, currentUser) != nil {
+				if err := adminOfEveryOrg(r.Context(), orgService, relationService, currentUser, usr); err != nil {
+					return userStateResponse{}, err
+				}
+			}
+			return change(r.Context(), usr.ID)
+		}()
+		if err != nil {
+			switch {
+			case errors.Is(err, user.ErrNotExist),
+				errors.Is(err, user.ErrInvalidUUID),
+				errors.Is(err, user.ErrInvalidID),
+				errors.Is(err, user.ErrInvalidEmail):
+				writeJSON(w, http.StatusNotFound, errorResponse{Error: "not_found", ErrorDescription: err.Error()})
+			default:
+				writeAccessError(w, err)
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// adminOfUser checks the current user can edit one of the organizations the
+// user is a member of. It only decides what is shown to the admins, the
+// changes to a user are checked with adminOfEveryOrg.
+func adminOfUser(ctx context.Context, orgService userOrgService, relationService permissionChecker, currentUser, usr user.User) error {
+	orgs, err := orgService.List(ctx, organization.Filter{UserID: usr.ID})
+	if err != nil {
+		return err
+	}
+	for _, org := range orgs {
+		allowed, err := relationService.CheckPermission(ctx, currentUser, namespace.Namespace{ID: schema.OrganizationNamespace}, org.ID, action.Action{ID: schema.EditPermission})
+		if err != nil {
+			return err
+		}
+		if allowed {
+			return nil
+		}
+	}
+	return shielderrors.ErrForbidden
+}
+
+// adminOfEveryOrg checks the current user can edit every organization the
+// user is a member of. Any user can be added to an organization, an admin
+// of one of them alone doesn't own the user.
+func adminOfEveryOrg(ctx context.Context, orgService userOrgService, relationService permissionChecker, currentUser, usr user.User) error {
+	orgs, err := orgService.List(ctx, organization.Filter{UserID: usr.ID})
+	if err != nil {
+		return err
+	}
+	if len(orgs) == 0 {
+		return shielderrors.ErrForbidden
+	}
+	for _, org := range orgs {
+		allowed, err := relationService.CheckPermission(ctx, currentUser, namespace.Namespace{ID: schema.OrganizationNamespace}, org.ID, action.Action{ID: schema.EditPermission})
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return shielderrors.ErrForbidden
+		}
+	}
+	return nil
+}
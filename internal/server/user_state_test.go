@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/user"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryUserStateService struct {
+	current  user.User
+	users    map[string]user.User
+	disabled map[string]bool
+}
+
+func (s *memoryUserStateService) FetchCurrentUser(ctx context.Context) (user.User, error) {
+	return s.current, nil
+}
+
+func (s *memoryUserStateService) Get(ctx context.Context, idOrEmail string) (user.User, error) {
+	usr, ok := s.users[idOrEmail]
+	if !ok {
+		return user.User{}, user.ErrNotExist
+	}
+	return usr, nil
+}
+
+func (s *memoryUserStateService) Enable(ctx context.Context, idOrEmail string) (user.User, error) {
+	delete(s.disabled, idOrEmail)
+	return s.Get(ctx, idOrEmail)
+}
+
+func (s *memoryUserStateService) Disable(ctx context.Context, idOrEmail string) (user.User, error) {
+	s.disabled[idOrEmail] = true
+	return s.Get(ctx, idOrEmail)
+}
+
+// memoryUserOrgService has the organizations of the users by user id
+type memoryUserOrgService map[string][]organization.Organization
+
+func (s memoryUserOrgService) List(ctx context.Context, flt organization.Filter) ([]organization.Organization, error) {
+	return s[flt.UserID], nil
+}
+
+// memoryPermissionChecker has the organizations the users are admins of by
+// user id
+type memoryPermissionChecker map[string][]string
+
+func (c memoryPermissionChecker) CheckPermission(ctx context.Context, usr user.User, resourceNS namespace.Namespace, resourceID string, act action.Action) (bool, error) {
+	for _, id := range c[usr.ID] {
+		if id == resourceID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestUserDisableHandler(t *testing.T) {
+	john := user.User{ID: "john", Email: "john.doe@odpf.io"}
+	jane := user.User{ID: "jane", Email: "jane.doe@odpf.io"}
+	orgs := memoryUserOrgService{"john": {{ID: "odpf"}}, "jane": {{ID: "odpf"}, {ID: "gotocompany"}}}
+	admins := memoryPermissionChecker{"admin": {"odpf"}, "other-admin": {"gotocompany"}}
+	superusers := []string{"root@odpf.io"}
+
+	tests := []struct {
+		name         string
+		current      user.User
+		target       user.User
+		wantStatus   int
+		wantDisabled bool
+	}{
+		{
+			name:         "should disable the user if the caller is an admin of their organization",
+			current:      user.User{ID: "admin"},
+			target:       john,
+			wantStatus:   http.StatusOK,
+			wantDisabled: true,
+		},
+		{
+			name:         "should disable the user if the caller is a superuser",
+			current:      user.User{ID: "root", Email: "root@odpf.io"},
+			target:       jane,
+			wantStatus:   http.StatusOK,
+			wantDisabled: true,
+		},
+		{
+			name:       "should return forbidden if the caller isn't an admin",
+			current:    user.User{ID: "member"},
+			target:     john,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "should return forbidden if the caller is an admin of another organization",
+			current:    user.User{ID: "other-admin"},
+			target:     john,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "should return forbidden if the caller isn't an admin of every organization of the user",
+			current:    user.User{ID: "admin"},
+			target:     jane,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "should return forbidden if the caller disables themselves without being an admin",
+			current:    john,
+			target:     john,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &memoryUserStateService{
+				current:  tt.current,
+				users:    map[string]user.User{john.ID: john, john.Email: john, jane.ID: jane, jane.Email: jane},
+				disabled: map[string]bool{},
+			}
+			h := userDisableHandler(users, orgs, admins, superusers, nil)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, usersDisablePath+"?id="+tt.target.Email, nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantDisabled, users.disabled[tt.target.ID])
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/core/user"
+)
+
+// eventsWatchPath streams the changes made to the resources as server-sent
+// events, there is no streaming rpc for them
+const eventsWatchPath = "/admin/v1beta1/events/watch"
+
+// watchHeartbeat is how often a comment is sent to the watchers so idle
+// connections are not closed by proxies
+const watchHeartbeat = 30 * time.Second
+
+// eventsWatchHandler streams the events matching the event query parameters
+// to the user, every event when none is given. The events recorded by the
+// instance are streamed from the moment the watch starts, a watcher falling
+// behind gets a reset event before the stream ends and has to list what it
+// missed before watching again.
+func eventsWatchHandler(userService *user.Service, hub *event.Hub) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "invalid_request"})
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "server_error", ErrorDescription: "streaming is not supported"})
+			return
+		}
+
+		if _, err := userService.FetchCurrentUser(r.Context()); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+
+		filters := r.URL.Query()["event"]
+		if len(filters) == 0 {
+			filters = []string{"*"}
+		}
+		events, err := hub.Watch(r.Context(), filters)
+		if err != nil {
+			if errors.Is(err, event.ErrInvalidDetail) {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "server_error"})
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(watchHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					if r.Context().Err() == nil {
+						fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+						flusher.Flush()
+					}
+					return
+				}
+				data, err := json.Marshal(ev)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+				flusher.Flush()
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+				flusher.Flush()
+			}
+		}
+	})
+}
@@ -2,12 +2,16 @@ package blob
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
-	"regexp"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/odpf/salt/log"
 
 	"github.com/robfig/cron/v3"
@@ -32,12 +36,19 @@ type Backend struct {
 	Methods   []string   `yaml:"methods"`
 	Frontends []Frontend `yaml:"frontends"`
 	Prefix    string     `yaml:"prefix"`
+
+	Timeout        string              `yaml:"timeout"`
+	Retry          rule.Retry          `yaml:"retry"`
+	CircuitBreaker rule.CircuitBreaker `yaml:"circuit_breaker" json:"circuit_breaker"`
+	Splits         []rule.Split        `yaml:"splits"`
 }
 
 type Frontend struct {
 	Action      string       `yaml:"action"`
 	Path        string       `yaml:"path"`
 	Method      string       `yaml:"method"`
+	GRPCMethod  string       `yaml:"grpc_method" json:"grpc_method"`
+	MaxBodySize int64        `yaml:"max_body_size" json:"max_body_size"`
 	Middlewares []Middleware `yaml:"middlewares"`
 	Hooks       []Hook       `yaml:"hooks"`
 }
@@ -52,13 +63,24 @@ type Hook struct {
 	Config map[string]interface{} `yaml:"config"`
 }
 
+// ruleWatchDelay is how long the changes to the rule files are waited for
+// to settle before reloading them, editors and syncs write a file in steps
+const ruleWatchDelay = time.Millisecond * 500
+
 type RuleRepository struct {
 	log log.Logger
 	mu  *sync.Mutex
 
-	cron   *cron.Cron
-	bucket Bucket
-	cached []rule.Ruleset
+	cron    *cron.Cron
+	watcher *fsnotify.Watcher
+	bucket  Bucket
+	cached  []rule.Ruleset
+	status  rule.Status
+
+	// store has the rules managed without rule files, the ones of the proxy
+	// are served ahead of the rule files
+	store rule.Repository
+	proxy string
 }
 
 func (repo *RuleRepository) GetAll(ctx context.Context) ([]rule.Ruleset, error) {
@@ -71,11 +93,65 @@ func (repo *RuleRepository) GetAll(ctx context.Context) ([]rule.Ruleset, error)
 	}
 
 	err := repo.refresh(ctx)
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
 	return repo.cached, err
 }
 
+// Reload loads the rule files right away instead of waiting for the next
+// refresh
+func (repo *RuleRepository) Reload(ctx context.Context) (rule.Status, error) {
+	err := repo.refresh(ctx)
+	return repo.Status(ctx), err
+}
+
+func (repo *RuleRepository) Status(ctx context.Context) rule.Status {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return repo.status
+}
+
+// refresh replaces the cached rules with the rule files, all the files are
+// rejected when one of them is invalid and the rules cached stay in use
 func (repo *RuleRepository) refresh(ctx context.Context) error {
+	ruleset, checksum, err := repo.load(ctx)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if err != nil {
+		if errors.Is(err, rule.ErrInvalidRuleset) {
+			repo.status.Error = err.Error()
+			repo.status.FailedAt = time.Now().UTC()
+		}
+		return err
+	}
+	repo.status.Error = ""
+	repo.status.FailedAt = time.Time{}
+	if repo.status.Version > 0 && checksum == repo.status.Checksum {
+		return nil
+	}
+
+	rulesCount := 0
+	for _, rs := range ruleset {
+		rulesCount += len(rs.Rules)
+	}
+	repo.cached = ruleset
+	repo.status = rule.Status{
+		Version:  repo.status.Version + 1,
+		Checksum: checksum,
+		LoadedAt: time.Now().UTC(),
+		Rulesets: len(ruleset),
+		Rules:    rulesCount,
+	}
+	repo.log.Info("rule cache refreshed", "ruleset_count", len(ruleset), "version", repo.status.Version, "checksum", checksum)
+	return nil
+}
+
+// load reads and validates the rule files, the checksum is of the names and
+// the content of the files in the order they are listed
+func (repo *RuleRepository) load(ctx context.Context) ([]rule.Ruleset, string, error) {
 	var ruleset []rule.Ruleset
+	hash := sha256.New()
 
 	// get all items
 	it := repo.bucket.List(&blob.ListOptions{})
@@ -85,7 +161,7 @@ func (repo *RuleRepository) refresh(ctx context.Context) error {
 			if err == io.EOF {
 				break
 			}
-			return err
+			return nil, "", err
 		}
 
 		if obj.IsDir {
@@ -96,12 +172,16 @@ func (repo *RuleRepository) refresh(ctx context.Context) error {
 		}
 		fileBytes, err := repo.bucket.ReadAll(ctx, obj.Key)
 		if err != nil {
-			return errors.Wrap(err, "bucket.ReadAll: "+obj.Key)
+			return nil, "", errors.Wrap(err, "bucket.ReadAll: "+obj.Key)
 		}
+		hash.Write([]byte(obj.Key))
+		hash.Write([]byte{0})
+		hash.Write(fileBytes)
+		hash.Write([]byte{0})
 
 		var s Ruleset
 		if err := yaml.Unmarshal(fileBytes, &s); err != nil {
-			return errors.Wrap(err, "yaml.Unmarshal: "+obj.Key)
+			return nil, "", errors.Wrapf(rule.ErrInvalidRuleset, "%s: %s", obj.Key, err)
 		}
 		if len(s.Rules) == 0 {
 			continue
@@ -130,10 +210,22 @@ func (repo *RuleRepository) refresh(ctx context.Context) error {
 
 					targetRuleSet.Rules = append(targetRuleSet.Rules, rule.Rule{
 						Frontend: rule.Frontend{
-							URL:    frontend.Path,
-							Method: frontend.Method,
+							URL:         frontend.Path,
+							Method:      frontend.Method,
+							GRPCMethod:  frontend.GRPCMethod,
+							MaxBodySize: frontend.MaxBodySize,
+						},
+						Backend: rule.Backend{
+							URL:       backend.Target,
+							Namespace: backend.Name,
+							Prefix:    backend.Prefix,
+							Policy: rule.Policy{
+								Timeout:        backend.Timeout,
+								Retry:          backend.Retry,
+								CircuitBreaker: backend.CircuitBreaker,
+							},
+							Splits: backend.Splits,
 						},
-						Backend:     rule.Backend{URL: backend.Target, Namespace: backend.Name, Prefix: backend.Prefix},
 						Middlewares: middlewares,
 						Hooks:       hooks,
 					})
@@ -142,29 +234,34 @@ func (repo *RuleRepository) refresh(ctx context.Context) error {
 		}
 
 		// parse all urls at this time only to avoid doing it usage
-		var rxParsingSuccess = true
-		for ruleIdx, rule := range targetRuleSet.Rules {
-			// TODO: only compile between delimiter, maybe angular brackets
-			targetRuleSet.Rules[ruleIdx].Frontend.URLRx, err = regexp.Compile(rule.Frontend.URL)
-			if err != nil {
-				rxParsingSuccess = false
-				repo.log.Error("failed to parse rule frontend as a valid regular expression",
-					"url", rule.Frontend.URL, "err", err)
+		for ruleIdx := range targetRuleSet.Rules {
+			if err := targetRuleSet.Rules[ruleIdx].Compile(); err != nil {
+				return nil, "", errors.Wrapf(rule.ErrInvalidRuleset, "%s: %s", obj.Key, err)
 			}
 		}
 
-		if rxParsingSuccess {
-			ruleset = append(ruleset, targetRuleSet)
-		} else {
-			repo.log.Warn("skipping rule set due to parsing errors", "content", string(fileBytes))
-		}
+		ruleset = append(ruleset, targetRuleSet)
 	}
 
-	repo.mu.Lock()
-	repo.cached = ruleset
-	repo.mu.Unlock()
-	repo.log.Debug("rule cache refreshed", "ruleset_count", len(repo.cached))
-	return nil
+	if repo.store != nil {
+		stored, err := repo.store.List(ctx, rule.Filter{Proxy: repo.proxy})
+		if err != nil {
+			return nil, "", errors.Wrap(err, "store.List")
+		}
+		storedRuleset := rule.Ruleset{}
+		for _, r := range stored {
+			if err := r.Compile(); err != nil {
+				return nil, "", errors.Wrapf(rule.ErrInvalidRuleset, "rule %s: %s", r.Name, err)
+			}
+			hash.Write([]byte(r.ID + "@" + r.UpdatedAt.UTC().Format(time.RFC3339Nano)))
+			hash.Write([]byte{0})
+			storedRuleset.Rules = append(storedRuleset.Rules, r)
+		}
+		if len(storedRuleset.Rules) > 0 {
+			ruleset = append([]rule.Ruleset{storedRuleset}, ruleset...)
+		}
+	}
+	return ruleset, hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 func (repo *RuleRepository) InitCache(ctx context.Context, refreshDelay time.Duration) error {
@@ -184,7 +281,98 @@ func (repo *RuleRepository) InitCache(ctx context.Context, refreshDelay time.Dur
 	return repo.refresh(ctx)
 }
 
+// Watch reloads the rules as soon as the files of dir, the local directory
+// the bucket is opened on, change. Buckets which are not local are only
+// refreshed by the cache.
+func (repo *RuleRepository) Watch(ctx context.Context, dir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// fsnotify doesn't watch the directories of a directory
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		return watcher.Add(path)
+	}); err != nil {
+		watcher.Close()
+		return err
+	}
+	repo.watcher = watcher
+
+	go func() {
+		var reload <-chan time.Time
+		for {
+			select {
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if ev.Op&fsnotify.Create != 0 {
+					if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+						if err := watcher.Add(ev.Name); err != nil {
+							repo.log.Warn("failed to watch rule directory", "dir", ev.Name, "err", err)
+						}
+					}
+				}
+				reload = time.After(ruleWatchDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				repo.log.Warn("failed to watch rule files", "err", err)
+			case <-reload:
+				reload = nil
+				if err := repo.refresh(ctx); err != nil {
+					repo.log.Warn("failed to reload changed rule files", "err", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// WatchStore reloads the rules as soon as the version of the stored rules
+// changes, the version is checked every interval
+func (repo *RuleRepository) WatchStore(ctx context.Context, interval time.Duration) {
+	if repo.store == nil {
+		return
+	}
+	// the rules were loaded with this version at the latest
+	lastVersion, _ := repo.store.Version(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				version, err := repo.store.Version(ctx)
+				if err != nil {
+					repo.log.Warn("failed to check the version of the stored rules", "err", err)
+					continue
+				}
+				if version == lastVersion {
+					continue
+				}
+				lastVersion = version
+				if err := repo.refresh(ctx); err != nil {
+					repo.log.Warn("failed to reload changed stored rules", "err", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
 func (repo *RuleRepository) Close() error {
+	if repo.watcher != nil {
+		repo.watcher.Close()
+	}
 	<-repo.cron.Stop().Done()
 	return repo.bucket.Close()
 }
@@ -196,3 +384,11 @@ func NewRuleRepository(logger log.Logger, b Bucket) *RuleRepository {
 		mu:     new(sync.Mutex),
 	}
 }
+
+// WithStore serves the rules stored for the proxy of the name ahead of the
+// rules of the files, so they can override the routes the files bootstrap
+func (repo *RuleRepository) WithStore(store rule.Repository, proxy string) *RuleRepository {
+	repo.store = store
+	repo.proxy = proxy
+	return repo
+}
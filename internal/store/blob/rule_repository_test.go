@@ -0,0 +1,249 @@
+package blob
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/memblob"
+)
+
+const testRules = `
+rules:
+  - backends:
+      - name: entropy
+        target: "http://localhost:3000"
+        frontends:
+          - name: ping
+            path: "/api/ping"
+            method: "GET"
+`
+
+func TestRuleRepositoryReload(t *testing.T) {
+	ctx := context.Background()
+	bucket := memblob.OpenBucket(nil)
+	require.NoError(t, bucket.WriteAll(ctx, "entropy.yaml", []byte(testRules), nil))
+	repo := NewRuleRepository(log.NewNoop(), bucket)
+
+	status, err := repo.Reload(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), status.Version)
+	assert.Equal(t, 1, status.Rules)
+	assert.NotEmpty(t, status.Checksum)
+
+	t.Run("should keep the version when the rule files didn't change", func(t *testing.T) {
+		got, err := repo.Reload(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, status, got)
+	})
+
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{name: "yaml", rules: "rules: [backends"},
+		{name: "regular expression", rules: `
+rules:
+  - backends:
+      - name: entropy
+        target: "http://localhost:3000"
+        frontends:
+          - path: "/api/(ping"
+`},
+		{name: "target", rules: `
+rules:
+  - backends:
+      - name: entropy
+        target: "localhost"
+        frontends:
+          - path: "/api/ping"
+`},
+		{name: "path", rules: `
+rules:
+  - backends:
+      - name: entropy
+        target: "http://localhost:3000"
+        frontends:
+          - method: "GET"
+`},
+	}
+	for _, tt := range tests {
+		t.Run("should keep the rules loaded when the "+tt.name+" of a rule file is invalid", func(t *testing.T) {
+			require.NoError(t, bucket.WriteAll(ctx, "invalid.yaml", []byte(tt.rules), nil))
+			defer bucket.Delete(ctx, "invalid.yaml")
+
+			got, err := repo.Reload(ctx)
+			assert.ErrorIs(t, err, rule.ErrInvalidRuleset)
+			assert.Equal(t, status.Version, got.Version)
+			assert.Equal(t, status.Checksum, got.Checksum)
+			assert.Contains(t, got.Error, "invalid.yaml")
+
+			rulesets, err := repo.GetAll(ctx)
+			assert.ErrorIs(t, err, rule.ErrInvalidRuleset)
+			require.Len(t, rulesets, 1)
+			assert.Equal(t, "/api/ping", rulesets[0].Rules[0].Frontend.URL)
+		})
+	}
+
+	t.Run("should load the rule files once they are fixed", func(t *testing.T) {
+		require.NoError(t, bucket.WriteAll(ctx, "odin.yaml", []byte(testRules), nil))
+		got, err := repo.Reload(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), got.Version)
+		assert.Equal(t, 2, got.Rulesets)
+		assert.NotEqual(t, status.Checksum, got.Checksum)
+		assert.Empty(t, got.Error)
+	})
+}
+
+func TestRuleRepositoryBackend(t *testing.T) {
+	ctx := context.Background()
+	bucket := memblob.OpenBucket(nil)
+	require.NoError(t, bucket.WriteAll(ctx, "entropy.yaml", []byte(`
+rules:
+  - backends:
+      - name: entropy
+        target: "http://localhost:3000"
+        timeout: 10s
+        retry:
+          attempts: 2
+          budget: 0.2
+        circuit_breaker:
+          failures: 5
+          cooldown: 30s
+        splits:
+          - name: canary
+            target: "http://localhost:3001"
+            weight: 10
+            header:
+              name: X-Canary
+              value: "true"
+        frontends:
+          - name: ping
+            path: "/api/ping"
+            method: "GET"
+`), nil))
+	repo := NewRuleRepository(log.NewNoop(), bucket)
+
+	rulesets, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, rulesets, 1)
+	assert.Equal(t, rule.Backend{
+		URL:       "http://localhost:3000",
+		Namespace: "entropy",
+		Policy: rule.Policy{
+			Timeout:        "10s",
+			Retry:          rule.Retry{Attempts: 2, Budget: 0.2},
+			CircuitBreaker: rule.CircuitBreaker{Failures: 5, Cooldown: "30s"},
+		},
+		Splits: []rule.Split{
+			{Name: "canary", Target: "http://localhost:3001", Weight: 10, Header: &rule.Match{Name: "X-Canary", Value: "true"}},
+		},
+	}, rulesets[0].Rules[0].Backend)
+}
+
+func TestRuleRepositoryWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "entropy.yaml"), []byte(testRules), 0o600))
+
+	bucket, err := NewStore(ctx, "file://"+dir, "")
+	require.NoError(t, err)
+	repo := NewRuleRepository(log.NewNoop(), bucket)
+	require.NoError(t, repo.InitCache(ctx, time.Hour))
+	defer repo.Close()
+	require.NoError(t, repo.Watch(ctx, dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "odin.yaml"), []byte(testRules), 0o600))
+	assert.Eventually(t, func() bool {
+		return repo.Status(ctx).Version == 2
+	}, 5*time.Second, 50*time.Millisecond)
+	rulesets, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rulesets, 2)
+}
+
+type memoryRuleStore struct {
+	rule.Repository
+	mu    sync.Mutex
+	rules []rule.Rule
+}
+
+func (s *memoryRuleStore) add(rl rule.Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rl.ID = strconv.Itoa(len(s.rules) + 1)
+	rl.UpdatedAt = time.Now()
+	s.rules = append(s.rules, rl)
+}
+
+func (s *memoryRuleStore) List(ctx context.Context, flt rule.Filter) ([]rule.Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rules []rule.Rule
+	for _, rl := range s.rules {
+		if rl.Proxy == flt.Proxy {
+			rules = append(rules, rl)
+		}
+	}
+	return rules, nil
+}
+
+func (s *memoryRuleStore) Version(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return strconv.Itoa(len(s.rules)), nil
+}
+
+func TestRuleRepositoryStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bucket := memblob.OpenBucket(nil)
+	require.NoError(t, bucket.WriteAll(ctx, "entropy.yaml", []byte(testRules), nil))
+	store := &memoryRuleStore{}
+	store.add(rule.Rule{
+		Name:     "ping",
+		Proxy:    "entropy",
+		Frontend: rule.Frontend{URL: "/api/ping", Method: "GET"},
+		Backend:  rule.Backend{URL: "http://localhost:4000", Namespace: "entropy"},
+	})
+	store.add(rule.Rule{
+		Name:     "ping",
+		Proxy:    "other",
+		Frontend: rule.Frontend{URL: "/api/ping", Method: "GET"},
+		Backend:  rule.Backend{URL: "http://localhost:5000", Namespace: "other"},
+	})
+
+	repo := NewRuleRepository(log.NewNoop(), bucket).WithStore(store, "entropy")
+	require.NoError(t, repo.InitCache(ctx, time.Hour))
+	defer repo.Close()
+	repo.WatchStore(ctx, 10*time.Millisecond)
+
+	rulesets, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, rulesets, 2)
+	// the stored rules of the proxy are matched ahead of the rules of the files
+	assert.Equal(t, "http://localhost:4000", rulesets[0].Rules[0].Backend.URL)
+	assert.NotNil(t, rulesets[0].Rules[0].Frontend.URLRx)
+	assert.Equal(t, "http://localhost:3000", rulesets[1].Rules[0].Backend.URL)
+
+	store.add(rule.Rule{
+		Name:     "health",
+		Proxy:    "entropy",
+		Frontend: rule.Frontend{URL: "/api/health", Method: "GET"},
+		Backend:  rule.Backend{URL: "http://localhost:4000", Namespace: "entropy"},
+	})
+	assert.Eventually(t, func() bool {
+		return repo.Status(ctx).Version == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, repo.Status(ctx).Rules)
+}
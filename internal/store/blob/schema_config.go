@@ -14,6 +14,11 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// SchemaFileVersion is the version of the schema file format read
+const SchemaFileVersion = 1
+
+var ErrSchemaFileVersion = errors.New("unsupported schema file version")
+
 type RoleConfig struct {
 	Name       string   `yaml:"name" json:"name"`
 	Principals []string `yaml:"principals" json:"principals"`
@@ -49,7 +54,6 @@ func NewSchemaConfigRepository(b Bucket) *SchemaConfig {
 }
 
 func (s *SchemaConfig) GetSchema(ctx context.Context) (schema.NamespaceConfigMapType, error) {
-	configMap := make(schema.NamespaceConfigMapType)
 	if s.config != nil {
 		return s.config, nil
 	}
@@ -59,6 +63,32 @@ func (s *SchemaConfig) GetSchema(ctx context.Context) (schema.NamespaceConfigMap
 		return nil, err
 	}
 
+	s.config = namespaceConfigs(configFromFiles)
+
+	return s.config, nil
+}
+
+// SchemaFile declares the namespaces of a server in a single versioned file,
+// namespaces are written as in the files of the resources config path
+type SchemaFile struct {
+	Version    int               `yaml:"version" json:"version"`
+	Namespaces map[string]Config `yaml:"namespaces" json:"namespaces"`
+}
+
+// ParseSchemaFile returns the namespace configs the schema file declares
+func ParseSchemaFile(content []byte) (schema.NamespaceConfigMapType, error) {
+	var f SchemaFile
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return nil, errors.Wrap(err, "yaml.Unmarshal")
+	}
+	if f.Version != SchemaFileVersion {
+		return nil, fmt.Errorf("%w: %d, version %d is supported", ErrSchemaFileVersion, f.Version, SchemaFileVersion)
+	}
+	return namespaceConfigs([]map[string]Config{f.Namespaces}), nil
+}
+
+func namespaceConfigs(configFromFiles []map[string]Config) schema.NamespaceConfigMapType {
+	configMap := make(schema.NamespaceConfigMapType)
 	for _, c := range configFromFiles {
 		for k, v := range c {
 			if v.Type == "resource_group" {
@@ -68,10 +98,7 @@ func (s *SchemaConfig) GetSchema(ctx context.Context) (schema.NamespaceConfigMap
 			}
 		}
 	}
-
-	s.config = configMap
-
-	return configMap, nil
+	return configMap
 }
 
 func (s *SchemaConfig) readYAMLFiles(ctx context.Context) ([]map[string]Config, error) {
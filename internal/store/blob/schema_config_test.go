@@ -134,3 +134,41 @@ func TestGetSchema(t *testing.T) {
 
 	assert.Equal(t, expectedMap, config)
 }
+
+func TestParseSchemaFile(t *testing.T) {
+	t.Run("should return the namespaces of the file", func(t *testing.T) {
+		config, err := ParseSchemaFile([]byte(`
+version: 1
+namespaces:
+  entropy:
+    type: resource_group
+    resource_types:
+      - name: firehose
+        roles:
+          - name: sink_editor
+            principals:
+              - shield/user
+        permissions:
+          - name: sink_edit
+            roles:
+              - owner
+              - sink_editor
+`))
+		assert.NoError(t, err)
+		assert.Equal(t, schema.NamespaceConfigMapType{
+			"entropy/firehose": schema.NamespaceConfig{
+				Roles:       map[string][]string{"sink_editor": {schema.UserPrincipal}},
+				Permissions: map[string][]string{"sink_edit": {"owner", "sink_editor"}},
+				Type:        schema.ResourceGroupNamespace,
+			},
+		}, config)
+	})
+
+	t.Run("should return error if the version isn't supported", func(t *testing.T) {
+		_, err := ParseSchemaFile([]byte("version: 2\nnamespaces: {}\n"))
+		assert.ErrorIs(t, err, ErrSchemaFileVersion)
+
+		_, err = ParseSchemaFile([]byte("namespaces: {}\n"))
+		assert.ErrorIs(t, err, ErrSchemaFileVersion)
+	})
+}
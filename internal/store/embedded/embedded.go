@@ -0,0 +1,156 @@
+// Package embedded evaluates the permissions of shield without spicedb, it
+// keeps the relation tuples and the schema generated for spicedb in the
+// database of shield and resolves the checks on them itself. It is meant for
+// the deployments small enough that the tuples checked by a request can be
+// read from postgres on every check.
+package embedded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/authzed/spicedb/pkg/caveats"
+	sdbcore "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/odpf/shield/core/relation"
+)
+
+var (
+	ErrInvalidSchema   = errors.New("invalid authz schema")
+	ErrUnknownRelation = errors.New("relation or permission doesn't exist")
+	ErrInvalidTuple    = errors.New("tuple is not allowed by the schema")
+	ErrMaxDepth        = errors.New("check exceeded the maximum depth, the schema or the tuples may have a cycle")
+)
+
+// Store keeps the schema and the tuples the engine evaluates,
+// postgres.AuthzRepository is the one of the server. The fields of the
+// filters left empty match any value.
+type Store interface {
+	ReadSchema(ctx context.Context) (string, error)
+	WriteSchema(ctx context.Context, schemaText string) error
+	Tuples(ctx context.Context, flt relation.Tuple) ([]relation.Tuple, error)
+	ResourceIDs(ctx context.Context, resourceType string) ([]string, error)
+	WriteTuples(ctx context.Context, tuples []relation.Tuple) error
+	DeleteTuples(ctx context.Context, tuples []relation.Tuple) error
+	DeleteMatching(ctx context.Context, flt relation.Tuple) error
+}
+
+// Engine resolves the checks on the tuples of the store, the schema is
+// read on every check as another instance may have changed it and compiled
+// again only when it did
+type Engine struct {
+	store Store
+
+	mu       sync.Mutex
+	source   string
+	compiled *compiledSchema
+}
+
+func New(store Store) *Engine {
+	return &Engine{
+		store: store,
+	}
+}
+
+// compiledSchema has the definitions of the schema by name
+type compiledSchema struct {
+	namespaces map[string]*sdbcore.NamespaceDefinition
+	caveats    map[string]compiledCaveat
+}
+
+type compiledCaveat struct {
+	caveat     *caveats.CompiledCaveat
+	parameters map[string]*sdbcore.CaveatTypeReference
+}
+
+// compile compiles the schema text into its definitions
+func compile(schemaText string) (*compiledSchema, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaText,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchema, err)
+	}
+
+	cs := &compiledSchema{
+		namespaces: map[string]*sdbcore.NamespaceDefinition{},
+		caveats:    map[string]compiledCaveat{},
+	}
+	for _, def := range compiled.ObjectDefinitions {
+		cs.namespaces[def.GetName()] = def
+	}
+	for _, def := range compiled.CaveatDefinitions {
+		caveat, err := caveats.DeserializeCaveat(def.GetSerializedExpression())
+		if err != nil {
+			return nil, fmt.Errorf("%w: caveat %s: %s", ErrInvalidSchema, def.GetName(), err)
+		}
+		cs.caveats[def.GetName()] = compiledCaveat{caveat: caveat, parameters: def.GetParameterTypes()}
+	}
+	return cs, nil
+}
+
+// schema returns the compiled schema of the store
+func (e *Engine) schema(ctx context.Context) (*compiledSchema, error) {
+	schemaText, err := e.store.ReadSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.compiled != nil && e.source == schemaText {
+		return e.compiled, nil
+	}
+	compiled, err := compile(schemaText)
+	if err != nil {
+		return nil, err
+	}
+	e.source, e.compiled = schemaText, compiled
+	return compiled, nil
+}
+
+// relation returns the relation or permission of the namespace
+func (s *compiledSchema) relation(namespaceID, name string) (*sdbcore.Relation, error) {
+	if def, ok := s.namespaces[namespaceID]; ok {
+		for _, rel := range def.GetRelation() {
+			if rel.GetName() == name {
+				return rel, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s#%s", ErrUnknownRelation, namespaceID, name)
+}
+
+// validate returns an error unless the schema allows the subject of the
+// tuple on its relation, the relations computed from others are not
+// written to
+func (s *compiledSchema) validate(t relation.Tuple) error {
+	rel, err := s.relation(t.ResourceType, t.Relation)
+	if err != nil {
+		return err
+	}
+	if rel.GetUsersetRewrite() == nil {
+		for _, allowed := range rel.GetTypeInformation().GetAllowedDirectRelations() {
+			if allowed.GetNamespace() != t.SubjectType {
+				continue
+			}
+			switch {
+			case t.SubjectID == "*":
+				if allowed.GetPublicWildcard() != nil {
+					return nil
+				}
+			case t.SubjectRelation == "":
+				if allowed.GetRelation() == "..." {
+					return nil
+				}
+			case allowed.GetRelation() == t.SubjectRelation:
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidTuple, t)
+}
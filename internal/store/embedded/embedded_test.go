@@ -0,0 +1,274 @@
+package embedded_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/store/embedded"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu         sync.Mutex
+	schemaText string
+	tuples     map[relation.Tuple]bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{tuples: map[relation.Tuple]bool{}}
+}
+
+func matches(flt, t relation.Tuple) bool {
+	for _, f := range [][2]string{
+		{flt.ResourceType, t.ResourceType},
+		{flt.ResourceID, t.ResourceID},
+		{flt.Relation, t.Relation},
+		{flt.SubjectType, t.SubjectType},
+		{flt.SubjectID, t.SubjectID},
+		{flt.SubjectRelation, t.SubjectRelation},
+	} {
+		if f[0] != "" && f[0] != f[1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *memoryStore) ReadSchema(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schemaText, nil
+}
+
+func (s *memoryStore) WriteSchema(ctx context.Context, schemaText string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemaText = schemaText
+	return nil
+}
+
+func (s *memoryStore) Tuples(ctx context.Context, flt relation.Tuple) ([]relation.Tuple, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tuples []relation.Tuple
+	for t := range s.tuples {
+		if matches(flt, t) {
+			tuples = append(tuples, t)
+		}
+	}
+	sort.Slice(tuples, func(i, j int) bool { return tuples[i].String() < tuples[j].String() })
+	return tuples, nil
+}
+
+func (s *memoryStore) ResourceIDs(ctx context.Context, resourceType string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := map[string]bool{}
+	var ids []string
+	for t := range s.tuples {
+		if t.ResourceType == resourceType && !seen[t.ResourceID] {
+			seen[t.ResourceID] = true
+			ids = append(ids, t.ResourceID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *memoryStore) WriteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range tuples {
+		s.tuples[t] = true
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range tuples {
+		delete(s.tuples, t)
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteMatching(ctx context.Context, flt relation.Tuple) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for t := range s.tuples {
+		if matches(flt, t) {
+			delete(s.tuples, t)
+		}
+	}
+	return nil
+}
+
+func grant(namespaceID, objectID, roleID, subjectNamespace, subjectID string) relation.RelationV2 {
+	return relation.RelationV2{
+		Object:  relation.Object{ID: objectID, NamespaceID: namespaceID},
+		Subject: relation.Subject{ID: subjectID, Namespace: subjectNamespace, RoleID: namespaceID + ":" + roleID},
+	}
+}
+
+func check(ctx context.Context, repo *embedded.RelationRepository, namespaceID, objectID, permission, userID string) (bool, error) {
+	return repo.Check(ctx, relation.Relation{
+		ObjectNamespace:  namespace.Namespace{ID: namespaceID},
+		ObjectID:         objectID,
+		SubjectNamespace: namespace.Namespace{ID: schema.UserPrincipal},
+		SubjectID:        userID,
+	}, action.Action{ID: permission})
+}
+
+func TestEngine(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	engine := embedded.New(store)
+	relations := embedded.NewRelationRepository(engine)
+	policies := embedded.NewPolicyRepository(engine)
+
+	require.NoError(t, policies.WriteSchema(ctx, schema.PreDefinedSystemNamespaceConfig))
+	for _, rel := range []relation.RelationV2{
+		grant(schema.OrganizationNamespace, "org-1", "owner", schema.UserPrincipal, "alice"),
+		grant(schema.ProjectNamespace, "project-1", "organization", schema.OrganizationNamespace, "org-1"),
+		grant(schema.ProjectNamespace, "project-2", "organization", schema.OrganizationNamespace, "org-1"),
+		grant(schema.GroupNamespace, "group-1", "member", schema.UserPrincipal, "bob"),
+		grant(schema.ProjectNamespace, "project-1", "viewer", schema.GroupPrincipal, "group-1"),
+	} {
+		require.NoError(t, relations.AddV2(ctx, rel))
+	}
+
+	t.Run("should resolve the permissions through the parents and the groups", func(t *testing.T) {
+		for _, tc := range []struct {
+			objectID   string
+			permission string
+			userID     string
+			want       bool
+		}{
+			{"project-1", "edit", "alice", true},
+			{"project-2", "delete", "alice", true},
+			{"project-1", "view", "bob", true},
+			{"project-1", "edit", "bob", false},
+			{"project-2", "view", "bob", false},
+			{"project-1", "view", "carol", false},
+		} {
+			got, err := check(ctx, relations, schema.ProjectNamespace, tc.objectID, tc.permission, tc.userID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got, "%s %s %s", tc.userID, tc.permission, tc.objectID)
+		}
+	})
+
+	t.Run("should reject the tuples and the checks the schema doesn't have", func(t *testing.T) {
+		err := relations.AddV2(ctx, grant(schema.ProjectNamespace, "project-1", "organization", schema.UserPrincipal, "bob"))
+		assert.ErrorIs(t, err, embedded.ErrInvalidTuple)
+		err = relations.AddV2(ctx, grant(schema.ProjectNamespace, "project-1", "edit", schema.UserPrincipal, "bob"))
+		assert.ErrorIs(t, err, embedded.ErrInvalidTuple)
+		_, err = check(ctx, relations, schema.ProjectNamespace, "project-1", "publish", "bob")
+		assert.ErrorIs(t, err, embedded.ErrUnknownRelation)
+	})
+
+	t.Run("should look up the objects and expand the permissions", func(t *testing.T) {
+		ids, err := relations.LookupResources(ctx, schema.ProjectNamespace, "view", relation.Subject{ID: "alice", Namespace: schema.UserPrincipal})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"project-1", "project-2"}, ids)
+		ids, err = relations.LookupResources(ctx, schema.ProjectNamespace, "view", relation.Subject{ID: "bob", Namespace: schema.UserPrincipal})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"project-1"}, ids)
+
+		tree, err := relations.Expand(ctx, relation.Object{ID: "project-1", NamespaceID: schema.ProjectNamespace}, "view")
+		assert.NoError(t, err)
+		assert.Equal(t, relation.OperationUnion, tree.Operation)
+		assert.True(t, tree.Reaches(relation.Subject{ID: "group-1", Namespace: schema.GroupPrincipal, RoleID: "membership"}))
+		assert.True(t, tree.Reaches(relation.Subject{ID: "alice", Namespace: schema.UserPrincipal}))
+	})
+
+	t.Run("should grant the role of a conditional policy when the check meets the condition", func(t *testing.T) {
+		conditional := policy.Policy{
+			ID:          "9f1c-42",
+			NamespaceID: schema.ProjectNamespace,
+			RoleID:      schema.ProjectNamespace + ":viewer",
+			ActionID:    "delete." + schema.ProjectNamespace,
+			Condition:   `ip.in_cidr("10.0.0.0/8")`,
+		}
+		require.NoError(t, policies.Add(ctx, []policy.Policy{conditional}))
+
+		// the condition isn't met without the ip
+		got, err := check(ctx, relations, schema.ProjectNamespace, "project-1", "delete", "bob")
+		assert.NoError(t, err)
+		assert.False(t, got)
+		internalCtx := relation.WithCheckContext(ctx, relation.CheckContext{Request: map[string]string{relation.CheckContextIP: "10.1.2.3"}})
+		got, err = check(internalCtx, relations, schema.ProjectNamespace, "project-1", "delete", "bob")
+		assert.NoError(t, err)
+		assert.True(t, got)
+
+		// the objects created after the policy are given the condition
+		require.NoError(t, relations.AddV2(ctx, grant(schema.ProjectNamespace, "project-3", "viewer", schema.UserPrincipal, "bob")))
+		got, err = check(internalCtx, relations, schema.ProjectNamespace, "project-3", "delete", "bob")
+		assert.NoError(t, err)
+		assert.False(t, got)
+		require.NoError(t, policies.AddObject(ctx, []policy.Policy{conditional}, "project-3"))
+		got, err = check(internalCtx, relations, schema.ProjectNamespace, "project-3", "delete", "bob")
+		assert.NoError(t, err)
+		assert.True(t, got)
+
+		require.NoError(t, policies.Remove(ctx, []policy.Policy{conditional}))
+		got, err = check(internalCtx, relations, schema.ProjectNamespace, "project-1", "delete", "bob")
+		assert.NoError(t, err)
+		assert.False(t, got)
+		tuples, err := store.Tuples(ctx, relation.Tuple{ResourceType: schema.ProjectNamespace, Relation: "condition_9f1c42"})
+		assert.NoError(t, err)
+		assert.Empty(t, tuples)
+	})
+
+	t.Run("should remove the relations of the objects", func(t *testing.T) {
+		require.NoError(t, relations.DeleteV2(ctx, grant(schema.GroupNamespace, "group-1", "member", schema.UserPrincipal, "bob")))
+		got, err := check(ctx, relations, schema.ProjectNamespace, "project-1", "view", "bob")
+		assert.NoError(t, err)
+		assert.False(t, got)
+
+		require.NoError(t, relations.DeleteSubjectRelations(ctx, schema.ProjectNamespace, "project-1"))
+		got, err = check(ctx, relations, schema.ProjectNamespace, "project-1", "view", "alice")
+		assert.NoError(t, err)
+		assert.False(t, got)
+		got, err = check(ctx, relations, schema.ProjectNamespace, "project-2", "view", "alice")
+		assert.NoError(t, err)
+		assert.True(t, got)
+	})
+}
+
+func TestEngineNestedGroups(t *testing.T) {
+	ctx := context.Background()
+	engine := embedded.New(newMemoryStore())
+	relations := embedded.NewRelationRepository(engine)
+	policies := embedded.NewPolicyRepository(engine)
+
+	require.NoError(t, policies.WriteSchema(ctx, schema.PreDefinedSystemNamespaceConfig))
+	for _, rel := range []relation.RelationV2{
+		grant(schema.GroupNamespace, "infra", "member", schema.UserPrincipal, "bob"),
+		grant(schema.GroupNamespace, "platform", "member", schema.GroupPrincipal, "infra"),
+		grant(schema.GroupNamespace, "engineering", "member", schema.GroupPrincipal, "platform"),
+		grant(schema.ProjectNamespace, "project-1", "viewer", schema.GroupPrincipal, "engineering"),
+	} {
+		require.NoError(t, relations.AddV2(ctx, rel))
+	}
+
+	got, err := check(ctx, relations, schema.ProjectNamespace, "project-1", "view", "bob")
+	assert.NoError(t, err)
+	assert.True(t, got)
+	got, err = check(ctx, relations, schema.GroupNamespace, "engineering", "membership", "bob")
+	assert.NoError(t, err)
+	assert.True(t, got)
+
+	require.NoError(t, relations.DeleteV2(ctx, grant(schema.GroupNamespace, "platform", "member", schema.GroupPrincipal, "infra")))
+	got, err = check(ctx, relations, schema.ProjectNamespace, "project-1", "view", "bob")
+	assert.NoError(t, err)
+	assert.False(t, got)
+}
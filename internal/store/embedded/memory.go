@@ -0,0 +1,108 @@
+package embedded
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/odpf/shield/core/relation"
+)
+
+// MemoryStore keeps the schema and the tuples in memory, for the engines
+// living as long as a command like the ones running the policy tests
+type MemoryStore struct {
+	mu         sync.Mutex
+	schemaText string
+	tuples     map[relation.Tuple]bool
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tuples: map[relation.Tuple]bool{}}
+}
+
+// matches tells whether the tuple has the fields set in the filter
+func matches(flt, t relation.Tuple) bool {
+	for _, f := range [][2]string{
+		{flt.ResourceType, t.ResourceType},
+		{flt.ResourceID, t.ResourceID},
+		{flt.Relation, t.Relation},
+		{flt.SubjectType, t.SubjectType},
+		{flt.SubjectID, t.SubjectID},
+		{flt.SubjectRelation, t.SubjectRelation},
+	} {
+		if f[0] != "" && f[0] != f[1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *MemoryStore) ReadSchema(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schemaText, nil
+}
+
+func (s *MemoryStore) WriteSchema(ctx context.Context, schemaText string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemaText = schemaText
+	return nil
+}
+
+func (s *MemoryStore) Tuples(ctx context.Context, flt relation.Tuple) ([]relation.Tuple, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tuples []relation.Tuple
+	for t := range s.tuples {
+		if matches(flt, t) {
+			tuples = append(tuples, t)
+		}
+	}
+	sort.Slice(tuples, func(i, j int) bool { return tuples[i].String() < tuples[j].String() })
+	return tuples, nil
+}
+
+func (s *MemoryStore) ResourceIDs(ctx context.Context, resourceType string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := map[string]bool{}
+	var ids []string
+	for t := range s.tuples {
+		if t.ResourceType == resourceType && !seen[t.ResourceID] {
+			seen[t.ResourceID] = true
+			ids = append(ids, t.ResourceID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *MemoryStore) WriteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range tuples {
+		s.tuples[t] = true
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range tuples {
+		delete(s.tuples, t)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteMatching(ctx context.Context, flt relation.Tuple) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for t := range s.tuples {
+		if matches(flt, t) {
+			delete(s.tuples, t)
+		}
+	}
+	return nil
+}
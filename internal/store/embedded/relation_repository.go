@@ -0,0 +1,206 @@
+package embedded
+
+import (
+	"context"
+	"strings"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
+)
+
+// RelationRepository writes the relations as tuples of the store and
+// checks them, the tuples are read at their latest state so the
+// consistency asked for by the checks is always met
+type RelationRepository struct {
+	engine *Engine
+}
+
+func NewRelationRepository(engine *Engine) *RelationRepository {
+	return &RelationRepository{
+		engine: engine,
+	}
+}
+
+func tupleOf(resourceType, resourceID, rel, subjectType, subjectID, subjectRelation string) relation.Tuple {
+	return relation.Tuple{
+		ResourceType:    resourceType,
+		ResourceID:      resourceID,
+		Relation:        rel,
+		SubjectType:     subjectType,
+		SubjectID:       subjectID,
+		SubjectRelation: subjectRelation,
+	}
+}
+
+func (r RelationRepository) Add(ctx context.Context, rel relation.Relation) error {
+	relationship, err := schema_generator.TransformRelation(rel)
+	if err != nil {
+		return err
+	}
+	return r.Import(ctx, []relation.Tuple{tupleOf(
+		relationship.GetResource().GetObjectType(),
+		relationship.GetResource().GetObjectId(),
+		relationship.GetRelation(),
+		relationship.GetSubject().GetObject().GetObjectType(),
+		relationship.GetSubject().GetObject().GetObjectId(),
+		relationship.GetSubject().GetOptionalRelation(),
+	)})
+}
+
+func getRelation(a string) string {
+	if a == schema.GroupPrincipal {
+		return "membership"
+	}
+
+	return ""
+}
+
+func (r RelationRepository) AddV2(ctx context.Context, rel relation.RelationV2) error {
+	return r.Import(ctx, []relation.Tuple{tupleOf(
+		rel.Object.NamespaceID,
+		rel.Object.ID,
+		schema.GetRoleName(rel.Subject.RoleID),
+		rel.Subject.Namespace,
+		rel.Subject.ID,
+		getRelation(rel.Subject.Namespace),
+	)})
+}
+
+func (r RelationRepository) Check(ctx context.Context, rel relation.Relation, act action.Action) (bool, error) {
+	relationship, err := schema_generator.TransformCheckRelation(rel)
+	if err != nil {
+		return false, err
+	}
+	compiled, err := r.engine.schema(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return newResolver(r.engine.store, compiled, relation.CheckContextFromContext(ctx)).check(ctx,
+		relationship.GetResource().GetObjectType(),
+		relationship.GetResource().GetObjectId(),
+		act.ID,
+		subjectRef{
+			namespace: relationship.GetSubject().GetObject().GetObjectType(),
+			id:        relationship.GetSubject().GetObject().GetObjectId(),
+			relation:  relationship.GetSubject().GetOptionalRelation(),
+		}, 0)
+}
+
+// Expand expands the permission or relation of the object into the tree
+// of the relations granting it, subject sets are left unexpanded
+func (r RelationRepository) Expand(ctx context.Context, obj relation.Object, permission string) (relation.AccessTree, error) {
+	compiled, err := r.engine.schema(ctx)
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+	return newResolver(r.engine.store, compiled, relation.CheckContext{}).expand(ctx,
+		strings.ReplaceAll(obj.NamespaceID, "-", "_"), obj.ID, permission, 0)
+}
+
+// LookupResources returns the ids of the objects of the namespace the
+// subject has the permission on, every object of the namespace having a
+// tuple is checked. The objects the subject only has it on under a
+// condition the context doesn't meet are left out.
+func (r RelationRepository) LookupResources(ctx context.Context, namespaceID, permission string, sub relation.Subject) ([]string, error) {
+	compiled, err := r.engine.schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	namespaceID = strings.ReplaceAll(namespaceID, "-", "_")
+	objectIDs, err := r.engine.store.ResourceIDs(ctx, namespaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the objects share the relations of their parents, so they are
+	// checked with a single resolver
+	res := newResolver(r.engine.store, compiled, relation.CheckContextFromContext(ctx))
+	subject := subjectRef{
+		namespace: strings.ReplaceAll(sub.Namespace, "-", "_"),
+		id:        sub.ID,
+		relation:  sub.RoleID,
+	}
+	var ids []string
+	for _, id := range objectIDs {
+		allowed, err := res.check(ctx, namespaceID, id, permission, subject, 0)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r RelationRepository) Delete(ctx context.Context, rel relation.Relation) error {
+	relationship, err := schema_generator.TransformRelation(rel)
+	if err != nil {
+		return err
+	}
+	return r.engine.store.DeleteMatching(ctx, relation.Tuple{
+		ResourceType: relationship.GetResource().GetObjectType(),
+		ResourceID:   relationship.GetResource().GetObjectId(),
+		Relation:     relationship.GetRelation(),
+		SubjectType:  relationship.GetSubject().GetObject().GetObjectType(),
+		SubjectID:    relationship.GetSubject().GetObject().GetObjectId(),
+	})
+}
+
+func (r RelationRepository) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	relationship, err := schema_generator.TransformRelationV2(rel)
+	if err != nil {
+		return err
+	}
+	return r.engine.store.DeleteMatching(ctx, relation.Tuple{
+		ResourceType: relationship.GetResource().GetObjectType(),
+		ResourceID:   relationship.GetResource().GetObjectId(),
+		Relation:     relationship.GetRelation(),
+		SubjectType:  relationship.GetSubject().GetObject().GetObjectType(),
+		SubjectID:    relationship.GetSubject().GetObject().GetObjectId(),
+	})
+}
+
+func (r RelationRepository) DeleteSubjectRelations(ctx context.Context, resourceType, optionalResourceID string) error {
+	return r.engine.store.DeleteMatching(ctx, relation.Tuple{
+		ResourceType: resourceType,
+		ResourceID:   optionalResourceID,
+	})
+}
+
+// Export streams every tuple on the objects of the namespace to fn
+func (r RelationRepository) Export(ctx context.Context, resourceType string, fn func(relation.Tuple) error) error {
+	tuples, err := r.engine.store.Tuples(ctx, relation.Tuple{ResourceType: resourceType})
+	if err != nil {
+		return err
+	}
+	for _, t := range tuples {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import writes the tuples the schema allows, existing tuples are left as
+// they are so an import can be retried
+func (r RelationRepository) Import(ctx context.Context, tuples []relation.Tuple) error {
+	compiled, err := r.engine.schema(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range tuples {
+		if err := compiled.validate(t); err != nil {
+			return err
+		}
+	}
+	return r.engine.store.WriteTuples(ctx, tuples)
+}
+
+// DeleteTuples removes the tuples, tuples which don't exist are skipped
+func (r RelationRepository) DeleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	return r.engine.store.DeleteTuples(ctx, tuples)
+}
@@ -0,0 +1,325 @@
+package embedded
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/authzed/spicedb/pkg/caveats"
+	sdbcore "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
+)
+
+// maxDepth is how many relations deep a check follows, the default of
+// spicedb
+const maxDepth = 50
+
+// subjectRef is the subject checked, relation is set for a subject set
+// like the members of a group
+type subjectRef struct {
+	namespace string
+	id        string
+	relation  string
+}
+
+type checkKey struct {
+	namespace string
+	id        string
+	relation  string
+	subject   subjectRef
+}
+
+// resolver resolves the checks of a single call, the tuples read and the
+// results are kept for the checks after as the permissions of a schema
+// mostly lead to the same relations
+type resolver struct {
+	store      Store
+	schema     *compiledSchema
+	context    map[string]interface{}
+	tuples     map[relation.Tuple][]relation.Tuple
+	results    map[checkKey]bool
+	conditions map[string]bool
+}
+
+func newResolver(store Store, schema *compiledSchema, checkCtx relation.CheckContext) *resolver {
+	return &resolver{
+		store:      store,
+		schema:     schema,
+		context:    schema_generator.ConditionContext(checkCtx),
+		tuples:     map[relation.Tuple][]relation.Tuple{},
+		results:    map[checkKey]bool{},
+		conditions: map[string]bool{},
+	}
+}
+
+// read returns the tuples of the relation of the object
+func (r *resolver) read(ctx context.Context, namespaceID, id, rel string) ([]relation.Tuple, error) {
+	flt := relation.Tuple{ResourceType: namespaceID, ResourceID: id, Relation: rel}
+	if tuples, ok := r.tuples[flt]; ok {
+		return tuples, nil
+	}
+	tuples, err := r.store.Tuples(ctx, flt)
+	if err != nil {
+		return nil, err
+	}
+	r.tuples[flt] = tuples
+	return tuples, nil
+}
+
+// check tells whether the subject has the relation or permission on the
+// object
+func (r *resolver) check(ctx context.Context, namespaceID, id, rel string, sub subjectRef, depth int) (bool, error) {
+	if depth > maxDepth {
+		return false, ErrMaxDepth
+	}
+	key := checkKey{namespace: namespaceID, id: id, relation: rel, subject: sub}
+	if allowed, ok := r.results[key]; ok {
+		return allowed, nil
+	}
+
+	def, err := r.schema.relation(namespaceID, rel)
+	if err != nil {
+		return false, err
+	}
+	var allowed bool
+	if rewrite := def.GetUsersetRewrite(); rewrite != nil {
+		allowed, err = r.rewrite(ctx, namespaceID, id, rel, rewrite, sub, depth)
+	} else {
+		allowed, err = r.direct(ctx, namespaceID, id, rel, sub, depth)
+	}
+	if err != nil {
+		return false, err
+	}
+	r.results[key] = allowed
+	return allowed, nil
+}
+
+// direct checks the tuples of the relation, the subject sets among them
+// are checked for the subject in turn
+func (r *resolver) direct(ctx context.Context, namespaceID, id, rel string, sub subjectRef, depth int) (bool, error) {
+	tuples, err := r.read(ctx, namespaceID, id, rel)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tuples {
+		if t.SubjectType == sub.namespace && t.SubjectRelation == sub.relation &&
+			(t.SubjectID == sub.id || (t.SubjectID == "*" && sub.relation == "")) {
+			met, err := r.condition(t.Relation)
+			if err != nil {
+				return false, err
+			}
+			if met {
+				return true, nil
+			}
+			continue
+		}
+		if t.SubjectRelation == "" {
+			continue
+		}
+		allowed, err := r.check(ctx, t.SubjectType, t.SubjectID, t.SubjectRelation, sub, depth+1)
+		if err != nil {
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// condition tells whether the condition of a condition relation is met in
+// the context of the check, the other relations have no condition. A
+// condition using a parameter the context doesn't have is not met.
+func (r *resolver) condition(rel string) (bool, error) {
+	caveat := schema_generator.ConditionCaveat(rel)
+	if caveat == nil {
+		return true, nil
+	}
+	if met, ok := r.conditions[caveat.GetCaveatName()]; ok {
+		return met, nil
+	}
+
+	compiled, ok := r.schema.caveats[caveat.GetCaveatName()]
+	if !ok {
+		return false, fmt.Errorf("%w: caveat %s doesn't exist", ErrInvalidSchema, caveat.GetCaveatName())
+	}
+	params, err := caveats.ConvertContextToParameters(r.context, compiled.parameters, caveats.SkipUnknownParameters)
+	if err != nil {
+		return false, err
+	}
+	result, err := caveats.EvaluateCaveat(compiled.caveat, params)
+	if err != nil {
+		return false, err
+	}
+	r.conditions[caveat.GetCaveatName()] = result.Value()
+	return result.Value(), nil
+}
+
+func (r *resolver) rewrite(ctx context.Context, namespaceID, id, rel string, rewrite *sdbcore.UsersetRewrite, sub subjectRef, depth int) (bool, error) {
+	switch {
+	case rewrite.GetIntersection() != nil:
+		children := rewrite.GetIntersection().GetChild()
+		for _, c := range children {
+			allowed, err := r.child(ctx, namespaceID, id, rel, c, sub, depth)
+			if err != nil || !allowed {
+				return false, err
+			}
+		}
+		return len(children) > 0, nil
+	case rewrite.GetExclusion() != nil:
+		children := rewrite.GetExclusion().GetChild()
+		if len(children) == 0 {
+			return false, nil
+		}
+		allowed, err := r.child(ctx, namespaceID, id, rel, children[0], sub, depth)
+		if err != nil || !allowed {
+			return false, err
+		}
+		for _, c := range children[1:] {
+			excluded, err := r.child(ctx, namespaceID, id, rel, c, sub, depth)
+			if err != nil || excluded {
+				return false, err
+			}
+		}
+		return true, nil
+	default:
+		for _, c := range rewrite.GetUnion().GetChild() {
+			allowed, err := r.child(ctx, namespaceID, id, rel, c, sub, depth)
+			if err != nil || allowed {
+				return allowed, err
+			}
+		}
+		return false, nil
+	}
+}
+
+func (r *resolver) child(ctx context.Context, namespaceID, id, rel string, c *sdbcore.SetOperation_Child, sub subjectRef, depth int) (bool, error) {
+	switch {
+	case c.GetXThis() != nil:
+		return r.direct(ctx, namespaceID, id, rel, sub, depth)
+	case c.GetComputedUserset() != nil:
+		return r.check(ctx, namespaceID, id, c.GetComputedUserset().GetRelation(), sub, depth+1)
+	case c.GetTupleToUserset() != nil:
+		ttu := c.GetTupleToUserset()
+		tuples, err := r.read(ctx, namespaceID, id, ttu.GetTupleset().GetRelation())
+		if err != nil {
+			return false, err
+		}
+		for _, t := range tuples {
+			// the arrow is followed to the objects having the relation
+			if _, err := r.schema.relation(t.SubjectType, ttu.GetComputedUserset().GetRelation()); err != nil {
+				continue
+			}
+			allowed, err := r.check(ctx, t.SubjectType, t.SubjectID, ttu.GetComputedUserset().GetRelation(), sub, depth+1)
+			if err != nil || allowed {
+				return allowed, err
+			}
+		}
+		return false, nil
+	case c.GetUsersetRewrite() != nil:
+		return r.rewrite(ctx, namespaceID, id, rel, c.GetUsersetRewrite(), sub, depth+1)
+	default:
+		return false, nil
+	}
+}
+
+// expand expands the relation or permission of the object into the tree of
+// the relations granting it, subject sets are left unexpanded
+func (r *resolver) expand(ctx context.Context, namespaceID, id, rel string, depth int) (relation.AccessTree, error) {
+	if depth > maxDepth {
+		return relation.AccessTree{}, ErrMaxDepth
+	}
+	def, err := r.schema.relation(namespaceID, rel)
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+	if rewrite := def.GetUsersetRewrite(); rewrite != nil {
+		return r.expandRewrite(ctx, namespaceID, id, rel, rewrite, depth)
+	}
+	return r.leaf(ctx, namespaceID, id, rel)
+}
+
+func (r *resolver) leaf(ctx context.Context, namespaceID, id, rel string) (relation.AccessTree, error) {
+	tuples, err := r.read(ctx, namespaceID, id, rel)
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+	tree := relation.AccessTree{
+		Object:   relation.Object{ID: id, NamespaceID: namespaceID},
+		Relation: rel,
+	}
+	for _, t := range tuples {
+		tree.Subjects = append(tree.Subjects, relation.Subject{
+			ID:        t.SubjectID,
+			Namespace: t.SubjectType,
+			RoleID:    t.SubjectRelation,
+		})
+	}
+	return tree, nil
+}
+
+func (r *resolver) expandRewrite(ctx context.Context, namespaceID, id, rel string, rewrite *sdbcore.UsersetRewrite, depth int) (relation.AccessTree, error) {
+	tree := relation.AccessTree{
+		Object:   relation.Object{ID: id, NamespaceID: namespaceID},
+		Relation: rel,
+	}
+	var children []*sdbcore.SetOperation_Child
+	switch {
+	case rewrite.GetIntersection() != nil:
+		tree.Operation = relation.OperationIntersection
+		children = rewrite.GetIntersection().GetChild()
+	case rewrite.GetExclusion() != nil:
+		tree.Operation = relation.OperationExclusion
+		children = rewrite.GetExclusion().GetChild()
+	default:
+		tree.Operation = relation.OperationUnion
+		children = rewrite.GetUnion().GetChild()
+	}
+
+	for _, c := range children {
+		var child relation.AccessTree
+		var err error
+		switch {
+		case c.GetXThis() != nil:
+			child, err = r.leaf(ctx, namespaceID, id, rel)
+		case c.GetComputedUserset() != nil:
+			child, err = r.expand(ctx, namespaceID, id, c.GetComputedUserset().GetRelation(), depth+1)
+		case c.GetTupleToUserset() != nil:
+			child, err = r.expandArrow(ctx, namespaceID, id, c.GetTupleToUserset(), depth)
+		case c.GetUsersetRewrite() != nil:
+			child, err = r.expandRewrite(ctx, namespaceID, id, rel, c.GetUsersetRewrite(), depth+1)
+		default:
+			child = relation.AccessTree{Object: tree.Object, Relation: rel, Operation: relation.OperationUnion}
+		}
+		if err != nil {
+			return relation.AccessTree{}, err
+		}
+		tree.Children = append(tree.Children, child)
+	}
+	return tree, nil
+}
+
+// expandArrow is the union of the expansions of the computed relation on
+// the objects of the tupleset relation
+func (r *resolver) expandArrow(ctx context.Context, namespaceID, id string, ttu *sdbcore.TupleToUserset, depth int) (relation.AccessTree, error) {
+	tree := relation.AccessTree{
+		Object:    relation.Object{ID: id, NamespaceID: namespaceID},
+		Relation:  ttu.GetTupleset().GetRelation(),
+		Operation: relation.OperationUnion,
+	}
+	tuples, err := r.read(ctx, namespaceID, id, ttu.GetTupleset().GetRelation())
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+	for _, t := range tuples {
+		if _, err := r.schema.relation(t.SubjectType, ttu.GetComputedUserset().GetRelation()); err != nil {
+			continue
+		}
+		child, err := r.expand(ctx, t.SubjectType, t.SubjectID, ttu.GetComputedUserset().GetRelation(), depth+1)
+		if err != nil {
+			return relation.AccessTree{}, err
+		}
+		tree.Children = append(tree.Children, child)
+	}
+	return tree, nil
+}
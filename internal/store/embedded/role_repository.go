@@ -0,0 +1,44 @@
+package embedded
+
+import (
+	"context"
+
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
+)
+
+// RoleRepository keeps the custom roles of the organizations in the schema,
+// the schema written from the namespace configs only has the predefined roles
+type RoleRepository struct {
+	engine *Engine
+}
+
+func NewRoleRepository(engine *Engine) *RoleRepository {
+	return &RoleRepository{
+		engine: engine,
+	}
+}
+
+func (r RoleRepository) Add(ctx context.Context, roles []role.Role) error {
+	return r.applyRoles(ctx, nil, roles)
+}
+
+func (r RoleRepository) Remove(ctx context.Context, roles []role.Role) error {
+	return r.applyRoles(ctx, roles, nil)
+}
+
+func (r RoleRepository) applyRoles(ctx context.Context, toRemove, toAdd []role.Role) error {
+	previousSchema, err := r.engine.store.ReadSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	updatedSchema, err := schema_generator.ApplyRoles(previousSchema, toRemove, toAdd)
+	if err != nil {
+		return err
+	}
+	if _, err := compile(updatedSchema); err != nil {
+		return err
+	}
+	return r.engine.store.WriteSchema(ctx, updatedSchema)
+}
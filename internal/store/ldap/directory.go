@@ -0,0 +1,115 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/odpf/shield/core/directory"
+)
+
+// Directory reads the users and groups of an ldap or active directory
+// server, each read binds a connection of its own
+type Directory struct {
+	config directory.Config
+}
+
+func NewDirectory(config directory.Config) *Directory {
+	return &Directory{config: config}
+}
+
+func (d Directory) Users(ctx context.Context) ([]directory.User, error) {
+	cfg := d.config.Users
+	entries, err := d.search(ctx, cfg.BaseDN, cfg.Filter, []string{cfg.IDAttribute, cfg.EmailAttribute, cfg.NameAttribute})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]directory.User, 0, len(entries))
+	for _, e := range entries {
+		users = append(users, newUser(cfg, e))
+	}
+	return users, nil
+}
+
+func (d Directory) Groups(ctx context.Context) ([]directory.Group, error) {
+	cfg := d.config.Groups
+	entries, err := d.search(ctx, cfg.BaseDN, cfg.Filter, []string{cfg.NameAttribute, cfg.MemberAttribute})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]directory.Group, 0, len(entries))
+	for _, e := range entries {
+		groups = append(groups, newGroup(cfg, e))
+	}
+	return groups, nil
+}
+
+// search returns the entries under the base dn matching the filter, they
+// are read PageSize entries at a time when it is set as active directory
+// returns at most 1000 entries of a search otherwise
+func (d Directory) search(ctx context.Context, baseDN, filter string, attributes []string) ([]*ldap.Entry, error) {
+	conn, err := ldap.DialURL(d.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: d.config.Timeout}),
+		ldap.DialWithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetTimeout(d.config.Timeout)
+
+	// the operations of the connection don't take the context, closing the
+	// connection ends them
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	if d.config.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(d.config.BindDN, d.config.BindPassword)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req := ldap.NewSearchRequest(baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil)
+	var res *ldap.SearchResult
+	if d.config.PageSize > 0 {
+		res, err = conn.SearchWithPaging(req, uint32(d.config.PageSize))
+	} else {
+		res, err = conn.Search(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res.Entries, nil
+}
+
+// newUser is the user of the entry, attribute names are case insensitive
+// so the values are looked up ignoring the case of the configured names
+func newUser(cfg directory.UsersConfig, e *ldap.Entry) directory.User {
+	return directory.User{
+		DN:    e.DN,
+		ID:    e.GetEqualFoldAttributeValue(cfg.IDAttribute),
+		Email: e.GetEqualFoldAttributeValue(cfg.EmailAttribute),
+		Name:  e.GetEqualFoldAttributeValue(cfg.NameAttribute),
+	}
+}
+
+func newGroup(cfg directory.GroupsConfig, e *ldap.Entry) directory.Group {
+	return directory.Group{
+		DN:      e.DN,
+		Name:    e.GetEqualFoldAttributeValue(cfg.NameAttribute),
+		Members: e.GetEqualFoldAttributeValues(cfg.MemberAttribute),
+	}
+}
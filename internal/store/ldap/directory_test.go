@@ -0,0 +1,39 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/odpf/shield/core/directory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUser(t *testing.T) {
+	cfg := directory.UsersConfig{IDAttribute: "uid", EmailAttribute: "mail", NameAttribute: "cn"}
+	e := ldap.NewEntry("uid=alice,dc=odpf,dc=io", map[string][]string{
+		"uid":  {"alice"},
+		"Mail": {"alice@odpf.io", "a@odpf.io"},
+		"CN":   {"Alice"},
+	})
+
+	assert.Equal(t, directory.User{
+		DN:    "uid=alice,dc=odpf,dc=io",
+		ID:    "alice",
+		Email: "alice@odpf.io",
+		Name:  "Alice",
+	}, newUser(cfg, e))
+}
+
+func TestNewGroup(t *testing.T) {
+	cfg := directory.GroupsConfig{NameAttribute: "cn", MemberAttribute: "member"}
+	e := ldap.NewEntry("cn=data,dc=odpf,dc=io", map[string][]string{
+		"cn":     {"data"},
+		"Member": {"uid=alice,dc=odpf,dc=io", "uid=bob,dc=odpf,dc=io"},
+	})
+
+	assert.Equal(t, directory.Group{
+		DN:      "cn=data,dc=odpf,dc=io",
+		Name:    "data",
+		Members: []string{"uid=alice,dc=odpf,dc=io", "uid=bob,dc=odpf,dc=io"},
+	}, newGroup(cfg, e))
+}
@@ -0,0 +1,17 @@
+package openfga
+
+import "time"
+
+type Config struct {
+	// APIURL is the http api of OpenFGA, like http://localhost:8080
+	APIURL string `yaml:"api_url" mapstructure:"api_url"`
+	// StoreID is the store the tuples are kept in, the store named
+	// StoreName is used, and created if missing, when it is not set
+	StoreID   string `yaml:"store_id" mapstructure:"store_id"`
+	StoreName string `yaml:"store_name" mapstructure:"store_name" default:"shield"`
+	// APIToken is the pre shared key OpenFGA authenticates the requests
+	// with, a value or a reference to a secret
+	APIToken string `yaml:"api_token" mapstructure:"api_token"`
+	// Timeout of the requests to OpenFGA
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" default:"10s"`
+}
@@ -0,0 +1,229 @@
+package openfga
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/caveats"
+	sdbcore "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
+	fgasdk "github.com/openfga/go-sdk"
+)
+
+var ErrUnsupportedSchema = errors.New("schema can't be translated into an openfga model")
+
+// conditionName is the name of the condition of OpenFGA the caveat is
+// translated into
+func conditionName(caveatName string) string {
+	return strings.ReplaceAll(caveatName, "/", "_")
+}
+
+// conditionOf is the condition the tuples of the relation are written
+// under, empty if it is not a condition relation
+func conditionOf(relationName string) string {
+	if caveat := schema_generator.ConditionCaveat(relationName); caveat != nil {
+		return conditionName(caveat.GetCaveatName())
+	}
+	return ""
+}
+
+// translate translates the schema text into the authorization model it
+// evaluates the same permissions as
+func translate(schemaText string) (fgasdk.WriteAuthorizationModelRequest, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaText,
+	}, nil)
+	if err != nil {
+		return fgasdk.WriteAuthorizationModelRequest{}, fmt.Errorf("%w: %s", ErrUnsupportedSchema, err)
+	}
+
+	model := fgasdk.WriteAuthorizationModelRequest{SchemaVersion: "1.1", TypeDefinitions: []fgasdk.TypeDefinition{}}
+	for _, def := range compiled.ObjectDefinitions {
+		typeDef := fgasdk.TypeDefinition{Type: def.GetName()}
+		relations := map[string]fgasdk.Userset{}
+		metadata := map[string]fgasdk.RelationMetadata{}
+		for _, rel := range def.GetRelation() {
+			us, err := relationUserset(rel)
+			if err != nil {
+				return fgasdk.WriteAuthorizationModelRequest{}, fmt.Errorf("%s#%s: %w", def.GetName(), rel.GetName(), err)
+			}
+			relations[rel.GetName()] = us
+
+			allowed := rel.GetTypeInformation().GetAllowedDirectRelations()
+			if len(allowed) == 0 {
+				continue
+			}
+			var refs []fgasdk.RelationReference
+			for _, a := range allowed {
+				ref := fgasdk.RelationReference{Type: a.GetNamespace()}
+				if a.GetPublicWildcard() != nil {
+					ref.Wildcard = &map[string]interface{}{}
+				} else if a.GetRelation() != "..." {
+					ref.Relation = fgasdk.PtrString(a.GetRelation())
+				}
+				if a.GetRequiredCaveat() != nil {
+					ref.Condition = fgasdk.PtrString(conditionName(a.GetRequiredCaveat().GetCaveatName()))
+				}
+				refs = append(refs, ref)
+			}
+			metadata[rel.GetName()] = fgasdk.RelationMetadata{DirectlyRelatedUserTypes: &refs}
+		}
+		if len(relations) > 0 {
+			typeDef.Relations = &relations
+		}
+		if len(metadata) > 0 {
+			typeDef.Metadata = &fgasdk.Metadata{Relations: &metadata}
+		}
+		model.TypeDefinitions = append(model.TypeDefinitions, typeDef)
+	}
+
+	conditions := map[string]fgasdk.Condition{}
+	for _, def := range compiled.CaveatDefinitions {
+		cond, err := translateCaveat(def)
+		if err != nil {
+			return fgasdk.WriteAuthorizationModelRequest{}, fmt.Errorf("caveat %s: %w", def.GetName(), err)
+		}
+		conditions[cond.Name] = cond
+	}
+	if len(conditions) > 0 {
+		model.Conditions = &conditions
+	}
+	sort.Slice(model.TypeDefinitions, func(i, j int) bool {
+		return model.TypeDefinitions[i].Type < model.TypeDefinitions[j].Type
+	})
+	return model, nil
+}
+
+// relationUserset is the userset of the relation, the relations written to
+// directly are the tuples written to them
+func relationUserset(rel *sdbcore.Relation) (fgasdk.Userset, error) {
+	if rel.GetUsersetRewrite() == nil {
+		return fgasdk.Userset{This: &map[string]interface{}{}}, nil
+	}
+	return rewriteUserset(rel.GetUsersetRewrite())
+}
+
+func rewriteUserset(rewrite *sdbcore.UsersetRewrite) (fgasdk.Userset, error) {
+	var op *sdbcore.SetOperation
+	switch {
+	case rewrite.GetIntersection() != nil:
+		op = rewrite.GetIntersection()
+	case rewrite.GetExclusion() != nil:
+		op = rewrite.GetExclusion()
+	default:
+		op = rewrite.GetUnion()
+	}
+
+	var children []fgasdk.Userset
+	for _, c := range op.GetChild() {
+		var child fgasdk.Userset
+		switch {
+		case c.GetXThis() != nil:
+			child = fgasdk.Userset{This: &map[string]interface{}{}}
+		case c.GetComputedUserset() != nil:
+			child = fgasdk.Userset{ComputedUserset: &fgasdk.ObjectRelation{Relation: fgasdk.PtrString(c.GetComputedUserset().GetRelation())}}
+		case c.GetTupleToUserset() != nil:
+			child = fgasdk.Userset{TupleToUserset: &fgasdk.TupleToUserset{
+				Tupleset:        fgasdk.ObjectRelation{Relation: fgasdk.PtrString(c.GetTupleToUserset().GetTupleset().GetRelation())},
+				ComputedUserset: fgasdk.ObjectRelation{Relation: fgasdk.PtrString(c.GetTupleToUserset().GetComputedUserset().GetRelation())},
+			}}
+		case c.GetUsersetRewrite() != nil:
+			var err error
+			if child, err = rewriteUserset(c.GetUsersetRewrite()); err != nil {
+				return fgasdk.Userset{}, err
+			}
+		default:
+			// nil grants nothing, it is left out of the operation
+			continue
+		}
+		children = append(children, child)
+	}
+	if len(children) == 0 {
+		return fgasdk.Userset{}, fmt.Errorf("%w: operation without children", ErrUnsupportedSchema)
+	}
+
+	switch {
+	case len(children) == 1:
+		return children[0], nil
+	case rewrite.GetIntersection() != nil:
+		return fgasdk.Userset{Intersection: &fgasdk.Usersets{Child: children}}, nil
+	case rewrite.GetExclusion() != nil:
+		// OpenFGA subtracts a single userset, a - b - c is (a - b) - c
+		us := children[0]
+		for _, subtract := range children[1:] {
+			us = fgasdk.Userset{Difference: &fgasdk.Difference{Base: us, Subtract: subtract}}
+		}
+		return us, nil
+	default:
+		return fgasdk.Userset{Union: &fgasdk.Usersets{Child: children}}, nil
+	}
+}
+
+// translateCaveat translates the caveat into the condition of OpenFGA, both
+// evaluate the same cel expression
+func translateCaveat(def *sdbcore.CaveatDefinition) (fgasdk.Condition, error) {
+	caveat, err := caveats.DeserializeCaveat(def.GetSerializedExpression())
+	if err != nil {
+		return fgasdk.Condition{}, err
+	}
+	expression, err := caveat.ExprString()
+	if err != nil {
+		return fgasdk.Condition{}, err
+	}
+
+	parameters := map[string]fgasdk.ConditionParamTypeRef{}
+	cond := fgasdk.Condition{
+		Name:       conditionName(def.GetName()),
+		Expression: expression,
+		Parameters: &parameters,
+	}
+	for name, ref := range def.GetParameterTypes() {
+		param, err := translateParameterType(ref)
+		if err != nil {
+			return fgasdk.Condition{}, fmt.Errorf("parameter %s: %w", name, err)
+		}
+		parameters[name] = param
+	}
+	return cond, nil
+}
+
+// parameterTypeNames are the types of the parameters of OpenFGA by the name
+// of the type of the caveat parameters
+var parameterTypeNames = map[string]fgasdk.TypeName{
+	"any":       fgasdk.ANY,
+	"bool":      fgasdk.BOOL,
+	"string":    fgasdk.STRING,
+	"int":       fgasdk.INT,
+	"uint":      fgasdk.UINT,
+	"double":    fgasdk.DOUBLE,
+	"duration":  fgasdk.DURATION,
+	"timestamp": fgasdk.TIMESTAMP,
+	"list":      fgasdk.LIST,
+	"map":       fgasdk.MAP,
+	"ipaddress": fgasdk.IPADDRESS,
+}
+
+func translateParameterType(ref *sdbcore.CaveatTypeReference) (fgasdk.ConditionParamTypeRef, error) {
+	typeName, ok := parameterTypeNames[ref.GetTypeName()]
+	if !ok {
+		return fgasdk.ConditionParamTypeRef{}, fmt.Errorf("%w: parameter type %s", ErrUnsupportedSchema, ref.GetTypeName())
+	}
+	param := fgasdk.ConditionParamTypeRef{TypeName: typeName}
+	var generics []fgasdk.ConditionParamTypeRef
+	for _, child := range ref.GetChildTypes() {
+		generic, err := translateParameterType(child)
+		if err != nil {
+			return fgasdk.ConditionParamTypeRef{}, err
+		}
+		generics = append(generics, generic)
+	}
+	if len(generics) > 0 {
+		param.GenericTypes = &generics
+	}
+	return param, nil
+}
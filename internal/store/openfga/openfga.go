@@ -0,0 +1,372 @@
+// Package openfga keeps the tuples of shield in an OpenFGA store and checks
+// the permissions with it, for the deployments standardized on OpenFGA
+// rather than spicedb. The schema generated for spicedb stays the source of
+// the permissions, it is kept in the database of shield and translated into
+// a new authorization model of the store every time it changes.
+package openfga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/relation"
+	fgasdk "github.com/openfga/go-sdk"
+)
+
+// batchSize is the most tuples OpenFGA takes in a write
+const batchSize = 100
+
+var ErrInvalidTuple = errors.New("invalid openfga tuple")
+
+// SchemaStore keeps the schema text the authorization models are translated
+// from, postgres.AuthzRepository is the one of the server
+type SchemaStore interface {
+	ReadSchema(ctx context.Context) (string, error)
+	WriteSchema(ctx context.Context, schemaText string) error
+}
+
+// OpenFGA is the client of a store of OpenFGA, the checks are evaluated with
+// the latest authorization model of the store
+type OpenFGA struct {
+	client *fgasdk.APIClient
+	schema SchemaStore
+}
+
+// tokenTransport authenticates the requests of the sdk with the key token
+// returns at the time of the request
+type tokenTransport struct {
+	token func(ctx context.Context) (string, error)
+	base  http.RoundTripper
+}
+
+func (t tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("openfga api token: %w", err)
+	}
+	if token != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// New connects to the store of the config, the api token of the config is
+// used when token is nil. Otherwise token is asked for the key of every
+// request, so a rotated key is used right away.
+func New(ctx context.Context, config Config, token func(ctx context.Context) (string, error), schemaStore SchemaStore, logger log.Logger) (*OpenFGA, error) {
+	if config.APIURL == "" {
+		return nil, errors.New("openfga api url is not set")
+	}
+	if token == nil {
+		token = func(ctx context.Context) (string, error) { return config.APIToken, nil }
+	}
+	cfg, err := fgasdk.NewConfiguration(fgasdk.Configuration{
+		ApiUrl:  strings.TrimRight(config.APIURL, "/"),
+		StoreId: config.StoreID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTPClient = &http.Client{
+		Timeout:   config.Timeout,
+		Transport: tokenTransport{token: token, base: http.DefaultTransport},
+	}
+	fga := &OpenFGA{
+		client: fgasdk.NewAPIClient(cfg),
+		schema: schemaStore,
+	}
+
+	if config.StoreID == "" {
+		storeID, err := fga.storeNamed(ctx, config.StoreName)
+		if err != nil {
+			return nil, err
+		}
+		fga.client.SetStoreId(storeID)
+	}
+	if err := fga.Check(ctx); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Connected to openfga store %s: %s", fga.client.GetStoreId(), cfg.ApiUrl))
+	return fga, nil
+}
+
+// Check returns an error if the store of OpenFGA is not reachable
+func (o *OpenFGA) Check(ctx context.Context) error {
+	_, _, err := o.client.OpenFgaApi.GetStore(ctx).Execute()
+	return err
+}
+
+// storeNamed returns the id of the store with the name, the store is
+// created when there is none
+func (o *OpenFGA) storeNamed(ctx context.Context, name string) (string, error) {
+	continuationToken := ""
+	for {
+		req := o.client.OpenFgaApi.ListStores(ctx).PageSize(100)
+		if continuationToken != "" {
+			req = req.ContinuationToken(continuationToken)
+		}
+		resp, _, err := req.Execute()
+		if err != nil {
+			return "", err
+		}
+		for _, s := range resp.Stores {
+			if s.Name == name {
+				return s.Id, nil
+			}
+		}
+		if resp.ContinuationToken == "" {
+			break
+		}
+		continuationToken = resp.ContinuationToken
+	}
+
+	created, _, err := o.client.OpenFgaApi.CreateStore(ctx).Body(fgasdk.CreateStoreRequest{Name: name}).Execute()
+	if err != nil {
+		return "", err
+	}
+	return created.Id, nil
+}
+
+// object is the id of the object in OpenFGA
+func object(namespaceID, id string) string {
+	return namespaceID + ":" + id
+}
+
+// user is the id of the subject in OpenFGA, the subject sets have their
+// relation after a #
+func user(namespaceID, id, rel string) string {
+	if rel == "" {
+		return object(namespaceID, id)
+	}
+	return object(namespaceID, id) + "#" + rel
+}
+
+// splitObject splits the id of the object in OpenFGA into its type and id
+func splitObject(obj string) (string, string, error) {
+	namespaceID, id, ok := strings.Cut(obj, ":")
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidTuple, obj)
+	}
+	return namespaceID, id, nil
+}
+
+// keyOf is the tuple key of the tuple, the tuples of the condition relations
+// are written under the condition of their relation
+func keyOf(t relation.Tuple) fgasdk.TupleKey {
+	key := fgasdk.TupleKey{
+		User:     user(t.SubjectType, t.SubjectID, t.SubjectRelation),
+		Relation: t.Relation,
+		Object:   object(t.ResourceType, t.ResourceID),
+	}
+	if name := conditionOf(t.Relation); name != "" {
+		key.Condition = &fgasdk.RelationshipCondition{Name: name}
+	}
+	return key
+}
+
+func tupleOf(key fgasdk.TupleKey) (relation.Tuple, error) {
+	resourceType, resourceID, err := splitObject(key.Object)
+	if err != nil {
+		return relation.Tuple{}, err
+	}
+	subject, subjectRelation, _ := strings.Cut(key.User, "#")
+	subjectType, subjectID, err := splitObject(subject)
+	if err != nil {
+		return relation.Tuple{}, err
+	}
+	return relation.Tuple{
+		ResourceType:    resourceType,
+		ResourceID:      resourceID,
+		Relation:        key.Relation,
+		SubjectType:     subjectType,
+		SubjectID:       subjectID,
+		SubjectRelation: subjectRelation,
+	}, nil
+}
+
+// matches tells whether the tuple matches the filter, the fields of the
+// filter left empty match any value
+func matches(flt, t relation.Tuple) bool {
+	for _, f := range [][2]string{
+		{flt.ResourceType, t.ResourceType},
+		{flt.ResourceID, t.ResourceID},
+		{flt.Relation, t.Relation},
+		{flt.SubjectType, t.SubjectType},
+		{flt.SubjectID, t.SubjectID},
+		{flt.SubjectRelation, t.SubjectRelation},
+	} {
+		if f[0] != "" && f[0] != f[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// tuples returns the tuples of the store matching the filter. OpenFGA only
+// filters the reads of a single object, the other filters read every tuple
+// of the store.
+func (o *OpenFGA) tuples(ctx context.Context, flt relation.Tuple) ([]relation.Tuple, error) {
+	req := fgasdk.ReadRequest{PageSize: fgasdk.PtrInt32(100)}
+	if flt.ResourceType != "" && flt.ResourceID != "" {
+		req.TupleKey = &fgasdk.ReadRequestTupleKey{Object: fgasdk.PtrString(object(flt.ResourceType, flt.ResourceID))}
+		if flt.Relation != "" {
+			req.TupleKey.Relation = fgasdk.PtrString(flt.Relation)
+		}
+	}
+
+	var tuples []relation.Tuple
+	for {
+		resp, _, err := o.client.OpenFgaApi.Read(ctx).Body(req).Execute()
+		if err != nil {
+			return nil, err
+		}
+		for _, rt := range resp.Tuples {
+			t, err := tupleOf(rt.Key)
+			if err != nil {
+				return nil, err
+			}
+			if matches(flt, t) {
+				tuples = append(tuples, t)
+			}
+		}
+		if resp.ContinuationToken == "" {
+			return tuples, nil
+		}
+		req.ContinuationToken = fgasdk.PtrString(resp.ContinuationToken)
+	}
+}
+
+// resourceIDs returns the ids of the objects of the namespace having a tuple
+func (o *OpenFGA) resourceIDs(ctx context.Context, namespaceID string) ([]string, error) {
+	tuples, err := o.tuples(ctx, relation.Tuple{ResourceType: namespaceID})
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var ids []string
+	for _, t := range tuples {
+		if !seen[t.ResourceID] {
+			seen[t.ResourceID] = true
+			ids = append(ids, t.ResourceID)
+		}
+	}
+	return ids, nil
+}
+
+// isInvalidWrite tells whether OpenFGA rejected the write because one of its
+// tuples already exists, or doesn't exist for a delete, with the phrase of
+// the message. The whole write is rejected then.
+func isInvalidWrite(err error, phrase string) bool {
+	var validationErr fgasdk.FgaApiValidationError
+	if !errors.As(err, &validationErr) || validationErr.ResponseCode() != fgasdk.WRITE_FAILED_DUE_TO_INVALID_INPUT {
+		return false
+	}
+	resp, ok := validationErr.Model().(fgasdk.ValidationErrorMessageResponse)
+	return ok && strings.Contains(resp.GetMessage(), phrase)
+}
+
+// write writes the batch of the request, a batch OpenFGA rejects for a
+// tuple which already exists, or doesn't for a delete, is written again a
+// tuple at a time skipping those
+func (o *OpenFGA) write(ctx context.Context, req fgasdk.WriteRequest, phrase string, split func(fgasdk.WriteRequest) []fgasdk.WriteRequest) error {
+	_, _, err := o.client.OpenFgaApi.Write(ctx).Body(req).Execute()
+	if err == nil || !isInvalidWrite(err, phrase) {
+		return err
+	}
+	for _, single := range split(req) {
+		if _, _, err := o.client.OpenFgaApi.Write(ctx).Body(single).Execute(); err != nil && !isInvalidWrite(err, phrase) {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTuples writes the tuples in batches, existing tuples are left as
+// they are
+func (o *OpenFGA) writeTuples(ctx context.Context, tuples []relation.Tuple) error {
+	for start := 0; start < len(tuples); start += batchSize {
+		end := start + batchSize
+		if end > len(tuples) {
+			end = len(tuples)
+		}
+		keys := make([]fgasdk.TupleKey, 0, end-start)
+		for _, t := range tuples[start:end] {
+			keys = append(keys, keyOf(t))
+		}
+		req := fgasdk.WriteRequest{Writes: &fgasdk.WriteRequestWrites{TupleKeys: keys}}
+		err := o.write(ctx, req, "already exists", func(req fgasdk.WriteRequest) []fgasdk.WriteRequest {
+			singles := make([]fgasdk.WriteRequest, 0, len(req.Writes.TupleKeys))
+			for _, key := range req.Writes.TupleKeys {
+				singles = append(singles, fgasdk.WriteRequest{Writes: &fgasdk.WriteRequestWrites{TupleKeys: []fgasdk.TupleKey{key}}})
+			}
+			return singles
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteTuples deletes the tuples in batches, tuples which don't exist are
+// skipped
+func (o *OpenFGA) deleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	for start := 0; start < len(tuples); start += batchSize {
+		end := start + batchSize
+		if end > len(tuples) {
+			end = len(tuples)
+		}
+		keys := make([]fgasdk.TupleKeyWithoutCondition, 0, end-start)
+		for _, t := range tuples[start:end] {
+			key := keyOf(t)
+			keys = append(keys, fgasdk.TupleKeyWithoutCondition{User: key.User, Relation: key.Relation, Object: key.Object})
+		}
+		req := fgasdk.WriteRequest{Deletes: &fgasdk.WriteRequestDeletes{TupleKeys: keys}}
+		err := o.write(ctx, req, "does not exist", func(req fgasdk.WriteRequest) []fgasdk.WriteRequest {
+			singles := make([]fgasdk.WriteRequest, 0, len(req.Deletes.TupleKeys))
+			for _, key := range req.Deletes.TupleKeys {
+				singles = append(singles, fgasdk.WriteRequest{Deletes: &fgasdk.WriteRequestDeletes{TupleKeys: []fgasdk.TupleKeyWithoutCondition{key}}})
+			}
+			return singles
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteMatching deletes the tuples matching the filter
+func (o *OpenFGA) deleteMatching(ctx context.Context, flt relation.Tuple) error {
+	tuples, err := o.tuples(ctx, flt)
+	if err != nil {
+		return err
+	}
+	return o.deleteTuples(ctx, tuples)
+}
+
+// writeModel writes the authorization model of the schema text, the checks
+// use it from then on
+func (o *OpenFGA) writeModel(ctx context.Context, schemaText string) error {
+	model, err := translate(schemaText)
+	if err != nil {
+		return err
+	}
+	_, _, err = o.client.OpenFgaApi.WriteAuthorizationModel(ctx).Body(model).Execute()
+	return err
+}
+
+// replaceSchema writes the authorization model of the schema text before
+// keeping the text for the next change
+func (o *OpenFGA) replaceSchema(ctx context.Context, schemaText string) error {
+	if err := o.writeModel(ctx, schemaText); err != nil {
+		return err
+	}
+	return o.schema.WriteSchema(ctx, schemaText)
+}
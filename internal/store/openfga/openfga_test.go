@@ -0,0 +1,313 @@
+package openfga_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/store/openfga"
+	fgasdk "github.com/openfga/go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySchemaStore struct {
+	schemaText string
+}
+
+func (s *memorySchemaStore) ReadSchema(ctx context.Context) (string, error) {
+	return s.schemaText, nil
+}
+
+func (s *memorySchemaStore) WriteSchema(ctx context.Context, schemaText string) error {
+	s.schemaText = schemaText
+	return nil
+}
+
+type tupleKey struct {
+	User      string `json:"user"`
+	Relation  string `json:"relation"`
+	Object    string `json:"object"`
+	Condition *struct {
+		Name string `json:"name"`
+	} `json:"condition,omitempty"`
+}
+
+// fakeOpenFGA serves the api of a single store, a check is allowed when the
+// tuple checked was written
+type fakeOpenFGA struct {
+	mu        sync.Mutex
+	storeID   string
+	tuples    []tupleKey
+	models    []map[string]any
+	checks    []map[string]any
+	expansion string
+}
+
+func (f *fakeOpenFGA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("Authorization") != "Bearer key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"code": "unauthenticated", "message": "unauthenticated"})
+		return
+	}
+
+	var req map[string]json.RawMessage
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	switch path := strings.TrimPrefix(r.URL.Path, "/stores/"+f.storeID); {
+	case r.URL.Path == "/stores" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{"stores": []map[string]string{{"id": "other", "name": "other"}}})
+	case r.URL.Path == "/stores" && r.Method == http.MethodPost:
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": f.storeID, "name": "shield"})
+	case path == "":
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": f.storeID})
+	case path == "/authorization-models":
+		var model map[string]any
+		raw, _ := json.Marshal(req)
+		_ = json.Unmarshal(raw, &model)
+		f.models = append(f.models, model)
+		_ = json.NewEncoder(w).Encode(map[string]string{"authorization_model_id": "model"})
+	case path == "/write":
+		var writes, deletes struct {
+			TupleKeys []tupleKey `json:"tuple_keys"`
+		}
+		_ = json.Unmarshal(req["writes"], &writes)
+		_ = json.Unmarshal(req["deletes"], &deletes)
+		// like openfga the whole write is rejected for a tuple which
+		// already exists or a delete of one which doesn't
+		for _, key := range writes.TupleKeys {
+			if f.indexOf(key) >= 0 {
+				f.invalidWrite(w, "cannot write a tuple which already exists")
+				return
+			}
+		}
+		for _, key := range deletes.TupleKeys {
+			if f.indexOf(key) < 0 {
+				f.invalidWrite(w, "cannot delete a tuple which does not exist")
+				return
+			}
+		}
+		f.tuples = append(f.tuples, writes.TupleKeys...)
+		for _, key := range deletes.TupleKeys {
+			i := f.indexOf(key)
+			f.tuples = append(f.tuples[:i], f.tuples[i+1:]...)
+		}
+		_, _ = w.Write([]byte("{}"))
+	case path == "/read":
+		// a page of a single tuple makes the client follow the tokens
+		var token string
+		_ = json.Unmarshal(req["continuation_token"], &token)
+		next, _ := strconv.Atoi(token)
+		resp := map[string]any{"tuples": []any{}}
+		if next < len(f.tuples) {
+			resp["tuples"] = []any{map[string]any{"key": f.tuples[next]}}
+			if next+1 < len(f.tuples) {
+				resp["continuation_token"] = strconv.Itoa(next + 1)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	case path == "/check":
+		var check struct {
+			TupleKey tupleKey `json:"tuple_key"`
+		}
+		raw, _ := json.Marshal(req)
+		_ = json.Unmarshal(raw, &check)
+		var checkReq map[string]any
+		_ = json.Unmarshal(raw, &checkReq)
+		f.checks = append(f.checks, checkReq)
+		allowed := false
+		for _, t := range f.tuples {
+			allowed = allowed || (t.User == check.TupleKey.User && t.Relation == check.TupleKey.Relation && t.Object == check.TupleKey.Object)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]bool{"allowed": allowed})
+	case path == "/list-objects":
+		_ = json.NewEncoder(w).Encode(map[string][]string{"objects": {"shield/project:project-1", "shield/project:project-2"}})
+	case path == "/expand":
+		_, _ = w.Write([]byte(f.expansion))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"code": "undefined_endpoint", "message": path})
+	}
+}
+
+func (f *fakeOpenFGA) indexOf(key tupleKey) int {
+	for i, t := range f.tuples {
+		if t.User == key.User && t.Relation == key.Relation && t.Object == key.Object {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *fakeOpenFGA) invalidWrite(w http.ResponseWriter, message string) {
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"code": "write_failed_due_to_invalid_input", "message": message})
+}
+
+func TestOpenFGA(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeOpenFGA{storeID: "01HVMMBCMGZNT3SED4Z17ECXCA"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	schemaStore := &memorySchemaStore{}
+	fga, err := openfga.New(ctx, openfga.Config{APIURL: server.URL, StoreName: "shield", APIToken: "key"}, nil, schemaStore, log.NewNoop())
+	require.NoError(t, err)
+	relations := openfga.NewRelationRepository(fga)
+	policies := openfga.NewPolicyRepository(fga)
+
+	t.Run("should return the error responses of openfga", func(t *testing.T) {
+		_, err := openfga.New(ctx, openfga.Config{APIURL: server.URL, StoreID: fake.storeID, APIToken: "wrong"}, nil, schemaStore, log.NewNoop())
+		var authErr fgasdk.FgaApiAuthenticationError
+		require.ErrorAs(t, err, &authErr)
+		assert.Equal(t, http.StatusUnauthorized, authErr.ResponseStatusCode())
+	})
+
+	t.Run("should write the model translated from the schema", func(t *testing.T) {
+		require.NoError(t, policies.WriteSchema(ctx, schema.PreDefinedSystemNamespaceConfig))
+		require.Len(t, fake.models, 1)
+		model := fake.models[0]
+		assert.Equal(t, "1.1", model["schema_version"])
+
+		var project map[string]any
+		for _, typeDef := range model["type_definitions"].([]any) {
+			if typeDef.(map[string]any)["type"] == schema.ProjectNamespace {
+				project = typeDef.(map[string]any)
+			}
+		}
+		require.NotNil(t, project)
+		relations := project["relations"].(map[string]any)
+		assert.Equal(t, map[string]any{"this": map[string]any{}}, relations["viewer"])
+		assert.Equal(t, map[string]any{"union": map[string]any{"child": []any{
+			map[string]any{"computedUserset": map[string]any{"relation": "owner"}},
+			map[string]any{"tupleToUserset": map[string]any{
+				"tupleset":        map[string]any{"relation": "organization"},
+				"computedUserset": map[string]any{"relation": "owner"},
+			}},
+		}}}, relations["delete"])
+		metadata := project["metadata"].(map[string]any)["relations"].(map[string]any)
+		assert.Equal(t, []any{
+			map[string]any{"type": schema.UserPrincipal},
+			map[string]any{"type": schema.GroupPrincipal, "relation": "membership"},
+		}, metadata["viewer"].(map[string]any)["directly_related_user_types"])
+
+		stored, err := policies.ReadSchema(ctx)
+		assert.NoError(t, err)
+		assert.Contains(t, stored, "definition "+schema.ProjectNamespace)
+	})
+
+	t.Run("should write the tuples and check them", func(t *testing.T) {
+		require.NoError(t, relations.AddV2(ctx, relation.RelationV2{
+			Object:  relation.Object{ID: "project-1", NamespaceID: schema.ProjectNamespace},
+			Subject: relation.Subject{ID: "group-1", Namespace: schema.GroupPrincipal, RoleID: schema.ProjectNamespace + ":viewer"},
+		}))
+		require.NoError(t, relations.AddV2(ctx, relation.RelationV2{
+			Object:  relation.Object{ID: "project-1", NamespaceID: schema.ProjectNamespace},
+			Subject: relation.Subject{ID: "alice", Namespace: schema.UserPrincipal, RoleID: schema.ProjectNamespace + ":owner"},
+		}))
+		assert.Equal(t, "shield/group:group-1#membership", fake.tuples[0].User)
+		assert.Equal(t, "shield/project:project-1", fake.tuples[0].Object)
+
+		// the tuples which already exist are skipped
+		require.NoError(t, relations.Import(ctx, []relation.Tuple{
+			{ResourceType: schema.ProjectNamespace, ResourceID: "project-1", Relation: "owner", SubjectType: schema.UserPrincipal, SubjectID: "alice"},
+			{ResourceType: schema.ProjectNamespace, ResourceID: "project-1", Relation: "viewer", SubjectType: schema.UserPrincipal, SubjectID: "bob"},
+		}))
+		require.NoError(t, relations.DeleteTuples(ctx, []relation.Tuple{
+			{ResourceType: schema.ProjectNamespace, ResourceID: "project-1", Relation: "viewer", SubjectType: schema.UserPrincipal, SubjectID: "bob"},
+			{ResourceType: schema.ProjectNamespace, ResourceID: "project-1", Relation: "viewer", SubjectType: schema.UserPrincipal, SubjectID: "carol"},
+		}))
+		assert.Len(t, fake.tuples, 2)
+
+		checkCtx := relation.WithCheckContext(ctx, relation.CheckContext{Request: map[string]string{relation.CheckContextIP: "10.1.2.3"}})
+		allowed, err := relations.Check(checkCtx, relation.Relation{
+			ObjectNamespace:  namespace.Namespace{ID: schema.ProjectNamespace},
+			ObjectID:         "project-1",
+			SubjectNamespace: namespace.Namespace{ID: schema.UserPrincipal},
+			SubjectID:        "alice",
+		}, action.Action{ID: "owner"})
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, "10.1.2.3", fake.checks[0]["context"].(map[string]any)["ip"])
+
+		var exported []relation.Tuple
+		require.NoError(t, relations.Export(ctx, schema.ProjectNamespace, func(tuple relation.Tuple) error {
+			exported = append(exported, tuple)
+			return nil
+		}))
+		assert.Equal(t, []relation.Tuple{
+			{ResourceType: schema.ProjectNamespace, ResourceID: "project-1", Relation: "viewer", SubjectType: schema.GroupPrincipal, SubjectID: "group-1", SubjectRelation: "membership"},
+			{ResourceType: schema.ProjectNamespace, ResourceID: "project-1", Relation: "owner", SubjectType: schema.UserPrincipal, SubjectID: "alice"},
+		}, exported)
+	})
+
+	t.Run("should write the condition tuples of a conditional policy", func(t *testing.T) {
+		conditional := policy.Policy{
+			ID:          "9f1c-42",
+			NamespaceID: schema.ProjectNamespace,
+			RoleID:      schema.ProjectNamespace + ":viewer",
+			ActionID:    "delete." + schema.ProjectNamespace,
+			Condition:   `ip.in_cidr("10.0.0.0/8")`,
+		}
+		require.NoError(t, policies.ValidateCondition(conditional.Condition))
+		require.NoError(t, policies.Add(ctx, []policy.Policy{conditional}))
+
+		conditions := fake.models[len(fake.models)-1]["conditions"].(map[string]any)
+		assert.Equal(t, map[string]any{
+			"name":       "shield_condition_9f1c42",
+			"expression": `ip.in_cidr("10.0.0.0/8")`,
+			"parameters": map[string]any{
+				"ip":       map[string]any{"type_name": "TYPE_NAME_IPADDRESS"},
+				"now":      map[string]any{"type_name": "TYPE_NAME_TIMESTAMP"},
+				"request":  map[string]any{"type_name": "TYPE_NAME_MAP", "generic_types": []any{map[string]any{"type_name": "TYPE_NAME_ANY"}}},
+				"resource": map[string]any{"type_name": "TYPE_NAME_MAP", "generic_types": []any{map[string]any{"type_name": "TYPE_NAME_ANY"}}},
+			},
+		}, conditions["shield_condition_9f1c42"])
+
+		conditionTuple := fake.tuples[len(fake.tuples)-1]
+		assert.Equal(t, "shield/user:*", conditionTuple.User)
+		assert.Equal(t, "condition_9f1c42", conditionTuple.Relation)
+		require.NotNil(t, conditionTuple.Condition)
+		assert.Equal(t, "shield_condition_9f1c42", conditionTuple.Condition.Name)
+
+		require.NoError(t, policies.Remove(ctx, []policy.Policy{conditional}))
+		for _, tuple := range fake.tuples {
+			assert.NotEqual(t, "condition_9f1c42", tuple.Relation)
+		}
+	})
+
+	t.Run("should look up the objects and expand the permissions", func(t *testing.T) {
+		ids, err := relations.LookupResources(ctx, schema.ProjectNamespace, "view", relation.Subject{ID: "alice", Namespace: schema.UserPrincipal})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"project-1", "project-2"}, ids)
+
+		fake.expansion = `{"tree":{"root":{"name":"shield/project:project-1#delete","union":{"nodes":[
+			{"name":"shield/project:project-1#owner","leaf":{"users":{"users":["shield/user:alice"]}}},
+			{"name":"shield/project:project-1#delete","leaf":{"tupleToUserset":{"tupleset":"shield/project:project-1#organization","computed":[{"userset":"shield/organization:org-1#owner"}]}}}
+		]}}}}`
+		tree, err := relations.Expand(ctx, relation.Object{ID: "project-1", NamespaceID: schema.ProjectNamespace}, "delete")
+		assert.NoError(t, err)
+		assert.Equal(t, relation.OperationUnion, tree.Operation)
+		assert.Equal(t, "delete", tree.Relation)
+		assert.True(t, tree.Grants(relation.Subject{ID: "alice", Namespace: schema.UserPrincipal}))
+		assert.True(t, tree.Reaches(relation.Subject{ID: "org-1", Namespace: schema.OrganizationNamespace, RoleID: "owner"}))
+	})
+
+	t.Run("should delete the tuples of the objects", func(t *testing.T) {
+		require.NoError(t, relations.DeleteSubjectRelations(ctx, schema.ProjectNamespace, "project-1"))
+		assert.Empty(t, fake.tuples)
+	})
+}
@@ -0,0 +1,138 @@
+package openfga
+
+import (
+	"context"
+	"strings"
+
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
+)
+
+// PolicyRepository keeps the grants of the policies in the schema the
+// authorization models of the store are translated from, the way
+// spicedb.PolicyRepository keeps them in the one of spicedb
+type PolicyRepository struct {
+	openFGA *OpenFGA
+}
+
+func NewPolicyRepository(openFGA *OpenFGA) *PolicyRepository {
+	return &PolicyRepository{
+		openFGA: openFGA,
+	}
+}
+
+// WriteSchema writes the schema generated from the namespace configs, the
+// condition relations of the previous schema are dropped with it until the
+// grants of the policies are restored
+func (r PolicyRepository) WriteSchema(ctx context.Context, namespaceConfigs schema.NamespaceConfigMapType) error {
+	generatedSchema := strings.Join(schema_generator.GenerateSchema(namespaceConfigs), "\n")
+	previousSchema, err := r.openFGA.schema.ReadSchema(ctx)
+	if err != nil {
+		return err
+	}
+	return r.replaceSchema(ctx, previousSchema, generatedSchema)
+}
+
+// ReadSchema returns the schema the latest authorization model was
+// translated from, the custom roles and the grants of the policies included
+func (r PolicyRepository) ReadSchema(ctx context.Context) (string, error) {
+	return r.openFGA.schema.ReadSchema(ctx)
+}
+
+func (r PolicyRepository) Add(ctx context.Context, policies []policy.Policy) error {
+	return r.applyPolicies(ctx, nil, policies)
+}
+
+func (r PolicyRepository) Remove(ctx context.Context, policies []policy.Policy) error {
+	return r.applyPolicies(ctx, policies, nil)
+}
+
+// ValidateCondition returns an error if the condition doesn't compile to a
+// caveat OpenFGA can evaluate as a condition
+func (r PolicyRepository) ValidateCondition(condition string) error {
+	def, err := schema_generator.CompileCondition("condition_validation", condition)
+	if err != nil {
+		return err
+	}
+	_, err = translateCaveat(def)
+	return err
+}
+
+// AddObject writes the tuples of the object to every user under the
+// conditions of the policies, the grants of the policies only apply to the
+// objects having them
+func (r PolicyRepository) AddObject(ctx context.Context, policies []policy.Policy, objectID string) error {
+	tuples := make([]relation.Tuple, 0, len(policies))
+	for _, pol := range policies {
+		tuples = append(tuples, conditionTuple(pol.NamespaceID, objectID, schema_generator.ConditionName(pol)))
+	}
+	return r.openFGA.writeTuples(ctx, tuples)
+}
+
+func (r PolicyRepository) applyPolicies(ctx context.Context, toRemove, toAdd []policy.Policy) error {
+	previousSchema, err := r.openFGA.schema.ReadSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	updatedSchema, err := schema_generator.ApplyPolicies(previousSchema, toRemove, toAdd)
+	if err != nil {
+		return err
+	}
+	return r.replaceSchema(ctx, previousSchema, updatedSchema)
+}
+
+// replaceSchema writes the model of the new schema over the one of the
+// previous schema, the tuples of the condition relations it drops are
+// deleted and the objects existing in the namespaces of the condition
+// relations it adds are given theirs
+func (r PolicyRepository) replaceSchema(ctx context.Context, previousSchema, newSchema string) error {
+	if _, err := translate(newSchema); err != nil {
+		return err
+	}
+
+	var before []schema_generator.ConditionRelation
+	if previousSchema != "" {
+		var err error
+		if before, err = schema_generator.ConditionRelations(previousSchema); err != nil {
+			return err
+		}
+	}
+	after, err := schema_generator.ConditionRelations(newSchema)
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range schema_generator.DiffConditionRelations(before, after) {
+		if err := r.openFGA.deleteMatching(ctx, relation.Tuple{ResourceType: rel.NamespaceID, Relation: rel.Name}); err != nil {
+			return err
+		}
+	}
+
+	if err := r.openFGA.replaceSchema(ctx, newSchema); err != nil {
+		return err
+	}
+
+	for _, rel := range schema_generator.DiffConditionRelations(after, before) {
+		objectIDs, err := r.openFGA.resourceIDs(ctx, rel.NamespaceID)
+		if err != nil {
+			return err
+		}
+		tuples := make([]relation.Tuple, 0, len(objectIDs))
+		for _, objectID := range objectIDs {
+			tuples = append(tuples, conditionTuple(rel.NamespaceID, objectID, rel.Name))
+		}
+		if err := r.openFGA.writeTuples(ctx, tuples); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// conditionTuple is the tuple of the object to every user of the condition
+// relation
+func conditionTuple(namespaceID, objectID, name string) relation.Tuple {
+	return tupleOfRelationship(namespaceID, objectID, name, schema.UserPrincipal, "*", "")
+}
@@ -0,0 +1,326 @@
+package openfga
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/metrics"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
+
+	newrelic "github.com/newrelic/go-agent"
+	fgasdk "github.com/openfga/go-sdk"
+)
+
+const nrProductName = "openfga"
+
+// RelationRepository writes the relations as tuples of the store of
+// OpenFGA and checks them with it. The consistency asked for a check isn't
+// passed on, the go-sdk has no field for it and OpenFGA evaluates the checks
+// against the latest tuples unless its check cache is turned on.
+type RelationRepository struct {
+	openFGA *OpenFGA
+}
+
+func NewRelationRepository(openFGA *OpenFGA) *RelationRepository {
+	return &RelationRepository{
+		openFGA: openFGA,
+	}
+}
+
+func tupleOfRelationship(resourceType, resourceID, rel, subjectType, subjectID, subjectRelation string) relation.Tuple {
+	return relation.Tuple{
+		ResourceType:    resourceType,
+		ResourceID:      resourceID,
+		Relation:        rel,
+		SubjectType:     subjectType,
+		SubjectID:       subjectID,
+		SubjectRelation: subjectRelation,
+	}
+}
+
+func (r RelationRepository) Add(ctx context.Context, rel relation.Relation) error {
+	relationship, err := schema_generator.TransformRelation(rel)
+	if err != nil {
+		return err
+	}
+	return r.openFGA.writeTuples(ctx, []relation.Tuple{tupleOfRelationship(
+		relationship.GetResource().GetObjectType(),
+		relationship.GetResource().GetObjectId(),
+		relationship.GetRelation(),
+		relationship.GetSubject().GetObject().GetObjectType(),
+		relationship.GetSubject().GetObject().GetObjectId(),
+		relationship.GetSubject().GetOptionalRelation(),
+	)})
+}
+
+func getRelation(a string) string {
+	if a == schema.GroupPrincipal {
+		return "membership"
+	}
+
+	return ""
+}
+
+func (r RelationRepository) AddV2(ctx context.Context, rel relation.RelationV2) error {
+	nrCtx := newrelic.FromContext(ctx)
+	if nrCtx != nil {
+		nr := newrelic.DatastoreSegment{
+			Product: nrProductName,
+			QueryParameters: map[string]interface{}{
+				"relation":          rel.Subject.RoleID,
+				"subject_namespace": rel.Subject.Namespace,
+				"object_namespace":  rel.Object.NamespaceID,
+			},
+			Operation: "Upsert_Relation",
+			StartTime: nrCtx.StartSegmentNow(),
+		}
+		defer nr.End()
+	}
+
+	return r.openFGA.writeTuples(ctx, []relation.Tuple{tupleOfRelationship(
+		rel.Object.NamespaceID,
+		rel.Object.ID,
+		schema.GetRoleName(rel.Subject.RoleID),
+		rel.Subject.Namespace,
+		rel.Subject.ID,
+		getRelation(rel.Subject.Namespace),
+	)})
+}
+
+// conditionContext is the context the conditions of the check are evaluated
+// with, nil when the check has none
+func conditionContext(ctx context.Context) *map[string]interface{} {
+	values := schema_generator.ConditionContext(relation.CheckContextFromContext(ctx))
+	if len(values) == 0 {
+		return nil
+	}
+	return &values
+}
+
+func (r RelationRepository) Check(ctx context.Context, rel relation.Relation, act action.Action) (bool, error) {
+	relationship, err := schema_generator.TransformCheckRelation(rel)
+	if err != nil {
+		return false, err
+	}
+	request := fgasdk.CheckRequest{
+		TupleKey: fgasdk.CheckRequestTupleKey{
+			User: user(
+				relationship.GetSubject().GetObject().GetObjectType(),
+				relationship.GetSubject().GetObject().GetObjectId(),
+				relationship.GetSubject().GetOptionalRelation(),
+			),
+			Relation: act.ID,
+			Object:   object(relationship.GetResource().GetObjectType(), relationship.GetResource().GetObjectId()),
+		},
+		Context: conditionContext(ctx),
+	}
+
+	nrCtx := newrelic.FromContext(ctx)
+	if nrCtx != nil {
+		nr := newrelic.DatastoreSegment{
+			Product:    nrProductName,
+			Collection: fmt.Sprintf("object:%s::subject:%s", relationship.GetResource().GetObjectType(), relationship.GetSubject().GetObject().GetObjectType()),
+			Operation:  "Check",
+			StartTime:  nrCtx.StartSegmentNow(),
+		}
+		defer nr.End()
+	}
+
+	start := time.Now()
+	response, _, err := r.openFGA.client.OpenFgaApi.Check(ctx).Body(request).Execute()
+	metrics.ObserveOpenFGACheck(relationship.GetResource().GetObjectType(), start, response.GetAllowed(), err)
+	if err != nil {
+		return false, err
+	}
+	return response.GetAllowed(), nil
+}
+
+// Expand expands the permission or relation of the object into the tree
+// of the relations granting it, subject sets are left unexpanded. OpenFGA
+// expands a single level, the relations it leaves to expand are subject
+// sets of the tree.
+func (r RelationRepository) Expand(ctx context.Context, obj relation.Object, permission string) (relation.AccessTree, error) {
+	request := fgasdk.ExpandRequest{
+		TupleKey: fgasdk.ExpandRequestTupleKey{
+			Relation: permission,
+			Object:   object(strings.ReplaceAll(obj.NamespaceID, "-", "_"), obj.ID),
+		},
+	}
+
+	response, _, err := r.openFGA.client.OpenFgaApi.Expand(ctx).Body(request).Execute()
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+	tree := response.GetTree()
+	return accessTree(tree.GetRoot())
+}
+
+// subjectOf is the subject of the user or userset of an expansion
+func subjectOf(u string) (relation.Subject, error) {
+	subject, rel, _ := strings.Cut(u, "#")
+	namespaceID, id, err := splitObject(subject)
+	if err != nil {
+		return relation.Subject{}, err
+	}
+	return relation.Subject{ID: id, Namespace: namespaceID, RoleID: rel}, nil
+}
+
+func accessTree(node fgasdk.Node) (relation.AccessTree, error) {
+	obj, rel, _ := strings.Cut(node.Name, "#")
+	namespaceID, id, err := splitObject(obj)
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+	tree := relation.AccessTree{
+		Object:   relation.Object{ID: id, NamespaceID: namespaceID},
+		Relation: rel,
+	}
+
+	var children []fgasdk.Node
+	switch {
+	case node.Leaf != nil:
+		var usersets []string
+		switch {
+		case node.Leaf.Users != nil:
+			usersets = node.Leaf.Users.Users
+		case node.Leaf.Computed != nil:
+			usersets = []string{node.Leaf.Computed.Userset}
+		case node.Leaf.TupleToUserset != nil:
+			for _, c := range node.Leaf.TupleToUserset.Computed {
+				usersets = append(usersets, c.Userset)
+			}
+		}
+		for _, u := range usersets {
+			sub, err := subjectOf(u)
+			if err != nil {
+				return relation.AccessTree{}, err
+			}
+			tree.Subjects = append(tree.Subjects, sub)
+		}
+		return tree, nil
+	case node.Intersection != nil:
+		tree.Operation = relation.OperationIntersection
+		children = node.Intersection.Nodes
+	case node.Difference != nil:
+		tree.Operation = relation.OperationExclusion
+		children = []fgasdk.Node{node.Difference.Base, node.Difference.Subtract}
+	default:
+		tree.Operation = relation.OperationUnion
+		if node.Union != nil {
+			children = node.Union.Nodes
+		}
+	}
+	for _, child := range children {
+		childTree, err := accessTree(child)
+		if err != nil {
+			return relation.AccessTree{}, err
+		}
+		tree.Children = append(tree.Children, childTree)
+	}
+	return tree, nil
+}
+
+// LookupResources returns the ids of the objects of the namespace the
+// subject has the permission on. The objects the subject only has it on
+// under a condition the context doesn't meet are left out.
+func (r RelationRepository) LookupResources(ctx context.Context, namespaceID, permission string, sub relation.Subject) ([]string, error) {
+	namespaceID = strings.ReplaceAll(namespaceID, "-", "_")
+	request := fgasdk.ListObjectsRequest{
+		Type:     namespaceID,
+		Relation: permission,
+		User:     user(strings.ReplaceAll(sub.Namespace, "-", "_"), sub.ID, sub.RoleID),
+		Context:  conditionContext(ctx),
+	}
+
+	nrCtx := newrelic.FromContext(ctx)
+	if nrCtx != nil {
+		nr := newrelic.DatastoreSegment{
+			Product:    nrProductName,
+			Collection: fmt.Sprintf("object:%s::subject:%s", namespaceID, sub.Namespace),
+			Operation:  "LookupResources",
+			StartTime:  nrCtx.StartSegmentNow(),
+		}
+		defer nr.End()
+	}
+
+	response, _, err := r.openFGA.client.OpenFgaApi.ListObjects(ctx).Body(request).Execute()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(response.Objects))
+	for _, obj := range response.Objects {
+		_, id, err := splitObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r RelationRepository) Delete(ctx context.Context, rel relation.Relation) error {
+	relationship, err := schema_generator.TransformRelation(rel)
+	if err != nil {
+		return err
+	}
+	return r.openFGA.deleteMatching(ctx, relation.Tuple{
+		ResourceType: relationship.GetResource().GetObjectType(),
+		ResourceID:   relationship.GetResource().GetObjectId(),
+		Relation:     relationship.GetRelation(),
+		SubjectType:  relationship.GetSubject().GetObject().GetObjectType(),
+		SubjectID:    relationship.GetSubject().GetObject().GetObjectId(),
+	})
+}
+
+func (r RelationRepository) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	relationship, err := schema_generator.TransformRelationV2(rel)
+	if err != nil {
+		return err
+	}
+	return r.openFGA.deleteMatching(ctx, relation.Tuple{
+		ResourceType: relationship.GetResource().GetObjectType(),
+		ResourceID:   relationship.GetResource().GetObjectId(),
+		Relation:     relationship.GetRelation(),
+		SubjectType:  relationship.GetSubject().GetObject().GetObjectType(),
+		SubjectID:    relationship.GetSubject().GetObject().GetObjectId(),
+	})
+}
+
+// DeleteSubjectRelations deletes the tuples of the objects of the namespace,
+// or of the object when its id is given
+func (r RelationRepository) DeleteSubjectRelations(ctx context.Context, resourceType, optionalResourceID string) error {
+	return r.openFGA.deleteMatching(ctx, relation.Tuple{
+		ResourceType: resourceType,
+		ResourceID:   optionalResourceID,
+	})
+}
+
+// Export streams every tuple on the objects of the namespace to fn
+func (r RelationRepository) Export(ctx context.Context, resourceType string, fn func(relation.Tuple) error) error {
+	tuples, err := r.openFGA.tuples(ctx, relation.Tuple{ResourceType: resourceType})
+	if err != nil {
+		return err
+	}
+	for _, t := range tuples {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import writes the tuples, existing tuples are left as they are so an
+// import can be retried. OpenFGA rejects the tuples its model doesn't allow.
+func (r RelationRepository) Import(ctx context.Context, tuples []relation.Tuple) error {
+	return r.openFGA.writeTuples(ctx, tuples)
+}
+
+// DeleteTuples removes the tuples, tuples which don't exist are skipped
+func (r RelationRepository) DeleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	return r.openFGA.deleteTuples(ctx, tuples)
+}
@@ -0,0 +1,41 @@
+package openfga
+
+import (
+	"context"
+
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
+)
+
+// RoleRepository keeps the custom roles of the organizations in the schema
+// the authorization models are translated from
+type RoleRepository struct {
+	openFGA *OpenFGA
+}
+
+func NewRoleRepository(openFGA *OpenFGA) *RoleRepository {
+	return &RoleRepository{
+		openFGA: openFGA,
+	}
+}
+
+func (r RoleRepository) Add(ctx context.Context, roles []role.Role) error {
+	return r.applyRoles(ctx, nil, roles)
+}
+
+func (r RoleRepository) Remove(ctx context.Context, roles []role.Role) error {
+	return r.applyRoles(ctx, roles, nil)
+}
+
+func (r RoleRepository) applyRoles(ctx context.Context, toRemove, toAdd []role.Role) error {
+	previousSchema, err := r.openFGA.schema.ReadSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	updatedSchema, err := schema_generator.ApplyRoles(previousSchema, toRemove, toAdd)
+	if err != nil {
+		return err
+	}
+	return r.openFGA.replaceSchema(ctx, updatedSchema)
+}
@@ -51,7 +51,7 @@ func (r ActionRepository) Get(ctx context.Context, id string) (action.Action, er
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &fetchedAction, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &fetchedAction, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return action.Action{}, action.ErrNotExist
@@ -128,7 +128,7 @@ func (r ActionRepository) List(ctx context.Context) ([]action.Action, error) {
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedActions, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedActions, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return []action.Action{}, nil
@@ -193,3 +193,22 @@ func (r ActionRepository) Update(ctx context.Context, act action.Action) (action
 
 	return actionModel.transformToAction(), nil
 }
+
+func (r ActionRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return action.ErrInvalidID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_ACTIONS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return action.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return action.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/apikey"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type APIKeyRepository struct {
+	dbc *db.Client
+}
+
+func NewAPIKeyRepository(dbc *db.Client) *APIKeyRepository {
+	return &APIKeyRepository{
+		dbc: dbc,
+	}
+}
+
+func (r APIKeyRepository) Create(ctx context.Context, key apikey.APIKey) (apikey.APIKey, error) {
+	if strings.TrimSpace(key.UserID) == "" || strings.TrimSpace(key.Prefix) == "" || strings.TrimSpace(key.Hash) == "" {
+		return apikey.APIKey{}, apikey.ErrInvalidDetail
+	}
+
+	query, params, err := dialect.Insert(TABLE_API_KEYS).Rows(
+		goqu.Record{
+			"user_id": key.UserID,
+			"name":    key.Name,
+			"prefix":  key.Prefix,
+			"hash":    key.Hash,
+		}).Returning(&APIKey{}).ToSQL()
+	if err != nil {
+		return apikey.APIKey{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModel APIKey
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_API_KEYS,
+				Operation:  "Create",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&keyModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, errDuplicateKey):
+			return apikey.APIKey{}, apikey.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return apikey.APIKey{}, fmt.Errorf("%w: user doesn't exist", apikey.ErrInvalidDetail)
+		default:
+			return apikey.APIKey{}, err
+		}
+	}
+
+	return keyModel.transformToAPIKey(), nil
+}
+
+func (r APIKeyRepository) Get(ctx context.Context, id string) (apikey.APIKey, error) {
+	return r.getBy(ctx, "Get", goqu.Ex{"id": id})
+}
+
+func (r APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (apikey.APIKey, error) {
+	return r.getBy(ctx, "GetByPrefix", goqu.Ex{"prefix": prefix})
+}
+
+func (r APIKeyRepository) getBy(ctx context.Context, operation string, ex goqu.Ex) (apikey.APIKey, error) {
+	query, params, err := dialect.From(TABLE_API_KEYS).Where(ex).ToSQL()
+	if err != nil {
+		return apikey.APIKey{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModel APIKey
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_API_KEYS,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.GetContext(ctx, &keyModel, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return apikey.APIKey{}, apikey.ErrNotExist
+		default:
+			return apikey.APIKey{}, err
+		}
+	}
+
+	return keyModel.transformToAPIKey(), nil
+}
+
+func (r APIKeyRepository) List(ctx context.Context, userID string) ([]apikey.APIKey, error) {
+	sqlStatement := dialect.From(TABLE_API_KEYS)
+	if userID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"user_id": userID})
+	}
+
+	query, params, err := sqlStatement.Order(goqu.C("created_at").Asc()).ToSQL()
+	if err != nil {
+		return []apikey.APIKey{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModels []APIKey
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_API_KEYS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &keyModels, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return []apikey.APIKey{}, nil
+		case errors.Is(err, errInvalidTexRepresentation):
+			return []apikey.APIKey{}, nil
+		default:
+			return []apikey.APIKey{}, fmt.Errorf("%w: %s", dbErr, err)
+		}
+	}
+
+	var transformedKeys []apikey.APIKey
+	for _, k := range keyModels {
+		transformedKeys = append(transformedKeys, k.transformToAPIKey())
+	}
+
+	return transformedKeys, nil
+}
+
+func (r APIKeyRepository) Revoke(ctx context.Context, id string) (apikey.APIKey, error) {
+	query, params, err := dialect.Update(TABLE_API_KEYS).Set(
+		goqu.Record{
+			"revoked_at": goqu.L("now()"),
+		}).Where(goqu.Ex{
+		"id":         id,
+		"revoked_at": nil,
+	}).Returning(&APIKey{}).ToSQL()
+	if err != nil {
+		return apikey.APIKey{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModel APIKey
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_API_KEYS,
+				Operation:  "Revoke",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&keyModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return apikey.APIKey{}, apikey.ErrNotExist
+		default:
+			return apikey.APIKey{}, err
+		}
+	}
+
+	return keyModel.transformToAPIKey(), nil
+}
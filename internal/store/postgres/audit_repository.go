@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type AuditRepository struct {
+	dbc *db.Client
+}
+
+func NewAuditRepository(dbc *db.Client) *AuditRepository {
+	return &AuditRepository{
+		dbc: dbc,
+	}
+}
+
+func (r AuditRepository) Create(ctx context.Context, log audit.Log) (audit.Log, error) {
+	if strings.TrimSpace(log.Actor) == "" || strings.TrimSpace(log.Action) == "" ||
+		strings.TrimSpace(log.ResourceType) == "" || strings.TrimSpace(log.ResourceID) == "" {
+		return audit.Log{}, audit.ErrInvalidDetail
+	}
+
+	oldPayload, err := marshalPayload(log.OldPayload)
+	if err != nil {
+		return audit.Log{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	newPayload, err := marshalPayload(log.NewPayload)
+	if err != nil {
+		return audit.Log{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	query, params, err := dialect.Insert(TABLE_AUDIT_LOGS).Rows(
+		goqu.Record{
+			"actor":         log.Actor,
+			"action":        log.Action,
+			"resource_type": log.ResourceType,
+			"resource_id":   log.ResourceID,
+			"old_payload":   oldPayload,
+			"new_payload":   newPayload,
+		}).Returning(&AuditLog{}).ToSQL()
+	if err != nil {
+		return audit.Log{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var logModel AuditLog
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_AUDIT_LOGS,
+				Operation:  "Create",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&logModel)
+	}); err != nil {
+		return audit.Log{}, fmt.Errorf("%w: %s", dbErr, checkPostgresError(err))
+	}
+
+	transformedLog, err := logModel.transformToAuditLog()
+	if err != nil {
+		return audit.Log{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return transformedLog, nil
+}
+
+func (r AuditRepository) List(ctx context.Context, flt audit.Filter) ([]audit.Log, error) {
+	sqlStatement := dialect.From(TABLE_AUDIT_LOGS)
+	if flt.Actor != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"actor": flt.Actor})
+	}
+	if !flt.Since.IsZero() {
+		sqlStatement = sqlStatement.Where(goqu.C("created_at").Gte(flt.Since))
+	}
+	if !flt.Until.IsZero() {
+		sqlStatement = sqlStatement.Where(goqu.C("created_at").Lt(flt.Until))
+	}
+
+	query, params, err := sqlStatement.Order(goqu.C("created_at").Desc()).ToSQL()
+	if err != nil {
+		return []audit.Log{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var logModels []AuditLog
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_AUDIT_LOGS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.Reader().SelectContext(ctx, &logModels, query, params...)
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []audit.Log{}, nil
+		}
+		return []audit.Log{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedLogs []audit.Log
+	for _, l := range logModels {
+		transformedLog, err := l.transformToAuditLog()
+		if err != nil {
+			return []audit.Log{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedLogs = append(transformedLogs, transformedLog)
+	}
+
+	return transformedLogs, nil
+}
+
+// marshalPayload keeps a missing payload as NULL rather than a json null,
+// an untyped nil is needed for that as goqu renders a nil []byte as an
+// empty string
+func marshalPayload(payload any) (any, error) {
+	if payload == nil {
+		return nil, nil
+	}
+	return json.Marshal(payload)
+}
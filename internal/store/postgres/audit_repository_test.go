@@ -0,0 +1,173 @@
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/internal/store/postgres"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/ory/dockertest"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditRepositoryTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	client     *db.Client
+	pool       *dockertest.Pool
+	resource   *dockertest.Resource
+	repository *postgres.AuditRepository
+}
+
+func (s *AuditRepositoryTestSuite) SetupSuite() {
+	var err error
+
+	logger := log.NewZap()
+	s.client, s.pool, s.resource, err = newTestClient(logger)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.ctx = context.TODO()
+	s.repository = postgres.NewAuditRepository(s.client)
+}
+
+func (s *AuditRepositoryTestSuite) SetupTest() {
+	logs := []audit.Log{
+		{
+			Actor:        "john.doe@odpf.io",
+			Action:       audit.ActionCreate,
+			ResourceType: "organization",
+			ResourceID:   "org-1",
+			NewPayload:   map[string]any{"name": "org1"},
+		},
+		{
+			Actor:        "jane.dee@odpf.io",
+			Action:       audit.ActionUpdate,
+			ResourceType: "organization",
+			ResourceID:   "org-1",
+			OldPayload:   map[string]any{"name": "org1"},
+			NewPayload:   map[string]any{"name": "org one"},
+		},
+	}
+	for _, l := range logs {
+		if _, err := s.repository.Create(s.ctx, l); err != nil {
+			s.T().Fatal(err)
+		}
+	}
+}
+
+func (s *AuditRepositoryTestSuite) TearDownSuite() {
+	// Clean tests
+	if err := purgeDocker(s.pool, s.resource); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *AuditRepositoryTestSuite) TearDownTest() {
+	if err := s.cleanup(); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *AuditRepositoryTestSuite) cleanup() error {
+	queries := []string{
+		fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE", postgres.TABLE_AUDIT_LOGS),
+	}
+	return execQueries(context.TODO(), s.client, queries)
+}
+
+func (s *AuditRepositoryTestSuite) TestCreate() {
+	type testCase struct {
+		Description string
+		LogToCreate audit.Log
+		ExpectedLog audit.Log
+		Err         error
+	}
+
+	var testCases = []testCase{
+		{
+			Description: "should create a log without an old payload",
+			LogToCreate: audit.Log{
+				Actor:        audit.SystemActor,
+				Action:       audit.ActionCreate,
+				ResourceType: "policy",
+				ResourceID:   "policy-1",
+				NewPayload:   map[string]any{"role_id": "owner"},
+			},
+			ExpectedLog: audit.Log{
+				Actor:        audit.SystemActor,
+				Action:       audit.ActionCreate,
+				ResourceType: "policy",
+				ResourceID:   "policy-1",
+				NewPayload:   map[string]any{"role_id": "owner"},
+			},
+		},
+		{
+			Description: "should return error if resource id is empty",
+			LogToCreate: audit.Log{
+				Actor:        audit.SystemActor,
+				Action:       audit.ActionCreate,
+				ResourceType: "policy",
+			},
+			Err: audit.ErrInvalidDetail,
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.Description, func() {
+			got, err := s.repository.Create(s.ctx, tc.LogToCreate)
+			if tc.Err != nil {
+				s.Assert().ErrorIs(err, tc.Err)
+				return
+			}
+			s.Assert().NoError(err)
+			if !cmp.Equal(got, tc.ExpectedLog, cmpopts.IgnoreFields(audit.Log{}, "ID", "CreatedAt")) {
+				s.T().Fatalf("got result %+v, expected was %+v", got, tc.ExpectedLog)
+			}
+		})
+	}
+}
+
+func (s *AuditRepositoryTestSuite) TestList() {
+	type testCase struct {
+		Description   string
+		Filter        audit.Filter
+		ExpectedCount int
+	}
+
+	var testCases = []testCase{
+		{
+			Description:   "should get all logs",
+			ExpectedCount: 2,
+		},
+		{
+			Description:   "should get the logs of the actor",
+			Filter:        audit.Filter{Actor: "jane.dee@odpf.io"},
+			ExpectedCount: 1,
+		},
+		{
+			Description:   "should get no logs after the time range",
+			Filter:        audit.Filter{Since: time.Now().Add(time.Hour)},
+			ExpectedCount: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.Description, func() {
+			got, err := s.repository.List(s.ctx, tc.Filter)
+			s.Assert().NoError(err)
+			s.Assert().Len(got, tc.ExpectedCount)
+		})
+	}
+}
+
+func TestAuditRepository(t *testing.T) {
+	suite.Run(t, new(AuditRepositoryTestSuite))
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/pkg/db"
+)
+
+// deleteByID removes the row of the table with the id, sql.ErrNoRows is
+// returned when there is no such row and postgres errors go through
+// checkPostgresError so callers can map them to their domain errors
+func deleteByID(ctx context.Context, dbc *db.Client, table, id string) error {
+	query, params, err := dialect.Delete(table).Where(goqu.Ex{
+		"id": id,
+	}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return execAffectingRow(ctx, dbc, table, "Delete", query, params)
+}
+
+// softDeleteByID marks the row of the table with the id as deleted, the
+// row is kept so it can be restored, sql.ErrNoRows is returned when
+// there is no such row or it is already deleted
+func softDeleteByID(ctx context.Context, dbc *db.Client, table, id string) error {
+	query, params, err := dialect.Update(table).Set(goqu.Record{
+		"deleted_at": goqu.L("now()"),
+	}).Where(goqu.Ex{
+		"id":         id,
+		"deleted_at": nil,
+	}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return execAffectingRow(ctx, dbc, table, "SoftDelete", query, params)
+}
+
+// restoreByID clears the deleted mark of the row of the table with the id,
+// sql.ErrNoRows is returned when there is no such row or it isn't deleted
+func restoreByID(ctx context.Context, dbc *db.Client, table, id string) error {
+	query, params, err := dialect.Update(table).Set(goqu.Record{
+		"deleted_at": nil,
+		"updated_at": goqu.L("now()"),
+	}).Where(
+		goqu.Ex{"id": id},
+		goqu.C("deleted_at").IsNotNull(),
+	).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return execAffectingRow(ctx, dbc, table, "Restore", query, params)
+}
+
+// purgeByID removes the row of the table with the id only if it is
+// soft deleted, sql.ErrNoRows is returned otherwise
+func purgeByID(ctx context.Context, dbc *db.Client, table, id string) error {
+	query, params, err := dialect.Delete(table).Where(
+		goqu.Ex{"id": id},
+		goqu.C("deleted_at").IsNotNull(),
+	).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return execAffectingRow(ctx, dbc, table, "Purge", query, params)
+}
+
+func execAffectingRow(ctx context.Context, dbc *db.Client, table, operation, query string, params []interface{}) error {
+	return dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: table,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+
+		result, err := dbc.ExecContext(ctx, query, params...)
+		if err != nil {
+			return checkPostgresError(err)
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
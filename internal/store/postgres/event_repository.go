@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	newrelic "github.com/newrelic/go-agent"
+
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/pkg/db"
+)
+
+// EventRepository is the outbox of the events, they are written along with
+// the changes to the store and published from it by the relay
+type EventRepository struct {
+	dbc *db.Client
+}
+
+func NewEventRepository(dbc *db.Client) *EventRepository {
+	return &EventRepository{
+		dbc: dbc,
+	}
+}
+
+func (r EventRepository) Create(ctx context.Context, msg event.Message) error {
+	if msg.ID == "" || msg.Type == "" || len(msg.Payload) == 0 {
+		return event.ErrInvalidDetail
+	}
+
+	query, params, err := dialect.Insert(TABLE_EVENT_OUTBOX).Rows(
+		goqu.Record{
+			"id":      msg.ID,
+			"type":    msg.Type,
+			"key":     msg.Key,
+			"payload": msg.Payload,
+		}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_EVENT_OUTBOX,
+				Operation:  "Create",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		_, err := r.dbc.ExecContext(ctx, query, params...)
+		return err
+	}); err != nil {
+		return fmt.Errorf("%w: %s", dbErr, checkPostgresError(err))
+	}
+	return nil
+}
+
+// Claim holds the oldest messages available for the lease, the rows locked
+// by another relay are skipped
+func (r EventRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]event.Message, error) {
+	available := dialect.From(TABLE_EVENT_OUTBOX).Select("id").Where(
+		goqu.C("available_at").Lte(goqu.L("now()")),
+	).Order(goqu.C("created_at").Asc()).Limit(uint(limit)).ForUpdate(exp.SkipLocked)
+
+	query, params, err := dialect.Update(TABLE_EVENT_OUTBOX).Set(
+		goqu.Record{
+			"available_at": goqu.L("now() + ?::interval", fmt.Sprintf("%d milliseconds", lease.Milliseconds())),
+			"attempts":     goqu.L("attempts + 1"),
+		}).Where(goqu.C("id").In(available)).Returning(&EventMessage{}).ToSQL()
+	if err != nil {
+		return []event.Message{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var msgModels []EventMessage
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_EVENT_OUTBOX,
+				Operation:  "Claim",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &msgModels, query, params...)
+	}); err != nil {
+		return []event.Message{}, fmt.Errorf("%w: %s", dbErr, checkPostgresError(err))
+	}
+
+	// the rows updated are returned in no particular order
+	sort.SliceStable(msgModels, func(i, j int) bool {
+		return msgModels[i].CreatedAt.Before(msgModels[j].CreatedAt)
+	})
+
+	var transformedMsgs []event.Message
+	for _, m := range msgModels {
+		transformedMsgs = append(transformedMsgs, m.transformToMessage())
+	}
+
+	return transformedMsgs, nil
+}
+
+func (r EventRepository) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query, params, err := dialect.Delete(TABLE_EVENT_OUTBOX).Where(goqu.Ex{"id": ids}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_EVENT_OUTBOX,
+				Operation:  "Delete",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		_, err := r.dbc.ExecContext(ctx, query, params...)
+		return err
+	}); err != nil {
+		return fmt.Errorf("%w: %s", dbErr, checkPostgresError(err))
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/folder"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type FolderRepository struct {
+	dbc *db.Client
+}
+
+func NewFolderRepository(dbc *db.Client) *FolderRepository {
+	return &FolderRepository{
+		dbc: dbc,
+	}
+}
+
+func (r FolderRepository) Create(ctx context.Context, f folder.Folder) (folder.Folder, error) {
+	if strings.TrimSpace(f.Name) == "" || strings.TrimSpace(f.ProjectID) == "" {
+		return folder.Folder{}, folder.ErrInvalidDetail
+	}
+
+	query, params, err := dialect.Insert(TABLE_FOLDERS).Rows(
+		goqu.Record{
+			"name":       f.Name,
+			"project_id": f.ProjectID,
+		}).Returning(&Folder{}).ToSQL()
+	if err != nil {
+		return folder.Folder{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.queryRow(ctx, "Create", query, params)
+}
+
+func (r FolderRepository) Get(ctx context.Context, id string) (folder.Folder, error) {
+	if strings.TrimSpace(id) == "" {
+		return folder.Folder{}, folder.ErrNotExist
+	}
+
+	query, params, err := dialect.From(TABLE_FOLDERS).Where(goqu.Ex{
+		"id": id,
+	}).ToSQL()
+	if err != nil {
+		return folder.Folder{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.queryRow(ctx, "Get", query, params)
+}
+
+func (r FolderRepository) List(ctx context.Context, flt folder.Filter) ([]folder.Folder, error) {
+	sqlStatement := dialect.From(TABLE_FOLDERS)
+	if flt.ProjectID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"project_id": flt.ProjectID})
+	}
+
+	query, params, err := sqlStatement.Order(goqu.C("name").Asc()).ToSQL()
+	if err != nil {
+		return []folder.Folder{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var folderModels []Folder
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_FOLDERS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.Reader().SelectContext(ctx, &folderModels, query, params...)
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []folder.Folder{}, nil
+		}
+		return []folder.Folder{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedFolders []folder.Folder
+	for _, f := range folderModels {
+		transformedFolders = append(transformedFolders, f.transformToFolder())
+	}
+
+	return transformedFolders, nil
+}
+
+func (r FolderRepository) queryRow(ctx context.Context, operation, query string, params []interface{}) (folder.Folder, error) {
+	var folderModel Folder
+	if err := r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_FOLDERS,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&folderModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return folder.Folder{}, folder.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return folder.Folder{}, folder.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return folder.Folder{}, fmt.Errorf("%w: project doesn't exist", folder.ErrInvalidDetail)
+		default:
+			return folder.Folder{}, err
+		}
+	}
+
+	return folderModel.transformToFolder(), nil
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLikePattern(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{glob: "*@gojek.com", want: "%@gojek.com"},
+		{glob: "odpf", want: "odpf"},
+		{glob: "team_*", want: `team\_%`},
+		{glob: "100%", want: `100\%`},
+		{glob: `back\slash*`, want: `back\\slash%`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			assert.Equal(t, tt.want, likePattern(tt.glob))
+		})
+	}
+}
+
+func TestGlobExpressions(t *testing.T) {
+	t.Run("should skip empty globs and match the columns in order", func(t *testing.T) {
+		query, params, err := dialect.From(TABLE_ORGANIZATIONS).Where(
+			globExpressions(map[string]string{"slug": "odpf-*", "name": ""})...,
+		).Where(
+			metadataGlobExpressions("metadata", map[string]string{"team": "data*"})...,
+		).Prepared(true).ToSQL()
+
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM "organizations" WHERE (("slug" ILIKE $1) AND "metadata" ->> $2 ILIKE $3)`, query)
+		assert.Equal(t, []interface{}{"odpf-%", "team", "data%"}, params)
+	})
+}
@@ -14,6 +14,7 @@ type Group struct {
 	Slug      string       `db:"slug"`
 	OrgID     string       `db:"org_id"`
 	Metadata  []byte       `db:"metadata"`
+	Version   int64        `db:"version"`
 	CreatedAt time.Time    `db:"created_at"`
 	UpdatedAt time.Time    `db:"updated_at"`
 	DeletedAt sql.NullTime `db:"deleted_at"`
@@ -31,6 +32,7 @@ func (from Group) transformToGroup() (group.Group, error) {
 		Slug:           from.Slug,
 		OrganizationID: from.OrgID,
 		Metadata:       unmarshalledMetadata,
+		Version:        from.Version,
 		CreatedAt:      from.CreatedAt,
 		UpdatedAt:      from.UpdatedAt,
 	}, nil
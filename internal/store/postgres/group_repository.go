@@ -55,7 +55,7 @@ func (r GroupRepository) GetByID(ctx context.Context, id string) (group.Group, e
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &groupModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &groupModel, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -102,7 +102,7 @@ func (r GroupRepository) GetBySlug(ctx context.Context, slug string) (group.Grou
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &groupModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &groupModel, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -144,7 +144,7 @@ func (r GroupRepository) GetByIDs(ctx context.Context, groupIDs []string) ([]gro
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedGroups, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedGroups, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -232,7 +232,12 @@ func (r GroupRepository) List(ctx context.Context, flt group.Filter) ([]group.Gr
 	if flt.OrganizationID != "" {
 		sqlStatement = sqlStatement.Where(goqu.Ex{"org_id": flt.OrganizationID})
 	}
-	query, params, err := sqlStatement.ToSQL()
+	sqlStatement = sqlStatement.Where(
+		globExpressions(map[string]string{"name": flt.Name, "slug": flt.Slug})...,
+	).Where(
+		metadataGlobExpressions("metadata", flt.Metadata)...,
+	)
+	query, params, err := paginate(sqlStatement, flt.Limit, flt.Page).ToSQL()
 	if err != nil {
 		return []group.Group{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -250,7 +255,7 @@ func (r GroupRepository) List(ctx context.Context, flt group.Filter) ([]group.Gr
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedGroups, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedGroups, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -289,16 +294,17 @@ func (r GroupRepository) UpdateByID(ctx context.Context, grp group.Group) (group
 		return group.Group{}, fmt.Errorf("%w: %s", parseErr, err)
 	}
 
-	query, params, err := dialect.Update(TABLE_GROUPS).Set(
-		goqu.Record{
-			"name":       grp.Name,
-			"slug":       grp.Slug,
-			"org_id":     grp.OrganizationID,
-			"metadata":   marshaledMetadata,
-			"updated_at": goqu.L("now()"),
-		}).Where(goqu.ExOr{
+	current := goqu.Ex{
 		"id": grp.ID,
-	}).Returning(&Group{}).ToSQL()
+	}
+	record, where := versioned(goqu.Record{
+		"name":       grp.Name,
+		"slug":       grp.Slug,
+		"org_id":     grp.OrganizationID,
+		"metadata":   marshaledMetadata,
+		"updated_at": goqu.L("now()"),
+	}, current, grp.Version)
+	query, params, err := dialect.Update(TABLE_GROUPS).Set(record).Where(where).Returning(&Group{}).ToSQL()
 	if err != nil {
 		return group.Group{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -321,6 +327,9 @@ func (r GroupRepository) UpdateByID(ctx context.Context, grp group.Group) (group
 		err = checkPostgresError(err)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_GROUPS, current, grp.Version), errVersionMismatch) {
+				return group.Group{}, group.ErrVersionMismatch
+			}
 			return group.Group{}, group.ErrNotExist
 		case errors.Is(err, errInvalidTexRepresentation):
 			return group.Group{}, group.ErrInvalidUUID
@@ -355,15 +364,16 @@ func (r GroupRepository) UpdateBySlug(ctx context.Context, grp group.Group) (gro
 		return group.Group{}, fmt.Errorf("%w: %s", parseErr, err)
 	}
 
-	query, params, err := dialect.Update(TABLE_GROUPS).Set(
-		goqu.Record{
-			"name":       grp.Name,
-			"org_id":     grp.OrganizationID,
-			"metadata":   marshaledMetadata,
-			"updated_at": goqu.L("now()"),
-		}).Where(goqu.Ex{
+	current := goqu.Ex{
 		"slug": grp.Slug,
-	}).Returning(&Group{}).ToSQL()
+	}
+	record, where := versioned(goqu.Record{
+		"name":       grp.Name,
+		"org_id":     grp.OrganizationID,
+		"metadata":   marshaledMetadata,
+		"updated_at": goqu.L("now()"),
+	}, current, grp.Version)
+	query, params, err := dialect.Update(TABLE_GROUPS).Set(record).Where(where).Returning(&Group{}).ToSQL()
 	if err != nil {
 		return group.Group{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -386,6 +396,9 @@ func (r GroupRepository) UpdateBySlug(ctx context.Context, grp group.Group) (gro
 		err = checkPostgresError(err)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_GROUPS, current, grp.Version), errVersionMismatch) {
+				return group.Group{}, group.ErrVersionMismatch
+			}
 			return group.Group{}, group.ErrNotExist
 		case errors.Is(err, errInvalidTexRepresentation):
 			return group.Group{}, organization.ErrInvalidUUID
@@ -457,7 +470,7 @@ func (r GroupRepository) ListUsersByGroupID(ctx context.Context, groupID string,
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedUsers, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedUsers, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -508,7 +521,7 @@ func (r GroupRepository) ListUsersByGroupSlug(ctx context.Context, groupSlug str
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedUsers, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedUsers, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -580,7 +593,7 @@ func (r GroupRepository) ListUserGroups(ctx context.Context, userID string, role
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedGroups, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedGroups, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return []group.Group{}, nil
@@ -636,7 +649,7 @@ func (r GroupRepository) ListGroupRelations(ctx context.Context, objectId string
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedRelations, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedRelations, query, params...)
 	}); err != nil {
 		// List should return empty list and no error instead
 		if errors.Is(err, sql.ErrNoRows) {
@@ -652,3 +665,24 @@ func (r GroupRepository) ListGroupRelations(ctx context.Context, objectId string
 
 	return transformedRelations, nil
 }
+
+func (r GroupRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return group.ErrInvalidID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_GROUPS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return group.ErrNotExist
+		case errors.Is(err, errInvalidTexRepresentation):
+			return group.ErrInvalidUUID
+		case errors.Is(err, errForeignKeyViolation):
+			return group.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type IdempotencyRepository struct {
+	dbc *db.Client
+}
+
+func NewIdempotencyRepository(dbc *db.Client) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		dbc: dbc,
+	}
+}
+
+// Reserve inserts the key, or takes over the same key once it expired, and
+// reads the recorded key when neither was possible
+func (r IdempotencyRepository) Reserve(ctx context.Context, key idempotency.Key) (idempotency.Key, bool, error) {
+	query, params, err := dialect.Insert(TABLE_IDEMPOTENCY_KEYS).Rows(
+		goqu.Record{
+			"key":          key.Key,
+			"identity":     key.Identity,
+			"method":       key.Method,
+			"request_hash": key.RequestHash,
+			"expires_at":   key.ExpiresAt,
+		}).OnConflict(goqu.DoUpdate("identity, method, key", goqu.Record{
+		"request_hash": goqu.L("EXCLUDED.request_hash"),
+		"response":     nil,
+		"created_at":   goqu.L("now()"),
+		"completed_at": nil,
+		"expires_at":   goqu.L("EXCLUDED.expires_at"),
+	}).Where(goqu.I(TABLE_IDEMPOTENCY_KEYS + ".expires_at").Lte(goqu.L("now()")))).
+		Returning(&IdempotencyKey{}).ToSQL()
+	if err != nil {
+		return idempotency.Key{}, false, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModel IdempotencyKey
+	err = r.queryRow(ctx, "Reserve", query, params, &keyModel)
+	if err == nil {
+		return keyModel.transformToIdempotencyKey(), true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return idempotency.Key{}, false, err
+	}
+
+	query, params, err = dialect.From(TABLE_IDEMPOTENCY_KEYS).Where(goqu.Ex{
+		"identity": key.Identity,
+		"method":   key.Method,
+		"key":      key.Key,
+	}).ToSQL()
+	if err != nil {
+		return idempotency.Key{}, false, fmt.Errorf("%w: %s", queryErr, err)
+	}
+	if err := r.queryRow(ctx, "Get", query, params, &keyModel); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// released by the request which reserved it in the meantime
+			return idempotency.Key{}, false, idempotency.ErrInProgress
+		}
+		return idempotency.Key{}, false, err
+	}
+	return keyModel.transformToIdempotencyKey(), false, nil
+}
+
+func (r IdempotencyRepository) Complete(ctx context.Context, key idempotency.Key) error {
+	query, params, err := dialect.Update(TABLE_IDEMPOTENCY_KEYS).Set(
+		goqu.Record{
+			"response":     key.Response,
+			"completed_at": goqu.L("now()"),
+		}).Where(goqu.Ex{
+		"identity":     key.Identity,
+		"method":       key.Method,
+		"key":          key.Key,
+		"request_hash": key.RequestHash,
+		"completed_at": nil,
+	}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return execAffectingRow(ctx, r.dbc, TABLE_IDEMPOTENCY_KEYS, "Complete", query, params)
+}
+
+func (r IdempotencyRepository) Release(ctx context.Context, key idempotency.Key) error {
+	query, params, err := dialect.Delete(TABLE_IDEMPOTENCY_KEYS).Where(goqu.Ex{
+		"identity":     key.Identity,
+		"method":       key.Method,
+		"key":          key.Key,
+		"request_hash": key.RequestHash,
+		"completed_at": nil,
+	}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	if err := execAffectingRow(ctx, r.dbc, TABLE_IDEMPOTENCY_KEYS, "Release", query, params); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+func (r IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query, params, err := dialect.Delete(TABLE_IDEMPOTENCY_KEYS).Where(
+		goqu.C("expires_at").Lte(before),
+	).ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var count int64
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_IDEMPOTENCY_KEYS,
+				Operation:  "DeleteExpired",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+
+		result, err := r.dbc.ExecContext(ctx, query, params...)
+		if err != nil {
+			return err
+		}
+		count, err = result.RowsAffected()
+		return err
+	}); err != nil {
+		return 0, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return count, nil
+}
+
+func (r IdempotencyRepository) queryRow(ctx context.Context, operation, query string, params []interface{}, dest *IdempotencyKey) error {
+	return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_IDEMPOTENCY_KEYS,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(dest)
+	})
+}
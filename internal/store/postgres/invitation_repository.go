@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/invitation"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type InvitationRepository struct {
+	dbc *db.Client
+}
+
+func NewInvitationRepository(dbc *db.Client) *InvitationRepository {
+	return &InvitationRepository{
+		dbc: dbc,
+	}
+}
+
+func (r InvitationRepository) Create(ctx context.Context, inv invitation.Invitation) (invitation.Invitation, error) {
+	if strings.TrimSpace(inv.OrgID) == "" || strings.TrimSpace(inv.Email) == "" || inv.ExpiresAt.IsZero() {
+		return invitation.Invitation{}, invitation.ErrInvalidDetail
+	}
+
+	groupIDs := inv.GroupIDs
+	if groupIDs == nil {
+		groupIDs = []string{}
+	}
+	marshaledGroupIDs, err := json.Marshal(groupIDs)
+	if err != nil {
+		return invitation.Invitation{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	query, params, err := dialect.Insert(TABLE_INVITATIONS).Rows(
+		goqu.Record{
+			"org_id":     inv.OrgID,
+			"email":      inv.Email,
+			"group_ids":  marshaledGroupIDs,
+			"invited_by": inv.InvitedBy,
+			"expires_at": inv.ExpiresAt,
+		}).Returning(&Invitation{}).ToSQL()
+	if err != nil {
+		return invitation.Invitation{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.queryRow(ctx, "Create", query, params)
+}
+
+func (r InvitationRepository) Get(ctx context.Context, id string) (invitation.Invitation, error) {
+	if strings.TrimSpace(id) == "" {
+		return invitation.Invitation{}, invitation.ErrNotExist
+	}
+
+	query, params, err := dialect.From(TABLE_INVITATIONS).Where(goqu.Ex{
+		"id": id,
+	}).ToSQL()
+	if err != nil {
+		return invitation.Invitation{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.queryRow(ctx, "Get", query, params)
+}
+
+func (r InvitationRepository) List(ctx context.Context, flt invitation.Filter) ([]invitation.Invitation, error) {
+	sqlStatement := dialect.From(TABLE_INVITATIONS)
+	if flt.OrgID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"org_id": flt.OrgID})
+	}
+	if flt.Email != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"email": flt.Email})
+	}
+
+	query, params, err := sqlStatement.Order(goqu.C("created_at").Desc()).ToSQL()
+	if err != nil {
+		return []invitation.Invitation{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var invitationModels []Invitation
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_INVITATIONS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &invitationModels, query, params...)
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []invitation.Invitation{}, nil
+		}
+		return []invitation.Invitation{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedInvitations []invitation.Invitation
+	for _, i := range invitationModels {
+		transformedInvitation, err := i.transformToInvitation()
+		if err != nil {
+			return []invitation.Invitation{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedInvitations = append(transformedInvitations, transformedInvitation)
+	}
+
+	return transformedInvitations, nil
+}
+
+func (r InvitationRepository) Accept(ctx context.Context, id string) (invitation.Invitation, error) {
+	return r.close(ctx, "Accept", "accepted_at", id)
+}
+
+func (r InvitationRepository) Revoke(ctx context.Context, id string) (invitation.Invitation, error) {
+	return r.close(ctx, "Revoke", "revoked_at", id)
+}
+
+// close sets the column to now if the invitation is neither accepted nor
+// revoked yet, so two requests can't both accept or revoke it
+func (r InvitationRepository) close(ctx context.Context, operation, column, id string) (invitation.Invitation, error) {
+	query, params, err := dialect.Update(TABLE_INVITATIONS).Set(
+		goqu.Record{
+			column: goqu.L("now()"),
+		}).Where(goqu.Ex{
+		"id":          id,
+		"accepted_at": nil,
+		"revoked_at":  nil,
+	}).Returning(&Invitation{}).ToSQL()
+	if err != nil {
+		return invitation.Invitation{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	inv, err := r.queryRow(ctx, operation, query, params)
+	if errors.Is(err, invitation.ErrNotExist) {
+		return invitation.Invitation{}, invitation.ErrNotPending
+	}
+	return inv, err
+}
+
+func (r InvitationRepository) queryRow(ctx context.Context, operation, query string, params []interface{}) (invitation.Invitation, error) {
+	var invitationModel Invitation
+	if err := r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_INVITATIONS,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&invitationModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return invitation.Invitation{}, invitation.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return invitation.Invitation{}, fmt.Errorf("%w: organization doesn't exist", invitation.ErrInvalidDetail)
+		default:
+			return invitation.Invitation{}, err
+		}
+	}
+
+	transformedInvitation, err := invitationModel.transformToInvitation()
+	if err != nil {
+		return invitation.Invitation{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedInvitation, nil
+}
@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/odpf/shield/core/metaschema"
+)
+
+type MetaSchema struct {
+	Entity    string    `db:"entity"`
+	Schema    string    `db:"schema"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (from MetaSchema) transformToMetaSchema() metaschema.MetaSchema {
+	return metaschema.MetaSchema{
+		Entity:    from.Entity,
+		Schema:    from.Schema,
+		CreatedAt: from.CreatedAt,
+		UpdatedAt: from.UpdatedAt,
+	}
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/metaschema"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type MetaSchemaRepository struct {
+	dbc *db.Client
+}
+
+func NewMetaSchemaRepository(dbc *db.Client) *MetaSchemaRepository {
+	return &MetaSchemaRepository{
+		dbc: dbc,
+	}
+}
+
+func (r MetaSchemaRepository) Upsert(ctx context.Context, ms metaschema.MetaSchema) (metaschema.MetaSchema, error) {
+	query, params, err := dialect.Insert(TABLE_METADATA_SCHEMAS).Rows(
+		goqu.Record{
+			"entity": ms.Entity,
+			"schema": ms.Schema,
+		}).OnConflict(goqu.DoUpdate("entity", goqu.Record{
+		"schema":     goqu.L("EXCLUDED.schema"),
+		"updated_at": goqu.L("now()"),
+	})).Returning(&MetaSchema{}).ToSQL()
+	if err != nil {
+		return metaschema.MetaSchema{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.queryRow(ctx, "Upsert", query, params)
+}
+
+func (r MetaSchemaRepository) Get(ctx context.Context, entity string) (metaschema.MetaSchema, error) {
+	query, params, err := dialect.From(TABLE_METADATA_SCHEMAS).Where(goqu.Ex{
+		"entity": entity,
+	}).ToSQL()
+	if err != nil {
+		return metaschema.MetaSchema{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.queryRow(ctx, "Get", query, params)
+}
+
+func (r MetaSchemaRepository) List(ctx context.Context) ([]metaschema.MetaSchema, error) {
+	query, params, err := dialect.From(TABLE_METADATA_SCHEMAS).Order(goqu.C("entity").Asc()).ToSQL()
+	if err != nil {
+		return []metaschema.MetaSchema{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var schemaModels []MetaSchema
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_METADATA_SCHEMAS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.Reader().SelectContext(ctx, &schemaModels, query, params...)
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []metaschema.MetaSchema{}, nil
+		}
+		return []metaschema.MetaSchema{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedSchemas []metaschema.MetaSchema
+	for _, ms := range schemaModels {
+		transformedSchemas = append(transformedSchemas, ms.transformToMetaSchema())
+	}
+
+	return transformedSchemas, nil
+}
+
+func (r MetaSchemaRepository) queryRow(ctx context.Context, operation, query string, params []interface{}) (metaschema.MetaSchema, error) {
+	var schemaModel MetaSchema
+	if err := r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_METADATA_SCHEMAS,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&schemaModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return metaschema.MetaSchema{}, metaschema.ErrNotExist
+		default:
+			return metaschema.MetaSchema{}, fmt.Errorf("%w: %s", dbErr, err)
+		}
+	}
+
+	return schemaModel.transformToMetaSchema(), nil
+}
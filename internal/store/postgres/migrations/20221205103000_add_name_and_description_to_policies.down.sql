@@ -0,0 +1,3 @@
+ALTER TABLE policies
+DROP COLUMN name,
+DROP COLUMN description;
@@ -0,0 +1,3 @@
+ALTER TABLE policies
+ADD COLUMN name varchar,
+ADD COLUMN description varchar;
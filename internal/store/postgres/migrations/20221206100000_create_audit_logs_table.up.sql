@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS audit_logs
+(
+    id            uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    actor         VARCHAR     NOT NULL,
+    action        VARCHAR     NOT NULL,
+    resource_type VARCHAR     NOT NULL,
+    resource_id   VARCHAR     NOT NULL,
+    old_payload   jsonb,
+    new_payload   jsonb,
+    created_at    timestamptz NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS audit_logs_created_at_idx ON audit_logs (created_at);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_idx ON audit_logs (actor);
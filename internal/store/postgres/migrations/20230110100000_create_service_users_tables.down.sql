@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS service_user_keys;
+DROP TABLE IF EXISTS service_users;
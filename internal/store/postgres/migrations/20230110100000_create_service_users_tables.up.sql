@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS service_users
+(
+    id         uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    user_id    uuid        NOT NULL UNIQUE REFERENCES users (id),
+    name       VARCHAR     NOT NULL UNIQUE,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS service_user_keys
+(
+    id              uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    service_user_id uuid        NOT NULL REFERENCES service_users (id) ON DELETE CASCADE,
+    algorithm       VARCHAR     NOT NULL,
+    public_key      TEXT        NOT NULL,
+    created_at      timestamptz NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS service_user_keys_service_user_id_idx ON service_user_keys (service_user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id         uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    user_id    uuid        NOT NULL REFERENCES users (id),
+    name       VARCHAR     NOT NULL,
+    prefix     VARCHAR     NOT NULL UNIQUE,
+    hash       VARCHAR     NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    revoked_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
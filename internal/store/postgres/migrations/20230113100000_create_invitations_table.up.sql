@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS invitations
+(
+    id          uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    org_id      uuid        NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    email       VARCHAR     NOT NULL,
+    group_ids   jsonb       NOT NULL DEFAULT '[]',
+    invited_by  VARCHAR     NOT NULL,
+    expires_at  timestamptz NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT NOW(),
+    accepted_at timestamptz,
+    revoked_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS invitations_org_id_idx ON invitations (org_id);
+CREATE INDEX IF NOT EXISTS invitations_email_idx ON invitations (email);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS organizations_parent_id_idx;
+ALTER TABLE organizations DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,2 @@
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS parent_id uuid REFERENCES organizations (id);
+CREATE INDEX IF NOT EXISTS organizations_parent_id_idx ON organizations (parent_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS roles_org_id_idx;
+ALTER TABLE roles DROP COLUMN IF EXISTS org_id;
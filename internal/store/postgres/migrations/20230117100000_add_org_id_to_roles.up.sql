@@ -0,0 +1,2 @@
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS org_id uuid REFERENCES organizations (id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS roles_org_id_idx ON roles (org_id);
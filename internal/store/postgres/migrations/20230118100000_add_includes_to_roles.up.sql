@@ -0,0 +1 @@
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS includes VARCHAR[];
@@ -0,0 +1 @@
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS effect VARCHAR NOT NULL DEFAULT 'allow';
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id         uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    url        VARCHAR     NOT NULL,
+    secret     VARCHAR     NOT NULL,
+    events     VARCHAR[]   NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    webhook_id      uuid        NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_type      VARCHAR     NOT NULL,
+    payload         jsonb       NOT NULL,
+    status          VARCHAR     NOT NULL DEFAULT 'pending',
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    last_error      VARCHAR     NOT NULL DEFAULT '',
+    next_attempt_at timestamptz NOT NULL DEFAULT NOW(),
+    created_at      timestamptz NOT NULL DEFAULT NOW(),
+    delivered_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS event_outbox
+(
+    id           uuid PRIMARY KEY,
+    type         VARCHAR     NOT NULL,
+    key          VARCHAR     NOT NULL,
+    payload      jsonb       NOT NULL,
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    available_at timestamptz NOT NULL DEFAULT NOW(),
+    created_at   timestamptz NOT NULL DEFAULT clock_timestamp()
+);
+CREATE INDEX IF NOT EXISTS event_outbox_created_at_idx ON event_outbox (created_at);
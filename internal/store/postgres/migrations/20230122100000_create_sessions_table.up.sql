@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id                uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    user_id           uuid        NOT NULL REFERENCES users (id),
+    user_agent        VARCHAR     NOT NULL DEFAULT '',
+    access_hash       VARCHAR     NOT NULL UNIQUE,
+    access_expires_at timestamptz NOT NULL,
+    refresh_hash      VARCHAR     NOT NULL UNIQUE,
+    expires_at        timestamptz NOT NULL,
+    created_at        timestamptz NOT NULL DEFAULT NOW(),
+    refreshed_at      timestamptz NOT NULL DEFAULT NOW(),
+    revoked_at        timestamptz
+);
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS folders
+(
+    id         uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    name       VARCHAR     NOT NULL,
+    project_id uuid        NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NOT NULL DEFAULT NOW(),
+    UNIQUE (project_id, name)
+);
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS folder_id uuid REFERENCES folders (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS resources_folder_id_idx ON resources (folder_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS resource_tag_policy_grants;
+DROP TABLE IF EXISTS resource_tag_policies;
+ALTER TABLE resources DROP COLUMN IF EXISTS tags;
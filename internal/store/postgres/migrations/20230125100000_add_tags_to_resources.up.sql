@@ -0,0 +1,21 @@
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS tags jsonb NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS resources_tags_idx ON resources USING GIN (tags);
+
+CREATE TABLE IF NOT EXISTS resource_tag_policies
+(
+    id                   uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    namespace_id         VARCHAR     NOT NULL REFERENCES namespaces (id) ON DELETE CASCADE,
+    tags                 jsonb       NOT NULL,
+    role_id              VARCHAR     NOT NULL,
+    subject_namespace_id VARCHAR     NOT NULL,
+    subject_id           VARCHAR     NOT NULL,
+    created_at           timestamptz NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS resource_tag_policies_namespace_id_idx ON resource_tag_policies (namespace_id);
+
+CREATE TABLE IF NOT EXISTS resource_tag_policy_grants
+(
+    policy_id   uuid NOT NULL REFERENCES resource_tag_policies (id) ON DELETE CASCADE,
+    resource_id uuid NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    PRIMARY KEY (policy_id, resource_id)
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS organization_usage
+(
+    org_id          uuid   NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    day             date   NOT NULL,
+    api_calls       BIGINT NOT NULL DEFAULT 0,
+    relation_writes BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (org_id, day)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS rules
+(
+    id              uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    name            VARCHAR     NOT NULL,
+    proxy           VARCHAR     NOT NULL,
+    frontend_url    VARCHAR     NOT NULL,
+    frontend_method VARCHAR     NOT NULL DEFAULT '',
+    backend_url     VARCHAR     NOT NULL,
+    backend_name    VARCHAR     NOT NULL DEFAULT '',
+    backend_prefix  VARCHAR     NOT NULL DEFAULT '',
+    middlewares     jsonb       NOT NULL DEFAULT '[]',
+    hooks           jsonb       NOT NULL DEFAULT '[]',
+    created_at      timestamptz NOT NULL DEFAULT NOW(),
+    updated_at      timestamptz NOT NULL DEFAULT NOW(),
+    UNIQUE (proxy, name)
+);
@@ -0,0 +1 @@
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS frontend_max_body_size BIGINT NOT NULL DEFAULT 0;
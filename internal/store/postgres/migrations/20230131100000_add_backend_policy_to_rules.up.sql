@@ -0,0 +1 @@
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS backend_policy JSONB NOT NULL DEFAULT '{}'::jsonb;
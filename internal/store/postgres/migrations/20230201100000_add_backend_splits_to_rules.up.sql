@@ -0,0 +1 @@
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS backend_splits JSONB NOT NULL DEFAULT '[]'::jsonb;
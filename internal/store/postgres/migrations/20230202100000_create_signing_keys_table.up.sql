@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS signing_keys
+(
+    id           uuid PRIMARY KEY,
+    algorithm    VARCHAR     NOT NULL,
+    public_key   TEXT        NOT NULL,
+    private_key  TEXT        NOT NULL,
+    created_at   timestamptz NOT NULL DEFAULT NOW(),
+    activates_at timestamptz NOT NULL
+);
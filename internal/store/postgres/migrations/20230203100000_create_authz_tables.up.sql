@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS authz_schema
+(
+    id         INT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    schema     TEXT        NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS authz_tuples
+(
+    resource_type    VARCHAR     NOT NULL,
+    resource_id      VARCHAR     NOT NULL,
+    relation         VARCHAR     NOT NULL,
+    subject_type     VARCHAR     NOT NULL,
+    subject_id       VARCHAR     NOT NULL,
+    subject_relation VARCHAR     NOT NULL DEFAULT '',
+    created_at       timestamptz NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (resource_type, resource_id, relation, subject_type, subject_id, subject_relation)
+);
+CREATE INDEX IF NOT EXISTS authz_tuples_subject_idx ON authz_tuples (subject_type, subject_id);
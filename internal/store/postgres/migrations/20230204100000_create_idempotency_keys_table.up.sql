@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    key          VARCHAR(255) NOT NULL,
+    identity     VARCHAR      NOT NULL,
+    method       VARCHAR      NOT NULL,
+    request_hash VARCHAR      NOT NULL,
+    response     BYTEA,
+    created_at   timestamptz  NOT NULL DEFAULT NOW(),
+    completed_at timestamptz,
+    expires_at   timestamptz  NOT NULL,
+    PRIMARY KEY (identity, method, key)
+);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,4 @@
+ALTER TABLE policies DROP COLUMN IF EXISTS version;
+ALTER TABLE groups DROP COLUMN IF EXISTS version;
+ALTER TABLE projects DROP COLUMN IF EXISTS version;
+ALTER TABLE organizations DROP COLUMN IF EXISTS version;
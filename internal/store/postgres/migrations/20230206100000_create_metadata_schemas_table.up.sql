@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS metadata_schemas
+(
+    entity     VARCHAR PRIMARY KEY,
+    schema     JSONB       NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NOT NULL DEFAULT NOW()
+);
@@ -37,7 +37,7 @@ func (r NamespaceRepository) Get(ctx context.Context, id string) (namespace.Name
 
 	var fetchedNamespace Namespace
 	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
-		return r.dbc.GetContext(ctx, &fetchedNamespace, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &fetchedNamespace, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return namespace.Namespace{}, namespace.ErrNotExist
@@ -121,7 +121,7 @@ func (r NamespaceRepository) List(ctx context.Context) ([]namespace.Namespace, e
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedNamespaces, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedNamespaces, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return []namespace.Namespace{}, nil
@@ -190,3 +190,22 @@ func (r NamespaceRepository) Update(ctx context.Context, ns namespace.Namespace)
 
 	return nsModel.transformToNamespace(), nil
 }
+
+func (r NamespaceRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return namespace.ErrInvalidID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_NAMESPACES, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return namespace.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return namespace.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+// maxObjectLookup is the most ids looked up in a single query
+const maxObjectLookup = 1000
+
+// objectTables are the tables of the objects of the predefined namespaces,
+// the objects of the other namespaces are resources
+var objectTables = map[string]string{
+	schema.OrganizationNamespace: TABLE_ORGANIZATIONS,
+	schema.ProjectNamespace:      TABLE_PROJECTS,
+	schema.GroupNamespace:        TABLE_GROUPS,
+	schema.UserPrincipal:         TABLE_USERS,
+}
+
+// ObjectRepository looks up the objects of the namespaces in the tables
+// they are stored in, deleted objects exist until they are purged
+type ObjectRepository struct {
+	dbc *db.Client
+}
+
+func NewObjectRepository(dbc *db.Client) *ObjectRepository {
+	return &ObjectRepository{
+		dbc: dbc,
+	}
+}
+
+// ExistingIDs returns which of the ids refer to an object of the namespace,
+// ids which can't be the id of a row of the table, like the wildcard, are
+// reported as existing
+func (r ObjectRepository) ExistingIDs(ctx context.Context, namespaceID string, ids []string) (map[string]bool, error) {
+	table, predefined := objectTables[namespaceID]
+	if !predefined {
+		table = TABLE_RESOURCES
+	}
+
+	existing := map[string]bool{}
+	var lookup []string
+	for _, id := range ids {
+		if _, seen := existing[id]; seen {
+			continue
+		}
+		if id == "*" || (predefined && !uuid.IsValid(id)) {
+			existing[id] = true
+			continue
+		}
+		existing[id] = false
+		lookup = append(lookup, id)
+	}
+
+	for start := 0; start < len(lookup); start += maxObjectLookup {
+		end := start + maxObjectLookup
+		if end > len(lookup) {
+			end = len(lookup)
+		}
+
+		where := goqu.Ex{"id": lookup[start:end]}
+		if !predefined {
+			where["namespace_id"] = namespaceID
+		}
+		query, params, err := dialect.From(table).Select("id").Where(where).ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", queryErr, err)
+		}
+
+		var found []string
+		if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+			nrCtx := newrelic.FromContext(ctx)
+			if nrCtx != nil {
+				nr := newrelic.DatastoreSegment{
+					Product:    newrelic.DatastorePostgres,
+					Collection: table,
+					Operation:  "ExistingIDs",
+					StartTime:  nrCtx.StartSegmentNow(),
+				}
+				defer nr.End()
+			}
+			return r.dbc.SelectContext(ctx, &found, query, params...)
+		}); err != nil {
+			return nil, fmt.Errorf("%w: %s", dbErr, err)
+		}
+
+		for _, id := range found {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+// OrgIDOf returns the organization the object belongs to, an organization
+// belongs to itself. Users and the objects which don't exist belong to none.
+func (r ObjectRepository) OrgIDOf(ctx context.Context, namespaceID, id string) (string, error) {
+	switch namespaceID {
+	case schema.OrganizationNamespace:
+		return id, nil
+	case schema.UserPrincipal:
+		return "", nil
+	}
+	if !uuid.IsValid(id) {
+		return "", nil
+	}
+
+	table, predefined := objectTables[namespaceID]
+	where := goqu.Ex{"id": id}
+	if !predefined {
+		table = TABLE_RESOURCES
+		where["namespace_id"] = namespaceID
+	}
+	query, params, err := dialect.From(table).Select("org_id").Where(where).ToSQL()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	// the organization of the resources is optional
+	var orgIDs []sql.NullString
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: table,
+				Operation:  "OrgIDOf",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &orgIDs, query, params...)
+	}); err != nil {
+		return "", fmt.Errorf("%w: %s", dbErr, err)
+	}
+	if len(orgIDs) == 0 {
+		return "", nil
+	}
+	return orgIDs[0].String, nil
+}
@@ -10,13 +10,15 @@ import (
 )
 
 type Organization struct {
-	ID        string       `db:"id"`
-	Name      string       `db:"name"`
-	Slug      string       `db:"slug"`
-	Metadata  []byte       `db:"metadata"`
-	CreatedAt time.Time    `db:"created_at"`
-	UpdatedAt time.Time    `db:"updated_at"`
-	DeletedAt sql.NullTime `db:"deleted_at"`
+	ID        string         `db:"id"`
+	Name      string         `db:"name"`
+	Slug      string         `db:"slug"`
+	Metadata  []byte         `db:"metadata"`
+	ParentID  sql.NullString `db:"parent_id"`
+	Version   int64          `db:"version"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+	DeletedAt sql.NullTime   `db:"deleted_at"`
 }
 
 func (from Organization) transformToOrg() (organization.Organization, error) {
@@ -30,7 +32,10 @@ func (from Organization) transformToOrg() (organization.Organization, error) {
 		Name:      from.Name,
 		Slug:      from.Slug,
 		Metadata:  unmarshalledMetadata,
+		ParentID:  from.ParentID.String,
+		Version:   from.Version,
 		CreatedAt: from.CreatedAt,
 		UpdatedAt: from.UpdatedAt,
+		DeletedAt: from.DeletedAt.Time,
 	}, nil
 }
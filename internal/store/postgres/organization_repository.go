@@ -7,8 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	newrelic "github.com/newrelic/go-agent"
 	"github.com/odpf/shield/core/organization"
 	"github.com/odpf/shield/core/user"
@@ -51,7 +53,7 @@ func (r OrganizationRepository) GetByID(ctx context.Context, id string) (organiz
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &orgModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &orgModel, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -96,7 +98,7 @@ func (r OrganizationRepository) GetBySlug(ctx context.Context, slug string) (org
 			}
 			defer nr.End()
 		}
-		return r.dbc.GetContext(ctx, &orgModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &orgModel, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -127,12 +129,16 @@ func (r OrganizationRepository) Create(ctx context.Context, org organization.Org
 		return organization.Organization{}, fmt.Errorf("%w: %s", parseErr, err)
 	}
 
-	query, params, err := dialect.Insert(TABLE_ORGANIZATIONS).Rows(
-		goqu.Record{
-			"name":     org.Name,
-			"slug":     org.Slug,
-			"metadata": marshaledMetadata,
-		}).Returning(&Organization{}).ToSQL()
+	record := goqu.Record{
+		"name":     org.Name,
+		"slug":     org.Slug,
+		"metadata": marshaledMetadata,
+	}
+	if org.ParentID != "" {
+		record["parent_id"] = org.ParentID
+	}
+
+	query, params, err := dialect.Insert(TABLE_ORGANIZATIONS).Rows(record).Returning(&Organization{}).ToSQL()
 	if err != nil {
 		return organization.Organization{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -155,6 +161,8 @@ func (r OrganizationRepository) Create(ctx context.Context, org organization.Org
 		switch {
 		case errors.Is(err, errDuplicateKey):
 			return organization.Organization{}, organization.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return organization.Organization{}, organization.ErrInvalidParent
 		default:
 			return organization.Organization{}, err
 		}
@@ -168,8 +176,23 @@ func (r OrganizationRepository) Create(ctx context.Context, org organization.Org
 	return transformedOrg, nil
 }
 
-func (r OrganizationRepository) List(ctx context.Context) ([]organization.Organization, error) {
-	query, params, err := dialect.From(TABLE_ORGANIZATIONS).ToSQL()
+func (r OrganizationRepository) List(ctx context.Context, flt organization.Filter) ([]organization.Organization, error) {
+	sqlStatement := dialect.From(TABLE_ORGANIZATIONS).Where(
+		globExpressions(map[string]string{"name": flt.Name, "slug": flt.Slug})...,
+	).Where(
+		metadataGlobExpressions("metadata", flt.Metadata)...,
+	)
+	if !flt.IncludeDeleted {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"deleted_at": nil})
+	}
+	if flt.ParentID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"parent_id": flt.ParentID})
+	}
+	if flt.UserID != "" {
+		sqlStatement = sqlStatement.Where(userOrganizationsExpression(flt.UserID))
+	}
+
+	query, params, err := paginate(sqlStatement, flt.Limit, flt.Page).ToSQL()
 	if err != nil {
 		return []organization.Organization{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -186,7 +209,7 @@ func (r OrganizationRepository) List(ctx context.Context) ([]organization.Organi
 			}
 			defer nr.End()
 		}
-		return r.dbc.SelectContext(ctx, &orgModels, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &orgModels, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return []organization.Organization{}, nil
@@ -220,15 +243,17 @@ func (r OrganizationRepository) UpdateByID(ctx context.Context, org organization
 		return organization.Organization{}, fmt.Errorf("%w: %s", parseErr, err)
 	}
 
-	query, params, err := dialect.Update(TABLE_ORGANIZATIONS).Set(
-		goqu.Record{
-			"name":       org.Name,
-			"slug":       org.Slug,
-			"metadata":   marshaledMetadata,
-			"updated_at": goqu.L("now()"),
-		}).Where(goqu.Ex{
-		"id": org.ID,
-	}).Returning(&Organization{}).ToSQL()
+	current := goqu.Ex{
+		"id":         org.ID,
+		"deleted_at": nil,
+	}
+	record, where := versioned(goqu.Record{
+		"name":       org.Name,
+		"slug":       org.Slug,
+		"metadata":   marshaledMetadata,
+		"updated_at": goqu.L("now()"),
+	}, current, org.Version)
+	query, params, err := dialect.Update(TABLE_ORGANIZATIONS).Set(record).Where(where).Returning(&Organization{}).ToSQL()
 	if err != nil {
 		return organization.Organization{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -250,6 +275,9 @@ func (r OrganizationRepository) UpdateByID(ctx context.Context, org organization
 		err = checkPostgresError(err)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_ORGANIZATIONS, current, org.Version), errVersionMismatch) {
+				return organization.Organization{}, organization.ErrVersionMismatch
+			}
 			return organization.Organization{}, organization.ErrNotExist
 		case errors.Is(err, errDuplicateKey):
 			return organization.Organization{}, organization.ErrConflict
@@ -282,16 +310,17 @@ func (r OrganizationRepository) UpdateBySlug(ctx context.Context, org organizati
 		return organization.Organization{}, fmt.Errorf("%w: %s", parseErr, err)
 	}
 
-	query, params, err := dialect.Update(TABLE_ORGANIZATIONS).Set(
-		goqu.Record{
-			"name":       org.Name,
-			"slug":       org.Slug,
-			"metadata":   marshaledMetadata,
-			"updated_at": goqu.L("now()"),
-		}).Where(
-		goqu.Ex{
-			"slug": org.Slug,
-		}).Returning(&Organization{}).ToSQL()
+	current := goqu.Ex{
+		"slug":       org.Slug,
+		"deleted_at": nil,
+	}
+	record, where := versioned(goqu.Record{
+		"name":       org.Name,
+		"slug":       org.Slug,
+		"metadata":   marshaledMetadata,
+		"updated_at": goqu.L("now()"),
+	}, current, org.Version)
+	query, params, err := dialect.Update(TABLE_ORGANIZATIONS).Set(record).Where(where).Returning(&Organization{}).ToSQL()
 	if err != nil {
 		return organization.Organization{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -313,6 +342,9 @@ func (r OrganizationRepository) UpdateBySlug(ctx context.Context, org organizati
 		err = checkPostgresError(err)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_ORGANIZATIONS, current, org.Version), errVersionMismatch) {
+				return organization.Organization{}, organization.ErrVersionMismatch
+			}
 			return organization.Organization{}, organization.ErrNotExist
 		case errors.Is(err, errDuplicateKey):
 			return organization.Organization{}, organization.ErrConflict
@@ -366,7 +398,7 @@ func (r OrganizationRepository) ListAdminsByOrgID(ctx context.Context, orgID str
 			}
 			defer nr.End()
 		}
-		return r.dbc.SelectContext(ctx, &userModels, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &userModels, query, params...)
 	}); err != nil {
 		// List should not return error if empty
 		if errors.Is(err, sql.ErrNoRows) {
@@ -387,3 +419,122 @@ func (r OrganizationRepository) ListAdminsByOrgID(ctx context.Context, orgID str
 
 	return transformedUsers, nil
 }
+
+func (r OrganizationRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return organization.ErrInvalidID
+	}
+
+	if err := softDeleteByID(ctx, r.dbc, TABLE_ORGANIZATIONS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return organization.ErrNotExist
+		case errors.Is(err, errInvalidTexRepresentation):
+			return organization.ErrInvalidUUID
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r OrganizationRepository) Restore(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return organization.ErrInvalidID
+	}
+
+	if err := restoreByID(ctx, r.dbc, TABLE_ORGANIZATIONS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return organization.ErrNotExist
+		case errors.Is(err, errInvalidTexRepresentation):
+			return organization.ErrInvalidUUID
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r OrganizationRepository) ListDeleted(ctx context.Context, deletedBefore time.Time) ([]organization.Organization, error) {
+	query, params, err := dialect.From(TABLE_ORGANIZATIONS).Where(
+		goqu.C("deleted_at").Lt(deletedBefore),
+	).Order(goqu.C("deleted_at").Asc()).ToSQL()
+	if err != nil {
+		return []organization.Organization{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var orgModels []Organization
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_ORGANIZATIONS,
+				Operation:  "ListDeleted",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &orgModels, query, params...)
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []organization.Organization{}, nil
+		}
+		return []organization.Organization{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var deleted []organization.Organization
+	for _, m := range orgModels {
+		transformed, err := m.transformToOrg()
+		if err != nil {
+			return []organization.Organization{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		deleted = append(deleted, transformed)
+	}
+
+	return deleted, nil
+}
+
+// Purge permanently removes the organization, only a deleted organization can be purged
+func (r OrganizationRepository) Purge(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return organization.ErrInvalidID
+	}
+
+	if err := purgeByID(ctx, r.dbc, TABLE_ORGANIZATIONS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return organization.ErrNotExist
+		case errors.Is(err, errInvalidTexRepresentation):
+			return organization.ErrInvalidUUID
+		case errors.Is(err, errForeignKeyViolation):
+			return organization.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// userOrganizationsExpression matches the organizations the user has a role
+// on or has a role on one of the groups of
+func userOrganizationsExpression(userID string) exp.Expression {
+	return goqu.Or(
+		goqu.L("id::text").In(dialect.From(TABLE_RELATIONS).Select("object_id").Where(goqu.Ex{
+			"object_namespace_id":  schema.OrganizationNamespace,
+			"subject_namespace_id": schema.UserPrincipal,
+			"subject_id":           userID,
+		})),
+		goqu.C("id").In(dialect.From(goqu.T(TABLE_GROUPS).As("g")).Join(goqu.T(TABLE_RELATIONS).As("r"), goqu.On(
+			goqu.I("g.id").Cast("VARCHAR").Eq(goqu.I("r.object_id")),
+		)).Select("g.org_id").Where(goqu.Ex{
+			"r.object_namespace_id":  schema.GroupNamespace,
+			"r.subject_namespace_id": schema.UserPrincipal,
+			"r.subject_id":           userID,
+		})),
+	)
+}
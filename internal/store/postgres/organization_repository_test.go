@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -267,6 +268,7 @@ func (s *OrganizationRepositoryTestSuite) TestCreate() {
 func (s *OrganizationRepositoryTestSuite) TestList() {
 	type testCase struct {
 		Description           string
+		Filter                organization.Filter
 		ExpectedOrganizations []organization.Organization
 		ErrString             string
 	}
@@ -285,11 +287,24 @@ func (s *OrganizationRepositoryTestSuite) TestList() {
 				},
 			},
 		},
+		{
+			Description: "should get a page of organizations",
+			Filter: organization.Filter{
+				Limit: 1,
+				Page:  2,
+			},
+			ExpectedOrganizations: []organization.Organization{
+				{
+					Name: "org2",
+					Slug: "org-2",
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		s.Run(tc.Description, func() {
-			got, err := s.repository.List(s.ctx)
+			got, err := s.repository.List(s.ctx, tc.Filter)
 			if tc.ErrString != "" {
 				if err.Error() != tc.ErrString {
 					s.T().Fatalf("got error %s, expected was %s", err.Error(), tc.ErrString)
@@ -480,3 +495,64 @@ func (s *OrganizationRepositoryTestSuite) TestListAdmins() {
 func TestOrganizationRepository(t *testing.T) {
 	suite.Run(t, new(OrganizationRepositoryTestSuite))
 }
+
+func (s *OrganizationRepositoryTestSuite) TestDeleteAndRestore() {
+	orgID := s.orgs[1].ID
+
+	s.Run("should mark the organization as deleted and leave it out of lists", func() {
+		s.Require().NoError(s.repository.Delete(s.ctx, orgID))
+
+		got, err := s.repository.GetByID(s.ctx, orgID)
+		s.Require().NoError(err)
+		s.True(got.IsDeleted())
+
+		listed, err := s.repository.List(s.ctx, organization.Filter{})
+		s.Require().NoError(err)
+		s.Len(listed, 1)
+
+		listed, err = s.repository.List(s.ctx, organization.Filter{IncludeDeleted: true})
+		s.Require().NoError(err)
+		s.Len(listed, 2)
+	})
+
+	s.Run("should return error if the organization is already deleted", func() {
+		s.ErrorIs(s.repository.Delete(s.ctx, orgID), organization.ErrNotExist)
+	})
+
+	s.Run("should not update a deleted organization", func() {
+		_, err := s.repository.UpdateByID(s.ctx, organization.Organization{ID: orgID, Name: "org2", Slug: "org-2"})
+		s.ErrorIs(err, organization.ErrNotExist)
+	})
+
+	s.Run("should restore the deleted organization", func() {
+		s.Require().NoError(s.repository.Restore(s.ctx, orgID))
+
+		got, err := s.repository.GetByID(s.ctx, orgID)
+		s.Require().NoError(err)
+		s.False(got.IsDeleted())
+
+		s.ErrorIs(s.repository.Restore(s.ctx, orgID), organization.ErrNotExist)
+	})
+}
+
+func (s *OrganizationRepositoryTestSuite) TestPurge() {
+	orgID := s.orgs[1].ID
+
+	s.Run("should return error if the organization isn't deleted", func() {
+		s.ErrorIs(s.repository.Purge(s.ctx, orgID), organization.ErrNotExist)
+	})
+
+	s.Run("should list and purge the deleted organization", func() {
+		s.Require().NoError(s.repository.Delete(s.ctx, orgID))
+
+		deleted, err := s.repository.ListDeleted(s.ctx, time.Now().Add(time.Minute))
+		s.Require().NoError(err)
+		s.Require().Len(deleted, 1)
+		s.Equal(orgID, deleted[0].ID)
+
+		s.Require().NoError(s.repository.Purge(s.ctx, orgID))
+
+		_, err = s.repository.GetByID(s.ctx, orgID)
+		s.ErrorIs(err, organization.ErrNotExist)
+	})
+}
@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/odpf/shield/core/usage"
+)
+
+type OrganizationUsage struct {
+	OrgID          string    `db:"org_id"`
+	Day            time.Time `db:"day"`
+	APICalls       int64     `db:"api_calls"`
+	RelationWrites int64     `db:"relation_writes"`
+}
+
+func (from OrganizationUsage) transformToUsage() usage.Usage {
+	return usage.Usage{
+		OrgID:          from.OrgID,
+		Day:            usage.DayOf(from.Day),
+		APICalls:       from.APICalls,
+		RelationWrites: from.RelationWrites,
+	}
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+
+	"github.com/odpf/shield/core/usage"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type OrganizationUsageRepository struct {
+	dbc *db.Client
+}
+
+func NewOrganizationUsageRepository(dbc *db.Client) *OrganizationUsageRepository {
+	return &OrganizationUsageRepository{
+		dbc: dbc,
+	}
+}
+
+// Add adds the counts in a single statement, the rows are written in the
+// order of their key so instances flushing at once don't deadlock
+func (r OrganizationUsageRepository) Add(ctx context.Context, usages []usage.Usage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+	sorted := make([]usage.Usage, len(usages))
+	copy(sorted, usages)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].OrgID != sorted[j].OrgID {
+			return sorted[i].OrgID < sorted[j].OrgID
+		}
+		return sorted[i].Day.Before(sorted[j].Day)
+	})
+
+	rows := make([]interface{}, 0, len(sorted))
+	for _, u := range sorted {
+		rows = append(rows, goqu.Record{
+			"org_id":          u.OrgID,
+			"day":             usage.DayOf(u.Day).Format("2006-01-02"),
+			"api_calls":       u.APICalls,
+			"relation_writes": u.RelationWrites,
+		})
+	}
+
+	query, params, err := dialect.Insert(TABLE_ORGANIZATION_USAGE).Rows(rows...).OnConflict(goqu.DoUpdate("org_id, day", goqu.Record{
+		"api_calls":       goqu.L(TABLE_ORGANIZATION_USAGE + ".api_calls + EXCLUDED.api_calls"),
+		"relation_writes": goqu.L(TABLE_ORGANIZATION_USAGE + ".relation_writes + EXCLUDED.relation_writes"),
+	})).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_ORGANIZATION_USAGE,
+				Operation:  "Add",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		_, err := r.dbc.ExecContext(ctx, query, params...)
+		return err
+	}); err != nil {
+		return fmt.Errorf("%w: %s", dbErr, checkPostgresError(err))
+	}
+	return nil
+}
+
+func (r OrganizationUsageRepository) List(ctx context.Context, orgID string, since time.Time) ([]usage.Usage, error) {
+	query, params, err := dialect.From(TABLE_ORGANIZATION_USAGE).Where(
+		goqu.Ex{"org_id": orgID},
+		goqu.C("day").Gte(usage.DayOf(since).Format("2006-01-02")),
+	).Order(goqu.C("day").Asc()).ToSQL()
+	if err != nil {
+		return []usage.Usage{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var usageModels []OrganizationUsage
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_ORGANIZATION_USAGE,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &usageModels, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return []usage.Usage{}, nil
+		}
+		return []usage.Usage{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	usages := make([]usage.Usage, 0, len(usageModels))
+	for _, u := range usageModels {
+		usages = append(usages, u.transformToUsage())
+	}
+	return usages, nil
+}
@@ -18,6 +18,11 @@ type Policy struct {
 	NamespaceID string         `db:"namespace_id"`
 	Action      Action         `db:"action"`
 	ActionID    sql.NullString `db:"action_id"`
+	Name        sql.NullString `db:"name"`
+	Description sql.NullString `db:"description"`
+	Effect      string         `db:"effect"`
+	Condition   string         `db:"condition"`
+	Version     int64          `db:"version"`
 	CreatedAt   time.Time      `db:"created_at"`
 	UpdatedAt   time.Time      `db:"updated_at"`
 }
@@ -48,7 +53,26 @@ func (from Policy) transformToPolicy() (policy.Policy, error) {
 		RoleID:      rl.ID,
 		ActionID:    act.ID,
 		NamespaceID: ns.ID,
+		Name:        from.Name.String,
+		Description: from.Description.String,
+		Effect:      from.Effect,
+		Condition:   from.Condition,
+		Version:     from.Version,
 		CreatedAt:   from.CreatedAt,
 		UpdatedAt:   from.UpdatedAt,
 	}, nil
 }
+
+func (from Policy) transformToExpandedPolicy() (policy.ExpandedPolicy, error) {
+	pol, err := from.transformToPolicy()
+	if err != nil {
+		return policy.ExpandedPolicy{}, err
+	}
+
+	return policy.ExpandedPolicy{
+		Policy:        pol,
+		RoleName:      from.Role.Name,
+		ActionName:    from.Action.Name,
+		NamespaceName: from.Namespace.Name,
+	}, nil
+}
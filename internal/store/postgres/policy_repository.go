@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
 	newrelic "github.com/newrelic/go-agent"
 	"github.com/odpf/shield/core/namespace"
 	"github.com/odpf/shield/core/policy"
@@ -15,19 +17,42 @@ import (
 )
 
 type PolicyRepository struct {
-	dbc *db.Client
+	dbc         *db.Client
+	maxRetries  int
+	queryLogger QueryLogger
 }
 
 func NewPolicyRepository(dbc *db.Client) *PolicyRepository {
 	return &PolicyRepository{
-		dbc: dbc,
+		dbc:         dbc,
+		maxRetries:  dbc.MaxTxnRetries(),
+		queryLogger: noopQueryLogger,
 	}
 }
 
+// WithQueryLogger returns a copy of the repository reporting
+// every query it runs to logger
+func (r PolicyRepository) WithQueryLogger(logger QueryLogger) *PolicyRepository {
+	if logger == nil {
+		logger = noopQueryLogger
+	}
+	r.queryLogger = logger
+	return &r
+}
+
+func (r PolicyRepository) logQuery(ctx context.Context, query string, params []interface{}, start time.Time) {
+	r.queryLogger(ctx, query, redactArgs(params), time.Since(start))
+}
+
 func (r PolicyRepository) buildListQuery() *goqu.SelectDataset {
 	selectStatement := dialect.Select(
 		"p.id",
 		"p.namespace_id",
+		"p.name",
+		"p.description",
+		"p.effect",
+		"p.condition",
+		"p.version",
 		goqu.I("roles.id").As(goqu.C("role.id")),
 		goqu.I("roles.name").As(goqu.C("role.name")),
 		goqu.I("roles.types").As(goqu.C("role.types")),
@@ -79,7 +104,8 @@ func (r PolicyRepository) Get(ctx context.Context, id string) (policy.Policy, er
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &policyModel, query, params...)
+		defer r.logQuery(ctx, query, params, time.Now())
+		return r.dbc.Reader().GetContext(ctx, &policyModel, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -100,11 +126,54 @@ func (r PolicyRepository) Get(ctx context.Context, id string) (policy.Policy, er
 	return transformedPolicy, nil
 }
 
-func (r PolicyRepository) List(ctx context.Context) ([]policy.Policy, error) {
+func (r PolicyRepository) List(ctx context.Context, flt policy.Filters) ([]policy.Policy, error) {
+	fetchedPolicies, err := r.list(ctx, flt, "List")
+	if err != nil {
+		return []policy.Policy{}, err
+	}
+
+	var transformedPolicies []policy.Policy
+	for _, p := range fetchedPolicies {
+		transformedPolicy, err := p.transformToPolicy()
+		if err != nil {
+			return []policy.Policy{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedPolicies = append(transformedPolicies, transformedPolicy)
+	}
+
+	return transformedPolicies, nil
+}
+
+// ListExpanded returns the policies with the names of their role, action and
+// namespace, they are part of the list query joins so no extra query is made
+func (r PolicyRepository) ListExpanded(ctx context.Context, flt policy.Filters) ([]policy.ExpandedPolicy, error) {
+	fetchedPolicies, err := r.list(ctx, flt, "ListExpanded")
+	if err != nil {
+		return []policy.ExpandedPolicy{}, err
+	}
+
+	var transformedPolicies []policy.ExpandedPolicy
+	for _, p := range fetchedPolicies {
+		transformedPolicy, err := p.transformToExpandedPolicy()
+		if err != nil {
+			return []policy.ExpandedPolicy{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedPolicies = append(transformedPolicies, transformedPolicy)
+	}
+
+	return transformedPolicies, nil
+}
+
+func (r PolicyRepository) list(ctx context.Context, flt policy.Filters, operation string) ([]Policy, error) {
 	var fetchedPolicies []Policy
-	query, params, err := r.buildListQuery().ToSQL()
+
+	sqlStatement := r.buildListQuery()
+	if flt.NamespaceID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"p.namespace_id": flt.NamespaceID})
+	}
+	query, params, err := sqlStatement.ToSQL()
 	if err != nil {
-		return []policy.Policy{}, fmt.Errorf("%w: %s", queryErr, err)
+		return nil, fmt.Errorf("%w: %s", queryErr, err)
 	}
 
 	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
@@ -113,71 +182,80 @@ func (r PolicyRepository) List(ctx context.Context) ([]policy.Policy, error) {
 			nr := newrelic.DatastoreSegment{
 				Product:    newrelic.DatastorePostgres,
 				Collection: TABLE_POLICIES,
-				Operation:  "List",
+				Operation:  operation,
 				StartTime:  nrCtx.StartSegmentNow(),
 			}
 			defer nr.End()
 		}
-		return r.dbc.SelectContext(ctx, &fetchedPolicies, query, params...)
+		defer r.logQuery(ctx, query, params, time.Now())
+		return r.dbc.Reader().SelectContext(ctx, &fetchedPolicies, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return []policy.Policy{}, nil
+			return nil, nil
 		default:
-			return []policy.Policy{}, err
-		}
-	}
-
-	var transformedPolicies []policy.Policy
-	for _, p := range fetchedPolicies {
-		transformedPolicy, err := p.transformToPolicy()
-		if err != nil {
-			return []policy.Policy{}, fmt.Errorf("%w: %s", parseErr, err)
+			return nil, err
 		}
-		transformedPolicies = append(transformedPolicies, transformedPolicy)
 	}
 
-	return transformedPolicies, nil
+	return fetchedPolicies, nil
 }
 
 // TODO this is actually upsert
-func (r PolicyRepository) Create(ctx context.Context, pol policy.Policy) (string, error) {
+// createPolicyQuery is the upsert of the policy, returning its id
+func createPolicyQuery(pol policy.Policy) (string, []interface{}, error) {
 	// TODO(krtkvrm) | IMP: need to find a way to deprecate this
 	// This is required by bootstrap, which will be changed in this PR
 	roleID := pol.RoleID
 	actionID := pol.ActionID
 	nsID := pol.NamespaceID
 
-	if strings.TrimSpace(actionID) == "" {
-		return "", policy.ErrInvalidDetail
+	effect := pol.Effect
+	if effect == "" {
+		effect = policy.EffectAllow
 	}
 
-	query, params, err := dialect.Insert(TABLE_POLICIES).Rows(
+	return dialect.Insert(TABLE_POLICIES).Rows(
 		goqu.Record{
 			"namespace_id": nsID,
 			"role_id":      roleID,
 			"action_id":    sql.NullString{String: actionID, Valid: actionID != ""},
+			"name":         sql.NullString{String: pol.Name, Valid: pol.Name != ""},
+			"description":  sql.NullString{String: pol.Description, Valid: pol.Description != ""},
+			"effect":       effect,
+			"condition":    pol.Condition,
 		}).OnConflict(goqu.DoUpdate("role_id, namespace_id, action_id", goqu.Record{
 		"namespace_id": nsID,
 	})).Returning("id").ToSQL()
+}
+
+func (r PolicyRepository) Create(ctx context.Context, pol policy.Policy) (string, error) {
+	if strings.TrimSpace(pol.ActionID) == "" {
+		return "", policy.ErrInvalidDetail
+	}
+
+	query, params, err := createPolicyQuery(pol)
 	if err != nil {
 		return "", fmt.Errorf("%w: %s", queryErr, err)
 	}
 
 	var policyID string
-	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
-		nrCtx := newrelic.FromContext(ctx)
-		if nrCtx != nil {
-			nr := newrelic.DatastoreSegment{
-				Product:    newrelic.DatastorePostgres,
-				Collection: TABLE_POLICIES,
-				Operation:  "Create",
-				StartTime:  nrCtx.StartSegmentNow(),
+	if err = withTxnRetry(ctx, r.maxRetries, func(ctx context.Context) error {
+		return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+			nrCtx := newrelic.FromContext(ctx)
+			if nrCtx != nil {
+				nr := newrelic.DatastoreSegment{
+					Product:    newrelic.DatastorePostgres,
+					Collection: TABLE_POLICIES,
+					Operation:  "Create",
+					StartTime:  nrCtx.StartSegmentNow(),
+				}
+				defer nr.End()
 			}
-			defer nr.End()
-		}
-		return r.dbc.QueryRowxContext(ctx, query, params...).Scan(&policyID)
+			defer r.logQuery(ctx, query, params, time.Now())
+			return r.dbc.QueryRowxContext(ctx, query, params...).Scan(&policyID)
+		})
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -191,6 +269,65 @@ func (r PolicyRepository) Create(ctx context.Context, pol policy.Policy) (string
 	return policyID, nil
 }
 
+// CreateBatch creates the policies in a single transaction, every policy in
+// a savepoint so the ones refused for an unknown role, action or namespace
+// are reported without rolling the others back
+func (r PolicyRepository) CreateBatch(ctx context.Context, policies []policy.Policy) ([]policy.Policy, []policy.BatchFailure, error) {
+	queries := make([]string, len(policies))
+	params := make([][]interface{}, len(policies))
+	for i, pol := range policies {
+		var err error
+		if queries[i], params[i], err = createPolicyQuery(pol); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", queryErr, err)
+		}
+	}
+
+	var created []policy.Policy
+	var failures []policy.BatchFailure
+	if err := withTxnRetry(ctx, r.maxRetries, func(ctx context.Context) error {
+		created, failures = nil, nil
+		return r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+			nrCtx := newrelic.FromContext(ctx)
+			if nrCtx != nil {
+				nr := newrelic.DatastoreSegment{
+					Product:    newrelic.DatastorePostgres,
+					Collection: TABLE_POLICIES,
+					Operation:  "CreateBatch",
+					StartTime:  nrCtx.StartSegmentNow(),
+				}
+				defer nr.End()
+			}
+
+			for i, pol := range policies {
+				if strings.TrimSpace(pol.ActionID) == "" {
+					failures = append(failures, policy.BatchFailure{Index: i, Err: policy.ErrInvalidDetail})
+					continue
+				}
+
+				// the timeout is per policy, a batch takes as long as its size
+				err := r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+					defer r.logQuery(ctx, queries[i], params[i], time.Now())
+					return withSavepoint(ctx, tx, func() error {
+						return tx.QueryRowxContext(ctx, queries[i], params[i]...).Scan(&pol.ID)
+					})
+				})
+				switch err = checkPostgresError(err); {
+				case err == nil:
+					created = append(created, pol)
+				case errors.Is(err, errForeignKeyViolation):
+					failures = append(failures, policy.BatchFailure{Index: i, Err: fmt.Errorf("%w: %s", policy.ErrInvalidDetail, err)})
+				default:
+					return err
+				}
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return created, failures, nil
+}
+
 func (r PolicyRepository) Update(ctx context.Context, toUpdate policy.Policy) (string, error) {
 	if strings.TrimSpace(toUpdate.ID) == "" {
 		return "", policy.ErrInvalidID
@@ -200,36 +337,47 @@ func (r PolicyRepository) Update(ctx context.Context, toUpdate policy.Policy) (s
 		return "", policy.ErrInvalidDetail
 	}
 
-	query, params, err := dialect.Update(TABLE_POLICIES).Set(
-		goqu.Record{
-			"namespace_id": toUpdate.NamespaceID,
-			"role_id":      toUpdate.RoleID,
-			"action_id":    sql.NullString{String: toUpdate.ActionID, Valid: toUpdate.ActionID != ""},
-			"updated_at":   goqu.L("now()"),
-		}).Where(goqu.Ex{
+	current := goqu.Ex{
 		"id": toUpdate.ID,
-	}).Returning("id").ToSQL()
+	}
+	record, where := versioned(goqu.Record{
+		"namespace_id": toUpdate.NamespaceID,
+		"role_id":      toUpdate.RoleID,
+		"action_id":    sql.NullString{String: toUpdate.ActionID, Valid: toUpdate.ActionID != ""},
+		"name":         sql.NullString{String: toUpdate.Name, Valid: toUpdate.Name != ""},
+		"description":  sql.NullString{String: toUpdate.Description, Valid: toUpdate.Description != ""},
+		"effect":       toUpdate.Effect,
+		"condition":    toUpdate.Condition,
+		"updated_at":   goqu.L("now()"),
+	}, current, toUpdate.Version)
+	query, params, err := dialect.Update(TABLE_POLICIES).Set(record).Where(where).Returning("id").ToSQL()
 	if err != nil {
 		return "", fmt.Errorf("%w: %s", queryErr, err)
 	}
 
 	var policyID string
-	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
-		nrCtx := newrelic.FromContext(ctx)
-		if nrCtx != nil {
-			nr := newrelic.DatastoreSegment{
-				Product:    newrelic.DatastorePostgres,
-				Collection: TABLE_POLICIES,
-				Operation:  "Update",
-				StartTime:  nrCtx.StartSegmentNow(),
+	if err = withTxnRetry(ctx, r.maxRetries, func(ctx context.Context) error {
+		return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+			nrCtx := newrelic.FromContext(ctx)
+			if nrCtx != nil {
+				nr := newrelic.DatastoreSegment{
+					Product:    newrelic.DatastorePostgres,
+					Collection: TABLE_POLICIES,
+					Operation:  "Update",
+					StartTime:  nrCtx.StartSegmentNow(),
+				}
+				defer nr.End()
 			}
-			defer nr.End()
-		}
-		return r.dbc.QueryRowxContext(ctx, query, params...).Scan(&policyID)
+			defer r.logQuery(ctx, query, params, time.Now())
+			return r.dbc.QueryRowxContext(ctx, query, params...).Scan(&policyID)
+		})
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_POLICIES, current, toUpdate.Version), errVersionMismatch) {
+				return "", policy.ErrVersionMismatch
+			}
 			return "", policy.ErrNotExist
 		case errors.Is(err, errDuplicateKey):
 			return "", policy.ErrConflict
@@ -244,3 +392,24 @@ func (r PolicyRepository) Update(ctx context.Context, toUpdate policy.Policy) (s
 
 	return policyID, nil
 }
+
+func (r PolicyRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return policy.ErrInvalidID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_POLICIES, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return policy.ErrNotExist
+		case errors.Is(err, errInvalidTexRepresentation):
+			return policy.ErrInvalidUUID
+		case errors.Is(err, errForeignKeyViolation):
+			return policy.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
@@ -20,18 +20,37 @@ var (
 )
 
 const (
-	TABLE_ACTIONS       = "actions"
-	TABLE_GROUPS        = "groups"
-	TABLE_NAMESPACES    = "namespaces"
-	TABLE_ORGANIZATIONS = "organizations"
-	TABLE_POLICIES      = "policies"
-	TABLE_PROJECTS      = "projects"
-	TABLE_RELATIONS     = "relations"
-	TABLE_RESOURCES     = "resources"
-	TABLE_ROLES         = "roles"
-	TABLE_USERS         = "users"
-	TABLE_METADATA      = "metadata"
-	TABLE_METADATA_KEYS = "metadata_keys"
+	TABLE_ACTIONS            = "actions"
+	TABLE_API_KEYS           = "api_keys"
+	TABLE_AUDIT_LOGS         = "audit_logs"
+	TABLE_AUTHZ_SCHEMA       = "authz_schema"
+	TABLE_AUTHZ_TUPLES       = "authz_tuples"
+	TABLE_EVENT_OUTBOX       = "event_outbox"
+	TABLE_FOLDERS            = "folders"
+	TABLE_GROUPS             = "groups"
+	TABLE_IDEMPOTENCY_KEYS   = "idempotency_keys"
+	TABLE_INVITATIONS        = "invitations"
+	TABLE_NAMESPACES         = "namespaces"
+	TABLE_ORGANIZATIONS      = "organizations"
+	TABLE_ORGANIZATION_USAGE = "organization_usage"
+	TABLE_POLICIES           = "policies"
+	TABLE_PROJECTS           = "projects"
+	TABLE_RELATIONS          = "relations"
+	TABLE_RESOURCES          = "resources"
+	TABLE_TAG_POLICIES       = "resource_tag_policies"
+	TABLE_TAG_POLICY_GRANTS  = "resource_tag_policy_grants"
+	TABLE_ROLES              = "roles"
+	TABLE_RULES              = "rules"
+	TABLE_SERVICE_USERS      = "service_users"
+	TABLE_SESSIONS           = "sessions"
+	TABLE_SIGNING_KEYS       = "signing_keys"
+	TABLE_SERVICE_USER_KEYS  = "service_user_keys"
+	TABLE_USERS              = "users"
+	TABLE_WEBHOOKS           = "webhooks"
+	TABLE_WEBHOOK_DELIVERIES = "webhook_deliveries"
+	TABLE_METADATA           = "metadata"
+	TABLE_METADATA_KEYS      = "metadata_keys"
+	TABLE_METADATA_SCHEMAS   = "metadata_schemas"
 )
 
 func checkPostgresError(err error) error {
@@ -16,6 +16,7 @@ type Project struct {
 	Slug      string       `db:"slug"`
 	OrgID     string       `db:"org_id"`
 	Metadata  []byte       `db:"metadata"`
+	Version   int64        `db:"version"`
 	CreatedAt time.Time    `db:"created_at"`
 	UpdatedAt time.Time    `db:"updated_at"`
 	DeletedAt sql.NullTime `db:"deleted_at"`
@@ -33,7 +34,9 @@ func (from Project) transformToProject() (project.Project, error) {
 		Slug:         from.Slug,
 		Organization: organization.Organization{ID: from.OrgID},
 		Metadata:     unmarshalledMetadata,
+		Version:      from.Version,
 		CreatedAt:    from.CreatedAt,
 		UpdatedAt:    from.UpdatedAt,
+		DeletedAt:    from.DeletedAt.Time,
 	}, nil
 }
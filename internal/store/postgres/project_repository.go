@@ -7,14 +7,17 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
 	newrelic "github.com/newrelic/go-agent"
 	"github.com/odpf/shield/core/namespace"
 	"github.com/odpf/shield/core/organization"
 	"github.com/odpf/shield/core/project"
-	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/core/relation"
 	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
 	"github.com/odpf/shield/pkg/db"
 )
 
@@ -53,7 +56,7 @@ func (r ProjectRepository) GetByID(ctx context.Context, id string) (project.Proj
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &projectModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &projectModel, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -98,7 +101,7 @@ func (r ProjectRepository) GetBySlug(ctx context.Context, slug string) (project.
 			}
 			defer nr.End()
 		}
-		return r.dbc.GetContext(ctx, &projectModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &projectModel, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -176,8 +179,17 @@ func (r ProjectRepository) Create(ctx context.Context, prj project.Project) (pro
 	return transformedProj, nil
 }
 
-func (r ProjectRepository) List(ctx context.Context) ([]project.Project, error) {
-	query, params, err := dialect.From(TABLE_PROJECTS).ToSQL()
+func (r ProjectRepository) List(ctx context.Context, flt project.Filter) ([]project.Project, error) {
+	sqlStatement := dialect.From(TABLE_PROJECTS).Where(
+		globExpressions(map[string]string{"name": flt.Name, "slug": flt.Slug})...,
+	).Where(
+		metadataGlobExpressions("metadata", flt.Metadata)...,
+	)
+	if !flt.IncludeDeleted {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"deleted_at": nil})
+	}
+
+	query, params, err := paginate(sqlStatement, flt.Limit, flt.Page).ToSQL()
 	if err != nil {
 		return []project.Project{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -195,7 +207,7 @@ func (r ProjectRepository) List(ctx context.Context) ([]project.Project, error)
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &projectModels, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &projectModels, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return []project.Project{}, project.ErrNotExist
@@ -230,16 +242,18 @@ func (r ProjectRepository) UpdateByID(ctx context.Context, prj project.Project)
 		return project.Project{}, fmt.Errorf("%w: %s", parseErr, err)
 	}
 
-	query, params, err := dialect.Update(TABLE_PROJECTS).Set(
-		goqu.Record{
-			"name":       prj.Name,
-			"slug":       prj.Slug,
-			"org_id":     prj.Organization.ID,
-			"metadata":   marshaledMetadata,
-			"updated_at": goqu.L("now()"),
-		}).Where(goqu.Ex{
-		"id": prj.ID,
-	}).Returning(&Project{}).ToSQL()
+	current := goqu.Ex{
+		"id":         prj.ID,
+		"deleted_at": nil,
+	}
+	record, where := versioned(goqu.Record{
+		"name":       prj.Name,
+		"slug":       prj.Slug,
+		"org_id":     prj.Organization.ID,
+		"metadata":   marshaledMetadata,
+		"updated_at": goqu.L("now()"),
+	}, current, prj.Version)
+	query, params, err := dialect.Update(TABLE_PROJECTS).Set(record).Where(where).Returning(&Project{}).ToSQL()
 	if err != nil {
 		return project.Project{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -262,6 +276,9 @@ func (r ProjectRepository) UpdateByID(ctx context.Context, prj project.Project)
 		err = checkPostgresError(err)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_PROJECTS, current, prj.Version), errVersionMismatch) {
+				return project.Project{}, project.ErrVersionMismatch
+			}
 			return project.Project{}, project.ErrNotExist
 		case errors.Is(err, errInvalidTexRepresentation):
 			return project.Project{}, project.ErrInvalidUUID
@@ -296,16 +313,18 @@ func (r ProjectRepository) UpdateBySlug(ctx context.Context, prj project.Project
 		return project.Project{}, fmt.Errorf("%w: %s", parseErr, err)
 	}
 
-	query, params, err := dialect.Update(TABLE_PROJECTS).Set(
-		goqu.Record{
-			"name":       prj.Name,
-			"slug":       prj.Slug,
-			"org_id":     prj.Organization.ID,
-			"metadata":   marshaledMetadata,
-			"updated_at": goqu.L("now()"),
-		}).Where(goqu.Ex{
-		"slug": prj.Slug,
-	}).Returning(&Project{}).ToSQL()
+	current := goqu.Ex{
+		"slug":       prj.Slug,
+		"deleted_at": nil,
+	}
+	record, where := versioned(goqu.Record{
+		"name":       prj.Name,
+		"slug":       prj.Slug,
+		"org_id":     prj.Organization.ID,
+		"metadata":   marshaledMetadata,
+		"updated_at": goqu.L("now()"),
+	}, current, prj.Version)
+	query, params, err := dialect.Update(TABLE_PROJECTS).Set(record).Where(where).Returning(&Project{}).ToSQL()
 	if err != nil {
 		return project.Project{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -327,6 +346,9 @@ func (r ProjectRepository) UpdateBySlug(ctx context.Context, prj project.Project
 		err = checkPostgresError(err)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_PROJECTS, current, prj.Version), errVersionMismatch) {
+				return project.Project{}, project.ErrVersionMismatch
+			}
 			return project.Project{}, project.ErrNotExist
 		case errors.Is(err, errInvalidTexRepresentation):
 			return project.Project{}, project.ErrInvalidUUID
@@ -362,7 +384,7 @@ func (r ProjectRepository) ListAdmins(ctx context.Context, projectID string) ([]
 			goqu.I("u.id").Cast("VARCHAR").Eq(goqu.I("r.subject_id")),
 		)).Where(goqu.Ex{
 		"r.object_id":            projectID,
-		"r.role_id":              role.DefinitionProjectAdmin.ID,
+		"r.role_id":              schema.GetRoleID(schema.ProjectNamespace, schema.OwnerRole),
 		"r.subject_namespace_id": namespace.DefinitionUser.ID,
 		"r.object_namespace_id":  namespace.DefinitionProject.ID,
 	}).ToSQL()
@@ -382,7 +404,7 @@ func (r ProjectRepository) ListAdmins(ctx context.Context, projectID string) ([]
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedUsers, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedUsers, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return []user.User{}, nil
@@ -402,3 +424,193 @@ func (r ProjectRepository) ListAdmins(ctx context.Context, projectID string) ([]
 
 	return transformedUsers, nil
 }
+
+func (r ProjectRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return project.ErrInvalidID
+	}
+
+	if err := softDeleteByID(ctx, r.dbc, TABLE_PROJECTS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return project.ErrNotExist
+		case errors.Is(err, errInvalidTexRepresentation):
+			return project.ErrInvalidUUID
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r ProjectRepository) Restore(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return project.ErrInvalidID
+	}
+
+	if err := restoreByID(ctx, r.dbc, TABLE_PROJECTS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return project.ErrNotExist
+		case errors.Is(err, errInvalidTexRepresentation):
+			return project.ErrInvalidUUID
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r ProjectRepository) ListDeleted(ctx context.Context, deletedBefore time.Time) ([]project.Project, error) {
+	query, params, err := dialect.From(TABLE_PROJECTS).Where(
+		goqu.C("deleted_at").Lt(deletedBefore),
+	).Order(goqu.C("deleted_at").Asc()).ToSQL()
+	if err != nil {
+		return []project.Project{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var projectModels []Project
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_PROJECTS,
+				Operation:  "ListDeleted",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &projectModels, query, params...)
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []project.Project{}, nil
+		}
+		return []project.Project{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var deleted []project.Project
+	for _, m := range projectModels {
+		transformed, err := m.transformToProject()
+		if err != nil {
+			return []project.Project{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		deleted = append(deleted, transformed)
+	}
+
+	return deleted, nil
+}
+
+// Purge permanently removes the project along with its resources and the
+// relations on the project and its resources in a single transaction, only
+// a deleted project can be purged. The removed resources are returned so
+// their tuples can be removed from the authz store as well
+func (r ProjectRepository) Purge(ctx context.Context, id string) ([]relation.Object, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, project.ErrInvalidID
+	}
+
+	resourceIDs := dialect.From(TABLE_RESOURCES).Select("id").Where(goqu.Ex{"project_id": id})
+	folderIDs := dialect.From(TABLE_FOLDERS).Select("id").Where(goqu.Ex{"project_id": id})
+	relationsQuery, relationsParams, err := dialect.Delete(TABLE_RELATIONS).Where(goqu.Or(
+		goqu.C("object_id").Eq(id),
+		goqu.C("subject_id").Eq(id),
+		goqu.C("object_id").In(resourceIDs),
+		goqu.C("subject_id").In(resourceIDs),
+		goqu.C("object_id").In(folderIDs),
+		goqu.C("subject_id").In(folderIDs),
+	)).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", queryErr, err)
+	}
+	resourcesQuery, resourcesParams, err := dialect.Delete(TABLE_RESOURCES).Where(goqu.Ex{
+		"project_id": id,
+	}).Returning("id", "namespace_id").ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", queryErr, err)
+	}
+	foldersQuery, foldersParams, err := dialect.Delete(TABLE_FOLDERS).Where(goqu.Ex{
+		"project_id": id,
+	}).Returning("id").ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", queryErr, err)
+	}
+	projectQuery, projectParams, err := dialect.Delete(TABLE_PROJECTS).Where(
+		goqu.Ex{"id": id},
+		goqu.C("deleted_at").IsNotNull(),
+	).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var resources []relation.Object
+	if err := r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+			nrCtx := newrelic.FromContext(ctx)
+			if nrCtx != nil {
+				nr := newrelic.DatastoreSegment{
+					Product:    newrelic.DatastorePostgres,
+					Collection: TABLE_PROJECTS,
+					Operation:  "Purge",
+					StartTime:  nrCtx.StartSegmentNow(),
+				}
+				defer nr.End()
+			}
+
+			if _, err := tx.ExecContext(ctx, relationsQuery, relationsParams...); err != nil {
+				return checkPostgresError(err)
+			}
+
+			rows, err := tx.QueryxContext(ctx, resourcesQuery, resourcesParams...)
+			if err != nil {
+				return checkPostgresError(err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var resourceID string
+				var namespaceID sql.NullString
+				if err := rows.Scan(&resourceID, &namespaceID); err != nil {
+					return err
+				}
+				resources = append(resources, relation.Object{ID: resourceID, NamespaceID: namespaceID.String})
+			}
+			if err := rows.Err(); err != nil {
+				return checkPostgresError(err)
+			}
+
+			var folderIDs []string
+			if err := tx.SelectContext(ctx, &folderIDs, foldersQuery, foldersParams...); err != nil {
+				return checkPostgresError(err)
+			}
+			for _, folderID := range folderIDs {
+				resources = append(resources, relation.Object{ID: folderID, NamespaceID: schema.FolderNamespace})
+			}
+
+			result, err := tx.ExecContext(ctx, projectQuery, projectParams...)
+			if err != nil {
+				return checkPostgresError(err)
+			}
+			if count, err := result.RowsAffected(); err != nil {
+				return err
+			} else if count == 0 {
+				return sql.ErrNoRows
+			}
+			return nil
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, project.ErrNotExist
+		case errors.Is(err, errInvalidTexRepresentation):
+			return nil, project.ErrInvalidUUID
+		case errors.Is(err, errForeignKeyViolation):
+			return nil, project.ErrInUse
+		default:
+			return nil, err
+		}
+	}
+
+	return resources, nil
+}
@@ -313,6 +313,7 @@ func (s *ProjectRepositoryTestSuite) TestCreate() {
 func (s *ProjectRepositoryTestSuite) TestList() {
 	type testCase struct {
 		Description      string
+		Filter           project.Filter
 		ExpectedProjects []project.Project
 		ErrString        string
 	}
@@ -335,11 +336,24 @@ func (s *ProjectRepositoryTestSuite) TestList() {
 				},
 			},
 		},
+		{
+			Description: "should get a page of projects",
+			Filter: project.Filter{
+				Limit: 2,
+				Page:  2,
+			},
+			ExpectedProjects: []project.Project{
+				{
+					Name: "project3",
+					Slug: "project-3",
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		s.Run(tc.Description, func() {
-			got, err := s.repository.List(s.ctx)
+			got, err := s.repository.List(s.ctx, tc.Filter)
 			if tc.ErrString != "" {
 				if err.Error() != tc.ErrString {
 					s.T().Fatalf("got error %s, expected was %s", err.Error(), tc.ErrString)
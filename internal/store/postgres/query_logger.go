@@ -0,0 +1,24 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// QueryLogger is called after every query run by a repository with the
+// statement, its arguments and how long the query took. Argument values are
+// redacted before being handed over, only their types are kept.
+type QueryLogger func(ctx context.Context, query string, args []interface{}, duration time.Duration)
+
+func noopQueryLogger(context.Context, string, []interface{}, time.Duration) {}
+
+// redactArgs replaces every argument with a placeholder holding its type so
+// the shape of a query can be analysed without leaking the values
+func redactArgs(args []interface{}) []interface{} {
+	redacted := make([]interface{}, 0, len(args))
+	for _, a := range args {
+		redacted = append(redacted, fmt.Sprintf("<%T>", a))
+	}
+	return redacted
+}
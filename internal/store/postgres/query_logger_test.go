@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyRepositoryQueryLogger(t *testing.T) {
+	t.Run("should redact the argument values passed to the logger", func(t *testing.T) {
+		var gotQuery string
+		var gotArgs []interface{}
+		repository := PolicyRepository{}.WithQueryLogger(func(ctx context.Context, query string, args []interface{}, duration time.Duration) {
+			gotQuery, gotArgs = query, args
+		})
+
+		repository.logQuery(context.Background(), "SELECT * FROM policies WHERE id = $1 AND role_id = $2", []interface{}{"secret-id", 42}, time.Now())
+
+		assert.Equal(t, "SELECT * FROM policies WHERE id = $1 AND role_id = $2", gotQuery)
+		assert.Equal(t, []interface{}{"<string>", "<int>"}, gotArgs)
+	})
+
+	t.Run("should not panic without a logger", func(t *testing.T) {
+		repository := PolicyRepository{}.WithQueryLogger(nil)
+
+		assert.NotPanics(t, func() {
+			repository.logQuery(context.Background(), "SELECT 1", nil, time.Now())
+		})
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"database/sql"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
 	newrelic "github.com/newrelic/go-agent"
 	"github.com/odpf/shield/core/relation"
 	"github.com/odpf/shield/internal/schema"
@@ -25,8 +26,8 @@ func NewRelationRepository(dbc *db.Client) *RelationRepository {
 	}
 }
 
-func (r RelationRepository) Create(ctx context.Context, relationToCreate relation.RelationV2) (relation.RelationV2, error) {
-	query, params, err := dialect.Insert(TABLE_RELATIONS).Rows(
+func createRelationQuery(relationToCreate relation.RelationV2) (string, []interface{}, error) {
+	return dialect.Insert(TABLE_RELATIONS).Rows(
 		goqu.Record{
 			"subject_namespace_id": relationToCreate.Subject.Namespace,
 			"subject_id":           relationToCreate.Subject.ID,
@@ -37,6 +38,10 @@ func (r RelationRepository) Create(ctx context.Context, relationToCreate relatio
 		goqu.DoUpdate("subject_namespace_id, subject_id, object_namespace_id,  object_id, role_id", goqu.Record{
 			"subject_namespace_id": relationToCreate.Subject.Namespace,
 		})).Returning(&relationCols{}).ToSQL()
+}
+
+func (r RelationRepository) Create(ctx context.Context, relationToCreate relation.RelationV2) (relation.RelationV2, error) {
+	query, params, err := createRelationQuery(relationToCreate)
 	if err != nil {
 		return relation.RelationV2{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -68,6 +73,57 @@ func (r RelationRepository) Create(ctx context.Context, relationToCreate relatio
 	return relationModel.transformToRelationV2(), nil
 }
 
+// CreateBatch creates the relations in a single transaction, every relation
+// in a savepoint so the ones refused for an unknown namespace or role are
+// reported without rolling the others back
+func (r RelationRepository) CreateBatch(ctx context.Context, relations []relation.RelationV2) ([]relation.RelationV2, []relation.BatchFailure, error) {
+	queries := make([]string, len(relations))
+	params := make([][]interface{}, len(relations))
+	for i, rel := range relations {
+		var err error
+		if queries[i], params[i], err = createRelationQuery(rel); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", queryErr, err)
+		}
+	}
+
+	var created []relation.RelationV2
+	var failures []relation.BatchFailure
+	if err := r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_RELATIONS,
+				Operation:  "CreateBatch",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+
+		for i := range relations {
+			var relationModel Relation
+			// the timeout is per relation, a batch takes as long as its size
+			err := r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+				return withSavepoint(ctx, tx, func() error {
+					return tx.QueryRowxContext(ctx, queries[i], params[i]...).StructScan(&relationModel)
+				})
+			})
+			switch err = checkPostgresError(err); {
+			case err == nil:
+				created = append(created, relationModel.transformToRelationV2())
+			case errors.Is(err, errForeignKeyViolation):
+				failures = append(failures, relation.BatchFailure{Index: i, Err: fmt.Errorf("%w: %s", relation.ErrInvalidDetail, err)})
+			default:
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", txnErr, err)
+	}
+	return created, failures, nil
+}
+
 func (r RelationRepository) List(ctx context.Context) ([]relation.RelationV2, error) {
 	query, params, err := dialect.Select(&relationCols{}).From(TABLE_RELATIONS).ToSQL()
 	if err != nil {
@@ -87,7 +143,7 @@ func (r RelationRepository) List(ctx context.Context) ([]relation.RelationV2, er
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedRelations, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedRelations, query, params...)
 	}); err != nil {
 		// List should return empty list and no error instead
 		if errors.Is(err, sql.ErrNoRows) {
@@ -130,7 +186,7 @@ func (r RelationRepository) Get(ctx context.Context, id string) (relation.Relati
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &relationModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &relationModel, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -194,6 +250,46 @@ func (r RelationRepository) DeleteByID(ctx context.Context, id string) error {
 	})
 }
 
+// DeleteBySubject removes the relations of the subject, the removed
+// relations are returned
+func (r RelationRepository) DeleteBySubject(ctx context.Context, sub relation.Subject) ([]relation.RelationV2, error) {
+	query, params, err := dialect.Delete(TABLE_RELATIONS).Where(goqu.Ex{
+		"subject_namespace_id": sub.Namespace,
+		"subject_id":           sub.ID,
+	}).Returning(&relationCols{}).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var deletedRelations []Relation
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_RELATIONS,
+				Operation:  "DeleteBySubject",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+
+		return r.dbc.SelectContext(ctx, &deletedRelations, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		if errors.Is(err, errInvalidTexRepresentation) {
+			return nil, relation.ErrInvalidUUID
+		}
+		return nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedRelations []relation.RelationV2
+	for _, r := range deletedRelations {
+		transformedRelations = append(transformedRelations, r.transformToRelationV2())
+	}
+	return transformedRelations, nil
+}
+
 // Update TO_DEPRECIATE
 func (r RelationRepository) Update(ctx context.Context, rel relation.Relation) (relation.Relation, error) {
 	return relation.Relation{}, nil
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"encoding/json"
 	"time"
 
 	"database/sql"
@@ -20,12 +21,21 @@ type Resource struct {
 	Namespace      Namespace      `db:"namespace"`
 	User           User           `db:"user"`
 	UserID         sql.NullString `db:"user_id"`
+	FolderID       sql.NullString `db:"folder_id"`
+	Tags           []byte         `db:"tags"`
 	CreatedAt      time.Time      `db:"created_at"`
 	UpdatedAt      time.Time      `db:"updated_at"`
 	DeletedAt      sql.NullTime   `db:"deleted_at"`
 }
 
-func (from Resource) transformToResource() resource.Resource {
+func (from Resource) transformToResource() (resource.Resource, error) {
+	tags := map[string]string{}
+	if len(from.Tags) > 0 {
+		if err := json.Unmarshal(from.Tags, &tags); err != nil {
+			return resource.Resource{}, err
+		}
+	}
+
 	// TODO: remove *ID
 	return resource.Resource{
 		Idxa:           from.ID,
@@ -35,9 +45,11 @@ func (from Resource) transformToResource() resource.Resource {
 		NamespaceID:    from.NamespaceID,
 		OrganizationID: from.OrganizationID,
 		UserID:         from.UserID.String,
+		FolderID:       from.FolderID.String,
+		Tags:           tags,
 		CreatedAt:      from.CreatedAt,
 		UpdatedAt:      from.UpdatedAt,
-	}
+	}, nil
 }
 
 type ResourceCols struct {
@@ -48,6 +60,8 @@ type ResourceCols struct {
 	OrganizationID string         `db:"org_id"`
 	NamespaceID    string         `db:"namespace_id"`
 	UserID         sql.NullString `db:"user_id"`
+	FolderID       sql.NullString `db:"folder_id"`
+	Tags           []byte         `db:"tags"`
 	CreatedAt      time.Time      `db:"created_at"`
 	UpdatedAt      time.Time      `db:"updated_at"`
 }
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -31,6 +32,22 @@ func (r ResourceRepository) Create(ctx context.Context, res resource.Resource) (
 	}
 
 	userID := sql.NullString{String: res.UserID, Valid: res.UserID != ""}
+	tags, err := marshalTags(res.Tags)
+	if err != nil {
+		return resource.Resource{}, err
+	}
+
+	// creating an existing resource again keeps its tags unless set
+	onConflict := goqu.Record{
+		"name":         res.Name,
+		"project_id":   res.ProjectID,
+		"org_id":       res.OrganizationID,
+		"namespace_id": res.NamespaceID,
+		"user_id":      userID,
+	}
+	if res.Tags != nil {
+		onConflict["tags"] = tags
+	}
 
 	query, params, err := dialect.Insert(TABLE_RESOURCES).Rows(
 		goqu.Record{
@@ -40,14 +57,9 @@ func (r ResourceRepository) Create(ctx context.Context, res resource.Resource) (
 			"org_id":       res.OrganizationID,
 			"namespace_id": res.NamespaceID,
 			"user_id":      userID,
+			"tags":         tags,
 		}).OnConflict(
-		goqu.DoUpdate("ON CONSTRAINT resources_urn_unique", goqu.Record{
-			"name":         res.Name,
-			"project_id":   res.ProjectID,
-			"org_id":       res.OrganizationID,
-			"namespace_id": res.NamespaceID,
-			"user_id":      userID,
-		})).Returning(&ResourceCols{}).ToSQL()
+		goqu.DoUpdate("ON CONSTRAINT resources_urn_unique", onConflict)).Returning(&ResourceCols{}).ToSQL()
 	if err != nil {
 		return resource.Resource{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -78,13 +90,20 @@ func (r ResourceRepository) Create(ctx context.Context, res resource.Resource) (
 		}
 	}
 
-	return resourceModel.transformToResource(), nil
+	transformedResource, err := resourceModel.transformToResource()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedResource, nil
 }
 
 func (r ResourceRepository) List(ctx context.Context, flt resource.Filter) ([]resource.Resource, error) {
 	var fetchedResources []Resource
 
 	sqlStatement := dialect.From(TABLE_RESOURCES)
+	if len(flt.IDs) > 0 {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"id": flt.IDs})
+	}
 	if flt.ProjectID != "" {
 		sqlStatement = sqlStatement.Where(goqu.Ex{"project_id": flt.ProjectID})
 	}
@@ -97,6 +116,16 @@ func (r ResourceRepository) List(ctx context.Context, flt resource.Filter) ([]re
 	if flt.NamespaceID != "" {
 		sqlStatement = sqlStatement.Where(goqu.Ex{"namespace_id": flt.NamespaceID})
 	}
+	if flt.FolderID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"folder_id": flt.FolderID})
+	}
+	if len(flt.Tags) > 0 {
+		tags, err := marshalTags(flt.Tags)
+		if err != nil {
+			return nil, err
+		}
+		sqlStatement = sqlStatement.Where(goqu.L("tags @> ?", tags))
+	}
 	query, params, err := sqlStatement.ToSQL()
 	if err != nil {
 		return nil, err
@@ -114,7 +143,7 @@ func (r ResourceRepository) List(ctx context.Context, flt resource.Filter) ([]re
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedResources, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedResources, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -128,7 +157,11 @@ func (r ResourceRepository) List(ctx context.Context, flt resource.Filter) ([]re
 
 	var transformedResources []resource.Resource
 	for _, r := range fetchedResources {
-		transformedResources = append(transformedResources, r.transformToResource())
+		transformedResource, err := r.transformToResource()
+		if err != nil {
+			return []resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedResources = append(transformedResources, transformedResource)
 	}
 
 	return transformedResources, nil
@@ -159,7 +192,7 @@ func (r ResourceRepository) GetByID(ctx context.Context, id string) (resource.Re
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &resourceModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &resourceModel, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -172,7 +205,11 @@ func (r ResourceRepository) GetByID(ctx context.Context, id string) (resource.Re
 		}
 	}
 
-	return resourceModel.transformToResource(), nil
+	transformedResource, err := resourceModel.transformToResource()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedResource, nil
 }
 
 func (r ResourceRepository) Update(ctx context.Context, id string, res resource.Resource) (resource.Resource, error) {
@@ -184,13 +221,22 @@ func (r ResourceRepository) Update(ctx context.Context, id string, res resource.
 		return resource.Resource{}, resource.ErrInvalidUUID
 	}
 
+	record := goqu.Record{
+		"name":         res.Name,
+		"project_id":   res.ProjectID,
+		"org_id":       res.OrganizationID,
+		"namespace_id": res.NamespaceID,
+	}
+	if res.Tags != nil {
+		tags, err := marshalTags(res.Tags)
+		if err != nil {
+			return resource.Resource{}, err
+		}
+		record["tags"] = tags
+	}
+
 	query, params, err := dialect.Update(TABLE_RESOURCES).Set(
-		goqu.Record{
-			"name":         res.Name,
-			"project_id":   res.ProjectID,
-			"org_id":       res.OrganizationID,
-			"namespace_id": res.NamespaceID,
-		},
+		record,
 	).Where(goqu.Ex{
 		"id": id,
 	}).Returning(&ResourceCols{}).ToSQL()
@@ -228,7 +274,65 @@ func (r ResourceRepository) Update(ctx context.Context, id string, res resource.
 		}
 	}
 
-	return resourceModel.transformToResource(), nil
+	transformedResource, err := resourceModel.transformToResource()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedResource, nil
+}
+
+// SetFolder moves the resource into the folder, an empty folder id moves it
+// out of its folder
+func (r ResourceRepository) SetFolder(ctx context.Context, id, folderID string) (resource.Resource, error) {
+	if strings.TrimSpace(id) == "" {
+		return resource.Resource{}, resource.ErrInvalidID
+	}
+
+	query, params, err := dialect.Update(TABLE_RESOURCES).Set(
+		goqu.Record{
+			"folder_id":  sql.NullString{String: folderID, Valid: folderID != ""},
+			"updated_at": goqu.L("now()"),
+		},
+	).Where(goqu.Ex{
+		"id": id,
+	}).Returning(&ResourceCols{}).ToSQL()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var resourceModel Resource
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_RESOURCES,
+				Operation:  "SetFolder",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&resourceModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return resource.Resource{}, resource.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return resource.Resource{}, fmt.Errorf("%w: folder doesn't exist", resource.ErrInvalidDetail)
+		case errors.Is(err, errInvalidTexRepresentation):
+			return resource.Resource{}, resource.ErrInvalidUUID
+		default:
+			return resource.Resource{}, err
+		}
+	}
+
+	transformedResource, err := resourceModel.transformToResource()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedResource, nil
 }
 
 func (r ResourceRepository) GetByURN(ctx context.Context, urn string) (resource.Resource, error) {
@@ -257,7 +361,7 @@ func (r ResourceRepository) GetByURN(ctx context.Context, urn string) (resource.
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &resourceModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &resourceModel, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return resource.Resource{}, resource.ErrNotExist
@@ -265,7 +369,11 @@ func (r ResourceRepository) GetByURN(ctx context.Context, urn string) (resource.
 		return resource.Resource{}, err
 	}
 
-	return resourceModel.transformToResource(), nil
+	transformedResource, err := resourceModel.transformToResource()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedResource, nil
 }
 
 func buildGetResourcesByNamespaceQuery(dialect goqu.DialectWrapper, name string, namespace string) (string, interface{}, error) {
@@ -297,7 +405,7 @@ func (r ResourceRepository) GetByNamespace(ctx context.Context, name string, ns
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &fetchedResource, query)
+		return r.dbc.Reader().GetContext(ctx, &fetchedResource, query)
 	})
 
 	if err != nil {
@@ -308,5 +416,20 @@ func (r ResourceRepository) GetByNamespace(ctx context.Context, name string, ns
 		return resource.Resource{}, fmt.Errorf("%w: %s", dbErr, err)
 	}
 
-	return fetchedResource.transformToResource(), nil
+	transformedResource, err := fetchedResource.transformToResource()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedResource, nil
+}
+
+func marshalTags(tags map[string]string) (string, error) {
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	marshaled, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return string(marshaled), nil
 }
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+)
+
+var txnRetryBackoff = 10 * time.Millisecond
+
+// isRetryableError reports whether postgres aborted the statement because of
+// contention with a concurrent transaction, in which case running it again
+// is expected to succeed
+func isRetryableError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.SerializationFailure, pgerrcode.DeadlockDetected:
+			return true
+		}
+	}
+	return false
+}
+
+// withTxnRetry runs op and retries it up to maxRetries times with a linear
+// backoff as long as it fails with a serialization failure or a deadlock
+func withTxnRetry(ctx context.Context, maxRetries int, op func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := op(ctx)
+		if err == nil || !isRetryableError(err) || attempt >= maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(txnRetryBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTxnRetry(t *testing.T) {
+	txnRetryBackoff = 0
+
+	serializationErr := &pgconn.PgError{Code: pgerrcode.SerializationFailure}
+	deadlockErr := &pgconn.PgError{Code: pgerrcode.DeadlockDetected}
+	uniqueErr := &pgconn.PgError{Code: pgerrcode.UniqueViolation}
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		errs       []error
+		wantCalls  int
+		wantErr    error
+	}{
+		{
+			name:       "should succeed after a serialization failure on the first attempt",
+			maxRetries: 3,
+			errs:       []error{serializationErr, nil},
+			wantCalls:  2,
+		},
+		{
+			name:       "should retry a deadlock",
+			maxRetries: 3,
+			errs:       []error{deadlockErr, deadlockErr, nil},
+			wantCalls:  3,
+		},
+		{
+			name:       "should surface the error once retries are exhausted",
+			maxRetries: 2,
+			errs:       []error{serializationErr, serializationErr, serializationErr, nil},
+			wantCalls:  3,
+			wantErr:    serializationErr,
+		},
+		{
+			name:       "should not retry other postgres errors",
+			maxRetries: 3,
+			errs:       []error{uniqueErr, nil},
+			wantCalls:  1,
+			wantErr:    uniqueErr,
+		},
+		{
+			name:       "should not retry when retries are disabled",
+			maxRetries: 0,
+			errs:       []error{serializationErr, nil},
+			wantCalls:  1,
+			wantErr:    serializationErr,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := withTxnRetry(context.Background(), tt.maxRetries, func(ctx context.Context) error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.True(t, errors.Is(err, tt.wantErr))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"database/sql"
 	"encoding/json"
 	"time"
 
@@ -14,6 +15,8 @@ type Role struct {
 	Types       pq.StringArray `db:"types"`
 	Namespace   Namespace      `db:"namespace"`
 	NamespaceID string         `db:"namespace_id"`
+	OrgID       sql.NullString `db:"org_id"`
+	Includes    pq.StringArray `db:"includes"`
 	Metadata    []byte         `db:"metadata"`
 	CreatedAt   time.Time      `db:"created_at"`
 	UpdatedAt   time.Time      `db:"updated_at"`
@@ -32,6 +35,8 @@ func (from Role) transformToRole() (role.Role, error) {
 		Name:        from.Name,
 		Types:       from.Types,
 		NamespaceID: from.NamespaceID,
+		OrgID:       from.OrgID.String,
+		Includes:    from.Includes,
 		Metadata:    unmarshalledMetadata,
 		CreatedAt:   from.CreatedAt,
 		UpdatedAt:   from.UpdatedAt,
@@ -10,6 +10,7 @@ import (
 	"database/sql"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/lib/pq"
 	newrelic "github.com/newrelic/go-agent"
 	"github.com/odpf/shield/core/role"
 	"github.com/odpf/shield/pkg/db"
@@ -31,6 +32,8 @@ func (r RoleRepository) buildListQuery(dialect goqu.DialectWrapper) *goqu.Select
 		goqu.I("r.name"),
 		goqu.I("r.types"),
 		goqu.I("r.namespace_id"),
+		goqu.I("r.org_id"),
+		goqu.I("r.includes"),
 		goqu.I("r.metadata"),
 		goqu.I("namespaces.id").As(goqu.C("namespace.id")),
 		goqu.I("namespaces.name").As(goqu.C("namespace.name")),
@@ -65,7 +68,7 @@ func (r RoleRepository) Get(ctx context.Context, id string) (role.Role, error) {
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &roleModel, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &roleModel, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return role.Role{}, role.ErrNotExist
@@ -104,6 +107,8 @@ func (r RoleRepository) Create(ctx context.Context, rl role.Role) (string, error
 			"types":        goqu.L("$3"),
 			"namespace_id": goqu.L("$4"),
 			"metadata":     goqu.L("$5"),
+			"org_id":       goqu.L("$6"),
+			"includes":     goqu.L("$7"),
 		}).OnConflict(
 		goqu.DoUpdate("id", goqu.Record{
 			"name": goqu.L("$2"),
@@ -126,7 +131,8 @@ func (r RoleRepository) Create(ctx context.Context, rl role.Role) (string, error
 			defer nr.End()
 		}
 
-		return r.dbc.QueryRowxContext(ctx, query, rl.ID, rl.Name, rl.Types, rl.NamespaceID, marshaledMetadata).Scan(&roleID)
+		return r.dbc.QueryRowxContext(ctx, query, rl.ID, rl.Name, rl.Types, rl.NamespaceID, marshaledMetadata,
+			sql.NullString{String: rl.OrgID, Valid: rl.OrgID != ""}, pq.StringArray(rl.Includes)).Scan(&roleID)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -161,7 +167,7 @@ func (r RoleRepository) List(ctx context.Context) ([]role.Role, error) {
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedRoles, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedRoles, query, params...)
 	}); err != nil {
 		return []role.Role{}, fmt.Errorf("%w: %s", dbErr, err)
 	}
@@ -199,6 +205,7 @@ func (r RoleRepository) Update(ctx context.Context, rl role.Role) (string, error
 			"types":        goqu.L("$3"),
 			"namespace_id": goqu.L("$4"),
 			"metadata":     goqu.L("$5"),
+			"includes":     goqu.L("$6"),
 			"updated_at":   goqu.L("now()"),
 		}).Where(
 		goqu.Ex{"id": goqu.L("$1")},
@@ -220,7 +227,7 @@ func (r RoleRepository) Update(ctx context.Context, rl role.Role) (string, error
 			defer nr.End()
 		}
 
-		return r.dbc.QueryRowxContext(ctx, query, rl.ID, rl.Name, rl.Types, rl.NamespaceID, marshaledMetadata).Scan(&roleID)
+		return r.dbc.QueryRowxContext(ctx, query, rl.ID, rl.Name, rl.Types, rl.NamespaceID, marshaledMetadata, pq.StringArray(rl.Includes)).Scan(&roleID)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -237,3 +244,22 @@ func (r RoleRepository) Update(ctx context.Context, rl role.Role) (string, error
 
 	return roleID, nil
 }
+
+func (r RoleRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return role.ErrInvalidID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_ROLES, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return role.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return role.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
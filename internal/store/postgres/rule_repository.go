@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+
+	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type RuleRepository struct {
+	dbc *db.Client
+}
+
+func NewRuleRepository(dbc *db.Client) *RuleRepository {
+	return &RuleRepository{
+		dbc: dbc,
+	}
+}
+
+func ruleRecord(rl rule.Rule) (goqu.Record, error) {
+	if rl.Middlewares == nil {
+		rl.Middlewares = rule.MiddlewareSpecs{}
+	}
+	if rl.Hooks == nil {
+		rl.Hooks = rule.HookSpecs{}
+	}
+	middlewares, err := json.Marshal(rl.Middlewares)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	hooks, err := json.Marshal(rl.Hooks)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	policy, err := json.Marshal(rl.Backend.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	if rl.Backend.Splits == nil {
+		rl.Backend.Splits = []rule.Split{}
+	}
+	splits, err := json.Marshal(rl.Backend.Splits)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return goqu.Record{
+		"name":                   rl.Name,
+		"proxy":                  rl.Proxy,
+		"frontend_url":           rl.Frontend.URL,
+		"frontend_method":        rl.Frontend.Method,
+		"frontend_max_body_size": rl.Frontend.MaxBodySize,
+		"backend_url":            rl.Backend.URL,
+		"backend_name":           rl.Backend.Namespace,
+		"backend_prefix":         rl.Backend.Prefix,
+		"backend_policy":         policy,
+		"backend_splits":         splits,
+		"middlewares":            middlewares,
+		"hooks":                  hooks,
+	}, nil
+}
+
+func (r RuleRepository) Create(ctx context.Context, rl rule.Rule) (rule.Rule, error) {
+	record, err := ruleRecord(rl)
+	if err != nil {
+		return rule.Rule{}, err
+	}
+
+	query, params, err := dialect.Insert(TABLE_RULES).Rows(record).Returning(&Rule{}).ToSQL()
+	if err != nil {
+		return rule.Rule{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var ruleModel Rule
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_RULES,
+				Operation:  "Create",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&ruleModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		if errors.Is(err, errDuplicateKey) {
+			return rule.Rule{}, rule.ErrConflict
+		}
+		return rule.Rule{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return ruleModel.transformToRule()
+}
+
+func (r RuleRepository) Update(ctx context.Context, rl rule.Rule) (rule.Rule, error) {
+	record, err := ruleRecord(rl)
+	if err != nil {
+		return rule.Rule{}, err
+	}
+	record["updated_at"] = goqu.L("now()")
+
+	query, params, err := dialect.Update(TABLE_RULES).Set(record).Where(goqu.Ex{
+		"id": rl.ID,
+	}).Returning(&Rule{}).ToSQL()
+	if err != nil {
+		return rule.Rule{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var ruleModel Rule
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_RULES,
+				Operation:  "Update",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&ruleModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return rule.Rule{}, rule.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return rule.Rule{}, rule.ErrConflict
+		default:
+			return rule.Rule{}, fmt.Errorf("%s: %w", txnErr, err)
+		}
+	}
+
+	return ruleModel.transformToRule()
+}
+
+func (r RuleRepository) Get(ctx context.Context, id string) (rule.Rule, error) {
+	query, params, err := dialect.From(TABLE_RULES).Where(goqu.Ex{"id": id}).ToSQL()
+	if err != nil {
+		return rule.Rule{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var ruleModel Rule
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_RULES,
+				Operation:  "Get",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.GetContext(ctx, &ruleModel, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return rule.Rule{}, rule.ErrNotExist
+		default:
+			return rule.Rule{}, err
+		}
+	}
+
+	return ruleModel.transformToRule()
+}
+
+// List returns the rules in the order they were created, the proxies match
+// them in that order
+func (r RuleRepository) List(ctx context.Context, flt rule.Filter) ([]rule.Rule, error) {
+	sqlStatement := dialect.From(TABLE_RULES)
+	if flt.Proxy != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"proxy": flt.Proxy})
+	}
+	query, params, err := sqlStatement.Order(goqu.C("created_at").Asc(), goqu.C("name").Asc()).ToSQL()
+	if err != nil {
+		return []rule.Rule{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var ruleModels []Rule
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_RULES,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &ruleModels, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return []rule.Rule{}, nil
+		}
+		return []rule.Rule{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedRules []rule.Rule
+	for _, rm := range ruleModels {
+		rl, err := rm.transformToRule()
+		if err != nil {
+			return []rule.Rule{}, err
+		}
+		transformedRules = append(transformedRules, rl)
+	}
+
+	return transformedRules, nil
+}
+
+// Version is the number of the rules along with the time the last of them
+// was updated, it is cheap enough to be polled by the proxies
+func (r RuleRepository) Version(ctx context.Context) (string, error) {
+	query, params, err := dialect.From(TABLE_RULES).Select(
+		goqu.COUNT("*").As("count"),
+		goqu.MAX("updated_at").As("updated_at"),
+	).ToSQL()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var version struct {
+		Count     int64        `db:"count"`
+		UpdatedAt sql.NullTime `db:"updated_at"`
+	}
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_RULES,
+				Operation:  "Version",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.GetContext(ctx, &version, query, params...)
+	}); err != nil {
+		return "", fmt.Errorf("%w: %s", dbErr, checkPostgresError(err))
+	}
+
+	return strconv.FormatInt(version.Count, 10) + ":" + strconv.FormatInt(version.UpdatedAt.Time.UnixNano(), 10), nil
+}
@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// withSavepoint runs op in a savepoint of the transaction so a statement of
+// op failing is rolled back without aborting the transaction, the other
+// rows of a batch are still written then. The error of op is returned, or
+// the error rolling back to the savepoint which leaves the transaction
+// aborted.
+func withSavepoint(ctx context.Context, tx *sqlx.Tx, op func() error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+		return err
+	}
+	opErr := op()
+	if opErr == nil {
+		_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item")
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item"); err != nil {
+		return fmt.Errorf("%w: rollback to savepoint: %s", txnErr, err)
+	}
+	return opErr
+}
@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type ServiceUserRepository struct {
+	dbc *db.Client
+}
+
+func NewServiceUserRepository(dbc *db.Client) *ServiceUserRepository {
+	return &ServiceUserRepository{
+		dbc: dbc,
+	}
+}
+
+func (r ServiceUserRepository) Create(ctx context.Context, serviceUser serviceuser.ServiceUser) (serviceuser.ServiceUser, error) {
+	if strings.TrimSpace(serviceUser.Name) == "" || strings.TrimSpace(serviceUser.UserID) == "" {
+		return serviceuser.ServiceUser{}, serviceuser.ErrInvalidDetail
+	}
+
+	query, params, err := dialect.Insert(TABLE_SERVICE_USERS).Rows(
+		goqu.Record{
+			"user_id": serviceUser.UserID,
+			"name":    serviceUser.Name,
+		}).Returning(&ServiceUser{}).ToSQL()
+	if err != nil {
+		return serviceuser.ServiceUser{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var serviceUserModel ServiceUser
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SERVICE_USERS,
+				Operation:  "Create",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&serviceUserModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, errDuplicateKey):
+			return serviceuser.ServiceUser{}, serviceuser.ErrConflict
+		default:
+			return serviceuser.ServiceUser{}, err
+		}
+	}
+
+	return serviceUserModel.transformToServiceUser(), nil
+}
+
+func (r ServiceUserRepository) Get(ctx context.Context, id string) (serviceuser.ServiceUser, error) {
+	if strings.TrimSpace(id) == "" {
+		return serviceuser.ServiceUser{}, serviceuser.ErrInvalidID
+	}
+
+	query, params, err := dialect.From(TABLE_SERVICE_USERS).Where(goqu.Ex{
+		"id": id,
+	}).ToSQL()
+	if err != nil {
+		return serviceuser.ServiceUser{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var serviceUserModel ServiceUser
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SERVICE_USERS,
+				Operation:  "Get",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.GetContext(ctx, &serviceUserModel, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return serviceuser.ServiceUser{}, serviceuser.ErrNotExist
+		case errors.Is(err, errInvalidTexRepresentation):
+			return serviceuser.ServiceUser{}, serviceuser.ErrInvalidID
+		default:
+			return serviceuser.ServiceUser{}, err
+		}
+	}
+
+	return serviceUserModel.transformToServiceUser(), nil
+}
+
+func (r ServiceUserRepository) List(ctx context.Context) ([]serviceuser.ServiceUser, error) {
+	query, params, err := dialect.From(TABLE_SERVICE_USERS).Order(goqu.C("name").Asc()).ToSQL()
+	if err != nil {
+		return []serviceuser.ServiceUser{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var serviceUserModels []ServiceUser
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SERVICE_USERS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &serviceUserModels, query, params...)
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []serviceuser.ServiceUser{}, nil
+		}
+		return []serviceuser.ServiceUser{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedServiceUsers []serviceuser.ServiceUser
+	for _, su := range serviceUserModels {
+		transformedServiceUsers = append(transformedServiceUsers, su.transformToServiceUser())
+	}
+
+	return transformedServiceUsers, nil
+}
+
+func (r ServiceUserRepository) CreateKey(ctx context.Context, key serviceuser.Key) (serviceuser.Key, error) {
+	if strings.TrimSpace(key.ServiceUserID) == "" || strings.TrimSpace(key.Algorithm) == "" || len(key.PublicKey) == 0 {
+		return serviceuser.Key{}, serviceuser.ErrInvalidDetail
+	}
+
+	query, params, err := dialect.Insert(TABLE_SERVICE_USER_KEYS).Rows(
+		goqu.Record{
+			"service_user_id": key.ServiceUserID,
+			"algorithm":       key.Algorithm,
+			"public_key":      string(key.PublicKey),
+		}).Returning(&ServiceUserKey{}).ToSQL()
+	if err != nil {
+		return serviceuser.Key{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModel ServiceUserKey
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SERVICE_USER_KEYS,
+				Operation:  "Create",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&keyModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, errForeignKeyViolation):
+			return serviceuser.Key{}, serviceuser.ErrNotExist
+		default:
+			return serviceuser.Key{}, err
+		}
+	}
+
+	return keyModel.transformToKey(), nil
+}
+
+func (r ServiceUserRepository) GetKey(ctx context.Context, id string) (serviceuser.Key, error) {
+	if strings.TrimSpace(id) == "" {
+		return serviceuser.Key{}, serviceuser.ErrKeyNotExist
+	}
+
+	query, params, err := dialect.From(TABLE_SERVICE_USER_KEYS).Where(goqu.Ex{
+		"id": id,
+	}).ToSQL()
+	if err != nil {
+		return serviceuser.Key{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModel ServiceUserKey
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SERVICE_USER_KEYS,
+				Operation:  "GetKey",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.GetContext(ctx, &keyModel, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return serviceuser.Key{}, serviceuser.ErrKeyNotExist
+		default:
+			return serviceuser.Key{}, err
+		}
+	}
+
+	return keyModel.transformToKey(), nil
+}
+
+func (r ServiceUserRepository) ListKeys(ctx context.Context, serviceUserID string) ([]serviceuser.Key, error) {
+	query, params, err := dialect.From(TABLE_SERVICE_USER_KEYS).Where(goqu.Ex{
+		"service_user_id": serviceUserID,
+	}).Order(goqu.C("created_at").Asc()).ToSQL()
+	if err != nil {
+		return []serviceuser.Key{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModels []ServiceUserKey
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SERVICE_USER_KEYS,
+				Operation:  "ListKeys",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &keyModels, query, params...)
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []serviceuser.Key{}, nil
+		}
+		return []serviceuser.Key{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedKeys []serviceuser.Key
+	for _, k := range keyModels {
+		transformedKeys = append(transformedKeys, k.transformToKey())
+	}
+
+	return transformedKeys, nil
+}
@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type SessionRepository struct {
+	dbc *db.Client
+}
+
+func NewSessionRepository(dbc *db.Client) *SessionRepository {
+	return &SessionRepository{
+		dbc: dbc,
+	}
+}
+
+func (r SessionRepository) Create(ctx context.Context, sess session.Session) (session.Session, error) {
+	if strings.TrimSpace(sess.UserID) == "" || strings.TrimSpace(sess.AccessHash) == "" || strings.TrimSpace(sess.RefreshHash) == "" {
+		return session.Session{}, session.ErrInvalidDetail
+	}
+
+	query, params, err := dialect.Insert(TABLE_SESSIONS).Rows(
+		goqu.Record{
+			"user_id":           sess.UserID,
+			"user_agent":        sess.UserAgent,
+			"access_hash":       sess.AccessHash,
+			"access_expires_at": sess.AccessExpiresAt,
+			"refresh_hash":      sess.RefreshHash,
+			"expires_at":        sess.ExpiresAt,
+		}).Returning(&Session{}).ToSQL()
+	if err != nil {
+		return session.Session{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var sessionModel Session
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SESSIONS,
+				Operation:  "Create",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&sessionModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, errForeignKeyViolation):
+			return session.Session{}, fmt.Errorf("%w: user doesn't exist", session.ErrInvalidDetail)
+		default:
+			return session.Session{}, err
+		}
+	}
+
+	return sessionModel.transformToSession(), nil
+}
+
+func (r SessionRepository) Get(ctx context.Context, id string) (session.Session, error) {
+	return r.getBy(ctx, "Get", goqu.Ex{"id": id})
+}
+
+func (r SessionRepository) GetByAccessHash(ctx context.Context, hash string) (session.Session, error) {
+	return r.getBy(ctx, "GetByAccessHash", goqu.Ex{"access_hash": hash})
+}
+
+func (r SessionRepository) GetByRefreshHash(ctx context.Context, hash string) (session.Session, error) {
+	return r.getBy(ctx, "GetByRefreshHash", goqu.Ex{"refresh_hash": hash})
+}
+
+func (r SessionRepository) getBy(ctx context.Context, operation string, ex goqu.Ex) (session.Session, error) {
+	query, params, err := dialect.From(TABLE_SESSIONS).Where(ex).ToSQL()
+	if err != nil {
+		return session.Session{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var sessionModel Session
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SESSIONS,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.GetContext(ctx, &sessionModel, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return session.Session{}, session.ErrNotExist
+		default:
+			return session.Session{}, err
+		}
+	}
+
+	return sessionModel.transformToSession(), nil
+}
+
+func (r SessionRepository) List(ctx context.Context, userID string) ([]session.Session, error) {
+	sqlStatement := dialect.From(TABLE_SESSIONS)
+	if userID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"user_id": userID})
+	}
+
+	query, params, err := sqlStatement.Order(goqu.C("created_at").Desc()).ToSQL()
+	if err != nil {
+		return []session.Session{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var sessionModels []Session
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SESSIONS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &sessionModels, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return []session.Session{}, nil
+		case errors.Is(err, errInvalidTexRepresentation):
+			return []session.Session{}, nil
+		default:
+			return []session.Session{}, fmt.Errorf("%w: %s", dbErr, err)
+		}
+	}
+
+	var transformedSessions []session.Session
+	for _, s := range sessionModels {
+		transformedSessions = append(transformedSessions, s.transformToSession())
+	}
+
+	return transformedSessions, nil
+}
+
+func (r SessionRepository) Rotate(ctx context.Context, toRotate session.Session, refreshHash string) (session.Session, error) {
+	query, params, err := dialect.Update(TABLE_SESSIONS).Set(
+		goqu.Record{
+			"access_hash":       toRotate.AccessHash,
+			"access_expires_at": toRotate.AccessExpiresAt,
+			"refresh_hash":      toRotate.RefreshHash,
+			"refreshed_at":      goqu.L("now()"),
+		}).Where(goqu.Ex{
+		"id":           toRotate.ID,
+		"refresh_hash": refreshHash,
+		"revoked_at":   nil,
+	}).Returning(&Session{}).ToSQL()
+	if err != nil {
+		return session.Session{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var sessionModel Session
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SESSIONS,
+				Operation:  "Rotate",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&sessionModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return session.Session{}, session.ErrNotExist
+		default:
+			return session.Session{}, err
+		}
+	}
+
+	return sessionModel.transformToSession(), nil
+}
+
+func (r SessionRepository) Revoke(ctx context.Context, id string) (session.Session, error) {
+	query, params, err := dialect.Update(TABLE_SESSIONS).Set(
+		goqu.Record{
+			"revoked_at": goqu.L("now()"),
+		}).Where(goqu.Ex{
+		"id":         id,
+		"revoked_at": nil,
+	}).Returning(&Session{}).ToSQL()
+	if err != nil {
+		return session.Session{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var sessionModel Session
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SESSIONS,
+				Operation:  "Revoke",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&sessionModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return session.Session{}, session.ErrNotExist
+		default:
+			return session.Session{}, err
+		}
+	}
+
+	return sessionModel.transformToSession(), nil
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/odpf/shield/core/signingkey"
+)
+
+type SigningKey struct {
+	ID        string `db:"id"`
+	Algorithm string `db:"algorithm"`
+	PublicKey string `db:"public_key"`
+	// PrivateKey is the encrypted private key in base64, goqu renders the
+	// values in the query so it can't be kept as bytea
+	PrivateKey  string    `db:"private_key"`
+	CreatedAt   time.Time `db:"created_at"`
+	ActivatesAt time.Time `db:"activates_at"`
+}
+
+func (from SigningKey) transformToSigningKey() (signingkey.Key, error) {
+	privateKey, err := base64.StdEncoding.DecodeString(from.PrivateKey)
+	if err != nil {
+		return signingkey.Key{}, err
+	}
+	return signingkey.Key{
+		ID:                  from.ID,
+		Algorithm:           from.Algorithm,
+		PublicKey:           []byte(from.PublicKey),
+		EncryptedPrivateKey: privateKey,
+		CreatedAt:           from.CreatedAt,
+		ActivatesAt:         from.ActivatesAt,
+	}, nil
+}
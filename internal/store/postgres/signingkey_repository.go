@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/signingkey"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type SigningKeyRepository struct {
+	dbc *db.Client
+}
+
+func NewSigningKeyRepository(dbc *db.Client) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		dbc: dbc,
+	}
+}
+
+func (r SigningKeyRepository) Create(ctx context.Context, key signingkey.Key, createdAfter time.Time) (signingkey.Key, error) {
+	if !uuid.IsValid(key.ID) || strings.TrimSpace(key.Algorithm) == "" || len(key.PublicKey) == 0 || len(key.EncryptedPrivateKey) == 0 {
+		return signingkey.Key{}, signingkey.ErrInvalidDetail
+	}
+
+	newerQuery, newerParams, err := dialect.From(TABLE_SIGNING_KEYS).Select(goqu.COUNT("*")).Where(
+		goqu.C("created_at").Gt(createdAfter),
+	).ToSQL()
+	if err != nil {
+		return signingkey.Key{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+	query, params, err := dialect.Insert(TABLE_SIGNING_KEYS).Rows(
+		goqu.Record{
+			"id":           key.ID,
+			"algorithm":    key.Algorithm,
+			"public_key":   string(key.PublicKey),
+			"private_key":  base64.StdEncoding.EncodeToString(key.EncryptedPrivateKey),
+			"activates_at": key.ActivatesAt,
+		}).Returning(&SigningKey{}).ToSQL()
+	if err != nil {
+		return signingkey.Key{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModel SigningKey
+	// the instances rotating at once wait on the lock, the ones after the
+	// first then see its key
+	if err = r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+			nrCtx := newrelic.FromContext(ctx)
+			if nrCtx != nil {
+				nr := newrelic.DatastoreSegment{
+					Product:    newrelic.DatastorePostgres,
+					Collection: TABLE_SIGNING_KEYS,
+					Operation:  "Create",
+					StartTime:  nrCtx.StartSegmentNow(),
+				}
+				defer nr.End()
+			}
+
+			if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", TABLE_SIGNING_KEYS); err != nil {
+				return err
+			}
+			var newer int
+			if err := tx.QueryRowxContext(ctx, newerQuery, newerParams...).Scan(&newer); err != nil {
+				return err
+			}
+			if newer > 0 {
+				return signingkey.ErrConflict
+			}
+			return tx.QueryRowxContext(ctx, query, params...).StructScan(&keyModel)
+		})
+	}); err != nil {
+		if errors.Is(err, signingkey.ErrConflict) {
+			return signingkey.Key{}, signingkey.ErrConflict
+		}
+		return signingkey.Key{}, checkPostgresError(err)
+	}
+
+	created, err := keyModel.transformToSigningKey()
+	if err != nil {
+		return signingkey.Key{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return created, nil
+}
+
+func (r SigningKeyRepository) List(ctx context.Context) ([]signingkey.Key, error) {
+	query, params, err := dialect.From(TABLE_SIGNING_KEYS).Order(
+		goqu.C("activates_at").Asc(),
+		goqu.C("created_at").Asc(),
+	).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var keyModels []SigningKey
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SIGNING_KEYS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &keyModels, query, params...)
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %s", dbErr, checkPostgresError(err))
+	}
+
+	keys := []signingkey.Key{}
+	for _, k := range keyModels {
+		key, err := k.transformToSigningKey()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (r SigningKeyRepository) Delete(ctx context.Context, id string) error {
+	if !uuid.IsValid(id) {
+		return signingkey.ErrNotExist
+	}
+	query, params, err := dialect.Delete(TABLE_SIGNING_KEYS).Where(goqu.Ex{"id": id}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_SIGNING_KEYS,
+				Operation:  "Delete",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		result, err := r.dbc.ExecContext(ctx, query, params...)
+		if err != nil {
+			return checkPostgresError(err)
+		}
+		if count, err := result.RowsAffected(); err == nil && count == 0 {
+			return signingkey.ErrNotExist
+		}
+		return nil
+	})
+}
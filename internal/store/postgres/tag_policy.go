@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+)
+
+type TagPolicy struct {
+	ID                 string    `db:"id"`
+	NamespaceID        string    `db:"namespace_id"`
+	Tags               []byte    `db:"tags"`
+	RoleID             string    `db:"role_id"`
+	SubjectNamespaceID string    `db:"subject_namespace_id"`
+	SubjectID          string    `db:"subject_id"`
+	CreatedAt          time.Time `db:"created_at"`
+}
+
+func (from TagPolicy) transformToTagPolicy() (resource.TagPolicy, error) {
+	var tags map[string]string
+	if err := json.Unmarshal(from.Tags, &tags); err != nil {
+		return resource.TagPolicy{}, err
+	}
+
+	return resource.TagPolicy{
+		ID:          from.ID,
+		NamespaceID: from.NamespaceID,
+		Tags:        tags,
+		RoleID:      from.RoleID,
+		Subject: relation.Subject{
+			ID:        from.SubjectID,
+			Namespace: from.SubjectNamespaceID,
+		},
+		CreatedAt: from.CreatedAt,
+	}, nil
+}
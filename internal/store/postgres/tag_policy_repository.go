@@ -0,0 +1,218 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type TagPolicyRepository struct {
+	dbc *db.Client
+}
+
+func NewTagPolicyRepository(dbc *db.Client) *TagPolicyRepository {
+	return &TagPolicyRepository{
+		dbc: dbc,
+	}
+}
+
+func (r TagPolicyRepository) Create(ctx context.Context, pol resource.TagPolicy) (resource.TagPolicy, error) {
+	tags, err := marshalTags(pol.Tags)
+	if err != nil {
+		return resource.TagPolicy{}, err
+	}
+
+	query, params, err := dialect.Insert(TABLE_TAG_POLICIES).Rows(
+		goqu.Record{
+			"namespace_id":         pol.NamespaceID,
+			"tags":                 tags,
+			"role_id":              pol.RoleID,
+			"subject_namespace_id": pol.Subject.Namespace,
+			"subject_id":           pol.Subject.ID,
+		}).Returning(&TagPolicy{}).ToSQL()
+	if err != nil {
+		return resource.TagPolicy{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.queryRow(ctx, "Create", query, params)
+}
+
+func (r TagPolicyRepository) Get(ctx context.Context, id string) (resource.TagPolicy, error) {
+	if strings.TrimSpace(id) == "" {
+		return resource.TagPolicy{}, resource.ErrTagPolicyNotExist
+	}
+
+	query, params, err := dialect.From(TABLE_TAG_POLICIES).Where(goqu.Ex{
+		"id": id,
+	}).ToSQL()
+	if err != nil {
+		return resource.TagPolicy{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.queryRow(ctx, "Get", query, params)
+}
+
+func (r TagPolicyRepository) List(ctx context.Context, namespaceID string) ([]resource.TagPolicy, error) {
+	sqlStatement := dialect.From(TABLE_TAG_POLICIES)
+	if namespaceID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"namespace_id": namespaceID})
+	}
+
+	query, params, err := sqlStatement.Order(goqu.C("created_at").Asc()).ToSQL()
+	if err != nil {
+		return []resource.TagPolicy{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var policyModels []TagPolicy
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_TAG_POLICIES,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.Reader().SelectContext(ctx, &policyModels, query, params...)
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []resource.TagPolicy{}, nil
+		}
+		return []resource.TagPolicy{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedPolicies []resource.TagPolicy
+	for _, p := range policyModels {
+		transformedPolicy, err := p.transformToTagPolicy()
+		if err != nil {
+			return []resource.TagPolicy{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedPolicies = append(transformedPolicies, transformedPolicy)
+	}
+
+	return transformedPolicies, nil
+}
+
+func (r TagPolicyRepository) Delete(ctx context.Context, id string) error {
+	query, params, err := dialect.Delete(TABLE_TAG_POLICIES).Where(goqu.Ex{
+		"id": id,
+	}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.exec(ctx, TABLE_TAG_POLICIES, "Delete", query, params)
+}
+
+func (r TagPolicyRepository) ListGrants(ctx context.Context, policyID string) ([]string, error) {
+	query, params, err := dialect.From(TABLE_TAG_POLICY_GRANTS).Select("resource_id").Where(goqu.Ex{
+		"policy_id": policyID,
+	}).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var resourceIDs []string
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_TAG_POLICY_GRANTS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.Reader().SelectContext(ctx, &resourceIDs, query, params...)
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return resourceIDs, nil
+}
+
+func (r TagPolicyRepository) AddGrant(ctx context.Context, policyID, resourceID string) error {
+	query, params, err := dialect.Insert(TABLE_TAG_POLICY_GRANTS).Rows(
+		goqu.Record{
+			"policy_id":   policyID,
+			"resource_id": resourceID,
+		}).OnConflict(goqu.DoNothing()).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.exec(ctx, TABLE_TAG_POLICY_GRANTS, "Create", query, params)
+}
+
+func (r TagPolicyRepository) RemoveGrant(ctx context.Context, policyID, resourceID string) error {
+	query, params, err := dialect.Delete(TABLE_TAG_POLICY_GRANTS).Where(goqu.Ex{
+		"policy_id":   policyID,
+		"resource_id": resourceID,
+	}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.exec(ctx, TABLE_TAG_POLICY_GRANTS, "Delete", query, params)
+}
+
+func (r TagPolicyRepository) exec(ctx context.Context, collection, operation, query string, params []interface{}) error {
+	return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: collection,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		if _, err := r.dbc.ExecContext(ctx, query, params...); err != nil {
+			return checkPostgresError(err)
+		}
+		return nil
+	})
+}
+
+func (r TagPolicyRepository) queryRow(ctx context.Context, operation, query string, params []interface{}) (resource.TagPolicy, error) {
+	var policyModel TagPolicy
+	if err := r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_TAG_POLICIES,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&policyModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return resource.TagPolicy{}, resource.ErrTagPolicyNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return resource.TagPolicy{}, fmt.Errorf("%w: namespace doesn't exist", resource.ErrInvalidDetail)
+		default:
+			return resource.TagPolicy{}, err
+		}
+	}
+
+	transformedPolicy, err := policyModel.transformToTagPolicy()
+	if err != nil {
+		return resource.TagPolicy{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedPolicy, nil
+}
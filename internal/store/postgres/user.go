@@ -10,13 +10,14 @@ import (
 )
 
 type User struct {
-	ID        string       `db:"id"`
-	Name      string       `db:"name"`
-	Email     string       `db:"email"`
-	Metadata  []byte       `db:"metadata"`
-	CreatedAt time.Time    `db:"created_at"`
-	UpdatedAt time.Time    `db:"updated_at"`
-	DeletedAt sql.NullTime `db:"deleted_at"`
+	ID         string       `db:"id"`
+	Name       string       `db:"name"`
+	Email      string       `db:"email"`
+	Metadata   []byte       `db:"metadata"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DeletedAt  sql.NullTime `db:"deleted_at"`
+	DisabledAt sql.NullTime `db:"disabled_at"`
 }
 
 type UserMetadataKey struct {
@@ -35,12 +36,13 @@ func (from User) transformToUser() (user.User, error) {
 	}
 
 	return user.User{
-		ID:        from.ID,
-		Name:      from.Name,
-		Email:     from.Email,
-		Metadata:  unmarshalledMetadata,
-		CreatedAt: from.CreatedAt,
-		UpdatedAt: from.UpdatedAt,
+		ID:         from.ID,
+		Name:       from.Name,
+		Email:      from.Email,
+		Metadata:   unmarshalledMetadata,
+		CreatedAt:  from.CreatedAt,
+		UpdatedAt:  from.UpdatedAt,
+		DisabledAt: from.DisabledAt.Time,
 	}, nil
 }
 
@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/jmoiron/sqlx"
 	newrelic "github.com/newrelic/go-agent"
 	"github.com/odpf/shield/core/user"
@@ -22,13 +24,14 @@ type UserRepository struct {
 }
 
 type joinUserMetadata struct {
-	ID        string    `db:"id"`
-	Name      string    `db:"name"`
-	Email     string    `db:"email"`
-	Key       any       `db:"key"`
-	Value     any       `db:"value"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID         string       `db:"id"`
+	Name       string       `db:"name"`
+	Email      string       `db:"email"`
+	Key        any          `db:"key"`
+	Value      any          `db:"value"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DisabledAt sql.NullTime `db:"disabled_at"`
 }
 
 func NewUserRepository(dbc *db.Client) *UserRepository {
@@ -63,7 +66,7 @@ func (r UserRepository) GetByID(ctx context.Context, id string) (user.User, erro
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &fetchedUser, userQuery, params...)
+		return r.dbc.Reader().GetContext(ctx, &fetchedUser, userQuery, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -259,10 +262,16 @@ func (r UserRepository) List(ctx context.Context, flt user.Filter) ([]user.User,
 
 	query, params, err := dialect.From(TABLE_USERS).LeftOuterJoin(
 		goqu.T(TABLE_METADATA),
-		goqu.On(goqu.Ex{"users.id": goqu.I("metadata.user_id")})).Select("users.id", "name", "email", "key", "value", "users.created_at", "users.updated_at").Where(goqu.Or(
+		goqu.On(goqu.Ex{"users.id": goqu.I("metadata.user_id")})).Select("users.id", "name", "email", "key", "value", "users.created_at", "users.updated_at", "users.disabled_at").Where(goqu.Or(
 		goqu.C("name").ILike(fmt.Sprintf("%%%s%%", flt.Keyword)),
 		goqu.C("email").ILike(fmt.Sprintf("%%%s%%", flt.Keyword)),
-	)).Limit(uint(flt.Limit)).Offset(uint(offset)).ToSQL()
+	)).Where(
+		globExpressions(map[string]string{"users.name": flt.Name, "users.email": flt.Email})...,
+	).Where(
+		userMetadataGlobExpressions(flt.Metadata)...,
+	).Where(
+		userStateExpressions(flt.State)...,
+	).Limit(uint(flt.Limit)).Offset(uint(offset)).ToSQL()
 	if err != nil {
 		return []user.User{}, fmt.Errorf("%w: %s", queryErr, err)
 	}
@@ -279,7 +288,7 @@ func (r UserRepository) List(ctx context.Context, flt user.Filter) ([]user.User,
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedJoinUserMetadata, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedJoinUserMetadata, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return []user.User{}, nil
@@ -298,6 +307,7 @@ func (r UserRepository) List(ctx context.Context, flt user.Filter) ([]user.User,
 		currentUser.Name = u.Name
 		currentUser.CreatedAt = u.CreatedAt
 		currentUser.UpdatedAt = u.UpdatedAt
+		currentUser.DisabledAt = u.DisabledAt.Time
 
 		if currentUser.Metadata == nil {
 			currentUser.Metadata = make(map[string]any)
@@ -341,7 +351,7 @@ func (r UserRepository) GetByIDs(ctx context.Context, userIDs []string) ([]user.
 			defer nr.End()
 		}
 
-		return r.dbc.SelectContext(ctx, &fetchedUsers, query, params...)
+		return r.dbc.Reader().SelectContext(ctx, &fetchedUsers, query, params...)
 	}); err != nil {
 		err = checkPostgresError(err)
 		switch {
@@ -725,6 +735,96 @@ func (r UserRepository) UpdateByID(ctx context.Context, usr user.User) (user.Use
 	return transformedUser, nil
 }
 
+func (r UserRepository) Disable(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return user.ErrInvalidID
+	}
+
+	query, params, err := dialect.Update(TABLE_USERS).Set(
+		goqu.Record{
+			"disabled_at": goqu.L("COALESCE(disabled_at, now())"),
+			"updated_at":  goqu.L("now()"),
+		}).Where(goqu.Ex{
+		"id": id,
+	}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_USERS,
+				Operation:  "Disable",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+
+		result, err := r.dbc.ExecContext(ctx, query, params...)
+		if err != nil {
+			err = checkPostgresError(err)
+			if errors.Is(err, errInvalidTexRepresentation) {
+				return user.ErrInvalidUUID
+			}
+			return fmt.Errorf("%w: %s", dbErr, err)
+		}
+		if count, err := result.RowsAffected(); err != nil {
+			return err
+		} else if count == 0 {
+			return user.ErrNotExist
+		}
+		return nil
+	})
+}
+
+func (r UserRepository) Enable(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return user.ErrInvalidID
+	}
+
+	query, params, err := dialect.Update(TABLE_USERS).Set(
+		goqu.Record{
+			"disabled_at": nil,
+			"updated_at":  goqu.L("now()"),
+		}).Where(goqu.Ex{
+		"id": id,
+	}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_USERS,
+				Operation:  "Enable",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+
+		result, err := r.dbc.ExecContext(ctx, query, params...)
+		if err != nil {
+			err = checkPostgresError(err)
+			if errors.Is(err, errInvalidTexRepresentation) {
+				return user.ErrInvalidUUID
+			}
+			return fmt.Errorf("%w: %s", dbErr, err)
+		}
+		if count, err := result.RowsAffected(); err != nil {
+			return err
+		} else if count == 0 {
+			return user.ErrNotExist
+		}
+		return nil
+	})
+}
+
 func (r UserRepository) GetByEmail(ctx context.Context, email string) (user.User, error) {
 	if strings.TrimSpace(email) == "" {
 		return user.User{}, user.ErrInvalidEmail
@@ -754,7 +854,7 @@ func (r UserRepository) GetByEmail(ctx context.Context, email string) (user.User
 			defer nr.End()
 		}
 
-		return r.dbc.GetContext(ctx, &fetchedUser, query, params...)
+		return r.dbc.Reader().GetContext(ctx, &fetchedUser, query, params...)
 	}); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return user.User{}, user.ErrNotExist
@@ -859,3 +959,38 @@ func (r UserRepository) CreateMetadataKey(ctx context.Context, key user.UserMeta
 
 	return metadataKey.tranformUserMetadataKey(), nil
 }
+
+// userMetadataGlobExpressions matches users having a metadata value for each
+// key matching the glob, users metadata lives in its own table so it can't
+// be matched on the joined rows without dropping the other keys of the user
+func userMetadataGlobExpressions(globs map[string]string) []exp.Expression {
+	keys := make([]string, 0, len(globs))
+	for key := range globs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var expressions []exp.Expression
+	for _, key := range keys {
+		expressions = append(expressions, goqu.I("users.id").In(
+			dialect.From(TABLE_METADATA).Select("user_id").Where(
+				goqu.C("key").Eq(key),
+				// strings are stored json encoded by Create but as is by Update
+				goqu.L(`trim(both '"' from "value") ILIKE ?`, likePattern(globs[key])),
+			),
+		))
+	}
+	return expressions
+}
+
+// userStateExpressions lists the users of the state, every user when it is
+// empty
+func userStateExpressions(state user.State) []exp.Expression {
+	switch state {
+	case user.StateEnabled:
+		return []exp.Expression{goqu.I("users.disabled_at").IsNull()}
+	case user.StateDisabled:
+		return []exp.Expression{goqu.I("users.disabled_at").IsNotNull()}
+	}
+	return nil
+}
@@ -246,6 +246,20 @@ func (s *UserRepositoryTestSuite) TestList() {
 				},
 			},
 		},
+		{
+			Description: "should get users with email matching the glob",
+			Filter: user.Filter{
+				Email: "jane.*@odpf.io",
+			},
+			ExpectedUsers: []user.User{s.users[1]},
+		},
+		{
+			Description: "should get users with metadata matching the glob",
+			Filter: user.Filter{
+				Metadata: map[string]string{"k1": "value-*"},
+			},
+			ExpectedUsers: []user.User{s.users[1]},
+		},
 	}
 
 	for _, tc := range testCases {
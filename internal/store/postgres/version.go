@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/pkg/db"
+)
+
+// errVersionMismatch is returned for an update made with a version other
+// than the one of the row, the row was updated since it was read
+var errVersionMismatch = errors.New("version mismatch")
+
+// versioned increments the version of the row an update sets and, when a
+// version is given, only updates the row while it has that version
+func versioned(record goqu.Record, where goqu.Ex, version int64) (goqu.Record, goqu.Ex) {
+	record["version"] = goqu.L("version + 1")
+	if version == 0 {
+		return record, where
+	}
+	conditioned := goqu.Ex{"version": version}
+	for k, v := range where {
+		conditioned[k] = v
+	}
+	return record, conditioned
+}
+
+// checkVersion tells apart an update made with a version which matched no
+// row: errVersionMismatch is returned when the row exists with another
+// version and sql.ErrNoRows when it doesn't exist at all
+func checkVersion(ctx context.Context, dbc *db.Client, table string, where goqu.Ex, version int64) error {
+	if version == 0 {
+		return sql.ErrNoRows
+	}
+
+	query, params, err := dialect.From(table).Select(goqu.L("1")).Where(where).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var exists int
+	if err := dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: table,
+				Operation:  "CheckVersion",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return dbc.GetContext(ctx, &exists, query, params...)
+	}); err != nil {
+		return err
+	}
+	return errVersionMismatch
+}
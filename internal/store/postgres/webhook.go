@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/odpf/shield/core/webhook"
+)
+
+type Webhook struct {
+	ID        string         `db:"id"`
+	URL       string         `db:"url"`
+	Secret    string         `db:"secret"`
+	Events    pq.StringArray `db:"events"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (from Webhook) transformToWebhook() webhook.Webhook {
+	return webhook.Webhook{
+		ID:        from.ID,
+		URL:       from.URL,
+		Secret:    from.Secret,
+		Events:    from.Events,
+		CreatedAt: from.CreatedAt,
+	}
+}
+
+type WebhookDelivery struct {
+	ID            string       `db:"id"`
+	WebhookID     string       `db:"webhook_id"`
+	EventType     string       `db:"event_type"`
+	Payload       []byte       `db:"payload"`
+	Status        string       `db:"status"`
+	Attempts      int          `db:"attempts"`
+	LastError     string       `db:"last_error"`
+	NextAttemptAt time.Time    `db:"next_attempt_at"`
+	CreatedAt     time.Time    `db:"created_at"`
+	DeliveredAt   sql.NullTime `db:"delivered_at"`
+}
+
+func (from WebhookDelivery) transformToDelivery() webhook.Delivery {
+	return webhook.Delivery{
+		ID:            from.ID,
+		WebhookID:     from.WebhookID,
+		EventType:     from.EventType,
+		Payload:       from.Payload,
+		Status:        from.Status,
+		Attempts:      from.Attempts,
+		LastError:     from.LastError,
+		NextAttemptAt: from.NextAttemptAt,
+		CreatedAt:     from.CreatedAt,
+		DeliveredAt:   from.DeliveredAt.Time,
+	}
+}
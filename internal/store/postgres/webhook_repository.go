@@ -0,0 +1,328 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/lib/pq"
+	newrelic "github.com/newrelic/go-agent"
+
+	"github.com/odpf/shield/core/webhook"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type WebhookRepository struct {
+	dbc *db.Client
+}
+
+func NewWebhookRepository(dbc *db.Client) *WebhookRepository {
+	return &WebhookRepository{
+		dbc: dbc,
+	}
+}
+
+func (r WebhookRepository) Create(ctx context.Context, hook webhook.Webhook) (webhook.Webhook, error) {
+	if strings.TrimSpace(hook.URL) == "" || strings.TrimSpace(hook.Secret) == "" || len(hook.Events) == 0 {
+		return webhook.Webhook{}, webhook.ErrInvalidDetail
+	}
+
+	query, params, err := dialect.Insert(TABLE_WEBHOOKS).Rows(
+		goqu.Record{
+			"url":    hook.URL,
+			"secret": hook.Secret,
+			"events": pq.StringArray(hook.Events),
+		}).Returning(&Webhook{}).ToSQL()
+	if err != nil {
+		return webhook.Webhook{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var hookModel Webhook
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_WEBHOOKS,
+				Operation:  "Create",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&hookModel)
+	}); err != nil {
+		return webhook.Webhook{}, fmt.Errorf("%w: %s", dbErr, checkPostgresError(err))
+	}
+
+	return hookModel.transformToWebhook(), nil
+}
+
+func (r WebhookRepository) Get(ctx context.Context, id string) (webhook.Webhook, error) {
+	query, params, err := dialect.From(TABLE_WEBHOOKS).Where(goqu.Ex{"id": id}).ToSQL()
+	if err != nil {
+		return webhook.Webhook{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var hookModel Webhook
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_WEBHOOKS,
+				Operation:  "Get",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.GetContext(ctx, &hookModel, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return webhook.Webhook{}, webhook.ErrNotExist
+		default:
+			return webhook.Webhook{}, err
+		}
+	}
+
+	return hookModel.transformToWebhook(), nil
+}
+
+func (r WebhookRepository) List(ctx context.Context) ([]webhook.Webhook, error) {
+	query, params, err := dialect.From(TABLE_WEBHOOKS).Order(goqu.C("created_at").Asc()).ToSQL()
+	if err != nil {
+		return []webhook.Webhook{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var hookModels []Webhook
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_WEBHOOKS,
+				Operation:  "List",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &hookModels, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return []webhook.Webhook{}, nil
+		}
+		return []webhook.Webhook{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedHooks []webhook.Webhook
+	for _, h := range hookModels {
+		transformedHooks = append(transformedHooks, h.transformToWebhook())
+	}
+
+	return transformedHooks, nil
+}
+
+func (r WebhookRepository) Delete(ctx context.Context, id string) error {
+	query, params, err := dialect.Delete(TABLE_WEBHOOKS).Where(goqu.Ex{"id": id}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	return r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_WEBHOOKS,
+				Operation:  "Delete",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+
+		result, err := r.dbc.ExecContext(ctx, query, params...)
+		if err != nil {
+			err = checkPostgresError(err)
+			if errors.Is(err, errInvalidTexRepresentation) {
+				return webhook.ErrNotExist
+			}
+			return err
+		}
+		if count, err := result.RowsAffected(); err == nil && count == 0 {
+			return webhook.ErrNotExist
+		}
+		return nil
+	})
+}
+
+func (r WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []webhook.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	rows := make([]interface{}, 0, len(deliveries))
+	for _, dl := range deliveries {
+		rows = append(rows, goqu.Record{
+			"webhook_id":      dl.WebhookID,
+			"event_type":      dl.EventType,
+			"payload":         dl.Payload,
+			"status":          dl.Status,
+			"next_attempt_at": dl.NextAttemptAt,
+		})
+	}
+
+	query, params, err := dialect.Insert(TABLE_WEBHOOK_DELIVERIES).Rows(rows...).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_WEBHOOK_DELIVERIES,
+				Operation:  "CreateDeliveries",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		_, err := r.dbc.ExecContext(ctx, query, params...)
+		return err
+	}); err != nil {
+		err = checkPostgresError(err)
+		if errors.Is(err, errForeignKeyViolation) {
+			return fmt.Errorf("%w: webhook doesn't exist", webhook.ErrInvalidDetail)
+		}
+		return fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return nil
+}
+
+func (r WebhookRepository) ListDeliveries(ctx context.Context, webhookID string) ([]webhook.Delivery, error) {
+	query, params, err := dialect.From(TABLE_WEBHOOK_DELIVERIES).Where(goqu.Ex{
+		"webhook_id": webhookID,
+	}).Order(goqu.C("created_at").Desc()).ToSQL()
+	if err != nil {
+		return []webhook.Delivery{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var deliveryModels []WebhookDelivery
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_WEBHOOK_DELIVERIES,
+				Operation:  "ListDeliveries",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &deliveryModels, query, params...)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return []webhook.Delivery{}, nil
+		default:
+			return []webhook.Delivery{}, fmt.Errorf("%w: %s", dbErr, err)
+		}
+	}
+
+	var transformedDeliveries []webhook.Delivery
+	for _, d := range deliveryModels {
+		transformedDeliveries = append(transformedDeliveries, d.transformToDelivery())
+	}
+
+	return transformedDeliveries, nil
+}
+
+// ClaimDeliveries pushes the next attempt of the pending deliveries due by
+// now past the lease, the rows locked by another dispatcher are skipped so
+// dispatchers of several instances don't claim the same delivery
+func (r WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	due := dialect.From(TABLE_WEBHOOK_DELIVERIES).Select("id").Where(
+		goqu.Ex{"status": webhook.DeliveryPending},
+		goqu.C("next_attempt_at").Lte(goqu.L("now()")),
+	).Order(goqu.C("next_attempt_at").Asc()).Limit(uint(limit)).ForUpdate(exp.SkipLocked)
+
+	query, params, err := dialect.Update(TABLE_WEBHOOK_DELIVERIES).Set(
+		goqu.Record{
+			"next_attempt_at": goqu.L("now() + ?::interval", fmt.Sprintf("%d milliseconds", lease.Milliseconds())),
+		}).Where(goqu.C("id").In(due)).Returning(&WebhookDelivery{}).ToSQL()
+	if err != nil {
+		return []webhook.Delivery{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var deliveryModels []WebhookDelivery
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_WEBHOOK_DELIVERIES,
+				Operation:  "ClaimDeliveries",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.SelectContext(ctx, &deliveryModels, query, params...)
+	}); err != nil {
+		return []webhook.Delivery{}, fmt.Errorf("%w: %s", dbErr, checkPostgresError(err))
+	}
+
+	var transformedDeliveries []webhook.Delivery
+	for _, d := range deliveryModels {
+		transformedDeliveries = append(transformedDeliveries, d.transformToDelivery())
+	}
+
+	return transformedDeliveries, nil
+}
+
+func (r WebhookRepository) UpdateDelivery(ctx context.Context, dl webhook.Delivery) (webhook.Delivery, error) {
+	deliveredAt := sql.NullTime{Time: dl.DeliveredAt, Valid: !dl.DeliveredAt.IsZero()}
+	query, params, err := dialect.Update(TABLE_WEBHOOK_DELIVERIES).Set(
+		goqu.Record{
+			"status":          dl.Status,
+			"attempts":        dl.Attempts,
+			"last_error":      dl.LastError,
+			"next_attempt_at": dl.NextAttemptAt,
+			"delivered_at":    deliveredAt,
+		}).Where(goqu.Ex{"id": dl.ID}).Returning(&WebhookDelivery{}).ToSQL()
+	if err != nil {
+		return webhook.Delivery{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+
+	var deliveryModel WebhookDelivery
+	if err = r.dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		nrCtx := newrelic.FromContext(ctx)
+		if nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastorePostgres,
+				Collection: TABLE_WEBHOOK_DELIVERIES,
+				Operation:  "UpdateDelivery",
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return r.dbc.QueryRowxContext(ctx, query, params...).StructScan(&deliveryModel)
+	}); err != nil {
+		err = checkPostgresError(err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, errInvalidTexRepresentation):
+			return webhook.Delivery{}, webhook.ErrNotExist
+		default:
+			return webhook.Delivery{}, err
+		}
+	}
+
+	return deliveryModel.transformToDelivery(), nil
+}
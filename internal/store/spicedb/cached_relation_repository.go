@@ -0,0 +1,169 @@
+package spicedb
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"time"
+
+	authzedpb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/metrics"
+)
+
+// watchRetryDelay is how long to wait before watching spicedb again
+// after the watch stream fails
+const watchRetryDelay = 5 * time.Second
+
+// checkCacheStats counts the hits and misses of the check cache per
+// object namespace, it is exported with the other expvars
+var checkCacheStats = expvar.NewMap("spicedb_check_cache")
+
+// CachedRelationRepository caches the results of permission checks, the
+// whole cache is cleared on any relation write as a single relation can
+// change the permissions derived from it on many objects
+type CachedRelationRepository struct {
+	*RelationRepository
+	cache      CheckCache
+	ttl        time.Duration
+	namespaces map[string]time.Duration
+}
+
+func NewCachedRelationRepository(repository *RelationRepository, cache CheckCache, config CheckCacheConfig) *CachedRelationRepository {
+	return &CachedRelationRepository{
+		RelationRepository: repository,
+		cache:              cache,
+		ttl:                config.TTL,
+		namespaces:         config.Namespaces,
+	}
+}
+
+func (r CachedRelationRepository) namespaceTTL(ns string) time.Duration {
+	if ttl, ok := r.namespaces[ns]; ok {
+		return ttl
+	}
+	return r.ttl
+}
+
+func checkCacheKey(rel relation.Relation, act action.Action) string {
+	key := fmt.Sprintf("%s:%s#%s@%s:%s", rel.ObjectNamespace.ID, rel.ObjectID, act.ID, rel.SubjectNamespace.ID, rel.SubjectID)
+	if rel.SubjectRoleID != "" {
+		key += "#" + rel.SubjectRoleID
+	}
+	return key
+}
+
+func (r CachedRelationRepository) Check(ctx context.Context, rel relation.Relation, act action.Action) (bool, error) {
+	ns := rel.ObjectNamespace.ID
+	ttl := r.namespaceTTL(ns)
+	// a check asking for fresher relations than minimized latency
+	// can't be answered from the cache, nor can one with a context the
+	// conditions of the policies are evaluated against
+	if ttl <= 0 || !relation.ConsistencyFromContext(ctx).IsZero() || !relation.CheckContextFromContext(ctx).IsZero() {
+		return r.RelationRepository.Check(ctx, rel, act)
+	}
+
+	// the cache is best effort, spicedb is asked when it fails
+	key := checkCacheKey(rel, act)
+	if allowed, found, err := r.cache.Get(ctx, key); err == nil && found {
+		checkCacheStats.Add(ns+".hits", 1)
+		metrics.CheckCacheHit(ns)
+		return allowed, nil
+	}
+	checkCacheStats.Add(ns+".misses", 1)
+	metrics.CheckCacheMiss(ns)
+
+	allowed, err := r.RelationRepository.Check(ctx, rel, act)
+	if err != nil {
+		return false, err
+	}
+	_ = r.cache.Set(ctx, key, allowed, ttl)
+	return allowed, nil
+}
+
+func (r CachedRelationRepository) Add(ctx context.Context, rel relation.Relation) error {
+	if err := r.RelationRepository.Add(ctx, rel); err != nil {
+		return err
+	}
+	return r.cache.Clear(ctx)
+}
+
+func (r CachedRelationRepository) AddV2(ctx context.Context, rel relation.RelationV2) error {
+	if err := r.RelationRepository.AddV2(ctx, rel); err != nil {
+		return err
+	}
+	return r.cache.Clear(ctx)
+}
+
+func (r CachedRelationRepository) Delete(ctx context.Context, rel relation.Relation) error {
+	if err := r.RelationRepository.Delete(ctx, rel); err != nil {
+		return err
+	}
+	return r.cache.Clear(ctx)
+}
+
+func (r CachedRelationRepository) DeleteV2(ctx context.Context, rel relation.RelationV2) error {
+	if err := r.RelationRepository.DeleteV2(ctx, rel); err != nil {
+		return err
+	}
+	return r.cache.Clear(ctx)
+}
+
+func (r CachedRelationRepository) DeleteSubjectRelations(ctx context.Context, resourceType, optionalResourceID string) error {
+	if err := r.RelationRepository.DeleteSubjectRelations(ctx, resourceType, optionalResourceID); err != nil {
+		return err
+	}
+	return r.cache.Clear(ctx)
+}
+
+// Watch clears the cache whenever relations are written to spicedb by
+// anyone else, such as another shield instance, until ctx is done
+func (r CachedRelationRepository) Watch(ctx context.Context, logger log.Logger) {
+	for {
+		if err := r.watch(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("watching spicedb relation changes failed", "err", err)
+		}
+		// changes may have been missed while the stream was down
+		_ = r.cache.Clear(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+func (r CachedRelationRepository) watch(ctx context.Context) error {
+	stream, err := r.spiceDB.client.Watch(ctx, &authzedpb.WatchRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if len(resp.GetUpdates()) > 0 {
+			if err := r.cache.Clear(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (r CachedRelationRepository) Import(ctx context.Context, tuples []relation.Tuple) error {
+	if err := r.RelationRepository.Import(ctx, tuples); err != nil {
+		return err
+	}
+	return r.cache.Clear(ctx)
+}
+
+func (r CachedRelationRepository) DeleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	if err := r.RelationRepository.DeleteTuples(ctx, tuples); err != nil {
+		return err
+	}
+	return r.cache.Clear(ctx)
+}
@@ -0,0 +1,95 @@
+package spicedb
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// CheckCache stores the results of permission checks, in process with
+// LRUCheckCache or shared between the instances with RedisCheckCache
+type CheckCache interface {
+	Get(ctx context.Context, key string) (allowed bool, found bool, err error)
+	Set(ctx context.Context, key string, allowed bool, ttl time.Duration) error
+	Clear(ctx context.Context) error
+}
+
+type lruEntry struct {
+	key       string
+	allowed   bool
+	expiresAt time.Time
+}
+
+// LRUCheckCache is an in process CheckCache holding a fixed number of
+// results, the least recently used result is evicted when it is full
+type LRUCheckCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+func NewLRUCheckCache(size int) *LRUCheckCache {
+	return &LRUCheckCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func (c *LRUCheckCache) Get(ctx context.Context, key string) (bool, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return false, false, nil
+	}
+	entry := el.Value.(*lruEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return false, false, nil
+	}
+	c.order.MoveToFront(el)
+	return entry.allowed, true, nil
+}
+
+func (c *LRUCheckCache) Set(ctx context.Context, key string, allowed bool, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.allowed = allowed
+		entry.expiresAt = c.now().Add(ttl)
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	if c.size > 0 && c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, allowed: allowed, expiresAt: c.now().Add(ttl)})
+	return nil
+}
+
+func (c *LRUCheckCache) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	return nil
+}
+
+func (c *LRUCheckCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,57 @@
+package spicedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCheckCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should return the results which are set and not expired", func(t *testing.T) {
+		now := time.Now()
+		c := NewLRUCheckCache(10)
+		c.now = func() time.Time { return now }
+
+		assert.NoError(t, c.Set(ctx, "a", true, time.Minute))
+		assert.NoError(t, c.Set(ctx, "b", false, time.Second))
+
+		allowed, found, err := c.Get(ctx, "a")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.True(t, allowed)
+
+		now = now.Add(2 * time.Second)
+		_, found, _ = c.Get(ctx, "b")
+		assert.False(t, found)
+		_, found, _ = c.Get(ctx, "c")
+		assert.False(t, found)
+	})
+
+	t.Run("should evict the least recently used result when full", func(t *testing.T) {
+		c := NewLRUCheckCache(2)
+		assert.NoError(t, c.Set(ctx, "a", true, time.Minute))
+		assert.NoError(t, c.Set(ctx, "b", true, time.Minute))
+		_, _, _ = c.Get(ctx, "a")
+		assert.NoError(t, c.Set(ctx, "c", true, time.Minute))
+
+		_, found, _ := c.Get(ctx, "b")
+		assert.False(t, found)
+		_, found, _ = c.Get(ctx, "a")
+		assert.True(t, found)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("should remove every result when cleared", func(t *testing.T) {
+		c := NewLRUCheckCache(2)
+		assert.NoError(t, c.Set(ctx, "a", true, time.Minute))
+		assert.NoError(t, c.Clear(ctx))
+
+		_, found, _ := c.Get(ctx, "a")
+		assert.False(t, found)
+		assert.Equal(t, 0, c.Len())
+	})
+}
@@ -1,7 +1,41 @@
 package spicedb
 
+import "time"
+
 type Config struct {
-	Host         string `yaml:"host"`
-	Port         string `yaml:"port" default:"50051"`
-	PreSharedKey string `yaml:"pre_shared_key" mapstructure:"pre_shared_key"`
+	Host         string           `yaml:"host"`
+	Port         string           `yaml:"port" default:"50051"`
+	PreSharedKey string           `yaml:"pre_shared_key" mapstructure:"pre_shared_key"`
+	CheckCache   CheckCacheConfig `yaml:"check_cache" mapstructure:"check_cache"`
+}
+
+type CheckCacheConfig struct {
+	// Enabled caches the results of permission checks, in process unless
+	// the address of redis is set
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Size is the number of results kept in process, the least recently
+	// used are evicted first
+	Size int `yaml:"size" mapstructure:"size" default:"10000"`
+	// TTL is how long a cached result is used for
+	TTL time.Duration `yaml:"ttl" mapstructure:"ttl" default:"10s"`
+	// Namespaces overrides the TTL of the checks on objects of a namespace,
+	// a TTL of 0 doesn't cache them
+	Namespaces map[string]time.Duration `yaml:"namespaces" mapstructure:"namespaces"`
+	// Redis shares the results between the instances, they are kept in the
+	// memory of every instance when no address is set
+	Redis CheckCacheRedisConfig `yaml:"redis" mapstructure:"redis"`
+}
+
+type CheckCacheRedisConfig struct {
+	// Addr is the host:port of redis
+	Addr     string `yaml:"addr" mapstructure:"addr"`
+	Password string `yaml:"password" mapstructure:"password"`
+	DB       int    `yaml:"db" mapstructure:"db"`
+	// KeyPrefix starts the keys of the results
+	KeyPrefix string `yaml:"key_prefix" mapstructure:"key_prefix" default:"shield:check:"`
+	// Timeout of a request to redis, spicedb is asked when redis doesn't
+	// answer in time
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" default:"50ms"`
+	// PoolSize is the number of connections kept open to redis
+	PoolSize int `yaml:"pool_size" mapstructure:"pool_size" default:"10"`
 }
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
+	"github.com/odpf/shield/core/policy"
 	"github.com/odpf/shield/internal/schema"
 	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
 
 	authzedpb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type PolicyRepository struct {
@@ -18,6 +22,7 @@ type PolicyRepository struct {
 
 var (
 	ErrWritingSchema = errors.New("error in writing schema to spicedb")
+	ErrReadingSchema = errors.New("error in reading schema from spicedb")
 )
 
 func NewPolicyRepository(spiceDB *SpiceDB) *PolicyRepository {
@@ -26,13 +31,159 @@ func NewPolicyRepository(spiceDB *SpiceDB) *PolicyRepository {
 	}
 }
 
+// WriteSchema writes the schema generated from the namespace configs, the
+// condition relations of the previous schema are dropped with it until the
+// grants of the policies are restored
 func (r PolicyRepository) WriteSchema(ctx context.Context, schema schema.NamespaceConfigMapType) error {
 	generatedSchema := schema_generator.GenerateSchema(schema)
-	request := &authzedpb.WriteSchemaRequest{Schema: strings.Join(generatedSchema, "\n")}
 	fmt.Println(strings.Join(generatedSchema, "\n"))
-	if _, err := r.spiceDB.client.WriteSchema(ctx, request); err != nil {
+
+	response, err := r.spiceDB.client.ReadSchema(ctx, &authzedpb.ReadSchemaRequest{})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return fmt.Errorf("%w: %s", ErrReadingSchema, err.Error())
+	}
+	return r.replaceSchema(ctx, response.GetSchemaText(), strings.Join(generatedSchema, "\n"))
+}
+
+// ReadSchema returns the schema the authz engine has, the custom roles and
+// the grants of the policies included
+func (r PolicyRepository) ReadSchema(ctx context.Context) (string, error) {
+	response, err := r.spiceDB.client.ReadSchema(ctx, &authzedpb.ReadSchemaRequest{})
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrReadingSchema, err.Error())
+	}
+	return response.GetSchemaText(), nil
+}
+
+func (r PolicyRepository) Add(ctx context.Context, policies []policy.Policy) error {
+	return r.applyPolicies(ctx, nil, policies)
+}
+
+func (r PolicyRepository) Remove(ctx context.Context, policies []policy.Policy) error {
+	return r.applyPolicies(ctx, policies, nil)
+}
+
+// ValidateCondition returns an error if the condition doesn't compile to a
+// caveat
+func (r PolicyRepository) ValidateCondition(condition string) error {
+	_, err := schema_generator.CompileCondition("condition_validation", condition)
+	return err
+}
+
+// AddObject writes the relationships of the object to every user under the
+// conditions of the policies, the grants of the policies only apply to the
+// objects having them
+func (r PolicyRepository) AddObject(ctx context.Context, policies []policy.Policy, objectID string) error {
+	updates := make([]*authzedpb.RelationshipUpdate, 0, len(policies))
+	for _, pol := range policies {
+		updates = append(updates, &authzedpb.RelationshipUpdate{
+			Operation:    authzedpb.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: schema_generator.ConditionRelationship(pol.NamespaceID, objectID, schema_generator.ConditionName(pol)),
+		})
+	}
+	_, err := r.spiceDB.client.WriteRelationships(ctx, &authzedpb.WriteRelationshipsRequest{Updates: updates})
+	return err
+}
+
+func (r PolicyRepository) applyPolicies(ctx context.Context, toRemove, toAdd []policy.Policy) error {
+	response, err := r.spiceDB.client.ReadSchema(ctx, &authzedpb.ReadSchemaRequest{})
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrReadingSchema, err.Error())
+	}
+
+	updatedSchema, err := schema_generator.ApplyPolicies(response.GetSchemaText(), toRemove, toAdd)
+	if err != nil {
+		return err
+	}
+	return r.replaceSchema(ctx, response.GetSchemaText(), updatedSchema)
+}
+
+// replaceSchema writes the new schema over the previous one, the
+// relationships of the condition relations it drops are deleted first as
+// spicedb doesn't drop a relation still having some. The objects existing
+// in the namespaces of the condition relations it adds are given theirs.
+func (r PolicyRepository) replaceSchema(ctx context.Context, previousSchema, newSchema string) error {
+	var before []schema_generator.ConditionRelation
+	if previousSchema != "" {
+		var err error
+		if before, err = schema_generator.ConditionRelations(previousSchema); err != nil {
+			return err
+		}
+	}
+	after, err := schema_generator.ConditionRelations(newSchema)
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range schema_generator.DiffConditionRelations(before, after) {
+		if _, err := r.spiceDB.client.DeleteRelationships(ctx, &authzedpb.DeleteRelationshipsRequest{
+			RelationshipFilter: &authzedpb.RelationshipFilter{
+				ResourceType:     rel.NamespaceID,
+				OptionalRelation: rel.Name,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.spiceDB.client.WriteSchema(ctx, &authzedpb.WriteSchemaRequest{Schema: newSchema}); err != nil {
 		return fmt.Errorf("%w: %s", ErrWritingSchema, err.Error())
 	}
 
+	for _, rel := range schema_generator.DiffConditionRelations(after, before) {
+		if err := r.addConditionRelation(ctx, rel); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// addConditionRelation writes the relationships of the condition relation
+// for every object of its namespace in batches of MaxImportBatch
+func (r PolicyRepository) addConditionRelation(ctx context.Context, rel schema_generator.ConditionRelation) error {
+	stream, err := r.spiceDB.client.ReadRelationships(ctx, &authzedpb.ReadRelationshipsRequest{
+		Consistency: &authzedpb.Consistency{
+			Requirement: &authzedpb.Consistency_FullyConsistent{FullyConsistent: true},
+		},
+		RelationshipFilter: &authzedpb.RelationshipFilter{ResourceType: rel.NamespaceID},
+	})
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	var updates []*authzedpb.RelationshipUpdate
+	write := func() error {
+		if len(updates) == 0 {
+			return nil
+		}
+		_, err := r.spiceDB.client.WriteRelationships(ctx, &authzedpb.WriteRelationshipsRequest{Updates: updates})
+		updates = updates[:0]
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return write()
+		}
+		if err != nil {
+			return err
+		}
+
+		objectID := resp.GetRelationship().GetResource().GetObjectId()
+		if seen[objectID] {
+			continue
+		}
+		seen[objectID] = true
+		updates = append(updates, &authzedpb.RelationshipUpdate{
+			Operation:    authzedpb.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: schema_generator.ConditionRelationship(rel.NamespaceID, objectID, rel.Name),
+		})
+		if len(updates) == MaxImportBatch {
+			if err := write(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package spicedb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// the results are kept under the generation of the cache, clearing the
+// cache moves it to the next generation and the results of the previous
+// ones are left to expire
+var (
+	redisCheckGetScript = redis.NewScript(`
+local generation = redis.call('GET', KEYS[1]) or '0'
+return redis.call('GET', ARGV[1] .. generation .. ':' .. ARGV[2])
+`)
+	redisCheckSetScript = redis.NewScript(`
+local generation = redis.call('GET', KEYS[1]) or '0'
+return redis.call('SET', ARGV[1] .. generation .. ':' .. ARGV[2], ARGV[3], 'PX', ARGV[4])
+`)
+)
+
+// RedisCheckCache is a CheckCache shared by the instances using the same
+// redis, a write made through any of them clears the results of all
+type RedisCheckCache struct {
+	cfg    CheckCacheRedisConfig
+	client *redis.Client
+}
+
+func NewRedisCheckCache(cfg CheckCacheRedisConfig) *RedisCheckCache {
+	return &RedisCheckCache{
+		cfg: cfg,
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+			PoolSize: cfg.PoolSize,
+			// the timeout of a request is the deadline of its context
+			ContextTimeoutEnabled: true,
+		}),
+	}
+}
+
+func (c *RedisCheckCache) generationKey() string {
+	return c.cfg.KeyPrefix + "generation"
+}
+
+func (c *RedisCheckCache) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.cfg.Timeout > 0 {
+		return context.WithTimeout(ctx, c.cfg.Timeout)
+	}
+	return ctx, func() {}
+}
+
+func (c *RedisCheckCache) Get(ctx context.Context, key string) (bool, bool, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	value, err := redisCheckGetScript.Run(ctx, c.client, []string{c.generationKey()}, c.cfg.KeyPrefix, key).Text()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	return value == "1", true, nil
+}
+
+func (c *RedisCheckCache) Set(ctx context.Context, key string, allowed bool, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	value := "0"
+	if allowed {
+		value = "1"
+	}
+	return redisCheckSetScript.Run(ctx, c.client, []string{c.generationKey()}, c.cfg.KeyPrefix, key, value, ttl.Milliseconds()).Err()
+}
+
+func (c *RedisCheckCache) Clear(ctx context.Context) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	return c.client.Incr(ctx, c.generationKey()).Err()
+}
+
+func (c *RedisCheckCache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,55 @@
+package spicedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisCheckCache(t *testing.T) {
+	ctx := context.Background()
+	m := miniredis.RunT(t)
+	c := NewRedisCheckCache(CheckCacheRedisConfig{Addr: m.Addr(), KeyPrefix: "shield:check:", Timeout: time.Second, PoolSize: 1})
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "a", true, time.Minute))
+	require.NoError(t, c.Set(ctx, "b", false, time.Second))
+
+	allowed, found, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.True(t, allowed)
+	allowed, found, err = c.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.False(t, allowed)
+
+	t.Run("should not return the expired results", func(t *testing.T) {
+		m.FastForward(2 * time.Second)
+		_, found, err := c.Get(ctx, "b")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should clear the results set by every instance", func(t *testing.T) {
+		other := NewRedisCheckCache(CheckCacheRedisConfig{Addr: m.Addr(), KeyPrefix: "shield:check:", Timeout: time.Second, PoolSize: 1})
+		defer other.Close()
+		_, found, _ := other.Get(ctx, "a")
+		require.True(t, found)
+
+		require.NoError(t, other.Clear(ctx))
+		_, found, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		require.NoError(t, c.Set(ctx, "a", false, time.Minute))
+		allowed, found, err := other.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.False(t, allowed)
+	})
+}
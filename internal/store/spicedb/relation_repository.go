@@ -2,15 +2,21 @@ package spicedb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/odpf/shield/core/action"
 	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/metrics"
 	"github.com/odpf/shield/internal/schema"
 	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
 
 	authzedpb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	newrelic "github.com/newrelic/go-agent"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type RelationRepository struct {
@@ -39,9 +45,11 @@ func (r RelationRepository) Add(ctx context.Context, rel relation.Relation) erro
 		},
 	}
 
-	if _, err = r.spiceDB.client.WriteRelationships(ctx, request); err != nil {
+	response, err := r.spiceDB.client.WriteRelationships(ctx, request)
+	if err != nil {
 		return err
 	}
+	relation.RecordRevision(ctx, response.GetWrittenAt().GetToken())
 
 	return nil
 }
@@ -93,9 +101,11 @@ func (r RelationRepository) AddV2(ctx context.Context, rel relation.RelationV2)
 		defer nr.End()
 	}
 
-	if _, err := r.spiceDB.client.WriteRelationships(ctx, request); err != nil {
+	response, err := r.spiceDB.client.WriteRelationships(ctx, request)
+	if err != nil {
 		return err
 	}
+	relation.RecordRevision(ctx, response.GetWrittenAt().GetToken())
 
 	return nil
 }
@@ -106,10 +116,17 @@ func (r RelationRepository) Check(ctx context.Context, rel relation.Relation, ac
 		return false, err
 	}
 
+	checkCtx, err := checkContext(relation.CheckContextFromContext(ctx))
+	if err != nil {
+		return false, err
+	}
+
 	request := &authzedpb.CheckPermissionRequest{
-		Resource:   relationship.Resource,
-		Subject:    relationship.Subject,
-		Permission: act.ID,
+		Consistency: consistency(relation.ConsistencyFromContext(ctx)),
+		Resource:    relationship.Resource,
+		Subject:     relationship.Subject,
+		Permission:  act.ID,
+		Context:     checkCtx,
 	}
 
 	nrCtx := newrelic.FromContext(ctx)
@@ -123,12 +140,157 @@ func (r RelationRepository) Check(ctx context.Context, rel relation.Relation, ac
 		defer nr.End()
 	}
 
+	start := time.Now()
 	response, err := r.spiceDB.client.CheckPermission(ctx, request)
+	allowed := response.GetPermissionship() == authzedpb.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+	metrics.ObserveSpiceDBCheck(request.Resource.ObjectType, start, allowed, err)
 	if err != nil {
 		return false, err
 	}
 
-	return response.Permissionship == authzedpb.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil
+	return allowed, nil
+}
+
+// Expand expands the permission or relation of the object into the tree
+// of the relations granting it, subject sets are left unexpanded
+func (r RelationRepository) Expand(ctx context.Context, obj relation.Object, permission string) (relation.AccessTree, error) {
+	request := &authzedpb.ExpandPermissionTreeRequest{
+		Consistency: consistency(relation.ConsistencyFromContext(ctx)),
+		Resource: &authzedpb.ObjectReference{
+			ObjectType: strings.ReplaceAll(obj.NamespaceID, "-", "_"),
+			ObjectId:   obj.ID,
+		},
+		Permission: permission,
+	}
+
+	nrCtx := newrelic.FromContext(ctx)
+	if nrCtx != nil {
+		nr := newrelic.DatastoreSegment{
+			Product:    nrProductName,
+			Collection: fmt.Sprintf("object:%s", request.Resource.ObjectType),
+			Operation:  "Expand",
+			StartTime:  nrCtx.StartSegmentNow(),
+		}
+		defer nr.End()
+	}
+
+	response, err := r.spiceDB.client.ExpandPermissionTree(ctx, request)
+	if err != nil {
+		return relation.AccessTree{}, err
+	}
+	return accessTree(response.GetTreeRoot()), nil
+}
+
+// LookupResources returns the ids of the objects of the namespace the
+// subject has the permission on, spicedb streams all of them. The objects
+// the subject only has it on under a condition the context doesn't meet
+// are left out.
+func (r RelationRepository) LookupResources(ctx context.Context, namespaceID, permission string, sub relation.Subject) ([]string, error) {
+	checkCtx, err := checkContext(relation.CheckContextFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	request := &authzedpb.LookupResourcesRequest{
+		Context:            checkCtx,
+		Consistency:        consistency(relation.ConsistencyFromContext(ctx)),
+		ResourceObjectType: strings.ReplaceAll(namespaceID, "-", "_"),
+		Permission:         permission,
+		Subject: &authzedpb.SubjectReference{
+			Object: &authzedpb.ObjectReference{
+				ObjectType: strings.ReplaceAll(sub.Namespace, "-", "_"),
+				ObjectId:   sub.ID,
+			},
+			OptionalRelation: sub.RoleID,
+		},
+	}
+
+	nrCtx := newrelic.FromContext(ctx)
+	if nrCtx != nil {
+		nr := newrelic.DatastoreSegment{
+			Product:    nrProductName,
+			Collection: fmt.Sprintf("object:%s::subject:%s", request.ResourceObjectType, request.Subject.Object.ObjectType),
+			Operation:  "LookupResources",
+			StartTime:  nrCtx.StartSegmentNow(),
+		}
+		defer nr.End()
+	}
+
+	stream, err := r.spiceDB.client.LookupResources(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.GetPermissionship() == authzedpb.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION {
+			continue
+		}
+		ids = append(ids, resp.GetResourceObjectId())
+	}
+}
+
+func accessTree(node *authzedpb.PermissionRelationshipTree) relation.AccessTree {
+	tree := relation.AccessTree{
+		Object: relation.Object{
+			ID:          node.GetExpandedObject().GetObjectId(),
+			NamespaceID: node.GetExpandedObject().GetObjectType(),
+		},
+		Relation: node.GetExpandedRelation(),
+	}
+
+	if leaf := node.GetLeaf(); leaf != nil {
+		for _, sub := range leaf.GetSubjects() {
+			tree.Subjects = append(tree.Subjects, relation.Subject{
+				ID:        sub.GetObject().GetObjectId(),
+				Namespace: sub.GetObject().GetObjectType(),
+				RoleID:    sub.GetOptionalRelation(),
+			})
+		}
+		return tree
+	}
+
+	switch node.GetIntermediate().GetOperation() {
+	case authzedpb.AlgebraicSubjectSet_OPERATION_INTERSECTION:
+		tree.Operation = relation.OperationIntersection
+	case authzedpb.AlgebraicSubjectSet_OPERATION_EXCLUSION:
+		tree.Operation = relation.OperationExclusion
+	default:
+		tree.Operation = relation.OperationUnion
+	}
+	for _, child := range node.GetIntermediate().GetChildren() {
+		tree.Children = append(tree.Children, accessTree(child))
+	}
+	return tree
+}
+
+// consistency is the requirement of a check, spicedb minimizes the
+// latency when no requirement is given
+func consistency(c relation.Consistency) *authzedpb.Consistency {
+	switch {
+	case c.Full:
+		return &authzedpb.Consistency{
+			Requirement: &authzedpb.Consistency_FullyConsistent{FullyConsistent: true},
+		}
+	case c.AtLeastAsFresh != "":
+		return &authzedpb.Consistency{
+			Requirement: &authzedpb.Consistency_AtLeastAsFresh{AtLeastAsFresh: &authzedpb.ZedToken{Token: c.AtLeastAsFresh}},
+		}
+	default:
+		return nil
+	}
+}
+
+// checkContext is the context the caveats of the conditions are evaluated
+// with
+func checkContext(c relation.CheckContext) (*structpb.Struct, error) {
+	return structpb.NewStruct(schema_generator.ConditionContext(c))
 }
 
 func (r RelationRepository) Delete(ctx context.Context, rel relation.Relation) error {
@@ -148,9 +310,11 @@ func (r RelationRepository) Delete(ctx context.Context, rel relation.Relation) e
 		},
 	}
 
-	if _, err = r.spiceDB.client.DeleteRelationships(ctx, request); err != nil {
+	response, err := r.spiceDB.client.DeleteRelationships(ctx, request)
+	if err != nil {
 		return err
 	}
+	relation.RecordRevision(ctx, response.GetDeletedAt().GetToken())
 
 	return nil
 }
@@ -186,10 +350,11 @@ func (r RelationRepository) DeleteV2(ctx context.Context, rel relation.RelationV
 		}
 		defer nr.End()
 	}
-	_, err = r.spiceDB.client.DeleteRelationships(ctx, request)
+	response, err := r.spiceDB.client.DeleteRelationships(ctx, request)
 	if err != nil {
 		return err
 	}
+	relation.RecordRevision(ctx, response.GetDeletedAt().GetToken())
 
 	return nil
 }
@@ -216,9 +381,11 @@ func (r RelationRepository) DeleteSubjectRelations(ctx context.Context, resource
 		defer nr.End()
 	}
 
-	if _, err := r.spiceDB.client.DeleteRelationships(ctx, request); err != nil {
+	response, err := r.spiceDB.client.DeleteRelationships(ctx, request)
+	if err != nil {
 		return err
 	}
+	relation.RecordRevision(ctx, response.GetDeletedAt().GetToken())
 
 	return nil
 }
@@ -0,0 +1,140 @@
+package spicedb
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	authzedpb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
+)
+
+// MaxImportBatch is the most relationships written to spicedb in a single
+// request, spicedb rejects larger writes by default
+const MaxImportBatch = 1000
+
+func tupleFromRelationship(rel *authzedpb.Relationship) relation.Tuple {
+	return relation.Tuple{
+		ResourceType:    rel.GetResource().GetObjectType(),
+		ResourceID:      rel.GetResource().GetObjectId(),
+		Relation:        rel.GetRelation(),
+		SubjectType:     rel.GetSubject().GetObject().GetObjectType(),
+		SubjectID:       rel.GetSubject().GetObject().GetObjectId(),
+		SubjectRelation: rel.GetSubject().GetOptionalRelation(),
+	}
+}
+
+// relationshipFromTuple is the relationship of the tuple, the relationships
+// of condition relations are under the caveat of the condition
+func relationshipFromTuple(t relation.Tuple) *authzedpb.Relationship {
+	return &authzedpb.Relationship{
+		Resource: &authzedpb.ObjectReference{
+			ObjectType: t.ResourceType,
+			ObjectId:   t.ResourceID,
+		},
+		Relation: t.Relation,
+		Subject: &authzedpb.SubjectReference{
+			Object: &authzedpb.ObjectReference{
+				ObjectType: t.SubjectType,
+				ObjectId:   t.SubjectID,
+			},
+			OptionalRelation: t.SubjectRelation,
+		},
+		OptionalCaveat: schema_generator.ConditionCaveat(t.Relation),
+	}
+}
+
+// Export streams every relationship on the objects of the namespace to fn,
+// the relationships are read at a single consistent revision
+func (r RelationRepository) Export(ctx context.Context, resourceType string, fn func(relation.Tuple) error) error {
+	request := &authzedpb.ReadRelationshipsRequest{
+		Consistency: &authzedpb.Consistency{
+			Requirement: &authzedpb.Consistency_FullyConsistent{FullyConsistent: true},
+		},
+		RelationshipFilter: &authzedpb.RelationshipFilter{
+			ResourceType: resourceType,
+		},
+	}
+
+	nrCtx := newrelic.FromContext(ctx)
+	if nrCtx != nil {
+		nr := newrelic.DatastoreSegment{
+			Product: nrProductName,
+			QueryParameters: map[string]interface{}{
+				"object_namespace": resourceType,
+			},
+			Operation: "Export_Relations",
+			StartTime: nrCtx.StartSegmentNow(),
+		}
+		defer nr.End()
+	}
+
+	stream, err := r.spiceDB.client.ReadRelationships(ctx, request)
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(tupleFromRelationship(resp.GetRelationship())); err != nil {
+			return err
+		}
+	}
+}
+
+// Import writes the relationships in batches of MaxImportBatch, existing
+// relationships are left as they are so an import can be retried
+func (r RelationRepository) Import(ctx context.Context, tuples []relation.Tuple) error {
+	for start := 0; start < len(tuples); start += MaxImportBatch {
+		end := start + MaxImportBatch
+		if end > len(tuples) {
+			end = len(tuples)
+		}
+
+		updates := make([]*authzedpb.RelationshipUpdate, 0, end-start)
+		for _, t := range tuples[start:end] {
+			updates = append(updates, &authzedpb.RelationshipUpdate{
+				Operation:    authzedpb.RelationshipUpdate_OPERATION_TOUCH,
+				Relationship: relationshipFromTuple(t),
+			})
+		}
+
+		response, err := r.spiceDB.client.WriteRelationships(ctx, &authzedpb.WriteRelationshipsRequest{Updates: updates})
+		if err != nil {
+			return err
+		}
+		relation.RecordRevision(ctx, response.GetWrittenAt().GetToken())
+	}
+	return nil
+}
+
+// DeleteTuples removes the relationships in batches of MaxImportBatch,
+// relationships which don't exist are skipped
+func (r RelationRepository) DeleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	for start := 0; start < len(tuples); start += MaxImportBatch {
+		end := start + MaxImportBatch
+		if end > len(tuples) {
+			end = len(tuples)
+		}
+
+		updates := make([]*authzedpb.RelationshipUpdate, 0, end-start)
+		for _, t := range tuples[start:end] {
+			updates = append(updates, &authzedpb.RelationshipUpdate{
+				Operation:    authzedpb.RelationshipUpdate_OPERATION_DELETE,
+				Relationship: relationshipFromTuple(t),
+			})
+		}
+
+		if _, err := r.spiceDB.client.WriteRelationships(ctx, &authzedpb.WriteRelationshipsRequest{Updates: updates}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package spicedb
+
+import (
+	"testing"
+
+	"github.com/odpf/shield/core/relation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTupleRelationship(t *testing.T) {
+	t.Run("should convert a tuple to a relationship and back", func(t *testing.T) {
+		tuple := relation.Tuple{
+			ResourceType:    "shield/project",
+			ResourceID:      "p1",
+			Relation:        "owner",
+			SubjectType:     "shield/group",
+			SubjectID:       "g1",
+			SubjectRelation: "membership",
+		}
+
+		rel := relationshipFromTuple(tuple)
+		assert.Equal(t, "shield/project", rel.GetResource().GetObjectType())
+		assert.Equal(t, "membership", rel.GetSubject().GetOptionalRelation())
+		assert.Equal(t, tuple, tupleFromRelationship(rel))
+	})
+}
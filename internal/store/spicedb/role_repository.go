@@ -0,0 +1,49 @@
+package spicedb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/internal/store/spicedb/schema_generator"
+
+	authzedpb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+// RoleRepository keeps the custom roles of the organizations in the schema,
+// the schema written from the namespace configs only has the predefined roles
+type RoleRepository struct {
+	spiceDB *SpiceDB
+}
+
+func NewRoleRepository(spiceDB *SpiceDB) *RoleRepository {
+	return &RoleRepository{
+		spiceDB: spiceDB,
+	}
+}
+
+func (r RoleRepository) Add(ctx context.Context, roles []role.Role) error {
+	return r.applyRoles(ctx, nil, roles)
+}
+
+func (r RoleRepository) Remove(ctx context.Context, roles []role.Role) error {
+	return r.applyRoles(ctx, roles, nil)
+}
+
+func (r RoleRepository) applyRoles(ctx context.Context, toRemove, toAdd []role.Role) error {
+	response, err := r.spiceDB.client.ReadSchema(ctx, &authzedpb.ReadSchemaRequest{})
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrReadingSchema, err.Error())
+	}
+
+	updatedSchema, err := schema_generator.ApplyRoles(response.GetSchemaText(), toRemove, toAdd)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.spiceDB.client.WriteSchema(ctx, &authzedpb.WriteSchemaRequest{Schema: updatedSchema}); err != nil {
+		return fmt.Errorf("%w: %s", ErrWritingSchema, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+package schema_generator
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/spicedb/pkg/caveats"
+	"github.com/authzed/spicedb/pkg/caveats/types"
+	sdbnamespace "github.com/authzed/spicedb/pkg/namespace"
+	sdbcore "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/schema"
+)
+
+const (
+	// conditionPrefix starts the names of the relations the namespaces of
+	// the conditional policies have to every user under their condition
+	conditionPrefix = "condition_"
+	// conditionCaveatPrefix starts the names of the caveats of the
+	// conditions, definitions have to be prefixed in the schema
+	conditionCaveatPrefix = "shield/" + conditionPrefix
+)
+
+// ConditionRelation is the relation of a namespace the grants of a
+// conditional policy are intersected with, every object of the namespace
+// has it to every user under the caveat of the condition
+type ConditionRelation struct {
+	NamespaceID string
+	Name        string
+}
+
+// conditionEnvironment has the parameters of the conditions, they are
+// set from the context of the checks by spicedb.checkContext
+func conditionEnvironment() *caveats.Environment {
+	return caveats.MustEnvForVariables(map[string]types.VariableType{
+		"ip":       types.IPAddressType,
+		"now":      types.TimestampType,
+		"request":  types.MapType(types.AnyType),
+		"resource": types.MapType(types.AnyType),
+	})
+}
+
+// ConditionName is the name of the relation of the namespace of the
+// conditional policy under its condition
+func ConditionName(pol policy.Policy) string {
+	return conditionPrefix + strings.ReplaceAll(pol.ID, "-", "")
+}
+
+// conditionCaveat is the name of the caveat of the condition relation
+func conditionCaveat(name string) string {
+	return strings.Replace(name, conditionPrefix, conditionCaveatPrefix, 1)
+}
+
+// ConditionCaveat is the caveat the relationships of the relation are
+// written with, nil if it is not a condition relation. The context of the
+// caveat is left to the checks.
+func ConditionCaveat(relationName string) *pb.ContextualizedCaveat {
+	if !strings.HasPrefix(relationName, conditionPrefix) {
+		return nil
+	}
+	return &pb.ContextualizedCaveat{CaveatName: conditionCaveat(relationName)}
+}
+
+// ConditionRelationship is the relationship of the object to every user
+// under the caveat of the condition relation
+func ConditionRelationship(namespaceID, objectID, name string) *pb.Relationship {
+	return &pb.Relationship{
+		Resource: &pb.ObjectReference{ObjectType: namespaceID, ObjectId: objectID},
+		Relation: name,
+		Subject: &pb.SubjectReference{
+			Object: &pb.ObjectReference{ObjectType: schema.UserPrincipal, ObjectId: "*"},
+		},
+		OptionalCaveat: ConditionCaveat(name),
+	}
+}
+
+// CompileCondition compiles the condition into the definition of the caveat
+// of the condition relation, the condition has to be a boolean expression
+// of the condition parameters
+func CompileCondition(name, condition string) (*sdbcore.CaveatDefinition, error) {
+	return sdbnamespace.CaveatDefinition(conditionEnvironment(), conditionCaveat(name), condition)
+}
+
+// ConditionContext is the context the caveats of the conditions are
+// evaluated with. The ip and now parameters are taken from the request, now
+// defaults to the time of the check.
+func ConditionContext(c relation.CheckContext) map[string]interface{} {
+	request := map[string]interface{}{}
+	for k, v := range c.Request {
+		request[k] = v
+	}
+	resource := map[string]interface{}{}
+	for k, v := range c.Resource {
+		resource[k] = v
+	}
+
+	params := map[string]interface{}{
+		"request":  request,
+		"resource": resource,
+		"now":      time.Now().UTC().Format(time.RFC3339),
+	}
+	if now, ok := c.Request[relation.CheckContextNow]; ok {
+		params["now"] = now
+	}
+	if ip, ok := c.Request[relation.CheckContextIP]; ok {
+		params["ip"] = ip
+	}
+	return params
+}
+
+// ConditionRelations returns the condition relations of the schema sorted
+// by namespace and name
+func ConditionRelations(schemaSource string) ([]ConditionRelation, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaSource,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var relations []ConditionRelation
+	for _, def := range compiled.ObjectDefinitions {
+		for _, rel := range def.GetRelation() {
+			if isConditionRelation(rel) {
+				relations = append(relations, ConditionRelation{NamespaceID: def.GetName(), Name: rel.GetName()})
+			}
+		}
+	}
+	sort.Slice(relations, func(i, j int) bool {
+		if relations[i].NamespaceID != relations[j].NamespaceID {
+			return relations[i].NamespaceID < relations[j].NamespaceID
+		}
+		return relations[i].Name < relations[j].Name
+	})
+	return relations, nil
+}
+
+// DiffConditionRelations returns the condition relations of a which are
+// not in b
+func DiffConditionRelations(a, b []ConditionRelation) []ConditionRelation {
+	inB := map[ConditionRelation]bool{}
+	for _, rel := range b {
+		inB[rel] = true
+	}
+
+	var diff []ConditionRelation
+	for _, rel := range a {
+		if !inB[rel] {
+			diff = append(diff, rel)
+		}
+	}
+	return diff
+}
+
+func isConditionRelation(rel *sdbcore.Relation) bool {
+	return rel.GetUsersetRewrite() == nil && strings.HasPrefix(rel.GetName(), conditionPrefix)
+}
+
+// conditionalChild is the grant of the role under the condition, the
+// intersection of the role with the condition relation
+func conditionalChild(child *sdbcore.SetOperation_Child, name string) *sdbcore.SetOperation_Child {
+	return sdbnamespace.Rewrite(sdbnamespace.Intersection(child, sdbnamespace.ComputedUserset(name)))
+}
+
+// addCondition adds the caveat of the condition to the schema and the
+// condition relation to the definition, an existing caveat of the same name
+// is replaced
+func addCondition(compiled *compiler.CompiledSchema, def *sdbcore.NamespaceDefinition, name, condition string) error {
+	caveat, err := CompileCondition(name, condition)
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, d := range compiled.OrderedDefinitions {
+		if existing, ok := d.(*sdbcore.CaveatDefinition); ok && existing.GetName() == caveat.GetName() {
+			compiled.OrderedDefinitions[i] = caveat
+			replaced = true
+		}
+	}
+	if !replaced {
+		// caveats are defined ahead of the definitions using them
+		compiled.OrderedDefinitions = append([]compiler.SchemaDefinition{caveat}, compiled.OrderedDefinitions...)
+	}
+
+	if findRelation(def, name) == nil {
+		def.Relation = append(def.Relation, sdbnamespace.Relation(name, nil,
+			sdbnamespace.AllowedPublicNamespaceWithCaveat(schema.UserPrincipal, sdbnamespace.AllowedCaveat(caveat.GetName()))))
+	}
+	return nil
+}
+
+// removeUnusedConditions removes the condition relations no permission
+// refers to anymore and then the caveats no relation refers to
+func removeUnusedConditions(compiled *compiler.CompiledSchema) {
+	usedCaveats := map[string]bool{}
+	for _, d := range compiled.OrderedDefinitions {
+		def, ok := d.(*sdbcore.NamespaceDefinition)
+		if !ok {
+			continue
+		}
+
+		used := map[string]bool{}
+		for _, rel := range def.GetRelation() {
+			collectComputedUsersets(rel.GetUsersetRewrite(), used)
+		}
+
+		relations := make([]*sdbcore.Relation, 0, len(def.GetRelation()))
+		for _, rel := range def.GetRelation() {
+			if isConditionRelation(rel) && !used[rel.GetName()] {
+				continue
+			}
+			relations = append(relations, rel)
+			for _, allowed := range rel.GetTypeInformation().GetAllowedDirectRelations() {
+				if allowed.GetRequiredCaveat() != nil {
+					usedCaveats[allowed.GetRequiredCaveat().GetCaveatName()] = true
+				}
+			}
+		}
+		def.Relation = relations
+	}
+
+	definitions := make([]compiler.SchemaDefinition, 0, len(compiled.OrderedDefinitions))
+	for _, d := range compiled.OrderedDefinitions {
+		if caveat, ok := d.(*sdbcore.CaveatDefinition); ok && strings.HasPrefix(caveat.GetName(), conditionCaveatPrefix) && !usedCaveats[caveat.GetName()] {
+			continue
+		}
+		definitions = append(definitions, d)
+	}
+	compiled.OrderedDefinitions = definitions
+}
+
+func collectComputedUsersets(rewrite *sdbcore.UsersetRewrite, used map[string]bool) {
+	if rewrite == nil {
+		return
+	}
+
+	var children []*sdbcore.SetOperation_Child
+	switch {
+	case rewrite.GetUnion() != nil:
+		children = rewrite.GetUnion().GetChild()
+	case rewrite.GetIntersection() != nil:
+		children = rewrite.GetIntersection().GetChild()
+	case rewrite.GetExclusion() != nil:
+		children = rewrite.GetExclusion().GetChild()
+	}
+	for _, c := range children {
+		if cu := c.GetComputedUserset(); cu != nil {
+			used[cu.GetRelation()] = true
+		}
+		collectComputedUsersets(c.GetUsersetRewrite(), used)
+	}
+}
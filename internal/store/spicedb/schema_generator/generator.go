@@ -1,11 +1,15 @@
 package schema_generator
 
 import (
+	"sort"
+
 	"github.com/odpf/shield/internal/schema"
 
 	sdbnamespace "github.com/authzed/spicedb/pkg/namespace"
 	sdbcore "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
 	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
 )
 
 func GenerateSchema(namespaceConfig schema.NamespaceConfigMapType) []string {
@@ -47,6 +51,45 @@ func GenerateSchema(namespaceConfig schema.NamespaceConfigMapType) []string {
 	return definitionSchemaStringified
 }
 
+// Normalize rewrites a spicedb schema with its caveats, its definitions and
+// their relations sorted by name, so schemas generated in a different order can be
+// compared line by line
+func Normalize(schemaSource string) (string, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaSource,
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	caveats := compiled.CaveatDefinitions
+	sort.Slice(caveats, func(i, j int) bool {
+		return caveats[i].GetName() < caveats[j].GetName()
+	})
+	ordered := make([]compiler.SchemaDefinition, 0, len(caveats)+len(compiled.ObjectDefinitions))
+	for _, caveat := range caveats {
+		ordered = append(ordered, caveat)
+	}
+
+	definitions := compiled.ObjectDefinitions
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].GetName() < definitions[j].GetName()
+	})
+	for _, def := range definitions {
+		sort.Slice(def.Relation, func(i, j int) bool {
+			return def.Relation[i].GetName() < def.Relation[j].GetName()
+		})
+		ordered = append(ordered, def)
+	}
+
+	source, ok := generator.GenerateSchema(ordered)
+	if !ok {
+		return "", ErrSchemaNotGenerated
+	}
+	return source, nil
+}
+
 func processPrincipal(s string) string {
 	return map[string]string{
 		"shield/group": "shield/group#membership",
@@ -35,3 +35,21 @@ func TestPredefinedSchema(t *testing.T) {
 	expectedPredefinedConfigs := makeDefnMap(strings.Split(string(content), "\n--\n"))
 	assert.Equal(t, actualPredefinedConfigs, expectedPredefinedConfigs)
 }
+
+func TestNormalize(t *testing.T) {
+	generated := GenerateSchema(schema.PreDefinedSystemNamespaceConfig)
+	reversed := make([]string, len(generated))
+	for i, s := range generated {
+		reversed[len(generated)-1-i] = s
+	}
+
+	first, err := Normalize(strings.Join(generated, "\n"))
+	assert.NoError(t, err)
+	second, err := Normalize(strings.Join(reversed, "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.True(t, strings.Index(first, "definition shield/group") < strings.Index(first, "definition shield/user"))
+
+	_, err = Normalize("definition shield/user {")
+	assert.Error(t, err)
+}
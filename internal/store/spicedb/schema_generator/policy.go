@@ -0,0 +1,267 @@
+package schema_generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	sdbnamespace "github.com/authzed/spicedb/pkg/namespace"
+	sdbcore "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+
+	"github.com/odpf/shield/core/policy"
+)
+
+var (
+	ErrNamespaceNotInSchema = errors.New("namespace not found in authz schema")
+	ErrRoleNotInSchema      = errors.New("role not reachable from namespace in authz schema")
+	ErrSchemaNotGenerated   = errors.New("authz schema can't be generated from its definitions")
+)
+
+// ApplyPolicies rewrites the permissions of an existing spicedb schema so the
+// roles granted by toRemove are no longer part of the permission while the
+// ones granted by toAdd are. The roles of deny policies are excluded from the
+// permission, they don't have it even if another policy allows it to them.
+// The grants of a conditional policy are intersected with its condition
+// relation, which every object has to every user under the caveat of the
+// condition. Removals are applied before additions, the condition
+// relations and caveats no grant uses anymore are removed last.
+func ApplyPolicies(schemaSource string, toRemove, toAdd []policy.Policy) (string, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaSource,
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	definitions := make(map[string]*sdbcore.NamespaceDefinition)
+	for _, def := range compiled.ObjectDefinitions {
+		definitions[def.GetName()] = def
+	}
+
+	for _, pol := range toRemove {
+		def, permission, child, err := resolvePolicy(definitions, pol)
+		if err != nil {
+			return "", err
+		}
+		if pol.Condition != "" {
+			child = conditionalChild(child, ConditionName(pol))
+		}
+		removePermissionChild(def, permission, child, pol.Effect == policy.EffectDeny)
+	}
+
+	for _, pol := range toAdd {
+		def, permission, child, err := resolvePolicy(definitions, pol)
+		if err != nil {
+			return "", err
+		}
+		if pol.Condition != "" {
+			if err := addCondition(compiled, def, ConditionName(pol), pol.Condition); err != nil {
+				return "", err
+			}
+			child = conditionalChild(child, ConditionName(pol))
+		}
+		addPermissionChild(def, permission, child, pol.Effect == policy.EffectDeny)
+	}
+
+	removeUnusedConditions(compiled)
+
+	source, ok := generator.GenerateSchema(compiled.OrderedDefinitions)
+	if !ok {
+		return "", ErrSchemaNotGenerated
+	}
+	return source, nil
+}
+
+// resolvePolicy finds the definition the policy applies to, the permission
+// name derived from its action and the userset granted by its role
+func resolvePolicy(definitions map[string]*sdbcore.NamespaceDefinition, pol policy.Policy) (*sdbcore.NamespaceDefinition, string, *sdbcore.SetOperation_Child, error) {
+	def, ok := definitions[pol.NamespaceID]
+	if !ok {
+		return nil, "", nil, fmt.Errorf("%w: %s", ErrNamespaceNotInSchema, pol.NamespaceID)
+	}
+
+	permission := strings.TrimSuffix(pol.ActionID, "."+pol.NamespaceID)
+
+	roleNamespace, roleName := pol.NamespaceID, pol.RoleID
+	if idx := strings.LastIndex(pol.RoleID, ":"); idx >= 0 {
+		roleNamespace, roleName = pol.RoleID[:idx], pol.RoleID[idx+1:]
+	}
+
+	if roleNamespace == pol.NamespaceID {
+		return def, permission, sdbnamespace.ComputedUserset(roleName), nil
+	}
+
+	// role belongs to an inherited namespace, it is reached by walking
+	// the relation pointing to that namespace
+	for _, rel := range def.GetRelation() {
+		if rel.GetUsersetRewrite() != nil {
+			continue
+		}
+		for _, allowed := range rel.GetTypeInformation().GetAllowedDirectRelations() {
+			if allowed.GetNamespace() == roleNamespace {
+				return def, permission, sdbnamespace.TupleToUserset(rel.GetName(), roleName), nil
+			}
+		}
+	}
+
+	return nil, "", nil, fmt.Errorf("%w: %s in %s", ErrRoleNotInSchema, pol.RoleID, pol.NamespaceID)
+}
+
+func findRelation(def *sdbcore.NamespaceDefinition, name string) *sdbcore.Relation {
+	for _, rel := range def.GetRelation() {
+		if rel.GetName() == name {
+			return rel
+		}
+	}
+	return nil
+}
+
+func addPermissionChild(def *sdbcore.NamespaceDefinition, permission string, child *sdbcore.SetOperation_Child, deny bool) {
+	rel := findRelation(def, permission)
+	if rel == nil || rel.GetUsersetRewrite() == nil {
+		rel = sdbnamespace.Relation(permission, sdbnamespace.Union(sdbnamespace.Nil()))
+		def.Relation = append(def.Relation, rel)
+	}
+
+	allowed, denied, ok := permissionChildren(rel.GetUsersetRewrite())
+	if !ok {
+		return
+	}
+
+	if deny {
+		denied = appendUserset(denied, child)
+	} else {
+		allowed = appendUserset(allowed, child)
+	}
+	rel.UsersetRewrite = permissionRewrite(allowed, denied)
+}
+
+func removePermissionChild(def *sdbcore.NamespaceDefinition, permission string, child *sdbcore.SetOperation_Child, deny bool) {
+	rel := findRelation(def, permission)
+	if rel == nil {
+		return
+	}
+
+	allowed, denied, ok := permissionChildren(rel.GetUsersetRewrite())
+	if !ok {
+		return
+	}
+
+	if deny {
+		denied = removeUserset(denied, child)
+	} else {
+		allowed = removeUserset(allowed, child)
+	}
+	rel.UsersetRewrite = permissionRewrite(allowed, denied)
+}
+
+// permissionChildren returns the usersets the permission is granted to and
+// the ones excluded from it, ok is false if the permission is not a union
+// or a union with exclusions. Operations are parsed nested to the left,
+// a + b + c - d - e being (((a + b) + c) - d) - e. The intersections of
+// conditional grants are single usersets.
+func permissionChildren(rewrite *sdbcore.UsersetRewrite) (allowed, denied []*sdbcore.SetOperation_Child, ok bool) {
+	if rewrite.GetIntersection() != nil {
+		return []*sdbcore.SetOperation_Child{sdbnamespace.Rewrite(rewrite)}, nil, true
+	}
+
+	if union := rewrite.GetUnion(); union != nil {
+		for _, c := range union.GetChild() {
+			if nested := c.GetUsersetRewrite(); nested != nil {
+				if nested.GetIntersection() != nil {
+					allowed = append(allowed, c)
+					continue
+				}
+				if nested.GetUnion() == nil {
+					return nil, nil, false
+				}
+				children, _, _ := permissionChildren(nested)
+				allowed = append(allowed, children...)
+				continue
+			}
+			allowed = append(allowed, c)
+		}
+		return allowed, nil, true
+	}
+
+	exclusion := rewrite.GetExclusion()
+	if exclusion == nil || len(exclusion.GetChild()) == 0 {
+		return nil, nil, false
+	}
+
+	base := exclusion.GetChild()[0]
+	if base.GetUsersetRewrite() != nil && base.GetUsersetRewrite().GetIntersection() == nil {
+		allowed, denied, ok = permissionChildren(base.GetUsersetRewrite())
+		if !ok {
+			return nil, nil, false
+		}
+	} else {
+		allowed = []*sdbcore.SetOperation_Child{base}
+	}
+	return allowed, append(denied, exclusion.GetChild()[1:]...), true
+}
+
+// permissionRewrite is the union of the allowed usersets excluding the
+// denied ones, a permission can't be an empty union so nil grants nobody
+func permissionRewrite(allowed, denied []*sdbcore.SetOperation_Child) *sdbcore.UsersetRewrite {
+	children := make([]*sdbcore.SetOperation_Child, 0, len(allowed))
+	for _, c := range allowed {
+		if c.GetXNil() == nil {
+			children = append(children, c)
+		}
+	}
+	if len(children) == 0 {
+		children = append(children, sdbnamespace.Nil())
+	}
+
+	union := sdbnamespace.Union(children[0], children[1:]...)
+	if len(denied) == 0 {
+		return union
+	}
+	return sdbnamespace.Exclusion(sdbnamespace.Rewrite(union), denied...)
+}
+
+func appendUserset(children []*sdbcore.SetOperation_Child, child *sdbcore.SetOperation_Child) []*sdbcore.SetOperation_Child {
+	for _, c := range children {
+		if sameUserset(c, child) {
+			return children
+		}
+	}
+	return append(children, child)
+}
+
+func removeUserset(children []*sdbcore.SetOperation_Child, child *sdbcore.SetOperation_Child) []*sdbcore.SetOperation_Child {
+	remaining := make([]*sdbcore.SetOperation_Child, 0, len(children))
+	for _, c := range children {
+		if !sameUserset(c, child) {
+			remaining = append(remaining, c)
+		}
+	}
+	return remaining
+}
+
+func sameUserset(a, b *sdbcore.SetOperation_Child) bool {
+	switch {
+	case a.GetComputedUserset() != nil && b.GetComputedUserset() != nil:
+		return a.GetComputedUserset().GetRelation() == b.GetComputedUserset().GetRelation()
+	case a.GetTupleToUserset() != nil && b.GetTupleToUserset() != nil:
+		return a.GetTupleToUserset().GetTupleset().GetRelation() == b.GetTupleToUserset().GetTupleset().GetRelation() &&
+			a.GetTupleToUserset().GetComputedUserset().GetRelation() == b.GetTupleToUserset().GetComputedUserset().GetRelation()
+	case a.GetUsersetRewrite().GetIntersection() != nil && b.GetUsersetRewrite().GetIntersection() != nil:
+		ac, bc := a.GetUsersetRewrite().GetIntersection().GetChild(), b.GetUsersetRewrite().GetIntersection().GetChild()
+		if len(ac) != len(bc) {
+			return false
+		}
+		for i := range ac {
+			if !sameUserset(ac[i], bc[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
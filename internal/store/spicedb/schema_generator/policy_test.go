@@ -0,0 +1,264 @@
+package schema_generator
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	sdbcore "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/odpf/shield/core/policy"
+)
+
+// grantedBy returns the roles whose members are granted the permission,
+// inherited roles are formatted as <relation>-><role>
+func grantedBy(t *testing.T, schemaSource, namespace, permission string) []string {
+	t.Helper()
+	allowed, _ := permissionRoles(t, schemaSource, namespace, permission)
+	return allowed
+}
+
+// deniedBy returns the roles whose members are excluded from the permission
+func deniedBy(t *testing.T, schemaSource, namespace, permission string) []string {
+	t.Helper()
+	_, denied := permissionRoles(t, schemaSource, namespace, permission)
+	return denied
+}
+
+func permissionRoles(t *testing.T, schemaSource, namespace, permission string) (allowed, denied []string) {
+	t.Helper()
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaSource,
+	}, nil)
+	require.NoError(t, err)
+
+	var roleNames func(children []*sdbcore.SetOperation_Child) []string
+	roleNames = func(children []*sdbcore.SetOperation_Child) []string {
+		var roles []string
+		for _, c := range children {
+			switch {
+			case c.GetComputedUserset() != nil:
+				roles = append(roles, c.GetComputedUserset().GetRelation())
+			case c.GetTupleToUserset() != nil:
+				roles = append(roles, c.GetTupleToUserset().GetTupleset().GetRelation()+"->"+c.GetTupleToUserset().GetComputedUserset().GetRelation())
+			case c.GetUsersetRewrite().GetIntersection() != nil:
+				roles = append(roles, strings.Join(roleNames(c.GetUsersetRewrite().GetIntersection().GetChild()), " & "))
+			}
+		}
+		return roles
+	}
+
+	for _, def := range compiled.ObjectDefinitions {
+		if def.GetName() != namespace {
+			continue
+		}
+		for _, rel := range def.GetRelation() {
+			if rel.GetName() != permission {
+				continue
+			}
+			allowedChildren, deniedChildren, ok := permissionChildren(rel.GetUsersetRewrite())
+			require.True(t, ok)
+			return roleNames(allowedChildren), roleNames(deniedChildren)
+		}
+	}
+	return nil, nil
+}
+
+func TestApplyPolicies(t *testing.T) {
+	content, err := ioutil.ReadFile("predefined_schema")
+	require.NoError(t, err)
+	predefinedSchema := strings.ReplaceAll(string(content), "\n--\n", "\n\n")
+
+	t.Run("should revoke the old role and grant the new one when the role of a policy changes", func(t *testing.T) {
+		oldPolicy := policy.Policy{
+			NamespaceID: "shield/project",
+			RoleID:      "shield/project:owner",
+			ActionID:    "delete.shield/project",
+		}
+		newPolicy := oldPolicy
+		newPolicy.RoleID = "shield/project:editor"
+
+		got, err := ApplyPolicies(predefinedSchema, []policy.Policy{oldPolicy}, []policy.Policy{newPolicy})
+		assert.NoError(t, err)
+
+		roles := grantedBy(t, got, "shield/project", "delete")
+		assert.NotContains(t, roles, "owner")
+		assert.ElementsMatch(t, []string{"editor", "organization->owner"}, roles)
+	})
+
+	t.Run("should move the grant when the action of a policy changes", func(t *testing.T) {
+		oldPolicy := policy.Policy{
+			NamespaceID: "shield/project",
+			RoleID:      "shield/organization:viewer",
+			ActionID:    "view.shield/project",
+		}
+		newPolicy := oldPolicy
+		newPolicy.ActionID = "edit.shield/project"
+
+		got, err := ApplyPolicies(predefinedSchema, []policy.Policy{oldPolicy}, []policy.Policy{newPolicy})
+		assert.NoError(t, err)
+
+		assert.NotContains(t, grantedBy(t, got, "shield/project", "view"), "organization->viewer")
+		assert.Contains(t, grantedBy(t, got, "shield/project", "edit"), "organization->viewer")
+	})
+
+	t.Run("should grant nobody once the last role of a permission is revoked", func(t *testing.T) {
+		toRemove := []policy.Policy{
+			{NamespaceID: "shield/project", RoleID: "shield/project:owner", ActionID: "delete.shield/project"},
+			{NamespaceID: "shield/project", RoleID: "shield/organization:owner", ActionID: "delete.shield/project"},
+		}
+
+		got, err := ApplyPolicies(predefinedSchema, toRemove, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, grantedBy(t, got, "shield/project", "delete"))
+	})
+
+	t.Run("should create the permission if it doesn't exist yet", func(t *testing.T) {
+		toAdd := []policy.Policy{
+			{NamespaceID: "shield/organization", RoleID: "shield/organization:owner", ActionID: "delete.shield/organization"},
+		}
+
+		got, err := ApplyPolicies(predefinedSchema, nil, toAdd)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"owner"}, grantedBy(t, got, "shield/organization", "delete"))
+	})
+
+	t.Run("should return error if the role can't be reached from the namespace", func(t *testing.T) {
+		toAdd := []policy.Policy{
+			{NamespaceID: "shield/organization", RoleID: "shield/project:owner", ActionID: "view.shield/organization"},
+		}
+
+		_, err := ApplyPolicies(predefinedSchema, nil, toAdd)
+		assert.ErrorIs(t, err, ErrRoleNotInSchema)
+	})
+
+	t.Run("should return error if the namespace is not part of the schema", func(t *testing.T) {
+		toAdd := []policy.Policy{
+			{NamespaceID: "entropy/firehose", RoleID: "entropy/firehose:owner", ActionID: "view.entropy/firehose"},
+		}
+
+		_, err := ApplyPolicies(predefinedSchema, nil, toAdd)
+		assert.ErrorIs(t, err, ErrNamespaceNotInSchema)
+	})
+
+	t.Run("should exclude the role of a deny policy from the permission", func(t *testing.T) {
+		toAdd := []policy.Policy{
+			{NamespaceID: "shield/project", RoleID: "shield/organization:owner", ActionID: "delete.shield/project", Effect: policy.EffectDeny},
+		}
+
+		got, err := ApplyPolicies(predefinedSchema, nil, toAdd)
+		assert.NoError(t, err)
+		assert.Contains(t, grantedBy(t, got, "shield/project", "delete"), "organization->owner")
+		assert.Equal(t, []string{"organization->owner"}, deniedBy(t, got, "shield/project", "delete"))
+		assert.Contains(t, got, "permission delete = owner + organization->owner - organization->owner")
+	})
+
+	t.Run("should keep the exclusions when the permission is granted to another role", func(t *testing.T) {
+		deny := policy.Policy{NamespaceID: "shield/project", RoleID: "shield/project:viewer", ActionID: "delete.shield/project", Effect: policy.EffectDeny}
+		withDeny, err := ApplyPolicies(predefinedSchema, nil, []policy.Policy{deny})
+		require.NoError(t, err)
+
+		allow := policy.Policy{NamespaceID: "shield/project", RoleID: "shield/project:editor", ActionID: "delete.shield/project"}
+		got, err := ApplyPolicies(withDeny, nil, []policy.Policy{allow})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"owner", "organization->owner", "editor"}, grantedBy(t, got, "shield/project", "delete"))
+		assert.Equal(t, []string{"viewer"}, deniedBy(t, got, "shield/project", "delete"))
+	})
+
+	t.Run("should restore the permission once the deny policy is removed", func(t *testing.T) {
+		deny := policy.Policy{NamespaceID: "shield/project", RoleID: "shield/project:owner", ActionID: "delete.shield/project", Effect: policy.EffectDeny}
+		withDeny, err := ApplyPolicies(predefinedSchema, nil, []policy.Policy{deny})
+		require.NoError(t, err)
+
+		got, err := ApplyPolicies(withDeny, []policy.Policy{deny}, nil)
+		assert.NoError(t, err)
+		assert.Contains(t, grantedBy(t, got, "shield/project", "delete"), "owner")
+		assert.Empty(t, deniedBy(t, got, "shield/project", "delete"))
+	})
+
+	t.Run("should deny a permission no role is granted", func(t *testing.T) {
+		toAdd := []policy.Policy{
+			{NamespaceID: "shield/organization", RoleID: "shield/organization:viewer", ActionID: "delete.shield/organization", Effect: policy.EffectDeny},
+		}
+
+		got, err := ApplyPolicies(predefinedSchema, nil, toAdd)
+		assert.NoError(t, err)
+		assert.Empty(t, grantedBy(t, got, "shield/organization", "delete"))
+		assert.Equal(t, []string{"viewer"}, deniedBy(t, got, "shield/organization", "delete"))
+	})
+}
+
+func TestApplyConditionalPolicies(t *testing.T) {
+	content, err := ioutil.ReadFile("predefined_schema")
+	require.NoError(t, err)
+	predefinedSchema := strings.ReplaceAll(string(content), "\n--\n", "\n\n")
+
+	conditional := policy.Policy{
+		ID:          "9f1c-42",
+		NamespaceID: "shield/project",
+		RoleID:      "shield/project:viewer",
+		ActionID:    "delete.shield/project",
+		Condition:   `resource["env"] == "dev"`,
+	}
+
+	t.Run("should grant the role under the condition of the policy", func(t *testing.T) {
+		got, err := ApplyPolicies(predefinedSchema, nil, []policy.Policy{conditional})
+		assert.NoError(t, err)
+
+		assert.Contains(t, grantedBy(t, got, "shield/project", "delete"), "viewer & condition_9f1c42")
+		assert.Contains(t, got, "caveat shield/condition_9f1c42(")
+		assert.Contains(t, got, "relation condition_9f1c42: shield/user:* with shield/condition_9f1c42")
+
+		relations, err := ConditionRelations(got)
+		assert.NoError(t, err)
+		assert.Equal(t, []ConditionRelation{{NamespaceID: "shield/project", Name: "condition_9f1c42"}}, relations)
+	})
+
+	t.Run("should deny the role under the condition of a deny policy", func(t *testing.T) {
+		deny := conditional
+		deny.Effect = policy.EffectDeny
+
+		got, err := ApplyPolicies(predefinedSchema, nil, []policy.Policy{deny})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"viewer & condition_9f1c42"}, deniedBy(t, got, "shield/project", "delete"))
+	})
+
+	t.Run("should replace the caveat when the condition changes", func(t *testing.T) {
+		withCondition, err := ApplyPolicies(predefinedSchema, nil, []policy.Policy{conditional})
+		require.NoError(t, err)
+
+		changed := conditional
+		changed.Condition = `request["team"] == "data"`
+		got, err := ApplyPolicies(withCondition, []policy.Policy{conditional}, []policy.Policy{changed})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(got, "caveat shield/condition_9f1c42("))
+		assert.Contains(t, got, `request["team"] == "data"`)
+		assert.NotContains(t, got, `resource["env"] == "dev"`)
+	})
+
+	t.Run("should remove the condition relation and caveat with the last grant using them", func(t *testing.T) {
+		withCondition, err := ApplyPolicies(predefinedSchema, nil, []policy.Policy{conditional})
+		require.NoError(t, err)
+
+		got, err := ApplyPolicies(withCondition, []policy.Policy{conditional}, nil)
+		assert.NoError(t, err)
+		assert.NotContains(t, grantedBy(t, got, "shield/project", "delete"), "viewer & condition_9f1c42")
+		assert.NotContains(t, got, "condition_9f1c42")
+	})
+
+	t.Run("should return error if the condition is not a boolean expression of its parameters", func(t *testing.T) {
+		for _, condition := range []string{`resource["env"]`, `user == "jane"`, `ip ==`} {
+			invalid := conditional
+			invalid.Condition = condition
+
+			_, err := ApplyPolicies(predefinedSchema, nil, []policy.Policy{invalid})
+			assert.Error(t, err, condition)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package schema_generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	sdbnamespace "github.com/authzed/spicedb/pkg/namespace"
+	sdbcore "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/internal/schema"
+)
+
+var ErrPrincipalNotSupported = errors.New("principal type can't be granted a role in authz schema")
+
+// ApplyRoles rewrites the relations of an existing spicedb schema so the roles
+// of toRemove are no longer relations of their namespace while the ones of
+// toAdd are, with their types as the principals they can be granted to.
+// Removals are applied before additions.
+func ApplyRoles(schemaSource string, toRemove, toAdd []role.Role) (string, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaSource,
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	definitions := make(map[string]*sdbcore.NamespaceDefinition)
+	for _, def := range compiled.ObjectDefinitions {
+		definitions[def.GetName()] = def
+	}
+
+	for _, rl := range toRemove {
+		def, ok := definitions[rl.NamespaceID]
+		if !ok {
+			continue
+		}
+		relations := make([]*sdbcore.Relation, 0, len(def.GetRelation()))
+		for _, rel := range def.GetRelation() {
+			if rel.GetName() != roleRelationName(rl) {
+				relations = append(relations, rel)
+			}
+		}
+		def.Relation = relations
+	}
+
+	for _, rl := range toAdd {
+		def, ok := definitions[rl.NamespaceID]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrNamespaceNotInSchema, rl.NamespaceID)
+		}
+
+		allowed := make([]*sdbcore.AllowedRelation, 0, len(rl.Types))
+		for _, t := range rl.Types {
+			switch t {
+			case schema.UserPrincipal:
+				allowed = append(allowed, sdbnamespace.AllowedRelation(schema.UserPrincipal, "..."))
+			case schema.GroupPrincipal:
+				allowed = append(allowed, sdbnamespace.AllowedRelation(schema.GroupPrincipal, schema.MembershipPermission))
+			default:
+				return "", fmt.Errorf("%w: %s", ErrPrincipalNotSupported, t)
+			}
+		}
+
+		relation := sdbnamespace.Relation(roleRelationName(rl), nil, allowed...)
+		if existing := findRelation(def, relation.GetName()); existing != nil {
+			existing.TypeInformation = relation.GetTypeInformation()
+			continue
+		}
+		def.Relation = append(def.Relation, relation)
+	}
+
+	source, ok := generator.GenerateSchema(compiled.OrderedDefinitions)
+	if !ok {
+		return "", ErrSchemaNotGenerated
+	}
+	return source, nil
+}
+
+// roleRelationName is the relation of the role in its namespace, the name
+// its id ends with
+func roleRelationName(rl role.Role) string {
+	return rl.ID[strings.LastIndex(rl.ID, ":")+1:]
+}
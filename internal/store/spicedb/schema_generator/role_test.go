@@ -0,0 +1,57 @@
+package schema_generator
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/odpf/shield/core/role"
+)
+
+func TestApplyRoles(t *testing.T) {
+	content, err := ioutil.ReadFile("predefined_schema")
+	require.NoError(t, err)
+	predefinedSchema := strings.ReplaceAll(string(content), "\n--\n", "\n\n")
+
+	reviewer := role.Role{
+		ID:          "shield/project:reviewer",
+		Name:        "reviewer",
+		Types:       []string{"shield/user", "shield/group"},
+		NamespaceID: "shield/project",
+	}
+
+	t.Run("should add the role as a relation of its namespace", func(t *testing.T) {
+		got, err := ApplyRoles(predefinedSchema, nil, []role.Role{reviewer})
+		assert.NoError(t, err)
+		assert.Contains(t, got, "relation reviewer: shield/user | shield/group#membership")
+	})
+
+	t.Run("should remove the role from its namespace", func(t *testing.T) {
+		withRole, err := ApplyRoles(predefinedSchema, nil, []role.Role{reviewer})
+		require.NoError(t, err)
+
+		got, err := ApplyRoles(withRole, []role.Role{reviewer}, nil)
+		assert.NoError(t, err)
+		assert.NotContains(t, got, "reviewer")
+	})
+
+	t.Run("should return error if the namespace is not in the schema", func(t *testing.T) {
+		operator := reviewer
+		operator.ID = "entropy/firehose:operator"
+		operator.NamespaceID = "entropy/firehose"
+
+		_, err := ApplyRoles(predefinedSchema, nil, []role.Role{operator})
+		assert.ErrorIs(t, err, ErrNamespaceNotInSchema)
+	})
+
+	t.Run("should return error if the role can't be granted to a type", func(t *testing.T) {
+		robot := reviewer
+		robot.Types = []string{"shield/robot"}
+
+		_, err := ApplyRoles(predefinedSchema, nil, []role.Role{robot})
+		assert.ErrorIs(t, err, ErrPrincipalNotSupported)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/authzed/grpcutil"
 
 	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
@@ -19,8 +20,9 @@ type SpiceDB struct {
 	client *authzed.Client
 }
 
-func (s *SpiceDB) Check() error {
-	_, err := s.client.ReadSchema(context.Background(), &authzedpb.ReadSchemaRequest{})
+// Check returns an error if spicedb is not reachable
+func (s *SpiceDB) Check(ctx context.Context) error {
+	_, err := s.client.ReadSchema(ctx, &authzedpb.ReadSchemaRequest{})
 	grpCStatus := status.Convert(err)
 	if grpCStatus.Code() == codes.Unavailable {
 		return err
@@ -28,12 +30,20 @@ func (s *SpiceDB) Check() error {
 	return nil
 }
 
-func New(config Config, logger log.Logger) (*SpiceDB, error) {
+// New connects to spicedb, the pre shared key of the config is used when
+// preSharedKey is nil. Otherwise preSharedKey is asked for the key of every
+// request, so a rotated key is used right away.
+func New(config Config, preSharedKey func(ctx context.Context) (string, error), logger log.Logger) (*SpiceDB, error) {
 	endpoint := fmt.Sprintf("%s:%s", config.Host, config.Port)
+	credentials := grpcutil.WithInsecureBearerToken(config.PreSharedKey)
+	if preSharedKey != nil {
+		credentials = grpc.WithPerRPCCredentials(bearerToken(preSharedKey))
+	}
 	client, err := authzed.NewClient(
 		endpoint,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpcutil.WithInsecureBearerToken(config.PreSharedKey),
+		credentials,
+		grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor()),
 	)
 	if err != nil {
 		return &SpiceDB{}, err
@@ -43,10 +53,25 @@ func New(config Config, logger log.Logger) (*SpiceDB, error) {
 		client: client,
 	}
 
-	if err := spiceDBClient.Check(); err != nil {
+	if err := spiceDBClient.Check(context.Background()); err != nil {
 		return nil, err
 	}
 
 	logger.Info(fmt.Sprintf("Connected to spiceDB: %s", endpoint))
 	return spiceDBClient, nil
 }
+
+// bearerToken authenticates the requests with the current pre shared key
+type bearerToken func(ctx context.Context) (string, error)
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	key, err := t(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + key}, nil
+}
+
+func (bearerToken) RequireTransportSecurity() bool {
+	return false
+}
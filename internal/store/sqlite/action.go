@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/odpf/shield/core/action"
+)
+
+type Action struct {
+	ID          string    `db:"id"`
+	Name        string    `db:"name"`
+	NamespaceID string    `db:"namespace_id"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func (from Action) transformToAction() action.Action {
+	return action.Action{
+		ID:          from.ID,
+		Name:        from.Name,
+		NamespaceID: from.NamespaceID,
+		CreatedAt:   from.CreatedAt,
+		UpdatedAt:   from.UpdatedAt,
+	}
+}
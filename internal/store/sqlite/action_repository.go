@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/action"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type ActionRepository struct {
+	dbc *db.Client
+}
+
+func NewActionRepository(dbc *db.Client) *ActionRepository {
+	return &ActionRepository{
+		dbc: dbc,
+	}
+}
+
+func (r ActionRepository) Get(ctx context.Context, id string) (action.Action, error) {
+	if strings.TrimSpace(id) == "" {
+		return action.Action{}, action.ErrInvalidID
+	}
+
+	q, err := toSQL(dialect.Select(&Action{}).From(TABLE_ACTIONS).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return action.Action{}, err
+	}
+
+	var fetchedAction Action
+	if err = get(ctx, r.dbc, TABLE_ACTIONS, "Get", &fetchedAction, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return action.Action{}, action.ErrNotExist
+		}
+		return action.Action{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return fetchedAction.transformToAction(), nil
+}
+
+// Create adds the action or renames the action of the id
+func (r ActionRepository) Create(ctx context.Context, act action.Action) (action.Action, error) {
+	if strings.TrimSpace(act.ID) == "" {
+		return action.Action{}, action.ErrInvalidID
+	}
+
+	createdAt := now()
+	upsert, err := toSQL(dialect.Insert(TABLE_ACTIONS).Rows(
+		goqu.Record{
+			"id":           act.ID,
+			"name":         act.Name,
+			"namespace_id": act.NamespaceID,
+			"created_at":   createdAt,
+			"updated_at":   createdAt,
+		}).OnConflict(
+		goqu.DoUpdate("id", goqu.Record{
+			"name": act.Name,
+		})))
+	if err != nil {
+		return action.Action{}, err
+	}
+	read, err := toSQL(dialect.Select(&Action{}).From(TABLE_ACTIONS).Where(goqu.Ex{"id": act.ID}))
+	if err != nil {
+		return action.Action{}, err
+	}
+
+	var actionModel Action
+	if err = updateRow(ctx, r.dbc, TABLE_ACTIONS, "Create", &actionModel, upsert, read); err != nil {
+		switch {
+		case errors.Is(err, errForeignKeyViolation):
+			return action.Action{}, namespace.ErrNotExist
+		default:
+			return action.Action{}, err
+		}
+	}
+
+	return actionModel.transformToAction(), nil
+}
+
+func (r ActionRepository) List(ctx context.Context) ([]action.Action, error) {
+	q, err := toSQL(dialect.Select(&Action{}).From(TABLE_ACTIONS))
+	if err != nil {
+		return []action.Action{}, err
+	}
+
+	var fetchedActions []Action
+	if err = list(ctx, r.dbc, TABLE_ACTIONS, "List", &fetchedActions, q); err != nil {
+		return []action.Action{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedActions []action.Action
+	for _, o := range fetchedActions {
+		transformedActions = append(transformedActions, o.transformToAction())
+	}
+
+	return transformedActions, nil
+}
+
+func (r ActionRepository) Update(ctx context.Context, act action.Action) (action.Action, error) {
+	if strings.TrimSpace(act.ID) == "" {
+		return action.Action{}, action.ErrInvalidID
+	}
+
+	if strings.TrimSpace(act.Name) == "" {
+		return action.Action{}, action.ErrInvalidDetail
+	}
+
+	update, err := toSQL(dialect.Update(TABLE_ACTIONS).Set(
+		goqu.Record{
+			"name":         act.Name,
+			"namespace_id": act.NamespaceID,
+			"updated_at":   now(),
+		}).Where(goqu.Ex{
+		"id": act.ID,
+	}))
+	if err != nil {
+		return action.Action{}, err
+	}
+	read, err := toSQL(dialect.Select(&Action{}).From(TABLE_ACTIONS).Where(goqu.Ex{"id": act.ID}))
+	if err != nil {
+		return action.Action{}, err
+	}
+
+	var actionModel Action
+	if err = updateRow(ctx, r.dbc, TABLE_ACTIONS, "Update", &actionModel, update, read); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return action.Action{}, action.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return action.Action{}, namespace.ErrNotExist
+		default:
+			return action.Action{}, err
+		}
+	}
+
+	return actionModel.transformToAction(), nil
+}
+
+func (r ActionRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return action.ErrInvalidID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_ACTIONS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return action.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return action.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
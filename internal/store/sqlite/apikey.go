@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/apikey"
+)
+
+type APIKey struct {
+	ID        string       `db:"id"`
+	UserID    string       `db:"user_id"`
+	Name      string       `db:"name"`
+	Prefix    string       `db:"prefix"`
+	Hash      string       `db:"hash"`
+	CreatedAt time.Time    `db:"created_at"`
+	RevokedAt sql.NullTime `db:"revoked_at"`
+}
+
+func (from APIKey) transformToAPIKey() apikey.APIKey {
+	return apikey.APIKey{
+		ID:        from.ID,
+		UserID:    from.UserID,
+		Name:      from.Name,
+		Prefix:    from.Prefix,
+		Hash:      from.Hash,
+		CreatedAt: from.CreatedAt,
+		RevokedAt: from.RevokedAt.Time,
+	}
+}
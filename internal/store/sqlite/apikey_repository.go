@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/apikey"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type APIKeyRepository struct {
+	dbc *db.Client
+}
+
+func NewAPIKeyRepository(dbc *db.Client) *APIKeyRepository {
+	return &APIKeyRepository{
+		dbc: dbc,
+	}
+}
+
+func (r APIKeyRepository) Create(ctx context.Context, key apikey.APIKey) (apikey.APIKey, error) {
+	if strings.TrimSpace(key.UserID) == "" || strings.TrimSpace(key.Prefix) == "" || strings.TrimSpace(key.Hash) == "" {
+		return apikey.APIKey{}, apikey.ErrInvalidDetail
+	}
+
+	keyModel := APIKey{
+		ID:        uuid.NewString(),
+		UserID:    key.UserID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Hash:      key.Hash,
+		CreatedAt: now(),
+	}
+	q, err := toSQL(dialect.Insert(TABLE_API_KEYS).Rows(
+		goqu.Record{
+			"id":         keyModel.ID,
+			"user_id":    keyModel.UserID,
+			"name":       keyModel.Name,
+			"prefix":     keyModel.Prefix,
+			"hash":       keyModel.Hash,
+			"created_at": keyModel.CreatedAt,
+		}))
+	if err != nil {
+		return apikey.APIKey{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_API_KEYS, "Create", q); err != nil {
+		switch {
+		case errors.Is(err, errDuplicateKey):
+			return apikey.APIKey{}, apikey.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return apikey.APIKey{}, fmt.Errorf("%w: user doesn't exist", apikey.ErrInvalidDetail)
+		default:
+			return apikey.APIKey{}, err
+		}
+	}
+
+	return keyModel.transformToAPIKey(), nil
+}
+
+func (r APIKeyRepository) Get(ctx context.Context, id string) (apikey.APIKey, error) {
+	return r.getBy(ctx, "Get", goqu.Ex{"id": id})
+}
+
+func (r APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (apikey.APIKey, error) {
+	return r.getBy(ctx, "GetByPrefix", goqu.Ex{"prefix": prefix})
+}
+
+func (r APIKeyRepository) getBy(ctx context.Context, operation string, ex goqu.Ex) (apikey.APIKey, error) {
+	q, err := toSQL(dialect.From(TABLE_API_KEYS).Where(ex))
+	if err != nil {
+		return apikey.APIKey{}, err
+	}
+
+	var keyModel APIKey
+	if err = get(ctx, r.dbc, TABLE_API_KEYS, operation, &keyModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apikey.APIKey{}, apikey.ErrNotExist
+		}
+		return apikey.APIKey{}, err
+	}
+
+	return keyModel.transformToAPIKey(), nil
+}
+
+func (r APIKeyRepository) List(ctx context.Context, userID string) ([]apikey.APIKey, error) {
+	sqlStatement := dialect.From(TABLE_API_KEYS)
+	if userID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"user_id": userID})
+	}
+
+	q, err := toSQL(sqlStatement.Order(goqu.C("created_at").Asc()))
+	if err != nil {
+		return []apikey.APIKey{}, err
+	}
+
+	var keyModels []APIKey
+	if err = list(ctx, r.dbc, TABLE_API_KEYS, "List", &keyModels, q); err != nil {
+		return []apikey.APIKey{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedKeys []apikey.APIKey
+	for _, k := range keyModels {
+		transformedKeys = append(transformedKeys, k.transformToAPIKey())
+	}
+
+	return transformedKeys, nil
+}
+
+func (r APIKeyRepository) Revoke(ctx context.Context, id string) (apikey.APIKey, error) {
+	update, err := toSQL(dialect.Update(TABLE_API_KEYS).Set(
+		goqu.Record{
+			"revoked_at": now(),
+		}).Where(goqu.Ex{
+		"id":         id,
+		"revoked_at": nil,
+	}))
+	if err != nil {
+		return apikey.APIKey{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_API_KEYS).Where(goqu.Ex{"id": id}))
+	if err != nil {
+		return apikey.APIKey{}, err
+	}
+
+	var keyModel APIKey
+	if err = updateRow(ctx, r.dbc, TABLE_API_KEYS, "Revoke", &keyModel, update, read); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apikey.APIKey{}, apikey.ErrNotExist
+		}
+		return apikey.APIKey{}, err
+	}
+
+	return keyModel.transformToAPIKey(), nil
+}
@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/odpf/shield/core/audit"
+)
+
+type AuditLog struct {
+	ID           string    `db:"id"`
+	Actor        string    `db:"actor"`
+	Action       string    `db:"action"`
+	ResourceType string    `db:"resource_type"`
+	ResourceID   string    `db:"resource_id"`
+	OldPayload   []byte    `db:"old_payload"`
+	NewPayload   []byte    `db:"new_payload"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+func (from AuditLog) transformToAuditLog() (audit.Log, error) {
+	var oldPayload, newPayload any
+	if len(from.OldPayload) > 0 {
+		if err := json.Unmarshal(from.OldPayload, &oldPayload); err != nil {
+			return audit.Log{}, err
+		}
+	}
+	if len(from.NewPayload) > 0 {
+		if err := json.Unmarshal(from.NewPayload, &newPayload); err != nil {
+			return audit.Log{}, err
+		}
+	}
+
+	return audit.Log{
+		ID:           from.ID,
+		Actor:        from.Actor,
+		Action:       from.Action,
+		ResourceType: from.ResourceType,
+		ResourceID:   from.ResourceID,
+		OldPayload:   oldPayload,
+		NewPayload:   newPayload,
+		CreatedAt:    from.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/audit"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type AuditRepository struct {
+	dbc *db.Client
+}
+
+func NewAuditRepository(dbc *db.Client) *AuditRepository {
+	return &AuditRepository{
+		dbc: dbc,
+	}
+}
+
+func (r AuditRepository) Create(ctx context.Context, log audit.Log) (audit.Log, error) {
+	if strings.TrimSpace(log.Actor) == "" || strings.TrimSpace(log.Action) == "" ||
+		strings.TrimSpace(log.ResourceType) == "" || strings.TrimSpace(log.ResourceID) == "" {
+		return audit.Log{}, audit.ErrInvalidDetail
+	}
+
+	oldPayload, err := marshalPayload(log.OldPayload)
+	if err != nil {
+		return audit.Log{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	newPayload, err := marshalPayload(log.NewPayload)
+	if err != nil {
+		return audit.Log{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	logModel := AuditLog{
+		ID:           uuid.NewString(),
+		Actor:        log.Actor,
+		Action:       log.Action,
+		ResourceType: log.ResourceType,
+		ResourceID:   log.ResourceID,
+		CreatedAt:    now(),
+	}
+	q, err := toSQL(dialect.Insert(TABLE_AUDIT_LOGS).Rows(
+		goqu.Record{
+			"id":            logModel.ID,
+			"actor":         logModel.Actor,
+			"action":        logModel.Action,
+			"resource_type": logModel.ResourceType,
+			"resource_id":   logModel.ResourceID,
+			"old_payload":   oldPayload,
+			"new_payload":   newPayload,
+			"created_at":    logModel.CreatedAt,
+		}))
+	if err != nil {
+		return audit.Log{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_AUDIT_LOGS, "Create", q); err != nil {
+		return audit.Log{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return audit.Log{
+		ID:           logModel.ID,
+		Actor:        logModel.Actor,
+		Action:       logModel.Action,
+		ResourceType: logModel.ResourceType,
+		ResourceID:   logModel.ResourceID,
+		OldPayload:   log.OldPayload,
+		NewPayload:   log.NewPayload,
+		CreatedAt:    logModel.CreatedAt,
+	}, nil
+}
+
+func (r AuditRepository) List(ctx context.Context, flt audit.Filter) ([]audit.Log, error) {
+	sqlStatement := dialect.From(TABLE_AUDIT_LOGS)
+	if flt.Actor != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"actor": flt.Actor})
+	}
+	if !flt.Since.IsZero() {
+		sqlStatement = sqlStatement.Where(goqu.C("created_at").Gte(flt.Since.UTC()))
+	}
+	if !flt.Until.IsZero() {
+		sqlStatement = sqlStatement.Where(goqu.C("created_at").Lt(flt.Until.UTC()))
+	}
+
+	q, err := toSQL(sqlStatement.Order(goqu.C("created_at").Desc()))
+	if err != nil {
+		return []audit.Log{}, err
+	}
+
+	var logModels []AuditLog
+	if err = list(ctx, r.dbc, TABLE_AUDIT_LOGS, "List", &logModels, q); err != nil {
+		return []audit.Log{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedLogs []audit.Log
+	for _, l := range logModels {
+		transformedLog, err := l.transformToAuditLog()
+		if err != nil {
+			return []audit.Log{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedLogs = append(transformedLogs, transformedLog)
+	}
+
+	return transformedLogs, nil
+}
+
+// marshalPayload keeps a missing payload as NULL rather than a json null
+func marshalPayload(payload any) (any, error) {
+	if payload == nil {
+		return nil, nil
+	}
+	marshaled, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return string(marshaled), nil
+}
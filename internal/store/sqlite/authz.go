@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/odpf/shield/core/relation"
+)
+
+type AuthzTuple struct {
+	ResourceType    string    `db:"resource_type"`
+	ResourceID      string    `db:"resource_id"`
+	Relation        string    `db:"relation"`
+	SubjectType     string    `db:"subject_type"`
+	SubjectID       string    `db:"subject_id"`
+	SubjectRelation string    `db:"subject_relation"`
+	CreatedAt       time.Time `db:"created_at"`
+}
+
+func (from AuthzTuple) transformToTuple() relation.Tuple {
+	return relation.Tuple{
+		ResourceType:    from.ResourceType,
+		ResourceID:      from.ResourceID,
+		Relation:        from.Relation,
+		SubjectType:     from.SubjectType,
+		SubjectID:       from.SubjectID,
+		SubjectRelation: from.SubjectRelation,
+	}
+}
@@ -0,0 +1,209 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/pkg/db"
+)
+
+// authzTupleBatch is the most tuples written or deleted in a single query,
+// the conditions of a delete nest one level per tuple and sqlite refuses
+// expressions nested deeper than a thousand levels
+const authzTupleBatch = 200
+
+// AuthzRepository keeps the schema and the relation tuples of the embedded
+// authz engine
+type AuthzRepository struct {
+	dbc *db.Client
+}
+
+func NewAuthzRepository(dbc *db.Client) *AuthzRepository {
+	return &AuthzRepository{
+		dbc: dbc,
+	}
+}
+
+// ReadSchema returns the schema, empty when none was written yet
+func (r AuthzRepository) ReadSchema(ctx context.Context) (string, error) {
+	q, err := toSQL(dialect.From(TABLE_AUTHZ_SCHEMA).Select("schema").Where(goqu.Ex{"id": 1}))
+	if err != nil {
+		return "", err
+	}
+
+	var schemaText string
+	if err = get(ctx, r.dbc, TABLE_AUTHZ_SCHEMA, "Get", &schemaText, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return schemaText, nil
+}
+
+// WriteSchema replaces the schema
+func (r AuthzRepository) WriteSchema(ctx context.Context, schemaText string) error {
+	updatedAt := now()
+	q, err := toSQL(dialect.Insert(TABLE_AUTHZ_SCHEMA).Rows(
+		goqu.Record{
+			"id":         1,
+			"schema":     schemaText,
+			"updated_at": updatedAt,
+		}).OnConflict(goqu.DoUpdate("id", goqu.Record{
+		"schema":     schemaText,
+		"updated_at": updatedAt,
+	})))
+	if err != nil {
+		return err
+	}
+	if err = exec(ctx, r.dbc, TABLE_AUTHZ_SCHEMA, "Upsert", q); err != nil {
+		return fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return nil
+}
+
+// Tuples returns the tuples matching the filter, the fields of the filter
+// left empty match any value
+func (r AuthzRepository) Tuples(ctx context.Context, flt relation.Tuple) ([]relation.Tuple, error) {
+	q, err := toSQL(dialect.From(TABLE_AUTHZ_TUPLES).Where(tupleFilter(flt)).Order(
+		goqu.C("resource_type").Asc(),
+		goqu.C("resource_id").Asc(),
+		goqu.C("relation").Asc(),
+		goqu.C("subject_type").Asc(),
+		goqu.C("subject_id").Asc(),
+		goqu.C("subject_relation").Asc(),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var tupleModels []AuthzTuple
+	if err = list(ctx, r.dbc, TABLE_AUTHZ_TUPLES, "List", &tupleModels, q); err != nil {
+		return nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	tuples := make([]relation.Tuple, 0, len(tupleModels))
+	for _, t := range tupleModels {
+		tuples = append(tuples, t.transformToTuple())
+	}
+	return tuples, nil
+}
+
+// ResourceIDs returns the ids of the objects of the namespace having any
+// tuple
+func (r AuthzRepository) ResourceIDs(ctx context.Context, resourceType string) ([]string, error) {
+	q, err := toSQL(dialect.From(TABLE_AUTHZ_TUPLES).Select("resource_id").Distinct().Where(
+		goqu.Ex{"resource_type": resourceType},
+	).Order(goqu.C("resource_id").Asc()))
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if err = list(ctx, r.dbc, TABLE_AUTHZ_TUPLES, "List", &ids, q); err != nil {
+		return nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return ids, nil
+}
+
+// WriteTuples writes the tuples in batches, existing tuples are left as
+// they are
+func (r AuthzRepository) WriteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	createdAt := now()
+	for start := 0; start < len(tuples); start += authzTupleBatch {
+		end := start + authzTupleBatch
+		if end > len(tuples) {
+			end = len(tuples)
+		}
+
+		rows := make([]interface{}, 0, end-start)
+		for _, t := range tuples[start:end] {
+			rows = append(rows, goqu.Record{
+				"resource_type":    t.ResourceType,
+				"resource_id":      t.ResourceID,
+				"relation":         t.Relation,
+				"subject_type":     t.SubjectType,
+				"subject_id":       t.SubjectID,
+				"subject_relation": t.SubjectRelation,
+				"created_at":       createdAt,
+			})
+		}
+		q, err := toSQL(dialect.Insert(TABLE_AUTHZ_TUPLES).Rows(rows...).OnConflict(goqu.DoNothing()))
+		if err != nil {
+			return err
+		}
+		if err := exec(ctx, r.dbc, TABLE_AUTHZ_TUPLES, "Create", q); err != nil {
+			return fmt.Errorf("%w: %s", dbErr, err)
+		}
+	}
+	return nil
+}
+
+// DeleteTuples removes the tuples in batches, tuples which don't exist are
+// skipped
+func (r AuthzRepository) DeleteTuples(ctx context.Context, tuples []relation.Tuple) error {
+	for start := 0; start < len(tuples); start += authzTupleBatch {
+		end := start + authzTupleBatch
+		if end > len(tuples) {
+			end = len(tuples)
+		}
+
+		conditions := make([]goqu.Expression, 0, end-start)
+		for _, t := range tuples[start:end] {
+			conditions = append(conditions, goqu.Ex{
+				"resource_type":    t.ResourceType,
+				"resource_id":      t.ResourceID,
+				"relation":         t.Relation,
+				"subject_type":     t.SubjectType,
+				"subject_id":       t.SubjectID,
+				"subject_relation": t.SubjectRelation,
+			})
+		}
+		q, err := toSQL(dialect.Delete(TABLE_AUTHZ_TUPLES).Where(goqu.Or(conditions...)))
+		if err != nil {
+			return err
+		}
+		if err := exec(ctx, r.dbc, TABLE_AUTHZ_TUPLES, "Delete", q); err != nil {
+			return fmt.Errorf("%w: %s", dbErr, err)
+		}
+	}
+	return nil
+}
+
+// DeleteMatching removes the tuples matching the filter, the fields of the
+// filter left empty match any value but the resource type is required
+func (r AuthzRepository) DeleteMatching(ctx context.Context, flt relation.Tuple) error {
+	if flt.ResourceType == "" {
+		return fmt.Errorf("%w: resource type of the tuples to delete is required", queryErr)
+	}
+	q, err := toSQL(dialect.Delete(TABLE_AUTHZ_TUPLES).Where(tupleFilter(flt)))
+	if err != nil {
+		return err
+	}
+	if err = exec(ctx, r.dbc, TABLE_AUTHZ_TUPLES, "DeleteMatching", q); err != nil {
+		return fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return nil
+}
+
+// tupleFilter matches the tuples on the fields of the filter which are set
+func tupleFilter(flt relation.Tuple) goqu.Ex {
+	ex := goqu.Ex{}
+	for column, value := range map[string]string{
+		"resource_type":    flt.ResourceType,
+		"resource_id":      flt.ResourceID,
+		"relation":         flt.Relation,
+		"subject_type":     flt.SubjectType,
+		"subject_id":       flt.SubjectID,
+		"subject_relation": flt.SubjectRelation,
+	} {
+		if value != "" {
+			ex[column] = value
+		}
+	}
+	return ex
+}
@@ -0,0 +1,60 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthzRepository(t *testing.T) {
+	ctx := context.Background()
+	repository := sqlite.NewAuthzRepository(newTestClient(t))
+
+	t.Run("should replace the schema", func(t *testing.T) {
+		schemaText, err := repository.ReadSchema(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, schemaText)
+
+		require.NoError(t, repository.WriteSchema(ctx, "definition user {}"))
+		require.NoError(t, repository.WriteSchema(ctx, "definition team {}"))
+		schemaText, err = repository.ReadSchema(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "definition team {}", schemaText)
+	})
+
+	t.Run("should write and delete more tuples than a batch holds", func(t *testing.T) {
+		var tuples []relation.Tuple
+		for i := 0; i < 450; i++ {
+			tuples = append(tuples, relation.Tuple{
+				ResourceType: "compute/instance",
+				ResourceID:   fmt.Sprintf("instance-%03d", i),
+				Relation:     "owner",
+				SubjectType:  "shield/user",
+				SubjectID:    "alice",
+			})
+		}
+		require.NoError(t, repository.WriteTuples(ctx, tuples))
+		// existing tuples are left as they are
+		require.NoError(t, repository.WriteTuples(ctx, tuples[:10]))
+
+		ids, err := repository.ResourceIDs(ctx, "compute/instance")
+		require.NoError(t, err)
+		assert.Len(t, ids, 450)
+
+		require.NoError(t, repository.DeleteTuples(ctx, tuples[:400]))
+		remaining, err := repository.Tuples(ctx, relation.Tuple{ResourceType: "compute/instance", SubjectID: "alice"})
+		require.NoError(t, err)
+		require.Len(t, remaining, 50)
+		assert.Equal(t, "instance-400", remaining[0].ResourceID)
+
+		require.NoError(t, repository.DeleteMatching(ctx, relation.Tuple{ResourceType: "compute/instance"}))
+		remaining, err = repository.Tuples(ctx, relation.Tuple{})
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+	})
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/pkg/db"
+)
+
+// deleteByID removes the row of the table with the id, sql.ErrNoRows is
+// returned when there is no such row and sqlite errors go through
+// checkSQLiteError so callers can map them to their domain errors
+func deleteByID(ctx context.Context, dbc *db.Client, table, id string) error {
+	q, err := toSQL(dialect.Delete(table).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return err
+	}
+
+	return execAffectingRow(ctx, dbc, table, "Delete", q)
+}
+
+// softDeleteByID marks the row of the table with the id as deleted, the
+// row is kept so it can be restored, sql.ErrNoRows is returned when
+// there is no such row or it is already deleted
+func softDeleteByID(ctx context.Context, dbc *db.Client, table, id string) error {
+	q, err := toSQL(dialect.Update(table).Set(goqu.Record{
+		"deleted_at": now(),
+	}).Where(goqu.Ex{
+		"id":         id,
+		"deleted_at": nil,
+	}))
+	if err != nil {
+		return err
+	}
+
+	return execAffectingRow(ctx, dbc, table, "SoftDelete", q)
+}
+
+// restoreByID clears the deleted mark of the row of the table with the id,
+// sql.ErrNoRows is returned when there is no such row or it isn't deleted
+func restoreByID(ctx context.Context, dbc *db.Client, table, id string) error {
+	q, err := toSQL(dialect.Update(table).Set(goqu.Record{
+		"deleted_at": nil,
+		"updated_at": now(),
+	}).Where(
+		goqu.Ex{"id": id},
+		goqu.C("deleted_at").IsNotNull(),
+	))
+	if err != nil {
+		return err
+	}
+
+	return execAffectingRow(ctx, dbc, table, "Restore", q)
+}
+
+// purgeByID removes the row of the table with the id only if it is
+// soft deleted, sql.ErrNoRows is returned otherwise
+func purgeByID(ctx context.Context, dbc *db.Client, table, id string) error {
+	q, err := toSQL(dialect.Delete(table).Where(
+		goqu.Ex{"id": id},
+		goqu.C("deleted_at").IsNotNull(),
+	))
+	if err != nil {
+		return err
+	}
+
+	return execAffectingRow(ctx, dbc, table, "Purge", q)
+}
+
+func execAffectingRow(ctx context.Context, dbc *db.Client, table, operation string, q sqlQuery) error {
+	return run(ctx, dbc, table, operation, func(ctx context.Context) error {
+		result, err := dbc.ExecContext(ctx, q.query, q.params...)
+		if err != nil {
+			return checkSQLiteError(err)
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/odpf/shield/core/event"
+)
+
+type EventMessage struct {
+	ID          string    `db:"id"`
+	Type        string    `db:"type"`
+	Key         string    `db:"key"`
+	Payload     []byte    `db:"payload"`
+	Attempts    int       `db:"attempts"`
+	AvailableAt time.Time `db:"available_at"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (from EventMessage) transformToMessage() event.Message {
+	return event.Message{
+		ID:        from.ID,
+		Type:      from.Type,
+		Key:       from.Key,
+		Payload:   from.Payload,
+		Attempts:  from.Attempts,
+		CreatedAt: from.CreatedAt,
+	}
+}
@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+	"github.com/odpf/shield/core/event"
+	"github.com/odpf/shield/pkg/db"
+)
+
+// EventRepository is the outbox of the events, they are written along with
+// the changes to the store and published from it by the relay
+type EventRepository struct {
+	dbc *db.Client
+}
+
+func NewEventRepository(dbc *db.Client) *EventRepository {
+	return &EventRepository{
+		dbc: dbc,
+	}
+}
+
+func (r EventRepository) Create(ctx context.Context, msg event.Message) error {
+	if msg.ID == "" || msg.Type == "" || len(msg.Payload) == 0 {
+		return event.ErrInvalidDetail
+	}
+
+	createdAt := now()
+	q, err := toSQL(dialect.Insert(TABLE_EVENT_OUTBOX).Rows(
+		goqu.Record{
+			"id":           msg.ID,
+			"type":         msg.Type,
+			"key":          msg.Key,
+			"payload":      string(msg.Payload),
+			"available_at": createdAt,
+			"created_at":   createdAt,
+		}))
+	if err != nil {
+		return err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_EVENT_OUTBOX, "Create", q); err != nil {
+		return fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return nil
+}
+
+// Claim holds the oldest messages available for the lease, the transactions
+// of sqlite take the write lock when they begin so two relays don't claim
+// the same message
+func (r EventRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]event.Message, error) {
+	claimedAt := now()
+	available, err := toSQL(dialect.From(TABLE_EVENT_OUTBOX).Where(
+		goqu.C("available_at").Lte(claimedAt),
+	).Order(goqu.C("created_at").Asc()).Limit(uint(limit)))
+	if err != nil {
+		return []event.Message{}, err
+	}
+
+	var msgModels []EventMessage
+	if err = r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return run(ctx, r.dbc, TABLE_EVENT_OUTBOX, "Claim", func(ctx context.Context) error {
+			if err := tx.SelectContext(ctx, &msgModels, available.query, available.params...); err != nil {
+				return err
+			}
+			if len(msgModels) == 0 {
+				return nil
+			}
+
+			ids := make([]string, 0, len(msgModels))
+			for i := range msgModels {
+				msgModels[i].Attempts++
+				ids = append(ids, msgModels[i].ID)
+			}
+			claim, err := toSQL(dialect.Update(TABLE_EVENT_OUTBOX).Set(goqu.Record{
+				"available_at": claimedAt.Add(lease),
+				"attempts":     goqu.L("attempts + 1"),
+			}).Where(goqu.C("id").In(ids)))
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, claim.query, claim.params...)
+			return err
+		})
+	}); err != nil {
+		return []event.Message{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedMsgs []event.Message
+	for _, m := range msgModels {
+		transformedMsgs = append(transformedMsgs, m.transformToMessage())
+	}
+
+	return transformedMsgs, nil
+}
+
+func (r EventRepository) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	q, err := toSQL(dialect.Delete(TABLE_EVENT_OUTBOX).Where(goqu.Ex{"id": ids}))
+	if err != nil {
+		return err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_EVENT_OUTBOX, "Delete", q); err != nil {
+		return fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/odpf/shield/core/folder"
+)
+
+type Folder struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	ProjectID string    `db:"project_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (from Folder) transformToFolder() folder.Folder {
+	return folder.Folder{
+		ID:        from.ID,
+		Name:      from.Name,
+		ProjectID: from.ProjectID,
+		CreatedAt: from.CreatedAt,
+		UpdatedAt: from.UpdatedAt,
+	}
+}
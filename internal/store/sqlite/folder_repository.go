@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/folder"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type FolderRepository struct {
+	dbc *db.Client
+}
+
+func NewFolderRepository(dbc *db.Client) *FolderRepository {
+	return &FolderRepository{
+		dbc: dbc,
+	}
+}
+
+func (r FolderRepository) Create(ctx context.Context, f folder.Folder) (folder.Folder, error) {
+	if strings.TrimSpace(f.Name) == "" || strings.TrimSpace(f.ProjectID) == "" {
+		return folder.Folder{}, folder.ErrInvalidDetail
+	}
+
+	createdAt := now()
+	folderModel := Folder{
+		ID:        uuid.NewString(),
+		Name:      f.Name,
+		ProjectID: f.ProjectID,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	q, err := toSQL(dialect.Insert(TABLE_FOLDERS).Rows(
+		goqu.Record{
+			"id":         folderModel.ID,
+			"name":       folderModel.Name,
+			"project_id": folderModel.ProjectID,
+			"created_at": folderModel.CreatedAt,
+			"updated_at": folderModel.UpdatedAt,
+		}))
+	if err != nil {
+		return folder.Folder{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_FOLDERS, "Create", q); err != nil {
+		switch {
+		case errors.Is(err, errDuplicateKey):
+			return folder.Folder{}, folder.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return folder.Folder{}, fmt.Errorf("%w: project doesn't exist", folder.ErrInvalidDetail)
+		default:
+			return folder.Folder{}, err
+		}
+	}
+
+	return folderModel.transformToFolder(), nil
+}
+
+func (r FolderRepository) Get(ctx context.Context, id string) (folder.Folder, error) {
+	if strings.TrimSpace(id) == "" {
+		return folder.Folder{}, folder.ErrNotExist
+	}
+
+	q, err := toSQL(dialect.From(TABLE_FOLDERS).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return folder.Folder{}, err
+	}
+
+	var folderModel Folder
+	if err = get(ctx, r.dbc, TABLE_FOLDERS, "Get", &folderModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return folder.Folder{}, folder.ErrNotExist
+		}
+		return folder.Folder{}, err
+	}
+
+	return folderModel.transformToFolder(), nil
+}
+
+func (r FolderRepository) List(ctx context.Context, flt folder.Filter) ([]folder.Folder, error) {
+	sqlStatement := dialect.From(TABLE_FOLDERS)
+	if flt.ProjectID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"project_id": flt.ProjectID})
+	}
+
+	q, err := toSQL(sqlStatement.Order(goqu.C("name").Asc()))
+	if err != nil {
+		return []folder.Folder{}, err
+	}
+
+	var folderModels []Folder
+	if err = list(ctx, r.dbc, TABLE_FOLDERS, "List", &folderModels, q); err != nil {
+		return []folder.Folder{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedFolders []folder.Folder
+	for _, f := range folderModels {
+		transformedFolders = append(transformedFolders, f.transformToFolder())
+	}
+
+	return transformedFolders, nil
+}
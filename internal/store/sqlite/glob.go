@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likePattern turns a glob, where * matches any run of characters, into a
+// LIKE pattern escaped with a backslash. The LIKE wildcards in the glob are
+// escaped so they match literally, a glob without * has to match the whole
+// value
+func likePattern(glob string) string {
+	return strings.ReplaceAll(likeEscaper.Replace(glob), "*", "%")
+}
+
+// like matches the expression against the glob, LIKE of sqlite is case
+// insensitive already but has no escape character unless one is given
+func like(expression interface{}, glob string) exp.Expression {
+	return goqu.L(`? LIKE ? ESCAPE '\'`, expression, likePattern(glob))
+}
+
+// jsonPath is the path of the key of a json object
+func jsonPath(key string) string {
+	return `$."` + key + `"`
+}
+
+// globExpressions matches the columns against the globs case insensitively,
+// empty globs are skipped so a zero filter doesn't narrow down the query
+func globExpressions(globs map[string]string) []exp.Expression {
+	columns := make([]string, 0, len(globs))
+	for column, glob := range globs {
+		if glob != "" {
+			columns = append(columns, column)
+		}
+	}
+	// stable order keeps the generated sql the same between calls
+	sort.Strings(columns)
+
+	var expressions []exp.Expression
+	for _, column := range columns {
+		expressions = append(expressions, like(goqu.I(column), globs[column]))
+	}
+	return expressions
+}
+
+// metadataGlobExpressions matches the keys of a json metadata column against
+// the globs, a row without the key never matches
+func metadataGlobExpressions(column string, globs map[string]string) []exp.Expression {
+	keys := make([]string, 0, len(globs))
+	for key := range globs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var expressions []exp.Expression
+	for _, key := range keys {
+		expressions = append(expressions, like(goqu.Func("json_extract", goqu.I(column), jsonPath(key)), globs[key]))
+	}
+	return expressions
+}
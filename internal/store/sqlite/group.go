@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/group"
+)
+
+type Group struct {
+	ID        string       `db:"id"`
+	Name      string       `db:"name"`
+	Slug      string       `db:"slug"`
+	OrgID     string       `db:"org_id"`
+	Metadata  []byte       `db:"metadata"`
+	Version   int64        `db:"version"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+	DeletedAt sql.NullTime `db:"deleted_at"`
+}
+
+func (from Group) transformToGroup() (group.Group, error) {
+	unmarshalledMetadata, err := unmarshalMetadata(from.Metadata)
+	if err != nil {
+		return group.Group{}, err
+	}
+
+	return group.Group{
+		ID:             from.ID,
+		Name:           from.Name,
+		Slug:           from.Slug,
+		OrganizationID: from.OrgID,
+		Metadata:       unmarshalledMetadata,
+		Version:        from.Version,
+		CreatedAt:      from.CreatedAt,
+		UpdatedAt:      from.UpdatedAt,
+	}, nil
+}
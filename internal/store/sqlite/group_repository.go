@@ -0,0 +1,424 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type GroupRepository struct {
+	dbc *db.Client
+}
+
+func NewGroupRepository(dbc *db.Client) *GroupRepository {
+	return &GroupRepository{
+		dbc: dbc,
+	}
+}
+
+func (r GroupRepository) GetByID(ctx context.Context, id string) (group.Group, error) {
+	if strings.TrimSpace(id) == "" {
+		return group.Group{}, group.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return group.Group{}, group.ErrInvalidUUID
+	}
+
+	return r.get(ctx, "GetByID", goqu.Ex{"id": id})
+}
+
+func (r GroupRepository) GetBySlug(ctx context.Context, slug string) (group.Group, error) {
+	if strings.TrimSpace(slug) == "" {
+		return group.Group{}, group.ErrInvalidID
+	}
+
+	return r.get(ctx, "GetBySlug", goqu.Ex{"slug": slug})
+}
+
+func (r GroupRepository) get(ctx context.Context, operation string, where goqu.Ex) (group.Group, error) {
+	q, err := toSQL(dialect.From(TABLE_GROUPS).Where(where))
+	if err != nil {
+		return group.Group{}, err
+	}
+
+	var groupModel Group
+	if err = get(ctx, r.dbc, TABLE_GROUPS, operation, &groupModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return group.Group{}, group.ErrNotExist
+		}
+		return group.Group{}, err
+	}
+
+	transformedGroup, err := groupModel.transformToGroup()
+	if err != nil {
+		return group.Group{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return transformedGroup, nil
+}
+
+func (r GroupRepository) GetByIDs(ctx context.Context, groupIDs []string) ([]group.Group, error) {
+	q, err := toSQL(dialect.From(TABLE_GROUPS).Where(
+		goqu.Ex{
+			"id": goqu.Op{"in": groupIDs},
+		}))
+	if err != nil {
+		return []group.Group{}, err
+	}
+
+	var fetchedGroups []Group
+	if err = list(ctx, r.dbc, TABLE_GROUPS, "GetByIDs", &fetchedGroups, q); err != nil {
+		return []group.Group{}, err
+	}
+
+	return transformToGroups(fetchedGroups)
+}
+
+func (r GroupRepository) Create(ctx context.Context, grp group.Group) (group.Group, error) {
+	if strings.TrimSpace(grp.Name) == "" || strings.TrimSpace(grp.Slug) == "" {
+		return group.Group{}, group.ErrInvalidDetail
+	}
+	if !uuid.IsValid(grp.OrganizationID) {
+		return group.Group{}, organization.ErrInvalidUUID
+	}
+
+	marshaledMetadata, err := marshalMetadata(grp.Metadata)
+	if err != nil {
+		return group.Group{}, err
+	}
+
+	createdAt := now()
+	groupModel := Group{
+		ID:        uuid.NewString(),
+		Name:      grp.Name,
+		Slug:      grp.Slug,
+		OrgID:     grp.OrganizationID,
+		Metadata:  []byte(marshaledMetadata),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	q, err := toSQL(dialect.Insert(TABLE_GROUPS).Rows(
+		goqu.Record{
+			"id":         groupModel.ID,
+			"name":       groupModel.Name,
+			"slug":       groupModel.Slug,
+			"org_id":     groupModel.OrgID,
+			"metadata":   marshaledMetadata,
+			"created_at": groupModel.CreatedAt,
+			"updated_at": groupModel.UpdatedAt,
+		}))
+	if err != nil {
+		return group.Group{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_GROUPS, "Create", q); err != nil {
+		switch {
+		case errors.Is(err, errForeignKeyViolation):
+			return group.Group{}, organization.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return group.Group{}, group.ErrConflict
+		default:
+			return group.Group{}, err
+		}
+	}
+
+	transformedGroup, err := groupModel.transformToGroup()
+	if err != nil {
+		return group.Group{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return transformedGroup, nil
+}
+
+func (r GroupRepository) List(ctx context.Context, flt group.Filter) ([]group.Group, error) {
+	sqlStatement := dialect.From(TABLE_GROUPS)
+	if flt.OrganizationID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"org_id": flt.OrganizationID})
+	}
+	sqlStatement = sqlStatement.Where(
+		globExpressions(map[string]string{"name": flt.Name, "slug": flt.Slug})...,
+	).Where(
+		metadataGlobExpressions("metadata", flt.Metadata)...,
+	)
+	q, err := toSQL(paginate(sqlStatement, flt.Limit, flt.Page))
+	if err != nil {
+		return []group.Group{}, err
+	}
+
+	var fetchedGroups []Group
+	if err = list(ctx, r.dbc, TABLE_GROUPS, "List", &fetchedGroups, q); err != nil {
+		return []group.Group{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return transformToGroups(fetchedGroups)
+}
+
+func (r GroupRepository) UpdateByID(ctx context.Context, grp group.Group) (group.Group, error) {
+	if strings.TrimSpace(grp.ID) == "" {
+		return group.Group{}, group.ErrInvalidID
+	}
+	if !uuid.IsValid(grp.ID) {
+		return group.Group{}, group.ErrInvalidUUID
+	}
+
+	if strings.TrimSpace(grp.Name) == "" || strings.TrimSpace(grp.Slug) == "" {
+		return group.Group{}, group.ErrInvalidDetail
+	}
+
+	return r.update(ctx, "UpdateByID", grp, goqu.Ex{"id": grp.ID})
+}
+
+func (r GroupRepository) UpdateBySlug(ctx context.Context, grp group.Group) (group.Group, error) {
+	if strings.TrimSpace(grp.Slug) == "" {
+		return group.Group{}, group.ErrInvalidID
+	}
+
+	if strings.TrimSpace(grp.Name) == "" {
+		return group.Group{}, group.ErrInvalidDetail
+	}
+
+	return r.update(ctx, "UpdateBySlug", grp, goqu.Ex{"slug": grp.Slug})
+}
+
+// update sets the details of the group matching the condition and reads it
+// back, by the slug it is updated to
+func (r GroupRepository) update(ctx context.Context, operation string, grp group.Group, where goqu.Ex) (group.Group, error) {
+	marshaledMetadata, err := marshalMetadata(grp.Metadata)
+	if err != nil {
+		return group.Group{}, err
+	}
+
+	record, conditioned := versioned(goqu.Record{
+		"name":       grp.Name,
+		"slug":       grp.Slug,
+		"org_id":     grp.OrganizationID,
+		"metadata":   marshaledMetadata,
+		"updated_at": now(),
+	}, where, grp.Version)
+	update, err := toSQL(dialect.Update(TABLE_GROUPS).Set(record).Where(conditioned))
+	if err != nil {
+		return group.Group{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_GROUPS).Where(goqu.Ex{"slug": grp.Slug}))
+	if err != nil {
+		return group.Group{}, err
+	}
+
+	var groupModel Group
+	if err = updateRow(ctx, r.dbc, TABLE_GROUPS, operation, &groupModel, update, read); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_GROUPS, where, grp.Version), errVersionMismatch) {
+				return group.Group{}, group.ErrVersionMismatch
+			}
+			return group.Group{}, group.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return group.Group{}, group.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return group.Group{}, organization.ErrNotExist
+		default:
+			return group.Group{}, fmt.Errorf("%w: %s", dbErr, err)
+		}
+	}
+
+	updated, err := groupModel.transformToGroup()
+	if err != nil {
+		return group.Group{}, fmt.Errorf("%s: %w", parseErr, err)
+	}
+
+	return updated, nil
+}
+
+func (r GroupRepository) buildListUsersByGroupIDQuery(groupID, roleID string) (sqlQuery, error) {
+	sqlStatement := dialect.Select(
+		goqu.I("u.id").As("id"),
+		goqu.I("u.name").As("name"),
+		goqu.I("u.email").As("email"),
+		goqu.I("u.created_at").As("created_at"),
+		goqu.I("u.updated_at").As("updated_at"),
+		goqu.I("u.disabled_at").As("disabled_at"),
+	).
+		From(goqu.T(TABLE_RELATIONS).As("r")).
+		Join(goqu.T(TABLE_USERS).As("u"), goqu.On(
+			goqu.I("u.id").Eq(goqu.I("r.subject_id")),
+		)).
+		Where(goqu.Ex{
+			"r.object_id":            groupID,
+			"r.subject_namespace_id": namespace.DefinitionUser.ID,
+			"r.object_namespace_id":  namespace.DefinitionTeam.ID,
+		})
+
+	if strings.TrimSpace(roleID) != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{
+			"r.role_id": roleID,
+		})
+	}
+
+	return toSQL(sqlStatement)
+}
+
+func (r GroupRepository) ListUsersByGroupID(ctx context.Context, groupID string, roleID string) ([]user.User, error) {
+	if strings.TrimSpace(groupID) == "" {
+		return nil, group.ErrInvalidID
+	}
+
+	return r.listUsers(ctx, "ListUsersByGroupID", groupID, roleID)
+}
+
+func (r GroupRepository) ListUsersByGroupSlug(ctx context.Context, groupSlug string, roleID string) ([]user.User, error) {
+	if strings.TrimSpace(groupSlug) == "" {
+		return nil, group.ErrInvalidID
+	}
+
+	fetchedGroup, err := r.GetBySlug(ctx, groupSlug)
+	if err != nil {
+		return []user.User{}, err
+	}
+
+	return r.listUsers(ctx, "ListUsersByGroupSlug", fetchedGroup.ID, roleID)
+}
+
+func (r GroupRepository) listUsers(ctx context.Context, operation, groupID, roleID string) ([]user.User, error) {
+	q, err := r.buildListUsersByGroupIDQuery(groupID, roleID)
+	if err != nil {
+		return []user.User{}, err
+	}
+
+	var fetchedUsers []User
+	if err = list(ctx, r.dbc, TABLE_GROUPS, operation, &fetchedUsers, q); err != nil {
+		return []user.User{}, err
+	}
+
+	var transformedUsers []user.User
+	for _, u := range fetchedUsers {
+		transformedUsers = append(transformedUsers, u.transformToUser())
+	}
+
+	return transformedUsers, nil
+}
+
+func (r GroupRepository) ListUserGroups(ctx context.Context, userID string, roleID string) ([]group.Group, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, group.ErrInvalidID
+	}
+
+	sqlStatement := dialect.Select(
+		goqu.I("g.id").As("id"),
+		goqu.I("g.metadata").As("metadata"),
+		goqu.I("g.name").As("name"),
+		goqu.I("g.slug").As("slug"),
+		goqu.I("g.updated_at").As("updated_at"),
+		goqu.I("g.created_at").As("created_at"),
+		goqu.I("g.org_id").As("org_id"),
+	).
+		From(goqu.T(TABLE_RELATIONS).As("r")).
+		Join(goqu.T(TABLE_GROUPS).As("g"), goqu.On(
+			goqu.I("g.id").Eq(goqu.I("r.object_id")),
+		)).
+		Where(goqu.Ex{
+			"r.object_namespace_id":  namespace.DefinitionTeam.ID,
+			"r.subject_namespace_id": namespace.DefinitionUser.ID,
+			"r.subject_id":           userID,
+		})
+
+	if strings.TrimSpace(roleID) != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{
+			"r.role_id": roleID,
+		})
+	}
+
+	q, err := toSQL(sqlStatement)
+	if err != nil {
+		return []group.Group{}, err
+	}
+
+	var fetchedGroups []Group
+	if err = list(ctx, r.dbc, TABLE_GROUPS, "ListUserGroups", &fetchedGroups, q); err != nil {
+		return []group.Group{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return transformToGroups(fetchedGroups)
+}
+
+func (r GroupRepository) ListGroupRelations(ctx context.Context, objectId string, subject_type string, role string) ([]relation.RelationV2, error) {
+	whereClauseExp := goqu.Ex{}
+	whereClauseExp["object_id"] = objectId
+	whereClauseExp["object_namespace_id"] = schema.GroupNamespace
+
+	if subject_type != "" {
+		if subject_type == "user" {
+			whereClauseExp["subject_namespace_id"] = schema.UserPrincipal
+		} else if subject_type == "group" {
+			whereClauseExp["subject_namespace_id"] = schema.GroupPrincipal
+		}
+	}
+
+	if role != "" {
+		like := "%:" + role
+		whereClauseExp["role_id"] = goqu.Op{"like": like}
+	}
+
+	q, err := toSQL(dialect.From(TABLE_RELATIONS).Where(whereClauseExp))
+	if err != nil {
+		return []relation.RelationV2{}, err
+	}
+
+	var fetchedRelations []Relation
+	if err = list(ctx, r.dbc, TABLE_GROUPS, "ListGroupRelations", &fetchedRelations, q); err != nil {
+		return []relation.RelationV2{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedRelations []relation.RelationV2
+	for _, r := range fetchedRelations {
+		transformedRelations = append(transformedRelations, r.transformToRelationV2())
+	}
+
+	return transformedRelations, nil
+}
+
+func (r GroupRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return group.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return group.ErrInvalidUUID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_GROUPS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return group.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return group.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func transformToGroups(groupModels []Group) ([]group.Group, error) {
+	var transformedGroups []group.Group
+	for _, g := range groupModels {
+		transformedGroup, err := g.transformToGroup()
+		if err != nil {
+			return []group.Group{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedGroups = append(transformedGroups, transformedGroup)
+	}
+
+	return transformedGroups, nil
+}
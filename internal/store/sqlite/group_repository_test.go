@@ -0,0 +1,94 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupRepository(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	org := bootstrapOrganization(t, client, "acme")
+	repository := sqlite.NewGroupRepository(client)
+
+	created, err := repository.Create(ctx, group.Group{
+		Name:           "Admins",
+		Slug:           "admins",
+		OrganizationID: org.ID,
+	})
+	require.NoError(t, err)
+	other, err := repository.Create(ctx, group.Group{Name: "Readers", Slug: "readers", OrganizationID: org.ID})
+	require.NoError(t, err)
+
+	t.Run("should get groups", func(t *testing.T) {
+		got, err := repository.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "admins", got.Slug)
+		assert.Equal(t, org.ID, got.OrganizationID)
+
+		got, err = repository.GetBySlug(ctx, "readers")
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, got.ID)
+
+		groups, err := repository.GetByIDs(ctx, []string{created.ID, other.ID})
+		require.NoError(t, err)
+		assert.Len(t, groups, 2)
+
+		groups, err = repository.List(ctx, group.Filter{OrganizationID: org.ID, Slug: "read*"})
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, other.ID, groups[0].ID)
+	})
+
+	t.Run("should refuse groups of unknown organizations and duplicate slugs", func(t *testing.T) {
+		_, err := repository.Create(ctx, group.Group{Name: "Admins 2", Slug: "admins", OrganizationID: org.ID})
+		assert.ErrorIs(t, err, group.ErrConflict)
+		_, err = repository.Create(ctx, group.Group{Name: "Orphans", Slug: "orphans", OrganizationID: "00000000-0000-0000-0000-000000000000"})
+		assert.ErrorIs(t, err, organization.ErrNotExist)
+	})
+
+	t.Run("should update a group", func(t *testing.T) {
+		updated, err := repository.UpdateBySlug(ctx, group.Group{Name: "Readers", Slug: "readers", OrganizationID: org.ID,
+			Metadata: map[string]any{"owner": "jane"}})
+		require.NoError(t, err)
+		assert.Equal(t, "jane", updated.Metadata["owner"])
+
+		_, err = repository.UpdateByID(ctx, group.Group{ID: other.ID, Name: "Readers", Slug: "admins", OrganizationID: org.ID})
+		assert.ErrorIs(t, err, group.ErrConflict)
+	})
+
+	t.Run("should list the members and the groups of a user", func(t *testing.T) {
+		member := bootstrapUser(t, client, "member@acme.io")
+		bootstrapRelation(t, client, "shield/user", member.ID, "shield/group", created.ID, "shield/group:member")
+
+		users, err := repository.ListUsersByGroupID(ctx, created.ID, "")
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, member.ID, users[0].ID)
+
+		users, err = repository.ListUsersByGroupSlug(ctx, "admins", "shield/group:owner")
+		require.NoError(t, err)
+		assert.Empty(t, users)
+
+		groups, err := repository.ListUserGroups(ctx, member.ID, "")
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, created.ID, groups[0].ID)
+
+		relations, err := repository.ListGroupRelations(ctx, created.ID, "user", "member")
+		require.NoError(t, err)
+		require.Len(t, relations, 1)
+		assert.Equal(t, member.ID, relations[0].Subject.ID)
+	})
+
+	t.Run("should delete a group", func(t *testing.T) {
+		require.NoError(t, repository.Delete(ctx, other.ID))
+		assert.ErrorIs(t, repository.Delete(ctx, other.ID), group.ErrNotExist)
+	})
+}
@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/idempotency"
+)
+
+type IdempotencyKey struct {
+	Key         string       `db:"key"`
+	Identity    string       `db:"identity"`
+	Method      string       `db:"method"`
+	RequestHash string       `db:"request_hash"`
+	Response    []byte       `db:"response"`
+	CreatedAt   time.Time    `db:"created_at"`
+	CompletedAt sql.NullTime `db:"completed_at"`
+	ExpiresAt   time.Time    `db:"expires_at"`
+}
+
+func (from IdempotencyKey) transformToIdempotencyKey() idempotency.Key {
+	return idempotency.Key{
+		Key:         from.Key,
+		Identity:    from.Identity,
+		Method:      from.Method,
+		RequestHash: from.RequestHash,
+		Response:    from.Response,
+		CreatedAt:   from.CreatedAt,
+		CompletedAt: from.CompletedAt.Time,
+		ExpiresAt:   from.ExpiresAt,
+	}
+}
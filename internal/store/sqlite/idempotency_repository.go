@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type IdempotencyRepository struct {
+	dbc *db.Client
+}
+
+func NewIdempotencyRepository(dbc *db.Client) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		dbc: dbc,
+	}
+}
+
+// Reserve inserts the key, or takes over the same key once it expired, and
+// reads the recorded key when neither was possible
+func (r IdempotencyRepository) Reserve(ctx context.Context, key idempotency.Key) (idempotency.Key, bool, error) {
+	createdAt := now()
+	reserve, err := toSQL(dialect.Insert(TABLE_IDEMPOTENCY_KEYS).Rows(
+		goqu.Record{
+			"key":          key.Key,
+			"identity":     key.Identity,
+			"method":       key.Method,
+			"request_hash": key.RequestHash,
+			"created_at":   createdAt,
+			"expires_at":   key.ExpiresAt.UTC(),
+		}).OnConflict(goqu.DoUpdate("identity, method, key", goqu.Record{
+		"request_hash": goqu.L("excluded.request_hash"),
+		"response":     nil,
+		"created_at":   createdAt,
+		"completed_at": nil,
+		"expires_at":   goqu.L("excluded.expires_at"),
+	}).Where(goqu.I(TABLE_IDEMPOTENCY_KEYS + ".expires_at").Lte(createdAt))))
+	if err != nil {
+		return idempotency.Key{}, false, err
+	}
+	read, err := toSQL(dialect.From(TABLE_IDEMPOTENCY_KEYS).Where(goqu.Ex{
+		"identity": key.Identity,
+		"method":   key.Method,
+		"key":      key.Key,
+	}))
+	if err != nil {
+		return idempotency.Key{}, false, err
+	}
+
+	var keyModel IdempotencyKey
+	var reserved bool
+	if err = r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return run(ctx, r.dbc, TABLE_IDEMPOTENCY_KEYS, "Reserve", func(ctx context.Context) error {
+			result, err := tx.ExecContext(ctx, reserve.query, reserve.params...)
+			if err != nil {
+				return checkSQLiteError(err)
+			}
+			count, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			reserved = count > 0
+			return checkSQLiteError(tx.GetContext(ctx, &keyModel, read.query, read.params...))
+		})
+	}); err != nil {
+		return idempotency.Key{}, false, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return keyModel.transformToIdempotencyKey(), reserved, nil
+}
+
+func (r IdempotencyRepository) Complete(ctx context.Context, key idempotency.Key) error {
+	// the response is bound rather than rendered in the query, it is kept
+	// as it was returned and needn't be text
+	q, err := toSQL(dialect.Update(TABLE_IDEMPOTENCY_KEYS).Prepared(true).Set(
+		goqu.Record{
+			"response":     key.Response,
+			"completed_at": now(),
+		}).Where(goqu.Ex{
+		"identity":     key.Identity,
+		"method":       key.Method,
+		"key":          key.Key,
+		"request_hash": key.RequestHash,
+		"completed_at": nil,
+	}))
+	if err != nil {
+		return err
+	}
+
+	return execAffectingRow(ctx, r.dbc, TABLE_IDEMPOTENCY_KEYS, "Complete", q)
+}
+
+func (r IdempotencyRepository) Release(ctx context.Context, key idempotency.Key) error {
+	q, err := toSQL(dialect.Delete(TABLE_IDEMPOTENCY_KEYS).Where(goqu.Ex{
+		"identity":     key.Identity,
+		"method":       key.Method,
+		"key":          key.Key,
+		"request_hash": key.RequestHash,
+		"completed_at": nil,
+	}))
+	if err != nil {
+		return err
+	}
+
+	if err := execAffectingRow(ctx, r.dbc, TABLE_IDEMPOTENCY_KEYS, "Release", q); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+func (r IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	q, err := toSQL(dialect.Delete(TABLE_IDEMPOTENCY_KEYS).Where(
+		goqu.C("expires_at").Lte(before.UTC()),
+	))
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err = run(ctx, r.dbc, TABLE_IDEMPOTENCY_KEYS, "DeleteExpired", func(ctx context.Context) error {
+		result, err := r.dbc.ExecContext(ctx, q.query, q.params...)
+		if err != nil {
+			return err
+		}
+		count, err = result.RowsAffected()
+		return err
+	}); err != nil {
+		return 0, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,68 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/idempotency"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	ctx := context.Background()
+	repository := sqlite.NewIdempotencyRepository(newTestClient(t))
+	key := idempotency.Key{
+		Key:         "create-org-1",
+		Identity:    "alice",
+		Method:      "/odpf.shield.v1beta1.ShieldService/CreateOrganization",
+		RequestHash: "hash",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	reserved, ok, err := repository.Reserve(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, reserved.IsCompleted())
+
+	t.Run("should read the key reserved already", func(t *testing.T) {
+		recorded, ok, err := repository.Reserve(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, "hash", recorded.RequestHash)
+	})
+
+	t.Run("should keep the response as it was recorded", func(t *testing.T) {
+		completed := key
+		completed.Response = []byte{0x0a, 0x00, 0x27, 0xff}
+		require.NoError(t, repository.Complete(ctx, completed))
+
+		recorded, ok, err := repository.Reserve(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.True(t, recorded.IsCompleted())
+		assert.Equal(t, completed.Response, recorded.Response)
+	})
+
+	t.Run("should take over the key once it expired", func(t *testing.T) {
+		count, err := repository.DeleteExpired(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		expired := key
+		expired.Key = "create-org-2"
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		_, ok, err := repository.Reserve(ctx, expired)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		expired.RequestHash = "other-hash"
+		expired.ExpiresAt = time.Now().Add(time.Hour)
+		recorded, ok, err := repository.Reserve(ctx, expired)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "other-hash", recorded.RequestHash)
+	})
+}
@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/odpf/shield/core/invitation"
+)
+
+type Invitation struct {
+	ID         string       `db:"id"`
+	OrgID      string       `db:"org_id"`
+	Email      string       `db:"email"`
+	GroupIDs   []byte       `db:"group_ids"`
+	InvitedBy  string       `db:"invited_by"`
+	ExpiresAt  time.Time    `db:"expires_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	AcceptedAt sql.NullTime `db:"accepted_at"`
+	RevokedAt  sql.NullTime `db:"revoked_at"`
+}
+
+func (from Invitation) transformToInvitation() (invitation.Invitation, error) {
+	var groupIDs []string
+	if err := json.Unmarshal(from.GroupIDs, &groupIDs); err != nil {
+		return invitation.Invitation{}, err
+	}
+
+	return invitation.Invitation{
+		ID:         from.ID,
+		OrgID:      from.OrgID,
+		Email:      from.Email,
+		GroupIDs:   groupIDs,
+		InvitedBy:  from.InvitedBy,
+		ExpiresAt:  from.ExpiresAt,
+		CreatedAt:  from.CreatedAt,
+		AcceptedAt: from.AcceptedAt.Time,
+		RevokedAt:  from.RevokedAt.Time,
+	}, nil
+}
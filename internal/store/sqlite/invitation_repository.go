@@ -0,0 +1,171 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/invitation"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type InvitationRepository struct {
+	dbc *db.Client
+}
+
+func NewInvitationRepository(dbc *db.Client) *InvitationRepository {
+	return &InvitationRepository{
+		dbc: dbc,
+	}
+}
+
+func (r InvitationRepository) Create(ctx context.Context, inv invitation.Invitation) (invitation.Invitation, error) {
+	if strings.TrimSpace(inv.OrgID) == "" || strings.TrimSpace(inv.Email) == "" || inv.ExpiresAt.IsZero() {
+		return invitation.Invitation{}, invitation.ErrInvalidDetail
+	}
+
+	marshaledGroupIDs, err := marshalStrings(inv.GroupIDs)
+	if err != nil {
+		return invitation.Invitation{}, err
+	}
+
+	invitationModel := Invitation{
+		ID:        uuid.NewString(),
+		OrgID:     inv.OrgID,
+		Email:     inv.Email,
+		GroupIDs:  []byte(marshaledGroupIDs),
+		InvitedBy: inv.InvitedBy,
+		ExpiresAt: inv.ExpiresAt.UTC(),
+		CreatedAt: now(),
+	}
+	q, err := toSQL(dialect.Insert(TABLE_INVITATIONS).Rows(
+		goqu.Record{
+			"id":         invitationModel.ID,
+			"org_id":     invitationModel.OrgID,
+			"email":      invitationModel.Email,
+			"group_ids":  marshaledGroupIDs,
+			"invited_by": invitationModel.InvitedBy,
+			"expires_at": invitationModel.ExpiresAt,
+			"created_at": invitationModel.CreatedAt,
+		}))
+	if err != nil {
+		return invitation.Invitation{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_INVITATIONS, "Create", q); err != nil {
+		if errors.Is(err, errForeignKeyViolation) {
+			return invitation.Invitation{}, fmt.Errorf("%w: organization doesn't exist", invitation.ErrInvalidDetail)
+		}
+		return invitation.Invitation{}, err
+	}
+
+	transformedInvitation, err := invitationModel.transformToInvitation()
+	if err != nil {
+		return invitation.Invitation{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedInvitation, nil
+}
+
+func (r InvitationRepository) Get(ctx context.Context, id string) (invitation.Invitation, error) {
+	if strings.TrimSpace(id) == "" {
+		return invitation.Invitation{}, invitation.ErrNotExist
+	}
+
+	q, err := toSQL(dialect.From(TABLE_INVITATIONS).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return invitation.Invitation{}, err
+	}
+
+	var invitationModel Invitation
+	if err = get(ctx, r.dbc, TABLE_INVITATIONS, "Get", &invitationModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invitation.Invitation{}, invitation.ErrNotExist
+		}
+		return invitation.Invitation{}, err
+	}
+
+	transformedInvitation, err := invitationModel.transformToInvitation()
+	if err != nil {
+		return invitation.Invitation{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedInvitation, nil
+}
+
+func (r InvitationRepository) List(ctx context.Context, flt invitation.Filter) ([]invitation.Invitation, error) {
+	sqlStatement := dialect.From(TABLE_INVITATIONS)
+	if flt.OrgID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"org_id": flt.OrgID})
+	}
+	if flt.Email != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"email": flt.Email})
+	}
+
+	q, err := toSQL(sqlStatement.Order(goqu.C("created_at").Desc()))
+	if err != nil {
+		return []invitation.Invitation{}, err
+	}
+
+	var invitationModels []Invitation
+	if err = list(ctx, r.dbc, TABLE_INVITATIONS, "List", &invitationModels, q); err != nil {
+		return []invitation.Invitation{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedInvitations []invitation.Invitation
+	for _, i := range invitationModels {
+		transformedInvitation, err := i.transformToInvitation()
+		if err != nil {
+			return []invitation.Invitation{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedInvitations = append(transformedInvitations, transformedInvitation)
+	}
+
+	return transformedInvitations, nil
+}
+
+func (r InvitationRepository) Accept(ctx context.Context, id string) (invitation.Invitation, error) {
+	return r.close(ctx, "Accept", "accepted_at", id)
+}
+
+func (r InvitationRepository) Revoke(ctx context.Context, id string) (invitation.Invitation, error) {
+	return r.close(ctx, "Revoke", "revoked_at", id)
+}
+
+// close sets the column to now if the invitation is neither accepted nor
+// revoked yet, so two requests can't both accept or revoke it
+func (r InvitationRepository) close(ctx context.Context, operation, column, id string) (invitation.Invitation, error) {
+	update, err := toSQL(dialect.Update(TABLE_INVITATIONS).Set(
+		goqu.Record{
+			column: now(),
+		}).Where(goqu.Ex{
+		"id":          id,
+		"accepted_at": nil,
+		"revoked_at":  nil,
+	}))
+	if err != nil {
+		return invitation.Invitation{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_INVITATIONS).Where(goqu.Ex{"id": id}))
+	if err != nil {
+		return invitation.Invitation{}, err
+	}
+
+	var invitationModel Invitation
+	if err = updateRow(ctx, r.dbc, TABLE_INVITATIONS, operation, &invitationModel, update, read); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invitation.Invitation{}, invitation.ErrNotPending
+		}
+		return invitation.Invitation{}, err
+	}
+
+	transformedInvitation, err := invitationModel.transformToInvitation()
+	if err != nil {
+		return invitation.Invitation{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedInvitation, nil
+}
@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/metaschema"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type MetaSchemaRepository struct {
+	dbc *db.Client
+}
+
+func NewMetaSchemaRepository(dbc *db.Client) *MetaSchemaRepository {
+	return &MetaSchemaRepository{
+		dbc: dbc,
+	}
+}
+
+func (r MetaSchemaRepository) Upsert(ctx context.Context, ms metaschema.MetaSchema) (metaschema.MetaSchema, error) {
+	updatedAt := now()
+	upsert, err := toSQL(dialect.Insert(TABLE_METADATA_SCHEMAS).Rows(
+		goqu.Record{
+			"entity":     ms.Entity,
+			"schema":     ms.Schema,
+			"created_at": updatedAt,
+			"updated_at": updatedAt,
+		}).OnConflict(goqu.DoUpdate("entity", goqu.Record{
+		"schema":     goqu.L("excluded.schema"),
+		"updated_at": updatedAt,
+	})))
+	if err != nil {
+		return metaschema.MetaSchema{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_METADATA_SCHEMAS).Where(goqu.Ex{
+		"entity": ms.Entity,
+	}))
+	if err != nil {
+		return metaschema.MetaSchema{}, err
+	}
+
+	var schemaModel MetaSchema
+	if err = updateRow(ctx, r.dbc, TABLE_METADATA_SCHEMAS, "Upsert", &schemaModel, upsert, read); err != nil {
+		return metaschema.MetaSchema{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return schemaModel.transformToMetaSchema(), nil
+}
+
+func (r MetaSchemaRepository) Get(ctx context.Context, entity string) (metaschema.MetaSchema, error) {
+	q, err := toSQL(dialect.From(TABLE_METADATA_SCHEMAS).Where(goqu.Ex{
+		"entity": entity,
+	}))
+	if err != nil {
+		return metaschema.MetaSchema{}, err
+	}
+
+	var schemaModel MetaSchema
+	if err = get(ctx, r.dbc, TABLE_METADATA_SCHEMAS, "Get", &schemaModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return metaschema.MetaSchema{}, metaschema.ErrNotExist
+		}
+		return metaschema.MetaSchema{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return schemaModel.transformToMetaSchema(), nil
+}
+
+func (r MetaSchemaRepository) List(ctx context.Context) ([]metaschema.MetaSchema, error) {
+	q, err := toSQL(dialect.From(TABLE_METADATA_SCHEMAS).Order(goqu.C("entity").Asc()))
+	if err != nil {
+		return []metaschema.MetaSchema{}, err
+	}
+
+	var schemaModels []MetaSchema
+	if err = list(ctx, r.dbc, TABLE_METADATA_SCHEMAS, "List", &schemaModels, q); err != nil {
+		return []metaschema.MetaSchema{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedSchemas []metaschema.MetaSchema
+	for _, ms := range schemaModels {
+		transformedSchemas = append(transformedSchemas, ms.transformToMetaSchema())
+	}
+	return transformedSchemas, nil
+}
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS resources;
+DROP TABLE IF EXISTS folders;
+DROP TABLE IF EXISTS relations;
+DROP TABLE IF EXISTS policies;
+DROP TABLE IF EXISTS actions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS namespaces;
+DROP TABLE IF EXISTS metadata;
+DROP TABLE IF EXISTS metadata_keys;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS "groups";
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS organizations;
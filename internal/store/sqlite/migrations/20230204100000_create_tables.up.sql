@@ -0,0 +1,167 @@
+-- the tables of the stores implemented on sqlite, with the tables they read
+-- from or clean up: the namespaces, roles and actions the policies refer to,
+-- the relations the admins are listed from and the folders of the projects.
+-- The ids are generated by the repositories and the times are kept in utc.
+
+CREATE TABLE IF NOT EXISTS organizations
+(
+    id         varchar   PRIMARY KEY,
+    name       varchar   UNIQUE NOT NULL,
+    slug       varchar   UNIQUE NOT NULL,
+    metadata   text,
+    parent_id  varchar   REFERENCES organizations (id),
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    deleted_at timestamp
+);
+CREATE INDEX IF NOT EXISTS organizations_parent_id_idx ON organizations (parent_id);
+
+CREATE TABLE IF NOT EXISTS projects
+(
+    id         varchar   PRIMARY KEY,
+    name       varchar   UNIQUE NOT NULL,
+    slug       varchar   UNIQUE NOT NULL,
+    org_id     varchar   NOT NULL REFERENCES organizations (id),
+    metadata   text,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    deleted_at timestamp
+);
+
+CREATE TABLE IF NOT EXISTS "groups"
+(
+    id         varchar   PRIMARY KEY,
+    name       varchar   UNIQUE NOT NULL,
+    slug       varchar   UNIQUE NOT NULL,
+    org_id     varchar   NOT NULL REFERENCES organizations (id),
+    metadata   text,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    deleted_at timestamp
+);
+
+CREATE TABLE IF NOT EXISTS users
+(
+    id          varchar   PRIMARY KEY,
+    name        varchar   NOT NULL,
+    email       varchar   UNIQUE NOT NULL,
+    created_at  timestamp NOT NULL,
+    updated_at  timestamp NOT NULL,
+    deleted_at  timestamp,
+    disabled_at timestamp
+);
+
+CREATE TABLE IF NOT EXISTS metadata_keys
+(
+    "key"       varchar   UNIQUE NOT NULL,
+    description varchar,
+    created_at  timestamp NOT NULL,
+    updated_at  timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS metadata
+(
+    id         varchar   PRIMARY KEY,
+    user_id    varchar   REFERENCES users (id),
+    "key"      varchar   REFERENCES metadata_keys ("key"),
+    "value"    text,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    UNIQUE (user_id, "key")
+);
+
+CREATE TABLE IF NOT EXISTS namespaces
+(
+    id            varchar   PRIMARY KEY,
+    name          varchar   UNIQUE NOT NULL,
+    backend       varchar,
+    resource_type varchar,
+    created_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at    timestamp
+);
+
+-- the types and the includes of the roles are json arrays
+CREATE TABLE IF NOT EXISTS roles
+(
+    id           varchar   PRIMARY KEY,
+    name         varchar   NOT NULL,
+    types        text      NOT NULL,
+    namespace_id varchar   REFERENCES namespaces (id),
+    org_id       varchar   REFERENCES organizations (id) ON DELETE CASCADE,
+    includes     text,
+    metadata     text,
+    created_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at   timestamp
+);
+CREATE INDEX IF NOT EXISTS roles_org_id_idx ON roles (org_id);
+
+CREATE TABLE IF NOT EXISTS actions
+(
+    id           varchar   PRIMARY KEY,
+    name         varchar   NOT NULL,
+    namespace_id varchar   REFERENCES namespaces (id),
+    created_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at   timestamp
+);
+
+CREATE TABLE IF NOT EXISTS policies
+(
+    id           varchar   PRIMARY KEY,
+    role_id      varchar   REFERENCES roles (id),
+    namespace_id varchar   REFERENCES namespaces (id),
+    action_id    varchar   REFERENCES actions (id),
+    name         varchar,
+    description  varchar,
+    effect       varchar   NOT NULL DEFAULT 'allow',
+    condition    varchar   NOT NULL DEFAULT '',
+    created_at   timestamp NOT NULL,
+    updated_at   timestamp NOT NULL,
+    deleted_at   timestamp,
+    UNIQUE (role_id, namespace_id, action_id)
+);
+
+CREATE TABLE IF NOT EXISTS relations
+(
+    id                   varchar   PRIMARY KEY,
+    subject_namespace_id varchar   REFERENCES namespaces (id),
+    subject_id           varchar,
+    object_namespace_id  varchar   REFERENCES namespaces (id),
+    object_id            varchar,
+    role_id              varchar   REFERENCES roles (id),
+    created_at           timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at           timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at           timestamp,
+    UNIQUE (subject_namespace_id, subject_id, object_namespace_id, object_id, role_id)
+);
+
+CREATE TABLE IF NOT EXISTS folders
+(
+    id         varchar   PRIMARY KEY,
+    name       varchar   NOT NULL,
+    project_id varchar   NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (project_id, name)
+);
+
+-- the tags of the resources are a json object
+CREATE TABLE IF NOT EXISTS resources
+(
+    id           varchar   PRIMARY KEY,
+    urn          varchar   NOT NULL,
+    name         varchar,
+    project_id   varchar   REFERENCES projects (id),
+    org_id       varchar   REFERENCES organizations (id),
+    namespace_id varchar   REFERENCES namespaces (id),
+    user_id      varchar   REFERENCES users (id),
+    folder_id    varchar   REFERENCES folders (id) ON DELETE SET NULL,
+    tags         text      NOT NULL DEFAULT '{}',
+    created_at   timestamp NOT NULL,
+    updated_at   timestamp NOT NULL,
+    deleted_at   timestamp,
+    CONSTRAINT resources_urn_unique UNIQUE (urn)
+);
+CREATE INDEX IF NOT EXISTS resources_folder_id_idx ON resources (folder_id);
@@ -0,0 +1,4 @@
+ALTER TABLE policies DROP COLUMN version;
+ALTER TABLE "groups" DROP COLUMN version;
+ALTER TABLE projects DROP COLUMN version;
+ALTER TABLE organizations DROP COLUMN version;
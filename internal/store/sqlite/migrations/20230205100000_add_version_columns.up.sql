@@ -0,0 +1,4 @@
+ALTER TABLE organizations ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE projects ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "groups" ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE policies ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS metadata_schemas;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS authz_tuples;
+DROP TABLE IF EXISTS authz_schema;
+DROP TABLE IF EXISTS signing_keys;
+DROP TABLE IF EXISTS rules;
+DROP TABLE IF EXISTS organization_usage;
+DROP TABLE IF EXISTS resource_tag_policy_grants;
+DROP TABLE IF EXISTS resource_tag_policies;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS event_outbox;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS invitations;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS service_user_keys;
+DROP TABLE IF EXISTS service_users;
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,220 @@
+-- the tables of the other stores the server needs, the arrays and the json
+-- payloads postgres keeps as jsonb are kept as json text
+
+CREATE TABLE IF NOT EXISTS audit_logs
+(
+    id            varchar   PRIMARY KEY,
+    actor         varchar   NOT NULL,
+    action        varchar   NOT NULL,
+    resource_type varchar   NOT NULL,
+    resource_id   varchar   NOT NULL,
+    old_payload   text,
+    new_payload   text,
+    created_at    timestamp NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_logs_created_at_idx ON audit_logs (created_at);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_idx ON audit_logs (actor);
+
+CREATE TABLE IF NOT EXISTS service_users
+(
+    id         varchar   PRIMARY KEY,
+    user_id    varchar   NOT NULL UNIQUE REFERENCES users (id),
+    name       varchar   NOT NULL UNIQUE,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS service_user_keys
+(
+    id              varchar   PRIMARY KEY,
+    service_user_id varchar   NOT NULL REFERENCES service_users (id) ON DELETE CASCADE,
+    algorithm       varchar   NOT NULL,
+    public_key      text      NOT NULL,
+    created_at      timestamp NOT NULL
+);
+CREATE INDEX IF NOT EXISTS service_user_keys_service_user_id_idx ON service_user_keys (service_user_id);
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id         varchar   PRIMARY KEY,
+    user_id    varchar   NOT NULL REFERENCES users (id),
+    name       varchar   NOT NULL,
+    prefix     varchar   NOT NULL UNIQUE,
+    hash       varchar   NOT NULL,
+    created_at timestamp NOT NULL,
+    revoked_at timestamp
+);
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
+
+-- the group ids are a json array
+CREATE TABLE IF NOT EXISTS invitations
+(
+    id          varchar   PRIMARY KEY,
+    org_id      varchar   NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    email       varchar   NOT NULL,
+    group_ids   text      NOT NULL DEFAULT '[]',
+    invited_by  varchar   NOT NULL,
+    expires_at  timestamp NOT NULL,
+    created_at  timestamp NOT NULL,
+    accepted_at timestamp,
+    revoked_at  timestamp
+);
+CREATE INDEX IF NOT EXISTS invitations_org_id_idx ON invitations (org_id);
+CREATE INDEX IF NOT EXISTS invitations_email_idx ON invitations (email);
+
+-- the events are a json array
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id         varchar   PRIMARY KEY,
+    url        varchar   NOT NULL,
+    secret     varchar   NOT NULL,
+    events     text      NOT NULL,
+    created_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              varchar   PRIMARY KEY,
+    webhook_id      varchar   NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_type      varchar   NOT NULL,
+    payload         text      NOT NULL,
+    status          varchar   NOT NULL DEFAULT 'pending',
+    attempts        INTEGER   NOT NULL DEFAULT 0,
+    last_error      varchar   NOT NULL DEFAULT '',
+    next_attempt_at timestamp NOT NULL,
+    created_at      timestamp NOT NULL,
+    delivered_at    timestamp
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS event_outbox
+(
+    id           varchar   PRIMARY KEY,
+    type         varchar   NOT NULL,
+    "key"        varchar   NOT NULL,
+    payload      text      NOT NULL,
+    attempts     INTEGER   NOT NULL DEFAULT 0,
+    available_at timestamp NOT NULL,
+    created_at   timestamp NOT NULL
+);
+CREATE INDEX IF NOT EXISTS event_outbox_created_at_idx ON event_outbox (created_at);
+
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id                varchar   PRIMARY KEY,
+    user_id           varchar   NOT NULL REFERENCES users (id),
+    user_agent        varchar   NOT NULL DEFAULT '',
+    access_hash       varchar   NOT NULL UNIQUE,
+    access_expires_at timestamp NOT NULL,
+    refresh_hash      varchar   NOT NULL UNIQUE,
+    expires_at        timestamp NOT NULL,
+    created_at        timestamp NOT NULL,
+    refreshed_at      timestamp NOT NULL,
+    revoked_at        timestamp
+);
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+
+-- the tags of the policies are a json object
+CREATE TABLE IF NOT EXISTS resource_tag_policies
+(
+    id                   varchar   PRIMARY KEY,
+    namespace_id         varchar   NOT NULL REFERENCES namespaces (id) ON DELETE CASCADE,
+    tags                 text      NOT NULL,
+    role_id              varchar   NOT NULL,
+    subject_namespace_id varchar   NOT NULL,
+    subject_id           varchar   NOT NULL,
+    created_at           timestamp NOT NULL
+);
+CREATE INDEX IF NOT EXISTS resource_tag_policies_namespace_id_idx ON resource_tag_policies (namespace_id);
+
+CREATE TABLE IF NOT EXISTS resource_tag_policy_grants
+(
+    policy_id   varchar NOT NULL REFERENCES resource_tag_policies (id) ON DELETE CASCADE,
+    resource_id varchar NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    PRIMARY KEY (policy_id, resource_id)
+);
+
+-- the days are kept as YYYY-MM-DD
+CREATE TABLE IF NOT EXISTS organization_usage
+(
+    org_id          varchar NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    day             varchar NOT NULL,
+    api_calls       INTEGER NOT NULL DEFAULT 0,
+    relation_writes INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (org_id, day)
+);
+
+-- the middlewares, the hooks, the backend policy and the backend splits are
+-- json
+CREATE TABLE IF NOT EXISTS rules
+(
+    id                     varchar   PRIMARY KEY,
+    name                   varchar   NOT NULL,
+    proxy                  varchar   NOT NULL,
+    frontend_url           varchar   NOT NULL,
+    frontend_method        varchar   NOT NULL DEFAULT '',
+    frontend_max_body_size INTEGER   NOT NULL DEFAULT 0,
+    backend_url            varchar   NOT NULL,
+    backend_name           varchar   NOT NULL DEFAULT '',
+    backend_prefix         varchar   NOT NULL DEFAULT '',
+    backend_policy         text      NOT NULL DEFAULT '{}',
+    backend_splits         text      NOT NULL DEFAULT '[]',
+    middlewares            text      NOT NULL DEFAULT '[]',
+    hooks                  text      NOT NULL DEFAULT '[]',
+    created_at             timestamp NOT NULL,
+    updated_at             timestamp NOT NULL,
+    UNIQUE (proxy, name)
+);
+
+CREATE TABLE IF NOT EXISTS signing_keys
+(
+    id           varchar   PRIMARY KEY,
+    algorithm    varchar   NOT NULL,
+    public_key   text      NOT NULL,
+    private_key  text      NOT NULL,
+    created_at   timestamp NOT NULL,
+    activates_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS authz_schema
+(
+    id         INTEGER   PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    schema     text      NOT NULL,
+    updated_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS authz_tuples
+(
+    resource_type    varchar   NOT NULL,
+    resource_id      varchar   NOT NULL,
+    relation         varchar   NOT NULL,
+    subject_type     varchar   NOT NULL,
+    subject_id       varchar   NOT NULL,
+    subject_relation varchar   NOT NULL DEFAULT '',
+    created_at       timestamp NOT NULL,
+    PRIMARY KEY (resource_type, resource_id, relation, subject_type, subject_id, subject_relation)
+);
+CREATE INDEX IF NOT EXISTS authz_tuples_subject_idx ON authz_tuples (subject_type, subject_id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    "key"        varchar   NOT NULL,
+    identity     varchar   NOT NULL,
+    method       varchar   NOT NULL,
+    request_hash varchar   NOT NULL,
+    response     blob,
+    created_at   timestamp NOT NULL,
+    completed_at timestamp,
+    expires_at   timestamp NOT NULL,
+    PRIMARY KEY (identity, method, "key")
+);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+CREATE TABLE IF NOT EXISTS metadata_schemas
+(
+    entity     varchar   PRIMARY KEY,
+    schema     text      NOT NULL,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL
+);
@@ -0,0 +1,29 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/namespace"
+)
+
+type Namespace struct {
+	ID           string         `db:"id"`
+	Name         string         `db:"name"`
+	Backend      sql.NullString `db:"backend"`
+	ResourceType sql.NullString `db:"resource_type"`
+	CreatedAt    time.Time      `db:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+	DeletedAt    sql.NullTime   `db:"deleted_at"`
+}
+
+func (from Namespace) transformToNamespace() namespace.Namespace {
+	return namespace.Namespace{
+		ID:           from.ID,
+		Name:         from.Name,
+		Backend:      from.Backend.String,
+		ResourceType: from.ResourceType.String,
+		CreatedAt:    from.CreatedAt,
+		UpdatedAt:    from.UpdatedAt,
+	}
+}
@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type NamespaceRepository struct {
+	dbc *db.Client
+}
+
+func NewNamespaceRepository(dbc *db.Client) *NamespaceRepository {
+	return &NamespaceRepository{
+		dbc: dbc,
+	}
+}
+
+func (r NamespaceRepository) Get(ctx context.Context, id string) (namespace.Namespace, error) {
+	if strings.TrimSpace(id) == "" {
+		return namespace.Namespace{}, namespace.ErrInvalidID
+	}
+
+	q, err := toSQL(dialect.Select(&Namespace{}).From(TABLE_NAMESPACES).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return namespace.Namespace{}, err
+	}
+
+	var fetchedNamespace Namespace
+	if err = get(ctx, r.dbc, TABLE_NAMESPACES, "Get", &fetchedNamespace, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return namespace.Namespace{}, namespace.ErrNotExist
+		}
+		return namespace.Namespace{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return fetchedNamespace.transformToNamespace(), nil
+}
+
+// Create adds the namespace or updates the namespace of the id
+func (r NamespaceRepository) Create(ctx context.Context, ns namespace.Namespace) (namespace.Namespace, error) {
+	if strings.TrimSpace(ns.ID) == "" {
+		return namespace.Namespace{}, namespace.ErrInvalidID
+	}
+
+	if strings.TrimSpace(ns.Name) == "" {
+		return namespace.Namespace{}, namespace.ErrInvalidDetail
+	}
+
+	createdAt := now()
+	upsert, err := toSQL(dialect.Insert(TABLE_NAMESPACES).Rows(
+		goqu.Record{
+			"id":            ns.ID,
+			"name":          ns.Name,
+			"backend":       ns.Backend,
+			"resource_type": ns.ResourceType,
+			"created_at":    createdAt,
+			"updated_at":    createdAt,
+		}).OnConflict(
+		goqu.DoUpdate("id", goqu.Record{
+			"name":          ns.Name,
+			"updated_at":    createdAt,
+			"backend":       ns.Backend,
+			"resource_type": ns.ResourceType,
+		})))
+	if err != nil {
+		return namespace.Namespace{}, err
+	}
+	read, err := toSQL(dialect.Select(&Namespace{}).From(TABLE_NAMESPACES).Where(goqu.Ex{"id": ns.ID}))
+	if err != nil {
+		return namespace.Namespace{}, err
+	}
+
+	var nsModel Namespace
+	if err = updateRow(ctx, r.dbc, TABLE_NAMESPACES, "Create", &nsModel, upsert, read); err != nil {
+		switch {
+		case errors.Is(err, errDuplicateKey):
+			return namespace.Namespace{}, namespace.ErrConflict
+		default:
+			return namespace.Namespace{}, err
+		}
+	}
+
+	return nsModel.transformToNamespace(), nil
+}
+
+func (r NamespaceRepository) List(ctx context.Context) ([]namespace.Namespace, error) {
+	q, err := toSQL(dialect.Select(&Namespace{}).From(TABLE_NAMESPACES))
+	if err != nil {
+		return []namespace.Namespace{}, err
+	}
+
+	var fetchedNamespaces []Namespace
+	if err = list(ctx, r.dbc, TABLE_NAMESPACES, "List", &fetchedNamespaces, q); err != nil {
+		return []namespace.Namespace{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedNamespaces []namespace.Namespace
+	for _, o := range fetchedNamespaces {
+		transformedNamespaces = append(transformedNamespaces, o.transformToNamespace())
+	}
+
+	return transformedNamespaces, nil
+}
+
+func (r NamespaceRepository) Update(ctx context.Context, ns namespace.Namespace) (namespace.Namespace, error) {
+	if strings.TrimSpace(ns.ID) == "" {
+		return namespace.Namespace{}, namespace.ErrInvalidID
+	}
+
+	if strings.TrimSpace(ns.Name) == "" {
+		return namespace.Namespace{}, namespace.ErrInvalidDetail
+	}
+
+	update, err := toSQL(dialect.Update(TABLE_NAMESPACES).Set(
+		goqu.Record{
+			"name":          ns.Name,
+			"updated_at":    now(),
+			"backend":       ns.Backend,
+			"resource_type": ns.ResourceType,
+		}).Where(goqu.Ex{
+		"id": ns.ID,
+	}))
+	if err != nil {
+		return namespace.Namespace{}, err
+	}
+	read, err := toSQL(dialect.Select(&Namespace{}).From(TABLE_NAMESPACES).Where(goqu.Ex{"id": ns.ID}))
+	if err != nil {
+		return namespace.Namespace{}, err
+	}
+
+	var nsModel Namespace
+	if err = updateRow(ctx, r.dbc, TABLE_NAMESPACES, "Update", &nsModel, update, read); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return namespace.Namespace{}, namespace.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return namespace.Namespace{}, namespace.ErrConflict
+		default:
+			return namespace.Namespace{}, err
+		}
+	}
+
+	return nsModel.transformToNamespace(), nil
+}
+
+func (r NamespaceRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return namespace.ErrInvalidID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_NAMESPACES, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return namespace.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return namespace.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
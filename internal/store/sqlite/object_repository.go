@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+// maxObjectLookup is the most ids looked up in a single query, sqlite
+// limits the number of the parameters of a statement
+const maxObjectLookup = 500
+
+// objectTables are the tables of the objects of the predefined namespaces,
+// the objects of the other namespaces are resources
+var objectTables = map[string]string{
+	schema.OrganizationNamespace: TABLE_ORGANIZATIONS,
+	schema.ProjectNamespace:      TABLE_PROJECTS,
+	schema.GroupNamespace:        TABLE_GROUPS,
+	schema.UserPrincipal:         TABLE_USERS,
+}
+
+// ObjectRepository looks up the objects of the namespaces in the tables
+// they are stored in, deleted objects exist until they are purged
+type ObjectRepository struct {
+	dbc *db.Client
+}
+
+func NewObjectRepository(dbc *db.Client) *ObjectRepository {
+	return &ObjectRepository{
+		dbc: dbc,
+	}
+}
+
+// ExistingIDs returns which of the ids refer to an object of the namespace,
+// ids which can't be the id of a row of the table, like the wildcard, are
+// reported as existing
+func (r ObjectRepository) ExistingIDs(ctx context.Context, namespaceID string, ids []string) (map[string]bool, error) {
+	table, predefined := objectTables[namespaceID]
+	if !predefined {
+		table = TABLE_RESOURCES
+	}
+
+	existing := map[string]bool{}
+	var lookup []string
+	for _, id := range ids {
+		if _, seen := existing[id]; seen {
+			continue
+		}
+		if id == "*" || (predefined && !uuid.IsValid(id)) {
+			existing[id] = true
+			continue
+		}
+		existing[id] = false
+		lookup = append(lookup, id)
+	}
+
+	for start := 0; start < len(lookup); start += maxObjectLookup {
+		end := start + maxObjectLookup
+		if end > len(lookup) {
+			end = len(lookup)
+		}
+
+		where := goqu.Ex{"id": lookup[start:end]}
+		if !predefined {
+			where["namespace_id"] = namespaceID
+		}
+		q, err := toSQL(dialect.From(table).Select("id").Where(where))
+		if err != nil {
+			return nil, err
+		}
+
+		var found []string
+		if err = list(ctx, r.dbc, table, "ExistingIDs", &found, q); err != nil {
+			return nil, fmt.Errorf("%w: %s", dbErr, err)
+		}
+
+		for _, id := range found {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+// OrgIDOf returns the organization the object belongs to, an organization
+// belongs to itself. Users and the objects which don't exist belong to none.
+func (r ObjectRepository) OrgIDOf(ctx context.Context, namespaceID, id string) (string, error) {
+	switch namespaceID {
+	case schema.OrganizationNamespace:
+		return id, nil
+	case schema.UserPrincipal:
+		return "", nil
+	}
+	if !uuid.IsValid(id) {
+		return "", nil
+	}
+
+	table, predefined := objectTables[namespaceID]
+	where := goqu.Ex{"id": id}
+	if !predefined {
+		table = TABLE_RESOURCES
+		where["namespace_id"] = namespaceID
+	}
+	q, err := toSQL(dialect.From(table).Select("org_id").Where(where))
+	if err != nil {
+		return "", err
+	}
+
+	// the organization of the resources is optional
+	var orgIDs []sql.NullString
+	if err = list(ctx, r.dbc, table, "OrgIDOf", &orgIDs, q); err != nil {
+		return "", fmt.Errorf("%w: %s", dbErr, err)
+	}
+	if len(orgIDs) == 0 {
+		return "", nil
+	}
+	return orgIDs[0].String, nil
+}
@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/organization"
+)
+
+type Organization struct {
+	ID        string         `db:"id"`
+	Name      string         `db:"name"`
+	Slug      string         `db:"slug"`
+	Metadata  []byte         `db:"metadata"`
+	ParentID  sql.NullString `db:"parent_id"`
+	Version   int64          `db:"version"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+	DeletedAt sql.NullTime   `db:"deleted_at"`
+}
+
+func (from Organization) transformToOrg() (organization.Organization, error) {
+	unmarshalledMetadata, err := unmarshalMetadata(from.Metadata)
+	if err != nil {
+		return organization.Organization{}, err
+	}
+
+	return organization.Organization{
+		ID:        from.ID,
+		Name:      from.Name,
+		Slug:      from.Slug,
+		Metadata:  unmarshalledMetadata,
+		ParentID:  from.ParentID.String,
+		Version:   from.Version,
+		CreatedAt: from.CreatedAt,
+		UpdatedAt: from.UpdatedAt,
+		DeletedAt: from.DeletedAt.Time,
+	}, nil
+}
@@ -0,0 +1,375 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type OrganizationRepository struct {
+	dbc *db.Client
+}
+
+func NewOrganizationRepository(dbc *db.Client) *OrganizationRepository {
+	return &OrganizationRepository{
+		dbc: dbc,
+	}
+}
+
+func (r OrganizationRepository) GetByID(ctx context.Context, id string) (organization.Organization, error) {
+	if strings.TrimSpace(id) == "" {
+		return organization.Organization{}, organization.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return organization.Organization{}, organization.ErrInvalidUUID
+	}
+
+	return r.get(ctx, "GetByID", goqu.Ex{"id": id})
+}
+
+func (r OrganizationRepository) GetBySlug(ctx context.Context, slug string) (organization.Organization, error) {
+	if strings.TrimSpace(slug) == "" {
+		return organization.Organization{}, organization.ErrInvalidID
+	}
+
+	return r.get(ctx, "GetBySlug", goqu.Ex{"slug": slug})
+}
+
+func (r OrganizationRepository) get(ctx context.Context, operation string, where goqu.Ex) (organization.Organization, error) {
+	q, err := toSQL(dialect.From(TABLE_ORGANIZATIONS).Where(where))
+	if err != nil {
+		return organization.Organization{}, err
+	}
+
+	var orgModel Organization
+	if err = get(ctx, r.dbc, TABLE_ORGANIZATIONS, operation, &orgModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return organization.Organization{}, organization.ErrNotExist
+		}
+		return organization.Organization{}, err
+	}
+
+	transformedOrg, err := orgModel.transformToOrg()
+	if err != nil {
+		return organization.Organization{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return transformedOrg, nil
+}
+
+func (r OrganizationRepository) Create(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	if strings.TrimSpace(org.Name) == "" || strings.TrimSpace(org.Slug) == "" {
+		return organization.Organization{}, organization.ErrInvalidDetail
+	}
+
+	marshaledMetadata, err := marshalMetadata(org.Metadata)
+	if err != nil {
+		return organization.Organization{}, err
+	}
+
+	createdAt := now()
+	orgModel := Organization{
+		ID:        uuid.NewString(),
+		Name:      org.Name,
+		Slug:      org.Slug,
+		Metadata:  []byte(marshaledMetadata),
+		ParentID:  sql.NullString{String: org.ParentID, Valid: org.ParentID != ""},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	q, err := toSQL(dialect.Insert(TABLE_ORGANIZATIONS).Rows(goqu.Record{
+		"id":         orgModel.ID,
+		"name":       orgModel.Name,
+		"slug":       orgModel.Slug,
+		"metadata":   marshaledMetadata,
+		"parent_id":  orgModel.ParentID,
+		"created_at": orgModel.CreatedAt,
+		"updated_at": orgModel.UpdatedAt,
+	}))
+	if err != nil {
+		return organization.Organization{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_ORGANIZATIONS, "Create", q); err != nil {
+		switch {
+		case errors.Is(err, errDuplicateKey):
+			return organization.Organization{}, organization.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return organization.Organization{}, organization.ErrInvalidParent
+		default:
+			return organization.Organization{}, err
+		}
+	}
+
+	transformedOrg, err := orgModel.transformToOrg()
+	if err != nil {
+		return organization.Organization{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return transformedOrg, nil
+}
+
+func (r OrganizationRepository) List(ctx context.Context, flt organization.Filter) ([]organization.Organization, error) {
+	sqlStatement := dialect.From(TABLE_ORGANIZATIONS).Where(
+		globExpressions(map[string]string{"name": flt.Name, "slug": flt.Slug})...,
+	).Where(
+		metadataGlobExpressions("metadata", flt.Metadata)...,
+	)
+	if !flt.IncludeDeleted {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"deleted_at": nil})
+	}
+	if flt.ParentID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"parent_id": flt.ParentID})
+	}
+	if flt.UserID != "" {
+		sqlStatement = sqlStatement.Where(userOrganizationsExpression(flt.UserID))
+	}
+
+	q, err := toSQL(paginate(sqlStatement, flt.Limit, flt.Page))
+	if err != nil {
+		return []organization.Organization{}, err
+	}
+
+	var orgModels []Organization
+	if err = list(ctx, r.dbc, TABLE_ORGANIZATIONS, "List", &orgModels, q); err != nil {
+		return []organization.Organization{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedOrgs []organization.Organization
+	for _, o := range orgModels {
+		transformedOrg, err := o.transformToOrg()
+		if err != nil {
+			return []organization.Organization{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedOrgs = append(transformedOrgs, transformedOrg)
+	}
+
+	return transformedOrgs, nil
+}
+
+func (r OrganizationRepository) UpdateByID(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	if strings.TrimSpace(org.ID) == "" {
+		return organization.Organization{}, organization.ErrInvalidID
+	}
+	if !uuid.IsValid(org.ID) {
+		return organization.Organization{}, organization.ErrInvalidUUID
+	}
+
+	if strings.TrimSpace(org.Name) == "" || strings.TrimSpace(org.Slug) == "" {
+		return organization.Organization{}, organization.ErrInvalidDetail
+	}
+
+	return r.update(ctx, "UpdateByID", org, goqu.Ex{"id": org.ID})
+}
+
+func (r OrganizationRepository) UpdateBySlug(ctx context.Context, org organization.Organization) (organization.Organization, error) {
+	if strings.TrimSpace(org.Slug) == "" {
+		return organization.Organization{}, organization.ErrInvalidID
+	}
+
+	if strings.TrimSpace(org.Name) == "" {
+		return organization.Organization{}, organization.ErrInvalidDetail
+	}
+
+	return r.update(ctx, "UpdateBySlug", org, goqu.Ex{"slug": org.Slug})
+}
+
+// update sets the details of the organization matching the condition and
+// reads it back, by the slug it is updated to
+func (r OrganizationRepository) update(ctx context.Context, operation string, org organization.Organization, where goqu.Ex) (organization.Organization, error) {
+	marshaledMetadata, err := marshalMetadata(org.Metadata)
+	if err != nil {
+		return organization.Organization{}, err
+	}
+
+	where["deleted_at"] = nil
+	record, conditioned := versioned(goqu.Record{
+		"name":       org.Name,
+		"slug":       org.Slug,
+		"metadata":   marshaledMetadata,
+		"updated_at": now(),
+	}, where, org.Version)
+	update, err := toSQL(dialect.Update(TABLE_ORGANIZATIONS).Set(record).Where(conditioned))
+	if err != nil {
+		return organization.Organization{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_ORGANIZATIONS).Where(goqu.Ex{"slug": org.Slug}))
+	if err != nil {
+		return organization.Organization{}, err
+	}
+
+	var orgModel Organization
+	if err = updateRow(ctx, r.dbc, TABLE_ORGANIZATIONS, operation, &orgModel, update, read); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_ORGANIZATIONS, where, org.Version), errVersionMismatch) {
+				return organization.Organization{}, organization.ErrVersionMismatch
+			}
+			return organization.Organization{}, organization.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return organization.Organization{}, organization.ErrConflict
+		default:
+			return organization.Organization{}, err
+		}
+	}
+
+	org, err = orgModel.transformToOrg()
+	if err != nil {
+		return organization.Organization{}, fmt.Errorf("%s: %w", parseErr, err)
+	}
+
+	return org, nil
+}
+
+func (r OrganizationRepository) ListAdminsByOrgID(ctx context.Context, orgID string) ([]user.User, error) {
+	if strings.TrimSpace(orgID) == "" {
+		return []user.User{}, organization.ErrInvalidID
+	}
+
+	q, err := toSQL(dialect.Select(
+		goqu.I("u.id").As("id"),
+		goqu.I("u.name").As("name"),
+		goqu.I("u.email").As("email"),
+		goqu.I("u.created_at").As("created_at"),
+		goqu.I("u.updated_at").As("updated_at"),
+		goqu.I("u.disabled_at").As("disabled_at"),
+	).
+		From(goqu.T(TABLE_RELATIONS).As("r")).
+		Join(goqu.T(TABLE_USERS).As("u"), goqu.On(
+			goqu.I("u.id").Eq(goqu.I("r.subject_id")),
+		)).Where(goqu.Ex{
+		"r.object_id":            orgID,
+		"r.role_id":              schema.GetRoleID(schema.OrganizationNamespace, schema.OwnerRole),
+		"r.subject_namespace_id": schema.UserPrincipal,
+		"r.object_namespace_id":  schema.OrganizationNamespace,
+	}))
+	if err != nil {
+		return []user.User{}, err
+	}
+
+	var userModels []User
+	if err = list(ctx, r.dbc, TABLE_ORGANIZATIONS, "ListAdminsByOrgID", &userModels, q); err != nil {
+		return []user.User{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedUsers []user.User
+	for _, u := range userModels {
+		transformedUsers = append(transformedUsers, u.transformToUser())
+	}
+
+	return transformedUsers, nil
+}
+
+func (r OrganizationRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return organization.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return organization.ErrInvalidUUID
+	}
+
+	if err := softDeleteByID(ctx, r.dbc, TABLE_ORGANIZATIONS, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return organization.ErrNotExist
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r OrganizationRepository) Restore(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return organization.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return organization.ErrInvalidUUID
+	}
+
+	if err := restoreByID(ctx, r.dbc, TABLE_ORGANIZATIONS, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return organization.ErrNotExist
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r OrganizationRepository) ListDeleted(ctx context.Context, deletedBefore time.Time) ([]organization.Organization, error) {
+	q, err := toSQL(dialect.From(TABLE_ORGANIZATIONS).Where(
+		goqu.C("deleted_at").Lt(deletedBefore),
+	).Order(goqu.C("deleted_at").Asc()))
+	if err != nil {
+		return []organization.Organization{}, err
+	}
+
+	var orgModels []Organization
+	if err = list(ctx, r.dbc, TABLE_ORGANIZATIONS, "ListDeleted", &orgModels, q); err != nil {
+		return []organization.Organization{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var deleted []organization.Organization
+	for _, m := range orgModels {
+		transformed, err := m.transformToOrg()
+		if err != nil {
+			return []organization.Organization{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		deleted = append(deleted, transformed)
+	}
+
+	return deleted, nil
+}
+
+// Purge permanently removes the organization, only a deleted organization can be purged
+func (r OrganizationRepository) Purge(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return organization.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return organization.ErrInvalidUUID
+	}
+
+	if err := purgeByID(ctx, r.dbc, TABLE_ORGANIZATIONS, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return organization.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return organization.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// userOrganizationsExpression matches the organizations the user has a role
+// on or has a role on one of the groups of
+func userOrganizationsExpression(userID string) exp.Expression {
+	return goqu.Or(
+		goqu.C("id").In(dialect.From(TABLE_RELATIONS).Select("object_id").Where(goqu.Ex{
+			"object_namespace_id":  schema.OrganizationNamespace,
+			"subject_namespace_id": schema.UserPrincipal,
+			"subject_id":           userID,
+		})),
+		goqu.C("id").In(dialect.From(goqu.T(TABLE_GROUPS).As("g")).Join(goqu.T(TABLE_RELATIONS).As("r"), goqu.On(
+			goqu.I("g.id").Eq(goqu.I("r.object_id")),
+		)).Select("g.org_id").Where(goqu.Ex{
+			"r.object_namespace_id":  schema.GroupNamespace,
+			"r.subject_namespace_id": schema.UserPrincipal,
+			"r.subject_id":           userID,
+		})),
+	)
+}
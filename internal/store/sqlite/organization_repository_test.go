@@ -0,0 +1,181 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/group"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/odpf/shield/pkg/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationRepository(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	repository := sqlite.NewOrganizationRepository(client)
+
+	created, err := repository.Create(ctx, organization.Organization{
+		Name:     "Acme",
+		Slug:     "acme",
+		Metadata: metadata.Metadata{"team": "platform"},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	t.Run("should get an organization by id and slug", func(t *testing.T) {
+		got, err := repository.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.Slug, got.Slug)
+		assert.Equal(t, metadata.Metadata{"team": "platform"}, got.Metadata)
+		assert.True(t, created.CreatedAt.Equal(got.CreatedAt))
+
+		got, err = repository.GetBySlug(ctx, "acme")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, got.ID)
+	})
+
+	t.Run("should tell an organization doesn't exist", func(t *testing.T) {
+		_, err := repository.GetByID(ctx, "00000000-0000-0000-0000-000000000000")
+		assert.ErrorIs(t, err, organization.ErrNotExist)
+		_, err = repository.GetByID(ctx, "not-a-uuid")
+		assert.ErrorIs(t, err, organization.ErrInvalidUUID)
+	})
+
+	t.Run("should refuse a duplicate slug and an unknown parent", func(t *testing.T) {
+		_, err := repository.Create(ctx, organization.Organization{Name: "Acme 2", Slug: "acme"})
+		assert.ErrorIs(t, err, organization.ErrConflict)
+		_, err = repository.Create(ctx, organization.Organization{
+			Name:     "Unit",
+			Slug:     "unit",
+			ParentID: "00000000-0000-0000-0000-000000000000",
+		})
+		assert.ErrorIs(t, err, organization.ErrInvalidParent)
+	})
+
+	t.Run("should list organizations matching the filter", func(t *testing.T) {
+		child, err := repository.Create(ctx, organization.Organization{Name: "Acme Labs", Slug: "acme-labs", ParentID: created.ID})
+		require.NoError(t, err)
+
+		orgs, err := repository.List(ctx, organization.Filter{Slug: "acme*"})
+		require.NoError(t, err)
+		assert.Len(t, orgs, 2)
+
+		orgs, err = repository.List(ctx, organization.Filter{Metadata: map[string]string{"team": "plat*"}})
+		require.NoError(t, err)
+		require.Len(t, orgs, 1)
+		assert.Equal(t, created.ID, orgs[0].ID)
+
+		orgs, err = repository.List(ctx, organization.Filter{ParentID: created.ID})
+		require.NoError(t, err)
+		require.Len(t, orgs, 1)
+		assert.Equal(t, child.ID, orgs[0].ID)
+
+		orgs, err = repository.List(ctx, organization.Filter{Limit: 1, Page: 2})
+		require.NoError(t, err)
+		require.Len(t, orgs, 1)
+		assert.Equal(t, child.ID, orgs[0].ID)
+	})
+
+	t.Run("should update an organization", func(t *testing.T) {
+		updated, err := repository.UpdateByID(ctx, organization.Organization{
+			ID:   created.ID,
+			Name: "Acme Corp",
+			Slug: "acme-corp",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme Corp", updated.Name)
+		assert.Equal(t, created.ID, updated.ID)
+
+		updated, err = repository.UpdateBySlug(ctx, organization.Organization{Name: "Acme", Slug: "acme-corp"})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", updated.Name)
+
+		_, err = repository.UpdateBySlug(ctx, organization.Organization{Name: "Acme", Slug: "missing"})
+		assert.ErrorIs(t, err, organization.ErrNotExist)
+	})
+
+	t.Run("should refuse an update made with a stale version", func(t *testing.T) {
+		got, err := repository.GetBySlug(ctx, "acme-corp")
+		require.NoError(t, err)
+
+		updated, err := repository.UpdateBySlug(ctx, organization.Organization{Name: "Acme Inc", Slug: "acme-corp", Version: got.Version})
+		require.NoError(t, err)
+		assert.Equal(t, got.Version+1, updated.Version)
+
+		_, err = repository.UpdateBySlug(ctx, organization.Organization{Name: "Acme Ltd", Slug: "acme-corp", Version: got.Version})
+		assert.ErrorIs(t, err, organization.ErrVersionMismatch)
+		_, err = repository.UpdateBySlug(ctx, organization.Organization{Name: "Acme Ltd", Slug: "missing", Version: got.Version})
+		assert.ErrorIs(t, err, organization.ErrNotExist)
+	})
+
+	t.Run("should delete, restore and purge an organization", func(t *testing.T) {
+		org, err := repository.Create(ctx, organization.Organization{Name: "Gone", Slug: "gone"})
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, repository.Purge(ctx, org.ID), organization.ErrNotExist)
+		require.NoError(t, repository.Delete(ctx, org.ID))
+		assert.ErrorIs(t, repository.Delete(ctx, org.ID), organization.ErrNotExist)
+
+		orgs, err := repository.List(ctx, organization.Filter{Slug: "gone"})
+		require.NoError(t, err)
+		assert.Empty(t, orgs)
+
+		deleted, err := repository.ListDeleted(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, deleted, 1)
+		assert.True(t, deleted[0].IsDeleted())
+
+		require.NoError(t, repository.Restore(ctx, org.ID))
+		require.NoError(t, repository.Delete(ctx, org.ID))
+		require.NoError(t, repository.Purge(ctx, org.ID))
+		_, err = repository.GetByID(ctx, org.ID)
+		assert.ErrorIs(t, err, organization.ErrNotExist)
+	})
+
+	t.Run("should refuse to purge an organization in use", func(t *testing.T) {
+		require.NoError(t, repository.Delete(ctx, created.ID))
+		assert.ErrorIs(t, repository.Purge(ctx, created.ID), organization.ErrInUse)
+		require.NoError(t, repository.Restore(ctx, created.ID))
+	})
+}
+
+func TestOrganizationRepositoryListAdmins(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	org := bootstrapOrganization(t, client, "acme")
+	admin := bootstrapUser(t, client, "admin@acme.io")
+	bootstrapUser(t, client, "member@acme.io")
+	bootstrapRelation(t, client, "shield/user", admin.ID, "shield/organization", org.ID, "shield/organization:owner")
+
+	admins, err := sqlite.NewOrganizationRepository(client).ListAdminsByOrgID(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, admins, 1)
+	assert.Equal(t, admin.Email, admins[0].Email)
+}
+
+func TestOrganizationRepositoryListUserOrganizations(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	acme := bootstrapOrganization(t, client, "acme")
+	globex := bootstrapOrganization(t, client, "globex")
+	bootstrapOrganization(t, client, "initech")
+	usr := bootstrapUser(t, client, "jane@acme.io")
+	bootstrapRelation(t, client, "shield/user", usr.ID, "shield/organization", acme.ID, "shield/organization:viewer")
+
+	grp, err := sqlite.NewGroupRepository(client).Create(ctx, group.Group{Name: "Readers", Slug: "readers", OrganizationID: globex.ID})
+	require.NoError(t, err)
+	bootstrapRelation(t, client, "shield/user", usr.ID, "shield/group", grp.ID, "shield/group:member")
+
+	orgs, err := sqlite.NewOrganizationRepository(client).List(ctx, organization.Filter{UserID: usr.ID})
+	require.NoError(t, err)
+	var slugs []string
+	for _, o := range orgs {
+		slugs = append(slugs, o.Slug)
+	}
+	assert.ElementsMatch(t, []string{"acme", "globex"}, slugs)
+}
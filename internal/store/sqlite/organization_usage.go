@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/odpf/shield/core/usage"
+)
+
+// dayFormat is the format the days of the usage are kept in
+const dayFormat = "2006-01-02"
+
+type OrganizationUsage struct {
+	OrgID          string `db:"org_id"`
+	Day            string `db:"day"`
+	APICalls       int64  `db:"api_calls"`
+	RelationWrites int64  `db:"relation_writes"`
+}
+
+func (from OrganizationUsage) transformToUsage() (usage.Usage, error) {
+	day, err := time.Parse(dayFormat, from.Day)
+	if err != nil {
+		return usage.Usage{}, err
+	}
+
+	return usage.Usage{
+		OrgID:          from.OrgID,
+		Day:            usage.DayOf(day),
+		APICalls:       from.APICalls,
+		RelationWrites: from.RelationWrites,
+	}, nil
+}
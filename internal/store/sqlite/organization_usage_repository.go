@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/usage"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type OrganizationUsageRepository struct {
+	dbc *db.Client
+}
+
+func NewOrganizationUsageRepository(dbc *db.Client) *OrganizationUsageRepository {
+	return &OrganizationUsageRepository{
+		dbc: dbc,
+	}
+}
+
+// Add adds the counts in a single statement, the rows are written in the
+// order of their key like the postgres repository does
+func (r OrganizationUsageRepository) Add(ctx context.Context, usages []usage.Usage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+	sorted := make([]usage.Usage, len(usages))
+	copy(sorted, usages)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].OrgID != sorted[j].OrgID {
+			return sorted[i].OrgID < sorted[j].OrgID
+		}
+		return sorted[i].Day.Before(sorted[j].Day)
+	})
+
+	rows := make([]interface{}, 0, len(sorted))
+	for _, u := range sorted {
+		rows = append(rows, goqu.Record{
+			"org_id":          u.OrgID,
+			"day":             usage.DayOf(u.Day).Format(dayFormat),
+			"api_calls":       u.APICalls,
+			"relation_writes": u.RelationWrites,
+		})
+	}
+
+	q, err := toSQL(dialect.Insert(TABLE_ORGANIZATION_USAGE).Rows(rows...).OnConflict(goqu.DoUpdate("org_id, day", goqu.Record{
+		"api_calls":       goqu.L(TABLE_ORGANIZATION_USAGE + ".api_calls + excluded.api_calls"),
+		"relation_writes": goqu.L(TABLE_ORGANIZATION_USAGE + ".relation_writes + excluded.relation_writes"),
+	})))
+	if err != nil {
+		return err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_ORGANIZATION_USAGE, "Add", q); err != nil {
+		return fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return nil
+}
+
+func (r OrganizationUsageRepository) List(ctx context.Context, orgID string, since time.Time) ([]usage.Usage, error) {
+	q, err := toSQL(dialect.From(TABLE_ORGANIZATION_USAGE).Where(
+		goqu.Ex{"org_id": orgID},
+		goqu.C("day").Gte(usage.DayOf(since).Format(dayFormat)),
+	).Order(goqu.C("day").Asc()))
+	if err != nil {
+		return []usage.Usage{}, err
+	}
+
+	var usageModels []OrganizationUsage
+	if err = list(ctx, r.dbc, TABLE_ORGANIZATION_USAGE, "List", &usageModels, q); err != nil {
+		return []usage.Usage{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	usages := make([]usage.Usage, 0, len(usageModels))
+	for _, u := range usageModels {
+		transformedUsage, err := u.transformToUsage()
+		if err != nil {
+			return []usage.Usage{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		usages = append(usages, transformedUsage)
+	}
+	return usages, nil
+}
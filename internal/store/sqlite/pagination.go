@@ -0,0 +1,21 @@
+package sqlite
+
+import (
+	"github.com/doug-martin/goqu/v9"
+)
+
+// paginate limits the query to a page of the results, rows are ordered by
+// creation so the pages are stable while new rows are being inserted. A
+// limit below 1 returns every row
+func paginate(ds *goqu.SelectDataset, limit, page int32) *goqu.SelectDataset {
+	if limit < 1 {
+		return ds
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	return ds.Order(goqu.C("created_at").Asc(), goqu.C("id").Asc()).
+		Limit(uint(limit)).
+		Offset(uint((page - 1) * limit))
+}
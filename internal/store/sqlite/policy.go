@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/policy"
+)
+
+// Policy is a policy with the names of its role, action and namespace
+type Policy struct {
+	ID            string         `db:"id"`
+	RoleID        string         `db:"role_id"`
+	RoleName      string         `db:"role_name"`
+	NamespaceID   string         `db:"namespace_id"`
+	NamespaceName string         `db:"namespace_name"`
+	ActionID      string         `db:"action_id"`
+	ActionName    string         `db:"action_name"`
+	Name          sql.NullString `db:"name"`
+	Description   sql.NullString `db:"description"`
+	Effect        string         `db:"effect"`
+	Condition     string         `db:"condition"`
+	Version       int64          `db:"version"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+func (from Policy) transformToPolicy() policy.Policy {
+	return policy.Policy{
+		ID:          from.ID,
+		RoleID:      from.RoleID,
+		ActionID:    from.ActionID,
+		NamespaceID: from.NamespaceID,
+		Name:        from.Name.String,
+		Description: from.Description.String,
+		Effect:      from.Effect,
+		Condition:   from.Condition,
+		Version:     from.Version,
+		CreatedAt:   from.CreatedAt,
+		UpdatedAt:   from.UpdatedAt,
+	}
+}
+
+func (from Policy) transformToExpandedPolicy() policy.ExpandedPolicy {
+	return policy.ExpandedPolicy{
+		Policy:        from.transformToPolicy(),
+		RoleName:      from.RoleName,
+		ActionName:    from.ActionName,
+		NamespaceName: from.NamespaceName,
+	}
+}
@@ -0,0 +1,330 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type PolicyRepository struct {
+	dbc         *db.Client
+	queryLogger QueryLogger
+}
+
+func NewPolicyRepository(dbc *db.Client) *PolicyRepository {
+	return &PolicyRepository{
+		dbc:         dbc,
+		queryLogger: noopQueryLogger,
+	}
+}
+
+// WithQueryLogger returns a copy of the repository reporting
+// every query it runs to logger
+func (r PolicyRepository) WithQueryLogger(logger QueryLogger) *PolicyRepository {
+	if logger == nil {
+		logger = noopQueryLogger
+	}
+	r.queryLogger = logger
+	return &r
+}
+
+func (r PolicyRepository) logQuery(ctx context.Context, q sqlQuery, start time.Time) {
+	r.queryLogger(ctx, q.query, redactArgs(q.params), time.Since(start))
+}
+
+func (r PolicyRepository) buildListQuery() *goqu.SelectDataset {
+	selectStatement := dialect.Select(
+		"p.id",
+		"p.role_id",
+		"p.namespace_id",
+		"p.action_id",
+		"p.name",
+		"p.description",
+		"p.effect",
+		"p.condition",
+		"p.version",
+		"p.created_at",
+		"p.updated_at",
+		goqu.I("roles.name").As("role_name"),
+		goqu.I("namespaces.name").As("namespace_name"),
+		goqu.I("actions.name").As("action_name"),
+	).From(goqu.T(TABLE_POLICIES).As("p"))
+
+	return selectStatement.Join(goqu.T(TABLE_ROLES), goqu.On(
+		goqu.I("roles.id").Eq(goqu.I("p.role_id")),
+	)).Join(goqu.T(TABLE_ACTIONS), goqu.On(
+		goqu.I("actions.id").Eq(goqu.I("p.action_id")),
+	)).Join(goqu.T(TABLE_NAMESPACES), goqu.On(
+		goqu.I("namespaces.id").Eq(goqu.I("p.namespace_id")),
+	))
+}
+
+func (r PolicyRepository) Get(ctx context.Context, id string) (policy.Policy, error) {
+	if strings.TrimSpace(id) == "" {
+		return policy.Policy{}, policy.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return policy.Policy{}, policy.ErrInvalidUUID
+	}
+
+	q, err := toSQL(r.buildListQuery().
+		Where(
+			goqu.Ex{
+				"p.id": id,
+			},
+		))
+	if err != nil {
+		return policy.Policy{}, err
+	}
+
+	var policyModel Policy
+	defer r.logQuery(ctx, q, time.Now())
+	if err = get(ctx, r.dbc, TABLE_POLICIES, "Get", &policyModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return policy.Policy{}, policy.ErrNotExist
+		}
+		return policy.Policy{}, err
+	}
+
+	return policyModel.transformToPolicy(), nil
+}
+
+func (r PolicyRepository) List(ctx context.Context, flt policy.Filters) ([]policy.Policy, error) {
+	fetchedPolicies, err := r.list(ctx, flt, "List")
+	if err != nil {
+		return []policy.Policy{}, err
+	}
+
+	var transformedPolicies []policy.Policy
+	for _, p := range fetchedPolicies {
+		transformedPolicies = append(transformedPolicies, p.transformToPolicy())
+	}
+
+	return transformedPolicies, nil
+}
+
+// ListExpanded returns the policies with the names of their role, action and
+// namespace, they are part of the list query joins so no extra query is made
+func (r PolicyRepository) ListExpanded(ctx context.Context, flt policy.Filters) ([]policy.ExpandedPolicy, error) {
+	fetchedPolicies, err := r.list(ctx, flt, "ListExpanded")
+	if err != nil {
+		return []policy.ExpandedPolicy{}, err
+	}
+
+	var transformedPolicies []policy.ExpandedPolicy
+	for _, p := range fetchedPolicies {
+		transformedPolicies = append(transformedPolicies, p.transformToExpandedPolicy())
+	}
+
+	return transformedPolicies, nil
+}
+
+func (r PolicyRepository) list(ctx context.Context, flt policy.Filters, operation string) ([]Policy, error) {
+	sqlStatement := r.buildListQuery()
+	if flt.NamespaceID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"p.namespace_id": flt.NamespaceID})
+	}
+	q, err := toSQL(sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+
+	var fetchedPolicies []Policy
+	defer r.logQuery(ctx, q, time.Now())
+	if err = list(ctx, r.dbc, TABLE_POLICIES, operation, &fetchedPolicies, q); err != nil {
+		return nil, err
+	}
+
+	return fetchedPolicies, nil
+}
+
+// createPolicyQueries are the upsert of the policy and the read of its id
+func createPolicyQueries(pol policy.Policy) (sqlQuery, sqlQuery, error) {
+	roleID := pol.RoleID
+	actionID := pol.ActionID
+	nsID := pol.NamespaceID
+
+	effect := pol.Effect
+	if effect == "" {
+		effect = policy.EffectAllow
+	}
+
+	createdAt := now()
+	upsert, err := toSQL(dialect.Insert(TABLE_POLICIES).Rows(
+		goqu.Record{
+			"id":           uuid.NewString(),
+			"namespace_id": nsID,
+			"role_id":      roleID,
+			"action_id":    sql.NullString{String: actionID, Valid: actionID != ""},
+			"name":         sql.NullString{String: pol.Name, Valid: pol.Name != ""},
+			"description":  sql.NullString{String: pol.Description, Valid: pol.Description != ""},
+			"effect":       effect,
+			"condition":    pol.Condition,
+			"created_at":   createdAt,
+			"updated_at":   createdAt,
+		}).OnConflict(goqu.DoUpdate("role_id, namespace_id, action_id", goqu.Record{
+		"namespace_id": nsID,
+	})))
+	if err != nil {
+		return sqlQuery{}, sqlQuery{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_POLICIES).Select("id").Where(goqu.Ex{
+		"role_id":      roleID,
+		"namespace_id": nsID,
+		"action_id":    actionID,
+	}))
+	if err != nil {
+		return sqlQuery{}, sqlQuery{}, err
+	}
+	return upsert, read, nil
+}
+
+// Create adds the policy or, when the role already has a policy for the
+// action on the namespace, returns the id of that one
+func (r PolicyRepository) Create(ctx context.Context, pol policy.Policy) (string, error) {
+	if strings.TrimSpace(pol.ActionID) == "" {
+		return "", policy.ErrInvalidDetail
+	}
+
+	upsert, read, err := createPolicyQueries(pol)
+	if err != nil {
+		return "", err
+	}
+
+	var policyID string
+	defer r.logQuery(ctx, upsert, time.Now())
+	if err = updateRow(ctx, r.dbc, TABLE_POLICIES, "Create", &policyID, upsert, read); err != nil {
+		if errors.Is(err, errForeignKeyViolation) {
+			return "", fmt.Errorf("%w: %s", policy.ErrInvalidDetail, err)
+		}
+		return "", fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return policyID, nil
+}
+
+// CreateBatch creates the policies like Create in a single transaction,
+// every policy in a savepoint so the ones refused for an unknown role,
+// action or namespace are reported without rolling the others back
+func (r PolicyRepository) CreateBatch(ctx context.Context, policies []policy.Policy) ([]policy.Policy, []policy.BatchFailure, error) {
+	var created []policy.Policy
+	var failures []policy.BatchFailure
+	if err := r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		for i, pol := range policies {
+			if strings.TrimSpace(pol.ActionID) == "" {
+				failures = append(failures, policy.BatchFailure{Index: i, Err: policy.ErrInvalidDetail})
+				continue
+			}
+			upsert, read, err := createPolicyQueries(pol)
+			if err != nil {
+				return err
+			}
+
+			err = run(ctx, r.dbc, TABLE_POLICIES, "CreateBatch", func(ctx context.Context) error {
+				defer r.logQuery(ctx, upsert, time.Now())
+				return withSavepoint(ctx, tx, func() error {
+					if _, err := tx.ExecContext(ctx, upsert.query, upsert.params...); err != nil {
+						return checkSQLiteError(err)
+					}
+					return checkSQLiteError(tx.GetContext(ctx, &pol.ID, read.query, read.params...))
+				})
+			})
+			switch {
+			case err == nil:
+				created = append(created, pol)
+			case errors.Is(err, errForeignKeyViolation):
+				failures = append(failures, policy.BatchFailure{Index: i, Err: fmt.Errorf("%w: %s", policy.ErrInvalidDetail, err)})
+			default:
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return created, failures, nil
+}
+
+func (r PolicyRepository) Update(ctx context.Context, toUpdate policy.Policy) (string, error) {
+	if strings.TrimSpace(toUpdate.ID) == "" {
+		return "", policy.ErrInvalidID
+	}
+	if !uuid.IsValid(toUpdate.ID) {
+		return "", policy.ErrInvalidUUID
+	}
+
+	if strings.TrimSpace(toUpdate.ActionID) == "" {
+		return "", policy.ErrInvalidDetail
+	}
+
+	current := goqu.Ex{
+		"id": toUpdate.ID,
+	}
+	record, where := versioned(goqu.Record{
+		"namespace_id": toUpdate.NamespaceID,
+		"role_id":      toUpdate.RoleID,
+		"action_id":    sql.NullString{String: toUpdate.ActionID, Valid: toUpdate.ActionID != ""},
+		"name":         sql.NullString{String: toUpdate.Name, Valid: toUpdate.Name != ""},
+		"description":  sql.NullString{String: toUpdate.Description, Valid: toUpdate.Description != ""},
+		"effect":       toUpdate.Effect,
+		"condition":    toUpdate.Condition,
+		"updated_at":   now(),
+	}, current, toUpdate.Version)
+	q, err := toSQL(dialect.Update(TABLE_POLICIES).Set(record).Where(where))
+	if err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	err = execAffectingRow(ctx, r.dbc, TABLE_POLICIES, "Update", q)
+	r.logQuery(ctx, q, start)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_POLICIES, current, toUpdate.Version), errVersionMismatch) {
+				return "", policy.ErrVersionMismatch
+			}
+			return "", policy.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return "", policy.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return "", namespace.ErrNotExist
+		default:
+			return "", err
+		}
+	}
+
+	return toUpdate.ID, nil
+}
+
+func (r PolicyRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return policy.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return policy.ErrInvalidUUID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_POLICIES, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return policy.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return policy.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/policy"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyRepository(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	bootstrapRole(t, client, "compute/instance", "compute/instance:viewer")
+	exec(t, client,
+		`INSERT INTO actions (id, name, namespace_id) VALUES ('compute/instance.get', 'get', 'compute/instance')`,
+		`INSERT INTO actions (id, name, namespace_id) VALUES ('compute/instance.list', 'list', 'compute/instance')`,
+	)
+	repository := sqlite.NewPolicyRepository(client)
+
+	id, err := repository.Create(ctx, policy.Policy{
+		RoleID:      "compute/instance:viewer",
+		NamespaceID: "compute/instance",
+		ActionID:    "compute/instance.get",
+	})
+	require.NoError(t, err)
+
+	t.Run("should return the existing policy when created again", func(t *testing.T) {
+		again, err := repository.Create(ctx, policy.Policy{
+			RoleID:      "compute/instance:viewer",
+			NamespaceID: "compute/instance",
+			ActionID:    "compute/instance.get",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, id, again)
+	})
+
+	t.Run("should refuse a policy of an unknown action", func(t *testing.T) {
+		_, err := repository.Create(ctx, policy.Policy{
+			RoleID:      "compute/instance:viewer",
+			NamespaceID: "compute/instance",
+			ActionID:    "compute/instance.delete",
+		})
+		assert.ErrorIs(t, err, policy.ErrInvalidDetail)
+	})
+
+	t.Run("should create a batch of policies and report the refused ones", func(t *testing.T) {
+		created, failures, err := repository.CreateBatch(ctx, []policy.Policy{
+			{RoleID: "compute/instance:viewer", NamespaceID: "compute/instance", ActionID: "compute/instance.get"},
+			{RoleID: "compute/instance:viewer", NamespaceID: "compute/instance", ActionID: "compute/instance.delete"},
+			{RoleID: "compute/instance:viewer", NamespaceID: "compute/instance"},
+		})
+		require.NoError(t, err)
+		require.Len(t, created, 1)
+		assert.Equal(t, id, created[0].ID)
+		require.Len(t, failures, 2)
+		assert.Equal(t, 1, failures[0].Index)
+		assert.ErrorIs(t, failures[0].Err, policy.ErrInvalidDetail)
+		assert.Equal(t, 2, failures[1].Index)
+	})
+
+	t.Run("should get and list policies", func(t *testing.T) {
+		got, err := repository.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, policy.EffectAllow, got.Effect)
+		assert.Equal(t, "compute/instance.get", got.ActionID)
+
+		expanded, err := repository.ListExpanded(ctx, policy.Filters{NamespaceID: "compute/instance"})
+		require.NoError(t, err)
+		require.Len(t, expanded, 1)
+		assert.Equal(t, "get", expanded[0].ActionName)
+		assert.Equal(t, "compute/instance:viewer", expanded[0].RoleName)
+
+		policies, err := repository.List(ctx, policy.Filters{NamespaceID: "shield/project"})
+		require.NoError(t, err)
+		assert.Empty(t, policies)
+	})
+
+	t.Run("should update and delete a policy", func(t *testing.T) {
+		updated, err := repository.Update(ctx, policy.Policy{
+			ID:          id,
+			RoleID:      "compute/instance:viewer",
+			NamespaceID: "compute/instance",
+			ActionID:    "compute/instance.list",
+			Effect:      policy.EffectDeny,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, id, updated)
+
+		got, err := repository.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, policy.EffectDeny, got.Effect)
+
+		_, err = repository.Update(ctx, policy.Policy{
+			ID:          id,
+			RoleID:      "compute/instance:viewer",
+			NamespaceID: "compute/instance",
+			ActionID:    "compute/instance.get",
+			Version:     got.Version - 1,
+		})
+		assert.ErrorIs(t, err, policy.ErrVersionMismatch)
+
+		require.NoError(t, repository.Delete(ctx, id))
+		_, err = repository.Get(ctx, id)
+		assert.ErrorIs(t, err, policy.ErrNotExist)
+		_, err = repository.Update(ctx, policy.Policy{ID: id, ActionID: "compute/instance.list"})
+		assert.ErrorIs(t, err, policy.ErrNotExist)
+	})
+}
@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/project"
+)
+
+type Project struct {
+	ID        string       `db:"id"`
+	Name      string       `db:"name"`
+	Slug      string       `db:"slug"`
+	OrgID     string       `db:"org_id"`
+	Metadata  []byte       `db:"metadata"`
+	Version   int64        `db:"version"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+	DeletedAt sql.NullTime `db:"deleted_at"`
+}
+
+func (from Project) transformToProject() (project.Project, error) {
+	unmarshalledMetadata, err := unmarshalMetadata(from.Metadata)
+	if err != nil {
+		return project.Project{}, err
+	}
+
+	return project.Project{
+		ID:           from.ID,
+		Name:         from.Name,
+		Slug:         from.Slug,
+		Organization: organization.Organization{ID: from.OrgID},
+		Metadata:     unmarshalledMetadata,
+		Version:      from.Version,
+		CreatedAt:    from.CreatedAt,
+		UpdatedAt:    from.UpdatedAt,
+		DeletedAt:    from.DeletedAt.Time,
+	}, nil
+}
@@ -0,0 +1,441 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+	"github.com/odpf/shield/core/namespace"
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type ProjectRepository struct {
+	dbc *db.Client
+}
+
+func NewProjectRepository(dbc *db.Client) *ProjectRepository {
+	return &ProjectRepository{
+		dbc: dbc,
+	}
+}
+
+func (r ProjectRepository) GetByID(ctx context.Context, id string) (project.Project, error) {
+	if strings.TrimSpace(id) == "" {
+		return project.Project{}, project.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return project.Project{}, project.ErrInvalidUUID
+	}
+
+	return r.get(ctx, "GetByID", goqu.Ex{"id": id})
+}
+
+func (r ProjectRepository) GetBySlug(ctx context.Context, slug string) (project.Project, error) {
+	if strings.TrimSpace(slug) == "" {
+		return project.Project{}, project.ErrInvalidID
+	}
+
+	return r.get(ctx, "GetBySlug", goqu.Ex{"slug": slug})
+}
+
+func (r ProjectRepository) get(ctx context.Context, operation string, where goqu.Ex) (project.Project, error) {
+	q, err := toSQL(dialect.From(TABLE_PROJECTS).Where(where))
+	if err != nil {
+		return project.Project{}, err
+	}
+
+	var projectModel Project
+	if err = get(ctx, r.dbc, TABLE_PROJECTS, operation, &projectModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return project.Project{}, project.ErrNotExist
+		}
+		return project.Project{}, err
+	}
+
+	transformedProject, err := projectModel.transformToProject()
+	if err != nil {
+		return project.Project{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return transformedProject, nil
+}
+
+func (r ProjectRepository) Create(ctx context.Context, prj project.Project) (project.Project, error) {
+	if strings.TrimSpace(prj.Name) == "" || strings.TrimSpace(prj.Slug) == "" {
+		return project.Project{}, project.ErrInvalidDetail
+	}
+	if !uuid.IsValid(prj.Organization.ID) {
+		return project.Project{}, organization.ErrInvalidUUID
+	}
+
+	marshaledMetadata, err := marshalMetadata(prj.Metadata)
+	if err != nil {
+		return project.Project{}, err
+	}
+
+	createdAt := now()
+	projectModel := Project{
+		ID:        uuid.NewString(),
+		Name:      prj.Name,
+		Slug:      prj.Slug,
+		OrgID:     prj.Organization.ID,
+		Metadata:  []byte(marshaledMetadata),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	q, err := toSQL(dialect.Insert(TABLE_PROJECTS).Rows(
+		goqu.Record{
+			"id":         projectModel.ID,
+			"name":       projectModel.Name,
+			"slug":       projectModel.Slug,
+			"org_id":     projectModel.OrgID,
+			"metadata":   marshaledMetadata,
+			"created_at": projectModel.CreatedAt,
+			"updated_at": projectModel.UpdatedAt,
+		}))
+	if err != nil {
+		return project.Project{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_PROJECTS, "Create", q); err != nil {
+		switch {
+		case errors.Is(err, errForeignKeyViolation):
+			return project.Project{}, project.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return project.Project{}, project.ErrConflict
+		default:
+			return project.Project{}, err
+		}
+	}
+
+	transformedProj, err := projectModel.transformToProject()
+	if err != nil {
+		return project.Project{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return transformedProj, nil
+}
+
+func (r ProjectRepository) List(ctx context.Context, flt project.Filter) ([]project.Project, error) {
+	sqlStatement := dialect.From(TABLE_PROJECTS).Where(
+		globExpressions(map[string]string{"name": flt.Name, "slug": flt.Slug})...,
+	).Where(
+		metadataGlobExpressions("metadata", flt.Metadata)...,
+	)
+	if !flt.IncludeDeleted {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"deleted_at": nil})
+	}
+
+	q, err := toSQL(paginate(sqlStatement, flt.Limit, flt.Page))
+	if err != nil {
+		return []project.Project{}, err
+	}
+
+	var projectModels []Project
+	if err = list(ctx, r.dbc, TABLE_PROJECTS, "List", &projectModels, q); err != nil {
+		return []project.Project{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedProjects []project.Project
+	for _, p := range projectModels {
+		transformedProj, err := p.transformToProject()
+		if err != nil {
+			return []project.Project{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+
+		transformedProjects = append(transformedProjects, transformedProj)
+	}
+
+	return transformedProjects, nil
+}
+
+func (r ProjectRepository) UpdateByID(ctx context.Context, prj project.Project) (project.Project, error) {
+	if strings.TrimSpace(prj.ID) == "" {
+		return project.Project{}, project.ErrInvalidID
+	}
+	if !uuid.IsValid(prj.ID) {
+		return project.Project{}, project.ErrInvalidUUID
+	}
+
+	if strings.TrimSpace(prj.Name) == "" || strings.TrimSpace(prj.Slug) == "" {
+		return project.Project{}, project.ErrInvalidDetail
+	}
+
+	return r.update(ctx, "UpdateByID", prj, goqu.Ex{"id": prj.ID})
+}
+
+func (r ProjectRepository) UpdateBySlug(ctx context.Context, prj project.Project) (project.Project, error) {
+	if strings.TrimSpace(prj.Slug) == "" {
+		return project.Project{}, project.ErrInvalidID
+	}
+
+	if strings.TrimSpace(prj.Name) == "" {
+		return project.Project{}, project.ErrInvalidDetail
+	}
+
+	return r.update(ctx, "UpdateBySlug", prj, goqu.Ex{"slug": prj.Slug})
+}
+
+// update sets the details of the project matching the condition and reads
+// it back, by the slug it is updated to
+func (r ProjectRepository) update(ctx context.Context, operation string, prj project.Project, where goqu.Ex) (project.Project, error) {
+	marshaledMetadata, err := marshalMetadata(prj.Metadata)
+	if err != nil {
+		return project.Project{}, err
+	}
+
+	where["deleted_at"] = nil
+	record, conditioned := versioned(goqu.Record{
+		"name":       prj.Name,
+		"slug":       prj.Slug,
+		"org_id":     prj.Organization.ID,
+		"metadata":   marshaledMetadata,
+		"updated_at": now(),
+	}, where, prj.Version)
+	update, err := toSQL(dialect.Update(TABLE_PROJECTS).Set(record).Where(conditioned))
+	if err != nil {
+		return project.Project{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_PROJECTS).Where(goqu.Ex{"slug": prj.Slug}))
+	if err != nil {
+		return project.Project{}, err
+	}
+
+	var projectModel Project
+	if err = updateRow(ctx, r.dbc, TABLE_PROJECTS, operation, &projectModel, update, read); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if errors.Is(checkVersion(ctx, r.dbc, TABLE_PROJECTS, where, prj.Version), errVersionMismatch) {
+				return project.Project{}, project.ErrVersionMismatch
+			}
+			return project.Project{}, project.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return project.Project{}, project.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return project.Project{}, organization.ErrNotExist
+		default:
+			return project.Project{}, fmt.Errorf("%w: %s", dbErr, err)
+		}
+	}
+
+	prj, err = projectModel.transformToProject()
+	if err != nil {
+		return project.Project{}, fmt.Errorf("%s: %w", parseErr, err)
+	}
+
+	return prj, nil
+}
+
+func (r ProjectRepository) ListAdmins(ctx context.Context, projectID string) ([]user.User, error) {
+	q, err := toSQL(dialect.Select(
+		goqu.I("u.id").As("id"),
+		goqu.I("u.name").As("name"),
+		goqu.I("u.email").As("email"),
+		goqu.I("u.created_at").As("created_at"),
+		goqu.I("u.updated_at").As("updated_at"),
+		goqu.I("u.disabled_at").As("disabled_at"),
+	).
+		From(goqu.T(TABLE_RELATIONS).As("r")).Join(
+		goqu.T(TABLE_USERS).As("u"), goqu.On(
+			goqu.I("u.id").Eq(goqu.I("r.subject_id")),
+		)).Where(goqu.Ex{
+		"r.object_id":            projectID,
+		"r.role_id":              schema.GetRoleID(schema.ProjectNamespace, schema.OwnerRole),
+		"r.subject_namespace_id": namespace.DefinitionUser.ID,
+		"r.object_namespace_id":  namespace.DefinitionProject.ID,
+	}))
+	if err != nil {
+		return []user.User{}, err
+	}
+
+	var fetchedUsers []User
+	if err = list(ctx, r.dbc, TABLE_PROJECTS, "ListAdmins", &fetchedUsers, q); err != nil {
+		return []user.User{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedUsers []user.User
+	for _, u := range fetchedUsers {
+		transformedUsers = append(transformedUsers, u.transformToUser())
+	}
+
+	return transformedUsers, nil
+}
+
+func (r ProjectRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return project.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return project.ErrInvalidUUID
+	}
+
+	if err := softDeleteByID(ctx, r.dbc, TABLE_PROJECTS, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return project.ErrNotExist
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r ProjectRepository) Restore(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return project.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return project.ErrInvalidUUID
+	}
+
+	if err := restoreByID(ctx, r.dbc, TABLE_PROJECTS, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return project.ErrNotExist
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r ProjectRepository) ListDeleted(ctx context.Context, deletedBefore time.Time) ([]project.Project, error) {
+	q, err := toSQL(dialect.From(TABLE_PROJECTS).Where(
+		goqu.C("deleted_at").Lt(deletedBefore),
+	).Order(goqu.C("deleted_at").Asc()))
+	if err != nil {
+		return []project.Project{}, err
+	}
+
+	var projectModels []Project
+	if err = list(ctx, r.dbc, TABLE_PROJECTS, "ListDeleted", &projectModels, q); err != nil {
+		return []project.Project{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var deleted []project.Project
+	for _, m := range projectModels {
+		transformed, err := m.transformToProject()
+		if err != nil {
+			return []project.Project{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		deleted = append(deleted, transformed)
+	}
+
+	return deleted, nil
+}
+
+// Purge permanently removes the project along with its resources and the
+// relations on the project and its resources in a single transaction, only
+// a deleted project can be purged. The removed resources are returned so
+// their tuples can be removed from the authz store as well, they are read
+// before being removed as sqlite can't return the deleted rows
+func (r ProjectRepository) Purge(ctx context.Context, id string) ([]relation.Object, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, project.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return nil, project.ErrInvalidUUID
+	}
+
+	resourceIDs := dialect.From(TABLE_RESOURCES).Select("id").Where(goqu.Ex{"project_id": id})
+	folderIDs := dialect.From(TABLE_FOLDERS).Select("id").Where(goqu.Ex{"project_id": id})
+	relationsQuery, err := toSQL(dialect.Delete(TABLE_RELATIONS).Where(goqu.Or(
+		goqu.C("object_id").Eq(id),
+		goqu.C("subject_id").Eq(id),
+		goqu.C("object_id").In(resourceIDs),
+		goqu.C("subject_id").In(resourceIDs),
+		goqu.C("object_id").In(folderIDs),
+		goqu.C("subject_id").In(folderIDs),
+	)))
+	if err != nil {
+		return nil, err
+	}
+	resourcesQuery, err := toSQL(dialect.From(TABLE_RESOURCES).Select("id", "namespace_id").Where(goqu.Ex{
+		"project_id": id,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	foldersQuery, err := toSQL(folderIDs)
+	if err != nil {
+		return nil, err
+	}
+	deleteResourcesQuery, err := toSQL(dialect.Delete(TABLE_RESOURCES).Where(goqu.Ex{
+		"project_id": id,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	deleteFoldersQuery, err := toSQL(dialect.Delete(TABLE_FOLDERS).Where(goqu.Ex{
+		"project_id": id,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	projectQuery, err := toSQL(dialect.Delete(TABLE_PROJECTS).Where(
+		goqu.Ex{"id": id},
+		goqu.C("deleted_at").IsNotNull(),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []relation.Object
+	if err := r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return run(ctx, r.dbc, TABLE_PROJECTS, "Purge", func(ctx context.Context) error {
+			var resourceModels []Resource
+			if err := tx.SelectContext(ctx, &resourceModels, resourcesQuery.query, resourcesQuery.params...); err != nil {
+				return checkSQLiteError(err)
+			}
+			var folderIDs []string
+			if err := tx.SelectContext(ctx, &folderIDs, foldersQuery.query, foldersQuery.params...); err != nil {
+				return checkSQLiteError(err)
+			}
+
+			for _, q := range []sqlQuery{relationsQuery, deleteResourcesQuery, deleteFoldersQuery} {
+				if _, err := tx.ExecContext(ctx, q.query, q.params...); err != nil {
+					return checkSQLiteError(err)
+				}
+			}
+
+			result, err := tx.ExecContext(ctx, projectQuery.query, projectQuery.params...)
+			if err != nil {
+				return checkSQLiteError(err)
+			}
+			if count, err := result.RowsAffected(); err != nil {
+				return err
+			} else if count == 0 {
+				return sql.ErrNoRows
+			}
+
+			resources = nil
+			for _, m := range resourceModels {
+				resources = append(resources, relation.Object{ID: m.ID, NamespaceID: m.NamespaceID.String})
+			}
+			for _, folderID := range folderIDs {
+				resources = append(resources, relation.Object{ID: folderID, NamespaceID: schema.FolderNamespace})
+			}
+			return nil
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, project.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return nil, project.ErrInUse
+		default:
+			return nil, err
+		}
+	}
+
+	return resources, nil
+}
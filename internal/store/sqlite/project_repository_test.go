@@ -0,0 +1,118 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectRepository(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	org := bootstrapOrganization(t, client, "acme")
+	repository := sqlite.NewProjectRepository(client)
+
+	created, err := repository.Create(ctx, project.Project{
+		Name:         "Billing",
+		Slug:         "billing",
+		Organization: org,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, org.ID, created.Organization.ID)
+
+	t.Run("should get a project by id and slug", func(t *testing.T) {
+		got, err := repository.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "billing", got.Slug)
+
+		got, err = repository.GetBySlug(ctx, "billing")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, got.ID)
+
+		_, err = repository.GetBySlug(ctx, "missing")
+		assert.ErrorIs(t, err, project.ErrNotExist)
+	})
+
+	t.Run("should refuse a project of an unknown organization", func(t *testing.T) {
+		_, err := repository.Create(ctx, project.Project{
+			Name:         "Orphan",
+			Slug:         "orphan",
+			Organization: organization.Organization{ID: "00000000-0000-0000-0000-000000000000"},
+		})
+		assert.ErrorIs(t, err, project.ErrNotExist)
+		_, err = repository.Create(ctx, project.Project{Name: "Orphan", Slug: "orphan"})
+		assert.ErrorIs(t, err, organization.ErrInvalidUUID)
+	})
+
+	t.Run("should update a project", func(t *testing.T) {
+		updated, err := repository.UpdateByID(ctx, project.Project{
+			ID:           created.ID,
+			Name:         "Billing v2",
+			Slug:         "billing",
+			Organization: org,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Billing v2", updated.Name)
+
+		other, err := repository.Create(ctx, project.Project{Name: "Search", Slug: "search", Organization: org})
+		require.NoError(t, err)
+		_, err = repository.UpdateByID(ctx, project.Project{ID: other.ID, Name: "Search", Slug: "billing", Organization: org})
+		assert.ErrorIs(t, err, project.ErrConflict)
+
+		projects, err := repository.List(ctx, project.Filter{Name: "billing*"})
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, created.ID, projects[0].ID)
+	})
+
+	t.Run("should purge a deleted project with its resources", func(t *testing.T) {
+		prj, err := repository.Create(ctx, project.Project{Name: "Gone", Slug: "gone", Organization: org})
+		require.NoError(t, err)
+		bootstrapNamespace(t, client, "compute/instance")
+		res, err := sqlite.NewResourceRepository(client).Create(ctx, resource.Resource{
+			URN:            "urn:compute:gone",
+			Name:           "gone",
+			ProjectID:      prj.ID,
+			OrganizationID: org.ID,
+			NamespaceID:    "compute/instance",
+		})
+		require.NoError(t, err)
+		bootstrapRelation(t, client, "shield/project", prj.ID, "compute/instance", res.Idxa, "compute/instance:project")
+
+		_, err = repository.Purge(ctx, prj.ID)
+		assert.ErrorIs(t, err, project.ErrNotExist)
+
+		require.NoError(t, repository.Delete(ctx, prj.ID))
+		purged, err := repository.Purge(ctx, prj.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []relation.Object{{ID: res.Idxa, NamespaceID: "compute/instance"}}, purged)
+
+		_, err = sqlite.NewResourceRepository(client).GetByID(ctx, res.Idxa)
+		assert.ErrorIs(t, err, resource.ErrNotExist)
+		var relations int
+		require.NoError(t, client.GetContext(ctx, &relations, `SELECT count(*) FROM relations WHERE object_id = ?`, res.Idxa))
+		assert.Zero(t, relations)
+	})
+}
+
+func TestProjectRepositoryListAdmins(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	org := bootstrapOrganization(t, client, "acme")
+	prj, err := sqlite.NewProjectRepository(client).Create(ctx, project.Project{Name: "Billing", Slug: "billing", Organization: org})
+	require.NoError(t, err)
+	admin := bootstrapUser(t, client, "admin@acme.io")
+	bootstrapRelation(t, client, "shield/user", admin.ID, "shield/project", prj.ID, "shield/project:owner")
+
+	admins, err := sqlite.NewProjectRepository(client).ListAdmins(ctx, prj.ID)
+	require.NoError(t, err)
+	require.Len(t, admins, 1)
+	assert.Equal(t, admin.ID, admins[0].ID)
+}
@@ -0,0 +1,243 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+	"github.com/odpf/shield/core/relation"
+	"github.com/odpf/shield/internal/schema"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type RelationRepository struct {
+	dbc *db.Client
+}
+
+func NewRelationRepository(dbc *db.Client) *RelationRepository {
+	return &RelationRepository{
+		dbc: dbc,
+	}
+}
+
+// createRelationQueries are the upsert of the relation and the read of it,
+// the relation already made is kept and returned
+func createRelationQueries(relationToCreate relation.RelationV2) (sqlQuery, sqlQuery, error) {
+	fields := goqu.Ex{
+		"subject_namespace_id": relationToCreate.Subject.Namespace,
+		"subject_id":           relationToCreate.Subject.ID,
+		"object_namespace_id":  relationToCreate.Object.NamespaceID,
+		"object_id":            relationToCreate.Object.ID,
+		"role_id":              schema.GetRoleID(relationToCreate.Object.NamespaceID, relationToCreate.Subject.RoleID),
+	}
+	createdAt := now()
+	record := goqu.Record{
+		"id":         uuid.NewString(),
+		"created_at": createdAt,
+		"updated_at": createdAt,
+	}
+	for k, v := range fields {
+		record[k] = v
+	}
+
+	upsert, err := toSQL(dialect.Insert(TABLE_RELATIONS).Rows(record).OnConflict(
+		goqu.DoUpdate("subject_namespace_id, subject_id, object_namespace_id, object_id, role_id", goqu.Record{
+			"subject_namespace_id": relationToCreate.Subject.Namespace,
+		})))
+	if err != nil {
+		return sqlQuery{}, sqlQuery{}, err
+	}
+	read, err := toSQL(dialect.Select(&Relation{}).From(TABLE_RELATIONS).Where(fields))
+	if err != nil {
+		return sqlQuery{}, sqlQuery{}, err
+	}
+	return upsert, read, nil
+}
+
+func (r RelationRepository) Create(ctx context.Context, relationToCreate relation.RelationV2) (relation.RelationV2, error) {
+	upsert, read, err := createRelationQueries(relationToCreate)
+	if err != nil {
+		return relation.RelationV2{}, err
+	}
+
+	var relationModel Relation
+	if err = updateRow(ctx, r.dbc, TABLE_RELATIONS, "Create", &relationModel, upsert, read); err != nil {
+		switch {
+		case errors.Is(err, errForeignKeyViolation):
+			return relation.RelationV2{}, fmt.Errorf("%w: %s", relation.ErrInvalidDetail, err)
+		default:
+			return relation.RelationV2{}, err
+		}
+	}
+
+	return relationModel.transformToRelationV2(), nil
+}
+
+// CreateBatch creates the relations in a single transaction, every relation
+// in a savepoint so the ones refused for an unknown namespace or role are
+// reported without rolling the others back
+func (r RelationRepository) CreateBatch(ctx context.Context, relations []relation.RelationV2) ([]relation.RelationV2, []relation.BatchFailure, error) {
+	var created []relation.RelationV2
+	var failures []relation.BatchFailure
+	if err := r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		for i, rel := range relations {
+			upsert, read, err := createRelationQueries(rel)
+			if err != nil {
+				return err
+			}
+
+			var relationModel Relation
+			err = run(ctx, r.dbc, TABLE_RELATIONS, "CreateBatch", func(ctx context.Context) error {
+				return withSavepoint(ctx, tx, func() error {
+					if _, err := tx.ExecContext(ctx, upsert.query, upsert.params...); err != nil {
+						return checkSQLiteError(err)
+					}
+					return checkSQLiteError(tx.GetContext(ctx, &relationModel, read.query, read.params...))
+				})
+			})
+			switch {
+			case err == nil:
+				created = append(created, relationModel.transformToRelationV2())
+			case errors.Is(err, errForeignKeyViolation):
+				failures = append(failures, relation.BatchFailure{Index: i, Err: fmt.Errorf("%w: %s", relation.ErrInvalidDetail, err)})
+			default:
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return created, failures, nil
+}
+
+func (r RelationRepository) List(ctx context.Context) ([]relation.RelationV2, error) {
+	q, err := toSQL(dialect.Select(&Relation{}).From(TABLE_RELATIONS))
+	if err != nil {
+		return []relation.RelationV2{}, err
+	}
+
+	var fetchedRelations []Relation
+	if err = list(ctx, r.dbc, TABLE_RELATIONS, "List", &fetchedRelations, q); err != nil {
+		return []relation.RelationV2{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedRelations []relation.RelationV2
+	for _, r := range fetchedRelations {
+		transformedRelations = append(transformedRelations, r.transformToRelationV2())
+	}
+
+	return transformedRelations, nil
+}
+
+func (r RelationRepository) Get(ctx context.Context, id string) (relation.RelationV2, error) {
+	if strings.TrimSpace(id) == "" {
+		return relation.RelationV2{}, relation.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return relation.RelationV2{}, relation.ErrInvalidUUID
+	}
+
+	q, err := toSQL(dialect.Select(&Relation{}).From(TABLE_RELATIONS).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return relation.RelationV2{}, err
+	}
+
+	var relationModel Relation
+	if err = get(ctx, r.dbc, TABLE_RELATIONS, "Get", &relationModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return relation.RelationV2{}, relation.ErrNotExist
+		}
+		return relation.RelationV2{}, err
+	}
+
+	return relationModel.transformToRelationV2(), nil
+}
+
+func (r RelationRepository) DeleteByID(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return relation.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return relation.ErrInvalidUUID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_RELATIONS, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return relation.ErrNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteBySubject removes the relations of the subject, the removed
+// relations are read in the transaction removing them and returned
+func (r RelationRepository) DeleteBySubject(ctx context.Context, sub relation.Subject) ([]relation.RelationV2, error) {
+	where := goqu.Ex{
+		"subject_namespace_id": sub.Namespace,
+		"subject_id":           sub.ID,
+	}
+	read, err := toSQL(dialect.Select(&Relation{}).From(TABLE_RELATIONS).Where(where))
+	if err != nil {
+		return nil, err
+	}
+	del, err := toSQL(dialect.Delete(TABLE_RELATIONS).Where(where))
+	if err != nil {
+		return nil, err
+	}
+
+	var deletedRelations []Relation
+	if err = r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return run(ctx, r.dbc, TABLE_RELATIONS, "DeleteBySubject", func(ctx context.Context) error {
+			if err := tx.SelectContext(ctx, &deletedRelations, read.query, read.params...); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, del.query, del.params...)
+			return checkSQLiteError(err)
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedRelations []relation.RelationV2
+	for _, r := range deletedRelations {
+		transformedRelations = append(transformedRelations, r.transformToRelationV2())
+	}
+	return transformedRelations, nil
+}
+
+// Update TO_DEPRECIATE
+func (r RelationRepository) Update(ctx context.Context, rel relation.Relation) (relation.Relation, error) {
+	return relation.Relation{}, nil
+}
+
+func (r RelationRepository) GetByFields(ctx context.Context, rel relation.RelationV2) (relation.RelationV2, error) {
+	q, err := toSQL(dialect.Select(&Relation{}).From(TABLE_RELATIONS).Where(
+		goqu.Ex{
+			"subject_id": rel.Subject.ID,
+			"object_id":  rel.Object.ID,
+		},
+		goqu.C("role_id").Like("%:"+rel.Subject.RoleID),
+	))
+	if err != nil {
+		return relation.RelationV2{}, err
+	}
+
+	var fetchedRelation Relation
+	if err = get(ctx, r.dbc, TABLE_RELATIONS, "GetByFields", &fetchedRelation, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return relation.RelationV2{}, relation.ErrNotExist
+		}
+		return relation.RelationV2{}, err
+	}
+
+	return fetchedRelation.transformToRelationV2(), nil
+}
@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/odpf/shield/core/resource"
+)
+
+type Resource struct {
+	ID             string         `db:"id"`
+	URN            string         `db:"urn"`
+	Name           string         `db:"name"`
+	ProjectID      sql.NullString `db:"project_id"`
+	OrganizationID sql.NullString `db:"org_id"`
+	NamespaceID    sql.NullString `db:"namespace_id"`
+	UserID         sql.NullString `db:"user_id"`
+	FolderID       sql.NullString `db:"folder_id"`
+	Tags           []byte         `db:"tags"`
+	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
+	DeletedAt      sql.NullTime   `db:"deleted_at"`
+}
+
+func (from Resource) transformToResource() (resource.Resource, error) {
+	tags := map[string]string{}
+	if len(from.Tags) > 0 {
+		if err := json.Unmarshal(from.Tags, &tags); err != nil {
+			return resource.Resource{}, err
+		}
+	}
+
+	return resource.Resource{
+		Idxa:           from.ID,
+		URN:            from.URN,
+		Name:           from.Name,
+		ProjectID:      from.ProjectID.String,
+		NamespaceID:    from.NamespaceID.String,
+		OrganizationID: from.OrganizationID.String,
+		UserID:         from.UserID.String,
+		FolderID:       from.FolderID.String,
+		Tags:           tags,
+		CreatedAt:      from.CreatedAt,
+		UpdatedAt:      from.UpdatedAt,
+	}, nil
+}
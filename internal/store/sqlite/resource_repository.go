@@ -0,0 +1,309 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type ResourceRepository struct {
+	dbc *db.Client
+}
+
+func NewResourceRepository(dbc *db.Client) *ResourceRepository {
+	return &ResourceRepository{
+		dbc: dbc,
+	}
+}
+
+// Create adds the resource or, when a resource with the urn exists, updates
+// it. Creating an existing resource again keeps its tags unless set.
+func (r ResourceRepository) Create(ctx context.Context, res resource.Resource) (resource.Resource, error) {
+	if strings.TrimSpace(res.URN) == "" {
+		return resource.Resource{}, resource.ErrInvalidURN
+	}
+	if !uuid.IsValid(res.ProjectID) || !uuid.IsValid(res.OrganizationID) ||
+		(res.UserID != "" && !uuid.IsValid(res.UserID)) {
+		return resource.Resource{}, resource.ErrInvalidUUID
+	}
+
+	userID := sql.NullString{String: res.UserID, Valid: res.UserID != ""}
+	tags, err := marshalTags(res.Tags)
+	if err != nil {
+		return resource.Resource{}, err
+	}
+
+	createdAt := now()
+	onConflict := goqu.Record{
+		"name":         res.Name,
+		"project_id":   res.ProjectID,
+		"org_id":       res.OrganizationID,
+		"namespace_id": res.NamespaceID,
+		"user_id":      userID,
+		"updated_at":   createdAt,
+	}
+	if res.Tags != nil {
+		onConflict["tags"] = tags
+	}
+
+	upsert, err := toSQL(dialect.Insert(TABLE_RESOURCES).Rows(
+		goqu.Record{
+			"id":           uuid.NewString(),
+			"urn":          res.URN,
+			"name":         res.Name,
+			"project_id":   res.ProjectID,
+			"org_id":       res.OrganizationID,
+			"namespace_id": res.NamespaceID,
+			"user_id":      userID,
+			"tags":         tags,
+			"created_at":   createdAt,
+			"updated_at":   createdAt,
+		}).OnConflict(goqu.DoUpdate("urn", onConflict)))
+	if err != nil {
+		return resource.Resource{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_RESOURCES).Where(goqu.Ex{"urn": res.URN}))
+	if err != nil {
+		return resource.Resource{}, err
+	}
+
+	var resourceModel Resource
+	if err = updateRow(ctx, r.dbc, TABLE_RESOURCES, "Create", &resourceModel, upsert, read); err != nil {
+		if errors.Is(err, errForeignKeyViolation) {
+			return resource.Resource{}, resource.ErrInvalidDetail
+		}
+		return resource.Resource{}, err
+	}
+
+	transformedResource, err := resourceModel.transformToResource()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedResource, nil
+}
+
+func (r ResourceRepository) List(ctx context.Context, flt resource.Filter) ([]resource.Resource, error) {
+	// resources aren't kept by group on sqlite, no resource belongs to one
+	if flt.GroupID != "" {
+		return []resource.Resource{}, nil
+	}
+
+	sqlStatement := dialect.From(TABLE_RESOURCES)
+	if len(flt.IDs) > 0 {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"id": flt.IDs})
+	}
+	if flt.ProjectID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"project_id": flt.ProjectID})
+	}
+	if flt.OrganizationID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"org_id": flt.OrganizationID})
+	}
+	if flt.NamespaceID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"namespace_id": flt.NamespaceID})
+	}
+	if flt.FolderID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"folder_id": flt.FolderID})
+	}
+	tagKeys := make([]string, 0, len(flt.Tags))
+	for key := range flt.Tags {
+		tagKeys = append(tagKeys, key)
+	}
+	sort.Strings(tagKeys)
+	for _, key := range tagKeys {
+		sqlStatement = sqlStatement.Where(
+			goqu.Func("json_extract", goqu.C("tags"), jsonPath(key)).Eq(flt.Tags[key]),
+		)
+	}
+	q, err := toSQL(sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+
+	var fetchedResources []Resource
+	if err = list(ctx, r.dbc, TABLE_RESOURCES, "List", &fetchedResources, q); err != nil {
+		return []resource.Resource{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedResources []resource.Resource
+	for _, r := range fetchedResources {
+		transformedResource, err := r.transformToResource()
+		if err != nil {
+			return []resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedResources = append(transformedResources, transformedResource)
+	}
+
+	return transformedResources, nil
+}
+
+func (r ResourceRepository) GetByID(ctx context.Context, id string) (resource.Resource, error) {
+	if strings.TrimSpace(id) == "" {
+		return resource.Resource{}, resource.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return resource.Resource{}, resource.ErrInvalidUUID
+	}
+
+	return r.get(ctx, "GetByID", goqu.Ex{"id": id})
+}
+
+func (r ResourceRepository) GetByURN(ctx context.Context, urn string) (resource.Resource, error) {
+	if strings.TrimSpace(urn) == "" {
+		return resource.Resource{}, resource.ErrInvalidURN
+	}
+
+	return r.get(ctx, "GetByURN", goqu.Ex{"urn": urn})
+}
+
+func (r ResourceRepository) GetByNamespace(ctx context.Context, name string, ns string) (resource.Resource, error) {
+	return r.get(ctx, "GetByNamespace", goqu.Ex{
+		"name":         name,
+		"namespace_id": ns,
+	})
+}
+
+func (r ResourceRepository) get(ctx context.Context, operation string, where goqu.Ex) (resource.Resource, error) {
+	q, err := toSQL(dialect.From(TABLE_RESOURCES).Where(where))
+	if err != nil {
+		return resource.Resource{}, err
+	}
+
+	var resourceModel Resource
+	if err = get(ctx, r.dbc, TABLE_RESOURCES, operation, &resourceModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return resource.Resource{}, resource.ErrNotExist
+		}
+		return resource.Resource{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	transformedResource, err := resourceModel.transformToResource()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedResource, nil
+}
+
+func (r ResourceRepository) Update(ctx context.Context, id string, res resource.Resource) (resource.Resource, error) {
+	if strings.TrimSpace(id) == "" {
+		return resource.Resource{}, resource.ErrInvalidID
+	}
+
+	if !uuid.IsValid(id) {
+		return resource.Resource{}, resource.ErrInvalidUUID
+	}
+
+	record := goqu.Record{
+		"name":         res.Name,
+		"project_id":   res.ProjectID,
+		"org_id":       res.OrganizationID,
+		"namespace_id": res.NamespaceID,
+		"updated_at":   now(),
+	}
+	if res.Tags != nil {
+		tags, err := marshalTags(res.Tags)
+		if err != nil {
+			return resource.Resource{}, err
+		}
+		record["tags"] = tags
+	}
+
+	update, err := toSQL(dialect.Update(TABLE_RESOURCES).Set(
+		record,
+	).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return resource.Resource{}, err
+	}
+
+	resourceModel, err := r.update(ctx, "Update", id, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return resource.Resource{}, resource.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return resource.Resource{}, resource.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return resource.Resource{}, resource.ErrNotExist
+		default:
+			return resource.Resource{}, err
+		}
+	}
+
+	return resourceModel, nil
+}
+
+// SetFolder moves the resource into the folder, an empty folder id moves it
+// out of its folder
+func (r ResourceRepository) SetFolder(ctx context.Context, id, folderID string) (resource.Resource, error) {
+	if strings.TrimSpace(id) == "" {
+		return resource.Resource{}, resource.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return resource.Resource{}, resource.ErrInvalidUUID
+	}
+
+	update, err := toSQL(dialect.Update(TABLE_RESOURCES).Set(
+		goqu.Record{
+			"folder_id":  sql.NullString{String: folderID, Valid: folderID != ""},
+			"updated_at": now(),
+		},
+	).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return resource.Resource{}, err
+	}
+
+	resourceModel, err := r.update(ctx, "SetFolder", id, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return resource.Resource{}, resource.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return resource.Resource{}, fmt.Errorf("%w: folder doesn't exist", resource.ErrInvalidDetail)
+		default:
+			return resource.Resource{}, err
+		}
+	}
+
+	return resourceModel, nil
+}
+
+func (r ResourceRepository) update(ctx context.Context, operation, id string, update sqlQuery) (resource.Resource, error) {
+	read, err := toSQL(dialect.From(TABLE_RESOURCES).Where(goqu.Ex{"id": id}))
+	if err != nil {
+		return resource.Resource{}, err
+	}
+
+	var resourceModel Resource
+	if err := updateRow(ctx, r.dbc, TABLE_RESOURCES, operation, &resourceModel, update, read); err != nil {
+		return resource.Resource{}, err
+	}
+
+	transformedResource, err := resourceModel.transformToResource()
+	if err != nil {
+		return resource.Resource{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedResource, nil
+}
+
+func marshalTags(tags map[string]string) (string, error) {
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	marshaled, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return string(marshaled), nil
+}
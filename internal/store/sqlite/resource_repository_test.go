@@ -0,0 +1,112 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/project"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/odpf/shield/pkg/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceRepository(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	org := bootstrapOrganization(t, client, "acme")
+	prj, err := sqlite.NewProjectRepository(client).Create(ctx, project.Project{Name: "Billing", Slug: "billing", Organization: org})
+	require.NoError(t, err)
+	bootstrapNamespace(t, client, "compute/instance")
+	repository := sqlite.NewResourceRepository(client)
+
+	created, err := repository.Create(ctx, resource.Resource{
+		URN:            "urn:compute:vm-1",
+		Name:           "vm-1",
+		ProjectID:      prj.ID,
+		OrganizationID: org.ID,
+		NamespaceID:    "compute/instance",
+		Tags:           map[string]string{"env": "prod"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod"}, created.Tags)
+
+	t.Run("should keep the tags of a resource created again unless set", func(t *testing.T) {
+		again, err := repository.Create(ctx, resource.Resource{
+			URN:            "urn:compute:vm-1",
+			Name:           "vm-1-renamed",
+			ProjectID:      prj.ID,
+			OrganizationID: org.ID,
+			NamespaceID:    "compute/instance",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, created.Idxa, again.Idxa)
+		assert.Equal(t, "vm-1-renamed", again.Name)
+		assert.Equal(t, map[string]string{"env": "prod"}, again.Tags)
+	})
+
+	t.Run("should refuse a resource of an unknown project", func(t *testing.T) {
+		_, err := repository.Create(ctx, resource.Resource{
+			URN:            "urn:compute:vm-2",
+			ProjectID:      uuid.NewString(),
+			OrganizationID: org.ID,
+			NamespaceID:    "compute/instance",
+		})
+		assert.ErrorIs(t, err, resource.ErrInvalidDetail)
+		_, err = repository.Create(ctx, resource.Resource{URN: "urn:compute:vm-2", ProjectID: "billing", OrganizationID: org.ID})
+		assert.ErrorIs(t, err, resource.ErrInvalidUUID)
+	})
+
+	t.Run("should get resources", func(t *testing.T) {
+		got, err := repository.GetByID(ctx, created.Idxa)
+		require.NoError(t, err)
+		assert.Equal(t, "urn:compute:vm-1", got.URN)
+
+		got, err = repository.GetByURN(ctx, "urn:compute:vm-1")
+		require.NoError(t, err)
+		assert.Equal(t, created.Idxa, got.Idxa)
+
+		got, err = repository.GetByNamespace(ctx, "vm-1-renamed", "compute/instance")
+		require.NoError(t, err)
+		assert.Equal(t, created.Idxa, got.Idxa)
+
+		_, err = repository.GetByURN(ctx, "urn:compute:missing")
+		assert.ErrorIs(t, err, resource.ErrNotExist)
+	})
+
+	t.Run("should list resources matching the filter", func(t *testing.T) {
+		resources, err := repository.List(ctx, resource.Filter{ProjectID: prj.ID, Tags: map[string]string{"env": "prod"}})
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, created.Idxa, resources[0].Idxa)
+
+		resources, err = repository.List(ctx, resource.Filter{Tags: map[string]string{"env": "dev"}})
+		require.NoError(t, err)
+		assert.Empty(t, resources)
+	})
+
+	t.Run("should update a resource and move it into a folder", func(t *testing.T) {
+		updated, err := repository.Update(ctx, created.Idxa, resource.Resource{
+			Name:           "vm-1",
+			ProjectID:      prj.ID,
+			OrganizationID: org.ID,
+			NamespaceID:    "compute/instance",
+			Tags:           map[string]string{"env": "dev"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "dev"}, updated.Tags)
+
+		_, err = repository.SetFolder(ctx, created.Idxa, uuid.NewString())
+		assert.ErrorIs(t, err, resource.ErrInvalidDetail)
+
+		folderID := uuid.NewString()
+		exec(t, client, `INSERT INTO folders (id, name, project_id) VALUES ('`+folderID+`', 'vms', '`+prj.ID+`')`)
+		moved, err := repository.SetFolder(ctx, created.Idxa, folderID)
+		require.NoError(t, err)
+		assert.Equal(t, folderID, moved.FolderID)
+
+		_, err = repository.Update(ctx, uuid.NewString(), resource.Resource{ProjectID: prj.ID, OrganizationID: org.ID})
+		assert.ErrorIs(t, err, resource.ErrNotExist)
+	})
+}
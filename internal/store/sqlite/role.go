@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/odpf/shield/core/role"
+)
+
+// Role keeps the types and the includes as json arrays
+type Role struct {
+	ID          string         `db:"id"`
+	Name        string         `db:"name"`
+	Types       []byte         `db:"types"`
+	NamespaceID string         `db:"namespace_id"`
+	OrgID       sql.NullString `db:"org_id"`
+	Includes    []byte         `db:"includes"`
+	Metadata    []byte         `db:"metadata"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+func (from Role) transformToRole() (role.Role, error) {
+	var unmarshalledMetadata map[string]any
+	if len(from.Metadata) > 0 {
+		if err := json.Unmarshal(from.Metadata, &unmarshalledMetadata); err != nil {
+			return role.Role{}, err
+		}
+	}
+	types, err := unmarshalStrings(from.Types)
+	if err != nil {
+		return role.Role{}, err
+	}
+	includes, err := unmarshalStrings(from.Includes)
+	if err != nil {
+		return role.Role{}, err
+	}
+
+	return role.Role{
+		ID:          from.ID,
+		Name:        from.Name,
+		Types:       types,
+		NamespaceID: from.NamespaceID,
+		OrgID:       from.OrgID.String,
+		Includes:    includes,
+		Metadata:    unmarshalledMetadata,
+		CreatedAt:   from.CreatedAt,
+		UpdatedAt:   from.UpdatedAt,
+	}, nil
+}
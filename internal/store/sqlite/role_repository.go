@@ -0,0 +1,204 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/role"
+	"github.com/odpf/shield/pkg/db"
+)
+
+type RoleRepository struct {
+	dbc *db.Client
+}
+
+func NewRoleRepository(dbc *db.Client) *RoleRepository {
+	return &RoleRepository{
+		dbc: dbc,
+	}
+}
+
+func (r RoleRepository) buildListQuery() *goqu.SelectDataset {
+	return dialect.Select(
+		goqu.I("r.id"),
+		goqu.I("r.name"),
+		goqu.I("r.types"),
+		goqu.I("r.namespace_id"),
+		goqu.I("r.org_id"),
+		goqu.I("r.includes"),
+		goqu.I("r.metadata"),
+		goqu.I("r.created_at"),
+		goqu.I("r.updated_at"),
+	).From(goqu.T(TABLE_ROLES).As("r")).Join(goqu.T(TABLE_NAMESPACES), goqu.On(
+		goqu.I("namespaces.id").Eq(goqu.I("r.namespace_id"))))
+}
+
+func (r RoleRepository) Get(ctx context.Context, id string) (role.Role, error) {
+	if strings.TrimSpace(id) == "" {
+		return role.Role{}, role.ErrInvalidID
+	}
+
+	q, err := toSQL(r.buildListQuery().Where(goqu.Ex{"r.id": id}))
+	if err != nil {
+		return role.Role{}, err
+	}
+
+	var roleModel Role
+	if err = get(ctx, r.dbc, TABLE_ROLES, "Get", &roleModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return role.Role{}, role.ErrNotExist
+		}
+		return role.Role{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	transformedRole, err := roleModel.transformToRole()
+	if err != nil {
+		return role.Role{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return transformedRole, nil
+}
+
+// roleRecord is the row of the role, the lists are kept as json arrays
+func roleRecord(rl role.Role) (goqu.Record, error) {
+	marshaledMetadata, err := json.Marshal(rl.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	types, err := marshalStrings(rl.Types)
+	if err != nil {
+		return nil, err
+	}
+	includes, err := marshalStrings(rl.Includes)
+	if err != nil {
+		return nil, err
+	}
+	return goqu.Record{
+		"name":         rl.Name,
+		"types":        types,
+		"namespace_id": rl.NamespaceID,
+		"metadata":     string(marshaledMetadata),
+		"includes":     includes,
+		"updated_at":   now(),
+	}, nil
+}
+
+// Create adds the role or renames the role of the id
+func (r RoleRepository) Create(ctx context.Context, rl role.Role) (string, error) {
+	if strings.TrimSpace(rl.ID) == "" {
+		return "", role.ErrInvalidID
+	}
+
+	if strings.TrimSpace(rl.Name) == "" {
+		return "", role.ErrInvalidDetail
+	}
+
+	record, err := roleRecord(rl)
+	if err != nil {
+		return "", err
+	}
+	record["id"] = rl.ID
+	record["org_id"] = sql.NullString{String: rl.OrgID, Valid: rl.OrgID != ""}
+	record["created_at"] = record["updated_at"]
+	q, err := toSQL(dialect.Insert(TABLE_ROLES).Rows(record).OnConflict(
+		goqu.DoUpdate("id", goqu.Record{
+			"name": rl.Name,
+		})))
+	if err != nil {
+		return "", err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_ROLES, "Create", q); err != nil {
+		switch {
+		case errors.Is(err, errDuplicateKey):
+			return "", role.ErrConflict
+		case errors.Is(err, errForeignKeyViolation):
+			return "", role.ErrInvalidDetail
+		default:
+			return "", err
+		}
+	}
+
+	return rl.ID, nil
+}
+
+func (r RoleRepository) List(ctx context.Context) ([]role.Role, error) {
+	q, err := toSQL(r.buildListQuery())
+	if err != nil {
+		return []role.Role{}, err
+	}
+
+	var fetchedRoles []Role
+	if err = list(ctx, r.dbc, TABLE_ROLES, "List", &fetchedRoles, q); err != nil {
+		return []role.Role{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedRoles []role.Role
+	for _, o := range fetchedRoles {
+		transformedRole, err := o.transformToRole()
+		if err != nil {
+			return []role.Role{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedRoles = append(transformedRoles, transformedRole)
+	}
+
+	return transformedRoles, nil
+}
+
+func (r RoleRepository) Update(ctx context.Context, rl role.Role) (string, error) {
+	if strings.TrimSpace(rl.ID) == "" {
+		return "", role.ErrInvalidID
+	}
+
+	if strings.TrimSpace(rl.Name) == "" {
+		return "", role.ErrInvalidDetail
+	}
+
+	record, err := roleRecord(rl)
+	if err != nil {
+		return "", err
+	}
+	q, err := toSQL(dialect.Update(TABLE_ROLES).Set(record).Where(goqu.Ex{"id": rl.ID}))
+	if err != nil {
+		return "", err
+	}
+
+	if err = execAffectingRow(ctx, r.dbc, TABLE_ROLES, "Update", q); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", role.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return "", role.ErrInvalidDetail
+		case errors.Is(err, errDuplicateKey):
+			return "", role.ErrConflict
+		default:
+			return "", err
+		}
+	}
+
+	return rl.ID, nil
+}
+
+func (r RoleRepository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return role.ErrInvalidID
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_ROLES, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return role.ErrNotExist
+		case errors.Is(err, errForeignKeyViolation):
+			return role.ErrInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/odpf/shield/core/rule"
+)
+
+type Rule struct {
+	ID                  string    `db:"id"`
+	Name                string    `db:"name"`
+	Proxy               string    `db:"proxy"`
+	FrontendURL         string    `db:"frontend_url"`
+	FrontendMethod      string    `db:"frontend_method"`
+	FrontendMaxBodySize int64     `db:"frontend_max_body_size"`
+	BackendURL          string    `db:"backend_url"`
+	BackendName         string    `db:"backend_name"`
+	BackendPrefix       string    `db:"backend_prefix"`
+	BackendPolicy       []byte    `db:"backend_policy"`
+	BackendSplits       []byte    `db:"backend_splits"`
+	Middlewares         []byte    `db:"middlewares"`
+	Hooks               []byte    `db:"hooks"`
+	CreatedAt           time.Time `db:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at"`
+}
+
+func (from Rule) transformToRule() (rule.Rule, error) {
+	var middlewares rule.MiddlewareSpecs
+	if err := json.Unmarshal(from.Middlewares, &middlewares); err != nil {
+		return rule.Rule{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	var hooks rule.HookSpecs
+	if err := json.Unmarshal(from.Hooks, &hooks); err != nil {
+		return rule.Rule{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	var policy rule.Policy
+	if err := json.Unmarshal(from.BackendPolicy, &policy); err != nil {
+		return rule.Rule{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	var splits []rule.Split
+	if err := json.Unmarshal(from.BackendSplits, &splits); err != nil {
+		return rule.Rule{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return rule.Rule{
+		ID:    from.ID,
+		Name:  from.Name,
+		Proxy: from.Proxy,
+		Frontend: rule.Frontend{
+			URL:         from.FrontendURL,
+			Method:      from.FrontendMethod,
+			MaxBodySize: from.FrontendMaxBodySize,
+		},
+		Backend: rule.Backend{
+			URL:       from.BackendURL,
+			Namespace: from.BackendName,
+			Prefix:    from.BackendPrefix,
+			Policy:    policy,
+			Splits:    splits,
+		},
+		Middlewares: middlewares,
+		Hooks:       hooks,
+		CreatedAt:   from.CreatedAt,
+		UpdatedAt:   from.UpdatedAt,
+	}, nil
+}
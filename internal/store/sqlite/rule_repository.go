@@ -0,0 +1,199 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/rule"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type RuleRepository struct {
+	dbc *db.Client
+}
+
+func NewRuleRepository(dbc *db.Client) *RuleRepository {
+	return &RuleRepository{
+		dbc: dbc,
+	}
+}
+
+func ruleRecord(rl rule.Rule) (goqu.Record, error) {
+	if rl.Middlewares == nil {
+		rl.Middlewares = rule.MiddlewareSpecs{}
+	}
+	if rl.Hooks == nil {
+		rl.Hooks = rule.HookSpecs{}
+	}
+	middlewares, err := json.Marshal(rl.Middlewares)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	hooks, err := json.Marshal(rl.Hooks)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	policy, err := json.Marshal(rl.Backend.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	if rl.Backend.Splits == nil {
+		rl.Backend.Splits = []rule.Split{}
+	}
+	splits, err := json.Marshal(rl.Backend.Splits)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", parseErr, err)
+	}
+
+	return goqu.Record{
+		"name":                   rl.Name,
+		"proxy":                  rl.Proxy,
+		"frontend_url":           rl.Frontend.URL,
+		"frontend_method":        rl.Frontend.Method,
+		"frontend_max_body_size": rl.Frontend.MaxBodySize,
+		"backend_url":            rl.Backend.URL,
+		"backend_name":           rl.Backend.Namespace,
+		"backend_prefix":         rl.Backend.Prefix,
+		"backend_policy":         string(policy),
+		"backend_splits":         string(splits),
+		"middlewares":            string(middlewares),
+		"hooks":                  string(hooks),
+		"updated_at":             now(),
+	}, nil
+}
+
+func (r RuleRepository) Create(ctx context.Context, rl rule.Rule) (rule.Rule, error) {
+	record, err := ruleRecord(rl)
+	if err != nil {
+		return rule.Rule{}, err
+	}
+	id := uuid.NewString()
+	record["id"] = id
+	record["created_at"] = record["updated_at"]
+
+	insert, err := toSQL(dialect.Insert(TABLE_RULES).Rows(record))
+	if err != nil {
+		return rule.Rule{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_RULES).Where(goqu.Ex{"id": id}))
+	if err != nil {
+		return rule.Rule{}, err
+	}
+
+	var ruleModel Rule
+	if err = updateRow(ctx, r.dbc, TABLE_RULES, "Create", &ruleModel, insert, read); err != nil {
+		if errors.Is(err, errDuplicateKey) {
+			return rule.Rule{}, rule.ErrConflict
+		}
+		return rule.Rule{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return ruleModel.transformToRule()
+}
+
+func (r RuleRepository) Update(ctx context.Context, rl rule.Rule) (rule.Rule, error) {
+	record, err := ruleRecord(rl)
+	if err != nil {
+		return rule.Rule{}, err
+	}
+
+	update, err := toSQL(dialect.Update(TABLE_RULES).Set(record).Where(goqu.Ex{
+		"id": rl.ID,
+	}))
+	if err != nil {
+		return rule.Rule{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_RULES).Where(goqu.Ex{"id": rl.ID}))
+	if err != nil {
+		return rule.Rule{}, err
+	}
+
+	var ruleModel Rule
+	if err = updateRow(ctx, r.dbc, TABLE_RULES, "Update", &ruleModel, update, read); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return rule.Rule{}, rule.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return rule.Rule{}, rule.ErrConflict
+		default:
+			return rule.Rule{}, fmt.Errorf("%w: %s", dbErr, err)
+		}
+	}
+
+	return ruleModel.transformToRule()
+}
+
+func (r RuleRepository) Get(ctx context.Context, id string) (rule.Rule, error) {
+	q, err := toSQL(dialect.From(TABLE_RULES).Where(goqu.Ex{"id": id}))
+	if err != nil {
+		return rule.Rule{}, err
+	}
+
+	var ruleModel Rule
+	if err = get(ctx, r.dbc, TABLE_RULES, "Get", &ruleModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return rule.Rule{}, rule.ErrNotExist
+		}
+		return rule.Rule{}, err
+	}
+
+	return ruleModel.transformToRule()
+}
+
+// List returns the rules in the order they were created, the proxies match
+// them in that order
+func (r RuleRepository) List(ctx context.Context, flt rule.Filter) ([]rule.Rule, error) {
+	sqlStatement := dialect.From(TABLE_RULES)
+	if flt.Proxy != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"proxy": flt.Proxy})
+	}
+	q, err := toSQL(sqlStatement.Order(goqu.C("created_at").Asc(), goqu.C("name").Asc()))
+	if err != nil {
+		return []rule.Rule{}, err
+	}
+
+	var ruleModels []Rule
+	if err = list(ctx, r.dbc, TABLE_RULES, "List", &ruleModels, q); err != nil {
+		return []rule.Rule{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedRules []rule.Rule
+	for _, rm := range ruleModels {
+		rl, err := rm.transformToRule()
+		if err != nil {
+			return []rule.Rule{}, err
+		}
+		transformedRules = append(transformedRules, rl)
+	}
+
+	return transformedRules, nil
+}
+
+// Version is the number of the rules along with the time the last of them
+// was updated, it is cheap enough to be polled by the proxies. The time is
+// the text sqlite keeps it as, the aggregate has no type to parse it by.
+func (r RuleRepository) Version(ctx context.Context) (string, error) {
+	q, err := toSQL(dialect.From(TABLE_RULES).Select(
+		goqu.COUNT("*").As("count"),
+		goqu.MAX("updated_at").As("updated_at"),
+	))
+	if err != nil {
+		return "", err
+	}
+
+	var version struct {
+		Count     int64          `db:"count"`
+		UpdatedAt sql.NullString `db:"updated_at"`
+	}
+	if err = get(ctx, r.dbc, TABLE_RULES, "Version", &version, q); err != nil {
+		return "", fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return strconv.FormatInt(version.Count, 10) + ":" + version.UpdatedAt.String, nil
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/odpf/shield/core/serviceuser"
+)
+
+type ServiceUser struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (from ServiceUser) transformToServiceUser() serviceuser.ServiceUser {
+	return serviceuser.ServiceUser{
+		ID:        from.ID,
+		UserID:    from.UserID,
+		Name:      from.Name,
+		CreatedAt: from.CreatedAt,
+		UpdatedAt: from.UpdatedAt,
+	}
+}
+
+type ServiceUserKey struct {
+	ID            string    `db:"id"`
+	ServiceUserID string    `db:"service_user_id"`
+	Algorithm     string    `db:"algorithm"`
+	PublicKey     string    `db:"public_key"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+func (from ServiceUserKey) transformToKey() serviceuser.Key {
+	return serviceuser.Key{
+		ID:            from.ID,
+		ServiceUserID: from.ServiceUserID,
+		Algorithm:     from.Algorithm,
+		PublicKey:     []byte(from.PublicKey),
+		CreatedAt:     from.CreatedAt,
+	}
+}
@@ -0,0 +1,186 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/serviceuser"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type ServiceUserRepository struct {
+	dbc *db.Client
+}
+
+func NewServiceUserRepository(dbc *db.Client) *ServiceUserRepository {
+	return &ServiceUserRepository{
+		dbc: dbc,
+	}
+}
+
+func (r ServiceUserRepository) Create(ctx context.Context, serviceUser serviceuser.ServiceUser) (serviceuser.ServiceUser, error) {
+	if strings.TrimSpace(serviceUser.Name) == "" || strings.TrimSpace(serviceUser.UserID) == "" {
+		return serviceuser.ServiceUser{}, serviceuser.ErrInvalidDetail
+	}
+
+	createdAt := now()
+	serviceUserModel := ServiceUser{
+		ID:        uuid.NewString(),
+		UserID:    serviceUser.UserID,
+		Name:      serviceUser.Name,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	q, err := toSQL(dialect.Insert(TABLE_SERVICE_USERS).Rows(
+		goqu.Record{
+			"id":         serviceUserModel.ID,
+			"user_id":    serviceUserModel.UserID,
+			"name":       serviceUserModel.Name,
+			"created_at": serviceUserModel.CreatedAt,
+			"updated_at": serviceUserModel.UpdatedAt,
+		}))
+	if err != nil {
+		return serviceuser.ServiceUser{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_SERVICE_USERS, "Create", q); err != nil {
+		switch {
+		case errors.Is(err, errDuplicateKey):
+			return serviceuser.ServiceUser{}, serviceuser.ErrConflict
+		default:
+			return serviceuser.ServiceUser{}, err
+		}
+	}
+
+	return serviceUserModel.transformToServiceUser(), nil
+}
+
+func (r ServiceUserRepository) Get(ctx context.Context, id string) (serviceuser.ServiceUser, error) {
+	if strings.TrimSpace(id) == "" {
+		return serviceuser.ServiceUser{}, serviceuser.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return serviceuser.ServiceUser{}, serviceuser.ErrInvalidID
+	}
+
+	q, err := toSQL(dialect.From(TABLE_SERVICE_USERS).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return serviceuser.ServiceUser{}, err
+	}
+
+	var serviceUserModel ServiceUser
+	if err = get(ctx, r.dbc, TABLE_SERVICE_USERS, "Get", &serviceUserModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceuser.ServiceUser{}, serviceuser.ErrNotExist
+		}
+		return serviceuser.ServiceUser{}, err
+	}
+
+	return serviceUserModel.transformToServiceUser(), nil
+}
+
+func (r ServiceUserRepository) List(ctx context.Context) ([]serviceuser.ServiceUser, error) {
+	q, err := toSQL(dialect.From(TABLE_SERVICE_USERS).Order(goqu.C("name").Asc()))
+	if err != nil {
+		return []serviceuser.ServiceUser{}, err
+	}
+
+	var serviceUserModels []ServiceUser
+	if err = list(ctx, r.dbc, TABLE_SERVICE_USERS, "List", &serviceUserModels, q); err != nil {
+		return []serviceuser.ServiceUser{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedServiceUsers []serviceuser.ServiceUser
+	for _, su := range serviceUserModels {
+		transformedServiceUsers = append(transformedServiceUsers, su.transformToServiceUser())
+	}
+
+	return transformedServiceUsers, nil
+}
+
+func (r ServiceUserRepository) CreateKey(ctx context.Context, key serviceuser.Key) (serviceuser.Key, error) {
+	if strings.TrimSpace(key.ServiceUserID) == "" || strings.TrimSpace(key.Algorithm) == "" || len(key.PublicKey) == 0 {
+		return serviceuser.Key{}, serviceuser.ErrInvalidDetail
+	}
+
+	keyModel := ServiceUserKey{
+		ID:            uuid.NewString(),
+		ServiceUserID: key.ServiceUserID,
+		Algorithm:     key.Algorithm,
+		PublicKey:     string(key.PublicKey),
+		CreatedAt:     now(),
+	}
+	q, err := toSQL(dialect.Insert(TABLE_SERVICE_USER_KEYS).Rows(
+		goqu.Record{
+			"id":              keyModel.ID,
+			"service_user_id": keyModel.ServiceUserID,
+			"algorithm":       keyModel.Algorithm,
+			"public_key":      keyModel.PublicKey,
+			"created_at":      keyModel.CreatedAt,
+		}))
+	if err != nil {
+		return serviceuser.Key{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_SERVICE_USER_KEYS, "CreateKey", q); err != nil {
+		switch {
+		case errors.Is(err, errForeignKeyViolation):
+			return serviceuser.Key{}, serviceuser.ErrNotExist
+		default:
+			return serviceuser.Key{}, err
+		}
+	}
+
+	return keyModel.transformToKey(), nil
+}
+
+func (r ServiceUserRepository) GetKey(ctx context.Context, id string) (serviceuser.Key, error) {
+	if strings.TrimSpace(id) == "" {
+		return serviceuser.Key{}, serviceuser.ErrKeyNotExist
+	}
+
+	q, err := toSQL(dialect.From(TABLE_SERVICE_USER_KEYS).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return serviceuser.Key{}, err
+	}
+
+	var keyModel ServiceUserKey
+	if err = get(ctx, r.dbc, TABLE_SERVICE_USER_KEYS, "GetKey", &keyModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return serviceuser.Key{}, serviceuser.ErrKeyNotExist
+		}
+		return serviceuser.Key{}, err
+	}
+
+	return keyModel.transformToKey(), nil
+}
+
+func (r ServiceUserRepository) ListKeys(ctx context.Context, serviceUserID string) ([]serviceuser.Key, error) {
+	q, err := toSQL(dialect.From(TABLE_SERVICE_USER_KEYS).Where(goqu.Ex{
+		"service_user_id": serviceUserID,
+	}).Order(goqu.C("created_at").Asc()))
+	if err != nil {
+		return []serviceuser.Key{}, err
+	}
+
+	var keyModels []ServiceUserKey
+	if err = list(ctx, r.dbc, TABLE_SERVICE_USER_KEYS, "ListKeys", &keyModels, q); err != nil {
+		return []serviceuser.Key{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedKeys []serviceuser.Key
+	for _, k := range keyModels {
+		transformedKeys = append(transformedKeys, k.transformToKey())
+	}
+
+	return transformedKeys, nil
+}
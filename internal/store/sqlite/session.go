@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/session"
+)
+
+type Session struct {
+	ID              string       `db:"id"`
+	UserID          string       `db:"user_id"`
+	UserAgent       string       `db:"user_agent"`
+	AccessHash      string       `db:"access_hash"`
+	AccessExpiresAt time.Time    `db:"access_expires_at"`
+	RefreshHash     string       `db:"refresh_hash"`
+	ExpiresAt       time.Time    `db:"expires_at"`
+	CreatedAt       time.Time    `db:"created_at"`
+	RefreshedAt     time.Time    `db:"refreshed_at"`
+	RevokedAt       sql.NullTime `db:"revoked_at"`
+}
+
+func (from Session) transformToSession() session.Session {
+	return session.Session{
+		ID:              from.ID,
+		UserID:          from.UserID,
+		UserAgent:       from.UserAgent,
+		AccessHash:      from.AccessHash,
+		AccessExpiresAt: from.AccessExpiresAt,
+		RefreshHash:     from.RefreshHash,
+		ExpiresAt:       from.ExpiresAt,
+		CreatedAt:       from.CreatedAt,
+		RefreshedAt:     from.RefreshedAt,
+		RevokedAt:       from.RevokedAt.Time,
+	}
+}
@@ -0,0 +1,171 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/session"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type SessionRepository struct {
+	dbc *db.Client
+}
+
+func NewSessionRepository(dbc *db.Client) *SessionRepository {
+	return &SessionRepository{
+		dbc: dbc,
+	}
+}
+
+func (r SessionRepository) Create(ctx context.Context, sess session.Session) (session.Session, error) {
+	if strings.TrimSpace(sess.UserID) == "" || strings.TrimSpace(sess.AccessHash) == "" || strings.TrimSpace(sess.RefreshHash) == "" {
+		return session.Session{}, session.ErrInvalidDetail
+	}
+
+	createdAt := now()
+	sessionModel := Session{
+		ID:              uuid.NewString(),
+		UserID:          sess.UserID,
+		UserAgent:       sess.UserAgent,
+		AccessHash:      sess.AccessHash,
+		AccessExpiresAt: sess.AccessExpiresAt.UTC(),
+		RefreshHash:     sess.RefreshHash,
+		ExpiresAt:       sess.ExpiresAt.UTC(),
+		CreatedAt:       createdAt,
+		RefreshedAt:     createdAt,
+	}
+	q, err := toSQL(dialect.Insert(TABLE_SESSIONS).Rows(
+		goqu.Record{
+			"id":                sessionModel.ID,
+			"user_id":           sessionModel.UserID,
+			"user_agent":        sessionModel.UserAgent,
+			"access_hash":       sessionModel.AccessHash,
+			"access_expires_at": sessionModel.AccessExpiresAt,
+			"refresh_hash":      sessionModel.RefreshHash,
+			"expires_at":        sessionModel.ExpiresAt,
+			"created_at":        sessionModel.CreatedAt,
+			"refreshed_at":      sessionModel.RefreshedAt,
+		}))
+	if err != nil {
+		return session.Session{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_SESSIONS, "Create", q); err != nil {
+		switch {
+		case errors.Is(err, errForeignKeyViolation):
+			return session.Session{}, fmt.Errorf("%w: user doesn't exist", session.ErrInvalidDetail)
+		default:
+			return session.Session{}, err
+		}
+	}
+
+	return sessionModel.transformToSession(), nil
+}
+
+func (r SessionRepository) Get(ctx context.Context, id string) (session.Session, error) {
+	return r.getBy(ctx, "Get", goqu.Ex{"id": id})
+}
+
+func (r SessionRepository) GetByAccessHash(ctx context.Context, hash string) (session.Session, error) {
+	return r.getBy(ctx, "GetByAccessHash", goqu.Ex{"access_hash": hash})
+}
+
+func (r SessionRepository) GetByRefreshHash(ctx context.Context, hash string) (session.Session, error) {
+	return r.getBy(ctx, "GetByRefreshHash", goqu.Ex{"refresh_hash": hash})
+}
+
+func (r SessionRepository) getBy(ctx context.Context, operation string, ex goqu.Ex) (session.Session, error) {
+	q, err := toSQL(dialect.From(TABLE_SESSIONS).Where(ex))
+	if err != nil {
+		return session.Session{}, err
+	}
+
+	var sessionModel Session
+	if err = get(ctx, r.dbc, TABLE_SESSIONS, operation, &sessionModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return session.Session{}, session.ErrNotExist
+		}
+		return session.Session{}, err
+	}
+
+	return sessionModel.transformToSession(), nil
+}
+
+func (r SessionRepository) List(ctx context.Context, userID string) ([]session.Session, error) {
+	sqlStatement := dialect.From(TABLE_SESSIONS)
+	if userID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"user_id": userID})
+	}
+
+	q, err := toSQL(sqlStatement.Order(goqu.C("created_at").Desc()))
+	if err != nil {
+		return []session.Session{}, err
+	}
+
+	var sessionModels []Session
+	if err = list(ctx, r.dbc, TABLE_SESSIONS, "List", &sessionModels, q); err != nil {
+		return []session.Session{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedSessions []session.Session
+	for _, s := range sessionModels {
+		transformedSessions = append(transformedSessions, s.transformToSession())
+	}
+
+	return transformedSessions, nil
+}
+
+func (r SessionRepository) Rotate(ctx context.Context, toRotate session.Session, refreshHash string) (session.Session, error) {
+	return r.update(ctx, "Rotate", toRotate.ID, goqu.Record{
+		"access_hash":       toRotate.AccessHash,
+		"access_expires_at": toRotate.AccessExpiresAt.UTC(),
+		"refresh_hash":      toRotate.RefreshHash,
+		"refreshed_at":      now(),
+	}, goqu.Ex{
+		"id":           toRotate.ID,
+		"refresh_hash": refreshHash,
+		"revoked_at":   nil,
+	})
+}
+
+func (r SessionRepository) Revoke(ctx context.Context, id string) (session.Session, error) {
+	return r.update(ctx, "Revoke", id, goqu.Record{
+		"revoked_at": now(),
+	}, goqu.Ex{
+		"id":         id,
+		"revoked_at": nil,
+	})
+}
+
+// update sets the record on the session if it matches where, the session
+// is returned as updated
+func (r SessionRepository) update(ctx context.Context, operation, id string, record goqu.Record, where goqu.Ex) (session.Session, error) {
+	update, err := toSQL(dialect.Update(TABLE_SESSIONS).Set(record).Where(where))
+	if err != nil {
+		return session.Session{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_SESSIONS).Where(goqu.Ex{"id": id}))
+	if err != nil {
+		return session.Session{}, err
+	}
+
+	var sessionModel Session
+	if err = updateRow(ctx, r.dbc, TABLE_SESSIONS, operation, &sessionModel, update, read); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return session.Session{}, session.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return session.Session{}, fmt.Errorf("%w: %s", dbErr, err)
+		default:
+			return session.Session{}, err
+		}
+	}
+
+	return sessionModel.transformToSession(), nil
+}
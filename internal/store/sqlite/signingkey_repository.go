@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+	"github.com/odpf/shield/core/signingkey"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type SigningKeyRepository struct {
+	dbc *db.Client
+}
+
+func NewSigningKeyRepository(dbc *db.Client) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		dbc: dbc,
+	}
+}
+
+func (r SigningKeyRepository) Create(ctx context.Context, key signingkey.Key, createdAfter time.Time) (signingkey.Key, error) {
+	if !uuid.IsValid(key.ID) || strings.TrimSpace(key.Algorithm) == "" || len(key.PublicKey) == 0 || len(key.EncryptedPrivateKey) == 0 {
+		return signingkey.Key{}, signingkey.ErrInvalidDetail
+	}
+
+	newer, err := toSQL(dialect.From(TABLE_SIGNING_KEYS).Select(goqu.COUNT("*")).Where(
+		goqu.C("created_at").Gt(createdAfter),
+	))
+	if err != nil {
+		return signingkey.Key{}, err
+	}
+	keyModel := SigningKey{
+		ID:          key.ID,
+		Algorithm:   key.Algorithm,
+		PublicKey:   string(key.PublicKey),
+		PrivateKey:  base64.StdEncoding.EncodeToString(key.EncryptedPrivateKey),
+		CreatedAt:   now(),
+		ActivatesAt: key.ActivatesAt.UTC(),
+	}
+	insert, err := toSQL(dialect.Insert(TABLE_SIGNING_KEYS).Rows(
+		goqu.Record{
+			"id":           keyModel.ID,
+			"algorithm":    keyModel.Algorithm,
+			"public_key":   keyModel.PublicKey,
+			"private_key":  keyModel.PrivateKey,
+			"created_at":   keyModel.CreatedAt,
+			"activates_at": keyModel.ActivatesAt,
+		}))
+	if err != nil {
+		return signingkey.Key{}, err
+	}
+
+	// the transactions of sqlite take the write lock when they begin, the
+	// instances rotating at once wait on it and the ones after the first
+	// then see its key
+	if err = r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return run(ctx, r.dbc, TABLE_SIGNING_KEYS, "Create", func(ctx context.Context) error {
+			var count int
+			if err := tx.GetContext(ctx, &count, newer.query, newer.params...); err != nil {
+				return err
+			}
+			if count > 0 {
+				return signingkey.ErrConflict
+			}
+			_, err := tx.ExecContext(ctx, insert.query, insert.params...)
+			return checkSQLiteError(err)
+		})
+	}); err != nil {
+		if errors.Is(err, signingkey.ErrConflict) {
+			return signingkey.Key{}, signingkey.ErrConflict
+		}
+		return signingkey.Key{}, err
+	}
+
+	created, err := keyModel.transformToSigningKey()
+	if err != nil {
+		return signingkey.Key{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return created, nil
+}
+
+func (r SigningKeyRepository) List(ctx context.Context) ([]signingkey.Key, error) {
+	q, err := toSQL(dialect.From(TABLE_SIGNING_KEYS).Order(
+		goqu.C("activates_at").Asc(),
+		goqu.C("created_at").Asc(),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var keyModels []SigningKey
+	if err = list(ctx, r.dbc, TABLE_SIGNING_KEYS, "List", &keyModels, q); err != nil {
+		return nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	keys := []signingkey.Key{}
+	for _, k := range keyModels {
+		key, err := k.transformToSigningKey()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (r SigningKeyRepository) Delete(ctx context.Context, id string) error {
+	if !uuid.IsValid(id) {
+		return signingkey.ErrNotExist
+	}
+
+	if err := deleteByID(ctx, r.dbc, TABLE_SIGNING_KEYS, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return signingkey.ErrNotExist
+		}
+		return err
+	}
+	return nil
+}
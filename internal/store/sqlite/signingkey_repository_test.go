@@ -0,0 +1,51 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/signingkey"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/odpf/shield/pkg/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningKeyRepository(t *testing.T) {
+	ctx := context.Background()
+	repository := sqlite.NewSigningKeyRepository(newTestClient(t))
+	newKey := func() signingkey.Key {
+		return signingkey.Key{
+			ID:                  uuid.NewString(),
+			Algorithm:           "RS256",
+			PublicKey:           []byte("public key"),
+			EncryptedPrivateKey: []byte{0x00, 0x01, 0xfe},
+			ActivatesAt:         time.Now(),
+		}
+	}
+
+	before := time.Now().Add(-time.Minute)
+	created, err := repository.Create(ctx, newKey(), before)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0x01, 0xfe}, created.EncryptedPrivateKey)
+
+	t.Run("should refuse a key when another was created after the time", func(t *testing.T) {
+		_, err := repository.Create(ctx, newKey(), before)
+		assert.ErrorIs(t, err, signingkey.ErrConflict)
+	})
+
+	t.Run("should list and delete the keys", func(t *testing.T) {
+		keys, err := repository.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, created.ID, keys[0].ID)
+
+		require.NoError(t, repository.Delete(ctx, created.ID))
+		assert.ErrorIs(t, repository.Delete(ctx, created.ID), signingkey.ErrNotExist)
+
+		keys, err = repository.List(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+}
@@ -0,0 +1,226 @@
+// Package sqlite keeps the entities of shield in a sqlite database, for
+// deployments running shield as a single binary and for integration tests
+// not standing up postgres. The repositories behave like the postgres ones,
+// the ids are generated here since sqlite has no uuids and the times are
+// kept in utc.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	goqusqlite3 "github.com/doug-martin/goqu/v9/dialect/sqlite3"
+	"github.com/jmoiron/sqlx"
+	newrelic "github.com/newrelic/go-agent"
+	"github.com/odpf/shield/pkg/db"
+)
+
+var (
+	parseErr = errors.New("parsing error")
+	queryErr = errors.New("error while creating the query")
+	dbErr    = errors.New("error while running query")
+	dialect  = goqu.Dialect(dialectName)
+
+	errDuplicateKey        = errors.New("duplicate key")
+	errCheckViolation      = errors.New("check constraint violation")
+	errForeignKeyViolation = errors.New("foreign key violation")
+)
+
+const (
+	dialectName = "shield-sqlite3"
+	// timeFormat keeps the fraction of the seconds in full, sqlite compares
+	// the times as text and a shorter fraction would sort after a longer one
+	timeFormat = "2006-01-02 15:04:05.000000000"
+)
+
+func init() {
+	opts := goqusqlite3.DialectOptions()
+	opts.TimeFormat = timeFormat
+	// sqlite has the where of the upserts since 3.24, goqu predates it
+	opts.SupportsConflictUpdateWhere = true
+	goqu.RegisterDialect(dialectName, opts)
+}
+
+const (
+	TABLE_ACTIONS            = "actions"
+	TABLE_API_KEYS           = "api_keys"
+	TABLE_AUDIT_LOGS         = "audit_logs"
+	TABLE_AUTHZ_SCHEMA       = "authz_schema"
+	TABLE_AUTHZ_TUPLES       = "authz_tuples"
+	TABLE_EVENT_OUTBOX       = "event_outbox"
+	TABLE_FOLDERS            = "folders"
+	TABLE_GROUPS             = "groups"
+	TABLE_IDEMPOTENCY_KEYS   = "idempotency_keys"
+	TABLE_INVITATIONS        = "invitations"
+	TABLE_METADATA           = "metadata"
+	TABLE_METADATA_KEYS      = "metadata_keys"
+	TABLE_METADATA_SCHEMAS   = "metadata_schemas"
+	TABLE_NAMESPACES         = "namespaces"
+	TABLE_ORGANIZATIONS      = "organizations"
+	TABLE_ORGANIZATION_USAGE = "organization_usage"
+	TABLE_POLICIES           = "policies"
+	TABLE_PROJECTS           = "projects"
+	TABLE_RELATIONS          = "relations"
+	TABLE_RESOURCES          = "resources"
+	TABLE_ROLES              = "roles"
+	TABLE_RULES              = "rules"
+	TABLE_SERVICE_USERS      = "service_users"
+	TABLE_SERVICE_USER_KEYS  = "service_user_keys"
+	TABLE_SESSIONS           = "sessions"
+	TABLE_SIGNING_KEYS       = "signing_keys"
+	TABLE_TAG_POLICIES       = "resource_tag_policies"
+	TABLE_TAG_POLICY_GRANTS  = "resource_tag_policy_grants"
+	TABLE_USERS              = "users"
+	TABLE_WEBHOOKS           = "webhooks"
+	TABLE_WEBHOOK_DELIVERIES = "webhook_deliveries"
+)
+
+// now is the time the rows are created or updated at, the queries write the
+// times in utc
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// run runs the operation on the table with the query timeout of the client,
+// in a datastore segment of the transaction of the request if it has one
+func run(ctx context.Context, dbc *db.Client, table, operation string, op func(ctx context.Context) error) error {
+	return dbc.WithTimeout(ctx, func(ctx context.Context) error {
+		if nrCtx := newrelic.FromContext(ctx); nrCtx != nil {
+			nr := newrelic.DatastoreSegment{
+				Product:    newrelic.DatastoreSQLite,
+				Collection: table,
+				Operation:  operation,
+				StartTime:  nrCtx.StartSegmentNow(),
+			}
+			defer nr.End()
+		}
+		return op(ctx)
+	})
+}
+
+// marshalMetadata is the json the metadata is kept as
+func marshalMetadata(metadata map[string]any) (string, error) {
+	marshaled, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return string(marshaled), nil
+}
+
+func unmarshalMetadata(data []byte) (map[string]any, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var metadata map[string]any
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// sqlQuery is a query with its parameters
+type sqlQuery struct {
+	query  string
+	params []interface{}
+}
+
+// toSQL builds the query of the dataset
+func toSQL(ds interface {
+	ToSQL() (string, []interface{}, error)
+}) (sqlQuery, error) {
+	query, params, err := ds.ToSQL()
+	if err != nil {
+		return sqlQuery{}, fmt.Errorf("%w: %s", queryErr, err)
+	}
+	return sqlQuery{query: query, params: params}, nil
+}
+
+// get reads the row selected by the query into dest, sql.ErrNoRows is
+// returned when there is no such row
+func get(ctx context.Context, dbc *db.Client, table, operation string, dest interface{}, q sqlQuery) error {
+	return run(ctx, dbc, table, operation, func(ctx context.Context) error {
+		return checkSQLiteError(dbc.GetContext(ctx, dest, q.query, q.params...))
+	})
+}
+
+// list reads the rows selected by the query into dest
+func list(ctx context.Context, dbc *db.Client, table, operation string, dest interface{}, q sqlQuery) error {
+	return run(ctx, dbc, table, operation, func(ctx context.Context) error {
+		return checkSQLiteError(dbc.SelectContext(ctx, dest, q.query, q.params...))
+	})
+}
+
+// exec runs the statement
+func exec(ctx context.Context, dbc *db.Client, table, operation string, q sqlQuery) error {
+	return run(ctx, dbc, table, operation, func(ctx context.Context) error {
+		_, err := dbc.ExecContext(ctx, q.query, q.params...)
+		return checkSQLiteError(err)
+	})
+}
+
+// withSavepoint runs op in a savepoint of the transaction so a statement of
+// op failing is rolled back without rolling back the transaction, the other
+// rows of a batch are still written then
+func withSavepoint(ctx context.Context, tx *sqlx.Tx, op func() error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+		return err
+	}
+	opErr := op()
+	if opErr == nil {
+		_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item")
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item"); err != nil {
+		return fmt.Errorf("%w: rollback to savepoint: %s", dbErr, err)
+	}
+	return opErr
+}
+
+// updateRow updates a row and reads it back into dest in one transaction,
+// sqlite can't return the rows an update changes. sql.ErrNoRows is returned
+// when the update changes no row.
+func updateRow(ctx context.Context, dbc *db.Client, table, operation string, dest interface{}, update, read sqlQuery) error {
+	return dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return run(ctx, dbc, table, operation, func(ctx context.Context) error {
+			result, err := tx.ExecContext(ctx, update.query, update.params...)
+			if err != nil {
+				return checkSQLiteError(err)
+			}
+			if count, err := result.RowsAffected(); err != nil {
+				return err
+			} else if count == 0 {
+				return sql.ErrNoRows
+			}
+			return checkSQLiteError(tx.GetContext(ctx, dest, read.query, read.params...))
+		})
+	})
+}
+
+// marshalStrings is the json array the lists of strings postgres keeps as
+// arrays are kept as
+func marshalStrings(values []string) (string, error) {
+	if values == nil {
+		values = []string{}
+	}
+	marshaled, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return string(marshaled), nil
+}
+
+func unmarshalStrings(data []byte) ([]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
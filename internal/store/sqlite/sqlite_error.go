@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+
+	modernc "modernc.org/sqlite"
+	sqlite3lib "modernc.org/sqlite/lib"
+)
+
+// checkSQLiteError maps the constraint violations of sqlite to the errors
+// the repositories map to their domain errors
+func checkSQLiteError(err error) error {
+	var sqliteErr *modernc.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3lib.SQLITE_CONSTRAINT_UNIQUE, sqlite3lib.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return fmt.Errorf("%w [%s]", errDuplicateKey, sqliteErr.Error())
+		case sqlite3lib.SQLITE_CONSTRAINT_CHECK:
+			return fmt.Errorf("%w [%s]", errCheckViolation, sqliteErr.Error())
+		case sqlite3lib.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return fmt.Errorf("%w [%s]", errForeignKeyViolation, sqliteErr.Error())
+		}
+	}
+	return err
+}
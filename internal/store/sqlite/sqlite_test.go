@@ -0,0 +1,88 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/core/organization"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/odpf/shield/internal/store/sqlite/migrations"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient opens a migrated database in a file of the temp dir of the test
+func newTestClient(t *testing.T) *db.Client {
+	t.Helper()
+
+	cfg := db.Config{
+		Driver:              db.DriverSQLite,
+		URL:                 filepath.Join(t.TempDir(), "shield.db"),
+		MaxIdleConns:        1,
+		MaxOpenConns:        1,
+		MaxQueryTimeoutInMS: 5 * time.Second,
+	}
+	require.NoError(t, db.RunMigrations(cfg, migrations.MigrationFs, migrations.ResourcePath))
+
+	client, err := db.New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// exec runs the statements seeding the tables no repository writes to
+func exec(t *testing.T, client *db.Client, statements ...string) {
+	t.Helper()
+	for _, statement := range statements {
+		_, err := client.ExecContext(context.Background(), statement)
+		require.NoError(t, err, statement)
+	}
+}
+
+func bootstrapOrganization(t *testing.T, client *db.Client, slug string) organization.Organization {
+	t.Helper()
+	org, err := sqlite.NewOrganizationRepository(client).Create(context.Background(), organization.Organization{
+		Name: slug,
+		Slug: slug,
+	})
+	require.NoError(t, err)
+	return org
+}
+
+func bootstrapUser(t *testing.T, client *db.Client, email string) user.User {
+	t.Helper()
+	usr, err := sqlite.NewUserRepository(client).Create(context.Background(), user.User{
+		Name:  email,
+		Email: email,
+	})
+	require.NoError(t, err)
+	return usr
+}
+
+// bootstrapNamespace adds the namespace unless it exists
+func bootstrapNamespace(t *testing.T, client *db.Client, id string) {
+	t.Helper()
+	exec(t, client, fmt.Sprintf(`INSERT OR IGNORE INTO namespaces (id, name) VALUES ('%s', '%s')`, id, id))
+}
+
+// bootstrapRole adds the role of the namespace unless it exists
+func bootstrapRole(t *testing.T, client *db.Client, namespaceID, id string) {
+	t.Helper()
+	bootstrapNamespace(t, client, namespaceID)
+	exec(t, client, fmt.Sprintf(`INSERT OR IGNORE INTO roles (id, name, types, namespace_id) VALUES ('%s', '%s', '[]', '%s')`,
+		id, id, namespaceID))
+}
+
+func bootstrapRelation(t *testing.T, client *db.Client, subjectNamespaceID, subjectID, objectNamespaceID, objectID, roleID string) {
+	t.Helper()
+	bootstrapNamespace(t, client, subjectNamespaceID)
+	bootstrapRole(t, client, objectNamespaceID, roleID)
+	exec(t, client, fmt.Sprintf(`INSERT INTO relations (id, subject_namespace_id, subject_id, object_namespace_id, object_id, role_id)
+		VALUES ('%s', '%s', '%s', '%s', '%s', '%s')`,
+		uuid.NewString(), subjectNamespaceID, subjectID, objectNamespaceID, objectID, roleID))
+}
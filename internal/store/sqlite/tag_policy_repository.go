@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/odpf/shield/core/resource"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type TagPolicyRepository struct {
+	dbc *db.Client
+}
+
+func NewTagPolicyRepository(dbc *db.Client) *TagPolicyRepository {
+	return &TagPolicyRepository{
+		dbc: dbc,
+	}
+}
+
+func (r TagPolicyRepository) Create(ctx context.Context, pol resource.TagPolicy) (resource.TagPolicy, error) {
+	tags, err := marshalTags(pol.Tags)
+	if err != nil {
+		return resource.TagPolicy{}, err
+	}
+
+	policyModel := TagPolicy{
+		ID:                 uuid.NewString(),
+		NamespaceID:        pol.NamespaceID,
+		Tags:               []byte(tags),
+		RoleID:             pol.RoleID,
+		SubjectNamespaceID: pol.Subject.Namespace,
+		SubjectID:          pol.Subject.ID,
+		CreatedAt:          now(),
+	}
+	q, err := toSQL(dialect.Insert(TABLE_TAG_POLICIES).Rows(
+		goqu.Record{
+			"id":                   policyModel.ID,
+			"namespace_id":         policyModel.NamespaceID,
+			"tags":                 tags,
+			"role_id":              policyModel.RoleID,
+			"subject_namespace_id": policyModel.SubjectNamespaceID,
+			"subject_id":           policyModel.SubjectID,
+			"created_at":           policyModel.CreatedAt,
+		}))
+	if err != nil {
+		return resource.TagPolicy{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_TAG_POLICIES, "Create", q); err != nil {
+		if errors.Is(err, errForeignKeyViolation) {
+			return resource.TagPolicy{}, fmt.Errorf("%w: namespace doesn't exist", resource.ErrInvalidDetail)
+		}
+		return resource.TagPolicy{}, err
+	}
+
+	transformedPolicy, err := policyModel.transformToTagPolicy()
+	if err != nil {
+		return resource.TagPolicy{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedPolicy, nil
+}
+
+func (r TagPolicyRepository) Get(ctx context.Context, id string) (resource.TagPolicy, error) {
+	if strings.TrimSpace(id) == "" {
+		return resource.TagPolicy{}, resource.ErrTagPolicyNotExist
+	}
+
+	q, err := toSQL(dialect.From(TABLE_TAG_POLICIES).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return resource.TagPolicy{}, err
+	}
+
+	var policyModel TagPolicy
+	if err = get(ctx, r.dbc, TABLE_TAG_POLICIES, "Get", &policyModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return resource.TagPolicy{}, resource.ErrTagPolicyNotExist
+		}
+		return resource.TagPolicy{}, err
+	}
+
+	transformedPolicy, err := policyModel.transformToTagPolicy()
+	if err != nil {
+		return resource.TagPolicy{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedPolicy, nil
+}
+
+func (r TagPolicyRepository) List(ctx context.Context, namespaceID string) ([]resource.TagPolicy, error) {
+	sqlStatement := dialect.From(TABLE_TAG_POLICIES)
+	if namespaceID != "" {
+		sqlStatement = sqlStatement.Where(goqu.Ex{"namespace_id": namespaceID})
+	}
+
+	q, err := toSQL(sqlStatement.Order(goqu.C("created_at").Asc()))
+	if err != nil {
+		return []resource.TagPolicy{}, err
+	}
+
+	var policyModels []TagPolicy
+	if err = list(ctx, r.dbc, TABLE_TAG_POLICIES, "List", &policyModels, q); err != nil {
+		return []resource.TagPolicy{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedPolicies []resource.TagPolicy
+	for _, p := range policyModels {
+		transformedPolicy, err := p.transformToTagPolicy()
+		if err != nil {
+			return []resource.TagPolicy{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedPolicies = append(transformedPolicies, transformedPolicy)
+	}
+
+	return transformedPolicies, nil
+}
+
+func (r TagPolicyRepository) Delete(ctx context.Context, id string) error {
+	q, err := toSQL(dialect.Delete(TABLE_TAG_POLICIES).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return err
+	}
+
+	return exec(ctx, r.dbc, TABLE_TAG_POLICIES, "Delete", q)
+}
+
+func (r TagPolicyRepository) ListGrants(ctx context.Context, policyID string) ([]string, error) {
+	q, err := toSQL(dialect.From(TABLE_TAG_POLICY_GRANTS).Select("resource_id").Where(goqu.Ex{
+		"policy_id": policyID,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var resourceIDs []string
+	if err = list(ctx, r.dbc, TABLE_TAG_POLICY_GRANTS, "List", &resourceIDs, q); err != nil {
+		return nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return resourceIDs, nil
+}
+
+func (r TagPolicyRepository) AddGrant(ctx context.Context, policyID, resourceID string) error {
+	q, err := toSQL(dialect.Insert(TABLE_TAG_POLICY_GRANTS).Rows(
+		goqu.Record{
+			"policy_id":   policyID,
+			"resource_id": resourceID,
+		}).OnConflict(goqu.DoNothing()))
+	if err != nil {
+		return err
+	}
+
+	return exec(ctx, r.dbc, TABLE_TAG_POLICY_GRANTS, "Create", q)
+}
+
+func (r TagPolicyRepository) RemoveGrant(ctx context.Context, policyID, resourceID string) error {
+	q, err := toSQL(dialect.Delete(TABLE_TAG_POLICY_GRANTS).Where(goqu.Ex{
+		"policy_id":   policyID,
+		"resource_id": resourceID,
+	}))
+	if err != nil {
+		return err
+	}
+
+	return exec(ctx, r.dbc, TABLE_TAG_POLICY_GRANTS, "Delete", q)
+}
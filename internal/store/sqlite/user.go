@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/user"
+)
+
+type User struct {
+	ID         string       `db:"id"`
+	Name       string       `db:"name"`
+	Email      string       `db:"email"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DeletedAt  sql.NullTime `db:"deleted_at"`
+	DisabledAt sql.NullTime `db:"disabled_at"`
+}
+
+type UserMetadataKey struct {
+	Key         string         `db:"key"`
+	Description sql.NullString `db:"description"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+func (from User) transformToUser() user.User {
+	return user.User{
+		ID:         from.ID,
+		Name:       from.Name,
+		Email:      from.Email,
+		CreatedAt:  from.CreatedAt,
+		UpdatedAt:  from.UpdatedAt,
+		DisabledAt: from.DisabledAt.Time,
+	}
+}
+
+func (from UserMetadataKey) transformToUserMetadataKey() user.UserMetadataKey {
+	return user.UserMetadataKey{
+		Key:         from.Key,
+		Description: from.Description.String,
+		CreatedAt:   from.CreatedAt,
+		UpdatedAt:   from.UpdatedAt,
+	}
+}
@@ -0,0 +1,490 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/metadata"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type UserRepository struct {
+	dbc *db.Client
+}
+
+// userMetadata is a metadata value of a user, the values are kept json
+// encoded
+type userMetadata struct {
+	UserID string `db:"user_id"`
+	Key    string `db:"key"`
+	Value  []byte `db:"value"`
+}
+
+func NewUserRepository(dbc *db.Client) *UserRepository {
+	return &UserRepository{
+		dbc: dbc,
+	}
+}
+
+func (r UserRepository) GetByID(ctx context.Context, id string) (user.User, error) {
+	if strings.TrimSpace(id) == "" {
+		return user.User{}, user.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return user.User{}, user.ErrInvalidUUID
+	}
+
+	return r.get(ctx, "GetByID", goqu.Ex{"id": id})
+}
+
+func (r UserRepository) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	if strings.TrimSpace(email) == "" {
+		return user.User{}, user.ErrInvalidEmail
+	}
+
+	return r.get(ctx, "GetByEmail", goqu.Ex{"email": email})
+}
+
+func (r UserRepository) get(ctx context.Context, operation string, where goqu.Ex) (user.User, error) {
+	q, err := toSQL(dialect.From(TABLE_USERS).Where(where))
+	if err != nil {
+		return user.User{}, err
+	}
+
+	var fetchedUser User
+	if err = get(ctx, r.dbc, TABLE_USERS, operation, &fetchedUser, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.User{}, user.ErrNotExist
+		}
+		return user.User{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	metadataByUser, err := r.metadataOf(ctx, []string{fetchedUser.ID})
+	if err != nil {
+		return user.User{}, err
+	}
+
+	transformedUser := fetchedUser.transformToUser()
+	transformedUser.Metadata = metadataByUser[fetchedUser.ID]
+	return transformedUser, nil
+}
+
+func (r UserRepository) GetByIDs(ctx context.Context, userIDs []string) ([]user.User, error) {
+	q, err := toSQL(dialect.From(TABLE_USERS).Where(
+		goqu.Ex{
+			"id": goqu.Op{"in": userIDs},
+		}))
+	if err != nil {
+		return []user.User{}, err
+	}
+
+	var fetchedUsers []User
+	if err = list(ctx, r.dbc, TABLE_USERS, "GetByIDs", &fetchedUsers, q); err != nil {
+		return []user.User{}, err
+	}
+
+	var transformedUsers []user.User
+	for _, u := range fetchedUsers {
+		transformedUsers = append(transformedUsers, u.transformToUser())
+	}
+
+	return transformedUsers, nil
+}
+
+func (r UserRepository) Create(ctx context.Context, usr user.User) (user.User, error) {
+	if strings.TrimSpace(usr.Email) == "" {
+		return user.User{}, user.ErrInvalidEmail
+	}
+
+	createdAt := now()
+	userModel := User{
+		ID:        uuid.NewString(),
+		Name:      usr.Name,
+		Email:     usr.Email,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	createQuery, err := toSQL(dialect.Insert(TABLE_USERS).Rows(
+		goqu.Record{
+			"id":         userModel.ID,
+			"name":       userModel.Name,
+			"email":      userModel.Email,
+			"created_at": userModel.CreatedAt,
+			"updated_at": userModel.UpdatedAt,
+		}))
+	if err != nil {
+		return user.User{}, err
+	}
+
+	if err := r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return run(ctx, r.dbc, TABLE_USERS, "Create", func(ctx context.Context) error {
+			if _, err := tx.ExecContext(ctx, createQuery.query, createQuery.params...); err != nil {
+				return checkSQLiteError(err)
+			}
+			return insertUserMetadata(ctx, tx, userModel.ID, usr.Metadata)
+		})
+	}); err != nil {
+		if errors.Is(err, errDuplicateKey) {
+			return user.User{}, user.ErrConflict
+		}
+		return user.User{}, err
+	}
+
+	transformedUser := userModel.transformToUser()
+	transformedUser.Metadata = usr.Metadata
+	return transformedUser, nil
+}
+
+func (r UserRepository) List(ctx context.Context, flt user.Filter) ([]user.User, error) {
+	var defaultLimit int32 = 50
+	var defaultPage int32 = 1
+	if flt.Limit < 1 {
+		flt.Limit = defaultLimit
+	}
+	if flt.Page < 1 {
+		flt.Page = defaultPage
+	}
+
+	keyword := "*" + flt.Keyword + "*"
+	sqlStatement := dialect.From(TABLE_USERS).Where(goqu.Or(
+		like(goqu.C("name"), keyword),
+		like(goqu.C("email"), keyword),
+	)).Where(
+		globExpressions(map[string]string{"name": flt.Name, "email": flt.Email})...,
+	).Where(
+		userMetadataGlobExpressions(flt.Metadata)...,
+	).Where(
+		userStateExpressions(flt.State)...,
+	)
+
+	q, err := toSQL(paginate(sqlStatement, flt.Limit, flt.Page))
+	if err != nil {
+		return []user.User{}, err
+	}
+
+	var fetchedUsers []User
+	if err = list(ctx, r.dbc, TABLE_USERS, "List", &fetchedUsers, q); err != nil {
+		return []user.User{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	userIDs := make([]string, 0, len(fetchedUsers))
+	for _, u := range fetchedUsers {
+		userIDs = append(userIDs, u.ID)
+	}
+	metadataByUser, err := r.metadataOf(ctx, userIDs)
+	if err != nil {
+		return []user.User{}, err
+	}
+
+	var transformedUsers []user.User
+	for _, u := range fetchedUsers {
+		transformedUser := u.transformToUser()
+		transformedUser.Metadata = metadataByUser[u.ID]
+		transformedUsers = append(transformedUsers, transformedUser)
+	}
+
+	return transformedUsers, nil
+}
+
+func (r UserRepository) UpdateByEmail(ctx context.Context, usr user.User) (user.User, error) {
+	if strings.TrimSpace(usr.Email) == "" {
+		return user.User{}, user.ErrInvalidEmail
+	}
+
+	updateQuery, err := toSQL(dialect.Update(TABLE_USERS).Set(
+		goqu.Record{
+			"name":       usr.Name,
+			"updated_at": now(),
+		}).Where(goqu.Ex{
+		"email": usr.Email,
+	}))
+	if err != nil {
+		return user.User{}, err
+	}
+
+	// the metadata given is merged into the existing one
+	return r.update(ctx, "UpdateByEmail", usr, updateQuery, goqu.Ex{"email": usr.Email}, true)
+}
+
+func (r UserRepository) UpdateByID(ctx context.Context, usr user.User) (user.User, error) {
+	if usr.ID == "" || !uuid.IsValid(usr.ID) {
+		return user.User{}, user.ErrInvalidID
+	}
+	if strings.TrimSpace(usr.Email) == "" {
+		return user.User{}, user.ErrInvalidEmail
+	}
+
+	updateQuery, err := toSQL(dialect.Update(TABLE_USERS).Set(
+		goqu.Record{
+			"name":       usr.Name,
+			"email":      usr.Email,
+			"updated_at": now(),
+		}).Where(goqu.Ex{
+		"id": usr.ID,
+	}))
+	if err != nil {
+		return user.User{}, err
+	}
+
+	// the metadata given replaces the existing one
+	return r.update(ctx, "UpdateByID", usr, updateQuery, goqu.Ex{"id": usr.ID}, false)
+}
+
+// update runs the update of the user and reads it back in a transaction,
+// then replaces its metadata or merges the metadata given into it. The
+// metadata of the user is left as it is when it is merged with nil.
+func (r UserRepository) update(ctx context.Context, operation string, usr user.User, updateQuery sqlQuery, where goqu.Ex, merge bool) (user.User, error) {
+	readQuery, err := toSQL(dialect.From(TABLE_USERS).Where(where))
+	if err != nil {
+		return user.User{}, err
+	}
+
+	var userModel User
+	if err := r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return run(ctx, r.dbc, TABLE_USERS, operation, func(ctx context.Context) error {
+			result, err := tx.ExecContext(ctx, updateQuery.query, updateQuery.params...)
+			if err != nil {
+				return checkSQLiteError(err)
+			}
+			if count, err := result.RowsAffected(); err != nil {
+				return err
+			} else if count == 0 {
+				return sql.ErrNoRows
+			}
+			if err := tx.GetContext(ctx, &userModel, readQuery.query, readQuery.params...); err != nil {
+				return err
+			}
+
+			if merge && usr.Metadata == nil {
+				return nil
+			}
+			deleteQuery := dialect.Delete(TABLE_METADATA).Where(goqu.Ex{"user_id": userModel.ID})
+			if merge {
+				keys := make([]string, 0, len(usr.Metadata))
+				for key := range usr.Metadata {
+					keys = append(keys, key)
+				}
+				deleteQuery = deleteQuery.Where(goqu.C("key").In(keys))
+			}
+			q, err := toSQL(deleteQuery)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, q.query, q.params...); err != nil {
+				return checkSQLiteError(err)
+			}
+			return insertUserMetadata(ctx, tx, userModel.ID, usr.Metadata)
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return user.User{}, user.ErrNotExist
+		case errors.Is(err, errDuplicateKey):
+			return user.User{}, user.ErrConflict
+		default:
+			return user.User{}, err
+		}
+	}
+
+	transformedUser := userModel.transformToUser()
+	transformedUser.Metadata = usr.Metadata
+	return transformedUser, nil
+}
+
+func (r UserRepository) Disable(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return user.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return user.ErrInvalidUUID
+	}
+
+	disabledAt := now()
+	q, err := toSQL(dialect.Update(TABLE_USERS).Set(
+		goqu.Record{
+			"disabled_at": goqu.COALESCE(goqu.C("disabled_at"), disabledAt),
+			"updated_at":  disabledAt,
+		}).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return err
+	}
+
+	if err := execAffectingRow(ctx, r.dbc, TABLE_USERS, "Disable", q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.ErrNotExist
+		}
+		return fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return nil
+}
+
+func (r UserRepository) Enable(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return user.ErrInvalidID
+	}
+	if !uuid.IsValid(id) {
+		return user.ErrInvalidUUID
+	}
+
+	q, err := toSQL(dialect.Update(TABLE_USERS).Set(
+		goqu.Record{
+			"disabled_at": nil,
+			"updated_at":  now(),
+		}).Where(goqu.Ex{
+		"id": id,
+	}))
+	if err != nil {
+		return err
+	}
+
+	if err := execAffectingRow(ctx, r.dbc, TABLE_USERS, "Enable", q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.ErrNotExist
+		}
+		return fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return nil
+}
+
+func (r UserRepository) CreateMetadataKey(ctx context.Context, key user.UserMetadataKey) (user.UserMetadataKey, error) {
+	if key.Key == "" {
+		return user.UserMetadataKey{}, user.ErrEmptyKey
+	}
+
+	createdAt := now()
+	metadataKey := UserMetadataKey{
+		Key:         key.Key,
+		Description: sql.NullString{String: key.Description, Valid: true},
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+	q, err := toSQL(dialect.Insert(TABLE_METADATA_KEYS).Rows(
+		goqu.Record{
+			"key":         metadataKey.Key,
+			"description": metadataKey.Description,
+			"created_at":  metadataKey.CreatedAt,
+			"updated_at":  metadataKey.UpdatedAt,
+		}))
+	if err != nil {
+		return user.UserMetadataKey{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_METADATA_KEYS, "Create", q); err != nil {
+		if errors.Is(err, errDuplicateKey) {
+			return user.UserMetadataKey{}, user.ErrKeyAlreadyExists
+		}
+		return user.UserMetadataKey{}, err
+	}
+
+	return metadataKey.transformToUserMetadataKey(), nil
+}
+
+// metadataOf reads the metadata of the users, a user without metadata has
+// an empty one
+func (r UserRepository) metadataOf(ctx context.Context, userIDs []string) (map[string]metadata.Metadata, error) {
+	metadataByUser := make(map[string]metadata.Metadata, len(userIDs))
+	if len(userIDs) == 0 {
+		return metadataByUser, nil
+	}
+	for _, id := range userIDs {
+		metadataByUser[id] = metadata.Metadata{}
+	}
+
+	q, err := toSQL(dialect.From(TABLE_METADATA).Select("user_id", "key", "value").Where(goqu.Ex{
+		"user_id": goqu.Op{"in": userIDs},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var values []userMetadata
+	if err := list(ctx, r.dbc, TABLE_METADATA, "GetByUserID", &values, q); err != nil {
+		return nil, fmt.Errorf("%w: %s", dbErr, err)
+	}
+	for _, v := range values {
+		var value any
+		if err := json.Unmarshal(v.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		metadataByUser[v.UserID][v.Key] = value
+	}
+	return metadataByUser, nil
+}
+
+// insertUserMetadata adds the metadata values of the user in the
+// transaction, json encoded
+func insertUserMetadata(ctx context.Context, tx *sqlx.Tx, userID string, md metadata.Metadata) error {
+	if len(md) == 0 {
+		return nil
+	}
+
+	createdAt := now()
+	var rows []interface{}
+	for key, value := range md {
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s", parseErr, err)
+		}
+		rows = append(rows, goqu.Record{
+			"id":         uuid.NewString(),
+			"user_id":    userID,
+			"key":        key,
+			"value":      string(valueJSON),
+			"created_at": createdAt,
+			"updated_at": createdAt,
+		})
+	}
+
+	q, err := toSQL(dialect.Insert(TABLE_METADATA).Rows(rows...))
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, q.query, q.params...)
+	return checkSQLiteError(err)
+}
+
+// userMetadataGlobExpressions matches users having a metadata value for each
+// key matching the glob, the string values are matched without the quotes
+// of their json encoding
+func userMetadataGlobExpressions(globs map[string]string) []exp.Expression {
+	keys := make([]string, 0, len(globs))
+	for key := range globs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var expressions []exp.Expression
+	for _, key := range keys {
+		expressions = append(expressions, goqu.C("id").In(
+			dialect.From(TABLE_METADATA).Select("user_id").Where(
+				goqu.C("key").Eq(key),
+				like(goqu.Func("json_extract", goqu.C("value"), "$"), globs[key]),
+			),
+		))
+	}
+	return expressions
+}
+
+// userStateExpressions lists the users of the state, every user when it is
+// empty
+func userStateExpressions(state user.State) []exp.Expression {
+	switch state {
+	case user.StateEnabled:
+		return []exp.Expression{goqu.C("disabled_at").IsNull()}
+	case user.StateDisabled:
+		return []exp.Expression{goqu.C("disabled_at").IsNotNull()}
+	}
+	return nil
+}
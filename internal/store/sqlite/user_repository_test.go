@@ -0,0 +1,154 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odpf/shield/core/user"
+	"github.com/odpf/shield/internal/store/sqlite"
+	"github.com/odpf/shield/pkg/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	repository := sqlite.NewUserRepository(client)
+
+	for _, key := range []string{"team", "level"} {
+		_, err := repository.CreateMetadataKey(ctx, user.UserMetadataKey{Key: key, Description: key})
+		require.NoError(t, err)
+	}
+	_, err := repository.CreateMetadataKey(ctx, user.UserMetadataKey{Key: "team"})
+	assert.ErrorIs(t, err, user.ErrKeyAlreadyExists)
+
+	created, err := repository.Create(ctx, user.User{
+		Name:     "Jane",
+		Email:    "jane@acme.io",
+		Metadata: metadata.Metadata{"team": "platform", "level": float64(3)},
+	})
+	require.NoError(t, err)
+	other, err := repository.Create(ctx, user.User{Name: "John", Email: "john@acme.io"})
+	require.NoError(t, err)
+
+	t.Run("should get a user with its metadata", func(t *testing.T) {
+		got, err := repository.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "jane@acme.io", got.Email)
+		assert.Equal(t, metadata.Metadata{"team": "platform", "level": float64(3)}, got.Metadata)
+
+		got, err = repository.GetByEmail(ctx, "john@acme.io")
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, got.ID)
+		assert.Empty(t, got.Metadata)
+
+		_, err = repository.GetByEmail(ctx, "missing@acme.io")
+		assert.ErrorIs(t, err, user.ErrNotExist)
+		_, err = repository.GetByID(ctx, "not-a-uuid")
+		assert.ErrorIs(t, err, user.ErrInvalidUUID)
+
+		users, err := repository.GetByIDs(ctx, []string{created.ID, other.ID})
+		require.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("should refuse a duplicate email", func(t *testing.T) {
+		_, err := repository.Create(ctx, user.User{Name: "Jane", Email: "jane@acme.io"})
+		assert.ErrorIs(t, err, user.ErrConflict)
+	})
+
+	t.Run("should list users matching the filter", func(t *testing.T) {
+		users, err := repository.List(ctx, user.Filter{})
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, created.ID, users[0].ID)
+		assert.Equal(t, "platform", users[0].Metadata["team"])
+
+		users, err = repository.List(ctx, user.Filter{Keyword: "john"})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, other.ID, users[0].ID)
+
+		users, err = repository.List(ctx, user.Filter{Metadata: map[string]string{"team": "plat*"}})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, created.ID, users[0].ID)
+
+		users, err = repository.List(ctx, user.Filter{Email: "*@acme.io", Limit: 1, Page: 2})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, other.ID, users[0].ID)
+	})
+
+	t.Run("should merge the metadata updated by email", func(t *testing.T) {
+		_, err := repository.UpdateByEmail(ctx, user.User{
+			Name:     "Jane Doe",
+			Email:    "jane@acme.io",
+			Metadata: metadata.Metadata{"team": "search"},
+		})
+		require.NoError(t, err)
+
+		got, err := repository.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", got.Name)
+		assert.Equal(t, metadata.Metadata{"team": "search", "level": float64(3)}, got.Metadata)
+
+		_, err = repository.UpdateByEmail(ctx, user.User{Name: "Nobody", Email: "missing@acme.io"})
+		assert.ErrorIs(t, err, user.ErrNotExist)
+	})
+
+	t.Run("should replace the metadata updated by id", func(t *testing.T) {
+		updated, err := repository.UpdateByID(ctx, user.User{
+			ID:       created.ID,
+			Name:     "Jane",
+			Email:    "jane.doe@acme.io",
+			Metadata: metadata.Metadata{"level": float64(4)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "jane.doe@acme.io", updated.Email)
+
+		got, err := repository.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, metadata.Metadata{"level": float64(4)}, got.Metadata)
+
+		_, err = repository.UpdateByID(ctx, user.User{ID: created.ID, Name: "Jane", Email: "john@acme.io"})
+		assert.ErrorIs(t, err, user.ErrConflict)
+	})
+
+	t.Run("should disable a user once", func(t *testing.T) {
+		require.NoError(t, repository.Disable(ctx, other.ID))
+		disabled, err := repository.GetByID(ctx, other.ID)
+		require.NoError(t, err)
+		assert.True(t, disabled.IsDisabled())
+
+		require.NoError(t, repository.Disable(ctx, other.ID))
+		again, err := repository.GetByID(ctx, other.ID)
+		require.NoError(t, err)
+		assert.True(t, disabled.DisabledAt.Equal(again.DisabledAt))
+
+		assert.ErrorIs(t, repository.Disable(ctx, "00000000-0000-0000-0000-000000000000"), user.ErrNotExist)
+	})
+
+	t.Run("should list the users of a state", func(t *testing.T) {
+		disabled, err := repository.List(ctx, user.Filter{State: user.StateDisabled})
+		require.NoError(t, err)
+		require.Len(t, disabled, 1)
+		assert.Equal(t, other.ID, disabled[0].ID)
+		assert.Equal(t, user.StateDisabled, disabled[0].State())
+
+		enabled, err := repository.List(ctx, user.Filter{State: user.StateEnabled})
+		require.NoError(t, err)
+		require.Len(t, enabled, 1)
+		assert.Equal(t, created.ID, enabled[0].ID)
+	})
+
+	t.Run("should enable a disabled user", func(t *testing.T) {
+		require.NoError(t, repository.Enable(ctx, other.ID))
+		enabled, err := repository.GetByID(ctx, other.ID)
+		require.NoError(t, err)
+		assert.False(t, enabled.IsDisabled())
+
+		assert.ErrorIs(t, repository.Enable(ctx, "00000000-0000-0000-0000-000000000000"), user.ErrNotExist)
+	})
+}
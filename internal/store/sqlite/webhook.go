@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/odpf/shield/core/webhook"
+)
+
+// Webhook keeps the events as a json array
+type Webhook struct {
+	ID        string    `db:"id"`
+	URL       string    `db:"url"`
+	Secret    string    `db:"secret"`
+	Events    []byte    `db:"events"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (from Webhook) transformToWebhook() (webhook.Webhook, error) {
+	events, err := unmarshalStrings(from.Events)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	return webhook.Webhook{
+		ID:        from.ID,
+		URL:       from.URL,
+		Secret:    from.Secret,
+		Events:    events,
+		CreatedAt: from.CreatedAt,
+	}, nil
+}
+
+type WebhookDelivery struct {
+	ID            string       `db:"id"`
+	WebhookID     string       `db:"webhook_id"`
+	EventType     string       `db:"event_type"`
+	Payload       []byte       `db:"payload"`
+	Status        string       `db:"status"`
+	Attempts      int          `db:"attempts"`
+	LastError     string       `db:"last_error"`
+	NextAttemptAt time.Time    `db:"next_attempt_at"`
+	CreatedAt     time.Time    `db:"created_at"`
+	DeliveredAt   sql.NullTime `db:"delivered_at"`
+}
+
+func (from WebhookDelivery) transformToDelivery() webhook.Delivery {
+	return webhook.Delivery{
+		ID:            from.ID,
+		WebhookID:     from.WebhookID,
+		EventType:     from.EventType,
+		Payload:       from.Payload,
+		Status:        from.Status,
+		Attempts:      from.Attempts,
+		LastError:     from.LastError,
+		NextAttemptAt: from.NextAttemptAt,
+		CreatedAt:     from.CreatedAt,
+		DeliveredAt:   from.DeliveredAt.Time,
+	}
+}
@@ -0,0 +1,246 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+	"github.com/odpf/shield/core/webhook"
+	"github.com/odpf/shield/pkg/db"
+	"github.com/odpf/shield/pkg/uuid"
+)
+
+type WebhookRepository struct {
+	dbc *db.Client
+}
+
+func NewWebhookRepository(dbc *db.Client) *WebhookRepository {
+	return &WebhookRepository{
+		dbc: dbc,
+	}
+}
+
+func (r WebhookRepository) Create(ctx context.Context, hook webhook.Webhook) (webhook.Webhook, error) {
+	if strings.TrimSpace(hook.URL) == "" || strings.TrimSpace(hook.Secret) == "" || len(hook.Events) == 0 {
+		return webhook.Webhook{}, webhook.ErrInvalidDetail
+	}
+
+	events, err := marshalStrings(hook.Events)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+	hookModel := Webhook{
+		ID:        uuid.NewString(),
+		URL:       hook.URL,
+		Secret:    hook.Secret,
+		Events:    []byte(events),
+		CreatedAt: now(),
+	}
+	q, err := toSQL(dialect.Insert(TABLE_WEBHOOKS).Rows(
+		goqu.Record{
+			"id":         hookModel.ID,
+			"url":        hookModel.URL,
+			"secret":     hookModel.Secret,
+			"events":     events,
+			"created_at": hookModel.CreatedAt,
+		}))
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_WEBHOOKS, "Create", q); err != nil {
+		return webhook.Webhook{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	return hookModel.transformToWebhook()
+}
+
+func (r WebhookRepository) Get(ctx context.Context, id string) (webhook.Webhook, error) {
+	q, err := toSQL(dialect.From(TABLE_WEBHOOKS).Where(goqu.Ex{"id": id}))
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	var hookModel Webhook
+	if err = get(ctx, r.dbc, TABLE_WEBHOOKS, "Get", &hookModel, q); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhook.Webhook{}, webhook.ErrNotExist
+		}
+		return webhook.Webhook{}, err
+	}
+
+	transformedHook, err := hookModel.transformToWebhook()
+	if err != nil {
+		return webhook.Webhook{}, fmt.Errorf("%w: %s", parseErr, err)
+	}
+	return transformedHook, nil
+}
+
+func (r WebhookRepository) List(ctx context.Context) ([]webhook.Webhook, error) {
+	q, err := toSQL(dialect.From(TABLE_WEBHOOKS).Order(goqu.C("created_at").Asc()))
+	if err != nil {
+		return []webhook.Webhook{}, err
+	}
+
+	var hookModels []Webhook
+	if err = list(ctx, r.dbc, TABLE_WEBHOOKS, "List", &hookModels, q); err != nil {
+		return []webhook.Webhook{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedHooks []webhook.Webhook
+	for _, h := range hookModels {
+		transformedHook, err := h.transformToWebhook()
+		if err != nil {
+			return []webhook.Webhook{}, fmt.Errorf("%w: %s", parseErr, err)
+		}
+		transformedHooks = append(transformedHooks, transformedHook)
+	}
+
+	return transformedHooks, nil
+}
+
+func (r WebhookRepository) Delete(ctx context.Context, id string) error {
+	if err := deleteByID(ctx, r.dbc, TABLE_WEBHOOKS, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhook.ErrNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+func (r WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []webhook.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	createdAt := now()
+	rows := make([]interface{}, 0, len(deliveries))
+	for _, dl := range deliveries {
+		rows = append(rows, goqu.Record{
+			"id":              uuid.NewString(),
+			"webhook_id":      dl.WebhookID,
+			"event_type":      dl.EventType,
+			"payload":         string(dl.Payload),
+			"status":          dl.Status,
+			"next_attempt_at": dl.NextAttemptAt,
+			"created_at":      createdAt,
+		})
+	}
+
+	q, err := toSQL(dialect.Insert(TABLE_WEBHOOK_DELIVERIES).Rows(rows...))
+	if err != nil {
+		return err
+	}
+
+	if err = exec(ctx, r.dbc, TABLE_WEBHOOK_DELIVERIES, "CreateDeliveries", q); err != nil {
+		if errors.Is(err, errForeignKeyViolation) {
+			return fmt.Errorf("%w: webhook doesn't exist", webhook.ErrInvalidDetail)
+		}
+		return fmt.Errorf("%w: %s", dbErr, err)
+	}
+	return nil
+}
+
+func (r WebhookRepository) ListDeliveries(ctx context.Context, webhookID string) ([]webhook.Delivery, error) {
+	q, err := toSQL(dialect.From(TABLE_WEBHOOK_DELIVERIES).Where(goqu.Ex{
+		"webhook_id": webhookID,
+	}).Order(goqu.C("created_at").Desc()))
+	if err != nil {
+		return []webhook.Delivery{}, err
+	}
+
+	var deliveryModels []WebhookDelivery
+	if err = list(ctx, r.dbc, TABLE_WEBHOOK_DELIVERIES, "ListDeliveries", &deliveryModels, q); err != nil {
+		return []webhook.Delivery{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedDeliveries []webhook.Delivery
+	for _, d := range deliveryModels {
+		transformedDeliveries = append(transformedDeliveries, d.transformToDelivery())
+	}
+
+	return transformedDeliveries, nil
+}
+
+// ClaimDeliveries pushes the next attempt of the pending deliveries due by
+// now past the lease, the transactions of sqlite take the write lock when
+// they begin so two dispatchers don't claim the same delivery
+func (r WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	claimedAt := now()
+	due, err := toSQL(dialect.From(TABLE_WEBHOOK_DELIVERIES).Where(
+		goqu.Ex{"status": webhook.DeliveryPending},
+		goqu.C("next_attempt_at").Lte(claimedAt),
+	).Order(goqu.C("next_attempt_at").Asc()).Limit(uint(limit)))
+	if err != nil {
+		return []webhook.Delivery{}, err
+	}
+
+	var deliveryModels []WebhookDelivery
+	if err = r.dbc.WithTxn(ctx, sql.TxOptions{}, func(tx *sqlx.Tx) error {
+		return run(ctx, r.dbc, TABLE_WEBHOOK_DELIVERIES, "ClaimDeliveries", func(ctx context.Context) error {
+			if err := tx.SelectContext(ctx, &deliveryModels, due.query, due.params...); err != nil {
+				return err
+			}
+			if len(deliveryModels) == 0 {
+				return nil
+			}
+
+			ids := make([]string, 0, len(deliveryModels))
+			for i := range deliveryModels {
+				deliveryModels[i].NextAttemptAt = claimedAt.Add(lease)
+				ids = append(ids, deliveryModels[i].ID)
+			}
+			claim, err := toSQL(dialect.Update(TABLE_WEBHOOK_DELIVERIES).Set(goqu.Record{
+				"next_attempt_at": claimedAt.Add(lease),
+			}).Where(goqu.C("id").In(ids)))
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, claim.query, claim.params...)
+			return err
+		})
+	}); err != nil {
+		return []webhook.Delivery{}, fmt.Errorf("%w: %s", dbErr, err)
+	}
+
+	var transformedDeliveries []webhook.Delivery
+	for _, d := range deliveryModels {
+		transformedDeliveries = append(transformedDeliveries, d.transformToDelivery())
+	}
+
+	return transformedDeliveries, nil
+}
+
+func (r WebhookRepository) UpdateDelivery(ctx context.Context, dl webhook.Delivery) (webhook.Delivery, error) {
+	update, err := toSQL(dialect.Update(TABLE_WEBHOOK_DELIVERIES).Set(
+		goqu.Record{
+			"status":          dl.Status,
+			"attempts":        dl.Attempts,
+			"last_error":      dl.LastError,
+			"next_attempt_at": dl.NextAttemptAt,
+			"delivered_at":    sql.NullTime{Time: dl.DeliveredAt, Valid: !dl.DeliveredAt.IsZero()},
+		}).Where(goqu.Ex{"id": dl.ID}))
+	if err != nil {
+		return webhook.Delivery{}, err
+	}
+	read, err := toSQL(dialect.From(TABLE_WEBHOOK_DELIVERIES).Where(goqu.Ex{"id": dl.ID}))
+	if err != nil {
+		return webhook.Delivery{}, err
+	}
+
+	var deliveryModel WebhookDelivery
+	if err = updateRow(ctx, r.dbc, TABLE_WEBHOOK_DELIVERIES, "UpdateDelivery", &deliveryModel, update, read); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhook.Delivery{}, webhook.ErrNotExist
+		}
+		return webhook.Delivery{}, err
+	}
+
+	return deliveryModel.transformToDelivery(), nil
+}
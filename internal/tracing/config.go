@@ -0,0 +1,35 @@
+package tracing
+
+import "time"
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterJaeger = "jaeger"
+
+	SamplerAlways = "always"
+	SamplerNever  = "never"
+	SamplerRatio  = "ratio"
+)
+
+type Config struct {
+	// Exporter is where the spans are exported to, otlp or jaeger, the
+	// requests are not traced when it is empty
+	Exporter string `yaml:"exporter" mapstructure:"exporter"`
+	// Endpoint is the url the spans are sent to. With otlp it is the url of
+	// the otlp http receiver, /v1/traces is appended to it unless it already
+	// ends with it. With jaeger it is the url of the http collector, e.g.
+	// http://localhost:14268/api/traces.
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint" default:"http://localhost:4318"`
+	// ServiceName is the name of the service the spans belong to
+	ServiceName string `yaml:"service_name" mapstructure:"service_name" default:"shield"`
+	// Sampler decides which traces started by shield are exported, always,
+	// never or ratio. Traces started by a caller are exported if the
+	// caller sampled them.
+	Sampler string `yaml:"sampler" mapstructure:"sampler" default:"ratio"`
+	// SampleRatio is the fraction of the traces exported by the ratio sampler
+	SampleRatio float64 `yaml:"sample_ratio" mapstructure:"sample_ratio" default:"0.1"`
+	// BatchSize is the number of spans exported at once
+	BatchSize int `yaml:"batch_size" mapstructure:"batch_size" default:"512"`
+	// Timeout is how long the receiver has to accept a batch
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" default:"10s"`
+}
@@ -0,0 +1,9 @@
+package tracing
+
+import "errors"
+
+var (
+	ErrInvalidDetail   = errors.New("invalid tracing detail")
+	ErrUnknownExporter = errors.New("unknown tracing exporter")
+	ErrUnknownSampler  = errors.New("unknown tracing sampler")
+)
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const otlpTracesPath = "/v1/traces"
+
+// NewExporter is the exporter of the config, the otlp exporter sends the
+// spans to an otlp http receiver and the jaeger exporter to the http
+// collector of jaeger. The spans are batched by the span processor of the
+// tracer provider.
+func NewExporter(cfg Config) (sdktrace.SpanExporter, error) {
+	if cfg.Exporter != ExporterOTLP && cfg.Exporter != ExporterJaeger {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExporter, cfg.Exporter)
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || !endpoint.IsAbs() || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: tracing endpoint should be an absolute url", ErrInvalidDetail)
+	}
+	if cfg.BatchSize <= 0 {
+		return nil, fmt.Errorf("%w: tracing batch size should be positive", ErrInvalidDetail)
+	}
+
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		path := strings.TrimSuffix(endpoint.Path, "/")
+		if !strings.HasSuffix(path, otlpTracesPath) {
+			path += otlpTracesPath
+		}
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(endpoint.Host),
+			otlptracehttp.WithURLPath(path),
+			otlptracehttp.WithTimeout(cfg.Timeout),
+		}
+		if endpoint.Scheme == "http" {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		return jaeger.New(jaeger.WithCollectorEndpoint(
+			jaeger.WithEndpoint(endpoint.String()),
+			jaeger.WithHTTPClient(&http.Client{Timeout: cfg.Timeout}),
+		))
+	}
+}
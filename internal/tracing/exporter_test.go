@@ -0,0 +1,126 @@
+package tracing_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewExporter(t *testing.T) {
+	t.Run("should export the spans ended before shutdown over otlp", func(t *testing.T) {
+		var got collectortrace.ExportTraceServiceRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, proto.Unmarshal(body, &got))
+		}))
+		defer server.Close()
+
+		exporter, err := tracing.NewExporter(tracing.Config{Exporter: tracing.ExporterOTLP, Endpoint: server.URL, ServiceName: "shield", BatchSize: 10, Timeout: time.Second})
+		require.NoError(t, err)
+		provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+		_, span := provider.Tracer("test").Start(ctx, "postgres ResourceRepository.Create", trace.WithSpanKind(trace.SpanKindClient))
+		span.SetStatus(codes.Error, "duplicate key")
+		span.End()
+		require.NoError(t, provider.Shutdown(context.Background()))
+
+		require.Len(t, got.GetResourceSpans(), 1)
+		require.Len(t, got.GetResourceSpans()[0].GetScopeSpans(), 1)
+		spans := got.GetResourceSpans()[0].GetScopeSpans()[0].GetSpans()
+		require.Len(t, spans, 1)
+		traceID, spanID := parent.SpanContext().TraceID(), parent.SpanContext().SpanID()
+		assert.Equal(t, traceID[:], spans[0].GetTraceId())
+		assert.Equal(t, spanID[:], spans[0].GetParentSpanId())
+		assert.Equal(t, "postgres ResourceRepository.Create", spans[0].GetName())
+		assert.Equal(t, "STATUS_CODE_ERROR", spans[0].GetStatus().GetCode().String())
+	})
+
+	t.Run("should export the spans to the jaeger collector", func(t *testing.T) {
+		var contentType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/traces" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			contentType = r.Header.Get("Content-Type")
+		}))
+		defer server.Close()
+
+		exporter, err := tracing.NewExporter(tracing.Config{Exporter: tracing.ExporterJaeger, Endpoint: server.URL + "/api/traces", BatchSize: 10, Timeout: time.Second})
+		require.NoError(t, err)
+
+		require.NoError(t, exporter.ExportSpans(context.Background(), tracetest.SpanStubs{{Name: "request"}}.Snapshots()))
+		assert.Equal(t, "application/x-thrift", contentType)
+	})
+
+	t.Run("should return error if the receiver refuses the spans", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		exporter, err := tracing.NewExporter(tracing.Config{Exporter: tracing.ExporterOTLP, Endpoint: server.URL, BatchSize: 10, Timeout: time.Second})
+		require.NoError(t, err)
+
+		err = exporter.ExportSpans(context.Background(), tracetest.SpanStubs{{Name: "request"}}.Snapshots())
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error if the endpoint is not an absolute url", func(t *testing.T) {
+		_, err := tracing.NewExporter(tracing.Config{Exporter: tracing.ExporterOTLP, Endpoint: "localhost:4318", BatchSize: 10})
+		assert.ErrorIs(t, err, tracing.ErrInvalidDetail)
+	})
+}
+func TestSetup(t *testing.T) {
+	t.Run("should return error if the exporter is unknown", func(t *testing.T) {
+		_, err := tracing.Setup(tracing.Config{Exporter: "zipkin", Sampler: tracing.SamplerAlways}, log.NewNoop())
+		assert.ErrorIs(t, err, tracing.ErrUnknownExporter)
+	})
+
+	t.Run("should return error if the sample ratio is out of range", func(t *testing.T) {
+		_, err := tracing.Setup(tracing.Config{Exporter: tracing.ExporterOTLP, Sampler: tracing.SamplerRatio, SampleRatio: 2}, log.NewNoop())
+		assert.ErrorIs(t, err, tracing.ErrInvalidDetail)
+	})
+
+	t.Run("should sample the traces of a caller the way the caller did", func(t *testing.T) {
+		stop, err := tracing.Setup(tracing.Config{
+			Exporter: tracing.ExporterOTLP, Endpoint: "http://localhost:4318", Sampler: tracing.SamplerNever,
+			BatchSize: 10, Timeout: time.Second,
+		}, log.NewNoop())
+		require.NoError(t, err)
+		defer stop()
+
+		var got trace.SpanContext
+		handler := tracing.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = trace.SpanContextFromContext(r.Context())
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/v1beta1/check", nil)
+		req.Header.Set(tracing.TraceparentHeader, traceparent)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.True(t, got.IsSampled())
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1beta1/check", nil))
+		assert.True(t, got.IsValid())
+		assert.False(t, got.IsSampled())
+	})
+}
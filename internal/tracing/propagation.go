@@ -0,0 +1,175 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// w3c trace context headers, they are also the grpc metadata keys the
+// trace context is propagated with
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+const instrumentationName = "github.com/odpf/shield/internal/tracing"
+
+var propagator = propagation.TraceContext{}
+
+// tracer is looked up on every span so the spans are started by the
+// tracer provider registered last
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// HTTPMiddleware traces the http requests, continuing the trace of the
+// traceparent header of the request if any
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer().Start(ctx, r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPMethodKey.String(r.Method), semconv.HTTPTargetKey.String(r.URL.Path)),
+		)
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the websocket connections through, the reverse proxy takes
+// over the connection of an upgraded request
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer doesn't support hijacking")
+	}
+	return h.Hijack()
+}
+
+// GatewayMetadata propagates the span of the http request to the grpc
+// handler the gateway forwards it to, it is a metadata annotator of the
+// gateway
+func GatewayMetadata(ctx context.Context, _ *http.Request) metadata.MD {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	md := metadata.MD{}
+	propagator.Inject(ctx, metadataCarrier(md))
+	return md
+}
+
+// UnaryServerInterceptor traces the grpc requests, continuing the trace of
+// the traceparent metadata of the request if any
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			ctx = propagator.Extract(ctx, metadataCarrier(md))
+		}
+		ctx, span := tracer().Start(ctx, info.FullMethod,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.RPCSystemKey.String("grpc")),
+		)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		setStatus(span, err)
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor traces the grpc calls made while handling a traced
+// request and propagates the trace to the server called, calls made outside
+// of a trace are not traced
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, span := tracer().Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.RPCSystemKey.String("grpc")),
+		)
+		defer span.End()
+
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if ok {
+			md = md.Copy()
+		} else {
+			md = metadata.MD{}
+		}
+		propagator.Inject(ctx, metadataCarrier(md))
+		ctx = metadata.NewOutgoingContext(ctx, md)
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		setStatus(span, err)
+		return err
+	}
+}
+
+// metadataCarrier lets the trace context be propagated with grpc metadata
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// setStatus marks the span failed with the grpc status of the error
+func setStatus(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	st := status.Convert(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int64(int64(st.Code())))
+	span.SetStatus(codes.Error, st.Message())
+}
@@ -0,0 +1,108 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/odpf/salt/log"
+	"github.com/odpf/shield/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent = "00-" + traceID + "-00f067aa0ba902b7-01"
+)
+
+func setupTracing(t *testing.T) {
+	t.Helper()
+	stop, err := tracing.Setup(tracing.Config{}, log.NewNoop())
+	require.NoError(t, err)
+	t.Cleanup(stop)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	setupTracing(t)
+	interceptor := tracing.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/odpf.shield.v1beta1.ShieldService/CheckResourcePermission"}
+
+	t.Run("should continue the trace of the traceparent metadata", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(tracing.TraceparentHeader, traceparent))
+
+		var got trace.SpanContext
+		_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			got = trace.SpanContextFromContext(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, traceID, got.TraceID().String())
+		assert.True(t, got.IsSampled())
+	})
+
+	t.Run("should start a trace if the request has none", func(t *testing.T) {
+		var got trace.SpanContext
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			got = trace.SpanContextFromContext(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.True(t, got.IsValid())
+		assert.NotEqual(t, traceID, got.TraceID().String())
+	})
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	setupTracing(t)
+	interceptor := tracing.UnaryClientInterceptor()
+	method := "/authzed.api.v1.PermissionsService/CheckPermission"
+
+	t.Run("should propagate the trace to the server called", func(t *testing.T) {
+		ctx, span := otel.Tracer("test").Start(context.Background(), "request")
+		defer span.End()
+
+		var got []string
+		err := interceptor(ctx, method, nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			got = md.Get(tracing.TraceparentHeader)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Contains(t, got[0], span.SpanContext().TraceID().String())
+		assert.NotContains(t, got[0], span.SpanContext().SpanID().String())
+	})
+
+	t.Run("should not trace calls made outside of a trace", func(t *testing.T) {
+		var got metadata.MD
+		err := interceptor(context.Background(), method, nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			got, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Empty(t, got.Get(tracing.TraceparentHeader))
+	})
+}
+
+func TestGatewayMetadata(t *testing.T) {
+	setupTracing(t)
+
+	var got metadata.MD
+	handler := tracing.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = tracing.GatewayMetadata(r.Context(), r)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1beta1/check", nil)
+	req.Header.Set(tracing.TraceparentHeader, traceparent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	values := got.Get(tracing.TraceparentHeader)
+	require.Len(t, values, 1)
+	assert.Contains(t, values[0], traceID)
+}
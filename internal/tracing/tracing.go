@@ -0,0 +1,82 @@
+// Package tracing traces the requests across the http gateway, the grpc
+// handlers, the postgres queries and the spicedb calls with opentelemetry,
+// the trace context is propagated with the w3c traceparent header and the
+// spans are exported over otlp or to jaeger
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odpf/salt/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+// Setup registers the tracer provider of the config as the global one, the
+// returned func exports the spans not exported yet. Spans are not exported
+// when no exporter is configured, the requests still get a trace id to be
+// logged with and the trace of a caller is still propagated.
+func Setup(cfg Config, logger log.Logger) (func(), error) {
+	otel.SetTextMapPropagator(propagator)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("failed to trace requests", "err", err)
+	}))
+
+	if cfg.Exporter == "" {
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.NeverSample())))
+		otel.SetTracerProvider(provider)
+		return func() { _ = provider.Shutdown(context.Background()) }, nil
+	}
+
+	sampler, err := newSampler(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter, err := NewExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// spans ended while the queue is full are dropped
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithMaxExportBatchSize(cfg.BatchSize),
+			sdktrace.WithMaxQueueSize(cfg.BatchSize*8),
+			sdktrace.WithExportTimeout(cfg.Timeout),
+		),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	logger.Info("tracing requests", "exporter", cfg.Exporter, "endpoint", cfg.Endpoint, "sampler", cfg.Sampler)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			logger.Warn("failed to export spans", "err", err)
+		}
+	}, nil
+}
+
+// newSampler samples the traces started by a caller the way the caller
+// did, the sampler of the config decides for the traces started by shield
+func newSampler(cfg Config) (sdktrace.Sampler, error) {
+	switch cfg.Sampler {
+	case SamplerAlways:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case SamplerNever:
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case SamplerRatio:
+		if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+			return nil, fmt.Errorf("%w: sample ratio should be between 0 and 1", ErrInvalidDetail)
+		}
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio)), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSampler, cfg.Sampler)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -15,7 +16,10 @@ func main() {
 		cliConfig = &cmd.Config{}
 	}
 	if err := cmd.New(cliConfig).Execute(); err != nil {
-		fmt.Printf("%+v", err)
+		// validation failures were already reported in the requested output format
+		if !errors.Is(err, cmd.ErrValidationFailed) {
+			fmt.Printf("%+v", err)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,91 @@
+package body_extractor
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// FormPayloadHandler reads the fields of a multipart/form-data or of an
+// application/x-www-form-urlencoded body of ContentType
+type FormPayloadHandler struct {
+	ContentType string
+}
+
+// Extract finds the field of key, the parts of a multipart body are only read
+// up to the field, so the fields sent before the files of a body are found
+// without reading the files
+func (h FormPayloadHandler) Extract(body *io.ReadCloser, key string) (interface{}, error) {
+	mediaType, params, err := mime.ParseMediaType(h.ContentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse content type")
+	}
+
+	switch mediaType {
+	case "multipart/form-data":
+		return h.extractMultipart(body, params["boundary"], key)
+	case "application/x-www-form-urlencoded":
+		reqBody, err := ioutil.ReadAll(*body)
+		if err != nil {
+			return nil, err
+		}
+		defer (*body).Close()
+
+		// repopulate body
+		*body = ioutil.NopCloser(bytes.NewBuffer(reqBody))
+		values, err := url.ParseQuery(string(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		if !values.Has(key) {
+			return nil, errors.Errorf("failed to find field: %s", key)
+		}
+		return values.Get(key), nil
+	default:
+		return nil, errors.Errorf("not a form payload: %s", mediaType)
+	}
+}
+
+func (h FormPayloadHandler) extractMultipart(body *io.ReadCloser, boundary, key string) (interface{}, error) {
+	if boundary == "" {
+		return nil, errors.New("multipart boundary not found")
+	}
+
+	// the parts read are kept to repopulate the body
+	var read bytes.Buffer
+	defer func() {
+		*body = readCloser{
+			Reader: io.MultiReader(&read, *body),
+			Closer: *body,
+		}
+	}()
+
+	reader := multipart.NewReader(io.TeeReader(*body, &read), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.Errorf("failed to find field: %s", key)
+			}
+			return nil, err
+		}
+		if part.FormName() != key || part.FileName() != "" {
+			continue
+		}
+		value, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		return string(value), nil
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
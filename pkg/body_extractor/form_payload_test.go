@@ -0,0 +1,88 @@
+package body_extractor
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func multipartBody(t *testing.T) (string, []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	assert.NoError(t, w.WriteField("project", "ab657ae7-8c9e-45eb-9862-dd9ceb6d5c71"))
+	file, err := w.CreateFormFile("upload", "data.csv")
+	assert.NoError(t, err)
+	_, err = file.Write(bytes.Repeat([]byte("a,b,c\n"), 1000))
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteField("name", "after-the-file"))
+	assert.NoError(t, w.Close())
+	return w.FormDataContentType(), buf.Bytes()
+}
+
+func TestFormPayloadHandler(t *testing.T) {
+	t.Run("multipart field", func(t *testing.T) {
+		contentType, payload := multipartBody(t)
+		body := ioutil.NopCloser(bytes.NewReader(payload))
+
+		value, err := FormPayloadHandler{ContentType: contentType}.Extract(&body, "project")
+		assert.NoError(t, err)
+		assert.Equal(t, "ab657ae7-8c9e-45eb-9862-dd9ceb6d5c71", value)
+
+		// the body is left as it was
+		read, err := ioutil.ReadAll(body)
+		assert.NoError(t, err)
+		assert.Equal(t, payload, read)
+	})
+
+	t.Run("multipart field before the end of a partial body", func(t *testing.T) {
+		contentType, payload := multipartBody(t)
+		body := ioutil.NopCloser(io.MultiReader(bytes.NewReader(payload[:len(payload)/2]), partialBody{}))
+
+		value, err := FormPayloadHandler{ContentType: contentType}.Extract(&body, "project")
+		assert.NoError(t, err)
+		assert.Equal(t, "ab657ae7-8c9e-45eb-9862-dd9ceb6d5c71", value)
+
+		_, err = FormPayloadHandler{ContentType: contentType}.Extract(&body, "name")
+		assert.Error(t, err)
+	})
+
+	t.Run("multipart file is not a field", func(t *testing.T) {
+		contentType, payload := multipartBody(t)
+		body := ioutil.NopCloser(bytes.NewReader(payload))
+
+		_, err := FormPayloadHandler{ContentType: contentType}.Extract(&body, "upload")
+		assert.Error(t, err)
+	})
+
+	t.Run("urlencoded field", func(t *testing.T) {
+		body := ioutil.NopCloser(strings.NewReader("project=p1&name=n1"))
+
+		value, err := FormPayloadHandler{ContentType: "application/x-www-form-urlencoded"}.Extract(&body, "name")
+		assert.NoError(t, err)
+		assert.Equal(t, "n1", value)
+
+		_, err = FormPayloadHandler{ContentType: "application/x-www-form-urlencoded"}.Extract(&body, "missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("not a form", func(t *testing.T) {
+		body := ioutil.NopCloser(strings.NewReader(`{"name":"n1"}`))
+
+		_, err := FormPayloadHandler{ContentType: "application/json"}.Extract(&body, "name")
+		assert.Error(t, err)
+	})
+}
+
+// partialBody fails reading the rest of a partial body
+type partialBody struct{}
+
+func (partialBody) Read(p []byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
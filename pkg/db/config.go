@@ -9,4 +9,17 @@ type Config struct {
 	MaxOpenConns        int           `yaml:"max_open_conns"     mapstructure:"max_open_conns"     default:"10"`
 	ConnMaxLifeTime     time.Duration `yaml:"conn_max_life_time" mapstructure:"conn_max_life_time" default:"10ms"`
 	MaxQueryTimeoutInMS time.Duration `yaml:"max_query_timeout"  mapstructure:"max_query_timeout"  default:"100ms"`
+	MaxTxnRetries       int           `yaml:"max_txn_retries"    mapstructure:"max_txn_retries"    default:"3"`
+	LogQueries          bool          `yaml:"log_queries"        mapstructure:"log_queries"        default:"false"`
+
+	// ReadURL is the url of a read replica, the reads that don't need the
+	// latest writes are routed to it and fall back to the primary when it
+	// fails. It shares the pool settings and the password of the primary
+	ReadURL string `yaml:"read_url" mapstructure:"read_url"`
+	// ConnMaxIdleTime closes the connections idle for longer, zero keeps them
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" mapstructure:"conn_max_idle_time"`
+
+	// Password replaces the password of the url, a secret reference that
+	// is fetched again when it expires for the connections opened after
+	Password string `yaml:"password" mapstructure:"password"`
 }
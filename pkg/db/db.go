@@ -3,44 +3,185 @@ package db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"net/url"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Client struct {
 	*sqlx.DB
-	queryTimeOut time.Duration
+	queryTimeOut  time.Duration
+	maxTxnRetries int
+	logQueries    bool
+	// replica takes the reads made through Reader when set
+	replica *replica
 }
 
 func New(cfg Config) (*Client, error) {
-	d, err := sqlx.Open(cfg.Driver, cfg.URL)
+	return NewWithPassword(cfg, nil)
+}
+
+// NewWithPassword sets the password returned by password in the url of
+// every connection it opens, so the connections opened after a password
+// rotated use the new one. The password of the url is used when it is nil.
+func NewWithPassword(cfg Config, password func(ctx context.Context) (string, error)) (*Client, error) {
+	if cfg.ReadURL != "" && IsSQLite(cfg.Driver) {
+		return nil, errors.New("a read url can't be set for a sqlite database")
+	}
+	if IsSQLite(cfg.Driver) {
+		if password != nil {
+			return nil, errors.New("a password can't be set for a sqlite database")
+		}
+		cfg.URL = sqliteDSN(cfg.URL)
+	}
+
+	d, err := open(cfg, cfg.URL, password)
 	if err != nil {
 		return nil, err
 	}
+	client := &Client{DB: d, queryTimeOut: cfg.MaxQueryTimeoutInMS, maxTxnRetries: cfg.MaxTxnRetries, logQueries: cfg.LogQueries}
+
+	if cfg.ReadURL != "" {
+		// the replica is reached with the credentials of the primary
+		rd, err := open(cfg, cfg.ReadURL, password)
+		if err != nil {
+			d.Close()
+			return nil, errors.Wrap(err, "read replica")
+		}
+		client.replica = &replica{db: rd}
+	}
+	return client, nil
+}
+
+// open opens a pool of connections to the url with the pool settings of cfg
+func open(cfg Config, url string, password func(ctx context.Context) (string, error)) (*sqlx.DB, error) {
+	var d *sqlx.DB
+	if password == nil {
+		var err error
+		if d, err = sqlx.Open(cfg.Driver, url); err != nil {
+			return nil, err
+		}
+	} else {
+		drv, err := driverOf(cfg.Driver)
+		if err != nil {
+			return nil, err
+		}
+		d = sqlx.NewDb(sql.OpenDB(passwordConnector{driver: drv, url: url, password: password}), cfg.Driver)
+	}
 
-	if err = d.Ping(); err != nil {
+	if err := d.Ping(); err != nil {
+		d.Close()
 		return nil, err
 	}
 
 	d.SetMaxIdleConns(cfg.MaxIdleConns)
 	d.SetMaxOpenConns(cfg.MaxOpenConns)
 	d.SetConnMaxLifetime(cfg.ConnMaxLifeTime)
+	d.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return d, nil
+}
+
+func driverOf(name string) (driver.Driver, error) {
+	// opening doesn't connect, it only looks the driver up
+	d, err := sql.Open(name, "")
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+	return d.Driver(), nil
+}
+
+// WithPassword returns the url with its password replaced
+func WithPassword(dsn, password string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.Scheme == "" {
+		return "", errors.New("a password can only be set in a db url like postgres://user@host:5432/db")
+	}
+	username := ""
+	if u.User != nil {
+		username = u.User.Username()
+	}
+	u.User = url.UserPassword(username, password)
+	return u.String(), nil
+}
 
-	return &Client{DB: d, queryTimeOut: cfg.MaxQueryTimeoutInMS}, err
+// passwordConnector opens the connections with the current password
+type passwordConnector struct {
+	driver   driver.Driver
+	url      string
+	password func(ctx context.Context) (string, error)
+}
+
+func (c passwordConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	password, err := c.password(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := WithPassword(c.url, password)
+	if err != nil {
+		return nil, err
+	}
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return connector.Connect(ctx)
+	}
+	return c.driver.Open(dsn)
+}
+
+func (c passwordConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// MaxTxnRetries is the number of times a transaction aborted by a
+// serialization failure or a deadlock should be retried
+func (c Client) MaxTxnRetries() int {
+	return c.maxTxnRetries
+}
+
+// LogQueries reports whether repositories supporting it
+// should log the queries they run
+func (c Client) LogQueries() bool {
+	return c.logQueries
+}
+
+// Close closes the primary and the replica
+func (c Client) Close() error {
+	if c.replica != nil {
+		if err := c.replica.db.Close(); err != nil {
+			return err
+		}
+	}
+	return c.DB.Close()
 }
 
 func (c Client) WithTimeout(ctx context.Context, op func(ctx context.Context) error) (err error) {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, c.queryTimeOut)
 	defer cancel()
 
+	ctxWithTimeout, end := startSpan(ctxWithTimeout, "postgres")
+	defer func() { end(err) }()
+
 	return op(ctxWithTimeout)
 }
 
 // Handling transactions: https://stackoverflow.com/a/23502629/8244298
 func (c Client) WithTxn(ctx context.Context, txnOptions sql.TxOptions, txFunc func(*sqlx.Tx) error) (err error) {
+	ctx, end := startSpan(ctx, "postgres txn")
+	defer func() { end(err) }()
+
 	txn, err := c.BeginTxx(ctx, &txnOptions)
 	if err != nil {
 		return err
@@ -71,3 +212,29 @@ func (c Client) WithTxn(ctx context.Context, txnOptions sql.TxOptions, txFunc fu
 	err = txFunc(txn)
 	return err
 }
+
+// startSpan traces the queries made while handling a traced request, the
+// span is named after the function running them
+func startSpan(ctx context.Context, prefix string) (context.Context, func(error)) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, func(error) {}
+	}
+
+	name := prefix
+	if pc, _, _, ok := runtime.Caller(2); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			name += " " + fn.Name()[strings.LastIndex(fn.Name(), "/")+1:]
+		}
+	}
+
+	ctx, span := otel.Tracer("github.com/odpf/shield/pkg/db").Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL),
+	)
+	return ctx, func(err error) {
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
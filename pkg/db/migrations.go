@@ -1,9 +1,13 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database"
@@ -13,6 +17,12 @@ import (
 	"github.com/golang-migrate/migrate/v4/source/httpfs"
 )
 
+var (
+	ErrMigrationsPending = errors.New("migrations pending")
+	ErrMigrationDirty    = errors.New("migration failed, database is dirty")
+	ErrInvalidRollback   = errors.New("invalid rollback")
+)
+
 func RunMigrations(config Config, embeddedMigrations embed.FS, resourcePath string) error {
 	m, err := getMigrationInstance(config, embeddedMigrations, resourcePath)
 	if err != nil {
@@ -41,10 +51,172 @@ func RunRollback(config Config, embeddedMigrations embed.FS, resourcePath string
 	return err
 }
 
+// RunRollbackTo migrates the database down to the version, the migrations
+// after it are rolled back, all of them are if the version is 0
+func RunRollbackTo(config Config, embeddedMigrations embed.FS, resourcePath string, version uint) error {
+	m, err := getMigrationInstance(config, embeddedMigrations, resourcePath)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	current, _, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	if version > current {
+		return fmt.Errorf("%w: version %d is not before the version of the database %d", ErrInvalidRollback, version, current)
+	}
+
+	if version == 0 {
+		err = m.Down()
+	} else {
+		err = m.Migrate(version)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: no migration with version %d", ErrInvalidRollback, version)
+	}
+	if err == migrate.ErrNoChange || err == nil {
+		return nil
+	}
+	return err
+}
+
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+type MigrationStatus struct {
+	// Version is the version of the last migration applied to the database,
+	// 0 if none was
+	Version uint
+	// Dirty is set if the last migration applied failed
+	Dirty      bool
+	Migrations []Migration
+}
+
+// GetMigrationStatus lists the migrations with whether they are applied
+// to the database
+func GetMigrationStatus(config Config, embeddedMigrations embed.FS, resourcePath string) (MigrationStatus, error) {
+	m, err := getMigrationInstance(config, embeddedMigrations, resourcePath)
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+	defer m.Close()
+
+	var status MigrationStatus
+	status.Version, status.Dirty, err = m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return MigrationStatus{}, err
+	}
+
+	src, err := httpfs.New(http.FS(embeddedMigrations), resourcePath)
+	if err != nil {
+		return MigrationStatus{}, fmt.Errorf("db migrator: %v", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	for err == nil {
+		r, name, readErr := src.ReadUp(version)
+		if readErr != nil {
+			return MigrationStatus{}, readErr
+		}
+		r.Close()
+
+		status.Migrations = append(status.Migrations, Migration{
+			Version: version,
+			Name:    name,
+			Applied: version <= status.Version,
+		})
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return MigrationStatus{}, err
+	}
+	return status, nil
+}
+
 func getMigrationInstance(config Config, embeddedMigrations embed.FS, resourcePath string) (*migrate.Migrate, error) {
 	src, err := httpfs.New(http.FS(embeddedMigrations), resourcePath)
 	if err != nil {
 		return &migrate.Migrate{}, fmt.Errorf("db migrator: %v", err)
 	}
-	return migrate.NewWithSourceInstance("httpfs", src, config.URL)
+	dbURL := config.URL
+	if IsSQLite(config.Driver) {
+		dbURL = sqliteMigrationURL(config.URL)
+	}
+	return migrate.NewWithSourceInstance("httpfs", src, dbURL)
+}
+
+// LatestMigration is the version of the last of the migrations
+func LatestMigration(embeddedMigrations embed.FS, resourcePath string) (uint, error) {
+	src, err := httpfs.New(http.FS(embeddedMigrations), resourcePath)
+	if err != nil {
+		return 0, fmt.Errorf("db migrator: %v", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// MigrationVersion is the version of the last migration applied to the
+// database, 0 if none was, the migration is dirty if it failed
+func (c Client) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	query := "SELECT to_regclass('schema_migrations') IS NOT NULL"
+	if IsSQLite(c.DriverName()) {
+		query = "SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')"
+	}
+	var exists bool
+	if err := c.QueryRowContext(ctx, query).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var version int64
+	var dirty bool
+	if err := c.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+// CheckMigrations returns an error if the last of the migrations is not
+// applied to the database or the last one applied failed
+func (c Client) CheckMigrations(ctx context.Context, embeddedMigrations embed.FS, resourcePath string) error {
+	latest, err := LatestMigration(embeddedMigrations, resourcePath)
+	if err != nil {
+		return err
+	}
+	version, dirty, err := c.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: version %d", ErrMigrationDirty, version)
+	}
+	if version < latest {
+		return fmt.Errorf("%w: database at version %d, latest is %d", ErrMigrationsPending, version, latest)
+	}
+	return nil
 }
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// replicaRetryInterval is how long the reads stay on the primary after the
+// replica failed one
+const replicaRetryInterval = 10 * time.Second
+
+// Reader runs the reads that don't need to see the latest writes
+type Reader interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// replica is the read only database the reads are routed to
+type replica struct {
+	db *sqlx.DB
+	// downUntil is the unix nano time until which the reads go to the
+	// primary after a failure of the replica
+	downUntil int64
+	fallbacks uint64
+}
+
+func (r *replica) up() bool {
+	return time.Now().UnixNano() >= atomic.LoadInt64(&r.downUntil)
+}
+
+func (r *replica) fellBack() {
+	atomic.StoreInt64(&r.downUntil, time.Now().Add(replicaRetryInterval).UnixNano())
+	atomic.AddUint64(&r.fallbacks, 1)
+}
+
+// Reader is the replica when one is set and up, the reads failing on the
+// replica for a reason other than the query itself are made again on the
+// primary, which takes the reads until the replica is tried again
+func (c Client) Reader() Reader {
+	if c.replica == nil {
+		return c.DB
+	}
+	return replicaReader{primary: c.DB, replica: c.replica}
+}
+
+// ReplicaStats are the stats of the connection pool of the replica, zero
+// when there is no replica
+func (c Client) ReplicaStats() sql.DBStats {
+	if c.replica == nil {
+		return sql.DBStats{}
+	}
+	return c.replica.db.Stats()
+}
+
+// ReplicaFallbacks is the number of reads made again on the primary after
+// failing on the replica
+func (c Client) ReplicaFallbacks() uint64 {
+	if c.replica == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&c.replica.fallbacks)
+}
+
+// HasReplica tells whether the reads are routed to a replica
+func (c Client) HasReplica() bool {
+	return c.replica != nil
+}
+
+type replicaReader struct {
+	primary *sqlx.DB
+	replica *replica
+}
+
+func (r replicaReader) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.read(ctx, func(db *sqlx.DB) error {
+		return db.GetContext(ctx, dest, query, args...)
+	})
+}
+
+func (r replicaReader) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.read(ctx, func(db *sqlx.DB) error {
+		return db.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+func (r replicaReader) read(ctx context.Context, op func(db *sqlx.DB) error) error {
+	if !r.replica.up() {
+		return op(r.primary)
+	}
+	err := op(r.replica.db)
+	if !shouldFallBack(ctx, err) {
+		return err
+	}
+	r.replica.fellBack()
+	return op(r.primary)
+}
+
+// shouldFallBack tells whether a read failed because of the replica rather
+// than the query: it couldn't be reached, is shutting down or cancelled the
+// query for a conflict with the recovery
+func shouldFallBack(ctx context.Context, err error) bool {
+	if err == nil || errors.Is(err, sql.ErrNoRows) || ctx.Err() != nil {
+		return false
+	}
+
+	var code string
+	var pgErr *pgconn.PgError
+	var pqErr *pq.Error
+	switch {
+	case errors.As(err, &pgErr):
+		code = pgErr.Code
+	case errors.As(err, &pqErr):
+		code = string(pqErr.Code)
+	default:
+		// not an error of the server, the replica couldn't be reached
+		return true
+	}
+	// connection exceptions, operator intervention and serialization
+	// failures, the latter being the recovery conflicts on a replica
+	return strings.HasPrefix(code, "08") || strings.HasPrefix(code, "57") || code == "40001"
+}
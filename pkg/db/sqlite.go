@@ -0,0 +1,38 @@
+package db
+
+import (
+	"net/url"
+
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// DriverSQLite is the driver of a sqlite database, the url of the database
+// is the path of its file. The driver is written in go, so the binaries
+// built without cgo have it too.
+const DriverSQLite = "sqlite"
+
+// sqliteOptions turns on the foreign keys, which sqlite doesn't enforce by
+// default, waits for a database locked by another connection instead of
+// failing and takes the write lock when a transaction begins, so two
+// transactions reading before they write can't deadlock
+var sqliteOptions = url.Values{
+	"_pragma": {"foreign_keys(1)", "busy_timeout(5000)"},
+	"_txlock": {"immediate"},
+}
+
+// IsSQLite tells whether the driver is sqlite
+func IsSQLite(driver string) bool {
+	return driver == DriverSQLite
+}
+
+// sqliteDSN is the dsn the database at the path is opened with
+func sqliteDSN(path string) string {
+	return "file:" + path + "?" + sqliteOptions.Encode()
+}
+
+// sqliteMigrationURL is the url the migrations of the database at the path
+// are run with
+func sqliteMigrationURL(path string) string {
+	return DriverSQLite + "://" + path + "?" + sqliteOptions.Encode()
+}
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// UnaryServerInterceptor authenticates and authorizes the requests before
+// their handler, the user is set on the context of the handler. The requests
+// fail with Unauthenticated when they are not authenticated,
+// PermissionDenied when they are not allowed and InvalidArgument when the
+// attributes of the permissions are missing in them.
+func (m *Middleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		rule, matched := m.matchGRPC(info.FullMethod)
+		if matched && rule.Public {
+			return handler(ctx, req)
+		}
+
+		creds := m.grpcCredentials(ctx)
+		usr, err := m.authenticate(ctx, creds)
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		ctx = context.WithValue(ctx, userContextKey{}, usr)
+		if err := m.authorizeGRPC(ctx, creds, usr, rule, matched, req); err != nil {
+			return nil, grpcError(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates and authorizes the streams, the
+// streams of rules with payload attributes are authorized on their first
+// message
+func (m *Middleware) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		rule, matched := m.matchGRPC(info.FullMethod)
+		if matched && rule.Public {
+			return handler(srv, ss)
+		}
+
+		ctx := ss.Context()
+		creds := m.grpcCredentials(ctx)
+		usr, err := m.authenticate(ctx, creds)
+		if err != nil {
+			return grpcError(err)
+		}
+		stream := &authorizedStream{
+			ServerStream: ss,
+			ctx:          context.WithValue(ctx, userContextKey{}, usr),
+		}
+		stream.authorize = func(msg interface{}) error {
+			return grpcError(m.authorizeGRPC(stream.ctx, creds, usr, rule, matched, msg))
+		}
+
+		if !matched || !hasPayloadAttribute(rule) {
+			if err := stream.authorize(nil); err != nil {
+				return err
+			}
+			stream.authorized = true
+		}
+		return handler(srv, stream)
+	}
+}
+
+// authorizedStream authorizes the stream on the first message it receives
+// unless it is authorized already
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx        context.Context
+	authorize  func(msg interface{}) error
+	authorized bool
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedStream) RecvMsg(msg interface{}) error {
+	if err := s.ServerStream.RecvMsg(msg); err != nil {
+		return err
+	}
+	if !s.authorized {
+		if err := s.authorize(msg); err != nil {
+			return err
+		}
+		s.authorized = true
+	}
+	return nil
+}
+
+func (m *Middleware) authorizeGRPC(ctx context.Context, creds credentials, usr *shieldv1beta1.User, rule Rule, matched bool, req interface{}) error {
+	if !matched {
+		if m.cfg.AllowUnmatched {
+			return nil
+		}
+		return ErrNoRule
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return m.authorize(ctx, creds, usr, rule, func(attr Attribute) (string, error) {
+		if value, ok := attributeValue(attr, func(key string) string { return first(md.Get(key)) }); ok {
+			return value, nil
+		}
+		if attr.Type == AttributeTypeGRPCPayload {
+			msg, ok := req.(proto.Message)
+			if !ok {
+				return "", fmt.Errorf("%w: %s", ErrMissingAttribute, attr.Key)
+			}
+			return payloadField(msg.ProtoReflect(), attr.Key), nil
+		}
+		return "", fmt.Errorf("attribute type %s isn't supported on grpc requests", attr.Type)
+	})
+}
+
+func (m *Middleware) grpcCredentials(ctx context.Context) credentials {
+	md, _ := metadata.FromIncomingContext(ctx)
+	creds := credentials{authorization: first(md.Get("authorization"))}
+	if m.cfg.IdentityHeader != "" {
+		creds.identity = first(md.Get(m.cfg.IdentityHeader))
+	}
+	return creds
+}
+
+func (m *Middleware) matchGRPC(fullMethod string) (Rule, bool) {
+	for _, rule := range m.cfg.Rules {
+		if rule.Method == fullMethod {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func hasPayloadAttribute(rule Rule) bool {
+	for _, attr := range rule.Attributes {
+		if attr.Type == AttributeTypeGRPCPayload {
+			return true
+		}
+	}
+	return false
+}
+
+// payloadField is the value of the field of the message, the fields of
+// nested messages are separated by dots
+func payloadField(msg protoreflect.Message, path string) string {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		field := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if field == nil || field.IsList() || field.IsMap() {
+			return ""
+		}
+		if i < len(names)-1 {
+			if field.Message() == nil || !msg.Has(field) {
+				return ""
+			}
+			msg = msg.Get(field).Message()
+			continue
+		}
+		if field.Message() != nil {
+			return ""
+		}
+		return msg.Get(field).String()
+	}
+	return ""
+}
+
+func grpcError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, ErrPermissionDenied), errors.Is(err, ErrNoRule):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrMissingAttribute):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
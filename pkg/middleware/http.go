@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Handler authenticates and authorizes the requests before next serves them,
+// the user is set on the context of the request. The requests are answered
+// with 401 when they are not authenticated, 403 when they are not allowed
+// and 400 when the attributes of the permissions are missing in them.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rule, params, matched := m.matchHTTP(req)
+		if matched && rule.Public {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		creds := credentials{authorization: req.Header.Get("Authorization")}
+		if m.cfg.IdentityHeader != "" {
+			creds.identity = req.Header.Get(m.cfg.IdentityHeader)
+		}
+		usr, err := m.authenticate(req.Context(), creds)
+		if err != nil {
+			httpError(rw, err)
+			return
+		}
+		ctx := context.WithValue(req.Context(), userContextKey{}, usr)
+
+		switch {
+		case matched:
+			err = m.authorize(ctx, creds, usr, rule, func(attr Attribute) (string, error) {
+				return httpAttribute(req, params, attr)
+			})
+		case !m.cfg.AllowUnmatched:
+			err = ErrNoRule
+		}
+		if err != nil {
+			httpError(rw, err)
+			return
+		}
+		next.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+func httpError(rw http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		code = http.StatusUnauthorized
+	case errors.Is(err, ErrPermissionDenied), errors.Is(err, ErrNoRule):
+		code = http.StatusForbidden
+	case errors.Is(err, ErrMissingAttribute):
+		code = http.StatusBadRequest
+	}
+	http.Error(rw, http.StatusText(code), code)
+}
+
+func httpAttribute(req *http.Request, params map[string]string, attr Attribute) (string, error) {
+	if value, ok := attributeValue(attr, req.Header.Get); ok {
+		return value, nil
+	}
+	switch attr.Type {
+	case AttributeTypeQuery:
+		return req.URL.Query().Get(attr.Key), nil
+	case AttributeTypePathParam:
+		return params[attr.Key], nil
+	}
+	return "", fmt.Errorf("attribute type %s isn't supported on http requests", attr.Type)
+}
+
+// matchHTTP returns the first rule matching the request and the path params
+// of its path
+func (m *Middleware) matchHTTP(req *http.Request) (Rule, map[string]string, bool) {
+	for _, rule := range m.cfg.Rules {
+		if rule.Method != "" && !strings.EqualFold(rule.Method, req.Method) {
+			continue
+		}
+		if params, ok := matchPath(rule.Path, req.URL.Path); ok {
+			return rule, params, true
+		}
+	}
+	return Rule{}, nil, false
+}
+
+func matchPath(pattern, path string) (map[string]string, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	params := map[string]string{}
+	for i, segment := range patternSegments {
+		if segment == "*" && i == len(patternSegments)-1 {
+			return params, true
+		}
+		if i >= len(pathSegments) {
+			return nil, false
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[segment[1:len(segment)-1]] = pathSegments[i]
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return params, len(patternSegments) == len(pathSegments)
+}
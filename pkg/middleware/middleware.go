@@ -0,0 +1,236 @@
+// Package middleware authenticates and authorizes the requests of a go
+// service against shield in process, as the authz middleware of the proxy
+// does for the services behind it. The net/http handler and the grpc
+// interceptors resolve the user of a request from its credentials and check
+// the permissions the rule matching the request asks for, the results are
+// cached locally for a short while.
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	AttributeTypeQuery       AttributeType = "query"
+	AttributeTypeHeader      AttributeType = "header"
+	AttributeTypeGRPCPayload AttributeType = "grpc_payload"
+	AttributeTypePathParam   AttributeType = "path_param"
+	AttributeTypeConstant    AttributeType = "constant"
+)
+
+var (
+	ErrUnauthenticated  = errors.New("request is not authenticated")
+	ErrPermissionDenied = errors.New("user doesn't have the permission")
+	ErrMissingAttribute = errors.New("attribute is missing in the request")
+	ErrNoRule           = errors.New("no rule matches the request")
+)
+
+// Shield is the api of shield the requests are authenticated and authorized
+// against, *shieldclient.Client is one. The credentials of the requests are
+// forwarded to it so it must not authenticate as a user of its own, a
+// shieldclient with a Token or an identity header in its Headers would check
+// the requests of anonymous callers as that user.
+type Shield interface {
+	GetCurrentUser(ctx context.Context, in *shieldv1beta1.GetCurrentUserRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetCurrentUserResponse, error)
+	CheckResourcePermission(ctx context.Context, in *shieldv1beta1.CheckResourcePermissionRequest, opts ...grpc.CallOption) (*shieldv1beta1.CheckResourcePermissionResponse, error)
+}
+
+type AttributeType string
+
+// Attribute is where a value of the request, like the id of the object a
+// permission is checked on, is read from
+type Attribute struct {
+	Type AttributeType `yaml:"type" mapstructure:"type"`
+	// Key is the name of the header, query or path param, or the field of
+	// the grpc request with the fields of nested messages separated by dots
+	Key string `yaml:"key" mapstructure:"key"`
+	// Value is the value of a constant attribute
+	Value string `yaml:"value" mapstructure:"value"`
+}
+
+// Permission is checked on the object the attribute of the request refers to
+type Permission struct {
+	Name      string `yaml:"name" mapstructure:"name"`
+	Namespace string `yaml:"namespace" mapstructure:"namespace"`
+	Attribute string `yaml:"attribute" mapstructure:"attribute"`
+}
+
+// Rule is the permissions the requests it matches are authorized with, the
+// user must have at least one of them
+type Rule struct {
+	// Method is the http method of the requests, any when it is empty, or the
+	// full grpc method like /odpf.shield.v1beta1.ShieldService/GetProject
+	Method string `yaml:"method" mapstructure:"method"`
+	// Path is the pattern of the http paths, a {name} segment matches any
+	// segment and is the path param of the name, a trailing * matches the
+	// rest of the path
+	Path string `yaml:"path" mapstructure:"path"`
+
+	// Public requests are let through without authentication
+	Public bool `yaml:"public" mapstructure:"public"`
+
+	Permissions []Permission         `yaml:"permissions" mapstructure:"permissions"`
+	Attributes  map[string]Attribute `yaml:"attributes" mapstructure:"attributes"`
+}
+
+type Config struct {
+	// IdentityHeader is the header with the email of the user a proxy in
+	// front of the service authenticated, like X-Shield-Email. It is
+	// forwarded to shield along with the authorization header, only when it
+	// is set as the callers could set it themselves otherwise.
+	IdentityHeader string `yaml:"identity_header" mapstructure:"identity_header"`
+
+	// Rules are matched in order, the first one matching a request applies
+	Rules []Rule `yaml:"rules" mapstructure:"rules"`
+	// AllowUnmatched lets the authenticated requests no rule matches
+	// through, they are rejected otherwise
+	AllowUnmatched bool `yaml:"allow_unmatched" mapstructure:"allow_unmatched"`
+
+	// CacheTTL is how long the users of the credentials and the results of
+	// the checks are kept, nothing is cached when it is not set
+	CacheTTL time.Duration `yaml:"cache_ttl" mapstructure:"cache_ttl"`
+	// CacheSize is the most users and results each kept, the least recently
+	// used are evicted first. 10000 when it is not set.
+	CacheSize int `yaml:"cache_size" mapstructure:"cache_size"`
+}
+
+// Middleware authenticates and authorizes the requests, its handler and
+// interceptors share the caches
+type Middleware struct {
+	shield Shield
+	cfg    Config
+	users  *cache
+	checks *cache
+}
+
+func New(shield Shield, cfg Config) *Middleware {
+	if cfg.CacheSize <= 0 {
+		cfg.CacheSize = 10000
+	}
+	return &Middleware{
+		shield: shield,
+		cfg:    cfg,
+		users:  newCache(cfg.CacheSize, cfg.CacheTTL),
+		checks: newCache(cfg.CacheSize, cfg.CacheTTL),
+	}
+}
+
+type userContextKey struct{}
+
+// UserFromContext returns the user the request of the context is
+// authenticated as
+func UserFromContext(ctx context.Context) (*shieldv1beta1.User, bool) {
+	usr, ok := ctx.Value(userContextKey{}).(*shieldv1beta1.User)
+	return usr, ok
+}
+
+// credentials are what a request is authenticated with
+type credentials struct {
+	authorization string
+	identity      string
+}
+
+func (c credentials) empty() bool {
+	return c.authorization == "" && c.identity == ""
+}
+
+// key identifies the credentials in the cache without keeping them
+func (c credentials) key() string {
+	sum := sha256.Sum256([]byte(c.authorization + "\x00" + c.identity))
+	return hex.EncodeToString(sum[:])
+}
+
+// outgoing is the context the requests to shield are sent as the caller with
+func (m *Middleware) outgoing(ctx context.Context, creds credentials) context.Context {
+	md := metadata.MD{}
+	if creds.authorization != "" {
+		md.Set("authorization", creds.authorization)
+	}
+	if creds.identity != "" {
+		md.Set(m.cfg.IdentityHeader, creds.identity)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// authenticate returns the user of the credentials
+func (m *Middleware) authenticate(ctx context.Context, creds credentials) (*shieldv1beta1.User, error) {
+	if creds.empty() {
+		return nil, ErrUnauthenticated
+	}
+	key := creds.key()
+	if usr, ok := m.users.get(key); ok {
+		return usr.(*shieldv1beta1.User), nil
+	}
+
+	res, err := m.shield.GetCurrentUser(m.outgoing(ctx, creds), &shieldv1beta1.GetCurrentUserRequest{})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unauthenticated, codes.NotFound, codes.PermissionDenied, codes.InvalidArgument:
+			return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, status.Convert(err).Message())
+		}
+		return nil, err
+	}
+	m.users.set(key, res.GetUser())
+	return res.GetUser(), nil
+}
+
+// authorize checks the user has one of the permissions of the rule on the
+// objects the attributes read from the request refer to
+func (m *Middleware) authorize(ctx context.Context, creds credentials, usr *shieldv1beta1.User, rule Rule, attribute func(Attribute) (string, error)) error {
+	for _, permission := range rule.Permissions {
+		attr, ok := rule.Attributes[permission.Attribute]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrMissingAttribute, permission.Attribute)
+		}
+		objectID, err := attribute(attr)
+		if err != nil {
+			return err
+		}
+		if objectID == "" {
+			return fmt.Errorf("%w: %s", ErrMissingAttribute, permission.Attribute)
+		}
+
+		key := strings.Join([]string{usr.GetId(), permission.Namespace, objectID, permission.Name}, "\x00")
+		allowed, cached := m.checks.get(key)
+		if !cached {
+			res, err := m.shield.CheckResourcePermission(m.outgoing(ctx, creds), &shieldv1beta1.CheckResourcePermissionRequest{
+				ObjectNamespace: permission.Namespace,
+				ObjectId:        objectID,
+				Permission:      permission.Name,
+			})
+			if err != nil {
+				return err
+			}
+			allowed = res.GetStatus()
+			m.checks.set(key, allowed)
+		}
+		if allowed.(bool) {
+			return nil
+		}
+	}
+	return ErrPermissionDenied
+}
+
+// attributeValue reads the constant attributes and the ones every request
+// has, the path params, queries and payloads are read by the caller
+func attributeValue(attr Attribute, header func(string) string) (string, bool) {
+	switch attr.Type {
+	case AttributeTypeConstant:
+		return attr.Value, true
+	case AttributeTypeHeader:
+		return header(attr.Key), true
+	}
+	return "", false
+}
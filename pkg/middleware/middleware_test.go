@@ -0,0 +1,194 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/pkg/middleware"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeShield authenticates the bearer tokens of its users, the users are
+// allowed the permissions of their grants
+type fakeShield struct {
+	mu     sync.Mutex
+	users  map[string]*shieldv1beta1.User
+	grants map[string]bool
+	calls  int
+}
+
+func (s *fakeShield) user(ctx context.Context) (*shieldv1beta1.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	md, _ := metadata.FromOutgoingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "no token")
+	}
+	usr, ok := s.users[values[0]]
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return usr, nil
+}
+
+func (s *fakeShield) GetCurrentUser(ctx context.Context, in *shieldv1beta1.GetCurrentUserRequest, opts ...grpc.CallOption) (*shieldv1beta1.GetCurrentUserResponse, error) {
+	usr, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &shieldv1beta1.GetCurrentUserResponse{User: usr}, nil
+}
+
+func (s *fakeShield) CheckResourcePermission(ctx context.Context, in *shieldv1beta1.CheckResourcePermissionRequest, opts ...grpc.CallOption) (*shieldv1beta1.CheckResourcePermissionResponse, error) {
+	usr, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	allowed := s.grants[usr.GetId()+":"+in.GetObjectNamespace()+":"+in.GetObjectId()+":"+in.GetPermission()]
+	return &shieldv1beta1.CheckResourcePermissionResponse{Status: allowed}, nil
+}
+
+func newFakeShield() *fakeShield {
+	return &fakeShield{
+		users: map[string]*shieldv1beta1.User{
+			"Bearer alice": {Id: "alice", Email: "alice@odpf.io"},
+			"Bearer bob":   {Id: "bob", Email: "bob@odpf.io"},
+		},
+		grants: map[string]bool{
+			"alice:shield/project:payments:view": true,
+			"bob:shield/project:ledger:edit":     true,
+		},
+	}
+}
+
+var projectRule = middleware.Rule{
+	Permissions: []middleware.Permission{
+		{Name: "view", Namespace: "shield/project", Attribute: "project"},
+		{Name: "edit", Namespace: "shield/project", Attribute: "project"},
+	},
+}
+
+func TestHandler(t *testing.T) {
+	shield := newFakeShield()
+	view := projectRule
+	view.Method = http.MethodGet
+	view.Path = "/projects/{id}/*"
+	view.Attributes = map[string]middleware.Attribute{
+		"project": {Type: middleware.AttributeTypePathParam, Key: "id"},
+	}
+	m := middleware.New(shield, middleware.Config{
+		Rules: []middleware.Rule{
+			{Path: "/ping", Public: true},
+			view,
+		},
+		CacheTTL: time.Minute,
+	})
+	handler := m.Handler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		usr, ok := middleware.UserFromContext(req.Context())
+		if ok {
+			rw.Write([]byte(usr.GetEmail()))
+		}
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		code   int
+		body   string
+	}{
+		{name: "should let the public requests through", method: http.MethodGet, path: "/ping", code: http.StatusOK},
+		{name: "should reject the requests without credentials", method: http.MethodGet, path: "/projects/payments/jobs", code: http.StatusUnauthorized},
+		{name: "should reject the invalid credentials", method: http.MethodGet, path: "/projects/payments/jobs", token: "Bearer eve", code: http.StatusUnauthorized},
+		{name: "should allow the user with one of the permissions", method: http.MethodGet, path: "/projects/payments/jobs", token: "Bearer alice", code: http.StatusOK, body: "alice@odpf.io"},
+		{name: "should allow the other permission", method: http.MethodGet, path: "/projects/ledger/jobs/1", token: "Bearer bob", code: http.StatusOK, body: "bob@odpf.io"},
+		{name: "should deny the user without the permissions", method: http.MethodGet, path: "/projects/ledger/jobs", token: "Bearer alice", code: http.StatusForbidden},
+		{name: "should deny the requests no rule matches", method: http.MethodDelete, path: "/projects/payments/jobs", token: "Bearer alice", code: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.code, rec.Code)
+			if tt.body != "" {
+				assert.Equal(t, tt.body, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("should cache the users and the checks", func(t *testing.T) {
+		shield.calls = 0
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/projects/payments/jobs", nil)
+			req.Header.Set("Authorization", "Bearer alice")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+		assert.Equal(t, 0, shield.calls)
+	})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	shield := newFakeShield()
+	get := projectRule
+	get.Method = "/odpf.shield.v1beta1.ShieldService/GetProject"
+	get.Attributes = map[string]middleware.Attribute{
+		"project": {Type: middleware.AttributeTypeGRPCPayload, Key: "id"},
+	}
+	interceptor := middleware.New(shield, middleware.Config{
+		Rules:          []middleware.Rule{get},
+		AllowUnmatched: true,
+	}).UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		usr, _ := middleware.UserFromContext(ctx)
+		return usr.GetEmail(), nil
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		req    interface{}
+		code   codes.Code
+		email  string
+	}{
+		{name: "should reject the requests without credentials", method: get.Method, req: &shieldv1beta1.GetProjectRequest{Id: "payments"}, code: codes.Unauthenticated},
+		{name: "should allow the object of the payload", method: get.Method, token: "Bearer alice", req: &shieldv1beta1.GetProjectRequest{Id: "payments"}, code: codes.OK, email: "alice@odpf.io"},
+		{name: "should deny the user without the permissions", method: get.Method, token: "Bearer bob", req: &shieldv1beta1.GetProjectRequest{Id: "payments"}, code: codes.PermissionDenied},
+		{name: "should reject the requests without the attribute", method: get.Method, token: "Bearer alice", req: &shieldv1beta1.GetProjectRequest{}, code: codes.InvalidArgument},
+		{name: "should let the requests no rule matches through", method: "/odpf.shield.v1beta1.ShieldService/ListProjects", token: "Bearer bob", req: &shieldv1beta1.ListProjectsRequest{}, code: codes.OK, email: "bob@odpf.io"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.token))
+			}
+			res, err := interceptor(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			require.Equal(t, tt.code, status.Code(err), err)
+			if tt.email != "" {
+				assert.Equal(t, tt.email, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// AWSConfig is the secrets manager the awssm://secret-id#field references
+// are read from, with the credentials of the default chain of the aws sdk:
+// the environment, the shared config files, web identities and the roles
+// of the instance or the task
+type AWSConfig struct {
+	// Region of the secrets, the region of the default chain when not set
+	Region string `yaml:"region" mapstructure:"region"`
+	// Endpoint of secrets manager, the one of the region when not set
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+}
+
+type awsProvider struct {
+	cfg AWSConfig
+
+	// the client is made on the first fetch, loading the default config
+	// reads the environment and the shared config files
+	mu     sync.Mutex
+	client *secretsmanager.Client
+}
+
+func newAWSProvider(cfg AWSConfig) *awsProvider {
+	return &awsProvider{cfg: cfg}
+}
+
+// Fetch gets the value of the secret, the secret id is the name or the arn
+// of the secret
+func (p *awsProvider) Fetch(ctx context.Context, ref *url.URL) (Secret, error) {
+	client, err := p.secretsManager(ctx)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	// an arn has colons, it is the opaque part of the reference
+	id := ref.Opaque
+	if id == "" {
+		id = strings.TrimPrefix(ref.Host+ref.Path, "/")
+	}
+	out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return Secret{}, ErrNotFound
+		}
+		return Secret{}, err
+	}
+	return fieldSecret(aws.ToString(out.SecretString), ref.Fragment)
+}
+
+func (p *awsProvider) secretsManager(ctx context.Context) (*secretsmanager.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	var opts []func(*config.LoadOptions) error
+	if p.cfg.Region != "" {
+		opts = append(opts, config.WithRegion(p.cfg.Region))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if awsCfg.Region == "" {
+		return nil, errors.New("aws region is not set")
+	}
+
+	p.client = secretsmanager.NewFromConfig(awsCfg, func(o *secretsmanager.Options) {
+		if p.cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(p.cfg.Endpoint)
+		}
+	})
+	return p.client, nil
+}
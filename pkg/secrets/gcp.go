@@ -0,0 +1,97 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+// GCPConfig is the secret manager the
+// gcpsm://projects/project/secrets/secret/versions/version#field references
+// are read from, the latest version when the version is left out. The
+// application default credentials are used.
+type GCPConfig struct {
+	// Endpoint of secret manager, the public one when not set
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+}
+
+type gcpProvider struct {
+	endpoint string
+
+	mu     sync.Mutex
+	client *http.Client
+}
+
+func newGCPProvider(cfg GCPConfig) *gcpProvider {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://secretmanager.googleapis.com"
+	}
+	return &gcpProvider{endpoint: strings.TrimRight(cfg.Endpoint, "/")}
+}
+
+// httpClient is authenticated with the default credentials, looked up on
+// the first fetch so the servers not using secret manager don't need them
+func (p *gcpProvider) httpClient() (*http.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		// the client outlives the fetch it is created for
+		client, err := google.DefaultClient(context.Background(), "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			return nil, err
+		}
+		client.Timeout = 10 * time.Second
+		p.client = client
+	}
+	return p.client, nil
+}
+
+// Fetch accesses the version of the secret
+func (p *gcpProvider) Fetch(ctx context.Context, ref *url.URL) (Secret, error) {
+	name := strings.Trim(ref.Host+ref.Path, "/")
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	client, err := p.httpClient()
+	if err != nil {
+		return Secret{}, fmt.Errorf("gcp credentials: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return Secret{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Secret{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Secret{}, ErrNotFound
+	}
+
+	var result struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil || resp.StatusCode != http.StatusOK {
+		return Secret{}, fmt.Errorf("secret manager responded with %d %s", resp.StatusCode, result.Error.Message)
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Payload.Data)
+	if err != nil {
+		return Secret{}, err
+	}
+	return fieldSecret(string(data), ref.Fragment)
+}
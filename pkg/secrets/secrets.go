@@ -0,0 +1,196 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("secret doesn't exist")
+	ErrFieldMissing = errors.New("secret doesn't have the field")
+)
+
+// Config is how the secrets referenced in the server config are fetched,
+// a config value like vault://database/creds/shield#password is fetched from
+// the provider of its scheme and the others are used as they are
+type Config struct {
+	// TTL is how long the secrets without a lease are used before they are
+	// fetched again, they are fetched once when it is 0
+	TTL time.Duration `yaml:"ttl" mapstructure:"ttl" default:"10m"`
+
+	Vault VaultConfig `yaml:"vault" mapstructure:"vault"`
+	AWS   AWSConfig   `yaml:"aws" mapstructure:"aws"`
+	GCP   GCPConfig   `yaml:"gcp" mapstructure:"gcp"`
+}
+
+// Secret is a value fetched from a provider
+type Secret struct {
+	Value string
+	// TTL is the lease of the secret, the ttl of the config is used when it
+	// is 0
+	TTL time.Duration
+}
+
+// Provider fetches the secrets of the references of its scheme, the field
+// after # of a reference is picked from a secret holding a json object
+type Provider interface {
+	Fetch(ctx context.Context, ref *url.URL) (Secret, error)
+}
+
+// Resolver fetches the secrets of the references in the config from the
+// providers of their scheme
+type Resolver struct {
+	providers map[string]Provider
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+func NewResolver(cfg Config) *Resolver {
+	r := &Resolver{ttl: cfg.TTL, now: time.Now}
+	r.providers = map[string]Provider{
+		"env":   envProvider{},
+		"file":  fileProvider{},
+		"vault": newVaultProvider(cfg.Vault),
+		"awssm": newAWSProvider(cfg.AWS),
+		"gcpsm": newGCPProvider(cfg.GCP),
+	}
+	return r
+}
+
+// localResolver resolves the references to the environment and to files
+// alone, for the credentials of the providers
+func localResolver() *Resolver {
+	return &Resolver{
+		providers: map[string]Provider{"env": envProvider{}, "file": fileProvider{}},
+		ttl:       time.Minute,
+		now:       time.Now,
+	}
+}
+
+// Resolve returns the secret of the reference, or the value itself when it
+// is not a reference
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	return r.Value(value).Get(ctx)
+}
+
+// Value returns the value kept up to date with the secret of the reference
+func (r *Resolver) Value(value string) *Value {
+	v := &Value{resolver: r, value: value}
+	if ref, err := url.Parse(value); err == nil {
+		if provider, ok := r.providers[ref.Scheme]; ok {
+			v.ref, v.provider = ref, provider
+		}
+	}
+	return v
+}
+
+// Value is a secret fetched again before its lease expires, the value of the
+// config when it is not a reference
+type Value struct {
+	resolver *Resolver
+	ref      *url.URL
+	provider Provider
+
+	mu        sync.Mutex
+	value     string
+	fetched   bool
+	refreshAt time.Time
+	expiresAt time.Time
+}
+
+// Get returns the secret, it is fetched when it wasn't yet or its lease is
+// about to expire. The secret fetched before is returned while it is valid
+// when it can't be fetched again.
+func (v *Value) Get(ctx context.Context) (string, error) {
+	if v.provider == nil {
+		return v.value, nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := v.resolver.now()
+	if v.fetched && (v.expiresAt.IsZero() || now.Before(v.refreshAt)) {
+		return v.value, nil
+	}
+
+	secret, err := v.provider.Fetch(ctx, v.ref)
+	if err != nil {
+		if v.fetched && now.Before(v.expiresAt) {
+			return v.value, nil
+		}
+		return "", fmt.Errorf("%s: %w", v.ref.Redacted(), err)
+	}
+
+	v.value, v.fetched = secret.Value, true
+	ttl := secret.TTL
+	if ttl <= 0 {
+		ttl = v.resolver.ttl
+	}
+	v.refreshAt, v.expiresAt = time.Time{}, time.Time{}
+	if ttl > 0 {
+		// fetched again a bit before the lease expires, so a credential
+		// isn't used right when it is revoked
+		v.refreshAt = now.Add(ttl * 3 / 4)
+		v.expiresAt = now.Add(ttl)
+	}
+	return v.value, nil
+}
+
+// fieldSecret is the field of a secret holding a json object, the secret
+// itself when no field is asked for
+func fieldSecret(secret, name string) (Secret, error) {
+	if name == "" {
+		return Secret{Value: secret}, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return Secret{}, fmt.Errorf("secret is not a json object to get field %s from", name)
+	}
+	value, err := fieldOf(fields, name)
+	return Secret{Value: value}, err
+}
+
+func fieldOf(fields map[string]any, name string) (string, error) {
+	value, ok := fields[name]
+	if !ok {
+		return "", fmt.Errorf("%w %s", ErrFieldMissing, name)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	return string(b), err
+}
+
+type envProvider struct{}
+
+// Fetch returns the environment variable of env://NAME
+func (envProvider) Fetch(ctx context.Context, ref *url.URL) (Secret, error) {
+	value, ok := os.LookupEnv(ref.Host)
+	if !ok {
+		return Secret{}, ErrNotFound
+	}
+	return fieldSecret(value, ref.Fragment)
+}
+
+type fileProvider struct{}
+
+// Fetch returns the content of file:///path without its trailing newline,
+// it is read again every ttl so a file written by an agent can change
+func (fileProvider) Fetch(ctx context.Context, ref *url.URL) (Secret, error) {
+	b, err := os.ReadFile(ref.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Secret{}, ErrNotFound
+		}
+		return Secret{}, err
+	}
+	return fieldSecret(strings.TrimRight(string(b), "\r\n"), ref.Fragment)
+}
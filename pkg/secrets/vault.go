@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultConfig is the vault the vault://path#field references are read from,
+// path is the api path of the secret without /v1, like secret/data/shield
+// for a kv v2 secret or database/static-creds/shield for a database one
+type VaultConfig struct {
+	// Address of vault, VAULT_ADDR when not set
+	Address string `yaml:"address" mapstructure:"address"`
+	// Token authenticates to vault, a reference like file:///vault/token
+	// to the token the vault agent writes. VAULT_TOKEN when not set.
+	Token string `yaml:"token" mapstructure:"token"`
+	// Namespace of vault enterprise
+	Namespace string `yaml:"namespace" mapstructure:"namespace"`
+}
+
+type vaultProvider struct {
+	address   string
+	namespace string
+	token     *Value
+	client    *http.Client
+}
+
+func newVaultProvider(cfg VaultConfig) *vaultProvider {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Token == "" {
+		cfg.Token = "env://VAULT_TOKEN"
+	}
+	return &vaultProvider{
+		address:   strings.TrimRight(cfg.Address, "/"),
+		namespace: cfg.Namespace,
+		token:     localResolver().Value(cfg.Token),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type vaultResponse struct {
+	LeaseDuration int            `json:"lease_duration"`
+	Data          map[string]any `json:"data"`
+	Errors        []string       `json:"errors"`
+}
+
+// Fetch reads the secret from vault, the field is looked up in the data of
+// a kv v2 secret. A secret with a lease is read again before it ends.
+func (p *vaultProvider) Fetch(ctx context.Context, ref *url.URL) (Secret, error) {
+	if p.address == "" {
+		return Secret{}, errors.New("vault address is not set")
+	}
+	token, err := p.token.Get(ctx)
+	if err != nil {
+		return Secret{}, fmt.Errorf("vault token: %w", err)
+	}
+
+	path := strings.Trim(ref.Host+ref.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/v1/"+path, nil)
+	if err != nil {
+		return Secret{}, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Secret{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Secret{}, ErrNotFound
+	}
+
+	var vr vaultResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&vr); err != nil || resp.StatusCode != http.StatusOK {
+		return Secret{}, fmt.Errorf("vault responded with %d %s", resp.StatusCode, strings.Join(vr.Errors, ", "))
+	}
+
+	data := vr.Data
+	// a kv v2 secret has its fields under data, next to its metadata
+	if nested, ok := data["data"].(map[string]any); ok && data["metadata"] != nil {
+		data = nested
+	}
+	name := ref.Fragment
+	if name == "" {
+		if len(data) != 1 {
+			return Secret{}, fmt.Errorf("vault secret has %d fields, the field to use is set after #", len(data))
+		}
+		for n := range data {
+			name = n
+		}
+	}
+	value, err := fieldOf(data, name)
+	if err != nil {
+		return Secret{}, err
+	}
+	return Secret{Value: value, TTL: time.Duration(vr.LeaseDuration) * time.Second}, nil
+}
@@ -0,0 +1,262 @@
+// Package shieldclient is the go client of the shield api, it wraps the grpc
+// client with the headers authenticating the requests, retries of the
+// requests shield is unavailable for and a pool of connections, along with
+// helpers for what services commonly ask shield.
+package shieldclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ProjectNamespace is the namespace the permissions on projects are checked in
+const ProjectNamespace = "shield/project"
+
+type Config struct {
+	// Host is the grpc address of shield, like localhost:8081
+	Host string
+	// TLS secures the connections, they are plaintext when it is nil
+	TLS *tls.Config
+
+	// Token returns the access token sent as the bearer token of the
+	// requests, no token is sent when it returns an empty one
+	Token func(ctx context.Context) (string, error)
+	// Headers are sent with every request, like the identity header of
+	// shield with the email of the user a service acts for
+	Headers map[string]string
+
+	// PoolSize is the number of connections the requests are spread over,
+	// 1 when it is not set
+	PoolSize int
+	// Block waits for the connections to be up before New returns, until
+	// the deadline of its context
+	Block bool
+
+	// MaxAttempts is the most times a request shield is unavailable for is
+	// sent, 3 when it is not set and 1 to not retry
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubling up to
+	// MaxBackoff for the next ones. 100ms and 2s when they are not set.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Client is the shield api, the rpcs of the service are called on it
+// directly and the helpers combine them
+type Client struct {
+	shieldv1beta1.ShieldServiceClient
+	pool *pool
+}
+
+// New connects to shield, the connections are kept until Close
+func New(ctx context.Context, cfg Config) (*Client, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("shield host is not set")
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 2 * time.Second
+	}
+
+	transport := insecure.NewCredentials()
+	if cfg.TLS != nil {
+		transport = credentials.NewTLS(cfg.TLS)
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(transport),
+		// the headers are attached once so every attempt sends them
+		grpc.WithChainUnaryInterceptor(authenticate(cfg), retry(cfg)),
+	}
+	if cfg.Block {
+		opts = append(opts, grpc.WithBlock())
+	}
+
+	p := &pool{}
+	for i := 0; i < cfg.PoolSize; i++ {
+		conn, err := grpc.DialContext(ctx, cfg.Host, opts...)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.conns = append(p.conns, conn)
+	}
+	return &Client{
+		ShieldServiceClient: shieldv1beta1.NewShieldServiceClient(p),
+		pool:                p,
+	}, nil
+}
+
+// Close closes the connections of the client
+func (c *Client) Close() error {
+	return c.pool.Close()
+}
+
+// pool spreads the requests over its connections round robin, the
+// connections multiplex the requests so a few of them spread the load of a
+// busy client over the instances of shield behind a load balancer
+type pool struct {
+	conns []*grpc.ClientConn
+	next  uint32
+}
+
+func (p *pool) conn() *grpc.ClientConn {
+	return p.conns[int(atomic.AddUint32(&p.next, 1))%len(p.conns)]
+}
+
+func (p *pool) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	return p.conn().Invoke(ctx, method, args, reply, opts...)
+}
+
+func (p *pool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.conn().NewStream(ctx, desc, method, opts...)
+}
+
+func (p *pool) Close() error {
+	var errs []string
+	for _, conn := range p.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// authenticate sends the headers of the config and the token with the
+// requests, the headers set on the context of a request are left as they are
+func authenticate(cfg Config) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		for key, value := range cfg.Headers {
+			if len(md.Get(key)) == 0 {
+				ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+			}
+		}
+		if cfg.Token != nil && len(md.Get("authorization")) == 0 {
+			token, err := cfg.Token(ctx)
+			if err != nil {
+				return err
+			}
+			if token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// retry sends the requests shield is unavailable for again after a backoff
+// with jitter, the request never reached shield or it was shutting down
+func retry(cfg Config) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		backoff := cfg.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if status.Code(err) != codes.Unavailable || attempt >= cfg.MaxAttempts {
+				return err
+			}
+
+			wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(wait):
+			}
+			if backoff *= 2; backoff > cfg.MaxBackoff {
+				backoff = cfg.MaxBackoff
+			}
+		}
+	}
+}
+
+// EnsureOrganization returns the organization of the slug, it is created
+// with the name and metadata when it doesn't exist
+func (c *Client) EnsureOrganization(ctx context.Context, slug, name string, orgMetadata map[string]any) (*shieldv1beta1.Organization, error) {
+	res, err := c.GetOrganization(ctx, &shieldv1beta1.GetOrganizationRequest{Id: slug})
+	if err == nil {
+		return res.GetOrganization(), nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+
+	body := &shieldv1beta1.OrganizationRequestBody{Name: name, Slug: slug}
+	if len(orgMetadata) > 0 {
+		if body.Metadata, err = structpb.NewStruct(orgMetadata); err != nil {
+			return nil, err
+		}
+	}
+	created, err := c.CreateOrganization(ctx, &shieldv1beta1.CreateOrganizationRequest{Body: body})
+	if status.Code(err) == codes.AlreadyExists {
+		// created by another caller in between
+		res, err := c.GetOrganization(ctx, &shieldv1beta1.GetOrganizationRequest{Id: slug})
+		if err != nil {
+			return nil, err
+		}
+		return res.GetOrganization(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return created.GetOrganization(), nil
+}
+
+// CheckPermission checks the permission of the user the request is
+// authenticated as on the object of the namespace, objects of the system
+// namespaces are referred to by id and the resources by name
+func (c *Client) CheckPermission(ctx context.Context, namespace, objectID, permission string) (bool, error) {
+	res, err := c.CheckResourcePermission(ctx, &shieldv1beta1.CheckResourcePermissionRequest{
+		ObjectNamespace: namespace,
+		ObjectId:        objectID,
+		Permission:      permission,
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.GetStatus(), nil
+}
+
+// ListUserProjects returns the projects the user the requests are
+// authenticated as has the permission on, like view, every project is
+// checked
+func (c *Client) ListUserProjects(ctx context.Context, permission string) ([]*shieldv1beta1.Project, error) {
+	res, err := c.ListProjects(ctx, &shieldv1beta1.ListProjectsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var projects []*shieldv1beta1.Project
+	for _, project := range res.GetProjects() {
+		allowed, err := c.CheckPermission(ctx, ProjectNamespace, project.GetId(), permission)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
@@ -0,0 +1,165 @@
+package shieldclient_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/shield/pkg/shieldclient"
+	shieldv1beta1 "github.com/odpf/shield/proto/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeShield serves the organizations and projects of the tests, the user
+// of the requests is the email of their identity header
+type fakeShield struct {
+	shieldv1beta1.UnimplementedShieldServiceServer
+
+	mu            sync.Mutex
+	organizations map[string]*shieldv1beta1.Organization
+	unavailable   int
+	headers       []metadata.MD
+}
+
+func (s *fakeShield) record(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.headers = append(s.headers, md)
+	if s.unavailable > 0 {
+		s.unavailable--
+		return status.Error(codes.Unavailable, "shutting down")
+	}
+	return nil
+}
+
+func (s *fakeShield) GetOrganization(ctx context.Context, req *shieldv1beta1.GetOrganizationRequest) (*shieldv1beta1.GetOrganizationResponse, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org, ok := s.organizations[req.GetId()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "org doesn't exist")
+	}
+	return &shieldv1beta1.GetOrganizationResponse{Organization: org}, nil
+}
+
+func (s *fakeShield) CreateOrganization(ctx context.Context, req *shieldv1beta1.CreateOrganizationRequest) (*shieldv1beta1.CreateOrganizationResponse, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org := &shieldv1beta1.Organization{
+		Id:       "org-" + req.GetBody().GetSlug(),
+		Name:     req.GetBody().GetName(),
+		Slug:     req.GetBody().GetSlug(),
+		Metadata: req.GetBody().GetMetadata(),
+	}
+	s.organizations[org.Slug] = org
+	return &shieldv1beta1.CreateOrganizationResponse{Organization: org}, nil
+}
+
+func (s *fakeShield) ListProjects(ctx context.Context, req *shieldv1beta1.ListProjectsRequest) (*shieldv1beta1.ListProjectsResponse, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+	return &shieldv1beta1.ListProjectsResponse{Projects: []*shieldv1beta1.Project{
+		{Id: "payments"}, {Id: "ledger"}, {Id: "search"},
+	}}, nil
+}
+
+func (s *fakeShield) CheckResourcePermission(ctx context.Context, req *shieldv1beta1.CheckResourcePermissionRequest) (*shieldv1beta1.CheckResourcePermissionResponse, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	allowed := req.GetObjectNamespace() == shieldclient.ProjectNamespace &&
+		req.GetObjectId() != "search" && req.GetPermission() == "view" &&
+		len(md.Get("x-shield-email")) == 1 && md.Get("x-shield-email")[0] == "alice@odpf.io"
+	return &shieldv1beta1.CheckResourcePermissionResponse{Status: allowed}, nil
+}
+
+func serve(t *testing.T, fake *fakeShield) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	shieldv1beta1.RegisterShieldServiceServer(server, fake)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestClient(t *testing.T) {
+	fake := &fakeShield{organizations: map[string]*shieldv1beta1.Organization{}}
+	host := serve(t, fake)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := shieldclient.New(ctx, shieldclient.Config{
+		Host:           host,
+		Token:          func(ctx context.Context) (string, error) { return "token", nil },
+		Headers:        map[string]string{"x-shield-email": "alice@odpf.io"},
+		PoolSize:       2,
+		Block:          true,
+		InitialBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	t.Run("should create the organization once", func(t *testing.T) {
+		org, err := client.EnsureOrganization(ctx, "odpf", "ODPF", map[string]any{"team": "platform"})
+		require.NoError(t, err)
+		assert.Equal(t, "org-odpf", org.GetId())
+		assert.Equal(t, "platform", org.GetMetadata().AsMap()["team"])
+
+		fake.organizations["odpf"].Name = "Renamed"
+		org, err = client.EnsureOrganization(ctx, "odpf", "ODPF", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", org.GetName())
+	})
+
+	t.Run("should send the token and the headers unless the request has its own", func(t *testing.T) {
+		fake.headers = nil
+		_, err := client.CheckPermission(ctx, shieldclient.ProjectNamespace, "payments", "view")
+		require.NoError(t, err)
+		own := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer own", "x-shield-email", "bob@odpf.io")
+		allowed, err := client.CheckPermission(own, shieldclient.ProjectNamespace, "payments", "view")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		require.Len(t, fake.headers, 2)
+		assert.Equal(t, []string{"Bearer token"}, fake.headers[0].Get("authorization"))
+		assert.Equal(t, []string{"alice@odpf.io"}, fake.headers[0].Get("x-shield-email"))
+		assert.Equal(t, []string{"Bearer own"}, fake.headers[1].Get("authorization"))
+		assert.Equal(t, []string{"bob@odpf.io"}, fake.headers[1].Get("x-shield-email"))
+	})
+
+	t.Run("should retry the requests shield is unavailable for", func(t *testing.T) {
+		fake.headers = nil
+		fake.unavailable = 2
+		projects, err := client.ListUserProjects(ctx, "view")
+		require.NoError(t, err)
+		var ids []string
+		for _, p := range projects {
+			ids = append(ids, p.GetId())
+		}
+		assert.Equal(t, []string{"payments", "ledger"}, ids)
+		// the two unavailable attempts, the list and the three checks
+		assert.Len(t, fake.headers, 6)
+
+		fake.unavailable = 3
+		_, err = client.ListUserProjects(ctx, "view")
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
@@ -0,0 +1,213 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Config terminates tls on a port of its own next to the plaintext port of a
+// server, with a certificate from files or obtained and renewed over acme
+type Config struct {
+	// Port to serve tls on, tls is not served when it is 0
+	Port int `yaml:"port" mapstructure:"port"`
+
+	// CertFile and KeyFile are the certificate of the server, they are
+	// loaded again when they change
+	CertFile string `yaml:"cert_file" mapstructure:"cert_file"`
+	KeyFile  string `yaml:"key_file" mapstructure:"key_file"`
+
+	// ACME obtains the certificates of its domains instead of the files
+	ACME ACMEConfig `yaml:"acme" mapstructure:"acme"`
+}
+
+type ACMEConfig struct {
+	// Domains the certificates are obtained for, the tls handshakes for
+	// other server names are refused
+	Domains []string `yaml:"domains" mapstructure:"domains"`
+	// Email the certificate authority tells about the expiring certificates
+	Email string `yaml:"email" mapstructure:"email"`
+	// CacheDir keeps the account key and the certificates across restarts
+	// so they are not issued again on every start
+	CacheDir string `yaml:"cache_dir" mapstructure:"cache_dir"`
+	// DirectoryURL is the directory of the certificate authority, lets
+	// encrypt when not set
+	DirectoryURL string `yaml:"directory_url" mapstructure:"directory_url"`
+	// HTTPPort serves the http-01 challenges when set, usually on port 80,
+	// the tls-alpn-01 challenges are always answered on the tls port
+	HTTPPort int `yaml:"http_port" mapstructure:"http_port"`
+}
+
+func (c Config) Enabled() bool {
+	return c.Port != 0
+}
+
+func (c Config) validate() error {
+	acmeEnabled := len(c.ACME.Domains) > 0
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("a certificate needs both a cert file and a key file")
+	}
+	if c.CertFile == "" && !acmeEnabled {
+		return errors.New("tls needs either a certificate or acme domains")
+	}
+	if c.CertFile != "" && acmeEnabled {
+		return errors.New("tls can't have both a certificate and acme domains")
+	}
+	if acmeEnabled && c.ACME.CacheDir == "" {
+		return errors.New("acme needs a cache dir")
+	}
+	return nil
+}
+
+// Terminator is the tls config of a server and the handler of the acme
+// http-01 challenges, the handler is nil without acme
+type Terminator struct {
+	TLSConfig *tls.Config
+	Challenge http.Handler
+}
+
+func New(cfg Config) (*Terminator, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.ACME.Domains) == 0 {
+		cert := &certFile{certFile: cfg.CertFile, keyFile: cfg.KeyFile}
+		modTime, err := cert.modified()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificate: %w", err)
+		}
+		if err := cert.load(); err != nil {
+			return nil, err
+		}
+		cert.modTime = modTime
+		return &Terminator{TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			NextProtos:     []string{"h2", "http/1.1"},
+			GetCertificate: cert.getCertificate,
+		}}, nil
+	}
+
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.ACME.Domains...),
+		Cache:      autocert.DirCache(cfg.ACME.CacheDir),
+		Email:      cfg.ACME.Email,
+	}
+	if cfg.ACME.DirectoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: cfg.ACME.DirectoryURL}
+	}
+	tlsConfig := m.TLSConfig()
+	tlsConfig.MinVersion = tls.VersionTLS12
+	return &Terminator{TLSConfig: tlsConfig, Challenge: m.HTTPHandler(nil)}, nil
+}
+
+// certFile loads the certificate again on a handshake once its files were
+// modified, a certificate that can't be loaded keeps the previous one
+type certFile struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func (c *certFile) load() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+	c.cert = &cert
+	return nil
+}
+
+func (c *certFile) modified() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (c *certFile) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modTime, err := c.modified()
+	if err != nil || !modTime.After(c.modTime) {
+		return c.cert, nil
+	}
+	if err := c.load(); err != nil {
+		return c.cert, nil
+	}
+	c.modTime = modTime
+	return c.cert, nil
+}
+
+// Serve serves the handler over tls on the port of the config and the acme
+// http-01 challenges on their port, until the returned shutdown is called
+func Serve(logger log.Logger, host string, cfg Config, handler http.Handler) (func(ctx context.Context) error, error) {
+	terminator, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsAddr := fmt.Sprintf("%s:%d", host, cfg.Port)
+	l, err := net.Listen("tcp", tlsAddr)
+	if err != nil {
+		return nil, err
+	}
+	var challengeL net.Listener
+	challengeAddr := fmt.Sprintf("%s:%d", host, cfg.ACME.HTTPPort)
+	if terminator.Challenge != nil && cfg.ACME.HTTPPort != 0 {
+		if challengeL, err = net.Listen("tcp", challengeAddr); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+
+	tlsSrv := &http.Server{Handler: handler, TLSConfig: terminator.TLSConfig}
+	servers := []*http.Server{tlsSrv}
+	go func() {
+		if err := tlsSrv.ServeTLS(l, "", ""); err != nil && err != http.ErrServerClosed {
+			logger.Error("failed to serve tls", "url", tlsAddr, "err", err)
+		}
+	}()
+	if challengeL != nil {
+		challengeSrv := &http.Server{Handler: terminator.Challenge}
+		servers = append(servers, challengeSrv)
+		go func() {
+			if err := challengeSrv.Serve(challengeL); err != nil && err != http.ErrServerClosed {
+				logger.Error("failed to serve acme challenges", "url", challengeAddr, "err", err)
+			}
+		}()
+	}
+	logger.Info("serving tls", "url", tlsAddr, "acme", terminator.Challenge != nil)
+
+	return func(ctx context.Context) error {
+		var shutdownErr error
+		for _, srv := range servers {
+			if err := srv.Shutdown(ctx); err != nil && shutdownErr == nil {
+				shutdownErr = err
+			}
+		}
+		return shutdownErr
+	}, nil
+}
@@ -0,0 +1,160 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self signed certificate for the common name to the
+// files of the config
+func writeCert(t *testing.T, cfg Config, commonName string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(cfg.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(cfg.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{
+			name:    "should need a certificate or acme domains",
+			cfg:     Config{Port: 8443},
+			wantErr: "either a certificate or acme domains",
+		},
+		{
+			name:    "should need both a cert file and a key file",
+			cfg:     Config{Port: 8443, CertFile: "tls.crt"},
+			wantErr: "both a cert file and a key file",
+		},
+		{
+			name:    "should not have both a certificate and acme domains",
+			cfg:     Config{Port: 8443, CertFile: "tls.crt", KeyFile: "tls.key", ACME: ACMEConfig{Domains: []string{"shield.odpf.io"}}},
+			wantErr: "both a certificate and acme domains",
+		},
+		{
+			name:    "should need a cache dir for acme",
+			cfg:     Config{Port: 8443, ACME: ACMEConfig{Domains: []string{"shield.odpf.io"}}},
+			wantErr: "cache dir",
+		},
+		{
+			name:    "should fail when the certificate doesn't exist",
+			cfg:     Config{Port: 8443, CertFile: "missing.crt", KeyFile: "missing.key"},
+			wantErr: "failed to load certificate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("should answer the acme challenges", func(t *testing.T) {
+		terminator, err := New(Config{Port: 8443, ACME: ACMEConfig{Domains: []string{"shield.odpf.io"}, CacheDir: t.TempDir()}})
+		require.NoError(t, err)
+		assert.NotNil(t, terminator.Challenge)
+		assert.Contains(t, terminator.TLSConfig.NextProtos, "acme-tls/1")
+	})
+}
+
+func TestServe(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{
+		Port:     freePort(t),
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+	}
+	cert := writeCert(t, cfg, "shield")
+
+	shutdown, err := Serve(log.NewNoop(), "127.0.0.1", cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	}))
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	get := func(cert *x509.Certificate) (string, error) {
+		roots := x509.NewCertPool()
+		roots.AddCert(cert)
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots},
+			ForceAttemptHTTP2: true,
+		}}
+		res, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d", cfg.Port))
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		return string(body), err
+	}
+
+	t.Run("should serve http/2 over tls", func(t *testing.T) {
+		proto, err := get(cert)
+		assert.NoError(t, err)
+		assert.Equal(t, "HTTP/2.0", proto)
+	})
+
+	t.Run("should serve the certificate written last", func(t *testing.T) {
+		// the modification time has to move past the one of the first
+		// certificate on file systems with coarse timestamps
+		time.Sleep(10 * time.Millisecond)
+		renewed := writeCert(t, cfg, "shield")
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(cfg.CertFile, future, future))
+
+		_, err := get(cert)
+		assert.Error(t, err)
+		proto, err := get(renewed)
+		assert.NoError(t, err)
+		assert.Equal(t, "HTTP/2.0", proto)
+	})
+}
@@ -97,12 +97,12 @@ func startSpiceDB(logger log.Logger, network *docker.Network, pool *dockertest.P
 			spiceClient, err = spicedb.New(spicedb.Config{
 				Host: "localhost",
 				Port: extPort,
-			}, logger)
+			}, nil, logger)
 			if err != nil {
 				return err
 			}
 		}
-		return spiceClient.Check()
+		return spiceClient.Check(context.Background())
 	}); err != nil {
 		err = fmt.Errorf("could not connect to docker: %w", err)
 		return
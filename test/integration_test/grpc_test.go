@@ -44,7 +44,7 @@ func TestGRPCProxyHelloWorld(t *testing.T) {
 	}
 
 	responseHooks := hookPipeline(log.NewNoop())
-	h2cProxy := proxy.NewH2c(proxy.NewH2cRoundTripper(log.NewNoop(), responseHooks), proxy.NewDirector())
+	h2cProxy := proxy.NewH2c(proxy.NewH2cRoundTripper(log.NewNoop(), responseHooks, proxy.NewBreakers("test"), nil), proxy.NewDirector())
 	ruleRepo := blob.NewRuleRepository(log.NewNoop(), blobFS)
 	if err := ruleRepo.InitCache(baseCtx, time.Minute); err != nil {
 		t.Fatal(err)
@@ -144,7 +144,7 @@ func BenchmarkGRPCProxyHelloWorld(b *testing.B) {
 		b.Fatal(err)
 	}
 
-	h2cProxy := proxy.NewH2c(proxy.NewH2cRoundTripper(log.NewNoop(), hook.New()), proxy.NewDirector())
+	h2cProxy := proxy.NewH2c(proxy.NewH2cRoundTripper(log.NewNoop(), hook.New(), proxy.NewBreakers("test"), nil), proxy.NewDirector())
 	ruleRepo := blob.NewRuleRepository(log.NewNoop(), blobFS)
 	if err := ruleRepo.InitCache(baseCtx, time.Minute); err != nil {
 		b.Fatal(err)
@@ -53,7 +53,7 @@ func TestREST(t *testing.T) {
 	}
 
 	responseHooks := hookPipeline(log.NewNoop())
-	h2cProxy := proxy.NewH2c(proxy.NewH2cRoundTripper(log.NewNoop(), responseHooks), proxy.NewDirector())
+	h2cProxy := proxy.NewH2c(proxy.NewH2cRoundTripper(log.NewNoop(), responseHooks, proxy.NewBreakers("test"), nil), proxy.NewDirector())
 	ruleRepo := blob.NewRuleRepository(log.NewNoop(), blobFS)
 	if err := ruleRepo.InitCache(baseCtx, time.Minute); err != nil {
 		t.Fatal(err)
@@ -184,7 +184,7 @@ func BenchmarkProxyOverHttp(b *testing.B) {
 		b.Fatal(err)
 	}
 
-	h2cProxy := proxy.NewH2c(proxy.NewH2cRoundTripper(log.NewNoop(), hook.New()), proxy.NewDirector())
+	h2cProxy := proxy.NewH2c(proxy.NewH2cRoundTripper(log.NewNoop(), hook.New(), proxy.NewBreakers("test"), nil), proxy.NewDirector())
 	ruleRepo := blob.NewRuleRepository(log.NewNoop(), blobFS)
 	if err := ruleRepo.InitCache(baseCtx, time.Minute); err != nil {
 		b.Fatal(err)